@@ -9,20 +9,21 @@ import (
 
 	"github.com/kaspanet/kaspad/infrastructure/config"
 	"github.com/kaspanet/kaspad/infrastructure/db/database"
-	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
 	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/kaspanet/kaspad/infrastructure/os/execenv"
 	"github.com/kaspanet/kaspad/infrastructure/os/limits"
 	"github.com/kaspanet/kaspad/infrastructure/os/signal"
+	"github.com/kaspanet/kaspad/infrastructure/os/systemd"
 	"github.com/kaspanet/kaspad/infrastructure/os/winservice"
 	"github.com/kaspanet/kaspad/util/panics"
 	"github.com/kaspanet/kaspad/util/profiling"
 	"github.com/kaspanet/kaspad/version"
+	"github.com/pkg/errors"
 )
 
 const (
-	leveldbCacheSizeMiB = 256
-	defaultDataDirname  = "datadir2"
+	databaseCacheSizeMiB = 256
+	defaultDataDirname   = "datadir2"
 )
 
 var desiredLimits = &limits.DesiredLimits{
@@ -131,6 +132,7 @@ func (app *kaspadApp) main(startedChan chan<- struct{}) error {
 
 	defer func() {
 		log.Infof("Gracefully shutting down kaspad...")
+		notifySystemd(systemd.Stopping)
 
 		shutdownDone := make(chan struct{})
 		go func() {
@@ -153,6 +155,7 @@ func (app *kaspadApp) main(startedChan chan<- struct{}) error {
 	if startedChan != nil {
 		startedChan <- struct{}{}
 	}
+	notifySystemd(systemd.Ready)
 
 	// Wait until the interrupt signal is received from an OS signal or
 	// shutdown is requested through one of the subsystems such as the RPC
@@ -161,6 +164,15 @@ func (app *kaspadApp) main(startedChan chan<- struct{}) error {
 	return nil
 }
 
+// notifySystemd notifies systemd of the given state when kaspad is run as a systemd
+// service of Type=notify
+func notifySystemd(state string) {
+	_, err := systemd.Notify(state)
+	if err != nil {
+		log.Warnf("%s", err)
+	}
+}
+
 // dbPath returns the path to the block database given a database type.
 func databasePath(cfg *config.Config) string {
 	return filepath.Join(cfg.AppDir, defaultDataDirname)
@@ -168,19 +180,48 @@ func databasePath(cfg *config.Config) string {
 
 func removeDatabase(cfg *config.Config) error {
 	dbPath := databasePath(cfg)
+	err := os.RemoveAll(dbPath + migrationDirSuffix)
+	if err != nil {
+		return err
+	}
 	return os.RemoveAll(dbPath)
 }
 
 func openDB(cfg *config.Config) (database.Database, error) {
+	err := validateDatabaseType(cfg.DbType)
+	if err != nil {
+		return nil, err
+	}
+
 	dbPath := databasePath(cfg)
+	err = finishInterruptedDatabaseMigration(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dbType, err := databaseType(cfg, dbPath)
+	if err != nil {
+		return nil, err
+	}
 
-	err := checkDatabaseVersion(dbPath)
+	err = checkDatabaseVersion(dbPath)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Infof("Loading database from '%s'", dbPath)
-	db, err := ldb.NewLevelDB(dbPath, leveldbCacheSizeMiB)
+	if dbType != cfg.DbType {
+		if !cfg.MigrateDbType {
+			return nil, errors.Errorf("The database at '%s' is a %s database, but --dbtype is %s. "+
+				"Use --migrate-dbtype to convert it", dbPath, dbType, cfg.DbType)
+		}
+		err = migrateDatabase(dbPath, dbType, cfg.DbType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.Infof("Loading %s database from '%s'", cfg.DbType, dbPath)
+	db, err := database.Open(cfg.DbType, dbPath, databaseCacheSizeMiB)
 	if err != nil {
 		return nil, err
 	}
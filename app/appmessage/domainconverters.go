@@ -344,36 +344,40 @@ func DomainOutpointAndUTXOEntryPairsToOutpointAndUTXOEntryPairs(
 
 // DomainBlockToRPCBlock converts DomainBlocks to RPCBlocks
 func DomainBlockToRPCBlock(block *externalapi.DomainBlock) *RPCBlock {
-	parents := make([]*RPCBlockLevelParents, len(block.Header.Parents()))
-	for i, blockLevelParents := range block.Header.Parents() {
-		parents[i] = &RPCBlockLevelParents{
-			ParentHashes: hashes.ToStrings(blockLevelParents),
-		}
-	}
-	header := &RPCBlockHeader{
-		Version:              uint32(block.Header.Version()),
-		Parents:              parents,
-		HashMerkleRoot:       block.Header.HashMerkleRoot().String(),
-		AcceptedIDMerkleRoot: block.Header.AcceptedIDMerkleRoot().String(),
-		UTXOCommitment:       block.Header.UTXOCommitment().String(),
-		Timestamp:            block.Header.TimeInMilliseconds(),
-		Bits:                 block.Header.Bits(),
-		Nonce:                block.Header.Nonce(),
-		DAAScore:             block.Header.DAAScore(),
-		BlueScore:            block.Header.BlueScore(),
-		BlueWork:             block.Header.BlueWork().Text(16),
-		PruningPoint:         block.Header.PruningPoint().String(),
-	}
 	transactions := make([]*RPCTransaction, len(block.Transactions))
 	for i, transaction := range block.Transactions {
 		transactions[i] = DomainTransactionToRPCTransaction(transaction)
 	}
 	return &RPCBlock{
-		Header:       header,
+		Header:       DomainBlockHeaderToRPCBlockHeader(block.Header),
 		Transactions: transactions,
 	}
 }
 
+// DomainBlockHeaderToRPCBlockHeader converts `header` into a RPCBlockHeader
+func DomainBlockHeaderToRPCBlockHeader(header externalapi.BlockHeader) *RPCBlockHeader {
+	parents := make([]*RPCBlockLevelParents, len(header.Parents()))
+	for i, blockLevelParents := range header.Parents() {
+		parents[i] = &RPCBlockLevelParents{
+			ParentHashes: hashes.ToStrings(blockLevelParents),
+		}
+	}
+	return &RPCBlockHeader{
+		Version:              uint32(header.Version()),
+		Parents:              parents,
+		HashMerkleRoot:       header.HashMerkleRoot().String(),
+		AcceptedIDMerkleRoot: header.AcceptedIDMerkleRoot().String(),
+		UTXOCommitment:       header.UTXOCommitment().String(),
+		Timestamp:            header.TimeInMilliseconds(),
+		Bits:                 header.Bits(),
+		Nonce:                header.Nonce(),
+		DAAScore:             header.DAAScore(),
+		BlueScore:            header.BlueScore(),
+		BlueWork:             header.BlueWork().Text(16),
+		PruningPoint:         header.PruningPoint().String(),
+	}
+}
+
 // RPCBlockToDomainBlock converts `block` into a DomainBlock
 func RPCBlockToDomainBlock(block *RPCBlock) (*externalapi.DomainBlock, error) {
 	parents := make([]externalapi.BlockLevelParents, len(block.Header.Parents))
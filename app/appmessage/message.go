@@ -73,6 +73,25 @@ const (
 	CmdRequestIBDChainBlockLocator
 	CmdIBDChainBlockLocator
 	CmdRequestAnticone
+	CmdStemTransaction
+	CmdFilterLoad
+	CmdFilterAdd
+	CmdFilterClear
+	CmdRequestMerkleBlocks
+	CmdMerkleBlock
+	CmdGetCFilters
+	CmdCFilter
+	CmdGetCFHeaders
+	CmdCFHeaders
+	CmdGetCFCheckpt
+	CmdCFCheckpt
+	CmdRequestAntichainLocator
+	CmdAntichainLocator
+	CmdInvPackage
+	CmdRequestPackage
+	CmdPackage
+	CmdRequestUTXOExistence
+	CmdUTXOExistence
 
 	// rpc
 	CmdGetCurrentNetworkRequestMessage
@@ -167,6 +186,62 @@ const (
 	CmdGetFeeEstimateResponseMessage
 	CmdSubmitTransactionReplacementRequestMessage
 	CmdSubmitTransactionReplacementResponseMessage
+	CmdGetUTXOSetInfoRequestMessage
+	CmdGetUTXOSetInfoResponseMessage
+	CmdNotifyDAGTipChangedRequestMessage
+	CmdNotifyDAGTipChangedResponseMessage
+	CmdDAGTipChangedNotificationMessage
+	CmdGetRawTransactionRequestMessage
+	CmdGetRawTransactionResponseMessage
+	CmdGetNetworkInfoRequestMessage
+	CmdGetNetworkInfoResponseMessage
+	CmdCompactDbRequestMessage
+	CmdCompactDbResponseMessage
+	CmdGetDbInfoRequestMessage
+	CmdGetDbInfoResponseMessage
+	CmdBackupDagStateRequestMessage
+	CmdBackupDagStateResponseMessage
+	CmdGetBlockDAGRelationsRequestMessage
+	CmdGetBlockDAGRelationsResponseMessage
+	CmdGetFinalityPointRequestMessage
+	CmdGetFinalityPointResponseMessage
+	CmdGetSyncStatusRequestMessage
+	CmdGetSyncStatusResponseMessage
+	CmdGetMempoolInfoRequestMessage
+	CmdGetMempoolInfoResponseMessage
+	CmdTestMempoolAcceptRequestMessage
+	CmdTestMempoolAcceptResponseMessage
+	CmdSubmitPackageRequestMessage
+	CmdSubmitPackageResponseMessage
+	CmdGetTxOutProofRequestMessage
+	CmdGetTxOutProofResponseMessage
+	CmdVerifyTxOutProofRequestMessage
+	CmdVerifyTxOutProofResponseMessage
+	CmdAddNodeRequestMessage
+	CmdAddNodeResponseMessage
+	CmdDisconnectNodeRequestMessage
+	CmdDisconnectNodeResponseMessage
+	CmdGetAddedNodeInfoRequestMessage
+	CmdGetAddedNodeInfoResponseMessage
+	CmdInvalidateBlockRequestMessage
+	CmdInvalidateBlockResponseMessage
+	CmdReconsiderBlockRequestMessage
+	CmdReconsiderBlockResponseMessage
+	CmdGetBlockStatsRequestMessage
+	CmdGetBlockStatsResponseMessage
+	CmdGetProcessingStatsRequestMessage
+	CmdGetProcessingStatsResponseMessage
+	CmdDebugLevelRequestMessage
+	CmdDebugLevelResponseMessage
+	CmdStartProfileRequestMessage
+	CmdStartProfileResponseMessage
+	CmdStopProfileRequestMessage
+	CmdStopProfileResponseMessage
+	CmdNotifyBlockHeaderAddedRequestMessage
+	CmdNotifyBlockHeaderAddedResponseMessage
+	CmdBlockHeaderAddedNotificationMessage
+	CmdGetNetTotalsRequestMessage
+	CmdGetNetTotalsResponseMessage
 )
 
 // ProtocolMessageCommandToString maps all MessageCommands to their string representation
@@ -214,6 +289,25 @@ var ProtocolMessageCommandToString = map[MessageCommand]string{
 	CmdRequestIBDChainBlockLocator:                 "RequestIBDChainBlockLocator",
 	CmdIBDChainBlockLocator:                        "IBDChainBlockLocator",
 	CmdRequestAnticone:                             "RequestAnticone",
+	CmdStemTransaction:                             "StemTransaction",
+	CmdFilterLoad:                                  "FilterLoad",
+	CmdFilterAdd:                                   "FilterAdd",
+	CmdFilterClear:                                 "FilterClear",
+	CmdRequestMerkleBlocks:                         "RequestMerkleBlocks",
+	CmdMerkleBlock:                                 "MerkleBlock",
+	CmdGetCFilters:                                 "GetCFilters",
+	CmdCFilter:                                     "CFilter",
+	CmdGetCFHeaders:                                "GetCFHeaders",
+	CmdCFHeaders:                                   "CFHeaders",
+	CmdGetCFCheckpt:                                "GetCFCheckpt",
+	CmdCFCheckpt:                                   "CFCheckpt",
+	CmdRequestAntichainLocator:                     "RequestAntichainLocator",
+	CmdAntichainLocator:                            "AntichainLocator",
+	CmdInvPackage:                                  "InvPackage",
+	CmdRequestPackage:                              "RequestPackage",
+	CmdPackage:                                     "Package",
+	CmdRequestUTXOExistence:                        "RequestUTXOExistence",
+	CmdUTXOExistence:                               "UTXOExistence",
 }
 
 // RPCMessageCommandToString maps all MessageCommands to their string representation
@@ -308,6 +402,62 @@ var RPCMessageCommandToString = map[MessageCommand]string{
 	CmdGetFeeEstimateResponseMessage:                              "GetFeeEstimateResponse",
 	CmdSubmitTransactionReplacementRequestMessage:                 "SubmitTransactionReplacementRequest",
 	CmdSubmitTransactionReplacementResponseMessage:                "SubmitTransactionReplacementResponse",
+	CmdGetUTXOSetInfoRequestMessage:                               "GetUTXOSetInfoRequest",
+	CmdGetUTXOSetInfoResponseMessage:                              "GetUTXOSetInfoResponse",
+	CmdNotifyDAGTipChangedRequestMessage:                          "NotifyDAGTipChangedRequest",
+	CmdNotifyDAGTipChangedResponseMessage:                         "NotifyDAGTipChangedResponse",
+	CmdDAGTipChangedNotificationMessage:                           "DAGTipChangedNotification",
+	CmdGetRawTransactionRequestMessage:                            "GetRawTransactionRequest",
+	CmdGetRawTransactionResponseMessage:                           "GetRawTransactionResponse",
+	CmdGetNetworkInfoRequestMessage:                               "GetNetworkInfoRequest",
+	CmdGetNetworkInfoResponseMessage:                              "GetNetworkInfoResponse",
+	CmdCompactDbRequestMessage:                                    "CompactDbRequest",
+	CmdCompactDbResponseMessage:                                   "CompactDbResponse",
+	CmdGetDbInfoRequestMessage:                                    "GetDbInfoRequest",
+	CmdGetDbInfoResponseMessage:                                   "GetDbInfoResponse",
+	CmdBackupDagStateRequestMessage:                               "BackupDagStateRequest",
+	CmdBackupDagStateResponseMessage:                              "BackupDagStateResponse",
+	CmdGetBlockDAGRelationsRequestMessage:                         "GetBlockDAGRelationsRequest",
+	CmdGetBlockDAGRelationsResponseMessage:                        "GetBlockDAGRelationsResponse",
+	CmdGetFinalityPointRequestMessage:                             "GetFinalityPointRequest",
+	CmdGetFinalityPointResponseMessage:                            "GetFinalityPointResponse",
+	CmdGetSyncStatusRequestMessage:                                "GetSyncStatusRequest",
+	CmdGetSyncStatusResponseMessage:                               "GetSyncStatusResponse",
+	CmdGetMempoolInfoRequestMessage:                               "GetMempoolInfoRequest",
+	CmdGetMempoolInfoResponseMessage:                              "GetMempoolInfoResponse",
+	CmdTestMempoolAcceptRequestMessage:                            "TestMempoolAcceptRequest",
+	CmdTestMempoolAcceptResponseMessage:                           "TestMempoolAcceptResponse",
+	CmdSubmitPackageRequestMessage:                                "SubmitPackageRequest",
+	CmdSubmitPackageResponseMessage:                               "SubmitPackageResponse",
+	CmdGetTxOutProofRequestMessage:                                "GetTxOutProofRequest",
+	CmdGetTxOutProofResponseMessage:                               "GetTxOutProofResponse",
+	CmdVerifyTxOutProofRequestMessage:                             "VerifyTxOutProofRequest",
+	CmdVerifyTxOutProofResponseMessage:                            "VerifyTxOutProofResponse",
+	CmdAddNodeRequestMessage:                                      "AddNodeRequest",
+	CmdAddNodeResponseMessage:                                     "AddNodeResponse",
+	CmdDisconnectNodeRequestMessage:                               "DisconnectNodeRequest",
+	CmdDisconnectNodeResponseMessage:                              "DisconnectNodeResponse",
+	CmdGetAddedNodeInfoRequestMessage:                             "GetAddedNodeInfoRequest",
+	CmdGetAddedNodeInfoResponseMessage:                            "GetAddedNodeInfoResponse",
+	CmdInvalidateBlockRequestMessage:                              "InvalidateBlockRequest",
+	CmdInvalidateBlockResponseMessage:                             "InvalidateBlockResponse",
+	CmdReconsiderBlockRequestMessage:                              "ReconsiderBlockRequest",
+	CmdReconsiderBlockResponseMessage:                             "ReconsiderBlockResponse",
+	CmdGetBlockStatsRequestMessage:                                "GetBlockStatsRequest",
+	CmdGetBlockStatsResponseMessage:                               "GetBlockStatsResponse",
+	CmdGetProcessingStatsRequestMessage:                           "GetProcessingStatsRequest",
+	CmdGetProcessingStatsResponseMessage:                          "GetProcessingStatsResponse",
+	CmdDebugLevelRequestMessage:                                   "DebugLevelRequest",
+	CmdDebugLevelResponseMessage:                                  "DebugLevelResponse",
+	CmdStartProfileRequestMessage:                                 "StartProfileRequest",
+	CmdStartProfileResponseMessage:                                "StartProfileResponse",
+	CmdStopProfileRequestMessage:                                  "StopProfileRequest",
+	CmdStopProfileResponseMessage:                                 "StopProfileResponse",
+	CmdNotifyBlockHeaderAddedRequestMessage:                       "NotifyBlockHeaderAddedRequest",
+	CmdNotifyBlockHeaderAddedResponseMessage:                      "NotifyBlockHeaderAddedResponse",
+	CmdBlockHeaderAddedNotificationMessage:                        "BlockHeaderAddedNotification",
+	CmdGetNetTotalsRequestMessage:                                 "GetNetTotalsRequest",
+	CmdGetNetTotalsResponseMessage:                                "GetNetTotalsResponse",
 }
 
 // Message is an interface that describes a kaspa message. A type that
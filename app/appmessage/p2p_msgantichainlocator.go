@@ -0,0 +1,42 @@
+package appmessage
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// MaxAntichainLocatorTipsPerMsg is the maximum number of tips allowed per
+// antichain locator message.
+const MaxAntichainLocatorTipsPerMsg = 100
+
+// MsgAntichainLocator implements the Message interface and represents a kaspa
+// AntichainLocator message. It holds a locator of the selected parent chain of
+// the peer along with the tips of its DAG. See externalapi.AntichainLocator for
+// details.
+type MsgAntichainLocator struct {
+	baseMessage
+	ChainLocatorHashes []*externalapi.DomainHash
+	TipHashes          []*externalapi.DomainHash
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgAntichainLocator) Command() MessageCommand {
+	return CmdAntichainLocator
+}
+
+// NewMsgAntichainLocator returns a new kaspa AntichainLocator message that conforms to
+// the Message interface. See MsgAntichainLocator for details.
+func NewMsgAntichainLocator(locator *externalapi.AntichainLocator) *MsgAntichainLocator {
+	return &MsgAntichainLocator{
+		ChainLocatorHashes: locator.ChainLocator,
+		TipHashes:          locator.Tips,
+	}
+}
+
+// AntichainLocator returns the antichain locator that the message holds
+func (msg *MsgAntichainLocator) AntichainLocator() *externalapi.AntichainLocator {
+	return &externalapi.AntichainLocator{
+		ChainLocator: msg.ChainLocatorHashes,
+		Tips:         msg.TipHashes,
+	}
+}
@@ -0,0 +1,44 @@
+package appmessage
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+const (
+	// CFCheckptInterval is the gap (in number of selected chain blocks)
+	// between each filter header checkpoint.
+	CFCheckptInterval = 1000
+
+	// MaxCFCheckptHeaders is the maximum number of filter headers in a
+	// CFCheckpt message.
+	MaxCFCheckptHeaders = 100000
+)
+
+// MsgCFCheckpt implements the Message interface and represents a kaspa
+// CFCheckpt message. It is used to deliver the filter headers of every
+// CFCheckptInterval-th block on the selected chain from the pruning point
+// up to StopHash, in response to a GetCFCheckpt message.
+type MsgCFCheckpt struct {
+	baseMessage
+	FilterType    FilterType
+	StopHash      *externalapi.DomainHash
+	FilterHeaders []*externalapi.DomainHash
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgCFCheckpt) Command() MessageCommand {
+	return CmdCFCheckpt
+}
+
+// NewMsgCFCheckpt returns a new kaspa CFCheckpt message that conforms to
+// the Message interface. See MsgCFCheckpt for details.
+func NewMsgCFCheckpt(filterType FilterType, stopHash *externalapi.DomainHash,
+	filterHeaders []*externalapi.DomainHash) *MsgCFCheckpt {
+
+	return &MsgCFCheckpt{
+		FilterType:    filterType,
+		StopHash:      stopHash,
+		FilterHeaders: filterHeaders,
+	}
+}
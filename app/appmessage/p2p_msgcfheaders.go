@@ -0,0 +1,41 @@
+package appmessage
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// MsgCFHeaders implements the Message interface and represents a kaspa
+// CFHeaders message. It is used to deliver the committed filter hashes and
+// headers of the blocks in the range requested by a GetCFHeaders message.
+//
+// The header of a filter commits to the filter and to the header of the
+// filter of the selected parent of its block. StopHash is the last block
+// of the range, which may be below the requested HighHash.
+type MsgCFHeaders struct {
+	baseMessage
+	FilterType    FilterType
+	StopHash      *externalapi.DomainHash
+	BlockHashes   []*externalapi.DomainHash
+	FilterHashes  []*externalapi.DomainHash
+	FilterHeaders []*externalapi.DomainHash
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgCFHeaders) Command() MessageCommand {
+	return CmdCFHeaders
+}
+
+// NewMsgCFHeaders returns a new kaspa CFHeaders message that conforms to
+// the Message interface. See MsgCFHeaders for details.
+func NewMsgCFHeaders(filterType FilterType, stopHash *externalapi.DomainHash, blockHashes []*externalapi.DomainHash,
+	filterHashes []*externalapi.DomainHash, filterHeaders []*externalapi.DomainHash) *MsgCFHeaders {
+
+	return &MsgCFHeaders{
+		FilterType:    filterType,
+		StopHash:      stopHash,
+		BlockHashes:   blockHashes,
+		FilterHashes:  filterHashes,
+		FilterHeaders: filterHeaders,
+	}
+}
@@ -0,0 +1,43 @@
+package appmessage
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// FilterType is used to represent a filter type.
+type FilterType uint8
+
+const (
+	// GCSFilterRegular is the regular filter type.
+	GCSFilterRegular FilterType = iota
+)
+
+// MaxCFilterDataSize is the maximum byte size of a committed filter.
+// The maximum size is currently defined as 256KiB.
+const MaxCFilterDataSize = 256 * 1024
+
+// MsgCFilter implements the Message interface and represents a kaspa
+// CFilter message. It is used to deliver a committed filter in response
+// to a GetCFilters message.
+type MsgCFilter struct {
+	baseMessage
+	FilterType FilterType
+	BlockHash  *externalapi.DomainHash
+	Data       []byte
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgCFilter) Command() MessageCommand {
+	return CmdCFilter
+}
+
+// NewMsgCFilter returns a new kaspa CFilter message that conforms to the
+// Message interface. See MsgCFilter for details.
+func NewMsgCFilter(filterType FilterType, blockHash *externalapi.DomainHash, data []byte) *MsgCFilter {
+	return &MsgCFilter{
+		FilterType: filterType,
+		BlockHash:  blockHash,
+		Data:       data,
+	}
+}
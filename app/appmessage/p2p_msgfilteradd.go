@@ -0,0 +1,27 @@
+package appmessage
+
+// MaxFilterAddDataSize is the maximum byte size of a data
+// element to add to the Bloom filter.
+const MaxFilterAddDataSize = 520
+
+// MsgFilterAdd implements the Message interface and represents a kaspa
+// FilterAdd message. It is used to add a data element to an existing
+// bloom filter.
+type MsgFilterAdd struct {
+	baseMessage
+	Data []byte
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgFilterAdd) Command() MessageCommand {
+	return CmdFilterAdd
+}
+
+// NewMsgFilterAdd returns a new kaspa FilterAdd message that conforms to
+// the Message interface. See MsgFilterAdd for details.
+func NewMsgFilterAdd(data []byte) *MsgFilterAdd {
+	return &MsgFilterAdd{
+		Data: data,
+	}
+}
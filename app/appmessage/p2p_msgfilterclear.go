@@ -0,0 +1,19 @@
+package appmessage
+
+// MsgFilterClear implements the Message interface and represents a kaspa
+// FilterClear message. It is used to reset a bloom filter.
+type MsgFilterClear struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgFilterClear) Command() MessageCommand {
+	return CmdFilterClear
+}
+
+// NewMsgFilterClear returns a new kaspa FilterClear message that conforms to
+// the Message interface. See MsgFilterClear for details.
+func NewMsgFilterClear() *MsgFilterClear {
+	return &MsgFilterClear{}
+}
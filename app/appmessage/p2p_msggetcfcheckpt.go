@@ -0,0 +1,29 @@
+package appmessage
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// MsgGetCFCheckpt implements the Message interface and represents a kaspa
+// GetCFCheckpt message. It is used to request the committed filter headers
+// of evenly spaced blocks on the selected chain of StopHash.
+type MsgGetCFCheckpt struct {
+	baseMessage
+	FilterType FilterType
+	StopHash   *externalapi.DomainHash
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgGetCFCheckpt) Command() MessageCommand {
+	return CmdGetCFCheckpt
+}
+
+// NewMsgGetCFCheckpt returns a new kaspa GetCFCheckpt message that conforms to
+// the Message interface. See MsgGetCFCheckpt for details.
+func NewMsgGetCFCheckpt(filterType FilterType, stopHash *externalapi.DomainHash) *MsgGetCFCheckpt {
+	return &MsgGetCFCheckpt{
+		FilterType: filterType,
+		StopHash:   stopHash,
+	}
+}
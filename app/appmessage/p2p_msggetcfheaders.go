@@ -0,0 +1,31 @@
+package appmessage
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// MsgGetCFHeaders implements the Message interface and represents a kaspa
+// GetCFHeaders message. It is used to request the committed filter headers
+// of the blocks between LowHash and HighHash.
+type MsgGetCFHeaders struct {
+	baseMessage
+	FilterType FilterType
+	LowHash    *externalapi.DomainHash
+	HighHash   *externalapi.DomainHash
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgGetCFHeaders) Command() MessageCommand {
+	return CmdGetCFHeaders
+}
+
+// NewMsgGetCFHeaders returns a new kaspa GetCFHeaders message that conforms to
+// the Message interface. See MsgGetCFHeaders for details.
+func NewMsgGetCFHeaders(filterType FilterType, lowHash, highHash *externalapi.DomainHash) *MsgGetCFHeaders {
+	return &MsgGetCFHeaders{
+		FilterType: filterType,
+		LowHash:    lowHash,
+		HighHash:   highHash,
+	}
+}
@@ -0,0 +1,38 @@
+package appmessage
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// MaxGetCFiltersReqRange the maximum number of filters that may be requested
+// in a GetCFilters message, as well as the maximum number of filter headers in
+// a CFHeaders message.
+const MaxGetCFiltersReqRange = 1000
+
+// MsgGetCFilters implements the Message interface and represents a kaspa
+// GetCFilters message. It is used to request the committed filters of the
+// blocks between LowHash and HighHash. Every block is answered with a CFilter
+// message, in the order of the BlockHashes of the CFHeaders message that
+// answers a GetCFHeaders message of the same range.
+type MsgGetCFilters struct {
+	baseMessage
+	FilterType FilterType
+	LowHash    *externalapi.DomainHash
+	HighHash   *externalapi.DomainHash
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgGetCFilters) Command() MessageCommand {
+	return CmdGetCFilters
+}
+
+// NewMsgGetCFilters returns a new kaspa GetCFilters message that conforms to
+// the Message interface. See MsgGetCFilters for details.
+func NewMsgGetCFilters(filterType FilterType, lowHash, highHash *externalapi.DomainHash) *MsgGetCFilters {
+	return &MsgGetCFilters{
+		FilterType: filterType,
+		LowHash:    lowHash,
+		HighHash:   highHash,
+	}
+}
@@ -0,0 +1,33 @@
+package appmessage
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// MaxInvPerPackageInvMsg is the maximum number of IDs that can
+// be in a single CmdInvPackage message.
+const MaxInvPerPackageInvMsg = MaxInvPerMsg
+
+// MsgInvPackage implements the Message interface and represents a kaspa
+// InvPackage message. It is used to notify the network about new packages of
+// transactions by sending the IDs of their last transactions. A package is a
+// transaction along with its unconfirmed ancestors, which pay the minimum fee
+// together rather than one by one.
+type MsgInvPackage struct {
+	baseMessage
+	IDs []*externalapi.DomainTransactionID
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgInvPackage) Command() MessageCommand {
+	return CmdInvPackage
+}
+
+// NewMsgInvPackage returns a new kaspa InvPackage message that conforms to
+// the Message interface. See MsgInvPackage for details.
+func NewMsgInvPackage(ids []*externalapi.DomainTransactionID) *MsgInvPackage {
+	return &MsgInvPackage{
+		IDs: ids,
+	}
+}
@@ -0,0 +1,44 @@
+package appmessage
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// MsgMerkleBlock implements the Message interface and represents a kaspa
+// MerkleBlock message. It holds the header of a block along with a partial
+// merkle tree proving which of its transactions matched the bloom filter of
+// the requesting peer.
+//
+// Hashes and Flags encode the partial merkle tree the same way as in BIP37,
+// except that a missing right node is hashed as the zero hash rather than as
+// a copy of its left sibling, like in the hash merkle root of the header.
+// Unlike in BIP37, the matched transactions are sent as part of the message
+// rather than as separate transaction messages.
+type MsgMerkleBlock struct {
+	baseMessage
+	Header           *MsgBlockHeader
+	TransactionCount uint32
+	Hashes           []*externalapi.DomainHash
+	Flags            []byte
+	Transactions     []*MsgTx
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgMerkleBlock) Command() MessageCommand {
+	return CmdMerkleBlock
+}
+
+// NewMsgMerkleBlock returns a new kaspa MerkleBlock message that conforms to
+// the Message interface. See MsgMerkleBlock for details.
+func NewMsgMerkleBlock(header *MsgBlockHeader, transactionCount uint32, hashes []*externalapi.DomainHash,
+	flags []byte, transactions []*MsgTx) *MsgMerkleBlock {
+
+	return &MsgMerkleBlock{
+		Header:           header,
+		TransactionCount: transactionCount,
+		Hashes:           hashes,
+		Flags:            flags,
+		Transactions:     transactions,
+	}
+}
@@ -0,0 +1,31 @@
+package appmessage
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// MsgPackage implements the Message interface and represents a kaspa
+// Package message. It is sent in response to a RequestPackage message, and holds
+// the transactions of the package of the requested transaction, ordered so that
+// every transaction comes after its parents. It has no transactions if the
+// package is no longer available on the peer.
+type MsgPackage struct {
+	baseMessage
+	ID           *externalapi.DomainTransactionID
+	Transactions []*MsgTx
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgPackage) Command() MessageCommand {
+	return CmdPackage
+}
+
+// NewMsgPackage returns a new kaspa Package message that conforms to
+// the Message interface. See MsgPackage for details.
+func NewMsgPackage(id *externalapi.DomainTransactionID, transactions []*MsgTx) *MsgPackage {
+	return &MsgPackage{
+		ID:           id,
+		Transactions: transactions,
+	}
+}
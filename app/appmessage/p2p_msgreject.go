@@ -1,10 +1,51 @@
 package appmessage
 
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// RejectCode describes why a peer refused a block, a transaction or the connection itself
+type RejectCode byte
+
+// RejectCode constants
+// Not using iota, since in the .proto file those are hardcoded
+const (
+	RejectCodeUnspecified        RejectCode = 0
+	RejectCodeProtocolViolation  RejectCode = 1
+	RejectCodeInvalidBlock       RejectCode = 2
+	RejectCodeInvalidTransaction RejectCode = 3
+	RejectCodeMissingParents     RejectCode = 4
+	RejectCodeTooManyParents     RejectCode = 5
+	RejectCodeFinalityViolation  RejectCode = 6
+)
+
+var rejectCodeToString = map[RejectCode]string{
+	RejectCodeUnspecified:        "Unspecified",
+	RejectCodeProtocolViolation:  "ProtocolViolation",
+	RejectCodeInvalidBlock:       "InvalidBlock",
+	RejectCodeInvalidTransaction: "InvalidTransaction",
+	RejectCodeMissingParents:     "MissingParents",
+	RejectCodeTooManyParents:     "TooManyParents",
+	RejectCodeFinalityViolation:  "FinalityViolation",
+}
+
+func (code RejectCode) String() string {
+	if codeString, ok := rejectCodeToString[code]; ok {
+		return codeString
+	}
+	return "Unknown"
+}
+
 // MsgReject implements the Message interface and represents a kaspa
 // Reject message. It is used to notify peers why they are banned.
 type MsgReject struct {
 	baseMessage
 	Reason string
+	Code   RejectCode
+
+	// RejectedHash is the hash of the rejected block or the ID of the
+	// rejected transaction. It is nil if the rejection isn't about either.
+	RejectedHash *externalapi.DomainHash
 }
 
 // Command returns the protocol command string for the message. This is part
@@ -15,8 +56,10 @@ func (msg *MsgReject) Command() MessageCommand {
 
 // NewMsgReject returns a new kaspa Reject message that conforms to the
 // Message interface.
-func NewMsgReject(reason string) *MsgReject {
+func NewMsgReject(code RejectCode, rejectedHash *externalapi.DomainHash, reason string) *MsgReject {
 	return &MsgReject{
-		Reason: reason,
+		Reason:       reason,
+		Code:         code,
+		RejectedHash: rejectedHash,
 	}
 }
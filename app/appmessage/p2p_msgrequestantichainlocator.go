@@ -0,0 +1,21 @@
+package appmessage
+
+// MsgRequestAntichainLocator implements the Message interface and represents a kaspa
+// RequestAntichainLocator message. It is used to request the antichain locator of a
+// peer, in order to find which of its blocks are missing.
+// The locator is returned via an antichain locator message (MsgAntichainLocator).
+type MsgRequestAntichainLocator struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgRequestAntichainLocator) Command() MessageCommand {
+	return CmdRequestAntichainLocator
+}
+
+// NewMsgRequestAntichainLocator returns a new RequestAntichainLocator message that
+// conforms to the Message interface.
+func NewMsgRequestAntichainLocator() *MsgRequestAntichainLocator {
+	return &MsgRequestAntichainLocator{}
+}
@@ -0,0 +1,32 @@
+package appmessage
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// MaxRequestMerkleBlocksHashes is the maximum number of hashes that can
+// be in a single RequestMerkleBlocks message.
+const MaxRequestMerkleBlocksHashes = MaxInvPerMsg
+
+// MsgRequestMerkleBlocks implements the Message interface and represents a kaspa
+// RequestMerkleBlocks message. It is used by SPV clients that loaded a bloom
+// filter to request blocks filtered by it. Every requested block is answered
+// with a MerkleBlock message.
+type MsgRequestMerkleBlocks struct {
+	baseMessage
+	Hashes []*externalapi.DomainHash
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgRequestMerkleBlocks) Command() MessageCommand {
+	return CmdRequestMerkleBlocks
+}
+
+// NewMsgRequestMerkleBlocks returns a new kaspa RequestMerkleBlocks message that conforms to
+// the Message interface. See MsgRequestMerkleBlocks for details.
+func NewMsgRequestMerkleBlocks(hashes []*externalapi.DomainHash) *MsgRequestMerkleBlocks {
+	return &MsgRequestMerkleBlocks{
+		Hashes: hashes,
+	}
+}
@@ -0,0 +1,27 @@
+package appmessage
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// MsgRequestPackage implements the Message interface and represents a kaspa
+// RequestPackage message. It is used to request the package of a transaction
+// that was announced with an InvPackage message, which is sent in a Package message.
+type MsgRequestPackage struct {
+	baseMessage
+	ID *externalapi.DomainTransactionID
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgRequestPackage) Command() MessageCommand {
+	return CmdRequestPackage
+}
+
+// NewMsgRequestPackage returns a new kaspa RequestPackage message that conforms to
+// the Message interface. See MsgRequestPackage for details.
+func NewMsgRequestPackage(id *externalapi.DomainTransactionID) *MsgRequestPackage {
+	return &MsgRequestPackage{
+		ID: id,
+	}
+}
@@ -0,0 +1,37 @@
+package appmessage
+
+// MaxUTXOExistenceOutpoints is the maximum number of outpoints that may be
+// checked in a single RequestUTXOExistence message
+const MaxUTXOExistenceOutpoints = 1 << 10
+
+// MsgRequestUTXOExistence implements the Message interface and represents a kaspa
+// RequestUTXOExistence message. It is used by light clients to check which of the
+// given outpoints are in the virtual UTXO set of the peer, which answers with a
+// UTXOExistence message. It is only sent to peers that advertise the SFNodeGetUTXO
+// service.
+//
+// With IncludeEntries, the response holds the UTXO entries of the outpoints that
+// exist, and with IncludeProofs, proofs that the transactions that created them
+// are in the blocks of the given headers.
+type MsgRequestUTXOExistence struct {
+	baseMessage
+	Outpoints      []*Outpoint
+	IncludeEntries bool
+	IncludeProofs  bool
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgRequestUTXOExistence) Command() MessageCommand {
+	return CmdRequestUTXOExistence
+}
+
+// NewMsgRequestUTXOExistence returns a new kaspa RequestUTXOExistence message that
+// conforms to the Message interface. See MsgRequestUTXOExistence for details.
+func NewMsgRequestUTXOExistence(outpoints []*Outpoint, includeEntries bool, includeProofs bool) *MsgRequestUTXOExistence {
+	return &MsgRequestUTXOExistence{
+		Outpoints:      outpoints,
+		IncludeEntries: includeEntries,
+		IncludeProofs:  includeProofs,
+	}
+}
@@ -0,0 +1,24 @@
+package appmessage
+
+// MsgStemTransaction implements the Message interface and represents a kaspa
+// StemTransaction message. It is used to relay a transaction along the stem
+// phase of Dandelion propagation, before it's announced to the whole network
+type MsgStemTransaction struct {
+	baseMessage
+	Transaction *MsgTx
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgStemTransaction) Command() MessageCommand {
+	return CmdStemTransaction
+}
+
+// NewMsgStemTransaction returns a new kaspa StemTransaction message that conforms to the
+// Message interface using the passed parameters and defaults for the remaining
+// fields.
+func NewMsgStemTransaction(tx *MsgTx) *MsgStemTransaction {
+	return &MsgStemTransaction{
+		Transaction: tx,
+	}
+}
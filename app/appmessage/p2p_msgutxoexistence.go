@@ -0,0 +1,67 @@
+package appmessage
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// MsgUTXOExistence implements the Message interface and represents a kaspa
+// UTXOExistence message. It is sent in response to a RequestUTXOExistence message.
+//
+// Bit i of ExistenceBits, counting from the least significant bit of the first
+// byte, is set if the i-th requested outpoint is in the virtual UTXO set of the
+// sender, whose selected parent is VirtualSelectedParent. Entries and Proofs
+// hold an entry and a proof for every outpoint that exists, in the order of the
+// request, if they were requested. Proofs is empty if the sender doesn't index
+// transactions, and skips the outpoints whose transactions are in blocks whose
+// bodies were pruned.
+type MsgUTXOExistence struct {
+	baseMessage
+	VirtualSelectedParent *externalapi.DomainHash
+	ExistenceBits         []byte
+	Entries               []*UTXOEntry
+	Proofs                []*UTXOExistenceProof
+}
+
+// UTXOExistenceProof proves that the block of Header contains Transaction, which
+// created the outpoint at OutpointIndex in the request. TransactionIndex and
+// Siblings are the merkle proof of the transaction against the hash merkle root
+// of the header, in the format of merkle.Proof.
+//
+// The proof only shows that the output was created. That it wasn't spent since
+// is only vouched for by the sender.
+type UTXOExistenceProof struct {
+	OutpointIndex    uint32
+	Header           *MsgBlockHeader
+	Transaction      *MsgTx
+	TransactionIndex uint32
+	Siblings         []*externalapi.DomainHash
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgUTXOExistence) Command() MessageCommand {
+	return CmdUTXOExistence
+}
+
+// Exists returns whether the outpoint at the given index in the request is in
+// the virtual UTXO set of the sender
+func (msg *MsgUTXOExistence) Exists(outpointIndex int) bool {
+	byteIndex := outpointIndex / 8
+	if byteIndex >= len(msg.ExistenceBits) {
+		return false
+	}
+	return msg.ExistenceBits[byteIndex]&(1<<(outpointIndex%8)) != 0
+}
+
+// NewMsgUTXOExistence returns a new kaspa UTXOExistence message that conforms to
+// the Message interface. See MsgUTXOExistence for details.
+func NewMsgUTXOExistence(virtualSelectedParent *externalapi.DomainHash, existenceBits []byte,
+	entries []*UTXOEntry, proofs []*UTXOExistenceProof) *MsgUTXOExistence {
+
+	return &MsgUTXOExistence{
+		VirtualSelectedParent: virtualSelectedParent,
+		ExistenceBits:         existenceBits,
+		Entries:               entries,
+		Proofs:                proofs,
+	}
+}
@@ -6,6 +6,7 @@ package appmessage
 
 import (
 	"net"
+	"strconv"
 
 	"github.com/kaspanet/kaspad/util/mstime"
 )
@@ -16,14 +17,23 @@ type NetAddress struct {
 	// Last time the address was seen.
 	Timestamp mstime.Time
 
-	// IP address of the peer.
+	// IP address of the peer. It's nil for onion addresses.
 	IP net.IP
 
+	// OnionHost is the v3 onion service host of the peer, for peers that are
+	// reachable only through Tor.
+	OnionHost string
+
 	// Port the peer is using. This is encoded in big endian on the appmessage
 	// which differs from most everything else.
 	Port uint16
 }
 
+// IsOnion returns whether the NetAddress is the address of an onion service
+func (na *NetAddress) IsOnion() bool {
+	return na.OnionHost != ""
+}
+
 // TCPAddress converts the NetAddress to *net.TCPAddr
 func (na *NetAddress) TCPAddress() *net.TCPAddr {
 	return &net.TCPAddr{
@@ -53,6 +63,16 @@ func NewNetAddressTimestamp(
 	return &na
 }
 
+// NewNetAddressOnion returns a new NetAddress using the provided onion service host
+// and port.
+func NewNetAddressOnion(onionHost string, port uint16) *NetAddress {
+	return &NetAddress{
+		Timestamp: mstime.Now(),
+		OnionHost: onionHost,
+		Port:      port,
+	}
+}
+
 // NewNetAddress returns a new NetAddress using the provided TCP address and
 // supported services with defaults for the remaining fields.
 func NewNetAddress(addr *net.TCPAddr) *NetAddress {
@@ -60,5 +80,8 @@ func NewNetAddress(addr *net.TCPAddr) *NetAddress {
 }
 
 func (na NetAddress) String() string {
+	if na.IsOnion() {
+		return net.JoinHostPort(na.OnionHost, strconv.Itoa(int(na.Port)))
+	}
 	return na.TCPAddress().String()
 }
@@ -13,7 +13,7 @@ import (
 const (
 	// DefaultServices describes the default services that are supported by
 	// the server.
-	DefaultServices = SFNodeNetwork | SFNodeBloom | SFNodeCF
+	DefaultServices = SFNodeNetwork | SFNodeGetUTXO | SFNodeBloom | SFNodeCF | SFNodeAntichainLocator | SFNodePackageRelay
 )
 
 // ServiceFlag identifies services supported by a kaspa peer.
@@ -23,8 +23,8 @@ const (
 	// SFNodeNetwork is a flag used to indicate a peer is a full node.
 	SFNodeNetwork ServiceFlag = 1 << iota
 
-	// SFNodeGetUTXO is a flag used to indicate a peer supports the
-	// getutxos and utxos commands (BIP0064).
+	// SFNodeGetUTXO is a flag used to indicate a peer answers
+	// RequestUTXOExistence messages.
 	SFNodeGetUTXO
 
 	// SFNodeBloom is a flag used to indicate a peer supports bloom
@@ -41,16 +41,36 @@ const (
 	// SFNodeCF is a flag used to indicate a peer supports committed
 	// filters (CFs).
 	SFNodeCF
+
+	// SFNodeDandelion is a flag used to indicate a peer relays
+	// transactions in the Dandelion stem phase.
+	SFNodeDandelion
+
+	// SFNodeAntichainLocator is a flag used to indicate a peer serves
+	// antichain locators.
+	SFNodeAntichainLocator
+
+	// SFNodePackageRelay is a flag used to indicate a peer relays
+	// packages of transactions that pay their fees together.
+	SFNodePackageRelay
+
+	// SFNodeEncryptedTransport is a flag used to indicate a peer answers
+	// encrypted handshakes, and connects to peers with one.
+	SFNodeEncryptedTransport
 )
 
 // Map of service flags back to their constant names for pretty printing.
 var sfStrings = map[ServiceFlag]string{
-	SFNodeNetwork: "SFNodeNetwork",
-	SFNodeGetUTXO: "SFNodeGetUTXO",
-	SFNodeBloom:   "SFNodeBloom",
-	SFNodeXthin:   "SFNodeXthin",
-	SFNodeBit5:    "SFNodeBit5",
-	SFNodeCF:      "SFNodeCF",
+	SFNodeNetwork:            "SFNodeNetwork",
+	SFNodeGetUTXO:            "SFNodeGetUTXO",
+	SFNodeBloom:              "SFNodeBloom",
+	SFNodeXthin:              "SFNodeXthin",
+	SFNodeBit5:               "SFNodeBit5",
+	SFNodeCF:                 "SFNodeCF",
+	SFNodeDandelion:          "SFNodeDandelion",
+	SFNodeAntichainLocator:   "SFNodeAntichainLocator",
+	SFNodePackageRelay:       "SFNodePackageRelay",
+	SFNodeEncryptedTransport: "SFNodeEncryptedTransport",
 }
 
 // orderedSFStrings is an ordered list of service flags from highest to
@@ -62,6 +82,10 @@ var orderedSFStrings = []ServiceFlag{
 	SFNodeXthin,
 	SFNodeBit5,
 	SFNodeCF,
+	SFNodeDandelion,
+	SFNodeAntichainLocator,
+	SFNodePackageRelay,
+	SFNodeEncryptedTransport,
 }
 
 // String returns the ServiceFlag in human-readable form.
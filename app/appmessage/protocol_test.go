@@ -19,7 +19,11 @@ func TestServiceFlagStringer(t *testing.T) {
 		{SFNodeXthin, "SFNodeXthin"},
 		{SFNodeBit5, "SFNodeBit5"},
 		{SFNodeCF, "SFNodeCF"},
-		{0xffffffff, "SFNodeNetwork|SFNodeGetUTXO|SFNodeBloom|SFNodeXthin|SFNodeBit5|SFNodeCF|0xffffffc0"},
+		{SFNodeDandelion, "SFNodeDandelion"},
+		{SFNodeAntichainLocator, "SFNodeAntichainLocator"},
+		{SFNodePackageRelay, "SFNodePackageRelay"},
+		{SFNodeEncryptedTransport, "SFNodeEncryptedTransport"},
+		{0xffffffff, "SFNodeNetwork|SFNodeGetUTXO|SFNodeBloom|SFNodeXthin|SFNodeBit5|SFNodeCF|SFNodeDandelion|SFNodeAntichainLocator|SFNodePackageRelay|SFNodeEncryptedTransport|0xfffffc00"},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -0,0 +1,51 @@
+package appmessage
+
+// The commands of AddNodeRequestMessage
+const (
+	// AddNodeCommandAdd adds a node that is connected to permanently, also after a restart
+	AddNodeCommandAdd = "add"
+
+	// AddNodeCommandRemove removes an added node and disconnects from it
+	AddNodeCommandRemove = "remove"
+
+	// AddNodeCommandOneTry attempts to connect to a node once, without adding it
+	AddNodeCommandOneTry = "onetry"
+)
+
+// AddNodeRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type AddNodeRequestMessage struct {
+	baseMessage
+	Address     string
+	NodeCommand string
+}
+
+// Command returns the protocol command string for the message
+func (msg *AddNodeRequestMessage) Command() MessageCommand {
+	return CmdAddNodeRequestMessage
+}
+
+// NewAddNodeRequestMessage returns a instance of the message
+func NewAddNodeRequestMessage(address string, nodeCommand string) *AddNodeRequestMessage {
+	return &AddNodeRequestMessage{
+		Address:     address,
+		NodeCommand: nodeCommand,
+	}
+}
+
+// AddNodeResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type AddNodeResponseMessage struct {
+	baseMessage
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *AddNodeResponseMessage) Command() MessageCommand {
+	return CmdAddNodeResponseMessage
+}
+
+// NewAddNodeResponseMessage returns a instance of the message
+func NewAddNodeResponseMessage() *AddNodeResponseMessage {
+	return &AddNodeResponseMessage{}
+}
@@ -0,0 +1,41 @@
+package appmessage
+
+// BackupDagStateRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type BackupDagStateRequestMessage struct {
+	baseMessage
+	Path string
+}
+
+// Command returns the protocol command string for the message
+func (msg *BackupDagStateRequestMessage) Command() MessageCommand {
+	return CmdBackupDagStateRequestMessage
+}
+
+// NewBackupDagStateRequestMessage returns an instance of the message
+func NewBackupDagStateRequestMessage(path string) *BackupDagStateRequestMessage {
+	return &BackupDagStateRequestMessage{
+		Path: path,
+	}
+}
+
+// BackupDagStateResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type BackupDagStateResponseMessage struct {
+	baseMessage
+	BackupPath string
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *BackupDagStateResponseMessage) Command() MessageCommand {
+	return CmdBackupDagStateResponseMessage
+}
+
+// NewBackupDagStateResponseMessage returns an instance of the message
+func NewBackupDagStateResponseMessage(backupPath string) *BackupDagStateResponseMessage {
+	return &BackupDagStateResponseMessage{
+		BackupPath: backupPath,
+	}
+}
@@ -0,0 +1,34 @@
+package appmessage
+
+// CompactDbRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type CompactDbRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *CompactDbRequestMessage) Command() MessageCommand {
+	return CmdCompactDbRequestMessage
+}
+
+// NewCompactDbRequestMessage returns a instance of the message
+func NewCompactDbRequestMessage() *CompactDbRequestMessage {
+	return &CompactDbRequestMessage{}
+}
+
+// CompactDbResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type CompactDbResponseMessage struct {
+	baseMessage
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *CompactDbResponseMessage) Command() MessageCommand {
+	return CmdCompactDbResponseMessage
+}
+
+// NewCompactDbResponseMessage returns a instance of the message
+func NewCompactDbResponseMessage() *CompactDbResponseMessage {
+	return &CompactDbResponseMessage{}
+}
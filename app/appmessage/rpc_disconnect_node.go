@@ -0,0 +1,37 @@
+package appmessage
+
+// DisconnectNodeRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type DisconnectNodeRequestMessage struct {
+	baseMessage
+	Address string
+}
+
+// Command returns the protocol command string for the message
+func (msg *DisconnectNodeRequestMessage) Command() MessageCommand {
+	return CmdDisconnectNodeRequestMessage
+}
+
+// NewDisconnectNodeRequestMessage returns a instance of the message
+func NewDisconnectNodeRequestMessage(address string) *DisconnectNodeRequestMessage {
+	return &DisconnectNodeRequestMessage{
+		Address: address,
+	}
+}
+
+// DisconnectNodeResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type DisconnectNodeResponseMessage struct {
+	baseMessage
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *DisconnectNodeResponseMessage) Command() MessageCommand {
+	return CmdDisconnectNodeResponseMessage
+}
+
+// NewDisconnectNodeResponseMessage returns a instance of the message
+func NewDisconnectNodeResponseMessage() *DisconnectNodeResponseMessage {
+	return &DisconnectNodeResponseMessage{}
+}
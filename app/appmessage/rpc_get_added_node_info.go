@@ -0,0 +1,43 @@
+package appmessage
+
+// GetAddedNodeInfoRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetAddedNodeInfoRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetAddedNodeInfoRequestMessage) Command() MessageCommand {
+	return CmdGetAddedNodeInfoRequestMessage
+}
+
+// NewGetAddedNodeInfoRequestMessage returns a instance of the message
+func NewGetAddedNodeInfoRequestMessage() *GetAddedNodeInfoRequestMessage {
+	return &GetAddedNodeInfoRequestMessage{}
+}
+
+// GetAddedNodeInfoResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetAddedNodeInfoResponseMessage struct {
+	baseMessage
+	Infos []*AddedNodeInfo
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetAddedNodeInfoResponseMessage) Command() MessageCommand {
+	return CmdGetAddedNodeInfoResponseMessage
+}
+
+// NewGetAddedNodeInfoResponseMessage returns a instance of the message
+func NewGetAddedNodeInfoResponseMessage(infos []*AddedNodeInfo) *GetAddedNodeInfoResponseMessage {
+	return &GetAddedNodeInfoResponseMessage{
+		Infos: infos,
+	}
+}
+
+// AddedNodeInfo holds information about a node that was added with AddNode
+type AddedNodeInfo struct {
+	Address     string
+	IsConnected bool
+}
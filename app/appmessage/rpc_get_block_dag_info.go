@@ -29,6 +29,9 @@ type GetBlockDAGInfoResponseMessage struct {
 	PastMedianTime      int64
 	PruningPointHash    string
 	VirtualDAAScore     uint64
+	VirtualBlueScore    uint64
+	UTXOCommitment      string
+	SyncProgress        float64
 
 	Error *RPCError
 }
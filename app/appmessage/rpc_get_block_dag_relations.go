@@ -0,0 +1,49 @@
+package appmessage
+
+// GetBlockDAGRelationsRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetBlockDAGRelationsRequestMessage struct {
+	baseMessage
+	Hash    string
+	LowHash string
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetBlockDAGRelationsRequestMessage) Command() MessageCommand {
+	return CmdGetBlockDAGRelationsRequestMessage
+}
+
+// NewGetBlockDAGRelationsRequestMessage returns a instance of the message
+func NewGetBlockDAGRelationsRequestMessage(hash string, lowHash string) *GetBlockDAGRelationsRequestMessage {
+	return &GetBlockDAGRelationsRequestMessage{
+		Hash:    hash,
+		LowHash: lowHash,
+	}
+}
+
+// GetBlockDAGRelationsResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetBlockDAGRelationsResponseMessage struct {
+	baseMessage
+	AnticoneHashes     []string
+	BlueHashes         []string
+	BlueHashesHighHash string
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetBlockDAGRelationsResponseMessage) Command() MessageCommand {
+	return CmdGetBlockDAGRelationsResponseMessage
+}
+
+// NewGetBlockDAGRelationsResponseMessage returns a instance of the message
+func NewGetBlockDAGRelationsResponseMessage(anticoneHashes []string, blueHashes []string,
+	blueHashesHighHash string) *GetBlockDAGRelationsResponseMessage {
+
+	return &GetBlockDAGRelationsResponseMessage{
+		AnticoneHashes:     anticoneHashes,
+		BlueHashes:         blueHashes,
+		BlueHashesHighHash: blueHashesHighHash,
+	}
+}
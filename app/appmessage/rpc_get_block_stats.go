@@ -0,0 +1,64 @@
+package appmessage
+
+// GetBlockStatsRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetBlockStatsRequestMessage struct {
+	baseMessage
+	Hash     string
+	HighHash string
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetBlockStatsRequestMessage) Command() MessageCommand {
+	return CmdGetBlockStatsRequestMessage
+}
+
+// NewGetBlockStatsRequestMessage returns a instance of the message
+func NewGetBlockStatsRequestMessage(hash string, highHash string) *GetBlockStatsRequestMessage {
+	return &GetBlockStatsRequestMessage{
+		Hash:     hash,
+		HighHash: highHash,
+	}
+}
+
+// GetBlockStatsResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetBlockStatsResponseMessage struct {
+	baseMessage
+	BlockStats []*RPCBlockStats
+	Error      *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetBlockStatsResponseMessage) Command() MessageCommand {
+	return CmdGetBlockStatsResponseMessage
+}
+
+// NewGetBlockStatsResponseMessage returns a instance of the message
+func NewGetBlockStatsResponseMessage(blockStats []*RPCBlockStats) *GetBlockStatsResponseMessage {
+	return &GetBlockStatsResponseMessage{
+		BlockStats: blockStats,
+	}
+}
+
+// RPCBlockStats holds aggregate statistics of a block
+type RPCBlockStats struct {
+	Hash                    string
+	BlueScore               uint64
+	Timestamp               int64
+	TimeSinceSelectedParent int64
+	ParentCount             uint32
+	MergeSetBluesCount      uint32
+	MergeSetRedsCount       uint32
+	IsChainBlock            bool
+	Size                    uint64
+	Mass                    uint64
+	TransactionCount        uint32
+	InputCount              uint32
+	OutputCount             uint32
+	IsAccepted              bool
+	AcceptingBlockHash      string
+	AcceptedTxCount         uint32
+	TotalFees               uint64
+	FeeratePercentiles      []float64
+}
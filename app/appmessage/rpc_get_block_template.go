@@ -1,11 +1,29 @@
 package appmessage
 
+// The capabilities a caller of getBlockTemplate may declare. BlockTemplateMutationTime
+// and BlockTemplateMutationCoinbaseAppend are also returned in the Mutable field of the
+// response when the caller is allowed to make the respective modification.
+const (
+	BlockTemplateCapabilityLongPoll     = "longpoll"
+	BlockTemplateMutationTime           = "time"
+	BlockTemplateMutationCoinbaseAppend = "coinbase/append"
+)
+
 // GetBlockTemplateRequestMessage is an appmessage corresponding to
 // its respective RPC message
 type GetBlockTemplateRequestMessage struct {
 	baseMessage
-	PayAddress string
-	ExtraData  string
+	PayAddress   string
+	ExtraData    string
+	LongPollID   string
+	Capabilities []string
+
+	// ExcludedTransactionIDs are never included in the template, while
+	// IncludedTransactionIDs are included before any other transaction. MaxMass,
+	// if it isn't 0, lowers the maximum mass of the transactions of the template.
+	ExcludedTransactionIDs []string
+	IncludedTransactionIDs []string
+	MaxMass                uint64
 }
 
 // Command returns the protocol command string for the message
@@ -25,8 +43,12 @@ func NewGetBlockTemplateRequestMessage(payAddress, extraData string) *GetBlockTe
 // its respective RPC message
 type GetBlockTemplateResponseMessage struct {
 	baseMessage
-	Block    *RPCBlock
-	IsSynced bool
+	Block                   *RPCBlock
+	IsSynced                bool
+	LongPollID              string
+	Capabilities            []string
+	Mutable                 []string
+	CoinbaseAppendMaxLength uint64
 
 	Error *RPCError
 }
@@ -47,4 +47,28 @@ type GetConnectedPeerInfoMessage struct {
 	AdvertisedProtocolVersion uint32
 	TimeConnected             int64
 	IsIBDPeer                 bool
+	BytesSent                 uint64
+	BytesReceived             uint64
+	BlockBytesServed          uint64
+	TransactionBytesServed    uint64
+	Permissions               []string
+	IsEncrypted               bool
+	MinPingDuration           int64
+	AveragePingDuration       int64
+
+	// LastBlockAnnouncementTime and LastTransactionAnnouncementTime are
+	// timestamps in milliseconds, or 0 if the peer never announced any
+	LastBlockAnnouncementTime       int64
+	LastTransactionAnnouncementTime int64
+
+	SentMessages     []*PeerMessageCount
+	ReceivedMessages []*PeerMessageCount
+}
+
+// PeerMessageCount holds the number of messages of a command that were
+// transferred with a connected peer, and their total size in bytes
+type PeerMessageCount struct {
+	Command  string
+	Messages uint64
+	Bytes    uint64
 }
@@ -0,0 +1,41 @@
+package appmessage
+
+// GetDbInfoRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetDbInfoRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetDbInfoRequestMessage) Command() MessageCommand {
+	return CmdGetDbInfoRequestMessage
+}
+
+// NewGetDbInfoRequestMessage returns a instance of the message
+func NewGetDbInfoRequestMessage() *GetDbInfoRequestMessage {
+	return &GetDbInfoRequestMessage{}
+}
+
+// GetDbInfoResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetDbInfoResponseMessage struct {
+	baseMessage
+	DbType                  string
+	SizeBytes               uint64
+	Buckets                 []*DbBucketInfo
+	IsCompacting            bool
+	LastCompactionTimestamp int64
+
+	Error *RPCError
+}
+
+// DbBucketInfo is the approximate on-disk size of a database bucket
+type DbBucketInfo struct {
+	Name      string
+	SizeBytes uint64
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetDbInfoResponseMessage) Command() MessageCommand {
+	return CmdGetDbInfoResponseMessage
+}
@@ -0,0 +1,45 @@
+package appmessage
+
+// GetMempoolInfoRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetMempoolInfoRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetMempoolInfoRequestMessage) Command() MessageCommand {
+	return CmdGetMempoolInfoRequestMessage
+}
+
+// NewGetMempoolInfoRequestMessage returns a instance of the message
+func NewGetMempoolInfoRequestMessage() *GetMempoolInfoRequestMessage {
+	return &GetMempoolInfoRequestMessage{}
+}
+
+// GetMempoolInfoResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetMempoolInfoResponseMessage struct {
+	baseMessage
+	TransactionCount           uint64
+	OrphanCount                uint64
+	MemoryUsage                uint64
+	MaximumMemoryUsage         uint64
+	MempoolMinimumFee          uint64
+	MinimumRelayTransactionFee uint64
+	IncrementalRelayFee        uint64
+	OrphanMemoryUsage          uint64
+	MaximumOrphanCount         uint64
+	MaximumOrphanMemoryUsage   uint64
+	MissingOrphanOutpointCount uint64
+	OrphanPeerCount            uint64
+	PromotedOrphanCount        uint64
+	EvictedOrphanCount         uint64
+	ExpiredOrphanCount         uint64
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetMempoolInfoResponseMessage) Command() MessageCommand {
+	return CmdGetMempoolInfoResponseMessage
+}
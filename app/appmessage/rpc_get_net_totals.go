@@ -0,0 +1,46 @@
+package appmessage
+
+// GetNetTotalsRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetNetTotalsRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetNetTotalsRequestMessage) Command() MessageCommand {
+	return CmdGetNetTotalsRequestMessage
+}
+
+// NewGetNetTotalsRequestMessage returns a instance of the message
+func NewGetNetTotalsRequestMessage() *GetNetTotalsRequestMessage {
+	return &GetNetTotalsRequestMessage{}
+}
+
+// GetNetTotalsResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetNetTotalsResponseMessage struct {
+	baseMessage
+	TotalBytesSent         uint64
+	TotalBytesReceived     uint64
+	BlockBytesServed       uint64
+	TransactionBytesServed uint64
+	TimeMilliseconds       int64
+	UploadTarget           *UploadTarget
+
+	Error *RPCError
+}
+
+// UploadTarget describes the state of the upload target set by --maxuploadtarget
+type UploadTarget struct {
+	TimeframeSeconds      uint64
+	Target                uint64
+	TargetReached         bool
+	ServeHistoricalBlocks bool
+	BytesLeftInCycle      uint64
+	SecondsLeftInCycle    uint64
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetNetTotalsResponseMessage) Command() MessageCommand {
+	return CmdGetNetTotalsResponseMessage
+}
@@ -0,0 +1,55 @@
+package appmessage
+
+// GetNetworkInfoRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetNetworkInfoRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetNetworkInfoRequestMessage) Command() MessageCommand {
+	return CmdGetNetworkInfoRequestMessage
+}
+
+// NewGetNetworkInfoRequestMessage returns a instance of the message
+func NewGetNetworkInfoRequestMessage() *GetNetworkInfoRequestMessage {
+	return &GetNetworkInfoRequestMessage{}
+}
+
+// GetNetworkInfoResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetNetworkInfoResponseMessage struct {
+	baseMessage
+	ProtocolVersion            uint32
+	LocalServices              uint64
+	MinimumRelayTransactionFee uint64
+	IncrementalRelayFee        uint64
+	LocalAddresses             []*LocalAddress
+	Networks                   []*NetworkReachability
+	InboundConnectionCount     uint32
+	OutboundConnectionCount    uint32
+
+	Error *RPCError
+}
+
+// LocalAddress is an address the node advertises to its peers,
+// along with the score of the method it was discovered by
+type LocalAddress struct {
+	Address string
+	Score   int32
+}
+
+// NetworkReachability describes whether the node is able to connect to peers in a network
+type NetworkReachability struct {
+	Name           string
+	Reachable      bool
+	Proxy          string
+	Limited        bool
+	SelfTestPassed bool
+	SelfTestError  string
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetNetworkInfoResponseMessage) Command() MessageCommand {
+	return CmdGetNetworkInfoResponseMessage
+}
@@ -0,0 +1,49 @@
+package appmessage
+
+// GetProcessingStatsRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetProcessingStatsRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetProcessingStatsRequestMessage) Command() MessageCommand {
+	return CmdGetProcessingStatsRequestMessage
+}
+
+// NewGetProcessingStatsRequestMessage returns a instance of the message
+func NewGetProcessingStatsRequestMessage() *GetProcessingStatsRequestMessage {
+	return &GetProcessingStatsRequestMessage{}
+}
+
+// GetProcessingStatsResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetProcessingStatsResponseMessage struct {
+	baseMessage
+	Stages []*ProcessingStageStats
+	Error  *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetProcessingStatsResponseMessage) Command() MessageCommand {
+	return CmdGetProcessingStatsResponseMessage
+}
+
+// NewGetProcessingStatsResponseMessage returns a instance of the message
+func NewGetProcessingStatsResponseMessage(stages []*ProcessingStageStats) *GetProcessingStatsResponseMessage {
+	return &GetProcessingStatsResponseMessage{
+		Stages: stages,
+	}
+}
+
+// ProcessingStageStats holds the statistics of the recent durations of a stage of
+// block processing, in microseconds
+type ProcessingStageStats struct {
+	Stage               string
+	Count               uint64
+	AverageMicroseconds uint64
+	P50Microseconds     uint64
+	P90Microseconds     uint64
+	P99Microseconds     uint64
+	MaxMicroseconds     uint64
+}
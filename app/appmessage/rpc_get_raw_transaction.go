@@ -0,0 +1,45 @@
+package appmessage
+
+// GetRawTransactionRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetRawTransactionRequestMessage struct {
+	baseMessage
+	TxID string
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetRawTransactionRequestMessage) Command() MessageCommand {
+	return CmdGetRawTransactionRequestMessage
+}
+
+// NewGetRawTransactionRequestMessage returns a instance of the message
+func NewGetRawTransactionRequestMessage(txID string) *GetRawTransactionRequestMessage {
+	return &GetRawTransactionRequestMessage{
+		TxID: txID,
+	}
+}
+
+// GetRawTransactionResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetRawTransactionResponseMessage struct {
+	baseMessage
+	Transaction        *RPCTransaction
+	IsInMempool        bool
+	Confirmations      uint64
+	AcceptingBlockHash string
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetRawTransactionResponseMessage) Command() MessageCommand {
+	return CmdGetRawTransactionResponseMessage
+}
+
+// NewGetRawTransactionResponseMessage returns a instance of the message
+func NewGetRawTransactionResponseMessage(transaction *RPCTransaction, isInMempool bool) *GetRawTransactionResponseMessage {
+	return &GetRawTransactionResponseMessage{
+		Transaction: transaction,
+		IsInMempool: isInMempool,
+	}
+}
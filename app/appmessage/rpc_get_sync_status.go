@@ -0,0 +1,61 @@
+package appmessage
+
+// GetSyncStatusRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetSyncStatusRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetSyncStatusRequestMessage) Command() MessageCommand {
+	return CmdGetSyncStatusRequestMessage
+}
+
+// NewGetSyncStatusRequestMessage returns a instance of the message
+func NewGetSyncStatusRequestMessage() *GetSyncStatusRequestMessage {
+	return &GetSyncStatusRequestMessage{}
+}
+
+// GetSyncStatusResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetSyncStatusResponseMessage struct {
+	baseMessage
+	IsSynced       bool
+	IsIBDRunning   bool
+	IBDPeerAddress string
+
+	HeaderCount uint64
+	BlockCount  uint64
+
+	HeadersDAAScore uint64
+	BlocksDAAScore  uint64
+	TargetDAAScore  uint64
+
+	HeadersProgress float64
+	BlocksProgress  float64
+
+	TrustedChainBlockHash string
+	TrustedPeers          []*TrustedPeerSyncStatus
+
+	Error *RPCError
+}
+
+// TrustedPeerSyncStatus describes how the view of the DAG of a trusted peer
+// compares to the one of the node
+type TrustedPeerSyncStatus struct {
+	Address              string
+	AnnouncedBlockHash   string
+	AnnouncementTime     int64
+	AgreedChainBlockHash string
+	IsDiverged           bool
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetSyncStatusResponseMessage) Command() MessageCommand {
+	return CmdGetSyncStatusResponseMessage
+}
+
+// NewGetSyncStatusResponseMessage returns a instance of the message
+func NewGetSyncStatusResponseMessage() *GetSyncStatusResponseMessage {
+	return &GetSyncStatusResponseMessage{}
+}
@@ -0,0 +1,52 @@
+package appmessage
+
+// GetTxOutProofRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetTxOutProofRequestMessage struct {
+	baseMessage
+	TransactionID string
+	BlockHash     string
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetTxOutProofRequestMessage) Command() MessageCommand {
+	return CmdGetTxOutProofRequestMessage
+}
+
+// NewGetTxOutProofRequestMessage returns a instance of the message
+func NewGetTxOutProofRequestMessage(transactionID string, blockHash string) *GetTxOutProofRequestMessage {
+	return &GetTxOutProofRequestMessage{
+		TransactionID: transactionID,
+		BlockHash:     blockHash,
+	}
+}
+
+// GetTxOutProofResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetTxOutProofResponseMessage struct {
+	baseMessage
+	Proof *RPCTransactionMerkleProof
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetTxOutProofResponseMessage) Command() MessageCommand {
+	return CmdGetTxOutProofResponseMessage
+}
+
+// NewGetTxOutProofResponseMessage returns a instance of the message
+func NewGetTxOutProofResponseMessage(proof *RPCTransactionMerkleProof) *GetTxOutProofResponseMessage {
+	return &GetTxOutProofResponseMessage{
+		Proof: proof,
+	}
+}
+
+// RPCTransactionMerkleProof is a proof that the block of the given header contains the
+// given transaction
+type RPCTransactionMerkleProof struct {
+	Header      *RPCBlockHeader
+	Transaction *RPCTransaction
+	Index       uint32
+	Siblings    []string
+}
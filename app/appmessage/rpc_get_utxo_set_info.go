@@ -0,0 +1,57 @@
+package appmessage
+
+// GetUTXOSetInfoRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetUTXOSetInfoRequestMessage struct {
+	baseMessage
+	Verbose bool
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetUTXOSetInfoRequestMessage) Command() MessageCommand {
+	return CmdGetUTXOSetInfoRequestMessage
+}
+
+// NewGetUTXOSetInfoRequestMessage returns a instance of the message
+func NewGetUTXOSetInfoRequestMessage(verbose bool) *GetUTXOSetInfoRequestMessage {
+	return &GetUTXOSetInfoRequestMessage{
+		Verbose: verbose,
+	}
+}
+
+// UTXOSetStatisticsEntry represents the number and total value of
+// a subset of the UTXO set
+type UTXOSetStatisticsEntry struct {
+	Name  string
+	Count uint64
+	Sompi uint64
+}
+
+// GetUTXOSetInfoResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetUTXOSetInfoResponseMessage struct {
+	baseMessage
+	UTXOCount              uint64
+	TotalSompi             uint64
+	ScriptClassStatistics  []*UTXOSetStatisticsEntry
+	AmountBucketStatistics []*UTXOSetStatisticsEntry
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetUTXOSetInfoResponseMessage) Command() MessageCommand {
+	return CmdGetUTXOSetInfoResponseMessage
+}
+
+// NewGetUTXOSetInfoResponseMessage returns a instance of the message
+func NewGetUTXOSetInfoResponseMessage(utxoCount uint64, totalSompi uint64,
+	scriptClassStatistics []*UTXOSetStatisticsEntry, amountBucketStatistics []*UTXOSetStatisticsEntry) *GetUTXOSetInfoResponseMessage {
+
+	return &GetUTXOSetInfoResponseMessage{
+		UTXOCount:              utxoCount,
+		TotalSompi:             totalSompi,
+		ScriptClassStatistics:  scriptClassStatistics,
+		AmountBucketStatistics: amountBucketStatistics,
+	}
+}
@@ -0,0 +1,37 @@
+package appmessage
+
+// InvalidateBlockRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type InvalidateBlockRequestMessage struct {
+	baseMessage
+	Hash string
+}
+
+// Command returns the protocol command string for the message
+func (msg *InvalidateBlockRequestMessage) Command() MessageCommand {
+	return CmdInvalidateBlockRequestMessage
+}
+
+// NewInvalidateBlockRequestMessage returns a instance of the message
+func NewInvalidateBlockRequestMessage(hash string) *InvalidateBlockRequestMessage {
+	return &InvalidateBlockRequestMessage{
+		Hash: hash,
+	}
+}
+
+// InvalidateBlockResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type InvalidateBlockResponseMessage struct {
+	baseMessage
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *InvalidateBlockResponseMessage) Command() MessageCommand {
+	return CmdInvalidateBlockResponseMessage
+}
+
+// NewInvalidateBlockResponseMessage returns a instance of the message
+func NewInvalidateBlockResponseMessage() *InvalidateBlockResponseMessage {
+	return &InvalidateBlockResponseMessage{}
+}
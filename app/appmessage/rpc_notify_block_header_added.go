@@ -0,0 +1,55 @@
+package appmessage
+
+// NotifyBlockHeaderAddedRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type NotifyBlockHeaderAddedRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *NotifyBlockHeaderAddedRequestMessage) Command() MessageCommand {
+	return CmdNotifyBlockHeaderAddedRequestMessage
+}
+
+// NewNotifyBlockHeaderAddedRequestMessage returns an instance of the message
+func NewNotifyBlockHeaderAddedRequestMessage() *NotifyBlockHeaderAddedRequestMessage {
+	return &NotifyBlockHeaderAddedRequestMessage{}
+}
+
+// NotifyBlockHeaderAddedResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type NotifyBlockHeaderAddedResponseMessage struct {
+	baseMessage
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *NotifyBlockHeaderAddedResponseMessage) Command() MessageCommand {
+	return CmdNotifyBlockHeaderAddedResponseMessage
+}
+
+// NewNotifyBlockHeaderAddedResponseMessage returns a instance of the message
+func NewNotifyBlockHeaderAddedResponseMessage() *NotifyBlockHeaderAddedResponseMessage {
+	return &NotifyBlockHeaderAddedResponseMessage{}
+}
+
+// BlockHeaderAddedNotificationMessage is an appmessage corresponding to
+// its respective RPC message
+type BlockHeaderAddedNotificationMessage struct {
+	baseMessage
+	Hash   string
+	Header *RPCBlockHeader
+}
+
+// Command returns the protocol command string for the message
+func (msg *BlockHeaderAddedNotificationMessage) Command() MessageCommand {
+	return CmdBlockHeaderAddedNotificationMessage
+}
+
+// NewBlockHeaderAddedNotificationMessage returns a instance of the message
+func NewBlockHeaderAddedNotificationMessage(hash string, header *RPCBlockHeader) *BlockHeaderAddedNotificationMessage {
+	return &BlockHeaderAddedNotificationMessage{
+		Hash:   hash,
+		Header: header,
+	}
+}
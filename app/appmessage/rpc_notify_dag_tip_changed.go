@@ -0,0 +1,61 @@
+package appmessage
+
+// NotifyDAGTipChangedRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type NotifyDAGTipChangedRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *NotifyDAGTipChangedRequestMessage) Command() MessageCommand {
+	return CmdNotifyDAGTipChangedRequestMessage
+}
+
+// NewNotifyDAGTipChangedRequestMessage returns an instance of the message
+func NewNotifyDAGTipChangedRequestMessage() *NotifyDAGTipChangedRequestMessage {
+	return &NotifyDAGTipChangedRequestMessage{}
+}
+
+// NotifyDAGTipChangedResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type NotifyDAGTipChangedResponseMessage struct {
+	baseMessage
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *NotifyDAGTipChangedResponseMessage) Command() MessageCommand {
+	return CmdNotifyDAGTipChangedResponseMessage
+}
+
+// NewNotifyDAGTipChangedResponseMessage returns a instance of the message
+func NewNotifyDAGTipChangedResponseMessage() *NotifyDAGTipChangedResponseMessage {
+	return &NotifyDAGTipChangedResponseMessage{}
+}
+
+// DAGTipChangedNotificationMessage is an appmessage corresponding to
+// its respective RPC message
+type DAGTipChangedNotificationMessage struct {
+	baseMessage
+	TipHashes               []string
+	RemovedChainBlockHashes []string
+	AddedChainBlockHashes   []string
+	AcceptedTransactionIDs  []*AcceptedTransactionIDs
+}
+
+// Command returns the protocol command string for the message
+func (msg *DAGTipChangedNotificationMessage) Command() MessageCommand {
+	return CmdDAGTipChangedNotificationMessage
+}
+
+// NewDAGTipChangedNotificationMessage returns a instance of the message
+func NewDAGTipChangedNotificationMessage(tipHashes, removedChainBlockHashes, addedChainBlockHashes []string,
+	acceptedTransactionIDs []*AcceptedTransactionIDs) *DAGTipChangedNotificationMessage {
+
+	return &DAGTipChangedNotificationMessage{
+		TipHashes:               tipHashes,
+		RemovedChainBlockHashes: removedChainBlockHashes,
+		AddedChainBlockHashes:   addedChainBlockHashes,
+		AcceptedTransactionIDs:  acceptedTransactionIDs,
+	}
+}
@@ -42,9 +42,10 @@ func NewNotifyVirtualSelectedParentChainChangedResponseMessage() *NotifyVirtualS
 // its respective RPC message
 type VirtualSelectedParentChainChangedNotificationMessage struct {
 	baseMessage
-	RemovedChainBlockHashes []string
-	AddedChainBlockHashes   []string
-	AcceptedTransactionIDs  []*AcceptedTransactionIDs
+	RemovedChainBlockHashes  []string
+	AddedChainBlockHashes    []string
+	AcceptedTransactionIDs   []*AcceptedTransactionIDs
+	UnacceptedTransactionIDs []*AcceptedTransactionIDs
 }
 
 // Command returns the protocol command string for the message
@@ -54,11 +55,13 @@ func (msg *VirtualSelectedParentChainChangedNotificationMessage) Command() Messa
 
 // NewVirtualSelectedParentChainChangedNotificationMessage returns a instance of the message
 func NewVirtualSelectedParentChainChangedNotificationMessage(removedChainBlockHashes,
-	addedChainBlocks []string, acceptedTransactionIDs []*AcceptedTransactionIDs) *VirtualSelectedParentChainChangedNotificationMessage {
+	addedChainBlocks []string, acceptedTransactionIDs []*AcceptedTransactionIDs,
+	unacceptedTransactionIDs []*AcceptedTransactionIDs) *VirtualSelectedParentChainChangedNotificationMessage {
 
 	return &VirtualSelectedParentChainChangedNotificationMessage{
-		RemovedChainBlockHashes: removedChainBlockHashes,
-		AddedChainBlockHashes:   addedChainBlocks,
-		AcceptedTransactionIDs:  acceptedTransactionIDs,
+		RemovedChainBlockHashes:  removedChainBlockHashes,
+		AddedChainBlockHashes:    addedChainBlocks,
+		AcceptedTransactionIDs:   acceptedTransactionIDs,
+		UnacceptedTransactionIDs: unacceptedTransactionIDs,
 	}
 }
@@ -0,0 +1,37 @@
+package appmessage
+
+// ReconsiderBlockRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type ReconsiderBlockRequestMessage struct {
+	baseMessage
+	Hash string
+}
+
+// Command returns the protocol command string for the message
+func (msg *ReconsiderBlockRequestMessage) Command() MessageCommand {
+	return CmdReconsiderBlockRequestMessage
+}
+
+// NewReconsiderBlockRequestMessage returns a instance of the message
+func NewReconsiderBlockRequestMessage(hash string) *ReconsiderBlockRequestMessage {
+	return &ReconsiderBlockRequestMessage{
+		Hash: hash,
+	}
+}
+
+// ReconsiderBlockResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type ReconsiderBlockResponseMessage struct {
+	baseMessage
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *ReconsiderBlockResponseMessage) Command() MessageCommand {
+	return CmdReconsiderBlockResponseMessage
+}
+
+// NewReconsiderBlockResponseMessage returns a instance of the message
+func NewReconsiderBlockResponseMessage() *ReconsiderBlockResponseMessage {
+	return &ReconsiderBlockResponseMessage{}
+}
@@ -0,0 +1,40 @@
+package appmessage
+
+// StartProfileRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type StartProfileRequestMessage struct {
+	baseMessage
+	ProfileType string
+}
+
+// Command returns the protocol command string for the message
+func (msg *StartProfileRequestMessage) Command() MessageCommand {
+	return CmdStartProfileRequestMessage
+}
+
+// NewStartProfileRequestMessage returns a instance of the message
+func NewStartProfileRequestMessage(profileType string) *StartProfileRequestMessage {
+	return &StartProfileRequestMessage{
+		ProfileType: profileType,
+	}
+}
+
+// StartProfileResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type StartProfileResponseMessage struct {
+	baseMessage
+	FilePath string
+	Error    *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *StartProfileResponseMessage) Command() MessageCommand {
+	return CmdStartProfileResponseMessage
+}
+
+// NewStartProfileResponseMessage returns a instance of the message
+func NewStartProfileResponseMessage(filePath string) *StartProfileResponseMessage {
+	return &StartProfileResponseMessage{
+		FilePath: filePath,
+	}
+}
@@ -0,0 +1,42 @@
+package appmessage
+
+// StopProfileRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type StopProfileRequestMessage struct {
+	baseMessage
+	ProfileType string
+}
+
+// Command returns the protocol command string for the message
+func (msg *StopProfileRequestMessage) Command() MessageCommand {
+	return CmdStopProfileRequestMessage
+}
+
+// NewStopProfileRequestMessage returns a instance of the message
+func NewStopProfileRequestMessage(profileType string) *StopProfileRequestMessage {
+	return &StopProfileRequestMessage{
+		ProfileType: profileType,
+	}
+}
+
+// StopProfileResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type StopProfileResponseMessage struct {
+	baseMessage
+	FilePath             string
+	DurationMilliseconds uint64
+	Error                *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *StopProfileResponseMessage) Command() MessageCommand {
+	return CmdStopProfileResponseMessage
+}
+
+// NewStopProfileResponseMessage returns a instance of the message
+func NewStopProfileResponseMessage(filePath string, durationMilliseconds uint64) *StopProfileResponseMessage {
+	return &StopProfileResponseMessage{
+		FilePath:             filePath,
+		DurationMilliseconds: durationMilliseconds,
+	}
+}
@@ -0,0 +1,41 @@
+package appmessage
+
+// SubmitPackageRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type SubmitPackageRequestMessage struct {
+	baseMessage
+	Transactions []*RPCTransaction
+}
+
+// Command returns the protocol command string for the message
+func (msg *SubmitPackageRequestMessage) Command() MessageCommand {
+	return CmdSubmitPackageRequestMessage
+}
+
+// NewSubmitPackageRequestMessage returns a instance of the message
+func NewSubmitPackageRequestMessage(transactions []*RPCTransaction) *SubmitPackageRequestMessage {
+	return &SubmitPackageRequestMessage{
+		Transactions: transactions,
+	}
+}
+
+// SubmitPackageResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type SubmitPackageResponseMessage struct {
+	baseMessage
+	TransactionIDs []string
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *SubmitPackageResponseMessage) Command() MessageCommand {
+	return CmdSubmitPackageResponseMessage
+}
+
+// NewSubmitPackageResponseMessage returns a instance of the message
+func NewSubmitPackageResponseMessage(transactionIDs []string) *SubmitPackageResponseMessage {
+	return &SubmitPackageResponseMessage{
+		TransactionIDs: transactionIDs,
+	}
+}
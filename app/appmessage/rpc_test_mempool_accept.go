@@ -0,0 +1,50 @@
+package appmessage
+
+// TestMempoolAcceptRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type TestMempoolAcceptRequestMessage struct {
+	baseMessage
+	Transactions []*RPCTransaction
+}
+
+// Command returns the protocol command string for the message
+func (msg *TestMempoolAcceptRequestMessage) Command() MessageCommand {
+	return CmdTestMempoolAcceptRequestMessage
+}
+
+// NewTestMempoolAcceptRequestMessage returns a instance of the message
+func NewTestMempoolAcceptRequestMessage(transactions []*RPCTransaction) *TestMempoolAcceptRequestMessage {
+	return &TestMempoolAcceptRequestMessage{
+		Transactions: transactions,
+	}
+}
+
+// TestMempoolAcceptResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type TestMempoolAcceptResponseMessage struct {
+	baseMessage
+	Results []*TestMempoolAcceptResult
+
+	Error *RPCError
+}
+
+// TestMempoolAcceptResult is whether a transaction would be accepted to the mempool
+type TestMempoolAcceptResult struct {
+	TransactionID string
+	Allowed       bool
+	RejectReason  string
+	Fee           uint64
+	Mass          uint64
+}
+
+// Command returns the protocol command string for the message
+func (msg *TestMempoolAcceptResponseMessage) Command() MessageCommand {
+	return CmdTestMempoolAcceptResponseMessage
+}
+
+// NewTestMempoolAcceptResponseMessage returns a instance of the message
+func NewTestMempoolAcceptResponseMessage(results []*TestMempoolAcceptResult) *TestMempoolAcceptResponseMessage {
+	return &TestMempoolAcceptResponseMessage{
+		Results: results,
+	}
+}
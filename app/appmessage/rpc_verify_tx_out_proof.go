@@ -0,0 +1,43 @@
+package appmessage
+
+// VerifyTxOutProofRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type VerifyTxOutProofRequestMessage struct {
+	baseMessage
+	Proof *RPCTransactionMerkleProof
+}
+
+// Command returns the protocol command string for the message
+func (msg *VerifyTxOutProofRequestMessage) Command() MessageCommand {
+	return CmdVerifyTxOutProofRequestMessage
+}
+
+// NewVerifyTxOutProofRequestMessage returns a instance of the message
+func NewVerifyTxOutProofRequestMessage(proof *RPCTransactionMerkleProof) *VerifyTxOutProofRequestMessage {
+	return &VerifyTxOutProofRequestMessage{
+		Proof: proof,
+	}
+}
+
+// VerifyTxOutProofResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type VerifyTxOutProofResponseMessage struct {
+	baseMessage
+	TransactionID string
+	BlockHash     string
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *VerifyTxOutProofResponseMessage) Command() MessageCommand {
+	return CmdVerifyTxOutProofResponseMessage
+}
+
+// NewVerifyTxOutProofResponseMessage returns a instance of the message
+func NewVerifyTxOutProofResponseMessage(transactionID string, blockHash string) *VerifyTxOutProofResponseMessage {
+	return &VerifyTxOutProofResponseMessage{
+		TransactionID: transactionID,
+		BlockHash:     blockHash,
+	}
+}
@@ -2,23 +2,43 @@ package app
 
 import (
 	"fmt"
+	"net/http"
+	"strconv"
 	"sync/atomic"
 
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 
 	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
 
+	"github.com/kaspanet/kaspad/app/eventbridge"
 	"github.com/kaspanet/kaspad/app/protocol"
 	"github.com/kaspanet/kaspad/app/rpc"
+	"github.com/kaspanet/kaspad/app/sqlmirror"
+	"github.com/kaspanet/kaspad/app/stratum"
+	"github.com/kaspanet/kaspad/app/utxosnapshot"
+	"github.com/kaspanet/kaspad/app/zmq"
 	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/cfindex"
 	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/txindex"
 	"github.com/kaspanet/kaspad/domain/utxoindex"
 	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/compactor"
 	infrastructuredatabase "github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/metrics"
 	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
 	"github.com/kaspanet/kaspad/infrastructure/network/connmanager"
+	"github.com/kaspanet/kaspad/infrastructure/network/dnsseeder"
+	"github.com/kaspanet/kaspad/infrastructure/network/httpserver"
+	"github.com/kaspanet/kaspad/infrastructure/network/nat"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/id"
+	"github.com/kaspanet/kaspad/infrastructure/network/tor"
+	"github.com/kaspanet/kaspad/infrastructure/os/notifycmd"
+	"github.com/kaspanet/kaspad/infrastructure/os/systemd"
+	"github.com/kaspanet/kaspad/util/memorybudget"
 	"github.com/kaspanet/kaspad/util/panics"
 )
 
@@ -28,8 +48,24 @@ type ComponentManager struct {
 	addressManager    *addressmanager.AddressManager
 	protocolManager   *protocol.Manager
 	rpcManager        *rpc.Manager
+	stratumServer     *stratum.Server
+	dnsSeeder         *dnsseeder.Server
+	zmqPublisher      *zmq.Publisher
+	sqlMirror         *sqlmirror.Mirror
+	eventBridge       *eventbridge.Bridge
+	utxoSnapshots     *utxosnapshot.Publisher
+	restServer        *httpserver.Server
+	metricsServer     *httpserver.Server
+	notifiers         []*notifycmd.Notifier
+	watchdog          *systemd.Watchdog
+	memoryBudget      *memorybudget.Manager
 	connectionManager *connmanager.ConnectionManager
 	netAdapter        *netadapter.NetAdapter
+	onionService      *tor.OnionService
+	portMapper        *nat.PortMapper
+	dbCompactor       *compactor.Compactor
+	dbBackup          *databaseBackup
+	validationCaches  *txscript.ValidationCaches
 
 	started, shutdown int32
 }
@@ -43,12 +79,93 @@ func (a *ComponentManager) Start() {
 
 	log.Trace("Starting kaspad")
 
+	// The saved transactions are loaded before any peer connects, so that the
+	// relayed transactions that depend on them aren't treated as orphans
+	if a.cfg.PersistMempool {
+		loadMempool(a.cfg, a.protocolManager.Context().Domain().MiningManager())
+	}
+
 	err := a.netAdapter.Start()
 	if err != nil {
 		panics.Exit(log, fmt.Sprintf("Error starting the net adapter: %+v", err))
 	}
 
+	if a.onionService != nil {
+		onionAddress, err := a.onionService.Start()
+		if err != nil {
+			panics.Exit(log, fmt.Sprintf("Error starting the onion service: %+v", err))
+		}
+		err = a.addressManager.AddLocalAddress(onionAddress, addressmanager.ManualPrio)
+		if err != nil {
+			panics.Exit(log, fmt.Sprintf("Error advertising the onion service: %+v", err))
+		}
+	}
+
+	if a.portMapper != nil {
+		a.portMapper.Start()
+	}
+
 	a.connectionManager.Start()
+	a.dbCompactor.Start()
+
+	if a.stratumServer != nil {
+		err := a.stratumServer.Start()
+		if err != nil {
+			panics.Exit(log, fmt.Sprintf("Error starting the Stratum server: %+v", err))
+		}
+	}
+
+	if a.dnsSeeder != nil {
+		err := a.dnsSeeder.Start()
+		if err != nil {
+			panics.Exit(log, fmt.Sprintf("Error starting the DNS seeder: %+v", err))
+		}
+	}
+
+	if a.restServer != nil {
+		err := a.restServer.Start()
+		if err != nil {
+			panics.Exit(log, fmt.Sprintf("Error starting the REST server: %+v", err))
+		}
+	}
+
+	if a.metricsServer != nil {
+		err := a.metricsServer.Start()
+		if err != nil {
+			panics.Exit(log, fmt.Sprintf("Error starting the metrics server: %+v", err))
+		}
+	}
+
+	if a.zmqPublisher != nil {
+		err := a.zmqPublisher.Start()
+		if err != nil {
+			panics.Exit(log, fmt.Sprintf("Error starting the ZMQ publisher: %+v", err))
+		}
+	}
+
+	for _, notifier := range a.notifiers {
+		notifier.Start()
+	}
+
+	if a.sqlMirror != nil {
+		a.sqlMirror.Start()
+	}
+
+	if a.eventBridge != nil {
+		a.eventBridge.Start()
+	}
+
+	if a.utxoSnapshots != nil {
+		a.utxoSnapshots.Start()
+	}
+
+	if a.memoryBudget != nil {
+		a.memoryBudget.Start()
+	}
+
+	if a.watchdog != nil {
+		a.watchdog.Start()
+	}
 }
 
 // Stop gracefully shuts down all the kaspad services.
@@ -61,7 +178,65 @@ func (a *ComponentManager) Stop() {
 
 	log.Warnf("Kaspad shutting down")
 
+	if a.watchdog != nil {
+		a.watchdog.Stop()
+	}
+
+	if a.memoryBudget != nil {
+		a.memoryBudget.Stop()
+	}
+
+	if a.stratumServer != nil {
+		a.stratumServer.Stop()
+	}
+
+	if a.dnsSeeder != nil {
+		a.dnsSeeder.Stop()
+	}
+
+	if a.restServer != nil {
+		a.restServer.Stop()
+	}
+
+	if a.metricsServer != nil {
+		a.metricsServer.Stop()
+	}
+
+	if a.zmqPublisher != nil {
+		a.zmqPublisher.Stop()
+	}
+
+	for _, notifier := range a.notifiers {
+		notifier.Stop()
+	}
+
+	if a.sqlMirror != nil {
+		a.sqlMirror.Stop()
+	}
+
+	if a.eventBridge != nil {
+		a.eventBridge.Stop()
+	}
+
+	if a.utxoSnapshots != nil {
+		a.utxoSnapshots.Stop()
+	}
+
+	if a.portMapper != nil {
+		a.portMapper.Stop()
+	}
+
+	if a.onionService != nil {
+		err := a.onionService.Stop()
+		if err != nil {
+			log.Errorf("Error stopping the onion service: %+v", err)
+		}
+	}
+
+	a.rpcManager.Stop()
 	a.connectionManager.Stop()
+	a.dbCompactor.Stop()
+	a.dbBackup.stop()
 
 	err := a.netAdapter.Stop()
 	if err != nil {
@@ -69,6 +244,14 @@ func (a *ComponentManager) Stop() {
 	}
 
 	a.protocolManager.Close()
+
+	// The mempool is saved once the net adapter is stopped, so that no transaction is missed
+	if a.cfg.PersistMempool {
+		saveMempool(a.cfg, a.protocolManager.Context().Domain().MiningManager())
+	}
+	if a.cfg.PersistValidationCaches {
+		saveValidationCaches(a.cfg, a.validationCaches)
+	}
 	close(a.protocolManager.Context().Domain().ConsensusEventsChannel())
 
 	return
@@ -84,15 +267,42 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 		IsArchival:                      cfg.IsArchivalNode,
 		EnableSanityCheckPruningUTXOSet: cfg.EnableSanityCheckPruningUTXOSet,
 	}
+	validationCaches := txscript.NewValidationCaches(cfg.SigCacheMaxSize, cfg.ScriptCacheMaxSize)
+	if cfg.PersistValidationCaches {
+		loadValidationCaches(cfg, validationCaches)
+	}
+	consensusConfig.ValidationCaches = validationCaches
 	mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
+	mempoolConfig.ValidationCaches = validationCaches
 	mempoolConfig.MaximumOrphanTransactionCount = cfg.MaxOrphanTxs
+	mempoolConfig.MaximumOrphanTransactionCountPerTag = cfg.MaxOrphanTxsPerPeer
 	mempoolConfig.MinimumRelayTransactionFee = cfg.MinRelayTxFee
+	mempoolConfig.MaximumMemoryUsage = cfg.MaxMempool * 1_000_000
+	if cfg.FreezeList != "" {
+		freezePolicy, err := mempool.LoadFreezePolicy(cfg.FreezeList, cfg.ActiveNetParams.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		mempoolConfig.FreezePolicy = freezePolicy
+	}
 
 	domain, err := domain.New(&consensusConfig, mempoolConfig, db)
 	if err != nil {
 		return nil, err
 	}
 
+	if cfg.Reindex {
+		err = reindex(domain, db)
+		if err != nil {
+			return nil, err
+		}
+	} else if cfg.ReindexState {
+		err = reindexState(domain, db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	netAdapter, err := netadapter.NewNetAdapter(cfg)
 	if err != nil {
 		return nil, err
@@ -103,6 +313,16 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 		return nil, err
 	}
 
+	onionService, err := tor.NewOnionService(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	portMapper, err := newPortMapper(cfg, addressManager)
+	if err != nil {
+		return nil, err
+	}
+
 	var utxoIndex *utxoindex.UTXOIndex
 	if cfg.UTXOIndex {
 		utxoIndex, err = utxoindex.New(domain, db)
@@ -113,25 +333,241 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 		log.Infof("UTXO index started")
 	}
 
-	connectionManager, err := connmanager.New(cfg, netAdapter, addressManager)
+	var txIndex *txindex.TXIndex
+	if cfg.TXIndex {
+		txIndex, err = txindex.New(domain, db)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Infof("Transaction index started")
+	}
+
+	var cfIndex *cfindex.CFIndex
+	if cfg.CFIndex {
+		cfIndex, err = cfindex.New(domain, db)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Infof("Compact filter index started")
+	}
+
+	connectionManager, err := connmanager.New(cfg, netAdapter, addressManager, db)
 	if err != nil {
 		return nil, err
 	}
-	protocolManager, err := protocol.NewManager(cfg, domain, netAdapter, addressManager, connectionManager)
+	protocolManager, err := protocol.NewManager(cfg, domain, netAdapter, addressManager, connectionManager, cfIndex, txIndex)
 	if err != nil {
 		return nil, err
 	}
-	rpcManager := setupRPC(cfg, domain, netAdapter, protocolManager, connectionManager, addressManager, utxoIndex, domain.ConsensusEventsChannel(), interrupt)
+	dbCompactor := compactor.New(db, cfg.DbCompactInterval)
+	dbBackup := newDatabaseBackup(cfg, db)
+	rpcManager := setupRPC(cfg, domain, netAdapter, protocolManager, connectionManager, addressManager, utxoIndex, txIndex,
+		db, dbCompactor, dbBackup, domain.ConsensusEventsChannel(), interrupt)
+
+	var stratumServer *stratum.Server
+	if len(cfg.StratumListeners) > 0 {
+		stratumServer = stratum.NewServer(cfg, domain, protocolManager)
+		protocolManager.SetOnNewBlockTemplateHandler(func() error {
+			err := rpcManager.NotifyNewBlockTemplate()
+			if err != nil {
+				return err
+			}
+			stratumServer.NotifyNewBlockTemplate()
+			return nil
+		})
+	}
+
+	var dnsSeeder *dnsseeder.Server
+	if len(cfg.DNSSeederListeners) > 0 {
+		dnsSeeder, err = dnsseeder.NewServer(cfg, addressManager)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var restServer *httpserver.Server
+	if cfg.EnableREST {
+		if len(cfg.RESTListeners) > 0 {
+			restServeMux := http.NewServeMux()
+			rpcManager.RegisterRESTHandlers(restServeMux)
+			restServer = httpserver.New("REST", cfg.RESTListeners, restServeMux, cfg.ListenerTLS.REST)
+		} else {
+			// The REST interface is served by the profile HTTP server, which uses the default mux
+			rpcManager.RegisterRESTHandlers(http.DefaultServeMux)
+		}
+	}
+
+	var metricsServer *httpserver.Server
+	if len(cfg.MetricsListeners) > 0 {
+		// The default mux holds the pprof handlers, as well as the REST handlers if they
+		// have no listeners of their own
+		metricsServeMux := http.NewServeMux()
+		metricsServeMux.Handle("/metrics", metrics.Handler())
+		metricsServeMux.Handle("/", http.DefaultServeMux)
+		metricsServer = httpserver.New("metrics", cfg.MetricsListeners, metricsServeMux, cfg.ListenerTLS.Metrics)
+	}
+
+	zmqPublisher := zmq.NewPublisher(cfg)
+	if zmqPublisher != nil {
+		protocolManager.SetOnTransactionAddedToMempoolHandler(zmqPublisher.PublishTransactions)
+	}
+
+	var notifiers []*notifycmd.Notifier
+	blockNotifier := notifycmd.New("blocknotify", cfg.BlockNotify, cfg.NotifyMaxProcesses, log)
+	if blockNotifier != nil {
+		notifiers = append(notifiers, blockNotifier)
+	}
+	finalityConflictNotifier := notifycmd.New("finalityconflictnotify", cfg.FinalityConflictNotify,
+		cfg.NotifyMaxProcesses, log)
+	if finalityConflictNotifier != nil {
+		notifiers = append(notifiers, finalityConflictNotifier)
+		rpcManager.SetOnFinalityConflictHandler(func(violatingBlockHash *externalapi.DomainHash) {
+			finalityConflictNotifier.Notify(map[rune]string{'s': violatingBlockHash.String()})
+		})
+	}
+
+	if zmqPublisher != nil || blockNotifier != nil {
+		protocolManager.SetOnBlockAddedHandler(func(block *externalapi.DomainBlock) {
+			if zmqPublisher != nil {
+				zmqPublisher.PublishBlock(block)
+			}
+			// Blocks are added too quickly during IBD to run a command for each of them
+			if blockNotifier != nil && !protocolManager.Context().IsIBDRunning() {
+				blockNotifier.Notify(map[rune]string{
+					's': consensushashing.BlockHash(block).String(),
+					'd': strconv.FormatUint(block.Header.DAAScore(), 10),
+				})
+			}
+		})
+	}
+
+	var sqlMirror *sqlmirror.Mirror
+	if cfg.SQLMirror != "" {
+		sqlMirror, err = sqlmirror.New(cfg.SQLMirror, cfg.NetParams(), domain)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var eventBridge *eventbridge.Bridge
+	if cfg.EventBroker != "" {
+		eventBridge, err = eventbridge.New(cfg.EventBroker, cfg.EventTopicPrefix, cfg.AppDir, domain)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var utxoSnapshots *utxosnapshot.Publisher
+	if cfg.UTXOSnapshot != "" {
+		utxoSnapshots, err = utxosnapshot.New(cfg, domain)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	return &ComponentManager{
+	rpcManager.SetOnVirtualChangeHandler(func(virtualChangeSet *externalapi.VirtualChangeSet) {
+		err := protocolManager.Context().OnVirtualChange(virtualChangeSet)
+		if err != nil {
+			panic(err)
+		}
+		if sqlMirror != nil {
+			sqlMirror.NotifyVirtualChange()
+		}
+		if eventBridge != nil {
+			eventBridge.NotifyVirtualChange()
+		}
+	})
+
+	componentManager := &ComponentManager{
 		cfg:               cfg,
 		protocolManager:   protocolManager,
 		rpcManager:        rpcManager,
+		stratumServer:     stratumServer,
+		dnsSeeder:         dnsSeeder,
+		zmqPublisher:      zmqPublisher,
+		sqlMirror:         sqlMirror,
+		eventBridge:       eventBridge,
+		utxoSnapshots:     utxoSnapshots,
+		restServer:        restServer,
+		metricsServer:     metricsServer,
+		notifiers:         notifiers,
 		connectionManager: connectionManager,
 		netAdapter:        netAdapter,
+		onionService:      onionService,
+		portMapper:        portMapper,
 		addressManager:    addressManager,
-	}, nil
+		dbCompactor:       dbCompactor,
+		dbBackup:          dbBackup,
+		validationCaches:  validationCaches,
+		memoryBudget:      newMemoryBudget(cfg, domain, validationCaches),
+	}
+
+	componentManager.watchdog, err = systemd.NewWatchdog(componentManager.healthCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	return componentManager, nil
+}
+
+// healthCheck reports the sync status of the node to systemd. Building the sync status
+// goes through consensus, so the check hangs, and the watchdog lets systemd restart
+// kaspad, if consensus ever gets stuck.
+func (a *ComponentManager) healthCheck() (string, error) {
+	flowContext := a.protocolManager.Context()
+	syncStatus, err := flowContext.SyncStatus()
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case syncStatus.IsIBDRunning:
+		return fmt.Sprintf("Syncing from %s: %.1f%% of the headers, %.1f%% of the blocks",
+			syncStatus.IBDPeer, syncStatus.HeadersProgress, syncStatus.BlocksProgress), nil
+	case !flowContext.HasPeers():
+		return "Waiting for peers", nil
+	case !syncStatus.IsSynced:
+		return "Not synced", nil
+	default:
+		return fmt.Sprintf("Synced at DAA score %d", syncStatus.BlocksDAAScore), nil
+	}
+}
+
+// reindex rebuilds the DAG state from the stored blocks, and drops all the
+// indexes so that the enabled ones are rebuilt from scratch once they're created
+func reindex(domain domain.Domain, db infrastructuredatabase.Database) error {
+	err := domain.Reindex()
+	if err != nil {
+		return err
+	}
+
+	err = utxoindex.Drop(db)
+	if err != nil {
+		return err
+	}
+	err = txindex.Drop(db)
+	if err != nil {
+		return err
+	}
+	err = cfindex.Drop(db)
+	if err != nil {
+		return err
+	}
+	log.Infof("Dropped all the indexes, the enabled ones will be rebuilt")
+	return nil
+}
+
+// reindexState rebuilds the UTXO state of the DAG, and drops the UTXO index
+// which mirrors the virtual UTXO set so it's resynced once it's created
+func reindexState(domain domain.Domain, db infrastructuredatabase.Database) error {
+	err := domain.ReindexState()
+	if err != nil {
+		return err
+	}
 
+	return utxoindex.Drop(db)
 }
 
 func setupRPC(
@@ -142,6 +578,10 @@ func setupRPC(
 	connectionManager *connmanager.ConnectionManager,
 	addressManager *addressmanager.AddressManager,
 	utxoIndex *utxoindex.UTXOIndex,
+	txIndex *txindex.TXIndex,
+	db infrastructuredatabase.Database,
+	dbCompactor *compactor.Compactor,
+	dbBackup *databaseBackup,
 	consensusEventsChan chan externalapi.ConsensusEvent,
 	shutDownChan chan<- struct{},
 ) *rpc.Manager {
@@ -154,6 +594,10 @@ func setupRPC(
 		connectionManager,
 		addressManager,
 		utxoIndex,
+		txIndex,
+		db,
+		dbCompactor,
+		dbBackup.start,
 		consensusEventsChan,
 		shutDownChan,
 	)
@@ -0,0 +1,245 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/os/diskspace"
+	"github.com/pkg/errors"
+)
+
+const (
+	partialBackupDirSuffix = ".partial"
+	backupBatchSize        = 10_000
+
+	// backupCacheSizeMiB is kept small since the backup is written
+	// while the database of the running node uses its own cache
+	backupCacheSizeMiB = 16
+)
+
+// databaseBackup copies the database while kaspad keeps running. Backups are
+// copied from a snapshot of the database, so they are consistent even though
+// the database is written to while they're made.
+type databaseBackup struct {
+	cfg       *config.Config
+	db        database.Database
+	isRunning uint32
+
+	// stopLock guards isStopped and the registration of backups in
+	// backupsWaitGroup, so that stop doesn't miss a backup that's starting
+	stopLock         sync.Mutex
+	isStopped        bool
+	quit             chan struct{}
+	backupsWaitGroup sync.WaitGroup
+}
+
+func newDatabaseBackup(cfg *config.Config, db database.Database) *databaseBackup {
+	return &databaseBackup{
+		cfg:  cfg,
+		db:   db,
+		quit: make(chan struct{}),
+	}
+}
+
+// start takes a snapshot of the database and starts copying it to backupPath
+// in the background. backupPath must be under the backup directory of kaspad,
+// and may be relative to it. An empty backupPath means a directory under the
+// backup directory named after the current time. It returns the path the
+// backup is written to.
+//
+// The backup is a complete database directory, which can replace the database
+// directory of kaspad while it isn't running.
+func (b *databaseBackup) start(backupPath string) (string, error) {
+	backupPath, err := b.resolveBackupPath(backupPath)
+	if err != nil {
+		return "", err
+	}
+	_, err = os.Stat(backupPath)
+	if err == nil {
+		return "", errors.Errorf("The backup path %s already exists", backupPath)
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	if !atomic.CompareAndSwapUint32(&b.isRunning, 0, 1) {
+		return "", errors.Errorf("A backup of the database is already running")
+	}
+
+	err = b.checkAvailableSpace(filepath.Dir(backupPath))
+	if err != nil {
+		atomic.StoreUint32(&b.isRunning, 0)
+		return "", err
+	}
+
+	b.stopLock.Lock()
+	defer b.stopLock.Unlock()
+	if b.isStopped {
+		atomic.StoreUint32(&b.isRunning, 0)
+		return "", errors.Errorf("Kaspad is shutting down")
+	}
+
+	// The snapshot is taken before returning, so the backup reflects the
+	// database at the time it was requested
+	snapshot, err := b.db.Snapshot()
+	if err != nil {
+		atomic.StoreUint32(&b.isRunning, 0)
+		return "", err
+	}
+
+	b.backupsWaitGroup.Add(1)
+	spawn("databaseBackup.backup", func() {
+		defer b.backupsWaitGroup.Done()
+		defer atomic.StoreUint32(&b.isRunning, 0)
+		defer snapshot.Release()
+
+		err := b.backup(snapshot, backupPath)
+		if errors.Is(err, database.ErrCopyInterrupted) {
+			log.Infof("Stopped backing up the database to %s since kaspad is shutting down", backupPath)
+			return
+		}
+		if err != nil {
+			log.Errorf("Error backing up the database to %s: %+v", backupPath, err)
+		}
+	})
+	return backupPath, nil
+}
+
+// stop interrupts the running backup, if there's one, and waits for it to exit, so that
+// the database may be closed. The partial backup is removed. Backups can't be started
+// once stop was called.
+func (b *databaseBackup) stop() {
+	b.stopLock.Lock()
+	if !b.isStopped {
+		b.isStopped = true
+		close(b.quit)
+	}
+	b.stopLock.Unlock()
+
+	b.backupsWaitGroup.Wait()
+}
+
+// resolveBackupPath returns the absolute path of the given backup path, and
+// returns an error if it isn't under the backup directory of kaspad. The parent
+// directories of the backup path are created, so that symbolic links in them
+// are resolved when checking that they don't lead out of the backup directory.
+func (b *databaseBackup) resolveBackupPath(backupPath string) (string, error) {
+	if backupPath == "" {
+		backupPath = time.Now().UTC().Format("20060102-150405")
+	}
+	if !filepath.IsAbs(backupPath) {
+		backupPath = filepath.Join(b.cfg.BackupDir, backupPath)
+	}
+	backupPath = filepath.Clean(backupPath)
+	if !isStrictlyUnder(b.cfg.BackupDir, backupPath) {
+		return "", errors.Errorf("The backup path %s is not under the backup directory %s",
+			backupPath, b.cfg.BackupDir)
+	}
+
+	err := os.MkdirAll(filepath.Dir(backupPath), 0700)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	resolvedBackupDir, err := filepath.EvalSymlinks(b.cfg.BackupDir)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	resolvedParentDir, err := filepath.EvalSymlinks(filepath.Dir(backupPath))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	resolvedBackupPath := filepath.Join(resolvedParentDir, filepath.Base(backupPath))
+	if !isStrictlyUnder(resolvedBackupDir, resolvedBackupPath) {
+		return "", errors.Errorf("The backup path %s leads out of the backup directory %s",
+			backupPath, b.cfg.BackupDir)
+	}
+	return backupPath, nil
+}
+
+// isStrictlyUnder returns whether path is a descendant of dir. Both must be clean absolute paths.
+func isStrictlyUnder(dir string, path string) bool {
+	relativePath, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return relativePath != "." && relativePath != ".." &&
+		!strings.HasPrefix(relativePath, ".."+string(filepath.Separator))
+}
+
+// checkAvailableSpace returns an error if the filesystem of dir doesn't have enough
+// space for a backup, which takes about as much space as the database does
+func (b *databaseBackup) checkAvailableSpace(dir string) error {
+	availableSpace, isKnown, err := diskspace.Available(dir)
+	if err != nil {
+		return err
+	}
+	if !isKnown {
+		log.Warnf("Couldn't determine the space available for the backup in %s", dir)
+		return nil
+	}
+	requiredSpace, err := b.db.ApproximateSize(nil, nil)
+	if err != nil {
+		return err
+	}
+	if availableSpace < requiredSpace {
+		return errors.Errorf("The backup requires about %d bytes, but only %d bytes are available in %s",
+			requiredSpace, availableSpace, dir)
+	}
+	return nil
+}
+
+// backup copies the snapshot into a partial backup directory, and renames it to
+// backupPath once it's complete, so that backupPath only ever holds a complete backup
+func (b *databaseBackup) backup(snapshot database.Snapshot, backupPath string) error {
+	log.Infof("Backing up the database to %s", backupPath)
+	start := time.Now()
+
+	partialPath := backupPath + partialBackupDirSuffix
+	err := os.RemoveAll(partialPath)
+	if err != nil {
+		return err
+	}
+
+	err = b.copySnapshot(snapshot, partialPath)
+	if err != nil {
+		removeErr := os.RemoveAll(partialPath)
+		if removeErr != nil {
+			log.Errorf("Error removing the partial backup at %s: %s", partialPath, removeErr)
+		}
+		return err
+	}
+
+	err = os.Rename(partialPath, backupPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Infof("Finished backing up the database to %s in %s", backupPath, time.Since(start))
+	return nil
+}
+
+func (b *databaseBackup) copySnapshot(snapshot database.Snapshot, targetPath string) error {
+	target, err := database.Open(b.cfg.DbType, targetPath, backupCacheSizeMiB)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	copiedCount, err := database.CopyInterruptibly(snapshot, target, backupBatchSize, b.quit)
+	if err != nil {
+		return err
+	}
+	log.Infof("Copied %d database entries to the backup", copiedCount)
+
+	err = createDatabaseVersionFile(targetPath, versionFilePath(targetPath))
+	if err != nil {
+		return err
+	}
+	return createDatabaseTypeFile(targetPath, b.cfg.DbType)
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+)
+
+func newDatabaseBackupForTest(t *testing.T) (*databaseBackup, func()) {
+	cfg := config.DefaultConfig()
+	cfg.DbType = ldb.DbType
+	cfg.BackupDir = t.TempDir()
+	db, err := ldb.NewLevelDB(t.TempDir(), 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %+v", err)
+	}
+	return newDatabaseBackup(cfg, db), func() { db.Close() }
+}
+
+func TestResolveBackupPath(t *testing.T) {
+	backup, teardown := newDatabaseBackupForTest(t)
+	defer teardown()
+	backupDir := backup.cfg.BackupDir
+
+	outsideDir := t.TempDir()
+	err := os.Symlink(outsideDir, filepath.Join(backupDir, "link"))
+	if err != nil {
+		t.Fatalf("Symlink: %+v", err)
+	}
+
+	tests := []struct {
+		backupPath   string
+		expectedPath string
+		isValid      bool
+	}{
+		{backupPath: "backup", expectedPath: filepath.Join(backupDir, "backup"), isValid: true},
+		{backupPath: "nested/backup", expectedPath: filepath.Join(backupDir, "nested", "backup"), isValid: true},
+		{backupPath: filepath.Join(backupDir, "absolute"), expectedPath: filepath.Join(backupDir, "absolute"), isValid: true},
+		{backupPath: "../escape"},
+		{backupPath: "nested/../../escape"},
+		{backupPath: backupDir},
+		{backupPath: filepath.Join(outsideDir, "backup")},
+		{backupPath: "link/backup"},
+	}
+	for _, test := range tests {
+		resolvedPath, err := backup.resolveBackupPath(test.backupPath)
+		if !test.isValid {
+			if err == nil {
+				t.Fatalf("expected backup path %s to be rejected, but it was resolved to %s",
+					test.backupPath, resolvedPath)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("resolveBackupPath(%s): %+v", test.backupPath, err)
+		}
+		if resolvedPath != test.expectedPath {
+			t.Fatalf("expected backup path %s to be resolved to %s, but got %s",
+				test.backupPath, test.expectedPath, resolvedPath)
+		}
+	}
+
+	resolvedPath, err := backup.resolveBackupPath("")
+	if err != nil {
+		t.Fatalf("resolveBackupPath: %+v", err)
+	}
+	if filepath.Dir(resolvedPath) != backupDir {
+		t.Fatalf("expected the default backup path to be in %s, but got %s", backupDir, resolvedPath)
+	}
+}
+
+func TestDatabaseBackupStop(t *testing.T) {
+	backup, teardown := newDatabaseBackupForTest(t)
+	defer teardown()
+
+	key := database.MakeBucket([]byte("bucket")).Key([]byte("key"))
+	err := backup.db.Put(key, []byte("value"))
+	if err != nil {
+		t.Fatalf("Put: %+v", err)
+	}
+
+	backupPath, err := backup.start("backup")
+	if err != nil {
+		t.Fatalf("start: %+v", err)
+	}
+
+	// stop waits for the backup, so it's complete once stop returns
+	backup.stop()
+	backupDB, err := database.Open(ldb.DbType, backupPath, 8)
+	if err != nil {
+		t.Fatalf("Open: %+v", err)
+	}
+	defer backupDB.Close()
+	value, err := backupDB.Get(key)
+	if err != nil {
+		t.Fatalf("Get: %+v", err)
+	}
+	if string(value) != "value" {
+		t.Fatalf("expected value %s but got %s", "value", value)
+	}
+
+	_, err = backup.start("another-backup")
+	if err == nil {
+		t.Fatalf("expected starting a backup after stop to fail")
+	}
+}
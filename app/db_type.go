@@ -0,0 +1,154 @@
+package app
+
+import (
+	"os"
+	"path"
+
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+	"github.com/pkg/errors"
+
+	// The backends register themselves as database drivers
+	_ "github.com/kaspanet/kaspad/infrastructure/db/database/badgerdb"
+)
+
+const (
+	dbTypeFileName     = "dbtype"
+	migrationDirSuffix = ".migration"
+	migrationBatchSize = 10_000
+)
+
+// validateDatabaseType returns an error if dbType isn't a registered database backend
+func validateDatabaseType(dbType string) error {
+	for _, supportedType := range database.SupportedDrivers() {
+		if dbType == supportedType {
+			return nil
+		}
+	}
+	return errors.Errorf("The database type %s is invalid -- supported types: %s",
+		dbType, database.SupportedDrivers())
+}
+
+// databaseType returns the backend type of the database at dbPath. New databases
+// use the type selected by --dbtype, and databases that were created before the
+// type was recorded are leveldb databases.
+func databaseType(cfg *config.Config, dbPath string) (string, error) {
+	dbTypeBytes, err := os.ReadFile(dbTypeFilePath(dbPath))
+	if err == nil {
+		return string(dbTypeBytes), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	dbType := cfg.DbType
+	_, err = os.Stat(versionFilePath(dbPath))
+	if err == nil {
+		dbType = ldb.DbType
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	err = createDatabaseTypeFile(dbPath, dbType)
+	if err != nil {
+		return "", err
+	}
+	return dbType, nil
+}
+
+func createDatabaseTypeFile(dbPath string, dbType string) error {
+	err := os.MkdirAll(dbPath, 0700)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(dbTypeFilePath(dbPath), []byte(dbType), 0600)
+}
+
+func dbTypeFilePath(dbPath string) string {
+	return path.Join(dbPath, dbTypeFileName)
+}
+
+// migrateDatabase copies the database at dbPath into a new database of type
+// toType, and replaces the original database with it.
+//
+// The copy is made in a separate directory whose type file is written last,
+// so an interrupted migration is either discarded or completed by
+// finishInterruptedDatabaseMigration on the next start.
+func migrateDatabase(dbPath string, fromType string, toType string) error {
+	log.Infof("Migrating the database from %s to %s. This might take a while", fromType, toType)
+	migrationPath := dbPath + migrationDirSuffix
+	err := os.RemoveAll(migrationPath)
+	if err != nil {
+		return err
+	}
+
+	err = copyDatabase(dbPath, fromType, migrationPath, toType)
+	if err != nil {
+		return err
+	}
+
+	err = createDatabaseVersionFile(migrationPath, versionFilePath(migrationPath))
+	if err != nil {
+		return err
+	}
+	err = createDatabaseTypeFile(migrationPath, toType)
+	if err != nil {
+		return err
+	}
+
+	err = replaceDatabase(dbPath, migrationPath)
+	if err != nil {
+		return err
+	}
+	log.Infof("Finished migrating the database to %s", toType)
+	return nil
+}
+
+func copyDatabase(sourcePath string, sourceType string, targetPath string, targetType string) error {
+	source, err := database.Open(sourceType, sourcePath, databaseCacheSizeMiB)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := database.Open(targetType, targetPath, databaseCacheSizeMiB)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	copiedCount, err := database.Copy(source, target, migrationBatchSize)
+	if err != nil {
+		return err
+	}
+	log.Infof("Copied %d database entries", copiedCount)
+	return nil
+}
+
+// finishInterruptedDatabaseMigration completes a migration that was interrupted
+// after the migrated database was fully written, and discards any other leftovers
+// of an interrupted migration.
+func finishInterruptedDatabaseMigration(dbPath string) error {
+	migrationPath := dbPath + migrationDirSuffix
+	_, err := os.Stat(dbTypeFilePath(migrationPath))
+	if os.IsNotExist(err) {
+		return os.RemoveAll(migrationPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Finishing an interrupted database migration")
+	return replaceDatabase(dbPath, migrationPath)
+}
+
+func replaceDatabase(dbPath string, migrationPath string) error {
+	err := os.RemoveAll(dbPath)
+	if err != nil {
+		return err
+	}
+
+	return errors.WithStack(os.Rename(migrationPath, dbPath))
+}
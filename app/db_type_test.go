@@ -0,0 +1,107 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/badgerdb"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+)
+
+// TestDatabaseMigration migrates a leveldb database to badger and back
+// through openDB, the same way kaspad does on startup
+func TestDatabaseMigration(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.AppDir = t.TempDir()
+	cfg.DbType = ldb.DbType
+
+	bucket := database.MakeBucket([]byte("bucket"))
+	subBucket := bucket.Bucket([]byte("subBucket"))
+	entryCount := migrationBatchSize + 10
+	entryKey := func(i int) *database.Key {
+		if i%2 == 0 {
+			return bucket.Key([]byte(fmt.Sprintf("key%d", i)))
+		}
+		return subBucket.Key([]byte(fmt.Sprintf("key%d", i)))
+	}
+	entryValue := func(i int) []byte {
+		return []byte(fmt.Sprintf("value%d", i))
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		t.Fatalf("openDB: %+v", err)
+	}
+	for i := 0; i < entryCount; i++ {
+		err := db.Put(entryKey(i), entryValue(i))
+		if err != nil {
+			t.Fatalf("Put: %+v", err)
+		}
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("Close: %+v", err)
+	}
+
+	// checkDatabase opens the database, migrating it if cfg.MigrateDbType is
+	// set, and checks that it's a database of expectedType holding all entries
+	checkDatabase := func(expectedType string) {
+		db, err := openDB(cfg)
+		if err != nil {
+			t.Fatalf("openDB: %+v", err)
+		}
+		for i := 0; i < entryCount; i++ {
+			value, err := db.Get(entryKey(i))
+			if err != nil {
+				t.Fatalf("Get: %+v", err)
+			}
+			if !bytes.Equal(value, entryValue(i)) {
+				t.Fatalf("expected value %s but got %s", entryValue(i), value)
+			}
+		}
+		err = db.Close()
+		if err != nil {
+			t.Fatalf("Close: %+v", err)
+		}
+
+		dbTypeBytes, err := os.ReadFile(dbTypeFilePath(databasePath(cfg)))
+		if err != nil {
+			t.Fatalf("ReadFile: %+v", err)
+		}
+		if string(dbTypeBytes) != expectedType {
+			t.Fatalf("expected a %s database but got %s", expectedType, dbTypeBytes)
+		}
+		_, err = os.Stat(databasePath(cfg) + migrationDirSuffix)
+		if !os.IsNotExist(err) {
+			t.Fatalf("expected the migration directory to be removed, but got: %v", err)
+		}
+	}
+
+	// Opening the database with another type requires --migrate-dbtype
+	cfg.DbType = badgerdb.DbType
+	_, err = openDB(cfg)
+	if err == nil {
+		t.Fatalf("expected opening a leveldb database as a badger database to fail")
+	}
+
+	cfg.MigrateDbType = true
+	checkDatabase(badgerdb.DbType)
+
+	// Once migrated, the database is opened without --migrate-dbtype
+	cfg.MigrateDbType = false
+	checkDatabase(badgerdb.DbType)
+
+	cfg.DbType = ldb.DbType
+	cfg.MigrateDbType = true
+	checkDatabase(ldb.DbType)
+
+	cfg.DbType = "unknown"
+	_, err = openDB(cfg)
+	if err == nil {
+		t.Fatalf("expected opening a database of an unknown type to fail")
+	}
+}
@@ -0,0 +1,393 @@
+package eventbridge
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/pkg/errors"
+)
+
+const (
+	// chainBlocksPerBatch is the number of chain blocks whose events are published
+	// before the cursor moves
+	chainBlocksPerBatch = 100
+
+	// cursorFilename is the name of the file, under the app directory, that keeps
+	// the last chain block whose events were acknowledged by the broker
+	cursorFilename = "eventbridge.cursor"
+
+	publishTimeout = time.Minute
+	retryInterval  = 10 * time.Second
+)
+
+// Bridge publishes block-connected, virtual-changed and tx-accepted events to a Kafka
+// or NATS broker.
+//
+// The bridge follows the virtual selected parent chain from a cursor: the last chain
+// block whose events were acknowledged by the broker. The cursor is saved to a file
+// only once the broker acknowledges a batch, so every event is published at least
+// once, even if the node or the broker go down. Consumers should be idempotent, since
+// the events of the batch that was in flight are published again.
+type Bridge struct {
+	brokerConfig *brokerConfig
+	topicPrefix  string
+	cursorPath   string
+	domain       domain.Domain
+
+	broker broker
+	cursor *externalapi.DomainHash
+
+	virtualChanged chan struct{}
+	quit           chan struct{}
+	stopOnce       sync.Once
+	stopped        sync.WaitGroup
+}
+
+// New creates a new Bridge to the broker at the given URL, which keeps its cursor in
+// the given app directory. Use Start() to begin publishing.
+func New(brokerURL string, topicPrefix string, appDir string, domain domain.Domain) (*Bridge, error) {
+	brokerConfig, err := parseBrokerURL(brokerURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Bridge{
+		brokerConfig:   brokerConfig,
+		topicPrefix:    topicPrefix,
+		cursorPath:     filepath.Join(appDir, cursorFilename),
+		domain:         domain,
+		virtualChanged: make(chan struct{}, 1),
+		quit:           make(chan struct{}),
+	}, nil
+}
+
+// Start starts publishing in the background. The bridge connects to the broker,
+// catches up with the selected chain from its cursor, and then follows it as it changes.
+func (b *Bridge) Start() {
+	b.stopped.Add(1)
+	spawn("eventbridge.Bridge.run", b.run)
+}
+
+// Stop stops publishing once the batch in progress, if any, is done
+func (b *Bridge) Stop() {
+	b.stopOnce.Do(func() {
+		close(b.quit)
+		b.stopped.Wait()
+	})
+}
+
+// NotifyVirtualChange notifies the bridge that the virtual selected parent chain may
+// have changed. It never blocks: notifications that arrive while the bridge is busy
+// are coalesced, since the bridge always follows the chain from its cursor.
+func (b *Bridge) NotifyVirtualChange() {
+	select {
+	case b.virtualChanged <- struct{}{}:
+	default:
+	}
+}
+
+func (b *Bridge) run() {
+	defer b.stopped.Done()
+	defer b.disconnect()
+
+	for {
+		err := b.sync()
+		if err != nil {
+			log.Errorf("Error publishing events to %s, retrying in %s: %s",
+				b.brokerConfig.address, retryInterval, err)
+			b.disconnect()
+			select {
+			case <-b.quit:
+				return
+			case <-time.After(retryInterval):
+			}
+			continue
+		}
+
+		select {
+		case <-b.quit:
+			return
+		case <-b.virtualChanged:
+		}
+	}
+}
+
+func (b *Bridge) disconnect() {
+	if b.broker != nil {
+		b.broker.close()
+		b.broker = nil
+	}
+}
+
+// sync publishes the events of the changes of the selected chain since the cursor
+func (b *Bridge) sync() error {
+	if b.broker == nil {
+		broker, err := connectBroker(b.brokerConfig)
+		if err != nil {
+			return err
+		}
+		b.broker = broker
+		log.Infof("Connected to the %s broker at %s", b.brokerConfig.scheme, b.brokerConfig.address)
+	}
+
+	err := b.validateCursor()
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-b.quit:
+			return nil
+		default:
+		}
+
+		chainPath, err := b.domain.Consensus().GetVirtualSelectedParentChainFromBlock(b.cursor)
+		if err != nil {
+			return err
+		}
+		if len(chainPath.Added) == 0 && len(chainPath.Removed) == 0 {
+			return nil
+		}
+
+		added := chainPath.Added
+		isCatchingUp := len(added) > chainBlocksPerBatch
+		if isCatchingUp {
+			added = added[:chainBlocksPerBatch]
+		}
+		blueScore, err := b.publishChainChanges(chainPath.Removed, added)
+		if err != nil {
+			return err
+		}
+		if isCatchingUp {
+			log.Infof("Published the events of the selected chain up to blue score %d, %d chain blocks remaining",
+				blueScore, len(chainPath.Added)-len(added))
+		}
+	}
+}
+
+// validateCursor loads the cursor if it wasn't loaded yet. Without a saved cursor, the
+// bridge starts from the virtual selected parent. A cursor that's no longer known to
+// consensus, such as after the node resynced, restarts the bridge from the pruning point.
+func (b *Bridge) validateCursor() error {
+	consensus := b.domain.Consensus()
+	if b.cursor == nil {
+		cursor, err := b.loadCursor()
+		if err != nil {
+			return err
+		}
+		if cursor == nil {
+			virtualSelectedParent, err := consensus.GetVirtualSelectedParent()
+			if err != nil {
+				return err
+			}
+			log.Infof("Publishing events from the virtual selected parent %s", virtualSelectedParent)
+			b.cursor = virtualSelectedParent
+			return b.saveCursor(virtualSelectedParent)
+		}
+		b.cursor = cursor
+	}
+
+	pruningPoint, err := consensus.PruningPoint()
+	if err != nil {
+		return err
+	}
+	cursorInfo, err := consensus.GetBlockInfo(b.cursor)
+	if err != nil {
+		return err
+	}
+	pruningPointInfo, err := consensus.GetBlockInfo(pruningPoint)
+	if err != nil {
+		return err
+	}
+	if !cursorInfo.HasBody() || cursorInfo.BlueScore < pruningPointInfo.BlueScore {
+		log.Warnf("The last published chain block %s is below the pruning point or unknown, so "+
+			"publishing restarts from the pruning point %s", b.cursor, pruningPoint)
+		b.cursor = pruningPoint
+	}
+	return nil
+}
+
+// loadCursor returns the saved cursor, or nil if there's none
+func (b *Bridge) loadCursor() (*externalapi.DomainHash, error) {
+	content, err := os.ReadFile(b.cursorPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	cursor, err := externalapi.NewDomainHashFromString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed event bridge cursor in %s", b.cursorPath)
+	}
+	return cursor, nil
+}
+
+// saveCursor replaces the saved cursor, so that a crash leaves either the old or the new one
+func (b *Bridge) saveCursor(cursor *externalapi.DomainHash) error {
+	temporaryPath := b.cursorPath + ".new"
+	file, err := os.OpenFile(temporaryPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(cursor.String() + "\n")
+	if err != nil {
+		file.Close()
+		return err
+	}
+	err = file.Sync()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, b.cursorPath)
+}
+
+// publishChainChanges publishes the events of the given changes of the selected chain,
+// and moves the cursor to the last added chain block once the broker acknowledged them.
+// It returns the blue score of the new cursor.
+func (b *Bridge) publishChainChanges(removed []*externalapi.DomainHash, added []*externalapi.DomainHash) (uint64, error) {
+	consensus := b.domain.Consensus()
+	var messages []*message
+
+	for _, chainBlockHash := range added {
+		chainBlockInfo, err := consensus.GetBlockInfo(chainBlockHash)
+		if err != nil {
+			return 0, err
+		}
+		acceptanceData, err := consensus.GetBlockAcceptanceData(chainBlockHash)
+		if err != nil {
+			return 0, err
+		}
+
+		// The chain block is connected along with its merge set, whose transactions it accepts.
+		// Its selected parent is in the merge set too, but it was connected as a chain block.
+		blockMessage, err := b.blockConnectedMessage(chainBlockHash, chainBlockHash)
+		if err != nil {
+			return 0, err
+		}
+		messages = append(messages, blockMessage)
+		for _, blockAcceptanceData := range acceptanceData {
+			isSelectedParent := chainBlockInfo.SelectedParent != nil &&
+				blockAcceptanceData.BlockHash.Equal(chainBlockInfo.SelectedParent)
+			if !isSelectedParent {
+				blockMessage, err := b.blockConnectedMessage(blockAcceptanceData.BlockHash, chainBlockHash)
+				if err != nil {
+					return 0, err
+				}
+				messages = append(messages, blockMessage)
+			}
+			for _, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+				if !transactionAcceptanceData.IsAccepted {
+					continue
+				}
+				transactionID := consensushashing.TransactionID(transactionAcceptanceData.Transaction).String()
+				txMessage, err := b.newMessage(topicTxAccepted, transactionID, &txAcceptedEvent{
+					TransactionID:      transactionID,
+					BlockHash:          blockAcceptanceData.BlockHash.String(),
+					AcceptingBlockHash: chainBlockHash.String(),
+					AcceptingBlueScore: chainBlockInfo.BlueScore,
+					Fee:                transactionAcceptanceData.Fee,
+				})
+				if err != nil {
+					return 0, err
+				}
+				messages = append(messages, txMessage)
+			}
+		}
+	}
+
+	// Once the chain moves back to a common ancestor without moving forward, it becomes the cursor
+	newCursor := b.cursor
+	if len(added) > 0 {
+		newCursor = added[len(added)-1]
+	} else if len(removed) > 0 {
+		lastRemovedInfo, err := consensus.GetBlockInfo(removed[len(removed)-1])
+		if err != nil {
+			return 0, err
+		}
+		newCursor = lastRemovedInfo.SelectedParent
+	}
+	newCursorInfo, err := consensus.GetBlockInfo(newCursor)
+	if err != nil {
+		return 0, err
+	}
+
+	virtualChangedMessage, err := b.newMessage(topicVirtualChanged, newCursor.String(), &virtualChangedEvent{
+		RemovedChainBlockHashes: hashStrings(removed),
+		AddedChainBlockHashes:   hashStrings(added),
+		SelectedTipHash:         newCursor.String(),
+		SelectedTipBlueScore:    newCursorInfo.BlueScore,
+	})
+	if err != nil {
+		return 0, err
+	}
+	messages = append(messages, virtualChangedMessage)
+
+	err = b.broker.publish(messages, publishTimeout)
+	if err != nil {
+		return 0, err
+	}
+	err = b.saveCursor(newCursor)
+	if err != nil {
+		return 0, err
+	}
+	b.cursor = newCursor
+	return newCursorInfo.BlueScore, nil
+}
+
+func (b *Bridge) blockConnectedMessage(blockHash *externalapi.DomainHash,
+	mergingBlockHash *externalapi.DomainHash) (*message, error) {
+
+	block, found, err := b.domain.Consensus().GetBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.Errorf("block %s not found", blockHash)
+	}
+	transactionIDs := make([]string, len(block.Transactions))
+	for i, transaction := range block.Transactions {
+		transactionIDs[i] = consensushashing.TransactionID(transaction).String()
+	}
+	return b.newMessage(topicBlockConnected, blockHash.String(), &blockConnectedEvent{
+		Hash:             blockHash.String(),
+		MergingBlockHash: mergingBlockHash.String(),
+		Timestamp:        block.Header.TimeInMilliseconds(),
+		DAAScore:         block.Header.DAAScore(),
+		BlueScore:        block.Header.BlueScore(),
+		ParentHashes:     hashStrings(block.Header.DirectParents()),
+		TransactionIDs:   transactionIDs,
+	})
+}
+
+func (b *Bridge) newMessage(topic string, key string, event interface{}) (*message, error) {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &message{
+		topic: b.topicPrefix + "." + topic,
+		key:   []byte(key),
+		value: value,
+	}, nil
+}
+
+func hashStrings(hashes []*externalapi.DomainHash) []string {
+	strs := make([]string, len(hashes))
+	for i, hash := range hashes {
+		strs[i] = hash.String()
+	}
+	return strs
+}
@@ -0,0 +1,195 @@
+package eventbridge
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/kaspanet/kaspad/domain/domaintest"
+	"github.com/pkg/errors"
+)
+
+// fakeBroker records the messages it acknowledges, or fails if isDown is set
+type fakeBroker struct {
+	isDown   bool
+	messages []*message
+}
+
+func (fb *fakeBroker) publish(messages []*message, _ time.Duration) error {
+	if fb.isDown {
+		return errors.New("the broker is down")
+	}
+	fb.messages = append(fb.messages, messages...)
+	return nil
+}
+
+func (fb *fakeBroker) close() {}
+
+// takeEvents returns the events published to the given topic since the last call, decoded into
+// new values of the type of event
+func takeEvents[T any](fb *fakeBroker, topic string) []*T {
+	var events []*T
+	remaining := fb.messages[:0]
+	for _, message := range fb.messages {
+		if message.topic != "kaspa."+topic {
+			remaining = append(remaining, message)
+			continue
+		}
+		event := new(T)
+		err := json.Unmarshal(message.value, event)
+		if err != nil {
+			panic(err)
+		}
+		events = append(events, event)
+	}
+	fb.messages = remaining
+	return events
+}
+
+func TestBridge(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestBridge")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		appDir := t.TempDir()
+		newBridge := func(broker *fakeBroker) *Bridge {
+			bridge, err := New("nats://localhost", "kaspa", appDir, domaintest.NewConsensusDomain(tc))
+			if err != nil {
+				t.Fatalf("New: %+v", err)
+			}
+			bridge.broker = broker
+			return bridge
+		}
+		addBlock := func(parentHash *externalapi.DomainHash) *externalapi.DomainHash {
+			blockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{parentHash}, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			return blockHash
+		}
+		savedCursor := func() string {
+			content, err := os.ReadFile(filepath.Join(appDir, cursorFilename))
+			if err != nil {
+				t.Fatalf("ReadFile: %s", err)
+			}
+			return string(content)
+		}
+
+		// Without a cursor, the bridge starts from the virtual selected parent
+		broker := &fakeBroker{}
+		bridge := newBridge(broker)
+		genesisHash := consensusConfig.GenesisHash
+		err = bridge.sync()
+		if err != nil {
+			t.Fatalf("sync: %+v", err)
+		}
+		if len(broker.messages) != 0 || savedCursor() != genesisHash.String()+"\n" {
+			t.Fatalf("Expected the bridge to start from the genesis without publishing")
+		}
+
+		a1 := addBlock(genesisHash)
+		a2 := addBlock(a1)
+		err = bridge.sync()
+		if err != nil {
+			t.Fatalf("sync: %+v", err)
+		}
+		blockEvents := takeEvents[blockConnectedEvent](broker, topicBlockConnected)
+		if len(blockEvents) != 2 || blockEvents[0].Hash != a1.String() || blockEvents[1].Hash != a2.String() ||
+			blockEvents[1].MergingBlockHash != a2.String() {
+			t.Fatalf("Unexpected block-connected events %+v", blockEvents)
+		}
+		// The coinbase transaction of a1 is accepted by a2
+		a1Block, _, err := tc.GetBlock(a1)
+		if err != nil {
+			t.Fatalf("GetBlock: %+v", err)
+		}
+		a1CoinbaseID := consensushashing.TransactionID(a1Block.Transactions[0]).String()
+		var a1CoinbaseEvent *txAcceptedEvent
+		for _, txEvent := range takeEvents[txAcceptedEvent](broker, topicTxAccepted) {
+			if txEvent.TransactionID == a1CoinbaseID {
+				a1CoinbaseEvent = txEvent
+			}
+		}
+		if a1CoinbaseEvent == nil || a1CoinbaseEvent.BlockHash != a1.String() ||
+			a1CoinbaseEvent.AcceptingBlockHash != a2.String() {
+			t.Fatalf("Unexpected tx-accepted event for the coinbase of a1: %+v", a1CoinbaseEvent)
+		}
+		virtualEvents := takeEvents[virtualChangedEvent](broker, topicVirtualChanged)
+		if len(virtualEvents) != 1 || virtualEvents[0].SelectedTipHash != a2.String() ||
+			len(virtualEvents[0].AddedChainBlockHashes) != 2 || len(virtualEvents[0].RemovedChainBlockHashes) != 0 {
+			t.Fatalf("Unexpected virtual-changed events %+v", virtualEvents)
+		}
+		if savedCursor() != a2.String()+"\n" {
+			t.Fatalf("Expected the cursor to move to %s", a2)
+		}
+
+		// The cursor doesn't move while the broker is down, so nothing is lost
+		a3 := addBlock(a2)
+		broker.isDown = true
+		err = bridge.sync()
+		if err == nil {
+			t.Fatalf("Expected sync to fail while the broker is down")
+		}
+		if !bridge.cursor.Equal(a2) || savedCursor() != a2.String()+"\n" {
+			t.Fatalf("Expected the cursor to remain %s", a2)
+		}
+
+		// A restarted bridge resumes from the saved cursor
+		broker = &fakeBroker{}
+		bridge = newBridge(broker)
+		err = bridge.sync()
+		if err != nil {
+			t.Fatalf("sync: %+v", err)
+		}
+		blockEvents = takeEvents[blockConnectedEvent](broker, topicBlockConnected)
+		if len(blockEvents) != 1 || blockEvents[0].Hash != a3.String() {
+			t.Fatalf("Expected only a3 to be published after the restart but got %+v", blockEvents)
+		}
+		broker.messages = nil
+
+		// A longer chain from the genesis reorgs the a blocks out of the selected chain
+		b1 := addBlock(genesisHash)
+		b2 := addBlock(b1)
+		b3 := addBlock(b2)
+		b4 := addBlock(b3)
+		err = bridge.sync()
+		if err != nil {
+			t.Fatalf("sync: %+v", err)
+		}
+		virtualEvents = takeEvents[virtualChangedEvent](broker, topicVirtualChanged)
+		if len(virtualEvents) != 1 {
+			t.Fatalf("Expected a single virtual-changed event but got %+v", virtualEvents)
+		}
+		expectedRemoved := []string{a3.String(), a2.String(), a1.String()}
+		expectedAdded := []string{b1.String(), b2.String(), b3.String(), b4.String()}
+		if !equalStrings(virtualEvents[0].RemovedChainBlockHashes, expectedRemoved) ||
+			!equalStrings(virtualEvents[0].AddedChainBlockHashes, expectedAdded) {
+			t.Fatalf("Unexpected virtual-changed event %+v", virtualEvents[0])
+		}
+		if savedCursor() != b4.String()+"\n" {
+			t.Fatalf("Expected the cursor to move to %s", b4)
+		}
+	})
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
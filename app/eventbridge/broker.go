@@ -0,0 +1,84 @@
+package eventbridge
+
+import (
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const dialTimeout = 30 * time.Second
+
+// broker publishes messages to a message broker
+type broker interface {
+	// publish publishes the given messages, and returns only once the broker
+	// acknowledged all of them. After an error, any of the messages may or may not
+	// have been published.
+	publish(messages []*message, timeout time.Duration) error
+	close()
+}
+
+// brokerConfig is the parsed form of the broker URL
+type brokerConfig struct {
+	scheme   string
+	address  string
+	host     string
+	user     string
+	password string
+}
+
+var defaultBrokerPorts = map[string]string{
+	"kafka": "9092",
+	"nats":  "4222",
+}
+
+// parseBrokerURL parses a URL of the form kafka://host:port or nats://[user:password@]host:port.
+// The errors don't include the URL, since it may include a password.
+func parseBrokerURL(brokerURL string) (*brokerConfig, error) {
+	parsedURL, err := url.Parse(brokerURL)
+	if err != nil {
+		return nil, errors.New("the event broker URL is malformed")
+	}
+	defaultPort, ok := defaultBrokerPorts[parsedURL.Scheme]
+	if !ok {
+		return nil, errors.Errorf("unsupported event broker scheme %q, expected kafka or nats", parsedURL.Scheme)
+	}
+	host := parsedURL.Hostname()
+	if host == "" {
+		return nil, errors.New("the event broker URL has no host")
+	}
+	port := parsedURL.Port()
+	if port == "" {
+		port = defaultPort
+	}
+
+	config := &brokerConfig{
+		scheme:  parsedURL.Scheme,
+		address: net.JoinHostPort(host, port),
+		host:    host,
+	}
+	if parsedURL.User != nil {
+		if config.scheme == "kafka" {
+			return nil, errors.New("Kafka authentication isn't supported")
+		}
+		config.user = parsedURL.User.Username()
+		config.password, _ = parsedURL.User.Password()
+	}
+	return config, nil
+}
+
+func connectBroker(config *brokerConfig) (broker, error) {
+	if config.scheme == "kafka" {
+		kafka, err := connectKafka(config)
+		if err != nil {
+			return nil, err
+		}
+		return kafka, nil
+	}
+	nats, err := connectNATS(config)
+	if err != nil {
+		return nil, err
+	}
+	return nats, nil
+}
@@ -0,0 +1,47 @@
+package eventbridge
+
+// The suffixes of the topics the events are published to. The topic of every event is
+// the topic prefix, followed by a dot and one of these.
+const (
+	topicBlockConnected = "block-connected"
+	topicVirtualChanged = "virtual-changed"
+	topicTxAccepted     = "tx-accepted"
+)
+
+// blockConnectedEvent is published for every block merged by a chain block, and for
+// the chain block itself. Its key is the block hash.
+type blockConnectedEvent struct {
+	Hash             string   `json:"hash"`
+	MergingBlockHash string   `json:"mergingBlockHash"`
+	Timestamp        int64    `json:"timestamp"`
+	DAAScore         uint64   `json:"daaScore"`
+	BlueScore        uint64   `json:"blueScore"`
+	ParentHashes     []string `json:"parentHashes"`
+	TransactionIDs   []string `json:"transactionIds"`
+}
+
+// virtualChangedEvent is published for every batch of changes to the selected chain,
+// after the events of the blocks the batch added. Its key is the new selected tip hash.
+type virtualChangedEvent struct {
+	RemovedChainBlockHashes []string `json:"removedChainBlockHashes"`
+	AddedChainBlockHashes   []string `json:"addedChainBlockHashes"`
+	SelectedTipHash         string   `json:"selectedTipHash"`
+	SelectedTipBlueScore    uint64   `json:"selectedTipBlueScore"`
+}
+
+// txAcceptedEvent is published for every transaction accepted by a chain block. Its
+// key is the transaction ID.
+type txAcceptedEvent struct {
+	TransactionID      string `json:"transactionId"`
+	BlockHash          string `json:"blockHash"`
+	AcceptingBlockHash string `json:"acceptingBlockHash"`
+	AcceptingBlueScore uint64 `json:"acceptingBlueScore"`
+	Fee                uint64 `json:"fee"`
+}
+
+// message is an event on its way to the broker
+type message struct {
+	topic string
+	key   []byte
+	value []byte
+}
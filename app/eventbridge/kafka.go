@@ -0,0 +1,66 @@
+package eventbridge
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const kafkaClientID = "kaspad"
+
+// kafkaBroker publishes messages to Kafka. Every message is produced with acks=all, so
+// it's acknowledged once all the in-sync replicas have it.
+//
+// The client retries the messages that a broker failed to store, and follows the leaders
+// of the partitions as they move. The partition of a message is chosen by the hash of its
+// key, as in the Java client, so all the events of the same block or transaction go to the
+// same partition, in order.
+type kafkaBroker struct {
+	client *kgo.Client
+}
+
+func connectKafka(config *brokerConfig) (*kafkaBroker, error) {
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(config.address),
+		kgo.ClientID(kafkaClientID),
+		kgo.DialTimeout(dialTimeout),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.AllowAutoTopicCreation(),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the Kafka client")
+	}
+
+	// The client connects lazily, so the broker is pinged for a failure to connect to
+	// surface here rather than on the first publish
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	err = client.Ping(ctx)
+	if err != nil {
+		client.Close()
+		return nil, errors.Wrap(err, "failed to connect to Kafka")
+	}
+	return &kafkaBroker{client: client}, nil
+}
+
+func (k *kafkaBroker) close() {
+	k.client.Close()
+}
+
+func (k *kafkaBroker) publish(messages []*message, timeout time.Duration) error {
+	records := make([]*kgo.Record, len(messages))
+	for i, message := range messages {
+		records[i] = &kgo.Record{Topic: message.topic, Key: message.key, Value: message.value}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, result := range k.client.ProduceSync(ctx, records...) {
+		if result.Err != nil {
+			return errors.Wrapf(result.Err, "Kafka failed to store an event published to %s", result.Record.Topic)
+		}
+	}
+	return nil
+}
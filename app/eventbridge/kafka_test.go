@@ -0,0 +1,212 @@
+package eventbridge
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// consumeKafkaRecords reads the first count records of the given topic, and returns their
+// keys and values by partition, as key=value
+func consumeKafkaRecords(t *testing.T, seedBrokers []string, topic string, count int) map[int32][]string {
+	consumer, err := kgo.NewClient(
+		kgo.SeedBrokers(seedBrokers...),
+		kgo.ConsumeTopics(topic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %s", err)
+	}
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	records := make(map[int32][]string)
+	receivedCount := 0
+	for receivedCount < count {
+		fetches := consumer.PollFetches(ctx)
+		if ctx.Err() != nil {
+			t.Fatalf("Received only %d records of %s out of %d", receivedCount, topic, count)
+		}
+		fetches.EachError(func(topic string, partition int32, err error) {
+			t.Fatalf("Fetching partition %d of %s: %s", partition, topic, err)
+		})
+		fetches.EachRecord(func(record *kgo.Record) {
+			records[record.Partition] = append(records[record.Partition], string(record.Key)+"="+string(record.Value))
+			receivedCount++
+		})
+	}
+	return records
+}
+
+// controlProduceRequests checks that every Produce request asks for acks=all, and answers
+// the next failedRequests of them with NOT_ENOUGH_REPLICAS, or all of them if it's negative
+func controlProduceRequests(t *testing.T, cluster *kfake.Cluster, failedRequests *atomic.Int32) {
+	const notEnoughReplicas = 19
+
+	cluster.ControlKey(int16(kmsg.Produce), func(request kmsg.Request) (kmsg.Response, error, bool) {
+		cluster.KeepControl()
+		produceRequest := request.(*kmsg.ProduceRequest)
+		if produceRequest.Acks != -1 {
+			t.Errorf("Expected acks=all but got %d", produceRequest.Acks)
+		}
+		if failedRequests.Load() == 0 {
+			return nil, nil, false
+		}
+		if failedRequests.Load() > 0 {
+			failedRequests.Add(-1)
+		}
+
+		response := produceRequest.ResponseKind().(*kmsg.ProduceResponse)
+		for _, requestTopic := range produceRequest.Topics {
+			topic := kmsg.NewProduceResponseTopic()
+			topic.Topic = requestTopic.Topic
+			for _, requestPartition := range requestTopic.Partitions {
+				partition := kmsg.NewProduceResponseTopicPartition()
+				partition.Partition = requestPartition.Partition
+				partition.ErrorCode = notEnoughReplicas
+				topic.Partitions = append(topic.Partitions, partition)
+			}
+			response.Topics = append(response.Topics, topic)
+		}
+		return response, nil, true
+	})
+}
+
+func TestKafkaBroker(t *testing.T) {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(3),
+		kfake.SeedTopics(2, "kaspa.tx-accepted", "kaspa.virtual-changed"))
+	if err != nil {
+		t.Fatalf("NewCluster: %s", err)
+	}
+	defer cluster.Close()
+
+	var failedProduceRequests atomic.Int32
+	controlProduceRequests(t, cluster, &failedProduceRequests)
+
+	config, err := parseBrokerURL("kafka://" + cluster.ListenAddrs()[0])
+	if err != nil {
+		t.Fatalf("parseBrokerURL: %s", err)
+	}
+	kafka, err := connectKafka(config)
+	if err != nil {
+		t.Fatalf("connectKafka: %s", err)
+	}
+	defer kafka.close()
+
+	var messages []*message
+	for _, key := range []string{"a", "b", "c", "a"} {
+		messages = append(messages, &message{topic: "kaspa.tx-accepted", key: []byte(key),
+			value: []byte(strconv.Itoa(len(messages)))})
+	}
+	messages = append(messages, &message{topic: "kaspa.virtual-changed", key: []byte("a"), value: []byte("v")})
+
+	// A produce error that the brokers recover from is retried
+	failedProduceRequests.Store(1)
+	err = kafka.publish(messages, time.Minute)
+	if err != nil {
+		t.Fatalf("publish: %s", err)
+	}
+
+	// The messages of every key are in the same partition, in order
+	txRecordsByPartition := consumeKafkaRecords(t, cluster.ListenAddrs(), "kaspa.tx-accepted", 4)
+	txRecordCount := 0
+	for _, records := range txRecordsByPartition {
+		txRecordCount += len(records)
+		var keyARecords []string
+		for _, record := range records {
+			if record[0] == 'a' {
+				keyARecords = append(keyARecords, record)
+			}
+		}
+		if len(keyARecords) != 0 && (len(keyARecords) != 2 || keyARecords[0] != "a=0" || keyARecords[1] != "a=3") {
+			t.Fatalf("Expected the records of key a to be in the same partition, in order, but got %q", records)
+		}
+	}
+	if txRecordCount != 4 {
+		t.Fatalf("Expected 4 tx-accepted records but got %v", txRecordsByPartition)
+	}
+	virtualRecordsByPartition := consumeKafkaRecords(t, cluster.ListenAddrs(), "kaspa.virtual-changed", 1)
+	var virtualRecords []string
+	for _, records := range virtualRecordsByPartition {
+		virtualRecords = append(virtualRecords, records...)
+	}
+	if len(virtualRecords) != 1 || virtualRecords[0] != "a=v" {
+		t.Fatalf("Unexpected virtual-changed records %q", virtualRecords)
+	}
+
+	// The messages follow the leaders of the partitions to their new brokers
+	for partition := int32(0); partition < 2; partition++ {
+		leader := cluster.LeaderFor("kaspa.tx-accepted", partition)
+		err = cluster.MoveTopicPartition("kaspa.tx-accepted", partition, (leader+1)%3)
+		if err != nil {
+			t.Fatalf("MoveTopicPartition: %s", err)
+		}
+	}
+	err = kafka.publish(messages, time.Minute)
+	if err != nil {
+		t.Fatalf("publish after the leaders moved: %s", err)
+	}
+	txRecordsByPartition = consumeKafkaRecords(t, cluster.ListenAddrs(), "kaspa.tx-accepted", 8)
+	txRecordCount = 0
+	for _, records := range txRecordsByPartition {
+		txRecordCount += len(records)
+	}
+	if txRecordCount != 8 {
+		t.Fatalf("Expected 8 tx-accepted records but got %v", txRecordsByPartition)
+	}
+
+	// A produce error that persists fails the publish once it times out
+	failedProduceRequests.Store(-1)
+	err = kafka.publish(messages, 3*time.Second)
+	if err == nil {
+		t.Fatalf("Expected a produce error to fail the publish")
+	}
+}
+
+// TestKafkaBrokerIntegration publishes to the Kafka broker at KASPAD_TEST_KAFKA_URL, such as
+// kafka://localhost:9092, which must allow the automatic creation of topics
+func TestKafkaBrokerIntegration(t *testing.T) {
+	brokerURL := os.Getenv("KASPAD_TEST_KAFKA_URL")
+	if brokerURL == "" {
+		t.Skip("KASPAD_TEST_KAFKA_URL isn't set")
+	}
+	config, err := parseBrokerURL(brokerURL)
+	if err != nil {
+		t.Fatalf("parseBrokerURL: %s", err)
+	}
+	kafka, err := connectKafka(config)
+	if err != nil {
+		t.Fatalf("connectKafka: %s", err)
+	}
+	defer kafka.close()
+
+	topic := fmt.Sprintf("kaspad-test-%d", time.Now().UnixNano())
+	var messages []*message
+	for i := 0; i < 100; i++ {
+		messages = append(messages, &message{topic: topic, key: []byte(strconv.Itoa(i % 7)),
+			value: []byte(strconv.Itoa(i))})
+	}
+	err = kafka.publish(messages, time.Minute)
+	if err != nil {
+		t.Fatalf("publish: %s", err)
+	}
+
+	// The broker stored every record, which it validates before it acknowledges them
+	recordsByPartition := consumeKafkaRecords(t, []string{config.address}, topic, len(messages))
+	storedCount := 0
+	for _, records := range recordsByPartition {
+		storedCount += len(records)
+	}
+	if storedCount != len(messages) {
+		t.Fatalf("Expected Kafka to store %d records but it stored %d", len(messages), storedCount)
+	}
+}
@@ -0,0 +1,9 @@
+package eventbridge
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/panics"
+)
+
+var log = logger.RegisterSubSystem("EVBR")
+var spawn = panics.GoroutineWrapperFunc(log)
@@ -0,0 +1,81 @@
+package eventbridge
+
+import (
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pkg/errors"
+)
+
+// natsBroker publishes messages to NATS JetStream.
+//
+// Every message is published asynchronously, and is published once JetStream acknowledges
+// that it's stored. Plain NATS has no acknowledgements, so a subject that no stream
+// captures is an error rather than a silent loss of events.
+type natsBroker struct {
+	conn      *nats.Conn
+	jetStream jetstream.JetStream
+}
+
+func connectNATS(config *brokerConfig) (*natsBroker, error) {
+	options := []nats.Option{
+		nats.Name("kaspad"),
+		nats.Timeout(dialTimeout),
+		// The bridge redelivers the events from its own queue after a failed publish, so
+		// the connection is dialed anew instead of being reconnected in the background
+		nats.NoReconnect(),
+	}
+	// Like in other NATS clients, a user without a password is a token
+	if config.password != "" {
+		options = append(options, nats.UserInfo(config.user, config.password))
+	} else if config.user != "" {
+		options = append(options, nats.Token(config.user))
+	}
+	conn, err := nats.Connect("nats://"+config.address, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to NATS")
+	}
+	if !conn.HeadersSupported() {
+		conn.Close()
+		return nil, errors.New("the NATS server doesn't support headers, which JetStream acknowledgements require")
+	}
+	jetStream, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, errors.WithStack(err)
+	}
+	return &natsBroker{conn: conn, jetStream: jetStream}, nil
+}
+
+func (n *natsBroker) close() {
+	n.conn.Close()
+}
+
+func (n *natsBroker) publish(messages []*message, timeout time.Duration) error {
+	acks := make([]jetstream.PubAckFuture, len(messages))
+	for i, message := range messages {
+		ack, err := n.jetStream.PublishAsync(message.topic, message.value)
+		if err != nil {
+			return errors.Wrapf(err, "failed to publish an event to %s", message.topic)
+		}
+		acks[i] = ack
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for i, ack := range acks {
+		topic := messages[i].topic
+		select {
+		case <-ack.Ok():
+		case err := <-ack.Err():
+			if errors.Is(err, jetstream.ErrNoStreamResponse) {
+				return errors.Errorf("no JetStream stream captures the subject %s", topic)
+			}
+			return errors.Wrapf(err, "JetStream rejected an event published to %s", topic)
+		case <-timer.C:
+			return errors.Errorf("timed out waiting for JetStream to acknowledge an event published to %s", topic)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package eventbridge
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// fakeNATSServer accepts connections with the given token, and acknowledges the messages
+// published to subjects under streamPrefix like JetStream. Messages published to other
+// subjects get a no responders status.
+type fakeNATSServer struct {
+	t            *testing.T
+	listener     net.Listener
+	token        string
+	streamPrefix string
+
+	payloadsLock sync.Mutex
+	payloads     map[string][]string
+}
+
+func newFakeNATSServer(t *testing.T, token string, streamPrefix string) *fakeNATSServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %s", err)
+	}
+	server := &fakeNATSServer{
+		t:            t,
+		listener:     listener,
+		token:        token,
+		streamPrefix: streamPrefix,
+		payloads:     make(map[string][]string),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.handleConnection(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeNATSServer) close() {
+	s.listener.Close()
+}
+
+func (s *fakeNATSServer) receivedPayloads(subject string) []string {
+	s.payloadsLock.Lock()
+	defer s.payloadsLock.Unlock()
+	return append([]string{}, s.payloads[subject]...)
+}
+
+func (s *fakeNATSServer) handleConnection(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	fmt.Fprintf(conn, "INFO {\"server_id\":\"fake\",\"headers\":true,\"max_payload\":1048576,\"auth_required\":true}\r\n")
+
+	sequence := 0
+	subscriptionID := ""
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "CONNECT":
+			if !strings.Contains(line, `"auth_token":"`+s.token+`"`) {
+				fmt.Fprintf(conn, "-ERR 'Authorization Violation'\r\n")
+				return
+			}
+		case "PING":
+			fmt.Fprintf(conn, "PONG\r\n")
+		case "SUB":
+			subscriptionID = fields[2]
+		case "PUB":
+			size, _ := strconv.Atoi(fields[3])
+			payload := make([]byte, size+2)
+			_, err := io.ReadFull(reader, payload)
+			if err != nil {
+				return
+			}
+			subject, replySubject := fields[1], fields[2]
+			if !strings.HasPrefix(subject, s.streamPrefix) {
+				status := "NATS/1.0 503\r\n\r\n"
+				fmt.Fprintf(conn, "HMSG %s %s %d %d\r\n%s\r\n", replySubject, subscriptionID,
+					len(status), len(status), status)
+				continue
+			}
+			s.payloadsLock.Lock()
+			s.payloads[subject] = append(s.payloads[subject], string(payload[:size]))
+			s.payloadsLock.Unlock()
+			sequence++
+			ack := fmt.Sprintf(`{"stream":"KASPA","seq":%d}`, sequence)
+			fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", replySubject, subscriptionID, len(ack), ack)
+		}
+	}
+}
+
+func TestNATSBroker(t *testing.T) {
+	server := newFakeNATSServer(t, "secret", "kaspa.")
+	defer server.close()
+
+	config, err := parseBrokerURL("nats://secret@" + server.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("parseBrokerURL: %s", err)
+	}
+	nats, err := connectNATS(config)
+	if err != nil {
+		t.Fatalf("connectNATS: %s", err)
+	}
+	defer nats.close()
+
+	messages := []*message{
+		{topic: "kaspa.block-connected", key: []byte("a"), value: []byte(`{"hash":"a"}`)},
+		{topic: "kaspa.block-connected", key: []byte("b"), value: []byte(`{"hash":"b"}`)},
+		{topic: "kaspa.virtual-changed", key: []byte("b"), value: []byte(`{}`)},
+	}
+	err = nats.publish(messages, time.Minute)
+	if err != nil {
+		t.Fatalf("publish: %s", err)
+	}
+	payloads := server.receivedPayloads("kaspa.block-connected")
+	if len(payloads) != 2 || payloads[0] != `{"hash":"a"}` || payloads[1] != `{"hash":"b"}` {
+		t.Fatalf("Unexpected payloads %q", payloads)
+	}
+
+	// A subject that no stream captures isn't acknowledged
+	err = nats.publish([]*message{{topic: "other.block-connected", value: []byte(`{}`)}}, time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "no JetStream stream") {
+		t.Fatalf("Expected a missing stream to fail the publish but got: %v", err)
+	}
+
+	config.user = "wrong"
+	_, err = connectNATS(config)
+	if err == nil {
+		t.Fatalf("Expected a wrong token to be rejected")
+	}
+}
+
+// TestNATSBrokerIntegration publishes to the NATS server at KASPAD_TEST_NATS_URL, such as
+// nats://localhost:4222, which must have JetStream enabled
+func TestNATSBrokerIntegration(t *testing.T) {
+	brokerURL := os.Getenv("KASPAD_TEST_NATS_URL")
+	if brokerURL == "" {
+		t.Skip("KASPAD_TEST_NATS_URL isn't set")
+	}
+	config, err := parseBrokerURL(brokerURL)
+	if err != nil {
+		t.Fatalf("parseBrokerURL: %s", err)
+	}
+	nats, err := connectNATS(config)
+	if err != nil {
+		t.Fatalf("connectNATS: %s", err)
+	}
+	defer nats.close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	streamName := fmt.Sprintf("KASPAD_TEST_%d", time.Now().UnixNano())
+	stream, err := nats.jetStream.CreateStream(ctx, jetstream.StreamConfig{
+		Name:     streamName,
+		Subjects: []string{streamName + ".>"},
+		Storage:  jetstream.MemoryStorage,
+	})
+	if err != nil {
+		t.Fatalf("CreateStream: %s", err)
+	}
+	defer nats.jetStream.DeleteStream(context.Background(), streamName)
+
+	var messages []*message
+	for i := 0; i < 100; i++ {
+		messages = append(messages, &message{topic: streamName + ".block-connected", value: []byte(strconv.Itoa(i))})
+	}
+	err = nats.publish(messages, time.Minute)
+	if err != nil {
+		t.Fatalf("publish: %s", err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info: %s", err)
+	}
+	if info.State.Msgs != uint64(len(messages)) {
+		t.Fatalf("Expected the stream to store %d messages but it stored %d", len(messages), info.State.Msgs)
+	}
+
+	err = nats.publish([]*message{{topic: streamName + "-other.block-connected", value: []byte(`{}`)}}, time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "no JetStream stream") {
+		t.Fatalf("Expected a missing stream to fail the publish but got: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/util/memorybudget"
+)
+
+// The weights by which the memory budget is split between the caches, and the memory,
+// in bytes, each of them keeps even under memory pressure. The minimum of the UTXO cache
+// is the size it has without a memory budget.
+const (
+	utxoCacheWeight        = 4
+	utxoCacheMinimumMemory = 2_000_000
+
+	blockCacheWeight        = 1
+	blockCacheMinimumMemory = 1_000_000
+
+	validationCachesWeight        = 1
+	validationCachesMinimumMemory = 10_000_000
+
+	mempoolWeight        = 4
+	mempoolMinimumMemory = 50_000_000
+)
+
+// newMemoryBudget creates the manager of the memory budget that was set with --maxmemory,
+// or returns nil if none was set
+func newMemoryBudget(cfg *config.Config, domain domain.Domain,
+	validationCaches *txscript.ValidationCaches) *memorybudget.Manager {
+
+	if cfg.MaxMemory == 0 {
+		return nil
+	}
+
+	manager := memorybudget.New(cfg.MaxMemory * 1_000_000)
+	manager.Register("UTXO cache", &consensusCache{domain: domain, cache: externalapi.UTXOCache},
+		utxoCacheWeight, utxoCacheMinimumMemory)
+	manager.Register("block cache", &consensusCache{domain: domain, cache: externalapi.BlockCache},
+		blockCacheWeight, blockCacheMinimumMemory)
+	manager.Register("validation caches", validationCaches, validationCachesWeight, validationCachesMinimumMemory)
+	manager.Register("mempool", &mempoolMemory{domain: domain}, mempoolWeight, mempoolMinimumMemory)
+	return manager
+}
+
+// consensusCache is a cache of the current consensus. The consensus is looked up on every
+// call, since it's replaced when the pruning point UTXO set is imported.
+type consensusCache struct {
+	domain domain.Domain
+	cache  externalapi.ConsensusCache
+}
+
+func (c *consensusCache) MemoryUsage() uint64 {
+	return c.domain.Consensus().CacheMemoryUsage(c.cache)
+}
+
+func (c *consensusCache) SetMemoryLimit(limit uint64) {
+	c.domain.Consensus().SetCacheMemoryLimit(c.cache, limit)
+}
+
+// mempoolMemory is the memory of the transactions of the mempool
+type mempoolMemory struct {
+	domain domain.Domain
+}
+
+func (m *mempoolMemory) MemoryUsage() uint64 {
+	return m.domain.MiningManager().MempoolInfo().MemoryUsage
+}
+
+func (m *mempoolMemory) SetMemoryLimit(limit uint64) {
+	err := m.domain.MiningManager().SetMaximumMempoolMemoryUsage(limit)
+	if err != nil {
+		log.Errorf("Error limiting the memory of the mempool: %s", err)
+	}
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"path/filepath"
+
+	"github.com/kaspanet/kaspad/domain/miningmanager"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+)
+
+// mempoolFilename is the name of the file, under the app directory, that the
+// mempool is saved to on shutdown when --persistmempool is specified
+const mempoolFilename = "mempool.dat"
+
+func mempoolFilePath(cfg *config.Config) string {
+	return filepath.Join(cfg.AppDir, mempoolFilename)
+}
+
+// loadMempool inserts the transactions that were saved on the last shutdown into the
+// mempool. A mempool file that can't be read is only logged, since the mempool is
+// rebuilt from the network anyway.
+func loadMempool(cfg *config.Config, miningManager miningmanager.MiningManager) {
+	path := mempoolFilePath(cfg)
+	acceptedCount, rejectedCount, err := miningManager.LoadMempoolFromFile(path)
+	if err != nil {
+		log.Errorf("Error loading the mempool from %s: %+v", path, err)
+		return
+	}
+	if acceptedCount == 0 && rejectedCount == 0 {
+		return
+	}
+	log.Infof("Loaded %d transactions into the mempool from %s. Dropped %d saved transactions that are "+
+		"no longer valid", acceptedCount, path, rejectedCount)
+}
+
+// saveMempool saves the transactions of the mempool, so that they're loaded on the next startup
+func saveMempool(cfg *config.Config, miningManager miningmanager.MiningManager) {
+	path := mempoolFilePath(cfg)
+	savedCount, err := miningManager.SaveMempoolToFile(path)
+	if err != nil {
+		log.Errorf("Error saving the mempool to %s: %+v", path, err)
+		return
+	}
+	log.Infof("Saved %d mempool transactions to %s", savedCount, path)
+}
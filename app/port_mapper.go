@@ -0,0 +1,44 @@
+package app
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
+	"github.com/kaspanet/kaspad/infrastructure/network/nat"
+	"github.com/pkg/errors"
+)
+
+// newPortMapper creates the mapper of the port of the first P2P listener that was set
+// with --natmethod, or returns nil if there's nothing to map. The external addresses it
+// finds are advertised to peers in place of one another.
+func newPortMapper(cfg *config.Config, addressManager *addressmanager.AddressManager) (*nat.PortMapper, error) {
+	method, _ := nat.MethodFromString(cfg.NATMethod)
+	if method == nat.MethodNone || cfg.DisableListen || len(cfg.ExternalIPs) > 0 {
+		return nil, nil
+	}
+
+	_, portString, err := net.SplitHostPort(cfg.Listeners[0])
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return nat.NewPortMapper(method, uint16(port), func(previous, current *net.TCPAddr) {
+		if previous != nil {
+			addressManager.RemoveLocalAddress(appmessage.NewNetAddressIPPort(previous.IP, uint16(previous.Port)))
+		}
+		if current != nil {
+			err := addressManager.AddLocalAddress(appmessage.NewNetAddressIPPort(current.IP, uint16(current.Port)),
+				addressmanager.UpnpPrio)
+			if err != nil {
+				log.Warnf("Not advertising the external address %s: %s", current, err)
+			}
+		}
+	}), nil
+}
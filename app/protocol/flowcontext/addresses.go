@@ -1,10 +1,100 @@
 package flowcontext
 
 import (
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
+	"github.com/kaspanet/kaspad/util/timesource"
+)
+
+// The addresses sent to peers are cached for about a day, so that peers can't
+// learn the full contents of the address manager, or when each address was added
+// to it, by requesting addresses repeatedly. The cache lifetime is randomized to
+// make it harder to tell when it's refreshed.
+const (
+	addressesCacheMinLifetime = 21 * time.Hour
+	addressesCacheJitter      = 6 * time.Hour
 )
 
 // AddressManager returns the address manager associated to the flow context.
 func (f *FlowContext) AddressManager() *addressmanager.AddressManager {
 	return f.addressManager
 }
+
+// AddAddresses adds the given addresses, which were learned from the peer at source, or
+// from no peer in particular if it's nil, to the address manager.
+//
+// A cache that isn't full holds all the known addresses anyway, so it's expired when
+// addresses are added. Otherwise a node that has just started would keep sending the few
+// addresses it knew at the first request, and wouldn't propagate the addresses it learns later.
+func (f *FlowContext) AddAddresses(source *appmessage.NetAddress, addresses ...*appmessage.NetAddress) error {
+	err := f.addressManager.AddAddressesFromSource(source, addresses...)
+	if err != nil {
+		return err
+	}
+
+	f.addressesCacheLock.Lock()
+	defer f.addressesCacheLock.Unlock()
+
+	if len(addresses) > 0 && len(f.addressesCache) < appmessage.MaxAddressesPerMsg {
+		f.addressesCacheExpiry = time.Time{}
+	}
+	return nil
+}
+
+// CachedAddresses returns the addresses to send to peers that request them.
+// The same addresses are returned until the cache expires.
+func (f *FlowContext) CachedAddresses() []*appmessage.NetAddress {
+	f.addressesCacheLock.Lock()
+	defer f.addressesCacheLock.Unlock()
+
+	now := f.timeSource.Now()
+	if now.After(f.addressesCacheExpiry) {
+		addresses := shuffleAddresses(f.timeSource, f.addressManager.Addresses())
+		f.addressesCache = prependLocalOnionAddresses(addresses, f.addressManager.LocalAddresses())
+		f.addressesCacheExpiry = now.Add(addressesCacheMinLifetime + time.Duration(f.timeSource.Int63n(int64(addressesCacheJitter))))
+	}
+	return f.addressesCache
+}
+
+// shuffleAddresses randomizes the given addresses and truncates them to the maximum allowed in one message.
+func shuffleAddresses(random timesource.Source, addresses []*appmessage.NetAddress) []*appmessage.NetAddress {
+	shuffledAddresses := make([]*appmessage.NetAddress, len(addresses))
+	copy(shuffledAddresses, addresses)
+
+	random.Shuffle(len(shuffledAddresses), func(i, j int) {
+		shuffledAddresses[i], shuffledAddresses[j] = shuffledAddresses[j], shuffledAddresses[i]
+	})
+
+	// Truncate it to the maximum size.
+	if len(shuffledAddresses) > appmessage.MaxAddressesPerMsg {
+		shuffledAddresses = shuffledAddresses[:appmessage.MaxAddressesPerMsg]
+	}
+	return shuffledAddresses
+}
+
+// prependLocalOnionAddresses puts the onion addresses of this node in front of the given
+// addresses, truncating them to the maximum allowed in one message. Peers that connect
+// through an onion service can't tell its address, so it's only learned from here.
+func prependLocalOnionAddresses(addresses []*appmessage.NetAddress,
+	localAddresses []*addressmanager.LocalAddress) []*appmessage.NetAddress {
+
+	var onionAddresses []*appmessage.NetAddress
+	for _, localAddress := range localAddresses {
+		if localAddress.NetAddress.IsOnion() {
+			// The address is copied so that it's advertised as seen now
+			onionAddresses = append(onionAddresses,
+				appmessage.NewNetAddressOnion(localAddress.NetAddress.OnionHost, localAddress.NetAddress.Port))
+		}
+	}
+	if len(onionAddresses) == 0 {
+		return addresses
+	}
+
+	addresses = append(onionAddresses, addresses...)
+	if len(addresses) > appmessage.MaxAddressesPerMsg {
+		addresses = addresses[:appmessage.MaxAddressesPerMsg]
+	}
+	return addresses
+}
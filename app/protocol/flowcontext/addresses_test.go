@@ -0,0 +1,76 @@
+package flowcontext
+
+import (
+	"net"
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
+)
+
+func TestCachedAddresses(t *testing.T) {
+	cfg := config.DefaultConfig()
+	database, err := ldb.NewLevelDB(t.TempDir(), 8)
+	if err != nil {
+		t.Fatalf("could not create a database: %s", err)
+	}
+	defer database.Close()
+	addressManager, err := addressmanager.New(addressmanager.NewConfig(cfg), database)
+	if err != nil {
+		t.Fatalf("error creating address manager: %s", err)
+	}
+	flowContext := New(cfg, nil, addressManager, nil, nil, nil, nil)
+
+	newAddress := func(i int) *appmessage.NetAddress {
+		return appmessage.NewNetAddressIPPort(net.IPv4(byte(i>>8)+1, byte(i), 0, 1), 16111)
+	}
+
+	err = flowContext.AddAddresses(nil, newAddress(0))
+	if err != nil {
+		t.Fatalf("AddAddresses: %s", err)
+	}
+	if len(flowContext.CachedAddresses()) != 1 {
+		t.Fatalf("expected the cache to hold the single known address")
+	}
+
+	// Addresses that are added without going through the flow context don't rebuild the cache
+	err = addressManager.AddAddresses(newAddress(1))
+	if err != nil {
+		t.Fatalf("AddAddresses: %s", err)
+	}
+	if len(flowContext.CachedAddresses()) != 1 {
+		t.Fatalf("expected the cache not to be rebuilt before it expires")
+	}
+
+	// A cache that isn't full is rebuilt once new addresses are learned
+	err = flowContext.AddAddresses(nil, newAddress(2))
+	if err != nil {
+		t.Fatalf("AddAddresses: %s", err)
+	}
+	if len(flowContext.CachedAddresses()) != 3 {
+		t.Fatalf("expected the cache to be rebuilt with all the known addresses")
+	}
+
+	// A full cache isn't rebuilt until it expires. The addresses are learned from many
+	// different sources, since the addresses of a single source are bounded to a few buckets.
+	const addressCount = 2 * appmessage.MaxAddressesPerMsg
+	for i := 3; i < addressCount+3; i++ {
+		err = flowContext.AddAddresses(newAddress(i), newAddress(i))
+		if err != nil {
+			t.Fatalf("AddAddresses: %s", err)
+		}
+	}
+	cachedAddresses := flowContext.CachedAddresses()
+	if len(cachedAddresses) != appmessage.MaxAddressesPerMsg {
+		t.Fatalf("expected a full cache, but got %d addresses", len(cachedAddresses))
+	}
+	err = flowContext.AddAddresses(nil, newAddress(addressCount+3))
+	if err != nil {
+		t.Fatalf("AddAddresses: %s", err)
+	}
+	if &flowContext.CachedAddresses()[0] != &cachedAddresses[0] {
+		t.Fatalf("expected the full cache not to be rebuilt before it expires")
+	}
+}
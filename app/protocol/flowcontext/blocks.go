@@ -1,13 +1,12 @@
 package flowcontext
 
 import (
-	"time"
-
 	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
 	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/util/processingstats"
 	"github.com/pkg/errors"
 
 	"github.com/kaspanet/kaspad/app/appmessage"
@@ -32,6 +31,23 @@ func (f *FlowContext) OnNewBlock(block *externalapi.DomainBlock) error {
 	newBlocks := []*externalapi.DomainBlock{block}
 	newBlocks = append(newBlocks, unorphanedBlocks...)
 
+	if f.cfIndex != nil {
+		for _, newBlock := range newBlocks {
+			onCFIndexUpdateEnd := processingstats.Measure(processingstats.CFIndexUpdate)
+			err := f.cfIndex.Update(newBlock)
+			onCFIndexUpdateEnd()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if f.onBlockAddedHandler != nil {
+		for _, newBlock := range newBlocks {
+			f.onBlockAddedHandler(newBlock)
+		}
+	}
+
 	allAcceptedTransactions := make([]*externalapi.DomainTransaction, 0)
 	for _, newBlock := range newBlocks {
 		log.Debugf("OnNewBlock: passing block %s transactions to mining manager", hash)
@@ -41,10 +57,39 @@ func (f *FlowContext) OnNewBlock(block *externalapi.DomainBlock) error {
 		}
 		allAcceptedTransactions = append(allAcceptedTransactions, acceptedTransactions...)
 	}
+	f.OnTransactionAddedToMempool(allAcceptedTransactions)
+
+	err = f.compareWithTrustedPeers()
+	if err != nil {
+		return err
+	}
 
 	return f.broadcastTransactionsAfterBlockAdded(newBlocks, allAcceptedTransactions)
 }
 
+// OnVirtualChange re-admits into the mempool, and relays, the transactions that the
+// virtual selected chain no longer accepts, such as the transactions of blocks that
+// turned red, so that they aren't silently dropped.
+func (f *FlowContext) OnVirtualChange(virtualChangeSet *externalapi.VirtualChangeSet) error {
+	// The chain changes during IBD are of blocks that were mined long ago
+	if f.IsIBDRunning() || virtualChangeSet.VirtualSelectedParentChainChanges == nil {
+		return nil
+	}
+
+	readmittedTransactions, err := f.Domain().MiningManager().HandleSelectedChainChanges(
+		virtualChangeSet.VirtualSelectedParentChainChanges)
+	if err != nil {
+		return err
+	}
+	if len(readmittedTransactions) == 0 {
+		return nil
+	}
+
+	log.Infof("Re-admitted %d transactions that are no longer accepted by the virtual", len(readmittedTransactions))
+	f.OnTransactionAddedToMempool(readmittedTransactions)
+	return f.EnqueueTransactionIDsForPropagation(consensushashing.TransactionIDs(readmittedTransactions))
+}
+
 // OnNewBlockTemplate calls the handler function whenever a new block template is available for miners.
 func (f *FlowContext) OnNewBlockTemplate() error {
 	// Clear current template cache. Note we call this even if the handler is nil, in order to keep the
@@ -80,8 +125,8 @@ func (f *FlowContext) broadcastTransactionsAfterBlockAdded(
 		if err != nil {
 			return err
 		}
-		txIDsToRebroadcast = consensushashing.TransactionIDs(txsToRebroadcast)
-		f.lastRebroadcastTime = time.Now()
+		txIDsToRebroadcast = f.transactionsToRebroadcast(consensushashing.TransactionIDs(txsToRebroadcast))
+		f.lastRebroadcastTime = f.timeSource.Now()
 	}
 
 	txIDsToBroadcast := make([]*externalapi.DomainTransactionID, len(transactionsAcceptedToMempool)+len(txIDsToRebroadcast))
@@ -109,7 +154,7 @@ func (f *FlowContext) AddBlock(block *externalapi.DomainBlock) error {
 
 	err := f.Domain().Consensus().ValidateAndInsertBlock(block, true)
 	if err != nil {
-		if errors.As(err, &ruleerrors.RuleError{}) {
+		if errors.As(err, &ruleerrors.RuleError{}) || errors.Is(err, externalapi.ErrRejectedByBlockValidator) {
 			log.Warnf("Validation failed for block %s: %s", consensushashing.BlockHash(block), err)
 		}
 		return err
@@ -151,13 +196,16 @@ func (f *FlowContext) TrySetIBDRunning(ibdPeer *peerpkg.Peer) bool {
 // UnsetIBDRunning unsets isInIBD
 func (f *FlowContext) UnsetIBDRunning() {
 	f.ibdPeerMutex.Lock()
-	defer f.ibdPeerMutex.Unlock()
-
 	if f.ibdPeer == nil {
+		f.ibdPeerMutex.Unlock()
 		panic("attempted to unset isInIBD when it was not set to begin with")
 	}
-
 	f.ibdPeer = nil
+	f.ibdPeerMutex.Unlock()
+
+	// The sync status lock is taken without holding the IBD peer lock, since
+	// SyncStatus takes them in the opposite order
+	f.SetIBDTargetDAAScore(0)
 }
 
 // IBDPeer returns the current IBD peer or null if the node is not
@@ -0,0 +1,8 @@
+package flowcontext
+
+import "github.com/kaspanet/kaspad/domain/cfindex"
+
+// CFIndex returns the compact filter index, or nil if it's disabled
+func (f *FlowContext) CFIndex() *cfindex.CFIndex {
+	return f.cfIndex
+}
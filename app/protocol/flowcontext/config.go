@@ -1,8 +1,40 @@
 package flowcontext
 
-import "github.com/kaspanet/kaspad/infrastructure/config"
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/network/permissions"
+)
 
 // Config returns an instance of *config.Config associated to the flow context.
 func (f *FlowContext) Config() *config.Config {
 	return f.cfg
 }
+
+// AcceptsTransactionsFrom returns whether transactions relayed by the given peer are accepted.
+// Transactions from remote peers aren't accepted in blocks-only mode, unless they have the relay permission.
+func (f *FlowContext) AcceptsTransactionsFrom(peer *peerpkg.Peer) bool {
+	return !f.cfg.BlocksOnly || peer.HasPermission(permissions.Relay)
+}
+
+// LocalServices returns the services this node advertises to its peers
+func (f *FlowContext) LocalServices() appmessage.ServiceFlag {
+	services := appmessage.DefaultServices
+	if f.cfg.NoPeerBloomFilters {
+		services &^= appmessage.SFNodeBloom
+	}
+	if f.cfg.NoGetUTXOs {
+		services &^= appmessage.SFNodeGetUTXO
+	}
+	if !f.cfg.CFIndex {
+		services &^= appmessage.SFNodeCF
+	}
+	if !f.cfg.DisableDandelion {
+		services |= appmessage.SFNodeDandelion
+	}
+	if !f.cfg.DisableP2PEncryption {
+		services |= appmessage.SFNodeEncryptedTransport
+	}
+	return services
+}
@@ -0,0 +1,30 @@
+package flowcontext
+
+import (
+	"testing"
+
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+)
+
+func TestAcceptsTransactionsFrom(t *testing.T) {
+	tests := []struct {
+		blocksOnly bool
+		expected   bool
+	}{
+		{blocksOnly: false, expected: true},
+		{blocksOnly: true, expected: false},
+	}
+
+	for _, test := range tests {
+		cfg := &config.Config{Flags: &config.Flags{BlocksOnly: test.blocksOnly}}
+		flowContext := &FlowContext{cfg: cfg}
+
+		// A peer without a connection has no permissions, so it's treated as any remote peer
+		accepts := flowContext.AcceptsTransactionsFrom(peerpkg.New(nil))
+		if accepts != test.expected {
+			t.Fatalf("blocksOnly=%t: expected AcceptsTransactionsFrom to return %t but got %t",
+				test.blocksOnly, test.expected, accepts)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package flowcontext
+
+import (
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
+	"github.com/kaspanet/kaspad/util/timesource"
+	"github.com/pkg/errors"
+)
+
+const (
+	// dandelionEpochDuration is the duration after which the stem route of the node is chosen anew
+	dandelionEpochDuration = 10 * time.Minute
+
+	// dandelionFluffProbability is the probability that the node fluffs all the stem
+	// transactions it receives during an epoch, rather than relaying them along the stem
+	dandelionFluffProbability = 0.1
+
+	// dandelionStemRelays is the number of outbound peers that stem transactions are
+	// relayed to during an epoch
+	dandelionStemRelays = 2
+
+	// dandelionEmbargoBase and dandelionEmbargoRandom define the time a node waits for a
+	// transaction it stemmed to be announced by the network before it announces it itself.
+	// The random part keeps the node that fluffs on timeout from being predictable.
+	dandelionEmbargoBase   = 30 * time.Second
+	dandelionEmbargoRandom = 30 * time.Second
+)
+
+// dandelionState is the stem route of this node in the current epoch. As in Dandelion++,
+// the node either fluffs every stem transaction it receives during an epoch, or relays them
+// to one of up to dandelionStemRelays outbound peers. Each peer that sends stem transactions
+// is routed to the same relay throughout the epoch, and the transactions of this node always
+// go to the first relay, so that an adversary learns little from observing many stems.
+type dandelionState struct {
+	epochStart   time.Time
+	isFluffEpoch bool
+	relays       []*peerpkg.Peer
+	routes       map[*peerpkg.Peer]*peerpkg.Peer
+}
+
+// localEmbargo is the embargo of a transaction that originated in this node and was passed
+// to relay along the stem. Until it ends, the transaction is in the mempool but isn't served
+// to any peer, since only the node it originated in could serve it during its stem phase.
+type localEmbargo struct {
+	relay *peerpkg.Peer
+}
+
+// AddStemTransaction handles a transaction that was received along a Dandelion stem. It either
+// fluffs the transaction, or keeps it in the stempool and passes it on along the stem.
+func (f *FlowContext) AddStemTransaction(tx *externalapi.DomainTransaction, sender *peerpkg.Peer) error {
+	transactionID := consensushashing.TransactionID(tx)
+	if f.stempool.has(transactionID) {
+		return nil
+	}
+	_, _, isInMempool := f.Domain().MiningManager().GetTransaction(transactionID, true, true)
+	if isInMempool {
+		// The transaction was already fluffed
+		return nil
+	}
+
+	relay := f.stemRelay(sender)
+	if relay == nil {
+		log.Debugf("Fluffing stem transaction %s", transactionID)
+		return f.FluffStemTransaction(tx)
+	}
+
+	// The transaction is validated on a clone, so that the one kept in the
+	// stempool is validated anew against the UTXO set of the time it's fluffed
+	err := f.Domain().MiningManager().ValidateTransaction(tx.Clone())
+	if err != nil {
+		return err
+	}
+	if !f.stempool.add(tx, dandelionEmbargo(f.timeSource)) {
+		log.Debugf("The stempool is full - fluffing stem transaction %s", transactionID)
+		return f.FluffStemTransaction(tx)
+	}
+	return f.sendStemTransaction(tx, relay)
+}
+
+// FluffStemTransaction ends the stem phase of a transaction that was received along a
+// Dandelion stem: it's added to the mempool and announced to all peers
+func (f *FlowContext) FluffStemTransaction(tx *externalapi.DomainTransaction) error {
+	acceptedTransactions, err := f.Domain().MiningManager().ValidateAndInsertTransaction(tx, false, false)
+	if err != nil {
+		return err
+	}
+	f.OnTransactionAddedToMempool(acceptedTransactions)
+	return f.EnqueueTransactionIDsForPropagation(consensushashing.TransactionIDs(acceptedTransactions))
+}
+
+// onStemTransactionEmbargoExpired fluffs a stem transaction that the network didn't fluff in time
+func (f *FlowContext) onStemTransactionEmbargoExpired(tx *externalapi.DomainTransaction) {
+	select {
+	case <-f.shutdownChan:
+		return
+	default:
+	}
+
+	transactionID := consensushashing.TransactionID(tx)
+	log.Debugf("The embargo of stem transaction %s is over - fluffing it", transactionID)
+	err := f.FluffStemTransaction(tx)
+	if err != nil {
+		if errors.As(err, &mempool.RuleError{}) {
+			log.Debugf("Stem transaction %s is no longer valid: %s", transactionID, err)
+			return
+		}
+		log.Warnf("Error fluffing stem transaction %s: %s", transactionID, err)
+	}
+}
+
+// stemLocalTransaction passes a transaction that originated in this node to the stem relay.
+// It returns false if there's no stem relay to pass it to.
+func (f *FlowContext) stemLocalTransaction(tx *externalapi.DomainTransaction,
+	acceptedTransactionIDs []*externalapi.DomainTransactionID) (bool, error) {
+
+	if f.cfg.DisableDandelion || len(acceptedTransactionIDs) == 0 {
+		return false, nil
+	}
+	relay := f.stemRelay(nil)
+	if relay == nil {
+		return false, nil
+	}
+
+	// Local transactions are in the mempool already, so until the embargo expires they're
+	// neither served to peers nor picked up by the rebroadcast of high priority transactions
+	embargo := dandelionEmbargo(f.timeSource)
+	f.holdFromRebroadcast(acceptedTransactionIDs, embargo)
+	f.addLocalEmbargoes(acceptedTransactionIDs, relay)
+
+	err := f.sendStemTransaction(tx, relay)
+	if err != nil {
+		f.removeLocalEmbargoes(acceptedTransactionIDs)
+		return false, err
+	}
+
+	f.timeSource.AfterFunc(embargo, func() {
+		select {
+		case <-f.shutdownChan:
+			return
+		default:
+		}
+
+		// Transactions whose embargo ended early were announced by the network already
+		transactionIDs := f.transactionsInMempool(f.removeLocalEmbargoes(acceptedTransactionIDs))
+		if len(transactionIDs) == 0 {
+			return
+		}
+		log.Debugf("The embargo of %d stemmed transactions is over - fluffing them", len(transactionIDs))
+		err := f.EnqueueTransactionIDsForPropagation(transactionIDs)
+		if err != nil {
+			log.Warnf("Error fluffing stemmed transactions: %s", err)
+		}
+	})
+	return true, nil
+}
+
+// IsTransactionEmbargoed returns whether the given transaction originated in this node
+// and is still in its stem phase, in which case it must not be served to peers
+func (f *FlowContext) IsTransactionEmbargoed(transactionID *externalapi.DomainTransactionID) bool {
+	f.localEmbargoesLock.Lock()
+	defer f.localEmbargoesLock.Unlock()
+
+	_, ok := f.localEmbargoes[*transactionID]
+	return ok
+}
+
+// OnTransactionAnnounced ends the embargo of a transaction of this node once a peer other
+// than its stem relay announces it, since the transaction is fluffed by then. The stem relay
+// itself isn't trusted to end the embargo, as it could keep the transaction from ever fluffing.
+func (f *FlowContext) OnTransactionAnnounced(transactionID *externalapi.DomainTransactionID, peer *peerpkg.Peer) {
+	f.localEmbargoesLock.Lock()
+	defer f.localEmbargoesLock.Unlock()
+
+	embargo, ok := f.localEmbargoes[*transactionID]
+	if !ok || embargo.relay == peer {
+		return
+	}
+	log.Debugf("Transaction %s was announced by %s - ending its embargo", transactionID, peer)
+	delete(f.localEmbargoes, *transactionID)
+}
+
+func (f *FlowContext) addLocalEmbargoes(transactionIDs []*externalapi.DomainTransactionID, relay *peerpkg.Peer) {
+	f.localEmbargoesLock.Lock()
+	defer f.localEmbargoesLock.Unlock()
+
+	embargo := &localEmbargo{relay: relay}
+	for _, transactionID := range transactionIDs {
+		f.localEmbargoes[*transactionID] = embargo
+	}
+}
+
+// removeLocalEmbargoes ends the embargoes of the given transactions, and returns
+// the ones that were still embargoed
+func (f *FlowContext) removeLocalEmbargoes(transactionIDs []*externalapi.DomainTransactionID) []*externalapi.DomainTransactionID {
+	f.localEmbargoesLock.Lock()
+	defer f.localEmbargoesLock.Unlock()
+
+	embargoedTransactionIDs := make([]*externalapi.DomainTransactionID, 0, len(transactionIDs))
+	for _, transactionID := range transactionIDs {
+		if _, ok := f.localEmbargoes[*transactionID]; ok {
+			delete(f.localEmbargoes, *transactionID)
+			embargoedTransactionIDs = append(embargoedTransactionIDs, transactionID)
+		}
+	}
+	return embargoedTransactionIDs
+}
+
+// sendStemTransaction passes tx along the stem to the stem peer
+func (f *FlowContext) sendStemTransaction(tx *externalapi.DomainTransaction, stemPeer *peerpkg.Peer) error {
+	log.Debugf("Passing transaction %s along the stem to %s", consensushashing.TransactionID(tx), stemPeer)
+	message := appmessage.NewMsgStemTransaction(appmessage.DomainTransactionToMsgTx(tx))
+	return f.netAdapter.P2PBroadcast([]*netadapter.NetConnection{stemPeer.Connection()}, message)
+}
+
+// dandelionEmbargo returns the time to wait for a stemmed transaction to be announced
+// by the network before this node announces it itself
+func dandelionEmbargo(random timesource.Source) time.Duration {
+	return dandelionEmbargoBase + time.Duration(random.Int63n(int64(dandelionEmbargoRandom)))
+}
+
+// stemRelay returns the peer to relay a stem transaction received from sender to, or nil if
+// the transaction should be fluffed. A nil sender stands for this node, whose transactions are
+// stemmed even during fluff epochs.
+func (f *FlowContext) stemRelay(sender *peerpkg.Peer) *peerpkg.Peer {
+	f.dandelionLock.Lock()
+	defer f.dandelionLock.Unlock()
+
+	if f.isDandelionEpochOver() {
+		f.startDandelionEpoch()
+	}
+	if sender == nil {
+		if len(f.dandelion.relays) == 0 {
+			return nil
+		}
+		return f.dandelion.relays[0]
+	}
+	if f.dandelion.isFluffEpoch {
+		return nil
+	}
+
+	if relay, ok := f.dandelion.routes[sender]; ok {
+		return relay
+	}
+	var candidates []*peerpkg.Peer
+	for _, relay := range f.dandelion.relays {
+		if relay != sender {
+			candidates = append(candidates, relay)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	relay := candidates[f.timeSource.Intn(len(candidates))]
+	f.dandelion.routes[sender] = relay
+	return relay
+}
+
+// isDandelionEpochOver returns whether the stem route should be chosen anew: when the epoch
+// ended, when one of the relays disconnected, or when there were no relays to choose from
+func (f *FlowContext) isDandelionEpochOver() bool {
+	if len(f.dandelion.relays) == 0 || f.timeSource.Since(f.dandelion.epochStart) >= dandelionEpochDuration {
+		return true
+	}
+	for _, relay := range f.dandelion.relays {
+		if !f.isConnected(relay) {
+			return true
+		}
+	}
+	return false
+}
+
+// startDandelionEpoch chooses the relays of a new epoch out of the outbound peers that support
+// Dandelion, and whether the node fluffs the stem transactions it receives during the epoch
+func (f *FlowContext) startDandelionEpoch() {
+	var candidates []*peerpkg.Peer
+	for _, peer := range f.Peers() {
+		if peer.IsOutbound() && peer.HasService(appmessage.SFNodeDandelion) {
+			candidates = append(candidates, peer)
+		}
+	}
+	f.timeSource.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	if len(candidates) > dandelionStemRelays {
+		candidates = candidates[:dandelionStemRelays]
+	}
+
+	f.dandelion = dandelionState{
+		epochStart:   f.timeSource.Now(),
+		isFluffEpoch: f.timeSource.Float64() < dandelionFluffProbability,
+		relays:       candidates,
+		routes:       make(map[*peerpkg.Peer]*peerpkg.Peer),
+	}
+	if len(candidates) > 0 {
+		log.Debugf("Started a Dandelion epoch with the stem relays %s (fluff epoch: %t)",
+			candidates, f.dandelion.isFluffEpoch)
+	}
+}
+
+func (f *FlowContext) isConnected(peer *peerpkg.Peer) bool {
+	f.peersMutex.RLock()
+	defer f.peersMutex.RUnlock()
+
+	connectedPeer, ok := f.peers[*peer.ID()]
+	return ok && connectedPeer == peer
+}
@@ -0,0 +1,67 @@
+package flowcontext
+
+import (
+	"testing"
+	"time"
+
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/util/timesource"
+)
+
+func TestLocalEmbargoes(t *testing.T) {
+	flowContext := New(&config.Config{Flags: &config.Flags{}}, nil, nil, nil, nil, nil, nil)
+	relay := peerpkg.New(nil)
+	otherPeer := peerpkg.New(nil)
+
+	announcedTransactionID := externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{1})
+	embargoedTransactionID := externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{2})
+	otherTransactionID := externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{3})
+	transactionIDs := []*externalapi.DomainTransactionID{announcedTransactionID, embargoedTransactionID}
+
+	flowContext.addLocalEmbargoes(transactionIDs, relay)
+	if !flowContext.IsTransactionEmbargoed(announcedTransactionID) || !flowContext.IsTransactionEmbargoed(embargoedTransactionID) {
+		t.Fatalf("the stemmed transactions aren't embargoed")
+	}
+	if flowContext.IsTransactionEmbargoed(otherTransactionID) {
+		t.Fatalf("a transaction that wasn't stemmed is embargoed")
+	}
+
+	// The stem relay can't end the embargo, since it could keep the transaction from fluffing
+	flowContext.OnTransactionAnnounced(announcedTransactionID, relay)
+	if !flowContext.IsTransactionEmbargoed(announcedTransactionID) {
+		t.Fatalf("the stem relay ended the embargo")
+	}
+	flowContext.OnTransactionAnnounced(announcedTransactionID, otherPeer)
+	if flowContext.IsTransactionEmbargoed(announcedTransactionID) {
+		t.Fatalf("the embargo didn't end when the transaction was announced by another peer")
+	}
+
+	// Only the transactions that are still embargoed are fluffed when the embargo expires
+	stillEmbargoed := flowContext.removeLocalEmbargoes(transactionIDs)
+	if len(stillEmbargoed) != 1 || !stillEmbargoed[0].Equal(embargoedTransactionID) {
+		t.Fatalf("expected only %s to still be embargoed, but got %s", embargoedTransactionID, stillEmbargoed)
+	}
+	if len(flowContext.localEmbargoes) != 0 {
+		t.Fatalf("expected no embargoes to remain, but got %d", len(flowContext.localEmbargoes))
+	}
+}
+
+// TestStemRelayWithoutRelays tests that stem transactions are fluffed when there are no
+// outbound peers that support Dandelion, and that the epoch is started anew once there are
+func TestStemRelayWithoutRelays(t *testing.T) {
+	flowContext := New(&config.Config{Flags: &config.Flags{}}, nil, nil, nil, nil, nil, nil)
+	timeSource := timesource.NewManual(0, time.Unix(0, 0))
+	flowContext.SetTimeSource(timeSource)
+
+	if relay := flowContext.stemRelay(nil); relay != nil {
+		t.Fatalf("expected local transactions to be announced without a stem relay, but got %s", relay)
+	}
+	if relay := flowContext.stemRelay(peerpkg.New(nil)); relay != nil {
+		t.Fatalf("expected stem transactions to be fluffed without a stem relay, but got %s", relay)
+	}
+	if !flowContext.isDandelionEpochOver() {
+		t.Fatalf("an epoch without stem relays isn't started anew")
+	}
+}
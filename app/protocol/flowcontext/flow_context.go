@@ -5,11 +5,15 @@ import (
 	"time"
 
 	"github.com/kaspanet/kaspad/util/mstime"
+	"github.com/kaspanet/kaspad/util/timesource"
 
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 
 	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/cfindex"
+	"github.com/kaspanet/kaspad/domain/txindex"
 
+	"github.com/kaspanet/kaspad/app/appmessage"
 	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
 	"github.com/kaspanet/kaspad/infrastructure/config"
 	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
@@ -26,8 +30,11 @@ type OnNewBlockTemplateHandler func() error
 type OnPruningPointUTXOSetOverrideHandler func() error
 
 // OnTransactionAddedToMempoolHandler is a handler function that's triggered
-// when a transaction is added to the mempool
-type OnTransactionAddedToMempoolHandler func()
+// when transactions are added to the mempool
+type OnTransactionAddedToMempoolHandler func(transactions []*externalapi.DomainTransaction)
+
+// OnBlockAddedHandler is a handler function that's triggered when a block is added to the DAG
+type OnBlockAddedHandler func(block *externalapi.DomainBlock)
 
 // FlowContext holds state that is relevant to more than one flow or one peer, and allows communication between
 // different flows that can be associated to different peers.
@@ -37,21 +44,34 @@ type FlowContext struct {
 	domain            domain.Domain
 	addressManager    *addressmanager.AddressManager
 	connectionManager *connmanager.ConnectionManager
+	cfIndex           *cfindex.CFIndex
+	txIndex           *txindex.TXIndex
 
 	timeStarted int64
+	timeSource  timesource.Source
 
 	onNewBlockTemplateHandler            OnNewBlockTemplateHandler
 	onPruningPointUTXOSetOverrideHandler OnPruningPointUTXOSetOverrideHandler
 	onTransactionAddedToMempoolHandler   OnTransactionAddedToMempoolHandler
+	onBlockAddedHandler                  OnBlockAddedHandler
 
 	lastRebroadcastTime         time.Time
 	sharedRequestedTransactions *SharedRequestedTransactions
+	sharedRequestedPackages     *SharedRequestedTransactions
 
 	sharedRequestedBlocks *SharedRequestedBlocks
 
 	ibdPeer      *peerpkg.Peer
 	ibdPeerMutex sync.RWMutex
 
+	isCurrent         bool
+	ibdTargetDAAScore uint64
+	syncStatusLock    sync.Mutex
+
+	trustedPeers                      []*trustedPeer
+	lastComparedVirtualSelectedParent *externalapi.DomainHash
+	trustedPeersLock                  sync.Mutex
+
 	peers      map[id.ID]*peerpkg.Peer
 	peersMutex sync.RWMutex
 
@@ -62,33 +82,74 @@ type FlowContext struct {
 	lastTransactionIDPropagationTime time.Time
 	transactionIDPropagationLock     sync.Mutex
 
+	dandelion     dandelionState
+	dandelionLock sync.Mutex
+	stempool      *stempool
+
+	localEmbargoes     map[externalapi.DomainTransactionID]*localEmbargo
+	localEmbargoesLock sync.Mutex
+
+	rebroadcastHolds     map[externalapi.DomainTransactionID]time.Time
+	rebroadcastHoldsLock sync.Mutex
+
+	addressesCache       []*appmessage.NetAddress
+	addressesCacheExpiry time.Time
+	addressesCacheLock   sync.Mutex
+
 	shutdownChan chan struct{}
 }
 
 // New returns a new instance of FlowContext.
 func New(cfg *config.Config, domain domain.Domain, addressManager *addressmanager.AddressManager,
-	netAdapter *netadapter.NetAdapter, connectionManager *connmanager.ConnectionManager) *FlowContext {
+	netAdapter *netadapter.NetAdapter, connectionManager *connmanager.ConnectionManager,
+	cfIndex *cfindex.CFIndex, txIndex *txindex.TXIndex) *FlowContext {
 
-	return &FlowContext{
+	flowContext := &FlowContext{
 		cfg:                              cfg,
 		netAdapter:                       netAdapter,
 		domain:                           domain,
 		addressManager:                   addressManager,
 		connectionManager:                connectionManager,
+		cfIndex:                          cfIndex,
+		txIndex:                          txIndex,
 		sharedRequestedTransactions:      NewSharedRequestedTransactions(),
+		sharedRequestedPackages:          NewSharedRequestedTransactions(),
 		sharedRequestedBlocks:            NewSharedRequestedBlocks(),
 		peers:                            make(map[id.ID]*peerpkg.Peer),
 		orphans:                          make(map[externalapi.DomainHash]*externalapi.DomainBlock),
 		timeStarted:                      mstime.Now().UnixMilliseconds(),
+		timeSource:                       timesource.System(),
 		transactionIDsToPropagate:        []*externalapi.DomainTransactionID{},
 		lastTransactionIDPropagationTime: time.Now(),
+		rebroadcastHolds:                 make(map[externalapi.DomainTransactionID]time.Time),
+		localEmbargoes:                   make(map[externalapi.DomainTransactionID]*localEmbargo),
+		trustedPeers:                     newTrustedPeers(cfg.TrustedPeers),
 		shutdownChan:                     make(chan struct{}),
 	}
+	flowContext.stempool = newStempool(flowContext.timeSource, flowContext.onStemTransactionEmbargoExpired)
+	return flowContext
+}
+
+// SetTimeSource replaces the source of the clock, the timers and the randomness
+// used by the flow context and by the flows it's passed to. It's meant for tests
+// and simulations that need these to be deterministic, and must be called before
+// the flow context is used.
+func (f *FlowContext) SetTimeSource(timeSource timesource.Source) {
+	f.timeSource = timeSource
+	f.lastTransactionIDPropagationTime = timeSource.Now()
+	f.stempool = newStempool(timeSource, f.onStemTransactionEmbargoExpired)
+}
+
+// TimeSource returns the source of the clock, the timers and the randomness
+// used by the flow context
+func (f *FlowContext) TimeSource() timesource.Source {
+	return f.timeSource
 }
 
 // Close signals to all flows the the protocol manager is closed.
 func (f *FlowContext) Close() {
 	close(f.shutdownChan)
+	f.stempool.close()
 }
 
 // ShutdownChan is a chan where flows can subscribe to shutdown
@@ -116,3 +177,8 @@ func (f *FlowContext) SetOnPruningPointUTXOSetOverrideHandler(onPruningPointUTXO
 func (f *FlowContext) SetOnTransactionAddedToMempoolHandler(onTransactionAddedToMempoolHandler OnTransactionAddedToMempoolHandler) {
 	f.onTransactionAddedToMempoolHandler = onTransactionAddedToMempoolHandler
 }
+
+// SetOnBlockAddedHandler sets the onBlockAdded handler
+func (f *FlowContext) SetOnBlockAddedHandler(onBlockAddedHandler OnBlockAddedHandler) {
+	f.onBlockAddedHandler = onBlockAddedHandler
+}
@@ -0,0 +1,129 @@
+package flowcontext
+
+import (
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
+	"github.com/kaspanet/kaspad/util/timesource"
+)
+
+// announceLocalTransactions announces transactions that originated in this node.
+// To make it harder for network observers to tell that the transactions originated here,
+// they are announced without delay only to a few randomly chosen first hops (chosen anew
+// on every call), and to every other peer after a random delay chosen independently per peer.
+func (f *FlowContext) announceLocalTransactions(transactionIDs []*externalapi.DomainTransactionID) error {
+	maxDelay := f.cfg.LocalTxRelayDelay
+	if maxDelay == 0 || len(transactionIDs) == 0 {
+		return f.EnqueueTransactionIDsForPropagation(transactionIDs)
+	}
+
+	// The periodic rebroadcast of high priority transactions announces to all peers at once,
+	// so it must not pick up these transactions before all the delayed announcements are done
+	f.holdFromRebroadcast(transactionIDs, maxDelay)
+
+	peers := f.Peers()
+	f.timeSource.Shuffle(len(peers), func(i, j int) {
+		peers[i], peers[j] = peers[j], peers[i]
+	})
+
+	for i, peer := range peers {
+		if i < f.cfg.LocalTxFirstHops {
+			err := f.announceTransactionsToPeer(peer, transactionIDs)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		peer := peer
+		f.timeSource.AfterFunc(localTransactionAnnouncementDelay(f.timeSource, maxDelay), func() {
+			select {
+			case <-f.shutdownChan:
+				return
+			default:
+			}
+
+			err := f.announceTransactionsToPeer(peer, f.transactionsInMempool(transactionIDs))
+			if err != nil {
+				log.Warnf("Error announcing local transactions to %s: %s", peer, err)
+			}
+		})
+	}
+	return nil
+}
+
+// localTransactionAnnouncementDelay returns a random delay between maxDelay/2 and maxDelay.
+// The delay is at least half of maxDelay so that the first hops have time to relay the
+// transactions onwards first.
+func localTransactionAnnouncementDelay(random timesource.Source, maxDelay time.Duration) time.Duration {
+	return maxDelay/2 + time.Duration(random.Int63n(int64(maxDelay-maxDelay/2)+1))
+}
+
+// holdFromRebroadcast keeps the given transactions out of the periodic rebroadcast of
+// high priority transactions for the given duration
+func (f *FlowContext) holdFromRebroadcast(transactionIDs []*externalapi.DomainTransactionID, duration time.Duration) {
+	f.rebroadcastHoldsLock.Lock()
+	defer f.rebroadcastHoldsLock.Unlock()
+
+	holdEnd := f.timeSource.Now().Add(duration)
+	for _, transactionID := range transactionIDs {
+		if currentHoldEnd, ok := f.rebroadcastHolds[*transactionID]; !ok || currentHoldEnd.Before(holdEnd) {
+			f.rebroadcastHolds[*transactionID] = holdEnd
+		}
+	}
+}
+
+// transactionsToRebroadcast filters out the given transactions that are held from rebroadcast.
+// Holds that ended are removed along the way.
+func (f *FlowContext) transactionsToRebroadcast(transactionIDs []*externalapi.DomainTransactionID) []*externalapi.DomainTransactionID {
+	f.rebroadcastHoldsLock.Lock()
+	defer f.rebroadcastHoldsLock.Unlock()
+
+	now := f.timeSource.Now()
+	for transactionID, holdEnd := range f.rebroadcastHolds {
+		if !now.Before(holdEnd) {
+			delete(f.rebroadcastHolds, transactionID)
+		}
+	}
+
+	transactionsToRebroadcast := make([]*externalapi.DomainTransactionID, 0, len(transactionIDs))
+	for _, transactionID := range transactionIDs {
+		if _, ok := f.rebroadcastHolds[*transactionID]; !ok {
+			transactionsToRebroadcast = append(transactionsToRebroadcast, transactionID)
+		}
+	}
+	return transactionsToRebroadcast
+}
+
+// transactionsInMempool filters out the transactions that already left the mempool
+func (f *FlowContext) transactionsInMempool(transactionIDs []*externalapi.DomainTransactionID) []*externalapi.DomainTransactionID {
+	transactionsInMempool := make([]*externalapi.DomainTransactionID, 0, len(transactionIDs))
+	for _, transactionID := range transactionIDs {
+		_, _, found := f.Domain().MiningManager().GetTransaction(transactionID, true, false)
+		if found {
+			transactionsInMempool = append(transactionsInMempool, transactionID)
+		}
+	}
+	return transactionsInMempool
+}
+
+func (f *FlowContext) announceTransactionsToPeer(peer *peerpkg.Peer, transactionIDs []*externalapi.DomainTransactionID) error {
+	transactionIDs = f.transactionsToAnnounceToPeer(peer, transactionIDs)
+	connections := []*netadapter.NetConnection{peer.Connection()}
+	for len(transactionIDs) > 0 {
+		transactionIDsToAnnounce := transactionIDs
+		if len(transactionIDsToAnnounce) > appmessage.MaxInvPerTxInvMsg {
+			transactionIDsToAnnounce = transactionIDs[:appmessage.MaxInvPerTxInvMsg]
+		}
+
+		err := f.netAdapter.P2PBroadcast(connections, appmessage.NewMsgInvTransaction(transactionIDsToAnnounce))
+		if err != nil {
+			return err
+		}
+		transactionIDs = transactionIDs[len(transactionIDsToAnnounce):]
+	}
+	return nil
+}
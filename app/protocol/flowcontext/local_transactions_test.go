@@ -0,0 +1,56 @@
+package flowcontext
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/util/timesource"
+)
+
+func TestLocalTransactionAnnouncementDelay(t *testing.T) {
+	for _, maxDelay := range []time.Duration{1, 3, time.Millisecond, 2 * time.Second} {
+		for i := 0; i < 1000; i++ {
+			delay := localTransactionAnnouncementDelay(timesource.System(), maxDelay)
+			if delay < maxDelay/2 || delay > maxDelay {
+				t.Fatalf("localTransactionAnnouncementDelay(%s) returned %s, which is out of range", maxDelay, delay)
+			}
+		}
+	}
+}
+
+func TestRebroadcastHolds(t *testing.T) {
+	flowContext := New(&config.Config{Flags: &config.Flags{}}, nil, nil, nil, nil, nil, nil)
+
+	heldTransactionID := externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{1})
+	expiredTransactionID := externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{2})
+	otherTransactionID := externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{3})
+	allTransactionIDs := []*externalapi.DomainTransactionID{heldTransactionID, expiredTransactionID, otherTransactionID}
+
+	flowContext.holdFromRebroadcast([]*externalapi.DomainTransactionID{heldTransactionID}, time.Hour)
+	flowContext.holdFromRebroadcast([]*externalapi.DomainTransactionID{expiredTransactionID}, -time.Second)
+
+	// A shorter hold must not cut an existing hold short
+	flowContext.holdFromRebroadcast([]*externalapi.DomainTransactionID{heldTransactionID}, -time.Second)
+
+	transactionsToRebroadcast := flowContext.transactionsToRebroadcast(allTransactionIDs)
+	if len(transactionsToRebroadcast) != 2 ||
+		!transactionsToRebroadcast[0].Equal(expiredTransactionID) || !transactionsToRebroadcast[1].Equal(otherTransactionID) {
+
+		t.Fatalf("expected only the transactions that aren't held to be rebroadcast, but got %s", transactionsToRebroadcast)
+	}
+	if _, ok := flowContext.rebroadcastHolds[*expiredTransactionID]; ok {
+		t.Fatalf("the expired hold wasn't removed")
+	}
+
+	// Once the hold ends the transaction is rebroadcast
+	flowContext.rebroadcastHolds[*heldTransactionID] = time.Now().Add(-time.Second)
+	transactionsToRebroadcast = flowContext.transactionsToRebroadcast(allTransactionIDs)
+	if len(transactionsToRebroadcast) != len(allTransactionIDs) {
+		t.Fatalf("expected all the transactions to be rebroadcast, but got %s", transactionsToRebroadcast)
+	}
+	if len(flowContext.rebroadcastHolds) != 0 {
+		t.Fatalf("expected no holds to remain, but got %d", len(flowContext.rebroadcastHolds))
+	}
+}
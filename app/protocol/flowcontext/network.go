@@ -19,6 +19,12 @@ func (f *FlowContext) ConnectionManager() *connmanager.ConnectionManager {
 	return f.connectionManager
 }
 
+// ShouldServeHistoricalData returns whether historical data, such as the blocks and the
+// UTXO set that syncing peers request, may still be served under the upload target
+func (f *FlowContext) ShouldServeHistoricalData() bool {
+	return f.netAdapter.UploadTarget().ShouldServeHistoricalData()
+}
+
 // AddToPeers marks this peer as ready and adds it to the ready peers list.
 func (f *FlowContext) AddToPeers(peer *peerpkg.Peer) error {
 	f.peersMutex.Lock()
@@ -79,3 +85,25 @@ func (f *FlowContext) HasPeers() bool {
 	defer f.peersMutex.RUnlock()
 	return len(f.peers) > 0
 }
+
+// PeerStats returns what the eviction of incoming connections needs to know about the
+// peer of netConnection, or false if it's not a ready peer
+func (f *FlowContext) PeerStats(netConnection *netadapter.NetConnection) (*connmanager.PeerStats, bool) {
+	f.peersMutex.RLock()
+	defer f.peersMutex.RUnlock()
+
+	// The ID of the connection is set by the handshake, so the peer is looked up by
+	// its connection instead
+	for _, peer := range f.peers {
+		if peer.Connection() != netConnection {
+			continue
+		}
+		return &connmanager.PeerStats{
+			MinPingDuration:                 peer.MinPingDuration(),
+			TimeConnected:                   peer.TimeConnected(),
+			LastBlockAnnouncementTime:       peer.LastBlockAnnouncementTime(),
+			LastTransactionAnnouncementTime: peer.LastTransactionAnnouncementTime(),
+		}, true
+	}
+	return nil, false
+}
@@ -143,7 +143,7 @@ func (f *FlowContext) unorphanBlock(orphanHash externalapi.DomainHash) (bool, er
 
 	err := f.domain.Consensus().ValidateAndInsertBlock(orphanBlock, true)
 	if err != nil {
-		if errors.As(err, &ruleerrors.RuleError{}) {
+		if errors.As(err, &ruleerrors.RuleError{}) || errors.Is(err, externalapi.ErrRejectedByBlockValidator) {
 			log.Warnf("Validation failed for orphan block %s: %s", orphanHash, err)
 			return false, nil
 		}
@@ -0,0 +1,72 @@
+package flowcontext
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
+)
+
+// AddPackage adds the transactions of the given package to the mempool, either all of them or
+// none of them, and propagates the package. Unlike single transactions, packages that originate
+// in this node aren't passed along a Dandelion stem, since only peers that relay packages can
+// accept them.
+func (f *FlowContext) AddPackage(transactions []*externalapi.DomainTransaction) error {
+	acceptedTransactions, err := f.Domain().MiningManager().ValidateAndInsertPackage(transactions, true)
+	if err != nil {
+		return err
+	}
+
+	f.OnTransactionAddedToMempool(acceptedTransactions)
+	return f.PropagateAcceptedPackage(transactions, acceptedTransactions)
+}
+
+// SharedRequestedPackages returns a *SharedRequestedTransactions for sharing data about packages
+// that were requested, by the IDs of their last transactions, between different peers.
+func (f *FlowContext) SharedRequestedPackages() *SharedRequestedTransactions {
+	return f.sharedRequestedPackages
+}
+
+// PropagateAcceptedPackage announces the given package, whose transactions were accepted to the
+// mempool, to the peers that relay packages. The rest of the accepted transactions are orphans
+// that the package's transactions let in, and are propagated on their own.
+func (f *FlowContext) PropagateAcceptedPackage(packageTransactions []*externalapi.DomainTransaction,
+	acceptedTransactions []*externalapi.DomainTransaction) error {
+
+	if len(acceptedTransactions) == 0 {
+		return nil
+	}
+
+	packageTransactionIDs := make(map[externalapi.DomainTransactionID]struct{}, len(packageTransactions))
+	for _, transaction := range packageTransactions {
+		packageTransactionIDs[*consensushashing.TransactionID(transaction)] = struct{}{}
+	}
+	var acceptedOrphanIDs []*externalapi.DomainTransactionID
+	for _, transaction := range acceptedTransactions {
+		transactionID := consensushashing.TransactionID(transaction)
+		if _, ok := packageTransactionIDs[*transactionID]; !ok {
+			acceptedOrphanIDs = append(acceptedOrphanIDs, transactionID)
+		}
+	}
+	if len(acceptedOrphanIDs) > 0 {
+		err := f.EnqueueTransactionIDsForPropagation(acceptedOrphanIDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	lastTransactionID := consensushashing.TransactionID(packageTransactions[len(packageTransactions)-1])
+	return f.broadcastPackageIDs([]*externalapi.DomainTransactionID{lastTransactionID})
+}
+
+// broadcastPackageIDs announces the packages of the given transactions to the ready peers that
+// relay packages. Peers that loaded a bloom filter aren't announced packages.
+func (f *FlowContext) broadcastPackageIDs(transactionIDs []*externalapi.DomainTransactionID) error {
+	var packageRelayPeerConnections []*netadapter.NetConnection
+	for _, peer := range f.Peers() {
+		if peer.RelaysTransactions() && peer.HasService(appmessage.SFNodePackageRelay) && !peer.Filter().IsLoaded() {
+			packageRelayPeerConnections = append(packageRelayPeerConnections, peer.Connection())
+		}
+	}
+	return f.netAdapter.P2PBroadcast(packageRelayPeerConnections, appmessage.NewMsgInvPackage(transactionIDs))
+}
@@ -0,0 +1,98 @@
+package flowcontext
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/util/timesource"
+)
+
+// maxStemTransactions bounds the number of transactions that may be in their stem phase at once.
+// Stem transactions beyond it are fluffed right away.
+const maxStemTransactions = 1000
+
+// stempool holds the transactions that were received along a Dandelion stem and haven't been
+// fluffed yet. They're kept out of the mempool so that this node doesn't serve them, mine them
+// or rebroadcast them, all of which would reveal that it saw them during their stem phase.
+type stempool struct {
+	transactions map[externalapi.DomainTransactionID]*stemTransaction
+	lock         sync.Mutex
+	timeSource   timesource.Source
+
+	onEmbargoExpired func(tx *externalapi.DomainTransaction)
+}
+
+type stemTransaction struct {
+	transaction  *externalapi.DomainTransaction
+	embargoTimer timesource.Timer
+}
+
+func newStempool(timeSource timesource.Source, onEmbargoExpired func(tx *externalapi.DomainTransaction)) *stempool {
+	return &stempool{
+		transactions:     make(map[externalapi.DomainTransactionID]*stemTransaction),
+		timeSource:       timeSource,
+		onEmbargoExpired: onEmbargoExpired,
+	}
+}
+
+// add adds tx to the stempool. If tx isn't fluffed by the network until the embargo
+// expires, it's removed from the stempool and passed to onEmbargoExpired.
+// Returns false if tx is already in the stempool or the stempool is full.
+func (sp *stempool) add(tx *externalapi.DomainTransaction, embargo time.Duration) bool {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	transactionID := *consensushashing.TransactionID(tx)
+	if _, ok := sp.transactions[transactionID]; ok {
+		return false
+	}
+	if len(sp.transactions) >= maxStemTransactions {
+		return false
+	}
+
+	sp.transactions[transactionID] = &stemTransaction{
+		transaction: tx,
+		embargoTimer: sp.timeSource.AfterFunc(embargo, func() {
+			tx, ok := sp.remove(&transactionID)
+			if ok {
+				sp.onEmbargoExpired(tx)
+			}
+		}),
+	}
+	return true
+}
+
+// remove removes the given transaction from the stempool, cancelling its embargo
+func (sp *stempool) remove(transactionID *externalapi.DomainTransactionID) (*externalapi.DomainTransaction, bool) {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	stemTx, ok := sp.transactions[*transactionID]
+	if !ok {
+		return nil, false
+	}
+	stemTx.embargoTimer.Stop()
+	delete(sp.transactions, *transactionID)
+	return stemTx.transaction, true
+}
+
+func (sp *stempool) has(transactionID *externalapi.DomainTransactionID) bool {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	_, ok := sp.transactions[*transactionID]
+	return ok
+}
+
+// close cancels the embargoes of all the transactions in the stempool
+func (sp *stempool) close() {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	for transactionID, stemTx := range sp.transactions {
+		stemTx.embargoTimer.Stop()
+		delete(sp.transactions, transactionID)
+	}
+}
@@ -0,0 +1,120 @@
+package flowcontext
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/util/timesource"
+)
+
+func newTestStemTransaction(lockTime uint64) *externalapi.DomainTransaction {
+	return &externalapi.DomainTransaction{
+		Inputs:   []*externalapi.DomainTransactionInput{},
+		Outputs:  []*externalapi.DomainTransactionOutput{},
+		LockTime: lockTime,
+	}
+}
+
+func TestStempoolEmbargoExpiry(t *testing.T) {
+	expiredChan := make(chan *externalapi.DomainTransaction, 1)
+	sp := newStempool(timesource.System(), func(tx *externalapi.DomainTransaction) {
+		expiredChan <- tx
+	})
+
+	tx := newTestStemTransaction(0)
+	if !sp.add(tx, 10*time.Millisecond) {
+		t.Fatalf("add unexpectedly failed")
+	}
+	if sp.add(tx, 10*time.Millisecond) {
+		t.Fatalf("add unexpectedly succeeded for a transaction that's already in the stempool")
+	}
+
+	select {
+	case expiredTx := <-expiredChan:
+		if expiredTx != tx {
+			t.Fatalf("the embargo of an unexpected transaction expired")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the embargo didn't expire")
+	}
+	if sp.has(consensushashing.TransactionID(tx)) {
+		t.Fatalf("the transaction remained in the stempool after its embargo expired")
+	}
+}
+
+func TestStempoolFull(t *testing.T) {
+	sp := newStempool(timesource.System(), func(tx *externalapi.DomainTransaction) {})
+	defer sp.close()
+
+	for i := 0; i < maxStemTransactions; i++ {
+		if !sp.add(newTestStemTransaction(uint64(i)), time.Hour) {
+			t.Fatalf("add unexpectedly failed")
+		}
+	}
+	if sp.add(newTestStemTransaction(maxStemTransactions), time.Hour) {
+		t.Fatalf("add unexpectedly succeeded with a full stempool")
+	}
+}
+
+// TestStemTransactionFluffedByNetwork tests that a stem transaction that's added to
+// the mempool leaves the stempool, and isn't fluffed again when its embargo expires
+func TestStemTransactionFluffedByNetwork(t *testing.T) {
+	flowContext := New(&config.Config{Flags: &config.Flags{}}, nil, nil, nil, nil, nil, nil)
+	isEmbargoExpired := make(chan struct{}, 1)
+	flowContext.stempool.onEmbargoExpired = func(_ *externalapi.DomainTransaction) {
+		isEmbargoExpired <- struct{}{}
+	}
+
+	tx := newTestStemTransaction(0)
+	if !flowContext.stempool.add(tx, 50*time.Millisecond) {
+		t.Fatalf("add unexpectedly failed")
+	}
+
+	flowContext.OnTransactionAddedToMempool([]*externalapi.DomainTransaction{tx})
+	if flowContext.stempool.has(consensushashing.TransactionID(tx)) {
+		t.Fatalf("the fluffed transaction remained in the stempool")
+	}
+
+	select {
+	case <-isEmbargoExpired:
+		t.Fatalf("the embargo of a fluffed transaction expired")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// TestStemTransactionEmbargoWithManualTimeSource tests that the embargo of a stem
+// transaction expires exactly when its duration elapses on the time source of the
+// flow context
+func TestStemTransactionEmbargoWithManualTimeSource(t *testing.T) {
+	flowContext := New(&config.Config{Flags: &config.Flags{}}, nil, nil, nil, nil, nil, nil)
+	timeSource := timesource.NewManual(0, time.Unix(0, 0))
+	flowContext.SetTimeSource(timeSource)
+
+	expiredTransactions := 0
+	flowContext.stempool.onEmbargoExpired = func(_ *externalapi.DomainTransaction) {
+		expiredTransactions++
+	}
+
+	fluffedTx := newTestStemTransaction(0)
+	expiringTx := newTestStemTransaction(1)
+	if !flowContext.stempool.add(fluffedTx, time.Minute) || !flowContext.stempool.add(expiringTx, time.Minute) {
+		t.Fatalf("add unexpectedly failed")
+	}
+	flowContext.OnTransactionAddedToMempool([]*externalapi.DomainTransaction{fluffedTx})
+
+	timeSource.Advance(time.Minute - time.Nanosecond)
+	if expiredTransactions != 0 || !flowContext.stempool.has(consensushashing.TransactionID(expiringTx)) {
+		t.Fatalf("the embargo expired early")
+	}
+
+	timeSource.Advance(time.Nanosecond)
+	if expiredTransactions != 1 {
+		t.Fatalf("expected exactly one embargo to expire, but %d expired", expiredTransactions)
+	}
+	if flowContext.stempool.has(consensushashing.TransactionID(expiringTx)) {
+		t.Fatalf("the transaction whose embargo expired remained in the stempool")
+	}
+}
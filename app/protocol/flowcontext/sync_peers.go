@@ -0,0 +1,41 @@
+package flowcontext
+
+import (
+	"time"
+
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+)
+
+// maxFarFutureBlocks is the number of blocks too far in the future that a peer
+// may relay before it's considered to misreport time
+const maxFarFutureBlocks = 3
+
+// IsDeprioritizedSyncPeer returns whether IBD shouldn't be started with peer,
+// because it misreports time while other peers don't. A peer misreports time
+// if the time in its version message is further off ours than the timestamps
+// of blocks are allowed to be, or if it relayed several blocks too far in the
+// future. A single bad time source could otherwise dominate block requests.
+func (f *FlowContext) IsDeprioritizedSyncPeer(peer *peerpkg.Peer) bool {
+	if !f.isMisreportingTime(peer) {
+		return false
+	}
+	for _, otherPeer := range f.Peers() {
+		if otherPeer != peer && !f.isMisreportingTime(otherPeer) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *FlowContext) isMisreportingTime(peer *peerpkg.Peer) bool {
+	params := f.Config().NetParams()
+	maxTimeOffset := time.Duration(params.TimestampDeviationTolerance) * params.TargetTimePerBlock
+	return isMisreportingTime(peer.TimeOffset(), peer.FarFutureBlockCount(), maxTimeOffset)
+}
+
+func isMisreportingTime(timeOffset time.Duration, farFutureBlockCount uint32, maxTimeOffset time.Duration) bool {
+	if timeOffset < 0 {
+		timeOffset = -timeOffset
+	}
+	return timeOffset > maxTimeOffset || farFutureBlockCount >= maxFarFutureBlocks
+}
@@ -0,0 +1,29 @@
+package flowcontext
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsMisreportingTime(t *testing.T) {
+	const maxTimeOffset = 2 * time.Minute
+	tests := []struct {
+		timeOffset          time.Duration
+		farFutureBlockCount uint32
+		expectedResult      bool
+	}{
+		{timeOffset: 0, farFutureBlockCount: 0, expectedResult: false},
+		{timeOffset: maxTimeOffset, farFutureBlockCount: 0, expectedResult: false},
+		{timeOffset: -maxTimeOffset, farFutureBlockCount: maxFarFutureBlocks - 1, expectedResult: false},
+		{timeOffset: maxTimeOffset + time.Millisecond, farFutureBlockCount: 0, expectedResult: true},
+		{timeOffset: -maxTimeOffset - time.Millisecond, farFutureBlockCount: 0, expectedResult: true},
+		{timeOffset: 0, farFutureBlockCount: maxFarFutureBlocks, expectedResult: true},
+	}
+	for _, test := range tests {
+		result := isMisreportingTime(test.timeOffset, test.farFutureBlockCount, maxTimeOffset)
+		if result != test.expectedResult {
+			t.Fatalf("time offset %s and %d far future blocks: expected %t but got %t",
+				test.timeOffset, test.farFutureBlockCount, test.expectedResult, result)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package flowcontext
+
+import (
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/util/mstime"
+)
+
+// lostSyncDAAWindowFactor is how many expected DAA window durations the selected tip
+// of a synced node has to fall behind before the node is no longer considered synced.
+// A node only becomes synced once its selected tip is within a single DAA window
+// duration, so that a node near the edge doesn't flap between the two states.
+const lostSyncDAAWindowFactor = 2
+
+// SyncStatus describes how far the node is in syncing with the network
+type SyncStatus struct {
+	IsSynced     bool
+	IsIBDRunning bool
+	IBDPeer      string
+
+	HeaderCount uint64
+	BlockCount  uint64
+
+	// The DAA scores of the headers selected tip and of the virtual, and
+	// the DAA score the node is syncing up to
+	HeadersDAAScore uint64
+	BlocksDAAScore  uint64
+	TargetDAAScore  uint64
+
+	// The estimated percentages of the headers and of the blocks up to
+	// the target that were already synced
+	HeadersProgress float64
+	BlocksProgress  float64
+
+	// The highest selected chain block that the quorum of trusted peers reported,
+	// and how the views of the DAG of the trusted peers compare to the one of the node
+	TrustedChainBlockHash *externalapi.DomainHash
+	TrustedPeers          []*TrustedPeerStatus
+}
+
+// IsCurrent returns whether the node is synced with the network. Unlike IsNearlySynced,
+// a synced node only stops being synced once its selected tip is well behind the
+// network, and an IBD has to finish before a node becomes synced. If the node has
+// trusted peers, it's also only synced while they report its selected chain.
+func (f *FlowContext) IsCurrent() (bool, error) {
+	f.syncStatusLock.Lock()
+	defer f.syncStatusLock.Unlock()
+
+	return f.isCurrentNoLock()
+}
+
+func (f *FlowContext) isCurrentNoLock() (bool, error) {
+	consensus := f.Domain().Consensus()
+	sink, err := consensus.GetVirtualSelectedParent()
+	if err != nil {
+		return false, err
+	}
+
+	if sink.Equal(f.Config().NetParams().GenesisHash) {
+		f.setIsCurrent(false)
+		return false, nil
+	}
+	sinkHeader, err := consensus.GetBlockHeader(sink)
+	if err != nil {
+		return false, err
+	}
+	sinkAge := time.Duration(mstime.Now().UnixMilliseconds()-sinkHeader.TimeInMilliseconds()) * time.Millisecond
+	f.setIsCurrent(isCurrent(f.isCurrent, sinkAge, f.expectedDAAWindowDuration(), f.IsIBDRunning()))
+
+	return f.isCurrent && f.isTrustedChainCurrent(sinkHeader.DAAScore()), nil
+}
+
+func (f *FlowContext) setIsCurrent(isCurrent bool) {
+	if isCurrent == f.isCurrent {
+		return
+	}
+	f.isCurrent = isCurrent
+	if isCurrent {
+		log.Infof("The node is synced with the network")
+	} else {
+		log.Infof("The node is no longer synced with the network")
+	}
+}
+
+// isCurrent returns whether a node with a selected tip of the given age is synced,
+// given whether it was synced before
+func isCurrent(wasCurrent bool, sinkAge time.Duration, expectedDAAWindowDuration time.Duration,
+	isIBDRunning bool) bool {
+
+	if wasCurrent {
+		return sinkAge < lostSyncDAAWindowFactor*expectedDAAWindowDuration
+	}
+	return !isIBDRunning && sinkAge < expectedDAAWindowDuration
+}
+
+func (f *FlowContext) expectedDAAWindowDuration() time.Duration {
+	params := f.Config().NetParams()
+	return params.TargetTimePerBlock * time.Duration(params.DifficultyAdjustmentWindowSize)
+}
+
+// SetIBDTargetDAAScore sets the DAA score of the block the running IBD syncs up to.
+// It's forgotten once the IBD is finished.
+func (f *FlowContext) SetIBDTargetDAAScore(daaScore uint64) {
+	f.syncStatusLock.Lock()
+	defer f.syncStatusLock.Unlock()
+
+	f.ibdTargetDAAScore = daaScore
+}
+
+// SyncStatus returns how far the node is in syncing with the network
+func (f *FlowContext) SyncStatus() (*SyncStatus, error) {
+	f.syncStatusLock.Lock()
+	defer f.syncStatusLock.Unlock()
+
+	isSynced, err := f.isCurrentNoLock()
+	if err != nil {
+		return nil, err
+	}
+
+	consensus := f.Domain().Consensus()
+	syncInfo, err := consensus.GetSyncInfo()
+	if err != nil {
+		return nil, err
+	}
+	headersSelectedTip, err := consensus.GetHeadersSelectedTip()
+	if err != nil {
+		return nil, err
+	}
+	headersSelectedTipHeader, err := consensus.GetBlockHeader(headersSelectedTip)
+	if err != nil {
+		return nil, err
+	}
+	virtualDAAScore, err := consensus.GetVirtualDAAScore()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &SyncStatus{
+		IsSynced:        isSynced,
+		HeaderCount:     syncInfo.HeaderCount,
+		BlockCount:      syncInfo.BlockCount,
+		HeadersDAAScore: headersSelectedTipHeader.DAAScore(),
+		BlocksDAAScore:  virtualDAAScore,
+	}
+
+	ibdPeer := f.IBDPeer()
+	if ibdPeer != nil {
+		status.IsIBDRunning = true
+		status.IBDPeer = ibdPeer.Address()
+	}
+
+	// Outside of IBD, the node only knows of the headers it already has, so
+	// it syncs up to its headers selected tip
+	status.TargetDAAScore = status.HeadersDAAScore
+	if status.IsIBDRunning && f.ibdTargetDAAScore > status.TargetDAAScore {
+		status.TargetDAAScore = f.ibdTargetDAAScore
+	}
+	status.HeadersProgress = syncProgress(status.HeadersDAAScore, status.TargetDAAScore)
+	status.BlocksProgress = syncProgress(status.BlocksDAAScore, status.TargetDAAScore)
+	status.TrustedPeers, status.TrustedChainBlockHash = f.TrustedPeersStatus()
+
+	return status, nil
+}
+
+// syncProgress returns the percentage of the way to the target DAA score
+func syncProgress(daaScore uint64, targetDAAScore uint64) float64 {
+	if targetDAAScore == 0 || daaScore >= targetDAAScore {
+		return 100
+	}
+	return float64(daaScore) / float64(targetDAAScore) * 100
+}
@@ -0,0 +1,52 @@
+package flowcontext
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsCurrent(t *testing.T) {
+	const window = 10 * time.Minute
+	tests := []struct {
+		name         string
+		wasCurrent   bool
+		sinkAge      time.Duration
+		isIBDRunning bool
+		expected     bool
+	}{
+		{name: "recent tip", sinkAge: window / 2, expected: true},
+		{name: "recent tip during IBD", sinkAge: window / 2, isIBDRunning: true, expected: false},
+		{name: "tip between the thresholds", sinkAge: window * 3 / 2, expected: false},
+		{name: "synced with a tip between the thresholds", wasCurrent: true, sinkAge: window * 3 / 2, expected: true},
+		{name: "synced during IBD", wasCurrent: true, sinkAge: window / 2, isIBDRunning: true, expected: true},
+		{name: "synced with an old tip", wasCurrent: true, sinkAge: window * 3, expected: false},
+	}
+
+	for _, test := range tests {
+		result := isCurrent(test.wasCurrent, test.sinkAge, window, test.isIBDRunning)
+		if result != test.expected {
+			t.Errorf("%s: expected %t but got %t", test.name, test.expected, result)
+		}
+	}
+}
+
+func TestSyncProgress(t *testing.T) {
+	tests := []struct {
+		daaScore       uint64
+		targetDAAScore uint64
+		expected       float64
+	}{
+		{daaScore: 0, targetDAAScore: 0, expected: 100},
+		{daaScore: 50, targetDAAScore: 200, expected: 25},
+		{daaScore: 200, targetDAAScore: 200, expected: 100},
+		{daaScore: 300, targetDAAScore: 200, expected: 100},
+	}
+
+	for _, test := range tests {
+		result := syncProgress(test.daaScore, test.targetDAAScore)
+		if result != test.expected {
+			t.Errorf("syncProgress(%d, %d): expected %f but got %f",
+				test.daaScore, test.targetDAAScore, test.expected, result)
+		}
+	}
+}
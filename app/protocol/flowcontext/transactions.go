@@ -4,27 +4,41 @@ import (
 	"time"
 
 	"github.com/kaspanet/kaspad/app/appmessage"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
 )
 
 // TransactionIDPropagationInterval is the interval between transaction IDs propagations
 const TransactionIDPropagationInterval = 500 * time.Millisecond
 
 // AddTransaction adds transaction to the mempool and propagates it.
+// Since the transaction originated in this node, it's passed along a Dandelion
+// stem if possible, and otherwise announced to most peers with a random delay.
+// See stemLocalTransaction and announceLocalTransactions.
 func (f *FlowContext) AddTransaction(tx *externalapi.DomainTransaction, allowOrphan bool) error {
 	acceptedTransactions, err := f.Domain().MiningManager().ValidateAndInsertTransaction(tx, true, allowOrphan)
 	if err != nil {
 		return err
 	}
 
+	f.OnTransactionAddedToMempool(acceptedTransactions)
+
 	acceptedTransactionIDs := consensushashing.TransactionIDs(acceptedTransactions)
-	return f.EnqueueTransactionIDsForPropagation(acceptedTransactionIDs)
+	isStemmed, err := f.stemLocalTransaction(tx, acceptedTransactionIDs)
+	if err != nil {
+		return err
+	}
+	if isStemmed {
+		return nil
+	}
+	return f.announceLocalTransactions(acceptedTransactionIDs)
 }
 
 func (f *FlowContext) shouldRebroadcastTransactions() bool {
 	const rebroadcastInterval = 30 * time.Second
-	return time.Since(f.lastRebroadcastTime) > rebroadcastInterval
+	return f.timeSource.Since(f.lastRebroadcastTime) > rebroadcastInterval
 }
 
 // SharedRequestedTransactions returns a *transactionrelay.SharedRequestedTransactions for sharing
@@ -33,11 +47,15 @@ func (f *FlowContext) SharedRequestedTransactions() *SharedRequestedTransactions
 	return f.sharedRequestedTransactions
 }
 
-// OnTransactionAddedToMempool notifies the handler function that a transaction
-// has been added to the mempool
-func (f *FlowContext) OnTransactionAddedToMempool() {
-	if f.onTransactionAddedToMempoolHandler != nil {
-		f.onTransactionAddedToMempoolHandler()
+// OnTransactionAddedToMempool notifies the handler function that transactions
+// have been added to the mempool. Transactions that were in their stem phase
+// are fluffed by now, so they're removed from the stempool.
+func (f *FlowContext) OnTransactionAddedToMempool(transactions []*externalapi.DomainTransaction) {
+	for _, transaction := range transactions {
+		f.stempool.remove(consensushashing.TransactionID(transaction))
+	}
+	if f.onTransactionAddedToMempoolHandler != nil && len(transactions) > 0 {
+		f.onTransactionAddedToMempoolHandler(transactions)
 	}
 }
 
@@ -54,7 +72,7 @@ func (f *FlowContext) EnqueueTransactionIDsForPropagation(transactionIDs []*exte
 }
 
 func (f *FlowContext) maybePropagateTransactions() error {
-	if time.Since(f.lastTransactionIDPropagationTime) < TransactionIDPropagationInterval &&
+	if f.timeSource.Since(f.lastTransactionIDPropagationTime) < TransactionIDPropagationInterval &&
 		len(f.transactionIDsToPropagate) < appmessage.MaxInvPerTxInvMsg {
 		return nil
 	}
@@ -66,8 +84,7 @@ func (f *FlowContext) maybePropagateTransactions() error {
 		}
 		log.Debugf("Transaction propagation: broadcasting %d transactions", len(transactionIDsToBroadcast))
 
-		inv := appmessage.NewMsgInvTransaction(transactionIDsToBroadcast)
-		err := f.Broadcast(inv)
+		err := f.broadcastTransactionIDs(transactionIDsToBroadcast)
 		if err != nil {
 			return err
 		}
@@ -75,7 +92,51 @@ func (f *FlowContext) maybePropagateTransactions() error {
 		f.transactionIDsToPropagate = f.transactionIDsToPropagate[len(transactionIDsToBroadcast):]
 	}
 
-	f.lastTransactionIDPropagationTime = time.Now()
+	f.lastTransactionIDPropagationTime = f.timeSource.Now()
 
 	return nil
 }
+
+// broadcastTransactionIDs announces the given transactions to all the ready peers
+// within a single transaction Inv message. Peers that loaded a bloom filter are
+// only announced the transactions that match it.
+func (f *FlowContext) broadcastTransactionIDs(transactionIDs []*externalapi.DomainTransactionID) error {
+	var unfilteredPeerConnections []*netadapter.NetConnection
+	for _, peer := range f.Peers() {
+		if peer.Filter().IsLoaded() {
+			err := f.announceTransactionsToPeer(peer, transactionIDs)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if peer.RelaysTransactions() {
+			unfilteredPeerConnections = append(unfilteredPeerConnections, peer.Connection())
+		}
+	}
+	return f.netAdapter.P2PBroadcast(unfilteredPeerConnections, appmessage.NewMsgInvTransaction(transactionIDs))
+}
+
+// transactionsToAnnounceToPeer returns the given transactions that should be announced
+// to the given peer: none if it asked not to be sent transactions, and only the ones
+// that match its bloom filter if it loaded one
+func (f *FlowContext) transactionsToAnnounceToPeer(peer *peerpkg.Peer,
+	transactionIDs []*externalapi.DomainTransactionID) []*externalapi.DomainTransactionID {
+
+	if !peer.RelaysTransactions() {
+		return nil
+	}
+	filter := peer.Filter()
+	if !filter.IsLoaded() {
+		return transactionIDs
+	}
+
+	var matchingTransactionIDs []*externalapi.DomainTransactionID
+	for _, transactionID := range transactionIDs {
+		transaction, _, found := f.Domain().MiningManager().GetTransaction(transactionID, true, false)
+		if found && filter.MatchTransactionAndUpdate(transaction) {
+			matchingTransactionIDs = append(matchingTransactionIDs, transactionID)
+		}
+	}
+	return matchingTransactionIDs
+}
@@ -0,0 +1,319 @@
+package flowcontext
+
+import (
+	"net"
+	"sort"
+	"time"
+
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// trustedPeer is what the node knows about the view of the DAG of one of its trusted peers
+type trustedPeer struct {
+	address string
+	ip      net.IP
+
+	announcedBlockHash *externalapi.DomainHash
+	announcementTime   time.Time
+
+	// agreedChainBlockHash is the highest block of the selected chain of the node
+	// that's also in the selected chain of the block the peer announced last. It's
+	// nil if the peer diverged, since the walk down its chain stops at the max divergence.
+	agreedChainBlockHash *externalapi.DomainHash
+	agreedDAAScore       uint64
+	isDiverged           bool
+}
+
+// TrustedPeerStatus describes how the view of the DAG of a trusted peer compares to the one of the node
+type TrustedPeerStatus struct {
+	Address              string
+	AnnouncedBlockHash   *externalapi.DomainHash
+	AnnouncementTime     time.Time
+	AgreedChainBlockHash *externalapi.DomainHash
+	IsDiverged           bool
+}
+
+func newTrustedPeers(addresses []string) []*trustedPeer {
+	trustedPeers := make([]*trustedPeer, 0, len(addresses))
+	for _, address := range addresses {
+		// The addresses are validated when the config is loaded
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			continue
+		}
+		trustedPeers = append(trustedPeers, &trustedPeer{address: address, ip: net.ParseIP(host)})
+	}
+	return trustedPeers
+}
+
+func (f *FlowContext) isTrustedModeEnabled() bool {
+	return len(f.trustedPeers) > 0
+}
+
+func (f *FlowContext) findTrustedPeer(peer *peerpkg.Peer) *trustedPeer {
+	// Outbound peers are found by the address they were dialed at, which may be
+	// a host name, such as that of an onion or I2P peer
+	for _, trustedPeer := range f.trustedPeers {
+		if trustedPeer.address == peer.Address() {
+			return trustedPeer
+		}
+	}
+
+	host, _, err := net.SplitHostPort(peer.Address())
+	if err != nil {
+		return nil
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil
+	}
+	for _, trustedPeer := range f.trustedPeers {
+		if trustedPeer.ip.Equal(ip) {
+			return trustedPeer
+		}
+	}
+	return nil
+}
+
+// trustedPeerComparison is where the selected chain of the block a trusted peer
+// announced forks from the selected chain of the node
+type trustedPeerComparison struct {
+	announcedBlockHash *externalapi.DomainHash
+	announcedDAAScore  uint64
+
+	// agreedChainBlockHash is nil if the fork is deeper than the max divergence
+	agreedChainBlockHash *externalapi.DomainHash
+	agreedDAAScore       uint64
+}
+
+// RecordAnnouncedBlock records the given block as the latest block the given peer
+// announced, if it's a trusted peer
+func (f *FlowContext) RecordAnnouncedBlock(peer *peerpkg.Peer, blockHash *externalapi.DomainHash) error {
+	if !f.isTrustedModeEnabled() {
+		return nil
+	}
+
+	f.trustedPeersLock.Lock()
+	trustedPeer := f.findTrustedPeer(peer)
+	if trustedPeer == nil {
+		f.trustedPeersLock.Unlock()
+		return nil
+	}
+	trustedPeer.announcedBlockHash = blockHash
+	trustedPeer.announcementTime = f.timeSource.Now()
+	f.trustedPeersLock.Unlock()
+
+	comparison, err := f.compareWithTrustedPeer(blockHash)
+	if err != nil {
+		return err
+	}
+
+	f.trustedPeersLock.Lock()
+	defer f.trustedPeersLock.Unlock()
+
+	f.applyTrustedPeerComparison(trustedPeer, comparison)
+	return nil
+}
+
+// compareWithTrustedPeers compares the selected chain of the node to the ones of all the trusted
+// peers, if the virtual selected parent changed since they were last compared. The comparisons
+// are made without holding trustedPeersLock, since they read from consensus.
+func (f *FlowContext) compareWithTrustedPeers() error {
+	if !f.isTrustedModeEnabled() || f.IsIBDRunning() {
+		return nil
+	}
+	virtualSelectedParent, err := f.Domain().Consensus().GetVirtualSelectedParent()
+	if err != nil {
+		return err
+	}
+
+	f.trustedPeersLock.Lock()
+	if f.lastComparedVirtualSelectedParent != nil && f.lastComparedVirtualSelectedParent.Equal(virtualSelectedParent) {
+		f.trustedPeersLock.Unlock()
+		return nil
+	}
+	f.lastComparedVirtualSelectedParent = virtualSelectedParent
+	trustedPeers := make([]*trustedPeer, len(f.trustedPeers))
+	announcedBlockHashes := make([]*externalapi.DomainHash, len(f.trustedPeers))
+	for i, trustedPeer := range f.trustedPeers {
+		trustedPeers[i] = trustedPeer
+		announcedBlockHashes[i] = trustedPeer.announcedBlockHash
+	}
+	f.trustedPeersLock.Unlock()
+
+	comparisons := make([]*trustedPeerComparison, len(trustedPeers))
+	for i, announcedBlockHash := range announcedBlockHashes {
+		comparisons[i], err = f.compareWithTrustedPeer(announcedBlockHash)
+		if err != nil {
+			return err
+		}
+	}
+
+	f.trustedPeersLock.Lock()
+	defer f.trustedPeersLock.Unlock()
+
+	for i, trustedPeer := range trustedPeers {
+		f.applyTrustedPeerComparison(trustedPeer, comparisons[i])
+	}
+	return nil
+}
+
+// compareWithTrustedPeer finds where the selected chain of the block a trusted peer announced
+// forks from the selected chain of the node. It returns nil if there's nothing to compare yet:
+// if the peer didn't announce a block, if the block wasn't processed yet, or during IBD.
+func (f *FlowContext) compareWithTrustedPeer(announcedBlockHash *externalapi.DomainHash) (*trustedPeerComparison, error) {
+	// The selected chain of the node changes with every block during IBD, so
+	// the comparison is postponed until it's over
+	if announcedBlockHash == nil || f.IsIBDRunning() {
+		return nil, nil
+	}
+	consensus := f.Domain().Consensus()
+	blockInfo, err := consensus.GetBlockInfo(announcedBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if !blockInfo.HasBody() {
+		return nil, nil
+	}
+	return findAgreedChainBlock(consensus, announcedBlockHash, f.cfg.TrustedPeerMaxDivergence)
+}
+
+// findAgreedChainBlock walks down the selected chain of the announced block until it meets the
+// selected chain of the node. The walk stops once it's more than maxDivergence DAA score below the
+// announced block, since the selected chains conflict then regardless of where they fork.
+func findAgreedChainBlock(consensus externalapi.Consensus, announcedBlockHash *externalapi.DomainHash,
+	maxDivergence uint64) (*trustedPeerComparison, error) {
+
+	virtualSelectedParent, err := consensus.GetVirtualSelectedParent()
+	if err != nil {
+		return nil, err
+	}
+	announcedBlockHeader, err := consensus.GetBlockHeader(announcedBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	comparison := &trustedPeerComparison{
+		announcedBlockHash: announcedBlockHash,
+		announcedDAAScore:  announcedBlockHeader.DAAScore(),
+	}
+
+	chainBlockHash := announcedBlockHash
+	chainBlockDAAScore := announcedBlockHeader.DAAScore()
+	for chainBlockDAAScore+maxDivergence >= comparison.announcedDAAScore {
+		isInSelectedChain, err := consensus.IsInSelectedParentChainOf(chainBlockHash, virtualSelectedParent)
+		if err != nil {
+			return nil, err
+		}
+		if isInSelectedChain {
+			comparison.agreedChainBlockHash = chainBlockHash
+			comparison.agreedDAAScore = chainBlockDAAScore
+			return comparison, nil
+		}
+
+		chainBlockInfo, err := consensus.GetBlockInfo(chainBlockHash)
+		if err != nil {
+			return nil, err
+		}
+		if chainBlockInfo.SelectedParent == nil {
+			break
+		}
+		chainBlockHash = chainBlockInfo.SelectedParent
+		chainBlockHeader, err := consensus.GetBlockHeader(chainBlockHash)
+		if err != nil {
+			return nil, err
+		}
+		chainBlockDAAScore = chainBlockHeader.DAAScore()
+	}
+	return comparison, nil
+}
+
+// applyTrustedPeerComparison records the given comparison for the trusted peer, and raises
+// a divergence alarm if the announced block is too deep in a conflicting chain. Comparisons
+// of blocks other than the one the peer announced last are outdated, and are ignored.
+func (f *FlowContext) applyTrustedPeerComparison(trustedPeer *trustedPeer, comparison *trustedPeerComparison) {
+	if comparison == nil || trustedPeer.announcedBlockHash == nil ||
+		!trustedPeer.announcedBlockHash.Equal(comparison.announcedBlockHash) {
+
+		return
+	}
+
+	// Blocks that are announced as soon as they're mined usually aren't in the selected
+	// chain, but fork from it just below them. The selected chain of the trusted peer
+	// conflicts with the one of the node only if the fork is deep below the announced block.
+	wasDiverged := trustedPeer.isDiverged
+	trustedPeer.isDiverged = comparison.agreedChainBlockHash == nil
+	trustedPeer.agreedChainBlockHash = comparison.agreedChainBlockHash
+	trustedPeer.agreedDAAScore = comparison.agreedDAAScore
+	if trustedPeer.isDiverged && !wasDiverged {
+		log.Warnf("DIVERGENCE ALARM: the selected chain of the trusted peer %s forks from the selected chain "+
+			"of the node more than %d DAA score below the block %s it announced",
+			trustedPeer.address, f.cfg.TrustedPeerMaxDivergence, comparison.announcedBlockHash)
+	} else if !trustedPeer.isDiverged && wasDiverged {
+		log.Infof("The selected chain of the trusted peer %s agrees with the selected chain of the node again",
+			trustedPeer.address)
+	}
+}
+
+// isTrustedChainCurrent returns whether none of the trusted peers diverged and enough of them reported
+// a selected chain block that's at most the max divergence below the given DAA score of the selected tip
+func (f *FlowContext) isTrustedChainCurrent(sinkDAAScore uint64) bool {
+	if !f.isTrustedModeEnabled() {
+		return true
+	}
+
+	f.trustedPeersLock.Lock()
+	defer f.trustedPeersLock.Unlock()
+
+	for _, trustedPeer := range f.trustedPeers {
+		if trustedPeer.isDiverged {
+			return false
+		}
+	}
+	quorumPeer := quorumTrustedPeer(f.trustedPeers, f.cfg.TrustedPeerQuorum)
+	return quorumPeer != nil && quorumPeer.agreedDAAScore+f.cfg.TrustedPeerMaxDivergence >= sinkDAAScore
+}
+
+// quorumTrustedPeer returns the trusted peer with the quorum-th highest agreed selected chain
+// block, which the quorum of trusted peers all reported, or nil if there's no such peer
+func quorumTrustedPeer(trustedPeers []*trustedPeer, quorum int) *trustedPeer {
+	agreeingPeers := make([]*trustedPeer, 0, len(trustedPeers))
+	for _, trustedPeer := range trustedPeers {
+		if trustedPeer.agreedChainBlockHash != nil && !trustedPeer.isDiverged {
+			agreeingPeers = append(agreeingPeers, trustedPeer)
+		}
+	}
+	if quorum < 1 || len(agreeingPeers) < quorum {
+		return nil
+	}
+	sort.Slice(agreeingPeers, func(i, j int) bool {
+		return agreeingPeers[i].agreedDAAScore > agreeingPeers[j].agreedDAAScore
+	})
+	return agreeingPeers[quorum-1]
+}
+
+// TrustedPeersStatus returns how the views of the DAG of the trusted peers compare to
+// the one of the node, and the highest selected chain block that the quorum of trusted
+// peers reported, which is nil if the quorum didn't report any
+func (f *FlowContext) TrustedPeersStatus() (
+	trustedPeersStatus []*TrustedPeerStatus, trustedChainBlockHash *externalapi.DomainHash) {
+
+	f.trustedPeersLock.Lock()
+	defer f.trustedPeersLock.Unlock()
+
+	trustedPeersStatus = make([]*TrustedPeerStatus, 0, len(f.trustedPeers))
+	for _, trustedPeer := range f.trustedPeers {
+		trustedPeersStatus = append(trustedPeersStatus, &TrustedPeerStatus{
+			Address:              trustedPeer.address,
+			AnnouncedBlockHash:   trustedPeer.announcedBlockHash,
+			AnnouncementTime:     trustedPeer.announcementTime,
+			AgreedChainBlockHash: trustedPeer.agreedChainBlockHash,
+			IsDiverged:           trustedPeer.isDiverged,
+		})
+	}
+	quorumPeer := quorumTrustedPeer(f.trustedPeers, f.cfg.TrustedPeerQuorum)
+	if quorumPeer != nil {
+		trustedChainBlockHash = quorumPeer.agreedChainBlockHash
+	}
+	return trustedPeersStatus, trustedChainBlockHash
+}
@@ -0,0 +1,95 @@
+package flowcontext
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+)
+
+func TestQuorumTrustedPeer(t *testing.T) {
+	agreedPeer := func(daaScore uint64) *trustedPeer {
+		return &trustedPeer{
+			agreedChainBlockHash: externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{byte(daaScore)}),
+			agreedDAAScore:       daaScore,
+		}
+	}
+	silentPeer := &trustedPeer{}
+	divergedPeer := agreedPeer(50)
+	divergedPeer.isDiverged = true
+	trustedPeers := []*trustedPeer{agreedPeer(10), silentPeer, agreedPeer(30), divergedPeer, agreedPeer(20)}
+
+	tests := []struct {
+		quorum           int
+		expectedDAAScore uint64
+		expectedNil      bool
+	}{
+		{quorum: 1, expectedDAAScore: 30},
+		{quorum: 2, expectedDAAScore: 20},
+		{quorum: 3, expectedDAAScore: 10},
+		// Neither peers that didn't report anything nor diverged peers count towards the quorum
+		{quorum: 4, expectedNil: true},
+	}
+	for _, test := range tests {
+		quorumPeer := quorumTrustedPeer(trustedPeers, test.quorum)
+		if test.expectedNil {
+			if quorumPeer != nil {
+				t.Fatalf("quorum %d: expected no quorum but got DAA score %d", test.quorum, quorumPeer.agreedDAAScore)
+			}
+			continue
+		}
+		if quorumPeer == nil || quorumPeer.agreedDAAScore != test.expectedDAAScore {
+			t.Fatalf("quorum %d: expected DAA score %d but got %+v", test.quorum, test.expectedDAAScore, quorumPeer)
+		}
+	}
+}
+
+func TestFindAgreedChainBlock(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestFindAgreedChainBlock")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		addChain := func(length int) *externalapi.DomainHash {
+			tip := consensusConfig.GenesisHash
+			for i := 0; i < length; i++ {
+				tip, _, err = tc.AddBlock([]*externalapi.DomainHash{tip}, nil, nil)
+				if err != nil {
+					t.Fatalf("AddBlock: %+v", err)
+				}
+			}
+			return tip
+		}
+		selectedTip := addChain(30)
+		forkTip := addChain(15)
+
+		comparison, err := findAgreedChainBlock(tc, selectedTip, 10)
+		if err != nil {
+			t.Fatalf("findAgreedChainBlock: %+v", err)
+		}
+		if comparison.agreedChainBlockHash == nil || !comparison.agreedChainBlockHash.Equal(selectedTip) {
+			t.Fatalf("expected the selected tip to agree with itself, but got %s", comparison.agreedChainBlockHash)
+		}
+
+		comparison, err = findAgreedChainBlock(tc, forkTip, 10)
+		if err != nil {
+			t.Fatalf("findAgreedChainBlock: %+v", err)
+		}
+		if comparison.agreedChainBlockHash != nil {
+			t.Fatalf("expected a fork deeper than the max divergence to diverge, but it agrees at %s",
+				comparison.agreedChainBlockHash)
+		}
+
+		comparison, err = findAgreedChainBlock(tc, forkTip, 20)
+		if err != nil {
+			t.Fatalf("findAgreedChainBlock: %+v", err)
+		}
+		if comparison.agreedChainBlockHash == nil || !comparison.agreedChainBlockHash.Equal(consensusConfig.GenesisHash) {
+			t.Fatalf("expected the fork to agree at the genesis, but got %s", comparison.agreedChainBlockHash)
+		}
+	})
+}
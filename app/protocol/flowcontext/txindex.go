@@ -0,0 +1,8 @@
+package flowcontext
+
+import "github.com/kaspanet/kaspad/domain/txindex"
+
+// TXIndex returns the transaction index, or nil if it's disabled
+func (f *FlowContext) TXIndex() *txindex.TXIndex {
+	return f.txIndex
+}
@@ -21,6 +21,8 @@ import (
 // HandleHandshakeContext is the interface for the context needed for the HandleHandshake flow.
 type HandleHandshakeContext interface {
 	Config() *config.Config
+	LocalServices() appmessage.ServiceFlag
+	AcceptsTransactionsFrom(peer *peerpkg.Peer) bool
 	NetAdapter() *netadapter.NetAdapter
 	Domain() domain.Domain
 	AddressManager() *addressmanager.AddressManager
@@ -89,11 +91,21 @@ func HandleHandshake(context HandleHandshakeContext, netConnection *netadapter.N
 	}
 
 	if peerAddress != nil {
-		err := context.AddressManager().AddAddresses(peerAddress)
+		err := context.AddressManager().AddAddressesFromSource(peer.Connection().NetAddress(), peerAddress)
 		if err != nil {
 			return nil, err
 		}
 	}
+
+	// Only the services of outbound peers are recorded, since the address of an inbound
+	// peer isn't the one it listens on. Peers that were connected to with --connect or
+	// --addpeer may be missing from the address manager.
+	if peer.IsOutbound() {
+		err := context.AddressManager().SetServices(peer.Connection().NetAddress(), peer.Services())
+		if err != nil && !errors.Is(err, addressmanager.ErrAddressNotFound) {
+			return nil, err
+		}
+	}
 	return peer, nil
 }
 
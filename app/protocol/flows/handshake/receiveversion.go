@@ -100,6 +100,14 @@ func (flow *receiveVersionFlow) start() (*appmessage.NetAddress, error) {
 		return nil, protocolerrors.New(false, "incompatible subnetworks")
 	}
 
+	// Both peers support encryption, so the connection should have been encrypted. It
+	// may have been downgraded to plaintext by someone who intercepts it.
+	isEncryptionSupported := !flow.Config().DisableP2PEncryption &&
+		msgVersion.HasService(appmessage.SFNodeEncryptedTransport)
+	if isEncryptionSupported && !flow.peer.Connection().IsEncrypted() {
+		return nil, protocolerrors.New(false, "the peer supports encryption, but the connection is in plaintext")
+	}
+
 	if flow.Config().ProtocolVersion > maxAcceptableProtocolVersion {
 		return nil, errors.Errorf("%d is a non existing protocol version", flow.Config().ProtocolVersion)
 	}
@@ -19,10 +19,6 @@ var (
 	// identify ourselves to other kaspa peers.
 	userAgentVersion = version.Version()
 
-	// defaultServices describes the default services that are supported by
-	// the server.
-	defaultServices = appmessage.DefaultServices
-
 	// defaultRequiredServices describes the default services that are
 	// required to be supported by outbound peers.
 	defaultRequiredServices = appmessage.SFNodeNetwork
@@ -65,13 +61,13 @@ func (flow *sendVersionFlow) start() error {
 	msg.AddUserAgent(userAgentName, userAgentVersion, flow.Config().UserAgentComments...)
 
 	// Advertise the services flag
-	msg.Services = defaultServices
+	msg.Services = flow.LocalServices()
 
 	// Advertise our max supported protocol version.
 	msg.ProtocolVersion = flow.Config().ProtocolVersion
 
-	// Advertise if inv messages for transactions are desired.
-	msg.DisableRelayTx = flow.Config().BlocksOnly
+	// Advertise if inv messages for transactions are desired
+	msg.DisableRelayTx = !flow.AcceptsTransactionsFrom(flow.peer)
 
 	err := flow.outgoingRoute.Enqueue(msg)
 	if err != nil {
@@ -0,0 +1,51 @@
+package addressexchange
+
+import (
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+)
+
+const (
+	// unsolicitedAddressesPerSecond is the rate at which unsolicited addresses
+	// of a single peer are processed. Addresses above that rate are dropped.
+	unsolicitedAddressesPerSecond = 0.1
+
+	// maxUnsolicitedAddressesBurst is the most unsolicited addresses
+	// of a single peer that are processed at once
+	maxUnsolicitedAddressesBurst = appmessage.MaxAddressesPerMsg
+)
+
+// addressRateLimiter is a token bucket that limits the rate at which
+// unsolicited addresses sent by a peer are processed
+type addressRateLimiter struct {
+	tokens     float64
+	lastUpdate time.Time
+}
+
+// newAddressRateLimiter returns an addressRateLimiter that initially allows
+// a single address, so that the peer may announce its own address right away
+func newAddressRateLimiter(now time.Time) *addressRateLimiter {
+	return &addressRateLimiter{
+		tokens:     1,
+		lastUpdate: now,
+	}
+}
+
+// take returns how many out of the given number of addresses may be processed at the given time
+func (l *addressRateLimiter) take(addressCount int, now time.Time) int {
+	if now.After(l.lastUpdate) {
+		l.tokens += now.Sub(l.lastUpdate).Seconds() * unsolicitedAddressesPerSecond
+		if l.tokens > maxUnsolicitedAddressesBurst {
+			l.tokens = maxUnsolicitedAddressesBurst
+		}
+		l.lastUpdate = now
+	}
+
+	allowed := addressCount
+	if float64(allowed) > l.tokens {
+		allowed = int(l.tokens)
+	}
+	l.tokens -= float64(allowed)
+	return allowed
+}
@@ -0,0 +1,36 @@
+package addressexchange
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAddressRateLimiter(t *testing.T) {
+	start := time.Now()
+	limiter := newAddressRateLimiter(start)
+
+	// Only a single address is allowed right away
+	if allowed := limiter.take(10, start); allowed != 1 {
+		t.Fatalf("Unexpected allowed address count. Want: 1, got: %d", allowed)
+	}
+	if allowed := limiter.take(10, start); allowed != 0 {
+		t.Fatalf("Unexpected allowed address count. Want: 0, got: %d", allowed)
+	}
+
+	// Tokens are refilled with time
+	now := start.Add(50 * time.Second)
+	if allowed := limiter.take(10, now); allowed != 5 {
+		t.Fatalf("Unexpected allowed address count. Want: 5, got: %d", allowed)
+	}
+
+	// The bucket is capped at maxUnsolicitedAddressesBurst
+	now = now.Add(24 * time.Hour)
+	if allowed := limiter.take(2*maxUnsolicitedAddressesBurst, now); allowed != maxUnsolicitedAddressesBurst {
+		t.Fatalf("Unexpected allowed address count. Want: %d, got: %d", maxUnsolicitedAddressesBurst, allowed)
+	}
+
+	// A clock that goes backwards doesn't refill the bucket
+	if allowed := limiter.take(10, start); allowed != 0 {
+		t.Fatalf("Unexpected allowed address count. Want: 0, got: %d", allowed)
+	}
+}
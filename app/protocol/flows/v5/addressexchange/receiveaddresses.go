@@ -1,6 +1,8 @@
 package addressexchange
 
 import (
+	"time"
+
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/app/protocol/common"
 	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
@@ -11,10 +13,11 @@ import (
 
 // ReceiveAddressesContext is the interface for the context needed for the ReceiveAddresses flow.
 type ReceiveAddressesContext interface {
-	AddressManager() *addressmanager.AddressManager
+	AddAddresses(source *appmessage.NetAddress, addresses ...*appmessage.NetAddress) error
 }
 
-// ReceiveAddresses asks a peer for more addresses if needed.
+// ReceiveAddresses asks a peer for more addresses if needed, and then keeps processing
+// the addresses the peer sends unsolicited, at a limited rate.
 func ReceiveAddresses(context ReceiveAddressesContext, incomingRoute *router.Route, outgoingRoute *router.Route,
 	peer *peerpkg.Peer) error {
 
@@ -30,10 +33,55 @@ func ReceiveAddresses(context ReceiveAddressesContext, incomingRoute *router.Rou
 		return err
 	}
 
+	msgAddresses, err := readAddresses(message)
+	if err != nil {
+		return err
+	}
+
+	// The addresses are grouped by the peer they were learned from, so that a single
+	// peer can only fill a small part of the address manager
+	source := peer.Connection().NetAddress()
+	err = context.AddAddresses(source, msgAddresses.AddressList...)
+	if err != nil {
+		return err
+	}
+
+	// Unsolicited addresses are rate limited, so that a peer spraying
+	// addresses can neither flood the address manager nor waste our CPU
+	rateLimiter := newAddressRateLimiter(time.Now())
+	for {
+		message, err := incomingRoute.Dequeue()
+		if err != nil {
+			return err
+		}
+
+		msgAddresses, err := readAddresses(message)
+		if err != nil {
+			return err
+		}
+
+		addressList := msgAddresses.AddressList
+		allowedCount := rateLimiter.take(len(addressList), time.Now())
+		if allowedCount < len(addressList) {
+			log.Debugf("Dropping %d out of %d unsolicited addresses from %s due to rate limiting",
+				len(addressList)-allowedCount, len(addressList), peer)
+			addressList = addressList[:allowedCount]
+		}
+		if len(addressList) == 0 {
+			continue
+		}
+
+		err = context.AddAddresses(source, addressList...)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func readAddresses(message appmessage.Message) (*appmessage.MsgAddresses, error) {
 	msgAddresses := message.(*appmessage.MsgAddresses)
 	if len(msgAddresses.AddressList) > addressmanager.GetAddressesMax {
-		return protocolerrors.Errorf(true, "address count exceeded %d", addressmanager.GetAddressesMax)
+		return nil, protocolerrors.Errorf(true, "address count exceeded %d", addressmanager.GetAddressesMax)
 	}
-
-	return context.AddressManager().AddAddresses(msgAddresses.AddressList...)
+	return msgAddresses, nil
 }
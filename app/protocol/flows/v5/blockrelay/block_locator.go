@@ -22,6 +22,11 @@ func (flow *handleRelayInvsFlow) receiveBlockLocator() (blockLocatorHashes []*ex
 		switch message := message.(type) {
 		case *appmessage.MsgInvRelayBlock:
 			flow.invsQueue = append(flow.invsQueue, invRelayBlock{Hash: message.Hash, IsOrphanRoot: false})
+		case *appmessage.MsgAntichainLocator:
+			err := flow.queueAntichainLocatorMissingBlocks(message)
+			if err != nil {
+				return nil, err
+			}
 		case *appmessage.MsgBlockLocator:
 			return message.BlockLocatorHashes, nil
 		default:
@@ -31,3 +36,37 @@ func (flow *handleRelayInvsFlow) receiveBlockLocator() (blockLocatorHashes []*ex
 		}
 	}
 }
+
+// requestAntichainLocator requests the antichain locator of the peer, if it serves them.
+// The locator is handled whenever it arrives, along with the invs of the peer.
+func (flow *handleRelayInvsFlow) requestAntichainLocator() error {
+	if !flow.peer.HasService(appmessage.SFNodeAntichainLocator) {
+		return nil
+	}
+	return flow.outgoingRoute.Enqueue(appmessage.NewMsgRequestAntichainLocator())
+}
+
+// queueAntichainLocatorMissingBlocks adds the tips of the antichain locator of the peer that
+// this node is missing to the invs queue, as if the peer relayed them. This catches up on
+// the blocks the peer has in the anticone of its selected chain, which wouldn't be found by
+// following the selected parent chain alone.
+func (flow *handleRelayInvsFlow) queueAntichainLocatorMissingBlocks(message *appmessage.MsgAntichainLocator) error {
+	highestSharedChainHash, missingHashes, err :=
+		flow.Domain().Consensus().AntichainLocatorMissingBlocks(message.AntichainLocator())
+	if err != nil {
+		return err
+	}
+	if len(missingHashes) == 0 {
+		return nil
+	}
+
+	if highestSharedChainHash == nil {
+		log.Debugf("None of the selected chain blocks of the antichain locator of %s are known. "+
+			"Its missing tips are resolved as orphans or by IBD", flow.peer)
+	}
+	log.Debugf("Adding %d missing tips of the antichain locator of %s to the invs queue", len(missingHashes), flow.peer)
+	for _, missingHash := range missingHashes {
+		flow.invsQueue = append(flow.invsQueue, invRelayBlock{Hash: missingHash, IsOrphanRoot: false})
+	}
+	return nil
+}
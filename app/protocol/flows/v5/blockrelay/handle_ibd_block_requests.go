@@ -2,6 +2,7 @@ package blockrelay
 
 import (
 	"github.com/kaspanet/kaspad/app/appmessage"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
 	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
 	"github.com/kaspanet/kaspad/domain"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
@@ -11,12 +12,13 @@ import (
 // HandleIBDBlockRequestsContext is the interface for the context needed for the HandleIBDBlockRequests flow.
 type HandleIBDBlockRequestsContext interface {
 	Domain() domain.Domain
+	ShouldServeHistoricalData() bool
 }
 
 // HandleIBDBlockRequests listens to appmessage.MsgRequestRelayBlocks messages and sends
 // their corresponding blocks to the requesting peer.
 func HandleIBDBlockRequests(context HandleIBDBlockRequestsContext, incomingRoute *router.Route,
-	outgoingRoute *router.Route) error {
+	outgoingRoute *router.Route, peer *peerpkg.Peer) error {
 
 	for {
 		message, err := incomingRoute.Dequeue()
@@ -25,6 +27,9 @@ func HandleIBDBlockRequests(context HandleIBDBlockRequestsContext, incomingRoute
 		}
 		msgRequestIBDBlocks := message.(*appmessage.MsgRequestIBDBlocks)
 		log.Debugf("Got request for %d ibd blocks", len(msgRequestIBDBlocks.Hashes))
+		if !shouldServeHistoricalData(context, peer) {
+			return errUploadTargetReached
+		}
 		for i, hash := range msgRequestIBDBlocks.Hashes {
 			// Fetch the block from the database.
 			block, found, err := context.Domain().Consensus().GetBlock(hash)
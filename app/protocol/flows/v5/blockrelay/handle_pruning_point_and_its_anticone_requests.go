@@ -15,6 +15,7 @@ import (
 type PruningPointAndItsAnticoneRequestsContext interface {
 	Domain() domain.Domain
 	Config() *config.Config
+	ShouldServeHistoricalData() bool
 }
 
 var isBusy uint32
@@ -37,6 +38,9 @@ func HandlePruningPointAndItsAnticoneRequests(context PruningPointAndItsAnticone
 			defer atomic.StoreUint32(&isBusy, 0)
 
 			log.Debugf("Got request for pruning point and its anticone from %s", peer)
+			if !shouldServeHistoricalData(context, peer) {
+				return errUploadTargetReached
+			}
 
 			pruningPointHeaders, err := context.Domain().Consensus().PruningPointHeaders()
 			if err != nil {
@@ -13,7 +13,9 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/hashset"
 	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util/processingstats"
 	"github.com/pkg/errors"
 )
 
@@ -37,6 +39,7 @@ type RelayInvsContext interface {
 	IsIBDRunning() bool
 	IsRecoverableError(err error) bool
 	IsNearlySynced() (bool, error)
+	RecordAnnouncedBlock(peer *peerpkg.Peer, blockHash *externalapi.DomainHash) error
 }
 
 type invRelayBlock struct {
@@ -70,6 +73,11 @@ func HandleRelayInvs(context RelayInvsContext, incomingRoute *router.Route, outg
 }
 
 func (flow *handleRelayInvsFlow) start() error {
+	err := flow.requestAntichainLocator()
+	if err != nil {
+		return err
+	}
+
 	for {
 		log.Debugf("Waiting for inv")
 		inv, err := flow.readInv()
@@ -77,7 +85,15 @@ func (flow *handleRelayInvsFlow) start() error {
 			return err
 		}
 
-		log.Debugf("Got relay inv for block %s", inv.Hash)
+		// The messages about the block carry the peer that relayed it and its hash
+		// as fields, so that they can be correlated in structured logs
+		blockLog := log.With(logger.Peer(flow.peer), logger.BlockHash(inv.Hash))
+		blockLog.Debugf("Got relay inv for block %s", inv.Hash)
+
+		err = flow.RecordAnnouncedBlock(flow.peer, inv.Hash)
+		if err != nil {
+			return err
+		}
 
 		blockInfo, err := flow.Domain().Consensus().GetBlockInfo(inv.Hash)
 		if err != nil {
@@ -88,7 +104,7 @@ func (flow *handleRelayInvsFlow) start() error {
 				return protocolerrors.Errorf(true, "sent inv of an invalid block %s",
 					inv.Hash)
 			}
-			log.Debugf("Block %s already exists. continuing...", inv.Hash)
+			blockLog.Debugf("Block %s already exists. continuing...", inv.Hash)
 			continue
 		}
 
@@ -99,12 +115,12 @@ func (flow *handleRelayInvsFlow) start() error {
 
 		if flow.IsOrphan(inv.Hash) {
 			if flow.Config().NetParams().DisallowDirectBlocksOnTopOfGenesis && !flow.Config().AllowSubmitBlockWhenNotSynced && isGenesisVirtualSelectedParent {
-				log.Infof("Cannot process orphan %s for a node with only the genesis block. The node needs to IBD "+
+				blockLog.Infof("Cannot process orphan %s for a node with only the genesis block. The node needs to IBD "+
 					"to the recent pruning point before normal operation can resume.", inv.Hash)
 				continue
 			}
 
-			log.Debugf("Block %s is a known orphan. Requesting its missing ancestors", inv.Hash)
+			blockLog.Debugf("Block %s is a known orphan. Requesting its missing ancestors", inv.Hash)
 			err := flow.AddOrphanRootsToQueue(inv.Hash)
 			if err != nil {
 				return err
@@ -119,18 +135,18 @@ func (flow *handleRelayInvsFlow) start() error {
 				return err
 			}
 			if !isNearlySynced {
-				log.Debugf("Got block %s while in IBD and the node is out of sync. Continuing...", inv.Hash)
+				blockLog.Debugf("Got block %s while in IBD and the node is out of sync. Continuing...", inv.Hash)
 				continue
 			}
 		}
 
-		log.Debugf("Requesting block %s", inv.Hash)
+		blockLog.Debugf("Requesting block %s", inv.Hash)
 		block, exists, err := flow.requestBlock(inv.Hash)
 		if err != nil {
 			return err
 		}
 		if exists {
-			log.Debugf("Aborting requesting block %s because it already exists", inv.Hash)
+			blockLog.Debugf("Aborting requesting block %s because it already exists", inv.Hash)
 			continue
 		}
 
@@ -140,7 +156,7 @@ func (flow *handleRelayInvsFlow) start() error {
 		}
 
 		if flow.Config().NetParams().DisallowDirectBlocksOnTopOfGenesis && !flow.Config().AllowSubmitBlockWhenNotSynced && !flow.Config().Devnet && flow.isChildOfGenesis(block) {
-			log.Infof("Cannot process %s because it's a direct child of genesis.", consensushashing.BlockHash(block))
+			blockLog.Infof("Cannot process %s because it's a direct child of genesis.", consensushashing.BlockHash(block))
 			continue
 		}
 
@@ -161,14 +177,14 @@ func (flow *handleRelayInvsFlow) start() error {
 				// block is not in the future of virtual's merge depth root, and thus cannot be merged unless
 				// other valid blocks Kosherize it, in which case it will be obtained once the merger is relayed
 				if block.Header.BlueWork().Cmp(mergeDepthRootHeader.BlueWork()) <= 0 {
-					log.Debugf("Block %s has lower blue work than virtual's merge root %s (%d <= %d), hence we are skipping it",
+					blockLog.Debugf("Block %s has lower blue work than virtual's merge root %s (%d <= %d), hence we are skipping it",
 						inv.Hash, virtualMergeDepthRoot, block.Header.BlueWork(), mergeDepthRootHeader.BlueWork())
 					continue
 				}
 			}
 		}
 
-		log.Debugf("Processing block %s", inv.Hash)
+		blockLog.Debugf("Processing block %s", inv.Hash)
 		oldVirtualInfo, err := flow.Domain().Consensus().GetVirtualInfo()
 		if err != nil {
 			return err
@@ -176,18 +192,35 @@ func (flow *handleRelayInvsFlow) start() error {
 		missingParents, err := flow.processBlock(block)
 		if err != nil {
 			if errors.Is(err, ruleerrors.ErrPrunedBlock) {
-				log.Infof("Ignoring pruned block %s", inv.Hash)
+				blockLog.Infof("Ignoring pruned block %s", inv.Hash)
 				continue
 			}
 
 			if errors.Is(err, ruleerrors.ErrDuplicateBlock) {
-				log.Infof("Ignoring duplicate block %s", inv.Hash)
+				blockLog.Infof("Ignoring duplicate block %s", inv.Hash)
+				continue
+			}
+
+			// A block that a block validator rejected is a matter of local policy rather than
+			// of consensus, so the peer isn't punished for relaying it
+			if errors.Is(err, externalapi.ErrRejectedByBlockValidator) {
+				blockLog.Infof("Ignoring block %s from %s: %s", inv.Hash, flow.peer, err)
+				continue
+			}
+
+			// A block too far in the future might only be so because of the clock of either node,
+			// so instead of banning the peer for it, it's deprioritized as a sync peer if it keeps
+			// relaying such blocks
+			if errors.Is(err, ruleerrors.ErrTimeTooMuchInTheFuture) {
+				farFutureBlockCount := flow.peer.RecordFarFutureBlock()
+				blockLog.Infof("Ignoring block %s, which is too far in the future. %s relayed %d such blocks",
+					inv.Hash, flow.peer, farFutureBlockCount)
 				continue
 			}
 			return err
 		}
 		if len(missingParents) > 0 {
-			log.Debugf("Block %s is orphan and has missing parents: %s", inv.Hash, missingParents)
+			blockLog.Debugf("Block %s is orphan and has missing parents: %s", inv.Hash, missingParents)
 			err := flow.processOrphan(block)
 			if err != nil {
 				return err
@@ -235,7 +268,7 @@ func (flow *handleRelayInvsFlow) start() error {
 			}
 		}
 
-		log.Infof("Accepted block %s via relay", inv.Hash)
+		blockLog.Infof("Accepted block %s via relay", inv.Hash)
 		err = flow.OnNewBlock(block)
 		if err != nil {
 			return err
@@ -264,11 +297,20 @@ func (flow *handleRelayInvsFlow) readInv() (invRelayBlock, error) {
 		return invRelayBlock{}, err
 	}
 
+	if msgAntichainLocator, ok := msg.(*appmessage.MsgAntichainLocator); ok {
+		err := flow.queueAntichainLocatorMissingBlocks(msgAntichainLocator)
+		if err != nil {
+			return invRelayBlock{}, err
+		}
+		return flow.readInv()
+	}
+
 	msgInv, ok := msg.(*appmessage.MsgInvRelayBlock)
 	if !ok {
 		return invRelayBlock{}, protocolerrors.Errorf(true, "unexpected %s message in the block relay handleRelayInvsFlow while "+
 			"expecting an inv message", msg.Command())
 	}
+	flow.peer.RecordBlockAnnouncement()
 	return invRelayBlock{Hash: msgInv.Hash, IsOrphanRoot: false}, nil
 }
 
@@ -293,7 +335,9 @@ func (flow *handleRelayInvsFlow) requestBlock(requestHash *externalapi.DomainHas
 		return nil, false, err
 	}
 
+	onDeserializationEnd := processingstats.Measure(processingstats.Deserialization)
 	block := appmessage.MsgBlockToDomainBlock(msgBlock)
+	onDeserializationEnd()
 	blockHash := consensushashing.BlockHash(block)
 	if !blockHash.Equal(requestHash) {
 		return nil, false, protocolerrors.Errorf(true, "got unrequested block %s", blockHash)
@@ -304,7 +348,8 @@ func (flow *handleRelayInvsFlow) requestBlock(requestHash *externalapi.DomainHas
 
 // readMsgBlock returns the next msgBlock in msgChan, and populates invsQueue with any inv messages that meanwhile arrive.
 //
-// Note: this function assumes msgChan can contain only appmessage.MsgInvRelayBlock and appmessage.MsgBlock messages.
+// Note: this function assumes msgChan can contain only appmessage.MsgInvRelayBlock, appmessage.MsgAntichainLocator
+// and appmessage.MsgBlock messages.
 func (flow *handleRelayInvsFlow) readMsgBlock() (msgBlock *appmessage.MsgBlock, err error) {
 	for {
 		message, err := flow.incomingRoute.DequeueWithTimeout(common.DefaultTimeout)
@@ -315,6 +360,11 @@ func (flow *handleRelayInvsFlow) readMsgBlock() (msgBlock *appmessage.MsgBlock,
 		switch message := message.(type) {
 		case *appmessage.MsgInvRelayBlock:
 			flow.invsQueue = append(flow.invsQueue, invRelayBlock{Hash: message.Hash, IsOrphanRoot: false})
+		case *appmessage.MsgAntichainLocator:
+			err := flow.queueAntichainLocatorMissingBlocks(message)
+			if err != nil {
+				return nil, err
+			}
 		case *appmessage.MsgBlock:
 			return message, nil
 		default:
@@ -327,6 +377,9 @@ func (flow *handleRelayInvsFlow) processBlock(block *externalapi.DomainBlock) ([
 	blockHash := consensushashing.BlockHash(block)
 	err := flow.Domain().Consensus().ValidateAndInsertBlock(block, true)
 	if err != nil {
+		if errors.Is(err, externalapi.ErrRejectedByBlockValidator) {
+			return nil, err
+		}
 		if !errors.As(err, &ruleerrors.RuleError{}) {
 			return nil, errors.Wrapf(err, "failed to process block %s", blockHash)
 		}
@@ -335,11 +388,12 @@ func (flow *handleRelayInvsFlow) processBlock(block *externalapi.DomainBlock) ([
 		if errors.As(err, missingParentsError) {
 			return missingParentsError.MissingParentHashes, nil
 		}
-		// A duplicate block should not appear to the user as a warning and is already reported in the calling function
-		if !errors.Is(err, ruleerrors.ErrDuplicateBlock) {
+		// A duplicate block or a block too far in the future should not appear to the user as a warning and is
+		// already reported in the calling function
+		if !errors.Is(err, ruleerrors.ErrDuplicateBlock) && !errors.Is(err, ruleerrors.ErrTimeTooMuchInTheFuture) {
 			log.Warnf("Rejected block %s from %s: %s", blockHash, flow.peer, err)
 		}
-		return nil, protocolerrors.Wrapf(true, err, "got invalid block %s from relay", blockHash)
+		return nil, protocolerrors.WrapRejectedf(true, err, blockHash, "got invalid block %s from relay", blockHash)
 	}
 	return nil, nil
 }
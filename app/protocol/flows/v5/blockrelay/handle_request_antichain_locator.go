@@ -0,0 +1,50 @@
+package blockrelay
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// RequestAntichainLocatorContext is the interface for the context needed for the HandleRequestAntichainLocator flow.
+type RequestAntichainLocatorContext interface {
+	Domain() domain.Domain
+}
+
+type handleRequestAntichainLocatorFlow struct {
+	RequestAntichainLocatorContext
+	incomingRoute, outgoingRoute *router.Route
+}
+
+// HandleRequestAntichainLocator handles requestAntichainLocator messages
+func HandleRequestAntichainLocator(context RequestAntichainLocatorContext, incomingRoute *router.Route,
+	outgoingRoute *router.Route) error {
+
+	flow := &handleRequestAntichainLocatorFlow{
+		RequestAntichainLocatorContext: context,
+		incomingRoute:                  incomingRoute,
+		outgoingRoute:                  outgoingRoute,
+	}
+	return flow.start()
+}
+
+func (flow *handleRequestAntichainLocatorFlow) start() error {
+	for {
+		_, err := flow.incomingRoute.Dequeue()
+		if err != nil {
+			return err
+		}
+		log.Debugf("Received requestAntichainLocator")
+
+		locator, err := flow.Domain().Consensus().CreateAntichainLocator(appmessage.MaxBlockLocatorsPerMsg,
+			appmessage.MaxAntichainLocatorTipsPerMsg)
+		if err != nil {
+			return err
+		}
+
+		err = flow.outgoingRoute.Enqueue(appmessage.NewMsgAntichainLocator(locator))
+		if err != nil {
+			return err
+		}
+	}
+}
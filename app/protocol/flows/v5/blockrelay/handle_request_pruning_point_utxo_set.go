@@ -4,6 +4,7 @@ import (
 	"errors"
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/app/protocol/common"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
 	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
 	"github.com/kaspanet/kaspad/domain"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
@@ -15,22 +16,25 @@ import (
 // HandleRequestPruningPointUTXOSetContext is the interface for the context needed for the HandleRequestPruningPointUTXOSet flow.
 type HandleRequestPruningPointUTXOSetContext interface {
 	Domain() domain.Domain
+	ShouldServeHistoricalData() bool
 }
 
 type handleRequestPruningPointUTXOSetFlow struct {
 	HandleRequestPruningPointUTXOSetContext
 	incomingRoute, outgoingRoute *router.Route
+	peer                         *peerpkg.Peer
 }
 
 // HandleRequestPruningPointUTXOSet listens to appmessage.MsgRequestPruningPointUTXOSet messages and sends
 // the pruning point UTXO set and block body.
 func HandleRequestPruningPointUTXOSet(context HandleRequestPruningPointUTXOSetContext, incomingRoute,
-	outgoingRoute *router.Route) error {
+	outgoingRoute *router.Route, peer *peerpkg.Peer) error {
 
 	flow := &handleRequestPruningPointUTXOSetFlow{
 		HandleRequestPruningPointUTXOSetContext: context,
 		incomingRoute:                           incomingRoute,
 		outgoingRoute:                           outgoingRoute,
+		peer:                                    peer,
 	}
 
 	return flow.start()
@@ -57,6 +61,9 @@ func (flow *handleRequestPruningPointUTXOSetFlow) handleRequestPruningPointUTXOS
 	defer onEnd()
 
 	log.Debugf("Got request for pruning point UTXO set")
+	if !shouldServeHistoricalData(flow, flow.peer) {
+		return errUploadTargetReached
+	}
 
 	return flow.sendPruningPointUTXOSet(msgRequestPruningPointUTXOSet)
 }
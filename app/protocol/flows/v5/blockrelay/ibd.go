@@ -13,6 +13,7 @@ import (
 	"github.com/kaspanet/kaspad/infrastructure/config"
 	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util/processingstats"
 	"github.com/pkg/errors"
 	"time"
 )
@@ -26,7 +27,9 @@ type IBDContext interface {
 	OnPruningPointUTXOSetOverride() error
 	IsIBDRunning() bool
 	TrySetIBDRunning(ibdPeer *peerpkg.Peer) bool
+	IsDeprioritizedSyncPeer(peer *peerpkg.Peer) bool
 	UnsetIBDRunning()
+	SetIBDTargetDAAScore(daaScore uint64)
 	IsRecoverableError(err error) bool
 }
 
@@ -64,6 +67,13 @@ func (flow *handleIBDFlow) start() error {
 }
 
 func (flow *handleIBDFlow) runIBDIfNotRunning(block *externalapi.DomainBlock) error {
+	if flow.IsDeprioritizedSyncPeer(flow.peer) {
+		log.Infof("Not starting IBD with peer %s, since it misreports time (time offset: %s, blocks too far "+
+			"in the future: %d), and other peers are available", flow.peer, flow.peer.TimeOffset(),
+			flow.peer.FarFutureBlockCount())
+		return nil
+	}
+
 	wasIBDNotRunning := flow.TrySetIBDRunning(flow.peer)
 	if !wasIBDNotRunning {
 		log.Debugf("IBD is already running")
@@ -78,6 +88,7 @@ func (flow *handleIBDFlow) runIBDIfNotRunning(block *externalapi.DomainBlock) er
 	}()
 
 	relayBlockHash := consensushashing.BlockHash(block)
+	flow.SetIBDTargetDAAScore(block.Header.DAAScore())
 
 	log.Infof("IBD started with peer %s and relayBlockHash %s", flow.peer, relayBlockHash)
 	log.Infof("Syncing blocks up to %s", relayBlockHash)
@@ -518,6 +529,9 @@ func (flow *handleIBDFlow) processHeader(consensus externalapi.Consensus, msgBlo
 	}
 	err = consensus.ValidateAndInsertBlock(block, false)
 	if err != nil {
+		if errors.Is(err, externalapi.ErrRejectedByBlockValidator) {
+			return rejectedByBlockValidatorError(err, flow.peer)
+		}
 		if !errors.As(err, &ruleerrors.RuleError{}) {
 			return errors.Wrapf(err, "failed to process header %s during IBD", blockHash)
 		}
@@ -526,7 +540,7 @@ func (flow *handleIBDFlow) processHeader(consensus externalapi.Consensus, msgBlo
 			log.Debugf("Skipping block header %s as it is a duplicate", blockHash)
 		} else {
 			log.Infof("Rejected block header %s from %s during IBD: %s", blockHash, flow.peer, err)
-			return protocolerrors.Wrapf(true, err, "got invalid block header %s during IBD", blockHash)
+			return protocolerrors.WrapRejectedf(true, err, blockHash, "got invalid block header %s during IBD", blockHash)
 		}
 	}
 
@@ -677,7 +691,9 @@ func (flow *handleIBDFlow) syncMissingBlockBodies(highHash *externalapi.DomainHa
 					"expected: %s, got: %s", appmessage.CmdIBDBlock, message.Command())
 			}
 
+			onDeserializationEnd := processingstats.Measure(processingstats.Deserialization)
 			block := appmessage.MsgBlockToDomainBlock(msgIBDBlock.MsgBlock)
+			onDeserializationEnd()
 			blockHash := consensushashing.BlockHash(block)
 			if !expectedHash.Equal(blockHash) {
 				return protocolerrors.Errorf(true, "expected block %s but got %s", expectedHash, blockHash)
@@ -694,7 +710,13 @@ func (flow *handleIBDFlow) syncMissingBlockBodies(highHash *externalapi.DomainHa
 					log.Debugf("Skipping IBD Block %s as it has already been added to the DAG", blockHash)
 					continue
 				}
-				return protocolerrors.ConvertToBanningProtocolErrorIfRuleError(err, "invalid block %s", blockHash)
+				if errors.Is(err, externalapi.ErrRejectedByBlockValidator) {
+					return rejectedByBlockValidatorError(err, flow.peer)
+				}
+				if !errors.As(err, &ruleerrors.RuleError{}) {
+					return err
+				}
+				return protocolerrors.WrapRejectedf(true, err, blockHash, "invalid block %s", blockHash)
 			}
 			err = flow.OnNewBlock(block)
 			if err != nil {
@@ -749,3 +771,11 @@ func (flow *handleIBDFlow) resolveVirtual(estimatedVirtualDAAScoreTarget uint64)
 	log.Infof("Resolved virtual")
 	return nil
 }
+
+// rejectedByBlockValidatorError stops IBD with a peer that sent a block that a block validator
+// rejected. The DAG of the peer can't be synced past the block, so the peer is disconnected,
+// but it isn't banned since the block is a matter of local policy rather than of consensus.
+func rejectedByBlockValidatorError(err error, peer *peerpkg.Peer) error {
+	log.Infof("Stopping IBD with %s: %s", peer, err)
+	return protocolerrors.Wrap(false, err, "block rejected by a block validator during IBD")
+}
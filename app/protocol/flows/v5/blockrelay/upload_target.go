@@ -0,0 +1,24 @@
+package blockrelay
+
+import (
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
+	"github.com/kaspanet/kaspad/infrastructure/network/permissions"
+)
+
+// errUploadTargetReached is returned by the flows that serve historical data once the
+// upload target is close to being reached. The peer is disconnected without being banned,
+// and is expected to sync from other peers, while the rest of the upload target is kept
+// for relaying new blocks and transactions.
+var errUploadTargetReached = protocolerrors.New(false, "the upload target is close to being reached, "+
+	"so historical data isn't served")
+
+type historicalDataContext interface {
+	ShouldServeHistoricalData() bool
+}
+
+// shouldServeHistoricalData returns whether historical data should be served to the given peer.
+// Peers with the download permission are served regardless of the upload target.
+func shouldServeHistoricalData(context historicalDataContext, peer *peerpkg.Peer) bool {
+	return peer.HasPermission(permissions.Download) || context.ShouldServeHistoricalData()
+}
@@ -0,0 +1,116 @@
+package bloomfilter
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// HandleFiltersContext is the interface for the context needed for the HandleFilters flow.
+type HandleFiltersContext interface {
+	Config() *config.Config
+	Domain() domain.Domain
+}
+
+type handleFiltersFlow struct {
+	HandleFiltersContext
+	incomingRoute, outgoingRoute *router.Route
+	peer                         *peerpkg.Peer
+}
+
+// HandleFilters listens to appmessage.MsgFilterLoad, appmessage.MsgFilterAdd and
+// appmessage.MsgFilterClear messages, and updates the bloom filter of the peer
+// accordingly. Once a filter is loaded, the transactions in the mempool that
+// match it are announced to the peer.
+func HandleFilters(context HandleFiltersContext, incomingRoute *router.Route, outgoingRoute *router.Route,
+	peer *peerpkg.Peer) error {
+
+	flow := &handleFiltersFlow{
+		HandleFiltersContext: context,
+		incomingRoute:        incomingRoute,
+		outgoingRoute:        outgoingRoute,
+		peer:                 peer,
+	}
+	return flow.start()
+}
+
+func (flow *handleFiltersFlow) start() error {
+	for {
+		message, err := flow.incomingRoute.Dequeue()
+		if err != nil {
+			return err
+		}
+
+		// Bloom filters are only supported if the SFNodeBloom service is advertised,
+		// so a peer that sends them anyway is misbehaving
+		if flow.Config().NoPeerBloomFilters {
+			return protocolerrors.Errorf(true, "peer sent %s while bloom filters are disabled", message.Command())
+		}
+
+		switch message := message.(type) {
+		case *appmessage.MsgFilterLoad:
+			err = flow.handleFilterLoad(message)
+		case *appmessage.MsgFilterAdd:
+			err = flow.handleFilterAdd(message)
+		case *appmessage.MsgFilterClear:
+			flow.peer.Filter().Unload()
+			log.Debugf("Peer %s cleared its bloom filter", flow.peer)
+		default:
+			return errors.Errorf("unexpected message %s", message.Command())
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (flow *handleFiltersFlow) handleFilterLoad(msgFilterLoad *appmessage.MsgFilterLoad) error {
+	flow.peer.Filter().Reload(msgFilterLoad)
+	log.Debugf("Peer %s loaded a bloom filter of %d bytes and %d hash functions",
+		flow.peer, len(msgFilterLoad.Filter), msgFilterLoad.HashFuncs)
+
+	return flow.announceMatchingMempoolTransactions()
+}
+
+func (flow *handleFiltersFlow) handleFilterAdd(msgFilterAdd *appmessage.MsgFilterAdd) error {
+	filter := flow.peer.Filter()
+	if !filter.IsLoaded() {
+		return protocolerrors.New(true, "peer sent FilterAdd before loading a filter")
+	}
+	filter.Add(msgFilterAdd.Data)
+	return nil
+}
+
+// announceMatchingMempoolTransactions announces the transactions in the mempool
+// that match the newly loaded filter, so that an SPV client learns about its
+// unconfirmed transactions without waiting for them to be relayed again
+func (flow *handleFiltersFlow) announceMatchingMempoolTransactions() error {
+	filter := flow.peer.Filter()
+	transactions, _ := flow.Domain().MiningManager().AllTransactions(true, false)
+
+	var matchingTransactionIDs []*externalapi.DomainTransactionID
+	for _, transaction := range transactions {
+		if filter.MatchTransactionAndUpdate(transaction) {
+			matchingTransactionIDs = append(matchingTransactionIDs, consensushashing.TransactionID(transaction))
+		}
+	}
+
+	for len(matchingTransactionIDs) > 0 {
+		transactionIDsToAnnounce := matchingTransactionIDs
+		if len(transactionIDsToAnnounce) > appmessage.MaxInvPerTxInvMsg {
+			transactionIDsToAnnounce = matchingTransactionIDs[:appmessage.MaxInvPerTxInvMsg]
+		}
+		err := flow.outgoingRoute.Enqueue(appmessage.NewMsgInvTransaction(transactionIDsToAnnounce))
+		if err != nil {
+			return err
+		}
+		matchingTransactionIDs = matchingTransactionIDs[len(transactionIDsToAnnounce):]
+	}
+	return nil
+}
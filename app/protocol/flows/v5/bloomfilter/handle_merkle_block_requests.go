@@ -0,0 +1,54 @@
+package bloomfilter
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util/bloom"
+	"github.com/pkg/errors"
+)
+
+// MerkleBlockRequestsContext is the interface for the context needed for the HandleMerkleBlockRequests flow.
+type MerkleBlockRequestsContext interface {
+	Domain() domain.Domain
+}
+
+// HandleMerkleBlockRequests listens to appmessage.MsgRequestMerkleBlocks messages and sends
+// the requested blocks, filtered by the bloom filter of the peer, as merkle blocks.
+func HandleMerkleBlockRequests(context MerkleBlockRequestsContext, incomingRoute *router.Route,
+	outgoingRoute *router.Route, peer *peerpkg.Peer) error {
+
+	for {
+		message, err := incomingRoute.Dequeue()
+		if err != nil {
+			return err
+		}
+		requestMerkleBlocksMessage := message.(*appmessage.MsgRequestMerkleBlocks)
+		log.Debugf("Got request for merkle blocks with hashes %s", requestMerkleBlocksMessage.Hashes)
+
+		filter := peer.Filter()
+		if !filter.IsLoaded() {
+			return protocolerrors.New(true, "peer requested merkle blocks without loading a filter")
+		}
+
+		for _, hash := range requestMerkleBlocksMessage.Hashes {
+			block, found, err := context.Domain().Consensus().GetBlock(hash)
+			if err != nil {
+				return errors.Wrapf(err, "unable to fetch requested block hash %s", hash)
+			}
+			if !found {
+				return protocolerrors.Errorf(false, "Merkle block %s not found", hash)
+			}
+
+			msgMerkleBlock := bloom.NewMerkleBlock(block, filter)
+			err = outgoingRoute.Enqueue(msgMerkleBlock)
+			if err != nil {
+				return err
+			}
+			log.Debugf("Sent merkle block %s with %d of its %d transactions",
+				hash, len(msgMerkleBlock.Transactions), msgMerkleBlock.TransactionCount)
+		}
+	}
+}
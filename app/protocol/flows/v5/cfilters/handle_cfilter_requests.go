@@ -0,0 +1,201 @@
+package cfilters
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/cfindex"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util/gcs/builder"
+)
+
+// CFilterRequestsContext is the interface for the context needed for the HandleCFilterRequests flow.
+type CFilterRequestsContext interface {
+	Domain() domain.Domain
+	CFIndex() *cfindex.CFIndex
+}
+
+type handleCFilterRequestsFlow struct {
+	CFilterRequestsContext
+	incomingRoute, outgoingRoute *router.Route
+	peer                         *peerpkg.Peer
+}
+
+// HandleCFilterRequests listens to appmessage.MsgGetCFilters, appmessage.MsgGetCFHeaders
+// and appmessage.MsgGetCFCheckpt messages, and answers them from the compact filter index
+func HandleCFilterRequests(context CFilterRequestsContext, incomingRoute *router.Route,
+	outgoingRoute *router.Route, peer *peerpkg.Peer) error {
+
+	flow := &handleCFilterRequestsFlow{
+		CFilterRequestsContext: context,
+		incomingRoute:          incomingRoute,
+		outgoingRoute:          outgoingRoute,
+		peer:                   peer,
+	}
+	return flow.start()
+}
+
+func (flow *handleCFilterRequestsFlow) start() error {
+	for {
+		message, err := flow.incomingRoute.Dequeue()
+		if err != nil {
+			return err
+		}
+
+		if flow.CFIndex() == nil {
+			return protocolerrors.Errorf(true, "peer sent %s while compact filters are disabled", message.Command())
+		}
+
+		switch message := message.(type) {
+		case *appmessage.MsgGetCFilters:
+			err = flow.handleGetCFilters(message)
+		case *appmessage.MsgGetCFHeaders:
+			err = flow.handleGetCFHeaders(message)
+		case *appmessage.MsgGetCFCheckpt:
+			err = flow.handleGetCFCheckpt(message)
+		default:
+			return protocolerrors.Errorf(true, "unexpected message %s", message.Command())
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (flow *handleCFilterRequestsFlow) handleGetCFilters(message *appmessage.MsgGetCFilters) error {
+	log.Debugf("Got request for the filters between %s and %s from %s", message.LowHash, message.HighHash, flow.peer)
+
+	err := validateFilterType(message.FilterType)
+	if err != nil {
+		return err
+	}
+	blockHashes, _, err := flow.blockHashesBetween(message.LowHash, message.HighHash)
+	if err != nil {
+		return err
+	}
+
+	for _, blockHash := range blockHashes {
+		filter, found, err := flow.CFIndex().Filter(blockHash)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return protocolerrors.Errorf(false, "the filter of block %s is not available", blockHash)
+		}
+		err = flow.outgoingRoute.Enqueue(appmessage.NewMsgCFilter(message.FilterType, blockHash, filter.NBytes()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (flow *handleCFilterRequestsFlow) handleGetCFHeaders(message *appmessage.MsgGetCFHeaders) error {
+	log.Debugf("Got request for the filter headers between %s and %s from %s",
+		message.LowHash, message.HighHash, flow.peer)
+
+	err := validateFilterType(message.FilterType)
+	if err != nil {
+		return err
+	}
+	blockHashes, stopHash, err := flow.blockHashesBetween(message.LowHash, message.HighHash)
+	if err != nil {
+		return err
+	}
+
+	filterHashes := make([]*externalapi.DomainHash, len(blockHashes))
+	filterHeaders := make([]*externalapi.DomainHash, len(blockHashes))
+	for i, blockHash := range blockHashes {
+		filter, found, err := flow.CFIndex().Filter(blockHash)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return protocolerrors.Errorf(false, "the filter of block %s is not available", blockHash)
+		}
+		filterHeader, found, err := flow.CFIndex().FilterHeader(blockHash)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return protocolerrors.Errorf(false, "the filter header of block %s is not available", blockHash)
+		}
+		filterHashes[i] = builder.FilterHash(filter)
+		filterHeaders[i] = filterHeader
+	}
+
+	return flow.outgoingRoute.Enqueue(
+		appmessage.NewMsgCFHeaders(message.FilterType, stopHash, blockHashes, filterHashes, filterHeaders))
+}
+
+func (flow *handleCFilterRequestsFlow) handleGetCFCheckpt(message *appmessage.MsgGetCFCheckpt) error {
+	log.Debugf("Got request for the filter header checkpoints up to %s from %s", message.StopHash, flow.peer)
+
+	err := validateFilterType(message.FilterType)
+	if err != nil {
+		return err
+	}
+	stopHashInfo, err := flow.Domain().Consensus().GetBlockInfo(message.StopHash)
+	if err != nil {
+		return err
+	}
+	if !stopHashInfo.HasBody() {
+		return protocolerrors.Errorf(false, "Block %s does not exist or has no body", message.StopHash)
+	}
+
+	filterHeaders, found, err := flow.CFIndex().SelectedChainFilterHeaders(message.StopHash, appmessage.CFCheckptInterval)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return protocolerrors.Errorf(false, "the filter header of block %s is not available", message.StopHash)
+	}
+	if len(filterHeaders) > appmessage.MaxCFCheckptHeaders {
+		filterHeaders = filterHeaders[:appmessage.MaxCFCheckptHeaders]
+	}
+
+	return flow.outgoingRoute.Enqueue(appmessage.NewMsgCFCheckpt(message.FilterType, message.StopHash, filterHeaders))
+}
+
+// blockHashesBetween returns the hashes of the blocks above lowHash up to highHash, in the
+// order the consensus returns them, capped at appmessage.MaxGetCFiltersReqRange blocks.
+// It also returns the last block of the returned range.
+func (flow *handleCFilterRequestsFlow) blockHashesBetween(lowHash, highHash *externalapi.DomainHash) (
+	[]*externalapi.DomainHash, *externalapi.DomainHash, error) {
+
+	consensus := flow.Domain().Consensus()
+
+	for _, hash := range []*externalapi.DomainHash{lowHash, highHash} {
+		blockInfo, err := consensus.GetBlockInfo(hash)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !blockInfo.HasHeader() {
+			return nil, nil, protocolerrors.Errorf(true, "Block %s does not exist", hash)
+		}
+	}
+
+	isLowSelectedAncestorOfHigh, err := consensus.IsInSelectedParentChainOf(lowHash, highHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isLowSelectedAncestorOfHigh {
+		return nil, nil, protocolerrors.Errorf(true, "Expected %s to be on the selected chain of %s",
+			lowHash, highHash)
+	}
+
+	blockHashes, stopHash, err := consensus.GetHashesBetween(lowHash, highHash, appmessage.MaxGetCFiltersReqRange)
+	if err != nil {
+		return nil, nil, err
+	}
+	return blockHashes, stopHash, nil
+}
+
+func validateFilterType(filterType appmessage.FilterType) error {
+	if filterType != appmessage.GCSFilterRegular {
+		return protocolerrors.Errorf(true, "unknown filter type %d", filterType)
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+package cfilters
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+)
+
+var log = logger.RegisterSubSystem("PROT")
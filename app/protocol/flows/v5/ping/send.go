@@ -11,11 +11,13 @@ import (
 	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
 	"github.com/kaspanet/kaspad/util/random"
+	"github.com/kaspanet/kaspad/util/timesource"
 )
 
 // SendPingsContext is the interface for the context needed for the SendPings flow.
 type SendPingsContext interface {
 	ShutdownChan() <-chan struct{}
+	TimeSource() timesource.Source
 }
 
 type sendPingsFlow struct {
@@ -39,14 +41,14 @@ func SendPings(context SendPingsContext, incomingRoute *router.Route, outgoingRo
 
 func (flow *sendPingsFlow) start() error {
 	const pingInterval = 2 * time.Minute
-	ticker := time.NewTicker(pingInterval)
+	ticker := flow.TimeSource().NewTicker(pingInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-flow.ShutdownChan():
 			return nil
-		case <-ticker.C:
+		case <-ticker.C():
 		}
 
 		nonce, err := random.Uint64()
@@ -6,9 +6,12 @@ import (
 	"github.com/kaspanet/kaspad/app/protocol/flowcontext"
 	"github.com/kaspanet/kaspad/app/protocol/flows/v5/addressexchange"
 	"github.com/kaspanet/kaspad/app/protocol/flows/v5/blockrelay"
+	"github.com/kaspanet/kaspad/app/protocol/flows/v5/bloomfilter"
+	"github.com/kaspanet/kaspad/app/protocol/flows/v5/cfilters"
 	"github.com/kaspanet/kaspad/app/protocol/flows/v5/ping"
 	"github.com/kaspanet/kaspad/app/protocol/flows/v5/rejects"
 	"github.com/kaspanet/kaspad/app/protocol/flows/v5/transactionrelay"
+	"github.com/kaspanet/kaspad/app/protocol/flows/v5/utxoexistence"
 	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
 	routerpkg "github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
 )
@@ -31,6 +34,9 @@ func Register(m protocolManager, router *routerpkg.Router, errChan chan error, i
 	flows = append(flows, registerPingFlows(m, router, isStopping, errChan)...)
 	flows = append(flows, registerTransactionRelayFlow(m, router, isStopping, errChan)...)
 	flows = append(flows, registerRejectsFlow(m, router, isStopping, errChan)...)
+	flows = append(flows, registerBloomFilterFlows(m, router, isStopping, errChan)...)
+	flows = append(flows, registerCFilterFlows(m, router, isStopping, errChan)...)
+	flows = append(flows, registerUTXOExistenceFlows(m, router, isStopping, errChan)...)
 
 	return flows
 }
@@ -45,7 +51,7 @@ func registerAddressFlows(m protocolManager, router *routerpkg.Router, isStoppin
 			},
 		),
 
-		m.RegisterOneTimeFlow("ReceiveAddresses", router, []appmessage.MessageCommand{appmessage.CmdAddresses}, isStopping, errChan,
+		m.RegisterFlow("ReceiveAddresses", router, []appmessage.MessageCommand{appmessage.CmdAddresses}, isStopping, errChan,
 			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
 				return addressexchange.ReceiveAddresses(m.Context(), incomingRoute, outgoingRoute, peer)
 			},
@@ -63,7 +69,7 @@ func registerBlockRelayFlows(m protocolManager, router *routerpkg.Router, isStop
 			}),
 
 		m.RegisterFlow("HandleRelayInvs", router, []appmessage.MessageCommand{
-			appmessage.CmdInvRelayBlock, appmessage.CmdBlock, appmessage.CmdBlockLocator,
+			appmessage.CmdInvRelayBlock, appmessage.CmdBlock, appmessage.CmdBlockLocator, appmessage.CmdAntichainLocator,
 		},
 			isStopping, errChan, func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
 				return blockrelay.HandleRelayInvs(m.Context(), incomingRoute,
@@ -99,6 +105,13 @@ func registerBlockRelayFlows(m protocolManager, router *routerpkg.Router, isStop
 			},
 		),
 
+		m.RegisterFlow("HandleRequestAntichainLocator", router,
+			[]appmessage.MessageCommand{appmessage.CmdRequestAntichainLocator}, isStopping, errChan,
+			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
+				return blockrelay.HandleRequestAntichainLocator(m.Context(), incomingRoute, outgoingRoute)
+			},
+		),
+
 		m.RegisterFlow("HandleRequestHeaders", router,
 			[]appmessage.MessageCommand{appmessage.CmdRequestHeaders, appmessage.CmdRequestNextHeaders}, isStopping, errChan,
 			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
@@ -109,7 +122,7 @@ func registerBlockRelayFlows(m protocolManager, router *routerpkg.Router, isStop
 		m.RegisterFlow("HandleIBDBlockRequests", router,
 			[]appmessage.MessageCommand{appmessage.CmdRequestIBDBlocks}, isStopping, errChan,
 			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
-				return blockrelay.HandleIBDBlockRequests(m.Context(), incomingRoute, outgoingRoute)
+				return blockrelay.HandleIBDBlockRequests(m.Context(), incomingRoute, outgoingRoute, peer)
 			},
 		),
 
@@ -117,7 +130,7 @@ func registerBlockRelayFlows(m protocolManager, router *routerpkg.Router, isStop
 			[]appmessage.MessageCommand{appmessage.CmdRequestPruningPointUTXOSet,
 				appmessage.CmdRequestNextPruningPointUTXOSetChunk}, isStopping, errChan,
 			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
-				return blockrelay.HandleRequestPruningPointUTXOSet(m.Context(), incomingRoute, outgoingRoute)
+				return blockrelay.HandleRequestPruningPointUTXOSet(m.Context(), incomingRoute, outgoingRoute, peer)
 			},
 		),
 
@@ -183,7 +196,7 @@ func registerTransactionRelayFlow(m protocolManager, router *routerpkg.Router, i
 		m.RegisterFlowWithCapacity("HandleRelayedTransactions", 10_000, router,
 			[]appmessage.MessageCommand{appmessage.CmdInvTransaction, appmessage.CmdTx, appmessage.CmdTransactionNotFound}, isStopping, errChan,
 			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
-				return transactionrelay.HandleRelayedTransactions(m.Context(), incomingRoute, outgoingRoute)
+				return transactionrelay.HandleRelayedTransactions(m.Context(), incomingRoute, outgoingRoute, peer)
 			},
 		),
 		m.RegisterFlow("HandleRequestTransactions", router,
@@ -192,6 +205,28 @@ func registerTransactionRelayFlow(m protocolManager, router *routerpkg.Router, i
 				return transactionrelay.HandleRequestedTransactions(m.Context(), incomingRoute, outgoingRoute)
 			},
 		),
+		m.RegisterFlow("HandleStemTransactions", router,
+			[]appmessage.MessageCommand{appmessage.CmdStemTransaction}, isStopping, errChan,
+			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
+				return transactionrelay.HandleStemTransactions(m.Context(), incomingRoute, peer)
+			},
+		),
+		m.RegisterFlow("HandleRelayedPackages", router,
+			[]appmessage.MessageCommand{appmessage.CmdInvPackage, appmessage.CmdPackage}, isStopping, errChan,
+			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
+				return transactionrelay.HandleRelayedPackages(m.Context(), incomingRoute, outgoingRoute, peer)
+			},
+		),
+		m.RegisterFlow("HandleRequestedPackages", router,
+			[]appmessage.MessageCommand{appmessage.CmdRequestPackage}, isStopping, errChan,
+			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
+				return transactionrelay.HandleRequestedPackages(m.Context(), incomingRoute, outgoingRoute)
+			},
+		),
+		m.RegisterOneTimeFlow("SendMempoolInv", router, []appmessage.MessageCommand{},
+			isStopping, errChan, func(route *routerpkg.Route, peer *peerpkg.Peer) error {
+				return transactionrelay.SendMempoolInv(m.Context(), outgoingRoute, peer)
+			}),
 	}
 }
 
@@ -207,3 +242,50 @@ func registerRejectsFlow(m protocolManager, router *routerpkg.Router, isStopping
 		),
 	}
 }
+
+func registerBloomFilterFlows(m protocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow {
+	outgoingRoute := router.OutgoingRoute()
+
+	return []*common.Flow{
+		m.RegisterFlow("HandleFilters", router,
+			[]appmessage.MessageCommand{appmessage.CmdFilterLoad, appmessage.CmdFilterAdd, appmessage.CmdFilterClear},
+			isStopping, errChan,
+			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
+				return bloomfilter.HandleFilters(m.Context(), incomingRoute, outgoingRoute, peer)
+			},
+		),
+		m.RegisterFlow("HandleMerkleBlockRequests", router,
+			[]appmessage.MessageCommand{appmessage.CmdRequestMerkleBlocks}, isStopping, errChan,
+			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
+				return bloomfilter.HandleMerkleBlockRequests(m.Context(), incomingRoute, outgoingRoute, peer)
+			},
+		),
+	}
+}
+
+func registerCFilterFlows(m protocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow {
+	outgoingRoute := router.OutgoingRoute()
+
+	return []*common.Flow{
+		m.RegisterFlow("HandleCFilterRequests", router,
+			[]appmessage.MessageCommand{appmessage.CmdGetCFilters, appmessage.CmdGetCFHeaders, appmessage.CmdGetCFCheckpt},
+			isStopping, errChan,
+			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
+				return cfilters.HandleCFilterRequests(m.Context(), incomingRoute, outgoingRoute, peer)
+			},
+		),
+	}
+}
+
+func registerUTXOExistenceFlows(m protocolManager, router *routerpkg.Router, isStopping *uint32, errChan chan error) []*common.Flow {
+	outgoingRoute := router.OutgoingRoute()
+
+	return []*common.Flow{
+		m.RegisterFlow("HandleUTXOExistenceRequests", router,
+			[]appmessage.MessageCommand{appmessage.CmdRequestUTXOExistence}, isStopping, errChan,
+			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
+				return utxoexistence.HandleUTXOExistenceRequests(m.Context(), incomingRoute, outgoingRoute, peer)
+			},
+		),
+	}
+}
@@ -1,11 +1,29 @@
 package rejects
 
 import (
+	"fmt"
+
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
 )
 
+// RejectError is the cause of the error returned by the HandleRejects flow.
+// It allows callers to tell why the peer rejected us without parsing the reason.
+type RejectError struct {
+	Code         appmessage.RejectCode
+	RejectedHash *externalapi.DomainHash
+	Reason       string
+}
+
+func (e RejectError) Error() string {
+	if e.RejectedHash != nil {
+		return fmt.Sprintf("%s (code: %s, rejected: %s)", e.Reason, e.Code, e.RejectedHash)
+	}
+	return fmt.Sprintf("%s (code: %s)", e.Reason, e.Code)
+}
+
 // HandleRejectsContext is the interface for the context needed for the HandleRejects flow.
 type HandleRejectsContext interface {
 }
@@ -33,5 +51,10 @@ func (flow *handleRejectsFlow) start() error {
 	}
 	rejectMessage := message.(*appmessage.MsgReject)
 
-	return protocolerrors.Errorf(false, "got reject message: `%s`", rejectMessage.Reason)
+	rejectError := RejectError{
+		Code:         rejectMessage.Code,
+		RejectedHash: rejectMessage.RejectedHash,
+		Reason:       rejectMessage.Reason,
+	}
+	return protocolerrors.Wrap(false, rejectError, "got reject message")
 }
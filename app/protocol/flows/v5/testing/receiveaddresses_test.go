@@ -15,7 +15,7 @@ import (
 
 type fakeReceiveAddressesContext struct{}
 
-func (f fakeReceiveAddressesContext) AddressManager() *addressmanager.AddressManager {
+func (f fakeReceiveAddressesContext) AddAddresses(_ *appmessage.NetAddress, _ ...*appmessage.NetAddress) error {
 	return nil
 }
 
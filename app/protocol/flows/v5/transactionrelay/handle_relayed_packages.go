@@ -0,0 +1,178 @@
+package transactionrelay
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/protocol/common"
+	"github.com/kaspanet/kaspad/app/protocol/flowcontext"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// PackagesRelayContext is the interface for the context needed for the
+// HandleRelayedPackages and HandleRequestedPackages flows.
+type PackagesRelayContext interface {
+	Domain() domain.Domain
+	SharedRequestedPackages() *flowcontext.SharedRequestedTransactions
+	OnTransactionAddedToMempool(transactions []*externalapi.DomainTransaction)
+	PropagateAcceptedPackage(packageTransactions []*externalapi.DomainTransaction,
+		acceptedTransactions []*externalapi.DomainTransaction) error
+	IsTransactionEmbargoed(transactionID *externalapi.DomainTransactionID) bool
+	IsCurrent() (bool, error)
+	AcceptsTransactionsFrom(peer *peerpkg.Peer) bool
+}
+
+type handleRelayedPackagesFlow struct {
+	PackagesRelayContext
+	incomingRoute, outgoingRoute *router.Route
+	peer                         *peerpkg.Peer
+	invsQueue                    []*appmessage.MsgInvPackage
+}
+
+// HandleRelayedPackages listens to appmessage.MsgInvPackage messages, requests the announced packages
+// whose last transactions are missing from the mempool, adds them to the mempool and propagates them
+// to the rest of the network.
+func HandleRelayedPackages(context PackagesRelayContext, incomingRoute *router.Route, outgoingRoute *router.Route,
+	peer *peerpkg.Peer) error {
+
+	flow := &handleRelayedPackagesFlow{
+		PackagesRelayContext: context,
+		incomingRoute:        incomingRoute,
+		outgoingRoute:        outgoingRoute,
+		peer:                 peer,
+	}
+	return flow.start()
+}
+
+func (flow *handleRelayedPackagesFlow) start() error {
+	for {
+		inv, err := flow.readInv()
+		if err != nil {
+			return err
+		}
+
+		if !flow.AcceptsTransactionsFrom(flow.peer) {
+			continue
+		}
+
+		isCurrent, err := flow.IsCurrent()
+		if err != nil {
+			return err
+		}
+		// Transaction relay is disabled if the node is out of sync and thus not mining
+		if !isCurrent {
+			continue
+		}
+
+		for _, transactionID := range inv.IDs {
+			// The last transaction of a package may be known as an orphan, waiting for
+			// the parents it pays for
+			if _, _, ok := flow.Domain().MiningManager().GetTransaction(transactionID, true, false); ok {
+				continue
+			}
+			exists := flow.SharedRequestedPackages().AddIfNotExists(transactionID)
+			if exists {
+				continue
+			}
+			err := flow.requestPackage(transactionID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (flow *handleRelayedPackagesFlow) readInv() (*appmessage.MsgInvPackage, error) {
+	if len(flow.invsQueue) > 0 {
+		var inv *appmessage.MsgInvPackage
+		inv, flow.invsQueue = flow.invsQueue[0], flow.invsQueue[1:]
+		return inv, nil
+	}
+
+	message, err := flow.incomingRoute.Dequeue()
+	if err != nil {
+		return nil, err
+	}
+
+	inv, ok := message.(*appmessage.MsgInvPackage)
+	if !ok {
+		return nil, protocolerrors.Errorf(true, "unexpected %s message in the package relay flow while "+
+			"expecting an inv message", message.Command())
+	}
+	return inv, nil
+}
+
+// readPackage returns the next package in incomingRoute, and populates invsQueue with any inv
+// messages that meanwhile arrive
+func (flow *handleRelayedPackagesFlow) readPackage() (*appmessage.MsgPackage, error) {
+	for {
+		message, err := flow.incomingRoute.DequeueWithTimeout(common.DefaultTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		switch message := message.(type) {
+		case *appmessage.MsgInvPackage:
+			flow.invsQueue = append(flow.invsQueue, message)
+		case *appmessage.MsgPackage:
+			return message, nil
+		default:
+			return nil, errors.Errorf("unexpected message %s", message.Command())
+		}
+	}
+}
+
+func (flow *handleRelayedPackagesFlow) requestPackage(expectedID *externalapi.DomainTransactionID) error {
+	defer flow.SharedRequestedPackages().Remove(expectedID)
+
+	err := flow.outgoingRoute.Enqueue(appmessage.NewMsgRequestPackage(expectedID))
+	if err != nil {
+		return err
+	}
+	msgPackage, err := flow.readPackage()
+	if err != nil {
+		return err
+	}
+	if !msgPackage.ID.Equal(expectedID) {
+		return protocolerrors.Errorf(true, "expected package %s, but got %s", expectedID, msgPackage.ID)
+	}
+	if len(msgPackage.Transactions) == 0 {
+		return nil
+	}
+	if len(msgPackage.Transactions) > mempool.MaximumPackageTransactions {
+		return protocolerrors.Errorf(true, "package %s has %d transactions, which exceeds the maximum of %d",
+			expectedID, len(msgPackage.Transactions), mempool.MaximumPackageTransactions)
+	}
+
+	transactions := make([]*externalapi.DomainTransaction, len(msgPackage.Transactions))
+	for i, msgTx := range msgPackage.Transactions {
+		transactions[i] = appmessage.MsgTxToDomainTransaction(msgTx)
+	}
+	lastTransactionID := consensushashing.TransactionID(transactions[len(transactions)-1])
+	if !lastTransactionID.Equal(expectedID) {
+		return protocolerrors.Errorf(true, "expected package %s to end with its transaction, but it ends with %s",
+			expectedID, lastTransactionID)
+	}
+
+	acceptedTransactions, err := flow.Domain().MiningManager().ValidateAndInsertPackage(transactions, false)
+	if err != nil {
+		ruleErr := &mempool.RuleError{}
+		if !errors.As(err, ruleErr) {
+			return errors.Wrapf(err, "failed to process package %s", expectedID)
+		}
+
+		txRuleErr := &mempool.TxRuleError{}
+		if errors.As(ruleErr.Err, txRuleErr) && txRuleErr.RejectCode == mempool.RejectInvalid {
+			return protocolerrors.WrapRejectedf(true, ruleErr, (*externalapi.DomainHash)(expectedID),
+				"rejected package %s", expectedID)
+		}
+		return nil
+	}
+	flow.OnTransactionAddedToMempool(acceptedTransactions)
+	return flow.PropagateAcceptedPackage(transactions, acceptedTransactions)
+}
@@ -4,13 +4,16 @@ import (
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/app/protocol/common"
 	"github.com/kaspanet/kaspad/app/protocol/flowcontext"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
 	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
 	"github.com/kaspanet/kaspad/domain"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
 	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	miningmanagermodel "github.com/kaspanet/kaspad/domain/miningmanager/model"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/infrastructure/network/permissions"
 	"github.com/pkg/errors"
 )
 
@@ -20,24 +23,31 @@ type TransactionsRelayContext interface {
 	NetAdapter() *netadapter.NetAdapter
 	Domain() domain.Domain
 	SharedRequestedTransactions() *flowcontext.SharedRequestedTransactions
-	OnTransactionAddedToMempool()
+	OnTransactionAddedToMempool(transactions []*externalapi.DomainTransaction)
 	EnqueueTransactionIDsForPropagation(transactionIDs []*externalapi.DomainTransactionID) error
-	IsNearlySynced() (bool, error)
+	IsTransactionEmbargoed(transactionID *externalapi.DomainTransactionID) bool
+	OnTransactionAnnounced(transactionID *externalapi.DomainTransactionID, peer *peerpkg.Peer)
+	IsCurrent() (bool, error)
+	AcceptsTransactionsFrom(peer *peerpkg.Peer) bool
 }
 
 type handleRelayedTransactionsFlow struct {
 	TransactionsRelayContext
 	incomingRoute, outgoingRoute *router.Route
+	peer                         *peerpkg.Peer
 	invsQueue                    []*appmessage.MsgInvTransaction
 }
 
 // HandleRelayedTransactions listens to appmessage.MsgInvTransaction messages, requests their corresponding transactions if they
 // are missing, adds them to the mempool and propagates them to the rest of the network.
-func HandleRelayedTransactions(context TransactionsRelayContext, incomingRoute *router.Route, outgoingRoute *router.Route) error {
+func HandleRelayedTransactions(context TransactionsRelayContext, incomingRoute *router.Route, outgoingRoute *router.Route,
+	peer *peerpkg.Peer) error {
+
 	flow := &handleRelayedTransactionsFlow{
 		TransactionsRelayContext: context,
 		incomingRoute:            incomingRoute,
 		outgoingRoute:            outgoingRoute,
+		peer:                     peer,
 		invsQueue:                make([]*appmessage.MsgInvTransaction, 0),
 	}
 	return flow.start()
@@ -49,13 +59,18 @@ func (flow *handleRelayedTransactionsFlow) start() error {
 		if err != nil {
 			return err
 		}
+		flow.peer.RecordTransactionAnnouncement()
 
-		isNearlySynced, err := flow.IsNearlySynced()
+		if !flow.AcceptsTransactionsFrom(flow.peer) {
+			continue
+		}
+
+		isCurrent, err := flow.IsCurrent()
 		if err != nil {
 			return err
 		}
 		// Transaction relay is disabled if the node is out of sync and thus not mining
-		if !isNearlySynced {
+		if !isCurrent {
 			continue
 		}
 
@@ -75,8 +90,18 @@ func (flow *handleRelayedTransactionsFlow) requestInvTransactions(
 	inv *appmessage.MsgInvTransaction) (requestedIDs []*externalapi.DomainTransactionID, err error) {
 
 	idsToRequest := make([]*externalapi.DomainTransactionID, 0, len(inv.TxIDs))
+	var idsToForceRelay []*externalapi.DomainTransactionID
+	isForceRelay := flow.peer.HasPermission(permissions.ForceRelay)
 	for _, txID := range inv.TxIDs {
 		if flow.isKnownTransaction(txID) {
+			flow.OnTransactionAnnounced(txID, flow.peer)
+			// Peers with the forcerelay permission have their transactions propagated
+			// again, as long as they aren't orphans
+			if isForceRelay {
+				if _, _, ok := flow.Domain().MiningManager().GetTransaction(txID, true, false); ok {
+					idsToForceRelay = append(idsToForceRelay, txID)
+				}
+			}
 			continue
 		}
 		exists := flow.SharedRequestedTransactions().AddIfNotExists(txID)
@@ -86,6 +111,13 @@ func (flow *handleRelayedTransactionsFlow) requestInvTransactions(
 		idsToRequest = append(idsToRequest, txID)
 	}
 
+	if len(idsToForceRelay) > 0 {
+		err := flow.EnqueueTransactionIDsForPropagation(idsToForceRelay)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if len(idsToRequest) == 0 {
 		return idsToRequest, nil
 	}
@@ -183,8 +215,10 @@ func (flow *handleRelayedTransactionsFlow) receiveTransactions(requestedTransact
 				expectedID, txID)
 		}
 
+		// Orphans are tagged with the peer that relayed them, so that a single peer can't fill the orphan pool
+		orphanTag := miningmanagermodel.Tag(flow.peer.ID().String())
 		acceptedTransactions, err :=
-			flow.Domain().MiningManager().ValidateAndInsertTransaction(tx, false, true)
+			flow.Domain().MiningManager().ValidateAndInsertTaggedTransaction(tx, false, true, orphanTag)
 		if err != nil {
 			ruleErr := &mempool.RuleError{}
 			if !errors.As(err, ruleErr) {
@@ -202,13 +236,13 @@ func (flow *handleRelayedTransactionsFlow) receiveTransactions(requestedTransact
 				continue
 			}
 
-			return protocolerrors.Errorf(true, "rejected transaction %s: %s", txID, ruleErr)
+			return protocolerrors.WrapRejectedf(true, ruleErr, (*externalapi.DomainHash)(txID), "rejected transaction %s", txID)
 		}
 		err = flow.broadcastAcceptedTransactions(consensushashing.TransactionIDs(acceptedTransactions))
 		if err != nil {
 			return err
 		}
-		flow.OnTransactionAddedToMempool()
+		flow.OnTransactionAddedToMempool(acceptedTransactions)
 	}
 	return nil
 }
@@ -0,0 +1,44 @@
+package transactionrelay
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleRequestedPackages listens to appmessage.MsgRequestPackage messages, responding with the
+// requested transaction along with its ancestors in the mempool. A package that isn't available,
+// or that holds a transaction of this node that's still in its Dandelion stem phase, is sent with
+// no transactions.
+func HandleRequestedPackages(context PackagesRelayContext, incomingRoute *router.Route, outgoingRoute *router.Route) error {
+	for {
+		message, err := incomingRoute.Dequeue()
+		if err != nil {
+			return err
+		}
+		msgRequestPackage := message.(*appmessage.MsgRequestPackage)
+
+		var msgTxs []*appmessage.MsgTx
+		transactions, ok := context.Domain().MiningManager().GetRelayPackage(msgRequestPackage.ID)
+		if ok && !isAnyTransactionEmbargoed(context, transactions) {
+			msgTxs = make([]*appmessage.MsgTx, len(transactions))
+			for i, transaction := range transactions {
+				msgTxs[i] = appmessage.DomainTransactionToMsgTx(transaction)
+			}
+		}
+		err = outgoingRoute.Enqueue(appmessage.NewMsgPackage(msgRequestPackage.ID, msgTxs))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func isAnyTransactionEmbargoed(context PackagesRelayContext, transactions []*externalapi.DomainTransaction) bool {
+	for _, transaction := range transactions {
+		if context.IsTransactionEmbargoed(consensushashing.TransactionID(transaction)) {
+			return true
+		}
+	}
+	return false
+}
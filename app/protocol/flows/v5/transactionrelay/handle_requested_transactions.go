@@ -12,7 +12,8 @@ type handleRequestedTransactionsFlow struct {
 
 // HandleRequestedTransactions listens to appmessage.MsgRequestTransactions messages, responding with the requested
 // transactions if those are in the mempool.
-// Missing transactions would be ignored
+// Missing transactions, and transactions of this node that are still in their Dandelion
+// stem phase, are responded to with appmessage.MsgTransactionNotFound
 func HandleRequestedTransactions(context TransactionsRelayContext, incomingRoute *router.Route, outgoingRoute *router.Route) error {
 	flow := &handleRequestedTransactionsFlow{
 		TransactionsRelayContext: context,
@@ -32,7 +33,7 @@ func (flow *handleRequestedTransactionsFlow) start() error {
 		for _, transactionID := range msgRequestTransactions.IDs {
 			tx, _, ok := flow.Domain().MiningManager().GetTransaction(transactionID, true, false)
 
-			if !ok {
+			if !ok || flow.IsTransactionEmbargoed(transactionID) {
 				msgTransactionNotFound := appmessage.NewMsgTransactionNotFound(transactionID)
 				err := flow.outgoingRoute.Enqueue(msgTransactionNotFound)
 				if err != nil {
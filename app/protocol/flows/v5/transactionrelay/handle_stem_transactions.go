@@ -0,0 +1,68 @@
+package transactionrelay
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// StemTransactionsContext is the interface for the context needed for the HandleStemTransactions flow.
+type StemTransactionsContext interface {
+	AddStemTransaction(tx *externalapi.DomainTransaction, sender *peerpkg.Peer) error
+	FluffStemTransaction(tx *externalapi.DomainTransaction) error
+	IsCurrent() (bool, error)
+	AcceptsTransactionsFrom(peer *peerpkg.Peer) bool
+	Config() *config.Config
+}
+
+// HandleStemTransactions listens to appmessage.MsgStemTransaction messages and passes their transactions
+// on along the Dandelion stem, or fluffs them to the rest of the network.
+func HandleStemTransactions(context StemTransactionsContext, incomingRoute *router.Route, peer *peerpkg.Peer) error {
+	cfg := context.Config()
+	for {
+		message, err := incomingRoute.Dequeue()
+		if err != nil {
+			return err
+		}
+		msgStemTransaction := message.(*appmessage.MsgStemTransaction)
+
+		if !context.AcceptsTransactionsFrom(peer) {
+			continue
+		}
+
+		isCurrent, err := context.IsCurrent()
+		if err != nil {
+			return err
+		}
+		// Transaction relay is disabled if the node is out of sync and thus not mining
+		if !isCurrent {
+			continue
+		}
+
+		tx := appmessage.MsgTxToDomainTransaction(msgStemTransaction.Transaction)
+		txID := consensushashing.TransactionID(tx)
+		if cfg.DisableDandelion {
+			err = context.FluffStemTransaction(tx)
+		} else {
+			err = context.AddStemTransaction(tx, peer)
+		}
+		if err != nil {
+			ruleErr := &mempool.RuleError{}
+			if !errors.As(err, ruleErr) {
+				return errors.Wrapf(err, "failed to process stem transaction %s", txID)
+			}
+
+			txRuleErr := &mempool.TxRuleError{}
+			if errors.As(ruleErr.Err, txRuleErr) && txRuleErr.RejectCode == mempool.RejectInvalid {
+				return protocolerrors.WrapRejectedf(true, ruleErr, (*externalapi.DomainHash)(txID),
+					"rejected stem transaction %s", txID)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package transactionrelay_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/protocol/flows/v5/transactionrelay"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+type mocStemTransactionsContext struct {
+	cfg                   *config.Config
+	stemTransactionCount  int
+	fluffTransactionCount int
+}
+
+func (m *mocStemTransactionsContext) AddStemTransaction(_ *externalapi.DomainTransaction, _ *peerpkg.Peer) error {
+	m.stemTransactionCount++
+	return nil
+}
+
+func (m *mocStemTransactionsContext) FluffStemTransaction(_ *externalapi.DomainTransaction) error {
+	m.fluffTransactionCount++
+	return nil
+}
+
+func (m *mocStemTransactionsContext) IsCurrent() (bool, error) {
+	return true, nil
+}
+
+func (m *mocStemTransactionsContext) AcceptsTransactionsFrom(_ *peerpkg.Peer) bool {
+	return !m.cfg.BlocksOnly
+}
+
+func (m *mocStemTransactionsContext) Config() *config.Config {
+	return m.cfg
+}
+
+// TestHandleStemTransactions tests that HandleStemTransactions passes stem transactions on along
+// the stem only if Dandelion is enabled, and ignores them altogether in blocks-only mode
+func TestHandleStemTransactions(t *testing.T) {
+	tests := []struct {
+		name                          string
+		disableDandelion              bool
+		blocksOnly                    bool
+		expectedStemTransactionCount  int
+		expectedFluffTransactionCount int
+	}{
+		{name: "dandelion enabled", expectedStemTransactionCount: 2},
+		{name: "dandelion disabled", disableDandelion: true, expectedFluffTransactionCount: 2},
+		{name: "blocks only", blocksOnly: true},
+	}
+
+	for _, test := range tests {
+		cfg := &config.Config{Flags: &config.Flags{DisableDandelion: test.disableDandelion, BlocksOnly: test.blocksOnly}}
+		context := &mocStemTransactionsContext{cfg: cfg}
+
+		incomingRoute := router.NewRoute("incoming")
+		for i := 0; i < 2; i++ {
+			tx := &appmessage.MsgTx{TxIn: []*appmessage.TxIn{}, TxOut: []*appmessage.TxOut{}}
+			err := incomingRoute.Enqueue(appmessage.NewMsgStemTransaction(tx))
+			if err != nil {
+				t.Fatalf("%s: Enqueue: %s", test.name, err)
+			}
+		}
+		incomingRoute.Close()
+
+		err := transactionrelay.HandleStemTransactions(context, incomingRoute, peerpkg.New(nil))
+		if !errors.Is(err, router.ErrRouteClosed) {
+			t.Fatalf("%s: expected ErrRouteClosed but got %v", test.name, err)
+		}
+		if context.stemTransactionCount != test.expectedStemTransactionCount {
+			t.Fatalf("%s: expected %d stem transactions but got %d",
+				test.name, test.expectedStemTransactionCount, context.stemTransactionCount)
+		}
+		if context.fluffTransactionCount != test.expectedFluffTransactionCount {
+			t.Fatalf("%s: expected %d fluffed transactions but got %d",
+				test.name, test.expectedFluffTransactionCount, context.fluffTransactionCount)
+		}
+	}
+}
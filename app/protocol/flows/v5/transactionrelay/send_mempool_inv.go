@@ -0,0 +1,48 @@
+package transactionrelay
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/infrastructure/network/permissions"
+)
+
+// SendMempoolInvContext is the interface for the context needed for the SendMempoolInv flow.
+type SendMempoolInvContext interface {
+	Domain() domain.Domain
+	IsTransactionEmbargoed(transactionID *externalapi.DomainTransactionID) bool
+}
+
+// SendMempoolInv announces all the transactions in the mempool to a peer with the
+// mempool permission, so that it doesn't have to wait for them to be relayed. Transactions of
+// this node that are still in their Dandelion stem phase aren't announced.
+func SendMempoolInv(context SendMempoolInvContext, outgoingRoute *router.Route, peer *peerpkg.Peer) error {
+	if !peer.HasPermission(permissions.Mempool) || !peer.RelaysTransactions() {
+		return nil
+	}
+
+	transactions, _ := context.Domain().MiningManager().AllTransactions(true, false)
+	transactionIDs := make([]*externalapi.DomainTransactionID, 0, len(transactions))
+	for _, transaction := range transactions {
+		transactionID := consensushashing.TransactionID(transaction)
+		if !context.IsTransactionEmbargoed(transactionID) {
+			transactionIDs = append(transactionIDs, transactionID)
+		}
+	}
+
+	for len(transactionIDs) > 0 {
+		batchSize := len(transactionIDs)
+		if batchSize > appmessage.MaxInvPerTxInvMsg {
+			batchSize = appmessage.MaxInvPerTxInvMsg
+		}
+		err := outgoingRoute.Enqueue(appmessage.NewMsgInvTransaction(transactionIDs[:batchSize]))
+		if err != nil {
+			return err
+		}
+		transactionIDs = transactionIDs[batchSize:]
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package utxoexistence
+
+import (
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/merkle"
+	"github.com/kaspanet/kaspad/domain/txindex"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// UTXOExistenceRequestsContext is the interface for the context needed for the HandleUTXOExistenceRequests flow.
+type UTXOExistenceRequestsContext interface {
+	Config() *config.Config
+	Domain() domain.Domain
+	TXIndex() *txindex.TXIndex
+}
+
+type handleUTXOExistenceRequestsFlow struct {
+	UTXOExistenceRequestsContext
+	incomingRoute, outgoingRoute *router.Route
+	peer                         *peerpkg.Peer
+	quota                        *quota
+}
+
+// HandleUTXOExistenceRequests listens to appmessage.MsgRequestUTXOExistence messages, and
+// answers which of the requested outpoints are in the virtual UTXO set. The requests of
+// a peer that exceed its quota are delayed.
+func HandleUTXOExistenceRequests(context UTXOExistenceRequestsContext, incomingRoute *router.Route,
+	outgoingRoute *router.Route, peer *peerpkg.Peer) error {
+
+	flow := &handleUTXOExistenceRequestsFlow{
+		UTXOExistenceRequestsContext: context,
+		incomingRoute:                incomingRoute,
+		outgoingRoute:                outgoingRoute,
+		peer:                         peer,
+		quota:                        newQuota(time.Now()),
+	}
+	return flow.start()
+}
+
+func (flow *handleUTXOExistenceRequestsFlow) start() error {
+	for {
+		message, err := flow.incomingRoute.Dequeue()
+		if err != nil {
+			return err
+		}
+		if flow.Config().NoGetUTXOs {
+			return protocolerrors.Errorf(true, "peer sent %s while UTXO existence requests are disabled",
+				message.Command())
+		}
+		request := message.(*appmessage.MsgRequestUTXOExistence)
+		log.Debugf("Got request to check %d outpoints from %s", len(request.Outpoints), flow.peer)
+
+		wait := flow.quota.take(requestCost(request), time.Now())
+		if wait > 0 {
+			log.Debugf("Delaying the UTXO existence request of %s by %s to keep it within its quota",
+				flow.peer, wait)
+			time.Sleep(wait)
+		}
+
+		response, err := flow.buildResponse(request)
+		if err != nil {
+			return err
+		}
+		err = flow.outgoingRoute.Enqueue(response)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (flow *handleUTXOExistenceRequestsFlow) buildResponse(
+	request *appmessage.MsgRequestUTXOExistence) (*appmessage.MsgUTXOExistence, error) {
+
+	outpoints := make([]*externalapi.DomainOutpoint, len(request.Outpoints))
+	for i, outpoint := range request.Outpoints {
+		outpoints[i] = &externalapi.DomainOutpoint{
+			TransactionID: outpoint.TxID,
+			Index:         outpoint.Index,
+		}
+	}
+	utxoEntries, virtualSelectedParent, err := flow.Domain().Consensus().GetVirtualUTXOEntries(outpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	existenceBits := make([]byte, (len(outpoints)+7)/8)
+	var entries []*appmessage.UTXOEntry
+	for i, utxoEntry := range utxoEntries {
+		if utxoEntry == nil {
+			continue
+		}
+		existenceBits[i/8] |= 1 << (i % 8)
+		if request.IncludeEntries {
+			entries = append(entries, &appmessage.UTXOEntry{
+				Amount:          utxoEntry.Amount(),
+				ScriptPublicKey: utxoEntry.ScriptPublicKey(),
+				BlockDAAScore:   utxoEntry.BlockDAAScore(),
+				IsCoinbase:      utxoEntry.IsCoinbase(),
+			})
+		}
+	}
+
+	var proofs []*appmessage.UTXOExistenceProof
+	if request.IncludeProofs && flow.TXIndex() != nil {
+		proofs, err = flow.buildProofs(outpoints, utxoEntries)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return appmessage.NewMsgUTXOExistence(virtualSelectedParent, existenceBits, entries, proofs), nil
+}
+
+// buildProofs returns the proofs of the outpoints that exist, skipping those whose
+// transactions aren't indexed or are in blocks whose bodies were pruned
+func (flow *handleUTXOExistenceRequestsFlow) buildProofs(outpoints []*externalapi.DomainOutpoint,
+	utxoEntries []externalapi.UTXOEntry) ([]*appmessage.UTXOExistenceProof, error) {
+
+	// Outputs of the same transaction share a block, which is only read once
+	blocks := make(map[externalapi.DomainHash]*externalapi.DomainBlock)
+
+	var proofs []*appmessage.UTXOExistenceProof
+	for i, outpoint := range outpoints {
+		if utxoEntries[i] == nil {
+			continue
+		}
+		_, blockHash, found, err := flow.TXIndex().Transaction(&outpoint.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		block, ok := blocks[*blockHash]
+		if !ok {
+			block, found, err = flow.Domain().Consensus().GetBlock(blockHash)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				continue
+			}
+			blocks[*blockHash] = block
+		}
+		proof, ok := merkle.TransactionMerkleProof(block.Transactions, &outpoint.TransactionID)
+		if !ok {
+			continue
+		}
+		proofs = append(proofs, &appmessage.UTXOExistenceProof{
+			OutpointIndex:    uint32(i),
+			Header:           appmessage.DomainBlockHeaderToBlockHeader(block.Header),
+			Transaction:      appmessage.DomainTransactionToMsgTx(block.Transactions[proof.Index]),
+			TransactionIndex: proof.Index,
+			Siblings:         proof.Siblings,
+		})
+	}
+	return proofs, nil
+}
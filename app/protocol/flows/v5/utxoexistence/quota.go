@@ -0,0 +1,62 @@
+package utxoexistence
+
+import (
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+)
+
+const (
+	// outpointsPerSecond is the rate at which the outpoints requested by a
+	// single peer are checked. Requests above that rate are delayed.
+	outpointsPerSecond = 100
+
+	// maxOutpointsBurst is the most outpoints of a single peer that are
+	// checked without delay
+	maxOutpointsBurst = appmessage.MaxUTXOExistenceOutpoints
+
+	// proofCost is how many outpoints an outpoint whose proof is requested
+	// counts as, since proving it requires reading its block
+	proofCost = 10
+)
+
+// quota is a token bucket that limits the rate at which the outpoints
+// requested by a peer are checked
+type quota struct {
+	tokens     float64
+	lastUpdate time.Time
+}
+
+// newQuota returns a quota that initially allows a full burst of outpoints
+func newQuota(now time.Time) *quota {
+	return &quota{
+		tokens:     maxOutpointsBurst,
+		lastUpdate: now,
+	}
+}
+
+// requestCost returns how many outpoints the given request counts as
+func requestCost(request *appmessage.MsgRequestUTXOExistence) int {
+	if request.IncludeProofs {
+		return len(request.Outpoints) * proofCost
+	}
+	return len(request.Outpoints)
+}
+
+// take takes cost outpoints out of the quota at the given time, and returns how
+// long to wait before checking them so that the rate stays within the quota
+func (q *quota) take(cost int, now time.Time) time.Duration {
+	if now.After(q.lastUpdate) {
+		q.tokens += now.Sub(q.lastUpdate).Seconds() * outpointsPerSecond
+		if q.tokens > maxOutpointsBurst {
+			q.tokens = maxOutpointsBurst
+		}
+		q.lastUpdate = now
+	}
+
+	q.tokens -= float64(cost)
+	if q.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-q.tokens / outpointsPerSecond * float64(time.Second))
+}
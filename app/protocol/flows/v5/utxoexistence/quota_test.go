@@ -0,0 +1,41 @@
+package utxoexistence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuota(t *testing.T) {
+	start := time.Now()
+	q := newQuota(start)
+
+	// A full burst is allowed right away
+	if wait := q.take(maxOutpointsBurst, start); wait != 0 {
+		t.Fatalf("Unexpected wait. Want: 0, got: %s", wait)
+	}
+
+	// Anything more has to wait until the tokens are refilled
+	if wait := q.take(outpointsPerSecond, start); wait != time.Second {
+		t.Fatalf("Unexpected wait. Want: %s, got: %s", time.Second, wait)
+	}
+
+	// Waiting as long as asked pays the debt back
+	now := start.Add(time.Second)
+	if wait := q.take(outpointsPerSecond/2, now); wait != time.Second/2 {
+		t.Fatalf("Unexpected wait. Want: %s, got: %s", time.Second/2, wait)
+	}
+
+	// The bucket is capped at maxOutpointsBurst
+	now = now.Add(24 * time.Hour)
+	if wait := q.take(maxOutpointsBurst, now); wait != 0 {
+		t.Fatalf("Unexpected wait. Want: 0, got: %s", wait)
+	}
+	if wait := q.take(1, now); wait == 0 {
+		t.Fatalf("Expected to wait once the burst is used up")
+	}
+
+	// A clock that goes backwards doesn't refill the bucket
+	if wait := q.take(1, start); wait == 0 {
+		t.Fatalf("Expected to wait when the clock goes backwards")
+	}
+}
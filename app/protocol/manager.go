@@ -10,6 +10,8 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/cfindex"
+	"github.com/kaspanet/kaspad/domain/txindex"
 
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 
@@ -30,13 +32,14 @@ type Manager struct {
 
 // NewManager creates a new instance of the p2p protocol manager
 func NewManager(cfg *config.Config, domain domain.Domain, netAdapter *netadapter.NetAdapter, addressManager *addressmanager.AddressManager,
-	connectionManager *connmanager.ConnectionManager) (*Manager, error) {
+	connectionManager *connmanager.ConnectionManager, cfIndex *cfindex.CFIndex, txIndex *txindex.TXIndex) (*Manager, error) {
 
 	manager := Manager{
-		context: flowcontext.New(cfg, domain, addressManager, netAdapter, connectionManager),
+		context: flowcontext.New(cfg, domain, addressManager, netAdapter, connectionManager, cfIndex, txIndex),
 	}
 
 	netAdapter.SetP2PRouterInitializer(manager.routerInitializer)
+	connectionManager.SetPeerStatsSource(manager.context)
 	return &manager, nil
 }
 
@@ -68,6 +71,11 @@ func (m *Manager) AddTransaction(tx *externalapi.DomainTransaction, allowOrphan
 	return m.context.AddTransaction(tx, allowOrphan)
 }
 
+// AddPackage adds the transactions of the given package to the mempool and propagates the package.
+func (m *Manager) AddPackage(transactions []*externalapi.DomainTransaction) error {
+	return m.context.AddPackage(transactions)
+}
+
 // AddBlock adds the given block to the DAG and propagates it.
 func (m *Manager) AddBlock(block *externalapi.DomainBlock) error {
 	return m.context.AddBlock(block)
@@ -106,6 +114,11 @@ func (m *Manager) SetOnTransactionAddedToMempoolHandler(onTransactionAddedToMemp
 	m.context.SetOnTransactionAddedToMempoolHandler(onTransactionAddedToMempoolHandler)
 }
 
+// SetOnBlockAddedHandler sets the onBlockAdded handler
+func (m *Manager) SetOnBlockAddedHandler(onBlockAddedHandler flowcontext.OnBlockAddedHandler) {
+	m.context.SetOnBlockAddedHandler(onBlockAddedHandler)
+}
+
 // IsIBDRunning returns true if IBD is currently marked as running
 func (m *Manager) IsIBDRunning() bool {
 	return m.context.IsIBDRunning()
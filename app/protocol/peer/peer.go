@@ -2,6 +2,7 @@ package peer
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
@@ -9,6 +10,8 @@ import (
 
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/id"
+	"github.com/kaspanet/kaspad/infrastructure/network/permissions"
+	"github.com/kaspanet/kaspad/util/bloom"
 	mathUtil "github.com/kaspanet/kaspad/util/math"
 	"github.com/kaspanet/kaspad/util/mstime"
 )
@@ -27,12 +30,23 @@ type Peer struct {
 	timeOffset        time.Duration
 	connectionStarted time.Time
 
-	pingLock         sync.RWMutex
-	lastPingNonce    uint64        // The nonce of the last ping we sent
-	lastPingTime     time.Time     // Time we sent last ping
-	lastPingDuration time.Duration // Time for last ping to return
+	pingLock          sync.RWMutex
+	lastPingNonce     uint64        // The nonce of the last ping we sent
+	lastPingTime      time.Time     // Time we sent last ping
+	lastPingDuration  time.Duration // Time for last ping to return
+	minPingDuration   time.Duration // Time for the fastest ping to return
+	totalPingDuration time.Duration // Time for all the pings to return
+	pingCount         uint64        // The number of pings that returned
+
+	announcementsLock               sync.RWMutex
+	lastBlockAnnouncementTime       time.Time // Time the peer last announced a block to us
+	lastTransactionAnnouncementTime time.Time // Time the peer last announced transactions to us
 
 	ibdRequestChannel chan *externalapi.DomainBlock // A channel used to communicate IBD requests between flows
+
+	farFutureBlockCount uint32 // The number of blocks too far in the future the peer relayed to us
+
+	filter *bloom.Filter // The BIP37 bloom filter loaded by the peer, if any
 }
 
 // New returns a new Peer
@@ -41,6 +55,7 @@ func New(connection *netadapter.NetConnection) *Peer {
 		connection:        connection,
 		connectionStarted: time.Now(),
 		ibdRequestChannel: make(chan *externalapi.DomainBlock),
+		filter:            bloom.LoadFilter(nil),
 	}
 }
 
@@ -49,6 +64,13 @@ func (p *Peer) Connection() *netadapter.NetConnection {
 	return p.connection
 }
 
+// HasPermission returns whether the peer was given all of the given permissions
+// by --whitelist or --whitebind
+func (p *Peer) HasPermission(flags permissions.Flags) bool {
+	// Peers that aren't backed by a connection, such as the ones of tests, have none
+	return p.connection != nil && p.connection.Permissions().Has(flags)
+}
+
 // SubnetworkID returns the subnetwork the peer is associated with.
 // It is nil in full nodes.
 func (p *Peer) SubnetworkID() *externalapi.DomainSubnetworkID {
@@ -90,6 +112,29 @@ func (p *Peer) IsOutbound() bool {
 	return p.connection.IsOutbound()
 }
 
+// HasService returns whether the peer advertised support for the given service.
+func (p *Peer) HasService(service appmessage.ServiceFlag) bool {
+	return p.services&service == service
+}
+
+// Services returns the services that the peer advertised.
+func (p *Peer) Services() appmessage.ServiceFlag {
+	return p.services
+}
+
+// Filter returns the bloom filter loaded by the peer.
+// Use Filter().IsLoaded() to check whether the peer loaded one.
+func (p *Peer) Filter() *bloom.Filter {
+	return p.filter
+}
+
+// RelaysTransactions returns whether transactions should be announced to the peer.
+// A peer that asked not to be sent transactions in its version message starts
+// receiving the ones that match its filter once it loads one.
+func (p *Peer) RelaysTransactions() bool {
+	return !p.disableRelayTx || p.filter.IsLoaded()
+}
+
 // UpdateFieldsFromMsgVersion updates the peer with the data from the version message.
 func (p *Peer) UpdateFieldsFromMsgVersion(msg *appmessage.MsgVersion, maxProtocolVersion uint32) {
 	// Negotiate the protocol version.
@@ -127,6 +172,11 @@ func (p *Peer) SetPingIdle() {
 
 	p.lastPingNonce = 0
 	p.lastPingDuration = time.Since(p.lastPingTime)
+	if p.pingCount == 0 || p.lastPingDuration < p.minPingDuration {
+		p.minPingDuration = p.lastPingDuration
+	}
+	p.totalPingDuration += p.lastPingDuration
+	p.pingCount++
 }
 
 func (p *Peer) String() string {
@@ -147,6 +197,73 @@ func (p *Peer) LastPingDuration() time.Duration {
 	return p.lastPingDuration
 }
 
+// MinPingDuration returns the duration of the fastest ping to
+// this peer
+func (p *Peer) MinPingDuration() time.Duration {
+	p.pingLock.RLock()
+	defer p.pingLock.RUnlock()
+
+	return p.minPingDuration
+}
+
+// AveragePingDuration returns the average duration of the pings to
+// this peer, or 0 if none returned yet
+func (p *Peer) AveragePingDuration() time.Duration {
+	p.pingLock.RLock()
+	defer p.pingLock.RUnlock()
+
+	if p.pingCount == 0 {
+		return 0
+	}
+	return p.totalPingDuration / time.Duration(p.pingCount)
+}
+
+// RecordBlockAnnouncement records that the peer announced a block to us
+func (p *Peer) RecordBlockAnnouncement() {
+	p.announcementsLock.Lock()
+	defer p.announcementsLock.Unlock()
+
+	p.lastBlockAnnouncementTime = time.Now()
+}
+
+// RecordTransactionAnnouncement records that the peer announced transactions to us
+func (p *Peer) RecordTransactionAnnouncement() {
+	p.announcementsLock.Lock()
+	defer p.announcementsLock.Unlock()
+
+	p.lastTransactionAnnouncementTime = time.Now()
+}
+
+// LastBlockAnnouncementTime returns the time the peer last announced a block
+// to us, or the zero time if it never did
+func (p *Peer) LastBlockAnnouncementTime() time.Time {
+	p.announcementsLock.RLock()
+	defer p.announcementsLock.RUnlock()
+
+	return p.lastBlockAnnouncementTime
+}
+
+// LastTransactionAnnouncementTime returns the time the peer last announced
+// transactions to us, or the zero time if it never did
+func (p *Peer) LastTransactionAnnouncementTime() time.Time {
+	p.announcementsLock.RLock()
+	defer p.announcementsLock.RUnlock()
+
+	return p.lastTransactionAnnouncementTime
+}
+
+// RecordFarFutureBlock records that the peer relayed a block whose timestamp is too far
+// in the future, and returns the number of such blocks it relayed
+func (p *Peer) RecordFarFutureBlock() uint32 {
+	return atomic.AddUint32(&p.farFutureBlockCount, 1)
+}
+
+// FarFutureBlockCount returns the number of blocks whose timestamp is too far in the
+// future that the peer relayed to us
+func (p *Peer) FarFutureBlockCount() uint32 {
+	return atomic.LoadUint32(&p.farFutureBlockCount)
+}
+
 // IBDRequestChannel returns the channel used in order to communicate an IBD request between peer flows
 func (p *Peer) IBDRequestChannel() chan *externalapi.DomainBlock {
 	return p.ibdRequestChannel
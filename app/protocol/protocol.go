@@ -46,6 +46,12 @@ func (m *Manager) routerInitializer(router *routerpkg.Router, netConnection *net
 			netConnection.Disconnect()
 			return
 		}
+		if !m.context.ConnectionManager().AllowIncoming(netConnection) {
+			log.Infof("Peer %s exceeded the incoming connection rate of its netgroup. Disconnecting...",
+				netConnection)
+			netConnection.Disconnect()
+			return
+		}
 
 		netConnection.SetOnInvalidMessageHandler(func(err error) {
 			if atomic.AddUint32(&isStopping, 1) == 1 {
@@ -94,6 +100,9 @@ func (m *Manager) routerInitializer(router *routerpkg.Router, netConnection *net
 		err = m.runFlows(flows, peer, errChan, flowsWaitGroup)
 		if err != nil {
 			m.handleError(err, netConnection, router.OutgoingRoute())
+			// Some flows, like SendPings, only notice that the connection is closed the
+			// next time they use it, so the peer is removed without waiting for them
+			m.context.RemoveFromPeers(peer)
 			// We call `flowsWaitGroup.Wait()` in two places instead of deferring, because
 			// we already defer `m.routersWaitGroup.Done()`, so we try to avoid error prone
 			// and confusing use of multiple dependent defers.
@@ -110,11 +119,13 @@ func (m *Manager) handleError(err error, netConnection *netadapter.NetConnection
 			log.Warnf("Banning %s (reason: %s)", netConnection, protocolErr.Cause)
 
 			err := m.context.ConnectionManager().Ban(netConnection)
-			if err != nil && !errors.Is(err, connmanager.ErrCannotBanPermanent) {
+			if err != nil && !errors.Is(err, connmanager.ErrCannotBanPermanent) &&
+				!errors.Is(err, connmanager.ErrCannotBanNoBan) {
 				panic(err)
 			}
 
-			err = outgoingRoute.Enqueue(appmessage.NewMsgReject(protocolErr.Error()))
+			rejectCode := protocolerrors.RejectCode(protocolErr.Cause)
+			err = outgoingRoute.Enqueue(appmessage.NewMsgReject(rejectCode, protocolErr.RejectedHash, protocolErr.Error()))
 			if err != nil && !errors.Is(err, routerpkg.ErrRouteClosed) {
 				panic(err)
 			}
@@ -1,7 +1,10 @@
 package protocolerrors
 
 import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
 	"github.com/pkg/errors"
 )
 
@@ -10,6 +13,11 @@ import (
 type ProtocolError struct {
 	ShouldBan bool
 	Cause     error
+
+	// RejectedHash is the hash of the block or the ID of the transaction
+	// that caused this error, if any. It is reported to the peer in the
+	// reject message sent when banning it.
+	RejectedHash *externalapi.DomainHash
 }
 
 func (e ProtocolError) Error() string {
@@ -55,6 +63,16 @@ func Wrapf(shouldBan bool, err error, format string, args ...interface{}) error
 	}
 }
 
+// WrapRejectedf wraps the given error with the given format and returns it as a ProtocolError
+// that reports the hash of the rejected block or the ID of the rejected transaction.
+func WrapRejectedf(shouldBan bool, err error, rejectedHash *externalapi.DomainHash, format string, args ...interface{}) error {
+	return ProtocolError{
+		ShouldBan:    shouldBan,
+		Cause:        errors.Wrapf(err, format, args...),
+		RejectedHash: rejectedHash,
+	}
+}
+
 // ConvertToBanningProtocolErrorIfRuleError converts the given error to
 // a banning protocol error if it's a rule error, and otherwise keep it
 // as is.
@@ -65,3 +83,23 @@ func ConvertToBanningProtocolErrorIfRuleError(err error, format string, args ...
 
 	return Wrapf(true, err, format, args...)
 }
+
+// RejectCode returns the code to report in a reject message sent because of the given error
+func RejectCode(err error) appmessage.RejectCode {
+	switch {
+	case errors.As(err, &ruleerrors.ErrMissingParents{}):
+		return appmessage.RejectCodeMissingParents
+	case errors.Is(err, ruleerrors.ErrTooManyParents):
+		return appmessage.RejectCodeTooManyParents
+	case errors.Is(err, ruleerrors.ErrViolatingBoundedMergeDepth),
+		errors.Is(err, ruleerrors.ErrFinalityPointTimeTooOld),
+		errors.Is(err, ruleerrors.ErrSuggestedPruningViolatesFinality):
+		return appmessage.RejectCodeFinalityViolation
+	case errors.As(err, &mempool.RuleError{}):
+		return appmessage.RejectCodeInvalidTransaction
+	case errors.As(err, &ruleerrors.RuleError{}):
+		return appmessage.RejectCodeInvalidBlock
+	default:
+		return appmessage.RejectCodeProtocolViolation
+	}
+}
@@ -0,0 +1,65 @@
+package protocolerrors
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/pkg/errors"
+)
+
+func TestRejectCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected appmessage.RejectCode
+	}{
+		{
+			name:     "missing parents",
+			err:      ruleerrors.NewErrMissingParents([]*externalapi.DomainHash{{}}),
+			expected: appmessage.RejectCodeMissingParents,
+		},
+		{
+			name:     "too many parents",
+			err:      errors.Wrapf(ruleerrors.ErrTooManyParents, "block points to too many parents"),
+			expected: appmessage.RejectCodeTooManyParents,
+		},
+		{
+			name:     "bounded merge depth violation",
+			err:      errors.Wrapf(ruleerrors.ErrViolatingBoundedMergeDepth, "block violates finality"),
+			expected: appmessage.RejectCodeFinalityViolation,
+		},
+		{
+			name:     "other block rule error",
+			err:      errors.Wrapf(ruleerrors.ErrBadMerkleRoot, "bad merkle root"),
+			expected: appmessage.RejectCodeInvalidBlock,
+		},
+		{
+			name:     "transaction rule error",
+			err:      mempool.RuleError{Err: errors.New("invalid transaction")},
+			expected: appmessage.RejectCodeInvalidTransaction,
+		},
+		{
+			name:     "protocol violation",
+			err:      errors.New("received unexpected message type"),
+			expected: appmessage.RejectCodeProtocolViolation,
+		},
+	}
+
+	for _, test := range tests {
+		rejectedHash := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{1})
+		protocolErr := ProtocolError{}
+		if !errors.As(WrapRejectedf(true, test.err, rejectedHash, "rejected"), &protocolErr) {
+			t.Fatalf("%s: expected a ProtocolError", test.name)
+		}
+		if !protocolErr.RejectedHash.Equal(rejectedHash) {
+			t.Fatalf("%s: unexpected rejected hash %s", test.name, protocolErr.RejectedHash)
+		}
+		code := RejectCode(protocolErr.Cause)
+		if code != test.expected {
+			t.Fatalf("%s: expected reject code %s but got %s", test.name, test.expected, code)
+		}
+	}
+}
@@ -1,25 +1,42 @@
 package rpc
 
 import (
+	"net/http"
+
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/app/protocol"
+	"github.com/kaspanet/kaspad/app/rpc/rest"
 	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
 	"github.com/kaspanet/kaspad/domain"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/txindex"
 	"github.com/kaspanet/kaspad/domain/utxoindex"
 	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/compactor"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
 	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
 	"github.com/kaspanet/kaspad/infrastructure/network/connmanager"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/kaspanet/kaspad/util/processingstats"
 	"github.com/pkg/errors"
 )
 
 // Manager is an RPC manager
 type Manager struct {
-	context *rpccontext.Context
+	context                   *rpccontext.Context
+	onFinalityConflictHandler OnFinalityConflictHandler
+	onVirtualChangeHandler    OnVirtualChangeHandler
 }
 
+// OnFinalityConflictHandler is a handler function that's triggered when a finality conflict is detected
+type OnFinalityConflictHandler func(violatingBlockHash *externalapi.DomainHash)
+
+// OnVirtualChangeHandler is a handler function that's triggered once the notifications of a virtual change are sent
+type OnVirtualChangeHandler func(virtualChangeSet *externalapi.VirtualChangeSet)
+
 // NewManager creates a new RPC Manager
 func NewManager(
 	cfg *config.Config,
@@ -29,6 +46,10 @@ func NewManager(
 	connectionManager *connmanager.ConnectionManager,
 	addressManager *addressmanager.AddressManager,
 	utxoIndex *utxoindex.UTXOIndex,
+	txIndex *txindex.TXIndex,
+	db database.Database,
+	dbCompactor *compactor.Compactor,
+	startDatabaseBackup func(backupPath string) (string, error),
 	consensusEventsChan chan externalapi.ConsensusEvent,
 	shutDownChan chan<- struct{}) *Manager {
 
@@ -41,16 +62,48 @@ func NewManager(
 			connectionManager,
 			addressManager,
 			utxoIndex,
+			txIndex,
+			db,
+			dbCompactor,
+			startDatabaseBackup,
 			shutDownChan,
 		),
 	}
 	netAdapter.SetRPCRouterInitializer(manager.routerInitializer)
+	netAdapter.RegisterRPCService(&protowire.TypedRPC_ServiceDesc, newTypedRPCServer(manager.context))
 
 	manager.initConsensusEventsHandler(consensusEventsChan)
 
 	return &manager
 }
 
+// Stop releases the RPC calls that are waiting on the node, such as long polling
+// getBlockTemplate calls, so that they don't outlive the node's shutdown. It also
+// completes the files of the profiles that are still being captured.
+func (m *Manager) Stop() {
+	m.context.BlockTemplateState.Close()
+
+	err := m.context.Profiler.StopAll()
+	if err != nil {
+		log.Errorf("Error stopping the running profiles: %s", err)
+	}
+}
+
+// SetOnFinalityConflictHandler sets the onFinalityConflict handler
+func (m *Manager) SetOnFinalityConflictHandler(onFinalityConflictHandler OnFinalityConflictHandler) {
+	m.onFinalityConflictHandler = onFinalityConflictHandler
+}
+
+// SetOnVirtualChangeHandler sets the onVirtualChange handler
+func (m *Manager) SetOnVirtualChangeHandler(onVirtualChangeHandler OnVirtualChangeHandler) {
+	m.onVirtualChangeHandler = onVirtualChangeHandler
+}
+
+// RegisterRESTHandlers registers the handlers of the read-only REST interface on the given mux
+func (m *Manager) RegisterRESTHandlers(mux *http.ServeMux) {
+	rest.RegisterHandlers(mux, m.context)
+}
+
 func (m *Manager) initConsensusEventsHandler(consensusEventsChan chan externalapi.ConsensusEvent) {
 	spawn("consensusEventsHandler", func() {
 		for {
@@ -64,11 +117,27 @@ func (m *Manager) initConsensusEventsHandler(consensusEventsChan chan externalap
 				if err != nil {
 					panic(err)
 				}
+				if m.onVirtualChangeHandler != nil {
+					m.onVirtualChangeHandler(event)
+				}
 			case *externalapi.BlockAdded:
 				err := m.notifyBlockAddedToDAG(event.Block)
 				if err != nil {
 					panic(err)
 				}
+			case *externalapi.BlockHeaderAdded:
+				err := m.notifyBlockHeaderAdded(event.Header)
+				if err != nil {
+					panic(err)
+				}
+			case *externalapi.FinalityConflict:
+				err := m.NotifyFinalityConflict(event.ViolatingBlockHash.String())
+				if err != nil {
+					panic(err)
+				}
+				if m.onFinalityConflictHandler != nil {
+					m.onFinalityConflictHandler(event.ViolatingBlockHash)
+				}
 			default:
 				panic(errors.Errorf("Got event of unsupported type %T", consensusEvent))
 			}
@@ -81,6 +150,15 @@ func (m *Manager) notifyBlockAddedToDAG(block *externalapi.DomainBlock) error {
 	onEnd := logger.LogAndMeasureExecutionTime(log, "RPCManager.notifyBlockAddedToDAG")
 	defer onEnd()
 
+	if m.context.TXIndex != nil {
+		onTXIndexUpdateEnd := processingstats.Measure(processingstats.TXIndexUpdate)
+		err := m.context.TXIndex.Update(block)
+		onTXIndexUpdateEnd()
+		if err != nil {
+			return err
+		}
+	}
+
 	// Before converting the block and populating it, we check if any listeners are interested.
 	// This is done since most nodes do not use this event.
 	if !m.context.NotificationManager.HasBlockAddedListeners() {
@@ -102,6 +180,20 @@ func (m *Manager) notifyBlockAddedToDAG(block *externalapi.DomainBlock) error {
 }
 
 // notifyVirtualChange notifies the manager that the virtual block has been changed.
+func (m *Manager) notifyBlockHeaderAdded(header externalapi.BlockHeader) error {
+	onEnd := logger.LogAndMeasureExecutionTime(log, "RPCManager.NotifyBlockHeaderAdded")
+	defer onEnd()
+
+	// Headers are added at a high rate during IBD, so they're only converted if somebody's listening
+	if !m.context.NotificationManager.HasBlockHeaderAddedListeners() {
+		return nil
+	}
+
+	notification := appmessage.NewBlockHeaderAddedNotificationMessage(
+		consensushashing.HeaderHash(header).String(), appmessage.DomainBlockHeaderToRPCBlockHeader(header))
+	return m.context.NotificationManager.NotifyBlockHeaderAdded(notification)
+}
+
 func (m *Manager) notifyVirtualChange(virtualChangeSet *externalapi.VirtualChangeSet) error {
 	onEnd := logger.LogAndMeasureExecutionTime(log, "RPCManager.NotifyVirtualChange")
 	defer onEnd()
@@ -123,6 +215,11 @@ func (m *Manager) notifyVirtualChange(virtualChangeSet *externalapi.VirtualChang
 		return err
 	}
 
+	err = m.notifyDAGTipChanged(virtualChangeSet)
+	if err != nil {
+		return err
+	}
+
 	if virtualChangeSet.VirtualSelectedParentChainChanges == nil ||
 		(len(virtualChangeSet.VirtualSelectedParentChainChanges.Added) == 0 &&
 			len(virtualChangeSet.VirtualSelectedParentChainChanges.Removed) == 0) {
@@ -141,6 +238,8 @@ func (m *Manager) notifyVirtualChange(virtualChangeSet *externalapi.VirtualChang
 // NotifyNewBlockTemplate notifies the manager that a new
 // block template is available for miners
 func (m *Manager) NotifyNewBlockTemplate() error {
+	m.context.BlockTemplateState.NotifyNewBlockTemplate()
+
 	notification := appmessage.NewNewBlockTemplateNotificationMessage()
 	return m.context.NotificationManager.NotifyNewBlockTemplate(notification)
 }
@@ -183,7 +282,9 @@ func (m *Manager) notifyUTXOsChanged(virtualChangeSet *externalapi.VirtualChange
 	onEnd := logger.LogAndMeasureExecutionTime(log, "RPCManager.NotifyUTXOsChanged")
 	defer onEnd()
 
+	onUTXOIndexUpdateEnd := processingstats.Measure(processingstats.UTXOIndexUpdate)
 	utxoIndexChanges, err := m.context.UTXOIndex.Update(virtualChangeSet)
+	onUTXOIndexUpdateEnd()
 	if err != nil {
 		return err
 	}
@@ -219,6 +320,22 @@ func (m *Manager) notifyVirtualDaaScoreChanged(virtualDAAScore uint64) error {
 	return m.context.NotificationManager.NotifyVirtualDaaScoreChanged(notification)
 }
 
+func (m *Manager) notifyDAGTipChanged(virtualChangeSet *externalapi.VirtualChangeSet) error {
+	onEnd := logger.LogAndMeasureExecutionTime(log, "RPCManager.NotifyDAGTipChanged")
+	defer onEnd()
+
+	// Collecting the accepted transaction IDs is a heavy operation, so it's skipped if nobody's listening
+	if !m.context.NotificationManager.HasDAGTipChangedListeners() {
+		return nil
+	}
+
+	notification, err := m.context.ConvertVirtualChangeSetToDAGTipChangedNotificationMessage(virtualChangeSet)
+	if err != nil {
+		return err
+	}
+	return m.context.NotificationManager.NotifyDAGTipChanged(notification)
+}
+
 func (m *Manager) notifyVirtualSelectedParentChainChanged(virtualChangeSet *externalapi.VirtualChangeSet) error {
 	onEnd := logger.LogAndMeasureExecutionTime(log, "RPCManager.NotifyVirtualSelectedParentChainChanged")
 	defer onEnd()
@@ -231,6 +348,13 @@ func (m *Manager) notifyVirtualSelectedParentChainChanged(virtualChangeSet *exte
 		if err != nil {
 			return err
 		}
+		if includeAcceptedTransactionIDs {
+			notification.UnacceptedTransactionIDs, err = m.context.GetUnacceptedTransactionIDs(
+				virtualChangeSet.VirtualSelectedParentChainChanges, notification.AcceptedTransactionIDs)
+			if err != nil {
+				return err
+			}
+		}
 		return m.context.NotificationManager.NotifyVirtualSelectedParentChainChanged(notification)
 	}
 
@@ -0,0 +1,294 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/app/rpc/rpchandlers"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionid"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// The formats in which blocks and transactions are served. JSON objects are
+// the same as the ones returned by the respective RPC commands, and binary
+// objects are serialized the way they're serialized in the P2P protocol.
+const (
+	formatJSON   = "json"
+	formatBinary = "bin"
+)
+
+const (
+	blockPath       = "/rest/block/"
+	transactionPath = "/rest/tx/"
+	checkpointPath  = "/rest/utxo/checkpoint"
+)
+
+// RegisterHandlers registers the handlers of the read-only REST interface on the given mux:
+//
+//	/rest/block/<hash>.json|.bin - a block along with its transactions
+//	/rest/tx/<id>[.json|.bin] - a transaction in the mempool, or in the DAG if the transaction index is enabled
+//	/rest/utxo/checkpoint - the pruning point and the commitment to its UTXO set
+func RegisterHandlers(mux *http.ServeMux, context *rpccontext.Context) {
+	mux.HandleFunc(blockPath, getOnly(func(w http.ResponseWriter, r *http.Request) {
+		handleBlock(context, w, r)
+	}))
+	mux.HandleFunc(transactionPath, getOnly(func(w http.ResponseWriter, r *http.Request) {
+		handleTransaction(context, w, r)
+	}))
+	mux.HandleFunc(checkpointPath, getOnly(func(w http.ResponseWriter, r *http.Request) {
+		handleCheckpoint(context, w, r)
+	}))
+	log.Infof("REST interface enabled at /rest/")
+}
+
+func getOnly(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "only GET requests are supported", http.StatusMethodNotAllowed)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// parseResource splits the last part of the path into the requested
+// resource and its format, falling back to defaultFormat if none is given
+func parseResource(path string, prefix string, defaultFormat string) (resource string, format string, ok bool) {
+	resource = strings.TrimPrefix(path, prefix)
+	format = defaultFormat
+	if dotIndex := strings.LastIndex(resource, "."); dotIndex >= 0 {
+		resource, format = resource[:dotIndex], resource[dotIndex+1:]
+	}
+	if format != formatJSON && format != formatBinary {
+		return "", "", false
+	}
+	return resource, format, resource != "" && !strings.Contains(resource, "/")
+}
+
+func handleBlock(context *rpccontext.Context, w http.ResponseWriter, r *http.Request) {
+	hashString, format, ok := parseResource(r.URL.Path, blockPath, "")
+	if !ok {
+		http.Error(w, "expected /rest/block/<hash>.json or /rest/block/<hash>.bin", http.StatusBadRequest)
+		return
+	}
+	hash, err := externalapi.NewDomainHashFromString(hashString)
+	if err != nil {
+		http.Error(w, "malformed block hash: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if format == formatBinary {
+		block, found, err := context.Domain.Consensus().GetBlock(hash)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		if !found {
+			http.Error(w, "block "+hash.String()+" not found", http.StatusNotFound)
+			return
+		}
+		message, err := protowire.FromAppMessage(appmessage.DomainBlockToMsgBlock(block))
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		writeProtobuf(w, r, message.GetBlock())
+		return
+	}
+
+	blockInfo, err := context.Domain.Consensus().GetBlockInfo(hash)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	if !blockInfo.Exists {
+		http.Error(w, "block "+hash.String()+" not found", http.StatusNotFound)
+		return
+	}
+	response, err := rpchandlers.HandleGetBlock(context, nil,
+		&appmessage.GetBlockRequestMessage{Hash: hashString, IncludeTransactions: true})
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	getBlockResponse := response.(*appmessage.GetBlockResponseMessage)
+	if getBlockResponse.Error != nil {
+		writeInternalError(w, r, errors.New(getBlockResponse.Error.Message))
+		return
+	}
+	message, err := protowire.FromAppMessage(getBlockResponse)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeProtoJSON(w, r, message.GetGetBlockResponse().Block)
+}
+
+func handleTransaction(context *rpccontext.Context, w http.ResponseWriter, r *http.Request) {
+	transactionIDString, format, ok := parseResource(r.URL.Path, transactionPath, formatJSON)
+	if !ok {
+		http.Error(w, "expected /rest/tx/<id>, /rest/tx/<id>.json or /rest/tx/<id>.bin", http.StatusBadRequest)
+		return
+	}
+	transactionID, err := transactionid.FromString(transactionIDString)
+	if err != nil {
+		http.Error(w, "malformed transaction ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	transaction, isInMempool, found, err := findTransaction(context, transactionID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	if !found {
+		http.Error(w, "transaction "+transactionID.String()+" not found", http.StatusNotFound)
+		return
+	}
+
+	if format == formatBinary {
+		message, err := protowire.FromAppMessage(appmessage.DomainTransactionToMsgTx(transaction))
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		writeProtobuf(w, r, message.GetTransaction())
+		return
+	}
+
+	if !isInMempool {
+		writeIndexedTransaction(context, w, r, transactionIDString)
+		return
+	}
+	response, err := rpchandlers.HandleGetMempoolEntry(context, nil,
+		&appmessage.GetMempoolEntryRequestMessage{TxID: transactionIDString, IncludeOrphanPool: true})
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	getMempoolEntryResponse := response.(*appmessage.GetMempoolEntryResponseMessage)
+	if getMempoolEntryResponse.Error != nil {
+		writeInternalError(w, r, errors.New(getMempoolEntryResponse.Error.Message))
+		return
+	}
+	message, err := protowire.FromAppMessage(getMempoolEntryResponse)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeProtoJSON(w, r, message.GetGetMempoolEntryResponse().Entry)
+}
+
+// findTransaction looks for the transaction of the given ID in the mempool, including
+// its orphan pool, and then in the transaction index if it's enabled
+func findTransaction(context *rpccontext.Context, transactionID *externalapi.DomainTransactionID) (
+	transaction *externalapi.DomainTransaction, isInMempool bool, found bool, err error) {
+
+	transaction, _, found = context.Domain.MiningManager().GetTransaction(transactionID, true, true)
+	if found || context.TXIndex == nil {
+		return transaction, found, found, nil
+	}
+	transaction, _, found, err = context.TXIndex.Transaction(transactionID)
+	if err != nil {
+		return nil, false, false, err
+	}
+	return transaction, false, found, nil
+}
+
+// writeIndexedTransaction writes a transaction that was found in the transaction index
+// as the response of getRawTransaction, which includes its confirmations
+func writeIndexedTransaction(context *rpccontext.Context, w http.ResponseWriter, r *http.Request,
+	transactionIDString string) {
+
+	response, err := rpchandlers.HandleGetRawTransaction(context, nil,
+		&appmessage.GetRawTransactionRequestMessage{TxID: transactionIDString})
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	getRawTransactionResponse := response.(*appmessage.GetRawTransactionResponseMessage)
+	if getRawTransactionResponse.Error != nil {
+		writeInternalError(w, r, errors.New(getRawTransactionResponse.Error.Message))
+		return
+	}
+	message, err := protowire.FromAppMessage(getRawTransactionResponse)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeProtoJSON(w, r, message.GetGetRawTransactionResponse())
+}
+
+// utxoCheckpoint is the pruning point along with the commitment to its UTXO set,
+// which light clients may use to verify a UTXO set they download
+type utxoCheckpoint struct {
+	PruningPointHash string `json:"pruningPointHash"`
+	UTXOCommitment   string `json:"utxoCommitment"`
+	DAAScore         uint64 `json:"daaScore"`
+	BlueScore        uint64 `json:"blueScore"`
+}
+
+func handleCheckpoint(context *rpccontext.Context, w http.ResponseWriter, r *http.Request) {
+	pruningPoint, err := context.Domain.Consensus().PruningPoint()
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	pruningPointHeader, err := context.Domain.Consensus().GetBlockHeader(pruningPoint)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	checkpoint := &utxoCheckpoint{
+		PruningPointHash: pruningPoint.String(),
+		UTXOCommitment:   pruningPointHeader.UTXOCommitment().String(),
+		DAAScore:         pruningPointHeader.DAAScore(),
+		BlueScore:        pruningPointHeader.BlueScore(),
+	}
+	body, err := json.Marshal(checkpoint)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	write(w, r, "application/json", body)
+}
+
+func writeProtoJSON(w http.ResponseWriter, r *http.Request, message proto.Message) {
+	body, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(message)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	write(w, r, "application/json", body)
+}
+
+func writeProtobuf(w http.ResponseWriter, r *http.Request, message proto.Message) {
+	body, err := proto.Marshal(message)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	write(w, r, "application/octet-stream", body)
+}
+
+func write(w http.ResponseWriter, r *http.Request, contentType string, body []byte) {
+	w.Header().Set("Content-Type", contentType)
+	_, err := w.Write(body)
+	if err != nil {
+		log.Debugf("Error writing the response to %s %s: %s", r.Method, r.URL.Path, err)
+	}
+}
+
+func writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	log.Errorf("Error handling %s %s: %+v", r.Method, r.URL.Path, err)
+	http.Error(w, "internal error", http.StatusInternalServerError)
+}
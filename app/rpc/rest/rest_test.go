@@ -0,0 +1,40 @@
+package rest
+
+import "testing"
+
+func TestParseResource(t *testing.T) {
+	tests := []struct {
+		path             string
+		prefix           string
+		defaultFormat    string
+		expectedResource string
+		expectedFormat   string
+		expectedOK       bool
+	}{
+		{path: "/rest/block/abcd.json", prefix: blockPath, expectedResource: "abcd", expectedFormat: formatJSON, expectedOK: true},
+		{path: "/rest/block/abcd.bin", prefix: blockPath, expectedResource: "abcd", expectedFormat: formatBinary, expectedOK: true},
+		{path: "/rest/block/abcd", prefix: blockPath, expectedOK: false},
+		{path: "/rest/block/abcd.hex", prefix: blockPath, expectedOK: false},
+		{path: "/rest/block/.json", prefix: blockPath, expectedOK: false},
+		{path: "/rest/block/ab/cd.json", prefix: blockPath, expectedOK: false},
+		{path: "/rest/tx/abcd", prefix: transactionPath, defaultFormat: formatJSON,
+			expectedResource: "abcd", expectedFormat: formatJSON, expectedOK: true},
+		{path: "/rest/tx/abcd.bin", prefix: transactionPath, defaultFormat: formatJSON,
+			expectedResource: "abcd", expectedFormat: formatBinary, expectedOK: true},
+	}
+
+	for _, test := range tests {
+		resource, format, ok := parseResource(test.path, test.prefix, test.defaultFormat)
+		if ok != test.expectedOK {
+			t.Errorf("parseResource(%s): expected ok %t but got %t", test.path, test.expectedOK, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if resource != test.expectedResource || format != test.expectedFormat {
+			t.Errorf("parseResource(%s): expected (%s, %s) but got (%s, %s)",
+				test.path, test.expectedResource, test.expectedFormat, resource, format)
+		}
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/pkg/errors"
 )
 
+// handler handles an RPC request and returns its response. A handler that responds
+// later, outside of the dispatch loop, returns a nil response and enqueues the
+// response to the router's outgoing route by itself.
 type handler func(context *rpccontext.Context, router *router.Router, request appmessage.Message) (appmessage.Message, error)
 
 var handlers = map[appmessage.MessageCommand]handler{
@@ -51,6 +54,33 @@ var handlers = map[appmessage.MessageCommand]handler{
 	appmessage.CmdNotifyNewBlockTemplateRequestMessage:                      rpchandlers.HandleNotifyNewBlockTemplate,
 	appmessage.CmdGetCoinSupplyRequestMessage:                               rpchandlers.HandleGetCoinSupply,
 	appmessage.CmdGetMempoolEntriesByAddressesRequestMessage:                rpchandlers.HandleGetMempoolEntriesByAddresses,
+	appmessage.CmdGetUTXOSetInfoRequestMessage:                              rpchandlers.HandleGetUTXOSetInfo,
+	appmessage.CmdNotifyDAGTipChangedRequestMessage:                         rpchandlers.HandleNotifyDAGTipChanged,
+	appmessage.CmdGetRawTransactionRequestMessage:                           rpchandlers.HandleGetRawTransaction,
+	appmessage.CmdGetNetworkInfoRequestMessage:                              rpchandlers.HandleGetNetworkInfo,
+	appmessage.CmdCompactDbRequestMessage:                                   rpchandlers.HandleCompactDb,
+	appmessage.CmdGetDbInfoRequestMessage:                                   rpchandlers.HandleGetDbInfo,
+	appmessage.CmdBackupDagStateRequestMessage:                              rpchandlers.HandleBackupDagState,
+	appmessage.CmdGetBlockDAGRelationsRequestMessage:                        rpchandlers.HandleGetBlockDAGRelations,
+	appmessage.CmdGetFinalityPointRequestMessage:                            rpchandlers.HandleGetFinalityPoint,
+	appmessage.CmdGetSyncStatusRequestMessage:                               rpchandlers.HandleGetSyncStatus,
+	appmessage.CmdGetMempoolInfoRequestMessage:                              rpchandlers.HandleGetMempoolInfo,
+	appmessage.CmdTestMempoolAcceptRequestMessage:                           rpchandlers.HandleTestMempoolAccept,
+	appmessage.CmdSubmitPackageRequestMessage:                               rpchandlers.HandleSubmitPackage,
+	appmessage.CmdGetTxOutProofRequestMessage:                               rpchandlers.HandleGetTxOutProof,
+	appmessage.CmdVerifyTxOutProofRequestMessage:                            rpchandlers.HandleVerifyTxOutProof,
+	appmessage.CmdAddNodeRequestMessage:                                     rpchandlers.HandleAddNode,
+	appmessage.CmdDisconnectNodeRequestMessage:                              rpchandlers.HandleDisconnectNode,
+	appmessage.CmdGetAddedNodeInfoRequestMessage:                            rpchandlers.HandleGetAddedNodeInfo,
+	appmessage.CmdInvalidateBlockRequestMessage:                             rpchandlers.HandleInvalidateBlock,
+	appmessage.CmdReconsiderBlockRequestMessage:                             rpchandlers.HandleReconsiderBlock,
+	appmessage.CmdGetBlockStatsRequestMessage:                               rpchandlers.HandleGetBlockStats,
+	appmessage.CmdGetProcessingStatsRequestMessage:                          rpchandlers.HandleGetProcessingStats,
+	appmessage.CmdDebugLevelRequestMessage:                                  rpchandlers.HandleDebugLevel,
+	appmessage.CmdStartProfileRequestMessage:                                rpchandlers.HandleStartProfile,
+	appmessage.CmdStopProfileRequestMessage:                                 rpchandlers.HandleStopProfile,
+	appmessage.CmdNotifyBlockHeaderAddedRequestMessage:                      rpchandlers.HandleNotifyBlockHeaderAdded,
+	appmessage.CmdGetNetTotalsRequestMessage:                                rpchandlers.HandleGetNetTotals,
 }
 
 func (m *Manager) routerInitializer(router *router.Router, netConnection *netadapter.NetConnection) {
@@ -87,6 +117,9 @@ func (m *Manager) handleIncomingMessages(router *router.Router, incomingRoute *r
 		if err != nil {
 			return err
 		}
+		if response == nil {
+			continue
+		}
 		err = outgoingRoute.Enqueue(response)
 		if err != nil {
 			return err
@@ -0,0 +1,157 @@
+package rpccontext
+
+import (
+	"sort"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrBuildBlockStatsMissingBody indicates that a block that was given to BuildBlockStats
+// is invalid or has no body.
+var ErrBuildBlockStatsMissingBody = errors.New("ErrBuildBlockStatsMissingBody")
+
+// feeratePercentiles are the percentiles of the feerates that are reported in the block stats
+var feeratePercentiles = []float64{10, 25, 50, 75, 90}
+
+// BuildBlockStats builds the aggregate statistics of the block with the given hash
+func (ctx *Context) BuildBlockStats(blockHash *externalapi.DomainHash) (*appmessage.RPCBlockStats, error) {
+	blockInfo, err := ctx.Domain.Consensus().GetBlockInfo(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if !blockInfo.HasBody() {
+		return nil, errors.Wrapf(ErrBuildBlockStatsMissingBody, "block %s", blockHash)
+	}
+
+	block, err := ctx.Domain.Consensus().GetBlockEvenIfHeaderOnly(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	isChainBlock, err := ctx.Domain.Consensus().IsChainBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	size, err := serializedBlockSize(block)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &appmessage.RPCBlockStats{
+		Hash:               blockHash.String(),
+		BlueScore:          blockInfo.BlueScore,
+		Timestamp:          block.Header.TimeInMilliseconds(),
+		ParentCount:        uint32(len(block.Header.DirectParents())),
+		MergeSetBluesCount: uint32(len(blockInfo.MergeSetBlues)),
+		MergeSetRedsCount:  uint32(len(blockInfo.MergeSetReds)),
+		IsChainBlock:       isChainBlock,
+		Size:               size,
+	}
+
+	// The selected parent of the genesis is the virtual genesis, which has no header
+	if blockInfo.SelectedParent != nil && !blockInfo.SelectedParent.Equal(model.VirtualGenesisBlockHash) {
+		selectedParentHeader, err := ctx.Domain.Consensus().GetBlockHeader(blockInfo.SelectedParent)
+		if err != nil {
+			return nil, err
+		}
+		stats.TimeSinceSelectedParent = stats.Timestamp - selectedParentHeader.TimeInMilliseconds()
+	}
+
+	for _, transaction := range block.Transactions {
+		ctx.Domain.Consensus().PopulateMass(transaction)
+		stats.Mass += transaction.Mass
+		if transactionhelper.IsCoinBase(transaction) {
+			continue
+		}
+		stats.TransactionCount++
+		stats.InputCount += uint32(len(transaction.Inputs))
+		stats.OutputCount += uint32(len(transaction.Outputs))
+	}
+
+	acceptingBlockHash, isAccepted, err := ctx.Domain.Consensus().GetAcceptingBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if !isAccepted {
+		return stats, nil
+	}
+	stats.IsAccepted = true
+	stats.AcceptingBlockHash = acceptingBlockHash.String()
+
+	acceptanceData, err := ctx.Domain.Consensus().GetBlockAcceptanceData(acceptingBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	var feerates []weightedFeerate
+	for _, blockAcceptanceData := range acceptanceData {
+		if !blockAcceptanceData.BlockHash.Equal(blockHash) {
+			continue
+		}
+		for _, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+			transaction := transactionAcceptanceData.Transaction
+			if !transactionAcceptanceData.IsAccepted || transactionhelper.IsCoinBase(transaction) {
+				continue
+			}
+			ctx.Domain.Consensus().PopulateMass(transaction)
+			stats.AcceptedTxCount++
+			stats.TotalFees += transactionAcceptanceData.Fee
+			if transaction.Mass > 0 {
+				feerates = append(feerates, weightedFeerate{
+					feerate: float64(transactionAcceptanceData.Fee) / float64(transaction.Mass),
+					mass:    transaction.Mass,
+				})
+			}
+		}
+	}
+	stats.FeeratePercentiles = calculateFeeratePercentiles(feerates)
+
+	return stats, nil
+}
+
+type weightedFeerate struct {
+	feerate float64
+	mass    uint64
+}
+
+// calculateFeeratePercentiles returns the feeratePercentiles of the given feerates, each
+// weighted by the mass of its transaction, so that a percentile is the feerate paid for
+// that percentage of the mass. It returns nil if there are no feerates.
+func calculateFeeratePercentiles(feerates []weightedFeerate) []float64 {
+	if len(feerates) == 0 {
+		return nil
+	}
+	sort.Slice(feerates, func(i, j int) bool {
+		return feerates[i].feerate < feerates[j].feerate
+	})
+
+	totalMass := uint64(0)
+	for _, feerate := range feerates {
+		totalMass += feerate.mass
+	}
+
+	percentiles := make([]float64, len(feeratePercentiles))
+	cumulativeMass := uint64(0)
+	feerateIndex := 0
+	for i, percentile := range feeratePercentiles {
+		threshold := float64(totalMass) * percentile / 100
+		for feerateIndex < len(feerates)-1 && float64(cumulativeMass+feerates[feerateIndex].mass) < threshold {
+			cumulativeMass += feerates[feerateIndex].mass
+			feerateIndex++
+		}
+		percentiles[i] = feerates[feerateIndex].feerate
+	}
+	return percentiles
+}
+
+func serializedBlockSize(block *externalapi.DomainBlock) (uint64, error) {
+	message, err := protowire.FromAppMessage(appmessage.DomainBlockToMsgBlock(block))
+	if err != nil {
+		return 0, err
+	}
+	return uint64(proto.Size(message.GetBlock())), nil
+}
@@ -0,0 +1,211 @@
+package rpccontext
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/hashes"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+	"github.com/pkg/errors"
+)
+
+// maxIssuedBlockTemplates is the number of issued templates that are remembered
+// in order to check the mutations made to them once they're submitted
+const maxIssuedBlockTemplates = 100
+
+// BlockTemplateState keeps track of the current block template generation, so that
+// getBlockTemplate calls can long poll until the template they were handed is stale.
+// It also remembers the templates handed out to callers that negotiated mutations, so
+// that submitted blocks are only allowed to differ from them in the negotiated ways.
+type BlockTemplateState struct {
+	lock            sync.Mutex
+	generation      uint64
+	newTemplateChan chan struct{}
+	isClosed        bool
+
+	issuedTemplates      map[externalapi.DomainHash][]*issuedBlockTemplate
+	issuedTemplatesOrder []externalapi.DomainHash
+}
+
+type issuedBlockTemplate struct {
+	block                   *externalapi.DomainBlock
+	isUnrestricted          bool
+	mutable                 map[string]struct{}
+	coinbaseAppendMaxLength uint64
+}
+
+// NewBlockTemplateState creates a new BlockTemplateState
+func NewBlockTemplateState() *BlockTemplateState {
+	return &BlockTemplateState{
+		newTemplateChan: make(chan struct{}),
+		issuedTemplates: make(map[externalapi.DomainHash][]*issuedBlockTemplate),
+	}
+}
+
+// NotifyNewBlockTemplate marks the current block template as stale and
+// releases all the calls that are long polling on it
+func (bts *BlockTemplateState) NotifyNewBlockTemplate() {
+	bts.lock.Lock()
+	defer bts.lock.Unlock()
+
+	if bts.isClosed {
+		return
+	}
+	bts.generation++
+	close(bts.newTemplateChan)
+	bts.newTemplateChan = make(chan struct{})
+}
+
+// Close releases all the calls that are long polling, and makes any further
+// long polling call return immediately. It's called when the node shuts down.
+func (bts *BlockTemplateState) Close() {
+	bts.lock.Lock()
+	defer bts.lock.Unlock()
+
+	if bts.isClosed {
+		return
+	}
+	bts.isClosed = true
+	close(bts.newTemplateChan)
+}
+
+// Current returns the current block template generation, along with a channel
+// that's closed once a newer template is available.
+// Current should be called before building the template, so that a template
+// that turns stale while it's being built is never reported as up to date.
+func (bts *BlockTemplateState) Current() (generation uint64, newTemplateChan <-chan struct{}) {
+	bts.lock.Lock()
+	defer bts.lock.Unlock()
+
+	return bts.generation, bts.newTemplateChan
+}
+
+// LongPollID returns the long poll ID of a template of the given generation
+// built on top of the given parents
+func LongPollID(parents []*externalapi.DomainHash, generation uint64) string {
+	writer := hashes.NewBlockHashWriter()
+	for _, parent := range parents {
+		writer.InfallibleWrite(parent.ByteSlice())
+	}
+	return fmt.Sprintf("%s-%d", writer.Finalize(), generation)
+}
+
+// RecordIssuedTemplate remembers a template that was handed out along with the mutations
+// its caller is allowed to make, so that ValidateMutations can check the block once it's submitted.
+// isUnrestricted marks templates handed out to legacy callers that didn't negotiate any
+// capabilities. These are remembered as well, since a template differs from the templates
+// built right before or after it only in the fields the mutations apply to.
+func (bts *BlockTemplateState) RecordIssuedTemplate(block *externalapi.DomainBlock, isUnrestricted bool,
+	mutable []string, coinbaseAppendMaxLength uint64) {
+
+	bts.lock.Lock()
+	defer bts.lock.Unlock()
+
+	mutableSet := make(map[string]struct{}, len(mutable))
+	for _, mutation := range mutable {
+		mutableSet[mutation] = struct{}{}
+	}
+
+	key := *issuedTemplateKey(block.Header)
+	bts.issuedTemplates[key] = append(bts.issuedTemplates[key], &issuedBlockTemplate{
+		block:                   block,
+		isUnrestricted:          isUnrestricted,
+		mutable:                 mutableSet,
+		coinbaseAppendMaxLength: coinbaseAppendMaxLength,
+	})
+	bts.issuedTemplatesOrder = append(bts.issuedTemplatesOrder, key)
+
+	for len(bts.issuedTemplatesOrder) > maxIssuedBlockTemplates {
+		oldestKey := bts.issuedTemplatesOrder[0]
+		bts.issuedTemplatesOrder = bts.issuedTemplatesOrder[1:]
+		bts.issuedTemplates[oldestKey] = bts.issuedTemplates[oldestKey][1:]
+		if len(bts.issuedTemplates[oldestKey]) == 0 {
+			delete(bts.issuedTemplates, oldestKey)
+		}
+	}
+}
+
+// ValidateMutations returns an error if the given block was built from an issued template
+// and was modified in a way that none of the matching templates allows. Blocks that don't
+// match any of the remembered templates aren't checked.
+func (bts *BlockTemplateState) ValidateMutations(block *externalapi.DomainBlock) error {
+	bts.lock.Lock()
+	defer bts.lock.Unlock()
+
+	candidates := bts.issuedTemplates[*issuedTemplateKey(block.Header)]
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var err error
+	for _, candidate := range candidates {
+		err = candidate.validateMutations(block)
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (ibt *issuedBlockTemplate) validateMutations(block *externalapi.DomainBlock) error {
+	if ibt.isUnrestricted {
+		return nil
+	}
+
+	templateTime := ibt.block.Header.TimeInMilliseconds()
+	blockTime := block.Header.TimeInMilliseconds()
+	if blockTime != templateTime {
+		if _, ok := ibt.mutable[appmessage.BlockTemplateMutationTime]; !ok {
+			return errors.Errorf("the block timestamp was modified without the %s mutation",
+				appmessage.BlockTemplateMutationTime)
+		}
+		if blockTime < templateTime {
+			return errors.Errorf("the %s mutation only allows increasing the block timestamp",
+				appmessage.BlockTemplateMutationTime)
+		}
+	}
+
+	if len(block.Transactions) != len(ibt.block.Transactions) {
+		return errors.Errorf("the block has %d transactions while its template has %d",
+			len(block.Transactions), len(ibt.block.Transactions))
+	}
+	for i := transactionhelper.CoinbaseTransactionIndex + 1; i < len(block.Transactions); i++ {
+		if !consensushashing.TransactionID(block.Transactions[i]).Equal(consensushashing.TransactionID(ibt.block.Transactions[i])) {
+			return errors.Errorf("transaction %d of the block differs from its template", i)
+		}
+	}
+
+	templateCoinbase := ibt.block.Transactions[transactionhelper.CoinbaseTransactionIndex]
+	blockCoinbase := block.Transactions[transactionhelper.CoinbaseTransactionIndex]
+	if bytes.Equal(blockCoinbase.Payload, templateCoinbase.Payload) {
+		return nil
+	}
+	if _, ok := ibt.mutable[appmessage.BlockTemplateMutationCoinbaseAppend]; !ok {
+		return errors.Errorf("the coinbase payload was modified without the %s mutation",
+			appmessage.BlockTemplateMutationCoinbaseAppend)
+	}
+	if !bytes.HasPrefix(blockCoinbase.Payload, templateCoinbase.Payload) {
+		return errors.Errorf("the %s mutation only allows appending data to the coinbase payload",
+			appmessage.BlockTemplateMutationCoinbaseAppend)
+	}
+	appendedLength := uint64(len(blockCoinbase.Payload) - len(templateCoinbase.Payload))
+	if appendedLength > ibt.coinbaseAppendMaxLength {
+		return errors.Errorf("%d bytes were appended to the coinbase payload while at most %d are allowed",
+			appendedLength, ibt.coinbaseAppendMaxLength)
+	}
+	return nil
+}
+
+// issuedTemplateKey identifies a template by all the header fields that stay the same under
+// the allowed mutations. The merkle root is excluded since appending to the coinbase changes it.
+func issuedTemplateKey(header externalapi.BlockHeader) *externalapi.DomainHash {
+	mutableHeader := header.ToMutable()
+	mutableHeader.SetTimeInMilliseconds(0)
+	mutableHeader.SetNonce(0)
+	mutableHeader.SetHashMerkleRoot(&externalapi.DomainHash{})
+	return consensushashing.HeaderHash(mutableHeader)
+}
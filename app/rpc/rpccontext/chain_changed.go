@@ -4,6 +4,7 @@ import (
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/hashes"
 )
 
 // ConvertVirtualSelectedParentChainChangesToChainChangedNotificationMessage converts
@@ -25,30 +26,92 @@ func (ctx *Context) ConvertVirtualSelectedParentChainChangesToChainChangedNotifi
 	var acceptedTransactionIDs []*appmessage.AcceptedTransactionIDs
 	if includeAcceptedTransactionIDs {
 		var err error
-		acceptedTransactionIDs, err = ctx.getAndConvertAcceptedTransactionIDs(selectedParentChainChanges)
+		acceptedTransactionIDs, err = ctx.getAndConvertAcceptedTransactionIDs(selectedParentChainChanges.Added)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	return appmessage.NewVirtualSelectedParentChainChangedNotificationMessage(
-		removedChainBlockHashes, addedChainBlocks, acceptedTransactionIDs), nil
+		removedChainBlockHashes, addedChainBlocks, acceptedTransactionIDs, nil), nil
 }
 
-func (ctx *Context) getAndConvertAcceptedTransactionIDs(selectedParentChainChanges *externalapi.SelectedChainPath) (
+// ConvertVirtualChangeSetToDAGTipChangedNotificationMessage converts a virtual change set
+// to a DAGTipChangedNotificationMessage, always including the accepted transaction IDs.
+// The tips are read from consensus, since the virtual parents are only a subset of them.
+func (ctx *Context) ConvertVirtualChangeSetToDAGTipChangedNotificationMessage(
+	virtualChangeSet *externalapi.VirtualChangeSet) (*appmessage.DAGTipChangedNotificationMessage, error) {
+
+	tips, err := ctx.Domain.Consensus().Tips()
+	if err != nil {
+		return nil, err
+	}
+	tipHashes := hashes.ToStrings(tips)
+
+	selectedParentChainChanges := virtualChangeSet.VirtualSelectedParentChainChanges
+	if selectedParentChainChanges == nil {
+		selectedParentChainChanges = &externalapi.SelectedChainPath{}
+	}
+	chainChangedNotification, err := ctx.ConvertVirtualSelectedParentChainChangesToChainChangedNotificationMessage(
+		selectedParentChainChanges, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return appmessage.NewDAGTipChangedNotificationMessage(tipHashes, chainChangedNotification.RemovedChainBlockHashes,
+		chainChangedNotification.AddedChainBlockHashes, chainChangedNotification.AcceptedTransactionIDs), nil
+}
+
+// GetUnacceptedTransactionIDs returns, for every chain block that was removed from the virtual
+// selected parent chain, the IDs of the transactions it accepted that aren't accepted again by
+// the added chain blocks. acceptedTransactionIDs are the transactions accepted by the added
+// chain blocks, as returned in VirtualSelectedParentChainChangedNotificationMessage
+func (ctx *Context) GetUnacceptedTransactionIDs(selectedParentChainChanges *externalapi.SelectedChainPath,
+	acceptedTransactionIDs []*appmessage.AcceptedTransactionIDs) ([]*appmessage.AcceptedTransactionIDs, error) {
+
+	if len(selectedParentChainChanges.Removed) == 0 {
+		return nil, nil
+	}
+
+	reacceptedTransactionIDs := make(map[string]struct{})
+	for _, chainBlockAcceptedTransactionIDs := range acceptedTransactionIDs {
+		for _, transactionID := range chainBlockAcceptedTransactionIDs.AcceptedTransactionIDs {
+			reacceptedTransactionIDs[transactionID] = struct{}{}
+		}
+	}
+
+	unacceptedTransactionIDs, err := ctx.getAndConvertAcceptedTransactionIDs(selectedParentChainChanges.Removed)
+	if err != nil {
+		return nil, err
+	}
+	for _, chainBlockUnacceptedTransactionIDs := range unacceptedTransactionIDs {
+		transactionIDs := chainBlockUnacceptedTransactionIDs.AcceptedTransactionIDs
+		chainBlockUnacceptedTransactionIDs.AcceptedTransactionIDs = nil
+		for _, transactionID := range transactionIDs {
+			if _, ok := reacceptedTransactionIDs[transactionID]; !ok {
+				chainBlockUnacceptedTransactionIDs.AcceptedTransactionIDs =
+					append(chainBlockUnacceptedTransactionIDs.AcceptedTransactionIDs, transactionID)
+			}
+		}
+	}
+
+	return unacceptedTransactionIDs, nil
+}
+
+func (ctx *Context) getAndConvertAcceptedTransactionIDs(chainBlocks []*externalapi.DomainHash) (
 	[]*appmessage.AcceptedTransactionIDs, error) {
 
-	acceptedTransactionIDs := make([]*appmessage.AcceptedTransactionIDs, len(selectedParentChainChanges.Added))
+	acceptedTransactionIDs := make([]*appmessage.AcceptedTransactionIDs, len(chainBlocks))
 
 	const chunk = 1000
 	position := 0
 
-	for position < len(selectedParentChainChanges.Added) {
+	for position < len(chainBlocks) {
 		var chainBlocksChunk []*externalapi.DomainHash
-		if position+chunk > len(selectedParentChainChanges.Added) {
-			chainBlocksChunk = selectedParentChainChanges.Added[position:]
+		if position+chunk > len(chainBlocks) {
+			chainBlocksChunk = chainBlocks[position:]
 		} else {
-			chainBlocksChunk = selectedParentChainChanges.Added[position : position+chunk]
+			chainBlocksChunk = chainBlocks[position : position+chunk]
 		}
 		// We use chunks in order to avoid blocking consensus for too long
 		chainBlocksAcceptanceData, err := ctx.Domain.Consensus().GetBlocksAcceptanceData(chainBlocksChunk)
@@ -56,10 +119,10 @@ func (ctx *Context) getAndConvertAcceptedTransactionIDs(selectedParentChainChang
 			return nil, err
 		}
 
-		for i, addedChainBlock := range chainBlocksChunk {
+		for i, chainBlock := range chainBlocksChunk {
 			chainBlockAcceptanceData := chainBlocksAcceptanceData[i]
 			acceptedTransactionIDs[position+i] = &appmessage.AcceptedTransactionIDs{
-				AcceptingBlockHash:     addedChainBlock.String(),
+				AcceptingBlockHash:     chainBlock.String(),
 				AcceptedTransactionIDs: nil,
 			}
 			for _, blockAcceptanceData := range chainBlockAcceptanceData {
@@ -1,13 +1,19 @@
 package rpccontext
 
 import (
+	"path/filepath"
+
 	"github.com/kaspanet/kaspad/app/protocol"
 	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/txindex"
 	"github.com/kaspanet/kaspad/domain/utxoindex"
 	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/compactor"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
 	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
 	"github.com/kaspanet/kaspad/infrastructure/network/connmanager"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
+	"github.com/kaspanet/kaspad/util/profiling"
 )
 
 // Context represents the RPC context
@@ -19,9 +25,18 @@ type Context struct {
 	ConnectionManager *connmanager.ConnectionManager
 	AddressManager    *addressmanager.AddressManager
 	UTXOIndex         *utxoindex.UTXOIndex
+	TXIndex           *txindex.TXIndex
+	Database          database.Database
+	DBCompactor       *compactor.Compactor
 	ShutDownChan      chan<- struct{}
 
+	// StartDatabaseBackup starts backing up the database to the given
+	// path in the background, and returns the path of the backup
+	StartDatabaseBackup func(backupPath string) (string, error)
+
 	NotificationManager *NotificationManager
+	BlockTemplateState  *BlockTemplateState
+	Profiler            *profiling.Profiler
 }
 
 // NewContext creates a new RPC context
@@ -32,6 +47,10 @@ func NewContext(cfg *config.Config,
 	connectionManager *connmanager.ConnectionManager,
 	addressManager *addressmanager.AddressManager,
 	utxoIndex *utxoindex.UTXOIndex,
+	txIndex *txindex.TXIndex,
+	db database.Database,
+	dbCompactor *compactor.Compactor,
+	startDatabaseBackup func(backupPath string) (string, error),
 	shutDownChan chan<- struct{}) *Context {
 
 	context := &Context{
@@ -42,9 +61,16 @@ func NewContext(cfg *config.Config,
 		ConnectionManager: connectionManager,
 		AddressManager:    addressManager,
 		UTXOIndex:         utxoIndex,
+		TXIndex:           txIndex,
+		Database:          db,
+		DBCompactor:       dbCompactor,
 		ShutDownChan:      shutDownChan,
+
+		StartDatabaseBackup: startDatabaseBackup,
 	}
 	context.NotificationManager = NewNotificationManager(cfg.ActiveNetParams)
+	context.BlockTemplateState = NewBlockTemplateState()
+	context.Profiler = profiling.NewProfiler(filepath.Join(cfg.AppDir, "profiles"))
 
 	return context
 }
@@ -41,6 +41,8 @@ type NotificationListener struct {
 	propagateVirtualDaaScoreChangedNotifications                bool
 	propagatePruningPointUTXOSetOverrideNotifications           bool
 	propagateNewBlockTemplateNotifications                      bool
+	propagateDAGTipChangedNotifications                         bool
+	propagateBlockHeaderAddedNotifications                      bool
 
 	propagateUTXOsChangedNotificationAddresses                                    map[utxoindex.ScriptPublicKeyString]*UTXOsChangedNotificationAddress
 	includeAcceptedTransactionIDsInVirtualSelectedParentChainChangedNotifications bool
@@ -167,6 +169,64 @@ func (nm *NotificationManager) HasListenersThatPropagateVirtualSelectedParentCha
 	return hasListeners, hasListenersThatRequireAcceptedTransactionIDs
 }
 
+// HasDAGTipChangedListeners indicates if the notification manager has any listeners for `DAGTipChanged` events
+func (nm *NotificationManager) HasDAGTipChangedListeners() bool {
+	nm.RLock()
+	defer nm.RUnlock()
+
+	for _, listener := range nm.listeners {
+		if listener.propagateDAGTipChangedNotifications {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifyDAGTipChanged notifies the notification manager that the DAG's tips have changed
+func (nm *NotificationManager) NotifyDAGTipChanged(notification *appmessage.DAGTipChangedNotificationMessage) error {
+	nm.RLock()
+	defer nm.RUnlock()
+
+	for router, listener := range nm.listeners {
+		if listener.propagateDAGTipChangedNotifications {
+			err := router.OutgoingRoute().MaybeEnqueue(notification)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// HasBlockHeaderAddedListeners indicates if the notification manager has any listeners for `BlockHeaderAdded` events
+func (nm *NotificationManager) HasBlockHeaderAddedListeners() bool {
+	nm.RLock()
+	defer nm.RUnlock()
+
+	for _, listener := range nm.listeners {
+		if listener.propagateBlockHeaderAddedNotifications {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifyBlockHeaderAdded notifies the notification manager that a block header has been added to the DAG
+func (nm *NotificationManager) NotifyBlockHeaderAdded(notification *appmessage.BlockHeaderAddedNotificationMessage) error {
+	nm.RLock()
+	defer nm.RUnlock()
+
+	for router, listener := range nm.listeners {
+		if listener.propagateBlockHeaderAddedNotifications {
+			err := router.OutgoingRoute().MaybeEnqueue(notification)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // NotifyFinalityConflict notifies the notification manager that there's a finality conflict in the DAG
 func (nm *NotificationManager) NotifyFinalityConflict(notification *appmessage.FinalityConflictNotificationMessage) error {
 	nm.RLock()
@@ -313,6 +373,8 @@ func newNotificationListener(params *dagconfig.Params) *NotificationListener {
 		propagateVirtualSelectedParentBlueScoreChangedNotifications: false,
 		propagateNewBlockTemplateNotifications:                      false,
 		propagatePruningPointUTXOSetOverrideNotifications:           false,
+		propagateDAGTipChangedNotifications:                         false,
+		propagateBlockHeaderAddedNotifications:                      false,
 	}
 }
 
@@ -335,6 +397,18 @@ func (nl *NotificationListener) PropagateVirtualSelectedParentChainChangedNotifi
 	nl.includeAcceptedTransactionIDsInVirtualSelectedParentChainChangedNotifications = includeAcceptedTransactionIDs
 }
 
+// PropagateDAGTipChangedNotifications instructs the listener to send DAG tip changed notifications
+// to the remote listener
+func (nl *NotificationListener) PropagateDAGTipChangedNotifications() {
+	nl.propagateDAGTipChangedNotifications = true
+}
+
+// PropagateBlockHeaderAddedNotifications instructs the listener to send block header added notifications
+// to the remote listener
+func (nl *NotificationListener) PropagateBlockHeaderAddedNotifications() {
+	nl.propagateBlockHeaderAddedNotifications = true
+}
+
 // PropagateFinalityConflictNotifications instructs the listener to send finality conflict notifications
 // to the remote listener
 func (nl *NotificationListener) PropagateFinalityConflictNotifications() {
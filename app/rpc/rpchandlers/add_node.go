@@ -0,0 +1,50 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util/network"
+)
+
+// HandleAddNode handles the respectively named RPC command
+func HandleAddNode(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if context.Config.SafeRPC {
+		log.Warn("AddNode RPC command called while node in safe RPC mode -- ignoring.")
+		response := appmessage.NewAddNodeResponseMessage()
+		response.Error =
+			appmessage.RPCErrorf("AddNode RPC command called while node in safe RPC mode")
+		return response, nil
+	}
+
+	addNodeRequest := request.(*appmessage.AddNodeRequestMessage)
+	address, err := network.NormalizeAddress(addNodeRequest.Address, context.Config.ActiveNetParams.DefaultPort)
+	if err != nil {
+		errorMessage := &appmessage.AddNodeResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Could not parse address: %s", err)
+		return errorMessage, nil
+	}
+
+	switch addNodeRequest.NodeCommand {
+	case appmessage.AddNodeCommandAdd:
+		err = context.ConnectionManager.AddNode(address)
+	case appmessage.AddNodeCommandRemove:
+		err = context.ConnectionManager.RemoveNode(address)
+	case appmessage.AddNodeCommandOneTry:
+		context.ConnectionManager.AddConnectionRequest(address, false)
+	default:
+		errorMessage := &appmessage.AddNodeResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Unknown command %q. Expected %q, %q or %q",
+			addNodeRequest.NodeCommand, appmessage.AddNodeCommandAdd, appmessage.AddNodeCommandRemove,
+			appmessage.AddNodeCommandOneTry)
+		return errorMessage, nil
+	}
+	if err != nil {
+		errorMessage := &appmessage.AddNodeResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Could not %s node: %s", addNodeRequest.NodeCommand, err)
+		return errorMessage, nil
+	}
+
+	response := appmessage.NewAddNodeResponseMessage()
+	return response, nil
+}
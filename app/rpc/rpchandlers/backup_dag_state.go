@@ -0,0 +1,28 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleBackupDagState handles the respectively named RPC command
+func HandleBackupDagState(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if context.Config.SafeRPC {
+		log.Warn("BackupDagState RPC command called while node in safe RPC mode -- ignoring.")
+		response := &appmessage.BackupDagStateResponseMessage{}
+		response.Error =
+			appmessage.RPCErrorf("BackupDagState RPC command called while node in safe RPC mode")
+		return response, nil
+	}
+
+	backupDagStateRequest := request.(*appmessage.BackupDagStateRequestMessage)
+	backupPath, err := context.StartDatabaseBackup(backupDagStateRequest.Path)
+	if err != nil {
+		response := &appmessage.BackupDagStateResponseMessage{}
+		response.Error = appmessage.RPCErrorf("Could not back up the DAG state: %s", err)
+		return response, nil
+	}
+
+	return appmessage.NewBackupDagStateResponseMessage(backupPath), nil
+}
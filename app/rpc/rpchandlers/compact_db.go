@@ -0,0 +1,27 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleCompactDb handles the respectively named RPC command
+func HandleCompactDb(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	if context.Config.SafeRPC {
+		log.Warn("CompactDb RPC command called while node in safe RPC mode -- ignoring.")
+		response := appmessage.NewCompactDbResponseMessage()
+		response.Error =
+			appmessage.RPCErrorf("CompactDb RPC command called while node in safe RPC mode")
+		return response, nil
+	}
+
+	if !context.DBCompactor.Compact() {
+		response := appmessage.NewCompactDbResponseMessage()
+		response.Error = appmessage.RPCErrorf("The database is already being compacted")
+		return response, nil
+	}
+
+	log.Infof("CompactDb RPC called, compacting the database in the background")
+	return appmessage.NewCompactDbResponseMessage(), nil
+}
@@ -0,0 +1,45 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleDebugLevel handles the respectively named RPC command
+func HandleDebugLevel(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	debugLevelRequest := request.(*appmessage.DebugLevelRequestMessage)
+
+	if debugLevelRequest.LevelSpec != "" && debugLevelRequest.LevelSpec != "show" {
+		if context.Config.SafeRPC {
+			log.Warn("DebugLevel RPC command called to change the log levels while node in safe RPC mode -- ignoring.")
+			errorMessage := &appmessage.DebugLevelResponseMessage{}
+			errorMessage.Error =
+				appmessage.RPCErrorf("DebugLevel RPC command can't change the log levels while node in safe RPC mode")
+			return errorMessage, nil
+		}
+
+		err := logger.ParseAndSetLogLevels(debugLevelRequest.LevelSpec)
+		if err != nil {
+			errorMessage := &appmessage.DebugLevelResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("Could not set the log levels: %s", err)
+			return errorMessage, nil
+		}
+		log.Infof("Set the log levels to %s", debugLevelRequest.LevelSpec)
+	}
+
+	subsystemIDs := logger.SupportedSubsystems()
+	subsystems := make([]*appmessage.SubsystemLogLevel, 0, len(subsystemIDs))
+	for _, subsystemID := range subsystemIDs {
+		level, ok := logger.SubsystemLevel(subsystemID)
+		if !ok {
+			continue
+		}
+		subsystems = append(subsystems, &appmessage.SubsystemLogLevel{
+			Subsystem: subsystemID,
+			Level:     level.String(),
+		})
+	}
+	return appmessage.NewDebugLevelResponseMessage(subsystems), nil
+}
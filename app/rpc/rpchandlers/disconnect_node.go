@@ -0,0 +1,37 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util/network"
+)
+
+// HandleDisconnectNode handles the respectively named RPC command
+func HandleDisconnectNode(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if context.Config.SafeRPC {
+		log.Warn("DisconnectNode RPC command called while node in safe RPC mode -- ignoring.")
+		response := appmessage.NewDisconnectNodeResponseMessage()
+		response.Error =
+			appmessage.RPCErrorf("DisconnectNode RPC command called while node in safe RPC mode")
+		return response, nil
+	}
+
+	disconnectNodeRequest := request.(*appmessage.DisconnectNodeRequestMessage)
+	address, err := network.NormalizeAddress(disconnectNodeRequest.Address, context.Config.ActiveNetParams.DefaultPort)
+	if err != nil {
+		errorMessage := &appmessage.DisconnectNodeResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Could not parse address: %s", err)
+		return errorMessage, nil
+	}
+
+	err = context.ConnectionManager.Disconnect(address)
+	if err != nil {
+		errorMessage := &appmessage.DisconnectNodeResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Could not disconnect node: %s", err)
+		return errorMessage, nil
+	}
+
+	response := appmessage.NewDisconnectNodeResponseMessage()
+	return response, nil
+}
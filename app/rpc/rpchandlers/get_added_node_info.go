@@ -0,0 +1,21 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleGetAddedNodeInfo handles the respectively named RPC command
+func HandleGetAddedNodeInfo(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	addedNodeInfos := context.ConnectionManager.AddedNodeInfos()
+	infos := make([]*appmessage.AddedNodeInfo, len(addedNodeInfos))
+	for i, addedNodeInfo := range addedNodeInfos {
+		infos[i] = &appmessage.AddedNodeInfo{
+			Address:     addedNodeInfo.Address,
+			IsConnected: addedNodeInfo.IsConnected,
+		}
+	}
+	response := appmessage.NewGetAddedNodeInfoResponseMessage(infos)
+	return response, nil
+}
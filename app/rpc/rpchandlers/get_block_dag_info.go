@@ -36,6 +36,13 @@ func HandleGetBlockDAGInfo(context *rpccontext.Context, _ *router.Router, _ appm
 	response.Difficulty = context.GetDifficultyRatio(virtualInfo.Bits, context.Config.ActiveNetParams)
 	response.PastMedianTime = virtualInfo.PastMedianTime
 	response.VirtualDAAScore = virtualInfo.DAAScore
+	response.VirtualBlueScore = virtualInfo.BlueScore
+
+	utxoCommitment, err := consensus.GetVirtualUTXOCommitment()
+	if err != nil {
+		return nil, err
+	}
+	response.UTXOCommitment = utxoCommitment.String()
 
 	pruningPoint, err := context.Domain.Consensus().PruningPoint()
 	if err != nil {
@@ -43,5 +50,12 @@ func HandleGetBlockDAGInfo(context *rpccontext.Context, _ *router.Router, _ appm
 	}
 	response.PruningPointHash = pruningPoint.String()
 
+	// The progress is the one of getSyncStatus, so that both agree on whether the node is synced
+	syncStatus, err := context.ProtocolManager.Context().SyncStatus()
+	if err != nil {
+		return nil, err
+	}
+	response.SyncProgress = syncStatus.BlocksProgress
+
 	return response, nil
 }
@@ -0,0 +1,95 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/hashes"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleGetBlockDAGRelations handles the respectively named RPC command
+func HandleGetBlockDAGRelations(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	getBlockDAGRelationsRequest := request.(*appmessage.GetBlockDAGRelationsRequestMessage)
+	consensus := context.Domain.Consensus()
+
+	errorMessage := &appmessage.GetBlockDAGRelationsResponseMessage{}
+
+	hash, err := externalapi.NewDomainHashFromString(getBlockDAGRelationsRequest.Hash)
+	if err != nil {
+		errorMessage.Error = appmessage.RPCErrorf("Hash could not be parsed: %s", err)
+		return errorMessage, nil
+	}
+	blockInfo, err := consensus.GetBlockInfo(hash)
+	if err != nil {
+		return nil, err
+	}
+	if !blockInfo.HasHeader() {
+		errorMessage.Error = appmessage.RPCErrorf("Block %s not found", hash)
+		return errorMessage, nil
+	}
+
+	// If lowHash is empty - use the pruning point instead
+	var lowHash *externalapi.DomainHash
+	if getBlockDAGRelationsRequest.LowHash != "" {
+		lowHash, err = externalapi.NewDomainHashFromString(getBlockDAGRelationsRequest.LowHash)
+		if err != nil {
+			errorMessage.Error = appmessage.RPCErrorf("Could not decode lowHash %s: %s", getBlockDAGRelationsRequest.LowHash, err)
+			return errorMessage, nil
+		}
+		lowBlockInfo, err := consensus.GetBlockInfo(lowHash)
+		if err != nil {
+			return nil, err
+		}
+		if !lowBlockInfo.HasHeader() {
+			errorMessage.Error = appmessage.RPCErrorf("Could not find lowHash %s", lowHash)
+			return errorMessage, nil
+		}
+	} else {
+		lowHash, err = consensus.PruningPoint()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !lowHash.Equal(hash) {
+		isInSelectedParentChain, err := consensus.IsInSelectedParentChainOf(lowHash, hash)
+		if err != nil {
+			return nil, err
+		}
+		if !isInSelectedParentChain {
+			errorMessage.Error = appmessage.RPCErrorf("lowHash %s is not in the selected parent chain of %s", lowHash, hash)
+			return errorMessage, nil
+		}
+	}
+
+	// The anticone is found by traversing the future of the block, so it's only
+	// served for blocks that can still be merged. The anticone of deeper blocks
+	// is final anyway.
+	header, err := consensus.GetBlockHeader(hash)
+	if err != nil {
+		return nil, err
+	}
+	virtualInfo, err := consensus.GetVirtualInfo()
+	if err != nil {
+		return nil, err
+	}
+	mergeDepth := context.Config.ActiveNetParams.MergeDepth
+	if header.BlueScore()+mergeDepth < virtualInfo.BlueScore {
+		errorMessage.Error = appmessage.RPCErrorf("Block %s is deeper than the merge depth (%d), "+
+			"so its anticone is not served", hash, mergeDepth)
+		return errorMessage, nil
+	}
+	anticone, err := consensus.Anticone(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	maxBlocks := context.Config.NetParams().MergeSetSizeLimit + 1
+	blueHashes, blueHashesHighHash, err := consensus.BluesBetween(lowHash, hash, maxBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	return appmessage.NewGetBlockDAGRelationsResponseMessage(hashes.ToStrings(anticone), hashes.ToStrings(blueHashes),
+		blueHashesHighHash.String()), nil
+}
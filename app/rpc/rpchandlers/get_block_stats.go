@@ -0,0 +1,78 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// HandleGetBlockStats handles the respectively named RPC command
+func HandleGetBlockStats(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	getBlockStatsRequest := request.(*appmessage.GetBlockStatsRequestMessage)
+
+	hash, err := externalapi.NewDomainHashFromString(getBlockStatsRequest.Hash)
+	if err != nil {
+		errorMessage := &appmessage.GetBlockStatsResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Hash could not be parsed: %s", err)
+		return errorMessage, nil
+	}
+	blockInfo, err := context.Domain.Consensus().GetBlockInfo(hash)
+	if err != nil {
+		return nil, err
+	}
+	if !blockInfo.HasHeader() {
+		errorMessage := &appmessage.GetBlockStatsResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Block %s not found", hash)
+		return errorMessage, nil
+	}
+
+	blockHashes := []*externalapi.DomainHash{hash}
+	if getBlockStatsRequest.HighHash != "" {
+		highHash, err := externalapi.NewDomainHashFromString(getBlockStatsRequest.HighHash)
+		if err != nil {
+			errorMessage := &appmessage.GetBlockStatsResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("High hash could not be parsed: %s", err)
+			return errorMessage, nil
+		}
+		highBlockInfo, err := context.Domain.Consensus().GetBlockInfo(highHash)
+		if err != nil {
+			return nil, err
+		}
+		if !highBlockInfo.HasHeader() {
+			errorMessage := &appmessage.GetBlockStatsResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("Block %s not found", highHash)
+			return errorMessage, nil
+		}
+		if blockInfo.BlueScore > highBlockInfo.BlueScore {
+			errorMessage := &appmessage.GetBlockStatsResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("The blue score of block %s is higher than that of "+
+				"the high hash %s", hash, highHash)
+			return errorMessage, nil
+		}
+
+		// maxBlocks MUST be >= MergeSetSizeLimit + 1
+		maxBlocks := context.Config.NetParams().MergeSetSizeLimit + 1
+		hashesBetween, _, err := context.Domain.Consensus().GetHashesBetween(hash, highHash, maxBlocks)
+		if err != nil {
+			return nil, err
+		}
+		blockHashes = append(blockHashes, hashesBetween...)
+	}
+
+	blockStats := make([]*appmessage.RPCBlockStats, len(blockHashes))
+	for i, blockHash := range blockHashes {
+		blockStats[i], err = context.BuildBlockStats(blockHash)
+		if err != nil {
+			if errors.Is(err, rpccontext.ErrBuildBlockStatsMissingBody) {
+				errorMessage := &appmessage.GetBlockStatsResponseMessage{}
+				errorMessage.Error = appmessage.RPCErrorf("Block %s is invalid or has no body", blockHash)
+				return errorMessage, nil
+			}
+			return nil, err
+		}
+	}
+
+	return appmessage.NewGetBlockStatsResponseMessage(blockStats), nil
+}
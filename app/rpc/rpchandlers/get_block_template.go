@@ -1,19 +1,42 @@
 package rpchandlers
 
 import (
+	"time"
+
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionid"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
-	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	miningmanagermodel "github.com/kaspanet/kaspad/domain/miningmanager/model"
+	routerpkg "github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
 	"github.com/kaspanet/kaspad/util"
 	"github.com/kaspanet/kaspad/version"
+	"github.com/pkg/errors"
 )
 
+// longPollTimeout is the maximum duration a long polling getBlockTemplate call waits
+// for a new template. It's kept below the default RPC client timeout.
+const longPollTimeout = 20 * time.Second
+
+// supportedBlockTemplateCapabilities are the getBlockTemplate capabilities this node supports
+var supportedBlockTemplateCapabilities = []string{
+	appmessage.BlockTemplateCapabilityLongPoll,
+	appmessage.BlockTemplateMutationTime,
+	appmessage.BlockTemplateMutationCoinbaseAppend,
+}
+
+// supportedBlockTemplateMutations are the modifications miners may make to a template before submitting it
+var supportedBlockTemplateMutations = []string{
+	appmessage.BlockTemplateMutationTime,
+	appmessage.BlockTemplateMutationCoinbaseAppend,
+}
+
 // HandleGetBlockTemplate handles the respectively named RPC command
-func HandleGetBlockTemplate(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+func HandleGetBlockTemplate(context *rpccontext.Context, router *routerpkg.Router, request appmessage.Message) (appmessage.Message, error) {
 	getBlockTemplateRequest := request.(*appmessage.GetBlockTemplateRequestMessage)
+	outgoingRoute := router.OutgoingRoute()
 
 	payAddress, err := util.DecodeAddress(getBlockTemplateRequest.PayAddress, context.Config.ActiveNetParams.Prefix)
 	if err != nil {
@@ -29,18 +52,160 @@ func HandleGetBlockTemplate(context *rpccontext.Context, _ *router.Router, reque
 
 	coinbaseData := &externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey, ExtraData: []byte(version.Version() + "/" + getBlockTemplateRequest.ExtraData)}
 
-	templateBlock, isNearlySynced, err := context.Domain.MiningManager().GetBlockTemplate(coinbaseData)
+	options := &miningmanagermodel.BlockTemplateOptions{MaxMass: getBlockTemplateRequest.MaxMass}
+	options.ExcludedTransactionIDs, err = parseTransactionIDs(getBlockTemplateRequest.ExcludedTransactionIDs)
 	if err != nil {
-		return nil, err
+		errorMessage := &appmessage.GetBlockTemplateResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Excluded transaction ID could not be parsed: %s", err)
+		return errorMessage, nil
+	}
+	options.IncludedTransactionIDs, err = parseTransactionIDs(getBlockTemplateRequest.IncludedTransactionIDs)
+	if err != nil {
+		errorMessage := &appmessage.GetBlockTemplateResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Included transaction ID could not be parsed: %s", err)
+		return errorMessage, nil
+	}
+
+	templateBlock, longPollID, newTemplateChan, err := getBlockTemplate(context, coinbaseData, options)
+	if err != nil {
+		return blockTemplateErrorResponse(err)
+	}
+
+	// The caller already has the current template -- wait for a new one instead of handing it out again.
+	// The wait happens outside of the RPC dispatch loop, so that the caller may keep using the connection,
+	// and most importantly submit a block, while the long poll is pending.
+	// newTemplateChan is also closed when the node shuts down, so the wait never outlives the RPC server.
+	if getBlockTemplateRequest.LongPollID != "" && getBlockTemplateRequest.LongPollID == longPollID {
+		spawn("HandleGetBlockTemplate-longPoll", func() {
+			timer := time.NewTimer(longPollTimeout)
+			select {
+			case <-newTemplateChan:
+			case <-timer.C:
+			}
+			timer.Stop()
+
+			response, err := newBlockTemplateResponse(context, getBlockTemplateRequest, coinbaseData, options)
+			if err != nil {
+				errorMessage := &appmessage.GetBlockTemplateResponseMessage{}
+				errorMessage.Error = appmessage.RPCErrorf("Could not build a block template: %s", err)
+				response = errorMessage
+			}
+			err = outgoingRoute.Enqueue(response)
+			if err != nil && !errors.Is(err, routerpkg.ErrRouteClosed) {
+				log.Warnf("Could not respond to a long polling getBlockTemplate call: %s", err)
+			}
+		})
+		return nil, nil
+	}
+
+	return blockTemplateResponse(context, getBlockTemplateRequest, templateBlock, longPollID)
+}
+
+// newBlockTemplateResponse builds a new block template that follows options and returns the
+// response to getBlockTemplateRequest that hands it out
+func newBlockTemplateResponse(context *rpccontext.Context,
+	getBlockTemplateRequest *appmessage.GetBlockTemplateRequestMessage, coinbaseData *externalapi.DomainCoinbaseData,
+	options *miningmanagermodel.BlockTemplateOptions) (appmessage.Message, error) {
+
+	templateBlock, longPollID, _, err := getBlockTemplate(context, coinbaseData, options)
+	if err != nil {
+		return blockTemplateErrorResponse(err)
 	}
+	return blockTemplateResponse(context, getBlockTemplateRequest, templateBlock, longPollID)
+}
+
+// blockTemplateErrorResponse returns the response to a getBlockTemplate call whose
+// template couldn't be built because of err
+func blockTemplateErrorResponse(err error) (appmessage.Message, error) {
+	if errors.Is(err, miningmanagermodel.ErrInvalidBlockTemplateOptions) {
+		errorMessage := &appmessage.GetBlockTemplateResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Could not build a block template: %s", err)
+		return errorMessage, nil
+	}
+	return nil, err
+}
+
+// blockTemplateResponse returns the response to getBlockTemplateRequest that hands out templateBlock,
+// and records the template as issued
+func blockTemplateResponse(context *rpccontext.Context, getBlockTemplateRequest *appmessage.GetBlockTemplateRequestMessage,
+	templateBlock *externalapi.DomainBlock, longPollID string) (appmessage.Message, error) {
 
-	if uint64(len(templateBlock.Transactions[transactionhelper.CoinbaseTransactionIndex].Payload)) > context.Config.NetParams().MaxCoinbasePayloadLength {
+	maxCoinbasePayloadLength := context.Config.NetParams().MaxCoinbasePayloadLength
+	coinbasePayloadLength := uint64(len(templateBlock.Transactions[transactionhelper.CoinbaseTransactionIndex].Payload))
+	if coinbasePayloadLength > maxCoinbasePayloadLength {
 		errorMessage := &appmessage.GetBlockTemplateResponseMessage{}
-		errorMessage.Error = appmessage.RPCErrorf("Coinbase payload is above max length (%d). Try to shorten the extra data.", context.Config.NetParams().MaxCoinbasePayloadLength)
+		errorMessage.Error = appmessage.RPCErrorf("Coinbase payload is above max length (%d). Try to shorten the extra data.", maxCoinbasePayloadLength)
 		return errorMessage, nil
 	}
 
+	isCurrent, err := context.ProtocolManager.Context().IsCurrent()
+	if err != nil {
+		return nil, err
+	}
+
 	rpcBlock := appmessage.DomainBlockToRPCBlock(templateBlock)
 
-	return appmessage.NewGetBlockTemplateResponseMessage(rpcBlock, context.ProtocolManager.Context().HasPeers() && isNearlySynced), nil
+	response := appmessage.NewGetBlockTemplateResponseMessage(rpcBlock, context.ProtocolManager.Context().HasPeers() && isCurrent)
+	response.LongPollID = longPollID
+	response.Capabilities = intersectCapabilities(getBlockTemplateRequest.Capabilities, supportedBlockTemplateCapabilities)
+	response.Mutable = intersectCapabilities(getBlockTemplateRequest.Capabilities, supportedBlockTemplateMutations)
+	for _, mutation := range response.Mutable {
+		if mutation == appmessage.BlockTemplateMutationCoinbaseAppend {
+			response.CoinbaseAppendMaxLength = maxCoinbasePayloadLength - coinbasePayloadLength
+		}
+	}
+
+	// Callers that negotiate capabilities are held to the mutations they were granted once they
+	// submit the block. Legacy callers that don't negotiate anything aren't restricted.
+	isUnrestricted := len(getBlockTemplateRequest.Capabilities) == 0
+	context.BlockTemplateState.RecordIssuedTemplate(templateBlock, isUnrestricted, response.Mutable,
+		response.CoinbaseAppendMaxLength)
+	return response, nil
+}
+
+// getBlockTemplate returns a block template that follows options along with its long
+// poll ID and a channel that's closed once the template is stale
+func getBlockTemplate(context *rpccontext.Context, coinbaseData *externalapi.DomainCoinbaseData,
+	options *miningmanagermodel.BlockTemplateOptions) (
+	templateBlock *externalapi.DomainBlock, longPollID string, newTemplateChan <-chan struct{}, err error) {
+
+	// The generation is taken before the template is built, so that a template
+	// that becomes stale while it's being built gets a stale long poll ID
+	generation, newTemplateChan := context.BlockTemplateState.Current()
+	templateBlock, _, err = context.Domain.MiningManager().GetBlockTemplateWithOptions(coinbaseData, options)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	longPollID = rpccontext.LongPollID(templateBlock.Header.DirectParents(), generation)
+	return templateBlock, longPollID, newTemplateChan, nil
+}
+
+// parseTransactionIDs parses the given transaction IDs, or returns nil if there are none
+func parseTransactionIDs(transactionIDStrings []string) ([]*externalapi.DomainTransactionID, error) {
+	if len(transactionIDStrings) == 0 {
+		return nil, nil
+	}
+	transactionIDs := make([]*externalapi.DomainTransactionID, len(transactionIDStrings))
+	for i, transactionIDString := range transactionIDStrings {
+		transactionID, err := transactionid.FromString(transactionIDString)
+		if err != nil {
+			return nil, err
+		}
+		transactionIDs[i] = transactionID
+	}
+	return transactionIDs, nil
+}
+
+// intersectCapabilities returns the requested capabilities that are also supported, in the order they were requested
+func intersectCapabilities(requested []string, supported []string) []string {
+	intersection := make([]string, 0, len(requested))
+	for _, capability := range requested {
+		for _, supportedCapability := range supported {
+			if capability == supportedCapability {
+				intersection = append(intersection, capability)
+				break
+			}
+		}
+	}
+	return intersection
 }
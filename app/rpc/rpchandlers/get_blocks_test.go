@@ -17,6 +17,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
 	"github.com/kaspanet/kaspad/domain/miningmanager"
 	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/intentlog"
 )
 
 type fakeDomain struct {
@@ -43,6 +44,22 @@ func (d fakeDomain) CommitStagingConsensus() error {
 	panic("implement me")
 }
 
+func (d fakeDomain) Reindex() error {
+	panic("implement me")
+}
+
+func (d fakeDomain) ReindexState() error {
+	panic("implement me")
+}
+
+func (d fakeDomain) EnableBlockAddedIntents() (*intentlog.IntentLog, error) {
+	panic("implement me")
+}
+
+func (d fakeDomain) RegisterBlockValidator(validator externalapi.BlockValidator) {
+	panic("implement me")
+}
+
 func (d fakeDomain) Consensus() externalapi.Consensus           { return d }
 func (d fakeDomain) MiningManager() miningmanager.MiningManager { return nil }
 
@@ -1,8 +1,12 @@
 package rpchandlers
 
 import (
+	"sort"
+	"time"
+
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
 )
 
@@ -12,6 +16,8 @@ func HandleGetConnectedPeerInfo(context *rpccontext.Context, _ *router.Router, _
 	ibdPeer := context.ProtocolManager.IBDPeer()
 	infos := make([]*appmessage.GetConnectedPeerInfoMessage, 0, len(peers))
 	for _, peer := range peers {
+		traffic := peer.Connection().Traffic()
+		messageCounts := peer.Connection().MessageCounts()
 		info := &appmessage.GetConnectedPeerInfoMessage{
 			ID:                        peer.ID().String(),
 			Address:                   peer.Address(),
@@ -22,9 +28,53 @@ func HandleGetConnectedPeerInfo(context *rpccontext.Context, _ *router.Router, _
 			AdvertisedProtocolVersion: peer.AdvertisedProtocolVersion(),
 			TimeConnected:             peer.TimeConnected().Milliseconds(),
 			IsIBDPeer:                 peer == ibdPeer,
+			BytesSent:                 traffic.BytesSent,
+			BytesReceived:             traffic.BytesReceived,
+			BlockBytesServed:          traffic.BlockBytesServed,
+			TransactionBytesServed:    traffic.TransactionBytesServed,
+			Permissions:               peer.Connection().Permissions().Names(),
+			IsEncrypted:               peer.Connection().IsEncrypted(),
+			MinPingDuration:           peer.MinPingDuration().Milliseconds(),
+			AveragePingDuration:       peer.AveragePingDuration().Milliseconds(),
+
+			LastBlockAnnouncementTime:       timestampMilliseconds(peer.LastBlockAnnouncementTime()),
+			LastTransactionAnnouncementTime: timestampMilliseconds(peer.LastTransactionAnnouncementTime()),
+
+			SentMessages:     peerMessageCounts(messageCounts.Sent),
+			ReceivedMessages: peerMessageCounts(messageCounts.Received),
 		}
 		infos = append(infos, info)
 	}
 	response := appmessage.NewGetConnectedPeerInfoResponseMessage(infos)
 	return response, nil
 }
+
+// peerMessageCounts converts the given message counts of a peer to their RPC
+// form, sorted by command
+func peerMessageCounts(counts map[appmessage.MessageCommand]netadapter.MessageCount) []*appmessage.PeerMessageCount {
+	peerCounts := make([]*appmessage.PeerMessageCount, 0, len(counts))
+	for command, count := range counts {
+		commandName, ok := appmessage.ProtocolMessageCommandToString[command]
+		if !ok {
+			commandName = command.String()
+		}
+		peerCounts = append(peerCounts, &appmessage.PeerMessageCount{
+			Command:  commandName,
+			Messages: count.Messages,
+			Bytes:    count.Bytes,
+		})
+	}
+	sort.Slice(peerCounts, func(i, j int) bool {
+		return peerCounts[i].Command < peerCounts[j].Command
+	})
+	return peerCounts
+}
+
+// timestampMilliseconds returns the given time in milliseconds since the
+// epoch, or 0 if it's the zero time
+func timestampMilliseconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
@@ -0,0 +1,107 @@
+package rpchandlers
+
+import (
+	"encoding/hex"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util/mstime"
+)
+
+// HandleGetDbInfo handles the respectively named RPC command
+func HandleGetDbInfo(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	db := context.Database
+	sizeBytes, err := db.ApproximateSize(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := namedBuckets(db)
+	if err != nil {
+		return nil, err
+	}
+	bucketInfos := make([]*appmessage.DbBucketInfo, len(buckets))
+	for i, bucket := range buckets {
+		bucketSizeBytes, err := db.ApproximateSize(bucket.bucket.Path(), bucket.bucket.End())
+		if err != nil {
+			return nil, err
+		}
+		bucketInfos[i] = &appmessage.DbBucketInfo{
+			Name:      bucket.name,
+			SizeBytes: bucketSizeBytes,
+		}
+	}
+
+	var lastCompactionTimestamp int64
+	lastCompactionTime := context.DBCompactor.LastCompactionTime()
+	if !lastCompactionTime.IsZero() {
+		lastCompactionTimestamp = mstime.ToMSTime(lastCompactionTime).UnixMilliseconds()
+	}
+
+	return &appmessage.GetDbInfoResponseMessage{
+		DbType:                  context.Config.DbType,
+		SizeBytes:               sizeBytes,
+		Buckets:                 bucketInfos,
+		IsCompacting:            context.DBCompactor.IsCompacting(),
+		LastCompactionTimestamp: lastCompactionTimestamp,
+	}, nil
+}
+
+type namedBucket struct {
+	name   string
+	bucket *database.Bucket
+}
+
+// namedBuckets returns the buckets in the root of the database along with
+// their names. The data of every consensus instance is stored under a single
+// byte prefix, so the buckets under such prefixes are returned instead of the
+// prefixes themselves.
+func namedBuckets(db database.Database) ([]*namedBucket, error) {
+	rootBuckets, err := database.SubBuckets(db, database.MakeBucket(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []*namedBucket
+	for _, rootBucket := range rootBuckets {
+		rootBucketName := bucketComponentName(rootBucket, database.MakeBucket(nil))
+		if len(rootBucket.Path()) != 2 {
+			buckets = append(buckets, &namedBucket{name: rootBucketName, bucket: rootBucket})
+			continue
+		}
+		prefixBuckets, err := database.SubBuckets(db, rootBucket)
+		if err != nil {
+			return nil, err
+		}
+		for _, prefixBucket := range prefixBuckets {
+			buckets = append(buckets, &namedBucket{
+				name:   rootBucketName + "/" + bucketComponentName(prefixBucket, rootBucket),
+				bucket: prefixBucket,
+			})
+		}
+	}
+	return buckets, nil
+}
+
+// bucketComponentName returns the name of the given direct sub-bucket of
+// parent. Single byte names, such as database prefixes and block levels,
+// and names that aren't printable are hex encoded.
+func bucketComponentName(bucket *database.Bucket, parent *database.Bucket) string {
+	path := bucket.Path()
+	component := path[len(parent.Path()) : len(path)-1]
+	if len(component) == 1 || !isPrintable(component) {
+		return hex.EncodeToString(component)
+	}
+	return string(component)
+}
+
+func isPrintable(bytes []byte) bool {
+	for _, b := range bytes {
+		if b < 0x20 || b > 0x7e {
+			return false
+		}
+	}
+	return true
+}
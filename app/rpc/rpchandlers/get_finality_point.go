@@ -0,0 +1,20 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleGetFinalityPoint handles the respectively named RPC command
+func HandleGetFinalityPoint(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	finalityPoint, err := context.Domain.Consensus().VirtualFinalityPoint()
+	if err != nil {
+		return nil, err
+	}
+
+	response := appmessage.NewGetFinalityPointResponseMessage(finalityPoint.String(),
+		context.Config.ActiveNetParams.FinalityDepth())
+
+	return response, nil
+}
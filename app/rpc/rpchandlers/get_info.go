@@ -9,7 +9,7 @@ import (
 
 // HandleGetInfo handles the respectively named RPC command
 func HandleGetInfo(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
-	isNearlySynced, err := context.Domain.Consensus().IsNearlySynced()
+	isCurrent, err := context.ProtocolManager.Context().IsCurrent()
 	if err != nil {
 		return nil, err
 	}
@@ -19,7 +19,7 @@ func HandleGetInfo(context *rpccontext.Context, _ *router.Router, _ appmessage.M
 		uint64(context.Domain.MiningManager().TransactionCount(true, false)),
 		version.Version(),
 		context.Config.UTXOIndex,
-		context.ProtocolManager.Context().HasPeers() && isNearlySynced,
+		context.ProtocolManager.Context().HasPeers() && isCurrent,
 	)
 
 	return response, nil
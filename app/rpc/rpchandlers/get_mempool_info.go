@@ -0,0 +1,31 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleGetMempoolInfo handles the respectively named RPC command
+func HandleGetMempoolInfo(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	mempoolInfo := context.Domain.MiningManager().MempoolInfo()
+	orphans := mempoolInfo.Orphans
+	return &appmessage.GetMempoolInfoResponseMessage{
+		TransactionCount:           mempoolInfo.TransactionCount,
+		OrphanCount:                orphans.Count,
+		MemoryUsage:                mempoolInfo.MemoryUsage,
+		MaximumMemoryUsage:         mempoolInfo.MaximumMemoryUsage,
+		MempoolMinimumFee:          mempoolInfo.MinimumFeeRate,
+		MinimumRelayTransactionFee: mempoolInfo.MinimumRelayTransactionFee,
+		// The minimum fee rate is raised above evicted transactions by the configured one
+		IncrementalRelayFee:        mempoolInfo.MinimumRelayTransactionFee,
+		OrphanMemoryUsage:          orphans.MemoryUsage,
+		MaximumOrphanCount:         orphans.MaximumCount,
+		MaximumOrphanMemoryUsage:   orphans.MaximumMemoryUsage,
+		MissingOrphanOutpointCount: orphans.MissingOutpointCount,
+		OrphanPeerCount:            orphans.PeerCount,
+		PromotedOrphanCount:        orphans.PromotedCount,
+		EvictedOrphanCount:         orphans.EvictedCount,
+		ExpiredOrphanCount:         orphans.ExpiredCount,
+	}, nil
+}
@@ -0,0 +1,31 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util/mstime"
+)
+
+// HandleGetNetTotals handles the respectively named RPC command
+func HandleGetNetTotals(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	traffic := context.NetAdapter.P2PTraffic()
+	uploadTarget := context.NetAdapter.UploadTarget()
+
+	return &appmessage.GetNetTotalsResponseMessage{
+		TotalBytesSent:         traffic.BytesSent,
+		TotalBytesReceived:     traffic.BytesReceived,
+		BlockBytesServed:       traffic.BlockBytesServed,
+		TransactionBytesServed: traffic.TransactionBytesServed,
+		TimeMilliseconds:       mstime.Now().UnixMilliseconds(),
+		UploadTarget: &appmessage.UploadTarget{
+			TimeframeSeconds:      uint64(netadapter.UploadTargetCycle.Seconds()),
+			Target:                uploadTarget.Target(),
+			TargetReached:         uploadTarget.IsReached(),
+			ServeHistoricalBlocks: uploadTarget.ShouldServeHistoricalData(),
+			BytesLeftInCycle:      uploadTarget.BytesLeftInCycle(),
+			SecondsLeftInCycle:    uint64(uploadTarget.TimeLeftInCycle().Seconds()),
+		},
+	}, nil
+}
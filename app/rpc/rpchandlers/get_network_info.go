@@ -0,0 +1,85 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/infrastructure/network/transport"
+)
+
+// HandleGetNetworkInfo handles the respectively named RPC command
+func HandleGetNetworkInfo(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	localAddresses := context.AddressManager.LocalAddresses()
+	rpcLocalAddresses := make([]*appmessage.LocalAddress, len(localAddresses))
+	hasIPv4Address, hasIPv6Address, hasOnionAddress := false, false, false
+	for i, localAddress := range localAddresses {
+		rpcLocalAddresses[i] = &appmessage.LocalAddress{
+			Address: localAddress.NetAddress.String(),
+			Score:   int32(localAddress.Score),
+		}
+		if localAddress.NetAddress.IsOnion() {
+			hasOnionAddress = true
+		} else if addressmanager.IsIPv4(localAddress.NetAddress) {
+			hasIPv4Address = true
+		} else {
+			hasIPv6Address = true
+		}
+	}
+
+	// A network is reachable if this node has a routable address in it, if it
+	// passed its self-test, or if connections go through a proxy. Onion peers are
+	// only reached through the proxy, which is assumed to be the SOCKS port of Tor.
+	// I2P peers are reached through the SAM bridge, which is reported as the proxy
+	// of I2P.
+	proxy := context.Config.Proxy
+	isClearnetEnabled := !context.Config.I2POnly
+	reachability := context.NetAdapter.Reachability()
+	networks := []*appmessage.NetworkReachability{
+		ipNetworkReachability("ipv4", &reachability.IPv4, isClearnetEnabled, hasIPv4Address, proxy),
+		ipNetworkReachability("ipv6", &reachability.IPv6, isClearnetEnabled, hasIPv6Address, proxy),
+		{Name: "onion", Reachable: isClearnetEnabled && (hasOnionAddress || proxy != ""), Proxy: proxy,
+			Limited: !isClearnetEnabled},
+		{Name: "i2p", Reachable: context.Config.I2PSAM != "", Proxy: context.Config.I2PSAM},
+	}
+
+	var inboundConnectionCount, outboundConnectionCount uint32
+	for _, peer := range context.ProtocolManager.Peers() {
+		if peer.IsOutbound() {
+			outboundConnectionCount++
+		} else {
+			inboundConnectionCount++
+		}
+	}
+
+	return &appmessage.GetNetworkInfoResponseMessage{
+		ProtocolVersion:            context.Config.ProtocolVersion,
+		LocalServices:              uint64(context.ProtocolManager.Context().LocalServices()),
+		MinimumRelayTransactionFee: uint64(context.Config.MinRelayTxFee),
+		IncrementalRelayFee:        uint64(context.Config.MinRelayTxFee),
+		LocalAddresses:             rpcLocalAddresses,
+		Networks:                   networks,
+		InboundConnectionCount:     inboundConnectionCount,
+		OutboundConnectionCount:    outboundConnectionCount,
+	}, nil
+}
+
+func ipNetworkReachability(name string, networkReachability *transport.NetworkReachability,
+	isClearnetEnabled bool, hasLocalAddress bool, proxy string) *appmessage.NetworkReachability {
+
+	isLimited := !isClearnetEnabled || networkReachability.Limited
+	isSelfTested := !isLimited && proxy == ""
+	selfTestError := ""
+	if isSelfTested && networkReachability.SelfTestError != nil {
+		selfTestError = networkReachability.SelfTestError.Error()
+	}
+	isSelfTestPassed := isSelfTested && networkReachability.SelfTestError == nil
+	return &appmessage.NetworkReachability{
+		Name:           name,
+		Reachable:      !isLimited && (hasLocalAddress || isSelfTestPassed || proxy != ""),
+		Proxy:          proxy,
+		Limited:        isLimited,
+		SelfTestPassed: isSelfTestPassed,
+		SelfTestError:  selfTestError,
+	}
+}
@@ -0,0 +1,27 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util/processingstats"
+)
+
+// HandleGetProcessingStats handles the respectively named RPC command
+func HandleGetProcessingStats(_ *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	stats := processingstats.Stats()
+	stages := make([]*appmessage.ProcessingStageStats, len(stats))
+	for i, stageStats := range stats {
+		stages[i] = &appmessage.ProcessingStageStats{
+			Stage:               string(stageStats.Stage),
+			Count:               stageStats.Count,
+			AverageMicroseconds: uint64(stageStats.Average.Microseconds()),
+			P50Microseconds:     uint64(stageStats.P50.Microseconds()),
+			P90Microseconds:     uint64(stageStats.P90.Microseconds()),
+			P99Microseconds:     uint64(stageStats.P99.Microseconds()),
+			MaxMicroseconds:     uint64(stageStats.Max.Microseconds()),
+		}
+	}
+	response := appmessage.NewGetProcessingStatsResponseMessage(stages)
+	return response, nil
+}
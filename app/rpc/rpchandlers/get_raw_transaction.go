@@ -0,0 +1,68 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionid"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleGetRawTransaction handles the respectively named RPC command
+func HandleGetRawTransaction(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	getRawTransactionRequest := request.(*appmessage.GetRawTransactionRequestMessage)
+
+	transactionID, err := transactionid.FromString(getRawTransactionRequest.TxID)
+	if err != nil {
+		errorMessage := &appmessage.GetRawTransactionResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Transaction ID could not be parsed: %s", err)
+		return errorMessage, nil
+	}
+
+	mempoolTransaction, _, found := context.Domain.MiningManager().GetTransaction(transactionID, true, false)
+	if found {
+		rpcTransaction := appmessage.DomainTransactionToRPCTransaction(mempoolTransaction)
+		err := context.PopulateTransactionWithVerboseData(rpcTransaction, nil)
+		if err != nil {
+			return nil, err
+		}
+		return appmessage.NewGetRawTransactionResponseMessage(rpcTransaction, true), nil
+	}
+
+	if context.TXIndex == nil {
+		errorMessage := &appmessage.GetRawTransactionResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Transaction %s was not found in the mempool. "+
+			"Transactions in the DAG may only be retrieved when the node runs with --txindex", transactionID)
+		return errorMessage, nil
+	}
+
+	transaction, blockHash, found, err := context.TXIndex.Transaction(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		errorMessage := &appmessage.GetRawTransactionResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Transaction %s was not found", transactionID)
+		return errorMessage, nil
+	}
+
+	blockHeader, err := context.Domain.Consensus().GetBlockHeader(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	rpcTransaction := appmessage.DomainTransactionToRPCTransaction(transaction)
+	err = context.PopulateTransactionWithVerboseData(rpcTransaction, blockHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	response := appmessage.NewGetRawTransactionResponseMessage(rpcTransaction, false)
+	confirmations, acceptingBlockHash, isAccepted, err := context.TXIndex.ConfirmationsByBlueScore(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if isAccepted {
+		response.Confirmations = confirmations
+		response.AcceptingBlockHash = acceptingBlockHash.String()
+	}
+	return response, nil
+}
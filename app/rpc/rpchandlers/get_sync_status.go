@@ -0,0 +1,47 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleGetSyncStatus handles the respectively named RPC command
+func HandleGetSyncStatus(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	syncStatus, err := context.ProtocolManager.Context().SyncStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	response := appmessage.NewGetSyncStatusResponseMessage()
+	response.IsSynced = context.ProtocolManager.Context().HasPeers() && syncStatus.IsSynced
+	response.IsIBDRunning = syncStatus.IsIBDRunning
+	response.IBDPeerAddress = syncStatus.IBDPeer
+	response.HeaderCount = syncStatus.HeaderCount
+	response.BlockCount = syncStatus.BlockCount
+	response.HeadersDAAScore = syncStatus.HeadersDAAScore
+	response.BlocksDAAScore = syncStatus.BlocksDAAScore
+	response.TargetDAAScore = syncStatus.TargetDAAScore
+	response.HeadersProgress = syncStatus.HeadersProgress
+	response.BlocksProgress = syncStatus.BlocksProgress
+
+	if syncStatus.TrustedChainBlockHash != nil {
+		response.TrustedChainBlockHash = syncStatus.TrustedChainBlockHash.String()
+	}
+	response.TrustedPeers = make([]*appmessage.TrustedPeerSyncStatus, len(syncStatus.TrustedPeers))
+	for i, trustedPeer := range syncStatus.TrustedPeers {
+		response.TrustedPeers[i] = &appmessage.TrustedPeerSyncStatus{
+			Address:    trustedPeer.Address,
+			IsDiverged: trustedPeer.IsDiverged,
+		}
+		if trustedPeer.AnnouncedBlockHash != nil {
+			response.TrustedPeers[i].AnnouncedBlockHash = trustedPeer.AnnouncedBlockHash.String()
+			response.TrustedPeers[i].AnnouncementTime = trustedPeer.AnnouncementTime.UnixMilli()
+		}
+		if trustedPeer.AgreedChainBlockHash != nil {
+			response.TrustedPeers[i].AgreedChainBlockHash = trustedPeer.AgreedChainBlockHash.String()
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,79 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/merkle"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionid"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleGetTxOutProof handles the respectively named RPC command
+func HandleGetTxOutProof(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	getTxOutProofRequest := request.(*appmessage.GetTxOutProofRequestMessage)
+
+	transactionID, err := transactionid.FromString(getTxOutProofRequest.TransactionID)
+	if err != nil {
+		errorMessage := &appmessage.GetTxOutProofResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Transaction ID could not be parsed: %s", err)
+		return errorMessage, nil
+	}
+
+	var blockHash *externalapi.DomainHash
+	if getTxOutProofRequest.BlockHash != "" {
+		blockHash, err = externalapi.NewDomainHashFromString(getTxOutProofRequest.BlockHash)
+		if err != nil {
+			errorMessage := &appmessage.GetTxOutProofResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("Block hash could not be parsed: %s", err)
+			return errorMessage, nil
+		}
+	} else {
+		if context.TXIndex == nil {
+			errorMessage := &appmessage.GetTxOutProofResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("The block hash may only be omitted when the node runs with --txindex")
+			return errorMessage, nil
+		}
+		var found bool
+		_, blockHash, found, err = context.TXIndex.Transaction(transactionID)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			errorMessage := &appmessage.GetTxOutProofResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("Transaction %s was not found", transactionID)
+			return errorMessage, nil
+		}
+	}
+
+	block, found, err := context.Domain.Consensus().GetBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		errorMessage := &appmessage.GetTxOutProofResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Block %s was not found or its body was pruned", blockHash)
+		return errorMessage, nil
+	}
+	proof, ok := merkle.TransactionMerkleProof(block.Transactions, transactionID)
+	if !ok {
+		errorMessage := &appmessage.GetTxOutProofResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Block %s does not contain transaction %s", blockHash, transactionID)
+		return errorMessage, nil
+	}
+
+	rpcBlock := appmessage.DomainBlockToRPCBlock(&externalapi.DomainBlock{
+		Header:       block.Header,
+		Transactions: []*externalapi.DomainTransaction{block.Transactions[proof.Index]},
+	})
+	siblings := make([]string, len(proof.Siblings))
+	for i, sibling := range proof.Siblings {
+		siblings[i] = sibling.String()
+	}
+	return appmessage.NewGetTxOutProofResponseMessage(&appmessage.RPCTransactionMerkleProof{
+		Header:      rpcBlock.Header,
+		Transaction: rpcBlock.Transactions[0],
+		Index:       proof.Index,
+		Siblings:    siblings,
+	}), nil
+}
@@ -0,0 +1,49 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/utxoindex"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleGetUTXOSetInfo handles the respectively named RPC command
+func HandleGetUTXOSetInfo(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if !context.Config.UTXOIndex {
+		errorMessage := &appmessage.GetUTXOSetInfoResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Method unavailable when kaspad is run without --utxoindex")
+		return errorMessage, nil
+	}
+
+	getUTXOSetInfoRequest := request.(*appmessage.GetUTXOSetInfoRequestMessage)
+
+	statistics, err := context.UTXOIndex.GetUTXOStatistics()
+	if err != nil {
+		return nil, err
+	}
+	total := statistics.Total()
+
+	if !getUTXOSetInfoRequest.Verbose {
+		return appmessage.NewGetUTXOSetInfoResponseMessage(total.Count, total.Sompi, nil, nil), nil
+	}
+
+	scriptClassStatistics := make([]*appmessage.UTXOSetStatisticsEntry, len(statistics.ByScriptClass))
+	for i, entry := range statistics.ByScriptClass {
+		scriptClassStatistics[i] = &appmessage.UTXOSetStatisticsEntry{
+			Name:  txscript.ScriptClass(i).String(),
+			Count: entry.Count,
+			Sompi: entry.Sompi,
+		}
+	}
+	amountBucketStatistics := make([]*appmessage.UTXOSetStatisticsEntry, len(statistics.ByAmountBucket))
+	for i, entry := range statistics.ByAmountBucket {
+		amountBucketStatistics[i] = &appmessage.UTXOSetStatisticsEntry{
+			Name:  utxoindex.AmountBucket(i).String(),
+			Count: entry.Count,
+			Sompi: entry.Sompi,
+		}
+	}
+
+	return appmessage.NewGetUTXOSetInfoResponseMessage(total.Count, total.Sompi, scriptClassStatistics, amountBucketStatistics), nil
+}
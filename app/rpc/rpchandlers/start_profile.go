@@ -0,0 +1,29 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleStartProfile handles the respectively named RPC command
+func HandleStartProfile(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if context.Config.SafeRPC {
+		log.Warn("StartProfile RPC command called while node in safe RPC mode -- ignoring.")
+		errorMessage := &appmessage.StartProfileResponseMessage{}
+		errorMessage.Error =
+			appmessage.RPCErrorf("StartProfile RPC command called while node in safe RPC mode")
+		return errorMessage, nil
+	}
+
+	startProfileRequest := request.(*appmessage.StartProfileRequestMessage)
+	filePath, err := context.Profiler.Start(startProfileRequest.ProfileType)
+	if err != nil {
+		errorMessage := &appmessage.StartProfileResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Could not start the profile: %s", err)
+		return errorMessage, nil
+	}
+	log.Infof("Started a %s profile written to %s", startProfileRequest.ProfileType, filePath)
+
+	return appmessage.NewStartProfileResponseMessage(filePath), nil
+}
@@ -0,0 +1,29 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleStopProfile handles the respectively named RPC command
+func HandleStopProfile(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if context.Config.SafeRPC {
+		log.Warn("StopProfile RPC command called while node in safe RPC mode -- ignoring.")
+		errorMessage := &appmessage.StopProfileResponseMessage{}
+		errorMessage.Error =
+			appmessage.RPCErrorf("StopProfile RPC command called while node in safe RPC mode")
+		return errorMessage, nil
+	}
+
+	stopProfileRequest := request.(*appmessage.StopProfileRequestMessage)
+	filePath, duration, err := context.Profiler.Stop(stopProfileRequest.ProfileType)
+	if err != nil {
+		errorMessage := &appmessage.StopProfileResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Could not stop the profile: %s", err)
+		return errorMessage, nil
+	}
+	log.Infof("Stopped the %s profile written to %s after %s", stopProfileRequest.ProfileType, filePath, duration)
+
+	return appmessage.NewStopProfileResponseMessage(filePath, uint64(duration.Milliseconds())), nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
 	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
@@ -17,9 +18,9 @@ func HandleSubmitBlock(context *rpccontext.Context, _ *router.Router, request ap
 
 	var err error
 	isSynced := false
-	// The node is considered synced if it has peers and consensus state is nearly synced
+	// The node is considered synced if it has peers and is current with the network
 	if context.ProtocolManager.Context().HasPeers() {
-		isSynced, err = context.ProtocolManager.Context().IsNearlySynced()
+		isSynced, err = context.ProtocolManager.Context().IsCurrent()
 		if err != nil {
 			return nil, err
 		}
@@ -40,6 +41,14 @@ func HandleSubmitBlock(context *rpccontext.Context, _ *router.Router, request ap
 		}, nil
 	}
 
+	err = context.BlockTemplateState.ValidateMutations(domainBlock)
+	if err != nil {
+		return &appmessage.SubmitBlockResponseMessage{
+			Error:        appmessage.RPCErrorf("Block rejected. Reason: %s", err),
+			RejectReason: appmessage.RejectReasonBlockInvalid,
+		}, nil
+	}
+
 	if !submitBlockRequest.AllowNonDAABlocks {
 		virtualDAAScore, err := context.Domain.Consensus().GetVirtualDAAScore()
 		if err != nil {
@@ -59,6 +68,12 @@ func HandleSubmitBlock(context *rpccontext.Context, _ *router.Router, request ap
 
 	err = context.ProtocolManager.AddBlock(domainBlock)
 	if err != nil {
+		if errors.Is(err, externalapi.ErrRejectedByBlockValidator) {
+			return &appmessage.SubmitBlockResponseMessage{
+				Error:        appmessage.RPCErrorf("Block rejected. Reason: %s", err),
+				RejectReason: appmessage.RejectReasonBlockInvalid,
+			}, nil
+		}
 		isProtocolOrRuleError := errors.As(err, &ruleerrors.RuleError{}) || errors.As(err, &protocolerrors.ProtocolError{})
 		if !isProtocolOrRuleError {
 			return nil, err
@@ -0,0 +1,52 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// HandleSubmitPackage handles the respectively named RPC command
+func HandleSubmitPackage(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	submitPackageRequest := request.(*appmessage.SubmitPackageRequestMessage)
+
+	if len(submitPackageRequest.Transactions) == 0 ||
+		len(submitPackageRequest.Transactions) > mempool.MaximumPackageTransactions {
+
+		errorMessage := &appmessage.SubmitPackageResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("A package has between 1 and %d transactions",
+			mempool.MaximumPackageTransactions)
+		return errorMessage, nil
+	}
+	domainTransactions := make([]*externalapi.DomainTransaction, len(submitPackageRequest.Transactions))
+	transactionIDs := make([]string, len(submitPackageRequest.Transactions))
+	for i, transaction := range submitPackageRequest.Transactions {
+		domainTransaction, err := appmessage.RPCTransactionToDomainTransaction(transaction)
+		if err != nil {
+			errorMessage := &appmessage.SubmitPackageResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("Could not parse transaction %d: %s", i, err)
+			return errorMessage, nil
+		}
+		domainTransactions[i] = domainTransaction
+		transactionIDs[i] = consensushashing.TransactionID(domainTransaction).String()
+	}
+
+	err := context.ProtocolManager.AddPackage(domainTransactions)
+	if err != nil {
+		if !errors.As(err, &mempool.RuleError{}) {
+			return nil, err
+		}
+
+		log.Debugf("Rejected package of %s: %s", transactionIDs[len(transactionIDs)-1], err)
+		// Return the IDs also in the case of error, so that clients can match the response to the correct package
+		errorMessage := appmessage.NewSubmitPackageResponseMessage(transactionIDs)
+		errorMessage.Error = appmessage.RPCErrorf("Rejected package: %s", err)
+		return errorMessage, nil
+	}
+
+	return appmessage.NewSubmitPackageResponseMessage(transactionIDs), nil
+}
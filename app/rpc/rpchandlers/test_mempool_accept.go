@@ -0,0 +1,52 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// maxTestMempoolAcceptTransactions is the number of transactions a single testMempoolAccept
+// request may check, since the mempool is locked while they're checked
+const maxTestMempoolAcceptTransactions = 100
+
+// HandleTestMempoolAccept handles the respectively named RPC command
+func HandleTestMempoolAccept(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	testMempoolAcceptRequest := request.(*appmessage.TestMempoolAcceptRequestMessage)
+
+	if len(testMempoolAcceptRequest.Transactions) > maxTestMempoolAcceptTransactions {
+		errorMessage := &appmessage.TestMempoolAcceptResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("At most %d transactions may be checked at once",
+			maxTestMempoolAcceptTransactions)
+		return errorMessage, nil
+	}
+	domainTransactions := make([]*externalapi.DomainTransaction, len(testMempoolAcceptRequest.Transactions))
+	for i, transaction := range testMempoolAcceptRequest.Transactions {
+		domainTransaction, err := appmessage.RPCTransactionToDomainTransaction(transaction)
+		if err != nil {
+			errorMessage := &appmessage.TestMempoolAcceptResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("Could not parse transaction %d: %s", i, err)
+			return errorMessage, nil
+		}
+		domainTransactions[i] = domainTransaction
+	}
+
+	checks, err := context.Domain.MiningManager().CheckTransactionForAcceptance(domainTransactions)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*appmessage.TestMempoolAcceptResult, len(checks))
+	for i, check := range checks {
+		results[i] = &appmessage.TestMempoolAcceptResult{
+			TransactionID: check.TransactionID.String(),
+			Allowed:       check.RejectReason == nil,
+			Fee:           check.Fee,
+			Mass:          check.Mass,
+		}
+		if check.RejectReason != nil {
+			results[i].RejectReason = check.RejectReason.Error()
+		}
+	}
+	return appmessage.NewTestMempoolAcceptResponseMessage(results), nil
+}
@@ -0,0 +1,59 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/merkle"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleVerifyTxOutProof handles the respectively named RPC command
+func HandleVerifyTxOutProof(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	verifyTxOutProofRequest := request.(*appmessage.VerifyTxOutProofRequestMessage)
+	rpcProof := verifyTxOutProofRequest.Proof
+
+	// The header and the transaction are converted together, as the block that they prove
+	block, err := appmessage.RPCBlockToDomainBlock(&appmessage.RPCBlock{
+		Header:       rpcProof.Header,
+		Transactions: []*appmessage.RPCTransaction{rpcProof.Transaction},
+	})
+	if err != nil {
+		errorMessage := &appmessage.VerifyTxOutProofResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Proof could not be parsed: %s", err)
+		return errorMessage, nil
+	}
+	proof := &merkle.Proof{
+		Index:    rpcProof.Index,
+		Siblings: make([]*externalapi.DomainHash, len(rpcProof.Siblings)),
+	}
+	for i, sibling := range rpcProof.Siblings {
+		proof.Siblings[i], err = externalapi.NewDomainHashFromString(sibling)
+		if err != nil {
+			errorMessage := &appmessage.VerifyTxOutProofResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("Proof sibling could not be parsed: %s", err)
+			return errorMessage, nil
+		}
+	}
+
+	blockHash := consensushashing.HeaderHash(block.Header)
+	blockInfo, err := context.Domain.Consensus().GetBlockInfo(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if !blockInfo.HasHeader() {
+		errorMessage := &appmessage.VerifyTxOutProofResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Block %s of the proof is not a valid block known to this node", blockHash)
+		return errorMessage, nil
+	}
+
+	transaction := block.Transactions[0]
+	if !merkle.VerifyTransactionMerkleProof(block.Header.HashMerkleRoot(), consensushashing.TransactionHash(transaction), proof) {
+		errorMessage := &appmessage.VerifyTxOutProofResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("The proof does not prove that block %s contains the transaction", blockHash)
+		return errorMessage, nil
+	}
+	return appmessage.NewVerifyTxOutProofResponseMessage(consensushashing.TransactionID(transaction).String(),
+		blockHash.String()), nil
+}
@@ -0,0 +1,262 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/kaspanet/kaspad/util/panics"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// typedRPCServer implements the TypedRPC gRPC service. Every method is served by
+// the same handler that serves the respective message of RPC.MessageStream, so
+// both services always behave the same.
+type typedRPCServer struct {
+	protowire.UnimplementedTypedRPCServer
+	context *rpccontext.Context
+}
+
+func newTypedRPCServer(context *rpccontext.Context) *typedRPCServer {
+	return &typedRPCServer{context: context}
+}
+
+// handle converts the given request to its app message, and passes it to its handler
+func (s *typedRPCServer) handle(router *router.Router, request *protowire.KaspadMessage) (*protowire.KaspadMessage, error) {
+	appRequest, err := request.ToAppMessage()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	handler, ok := handlers[appRequest.Command()]
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "no handler for %s", appRequest.Command())
+	}
+	appResponse, err := handler(s.context, router, appRequest)
+	if err != nil {
+		log.Errorf("Error handling typed RPC request %s: %+v", appRequest.Command(), err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	response, err := protowire.FromAppMessage(appResponse)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return response, nil
+}
+
+// stream registers a new notification listener, passes the given notify request
+// to its handler, and then sends every notification the listener receives until
+// the stream is canceled by the client.
+//
+// While send blocks on a slow client, notifications pile up in the outgoing route of
+// the listener's router, whose capacity is bounded. Once it's full, the notification
+// manager drops further notifications to this client (see Route.MaybeEnqueue) rather
+// than blocking, so a slow client never holds up the node or the other clients.
+func (s *typedRPCServer) stream(ctx context.Context, request *protowire.KaspadMessage,
+	responseError func(response *protowire.KaspadMessage) *protowire.RPCError,
+	send func(notification *protowire.KaspadMessage) error) error {
+
+	defer panics.HandlePanic(log, "typedRPCServer.stream", nil)
+
+	streamRouter := router.NewRouter("typed rpc stream")
+	s.context.NotificationManager.AddListener(streamRouter)
+	// The context of the stream is canceled once this method returns,
+	// so this is the only place that closes the router
+	spawn("typedRPCServer.stream-closeRouter", func() {
+		<-ctx.Done()
+		s.context.NotificationManager.RemoveListener(streamRouter)
+		streamRouter.Close()
+	})
+
+	response, err := s.handle(streamRouter, request)
+	if err != nil {
+		return err
+	}
+	if rpcError := responseError(response); rpcError != nil {
+		return status.Error(codes.FailedPrecondition, rpcError.Message)
+	}
+
+	for {
+		notification, err := streamRouter.OutgoingRoute().Dequeue()
+		if err != nil {
+			if errors.Is(err, router.ErrRouteClosed) {
+				return nil
+			}
+			return err
+		}
+		notificationMessage, err := protowire.FromAppMessage(notification)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		err = send(notificationMessage)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *typedRPCServer) GetInfo(_ context.Context, request *protowire.GetInfoRequestMessage) (
+	*protowire.GetInfoResponseMessage, error) {
+
+	response, err := s.handle(nil, &protowire.KaspadMessage{
+		Payload: &protowire.KaspadMessage_GetInfoRequest{GetInfoRequest: request}})
+	if err != nil {
+		return nil, err
+	}
+	return response.GetGetInfoResponse(), nil
+}
+
+func (s *typedRPCServer) GetBlockDagInfo(_ context.Context, request *protowire.GetBlockDagInfoRequestMessage) (
+	*protowire.GetBlockDagInfoResponseMessage, error) {
+
+	response, err := s.handle(nil, &protowire.KaspadMessage{
+		Payload: &protowire.KaspadMessage_GetBlockDagInfoRequest{GetBlockDagInfoRequest: request}})
+	if err != nil {
+		return nil, err
+	}
+	return response.GetGetBlockDagInfoResponse(), nil
+}
+
+func (s *typedRPCServer) GetBlock(_ context.Context, request *protowire.GetBlockRequestMessage) (
+	*protowire.GetBlockResponseMessage, error) {
+
+	response, err := s.handle(nil, &protowire.KaspadMessage{
+		Payload: &protowire.KaspadMessage_GetBlockRequest{GetBlockRequest: request}})
+	if err != nil {
+		return nil, err
+	}
+	return response.GetGetBlockResponse(), nil
+}
+
+func (s *typedRPCServer) SubmitBlock(_ context.Context, request *protowire.SubmitBlockRequestMessage) (
+	*protowire.SubmitBlockResponseMessage, error) {
+
+	response, err := s.handle(nil, &protowire.KaspadMessage{
+		Payload: &protowire.KaspadMessage_SubmitBlockRequest{SubmitBlockRequest: request}})
+	if err != nil {
+		return nil, err
+	}
+	return response.GetSubmitBlockResponse(), nil
+}
+
+func (s *typedRPCServer) SubmitTransaction(_ context.Context, request *protowire.SubmitTransactionRequestMessage) (
+	*protowire.SubmitTransactionResponseMessage, error) {
+
+	response, err := s.handle(nil, &protowire.KaspadMessage{
+		Payload: &protowire.KaspadMessage_SubmitTransactionRequest{SubmitTransactionRequest: request}})
+	if err != nil {
+		return nil, err
+	}
+	return response.GetSubmitTransactionResponse(), nil
+}
+
+func (s *typedRPCServer) GetMempoolEntry(_ context.Context, request *protowire.GetMempoolEntryRequestMessage) (
+	*protowire.GetMempoolEntryResponseMessage, error) {
+
+	response, err := s.handle(nil, &protowire.KaspadMessage{
+		Payload: &protowire.KaspadMessage_GetMempoolEntryRequest{GetMempoolEntryRequest: request}})
+	if err != nil {
+		return nil, err
+	}
+	return response.GetGetMempoolEntryResponse(), nil
+}
+
+func (s *typedRPCServer) GetMempoolEntries(_ context.Context, request *protowire.GetMempoolEntriesRequestMessage) (
+	*protowire.GetMempoolEntriesResponseMessage, error) {
+
+	response, err := s.handle(nil, &protowire.KaspadMessage{
+		Payload: &protowire.KaspadMessage_GetMempoolEntriesRequest{GetMempoolEntriesRequest: request}})
+	if err != nil {
+		return nil, err
+	}
+	return response.GetGetMempoolEntriesResponse(), nil
+}
+
+func (s *typedRPCServer) StreamBlockAdded(request *protowire.NotifyBlockAddedRequestMessage,
+	stream protowire.TypedRPC_StreamBlockAddedServer) error {
+
+	return s.stream(stream.Context(),
+		&protowire.KaspadMessage{
+			Payload: &protowire.KaspadMessage_NotifyBlockAddedRequest{NotifyBlockAddedRequest: request}},
+		func(response *protowire.KaspadMessage) *protowire.RPCError {
+			return response.GetNotifyBlockAddedResponse().GetError()
+		},
+		func(notification *protowire.KaspadMessage) error {
+			return stream.Send(notification.GetBlockAddedNotification())
+		})
+}
+
+func (s *typedRPCServer) StreamVirtualSelectedParentChainChanged(
+	request *protowire.NotifyVirtualSelectedParentChainChangedRequestMessage,
+	stream protowire.TypedRPC_StreamVirtualSelectedParentChainChangedServer) error {
+
+	return s.stream(stream.Context(),
+		&protowire.KaspadMessage{
+			Payload: &protowire.KaspadMessage_NotifyVirtualSelectedParentChainChangedRequest{
+				NotifyVirtualSelectedParentChainChangedRequest: request}},
+		func(response *protowire.KaspadMessage) *protowire.RPCError {
+			return response.GetNotifyVirtualSelectedParentChainChangedResponse().GetError()
+		},
+		func(notification *protowire.KaspadMessage) error {
+			return stream.Send(notification.GetVirtualSelectedParentChainChangedNotification())
+		})
+}
+
+func (s *typedRPCServer) StreamDagTipChanged(request *protowire.NotifyDagTipChangedRequestMessage,
+	stream protowire.TypedRPC_StreamDagTipChangedServer) error {
+
+	return s.stream(stream.Context(),
+		&protowire.KaspadMessage{
+			Payload: &protowire.KaspadMessage_NotifyDagTipChangedRequest{NotifyDagTipChangedRequest: request}},
+		func(response *protowire.KaspadMessage) *protowire.RPCError {
+			return response.GetNotifyDagTipChangedResponse().GetError()
+		},
+		func(notification *protowire.KaspadMessage) error {
+			return stream.Send(notification.GetDagTipChangedNotification())
+		})
+}
+
+func (s *typedRPCServer) StreamUtxosChanged(request *protowire.NotifyUtxosChangedRequestMessage,
+	stream protowire.TypedRPC_StreamUtxosChangedServer) error {
+
+	return s.stream(stream.Context(),
+		&protowire.KaspadMessage{
+			Payload: &protowire.KaspadMessage_NotifyUtxosChangedRequest{NotifyUtxosChangedRequest: request}},
+		func(response *protowire.KaspadMessage) *protowire.RPCError {
+			return response.GetNotifyUtxosChangedResponse().GetError()
+		},
+		func(notification *protowire.KaspadMessage) error {
+			return stream.Send(notification.GetUtxosChangedNotification())
+		})
+}
+
+func (s *typedRPCServer) StreamVirtualDaaScoreChanged(request *protowire.NotifyVirtualDaaScoreChangedRequestMessage,
+	stream protowire.TypedRPC_StreamVirtualDaaScoreChangedServer) error {
+
+	return s.stream(stream.Context(),
+		&protowire.KaspadMessage{
+			Payload: &protowire.KaspadMessage_NotifyVirtualDaaScoreChangedRequest{
+				NotifyVirtualDaaScoreChangedRequest: request}},
+		func(response *protowire.KaspadMessage) *protowire.RPCError {
+			return response.GetNotifyVirtualDaaScoreChangedResponse().GetError()
+		},
+		func(notification *protowire.KaspadMessage) error {
+			return stream.Send(notification.GetVirtualDaaScoreChangedNotification())
+		})
+}
+
+func (s *typedRPCServer) StreamBlockHeaderAdded(request *protowire.NotifyBlockHeaderAddedRequestMessage,
+	stream protowire.TypedRPC_StreamBlockHeaderAddedServer) error {
+
+	return s.stream(stream.Context(),
+		&protowire.KaspadMessage{
+			Payload: &protowire.KaspadMessage_NotifyBlockHeaderAddedRequest{NotifyBlockHeaderAddedRequest: request}},
+		func(response *protowire.KaspadMessage) *protowire.RPCError {
+			return response.GetNotifyBlockHeaderAddedResponse().GetError()
+		},
+		func(notification *protowire.KaspadMessage) error {
+			return stream.Send(notification.GetBlockHeaderAddedNotification())
+		})
+}
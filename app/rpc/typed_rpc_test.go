@@ -0,0 +1,160 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
+)
+
+func newTypedRPCServerForTest() *typedRPCServer {
+	return newTypedRPCServer(&rpccontext.Context{
+		NotificationManager: rpccontext.NewNotificationManager(&dagconfig.MainnetParams),
+	})
+}
+
+func TestTypedRPCRequestResponse(t *testing.T) {
+	server := newTypedRPCServerForTest()
+
+	response, err := server.GetBlock(context.Background(), &protowire.GetBlockRequestMessage{Hash: "not a hash"})
+	if err != nil {
+		t.Fatalf("GetBlock: %s", err)
+	}
+	if response.GetError() == nil || !strings.Contains(response.GetError().Message, "could not be parsed") {
+		t.Fatalf("expected a malformed hash error, but got %+v", response)
+	}
+
+	mempoolEntryResponse, err := server.GetMempoolEntry(context.Background(),
+		&protowire.GetMempoolEntryRequestMessage{TxId: "not a transaction ID"})
+	if err != nil {
+		t.Fatalf("GetMempoolEntry: %s", err)
+	}
+	if mempoolEntryResponse.GetError() == nil ||
+		!strings.Contains(mempoolEntryResponse.GetError().Message, "could not be parsed") {
+
+		t.Fatalf("expected a malformed transaction ID error, but got %+v", mempoolEntryResponse)
+	}
+}
+
+// startVirtualDaaScoreChangedStream starts a stream whose client reads its notifications
+// from the returned channel, and waits until the stream is registered for notifications
+func startVirtualDaaScoreChangedStream(t *testing.T, server *typedRPCServer) (
+	notificationChan chan *protowire.VirtualDaaScoreChangedNotificationMessage, cancel func(), streamErrChan chan error) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	notificationChan = make(chan *protowire.VirtualDaaScoreChangedNotificationMessage)
+	isRegistered := make(chan struct{})
+	streamErrChan = make(chan error, 1)
+	go func() {
+		streamErrChan <- server.stream(ctx,
+			&protowire.KaspadMessage{
+				Payload: &protowire.KaspadMessage_NotifyVirtualDaaScoreChangedRequest{
+					NotifyVirtualDaaScoreChangedRequest: &protowire.NotifyVirtualDaaScoreChangedRequestMessage{}}},
+			func(response *protowire.KaspadMessage) *protowire.RPCError {
+				close(isRegistered)
+				return response.GetNotifyVirtualDaaScoreChangedResponse().GetError()
+			},
+			func(notification *protowire.KaspadMessage) error {
+				select {
+				case notificationChan <- notification.GetVirtualDaaScoreChangedNotification():
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+	}()
+
+	select {
+	case <-isRegistered:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the stream wasn't registered for notifications")
+	}
+	return notificationChan, cancel, streamErrChan
+}
+
+func TestTypedRPCStream(t *testing.T) {
+	server := newTypedRPCServerForTest()
+	notificationChan, cancel, streamErrChan := startVirtualDaaScoreChangedStream(t, server)
+
+	for daaScore := uint64(1); daaScore <= 3; daaScore++ {
+		err := server.context.NotificationManager.NotifyVirtualDaaScoreChanged(
+			appmessage.NewVirtualDaaScoreChangedNotificationMessage(daaScore))
+		if err != nil {
+			t.Fatalf("NotifyVirtualDaaScoreChanged: %s", err)
+		}
+		select {
+		case notification := <-notificationChan:
+			if notification.VirtualDaaScore != daaScore {
+				t.Fatalf("expected DAA score %d but got %d", daaScore, notification.VirtualDaaScore)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("the notification wasn't streamed")
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-streamErrChan:
+		if err != nil {
+			t.Fatalf("the stream ended with an error: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the stream didn't end when its context was canceled")
+	}
+}
+
+// TestTypedRPCStreamSlowClient tests that a client that doesn't read its notifications
+// has them dropped once its buffer is full, and doesn't block the notification manager
+func TestTypedRPCStreamSlowClient(t *testing.T) {
+	server := newTypedRPCServerForTest()
+	notificationChan, cancel, streamErrChan := startVirtualDaaScoreChangedStream(t, server)
+	defer cancel()
+
+	// The client stops reading, so all of these pile up in the buffer of the stream
+	const notificationCount = appmessage.MaxInvPerMsg + router.DefaultMaxMessages + 100
+	notifyDone := make(chan struct{})
+	go func() {
+		defer close(notifyDone)
+		for i := 0; i < notificationCount; i++ {
+			err := server.context.NotificationManager.NotifyVirtualDaaScoreChanged(
+				appmessage.NewVirtualDaaScoreChangedNotificationMessage(uint64(i)))
+			if err != nil {
+				t.Errorf("NotifyVirtualDaaScoreChanged: %s", err)
+				return
+			}
+		}
+	}()
+	select {
+	case <-notifyDone:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the notification manager was blocked by a slow client")
+	}
+
+	receivedCount := 0
+	func() {
+		for {
+			select {
+			case <-notificationChan:
+				receivedCount++
+			case <-time.After(100 * time.Millisecond):
+				return
+			}
+		}
+	}()
+	if receivedCount == 0 || receivedCount >= notificationCount {
+		t.Fatalf("expected some but not all of the %d notifications to be streamed, but got %d",
+			notificationCount, receivedCount)
+	}
+
+	cancel()
+	err := <-streamErrChan
+	if err != nil {
+		t.Fatalf("the stream ended with an error: %s", err)
+	}
+}
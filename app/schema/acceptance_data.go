@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+)
+
+// DomainAcceptanceDataToAcceptanceData converts a domain AcceptanceData to AcceptanceData
+func DomainAcceptanceDataToAcceptanceData(domainAcceptanceData externalapi.AcceptanceData) *AcceptanceData {
+	blockAcceptanceData := make([]*BlockAcceptanceData, len(domainAcceptanceData))
+	for i, domainBlockAcceptanceData := range domainAcceptanceData {
+		transactionAcceptanceData := make([]*TransactionAcceptanceData,
+			len(domainBlockAcceptanceData.TransactionAcceptanceData))
+
+		for j, domainTransactionAcceptanceData := range domainBlockAcceptanceData.TransactionAcceptanceData {
+			transactionInputUTXOEntries := make([]*UtxoEntry,
+				len(domainTransactionAcceptanceData.TransactionInputUTXOEntries))
+			for k, domainUTXOEntry := range domainTransactionAcceptanceData.TransactionInputUTXOEntries {
+				transactionInputUTXOEntries[k] = DomainUTXOEntryToUtxoEntry(domainUTXOEntry)
+			}
+
+			transactionAcceptanceData[j] = &TransactionAcceptanceData{
+				Transaction:                 DomainTransactionToTransaction(domainTransactionAcceptanceData.Transaction),
+				Fee:                         domainTransactionAcceptanceData.Fee,
+				IsAccepted:                  domainTransactionAcceptanceData.IsAccepted,
+				TransactionInputUtxoEntries: transactionInputUTXOEntries,
+			}
+		}
+
+		blockAcceptanceData[i] = &BlockAcceptanceData{
+			BlockHash:                 domainBlockAcceptanceData.BlockHash.ByteSlice(),
+			TransactionAcceptanceData: transactionAcceptanceData,
+		}
+	}
+
+	return &AcceptanceData{BlockAcceptanceData: blockAcceptanceData}
+}
+
+// AcceptanceDataToDomainAcceptanceData converts AcceptanceData to a domain AcceptanceData
+func AcceptanceDataToDomainAcceptanceData(acceptanceData *AcceptanceData) (externalapi.AcceptanceData, error) {
+	if acceptanceData == nil {
+		return nil, errors.New("missing acceptance data")
+	}
+	domainAcceptanceData := make(externalapi.AcceptanceData, len(acceptanceData.BlockAcceptanceData))
+	for i, blockAcceptanceData := range acceptanceData.BlockAcceptanceData {
+		if blockAcceptanceData == nil {
+			return nil, errors.Errorf("missing acceptance data of block #%d", i)
+		}
+		blockHash, err := externalapi.NewDomainHashFromByteSlice(blockAcceptanceData.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+
+		domainTransactionAcceptanceData := make([]*externalapi.TransactionAcceptanceData,
+			len(blockAcceptanceData.TransactionAcceptanceData))
+		for j, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+			if transactionAcceptanceData == nil {
+				return nil, errors.Errorf("missing acceptance data of transaction #%d of block %s", j, blockHash)
+			}
+			domainTransaction, err := TransactionToDomainTransaction(transactionAcceptanceData.Transaction)
+			if err != nil {
+				return nil, err
+			}
+
+			domainTransactionInputUTXOEntries := make([]externalapi.UTXOEntry,
+				len(transactionAcceptanceData.TransactionInputUtxoEntries))
+			for k, utxoEntry := range transactionAcceptanceData.TransactionInputUtxoEntries {
+				domainTransactionInputUTXOEntries[k], err = UtxoEntryToDomainUTXOEntry(utxoEntry)
+				if err != nil {
+					return nil, err
+				}
+				// Like the acceptance data that consensus builds, the inputs of accepted
+				// transactions hold the UTXO entries they spent
+				if k < len(domainTransaction.Inputs) {
+					domainTransaction.Inputs[k].UTXOEntry = domainTransactionInputUTXOEntries[k]
+				}
+			}
+
+			domainTransactionAcceptanceData[j] = &externalapi.TransactionAcceptanceData{
+				Transaction:                 domainTransaction,
+				Fee:                         transactionAcceptanceData.Fee,
+				IsAccepted:                  transactionAcceptanceData.IsAccepted,
+				TransactionInputUTXOEntries: domainTransactionInputUTXOEntries,
+			}
+		}
+
+		domainAcceptanceData[i] = &externalapi.BlockAcceptanceData{
+			BlockHash:                 blockHash,
+			TransactionAcceptanceData: domainTransactionAcceptanceData,
+		}
+	}
+	return domainAcceptanceData, nil
+}
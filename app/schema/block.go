@@ -0,0 +1,129 @@
+package schema
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/blockheader"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/pkg/errors"
+)
+
+// DomainBlockToBlock converts DomainBlock to Block
+func DomainBlockToBlock(domainBlock *externalapi.DomainBlock) *Block {
+	transactions := make([]*Transaction, len(domainBlock.Transactions))
+	for i, domainTransaction := range domainBlock.Transactions {
+		transactions[i] = DomainTransactionToTransaction(domainTransaction)
+	}
+	return &Block{
+		Hash:         consensushashing.BlockHash(domainBlock).ByteSlice(),
+		Header:       DomainBlockHeaderToBlockHeader(domainBlock.Header),
+		Transactions: transactions,
+	}
+}
+
+// BlockToDomainBlock converts Block to DomainBlock
+func BlockToDomainBlock(block *Block) (*externalapi.DomainBlock, error) {
+	if block == nil {
+		return nil, errors.New("missing block")
+	}
+	header, err := BlockHeaderToDomainBlockHeader(block.Header)
+	if err != nil {
+		return nil, err
+	}
+	domainTransactions := make([]*externalapi.DomainTransaction, len(block.Transactions))
+	for i, transaction := range block.Transactions {
+		domainTransactions[i], err = TransactionToDomainTransaction(transaction)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &externalapi.DomainBlock{
+		Header:       header,
+		Transactions: domainTransactions,
+	}, nil
+}
+
+// DomainBlockHeaderToBlockHeader converts a domain BlockHeader to BlockHeader
+func DomainBlockHeaderToBlockHeader(domainBlockHeader externalapi.BlockHeader) *BlockHeader {
+	parents := make([]*BlockLevelParents, len(domainBlockHeader.Parents()))
+	for i, domainBlockLevelParents := range domainBlockHeader.Parents() {
+		parentHashes := make([][]byte, len(domainBlockLevelParents))
+		for j, parentHash := range domainBlockLevelParents {
+			parentHashes[j] = parentHash.ByteSlice()
+		}
+		parents[i] = &BlockLevelParents{ParentHashes: parentHashes}
+	}
+
+	return &BlockHeader{
+		Version:              uint32(domainBlockHeader.Version()),
+		Parents:              parents,
+		HashMerkleRoot:       domainBlockHeader.HashMerkleRoot().ByteSlice(),
+		AcceptedIdMerkleRoot: domainBlockHeader.AcceptedIDMerkleRoot().ByteSlice(),
+		UtxoCommitment:       domainBlockHeader.UTXOCommitment().ByteSlice(),
+		Timestamp:            domainBlockHeader.TimeInMilliseconds(),
+		Bits:                 domainBlockHeader.Bits(),
+		Nonce:                domainBlockHeader.Nonce(),
+		DaaScore:             domainBlockHeader.DAAScore(),
+		BlueScore:            domainBlockHeader.BlueScore(),
+		BlueWork:             domainBlockHeader.BlueWork().Bytes(),
+		PruningPoint:         domainBlockHeader.PruningPoint().ByteSlice(),
+	}
+}
+
+// BlockHeaderToDomainBlockHeader converts BlockHeader to a domain BlockHeader
+func BlockHeaderToDomainBlockHeader(header *BlockHeader) (externalapi.BlockHeader, error) {
+	if header == nil {
+		return nil, errors.New("missing block header")
+	}
+	if header.Version > math.MaxUint16 {
+		return nil, errors.Errorf("block header version %d is bigger than uint16", header.Version)
+	}
+
+	parents := make([]externalapi.BlockLevelParents, len(header.Parents))
+	for i, blockLevelParents := range header.Parents {
+		if blockLevelParents == nil {
+			return nil, errors.Errorf("missing parents of level %d", i)
+		}
+		parents[i] = make(externalapi.BlockLevelParents, len(blockLevelParents.ParentHashes))
+		for j, parentHash := range blockLevelParents.ParentHashes {
+			var err error
+			parents[i][j], err = externalapi.NewDomainHashFromByteSlice(parentHash)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	hashMerkleRoot, err := externalapi.NewDomainHashFromByteSlice(header.HashMerkleRoot)
+	if err != nil {
+		return nil, err
+	}
+	acceptedIDMerkleRoot, err := externalapi.NewDomainHashFromByteSlice(header.AcceptedIdMerkleRoot)
+	if err != nil {
+		return nil, err
+	}
+	utxoCommitment, err := externalapi.NewDomainHashFromByteSlice(header.UtxoCommitment)
+	if err != nil {
+		return nil, err
+	}
+	pruningPoint, err := externalapi.NewDomainHashFromByteSlice(header.PruningPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return blockheader.NewImmutableBlockHeader(
+		uint16(header.Version),
+		parents,
+		hashMerkleRoot,
+		acceptedIDMerkleRoot,
+		utxoCommitment,
+		header.Timestamp,
+		header.Bits,
+		header.Nonce,
+		header.DaaScore,
+		header.BlueScore,
+		new(big.Int).SetBytes(header.BlueWork),
+		pruningPoint,
+	), nil
+}
@@ -0,0 +1,3 @@
+//go:generate protoc --go_out=. --go_opt=paths=source_relative schema.proto
+
+package schema
@@ -0,0 +1,123 @@
+package schema
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBlockRoundTrip(t *testing.T) {
+	domainBlock := dagconfig.MainnetParams.GenesisBlock
+
+	block := DomainBlockToBlock(domainBlock)
+	if !bytes.Equal(block.Hash, dagconfig.MainnetParams.GenesisHash.ByteSlice()) {
+		t.Fatalf("Expected the block hash to be the genesis hash")
+	}
+	serialized, err := proto.Marshal(block)
+	if err != nil {
+		t.Fatalf("Marshal: %s", err)
+	}
+	deserialized := &Block{}
+	err = proto.Unmarshal(serialized, deserialized)
+	if err != nil {
+		t.Fatalf("Unmarshal: %s", err)
+	}
+	roundTripBlock, err := BlockToDomainBlock(deserialized)
+	if err != nil {
+		t.Fatalf("BlockToDomainBlock: %+v", err)
+	}
+	if !roundTripBlock.Equal(domainBlock) {
+		t.Fatalf("Expected the block to survive a round trip")
+	}
+
+	// A hash of the wrong length is rejected rather than truncated
+	deserialized.Header.PruningPoint = deserialized.Header.PruningPoint[1:]
+	_, err = BlockToDomainBlock(deserialized)
+	if err == nil {
+		t.Fatalf("Expected a malformed pruning point to be rejected")
+	}
+	deserialized.Header = nil
+	_, err = BlockToDomainBlock(deserialized)
+	if err == nil {
+		t.Fatalf("Expected a block without a header to be rejected")
+	}
+}
+
+func TestAcceptanceDataRoundTrip(t *testing.T) {
+	scriptPublicKey := &externalapi.ScriptPublicKey{Script: []byte{1, 2, 3}, Version: 0}
+	utxoEntry := utxo.NewUTXOEntry(5000, scriptPublicKey, true, 7)
+	transaction := &externalapi.DomainTransaction{
+		Version: 0,
+		Inputs: []*externalapi.DomainTransactionInput{{
+			PreviousOutpoint: *externalapi.NewDomainOutpoint(&externalapi.DomainTransactionID{}, 2),
+			SignatureScript:  []byte{4, 5},
+			Sequence:         3,
+			SigOpCount:       1,
+			UTXOEntry:        utxoEntry,
+		}},
+		Outputs: []*externalapi.DomainTransactionOutput{{
+			Value:           4000,
+			ScriptPublicKey: scriptPublicKey,
+		}},
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+		Payload:      []byte{},
+	}
+	rejectedTransaction := transaction.Clone()
+	rejectedTransaction.Inputs[0].Sequence = 4
+	rejectedTransaction.Inputs[0].UTXOEntry = nil
+	domainAcceptanceData := externalapi.AcceptanceData{{
+		BlockHash: &externalapi.DomainHash{},
+		TransactionAcceptanceData: []*externalapi.TransactionAcceptanceData{
+			{
+				Transaction:                 transaction,
+				Fee:                         1000,
+				IsAccepted:                  true,
+				TransactionInputUTXOEntries: []externalapi.UTXOEntry{utxoEntry},
+			},
+			{
+				Transaction:                 rejectedTransaction,
+				IsAccepted:                  false,
+				TransactionInputUTXOEntries: []externalapi.UTXOEntry{},
+			},
+		},
+	}}
+
+	acceptanceData := DomainAcceptanceDataToAcceptanceData(domainAcceptanceData)
+	transactionID := consensushashing.TransactionID(transaction)
+	if !bytes.Equal(acceptanceData.BlockAcceptanceData[0].TransactionAcceptanceData[0].Transaction.Id,
+		transactionID.ByteSlice()) {
+		t.Fatalf("Expected the transaction ID to be %s", transactionID)
+	}
+	serialized, err := proto.Marshal(acceptanceData)
+	if err != nil {
+		t.Fatalf("Marshal: %s", err)
+	}
+	deserialized := &AcceptanceData{}
+	err = proto.Unmarshal(serialized, deserialized)
+	if err != nil {
+		t.Fatalf("Unmarshal: %s", err)
+	}
+	roundTripAcceptanceData, err := AcceptanceDataToDomainAcceptanceData(deserialized)
+	if err != nil {
+		t.Fatalf("AcceptanceDataToDomainAcceptanceData: %+v", err)
+	}
+	if !roundTripAcceptanceData.Equal(domainAcceptanceData) {
+		t.Fatalf("Expected the acceptance data to survive a round trip")
+	}
+
+	pair := &externalapi.OutpointAndUTXOEntryPair{Outpoint: &transaction.Inputs[0].PreviousOutpoint, UTXOEntry: utxoEntry}
+	roundTripPair, err := OutpointAndUtxoEntryToDomainOutpointAndUTXOEntryPair(
+		DomainOutpointAndUTXOEntryPairToOutpointAndUtxoEntry(pair))
+	if err != nil {
+		t.Fatalf("OutpointAndUtxoEntryToDomainOutpointAndUTXOEntryPair: %+v", err)
+	}
+	if !roundTripPair.Outpoint.Equal(pair.Outpoint) || !roundTripPair.UTXOEntry.Equal(pair.UTXOEntry) {
+		t.Fatalf("Expected the outpoint and UTXO entry to survive a round trip")
+	}
+}
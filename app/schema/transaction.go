@@ -0,0 +1,143 @@
+package schema
+
+import (
+	"math"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/pkg/errors"
+)
+
+// DomainTransactionToTransaction converts DomainTransaction to Transaction
+func DomainTransactionToTransaction(domainTransaction *externalapi.DomainTransaction) *Transaction {
+	inputs := make([]*TransactionInput, len(domainTransaction.Inputs))
+	for i, domainTransactionInput := range domainTransaction.Inputs {
+		inputs[i] = &TransactionInput{
+			PreviousOutpoint: DomainOutpointToOutpoint(&domainTransactionInput.PreviousOutpoint),
+			SignatureScript:  domainTransactionInput.SignatureScript,
+			Sequence:         domainTransactionInput.Sequence,
+			SigOpCount:       uint32(domainTransactionInput.SigOpCount),
+		}
+	}
+
+	outputs := make([]*TransactionOutput, len(domainTransaction.Outputs))
+	for i, domainTransactionOutput := range domainTransaction.Outputs {
+		outputs[i] = &TransactionOutput{
+			Amount:          domainTransactionOutput.Value,
+			ScriptPublicKey: DomainScriptPublicKeyToScriptPublicKey(domainTransactionOutput.ScriptPublicKey),
+		}
+	}
+
+	return &Transaction{
+		Id:           consensushashing.TransactionID(domainTransaction).ByteSlice(),
+		Version:      uint32(domainTransaction.Version),
+		Inputs:       inputs,
+		Outputs:      outputs,
+		LockTime:     domainTransaction.LockTime,
+		SubnetworkId: domainTransaction.SubnetworkID[:],
+		Gas:          domainTransaction.Gas,
+		Payload:      domainTransaction.Payload,
+	}
+}
+
+// TransactionToDomainTransaction converts Transaction to DomainTransaction
+func TransactionToDomainTransaction(transaction *Transaction) (*externalapi.DomainTransaction, error) {
+	if transaction == nil {
+		return nil, errors.New("missing transaction")
+	}
+	if transaction.Version > math.MaxUint16 {
+		return nil, errors.Errorf("transaction version %d is bigger than uint16", transaction.Version)
+	}
+	subnetworkID, err := subnetworks.FromBytes(transaction.SubnetworkId)
+	if err != nil {
+		return nil, err
+	}
+
+	domainInputs := make([]*externalapi.DomainTransactionInput, len(transaction.Inputs))
+	for i, input := range transaction.Inputs {
+		if input == nil {
+			return nil, errors.Errorf("missing input #%d", i)
+		}
+		if input.SigOpCount > math.MaxUint8 {
+			return nil, errors.Errorf("sig op count %d of input #%d is bigger than uint8", input.SigOpCount, i)
+		}
+		previousOutpoint, err := OutpointToDomainOutpoint(input.PreviousOutpoint)
+		if err != nil {
+			return nil, err
+		}
+		domainInputs[i] = &externalapi.DomainTransactionInput{
+			PreviousOutpoint: *previousOutpoint,
+			SignatureScript:  input.SignatureScript,
+			Sequence:         input.Sequence,
+			SigOpCount:       byte(input.SigOpCount),
+		}
+	}
+
+	domainOutputs := make([]*externalapi.DomainTransactionOutput, len(transaction.Outputs))
+	for i, output := range transaction.Outputs {
+		if output == nil {
+			return nil, errors.Errorf("missing output #%d", i)
+		}
+		scriptPublicKey, err := ScriptPublicKeyToDomainScriptPublicKey(output.ScriptPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		domainOutputs[i] = &externalapi.DomainTransactionOutput{
+			Value:           output.Amount,
+			ScriptPublicKey: scriptPublicKey,
+		}
+	}
+
+	return &externalapi.DomainTransaction{
+		Version:      uint16(transaction.Version),
+		Inputs:       domainInputs,
+		Outputs:      domainOutputs,
+		LockTime:     transaction.LockTime,
+		SubnetworkID: *subnetworkID,
+		Gas:          transaction.Gas,
+		Payload:      transaction.Payload,
+	}, nil
+}
+
+// DomainOutpointToOutpoint converts DomainOutpoint to Outpoint
+func DomainOutpointToOutpoint(domainOutpoint *externalapi.DomainOutpoint) *Outpoint {
+	return &Outpoint{
+		TransactionId: domainOutpoint.TransactionID.ByteSlice(),
+		Index:         domainOutpoint.Index,
+	}
+}
+
+// OutpointToDomainOutpoint converts Outpoint to DomainOutpoint
+func OutpointToDomainOutpoint(outpoint *Outpoint) (*externalapi.DomainOutpoint, error) {
+	if outpoint == nil {
+		return nil, errors.New("missing outpoint")
+	}
+	transactionID, err := externalapi.NewDomainTransactionIDFromByteSlice(outpoint.TransactionId)
+	if err != nil {
+		return nil, err
+	}
+	return externalapi.NewDomainOutpoint(transactionID, outpoint.Index), nil
+}
+
+// DomainScriptPublicKeyToScriptPublicKey converts a domain ScriptPublicKey to ScriptPublicKey
+func DomainScriptPublicKeyToScriptPublicKey(domainScriptPublicKey *externalapi.ScriptPublicKey) *ScriptPublicKey {
+	return &ScriptPublicKey{
+		Version: uint32(domainScriptPublicKey.Version),
+		Script:  domainScriptPublicKey.Script,
+	}
+}
+
+// ScriptPublicKeyToDomainScriptPublicKey converts ScriptPublicKey to a domain ScriptPublicKey
+func ScriptPublicKeyToDomainScriptPublicKey(scriptPublicKey *ScriptPublicKey) (*externalapi.ScriptPublicKey, error) {
+	if scriptPublicKey == nil {
+		return nil, errors.New("missing script public key")
+	}
+	if scriptPublicKey.Version > math.MaxUint16 {
+		return nil, errors.Errorf("script public key version %d is bigger than uint16", scriptPublicKey.Version)
+	}
+	return &externalapi.ScriptPublicKey{
+		Version: uint16(scriptPublicKey.Version),
+		Script:  scriptPublicKey.Script,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package schema
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/pkg/errors"
+)
+
+// DomainUTXOEntryToUtxoEntry converts UTXOEntry to UtxoEntry
+func DomainUTXOEntryToUtxoEntry(domainUTXOEntry externalapi.UTXOEntry) *UtxoEntry {
+	return &UtxoEntry{
+		Amount:          domainUTXOEntry.Amount(),
+		ScriptPublicKey: DomainScriptPublicKeyToScriptPublicKey(domainUTXOEntry.ScriptPublicKey()),
+		BlockDaaScore:   domainUTXOEntry.BlockDAAScore(),
+		IsCoinbase:      domainUTXOEntry.IsCoinbase(),
+	}
+}
+
+// UtxoEntryToDomainUTXOEntry converts UtxoEntry to UTXOEntry
+func UtxoEntryToDomainUTXOEntry(utxoEntry *UtxoEntry) (externalapi.UTXOEntry, error) {
+	if utxoEntry == nil {
+		return nil, errors.New("missing UTXO entry")
+	}
+	scriptPublicKey, err := ScriptPublicKeyToDomainScriptPublicKey(utxoEntry.ScriptPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return utxo.NewUTXOEntry(utxoEntry.Amount, scriptPublicKey, utxoEntry.IsCoinbase, utxoEntry.BlockDaaScore), nil
+}
+
+// DomainOutpointAndUTXOEntryPairToOutpointAndUtxoEntry converts OutpointAndUTXOEntryPair to OutpointAndUtxoEntry
+func DomainOutpointAndUTXOEntryPairToOutpointAndUtxoEntry(
+	domainPair *externalapi.OutpointAndUTXOEntryPair) *OutpointAndUtxoEntry {
+
+	return &OutpointAndUtxoEntry{
+		Outpoint:  DomainOutpointToOutpoint(domainPair.Outpoint),
+		UtxoEntry: DomainUTXOEntryToUtxoEntry(domainPair.UTXOEntry),
+	}
+}
+
+// OutpointAndUtxoEntryToDomainOutpointAndUTXOEntryPair converts OutpointAndUtxoEntry to OutpointAndUTXOEntryPair
+func OutpointAndUtxoEntryToDomainOutpointAndUTXOEntryPair(
+	pair *OutpointAndUtxoEntry) (*externalapi.OutpointAndUTXOEntryPair, error) {
+
+	if pair == nil {
+		return nil, errors.New("missing outpoint and UTXO entry")
+	}
+	outpoint, err := OutpointToDomainOutpoint(pair.Outpoint)
+	if err != nil {
+		return nil, err
+	}
+	utxoEntry, err := UtxoEntryToDomainUTXOEntry(pair.UtxoEntry)
+	if err != nil {
+		return nil, err
+	}
+	return &externalapi.OutpointAndUTXOEntryPair{
+		Outpoint:  outpoint,
+		UTXOEntry: utxoEntry,
+	}, nil
+}
@@ -0,0 +1,9 @@
+package sqlmirror
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/panics"
+)
+
+var log = logger.RegisterSubSystem("SQLM")
+var spawn = panics.GoroutineWrapperFunc(log)
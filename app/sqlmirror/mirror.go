@@ -0,0 +1,456 @@
+package sqlmirror
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+const (
+	// chainBlocksPerTransaction is the number of chain blocks whose changes are written
+	// in a single SQL transaction, along with the checkpoint that follows them
+	chainBlocksPerTransaction = 100
+
+	queryTimeout  = 2 * time.Minute
+	retryInterval = 10 * time.Second
+)
+
+// Mirror mirrors the blocks merged by the selected chain, along with their transactions
+// and the acceptance data of the chain, to a PostgreSQL database.
+//
+// The mirror keeps the last chain block it mirrored as a checkpoint in the database, and
+// follows the virtual selected parent chain from it. This way it catches up on whatever
+// it missed while the node or the database were down, and it handles reorgs the same way:
+// the chain blocks that were removed from the chain are unmarked, and their acceptance
+// data is deleted, in the same SQL transaction that adds the new chain blocks.
+type Mirror struct {
+	connConfig *pgx.ConnConfig
+	domain     domain.Domain
+	dagParams  *dagconfig.Params
+
+	conn       *pgx.Conn
+	checkpoint *externalapi.DomainHash
+
+	virtualChanged chan struct{}
+	quit           chan struct{}
+	stopOnce       sync.Once
+	stopped        sync.WaitGroup
+}
+
+// New creates a new Mirror to the database at the given connection URL. Use Start()
+// to begin mirroring.
+func New(connectionURL string, dagParams *dagconfig.Params, domain domain.Domain) (*Mirror, error) {
+	connConfig, err := parsePostgresURL(connectionURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Mirror{
+		connConfig:     connConfig,
+		domain:         domain,
+		dagParams:      dagParams,
+		virtualChanged: make(chan struct{}, 1),
+		quit:           make(chan struct{}),
+	}, nil
+}
+
+// Start starts mirroring in the background. The mirror connects to the database,
+// catches up with the selected chain, and then follows it as it changes.
+func (m *Mirror) Start() {
+	m.stopped.Add(1)
+	spawn("sqlmirror.Mirror.run", m.run)
+}
+
+// Stop stops mirroring once the SQL transaction in progress, if any, is done
+func (m *Mirror) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.quit)
+		m.stopped.Wait()
+	})
+}
+
+// NotifyVirtualChange notifies the mirror that the virtual selected parent chain may
+// have changed. It never blocks: notifications that arrive while the mirror is busy are
+// coalesced, since the mirror always follows the chain from its checkpoint.
+func (m *Mirror) NotifyVirtualChange() {
+	select {
+	case m.virtualChanged <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Mirror) run() {
+	defer m.stopped.Done()
+	defer m.disconnect()
+
+	for {
+		err := m.sync()
+		if err != nil {
+			log.Errorf("Error mirroring to PostgreSQL at %s, retrying in %s: %s",
+				postgresAddress(m.connConfig), retryInterval, err)
+			m.disconnect()
+			select {
+			case <-m.quit:
+				return
+			case <-time.After(retryInterval):
+			}
+			continue
+		}
+
+		select {
+		case <-m.quit:
+			return
+		case <-m.virtualChanged:
+		}
+	}
+}
+
+func (m *Mirror) disconnect() {
+	if m.conn != nil {
+		closePostgres(m.conn)
+		m.conn = nil
+	}
+}
+
+func (m *Mirror) connect() error {
+	conn, err := connectPostgres(m.connConfig)
+	if err != nil {
+		return err
+	}
+	m.conn = conn
+
+	err = execute(m.conn, schemaStatements, queryTimeout)
+	if err != nil {
+		return err
+	}
+	m.checkpoint, err = m.selectCheckpoint()
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Connected to PostgreSQL at %s", postgresAddress(m.connConfig))
+	return nil
+}
+
+// selectCheckpoint returns the checkpoint of the database, or nil if nothing was mirrored to it yet
+func (m *Mirror) selectCheckpoint() (*externalapi.DomainHash, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	var network, chainBlockHash string
+	err := m.conn.QueryRow(ctx, selectMirrorStateStatement).Scan(&network, &chainBlockHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if network != m.dagParams.Name {
+		return nil, errors.Errorf("the database mirrors %s rather than %s", network, m.dagParams.Name)
+	}
+	return externalapi.NewDomainHashFromString(chainBlockHash)
+}
+
+// sync mirrors the changes of the selected chain since the checkpoint
+func (m *Mirror) sync() error {
+	if m.conn == nil {
+		err := m.connect()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := m.validateCheckpoint()
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-m.quit:
+			return nil
+		default:
+		}
+
+		chainPath, err := m.domain.Consensus().GetVirtualSelectedParentChainFromBlock(m.checkpoint)
+		if err != nil {
+			return err
+		}
+		if len(chainPath.Added) == 0 && len(chainPath.Removed) == 0 {
+			return nil
+		}
+
+		added := chainPath.Added
+		isCatchingUp := len(added) > chainBlocksPerTransaction
+		if isCatchingUp {
+			added = added[:chainBlocksPerTransaction]
+		}
+		blueScore, err := m.mirrorChainChanges(chainPath.Removed, added)
+		if err != nil {
+			return err
+		}
+		if isCatchingUp {
+			log.Infof("Mirrored the selected chain up to blue score %d, %d chain blocks remaining",
+				blueScore, len(chainPath.Added)-len(added))
+		}
+	}
+}
+
+// validateCheckpoint starts mirroring from the pruning point if there's no checkpoint,
+// or if the checkpoint is no longer known to consensus, such as after the node resynced
+func (m *Mirror) validateCheckpoint() error {
+	consensus := m.domain.Consensus()
+	pruningPoint, err := consensus.PruningPoint()
+	if err != nil {
+		return err
+	}
+	if m.checkpoint == nil {
+		log.Infof("Mirroring the selected chain from the pruning point %s", pruningPoint)
+		m.checkpoint = pruningPoint
+		return nil
+	}
+
+	checkpointInfo, err := consensus.GetBlockInfo(m.checkpoint)
+	if err != nil {
+		return err
+	}
+	pruningPointInfo, err := consensus.GetBlockInfo(pruningPoint)
+	if err != nil {
+		return err
+	}
+	if !checkpointInfo.HasBody() || checkpointInfo.BlueScore < pruningPointInfo.BlueScore {
+		log.Warnf("The mirrored chain block %s is below the pruning point or unknown, so mirroring "+
+			"restarts from the pruning point %s. Blocks that are no longer in the selected chain may "+
+			"remain marked as chain blocks.", m.checkpoint, pruningPoint)
+		m.checkpoint = pruningPoint
+	}
+	return nil
+}
+
+// chainChanges accumulates the statements that mirror a batch of chain changes
+type chainChanges struct {
+	blocks            *insertStatement
+	blockParents      *insertStatement
+	transactions      *insertStatement
+	blockTransactions *insertStatement
+	inputs            *insertStatement
+	outputs           *insertStatement
+	acceptances       *insertStatement
+
+	mirroredBlocks       map[externalapi.DomainHash]struct{}
+	mirroredTransactions map[externalapi.DomainTransactionID]struct{}
+}
+
+func newChainChanges() *chainChanges {
+	return &chainChanges{
+		blocks: newInsertStatement("blocks", "DO NOTHING", "hash", "version", "timestamp", "bits",
+			"nonce", "daa_score", "blue_score", "blue_work", "selected_parent_hash"),
+		blockParents: newInsertStatement("block_parents", "DO NOTHING", "block_hash", "parent_hash"),
+		transactions: newInsertStatement("transactions", "DO NOTHING", "transaction_id", "hash", "version",
+			"lock_time", "subnetwork_id", "gas", "payload"),
+		blockTransactions: newInsertStatement("block_transactions", "DO NOTHING",
+			"block_hash", "transaction_id", "index"),
+		inputs: newInsertStatement("transaction_inputs", "DO NOTHING", "transaction_id", "index",
+			"previous_transaction_id", "previous_index", "signature_script", "sequence", "sig_op_count"),
+		outputs: newInsertStatement("transaction_outputs", "DO NOTHING", "transaction_id", "index",
+			"amount", "script_public_key_version", "script_public_key", "address"),
+		acceptances: newInsertStatement("transaction_acceptances",
+			"(transaction_id) DO UPDATE SET block_hash = EXCLUDED.block_hash, "+
+				"accepting_block_hash = EXCLUDED.accepting_block_hash, fee = EXCLUDED.fee",
+			"transaction_id", "block_hash", "accepting_block_hash", "fee"),
+		mirroredBlocks:       make(map[externalapi.DomainHash]struct{}),
+		mirroredTransactions: make(map[externalapi.DomainTransactionID]struct{}),
+	}
+}
+
+// mirrorChainChanges writes the given changes of the selected chain, and moves the
+// checkpoint to the last added chain block, in a single SQL transaction. It returns
+// the blue score of the new checkpoint.
+func (m *Mirror) mirrorChainChanges(removed []*externalapi.DomainHash, added []*externalapi.DomainHash) (uint64, error) {
+	consensus := m.domain.Consensus()
+	changes := newChainChanges()
+
+	for _, chainBlockHash := range added {
+		// A chain block is mirrored along with its merge set, whose transactions it accepts
+		err := m.addBlock(changes, chainBlockHash)
+		if err != nil {
+			return 0, err
+		}
+		acceptanceData, err := consensus.GetBlockAcceptanceData(chainBlockHash)
+		if err != nil {
+			return 0, err
+		}
+		for _, blockAcceptanceData := range acceptanceData {
+			err := m.addBlock(changes, blockAcceptanceData.BlockHash)
+			if err != nil {
+				return 0, err
+			}
+			for _, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+				if !transactionAcceptanceData.IsAccepted {
+					continue
+				}
+				changes.acceptances.addRow(
+					sqlString(consensushashing.TransactionID(transactionAcceptanceData.Transaction).String()),
+					sqlString(blockAcceptanceData.BlockHash.String()),
+					sqlString(chainBlockHash.String()),
+					sqlUint64(transactionAcceptanceData.Fee),
+				)
+			}
+		}
+	}
+
+	// Once the chain moves back to a common ancestor without moving forward, it becomes the checkpoint
+	newCheckpoint := m.checkpoint
+	if len(added) > 0 {
+		newCheckpoint = added[len(added)-1]
+	} else if len(removed) > 0 {
+		lastRemovedInfo, err := consensus.GetBlockInfo(removed[len(removed)-1])
+		if err != nil {
+			return 0, err
+		}
+		newCheckpoint = lastRemovedInfo.SelectedParent
+	}
+	newCheckpointInfo, err := consensus.GetBlockInfo(newCheckpoint)
+	if err != nil {
+		return 0, err
+	}
+
+	statements := &strings.Builder{}
+	statements.WriteString("BEGIN;\n")
+	if len(removed) > 0 {
+		removedHashes := sqlStringList(hashStrings(removed))
+		statements.WriteString("UPDATE blocks SET is_chain_block = FALSE WHERE hash IN " + removedHashes + ";\n")
+		statements.WriteString("DELETE FROM transaction_acceptances WHERE accepting_block_hash IN " + removedHashes + ";\n")
+	}
+	for _, statement := range []*insertStatement{changes.blocks, changes.blockParents, changes.transactions,
+		changes.blockTransactions, changes.inputs, changes.outputs, changes.acceptances} {
+
+		statement.writeTo(statements)
+	}
+	if len(added) > 0 {
+		statements.WriteString("UPDATE blocks SET is_chain_block = TRUE WHERE hash IN " +
+			sqlStringList(hashStrings(added)) + ";\n")
+	}
+	mirrorState := newInsertStatement("mirror_state",
+		"(id) DO UPDATE SET chain_block_hash = EXCLUDED.chain_block_hash, blue_score = EXCLUDED.blue_score",
+		"network", "chain_block_hash", "blue_score")
+	mirrorState.addRow(sqlString(m.dagParams.Name), sqlString(newCheckpoint.String()),
+		sqlUint64(newCheckpointInfo.BlueScore))
+	mirrorState.writeTo(statements)
+	statements.WriteString("COMMIT;\n")
+
+	err = execute(m.conn, statements.String(), queryTimeout)
+	if err != nil {
+		return 0, err
+	}
+	m.checkpoint = newCheckpoint
+	return newCheckpointInfo.BlueScore, nil
+}
+
+// addBlock adds the given block and its transactions to the changes, unless they were already added
+func (m *Mirror) addBlock(changes *chainChanges, blockHash *externalapi.DomainHash) error {
+	if _, ok := changes.mirroredBlocks[*blockHash]; ok {
+		return nil
+	}
+	changes.mirroredBlocks[*blockHash] = struct{}{}
+
+	consensus := m.domain.Consensus()
+	block, found, err := consensus.GetBlock(blockHash)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.Errorf("block %s not found", blockHash)
+	}
+	blockInfo, err := consensus.GetBlockInfo(blockHash)
+	if err != nil {
+		return err
+	}
+	// The genesis has no selected parent
+	selectedParentHash := ""
+	if blockInfo.SelectedParent != nil {
+		selectedParentHash = blockInfo.SelectedParent.String()
+	}
+
+	header := block.Header
+	blockHashString := blockHash.String()
+	changes.blocks.addRow(
+		sqlString(blockHashString),
+		sqlUint64(uint64(header.Version())),
+		sqlUint64(uint64(header.TimeInMilliseconds())),
+		sqlUint64(uint64(header.Bits())),
+		sqlUint64(header.Nonce()),
+		sqlUint64(header.DAAScore()),
+		sqlUint64(header.BlueScore()),
+		sqlString(header.BlueWork().Text(16)),
+		sqlString(selectedParentHash),
+	)
+	for _, parentHash := range header.DirectParents() {
+		changes.blockParents.addRow(sqlString(blockHashString), sqlString(parentHash.String()))
+	}
+
+	for i, transaction := range block.Transactions {
+		transactionID := consensushashing.TransactionID(transaction)
+		transactionIDString := sqlString(transactionID.String())
+		changes.blockTransactions.addRow(sqlString(blockHashString), transactionIDString, sqlUint64(uint64(i)))
+
+		// A transaction may be included in more than one block
+		if _, ok := changes.mirroredTransactions[*transactionID]; ok {
+			continue
+		}
+		changes.mirroredTransactions[*transactionID] = struct{}{}
+
+		changes.transactions.addRow(
+			transactionIDString,
+			sqlString(consensushashing.TransactionHash(transaction).String()),
+			sqlUint64(uint64(transaction.Version)),
+			sqlUint64(transaction.LockTime),
+			sqlString(transaction.SubnetworkID.String()),
+			sqlUint64(transaction.Gas),
+			sqlString(hex.EncodeToString(transaction.Payload)),
+		)
+		for j, input := range transaction.Inputs {
+			changes.inputs.addRow(
+				transactionIDString,
+				sqlUint64(uint64(j)),
+				sqlString(input.PreviousOutpoint.TransactionID.String()),
+				sqlUint64(uint64(input.PreviousOutpoint.Index)),
+				sqlString(hex.EncodeToString(input.SignatureScript)),
+				sqlUint64(input.Sequence),
+				sqlUint64(uint64(input.SigOpCount)),
+			)
+		}
+		for j, output := range transaction.Outputs {
+			address := ""
+			_, extractedAddress, err := txscript.ExtractScriptPubKeyAddress(output.ScriptPublicKey, m.dagParams)
+			if err == nil && extractedAddress != nil {
+				address = extractedAddress.String()
+			}
+			changes.outputs.addRow(
+				transactionIDString,
+				sqlUint64(uint64(j)),
+				sqlUint64(output.Value),
+				sqlUint64(uint64(output.ScriptPublicKey.Version)),
+				sqlString(hex.EncodeToString(output.ScriptPublicKey.Script)),
+				sqlString(address),
+			)
+		}
+	}
+	return nil
+}
+
+func hashStrings(hashes []*externalapi.DomainHash) []string {
+	strs := make([]string, len(hashes))
+	for i, hash := range hashes {
+		strs[i] = hash.String()
+	}
+	return strs
+}
@@ -0,0 +1,157 @@
+package sqlmirror
+
+import (
+	"strconv"
+	"strings"
+)
+
+// schemaStatements create the tables of the mirror if they don't exist yet. Hashes,
+// scripts and payloads are stored as hex strings, and timestamps as milliseconds since
+// the epoch. The uint64 values that may exceed the range of BIGINT are stored as NUMERIC.
+// The schema is documented in README.md, and has to be kept in sync with it.
+const schemaStatements = `
+CREATE TABLE IF NOT EXISTS blocks (
+	hash TEXT PRIMARY KEY,
+	version INTEGER NOT NULL,
+	timestamp BIGINT NOT NULL,
+	bits BIGINT NOT NULL,
+	nonce NUMERIC(20) NOT NULL,
+	daa_score BIGINT NOT NULL,
+	blue_score BIGINT NOT NULL,
+	blue_work TEXT NOT NULL,
+	selected_parent_hash TEXT NOT NULL,
+	is_chain_block BOOLEAN NOT NULL DEFAULT FALSE
+);
+CREATE INDEX IF NOT EXISTS blocks_blue_score ON blocks (blue_score);
+
+CREATE TABLE IF NOT EXISTS block_parents (
+	block_hash TEXT NOT NULL,
+	parent_hash TEXT NOT NULL,
+	PRIMARY KEY (block_hash, parent_hash)
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+	transaction_id TEXT PRIMARY KEY,
+	hash TEXT NOT NULL,
+	version INTEGER NOT NULL,
+	lock_time NUMERIC(20) NOT NULL,
+	subnetwork_id TEXT NOT NULL,
+	gas NUMERIC(20) NOT NULL,
+	payload TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS block_transactions (
+	block_hash TEXT NOT NULL,
+	transaction_id TEXT NOT NULL,
+	index INTEGER NOT NULL,
+	PRIMARY KEY (block_hash, transaction_id)
+);
+CREATE INDEX IF NOT EXISTS block_transactions_transaction_id ON block_transactions (transaction_id);
+
+CREATE TABLE IF NOT EXISTS transaction_inputs (
+	transaction_id TEXT NOT NULL,
+	index INTEGER NOT NULL,
+	previous_transaction_id TEXT NOT NULL,
+	previous_index INTEGER NOT NULL,
+	signature_script TEXT NOT NULL,
+	sequence NUMERIC(20) NOT NULL,
+	sig_op_count INTEGER NOT NULL,
+	PRIMARY KEY (transaction_id, index)
+);
+CREATE INDEX IF NOT EXISTS transaction_inputs_previous_outpoint
+	ON transaction_inputs (previous_transaction_id, previous_index);
+
+CREATE TABLE IF NOT EXISTS transaction_outputs (
+	transaction_id TEXT NOT NULL,
+	index INTEGER NOT NULL,
+	amount BIGINT NOT NULL,
+	script_public_key_version INTEGER NOT NULL,
+	script_public_key TEXT NOT NULL,
+	address TEXT NOT NULL,
+	PRIMARY KEY (transaction_id, index)
+);
+CREATE INDEX IF NOT EXISTS transaction_outputs_address ON transaction_outputs (address);
+
+CREATE TABLE IF NOT EXISTS transaction_acceptances (
+	transaction_id TEXT PRIMARY KEY,
+	block_hash TEXT NOT NULL,
+	accepting_block_hash TEXT NOT NULL,
+	fee BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS transaction_acceptances_accepting_block_hash
+	ON transaction_acceptances (accepting_block_hash);
+
+CREATE TABLE IF NOT EXISTS mirror_state (
+	id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+	network TEXT NOT NULL,
+	chain_block_hash TEXT NOT NULL,
+	blue_score BIGINT NOT NULL
+);
+`
+
+const selectMirrorStateStatement = "SELECT network, chain_block_hash FROM mirror_state"
+
+// insertStatement builds a multi-row INSERT statement
+type insertStatement struct {
+	table      string
+	columns    []string
+	onConflict string
+	rows       []string
+}
+
+// newInsertStatement returns an insertStatement for the given table and columns.
+// onConflict is the ON CONFLICT clause of the statement.
+func newInsertStatement(table string, onConflict string, columns ...string) *insertStatement {
+	return &insertStatement{
+		table:      table,
+		columns:    columns,
+		onConflict: onConflict,
+	}
+}
+
+// addRow adds a row of values, which are expected to be SQL literals, to the statement
+func (is *insertStatement) addRow(values ...string) {
+	is.rows = append(is.rows, "("+strings.Join(values, ", ")+")")
+}
+
+// writeTo writes the statement to the given builder, unless it has no rows
+func (is *insertStatement) writeTo(builder *strings.Builder) {
+	if len(is.rows) == 0 {
+		return
+	}
+	builder.WriteString("INSERT INTO ")
+	builder.WriteString(is.table)
+	builder.WriteString(" (")
+	builder.WriteString(strings.Join(is.columns, ", "))
+	builder.WriteString(") VALUES ")
+	builder.WriteString(strings.Join(is.rows, ", "))
+	builder.WriteString(" ON CONFLICT ")
+	builder.WriteString(is.onConflict)
+	builder.WriteString(";\n")
+}
+
+// sqlString returns the given string as an SQL literal. Since standard_conforming_strings
+// is required, only single quotes have to be escaped.
+func sqlString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func sqlUint64(value uint64) string {
+	return strconv.FormatUint(value, 10)
+}
+
+func sqlBool(value bool) string {
+	if value {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// sqlStringList returns the given strings as a parenthesized list of SQL literals
+func sqlStringList(values []string) string {
+	literals := make([]string, len(values))
+	for i, value := range values {
+		literals[i] = sqlString(value)
+	}
+	return "(" + strings.Join(literals, ", ") + ")"
+}
@@ -0,0 +1,345 @@
+package stratum
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/kaspanet/kaspad/version"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxRequestLength is the maximal length of a single line sent by a miner
+	maxRequestLength = 16 * 1024
+
+	// maxWorkerNameLength bounds the worker name, which is written into the coinbase payload
+	maxWorkerNameLength = 32
+
+	writeTimeout = 10 * time.Second
+)
+
+// client is a single Stratum connection
+type client struct {
+	server          *Server
+	conn            net.Conn
+	extraNonceValue uint16
+	extraNonce      string
+
+	writeLock sync.Mutex
+
+	lock         sync.Mutex
+	isSubscribed bool
+	coinbaseData *externalapi.DomainCoinbaseData
+	workerName   string
+	varDiff      *varDiff
+	jobs         map[string]*job
+	jobIDs       []string
+	nextJobID    uint64
+}
+
+func newClient(server *Server, conn net.Conn, extraNonce uint16) *client {
+	return &client{
+		server:          server,
+		conn:            conn,
+		extraNonceValue: extraNonce,
+		extraNonce:      fmt.Sprintf("%0*x", extraNonceSize*2, extraNonce),
+		varDiff:         newVarDiff(server.cfg.StratumMinDifficulty, time.Now()),
+		jobs:            make(map[string]*job),
+	}
+}
+
+func (c *client) String() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.workerName != "" {
+		return fmt.Sprintf("%s (%s)", c.conn.RemoteAddr(), c.workerName)
+	}
+	return c.conn.RemoteAddr().String()
+}
+
+func (c *client) disconnect() {
+	err := c.conn.Close()
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		log.Warnf("Error closing Stratum connection %s: %s", c, err)
+	}
+}
+
+func (c *client) run() {
+	defer c.disconnect()
+
+	scanner := bufio.NewScanner(c.conn)
+	scanner.Buffer(make([]byte, 0, maxRequestLength), maxRequestLength)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		request := &stratumRequest{}
+		err := json.Unmarshal([]byte(line), request)
+		if err != nil {
+			log.Warnf("Got malformed Stratum request from %s: %s", c, err)
+			return
+		}
+
+		err = c.handleRequest(request)
+		if err != nil {
+			log.Warnf("Error handling Stratum request %s from %s: %s", request.Method, c, err)
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, net.ErrClosed) {
+		log.Debugf("Error reading from Stratum connection %s: %s", c, err)
+	}
+}
+
+func (c *client) handleRequest(request *stratumRequest) error {
+	switch request.Method {
+	case methodSubscribe:
+		return c.handleSubscribe(request)
+	case methodExtraNonceSubscribe:
+		return c.respond(request, true, nil)
+	case methodAuthorize:
+		return c.handleAuthorize(request)
+	case methodSubmit:
+		return c.handleSubmit(request)
+	default:
+		log.Debugf("Got unknown Stratum method %s from %s", request.Method, c)
+		return c.respond(request, nil, errOther)
+	}
+}
+
+func (c *client) handleSubscribe(request *stratumRequest) error {
+	c.lock.Lock()
+	c.isSubscribed = true
+	c.lock.Unlock()
+
+	err := c.respond(request, subscribeResult, nil)
+	if err != nil {
+		return err
+	}
+	return c.notify(methodSetExtraNonce, c.extraNonce, extraNonceSize)
+}
+
+func (c *client) handleAuthorize(request *stratumRequest) error {
+	c.lock.Lock()
+	isSubscribed := c.isSubscribed
+	c.lock.Unlock()
+	if !isSubscribed {
+		return c.respond(request, false, errNotSubscribed)
+	}
+
+	// The username is the address to mine to, optionally followed by a worker name
+	username, ok := stringParam(request.Params, 0)
+	if !ok {
+		return c.respond(request, false, errUnauthorized)
+	}
+	if c.server.cfg.StratumPassword != "" {
+		password, _ := stringParam(request.Params, 1)
+		if subtle.ConstantTimeCompare([]byte(password), []byte(c.server.cfg.StratumPassword)) != 1 {
+			log.Infof("Stratum connection %s failed to authorize: wrong password", c)
+			return c.respond(request, false, errUnauthorized)
+		}
+	}
+	addressString, workerName := username, ""
+	if separatorIndex := strings.LastIndex(username, "."); separatorIndex != -1 {
+		addressString, workerName = username[:separatorIndex], username[separatorIndex+1:]
+	}
+	if len(workerName) > maxWorkerNameLength {
+		workerName = workerName[:maxWorkerNameLength]
+	}
+
+	address, err := util.DecodeAddress(addressString, c.server.cfg.ActiveNetParams.Prefix)
+	if err != nil {
+		log.Infof("Stratum connection %s failed to authorize with address %s: %s", c, addressString, err)
+		return c.respond(request, false, errUnauthorized)
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	c.coinbaseData = &externalapi.DomainCoinbaseData{
+		ScriptPublicKey: scriptPublicKey,
+		ExtraData:       []byte(version.Version() + "/" + workerName),
+	}
+	c.workerName = workerName
+	difficulty := c.varDiff.difficulty
+	c.lock.Unlock()
+
+	log.Infof("Stratum connection %s authorized to mine to %s", c, address)
+	err = c.respond(request, true, nil)
+	if err != nil {
+		return err
+	}
+	err = c.notify(methodSetDifficulty, difficulty)
+	if err != nil {
+		return err
+	}
+	return c.sendNewJob()
+}
+
+func (c *client) handleSubmit(request *stratumRequest) error {
+	jobID, hasJobID := stringParam(request.Params, 1)
+	nonceString, hasNonce := stringParam(request.Params, 2)
+	if !hasJobID || !hasNonce {
+		return c.respond(request, false, errOther)
+	}
+	nonce, err := c.parseNonce(nonceString)
+	if err != nil {
+		log.Debugf("Got malformed nonce %s from %s: %s", nonceString, c, err)
+		return c.respond(request, false, errOther)
+	}
+
+	c.lock.Lock()
+	if c.coinbaseData == nil {
+		c.lock.Unlock()
+		return c.respond(request, false, errUnauthorized)
+	}
+	job, ok := c.jobs[jobID]
+	if !ok {
+		c.lock.Unlock()
+		return c.respond(request, false, errJobNotFound)
+	}
+	if _, ok := job.submittedNonces[nonce]; ok {
+		c.lock.Unlock()
+		return c.respond(request, false, errDuplicateShare)
+	}
+	job.submittedNonces[nonce] = struct{}{}
+
+	powValue := job.proofOfWorkValue(nonce)
+	if powValue.Cmp(difficultyToTarget(c.varDiff.difficulty)) > 0 {
+		c.lock.Unlock()
+		return c.respond(request, false, errLowDifficultyShare)
+	}
+	c.varDiff.addShare()
+	isBlock := powValue.Cmp(job.networkTarget()) <= 0
+	c.lock.Unlock()
+
+	if isBlock {
+		block := job.solvedBlock(nonce)
+		err := c.server.protocolManager.AddBlock(block)
+		if err != nil {
+			isProtocolOrRuleError := errors.As(err, &ruleerrors.RuleError{}) || errors.As(err, &protocolerrors.ProtocolError{})
+			if !isProtocolOrRuleError {
+				return err
+			}
+			log.Warnf("Block %s found by %s was rejected: %s", consensushashing.BlockHash(block), c, err)
+			return c.respond(request, false, &stratumError{code: errOther.code, message: fmt.Sprintf("Block rejected: %s", err)})
+		}
+		log.Infof("Accepted block %s found by %s", consensushashing.BlockHash(block), c)
+	}
+	return c.respond(request, true, nil)
+}
+
+// parseNonce parses a nonce submitted by the miner. Miners that only search
+// the part of the nonce space that wasn't assigned to the connection as
+// extra nonce submit only that part.
+func (c *client) parseNonce(nonceString string) (uint64, error) {
+	nonceString = strings.TrimPrefix(nonceString, "0x")
+	const nonceHexLength = 16
+	if len(nonceString) <= nonceHexLength-len(c.extraNonce) {
+		padding := strings.Repeat("0", nonceHexLength-len(c.extraNonce)-len(nonceString))
+		nonceString = c.extraNonce + padding + nonceString
+	}
+	return strconv.ParseUint(nonceString, 16, 64)
+}
+
+// sendNewJob sends a job built on top of the current block template to the miner,
+// adjusting its share difficulty beforehand if required
+func (c *client) sendNewJob() error {
+	c.lock.Lock()
+	coinbaseData := c.coinbaseData
+	c.lock.Unlock()
+	if coinbaseData == nil {
+		return nil
+	}
+
+	templateBlock, _, err := c.server.domain.MiningManager().GetBlockTemplate(coinbaseData)
+	if err != nil {
+		return err
+	}
+	isSynced, err := c.server.isSynced()
+	if err != nil {
+		return err
+	}
+	if !isSynced {
+		log.Debugf("Not sending a new job to %s since the node is not synced", c)
+		return nil
+	}
+
+	c.lock.Lock()
+	difficultyChanged := c.varDiff.retarget(time.Now())
+	difficulty := c.varDiff.difficulty
+
+	jobID := strconv.FormatUint(c.nextJobID, 16)
+	c.nextJobID++
+	newJob := newJob(jobID, templateBlock)
+	c.jobs[jobID] = newJob
+	c.jobIDs = append(c.jobIDs, jobID)
+	if len(c.jobIDs) > maxJobsPerClient {
+		delete(c.jobs, c.jobIDs[0])
+		c.jobIDs = c.jobIDs[1:]
+	}
+	c.lock.Unlock()
+
+	if difficultyChanged {
+		log.Debugf("Changing the share difficulty of %s to %f", c, difficulty)
+		err := c.notify(methodSetDifficulty, difficulty)
+		if err != nil {
+			return err
+		}
+	}
+	return c.notify(methodNotify, newJob.notifyParams()...)
+}
+
+func (c *client) respond(request *stratumRequest, result interface{}, stratumErr *stratumError) error {
+	response := &stratumResponse{
+		ID:     request.ID,
+		Result: result,
+	}
+	if stratumErr != nil {
+		response.Error = stratumErr.toResponseError()
+	}
+	return c.write(response)
+}
+
+func (c *client) notify(method string, params ...interface{}) error {
+	return c.write(&stratumNotification{
+		Method: method,
+		Params: params,
+	})
+}
+
+func (c *client) write(message interface{}) error {
+	serializedMessage, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	serializedMessage = append(serializedMessage, '\n')
+
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	err = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(serializedMessage)
+	return err
+}
@@ -0,0 +1,35 @@
+package stratum
+
+import (
+	"testing"
+)
+
+func TestParseNonce(t *testing.T) {
+	c := &client{extraNonce: "abcd"}
+
+	tests := []struct {
+		nonceString   string
+		expectedNonce uint64
+	}{
+		// A full nonce is taken as is
+		{nonceString: "0123456789abcdef", expectedNonce: 0x0123456789abcdef},
+		{nonceString: "0x0123456789abcdef", expectedNonce: 0x0123456789abcdef},
+		// A partial nonce is prefixed with the extra nonce
+		{nonceString: "0123456789ab", expectedNonce: 0xabcd0123456789ab},
+		{nonceString: "1", expectedNonce: 0xabcd000000000001},
+	}
+	for _, test := range tests {
+		nonce, err := c.parseNonce(test.nonceString)
+		if err != nil {
+			t.Fatalf("parseNonce(%s): %s", test.nonceString, err)
+		}
+		if nonce != test.expectedNonce {
+			t.Fatalf("parseNonce(%s): expected %x but got %x", test.nonceString, test.expectedNonce, nonce)
+		}
+	}
+
+	_, err := c.parseNonce("not a nonce")
+	if err == nil {
+		t.Fatalf("Expected an error parsing a malformed nonce")
+	}
+}
@@ -0,0 +1,88 @@
+package stratum
+
+import (
+	"math/big"
+	"time"
+)
+
+// difficultyOneTarget is the share target at difficulty 1, under
+// which a share is found once every 2^32 hashes on average
+var difficultyOneTarget = new(big.Int).Lsh(big.NewInt(1), 224)
+
+const (
+	// targetSharesPerMinute is the rate of shares the difficulty of every connection is adjusted towards
+	targetSharesPerMinute = 20
+
+	// varDiffRetargetInterval is the minimal duration between two difficulty adjustments of a connection
+	varDiffRetargetInterval = 30 * time.Second
+
+	// maxDifficultyChangeFactor bounds how much a single adjustment may change the difficulty
+	maxDifficultyChangeFactor = 4
+
+	// varDiffTolerance is how far (as a fraction) the rate of shares may drift from
+	// targetSharesPerMinute before the difficulty is adjusted
+	varDiffTolerance = 0.5
+)
+
+// difficultyToTarget returns the share target of the given difficulty
+func difficultyToTarget(difficulty float64) *big.Int {
+	target, _ := new(big.Float).Quo(
+		new(big.Float).SetInt(difficultyOneTarget),
+		big.NewFloat(difficulty),
+	).Int(nil)
+	return target
+}
+
+// varDiff adjusts the share difficulty of a single connection according to its hashrate
+type varDiff struct {
+	difficulty    float64
+	minDifficulty float64
+	shareCount    int
+	windowStart   time.Time
+}
+
+func newVarDiff(minDifficulty float64, now time.Time) *varDiff {
+	return &varDiff{
+		difficulty:    minDifficulty,
+		minDifficulty: minDifficulty,
+		windowStart:   now,
+	}
+}
+
+func (vd *varDiff) addShare() {
+	vd.shareCount++
+}
+
+// retarget adjusts the difficulty so that the connection submits about targetSharesPerMinute
+// shares, and returns whether the difficulty was changed
+func (vd *varDiff) retarget(now time.Time) bool {
+	elapsed := now.Sub(vd.windowStart)
+	if elapsed < varDiffRetargetInterval {
+		return false
+	}
+
+	sharesPerMinute := float64(vd.shareCount) / elapsed.Minutes()
+	ratio := sharesPerMinute / targetSharesPerMinute
+	vd.shareCount = 0
+	vd.windowStart = now
+
+	if ratio > 1-varDiffTolerance && ratio < 1+varDiffTolerance {
+		return false
+	}
+	if ratio > maxDifficultyChangeFactor {
+		ratio = maxDifficultyChangeFactor
+	}
+	if ratio < 1.0/maxDifficultyChangeFactor {
+		ratio = 1.0 / maxDifficultyChangeFactor
+	}
+
+	newDifficulty := vd.difficulty * ratio
+	if newDifficulty < vd.minDifficulty {
+		newDifficulty = vd.minDifficulty
+	}
+	if newDifficulty == vd.difficulty {
+		return false
+	}
+	vd.difficulty = newDifficulty
+	return true
+}
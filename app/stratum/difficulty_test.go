@@ -0,0 +1,74 @@
+package stratum
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestDifficultyToTarget(t *testing.T) {
+	if difficultyToTarget(1).Cmp(difficultyOneTarget) != 0 {
+		t.Fatalf("Unexpected target for difficulty 1: %x", difficultyToTarget(1))
+	}
+
+	expectedTarget := new(big.Int).Rsh(difficultyOneTarget, 4)
+	if difficultyToTarget(16).Cmp(expectedTarget) != 0 {
+		t.Fatalf("Unexpected target for difficulty 16. Want: %x, got: %x", expectedTarget, difficultyToTarget(16))
+	}
+
+	if difficultyToTarget(0.5).Cmp(difficultyToTarget(1)) <= 0 {
+		t.Fatalf("Expected the target of a fractional difficulty to be above the target of difficulty 1")
+	}
+}
+
+func TestVarDiff(t *testing.T) {
+	start := time.Now()
+	vd := newVarDiff(1, start)
+
+	// Nothing changes before the retarget interval passes
+	for i := 0; i < 1000; i++ {
+		vd.addShare()
+	}
+	if vd.retarget(start.Add(varDiffRetargetInterval / 2)) {
+		t.Fatalf("Expected no retarget before the retarget interval passes")
+	}
+
+	// Way too many shares - the difficulty rises by at most maxDifficultyChangeFactor
+	if !vd.retarget(start.Add(varDiffRetargetInterval)) {
+		t.Fatalf("Expected the difficulty to change")
+	}
+	if vd.difficulty != maxDifficultyChangeFactor {
+		t.Fatalf("Unexpected difficulty. Want: %d, got: %f", maxDifficultyChangeFactor, vd.difficulty)
+	}
+
+	// About the target rate of shares - the difficulty stays the same
+	windowStart := vd.windowStart
+	sharesInInterval := int(targetSharesPerMinute * varDiffRetargetInterval.Minutes())
+	for i := 0; i < sharesInInterval; i++ {
+		vd.addShare()
+	}
+	if vd.retarget(windowStart.Add(varDiffRetargetInterval)) {
+		t.Fatalf("Expected the difficulty not to change when the share rate is on target")
+	}
+
+	// A quarter of the target rate of shares - the difficulty drops fourfold
+	windowStart = vd.windowStart
+	for i := 0; i < sharesInInterval/4; i++ {
+		vd.addShare()
+	}
+	if !vd.retarget(windowStart.Add(varDiffRetargetInterval)) {
+		t.Fatalf("Expected the difficulty to change")
+	}
+	if vd.difficulty != maxDifficultyChangeFactor/4 {
+		t.Fatalf("Unexpected difficulty. Want: %d, got: %f", maxDifficultyChangeFactor/4, vd.difficulty)
+	}
+
+	// No shares at all - the difficulty never drops below the minimum
+	windowStart = vd.windowStart
+	if vd.retarget(windowStart.Add(varDiffRetargetInterval)) {
+		t.Fatalf("Expected the difficulty to stay at the minimum")
+	}
+	if vd.difficulty != 1 {
+		t.Fatalf("Unexpected difficulty. Want: 1, got: %f", vd.difficulty)
+	}
+}
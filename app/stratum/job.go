@@ -0,0 +1,71 @@
+package stratum
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/pow"
+)
+
+// maxJobsPerClient is the number of recent jobs a connection may submit shares for
+const maxJobsPerClient = 64
+
+// job is a block template handed out to a single Stratum connection
+type job struct {
+	id              string
+	block           *externalapi.DomainBlock
+	prePowHash      *externalapi.DomainHash
+	powState        *pow.State
+	submittedNonces map[uint64]struct{}
+}
+
+func newJob(id string, block *externalapi.DomainBlock) *job {
+	header := block.Header.ToMutable()
+	powState := pow.NewState(header)
+
+	header.SetTimeInMilliseconds(0)
+	header.SetNonce(0)
+	prePowHash := consensushashing.HeaderHash(header)
+
+	return &job{
+		id:              id,
+		block:           block,
+		prePowHash:      prePowHash,
+		powState:        powState,
+		submittedNonces: make(map[uint64]struct{}),
+	}
+}
+
+// notifyParams returns the params of the mining.notify message that announces this job:
+// the job ID, the pre-PoW hash as four little-endian uint64 words and the header timestamp
+func (j *job) notifyParams() []interface{} {
+	prePowHashBytes := j.prePowHash.ByteSlice()
+	words := make([]uint64, 4)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(prePowHashBytes[i*8:])
+	}
+	return []interface{}{j.id, words, j.block.Header.TimeInMilliseconds()}
+}
+
+// proofOfWorkValue returns the proof of work value of this job's block with the given nonce
+func (j *job) proofOfWorkValue(nonce uint64) *big.Int {
+	j.powState.Nonce = nonce
+	return j.powState.CalculateProofOfWorkValue()
+}
+
+// networkTarget returns the target the proof of work value of a block must not exceed
+func (j *job) networkTarget() *big.Int {
+	return &j.powState.Target
+}
+
+// solvedBlock returns this job's block with the given nonce
+func (j *job) solvedBlock(nonce uint64) *externalapi.DomainBlock {
+	header := j.block.Header.ToMutable()
+	header.SetNonce(nonce)
+	return &externalapi.DomainBlock{
+		Header:       header.ToImmutable(),
+		Transactions: j.block.Transactions,
+	}
+}
@@ -0,0 +1,77 @@
+package stratum
+
+import (
+	"encoding/json"
+)
+
+// The Stratum methods handled by the server
+const (
+	methodSubscribe           = "mining.subscribe"
+	methodExtraNonceSubscribe = "mining.extranonce.subscribe"
+	methodAuthorize           = "mining.authorize"
+	methodSubmit              = "mining.submit"
+)
+
+// The Stratum methods sent by the server
+const (
+	methodNotify        = "mining.notify"
+	methodSetDifficulty = "mining.set_difficulty"
+	methodSetExtraNonce = "mining.set_extranonce"
+)
+
+// subscribeResult is the result of the mining.subscribe method
+var subscribeResult = []interface{}{true, "EthereumStratum/1.0.0"}
+
+// extraNonceSize is the number of nonce bytes assigned to every connection,
+// so that workers mining for the same address don't repeat each other's work
+const extraNonceSize = 2
+
+type stratumRequest struct {
+	ID     interface{}       `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type stratumResponse struct {
+	ID     interface{}   `json:"id"`
+	Result interface{}   `json:"result"`
+	Error  []interface{} `json:"error"`
+}
+
+type stratumNotification struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// stratumError is an error reported to a miner in a Stratum response
+type stratumError struct {
+	code    int
+	message string
+}
+
+func (e *stratumError) toResponseError() []interface{} {
+	return []interface{}{e.code, e.message, nil}
+}
+
+var (
+	errOther              = &stratumError{code: 20, message: "Other/Unknown"}
+	errJobNotFound        = &stratumError{code: 21, message: "Job not found"}
+	errDuplicateShare     = &stratumError{code: 22, message: "Duplicate share"}
+	errLowDifficultyShare = &stratumError{code: 23, message: "Low difficulty share"}
+	errUnauthorized       = &stratumError{code: 24, message: "Unauthorized worker"}
+	errNotSubscribed      = &stratumError{code: 25, message: "Not subscribed"}
+)
+
+// stringParam returns the string param at the given index, if there is one
+func stringParam(params []json.RawMessage, index int) (string, bool) {
+	if index >= len(params) {
+		return "", false
+	}
+	var param string
+	err := json.Unmarshal(params[index], &param)
+	if err != nil {
+		return "", false
+	}
+	return param, true
+}
@@ -0,0 +1,216 @@
+package stratum
+
+import (
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/protocol"
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/pkg/errors"
+)
+
+// jobRefreshInterval is the interval at which new jobs are sent even if
+// no new block template is available, so that they include new transactions
+const jobRefreshInterval = 10 * time.Second
+
+// Server is a Stratum v1 server that serves jobs derived from block templates to
+// miners over TCP, and submits the blocks they solve
+type Server struct {
+	cfg             *config.Config
+	domain          domain.Domain
+	protocolManager *protocol.Manager
+
+	listeners []net.Listener
+
+	clients         map[*client]struct{}
+	clientsLock     sync.Mutex
+	usedExtraNonces map[uint16]struct{}
+	nextExtraNonce  uint16
+
+	newBlockTemplateChan chan struct{}
+	quit                 chan struct{}
+	stopOnce             sync.Once
+}
+
+// NewServer creates a new Stratum server. Use Start() to begin listening
+func NewServer(cfg *config.Config, domain domain.Domain, protocolManager *protocol.Manager) *Server {
+	return &Server{
+		cfg:                  cfg,
+		domain:               domain,
+		protocolManager:      protocolManager,
+		clients:              make(map[*client]struct{}),
+		usedExtraNonces:      make(map[uint16]struct{}),
+		newBlockTemplateChan: make(chan struct{}, 1),
+		quit:                 make(chan struct{}),
+	}
+}
+
+// Start starts listening on all the configured Stratum listeners
+func (s *Server) Start() error {
+	for _, listenAddress := range s.cfg.StratumListeners {
+		listener, err := net.Listen("tcp", listenAddress)
+		if err != nil {
+			s.closeListeners()
+			return errors.Wrapf(err, "failed to listen for Stratum connections on %s", listenAddress)
+		}
+		s.listeners = append(s.listeners, listener)
+		log.Infof("Stratum server listening on %s", listenAddress)
+	}
+
+	for _, listener := range s.listeners {
+		listener := listener
+		spawn("stratum.Server.acceptConnections", func() {
+			s.acceptConnections(listener)
+		})
+	}
+	spawn("stratum.Server.distributeJobs", s.distributeJobs)
+	return nil
+}
+
+// Stop closes all the listeners and connections of the server
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.quit)
+		s.closeListeners()
+
+		s.clientsLock.Lock()
+		defer s.clientsLock.Unlock()
+		for client := range s.clients {
+			client.disconnect()
+		}
+	})
+}
+
+// NotifyNewBlockTemplate notifies the server that a new block template is
+// available, so that new jobs are sent to all the connected miners
+func (s *Server) NotifyNewBlockTemplate() {
+	select {
+	case s.newBlockTemplateChan <- struct{}{}:
+	default:
+		// A job distribution is already pending
+	}
+}
+
+func (s *Server) closeListeners() {
+	for _, listener := range s.listeners {
+		err := listener.Close()
+		if err != nil {
+			log.Warnf("Error closing Stratum listener %s: %s", listener.Addr(), err)
+		}
+	}
+}
+
+func (s *Server) acceptConnections(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+				return
+			default:
+			}
+			log.Errorf("Error accepting Stratum connection on %s: %s", listener.Addr(), err)
+			return
+		}
+
+		client, err := s.addClient(conn)
+		if err != nil {
+			log.Warnf("Rejected Stratum connection from %s: %s", conn.RemoteAddr(), err)
+			closeErr := conn.Close()
+			if closeErr != nil {
+				log.Warnf("Error closing Stratum connection %s: %s", conn.RemoteAddr(), closeErr)
+			}
+			continue
+		}
+		log.Infof("New Stratum connection from %s", conn.RemoteAddr())
+		spawn("stratum.client.run", func() {
+			client.run()
+			s.removeClient(client)
+			log.Infof("Stratum connection from %s closed", conn.RemoteAddr())
+		})
+	}
+}
+
+func (s *Server) addClient(conn net.Conn) (*client, error) {
+	s.clientsLock.Lock()
+	defer s.clientsLock.Unlock()
+
+	if len(s.clients) >= s.cfg.StratumMaxClients {
+		return nil, errors.Errorf("the max number of Stratum connections (%d) is reached", s.cfg.StratumMaxClients)
+	}
+	extraNonce, err := s.allocateExtraNonce()
+	if err != nil {
+		return nil, err
+	}
+	client := newClient(s, conn, extraNonce)
+	s.clients[client] = struct{}{}
+	return client, nil
+}
+
+// allocateExtraNonce returns an extra nonce that isn't used by any connected client, so that
+// no two clients ever search the same nonce space. Extra nonces of disconnected clients are reused.
+// This function must be called with the clients lock held.
+func (s *Server) allocateExtraNonce() (uint16, error) {
+	if len(s.usedExtraNonces) > math.MaxUint16 {
+		return 0, errors.New("all the extra nonces are in use")
+	}
+	for {
+		extraNonce := s.nextExtraNonce
+		s.nextExtraNonce++
+		if _, ok := s.usedExtraNonces[extraNonce]; !ok {
+			s.usedExtraNonces[extraNonce] = struct{}{}
+			return extraNonce, nil
+		}
+	}
+}
+
+func (s *Server) removeClient(client *client) {
+	s.clientsLock.Lock()
+	defer s.clientsLock.Unlock()
+
+	delete(s.clients, client)
+	delete(s.usedExtraNonces, client.extraNonceValue)
+}
+
+func (s *Server) distributeJobs() {
+	ticker := time.NewTicker(jobRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-s.newBlockTemplateChan:
+		case <-ticker.C:
+		}
+
+		s.clientsLock.Lock()
+		clients := make([]*client, 0, len(s.clients))
+		for client := range s.clients {
+			clients = append(clients, client)
+		}
+		s.clientsLock.Unlock()
+
+		for _, client := range clients {
+			err := client.sendNewJob()
+			if err != nil {
+				log.Warnf("Error sending a new job to %s: %s", client, err)
+				client.disconnect()
+			}
+		}
+	}
+}
+
+// isSynced returns whether blocks mined on top of the current templates are likely to be accepted by the network
+func (s *Server) isSynced() (bool, error) {
+	if s.cfg.AllowSubmitBlockWhenNotSynced {
+		return true, nil
+	}
+	if !s.protocolManager.Context().HasPeers() {
+		return false, nil
+	}
+	return s.protocolManager.Context().IsCurrent()
+}
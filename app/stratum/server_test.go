@@ -0,0 +1,72 @@
+package stratum
+
+import (
+	"math"
+	"net"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/config"
+)
+
+func TestAddClient(t *testing.T) {
+	cfg := &config.Config{Flags: &config.Flags{StratumMinDifficulty: 1, StratumMaxClients: 2}}
+	server := NewServer(cfg, nil, nil)
+
+	addClient := func() (*client, error) {
+		conn, _ := net.Pipe()
+		return server.addClient(conn)
+	}
+
+	first, err := addClient()
+	if err != nil {
+		t.Fatalf("addClient: %s", err)
+	}
+	second, err := addClient()
+	if err != nil {
+		t.Fatalf("addClient: %s", err)
+	}
+	if first.extraNonce == second.extraNonce {
+		t.Fatalf("two clients got the same extra nonce %s", first.extraNonce)
+	}
+
+	// The connection limit is reached
+	_, err = addClient()
+	if err == nil {
+		t.Fatalf("addClient unexpectedly succeeded above the connection limit")
+	}
+
+	// Removing a client frees up both its connection slot and its extra nonce
+	server.removeClient(first)
+	third, err := addClient()
+	if err != nil {
+		t.Fatalf("addClient: %s", err)
+	}
+	if third.extraNonce == second.extraNonce {
+		t.Fatalf("two clients got the same extra nonce %s", third.extraNonce)
+	}
+}
+
+func TestAllocateExtraNonceWraparound(t *testing.T) {
+	server := NewServer(&config.Config{Flags: &config.Flags{}}, nil, nil)
+
+	// Occupy every extra nonce except for one, and start allocating right after it
+	for i := 0; i <= math.MaxUint16; i++ {
+		if i != 7 {
+			server.usedExtraNonces[uint16(i)] = struct{}{}
+		}
+	}
+	server.nextExtraNonce = 8
+
+	extraNonce, err := server.allocateExtraNonce()
+	if err != nil {
+		t.Fatalf("allocateExtraNonce: %s", err)
+	}
+	if extraNonce != 7 {
+		t.Fatalf("expected the only free extra nonce 7 but got %d", extraNonce)
+	}
+
+	_, err = server.allocateExtraNonce()
+	if err == nil {
+		t.Fatalf("allocateExtraNonce unexpectedly succeeded with all the extra nonces in use")
+	}
+}
@@ -0,0 +1,84 @@
+package utxosnapshot
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// destination is where snapshots are published to
+type destination interface {
+	// put writes data to the object at the given slash-separated path under the
+	// destination, replacing the object if it exists
+	put(path string, data []byte) error
+
+	// String returns a description of the destination that doesn't include credentials
+	String() string
+}
+
+// parseDestinationURL parses a URL of the form file:///path/to/directory,
+// s3://[access-key:secret-key@]host[:port]/bucket[/prefix] or the same with s3+http
+// for S3-compatible endpoints that aren't served over TLS. The errors don't include
+// the URL, since it may include a secret key.
+func parseDestinationURL(destinationURL string) (destination, error) {
+	parsedURL, err := url.Parse(destinationURL)
+	if err != nil {
+		return nil, errors.New("the UTXO snapshot URL is malformed")
+	}
+	switch parsedURL.Scheme {
+	case "file":
+		if parsedURL.Host != "" && parsedURL.Host != "localhost" {
+			return nil, errors.New("the UTXO snapshot directory must be on the local host")
+		}
+		if !filepath.IsAbs(parsedURL.Path) {
+			return nil, errors.New("the UTXO snapshot directory must be an absolute path")
+		}
+		return &directoryDestination{directory: filepath.Clean(parsedURL.Path)}, nil
+	case "s3", "s3+http":
+		return newS3Destination(parsedURL)
+	default:
+		return nil, errors.Errorf("unsupported UTXO snapshot URL scheme %q, expected file, s3 or s3+http",
+			parsedURL.Scheme)
+	}
+}
+
+// directoryDestination publishes snapshots to a local directory, for a web server
+// to serve or for another tool to upload
+type directoryDestination struct {
+	directory string
+}
+
+// put writes data to a temporary file and renames it to path, so that path only
+// ever holds a complete object
+func (d *directoryDestination) put(path string, data []byte) error {
+	objectPath := filepath.Join(d.directory, filepath.FromSlash(path))
+	if !strings.HasPrefix(objectPath, d.directory+string(filepath.Separator)) {
+		return errors.Errorf("the object path %s leads out of %s", path, d.directory)
+	}
+	err := os.MkdirAll(filepath.Dir(objectPath), 0755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	temporaryPath := objectPath + ".tmp"
+	err = os.WriteFile(temporaryPath, data, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = os.Rename(temporaryPath, objectPath)
+	if err != nil {
+		removeErr := os.Remove(temporaryPath)
+		if removeErr != nil {
+			log.Warnf("Error removing %s: %s", temporaryPath, removeErr)
+		}
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func (d *directoryDestination) String() string {
+	return d.directory
+}
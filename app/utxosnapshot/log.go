@@ -0,0 +1,9 @@
+package utxosnapshot
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/panics"
+)
+
+var log = logger.RegisterSubSystem("UTXS")
+var spawn = panics.GoroutineWrapperFunc(log)
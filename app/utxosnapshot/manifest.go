@@ -0,0 +1,102 @@
+package utxosnapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/pkg/errors"
+)
+
+// manifest describes a published snapshot. It's signed by the node that published it,
+// and the signature is published next to it, in hex, as manifest.json.sig.
+type manifest struct {
+	Network          string   `json:"network"`
+	PruningPointHash string   `json:"pruningPointHash"`
+	BlueScore        uint64   `json:"blueScore"`
+	DAAScore         uint64   `json:"daaScore"`
+	UTXOCommitment   string   `json:"utxoCommitment"`
+	UTXOCount        uint64   `json:"utxoCount"`
+	Timestamp        int64    `json:"timestamp"`
+	PublicKey        string   `json:"publicKey"`
+	Chunks           []*chunk `json:"chunks"`
+}
+
+// chunk is a file of a snapshot, holding some of its UTXOs
+type chunk struct {
+	Name      string `json:"name"`
+	UTXOCount uint64 `json:"utxoCount"`
+	Size      int    `json:"size"`
+	SHA256    string `json:"sha256"`
+}
+
+// latest points to the last snapshot that was published
+type latest struct {
+	PruningPointHash string `json:"pruningPointHash"`
+	BlueScore        uint64 `json:"blueScore"`
+	Manifest         string `json:"manifest"`
+}
+
+// signManifest returns the hex Schnorr signature of the SHA256 of serializedManifest
+func signManifest(serializedManifest []byte, signingKey *secp256k1.SchnorrKeyPair) (string, error) {
+	hash := secp256k1.Hash(sha256.Sum256(serializedManifest))
+	signature, err := signingKey.SchnorrSign(&hash)
+	if err != nil {
+		return "", errors.Wrap(err, "error signing the UTXO snapshot manifest")
+	}
+	return hex.EncodeToString(signature.Serialize()[:]), nil
+}
+
+// loadSigningKey loads the hex Schnorr private key in the file at keyPath, and
+// generates one into it if it doesn't exist
+func loadSigningKey(keyPath string) (*secp256k1.SchnorrKeyPair, error) {
+	keyHex, err := os.ReadFile(keyPath)
+	if os.IsNotExist(err) {
+		return generateSigningKey(keyPath)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	keyBytes, err := hex.DecodeString(strings.TrimSpace(string(keyHex)))
+	if err != nil {
+		return nil, errors.Errorf("the UTXO snapshot signing key in %s isn't hex", keyPath)
+	}
+	signingKey, err := secp256k1.DeserializeSchnorrPrivateKeyFromSlice(keyBytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "the UTXO snapshot signing key in %s is invalid", keyPath)
+	}
+	return signingKey, nil
+}
+
+func generateSigningKey(keyPath string) (*secp256k1.SchnorrKeyPair, error) {
+	signingKey, err := secp256k1.GenerateSchnorrKeyPair()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = os.MkdirAll(filepath.Dir(keyPath), 0700)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	keyHex := hex.EncodeToString(signingKey.SerializePrivateKey()[:])
+	err = os.WriteFile(keyPath, []byte(keyHex+"\n"), 0600)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	log.Infof("Generated a new UTXO snapshot signing key into %s", keyPath)
+	return signingKey, nil
+}
+
+func serializedPublicKey(signingKey *secp256k1.SchnorrKeyPair) (string, error) {
+	publicKey, err := signingKey.SchnorrPublicKey()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	serializedPublicKey, err := publicKey.Serialize()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return hex.EncodeToString(serializedPublicKey[:]), nil
+}
@@ -0,0 +1,355 @@
+package utxosnapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/multiset"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	// utxosPerChunk is the number of UTXOs in every chunk of a snapshot but the last
+	utxosPerChunk = 100_000
+
+	// utxosPerRead is the number of UTXOs that are read from the consensus at once
+	utxosPerRead = 1000
+
+	// stateFilename is the name of the file, under the app directory, that keeps the
+	// pruning point whose snapshot was last published
+	stateFilename = "utxosnapshot.last"
+
+	manifestFilename = "manifest.json"
+	latestFilename   = "latest.json"
+)
+
+var errInterrupted = errors.New("the UTXO snapshot publisher is stopping")
+
+// Publisher periodically publishes signed snapshots of the UTXO set of the pruning
+// point, which nodes that sync from scratch may start from instead of downloading it
+// from peers.
+//
+// Every snapshot is published under the hash of its pruning point: its UTXOs in gzipped
+// chunks, and a manifest of the chunks that's signed by the node. The UTXOs of a snapshot
+// hash to the UTXO commitment of the header of its pruning point, which is checked before
+// the manifest is published, so the chunks of a snapshot are complete once its manifest
+// is. latest.json, at the root, points to the last snapshot that was published.
+type Publisher struct {
+	destination destination
+	interval    time.Duration
+	network     string
+	signingKey  *secp256k1.SchnorrKeyPair
+	publicKey   string
+	statePath   string
+	domain      domain.Domain
+
+	quit     chan struct{}
+	stopOnce sync.Once
+	stopped  sync.WaitGroup
+}
+
+// New creates a Publisher of snapshots to the destination of --utxosnapshot, signed with
+// the key of --utxosnapshotkey. Use Start() to begin publishing.
+func New(cfg *config.Config, domain domain.Domain) (*Publisher, error) {
+	destination, err := parseDestinationURL(cfg.UTXOSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	signingKey, err := loadSigningKey(cfg.UTXOSnapshotKey)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := serializedPublicKey(signingKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Publisher{
+		destination: destination,
+		interval:    cfg.UTXOSnapshotInterval,
+		network:     cfg.NetParams().Name,
+		signingKey:  signingKey,
+		publicKey:   publicKey,
+		statePath:   filepath.Join(cfg.AppDir, stateFilename),
+		domain:      domain,
+		quit:        make(chan struct{}),
+	}, nil
+}
+
+// Start starts publishing in the background. Whether the pruning point moved since the
+// last snapshot is checked right away, and then every --utxosnapshotinterval.
+func (p *Publisher) Start() {
+	log.Infof("Publishing UTXO snapshots to %s, signed by %s", p.destination, p.publicKey)
+	p.stopped.Add(1)
+	spawn("utxosnapshot.Publisher.run", p.run)
+}
+
+// Stop stops publishing. A snapshot that's being published is left incomplete, and
+// published again from the start once the node restarts.
+func (p *Publisher) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.quit)
+		p.stopped.Wait()
+	})
+}
+
+func (p *Publisher) run() {
+	defer p.stopped.Done()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		err := p.publishIfPruningPointMoved()
+		if errors.Is(err, errInterrupted) {
+			return
+		}
+		if err != nil {
+			log.Errorf("Error publishing a UTXO snapshot to %s, retrying in %s: %+v", p.destination, p.interval, err)
+		}
+
+		select {
+		case <-p.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishIfPruningPointMoved publishes a snapshot of the UTXO set of the pruning point,
+// unless it was already published or the node isn't synced yet
+func (p *Publisher) publishIfPruningPointMoved() error {
+	consensus := p.domain.Consensus()
+	isNearlySynced, err := consensus.IsNearlySynced()
+	if err != nil {
+		return err
+	}
+	if !isNearlySynced {
+		log.Debugf("Not publishing a UTXO snapshot since the node isn't synced")
+		return nil
+	}
+
+	pruningPoint, err := consensus.PruningPoint()
+	if err != nil {
+		return err
+	}
+	lastPublished, err := p.lastPublishedPruningPoint()
+	if err != nil {
+		return err
+	}
+	if lastPublished != nil && lastPublished.Equal(pruningPoint) {
+		return nil
+	}
+
+	err = p.publish(consensus, pruningPoint)
+	if errors.Is(err, ruleerrors.ErrWrongPruningPointHash) {
+		log.Infof("The pruning point moved while the snapshot of %s was published. "+
+			"The new one is published next", pruningPoint)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return p.saveLastPublishedPruningPoint(pruningPoint)
+}
+
+// publish publishes the snapshot of the UTXO set of pruningPoint. It returns an error
+// that wraps ruleerrors.ErrWrongPruningPointHash if the pruning point moved meanwhile.
+func (p *Publisher) publish(consensus externalapi.Consensus, pruningPoint *externalapi.DomainHash) error {
+	log.Infof("Publishing the UTXO snapshot of pruning point %s to %s", pruningPoint, p.destination)
+	start := time.Now()
+
+	header, err := consensus.GetBlockHeader(pruningPoint)
+	if err != nil {
+		return err
+	}
+	snapshotManifest := &manifest{
+		Network:          p.network,
+		PruningPointHash: pruningPoint.String(),
+		BlueScore:        header.BlueScore(),
+		DAAScore:         header.DAAScore(),
+		UTXOCommitment:   header.UTXOCommitment().String(),
+		Timestamp:        time.Now().UnixMilli(),
+		PublicKey:        p.publicKey,
+		Chunks:           []*chunk{},
+	}
+
+	utxoMultiset := multiset.New()
+	chunkWriter := newChunkWriter()
+	var fromOutpoint *externalapi.DomainOutpoint
+	for {
+		select {
+		case <-p.quit:
+			return errInterrupted
+		default:
+		}
+
+		pairs, err := consensus.GetPruningPointUTXOs(pruningPoint, fromOutpoint, utxosPerRead)
+		if err != nil {
+			return err
+		}
+		for _, pair := range pairs {
+			serializedUTXO, err := utxo.SerializeUTXO(pair.UTXOEntry, pair.Outpoint)
+			if err != nil {
+				return err
+			}
+			utxoMultiset.Add(serializedUTXO)
+			err = chunkWriter.add(serializedUTXO)
+			if err != nil {
+				return err
+			}
+			if chunkWriter.utxoCount == utxosPerChunk {
+				err = p.putChunk(snapshotManifest, chunkWriter)
+				if err != nil {
+					return err
+				}
+				chunkWriter = newChunkWriter()
+			}
+		}
+		if len(pairs) < utxosPerRead {
+			break
+		}
+		fromOutpoint = pairs[len(pairs)-1].Outpoint
+	}
+	if chunkWriter.utxoCount > 0 {
+		err = p.putChunk(snapshotManifest, chunkWriter)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The manifest is only published if the chunks are exactly the UTXO set that the
+	// pruning point commits to
+	if !utxoMultiset.Hash().Equal(header.UTXOCommitment()) {
+		return errors.Errorf("the UTXO set of pruning point %s hashes to %s, while its header commits to %s",
+			pruningPoint, utxoMultiset.Hash(), header.UTXOCommitment())
+	}
+
+	serializedManifest, err := json.MarshalIndent(snapshotManifest, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	signature, err := signManifest(serializedManifest, p.signingKey)
+	if err != nil {
+		return err
+	}
+	manifestPath := snapshotManifest.PruningPointHash + "/" + manifestFilename
+	err = p.destination.put(manifestPath, serializedManifest)
+	if err != nil {
+		return err
+	}
+	err = p.destination.put(manifestPath+".sig", []byte(signature+"\n"))
+	if err != nil {
+		return err
+	}
+
+	serializedLatest, err := json.MarshalIndent(&latest{
+		PruningPointHash: snapshotManifest.PruningPointHash,
+		BlueScore:        snapshotManifest.BlueScore,
+		Manifest:         manifestPath,
+	}, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = p.destination.put(latestFilename, serializedLatest)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Published the UTXO snapshot of pruning point %s with %d UTXOs in %d chunks in %s",
+		pruningPoint, snapshotManifest.UTXOCount, len(snapshotManifest.Chunks), time.Since(start))
+	return nil
+}
+
+func (p *Publisher) putChunk(snapshotManifest *manifest, chunkWriter *chunkWriter) error {
+	data, err := chunkWriter.close()
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(data)
+	snapshotChunk := &chunk{
+		Name:      fmt.Sprintf("utxos-%05d.bin.gz", len(snapshotManifest.Chunks)),
+		UTXOCount: chunkWriter.utxoCount,
+		Size:      len(data),
+		SHA256:    hex.EncodeToString(hash[:]),
+	}
+	err = p.destination.put(snapshotManifest.PruningPointHash+"/"+snapshotChunk.Name, data)
+	if err != nil {
+		return err
+	}
+	snapshotManifest.Chunks = append(snapshotManifest.Chunks, snapshotChunk)
+	snapshotManifest.UTXOCount += snapshotChunk.UTXOCount
+	return nil
+}
+
+func (p *Publisher) lastPublishedPruningPoint() (*externalapi.DomainHash, error) {
+	state, err := os.ReadFile(p.statePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	pruningPoint, err := externalapi.NewDomainHashFromString(strings.TrimSpace(string(state)))
+	if err != nil {
+		log.Warnf("Ignoring the malformed last published pruning point in %s", p.statePath)
+		return nil, nil
+	}
+	return pruningPoint, nil
+}
+
+func (p *Publisher) saveLastPublishedPruningPoint(pruningPoint *externalapi.DomainHash) error {
+	return errors.WithStack(os.WriteFile(p.statePath, []byte(pruningPoint.String()+"\n"), 0600))
+}
+
+// chunkWriter writes the UTXOs of a chunk. A chunk is a gzipped sequence of UTXOs, each
+// serialized the way the UTXO commitment hashes it and prefixed by its length as a
+// little-endian uint32.
+type chunkWriter struct {
+	buffer    bytes.Buffer
+	gzip      *gzip.Writer
+	utxoCount uint64
+}
+
+func newChunkWriter() *chunkWriter {
+	writer := &chunkWriter{}
+	writer.gzip = gzip.NewWriter(&writer.buffer)
+	return writer
+}
+
+func (w *chunkWriter) add(serializedUTXO []byte) error {
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(serializedUTXO)))
+	_, err := w.gzip.Write(length[:])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = w.gzip.Write(serializedUTXO)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	w.utxoCount++
+	return nil
+}
+
+func (w *chunkWriter) close() ([]byte, error) {
+	err := w.gzip.Close()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return w.buffer.Bytes(), nil
+}
@@ -0,0 +1,249 @@
+package utxosnapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/multiset"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/domain/domaintest"
+)
+
+func TestPublish(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		// This is done to reduce the pruning depth to 8 blocks
+		finalityDepth := 4
+		consensusConfig.FinalityDuration = time.Duration(finalityDepth) * consensusConfig.TargetTimePerBlock
+		consensusConfig.K = 0
+
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestPublish")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		scriptPublicKey, _ := testutils.OpTrueScript()
+		coinbaseData := &externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey}
+		var pruningPoint *externalapi.DomainHash
+		for pruningPoint == nil || pruningPoint.Equal(consensusConfig.GenesisHash) {
+			block, err := tc.BuildBlock(coinbaseData, nil)
+			if err != nil {
+				t.Fatalf("BuildBlock: %+v", err)
+			}
+			err = tc.ValidateAndInsertBlock(block, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+			pruningPoint, err = tc.PruningPoint()
+			if err != nil {
+				t.Fatalf("PruningPoint: %+v", err)
+			}
+		}
+
+		signingKey, err := loadSigningKey(filepath.Join(t.TempDir(), "utxosnapshot.key"))
+		if err != nil {
+			t.Fatalf("loadSigningKey: %+v", err)
+		}
+		publicKey, err := serializedPublicKey(signingKey)
+		if err != nil {
+			t.Fatalf("serializedPublicKey: %+v", err)
+		}
+		snapshotDirectory := t.TempDir()
+		publisher := &Publisher{
+			destination: &directoryDestination{directory: snapshotDirectory},
+			network:     consensusConfig.Name,
+			signingKey:  signingKey,
+			publicKey:   publicKey,
+			statePath:   filepath.Join(t.TempDir(), stateFilename),
+			domain:      domaintest.NewConsensusDomain(tc),
+			quit:        make(chan struct{}),
+		}
+		err = publisher.publish(tc, pruningPoint)
+		if err != nil {
+			t.Fatalf("publish: %+v", err)
+		}
+
+		var latestSnapshot latest
+		readJSON(t, filepath.Join(snapshotDirectory, latestFilename), &latestSnapshot)
+		if latestSnapshot.PruningPointHash != pruningPoint.String() {
+			t.Fatalf("Expected latest.json to point to %s, but it points to %s", pruningPoint,
+				latestSnapshot.PruningPointHash)
+		}
+
+		manifestPath := filepath.Join(snapshotDirectory, filepath.FromSlash(latestSnapshot.Manifest))
+		var snapshotManifest manifest
+		readJSON(t, manifestPath, &snapshotManifest)
+		verifyManifestSignature(t, manifestPath, publicKey)
+
+		header, err := tc.GetBlockHeader(pruningPoint)
+		if err != nil {
+			t.Fatalf("GetBlockHeader: %+v", err)
+		}
+		if snapshotManifest.Network != consensusConfig.Name || snapshotManifest.BlueScore != header.BlueScore() ||
+			snapshotManifest.UTXOCommitment != header.UTXOCommitment().String() {
+
+			t.Fatalf("The manifest doesn't match the pruning point: %+v", snapshotManifest)
+		}
+
+		// The UTXOs of the chunks must hash to the UTXO commitment of the pruning point
+		utxoMultiset := multiset.New()
+		utxoCount := uint64(0)
+		for _, snapshotChunk := range snapshotManifest.Chunks {
+			for _, serializedUTXO := range readChunk(t, filepath.Join(filepath.Dir(manifestPath), snapshotChunk.Name),
+				snapshotChunk) {
+
+				_, _, err := utxo.DeserializeUTXO(serializedUTXO)
+				if err != nil {
+					t.Fatalf("DeserializeUTXO: %+v", err)
+				}
+				utxoMultiset.Add(serializedUTXO)
+				utxoCount++
+			}
+		}
+		if utxoCount == 0 || utxoCount != snapshotManifest.UTXOCount {
+			t.Fatalf("Expected the %d UTXOs of the manifest in the chunks, but got %d",
+				snapshotManifest.UTXOCount, utxoCount)
+		}
+		if !utxoMultiset.Hash().Equal(header.UTXOCommitment()) {
+			t.Fatalf("The UTXOs of the snapshot hash to %s, while the pruning point commits to %s",
+				utxoMultiset.Hash(), header.UTXOCommitment())
+		}
+
+		// A snapshot of a pruning point that isn't the current one isn't published
+		err = publisher.publish(tc, consensusConfig.GenesisHash)
+		if err == nil {
+			t.Fatalf("Expected publishing the snapshot of a past pruning point to fail")
+		}
+	})
+}
+
+func TestLastPublishedPruningPoint(t *testing.T) {
+	publisher := &Publisher{statePath: filepath.Join(t.TempDir(), stateFilename)}
+
+	lastPublished, err := publisher.lastPublishedPruningPoint()
+	if err != nil || lastPublished != nil {
+		t.Fatalf("Expected no pruning point before anything was published, but got %v, %v", lastPublished, err)
+	}
+
+	pruningPoint := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{1})
+	err = publisher.saveLastPublishedPruningPoint(pruningPoint)
+	if err != nil {
+		t.Fatalf("saveLastPublishedPruningPoint: %+v", err)
+	}
+	lastPublished, err = publisher.lastPublishedPruningPoint()
+	if err != nil || !lastPublished.Equal(pruningPoint) {
+		t.Fatalf("Expected the pruning point %s, but got %v, %v", pruningPoint, lastPublished, err)
+	}
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "keys", "utxosnapshot.key")
+	generatedKey, err := loadSigningKey(keyPath)
+	if err != nil {
+		t.Fatalf("loadSigningKey: %+v", err)
+	}
+	loadedKey, err := loadSigningKey(keyPath)
+	if err != nil {
+		t.Fatalf("loadSigningKey: %+v", err)
+	}
+	if *generatedKey.SerializePrivateKey() != *loadedKey.SerializePrivateKey() {
+		t.Fatalf("Expected the generated key to be loaded again")
+	}
+
+	err = os.WriteFile(keyPath, []byte("not hex"), 0600)
+	if err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+	_, err = loadSigningKey(keyPath)
+	if err == nil {
+		t.Fatalf("Expected a malformed key to be rejected")
+	}
+}
+
+func readJSON(t *testing.T, path string, value interface{}) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+	err = json.Unmarshal(data, value)
+	if err != nil {
+		t.Fatalf("Unmarshal %s: %s", path, err)
+	}
+}
+
+func verifyManifestSignature(t *testing.T, manifestPath string, publicKeyHex string) {
+	serializedManifest, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+	signatureHex, err := os.ReadFile(manifestPath + ".sig")
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+	signatureBytes, err := hex.DecodeString(strings.TrimSpace(string(signatureHex)))
+	if err != nil {
+		t.Fatalf("DecodeString: %s", err)
+	}
+	signature, err := secp256k1.DeserializeSchnorrSignatureFromSlice(signatureBytes)
+	if err != nil {
+		t.Fatalf("DeserializeSchnorrSignatureFromSlice: %s", err)
+	}
+	publicKeyBytes, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		t.Fatalf("DecodeString: %s", err)
+	}
+	publicKey, err := secp256k1.DeserializeSchnorrPubKey(publicKeyBytes)
+	if err != nil {
+		t.Fatalf("DeserializeSchnorrPubKey: %s", err)
+	}
+	hash := secp256k1.Hash(sha256.Sum256(serializedManifest))
+	if !publicKey.SchnorrVerify(&hash, signature) {
+		t.Fatalf("The signature of the manifest is invalid")
+	}
+}
+
+func readChunk(t *testing.T, chunkPath string, snapshotChunk *chunk) [][]byte {
+	data, err := os.ReadFile(chunkPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+	hash := sha256.Sum256(data)
+	if len(data) != snapshotChunk.Size || hex.EncodeToString(hash[:]) != snapshotChunk.SHA256 {
+		t.Fatalf("The chunk %s doesn't match its manifest entry", snapshotChunk.Name)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewReader: %s", err)
+	}
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll: %s", err)
+	}
+
+	var serializedUTXOs [][]byte
+	for len(decompressed) > 0 {
+		length := binary.LittleEndian.Uint32(decompressed[:4])
+		serializedUTXOs = append(serializedUTXOs, decompressed[4:4+length])
+		decompressed = decompressed[4+length:]
+	}
+	if uint64(len(serializedUTXOs)) != snapshotChunk.UTXOCount {
+		t.Fatalf("Expected %d UTXOs in the chunk %s, but got %d", snapshotChunk.UTXOCount, snapshotChunk.Name,
+			len(serializedUTXOs))
+	}
+	return serializedUTXOs
+}
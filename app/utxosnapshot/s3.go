@@ -0,0 +1,97 @@
+package utxosnapshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/errors"
+)
+
+// s3PutTimeout is how long uploading a single object may take
+const s3PutTimeout = 5 * time.Minute
+
+// s3Destination publishes snapshots to a bucket of Amazon S3 or of an S3-compatible
+// object store. The client picks virtual-hosted or path-style addressing by the
+// endpoint, and looks up the region of the bucket unless it's given.
+type s3Destination struct {
+	endpoint *url.URL
+	bucket   string
+	prefix   string
+	client   *minio.Client
+}
+
+// newS3Destination returns the S3 destination of the given URL. The keys are taken
+// from the environment, as AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and optionally
+// AWS_SESSION_TOKEN, unless the URL includes them, and the region is taken from the
+// region query parameter.
+func newS3Destination(parsedURL *url.URL) (*s3Destination, error) {
+	if parsedURL.Host == "" {
+		return nil, errors.New("the UTXO snapshot URL has no host")
+	}
+	bucket, prefix, _ := strings.Cut(strings.Trim(parsedURL.Path, "/"), "/")
+	if bucket == "" {
+		return nil, errors.New("the UTXO snapshot URL has no bucket")
+	}
+
+	var s3Credentials *credentials.Credentials
+	if parsedURL.User != nil {
+		secretKey, _ := parsedURL.User.Password()
+		if parsedURL.User.Username() == "" || secretKey == "" {
+			return nil, errors.New("the UTXO snapshot URL has incomplete S3 keys")
+		}
+		s3Credentials = credentials.NewStaticV4(parsedURL.User.Username(), secretKey, "")
+	} else {
+		if os.Getenv("AWS_ACCESS_KEY_ID") == "" || os.Getenv("AWS_SECRET_ACCESS_KEY") == "" {
+			return nil, errors.New("the UTXO snapshot URL has no S3 keys, and neither AWS_ACCESS_KEY_ID " +
+				"nor AWS_SECRET_ACCESS_KEY are set")
+		}
+		s3Credentials = credentials.NewEnvAWS()
+	}
+
+	endpoint := &url.URL{Scheme: "https", Host: parsedURL.Host}
+	if parsedURL.Scheme == "s3+http" {
+		endpoint.Scheme = "http"
+	}
+	client, err := minio.New(endpoint.Host, &minio.Options{
+		Creds:  s3Credentials,
+		Secure: endpoint.Scheme == "https",
+		Region: parsedURL.Query().Get("region"),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the S3 client of the UTXO snapshot URL")
+	}
+
+	return &s3Destination{
+		endpoint: endpoint,
+		bucket:   bucket,
+		prefix:   strings.Trim(prefix, "/"),
+		client:   client,
+	}, nil
+}
+
+func (d *s3Destination) put(path string, data []byte) error {
+	key := path
+	if d.prefix != "" {
+		key = d.prefix + "/" + path
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3PutTimeout)
+	defer cancel()
+	_, err := d.client.PutObject(ctx, d.bucket, key, bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "error uploading %s to %s", key, d)
+	}
+	return nil
+}
+
+func (d *s3Destination) String() string {
+	return fmt.Sprintf("%s/%s/%s", d.endpoint, d.bucket, d.prefix)
+}
@@ -0,0 +1,126 @@
+package utxosnapshot
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// readS3Body returns the body of the given upload, decoding it if it was
+// sent in signed chunks, as uploads that aren't sent over TLS are
+func readS3Body(request *http.Request) ([]byte, error) {
+	if request.Header.Get("x-amz-content-sha256") != "STREAMING-AWS4-HMAC-SHA256-PAYLOAD" {
+		return io.ReadAll(request.Body)
+	}
+	var body []byte
+	reader := bufio.NewReader(request.Body)
+	for {
+		chunkHeader, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		chunkSizeHex, _, _ := strings.Cut(chunkHeader, ";")
+		chunkSize, err := strconv.ParseInt(chunkSizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		chunk := make([]byte, chunkSize+2) // The chunk is followed by \r\n
+		_, err = io.ReadFull(reader, chunk)
+		if err != nil {
+			return nil, err
+		}
+		if chunkSize == 0 {
+			return body, nil
+		}
+		body = append(body, chunk[:chunkSize]...)
+	}
+}
+
+func TestS3Put(t *testing.T) {
+	objects := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if !strings.HasPrefix(request.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+			writer.WriteHeader(http.StatusForbidden)
+			return
+		}
+		// The region of the bucket is looked up, since the URL doesn't include it
+		if _, ok := request.URL.Query()["location"]; ok && request.Method == http.MethodGet {
+			writer.Write([]byte(`<LocationConstraint>eu-west-1</LocationConstraint>`))
+			return
+		}
+		if request.Method != http.MethodPut ||
+			!strings.Contains(request.Header.Get("Authorization"), "/eu-west-1/s3/aws4_request") {
+
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, err := readS3Body(request)
+		if err != nil {
+			t.Errorf("readS3Body: %s", err)
+		}
+		objects[request.URL.EscapedPath()] = string(body)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Parse: %s", err)
+	}
+	destination, err := parseDestinationURL("s3+http://key:secret@" + serverURL.Host + "/bucket/some/prefix/")
+	if err != nil {
+		t.Fatalf("parseDestinationURL: %+v", err)
+	}
+	err = destination.put("snapshot/a b.json", []byte("data"))
+	if err != nil {
+		t.Fatalf("put: %+v", err)
+	}
+	if objects["/bucket/some/prefix/snapshot/a%20b.json"] != "data" {
+		t.Fatalf("Expected the object to be uploaded under its prefix, but got %v", objects)
+	}
+
+	destination, err = parseDestinationURL("s3+http://other:secret@" + serverURL.Host + "/bucket")
+	if err != nil {
+		t.Fatalf("parseDestinationURL: %+v", err)
+	}
+	err = destination.put("object", []byte("data"))
+	if err == nil {
+		t.Fatalf("Expected the upload to be rejected")
+	}
+}
+
+func TestParseDestinationURL(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	invalidURLs := []string{
+		"http://example.com/bucket",
+		"file://example.com/var/snapshots",
+		"file:relative/path",
+		"s3://key:secret@example.com",
+		"s3://example.com/bucket",
+	}
+	for _, invalidURL := range invalidURLs {
+		_, err := parseDestinationURL(invalidURL)
+		if err == nil {
+			t.Errorf("Expected %s to be rejected", invalidURL)
+		}
+	}
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	destination, err := parseDestinationURL("s3://example.com/bucket?region=eu-west-1")
+	if err != nil {
+		t.Fatalf("parseDestinationURL: %+v", err)
+	}
+	s3 := destination.(*s3Destination)
+	if s3.endpoint.Scheme != "https" || s3.bucket != "bucket" || s3.prefix != "" ||
+		s3.client.EndpointURL().Host != "example.com" {
+
+		t.Fatalf("Unexpected S3 destination %+v", s3)
+	}
+}
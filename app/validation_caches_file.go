@@ -0,0 +1,44 @@
+package app
+
+import (
+	"path/filepath"
+
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+)
+
+// validationCachesFilename is the name of the file, under the app directory, that the
+// validation caches are saved to on shutdown when --persistvalidationcaches is specified
+const validationCachesFilename = "validationcaches.dat"
+
+func validationCachesFilePath(cfg *config.Config) string {
+	return filepath.Join(cfg.AppDir, validationCachesFilename)
+}
+
+// loadValidationCaches fills the validation caches with the entries that were saved on
+// the last shutdown. A file that can't be read is only logged, since the caches only
+// spare verifications.
+func loadValidationCaches(cfg *config.Config, validationCaches *txscript.ValidationCaches) {
+	path := validationCachesFilePath(cfg)
+	loadedCount, err := validationCaches.LoadFromFile(path)
+	if err != nil {
+		log.Errorf("Error loading the validation caches from %s: %+v", path, err)
+		return
+	}
+	if loadedCount == 0 {
+		return
+	}
+	log.Infof("Loaded %d validation cache entries from %s", loadedCount, path)
+}
+
+// saveValidationCaches saves the entries of the validation caches, so that they're loaded
+// on the next startup
+func saveValidationCaches(cfg *config.Config, validationCaches *txscript.ValidationCaches) {
+	path := validationCachesFilePath(cfg)
+	savedCount, err := validationCaches.SaveToFile(path)
+	if err != nil {
+		log.Errorf("Error saving the validation caches to %s: %+v", path, err)
+		return
+	}
+	log.Infof("Saved %d validation cache entries to %s", savedCount, path)
+}
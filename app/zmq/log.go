@@ -0,0 +1,9 @@
+package zmq
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/panics"
+)
+
+var log = logger.RegisterSubSystem("ZMQP")
+var spawn = panics.GoroutineWrapperFunc(log)
@@ -0,0 +1,246 @@
+package zmq
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// The topics published by the publisher. Every message consists of three parts:
+// the topic, the body, and a 4 byte little-endian sequence number that's
+// incremented separately for every topic.
+//
+// The body of the hash topics is the 32 byte hash of the block or the transaction ID.
+// The body of the raw topics is the block or transaction serialized the way
+// it's serialized in the P2P protocol.
+const (
+	TopicHashBlock = "hashblock"
+	TopicHashTx    = "hashtx"
+	TopicRawBlock  = "rawblock"
+	TopicRawTx     = "rawtx"
+)
+
+// endpoint is a single address the publisher listens on. It may publish more than one topic.
+type endpoint struct {
+	address  string
+	listener net.Listener
+
+	subscribers     map[*subscriber]struct{}
+	subscribersLock sync.Mutex
+}
+
+// Publisher publishes blocks and transactions over ZeroMQ to any SUB socket
+// that connects to one of its endpoints
+type Publisher struct {
+	endpoints       []*endpoint
+	topicsEndpoints map[string][]*endpoint
+
+	sequenceNumbers     map[string]uint32
+	sequenceNumbersLock sync.Mutex
+
+	quit     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewPublisher creates a new Publisher for the topics enabled in the given config.
+// It returns nil if no topic is enabled. Use Start() to begin listening.
+func NewPublisher(cfg *config.Config) *Publisher {
+	topicsAddresses := map[string]string{
+		TopicHashBlock: cfg.ZMQPubHashBlock,
+		TopicHashTx:    cfg.ZMQPubHashTx,
+		TopicRawBlock:  cfg.ZMQPubRawBlock,
+		TopicRawTx:     cfg.ZMQPubRawTx,
+	}
+
+	publisher := &Publisher{
+		topicsEndpoints: make(map[string][]*endpoint),
+		sequenceNumbers: make(map[string]uint32),
+		quit:            make(chan struct{}),
+	}
+	// Topics configured with the same address share a single endpoint
+	endpointsByAddress := make(map[string]*endpoint)
+	for topic, address := range topicsAddresses {
+		if address == "" {
+			continue
+		}
+		address = strings.TrimPrefix(address, config.ZMQAddressPrefix)
+		topicEndpoint, ok := endpointsByAddress[address]
+		if !ok {
+			topicEndpoint = &endpoint{
+				address:     address,
+				subscribers: make(map[*subscriber]struct{}),
+			}
+			endpointsByAddress[address] = topicEndpoint
+			publisher.endpoints = append(publisher.endpoints, topicEndpoint)
+		}
+		publisher.topicsEndpoints[topic] = append(publisher.topicsEndpoints[topic], topicEndpoint)
+	}
+
+	if len(publisher.endpoints) == 0 {
+		return nil
+	}
+	return publisher
+}
+
+// Start starts listening on all the endpoints
+func (p *Publisher) Start() error {
+	for i, endpoint := range p.endpoints {
+		listener, err := net.Listen("tcp", endpoint.address)
+		if err != nil {
+			p.closeListeners(p.endpoints[:i])
+			return errors.Wrapf(err, "failed to listen for ZMQ connections on %s", endpoint.address)
+		}
+		endpoint.listener = listener
+		log.Infof("ZMQ publisher listening on %s", endpoint.address)
+	}
+
+	for _, endpoint := range p.endpoints {
+		endpoint := endpoint
+		spawn("zmq.Publisher.acceptConnections", func() {
+			p.acceptConnections(endpoint)
+		})
+	}
+	return nil
+}
+
+// Stop closes all the endpoints and their connections
+func (p *Publisher) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.quit)
+		p.closeListeners(p.endpoints)
+
+		for _, endpoint := range p.endpoints {
+			endpoint.subscribersLock.Lock()
+			for subscriber := range endpoint.subscribers {
+				subscriber.close()
+			}
+			endpoint.subscribersLock.Unlock()
+		}
+	})
+}
+
+// PublishBlock publishes a block that was added to the DAG
+func (p *Publisher) PublishBlock(block *externalapi.DomainBlock) {
+	if p.hasTopic(TopicHashBlock) {
+		p.publish(TopicHashBlock, consensushashing.BlockHash(block).ByteSlice())
+	}
+	if p.hasTopic(TopicRawBlock) {
+		serializedBlock, err := serializeBlock(block)
+		if err != nil {
+			log.Errorf("Error serializing block %s: %s", consensushashing.BlockHash(block), err)
+			return
+		}
+		p.publish(TopicRawBlock, serializedBlock)
+	}
+}
+
+// PublishTransactions publishes transactions that were added to the mempool
+func (p *Publisher) PublishTransactions(transactions []*externalapi.DomainTransaction) {
+	for _, transaction := range transactions {
+		if p.hasTopic(TopicHashTx) {
+			p.publish(TopicHashTx, consensushashing.TransactionID(transaction).ByteSlice())
+		}
+		if p.hasTopic(TopicRawTx) {
+			serializedTransaction, err := serializeTransaction(transaction)
+			if err != nil {
+				log.Errorf("Error serializing transaction %s: %s", consensushashing.TransactionID(transaction), err)
+				continue
+			}
+			p.publish(TopicRawTx, serializedTransaction)
+		}
+	}
+}
+
+func serializeBlock(block *externalapi.DomainBlock) ([]byte, error) {
+	message, err := protowire.FromAppMessage(appmessage.DomainBlockToMsgBlock(block))
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(message.GetBlock())
+}
+
+func serializeTransaction(transaction *externalapi.DomainTransaction) ([]byte, error) {
+	message, err := protowire.FromAppMessage(appmessage.DomainTransactionToMsgTx(transaction))
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(message.GetTransaction())
+}
+
+func (p *Publisher) hasTopic(topic string) bool {
+	_, ok := p.topicsEndpoints[topic]
+	return ok
+}
+
+func (p *Publisher) publish(topic string, body []byte) {
+	p.sequenceNumbersLock.Lock()
+	sequenceNumber := p.sequenceNumbers[topic]
+	p.sequenceNumbers[topic]++
+	p.sequenceNumbersLock.Unlock()
+
+	encodedMessage := encodeMessage([][]byte{
+		[]byte(topic),
+		body,
+		binary.LittleEndian.AppendUint32(nil, sequenceNumber),
+	})
+	for _, endpoint := range p.topicsEndpoints[topic] {
+		endpoint.subscribersLock.Lock()
+		for subscriber := range endpoint.subscribers {
+			if subscriber.isSubscribedTo(topic) {
+				subscriber.enqueue(encodedMessage)
+			}
+		}
+		endpoint.subscribersLock.Unlock()
+	}
+}
+
+func (p *Publisher) closeListeners(endpoints []*endpoint) {
+	for _, endpoint := range endpoints {
+		if endpoint.listener == nil {
+			continue
+		}
+		err := endpoint.listener.Close()
+		if err != nil {
+			log.Warnf("Error closing ZMQ listener %s: %s", endpoint.address, err)
+		}
+	}
+}
+
+func (p *Publisher) acceptConnections(endpoint *endpoint) {
+	for {
+		conn, err := endpoint.listener.Accept()
+		if err != nil {
+			select {
+			case <-p.quit:
+				return
+			default:
+			}
+			log.Errorf("Error accepting ZMQ connection on %s: %s", endpoint.address, err)
+			return
+		}
+
+		subscriber := newSubscriber(conn)
+		endpoint.subscribersLock.Lock()
+		endpoint.subscribers[subscriber] = struct{}{}
+		endpoint.subscribersLock.Unlock()
+
+		log.Infof("New ZMQ connection from %s", conn.RemoteAddr())
+		spawn("zmq.subscriber.run", func() {
+			subscriber.run()
+
+			endpoint.subscribersLock.Lock()
+			delete(endpoint.subscribers, subscriber)
+			endpoint.subscribersLock.Unlock()
+			log.Infof("ZMQ connection from %s closed", conn.RemoteAddr())
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package zmq
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+)
+
+func TestPublisher(t *testing.T) {
+	cfg := &config.Config{Flags: &config.Flags{
+		ZMQPubHashTx:    "tcp://127.0.0.1:0",
+		ZMQPubHashBlock: "tcp://127.0.0.1:0",
+	}}
+	publisher := NewPublisher(cfg)
+	if len(publisher.endpoints) != 1 {
+		t.Fatalf("Topics with the same address are expected to share an endpoint, but got %d endpoints",
+			len(publisher.endpoints))
+	}
+	err := publisher.Start()
+	if err != nil {
+		t.Fatalf("Start: %s", err)
+	}
+	defer publisher.Stop()
+
+	endpoint := publisher.endpoints[0]
+	conn, err := net.Dial("tcp", endpoint.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial: %s", err)
+	}
+	defer conn.Close()
+	err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if err != nil {
+		t.Fatalf("SetDeadline: %s", err)
+	}
+
+	// Perform the handshake of a SUB socket
+	err = writeGreeting(conn)
+	if err != nil {
+		t.Fatalf("writeGreeting: %s", err)
+	}
+	err = readGreeting(conn)
+	if err != nil {
+		t.Fatalf("readGreeting: %s", err)
+	}
+	_, err = conn.Write(encodeCommand(commandReady, encodeProperties(map[string]string{propertySocketType: "SUB"})))
+	if err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+	ready, err := readFrame(conn)
+	if err != nil {
+		t.Fatalf("readFrame: %s", err)
+	}
+	name, data, err := parseCommand(ready.body)
+	if err != nil {
+		t.Fatalf("parseCommand: %s", err)
+	}
+	properties, err := parseProperties(data)
+	if err != nil {
+		t.Fatalf("parseProperties: %s", err)
+	}
+	if name != commandReady || properties[propertySocketType] != socketTypePub {
+		t.Fatalf("Unexpected handshake command %s with properties %v", name, properties)
+	}
+
+	// Subscribe to all the hash topics, ZMTP 3.0 style
+	_, err = conn.Write(encodeFrame(0, append([]byte{1}, "hash"...)))
+	if err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+	waitForSubscription(t, endpoint, TopicHashTx)
+
+	transaction := &externalapi.DomainTransaction{
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+		Payload:      []byte{},
+	}
+	for expectedSequenceNumber := uint32(0); expectedSequenceNumber < 2; expectedSequenceNumber++ {
+		publisher.PublishTransactions([]*externalapi.DomainTransaction{transaction})
+
+		expectedParts := [][]byte{
+			[]byte(TopicHashTx),
+			consensushashing.TransactionID(transaction).ByteSlice(),
+			binary.LittleEndian.AppendUint32(nil, expectedSequenceNumber),
+		}
+		for i, expectedPart := range expectedParts {
+			part, err := readFrame(conn)
+			if err != nil {
+				t.Fatalf("readFrame: %s", err)
+			}
+			expectedHasMore := i < len(expectedParts)-1
+			if part.isCommand || part.hasMore != expectedHasMore || !bytes.Equal(part.body, expectedPart) {
+				t.Fatalf("Unexpected part %d. Want: %x (hasMore: %t), got: %x (hasMore: %t)",
+					i, expectedPart, expectedHasMore, part.body, part.hasMore)
+			}
+		}
+	}
+}
+
+func waitForSubscription(t *testing.T, endpoint *endpoint, topic string) {
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		endpoint.subscribersLock.Lock()
+		for subscriber := range endpoint.subscribers {
+			if subscriber.isSubscribedTo(topic) {
+				endpoint.subscribersLock.Unlock()
+				return
+			}
+		}
+		endpoint.subscribersLock.Unlock()
+	}
+	t.Fatalf("Timed out waiting for a subscription to %s", topic)
+}
+
+// libzmqSubscriberScript subscribes to the hash topics with a SUB socket of libzmq, the
+// reference implementation of ZeroMQ, and prints the parts of the first message it receives
+// in hex, one per line
+const libzmqSubscriberScript = `
+import sys
+import zmq
+socket = zmq.Context().socket(zmq.SUB)
+socket.setsockopt(zmq.RCVTIMEO, 10000)
+socket.setsockopt(zmq.SUBSCRIBE, b"hash")
+socket.connect(sys.argv[1])
+print("\n".join(part.hex() for part in socket.recv_multipart()), flush=True)
+`
+
+// TestPublisherConformance checks that a SUB socket of libzmq receives the messages of the
+// publisher. It's skipped unless the Python at KASPAD_TEST_PYTHON, or python3, has pyzmq installed.
+func TestPublisherConformance(t *testing.T) {
+	python := os.Getenv("KASPAD_TEST_PYTHON")
+	if python == "" {
+		python = "python3"
+	}
+	if exec.Command(python, "-c", "import zmq").Run() != nil {
+		t.Skipf("%s has no pyzmq", python)
+	}
+
+	cfg := &config.Config{Flags: &config.Flags{ZMQPubHashTx: "tcp://127.0.0.1:0"}}
+	publisher := NewPublisher(cfg)
+	err := publisher.Start()
+	if err != nil {
+		t.Fatalf("Start: %s", err)
+	}
+	defer publisher.Stop()
+	endpoint := publisher.endpoints[0]
+
+	subscriber := exec.Command(python, "-c", libzmqSubscriberScript, "tcp://"+endpoint.listener.Addr().String())
+	output := &bytes.Buffer{}
+	subscriber.Stdout = output
+	subscriber.Stderr = os.Stderr
+	err = subscriber.Start()
+	if err != nil {
+		t.Fatalf("Start: %s", err)
+	}
+	defer subscriber.Process.Kill()
+	waitForSubscription(t, endpoint, TopicHashTx)
+
+	transaction := &externalapi.DomainTransaction{
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+		Payload:      []byte{},
+	}
+	publisher.PublishTransactions([]*externalapi.DomainTransaction{transaction})
+	err = subscriber.Wait()
+	if err != nil {
+		t.Fatalf("The libzmq subscriber failed: %s", err)
+	}
+
+	expectedParts := []string{
+		hex.EncodeToString([]byte(TopicHashTx)),
+		hex.EncodeToString(consensushashing.TransactionID(transaction).ByteSlice()),
+		"00000000",
+	}
+	parts := strings.Fields(output.String())
+	if !reflect.DeepEqual(parts, expectedParts) {
+		t.Fatalf("Expected the parts %v but got %v", expectedParts, parts)
+	}
+}
@@ -0,0 +1,190 @@
+package zmq
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	handshakeTimeout = 10 * time.Second
+	writeTimeout     = 10 * time.Second
+
+	// sendQueueSize is the number of messages queued for a subscriber before
+	// new messages are dropped. It matches the default high water mark of ZeroMQ.
+	sendQueueSize = 1000
+)
+
+// subscriber is a single connection of a SUB socket to one of the endpoints
+type subscriber struct {
+	conn      net.Conn
+	sendQueue chan []byte
+
+	lock          sync.Mutex
+	subscriptions map[string]int
+
+	closeOnce sync.Once
+	quit      chan struct{}
+}
+
+func newSubscriber(conn net.Conn) *subscriber {
+	return &subscriber{
+		conn:          conn,
+		sendQueue:     make(chan []byte, sendQueueSize),
+		subscriptions: make(map[string]int),
+		quit:          make(chan struct{}),
+	}
+}
+
+func (s *subscriber) String() string {
+	return s.conn.RemoteAddr().String()
+}
+
+func (s *subscriber) close() {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+		err := s.conn.Close()
+		if err != nil && !errors.Is(err, net.ErrClosed) {
+			log.Warnf("Error closing ZMQ connection %s: %s", s, err)
+		}
+	})
+}
+
+// run performs the handshake and then handles the subscriber until it disconnects
+func (s *subscriber) run() {
+	defer s.close()
+
+	err := s.conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err != nil {
+		log.Warnf("Error setting the handshake deadline of ZMQ connection %s: %s", s, err)
+		return
+	}
+	reader := bufio.NewReader(s.conn)
+	err = handshake(struct {
+		io.Reader
+		io.Writer
+	}{reader, s.conn})
+	if err != nil {
+		log.Infof("ZMQ handshake with %s failed: %s", s, err)
+		return
+	}
+	err = s.conn.SetDeadline(time.Time{})
+	if err != nil {
+		log.Warnf("Error clearing the handshake deadline of ZMQ connection %s: %s", s, err)
+		return
+	}
+
+	spawn("zmq.subscriber.sendMessages", s.sendMessages)
+
+	for {
+		frame, err := readFrame(reader)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Debugf("Error reading from ZMQ connection %s: %s", s, err)
+			}
+			return
+		}
+		err = s.handleFrame(frame)
+		if err != nil {
+			log.Infof("Disconnecting ZMQ connection %s: %s", s, err)
+			return
+		}
+	}
+}
+
+func (s *subscriber) handleFrame(frame *frame) error {
+	if !frame.isCommand {
+		// Subscriptions are messages prefixed with 1 for subscribe and 0 for cancel
+		if len(frame.body) == 0 {
+			return nil
+		}
+		switch frame.body[0] {
+		case 1:
+			s.subscribe(string(frame.body[1:]))
+		case 0:
+			s.cancel(string(frame.body[1:]))
+		}
+		return nil
+	}
+
+	name, data, err := parseCommand(frame.body)
+	if err != nil {
+		return err
+	}
+	if name == commandPing {
+		// A PING carries a 2 byte TTL followed by a context that's echoed in the PONG
+		if len(data) < 2 {
+			return errors.New("malformed PING command")
+		}
+		s.enqueue(encodeCommand(commandPong, data[2:]))
+	}
+	return nil
+}
+
+func (s *subscriber) subscribe(prefix string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	log.Debugf("ZMQ connection %s subscribed to '%s'", s, prefix)
+	s.subscriptions[prefix]++
+}
+
+func (s *subscriber) cancel(prefix string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.subscriptions[prefix] <= 1 {
+		delete(s.subscriptions, prefix)
+		return
+	}
+	s.subscriptions[prefix]--
+}
+
+func (s *subscriber) isSubscribedTo(topic string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for prefix := range s.subscriptions {
+		if strings.HasPrefix(topic, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue queues an encoded message to be sent to the subscriber.
+// Like in ZeroMQ, the message is dropped if the subscriber's queue is full.
+func (s *subscriber) enqueue(encodedMessage []byte) {
+	select {
+	case s.sendQueue <- encodedMessage:
+	default:
+		log.Debugf("Dropping a message to ZMQ connection %s since its queue is full", s)
+	}
+}
+
+func (s *subscriber) sendMessages() {
+	defer s.close()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case encodedMessage := <-s.sendQueue:
+			err := s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err != nil {
+				log.Debugf("Error setting the write deadline of ZMQ connection %s: %s", s, err)
+				return
+			}
+			_, err = s.conn.Write(encodedMessage)
+			if err != nil {
+				log.Debugf("Error writing to ZMQ connection %s: %s", s, err)
+				return
+			}
+		}
+	}
+}
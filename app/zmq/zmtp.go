@@ -0,0 +1,235 @@
+package zmq
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// This file implements the parts of ZMTP 3.0 (https://rfc.zeromq.org/spec/23/)
+// that a PUB socket using the NULL security mechanism requires. Since the greeting
+// announces 3.0, peers of later versions send their subscriptions as messages too,
+// rather than as the SUBSCRIBE and CANCEL commands of ZMTP 3.1.
+
+const (
+	greetingLength = 64
+
+	flagMore    = 0x01
+	flagLong    = 0x02
+	flagCommand = 0x04
+
+	commandReady = "READY"
+	commandPing  = "PING"
+	commandPong  = "PONG"
+
+	propertySocketType = "Socket-Type"
+	socketTypePub      = "PUB"
+
+	mechanismNull = "NULL"
+
+	// maxIncomingFrameSize bounds the frames subscribers may send. Subscribers
+	// only send subscriptions and heartbeats, which are expected to be small.
+	maxIncomingFrameSize = 64 * 1024
+)
+
+// subscriberSocketTypes are the socket types that may connect to a PUB socket
+var subscriberSocketTypes = map[string]struct{}{
+	"SUB":  {},
+	"XSUB": {},
+}
+
+type frame struct {
+	isCommand bool
+	hasMore   bool
+	body      []byte
+}
+
+func writeGreeting(w io.Writer) error {
+	greeting := make([]byte, greetingLength)
+	greeting[0] = 0xff
+	greeting[9] = 0x7f
+	greeting[10] = 3 // Major version
+	greeting[11] = 0 // Minor version
+	copy(greeting[12:32], mechanismNull)
+	// as-server and filler are left zero
+	_, err := w.Write(greeting)
+	return err
+}
+
+func readGreeting(r io.Reader) error {
+	greeting := make([]byte, greetingLength)
+	_, err := io.ReadFull(r, greeting)
+	if err != nil {
+		return err
+	}
+	if greeting[0] != 0xff || greeting[9] != 0x7f {
+		return errors.New("invalid ZMTP greeting signature")
+	}
+	majorVersion := greeting[10]
+	if majorVersion < 3 {
+		return errors.Errorf("unsupported ZMTP version %d", majorVersion)
+	}
+	mechanism := string(bytes.TrimRight(greeting[12:32], "\x00"))
+	if mechanism != mechanismNull {
+		return errors.Errorf("unsupported ZMTP security mechanism %s", mechanism)
+	}
+	return nil
+}
+
+func encodeFrame(flags byte, body []byte) []byte {
+	if len(body) > 0xff {
+		encoded := make([]byte, 9, 9+len(body))
+		encoded[0] = flags | flagLong
+		binary.BigEndian.PutUint64(encoded[1:], uint64(len(body)))
+		return append(encoded, body...)
+	}
+	encoded := make([]byte, 2, 2+len(body))
+	encoded[0] = flags
+	encoded[1] = byte(len(body))
+	return append(encoded, body...)
+}
+
+// encodeMessage encodes the given parts as a single multipart message
+func encodeMessage(parts [][]byte) []byte {
+	var encoded []byte
+	for i, part := range parts {
+		var flags byte
+		if i < len(parts)-1 {
+			flags = flagMore
+		}
+		encoded = append(encoded, encodeFrame(flags, part)...)
+	}
+	return encoded
+}
+
+func encodeCommand(name string, data []byte) []byte {
+	body := make([]byte, 0, 1+len(name)+len(data))
+	body = append(body, byte(len(name)))
+	body = append(body, name...)
+	body = append(body, data...)
+	return encodeFrame(flagCommand, body)
+}
+
+func readFrame(r io.Reader) (*frame, error) {
+	header := make([]byte, 1)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, err
+	}
+	flags := header[0]
+
+	var size uint64
+	if flags&flagLong != 0 {
+		sizeBytes := make([]byte, 8)
+		_, err = io.ReadFull(r, sizeBytes)
+		if err != nil {
+			return nil, err
+		}
+		size = binary.BigEndian.Uint64(sizeBytes)
+	} else {
+		sizeBytes := make([]byte, 1)
+		_, err = io.ReadFull(r, sizeBytes)
+		if err != nil {
+			return nil, err
+		}
+		size = uint64(sizeBytes[0])
+	}
+	if size > maxIncomingFrameSize {
+		return nil, errors.Errorf("frame of size %d exceeds the maximum of %d", size, maxIncomingFrameSize)
+	}
+
+	body := make([]byte, size)
+	_, err = io.ReadFull(r, body)
+	if err != nil {
+		return nil, err
+	}
+	return &frame{
+		isCommand: flags&flagCommand != 0,
+		hasMore:   flags&flagMore != 0,
+		body:      body,
+	}, nil
+}
+
+func parseCommand(body []byte) (name string, data []byte, err error) {
+	if len(body) == 0 || len(body) < 1+int(body[0]) {
+		return "", nil, errors.New("malformed ZMTP command")
+	}
+	nameLength := int(body[0])
+	return string(body[1 : 1+nameLength]), body[1+nameLength:], nil
+}
+
+func encodeProperties(properties map[string]string) []byte {
+	var encoded []byte
+	for name, value := range properties {
+		encoded = append(encoded, byte(len(name)))
+		encoded = append(encoded, name...)
+		encoded = binary.BigEndian.AppendUint32(encoded, uint32(len(value)))
+		encoded = append(encoded, value...)
+	}
+	return encoded
+}
+
+func parseProperties(data []byte) (map[string]string, error) {
+	properties := make(map[string]string)
+	for len(data) > 0 {
+		nameLength := int(data[0])
+		if len(data) < 1+nameLength+4 {
+			return nil, errors.New("malformed ZMTP property")
+		}
+		name := string(data[1 : 1+nameLength])
+		data = data[1+nameLength:]
+
+		valueLength := binary.BigEndian.Uint32(data[:4])
+		data = data[4:]
+		if uint64(len(data)) < uint64(valueLength) {
+			return nil, errors.New("malformed ZMTP property")
+		}
+		properties[name] = string(data[:valueLength])
+		data = data[valueLength:]
+	}
+	return properties, nil
+}
+
+// handshake performs the ZMTP handshake of a PUB socket over the given connection
+func handshake(rw io.ReadWriter) error {
+	err := writeGreeting(rw)
+	if err != nil {
+		return err
+	}
+	err = readGreeting(rw)
+	if err != nil {
+		return err
+	}
+
+	ready := encodeCommand(commandReady, encodeProperties(map[string]string{propertySocketType: socketTypePub}))
+	_, err = rw.Write(ready)
+	if err != nil {
+		return err
+	}
+
+	peerReady, err := readFrame(rw)
+	if err != nil {
+		return err
+	}
+	if !peerReady.isCommand {
+		return errors.New("expected a READY command but got a message")
+	}
+	name, data, err := parseCommand(peerReady.body)
+	if err != nil {
+		return err
+	}
+	if name != commandReady {
+		return errors.Errorf("expected a READY command but got %s", name)
+	}
+	properties, err := parseProperties(data)
+	if err != nil {
+		return err
+	}
+	socketType := properties[propertySocketType]
+	if _, ok := subscriberSocketTypes[socketType]; !ok {
+		return errors.Errorf("socket type %s can't connect to a PUB socket", socketType)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	exportSubCmd  = "export"
+	versionSubCmd = "version"
+)
+
+const (
+	formatCSV     = "csv"
+	formatParquet = "parquet"
+)
+
+const (
+	defaultRPCServer        = "localhost"
+	defaultTimeout   uint64 = 30
+	defaultFormat           = formatCSV
+	defaultOutputDir        = "."
+)
+
+type exportConfig struct {
+	RPCServer     string  `long:"rpcserver" short:"s" description:"RPC server to connect to"`
+	Timeout       uint64  `long:"timeout" short:"t" description:"Timeout for every RPC request (in seconds)"`
+	FromBlueScore uint64  `long:"from-blue-score" description:"The lowest blue score of the exported blocks"`
+	ToBlueScore   *uint64 `long:"to-blue-score" description:"The highest blue score of the exported blocks (default: the blue score of the selected tip)"`
+	LowHash       string  `long:"low-hash" description:"A block in the past of the exported blocks to look for them from. Setting it to a chain block shortly before --from-blue-score skips requesting the blocks between the pruning point and it (default: the pruning point)"`
+	Format        string  `long:"format" short:"f" choice:"csv" choice:"parquet" description:"The format of the exported files"`
+	OutputDir     string  `long:"output-dir" short:"o" description:"The directory to write blocks, transactions, inputs and outputs files to. Existing files are never overwritten"`
+	config.NetworkFlags
+}
+
+type versionConfig struct {
+}
+
+func parseCommandLine() (subCommand string, config interface{}) {
+	parser := flags.NewParser(nil, flags.PrintErrors|flags.HelpFlag)
+
+	exportConf := &exportConfig{
+		RPCServer: defaultRPCServer,
+		Timeout:   defaultTimeout,
+		Format:    defaultFormat,
+		OutputDir: defaultOutputDir,
+	}
+	parser.AddCommand(exportSubCmd, "Exports blocks, transactions, inputs and outputs to CSV or Parquet files",
+		"Exports the blocks in a blue score range, along with their transactions, inputs and outputs, from a kaspad "+
+			"node to blocks, transactions, inputs and outputs files in CSV or Parquet format. The schema of the files "+
+			"is documented in the README of dagtool.", exportConf)
+	parser.AddCommand(versionSubCmd, "Get the dagtool version", "Get the dagtool version", &versionConfig{})
+
+	_, err := parser.Parse()
+	if err != nil {
+		var flagsErr *flags.Error
+		if ok := errors.As(err, &flagsErr); ok && flagsErr.Type == flags.ErrHelp {
+			os.Exit(0)
+		} else {
+			os.Exit(1)
+		}
+		return "", nil
+	}
+
+	switch parser.Command.Active.Name {
+	case exportSubCmd:
+		err := exportConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		if exportConf.ToBlueScore != nil && *exportConf.ToBlueScore < exportConf.FromBlueScore {
+			printErrorAndExit(errors.Errorf("--to-blue-score must not be lower than --from-blue-score"))
+		}
+		config = exportConf
+	case versionSubCmd:
+	}
+
+	return parser.Command.Active.Name, config
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// csvWriter writes a table as CSV with a header row. Timestamps are written in RFC 3339
+// format with millisecond precision, and booleans as true and false.
+type csvWriter struct {
+	table  *table
+	file   *os.File
+	writer *csv.Writer
+	fields []string
+}
+
+func newCSVWriter(path string, table *table) (*csvWriter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, err
+	}
+	writer := &csvWriter{
+		table:  table,
+		file:   file,
+		writer: csv.NewWriter(file),
+		fields: make([]string, len(table.columns)),
+	}
+	for i, column := range table.columns {
+		writer.fields[i] = column.name
+	}
+	err = writer.writer.Write(writer.fields)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (cw *csvWriter) writeRow(values ...interface{}) error {
+	if len(values) != len(cw.table.columns) {
+		return errors.Errorf("expected %d values for a row of %s but got %d",
+			len(cw.table.columns), cw.table.name, len(values))
+	}
+	for i, column := range cw.table.columns {
+		switch column.columnType {
+		case columnTypeUint64:
+			cw.fields[i] = strconv.FormatUint(values[i].(uint64), 10)
+		case columnTypeBool:
+			cw.fields[i] = strconv.FormatBool(values[i].(bool))
+		case columnTypeString:
+			cw.fields[i] = values[i].(string)
+		case columnTypeTimestamp:
+			cw.fields[i] = time.UnixMilli(values[i].(int64)).UTC().Format("2006-01-02T15:04:05.000Z07:00")
+		}
+	}
+	return cw.writer.Write(cw.fields)
+}
+
+func (cw *csvWriter) close() error {
+	cw.writer.Flush()
+	err := cw.writer.Error()
+	if err != nil {
+		cw.file.Close()
+		return err
+	}
+	return cw.file.Close()
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcclient"
+	"github.com/pkg/errors"
+)
+
+type exportWriters struct {
+	blocks       tableWriter
+	transactions tableWriter
+	inputs       tableWriter
+	outputs      tableWriter
+}
+
+func newExportWriters(outputDir string, format string) (*exportWriters, error) {
+	err := os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	var writers []tableWriter
+	for _, table := range []*table{blocksTable, transactionsTable, inputsTable, outputsTable} {
+		path := filepath.Join(outputDir, table.name+"."+format)
+		var writer tableWriter
+		switch format {
+		case formatCSV:
+			writer, err = newCSVWriter(path, table)
+		case formatParquet:
+			writer, err = newParquetWriter(path, table)
+		default:
+			err = errors.Errorf("unknown format %s", format)
+		}
+		if err != nil {
+			for _, writer := range writers {
+				writer.close()
+			}
+			return nil, err
+		}
+		writers = append(writers, writer)
+	}
+	return &exportWriters{
+		blocks:       writers[0],
+		transactions: writers[1],
+		inputs:       writers[2],
+		outputs:      writers[3],
+	}, nil
+}
+
+func (ew *exportWriters) close() error {
+	var firstErr error
+	for _, writer := range []tableWriter{ew.blocks, ew.transactions, ew.inputs, ew.outputs} {
+		err := writer.close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func export(conf *exportConfig) error {
+	rpcAddress, err := conf.NetParams().NormalizeRPCServerAddress(conf.RPCServer)
+	if err != nil {
+		return err
+	}
+	client, err := rpcclient.NewRPCClient(rpcAddress)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	client.SetTimeout(time.Duration(conf.Timeout) * time.Second)
+
+	lowHash := conf.LowHash
+	if lowHash == "" {
+		dagInfo, err := client.GetBlockDAGInfo()
+		if err != nil {
+			return err
+		}
+		lowHash = dagInfo.PruningPointHash
+	}
+	toBlueScore := conf.ToBlueScore
+	if toBlueScore == nil {
+		blueScoreResponse, err := client.GetVirtualSelectedParentBlueScore()
+		if err != nil {
+			return err
+		}
+		toBlueScore = &blueScoreResponse.BlueScore
+	}
+
+	writers, err := newExportWriters(conf.OutputDir, conf.Format)
+	if err != nil {
+		return err
+	}
+	exporter := &exporter{
+		client:        client,
+		writers:       writers,
+		fromBlueScore: conf.FromBlueScore,
+		toBlueScore:   *toBlueScore,
+		mergeDepth:    conf.NetParams().MergeDepth,
+	}
+	err = exporter.export(lowHash)
+	closeErr := writers.close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	fmt.Printf("Exported %d blocks, %d transactions, %d inputs and %d outputs to %s\n", exporter.blockCount,
+		exporter.transactionCount, exporter.inputCount, exporter.outputCount, conf.OutputDir)
+	return nil
+}
+
+type exporter struct {
+	client        *rpcclient.RPCClient
+	writers       *exportWriters
+	fromBlueScore uint64
+	toBlueScore   uint64
+	mergeDepth    uint64
+
+	blockCount       uint64
+	transactionCount uint64
+	inputCount       uint64
+	outputCount      uint64
+}
+
+// export exports the blocks in the blue score range that are in the future of lowHash.
+// GetBlocks returns the blocks in the past of a selected chain block that aren't in the
+// past of lowHash, so the blocks are requested in pages that start from the highest chain
+// block of the previous page. Blocks are merged at most mergeDepth blue score after
+// their own, so once the chain passes toBlueScore by more than that, no block of the
+// range can be left.
+func (e *exporter) export(lowHash string) error {
+	isFirstPage := true
+	for {
+		response, err := e.client.GetBlocks(lowHash, true, true)
+		if err != nil {
+			return err
+		}
+
+		// Once a page reaches the selected tip, the anticone of the tip is appended to it. Since
+		// it's returned again with every following page, it's only exported with the last one.
+		highIndex := -1
+		for i, block := range response.Blocks {
+			if block.VerboseData.IsChainBlock && block.VerboseData.Hash != lowHash {
+				highIndex = i
+			}
+		}
+		isLastPage := highIndex == -1
+		blocks := response.Blocks
+		if !isLastPage {
+			blocks = blocks[:highIndex+1]
+		}
+
+		for _, block := range blocks {
+			// Every page starts with its low hash, which was exported with the previous page
+			if block.VerboseData.Hash == lowHash && !isFirstPage {
+				continue
+			}
+			if block.Header.BlueScore < e.fromBlueScore || block.Header.BlueScore > e.toBlueScore {
+				continue
+			}
+			err := e.exportBlock(block)
+			if err != nil {
+				return err
+			}
+		}
+		if isLastPage {
+			return nil
+		}
+
+		highBlock := response.Blocks[highIndex]
+		if highBlock.Header.BlueScore > e.toBlueScore+e.mergeDepth {
+			return nil
+		}
+		fmt.Printf("Exported %d blocks up to blue score %d\n", e.blockCount, highBlock.Header.BlueScore)
+		lowHash = highBlock.VerboseData.Hash
+		isFirstPage = false
+	}
+}
+
+func (e *exporter) exportBlock(block *appmessage.RPCBlock) error {
+	header := block.Header
+	blockHash := block.VerboseData.Hash
+	var parentHashes []string
+	if len(header.Parents) > 0 {
+		parentHashes = header.Parents[0].ParentHashes
+	}
+	err := e.writers.blocks.writeRow(
+		blockHash,
+		uint64(header.Version),
+		header.Timestamp,
+		uint64(header.Bits),
+		header.Nonce,
+		header.DAAScore,
+		header.BlueScore,
+		header.BlueWork,
+		strings.Join(parentHashes, ","),
+		block.VerboseData.SelectedParentHash,
+		block.VerboseData.IsChainBlock,
+		uint64(len(block.Transactions)),
+	)
+	if err != nil {
+		return err
+	}
+	e.blockCount++
+
+	for i, transaction := range block.Transactions {
+		transactionID := transaction.VerboseData.TransactionID
+		err := e.writers.transactions.writeRow(
+			transactionID,
+			transaction.VerboseData.Hash,
+			blockHash,
+			uint64(i),
+			header.Timestamp,
+			i == 0,
+			uint64(transaction.Version),
+			transaction.LockTime,
+			transaction.SubnetworkID,
+			transaction.Gas,
+			transaction.Payload,
+			transaction.VerboseData.Mass,
+			uint64(len(transaction.Inputs)),
+			uint64(len(transaction.Outputs)),
+		)
+		if err != nil {
+			return err
+		}
+		e.transactionCount++
+
+		for j, input := range transaction.Inputs {
+			err := e.writers.inputs.writeRow(
+				transactionID,
+				blockHash,
+				uint64(j),
+				input.PreviousOutpoint.TransactionID,
+				uint64(input.PreviousOutpoint.Index),
+				input.SignatureScript,
+				input.Sequence,
+				uint64(input.SigOpCount),
+			)
+			if err != nil {
+				return err
+			}
+			e.inputCount++
+		}
+
+		for j, output := range transaction.Outputs {
+			err := e.writers.outputs.writeRow(
+				transactionID,
+				blockHash,
+				uint64(j),
+				output.Amount,
+				uint64(output.ScriptPublicKey.Version),
+				output.ScriptPublicKey.Script,
+				output.VerboseData.ScriptPublicKeyType,
+				output.VerboseData.ScriptPublicKeyAddress,
+			)
+			if err != nil {
+				return err
+			}
+			e.outputCount++
+		}
+	}
+	return nil
+}
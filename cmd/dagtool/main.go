@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kaspanet/kaspad/version"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	subCmd, config := parseCommandLine()
+	var err error
+	switch subCmd {
+	case exportSubCmd:
+		err = export(config.(*exportConfig))
+	case versionSubCmd:
+		fmt.Println(version.Version())
+	default:
+		err = errors.Errorf("Unknown sub-command '%s'\n", subCmd)
+	}
+
+	if err != nil {
+		printErrorAndExit(err)
+	}
+}
+
+func printErrorAndExit(err error) {
+	fmt.Fprintf(os.Stderr, "%s\n", err)
+	os.Exit(1)
+}
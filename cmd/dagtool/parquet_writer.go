@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+
+	"github.com/kaspanet/kaspad/version"
+	"github.com/pkg/errors"
+)
+
+const parquetMagic = "PAR1"
+
+// parquetRowGroupSize is the number of rows that are buffered in memory before they're
+// written to the file as a row group
+const parquetRowGroupSize = 100_000
+
+// The values of the enums of the Parquet format that are used by parquetWriter
+const (
+	parquetTypeBoolean   = 0
+	parquetTypeInt64     = 2
+	parquetTypeByteArray = 6
+
+	parquetConvertedTypeUTF8            = 0
+	parquetConvertedTypeTimestampMillis = 9
+	parquetConvertedTypeUint64          = 14
+
+	parquetRepetitionRequired = 0
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetCompressionNone  = 0
+	parquetPageTypeDataPage = 0
+
+	parquetMetadataFileVersion = 1
+)
+
+type parquetColumnChunk struct {
+	offset     int64
+	size       int64
+	valueCount int64
+}
+
+type parquetRowGroup struct {
+	columns  []*parquetColumnChunk
+	rowCount int64
+}
+
+// parquetWriter writes a table as an uncompressed Parquet file. All columns are required
+// and PLAIN encoded, and every column chunk is written as a single data page.
+type parquetWriter struct {
+	table  *table
+	file   *os.File
+	offset int64
+
+	// values holds the PLAIN encoded values of the current row group for every column,
+	// except for boolean columns, whose values are held in boolValues, since they're
+	// bit-packed
+	values     []*bytes.Buffer
+	boolValues [][]bool
+	rowCount   int
+
+	rowGroups     []*parquetRowGroup
+	totalRowCount int64
+}
+
+func newParquetWriter(path string, table *table) (*parquetWriter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, err
+	}
+	_, err = file.WriteString(parquetMagic)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	writer := &parquetWriter{
+		table:      table,
+		file:       file,
+		offset:     int64(len(parquetMagic)),
+		values:     make([]*bytes.Buffer, len(table.columns)),
+		boolValues: make([][]bool, len(table.columns)),
+	}
+	for i := range table.columns {
+		writer.values[i] = &bytes.Buffer{}
+	}
+	return writer, nil
+}
+
+func (pw *parquetWriter) writeRow(values ...interface{}) error {
+	if len(values) != len(pw.table.columns) {
+		return errors.Errorf("expected %d values for a row of %s but got %d",
+			len(pw.table.columns), pw.table.name, len(values))
+	}
+	var encoded [8]byte
+	for i, column := range pw.table.columns {
+		switch column.columnType {
+		case columnTypeUint64:
+			binary.LittleEndian.PutUint64(encoded[:], values[i].(uint64))
+			pw.values[i].Write(encoded[:])
+		case columnTypeTimestamp:
+			binary.LittleEndian.PutUint64(encoded[:], uint64(values[i].(int64)))
+			pw.values[i].Write(encoded[:])
+		case columnTypeBool:
+			pw.boolValues[i] = append(pw.boolValues[i], values[i].(bool))
+		case columnTypeString:
+			value := values[i].(string)
+			binary.LittleEndian.PutUint32(encoded[:4], uint32(len(value)))
+			pw.values[i].Write(encoded[:4])
+			pw.values[i].WriteString(value)
+		}
+	}
+
+	pw.rowCount++
+	if pw.rowCount >= parquetRowGroupSize {
+		return pw.flushRowGroup()
+	}
+	return nil
+}
+
+func (pw *parquetWriter) flushRowGroup() error {
+	rowGroup := &parquetRowGroup{
+		columns:  make([]*parquetColumnChunk, len(pw.table.columns)),
+		rowCount: int64(pw.rowCount),
+	}
+	for i, column := range pw.table.columns {
+		data := pw.values[i].Bytes()
+		if column.columnType == columnTypeBool {
+			data = packBools(pw.boolValues[i])
+		}
+		pageHeader := serializeDataPageHeader(pw.rowCount, len(data))
+
+		chunk := &parquetColumnChunk{
+			offset:     pw.offset,
+			size:       int64(len(pageHeader) + len(data)),
+			valueCount: int64(pw.rowCount),
+		}
+		_, err := pw.file.Write(pageHeader)
+		if err != nil {
+			return err
+		}
+		_, err = pw.file.Write(data)
+		if err != nil {
+			return err
+		}
+		pw.offset += chunk.size
+		rowGroup.columns[i] = chunk
+
+		pw.values[i].Reset()
+		pw.boolValues[i] = pw.boolValues[i][:0]
+	}
+
+	pw.rowGroups = append(pw.rowGroups, rowGroup)
+	pw.totalRowCount += int64(pw.rowCount)
+	pw.rowCount = 0
+	return nil
+}
+
+func (pw *parquetWriter) close() error {
+	if pw.rowCount > 0 {
+		err := pw.flushRowGroup()
+		if err != nil {
+			pw.file.Close()
+			return err
+		}
+	}
+
+	footer := pw.serializeFileMetadata()
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	footer = append(footer, parquetMagic...)
+	_, err := pw.file.Write(footer)
+	if err != nil {
+		pw.file.Close()
+		return err
+	}
+	return pw.file.Close()
+}
+
+// packBools bit-packs the given values, starting from the least significant bit,
+// as booleans are PLAIN encoded
+func packBools(values []bool) []byte {
+	packed := make([]byte, (len(values)+7)/8)
+	for i, value := range values {
+		if value {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
+
+func serializeDataPageHeader(valueCount int, dataSize int) []byte {
+	writer := &thriftWriter{}
+	writer.beginStruct()
+	writer.writeI32Field(1, parquetPageTypeDataPage)
+	writer.writeI32Field(2, int32(dataSize))
+	writer.writeI32Field(3, int32(dataSize))
+	writer.writeStructFieldHeader(5)
+	writer.beginStruct()
+	writer.writeI32Field(1, int32(valueCount))
+	writer.writeI32Field(2, parquetEncodingPlain)
+	writer.writeI32Field(3, parquetEncodingRLE)
+	writer.writeI32Field(4, parquetEncodingRLE)
+	writer.endStruct()
+	writer.endStruct()
+	return writer.bytes()
+}
+
+func (pw *parquetWriter) serializeFileMetadata() []byte {
+	writer := &thriftWriter{}
+	writer.beginStruct()
+	writer.writeI32Field(1, parquetMetadataFileVersion)
+
+	// The schema is flattened, starting with a root element whose children are the columns
+	writer.writeStructListFieldHeader(2, len(pw.table.columns)+1)
+	writer.beginStruct()
+	writer.writeStringField(4, "schema")
+	writer.writeI32Field(5, int32(len(pw.table.columns)))
+	writer.endStruct()
+	for _, column := range pw.table.columns {
+		physicalType, convertedType, hasConvertedType := parquetColumnTypes(column.columnType)
+		writer.beginStruct()
+		writer.writeI32Field(1, physicalType)
+		writer.writeI32Field(3, parquetRepetitionRequired)
+		writer.writeStringField(4, column.name)
+		if hasConvertedType {
+			writer.writeI32Field(6, convertedType)
+		}
+		writer.endStruct()
+	}
+
+	writer.writeI64Field(3, pw.totalRowCount)
+
+	writer.writeStructListFieldHeader(4, len(pw.rowGroups))
+	for _, rowGroup := range pw.rowGroups {
+		writer.beginStruct()
+		writer.writeStructListFieldHeader(1, len(rowGroup.columns))
+		var totalSize int64
+		for i, chunk := range rowGroup.columns {
+			column := pw.table.columns[i]
+			physicalType, _, _ := parquetColumnTypes(column.columnType)
+
+			writer.beginStruct()
+			writer.writeI64Field(2, chunk.offset)
+			writer.writeStructFieldHeader(3)
+			writer.beginStruct()
+			writer.writeI32Field(1, physicalType)
+			writer.writeI32ListField(2, []int32{parquetEncodingPlain})
+			writer.writeStringListField(3, []string{column.name})
+			writer.writeI32Field(4, parquetCompressionNone)
+			writer.writeI64Field(5, chunk.valueCount)
+			writer.writeI64Field(6, chunk.size)
+			writer.writeI64Field(7, chunk.size)
+			writer.writeI64Field(9, chunk.offset)
+			writer.endStruct()
+			writer.endStruct()
+
+			totalSize += chunk.size
+		}
+		writer.writeI64Field(2, totalSize)
+		writer.writeI64Field(3, rowGroup.rowCount)
+		writer.endStruct()
+	}
+
+	writer.writeStringField(6, "dagtool version "+version.Version())
+	writer.endStruct()
+	return writer.bytes()
+}
+
+func parquetColumnTypes(columnType columnType) (physicalType int32, convertedType int32, hasConvertedType bool) {
+	switch columnType {
+	case columnTypeUint64:
+		return parquetTypeInt64, parquetConvertedTypeUint64, true
+	case columnTypeTimestamp:
+		return parquetTypeInt64, parquetConvertedTypeTimestampMillis, true
+	case columnTypeBool:
+		return parquetTypeBoolean, 0, false
+	case columnTypeString:
+		return parquetTypeByteArray, parquetConvertedTypeUTF8, true
+	}
+	panic(errors.Errorf("unknown column type %d", columnType))
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParquetWriter(t *testing.T) {
+	testTable := &table{
+		name: "test",
+		columns: []column{
+			{name: "number", columnType: columnTypeUint64},
+			{name: "flag", columnType: columnTypeBool},
+			{name: "text", columnType: columnTypeString},
+			{name: "time", columnType: columnTypeTimestamp},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "test.parquet")
+	writer, err := newParquetWriter(path, testTable)
+	if err != nil {
+		t.Fatalf("newParquetWriter: %s", err)
+	}
+	const rowCount = parquetRowGroupSize + 10
+	for i := 0; i < rowCount; i++ {
+		err := writer.writeRow(uint64(i), i%3 == 0, "row", int64(i)*1000)
+		if err != nil {
+			t.Fatalf("writeRow: %s", err)
+		}
+	}
+	err = writer.writeRow(uint64(1))
+	if err == nil {
+		t.Fatalf("expected an error for a row with missing values")
+	}
+	err = writer.close()
+	if err != nil {
+		t.Fatalf("close: %s", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+	if !bytes.HasPrefix(data, []byte(parquetMagic)) || !bytes.HasSuffix(data, []byte(parquetMagic)) {
+		t.Fatalf("expected the file to start and end with %s", parquetMagic)
+	}
+	footerLength := binary.LittleEndian.Uint32(data[len(data)-8:])
+	if int(footerLength) > len(data)-12 {
+		t.Fatalf("the footer length %d exceeds the file size %d", footerLength, len(data))
+	}
+
+	if len(writer.rowGroups) != 2 || writer.totalRowCount != rowCount {
+		t.Fatalf("expected %d rows in 2 row groups but got %d rows in %d row groups",
+			rowCount, writer.totalRowCount, len(writer.rowGroups))
+	}
+	// The numbers of the second row group are PLAIN encoded right after the page header
+	numbersChunk := writer.rowGroups[1].columns[0]
+	pageHeaderSize := numbersChunk.size - 10*8
+	firstNumber := binary.LittleEndian.Uint64(data[numbersChunk.offset+pageHeaderSize:])
+	if firstNumber != parquetRowGroupSize {
+		t.Fatalf("expected the second row group to start with %d but got %d", parquetRowGroupSize, firstNumber)
+	}
+}
+
+// parquetConformanceScript reads a Parquet file with pyarrow, the reference implementation of
+// Parquet, and prints its column types, its row group count and some of its rows as JSON
+const parquetConformanceScript = `
+import json, sys
+import pyarrow.parquet as pq
+file = pq.ParquetFile(sys.argv[1])
+table = file.read()
+columns = [table.column(i).cast("int64") if str(field.type).startswith("timestamp") else table.column(i)
+           for i, field in enumerate(table.schema)]
+print(json.dumps({
+    "types": [str(field.type) for field in table.schema],
+    "rowGroups": file.num_row_groups,
+    "rows": [[column[int(row)].as_py() for column in columns] for row in sys.argv[2:]],
+}))
+`
+
+// TestParquetWriterConformance checks that pyarrow reads the files of parquetWriter. The
+// test is skipped unless the Python at KASPAD_TEST_PYTHON, or python3, has pyarrow installed.
+func TestParquetWriterConformance(t *testing.T) {
+	python := os.Getenv("KASPAD_TEST_PYTHON")
+	if python == "" {
+		python = "python3"
+	}
+	if exec.Command(python, "-c", "import pyarrow.parquet").Run() != nil {
+		t.Skipf("%s has no pyarrow", python)
+	}
+
+	testTable := &table{
+		name: "test",
+		columns: []column{
+			{name: "number", columnType: columnTypeUint64},
+			{name: "flag", columnType: columnTypeBool},
+			{name: "text", columnType: columnTypeString},
+			{name: "time", columnType: columnTypeTimestamp},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "test.parquet")
+	writer, err := newParquetWriter(path, testTable)
+	if err != nil {
+		t.Fatalf("newParquetWriter: %s", err)
+	}
+	const rowCount = parquetRowGroupSize + 10
+	for i := 0; i < rowCount; i++ {
+		err := writer.writeRow(uint64(i)<<40, i%3 == 0, strconv.Itoa(i), int64(i)*1000)
+		if err != nil {
+			t.Fatalf("writeRow: %s", err)
+		}
+	}
+	err = writer.close()
+	if err != nil {
+		t.Fatalf("close: %s", err)
+	}
+
+	rows := []int{0, 1, parquetRowGroupSize - 1, parquetRowGroupSize, rowCount - 1}
+	args := []string{"-c", parquetConformanceScript, path}
+	for _, row := range rows {
+		args = append(args, strconv.Itoa(row))
+	}
+	output, err := exec.Command(python, args...).Output()
+	if err != nil {
+		t.Fatalf("pyarrow failed to read the file: %s", err)
+	}
+	var result struct {
+		Types     []string
+		RowGroups int
+		Rows      [][]interface{}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	decoder.UseNumber()
+	err = decoder.Decode(&result)
+	if err != nil {
+		t.Fatalf("Decode: %s", err)
+	}
+
+	// Depending on its version, pyarrow reads timestamps without a logical type as UTC or as naive
+	expectedTypes := []string{"uint64", "bool", "string", "timestamp[ms"}
+	if len(result.Types) != len(expectedTypes) {
+		t.Fatalf("expected the types %v but got %v", expectedTypes, result.Types)
+	}
+	for i, expectedType := range expectedTypes {
+		if !strings.HasPrefix(result.Types[i], expectedType) {
+			t.Fatalf("expected the types %v but got %v", expectedTypes, result.Types)
+		}
+	}
+	if result.RowGroups != 2 {
+		t.Fatalf("expected 2 row groups but got %d", result.RowGroups)
+	}
+	for i, row := range rows {
+		expectedRow := []interface{}{json.Number(strconv.FormatUint(uint64(row)<<40, 10)), row%3 == 0,
+			strconv.Itoa(row), json.Number(strconv.Itoa(row * 1000))}
+		if !reflect.DeepEqual(result.Rows[i], expectedRow) {
+			t.Fatalf("expected row %d to be %v but got %v", row, expectedRow, result.Rows[i])
+		}
+	}
+}
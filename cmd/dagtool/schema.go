@@ -0,0 +1,98 @@
+package main
+
+type columnType int
+
+const (
+	columnTypeUint64 columnType = iota
+	columnTypeBool
+	columnTypeString
+
+	// columnTypeTimestamp is an int64 of milliseconds since the epoch
+	columnTypeTimestamp
+)
+
+type column struct {
+	name       string
+	columnType columnType
+}
+
+// table describes the columns of one of the exported files. The values of a row are
+// passed to the writers as uint64, bool, string, or int64 for timestamps, according to
+// the column types. The schema is documented in README.md, and has to be kept in sync
+// with it.
+type table struct {
+	name    string
+	columns []column
+}
+
+var blocksTable = &table{
+	name: "blocks",
+	columns: []column{
+		{name: "hash", columnType: columnTypeString},
+		{name: "version", columnType: columnTypeUint64},
+		{name: "timestamp", columnType: columnTypeTimestamp},
+		{name: "bits", columnType: columnTypeUint64},
+		{name: "nonce", columnType: columnTypeUint64},
+		{name: "daa_score", columnType: columnTypeUint64},
+		{name: "blue_score", columnType: columnTypeUint64},
+		{name: "blue_work", columnType: columnTypeString},
+		{name: "parent_hashes", columnType: columnTypeString},
+		{name: "selected_parent_hash", columnType: columnTypeString},
+		{name: "is_chain_block", columnType: columnTypeBool},
+		{name: "transaction_count", columnType: columnTypeUint64},
+	},
+}
+
+var transactionsTable = &table{
+	name: "transactions",
+	columns: []column{
+		{name: "transaction_id", columnType: columnTypeString},
+		{name: "hash", columnType: columnTypeString},
+		{name: "block_hash", columnType: columnTypeString},
+		{name: "block_index", columnType: columnTypeUint64},
+		{name: "block_time", columnType: columnTypeTimestamp},
+		{name: "is_coinbase", columnType: columnTypeBool},
+		{name: "version", columnType: columnTypeUint64},
+		{name: "lock_time", columnType: columnTypeUint64},
+		{name: "subnetwork_id", columnType: columnTypeString},
+		{name: "gas", columnType: columnTypeUint64},
+		{name: "payload", columnType: columnTypeString},
+		{name: "mass", columnType: columnTypeUint64},
+		{name: "input_count", columnType: columnTypeUint64},
+		{name: "output_count", columnType: columnTypeUint64},
+	},
+}
+
+var inputsTable = &table{
+	name: "inputs",
+	columns: []column{
+		{name: "transaction_id", columnType: columnTypeString},
+		{name: "block_hash", columnType: columnTypeString},
+		{name: "index", columnType: columnTypeUint64},
+		{name: "previous_transaction_id", columnType: columnTypeString},
+		{name: "previous_index", columnType: columnTypeUint64},
+		{name: "signature_script", columnType: columnTypeString},
+		{name: "sequence", columnType: columnTypeUint64},
+		{name: "sig_op_count", columnType: columnTypeUint64},
+	},
+}
+
+var outputsTable = &table{
+	name: "outputs",
+	columns: []column{
+		{name: "transaction_id", columnType: columnTypeString},
+		{name: "block_hash", columnType: columnTypeString},
+		{name: "index", columnType: columnTypeUint64},
+		{name: "amount", columnType: columnTypeUint64},
+		{name: "script_public_key_version", columnType: columnTypeUint64},
+		{name: "script_public_key", columnType: columnTypeString},
+		{name: "script_public_key_type", columnType: columnTypeString},
+		{name: "address", columnType: columnTypeString},
+	},
+}
+
+// tableWriter writes the rows of a table to a file
+type tableWriter interface {
+	writeRow(values ...interface{}) error
+	close() error
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// The types of the Thrift compact protocol, which Parquet metadata is serialized with
+const (
+	thriftTypeI32    = 5
+	thriftTypeI64    = 6
+	thriftTypeBinary = 8
+	thriftTypeList   = 9
+	thriftTypeStruct = 12
+)
+
+// thriftWriter serializes Thrift structs with the compact protocol. Structs are written
+// by calling beginStruct, writing their fields in ascending ID order, and calling endStruct.
+type thriftWriter struct {
+	buffer bytes.Buffer
+
+	// lastFieldIDs holds the ID of the last field written to every struct that's being
+	// written, since field IDs are encoded as the delta from the previous field
+	lastFieldIDs []int16
+}
+
+func (tw *thriftWriter) bytes() []byte {
+	return tw.buffer.Bytes()
+}
+
+func (tw *thriftWriter) writeVarint(value uint64) {
+	tw.buffer.Write(binary.AppendUvarint(nil, value))
+}
+
+func (tw *thriftWriter) writeZigZag(value int64) {
+	tw.writeVarint(uint64((value << 1) ^ (value >> 63)))
+}
+
+func (tw *thriftWriter) beginStruct() {
+	tw.lastFieldIDs = append(tw.lastFieldIDs, 0)
+}
+
+func (tw *thriftWriter) endStruct() {
+	tw.buffer.WriteByte(0)
+	tw.lastFieldIDs = tw.lastFieldIDs[:len(tw.lastFieldIDs)-1]
+}
+
+func (tw *thriftWriter) writeFieldHeader(fieldID int16, fieldType byte) {
+	lastFieldID := &tw.lastFieldIDs[len(tw.lastFieldIDs)-1]
+	delta := fieldID - *lastFieldID
+	if delta > 0 && delta <= 15 {
+		tw.buffer.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		tw.buffer.WriteByte(fieldType)
+		tw.writeZigZag(int64(fieldID))
+	}
+	*lastFieldID = fieldID
+}
+
+func (tw *thriftWriter) writeListHeader(size int, elementType byte) {
+	if size < 15 {
+		tw.buffer.WriteByte(byte(size)<<4 | elementType)
+		return
+	}
+	tw.buffer.WriteByte(0xf0 | elementType)
+	tw.writeVarint(uint64(size))
+}
+
+func (tw *thriftWriter) writeI32Field(fieldID int16, value int32) {
+	tw.writeFieldHeader(fieldID, thriftTypeI32)
+	tw.writeZigZag(int64(value))
+}
+
+func (tw *thriftWriter) writeI64Field(fieldID int16, value int64) {
+	tw.writeFieldHeader(fieldID, thriftTypeI64)
+	tw.writeZigZag(value)
+}
+
+func (tw *thriftWriter) writeStringField(fieldID int16, value string) {
+	tw.writeFieldHeader(fieldID, thriftTypeBinary)
+	tw.writeVarint(uint64(len(value)))
+	tw.buffer.WriteString(value)
+}
+
+func (tw *thriftWriter) writeStructFieldHeader(fieldID int16) {
+	tw.writeFieldHeader(fieldID, thriftTypeStruct)
+}
+
+func (tw *thriftWriter) writeI32ListField(fieldID int16, values []int32) {
+	tw.writeFieldHeader(fieldID, thriftTypeList)
+	tw.writeListHeader(len(values), thriftTypeI32)
+	for _, value := range values {
+		tw.writeZigZag(int64(value))
+	}
+}
+
+func (tw *thriftWriter) writeStringListField(fieldID int16, values []string) {
+	tw.writeFieldHeader(fieldID, thriftTypeList)
+	tw.writeListHeader(len(values), thriftTypeBinary)
+	for _, value := range values {
+		tw.writeVarint(uint64(len(value)))
+		tw.buffer.WriteString(value)
+	}
+}
+
+// writeStructListFieldHeader writes the header of a list of the given number of structs,
+// which are then written with beginStruct and endStruct
+func (tw *thriftWriter) writeStructListFieldHeader(fieldID int16, size int) {
+	tw.writeFieldHeader(fieldID, thriftTypeList)
+	tw.writeListHeader(size, thriftTypeStruct)
+}
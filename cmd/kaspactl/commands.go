@@ -11,6 +11,8 @@ import (
 var commandTypes = []reflect.Type{
 	reflect.TypeOf(protowire.KaspadMessage_AddPeerRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_GetConnectedPeerInfoRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GetNetworkInfoRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GetNetTotalsRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_GetPeerAddressesRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_GetCurrentNetworkRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_GetInfoRequest{}),
@@ -20,7 +22,10 @@ var commandTypes = []reflect.Type{
 	reflect.TypeOf(protowire.KaspadMessage_GetHeadersRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_GetBlockCountRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_GetBlockDagInfoRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GetSyncStatusRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GetBlockDagRelationsRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_GetSelectedTipHashRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GetFinalityPointRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_GetVirtualSelectedParentBlueScoreRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_GetVirtualSelectedParentChainFromBlockRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_ResolveFinalityConflictRequest{}),
@@ -30,17 +35,38 @@ var commandTypes = []reflect.Type{
 	reflect.TypeOf(protowire.KaspadMessage_SubmitBlockRequest{}),
 
 	reflect.TypeOf(protowire.KaspadMessage_GetMempoolEntryRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GetRawTransactionRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_GetMempoolEntriesRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GetMempoolInfoRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_GetMempoolEntriesByAddressesRequest{}),
 
 	reflect.TypeOf(protowire.KaspadMessage_SubmitTransactionRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_TestMempoolAcceptRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_SubmitPackageRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GetTxOutProofRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_VerifyTxOutProofRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_AddNodeRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_DisconnectNodeRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GetAddedNodeInfoRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_InvalidateBlockRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_ReconsiderBlockRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GetBlockStatsRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GetProcessingStatsRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_DebugLevelRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_StartProfileRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_StopProfileRequest{}),
 
 	reflect.TypeOf(protowire.KaspadMessage_GetUtxosByAddressesRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_GetBalanceByAddressRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_GetCoinSupplyRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_GetUtxoSetInfoRequest{}),
 
 	reflect.TypeOf(protowire.KaspadMessage_BanRequest{}),
 	reflect.TypeOf(protowire.KaspadMessage_UnbanRequest{}),
+
+	reflect.TypeOf(protowire.KaspadMessage_GetDbInfoRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_CompactDbRequest{}),
+	reflect.TypeOf(protowire.KaspadMessage_BackupDagStateRequest{}),
 }
 
 type commandDescription struct {
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/client"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/pkg/errors"
+)
+
+func backupWallet(conf *backupWalletConfig) error {
+	_, err := os.Stat(conf.File)
+	if err == nil {
+		return errors.Errorf("the file %s already exists", conf.File)
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	if len(conf.Password) == 0 {
+		conf.Password = keys.GetPassword("Password:")
+	}
+
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.BackupWallet(ctx, &pb.BackupWalletRequest{Password: conf.Password})
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(conf.File, response.Archive, 0600)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("The wallet was backed up to %s. The backup is encrypted with the wallet password.\n", conf.File)
+	return nil
+}
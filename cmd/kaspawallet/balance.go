@@ -18,7 +18,7 @@ func balance(conf *balanceConfig) error {
 
 	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
 	defer cancel()
-	response, err := daemonClient.GetBalance(ctx, &pb.GetBalanceRequest{})
+	response, err := daemonClient.GetBalance(ctx, &pb.GetBalanceRequest{MinConfirmations: conf.MinConfirmations})
 	if err != nil {
 		return err
 	}
@@ -38,6 +38,8 @@ func balance(conf *balanceConfig) error {
 		print("                                                 ")
 	}
 	fmt.Printf("Total balance, KAS %s %s%s\n", utils.FormatKas(response.Available), utils.FormatKas(response.Pending), pendingSuffix)
+	fmt.Printf("Immature KAS %s, unconfirmed KAS %s, confirmed at depth %d KAS %s\n", utils.FormatKas(response.Immature),
+		utils.FormatKas(response.Unconfirmed), conf.MinConfirmations, utils.FormatKas(response.Confirmed))
 
 	return nil
 }
@@ -81,7 +81,7 @@ func bumpFee(conf *bumpFeeConfig) error {
 		signedTransactions[i] = signedTransaction
 	}
 
-	fmt.Printf("Broadcasting %d transaction(s)\n", len(signedTransactions))
+	fmt.Printf("Broadcasting %d transaction(s) (fee bumped using %s)\n", len(signedTransactions), createUnsignedTransactionsResponse.Strategy)
 	// Since we waited for user input when getting the password, which could take unbound amount of time -
 	// create a new context for broadcast, to reset the timeout.
 	broadcastCtx, broadcastCancel := context.WithTimeout(context.Background(), daemonTimeout)
@@ -95,7 +95,12 @@ func bumpFee(conf *bumpFeeConfig) error {
 		}
 
 		chunk := signedTransactions[offset:end]
-		response, err := daemonClient.BroadcastReplacement(broadcastCtx, &pb.BroadcastRequest{Transactions: chunk})
+		var response *pb.BroadcastResponse
+		if createUnsignedTransactionsResponse.Strategy == pb.BumpFeeStrategy_CPFP {
+			response, err = daemonClient.Broadcast(broadcastCtx, &pb.BroadcastRequest{Transactions: chunk})
+		} else {
+			response, err = daemonClient.BroadcastReplacement(broadcastCtx, &pb.BroadcastRequest{Transactions: chunk})
+		}
 		if err != nil {
 			return err
 		}
@@ -51,7 +51,13 @@ func bumpFeeUnsigned(conf *bumpFeeUnsignedConfig) error {
 		return err
 	}
 
-	fmt.Fprintln(os.Stderr, "Created unsigned transaction")
+	if response.Strategy == pb.BumpFeeStrategy_CPFP {
+		fmt.Fprintf(os.Stderr, "Created unsigned child transaction that pays for %s. "+
+			"Sign it and then broadcast it with the '%s' command\n", conf.TxID, broadcastSubCmd)
+	} else {
+		fmt.Fprintf(os.Stderr, "Created unsigned replacement transaction. "+
+			"Sign it and then broadcast it with the '%s' command\n", broadcastReplacementSubCmd)
+	}
 	fmt.Println(server.EncodeTransactionsToHex(response.Transactions))
 
 	return nil
@@ -27,11 +27,22 @@ const (
 	bumpFeeSubCmd                   = "bump-fee"
 	bumpFeeUnsignedSubCmd           = "bump-fee-unsigned"
 	broadcastReplacementSubCmd      = "broadcast-replacement"
+	importPrivateKeySubCmd          = "import-private-key"
+	dumpPrivateKeySubCmd            = "dump-private-key"
+	backupWalletSubCmd              = "backup-wallet"
+	restoreWalletSubCmd             = "restore-wallet"
+	listTransactionsSubCmd          = "list-transactions"
+	lockUnspentSubCmd               = "lock-unspent"
+	listLockUnspentSubCmd           = "list-lock-unspent"
+	getTransactionSubCmd            = "get-transaction"
 )
 
 const (
-	defaultListen    = "localhost:8082"
-	defaultRPCServer = "localhost"
+	defaultListen                = "localhost:8082"
+	defaultRPCServer             = "localhost"
+	defaultGapLimit              = 1000
+	defaultConsolidateMaxFeeRate = 1.0
+	defaultNotifyMaxProcesses    = 4
 )
 
 type configFlags struct {
@@ -54,6 +65,8 @@ type createConfig struct {
 type balanceConfig struct {
 	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
 	Verbose       bool   `long:"verbose" short:"v" description:"Verbose: show addresses with balance"`
+
+	MinConfirmations uint64 `long:"min-confirmations" short:"c" description:"The DAA score depth at which received funds are counted as confirmed"`
 	config.NetworkFlags
 }
 
@@ -132,6 +145,15 @@ type startDaemonConfig struct {
 	Listen    string `long:"listen" short:"l" description:"Address to listen on (default: 0.0.0.0:8082)"`
 	Timeout   uint32 `long:"wait-timeout" short:"w" description:"Waiting timeout for RPC calls, seconds (default: 30 s)"`
 	Profile   string `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
+	GapLimit  uint32 `long:"gap-limit" description:"The number of consecutive unused addresses on each key chain after which the wallet stops looking for used addresses (default: 1000)"`
+
+	ConsolidateUTXOsAbove uint32  `long:"consolidate-utxos-above" description:"Automatically consolidate the smallest UTXOs of the wallet while it has more than this many spendable UTXOs. Requires the wallet password (default: 0, disabled)"`
+	ConsolidateMaxFeeRate float64 `long:"consolidate-max-fee-rate" description:"The highest estimated fee rate, in sompi per gram, at which UTXOs are consolidated (default: 1)"`
+
+	Wallets []string `long:"wallet" description:"Load an additional wallet, formatted as <name>=<keys file>. Clients use it by appending /<name> to the daemon address (may be repeated)"`
+
+	WalletNotify       string `long:"wallet-notify" description:"Execute <command> when a transaction that pays to or spends from a wallet is first seen (%s in the command is replaced by the transaction ID and %w by the wallet name, which is empty for the default wallet)"`
+	NotifyMaxProcesses int    `long:"notify-max-processes" description:"Max number of wallet-notify commands that run at the same time (default: 4)"`
 	config.NetworkFlags
 }
 
@@ -167,6 +189,64 @@ type bumpFeeConfig struct {
 	config.NetworkFlags
 }
 
+type importPrivateKeyConfig struct {
+	PrivateKey    string `long:"private-key" short:"k" description:"Private key in the Wallet Import Format (WIF)"`
+	Password      string `long:"password" short:"p" description:"Wallet password"`
+	Rescan        bool   `long:"rescan" short:"r" description:"Sweep the funds of the imported key into the wallet without asking"`
+	NoRescan      bool   `long:"no-rescan" description:"Don't sweep the funds of the imported key into the wallet, and don't ask about it"`
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	config.NetworkFlags
+}
+
+type dumpPrivateKeyConfig struct {
+	Address       string `long:"address" short:"a" description:"The address to dump the private key of" required:"true"`
+	Password      string `long:"password" short:"p" description:"Wallet password"`
+	Yes           bool   `long:"yes" short:"y" description:"Assume \"yes\" to all questions"`
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	config.NetworkFlags
+}
+
+type backupWalletConfig struct {
+	File          string `long:"file" short:"o" description:"The file to write the backup to" required:"true"`
+	Password      string `long:"password" short:"p" description:"Wallet password"`
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	config.NetworkFlags
+}
+
+type listTransactionsConfig struct {
+	Cursor        string `long:"cursor" short:"c" description:"List the transactions after this cursor, as printed by a previous call"`
+	Limit         uint32 `long:"limit" short:"l" description:"The maximum number of transactions to list (default: 100)"`
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	config.NetworkFlags
+}
+
+type lockUnspentConfig struct {
+	Outpoints     []string `long:"outpoint" short:"o" description:"An outpoint to lock or unlock, formatted as <transaction ID>:<index> (may be repeated)"`
+	Unlock        bool     `long:"unlock" short:"u" description:"Unlock the outpoints instead of locking them. Unlocks all the outpoints if none are specified"`
+	Persist       bool     `long:"persist" short:"p" description:"Keep the outpoints locked after the wallet daemon restarts"`
+	DaemonAddress string   `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	config.NetworkFlags
+}
+
+type listLockUnspentConfig struct {
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	config.NetworkFlags
+}
+
+type getTransactionConfig struct {
+	TransactionID string `long:"txid" short:"t" description:"The ID of the transaction" required:"true"`
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	config.NetworkFlags
+}
+
+type restoreWalletConfig struct {
+	File          string `long:"file" short:"i" description:"The backup file to restore the wallet from" required:"true"`
+	Password      string `long:"password" short:"p" description:"Password of the backed up wallet"`
+	Yes           bool   `long:"yes" short:"y" description:"Assume \"yes\" to all questions"`
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	config.NetworkFlags
+}
+
 type versionConfig struct {
 }
 
@@ -225,9 +305,57 @@ func parseCommandLine() (subCommand string, config interface{}) {
 		"Prints the unencrypted wallet data including its private keys. Anyone that sees it can access "+
 			"the funds. Use only on safe environment.", dumpUnencryptedDataConf)
 
+	importPrivateKeyConf := &importPrivateKeyConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(importPrivateKeySubCmd, "Imports a private key in the Wallet Import Format (WIF) to the wallet",
+		"Imports a private key in the Wallet Import Format (WIF) to the wallet, encrypted with the wallet password, and "+
+			"optionally sweeps its funds to a new address of the wallet. The key must belong to the network of the wallet, "+
+			"and the wallet must hold encrypted private keys.", importPrivateKeyConf)
+
+	dumpPrivateKeyConf := &dumpPrivateKeyConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(dumpPrivateKeySubCmd, "Prints the private key of an address of the wallet in the Wallet Import Format (WIF)",
+		"Prints the private key of an address of a single-signature wallet, or of an imported private key, in the Wallet "+
+			"Import Format (WIF). Anyone that sees it can access the funds of the address. Use only on safe environment.",
+		dumpPrivateKeyConf)
+
+	backupWalletConf := &backupWalletConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(backupWalletSubCmd, "Writes an encrypted backup of the wallet to a file",
+		"Writes a backup of the wallet keys file, including its imported private keys, to a file. The backup is "+
+			"encrypted with the wallet password.", backupWalletConf)
+
+	restoreWalletConf := &restoreWalletConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(restoreWalletSubCmd, "Restores the wallet from an encrypted backup",
+		"Verifies the given wallet backup and replaces the keys file of the wallet daemon with it. The daemon "+
+			"rescans the addresses of the restored wallet afterwards.", restoreWalletConf)
+
+	listTransactionsConf := &listTransactionsConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(listTransactionsSubCmd, "Lists the transactions that paid to the wallet or spent its outputs",
+		"Lists the transactions that paid to the wallet or spent its outputs since the wallet daemon started keeping "+
+			"its transaction history, ordered by the DAA score of their accepting block. The listing is paged with the "+
+			"printed cursor, and continuing from a cursor never skips or repeats transactions, even when new ones are "+
+			"accepted in the meanwhile.", listTransactionsConf)
+
+	lockUnspentConf := &lockUnspentConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(lockUnspentSubCmd, "Locks or unlocks unspent outputs of the wallet",
+		"Locks unspent outputs of the wallet so that they aren't selected automatically when creating "+
+			"transactions, and can be reserved for transactions that spend them manually. Locks are released "+
+			"once the outputs are spent.", lockUnspentConf)
+
+	listLockUnspentConf := &listLockUnspentConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(listLockUnspentSubCmd, "Lists the locked unspent outputs of the wallet",
+		"Lists the unspent outputs of the wallet that are locked against automatic selection", listLockUnspentConf)
+
+	getTransactionConf := &getTransactionConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(getTransactionSubCmd, "Shows the status of a transaction",
+		"Shows whether the transaction is in the mempool, and once it's accepted, its confirmations by blue score "+
+			"and the block that accepted it, along with the amounts it paid to the wallet or spent from it. Accepted "+
+			"transactions can only be found if the node runs with --txindex.", getTransactionConf)
+
 	startDaemonConf := &startDaemonConfig{
-		RPCServer: defaultRPCServer,
-		Listen:    defaultListen,
+		RPCServer:             defaultRPCServer,
+		Listen:                defaultListen,
+		GapLimit:              defaultGapLimit,
+		ConsolidateMaxFeeRate: defaultConsolidateMaxFeeRate,
+		NotifyMaxProcesses:    defaultNotifyMaxProcesses,
 	}
 	parser.AddCommand(startDaemonSubCmd, "Start the wallet daemon", "Start the wallet daemon", startDaemonConf)
 	parser.AddCommand(versionSubCmd, "Get the wallet version", "Get the wallet version", &versionConfig{})
@@ -350,6 +478,12 @@ func parseCommandLine() (subCommand string, config interface{}) {
 		if err != nil {
 			printErrorAndExit(err)
 		}
+		if startDaemonConf.GapLimit == 0 {
+			printErrorAndExit(errors.New("--gap-limit must be positive"))
+		}
+		if startDaemonConf.NotifyMaxProcesses <= 0 {
+			printErrorAndExit(errors.New("--notify-max-processes must be positive"))
+		}
 		config = startDaemonConf
 	case versionSubCmd:
 	case getDaemonVersionSubCmd:
@@ -380,6 +514,67 @@ func parseCommandLine() (subCommand string, config interface{}) {
 		}
 
 		config = bumpFeeUnsignedConf
+	case importPrivateKeySubCmd:
+		combineNetworkFlags(&importPrivateKeyConf.NetworkFlags, &cfg.NetworkFlags)
+		err := importPrivateKeyConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+
+		if importPrivateKeyConf.Rescan && importPrivateKeyConf.NoRescan {
+			printErrorAndExit(errors.New("'--rescan' and '--no-rescan' cannot be passed at the same time"))
+		}
+
+		config = importPrivateKeyConf
+	case dumpPrivateKeySubCmd:
+		combineNetworkFlags(&dumpPrivateKeyConf.NetworkFlags, &cfg.NetworkFlags)
+		err := dumpPrivateKeyConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = dumpPrivateKeyConf
+	case backupWalletSubCmd:
+		combineNetworkFlags(&backupWalletConf.NetworkFlags, &cfg.NetworkFlags)
+		err := backupWalletConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = backupWalletConf
+	case restoreWalletSubCmd:
+		combineNetworkFlags(&restoreWalletConf.NetworkFlags, &cfg.NetworkFlags)
+		err := restoreWalletConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = restoreWalletConf
+	case listTransactionsSubCmd:
+		combineNetworkFlags(&listTransactionsConf.NetworkFlags, &cfg.NetworkFlags)
+		err := listTransactionsConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = listTransactionsConf
+	case lockUnspentSubCmd:
+		combineNetworkFlags(&lockUnspentConf.NetworkFlags, &cfg.NetworkFlags)
+		err := lockUnspentConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = lockUnspentConf
+	case listLockUnspentSubCmd:
+		combineNetworkFlags(&listLockUnspentConf.NetworkFlags, &cfg.NetworkFlags)
+		err := listLockUnspentConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = listLockUnspentConf
+	case getTransactionSubCmd:
+		combineNetworkFlags(&getTransactionConf.NetworkFlags, &cfg.NetworkFlags)
+		err := getTransactionConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = getTransactionConf
 	}
 
 	return parser.Command.Active.Name, config
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/server"
@@ -10,15 +11,25 @@ import (
 
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
-// Connect connects to the kaspawalletd server, and returns the client instance
+// Connect connects to the kaspawalletd server, and returns the client instance.
+// A wallet other than the default one of the daemon is selected by appending
+// /<wallet name> to the address.
 func Connect(address string) (pb.KaspawalletdClient, func(), error) {
 	// Connection is local, so 1 second timeout is sufficient
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
 
-	conn, err := grpc.DialContext(ctx, address, grpc.WithInsecure(), grpc.WithBlock(), grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(server.MaxDaemonSendMsgSize)))
+	address, walletName := splitWalletName(address)
+	options := []grpc.DialOption{grpc.WithInsecure(), grpc.WithBlock(),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(server.MaxDaemonSendMsgSize))}
+	if walletName != "" {
+		options = append(options, grpc.WithUnaryInterceptor(walletNameInterceptor(walletName)))
+	}
+
+	conn, err := grpc.DialContext(ctx, address, options...)
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			return nil, nil, errors.New("kaspawallet daemon is not running, start it with `kaspawallet start-daemon`")
@@ -30,3 +41,20 @@ func Connect(address string) (pb.KaspawalletdClient, func(), error) {
 		conn.Close()
 	}, nil
 }
+
+func splitWalletName(address string) (string, string) {
+	slashIndex := strings.Index(address, "/")
+	if slashIndex == -1 {
+		return address, ""
+	}
+	return address[:slashIndex], address[slashIndex+1:]
+}
+
+func walletNameInterceptor(walletName string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		ctx = metadata.AppendToOutgoingContext(ctx, server.WalletMetadataKey, walletName)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -39,6 +39,21 @@ type KaspawalletdClient interface {
 	Sign(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*SignResponse, error)
 	GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*GetVersionResponse, error)
 	BumpFee(ctx context.Context, in *BumpFeeRequest, opts ...grpc.CallOption) (*BumpFeeResponse, error)
+	// Since ImportPrivateKeyRequest contains a password and a private key - this
+	// command should only be used on a trusted or secure connection
+	ImportPrivateKey(ctx context.Context, in *ImportPrivateKeyRequest, opts ...grpc.CallOption) (*ImportPrivateKeyResponse, error)
+	// Since DumpPrivateKeyRequest contains a password and DumpPrivateKeyResponse
+	// contains a private key - this command should only be used on a trusted or
+	// secure connection
+	DumpPrivateKey(ctx context.Context, in *DumpPrivateKeyRequest, opts ...grpc.CallOption) (*DumpPrivateKeyResponse, error)
+	// Since BackupWalletRequest and RestoreWalletRequest contain a password - these
+	// commands should only be used on a trusted or secure connection
+	BackupWallet(ctx context.Context, in *BackupWalletRequest, opts ...grpc.CallOption) (*BackupWalletResponse, error)
+	RestoreWallet(ctx context.Context, in *RestoreWalletRequest, opts ...grpc.CallOption) (*RestoreWalletResponse, error)
+	ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error)
+	LockUnspent(ctx context.Context, in *LockUnspentRequest, opts ...grpc.CallOption) (*LockUnspentResponse, error)
+	ListLockUnspent(ctx context.Context, in *ListLockUnspentRequest, opts ...grpc.CallOption) (*ListLockUnspentResponse, error)
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error)
 }
 
 type kaspawalletdClient struct {
@@ -157,6 +172,78 @@ func (c *kaspawalletdClient) BumpFee(ctx context.Context, in *BumpFeeRequest, op
 	return out, nil
 }
 
+func (c *kaspawalletdClient) ImportPrivateKey(ctx context.Context, in *ImportPrivateKeyRequest, opts ...grpc.CallOption) (*ImportPrivateKeyResponse, error) {
+	out := new(ImportPrivateKeyResponse)
+	err := c.cc.Invoke(ctx, "/kaspawalletd.kaspawalletd/ImportPrivateKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kaspawalletdClient) DumpPrivateKey(ctx context.Context, in *DumpPrivateKeyRequest, opts ...grpc.CallOption) (*DumpPrivateKeyResponse, error) {
+	out := new(DumpPrivateKeyResponse)
+	err := c.cc.Invoke(ctx, "/kaspawalletd.kaspawalletd/DumpPrivateKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kaspawalletdClient) BackupWallet(ctx context.Context, in *BackupWalletRequest, opts ...grpc.CallOption) (*BackupWalletResponse, error) {
+	out := new(BackupWalletResponse)
+	err := c.cc.Invoke(ctx, "/kaspawalletd.kaspawalletd/BackupWallet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kaspawalletdClient) RestoreWallet(ctx context.Context, in *RestoreWalletRequest, opts ...grpc.CallOption) (*RestoreWalletResponse, error) {
+	out := new(RestoreWalletResponse)
+	err := c.cc.Invoke(ctx, "/kaspawalletd.kaspawalletd/RestoreWallet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kaspawalletdClient) ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error) {
+	out := new(ListTransactionsResponse)
+	err := c.cc.Invoke(ctx, "/kaspawalletd.kaspawalletd/ListTransactions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kaspawalletdClient) LockUnspent(ctx context.Context, in *LockUnspentRequest, opts ...grpc.CallOption) (*LockUnspentResponse, error) {
+	out := new(LockUnspentResponse)
+	err := c.cc.Invoke(ctx, "/kaspawalletd.kaspawalletd/LockUnspent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kaspawalletdClient) ListLockUnspent(ctx context.Context, in *ListLockUnspentRequest, opts ...grpc.CallOption) (*ListLockUnspentResponse, error) {
+	out := new(ListLockUnspentResponse)
+	err := c.cc.Invoke(ctx, "/kaspawalletd.kaspawalletd/ListLockUnspent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kaspawalletdClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error) {
+	out := new(GetTransactionResponse)
+	err := c.cc.Invoke(ctx, "/kaspawalletd.kaspawalletd/GetTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // KaspawalletdServer is the server API for Kaspawalletd service.
 // All implementations must embed UnimplementedKaspawalletdServer
 // for forward compatibility
@@ -178,6 +265,21 @@ type KaspawalletdServer interface {
 	Sign(context.Context, *SignRequest) (*SignResponse, error)
 	GetVersion(context.Context, *GetVersionRequest) (*GetVersionResponse, error)
 	BumpFee(context.Context, *BumpFeeRequest) (*BumpFeeResponse, error)
+	// Since ImportPrivateKeyRequest contains a password and a private key - this
+	// command should only be used on a trusted or secure connection
+	ImportPrivateKey(context.Context, *ImportPrivateKeyRequest) (*ImportPrivateKeyResponse, error)
+	// Since DumpPrivateKeyRequest contains a password and DumpPrivateKeyResponse
+	// contains a private key - this command should only be used on a trusted or
+	// secure connection
+	DumpPrivateKey(context.Context, *DumpPrivateKeyRequest) (*DumpPrivateKeyResponse, error)
+	// Since BackupWalletRequest and RestoreWalletRequest contain a password - these
+	// commands should only be used on a trusted or secure connection
+	BackupWallet(context.Context, *BackupWalletRequest) (*BackupWalletResponse, error)
+	RestoreWallet(context.Context, *RestoreWalletRequest) (*RestoreWalletResponse, error)
+	ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error)
+	LockUnspent(context.Context, *LockUnspentRequest) (*LockUnspentResponse, error)
+	ListLockUnspent(context.Context, *ListLockUnspentRequest) (*ListLockUnspentResponse, error)
+	GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error)
 	mustEmbedUnimplementedKaspawalletdServer()
 }
 
@@ -221,6 +323,30 @@ func (UnimplementedKaspawalletdServer) GetVersion(context.Context, *GetVersionRe
 func (UnimplementedKaspawalletdServer) BumpFee(context.Context, *BumpFeeRequest) (*BumpFeeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BumpFee not implemented")
 }
+func (UnimplementedKaspawalletdServer) ImportPrivateKey(context.Context, *ImportPrivateKeyRequest) (*ImportPrivateKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportPrivateKey not implemented")
+}
+func (UnimplementedKaspawalletdServer) DumpPrivateKey(context.Context, *DumpPrivateKeyRequest) (*DumpPrivateKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DumpPrivateKey not implemented")
+}
+func (UnimplementedKaspawalletdServer) BackupWallet(context.Context, *BackupWalletRequest) (*BackupWalletResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BackupWallet not implemented")
+}
+func (UnimplementedKaspawalletdServer) RestoreWallet(context.Context, *RestoreWalletRequest) (*RestoreWalletResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreWallet not implemented")
+}
+func (UnimplementedKaspawalletdServer) ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTransactions not implemented")
+}
+func (UnimplementedKaspawalletdServer) LockUnspent(context.Context, *LockUnspentRequest) (*LockUnspentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LockUnspent not implemented")
+}
+func (UnimplementedKaspawalletdServer) ListLockUnspent(context.Context, *ListLockUnspentRequest) (*ListLockUnspentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLockUnspent not implemented")
+}
+func (UnimplementedKaspawalletdServer) GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
+}
 func (UnimplementedKaspawalletdServer) mustEmbedUnimplementedKaspawalletdServer() {}
 
 // UnsafeKaspawalletdServer may be embedded to opt out of forward compatibility for this service.
@@ -450,6 +576,150 @@ func _Kaspawalletd_BumpFee_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Kaspawalletd_ImportPrivateKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportPrivateKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).ImportPrivateKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kaspawalletd.kaspawalletd/ImportPrivateKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).ImportPrivateKey(ctx, req.(*ImportPrivateKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_DumpPrivateKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DumpPrivateKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).DumpPrivateKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kaspawalletd.kaspawalletd/DumpPrivateKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).DumpPrivateKey(ctx, req.(*DumpPrivateKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_BackupWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackupWalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).BackupWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kaspawalletd.kaspawalletd/BackupWallet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).BackupWallet(ctx, req.(*BackupWalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_RestoreWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreWalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).RestoreWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kaspawalletd.kaspawalletd/RestoreWallet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).RestoreWallet(ctx, req.(*RestoreWalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_ListTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).ListTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kaspawalletd.kaspawalletd/ListTransactions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).ListTransactions(ctx, req.(*ListTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_LockUnspent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockUnspentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).LockUnspent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kaspawalletd.kaspawalletd/LockUnspent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).LockUnspent(ctx, req.(*LockUnspentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_ListLockUnspent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLockUnspentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).ListLockUnspent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kaspawalletd.kaspawalletd/ListLockUnspent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).ListLockUnspent(ctx, req.(*ListLockUnspentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kaspawalletd.kaspawalletd/GetTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).GetTransaction(ctx, req.(*GetTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Kaspawalletd_ServiceDesc is the grpc.ServiceDesc for Kaspawalletd service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -505,6 +775,38 @@ var Kaspawalletd_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "BumpFee",
 			Handler:    _Kaspawalletd_BumpFee_Handler,
 		},
+		{
+			MethodName: "ImportPrivateKey",
+			Handler:    _Kaspawalletd_ImportPrivateKey_Handler,
+		},
+		{
+			MethodName: "DumpPrivateKey",
+			Handler:    _Kaspawalletd_DumpPrivateKey_Handler,
+		},
+		{
+			MethodName: "BackupWallet",
+			Handler:    _Kaspawalletd_BackupWallet_Handler,
+		},
+		{
+			MethodName: "RestoreWallet",
+			Handler:    _Kaspawalletd_RestoreWallet_Handler,
+		},
+		{
+			MethodName: "ListTransactions",
+			Handler:    _Kaspawalletd_ListTransactions_Handler,
+		},
+		{
+			MethodName: "LockUnspent",
+			Handler:    _Kaspawalletd_LockUnspent_Handler,
+		},
+		{
+			MethodName: "ListLockUnspent",
+			Handler:    _Kaspawalletd_ListLockUnspent_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _Kaspawalletd_GetTransaction_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "kaspawalletd.proto",
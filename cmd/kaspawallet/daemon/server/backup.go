@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+)
+
+func (s *server) BackupWallet(_ context.Context, request *pb.BackupWalletRequest) (*pb.BackupWalletResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	dagInfo, err := s.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := s.keysFile.CreateBackup(s.params, request.Password, dagInfo.VirtualDAAScore)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BackupWalletResponse{Archive: archive}, nil
+}
+
+func (s *server) RestoreWallet(_ context.Context, request *pb.RestoreWalletRequest) (*pb.RestoreWalletResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// The backup is fully verified before it replaces the keys file
+	backup, err := keys.OpenBackup(s.params, request.Archive, request.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.keysFile.Restore(backup)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.loadLockedOutpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.transactionHistory.reset()
+	if err != nil {
+		return nil, err
+	}
+
+	// The addresses of the restored wallet are scanned from scratch. The wallet isn't
+	// considered synced until the scan reaches its last used address again.
+	s.nextSyncStartIndex = 0
+	s.shouldResetAddresses = true
+	log.Infof("Restored the wallet from a backup created at %s", backup.CreatedAt)
+
+	return &pb.RestoreWalletResponse{
+		CreatedAt: backup.CreatedAt.UnixMilli(),
+		DaaScore:  backup.DAAScore,
+	}, nil
+}
@@ -8,10 +8,15 @@ import (
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
 )
 
-type balancesType struct{ available, pending uint64 }
+type balancesType struct {
+	available, pending uint64
+
+	// The confirmation tiers of the balances
+	immature, unconfirmed, confirmed uint64
+}
 type balancesMapType map[*walletAddress]*balancesType
 
-func (s *server) GetBalance(_ context.Context, _ *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
+func (s *server) GetBalance(_ context.Context, request *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
 
@@ -23,50 +28,89 @@ func (s *server) GetBalance(_ context.Context, _ *pb.GetBalanceRequest) (*pb.Get
 	if err != nil {
 		return nil, err
 	}
-	daaScore := dagInfo.VirtualDAAScore
-	balancesMap := make(balancesMapType, 0)
-	for _, entry := range s.utxosSortedByAmount {
-		amount := entry.UTXOEntry.Amount()
-		address := entry.address
-		balances, ok := balancesMap[address]
-		if !ok {
-			balances = new(balancesType)
-			balancesMap[address] = balances
-		}
-		if s.isUTXOSpendable(entry, daaScore) {
-			balances.available += amount
-		} else {
-			balances.pending += amount
-		}
-	}
+	balancesMap := s.balances(dagInfo.VirtualDAAScore, request.MinConfirmations)
 
 	addressBalances := make([]*pb.AddressBalances, len(balancesMap))
 	i := 0
-	var available, pending uint64
+	total := new(balancesType)
 	for walletAddress, balances := range balancesMap {
 		address, err := libkaspawallet.Address(s.params, s.keysFile.ExtendedPublicKeys, s.keysFile.MinimumSignatures, s.walletAddressPath(walletAddress), s.keysFile.ECDSA)
 		if err != nil {
 			return nil, err
 		}
 		addressBalances[i] = &pb.AddressBalances{
-			Address:   address.String(),
-			Available: balances.available,
-			Pending:   balances.pending,
+			Address:     address.String(),
+			Available:   balances.available,
+			Pending:     balances.pending,
+			Immature:    balances.immature,
+			Unconfirmed: balances.unconfirmed,
+			Confirmed:   balances.confirmed,
 		}
 		i++
-		available += balances.available
-		pending += balances.pending
+		total.available += balances.available
+		total.pending += balances.pending
+		total.immature += balances.immature
+		total.unconfirmed += balances.unconfirmed
+		total.confirmed += balances.confirmed
 	}
 
 	log.Infof("GetBalance request scanned %d UTXOs overall over %d addresses", len(s.utxosSortedByAmount), len(balancesMap))
 
 	return &pb.GetBalanceResponse{
-		Available:       available,
-		Pending:         pending,
+		Available:       total.available,
+		Pending:         total.pending,
 		AddressBalances: addressBalances,
+		Immature:        total.immature,
+		Unconfirmed:     total.unconfirmed,
+		Confirmed:       total.confirmed,
 	}, nil
 }
 
+// balances returns the balances of every address of the wallet that has any. The depth of
+// an output is measured by the DAA score of its accepting block, which is what UTXO entries
+// carry, and which grows along with the blue score of the DAG.
+func (s *server) balances(virtualDAAScore uint64, minConfirmations uint64) balancesMapType {
+	balancesMap := make(balancesMapType, 0)
+	addressBalances := func(address *walletAddress) *balancesType {
+		balances, ok := balancesMap[address]
+		if !ok {
+			balances = new(balancesType)
+			balancesMap[address] = balances
+		}
+		return balances
+	}
+
+	for _, entry := range s.utxosSortedByAmount {
+		amount := entry.UTXOEntry.Amount()
+		balances := addressBalances(entry.address)
+		if !s.isUTXOSpendable(entry, virtualDAAScore) {
+			balances.pending += amount
+			balances.immature += amount
+			continue
+		}
+
+		balances.available += amount
+		if confirmations(entry, virtualDAAScore) < minConfirmations {
+			balances.unconfirmed += amount
+		} else {
+			balances.confirmed += amount
+		}
+	}
+
+	for address, amount := range s.mempoolReceivedAmounts {
+		addressBalances(address).unconfirmed += amount
+	}
+
+	return balancesMap
+}
+
+func confirmations(entry *walletUTXO, virtualDAAScore uint64) uint64 {
+	if entry.UTXOEntry.BlockDAAScore() >= virtualDAAScore {
+		return 0
+	}
+	return virtualDAAScore - entry.UTXOEntry.BlockDAAScore()
+}
+
 func (s *server) isUTXOSpendable(entry *walletUTXO, virtualDAAScore uint64) bool {
 	if !entry.UTXOEntry.IsCoinbase() {
 		return true
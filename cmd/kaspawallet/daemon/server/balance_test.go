@@ -0,0 +1,49 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+)
+
+func TestBalanceConfirmationTiers(t *testing.T) {
+	const coinbaseMaturity = 100
+	const virtualDAAScore = 1000
+	const minConfirmations = 10
+
+	address := &walletAddress{index: 0}
+	walletUTXOWithEntry := func(amount uint64, isCoinbase bool, blockDAAScore uint64) *walletUTXO {
+		return &walletUTXO{
+			Outpoint:  &externalapi.DomainOutpoint{Index: uint32(amount)},
+			UTXOEntry: utxo.NewUTXOEntry(amount, &externalapi.ScriptPublicKey{}, isCoinbase, blockDAAScore),
+			address:   address,
+		}
+	}
+
+	serverInstance := &server{
+		coinbaseMaturity: coinbaseMaturity,
+		utxosSortedByAmount: []*walletUTXO{
+			walletUTXOWithEntry(1, true, virtualDAAScore-coinbaseMaturity),   // An immature coinbase
+			walletUTXOWithEntry(2, true, virtualDAAScore-coinbaseMaturity-1), // A mature coinbase
+			walletUTXOWithEntry(4, false, virtualDAAScore-minConfirmations+1),
+			walletUTXOWithEntry(8, false, virtualDAAScore-minConfirmations),
+		},
+		mempoolReceivedAmounts: map[*walletAddress]uint64{address: 16},
+	}
+
+	balances := serverInstance.balances(virtualDAAScore, minConfirmations)[address]
+	if balances.immature != 1 {
+		t.Fatalf("expected an immature balance of 1, but got %d", balances.immature)
+	}
+	if balances.unconfirmed != 4+16 {
+		t.Fatalf("expected an unconfirmed balance of %d, but got %d", 4+16, balances.unconfirmed)
+	}
+	if balances.confirmed != 2+8 {
+		t.Fatalf("expected a confirmed balance of %d, but got %d", 2+8, balances.confirmed)
+	}
+	if balances.available != 2+4+8 || balances.pending != 1 {
+		t.Fatalf("expected the available and pending balances not to change, but got %d and %d",
+			balances.available, balances.pending)
+	}
+}
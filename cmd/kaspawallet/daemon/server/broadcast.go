@@ -54,12 +54,24 @@ func (s *server) broadcast(transactions [][]byte, isDomain bool) ([]string, erro
 		for _, input := range tx.Inputs {
 			s.usedOutpoints[input.PreviousOutpoint] = time.Now()
 		}
+		s.recordSpendingTransaction(tx)
 	}
 
 	s.forceSync()
 	return txIDs, nil
 }
 
+// recordSpendingTransaction records the given transaction in the transaction history
+// as the spender of the outputs of the wallet it spends. It's also recorded once it's
+// seen in the mempool, so failing to record it here only costs a warning.
+func (s *server) recordSpendingTransaction(tx *externalapi.DomainTransaction) {
+	err := s.transactionHistory.recordSpendingTransaction(tx, consensushashing.TransactionID(tx))
+	if err != nil {
+		log.Warnf("Error recording the spending transaction %s in the transaction history: %s",
+			consensushashing.TransactionID(tx), err)
+	}
+}
+
 func sendTransaction(client *rpcclient.RPCClient, tx *externalapi.DomainTransaction) (string, error) {
 	submitTransactionResponse, err := client.SubmitTransaction(appmessage.DomainTransactionToRPCTransaction(tx), consensushashing.TransactionID(tx).String(), false)
 	if err != nil {
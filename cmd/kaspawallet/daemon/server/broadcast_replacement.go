@@ -66,6 +66,7 @@ func (s *server) broadcastReplacement(transactions [][]byte, isDomain bool) ([]s
 		for _, input := range tx.Inputs {
 			s.usedOutpoints[input.PreviousOutpoint] = time.Now()
 		}
+		s.recordSpendingTransaction(tx)
 	}
 
 	s.forceSync()
@@ -2,15 +2,25 @@ package server
 
 import (
 	"context"
+	"math"
+	"time"
 
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/serialization"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
 	"github.com/pkg/errors"
 )
 
+// BumpFee raises the fee rate of a mempool transaction either by replacing it (RBF) or
+// by spending one of its outputs to the wallet in a child transaction (CPFP). When both
+// are possible, the one that costs less additional fee is used.
 func (s *server) BumpFee(_ context.Context, request *pb.BumpFeeRequest) (*pb.BumpFeeResponse, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -25,6 +35,85 @@ func (s *server) BumpFee(_ context.Context, request *pb.BumpFeeRequest) (*pb.Bum
 		return nil, err
 	}
 
+	mass := s.txMassCalculator.CalculateTransactionOverallMass(domainTx)
+	feeRate := float64(entry.Entry.Fee) / float64(mass)
+	newFeeRate, maxFee, err := s.calculateFeeLimits(request.FeePolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	if feeRate >= newFeeRate {
+		return nil, errors.Errorf("new fee rate (%f) is not higher than the current fee rate (%f)", newFeeRate, feeRate)
+	}
+
+	var fromAddresses []*walletAddress
+	for _, from := range request.From {
+		fromAddress, exists := s.addressSet[from]
+		if !exists {
+			return nil, errors.Errorf("specified from address %s does not exists", from)
+		}
+		fromAddresses = append(fromAddresses, fromAddress)
+	}
+
+	rbfTransactions, rbfFee, rbfErr := s.createRBFTransactions(request, domainTx, newFeeRate, maxFee, fromAddresses)
+	cpfpTransaction, cpfpParentOutpoint, cpfpFee, cpfpErr := s.createCPFPTransaction(domainTx, entry.Entry.Fee, mass, newFeeRate, maxFee,
+		request.UseExistingChangeAddress, fromAddresses)
+	if rbfErr != nil && cpfpErr != nil {
+		return nil, errors.Errorf("couldn't bump the fee of transaction %s: replacing it failed: %s, "+
+			"and spending its outputs failed: %s", request.TxID, rbfErr, cpfpErr)
+	}
+
+	// The fee of the replaced transaction is no longer paid once it's replaced,
+	// so only the difference counts towards the cost of RBF
+	strategy := pb.BumpFeeStrategy_RBF
+	unsignedTransactions := rbfTransactions
+	if rbfErr != nil || (cpfpErr == nil && cpfpFee < rbfFee-min(rbfFee, entry.Entry.Fee)) {
+		strategy = pb.BumpFeeStrategy_CPFP
+		unsignedTransactions = [][]byte{cpfpTransaction}
+	}
+	log.Infof("Bumping the fee of transaction %s using %s", request.TxID, strategy)
+
+	// The output of the parent that the child spends isn't in the UTXO set of the wallet
+	// yet, so it's marked as used right away rather than only when the child is broadcast,
+	// so that bumping the fee again doesn't create a conflicting child
+	if strategy == pb.BumpFeeStrategy_CPFP {
+		s.usedOutpoints[cpfpParentOutpoint] = time.Now()
+	}
+
+	if request.Password == "" {
+		return &pb.BumpFeeResponse{
+			Transactions: unsignedTransactions,
+			Strategy:     strategy,
+		}, nil
+	}
+
+	signedTransactions, err := s.signTransactions(unsignedTransactions, request.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	var txIDs []string
+	if strategy == pb.BumpFeeStrategy_CPFP {
+		txIDs, err = s.broadcast(signedTransactions, false)
+	} else {
+		txIDs, err = s.broadcastReplacement(signedTransactions, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BumpFeeResponse{
+		TxIDs:        txIDs,
+		Transactions: signedTransactions,
+		Strategy:     strategy,
+	}, nil
+}
+
+// createRBFTransactions creates transactions that replace the given mempool transaction
+// with one that pays the given fee rate, and returns them along with their total fee
+func (s *server) createRBFTransactions(request *pb.BumpFeeRequest, domainTx *externalapi.DomainTransaction,
+	newFeeRate float64, maxFee uint64, fromAddresses []*walletAddress) ([][]byte, uint64, error) {
+
 	outpointsToInputs := make(map[externalapi.DomainOutpoint]*externalapi.DomainTransactionInput)
 	var maxUTXO *walletUTXO
 	for _, input := range domainTx.Inputs {
@@ -56,31 +145,11 @@ func (s *server) BumpFee(_ context.Context, request *pb.BumpFeeRequest) (*pb.Bum
 	}
 
 	if maxUTXO == nil {
-		return nil, errors.Errorf("no UTXOs were found for transaction %s. This probably means the transaction is already accepted", request.TxID)
-	}
-
-	mass := s.txMassCalculator.CalculateTransactionOverallMass(domainTx)
-	feeRate := float64(entry.Entry.Fee) / float64(mass)
-	newFeeRate, maxFee, err := s.calculateFeeLimits(request.FeePolicy)
-	if err != nil {
-		return nil, err
-	}
-
-	if feeRate >= newFeeRate {
-		return nil, errors.Errorf("new fee rate (%f) is not higher than the current fee rate (%f)", newFeeRate, feeRate)
+		return nil, 0, errors.Errorf("no UTXOs of the wallet are spent by transaction %s", request.TxID)
 	}
 
 	if len(domainTx.Outputs) == 0 || len(domainTx.Outputs) > 2 {
-		return nil, errors.Errorf("kaspawallet supports only transactions with 1 or 2 outputs in transaction %s, but this transaction got %d", request.TxID, len(domainTx.Outputs))
-	}
-
-	var fromAddresses []*walletAddress
-	for _, from := range request.From {
-		fromAddress, exists := s.addressSet[from]
-		if !exists {
-			return nil, errors.Errorf("specified from address %s does not exists", from)
-		}
-		fromAddresses = append(fromAddresses, fromAddress)
+		return nil, 0, errors.Errorf("kaspawallet supports only transactions with 1 or 2 outputs in transaction %s, but this transaction got %d", request.TxID, len(domainTx.Outputs))
 	}
 
 	allowUsed := make(map[externalapi.DomainOutpoint]struct{})
@@ -89,21 +158,21 @@ func (s *server) BumpFee(_ context.Context, request *pb.BumpFeeRequest) (*pb.Bum
 	}
 	selectedUTXOs, spendValue, changeSompi, err := s.selectUTXOsWithPreselected([]*walletUTXO{maxUTXO}, allowUsed, domainTx.Outputs[0].Value, false, newFeeRate, maxFee, fromAddresses)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	_, toAddress, err := txscript.ExtractScriptPubKeyAddress(domainTx.Outputs[0].ScriptPublicKey, s.params)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	changeAddress, changeWalletAddress, err := s.changeAddress(request.UseExistingChangeAddress, fromAddresses)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	if len(selectedUTXOs) == 0 {
-		return nil, errors.Errorf("couldn't find funds to spend")
+		return nil, 0, errors.Errorf("couldn't find funds to spend")
 	}
 
 	payments := []*libkaspawallet.Payment{{
@@ -113,7 +182,7 @@ func (s *server) BumpFee(_ context.Context, request *pb.BumpFeeRequest) (*pb.Bum
 	if changeSompi > 0 {
 		changeAddress, _, err := s.changeAddress(request.UseExistingChangeAddress, fromAddresses)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
 		payments = append(payments, &libkaspawallet.Payment{
@@ -121,36 +190,141 @@ func (s *server) BumpFee(_ context.Context, request *pb.BumpFeeRequest) (*pb.Bum
 			Amount:  changeSompi,
 		})
 	}
-	unsignedTransaction, err := libkaspawallet.CreateUnsignedTransaction(s.keysFile.ExtendedPublicKeys,
+	lockTime, err := s.antiFeeSnipingLockTime()
+	if err != nil {
+		return nil, 0, err
+	}
+	unsignedTransaction, err := libkaspawallet.CreateUnsignedTransactionWithLockTime(s.keysFile.ExtendedPublicKeys,
 		s.keysFile.MinimumSignatures,
-		payments, selectedUTXOs)
+		payments, selectedUTXOs, lockTime)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	unsignedTransactions, err := s.maybeAutoCompoundTransaction(unsignedTransaction, toAddress, changeAddress, changeWalletAddress, newFeeRate, maxFee)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	if request.Password == "" {
-		return &pb.BumpFeeResponse{
-			Transactions: unsignedTransactions,
-		}, nil
+	fee, err := unsignedTransactionsFee(unsignedTransactions)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	signedTransactions, err := s.signTransactions(unsignedTransactions, request.Password)
+	return unsignedTransactions, fee, nil
+}
+
+// createCPFPTransaction creates a transaction that spends the largest output of the given
+// mempool transaction that pays to the wallet back to the wallet, with a fee that brings
+// the fee rate of both transactions together up to the given fee rate. It returns the
+// transaction along with the output of the parent it spends and its fee
+func (s *server) createCPFPTransaction(parentTx *externalapi.DomainTransaction, parentFee uint64, parentMass uint64,
+	feeRate float64, maxFee uint64, useExistingChangeAddress bool, fromAddresses []*walletAddress) (
+	[]byte, externalapi.DomainOutpoint, uint64, error) {
+
+	parentTxID := consensushashing.TransactionID(parentTx)
+	var parentUTXO *libkaspawallet.UTXO
+	for i, output := range parentTx.Outputs {
+		_, address, err := txscript.ExtractScriptPubKeyAddress(output.ScriptPublicKey, s.params)
+		if err != nil || address == nil {
+			continue
+		}
+		outputWalletAddress, ok := s.addressSet[address.String()]
+		if !ok || (fromAddresses != nil && !walletAddressesContain(fromAddresses, outputWalletAddress)) {
+			continue
+		}
+		if parentUTXO != nil && parentUTXO.UTXOEntry.Amount() >= output.Value {
+			continue
+		}
+
+		// An output that an earlier child already spends isn't spent again
+		outpoint := externalapi.NewDomainOutpoint(parentTxID, uint32(i))
+		if broadcastTime, ok := s.usedOutpoints[*outpoint]; ok && !s.usedOutpointHasExpired(broadcastTime) {
+			continue
+		}
+
+		parentUTXO = &libkaspawallet.UTXO{
+			Outpoint:       outpoint,
+			UTXOEntry:      utxo.NewUTXOEntry(output.Value, output.ScriptPublicKey, false, constants.UnacceptedDAAScore),
+			DerivationPath: s.walletAddressPath(outputWalletAddress),
+		}
+	}
+
+	if parentUTXO == nil {
+		return nil, externalapi.DomainOutpoint{}, 0, errors.Errorf("transaction %s has no outputs that pay to the wallet", parentTxID)
+	}
+
+	changeAddress, _, err := s.changeAddress(useExistingChangeAddress, fromAddresses)
 	if err != nil {
-		return nil, err
+		return nil, externalapi.DomainOutpoint{}, 0, err
+	}
+
+	createChild := func(fee uint64) (*serialization.PartiallySignedTransaction, error) {
+		return libkaspawallet.CreateUnsignedTransaction(s.keysFile.ExtendedPublicKeys,
+			s.keysFile.MinimumSignatures,
+			[]*libkaspawallet.Payment{{
+				Address: changeAddress,
+				Amount:  parentUTXO.UTXOEntry.Amount() - fee,
+			}}, []*libkaspawallet.UTXO{parentUTXO})
 	}
 
-	txIDs, err := s.broadcastReplacement(signedTransactions, false)
+	mockChild, err := createChild(0)
 	if err != nil {
-		return nil, err
+		return nil, externalapi.DomainOutpoint{}, 0, err
+	}
+	childMass, err := s.estimateMassAfterSignatures(mockChild)
+	if err != nil {
+		return nil, externalapi.DomainOutpoint{}, 0, err
 	}
 
-	return &pb.BumpFeeResponse{
-		TxIDs:        txIDs,
-		Transactions: signedTransactions,
-	}, nil
+	// The parent pays less than feeRate for its own mass, so the package fee is always
+	// higher than the parent fee
+	packageFee := uint64(math.Ceil(float64(parentMass+childMass) * feeRate))
+	fee := packageFee - min(packageFee, parentFee)
+	if fee > maxFee {
+		return nil, externalapi.DomainOutpoint{}, 0, errors.Errorf("spending the outputs of transaction %s requires a fee of %d, "+
+			"which is higher than the maximum fee %d", parentTxID, fee, maxFee)
+	}
+	if fee >= parentUTXO.UTXOEntry.Amount() {
+		return nil, externalapi.DomainOutpoint{}, 0, errors.Errorf("the outputs of transaction %s to the wallet are too small to pay "+
+			"a fee of %d", parentTxID, fee)
+	}
+	childOutput := &externalapi.DomainTransactionOutput{
+		Value:           parentUTXO.UTXOEntry.Amount() - fee,
+		ScriptPublicKey: mockChild.Tx.Outputs[0].ScriptPublicKey,
+	}
+	if mempool.IsTransactionOutputDust(childOutput, mempool.DefaultConfig(s.params).MinimumRelayTransactionFee) {
+		return nil, externalapi.DomainOutpoint{}, 0, errors.Errorf("spending the outputs of transaction %s with a fee of %d leaves "+
+			"a dust output of %d", parentTxID, fee, childOutput.Value)
+	}
+
+	child, err := createChild(fee)
+	if err != nil {
+		return nil, externalapi.DomainOutpoint{}, 0, err
+	}
+	serializedChild, err := serialization.SerializePartiallySignedTransaction(child)
+	if err != nil {
+		return nil, externalapi.DomainOutpoint{}, 0, err
+	}
+
+	return serializedChild, *parentUTXO.Outpoint, fee, nil
+}
+
+// unsignedTransactionsFee returns the total fee paid by the given unsigned transactions
+func unsignedTransactionsFee(unsignedTransactions [][]byte) (uint64, error) {
+	fee := uint64(0)
+	for _, unsignedTransaction := range unsignedTransactions {
+		transaction, err := serialization.DeserializePartiallySignedTransaction(unsignedTransaction)
+		if err != nil {
+			return 0, err
+		}
+		for _, input := range transaction.PartiallySignedInputs {
+			fee += input.PrevOutput.Value
+		}
+		for _, output := range transaction.Tx.Outputs {
+			fee -= output.Value
+		}
+	}
+
+	return fee, nil
 }
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/serialization"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/kaspanet/kaspad/util/txmass"
+)
+
+func TestCreateCPFPTransaction(t *testing.T) {
+	params := &dagconfig.SimnetParams
+	mnemonic, err := libkaspawallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+	keysFile, err := keys.NewFileFromMnemonic(params, mnemonic, "password")
+	if err != nil {
+		t.Fatalf("NewFileFromMnemonic: %+v", err)
+	}
+
+	serverInstance := &server{
+		params:           params,
+		keysFile:         keysFile,
+		addressSet:       make(walletAddressSet),
+		usedOutpoints:    map[externalapi.DomainOutpoint]time.Time{},
+		txMassCalculator: txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp),
+	}
+
+	walletAddr := &walletAddress{index: 1, keyChain: libkaspawallet.ExternalKeychain}
+	walletAddressString, err := serverInstance.walletAddressString(walletAddr)
+	if err != nil {
+		t.Fatalf("walletAddressString: %+v", err)
+	}
+	serverInstance.addressSet[walletAddressString] = walletAddr
+	address, err := util.DecodeAddress(walletAddressString, params.Prefix)
+	if err != nil {
+		t.Fatalf("DecodeAddress: %+v", err)
+	}
+	walletScriptPublicKey, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %+v", err)
+	}
+
+	parentTx := &externalapi.DomainTransaction{
+		Inputs: []*externalapi.DomainTransactionInput{{
+			PreviousOutpoint: externalapi.DomainOutpoint{
+				TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{1}),
+			},
+			SignatureScript: make([]byte, 66),
+			SigOpCount:      1,
+			UTXOEntry:       utxo.NewUTXOEntry(200*constants.SompiPerKaspa, walletScriptPublicKey, false, 0),
+		}},
+		Outputs: []*externalapi.DomainTransactionOutput{
+			{Value: 50 * constants.SompiPerKaspa, ScriptPublicKey: &externalapi.ScriptPublicKey{Script: make([]byte, 34)}},
+			{Value: 150*constants.SompiPerKaspa - 1000, ScriptPublicKey: walletScriptPublicKey},
+		},
+	}
+	const parentFee = 1000
+	parentMass := serverInstance.txMassCalculator.CalculateTransactionOverallMass(parentTx)
+	const feeRate = 10.0
+
+	child, parentOutpoint, childFee, err := serverInstance.createCPFPTransaction(parentTx, parentFee, parentMass, feeRate,
+		constants.SompiPerKaspa, true, nil)
+	if err != nil {
+		t.Fatalf("createCPFPTransaction: %+v", err)
+	}
+
+	signedChild, err := libkaspawallet.Sign(params, []string{mnemonic}, child, keysFile.ECDSA)
+	if err != nil {
+		t.Fatalf("Sign: %+v", err)
+	}
+	childTx, err := libkaspawallet.ExtractTransaction(signedChild, keysFile.ECDSA)
+	if err != nil {
+		t.Fatalf("ExtractTransaction: %+v", err)
+	}
+
+	expectedOutpoint := externalapi.NewDomainOutpoint(consensushashing.TransactionID(parentTx), 1)
+	if len(childTx.Inputs) != 1 || childTx.Inputs[0].PreviousOutpoint != *expectedOutpoint ||
+		parentOutpoint != *expectedOutpoint {
+
+		t.Fatalf("expected the child to spend only %s", expectedOutpoint)
+	}
+	if childTx.Outputs[0].Value != parentTx.Outputs[1].Value-childFee {
+		t.Fatalf("expected the child to pay a fee of %d", childFee)
+	}
+
+	childTx.Inputs[0].UTXOEntry = utxo.NewUTXOEntry(parentTx.Outputs[1].Value, walletScriptPublicKey, false,
+		constants.UnacceptedDAAScore)
+	childMass := serverInstance.txMassCalculator.CalculateTransactionOverallMass(childTx)
+	packageFeeRate := float64(parentFee+childFee) / float64(parentMass+childMass)
+	if packageFeeRate < feeRate {
+		t.Fatalf("expected the fee rate of the parent and the child to be at least %f but got %f",
+			feeRate, packageFeeRate)
+	}
+
+	_, _, _, err = serverInstance.createCPFPTransaction(parentTx, parentFee, parentMass, feeRate, childFee-1, true, nil)
+	if err == nil {
+		t.Fatalf("expected an error when the fee of the child is higher than the maximum fee")
+	}
+
+	// An output of the parent that an earlier child spends isn't spent again
+	serverInstance.usedOutpoints[parentOutpoint] = time.Now()
+	_, _, _, err = serverInstance.createCPFPTransaction(parentTx, parentFee, parentMass, feeRate,
+		constants.SompiPerKaspa, true, nil)
+	if err == nil {
+		t.Fatalf("expected an error when the output of the parent is already spent by a child")
+	}
+	delete(serverInstance.usedOutpoints, parentOutpoint)
+
+	walletOutputValue := parentTx.Outputs[1].Value
+	parentTx.Outputs[1].Value = childFee + 100
+	_, _, _, err = serverInstance.createCPFPTransaction(parentTx, parentFee, parentMass, feeRate,
+		constants.SompiPerKaspa, true, nil)
+	if err == nil || !strings.Contains(err.Error(), "dust") {
+		t.Fatalf("expected an error when the output of the child is dust, but got %v", err)
+	}
+	parentTx.Outputs[1].Value = walletOutputValue
+
+	parentTx.Outputs[1].ScriptPublicKey = parentTx.Outputs[0].ScriptPublicKey
+	_, _, _, err = serverInstance.createCPFPTransaction(parentTx, parentFee, parentMass, feeRate,
+		constants.SompiPerKaspa, true, nil)
+	if err == nil {
+		t.Fatalf("expected an error when no output of the parent pays to the wallet")
+	}
+}
+
+func TestUnsignedTransactionsFee(t *testing.T) {
+	transaction := &serialization.PartiallySignedTransaction{
+		Tx: &externalapi.DomainTransaction{
+			Outputs: []*externalapi.DomainTransactionOutput{
+				{Value: 70, ScriptPublicKey: &externalapi.ScriptPublicKey{}},
+				{Value: 20, ScriptPublicKey: &externalapi.ScriptPublicKey{}},
+			},
+		},
+		PartiallySignedInputs: []*serialization.PartiallySignedInput{
+			{PrevOutput: &externalapi.DomainTransactionOutput{Value: 60, ScriptPublicKey: &externalapi.ScriptPublicKey{}}},
+			{PrevOutput: &externalapi.DomainTransactionOutput{Value: 40, ScriptPublicKey: &externalapi.ScriptPublicKey{}}},
+		},
+	}
+	serializedTransaction, err := serialization.SerializePartiallySignedTransaction(transaction)
+	if err != nil {
+		t.Fatalf("SerializePartiallySignedTransaction: %+v", err)
+	}
+
+	fee, err := unsignedTransactionsFee([][]byte{serializedTransaction, serializedTransaction})
+	if err != nil {
+		t.Fatalf("unsignedTransactionsFee: %+v", err)
+	}
+	if fee != 20 {
+		t.Fatalf("expected a fee of 20 but got %d", fee)
+	}
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"math"
+	"time"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/serialization"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/pkg/errors"
+)
+
+// consolidationInterval is how often the wallet checks whether its UTXOs should be consolidated
+const consolidationInterval = time.Minute
+
+// ConsolidationConfig configures the automatic consolidation of the wallet UTXOs. While the
+// wallet has more than MaxUTXOs spendable UTXOs and the estimated fee rate is at most
+// MaxFeeRate, its smallest UTXOs are merged into a single change output, so that the
+// transactions of busy wallets don't grow too large to be standard.
+type ConsolidationConfig struct {
+	MaxUTXOs   uint32 // Zero disables the consolidation
+	MaxFeeRate float64
+	Password   string
+}
+
+func (s *server) consolidationLoop() {
+	ticker := time.NewTicker(consolidationInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := s.maybeConsolidate()
+		if err != nil {
+			log.Errorf("Error consolidating the wallet UTXOs: %+v", err)
+		}
+	}
+}
+
+// maybeConsolidate submits a consolidation transaction if the wallet has too many
+// UTXOs and the fee rate is low enough
+func (s *server) maybeConsolidate() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.isSynced() {
+		return nil
+	}
+
+	dagInfo, err := s.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		return err
+	}
+	candidates := s.consolidationCandidates(dagInfo.VirtualDAAScore)
+	if uint32(len(candidates)) <= s.consolidationConfig.MaxUTXOs {
+		return nil
+	}
+
+	estimate, err := s.rpcClient.GetFeeEstimate()
+	if err != nil {
+		return err
+	}
+	feeRate := math.Max(estimate.Estimate.NormalBuckets[0].Feerate, minFeeRate)
+	if feeRate > s.consolidationConfig.MaxFeeRate {
+		log.Debugf("Not consolidating %d UTXOs since the fee rate %f is higher than %f",
+			len(candidates), feeRate, s.consolidationConfig.MaxFeeRate)
+		return nil
+	}
+
+	unsignedTransaction, inputCount, err := s.createConsolidationTransaction(candidates, feeRate)
+	if err != nil {
+		return err
+	}
+	signedTransactions, err := s.signTransactions([][]byte{unsignedTransaction}, s.consolidationConfig.Password)
+	if err != nil {
+		return err
+	}
+	txIDs, err := s.broadcast(signedTransactions, false)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Submitted consolidation transaction %s, which merges %d of the %d spendable UTXOs",
+		txIDs[0], inputCount, len(candidates))
+	return nil
+}
+
+// consolidationCandidates returns the UTXOs that may be consolidated, from the smallest
+// to the largest
+func (s *server) consolidationCandidates(virtualDAAScore uint64) []*walletUTXO {
+	var candidates []*walletUTXO
+	for i := len(s.utxosSortedByAmount) - 1; i >= 0; i-- {
+		utxo := s.utxosSortedByAmount[i]
+		if !s.isUTXOSpendable(utxo, virtualDAAScore) || s.isOutpointLocked(utxo.Outpoint) {
+			continue
+		}
+		if broadcastTime, ok := s.usedOutpoints[*utxo.Outpoint]; ok && !s.usedOutpointHasExpired(broadcastTime) {
+			continue
+		}
+		candidates = append(candidates, utxo)
+	}
+	return candidates
+}
+
+// createConsolidationTransaction creates a transaction that spends as many of the given
+// UTXOs as fit in a standard transaction, in their order, to a new change address. It
+// returns the transaction along with the number of UTXOs it spends.
+func (s *server) createConsolidationTransaction(candidates []*walletUTXO, feeRate float64) ([]byte, int, error) {
+	changeAddress, _, err := s.changeAddress(false, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	createTransaction := func(utxos []*libkaspawallet.UTXO, amount uint64) (*serialization.PartiallySignedTransaction, error) {
+		return libkaspawallet.CreateUnsignedTransaction(s.keysFile.ExtendedPublicKeys,
+			s.keysFile.MinimumSignatures,
+			[]*libkaspawallet.Payment{{
+				Address: changeAddress,
+				Amount:  amount,
+			}}, utxos)
+	}
+
+	var selectedUTXOs []*libkaspawallet.UTXO
+	totalValue := uint64(0)
+	mass := uint64(0)
+	for _, candidate := range candidates {
+		utxos := append(selectedUTXOs, &libkaspawallet.UTXO{
+			Outpoint:       candidate.Outpoint,
+			UTXOEntry:      candidate.UTXOEntry,
+			DerivationPath: s.walletAddressPath(candidate.address),
+		})
+		mockTransaction, err := createTransaction(utxos, totalValue+candidate.UTXOEntry.Amount())
+		if err != nil {
+			return nil, 0, err
+		}
+		mockMass, err := s.estimateMassAfterSignatures(mockTransaction)
+		if err != nil {
+			return nil, 0, err
+		}
+		if mockMass > mempool.MaximumStandardTransactionMass {
+			break
+		}
+
+		selectedUTXOs = utxos
+		totalValue += candidate.UTXOEntry.Amount()
+		mass = mockMass
+	}
+
+	if len(selectedUTXOs) < 2 {
+		return nil, 0, errors.Errorf("couldn't fit at least two UTXOs in a consolidation transaction")
+	}
+	fee := uint64(math.Ceil(float64(mass) * feeRate))
+	if fee >= totalValue {
+		return nil, 0, errors.Errorf("the %d smallest UTXOs are too small to pay a consolidation fee of %d",
+			len(selectedUTXOs), fee)
+	}
+
+	transaction, err := createTransaction(selectedUTXOs, totalValue-fee)
+	if err != nil {
+		return nil, 0, err
+	}
+	serializedTransaction, err := serialization.SerializePartiallySignedTransaction(transaction)
+	if err != nil {
+		return nil, 0, err
+	}
+	return serializedTransaction, len(selectedUTXOs), nil
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/serialization"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/kaspanet/kaspad/util/txmass"
+)
+
+func TestCreateConsolidationTransaction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestCreateConsolidationTransaction")
+	if err != nil {
+		t.Fatalf("TempDir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	params := &dagconfig.SimnetParams
+	mnemonic, err := libkaspawallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+	keysFile, err := keys.NewFileFromMnemonic(params, mnemonic, "password")
+	if err != nil {
+		t.Fatalf("NewFileFromMnemonic: %+v", err)
+	}
+	err = keysFile.SetPath(params, filepath.Join(dir, "keys.json"), true)
+	if err != nil {
+		t.Fatalf("SetPath: %+v", err)
+	}
+
+	serverInstance := &server{
+		params:           params,
+		keysFile:         keysFile,
+		addressSet:       make(walletAddressSet),
+		txMassCalculator: txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp),
+		usedOutpoints:    map[externalapi.DomainOutpoint]time.Time{},
+		lockedOutpoints:  map[externalapi.DomainOutpoint]bool{},
+	}
+
+	walletAddr := &walletAddress{index: 1, keyChain: libkaspawallet.ExternalKeychain}
+	walletAddressString, err := serverInstance.walletAddressString(walletAddr)
+	if err != nil {
+		t.Fatalf("walletAddressString: %+v", err)
+	}
+	address, err := util.DecodeAddress(walletAddressString, params.Prefix)
+	if err != nil {
+		t.Fatalf("DecodeAddress: %+v", err)
+	}
+	walletScriptPublicKey, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %+v", err)
+	}
+
+	// The UTXOs are sorted by amount, from the largest to the smallest
+	const utxoCount = 200
+	for i := 0; i < utxoCount; i++ {
+		transactionID := externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{byte(i), byte(i >> 8)})
+		serverInstance.utxosSortedByAmount = append(serverInstance.utxosSortedByAmount, &walletUTXO{
+			Outpoint:  externalapi.NewDomainOutpoint(transactionID, 0),
+			UTXOEntry: utxo.NewUTXOEntry(uint64(utxoCount-i)*constants.SompiPerKaspa, walletScriptPublicKey, false, 0),
+			address:   walletAddr,
+		})
+	}
+	smallestUTXO := serverInstance.utxosSortedByAmount[utxoCount-1]
+	lockedUTXO := serverInstance.utxosSortedByAmount[utxoCount-2]
+	usedUTXO := serverInstance.utxosSortedByAmount[utxoCount-3]
+	serverInstance.lockedOutpoints[*lockedUTXO.Outpoint] = false
+	serverInstance.usedOutpoints[*usedUTXO.Outpoint] = time.Now()
+
+	candidates := serverInstance.consolidationCandidates(0)
+	if len(candidates) != utxoCount-2 {
+		t.Fatalf("expected %d consolidation candidates but got %d", utxoCount-2, len(candidates))
+	}
+	if candidates[0] != smallestUTXO {
+		t.Fatalf("expected the smallest UTXO to be consolidated first")
+	}
+
+	const feeRate = 1.0
+	unsignedTransaction, inputCount, err := serverInstance.createConsolidationTransaction(candidates, feeRate)
+	if err != nil {
+		t.Fatalf("createConsolidationTransaction: %+v", err)
+	}
+	if inputCount < 2 || inputCount >= len(candidates) {
+		t.Fatalf("expected the consolidation transaction to spend only part of the candidates, but it spends %d",
+			inputCount)
+	}
+
+	transaction, err := serialization.DeserializePartiallySignedTransaction(unsignedTransaction)
+	if err != nil {
+		t.Fatalf("DeserializePartiallySignedTransaction: %+v", err)
+	}
+	if len(transaction.Tx.Inputs) != inputCount || len(transaction.Tx.Outputs) != 1 {
+		t.Fatalf("expected %d inputs and a single output but got %d inputs and %d outputs",
+			inputCount, len(transaction.Tx.Inputs), len(transaction.Tx.Outputs))
+	}
+	for i, input := range transaction.Tx.Inputs {
+		if input.PreviousOutpoint != *candidates[i].Outpoint {
+			t.Fatalf("expected input %d to spend %s but got %s", i, candidates[i].Outpoint, input.PreviousOutpoint)
+		}
+	}
+
+	mass, err := serverInstance.estimateMassAfterSignatures(transaction)
+	if err != nil {
+		t.Fatalf("estimateMassAfterSignatures: %+v", err)
+	}
+	if mass > mempool.MaximumStandardTransactionMass {
+		t.Fatalf("expected the consolidation transaction to be standard, but its mass is %d", mass)
+	}
+	fee, err := unsignedTransactionsFee([][]byte{unsignedTransaction})
+	if err != nil {
+		t.Fatalf("unsignedTransactionsFee: %+v", err)
+	}
+	if fee != mass {
+		t.Fatalf("expected a fee of %d but got %d", mass, fee)
+	}
+}
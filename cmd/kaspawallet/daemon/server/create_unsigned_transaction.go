@@ -133,9 +133,13 @@ func (s *server) createUnsignedTransactions(address string, amount uint64, isSen
 			Amount:  changeSompi,
 		})
 	}
-	unsignedTransaction, err := libkaspawallet.CreateUnsignedTransaction(s.keysFile.ExtendedPublicKeys,
+	lockTime, err := s.antiFeeSnipingLockTime()
+	if err != nil {
+		return nil, err
+	}
+	unsignedTransaction, err := libkaspawallet.CreateUnsignedTransactionWithLockTime(s.keysFile.ExtendedPublicKeys,
 		s.keysFile.MinimumSignatures,
-		payments, selectedUTXOs)
+		payments, selectedUTXOs, lockTime)
 	if err != nil {
 		return nil, err
 	}
@@ -147,6 +151,16 @@ func (s *server) createUnsignedTransactions(address string, amount uint64, isSen
 	return unsignedTransactions, nil
 }
 
+// antiFeeSnipingLockTime returns the lock time of a new transaction that the wallet sends,
+// which is around the current DAA score. See libkaspawallet.AntiFeeSnipingLockTime.
+func (s *server) antiFeeSnipingLockTime() (uint64, error) {
+	dagInfo, err := s.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		return 0, err
+	}
+	return libkaspawallet.AntiFeeSnipingLockTime(dagInfo.VirtualDAAScore), nil
+}
+
 func (s *server) selectUTXOs(spendAmount uint64, isSendAll bool, feeRate float64, maxFee uint64, fromAddresses []*walletAddress) (
 	selectedUTXOs []*libkaspawallet.UTXO, totalReceived uint64, changeSompi uint64, err error) {
 	return s.selectUTXOsWithPreselected(nil, map[externalapi.DomainOutpoint]struct{}{}, spendAmount, isSendAll, feeRate, maxFee, fromAddresses)
@@ -173,6 +187,10 @@ func (s *server) selectUTXOsWithPreselected(preSelectedUTXOs []*walletUTXO, allo
 			return true, nil
 		}
 
+		if _, ok := allowUsed[*utxo.Outpoint]; !ok && s.isOutpointLocked(utxo.Outpoint) {
+			return true, nil
+		}
+
 		if broadcastTime, ok := s.usedOutpoints[*utxo.Outpoint]; ok {
 			if _, ok := allowUsed[*utxo.Outpoint]; !ok {
 				if s.usedOutpointHasExpired(broadcastTime) {
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"sort"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionid"
+	"github.com/pkg/errors"
+)
+
+// GetTransaction returns the status of the given transaction in the node, including its
+// confirmations by blue score, along with what the transaction history of the wallet
+// recorded about it. Transactions that were already accepted can only be found by the
+// node if it runs with --txindex, so the wallet details are returned on their own when
+// the node can't find the transaction.
+func (s *server) GetTransaction(_ context.Context, request *pb.GetTransactionRequest) (*pb.GetTransactionResponse, error) {
+	transactionID, err := transactionid.FromString(request.TransactionId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed transaction ID %s", request.TransactionId)
+	}
+
+	walletTransaction, err := s.walletTransaction(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.GetTransactionResponse{WalletTransaction: walletTransaction}
+	getRawTransactionResponse, err := s.rpcClient.GetRawTransaction(transactionID.String())
+	if err != nil {
+		if walletTransaction == nil {
+			return nil, err
+		}
+		log.Warnf("Could not get the status of transaction %s from the node: %s", transactionID, err)
+		return response, nil
+	}
+
+	response.IsInMempool = getRawTransactionResponse.IsInMempool
+	response.Confirmations = getRawTransactionResponse.Confirmations
+	response.AcceptingBlockHash = getRawTransactionResponse.AcceptingBlockHash
+	return response, nil
+}
+
+// walletTransaction returns the given transaction as recorded in the transaction history
+// of the wallet, or nil if the transaction isn't in the history
+func (s *server) walletTransaction(transactionID *externalapi.DomainTransactionID) (*pb.WalletTransaction, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	transactionsByID, err := s.transactionHistory.transactions()
+	if err != nil {
+		return nil, err
+	}
+	transaction, ok := transactionsByID[*transactionID]
+	if !ok {
+		return nil, nil
+	}
+
+	sort.Strings(transaction.addresses)
+	return transaction.toProto(), nil
+}
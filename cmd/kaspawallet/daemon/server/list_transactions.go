@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionid"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultListTransactionsLimit = 100
+	maxListTransactionsLimit     = 1000
+)
+
+// transactionCursor is the position of a transaction in the listing of the wallet
+// transactions. Transactions are ordered by the DAA score of their accepting block,
+// and then by their ID, so that newly accepted transactions are always listed after
+// the ones that were already accepted, and a cursor never skips or repeats any of them.
+type transactionCursor struct {
+	blockDAAScore uint64
+	transactionID externalapi.DomainTransactionID
+}
+
+func (c *transactionCursor) less(other *transactionCursor) bool {
+	if c.blockDAAScore != other.blockDAAScore {
+		return c.blockDAAScore < other.blockDAAScore
+	}
+	return c.transactionID.Less(&other.transactionID)
+}
+
+func (c *transactionCursor) String() string {
+	return fmt.Sprintf("%d:%s", c.blockDAAScore, &c.transactionID)
+}
+
+func parseTransactionCursor(cursorString string) (*transactionCursor, error) {
+	parts := strings.Split(cursorString, ":")
+	if len(parts) != 2 {
+		return nil, errors.Errorf("malformed cursor %s", cursorString)
+	}
+
+	blockDAAScore, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed cursor %s", cursorString)
+	}
+
+	transactionID, err := transactionid.FromString(parts[1])
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed cursor %s", cursorString)
+	}
+
+	return &transactionCursor{blockDAAScore: blockDAAScore, transactionID: *transactionID}, nil
+}
+
+type walletTransaction struct {
+	cursor      *transactionCursor
+	amount      uint64
+	spentAmount uint64
+	addresses   []string
+	isCoinbase  bool
+}
+
+func (wt *walletTransaction) addAddress(address string) {
+	if !containsString(wt.addresses, address) {
+		wt.addresses = append(wt.addresses, address)
+	}
+}
+
+func (wt *walletTransaction) toProto() *pb.WalletTransaction {
+	return &pb.WalletTransaction{
+		TransactionId: wt.cursor.transactionID.String(),
+		BlockDaaScore: wt.cursor.blockDAAScore,
+		Amount:        wt.amount,
+		SpentAmount:   wt.spentAmount,
+		Addresses:     wt.addresses,
+		IsCoinbase:    wt.isCoinbase,
+	}
+}
+
+// ListTransactions lists the accepted transactions that paid to the wallet or spent its outputs,
+// as recorded in the transaction history of the wallet.
+func (s *server) ListTransactions(_ context.Context, request *pb.ListTransactionsRequest) (*pb.ListTransactionsResponse, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if !s.isSynced() {
+		return nil, errors.Errorf("wallet daemon is not synced yet, %s", s.formatSyncStateReport())
+	}
+
+	limit := request.Limit
+	if limit == 0 {
+		limit = defaultListTransactionsLimit
+	}
+	if limit > maxListTransactionsLimit {
+		return nil, errors.Errorf("limit cannot exceed %d", maxListTransactionsLimit)
+	}
+
+	var cursor *transactionCursor
+	if request.Cursor != "" {
+		var err error
+		cursor, err = parseTransactionCursor(request.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	transactions, err := s.walletTransactions()
+	if err != nil {
+		return nil, err
+	}
+
+	firstIndex := 0
+	if cursor != nil {
+		firstIndex = sort.Search(len(transactions), func(i int) bool {
+			return cursor.less(transactions[i].cursor)
+		})
+	}
+
+	response := &pb.ListTransactionsResponse{NextCursor: request.Cursor}
+	for _, transaction := range transactions[firstIndex:] {
+		if uint32(len(response.Transactions)) == limit {
+			break
+		}
+		response.Transactions = append(response.Transactions, transaction.toProto())
+		response.NextCursor = transaction.cursor.String()
+	}
+
+	return response, nil
+}
+
+// walletTransactions returns the transactions in the history of the wallet, sorted by their cursors
+func (s *server) walletTransactions() ([]*walletTransaction, error) {
+	transactionsByID, err := s.transactionHistory.transactions()
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*walletTransaction, 0, len(transactionsByID))
+	for _, transaction := range transactionsByID {
+		sort.Strings(transaction.addresses)
+		transactions = append(transactions, transaction)
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].cursor.less(transactions[j].cursor)
+	})
+
+	return transactions, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, str := range values {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+)
+
+func TestListTransactions(t *testing.T) {
+	params := &dagconfig.SimnetParams
+	mnemonic, err := libkaspawallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+	keysFile, err := keys.NewFileFromMnemonic(params, mnemonic, "password")
+	if err != nil {
+		t.Fatalf("NewFileFromMnemonic: %+v", err)
+	}
+
+	transactionHistory, err := openTransactionHistory(transactionHistoryPath(filepath.Join(t.TempDir(), "keys.json")))
+	if err != nil {
+		t.Fatalf("openTransactionHistory: %+v", err)
+	}
+	newTransactionIDs := make(map[string]int)
+	transactionHistory.onNewTransaction = func(transactionID string) {
+		newTransactionIDs[transactionID]++
+	}
+	serverInstance := &server{
+		params:               params,
+		keysFile:             keysFile,
+		nextSyncStartIndex:   1,
+		mempoolExcludedUTXOs: map[externalapi.DomainOutpoint]*walletUTXO{},
+		transactionHistory:   transactionHistory,
+	}
+	serverInstance.firstSyncDone.Store(true)
+
+	transactionID := func(transactionIDByte byte) *externalapi.DomainTransactionID {
+		return externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{transactionIDByte})
+	}
+	addUTXO := func(transactionIDByte byte, index uint32, blockDAAScore uint64) {
+		serverInstance.utxosSortedByAmount = append(serverInstance.utxosSortedByAmount, &walletUTXO{
+			Outpoint: &externalapi.DomainOutpoint{
+				TransactionID: *transactionID(transactionIDByte),
+				Index:         index,
+			},
+			UTXOEntry: utxo.NewUTXOEntry(1000, &externalapi.ScriptPublicKey{}, false, blockDAAScore),
+			address:   &walletAddress{index: index, keyChain: libkaspawallet.ExternalKeychain},
+		})
+	}
+	refresh := func(virtualDAAScore uint64) {
+		err := serverInstance.updateTransactionHistory(nil, virtualDAAScore)
+		if err != nil {
+			t.Fatalf("updateTransactionHistory: %+v", err)
+		}
+	}
+	// Transaction 3 pays to two addresses of the wallet
+	addUTXO(3, 0, 10)
+	addUTXO(3, 1, 10)
+	addUTXO(2, 0, 10)
+	addUTXO(1, 0, 20)
+	refresh(20)
+
+	listedTransactionIDs := make(map[string]struct{})
+	var listed []*pb.WalletTransaction
+	list := func(cursor string) string {
+		response, err := serverInstance.ListTransactions(context.Background(), &pb.ListTransactionsRequest{Cursor: cursor, Limit: 2})
+		if err != nil {
+			t.Fatalf("ListTransactions: %+v", err)
+		}
+		for _, transaction := range response.Transactions {
+			if _, ok := listedTransactionIDs[transaction.TransactionId]; ok {
+				t.Fatalf("transaction %s was listed twice", transaction.TransactionId)
+			}
+			listedTransactionIDs[transaction.TransactionId] = struct{}{}
+			listed = append(listed, transaction)
+		}
+		return response.NextCursor
+	}
+
+	cursor := list("")
+	if len(listed) != 2 || listed[0].BlockDaaScore != 10 || listed[1].BlockDaaScore != 10 {
+		t.Fatalf("expected the first page to hold the transactions of DAA score 10, but got %v", listed)
+	}
+	if listed[1].Amount != 2000 || len(listed[1].Addresses) != 2 {
+		t.Fatalf("expected the outputs of a transaction to be grouped, but got %v", listed[1])
+	}
+
+	// A transaction that is accepted in the middle of the paging is listed after the existing ones.
+	// Transaction 5 spends the output of transaction 2, which is still listed, and pays nothing
+	// back to the wallet.
+	addUTXO(4, 0, 30)
+	spendingTransaction := &externalapi.DomainTransaction{
+		Inputs: []*externalapi.DomainTransactionInput{{
+			PreviousOutpoint: externalapi.DomainOutpoint{TransactionID: *transactionID(2), Index: 0},
+		}},
+	}
+	err = serverInstance.updateTransactionHistory([]*externalapi.DomainTransaction{spendingTransaction}, 30)
+	if err != nil {
+		t.Fatalf("updateTransactionHistory: %+v", err)
+	}
+	spentUTXOs := serverInstance.utxosSortedByAmount
+	serverInstance.utxosSortedByAmount = nil
+	for _, walletUTXO := range spentUTXOs {
+		if walletUTXO.Outpoint.TransactionID != *transactionID(2) {
+			serverInstance.utxosSortedByAmount = append(serverInstance.utxosSortedByAmount, walletUTXO)
+		}
+	}
+	refresh(31)
+
+	cursor = list(cursor)
+	cursor = list(cursor)
+	if len(listed) != 5 || listed[2].BlockDaaScore != 20 || listed[3].BlockDaaScore != 30 ||
+		listed[4].BlockDaaScore != 31 {
+
+		t.Fatalf("expected all 5 transactions to be listed in order, but got %v", listed)
+	}
+
+	// Every transaction is notified once, including the spending one that
+	// was recorded both when it was seen and when its spend was accepted
+	if len(newTransactionIDs) != 5 {
+		t.Fatalf("expected 5 new transactions, but got %v", newTransactionIDs)
+	}
+	for transactionID, count := range newTransactionIDs {
+		if _, ok := listedTransactionIDs[transactionID]; !ok || count != 1 {
+			t.Fatalf("expected transaction %s to be notified once, but it was notified %d times",
+				transactionID, count)
+		}
+	}
+
+	nextCursor := list(cursor)
+	if nextCursor != cursor || len(listed) != 5 {
+		t.Fatalf("expected an empty page to keep the cursor")
+	}
+
+	spendingTransactionID := consensushashing.TransactionID(spendingTransaction).String()
+	if listed[4].TransactionId != spendingTransactionID || listed[4].Amount != 0 || listed[4].SpentAmount != 1000 {
+		t.Fatalf("expected the spending transaction to be listed with the amount it spent, but got %v", listed[4])
+	}
+
+	// The history is kept across restarts of the wallet daemon
+	serverInstance.transactionHistory, err = openTransactionHistory(transactionHistory.path)
+	if err != nil {
+		t.Fatalf("openTransactionHistory: %+v", err)
+	}
+	transactions, err := serverInstance.walletTransactions()
+	if err != nil {
+		t.Fatalf("walletTransactions: %+v", err)
+	}
+	if len(transactions) != 5 {
+		t.Fatalf("expected the 5 transactions to be kept across restarts, but got %d", len(transactions))
+	}
+
+	_, err = serverInstance.ListTransactions(context.Background(), &pb.ListTransactionsRequest{Cursor: "malformed"})
+	if err == nil {
+		t.Fatalf("expected listing with a malformed cursor to fail")
+	}
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"sort"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionid"
+	"github.com/pkg/errors"
+)
+
+// LockUnspent locks or unlocks outpoints of the wallet against automatic coin selection,
+// so that they can be reserved for transactions that spend them manually
+func (s *server) LockUnspent(_ context.Context, request *pb.LockUnspentRequest) (*pb.LockUnspentResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if request.Unlock && len(request.Outpoints) == 0 {
+		s.lockedOutpoints = make(map[externalapi.DomainOutpoint]bool)
+		return &pb.LockUnspentResponse{}, s.saveLockedOutpoints()
+	}
+
+	if !request.Unlock && !s.isSynced() {
+		return nil, errors.Errorf("wallet daemon is not synced yet, %s", s.formatSyncStateReport())
+	}
+
+	// All the outpoints are validated before any of them is locked or unlocked
+	outpoints := make([]*externalapi.DomainOutpoint, len(request.Outpoints))
+	for i, protoOutpoint := range request.Outpoints {
+		outpoint, err := outpointFromProto(protoOutpoint)
+		if err != nil {
+			return nil, err
+		}
+		if request.Unlock {
+			if _, ok := s.lockedOutpoints[*outpoint]; !ok {
+				return nil, errors.Errorf("outpoint %s is not locked", outpoint)
+			}
+		} else if !s.isWalletOutpoint(outpoint) {
+			return nil, errors.Errorf("outpoint %s is not an unspent output of the wallet", outpoint)
+		}
+		outpoints[i] = outpoint
+	}
+
+	for _, outpoint := range outpoints {
+		if request.Unlock {
+			delete(s.lockedOutpoints, *outpoint)
+		} else {
+			s.lockedOutpoints[*outpoint] = request.Persist || s.lockedOutpoints[*outpoint]
+		}
+	}
+
+	return &pb.LockUnspentResponse{}, s.saveLockedOutpoints()
+}
+
+// ListLockUnspent lists the outpoints that are locked against automatic coin selection
+func (s *server) ListLockUnspent(_ context.Context, _ *pb.ListLockUnspentRequest) (*pb.ListLockUnspentResponse, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	outpoints := make([]*externalapi.DomainOutpoint, 0, len(s.lockedOutpoints))
+	for outpoint := range s.lockedOutpoints {
+		outpoint := outpoint
+		outpoints = append(outpoints, &outpoint)
+	}
+	sortOutpoints(outpoints)
+
+	response := &pb.ListLockUnspentResponse{Outpoints: make([]*pb.LockedOutpoint, len(outpoints))}
+	for i, outpoint := range outpoints {
+		response.Outpoints[i] = &pb.LockedOutpoint{
+			Outpoint: &pb.Outpoint{
+				TransactionId: outpoint.TransactionID.String(),
+				Index:         outpoint.Index,
+			},
+			IsPersisted: s.lockedOutpoints[*outpoint],
+		}
+	}
+	return response, nil
+}
+
+func (s *server) isOutpointLocked(outpoint *externalapi.DomainOutpoint) bool {
+	_, ok := s.lockedOutpoints[*outpoint]
+	return ok
+}
+
+func (s *server) isWalletOutpoint(outpoint *externalapi.DomainOutpoint) bool {
+	if _, ok := s.mempoolExcludedUTXOs[*outpoint]; ok {
+		return true
+	}
+	for _, utxo := range s.utxosSortedByAmount {
+		if *utxo.Outpoint == *outpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// unlockSpentOutpoints unlocks the locked outpoints that are no longer unspent
+// outputs of the wallet. It must be called only once the wallet is synced, since
+// the UTXOs of the addresses that weren't scanned yet are missing before that.
+func (s *server) unlockSpentOutpoints() error {
+	shouldSave := false
+	for outpoint, isPersisted := range s.lockedOutpoints {
+		outpoint := outpoint
+		if s.isWalletOutpoint(&outpoint) {
+			continue
+		}
+		delete(s.lockedOutpoints, outpoint)
+		shouldSave = shouldSave || isPersisted
+	}
+
+	if !shouldSave {
+		return nil
+	}
+	return s.saveLockedOutpoints()
+}
+
+// saveLockedOutpoints saves the persisted locks to the keys file, if they changed
+func (s *server) saveLockedOutpoints() error {
+	var lockedOutpoints []*keys.LockedOutpoint
+	for outpoint, isPersisted := range s.lockedOutpoints {
+		if !isPersisted {
+			continue
+		}
+		lockedOutpoints = append(lockedOutpoints, &keys.LockedOutpoint{
+			TransactionID: outpoint.TransactionID.String(),
+			Index:         outpoint.Index,
+		})
+	}
+	sort.Slice(lockedOutpoints, func(i, j int) bool {
+		if lockedOutpoints[i].TransactionID != lockedOutpoints[j].TransactionID {
+			return lockedOutpoints[i].TransactionID < lockedOutpoints[j].TransactionID
+		}
+		return lockedOutpoints[i].Index < lockedOutpoints[j].Index
+	})
+
+	if lockedOutpointsEqual(lockedOutpoints, s.keysFile.LockedOutpoints()) {
+		return nil
+	}
+	return s.keysFile.SetLockedOutpoints(lockedOutpoints)
+}
+
+// loadLockedOutpoints replaces the locked outpoints with the ones persisted in the keys file
+func (s *server) loadLockedOutpoints() error {
+	s.lockedOutpoints = make(map[externalapi.DomainOutpoint]bool)
+	for _, lockedOutpoint := range s.keysFile.LockedOutpoints() {
+		transactionID, err := transactionid.FromString(lockedOutpoint.TransactionID)
+		if err != nil {
+			return errors.Wrapf(err, "malformed locked outpoint in the keys file")
+		}
+		s.lockedOutpoints[*externalapi.NewDomainOutpoint(transactionID, lockedOutpoint.Index)] = true
+	}
+	return nil
+}
+
+func lockedOutpointsEqual(a, b []*keys.LockedOutpoint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if *a[i] != *b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func outpointFromProto(protoOutpoint *pb.Outpoint) (*externalapi.DomainOutpoint, error) {
+	if protoOutpoint == nil {
+		return nil, errors.New("outpoint is missing")
+	}
+	transactionID, err := transactionid.FromString(protoOutpoint.TransactionId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed transaction ID %s", protoOutpoint.TransactionId)
+	}
+	return externalapi.NewDomainOutpoint(transactionID, protoOutpoint.Index), nil
+}
+
+func sortOutpoints(outpoints []*externalapi.DomainOutpoint) {
+	sort.Slice(outpoints, func(i, j int) bool {
+		if outpoints[i].TransactionID != outpoints[j].TransactionID {
+			return outpoints[i].TransactionID.Less(&outpoints[j].TransactionID)
+		}
+		return outpoints[i].Index < outpoints[j].Index
+	})
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+)
+
+func TestLockUnspent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestLockUnspent")
+	if err != nil {
+		t.Fatalf("TempDir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	keysFile := &keys.File{Version: keys.LastVersion, MinimumSignatures: 1}
+	err = keysFile.SetPath(&dagconfig.SimnetParams, filepath.Join(dir, "keys.json"), true)
+	if err != nil {
+		t.Fatalf("SetPath: %+v", err)
+	}
+
+	walletOutpoint := func(id byte, index uint32) *externalapi.DomainOutpoint {
+		transactionID := externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{id})
+		return externalapi.NewDomainOutpoint(transactionID, index)
+	}
+	protoOutpoint := func(outpoint *externalapi.DomainOutpoint) *pb.Outpoint {
+		return &pb.Outpoint{TransactionId: outpoint.TransactionID.String(), Index: outpoint.Index}
+	}
+	firstOutpoint := walletOutpoint(1, 0)
+	secondOutpoint := walletOutpoint(2, 1)
+	unknownOutpoint := walletOutpoint(3, 0)
+
+	serverInstance := &server{
+		keysFile:             keysFile,
+		utxosSortedByAmount:  []*walletUTXO{{Outpoint: firstOutpoint}},
+		mempoolExcludedUTXOs: map[externalapi.DomainOutpoint]*walletUTXO{*secondOutpoint: {Outpoint: secondOutpoint}},
+		nextSyncStartIndex:   1,
+	}
+	serverInstance.firstSyncDone.Store(true)
+	err = serverInstance.loadLockedOutpoints()
+	if err != nil {
+		t.Fatalf("loadLockedOutpoints: %+v", err)
+	}
+
+	// Nothing is locked if one of the outpoints doesn't belong to the wallet
+	_, err = serverInstance.LockUnspent(context.Background(), &pb.LockUnspentRequest{
+		Outpoints: []*pb.Outpoint{protoOutpoint(firstOutpoint), protoOutpoint(unknownOutpoint)},
+	})
+	if err == nil {
+		t.Fatalf("expected an error when locking an outpoint that isn't a wallet UTXO")
+	}
+	if serverInstance.isOutpointLocked(firstOutpoint) {
+		t.Fatalf("expected no outpoints to be locked after a failed request")
+	}
+
+	_, err = serverInstance.LockUnspent(context.Background(), &pb.LockUnspentRequest{
+		Outpoints: []*pb.Outpoint{protoOutpoint(firstOutpoint)},
+	})
+	if err != nil {
+		t.Fatalf("LockUnspent: %+v", err)
+	}
+	_, err = serverInstance.LockUnspent(context.Background(), &pb.LockUnspentRequest{
+		Outpoints: []*pb.Outpoint{protoOutpoint(secondOutpoint)},
+		Persist:   true,
+	})
+	if err != nil {
+		t.Fatalf("LockUnspent: %+v", err)
+	}
+
+	response, err := serverInstance.ListLockUnspent(context.Background(), &pb.ListLockUnspentRequest{})
+	if err != nil {
+		t.Fatalf("ListLockUnspent: %+v", err)
+	}
+	if len(response.Outpoints) != 2 ||
+		response.Outpoints[0].Outpoint.TransactionId != firstOutpoint.TransactionID.String() ||
+		response.Outpoints[0].IsPersisted ||
+		response.Outpoints[1].Outpoint.TransactionId != secondOutpoint.TransactionID.String() ||
+		!response.Outpoints[1].IsPersisted {
+		t.Fatalf("unexpected locked outpoints %+v", response.Outpoints)
+	}
+
+	// Only the persisted lock survives a restart
+	readKeysFile, err := keys.ReadKeysFile(&dagconfig.SimnetParams, keysFile.Path())
+	if err != nil {
+		t.Fatalf("ReadKeysFile: %+v", err)
+	}
+	restartedServer := &server{keysFile: readKeysFile}
+	err = restartedServer.loadLockedOutpoints()
+	if err != nil {
+		t.Fatalf("loadLockedOutpoints: %+v", err)
+	}
+	if restartedServer.isOutpointLocked(firstOutpoint) || !restartedServer.isOutpointLocked(secondOutpoint) {
+		t.Fatalf("expected only the persisted outpoint to be locked after a restart")
+	}
+
+	// Locks on outpoints that were spent are released
+	serverInstance.mempoolExcludedUTXOs = map[externalapi.DomainOutpoint]*walletUTXO{}
+	err = serverInstance.unlockSpentOutpoints()
+	if err != nil {
+		t.Fatalf("unlockSpentOutpoints: %+v", err)
+	}
+	if !serverInstance.isOutpointLocked(firstOutpoint) || serverInstance.isOutpointLocked(secondOutpoint) {
+		t.Fatalf("expected only the lock of the spent outpoint to be released")
+	}
+	if len(keysFile.LockedOutpoints()) != 0 {
+		t.Fatalf("expected the released lock to be removed from the keys file")
+	}
+
+	_, err = serverInstance.LockUnspent(context.Background(), &pb.LockUnspentRequest{
+		Unlock:    true,
+		Outpoints: []*pb.Outpoint{protoOutpoint(secondOutpoint)},
+	})
+	if err == nil {
+		t.Fatalf("expected an error when unlocking an outpoint that isn't locked")
+	}
+
+	_, err = serverInstance.LockUnspent(context.Background(), &pb.LockUnspentRequest{Unlock: true})
+	if err != nil {
+		t.Fatalf("LockUnspent: %+v", err)
+	}
+	if serverInstance.isOutpointLocked(firstOutpoint) {
+		t.Fatalf("expected all the outpoints to be unlocked")
+	}
+}
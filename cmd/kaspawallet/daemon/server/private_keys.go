@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+)
+
+func (s *server) ImportPrivateKey(_ context.Context, request *pb.ImportPrivateKeyRequest) (*pb.ImportPrivateKeyResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	privateKey, err := libkaspawallet.DecodeWIF(s.params, request.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := libkaspawallet.PublicKeyFromPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := util.NewAddressPublicKey(publicKey, s.params.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key is re-encoded so that the saved key is always in the compressed form
+	wif, err := libkaspawallet.EncodeWIF(s.params, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.keysFile.AddImportedPrivateKey(address.String(), wif, request.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Imported the private key of %s", address)
+	return &pb.ImportPrivateKeyResponse{Address: address.String()}, nil
+}
+
+func (s *server) DumpPrivateKey(_ context.Context, request *pb.DumpPrivateKeyRequest) (*pb.DumpPrivateKeyResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, err := util.DecodeAddress(request.Address, s.params.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	wif, found, err := s.keysFile.ImportedPrivateKey(request.Address, request.Password)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return &pb.DumpPrivateKeyResponse{PrivateKey: wif}, nil
+	}
+
+	if s.isMultisig() {
+		return nil, errors.New("multisig addresses don't have a single private key, so they can't be dumped")
+	}
+
+	walletAddr, err := s.walletAddressByString(request.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	mnemonics, err := s.keysFile.DecryptMnemonics(request.Password)
+	if err != nil {
+		return nil, err
+	}
+	if len(mnemonics) == 0 {
+		return nil, errors.New("the wallet doesn't hold any private keys")
+	}
+
+	privateKey, err := libkaspawallet.PrivateKeyFromMnemonic(s.params, mnemonics[0], s.walletAddressPath(walletAddr))
+	if err != nil {
+		return nil, err
+	}
+
+	wif, err = libkaspawallet.EncodeWIF(s.params, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DumpPrivateKeyResponse{PrivateKey: wif}, nil
+}
+
+// walletAddressByString returns the derivation data of the given address, which is either
+// an address that holds funds or an address that was handed out by the wallet
+func (s *server) walletAddressByString(address string) (*walletAddress, error) {
+	if walletAddr, ok := s.addressSet[address]; ok {
+		return walletAddr, nil
+	}
+
+	lastUsedIndexes := map[uint8]uint32{
+		libkaspawallet.ExternalKeychain: s.keysFile.LastUsedExternalIndex(),
+		libkaspawallet.InternalKeychain: s.keysFile.LastUsedInternalIndex(),
+	}
+	for _, keyChain := range keyChains {
+		for index := uint32(0); index <= lastUsedIndexes[keyChain]; index++ {
+			walletAddr := &walletAddress{
+				index:         index,
+				cosignerIndex: s.keysFile.CosignerIndex,
+				keyChain:      keyChain,
+			}
+			addressString, err := s.walletAddressString(walletAddr)
+			if err != nil {
+				return nil, err
+			}
+			if addressString == address {
+				return walletAddr, nil
+			}
+		}
+	}
+
+	return nil, errors.Errorf("address %s doesn't belong to the wallet", address)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
 	"github.com/kaspanet/kaspad/domain/dagconfig"
 	"github.com/kaspanet/kaspad/infrastructure/network/rpcclient"
+	"github.com/kaspanet/kaspad/infrastructure/os/notifycmd"
 	"github.com/kaspanet/kaspad/infrastructure/os/signal"
 	"github.com/kaspanet/kaspad/util/panics"
 	"github.com/pkg/errors"
@@ -38,15 +39,22 @@ type server struct {
 	lock                            sync.RWMutex
 	utxosSortedByAmount             []*walletUTXO
 	mempoolExcludedUTXOs            map[externalapi.DomainOutpoint]*walletUTXO
+	mempoolReceivedAmounts          map[*walletAddress]uint64 // The amounts that mempool transactions pay to each address
 	nextSyncStartIndex              uint32
 	keysFile                        *keys.File
-	shutdown                        chan struct{}
+	shutdown                        chan struct{} // Shared by all the wallets of the daemon
+	shutdownOnce                    *sync.Once
 	forceSyncChan                   chan struct{}
 	startTimeOfLastCompletedRefresh time.Time
 	addressSet                      walletAddressSet
+	transactionHistory              *transactionHistory
 	txMassCalculator                *txmass.Calculator
 	usedOutpoints                   map[externalapi.DomainOutpoint]time.Time
+	lockedOutpoints                 map[externalapi.DomainOutpoint]bool // Maps to whether the lock is persisted in the keys file
 	firstSyncDone                   atomic.Bool
+	shouldResetAddresses            bool   // Set when the wallet is restored from a backup
+	gapLimit                        uint32 // The number of consecutive unused addresses that are scanned on every key chain
+	consolidationConfig             *ConsolidationConfig
 
 	isLogFinalProgressLineShown bool
 	maxUsedAddressesForLog      uint32
@@ -57,8 +65,19 @@ type server struct {
 // Currently, set to 100MB
 const MaxDaemonSendMsgSize = 100_000_000
 
+// WalletConfig is a wallet that's loaded by the daemon
+type WalletConfig struct {
+	// Name is the name clients route their requests to the wallet with. The wallet
+	// with the empty name is the default one, which gets the requests without a name.
+	Name                string
+	KeysFilePath        string
+	ConsolidationConfig *ConsolidationConfig
+}
+
 // Start starts the kaspawalletd server
-func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath string, profile string, timeout uint32) error {
+func Start(params *dagconfig.Params, listen, rpcServer string, wallets []*WalletConfig, profile string, timeout uint32,
+	gapLimit uint32, walletNotify string, notifyMaxProcesses int) error {
+
 	initLog(defaultLogFile, defaultErrLogFile)
 
 	defer panics.HandlePanic(log, "MAIN", nil)
@@ -75,30 +94,122 @@ func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath stri
 	}
 	log.Infof("Listening to TCP on %s", listen)
 
+	// The notifier is shared by all the wallets, so that its concurrency limit applies to all of them
+	walletNotifier := notifycmd.New("wallet-notify", walletNotify, notifyMaxProcesses, log)
+	if walletNotifier != nil {
+		walletNotifier.Start()
+		defer walletNotifier.Stop()
+	}
+
+	shutdown := make(chan struct{})
+	shutdownOnce := &sync.Once{}
+	router := &walletRouter{wallets: make(map[string]*server, len(wallets))}
+	for _, wallet := range wallets {
+		if _, ok := router.wallets[wallet.Name]; ok {
+			return errors.Errorf("Wallet %s is loaded more than once", wallet.Name)
+		}
+		serverInstance, err := newServer(params, rpcServer, timeout, wallet, gapLimit, walletNotifier,
+			shutdown, shutdownOnce)
+		if err != nil {
+			return err
+		}
+		router.wallets[wallet.Name] = serverInstance
+	}
+
+	for name, serverInstance := range router.wallets {
+		serverInstance := serverInstance
+		walletDescription := "the wallet"
+		if name != "" {
+			walletDescription = fmt.Sprintf("wallet %s", name)
+		}
+
+		if serverInstance.consolidationConfig.MaxUTXOs != 0 {
+			log.Infof("Consolidating the UTXOs of %s while there are more than %d of them and the fee rate is at "+
+				"most %f", walletDescription, serverInstance.consolidationConfig.MaxUTXOs,
+				serverInstance.consolidationConfig.MaxFeeRate)
+			spawn("serverInstance.consolidationLoop", serverInstance.consolidationLoop)
+		}
+
+		log.Infof("Read, syncing %s...", walletDescription)
+		spawn("serverInstance.syncLoop", func() {
+			err := serverInstance.syncLoop()
+			if err != nil {
+				printErrorAndExit(errors.Wrapf(err, "error syncing %s", walletDescription))
+			}
+		})
+	}
+
+	grpcServer := grpc.NewServer(grpc.MaxSendMsgSize(MaxDaemonSendMsgSize))
+	router.register(grpcServer)
+
+	spawn("grpcServer.Serve", func() {
+		err := grpcServer.Serve(listener)
+		if err != nil {
+			printErrorAndExit(errors.Wrap(err, "Error serving gRPC"))
+		}
+	})
+
+	select {
+	case <-shutdown:
+	case <-interrupt:
+		const stopTimeout = 2 * time.Second
+
+		stopChan := make(chan interface{})
+		spawn("gRPCServer.Stop", func() {
+			grpcServer.GracefulStop()
+			close(stopChan)
+		})
+
+		select {
+		case <-stopChan:
+		case <-time.After(stopTimeout):
+			log.Warnf("Could not gracefully stop: timed out after %s", stopTimeout)
+			grpcServer.Stop()
+		}
+	}
+
+	return nil
+}
+
+// newServer loads the given wallet. Every wallet has its own connections to the node,
+// since the background connection is used by the sync loop of the wallet.
+func newServer(params *dagconfig.Params, rpcServer string, timeout uint32, wallet *WalletConfig, gapLimit uint32,
+	walletNotifier *notifycmd.Notifier, shutdown chan struct{}, shutdownOnce *sync.Once) (*server, error) {
+
 	log.Infof("Connecting to a node at %s...", rpcServer)
 	rpcClient, err := connectToRPC(params, rpcServer, timeout)
 	if err != nil {
-		return (errors.Wrapf(err, "Error connecting to RPC server %s", rpcServer))
+		return nil, (errors.Wrapf(err, "Error connecting to RPC server %s", rpcServer))
 	}
 	backgroundRPCClient, err := connectToRPC(params, rpcServer, timeout)
 	if err != nil {
-		return (errors.Wrapf(err, "Error making a second connection to RPC server %s", rpcServer))
+		return nil, (errors.Wrapf(err, "Error making a second connection to RPC server %s", rpcServer))
 	}
 
-	log.Infof("Connected, reading keys file %s...", keysFilePath)
-	keysFile, err := keys.ReadKeysFile(params, keysFilePath)
+	log.Infof("Connected, reading keys file %s...", wallet.KeysFilePath)
+	keysFile, err := keys.ReadKeysFile(params, wallet.KeysFilePath)
 	if err != nil {
-		return (errors.Wrapf(err, "Error reading keys file %s", keysFilePath))
+		return nil, (errors.Wrapf(err, "Error reading keys file %s", wallet.KeysFilePath))
 	}
 
 	err = keysFile.TryLock()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	transactionHistory, err := openTransactionHistory(transactionHistoryPath(keysFile.Path()))
+	if err != nil {
+		return nil, err
+	}
+	if walletNotifier != nil {
+		transactionHistory.onNewTransaction = func(transactionID string) {
+			walletNotifier.Notify(map[rune]string{'s': transactionID, 'w': wallet.Name})
+		}
 	}
 
 	dagInfo, err := rpcClient.GetBlockDAGInfo()
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
 	coinbaseMaturity := params.BlockCoinbaseMaturity
@@ -106,6 +217,11 @@ func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath stri
 		coinbaseMaturity = 1000
 	}
 
+	consolidationConfig := wallet.ConsolidationConfig
+	if consolidationConfig == nil {
+		consolidationConfig = &ConsolidationConfig{}
+	}
+
 	serverInstance := &server{
 		rpcClient:                   rpcClient,
 		backgroundRPCClient:         backgroundRPCClient,
@@ -113,56 +229,38 @@ func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath stri
 		coinbaseMaturity:            coinbaseMaturity,
 		utxosSortedByAmount:         []*walletUTXO{},
 		mempoolExcludedUTXOs:        map[externalapi.DomainOutpoint]*walletUTXO{},
+		mempoolReceivedAmounts:      map[*walletAddress]uint64{},
 		nextSyncStartIndex:          0,
 		keysFile:                    keysFile,
-		shutdown:                    make(chan struct{}),
+		shutdown:                    shutdown,
+		shutdownOnce:                shutdownOnce,
 		forceSyncChan:               make(chan struct{}),
 		addressSet:                  make(walletAddressSet),
+		transactionHistory:          transactionHistory,
 		txMassCalculator:            txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp),
 		usedOutpoints:               map[externalapi.DomainOutpoint]time.Time{},
+		gapLimit:                    gapLimit,
+		consolidationConfig:         consolidationConfig,
 		isLogFinalProgressLineShown: false,
 		maxUsedAddressesForLog:      0,
 		maxProcessedAddressesForLog: 0,
 	}
 
-	log.Infof("Read, syncing the wallet...")
-	spawn("serverInstance.syncLoop", func() {
-		err := serverInstance.syncLoop()
-		if err != nil {
-			printErrorAndExit(errors.Wrap(err, "error syncing the wallet"))
-		}
-	})
-
-	grpcServer := grpc.NewServer(grpc.MaxSendMsgSize(MaxDaemonSendMsgSize))
-	pb.RegisterKaspawalletdServer(grpcServer, serverInstance)
+	err = serverInstance.loadLockedOutpoints()
+	if err != nil {
+		return nil, err
+	}
 
-	spawn("grpcServer.Serve", func() {
-		err := grpcServer.Serve(listener)
+	if consolidationConfig.MaxUTXOs != 0 {
+		// The password is verified in advance, since consolidation transactions are signed in the background
+		_, err := keysFile.DecryptMnemonics(consolidationConfig.Password)
 		if err != nil {
-			printErrorAndExit(errors.Wrap(err, "Error serving gRPC"))
-		}
-	})
-
-	select {
-	case <-serverInstance.shutdown:
-	case <-interrupt:
-		const stopTimeout = 2 * time.Second
-
-		stopChan := make(chan interface{})
-		spawn("gRPCServer.Stop", func() {
-			grpcServer.GracefulStop()
-			close(stopChan)
-		})
-
-		select {
-		case <-stopChan:
-		case <-time.After(stopTimeout):
-			log.Warnf("Could not gracefully stop: timed out after %s", stopTimeout)
-			grpcServer.Stop()
+			return nil, errors.Wrapf(err, "Error decrypting the wallet %s for the UTXO consolidation",
+				wallet.KeysFilePath)
 		}
 	}
 
-	return nil
+	return serverInstance, nil
 }
 
 func printErrorAndExit(err error) {
@@ -8,6 +8,9 @@ import (
 func (s *server) Shutdown(ctx context.Context, request *pb.ShutdownRequest) (*pb.ShutdownResponse, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
-	close(s.shutdown)
+	// Any of the wallets may shut the daemon down, and may do so more than once
+	s.shutdownOnce.Do(func() {
+		close(s.shutdown)
+	})
 	return &pb.ShutdownResponse{}, nil
 }
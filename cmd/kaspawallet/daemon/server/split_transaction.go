@@ -21,6 +21,7 @@ import (
 // into a change address.
 // An additional `mergeTransaction` is generated - which merges the outputs of the above splits into a single output
 // paying to the original transaction's payee.
+// All of the generated transactions have the lock time of the original transaction.
 func (s *server) maybeAutoCompoundTransaction(transaction *serialization.PartiallySignedTransaction, toAddress util.Address,
 	changeAddress util.Address, changeWalletAddress *walletAddress, feeRate float64, maxFee uint64) ([][]byte, error) {
 
@@ -101,8 +102,8 @@ func (s *server) mergeTransaction(
 		})
 	}
 
-	return libkaspawallet.CreateUnsignedTransaction(s.keysFile.ExtendedPublicKeys,
-		s.keysFile.MinimumSignatures, payments, utxos)
+	return libkaspawallet.CreateUnsignedTransactionWithLockTime(s.keysFile.ExtendedPublicKeys,
+		s.keysFile.MinimumSignatures, payments, utxos, originalTransaction.Tx.LockTime)
 }
 
 func (s *server) transactionFeeRate(psTx *serialization.PartiallySignedTransaction) (float64, error) {
@@ -261,12 +262,12 @@ func (s *server) createSplitTransaction(transaction *serialization.PartiallySign
 		totalSompi -= fee
 	}
 
-	return libkaspawallet.CreateUnsignedTransaction(s.keysFile.ExtendedPublicKeys,
+	return libkaspawallet.CreateUnsignedTransactionWithLockTime(s.keysFile.ExtendedPublicKeys,
 		s.keysFile.MinimumSignatures,
 		[]*libkaspawallet.Payment{{
 			Address: changeAddress,
 			Amount:  totalSompi,
-		}}, selectedUTXOs)
+		}}, selectedUTXOs, transaction.Tx.LockTime)
 }
 
 func (s *server) estimateMassAfterSignatures(transaction *serialization.PartiallySignedTransaction) (uint64, error) {
@@ -338,7 +339,7 @@ func (s *server) moreUTXOsForMergeTransaction(alreadySelectedUTXOs []*libkaspawa
 		if _, ok := alreadySelectedUTXOsMap[*utxo.Outpoint]; ok {
 			continue
 		}
-		if !s.isUTXOSpendable(utxo, dagInfo.VirtualDAAScore) {
+		if !s.isUTXOSpendable(utxo, dagInfo.VirtualDAAScore) || s.isOutpointLocked(utxo.Outpoint) {
 			continue
 		}
 		additionalUTXOs = append(additionalUTXOs, &libkaspawallet.UTXO{
@@ -5,8 +5,11 @@ import (
 	"sort"
 	"time"
 
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
 
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/pkg/errors"
@@ -28,7 +31,12 @@ func (s *server) syncLoop() error {
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
-	err := s.collectRecentAddresses()
+	err := s.restoreUsedAddressesWithLock(s.keysFile.UsedAddresses())
+	if err != nil {
+		return err
+	}
+
+	err = s.collectRecentAddressesFromSyncCheckpoint()
 	if err != nil {
 		return err
 	}
@@ -55,6 +63,8 @@ func (s *server) syncLoop() error {
 }
 
 func (s *server) sync() error {
+	s.resetAddressesIfRequired()
+
 	err := s.collectFarAddresses()
 	if err != nil {
 		return err
@@ -68,20 +78,39 @@ func (s *server) sync() error {
 	return s.refreshUTXOs()
 }
 
+// resetAddressesIfRequired forgets the addresses and UTXOs of the wallet after it's
+// restored from a backup. The address set is only written by the sync loop, so it's
+// reset here rather than in RestoreWallet.
+func (s *server) resetAddressesIfRequired() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.shouldResetAddresses {
+		return
+	}
+
+	s.addressSet = make(walletAddressSet)
+	s.utxosSortedByAmount = []*walletUTXO{}
+	s.mempoolExcludedUTXOs = map[externalapi.DomainOutpoint]*walletUTXO{}
+	s.mempoolReceivedAmounts = map[*walletAddress]uint64{}
+	s.nextSyncStartIndex = 0
+	s.shouldResetAddresses = false
+}
+
 const (
 	numIndexesToQueryForFarAddresses    = 100
 	numIndexesToQueryForRecentAddresses = 1000
 )
 
-// addressesToQuery scans the addresses in the given range. Because
-// each cosigner in a multisig has its own unique path for generating
-// addresses it goes over all the cosigners and add their addresses
+// addressesToQuery scans the addresses of the given key chains in the given
+// range. Because each cosigner in a multisig has its own unique path for
+// generating addresses it goes over all the cosigners and add their addresses
 // for each key chain.
-func (s *server) addressesToQuery(start, end uint32) (walletAddressSet, error) {
+func (s *server) addressesToQuery(start, end uint32, keyChainsToQuery []uint8) (walletAddressSet, error) {
 	addresses := make(walletAddressSet)
 	for index := start; index < end; index++ {
 		for cosignerIndex := uint32(0); cosignerIndex < uint32(len(s.keysFile.ExtendedPublicKeys)); cosignerIndex++ {
-			for _, keychain := range keyChains {
+			for _, keychain := range keyChainsToQuery {
 				address := &walletAddress{
 					index:         index,
 					cosignerIndex: cosignerIndex,
@@ -104,7 +133,7 @@ func (s *server) addressesToQuery(start, end uint32) (walletAddressSet, error) {
 func (s *server) collectFarAddresses() error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
-	err := s.collectAddresses(s.nextSyncStartIndex, s.nextSyncStartIndex+numIndexesToQueryForFarAddresses)
+	err := s.collectAddresses(s.nextSyncStartIndex, s.nextSyncStartIndex+numIndexesToQueryForFarAddresses, keyChains)
 	if err != nil {
 		return err
 	}
@@ -129,20 +158,82 @@ func (s *server) maxUsedIndex() uint32 {
 	return maxUsedIndex
 }
 
-// collectRecentAddresses collects addresses from used addresses until
-// the address with the index of the last used address + numIndexesToQueryForRecentAddresses.
-// collectRecentAddresses scans addresses in batches of numIndexesToQueryForRecentAddresses,
-// and releases the lock between scans.
+// keyChainsWithinGapLimit returns the key chains whose address with the given index is
+// within the gap limit, that is, less than gapLimit addresses past the last used address
+// of the key chain. Since the last used indexes grow as used addresses are found, the
+// scanned range is extended until gapLimit consecutive unused addresses are found.
+func (s *server) keyChainsWithinGapLimit(index uint32) []uint8 {
+	lastUsedIndexes := map[uint8]uint32{
+		libkaspawallet.ExternalKeychain: s.keysFile.LastUsedExternalIndex(),
+		libkaspawallet.InternalKeychain: s.keysFile.LastUsedInternalIndex(),
+	}
+
+	var keyChainsWithinGapLimit []uint8
+	for _, keyChain := range keyChains {
+		if uint64(index) <= uint64(lastUsedIndexes[keyChain])+uint64(s.gapLimit) {
+			keyChainsWithinGapLimit = append(keyChainsWithinGapLimit, keyChain)
+		}
+	}
+	return keyChainsWithinGapLimit
+}
+
+func (s *server) keyChainsWithinGapLimitWithLock(index uint32) []uint8 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.keyChainsWithinGapLimit(index)
+}
+
+// collectRecentAddresses collects the addresses of every key chain from its first address
+// until gapLimit consecutive unused addresses are found, BIP44-style. It scans addresses
+// in batches of numIndexesToQueryForRecentAddresses, and releases the lock between scans.
 func (s *server) collectRecentAddresses() error {
+	return s.collectRecentAddressesFrom(0, nil)
+}
+
+// collectRecentAddressesFromSyncCheckpoint is the same as collectRecentAddresses, but it
+// resumes the scan from the sync checkpoint left by a previous interrupted call, and saves
+// a checkpoint after every batch of numIndexesToQueryForRecentAddresses addresses.
+// It's used for the first sync of the wallet, which is long when the wallet has many used addresses.
+func (s *server) collectRecentAddressesFromSyncCheckpoint() error {
 	index := uint32(0)
-	maxUsedIndex := uint32(0)
-	for ; index < maxUsedIndex+numIndexesToQueryForRecentAddresses; index += numIndexesToQueryForRecentAddresses {
-		err := s.collectAddressesWithLock(index, index+numIndexesToQueryForRecentAddresses)
+	checkpoint := s.keysFile.SyncCheckpoint()
+	if checkpoint != nil {
+		log.Infof("Resuming the scan of the recent addresses from index %d", checkpoint.NextIndex)
+		err := s.restoreUsedAddressesWithLock(checkpoint.UsedAddresses)
+		if err != nil {
+			return err
+		}
+		index = checkpoint.NextIndex
+	}
+
+	err := s.collectRecentAddressesFrom(index, s.saveSyncCheckpoint)
+	if err != nil {
+		return err
+	}
+
+	return s.keysFile.SetSyncCheckpoint(nil)
+}
+
+func (s *server) collectRecentAddressesFrom(index uint32, onBatchCollected func(nextIndex uint32) error) error {
+	for ; ; index += numIndexesToQueryForRecentAddresses {
+		keyChainsToQuery := s.keyChainsWithinGapLimitWithLock(index)
+		if len(keyChainsToQuery) == 0 {
+			break
+		}
 
+		err := s.collectAddressesWithLock(index, index+numIndexesToQueryForRecentAddresses, keyChainsToQuery)
 		if err != nil {
 			return err
 		}
-		maxUsedIndex = s.maxUsedIndexWithLock()
+		maxUsedIndex := s.maxUsedIndexWithLock()
+
+		if onBatchCollected != nil {
+			err := onBatchCollected(index + numIndexesToQueryForRecentAddresses)
+			if err != nil {
+				return err
+			}
+		}
 
 		s.updateSyncingProgressLog(index, maxUsedIndex)
 	}
@@ -156,25 +247,64 @@ func (s *server) collectRecentAddresses() error {
 	return nil
 }
 
-func (s *server) collectAddressesWithLock(start, end uint32) error {
+func (s *server) saveSyncCheckpoint(nextIndex uint32) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	usedAddresses := make([]*keys.UsedAddress, 0, len(s.addressSet))
+	for _, address := range s.addressSet {
+		usedAddresses = append(usedAddresses, &keys.UsedAddress{
+			Index:         address.index,
+			CosignerIndex: address.cosignerIndex,
+			KeyChain:      address.keyChain,
+		})
+	}
+
+	return s.keysFile.SetSyncCheckpoint(&keys.SyncCheckpoint{
+		NextIndex:     nextIndex,
+		UsedAddresses: usedAddresses,
+	})
+}
+
+func (s *server) restoreUsedAddressesWithLock(usedAddresses []*keys.UsedAddress) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, usedAddress := range usedAddresses {
+		address := &walletAddress{
+			index:         usedAddress.Index,
+			cosignerIndex: usedAddress.CosignerIndex,
+			keyChain:      usedAddress.KeyChain,
+		}
+		addressString, err := s.walletAddressString(address)
+		if err != nil {
+			return err
+		}
+		s.addressSet[addressString] = address
+	}
+
+	return nil
+}
+
+func (s *server) collectAddressesWithLock(start, end uint32, keyChainsToQuery []uint8) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	return s.collectAddresses(start, end)
+	return s.collectAddresses(start, end, keyChainsToQuery)
 }
 
-func (s *server) collectAddresses(start, end uint32) error {
-	addressSet, err := s.addressesToQuery(start, end)
+func (s *server) collectAddresses(start, end uint32, keyChainsToQuery []uint8) error {
+	addressSet, err := s.addressesToQuery(start, end, keyChainsToQuery)
 	if err != nil {
 		return err
 	}
 
-	getBalancesByAddressesResponse, err := s.backgroundRPCClient.GetBalancesByAddresses(addressSet.strings())
+	usedAddresses, err := s.usedAddresses(addressSet)
 	if err != nil {
 		return err
 	}
 
-	err = s.updateAddressesAndLastUsedIndexes(addressSet, getBalancesByAddressesResponse)
+	err = s.updateAddressesAndLastUsedIndexes(addressSet, usedAddresses)
 	if err != nil {
 		return err
 	}
@@ -182,23 +312,61 @@ func (s *server) collectAddresses(start, end uint32) error {
 	return nil
 }
 
-func (s *server) updateAddressesAndLastUsedIndexes(requestedAddressSet walletAddressSet,
-	getBalancesByAddressesResponse *appmessage.GetBalancesByAddressesResponseMessage) error {
+// usedAddresses returns the addresses out of the given ones that ever received funds:
+// the ones that hold funds, the ones that receive funds in a transaction that is still
+// in the mempool, so that deposits are discovered before they're accepted, and the ones
+// that were already found to be used, even if they were emptied since
+func (s *server) usedAddresses(requestedAddressSet walletAddressSet) ([]string, error) {
+	addresses := requestedAddressSet.strings()
+	getBalancesByAddressesResponse, err := s.backgroundRPCClient.GetBalancesByAddresses(addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	getMempoolEntriesByAddressesResponse, err := s.backgroundRPCClient.GetMempoolEntriesByAddresses(addresses, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var usedAddresses []string
+	for address := range requestedAddressSet {
+		if _, ok := s.addressSet[address]; ok {
+			usedAddresses = append(usedAddresses, address)
+		}
+	}
+	for _, entry := range getBalancesByAddressesResponse.Entries {
+		if entry.Balance > 0 {
+			usedAddresses = append(usedAddresses, entry.Address)
+		}
+	}
+	for _, entry := range getMempoolEntriesByAddressesResponse.Entries {
+		if len(entry.Receiving) > 0 {
+			usedAddresses = append(usedAddresses, entry.Address)
+		}
+	}
+	return usedAddresses, nil
+}
+
+func (s *server) updateAddressesAndLastUsedIndexes(requestedAddressSet walletAddressSet, usedAddresses []string) error {
 	lastUsedExternalIndex := s.keysFile.LastUsedExternalIndex()
 	lastUsedInternalIndex := s.keysFile.LastUsedInternalIndex()
 
-	for _, entry := range getBalancesByAddressesResponse.Entries {
-		walletAddress, ok := requestedAddressSet[entry.Address]
+	var newUsedAddresses []*keys.UsedAddress
+	for _, address := range usedAddresses {
+		walletAddress, ok := requestedAddressSet[address]
 		if !ok {
-			return errors.Errorf("Got result from address %s even though it wasn't requested", entry.Address)
+			return errors.Errorf("Got result from address %s even though it wasn't requested", address)
 		}
 
-		if entry.Balance == 0 {
-			continue
+		if _, ok := s.addressSet[address]; !ok {
+			s.addressSet[address] = walletAddress
+			newUsedAddresses = append(newUsedAddresses, &keys.UsedAddress{
+				Index:         walletAddress.index,
+				CosignerIndex: walletAddress.cosignerIndex,
+				KeyChain:      walletAddress.keyChain,
+			})
 		}
 
-		s.addressSet[entry.Address] = walletAddress
-
 		if walletAddress.keyChain == libkaspawallet.ExternalKeychain {
 			if walletAddress.index > lastUsedExternalIndex {
 				lastUsedExternalIndex = walletAddress.index
@@ -211,7 +379,12 @@ func (s *server) updateAddressesAndLastUsedIndexes(requestedAddressSet walletAdd
 		}
 	}
 
-	err := s.keysFile.SetLastUsedExternalIndex(lastUsedExternalIndex)
+	err := s.keysFile.AddUsedAddresses(newUsedAddresses)
+	if err != nil {
+		return err
+	}
+
+	err = s.keysFile.SetLastUsedExternalIndex(lastUsedExternalIndex)
 	if err != nil {
 		return err
 	}
@@ -229,18 +402,62 @@ func (s *server) usedOutpointHasExpired(outpointBroadcastTime time.Time) bool {
 }
 
 // updateUTXOSet clears the current UTXO set, and re-fills it with the given entries
-func (s *server) updateUTXOSet(entries []*appmessage.UTXOsByAddressesEntry, mempoolEntries []*appmessage.MempoolEntryByAddress, refreshStart time.Time) error {
+// mempoolReceivedAmountsByAddress sums the outputs of mempool transactions that pay to
+// each of the addresses of the wallet
+func (s *server) mempoolReceivedAmountsByAddress(mempoolEntries []*appmessage.MempoolEntryByAddress) (
+	map[*walletAddress]uint64, error) {
+
+	mempoolReceivedAmounts := make(map[*walletAddress]uint64)
+	for _, entriesByAddress := range mempoolEntries {
+		address, ok := s.addressSet[entriesByAddress.Address]
+		if !ok {
+			return nil, errors.Errorf("Got mempool entries of address %s even though it wasn't requested",
+				entriesByAddress.Address)
+		}
+		for _, entry := range entriesByAddress.Receiving {
+			transaction, err := appmessage.RPCTransactionToDomainTransaction(entry.Transaction)
+			if err != nil {
+				return nil, err
+			}
+			for _, output := range transaction.Outputs {
+				_, outputAddress, err := txscript.ExtractScriptPubKeyAddress(output.ScriptPublicKey, s.params)
+				if err != nil {
+					return nil, err
+				}
+				if outputAddress != nil && outputAddress.String() == entriesByAddress.Address {
+					mempoolReceivedAmounts[address] += output.Value
+				}
+			}
+		}
+	}
+	return mempoolReceivedAmounts, nil
+}
+
+func (s *server) updateUTXOSet(entries []*appmessage.UTXOsByAddressesEntry, mempoolEntries []*appmessage.MempoolEntryByAddress,
+	virtualDAAScore uint64, refreshStart time.Time) error {
+
 	utxos := make([]*walletUTXO, 0, len(entries))
 
 	exclude := make(map[appmessage.RPCOutpoint]struct{})
+	var spendingTransactions []*externalapi.DomainTransaction
 	for _, entriesByAddress := range mempoolEntries {
 		for _, entry := range entriesByAddress.Sending {
 			for _, input := range entry.Transaction.Inputs {
 				exclude[*input.PreviousOutpoint] = struct{}{}
 			}
+			spendingTransaction, err := appmessage.RPCTransactionToDomainTransaction(entry.Transaction)
+			if err != nil {
+				return err
+			}
+			spendingTransactions = append(spendingTransactions, spendingTransaction)
 		}
 	}
 
+	mempoolReceivedAmounts, err := s.mempoolReceivedAmountsByAddress(mempoolEntries)
+	if err != nil {
+		return err
+	}
+
 	mempoolExcludedUTXOs := make(map[externalapi.DomainOutpoint]*walletUTXO)
 	for _, entry := range entries {
 		outpoint, err := appmessage.RPCOutpointToDomainOutpoint(entry.Outpoint)
@@ -282,6 +499,7 @@ func (s *server) updateUTXOSet(entries []*appmessage.UTXOsByAddressesEntry, memp
 	s.startTimeOfLastCompletedRefresh = refreshStart
 	s.utxosSortedByAmount = utxos
 	s.mempoolExcludedUTXOs = mempoolExcludedUTXOs
+	s.mempoolReceivedAmounts = mempoolReceivedAmounts
 
 	// Cleanup expired used outpoints to avoid a memory leak
 	for outpoint, broadcastTime := range s.usedOutpoints {
@@ -289,9 +507,38 @@ func (s *server) updateUTXOSet(entries []*appmessage.UTXOsByAddressesEntry, memp
 			delete(s.usedOutpoints, outpoint)
 		}
 	}
+
+	// Locks on outpoints that were spent are released, so that they don't pile up
+	if s.isSynced() {
+		err = s.unlockSpentOutpoints()
+		if err == nil {
+			err = s.updateTransactionHistory(spendingTransactions, virtualDAAScore)
+		}
+	}
 	s.lock.Unlock()
 
-	return nil
+	return err
+}
+
+// updateTransactionHistory records the transactions that paid to the wallet or spent its
+// outputs since the last refresh. Outputs that left the UTXO set are only recorded as spent
+// once the wallet is synced, so that outputs of addresses that weren't scanned yet aren't
+// mistaken for spent ones.
+func (s *server) updateTransactionHistory(spendingTransactions []*externalapi.DomainTransaction, virtualDAAScore uint64) error {
+	for _, spendingTransaction := range spendingTransactions {
+		err := s.transactionHistory.recordSpendingTransaction(spendingTransaction,
+			consensushashing.TransactionID(spendingTransaction))
+		if err != nil {
+			return err
+		}
+	}
+
+	utxos := make([]*walletUTXO, 0, len(s.utxosSortedByAmount)+len(s.mempoolExcludedUTXOs))
+	utxos = append(utxos, s.utxosSortedByAmount...)
+	for _, utxo := range s.mempoolExcludedUTXOs {
+		utxos = append(utxos, utxo)
+	}
+	return s.transactionHistory.update(utxos, virtualDAAScore, s.walletAddressString)
 }
 
 func (s *server) refreshUTXOs() error {
@@ -314,7 +561,16 @@ func (s *server) refreshUTXOs() error {
 		return err
 	}
 
-	return s.updateUTXOSet(getUTXOsByAddressesResponse.Entries, mempoolEntriesByAddresses.Entries, refreshStart)
+	// The outputs that were spent since the last refresh are recorded in the transaction
+	// history with the DAA score of the virtual, which is queried after the UTXOs so that
+	// it's never lower than the DAA score of the blocks that spent them
+	getBlockDAGInfoResponse, err := s.backgroundRPCClient.GetBlockDAGInfo()
+	if err != nil {
+		return err
+	}
+
+	return s.updateUTXOSet(getUTXOsByAddressesResponse.Entries, mempoolEntriesByAddresses.Entries,
+		getBlockDAGInfoResponse.VirtualDAAScore, refreshStart)
 }
 
 func (s *server) forceSync() {
@@ -328,7 +584,7 @@ func (s *server) forceSync() {
 }
 
 func (s *server) isSynced() bool {
-	return s.nextSyncStartIndex > s.maxUsedIndex() && s.firstSyncDone.Load()
+	return !s.shouldResetAddresses && s.nextSyncStartIndex > s.maxUsedIndex() && s.firstSyncDone.Load()
 }
 
 func (s *server) formatSyncStateReport() string {
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+)
+
+func TestKeyChainsWithinGapLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestKeyChainsWithinGapLimit")
+	if err != nil {
+		t.Fatalf("TempDir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	keysFile := &keys.File{Version: keys.LastVersion, MinimumSignatures: 1}
+	err = keysFile.SetPath(&dagconfig.SimnetParams, filepath.Join(dir, "keys.json"), true)
+	if err != nil {
+		t.Fatalf("SetPath: %+v", err)
+	}
+	serverInstance := &server{keysFile: keysFile, gapLimit: 20}
+
+	bothKeyChains := []uint8{libkaspawallet.ExternalKeychain, libkaspawallet.InternalKeychain}
+	externalKeyChain := []uint8{libkaspawallet.ExternalKeychain}
+	tests := []struct {
+		index    uint32
+		expected []uint8
+	}{
+		{index: 0, expected: bothKeyChains},
+		{index: 20, expected: bothKeyChains},
+		{index: 21, expected: nil},
+	}
+	for _, test := range tests {
+		keyChainsToQuery := serverInstance.keyChainsWithinGapLimit(test.index)
+		if !reflect.DeepEqual(keyChainsToQuery, test.expected) {
+			t.Fatalf("index %d: expected key chains %v but got %v", test.index, test.expected, keyChainsToQuery)
+		}
+	}
+
+	// Finding a used address extends the scanned range of its key chain only
+	err = keysFile.SetLastUsedExternalIndex(30)
+	if err != nil {
+		t.Fatalf("SetLastUsedExternalIndex: %+v", err)
+	}
+	tests = []struct {
+		index    uint32
+		expected []uint8
+	}{
+		{index: 20, expected: bothKeyChains},
+		{index: 21, expected: externalKeyChain},
+		{index: 50, expected: externalKeyChain},
+		{index: 51, expected: nil},
+	}
+	for _, test := range tests {
+		keyChainsToQuery := serverInstance.keyChainsWithinGapLimit(test.index)
+		if !reflect.DeepEqual(keyChainsToQuery, test.expected) {
+			t.Fatalf("index %d: expected key chains %v but got %v", test.index, test.expected, keyChainsToQuery)
+		}
+	}
+}
+
+// TestUsedAddressesAreRemembered tests that addresses that are found to be used are saved
+// once, so that they're still counted as used after they're emptied
+func TestUsedAddressesAreRemembered(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestUsedAddressesAreRemembered")
+	if err != nil {
+		t.Fatalf("TempDir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	keysFile := &keys.File{Version: keys.LastVersion, MinimumSignatures: 1}
+	err = keysFile.SetPath(&dagconfig.SimnetParams, filepath.Join(dir, "keys.json"), true)
+	if err != nil {
+		t.Fatalf("SetPath: %+v", err)
+	}
+	serverInstance := &server{keysFile: keysFile, gapLimit: 20, addressSet: make(walletAddressSet)}
+
+	requestedAddressSet := walletAddressSet{
+		"used":   {index: 7, keyChain: libkaspawallet.ExternalKeychain},
+		"unused": {index: 8, keyChain: libkaspawallet.ExternalKeychain},
+	}
+	for i := 0; i < 2; i++ {
+		err = serverInstance.updateAddressesAndLastUsedIndexes(requestedAddressSet, []string{"used"})
+		if err != nil {
+			t.Fatalf("updateAddressesAndLastUsedIndexes: %+v", err)
+		}
+	}
+
+	expected := []*keys.UsedAddress{{Index: 7, KeyChain: libkaspawallet.ExternalKeychain}}
+	if !reflect.DeepEqual(keysFile.UsedAddresses(), expected) {
+		t.Fatalf("expected used addresses %+v but got %+v", expected, keysFile.UsedAddresses())
+	}
+	if keysFile.LastUsedExternalIndex() != 7 {
+		t.Fatalf("expected last used external index 7 but got %d", keysFile.LastUsedExternalIndex())
+	}
+}
@@ -0,0 +1,316 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionid"
+	"github.com/pkg/errors"
+)
+
+const (
+	transactionHistoryRecordTypeReceived = "received"
+	transactionHistoryRecordTypeSpending = "spending"
+	transactionHistoryRecordTypeSpent    = "spent"
+)
+
+// transactionHistoryRecord is a line of the transaction history file. Every record is about
+// a single output of the wallet: it was received, a transaction that spends it was seen,
+// or its spend was accepted.
+type transactionHistoryRecord struct {
+	Type                  string `json:"type"`
+	TransactionID         string `json:"transactionId"`
+	Index                 uint32 `json:"index"`
+	Amount                uint64 `json:"amount,omitempty"`
+	Address               string `json:"address,omitempty"`
+	BlockDAAScore         uint64 `json:"blockDaaScore,omitempty"`
+	IsCoinbase            bool   `json:"isCoinbase,omitempty"`
+	SpendingTransactionID string `json:"spendingTransactionId,omitempty"`
+}
+
+// walletTransactionID returns the ID of the transaction of the wallet the record is
+// about: the transaction that paid the received output, or the transaction that spent it,
+// which is empty if it's unknown
+func (record *transactionHistoryRecord) walletTransactionID() string {
+	if record.Type == transactionHistoryRecordTypeReceived {
+		return record.TransactionID
+	}
+	return record.SpendingTransactionID
+}
+
+// transactionHistory keeps the outputs the wallet ever received and the transactions that
+// spent them, so that transactions are still listed after their outputs are spent. It's
+// saved next to the keys file in a file that records are only appended to.
+type transactionHistory struct {
+	path                   string
+	received               map[externalapi.DomainOutpoint]*transactionHistoryRecord
+	spent                  map[externalapi.DomainOutpoint]*transactionHistoryRecord
+	spendingTransactionIDs map[externalapi.DomainOutpoint]string
+	transactionIDs         map[string]struct{}
+
+	// onNewTransaction, if set, is called with the ID of every transaction that's
+	// added to the history, once the records that added it are saved
+	onNewTransaction func(transactionID string)
+}
+
+// transactionHistoryPath returns the path of the transaction history of the given keys file
+func transactionHistoryPath(keysFilePath string) string {
+	return keysFilePath + ".transactions"
+}
+
+func openTransactionHistory(path string) (*transactionHistory, error) {
+	history := &transactionHistory{path: path}
+	history.clear()
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return history, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		record := &transactionHistoryRecord{}
+		err := json.Unmarshal(scanner.Bytes(), record)
+		if err != nil {
+			// A record that was only partially written before a crash is dropped. It's
+			// recorded again on the next refresh of the UTXOs.
+			log.Warnf("Ignoring a malformed record of the transaction history %s: %s", path, err)
+			continue
+		}
+		err = history.apply(record)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "error reading the transaction history %s", path)
+	}
+
+	return history, nil
+}
+
+func (th *transactionHistory) clear() {
+	th.received = make(map[externalapi.DomainOutpoint]*transactionHistoryRecord)
+	th.spent = make(map[externalapi.DomainOutpoint]*transactionHistoryRecord)
+	th.spendingTransactionIDs = make(map[externalapi.DomainOutpoint]string)
+	th.transactionIDs = make(map[string]struct{})
+}
+
+func (th *transactionHistory) apply(record *transactionHistoryRecord) error {
+	transactionID, err := transactionid.FromString(record.TransactionID)
+	if err != nil {
+		return err
+	}
+	outpoint := externalapi.DomainOutpoint{TransactionID: *transactionID, Index: record.Index}
+
+	switch record.Type {
+	case transactionHistoryRecordTypeReceived:
+		th.received[outpoint] = record
+	case transactionHistoryRecordTypeSpending:
+		th.spendingTransactionIDs[outpoint] = record.SpendingTransactionID
+	case transactionHistoryRecordTypeSpent:
+		th.spent[outpoint] = record
+	default:
+		return errors.Errorf("unknown transaction history record type %s", record.Type)
+	}
+	if walletTransactionID := record.walletTransactionID(); walletTransactionID != "" {
+		th.transactionIDs[walletTransactionID] = struct{}{}
+	}
+	return nil
+}
+
+// append saves the given records to the end of the history file, and applies them
+func (th *transactionHistory) append(records []*transactionHistoryRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	var serializedRecords []byte
+	for _, record := range records {
+		serializedRecord, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		serializedRecords = append(serializedRecords, serializedRecord...)
+		serializedRecords = append(serializedRecords, '\n')
+	}
+
+	file, err := os.OpenFile(th.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(serializedRecords)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	err = file.Sync()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	var newTransactionIDs []string
+	for _, record := range records {
+		walletTransactionID := record.walletTransactionID()
+		if _, ok := th.transactionIDs[walletTransactionID]; !ok && walletTransactionID != "" {
+			newTransactionIDs = append(newTransactionIDs, walletTransactionID)
+		}
+		err := th.apply(record)
+		if err != nil {
+			return err
+		}
+	}
+	if th.onNewTransaction != nil {
+		for _, transactionID := range newTransactionIDs {
+			th.onNewTransaction(transactionID)
+		}
+	}
+	return nil
+}
+
+// recordSpendingTransaction records the given transaction as the spender of the
+// outputs of the wallet it spends
+func (th *transactionHistory) recordSpendingTransaction(transaction *externalapi.DomainTransaction,
+	transactionID *externalapi.DomainTransactionID) error {
+
+	var records []*transactionHistoryRecord
+	for _, input := range transaction.Inputs {
+		outpoint := input.PreviousOutpoint
+		if _, ok := th.received[outpoint]; !ok {
+			continue
+		}
+		if _, ok := th.spent[outpoint]; ok {
+			continue
+		}
+		if th.spendingTransactionIDs[outpoint] == transactionID.String() {
+			continue
+		}
+		records = append(records, &transactionHistoryRecord{
+			Type:                  transactionHistoryRecordTypeSpending,
+			TransactionID:         outpoint.TransactionID.String(),
+			Index:                 outpoint.Index,
+			SpendingTransactionID: transactionID.String(),
+		})
+	}
+	return th.append(records)
+}
+
+// update records the outputs in the given UTXO set of the wallet that weren't received
+// before, and records the outputs that were received but left the UTXO set as spent by
+// a block with the given DAA score
+func (th *transactionHistory) update(utxos []*walletUTXO, virtualDAAScore uint64,
+	addressString func(address *walletAddress) (string, error)) error {
+
+	var records []*transactionHistoryRecord
+	unspent := make(map[externalapi.DomainOutpoint]struct{}, len(utxos))
+	for _, utxo := range utxos {
+		unspent[*utxo.Outpoint] = struct{}{}
+		if _, ok := th.received[*utxo.Outpoint]; ok {
+			continue
+		}
+		address, err := addressString(utxo.address)
+		if err != nil {
+			return err
+		}
+		records = append(records, &transactionHistoryRecord{
+			Type:          transactionHistoryRecordTypeReceived,
+			TransactionID: utxo.Outpoint.TransactionID.String(),
+			Index:         utxo.Outpoint.Index,
+			Amount:        utxo.UTXOEntry.Amount(),
+			Address:       address,
+			BlockDAAScore: utxo.UTXOEntry.BlockDAAScore(),
+			IsCoinbase:    utxo.UTXOEntry.IsCoinbase(),
+		})
+	}
+
+	for outpoint, received := range th.received {
+		if _, ok := unspent[outpoint]; ok {
+			continue
+		}
+		if _, ok := th.spent[outpoint]; ok {
+			continue
+		}
+		records = append(records, &transactionHistoryRecord{
+			Type:                  transactionHistoryRecordTypeSpent,
+			TransactionID:         received.TransactionID,
+			Index:                 received.Index,
+			Amount:                received.Amount,
+			Address:               received.Address,
+			BlockDAAScore:         virtualDAAScore,
+			SpendingTransactionID: th.spendingTransactionIDs[outpoint],
+		})
+	}
+
+	return th.append(records)
+}
+
+// reset forgets the whole history
+func (th *transactionHistory) reset() error {
+	err := os.Remove(th.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	th.clear()
+	return nil
+}
+
+// transactions returns the transactions that paid to the wallet or spent its outputs.
+// Outputs that were spent by a transaction the wallet never saw, for example one that
+// was sent by another wallet with the same keys while this one was offline, can't be
+// attributed to a transaction, so they're only listed as received.
+func (th *transactionHistory) transactions() (map[externalapi.DomainTransactionID]*walletTransaction, error) {
+	transactionsByID := make(map[externalapi.DomainTransactionID]*walletTransaction)
+	transaction := func(transactionIDString string, blockDAAScore uint64) (*walletTransaction, error) {
+		transactionID, err := transactionid.FromString(transactionIDString)
+		if err != nil {
+			return nil, err
+		}
+		transaction, ok := transactionsByID[*transactionID]
+		if !ok {
+			transaction = &walletTransaction{
+				cursor: &transactionCursor{blockDAAScore: blockDAAScore, transactionID: *transactionID},
+			}
+			transactionsByID[*transactionID] = transaction
+		}
+		return transaction, nil
+	}
+
+	for _, record := range th.received {
+		walletTransaction, err := transaction(record.TransactionID, record.BlockDAAScore)
+		if err != nil {
+			return nil, err
+		}
+		walletTransaction.amount += record.Amount
+		walletTransaction.isCoinbase = record.IsCoinbase
+		walletTransaction.addAddress(record.Address)
+	}
+
+	// The outputs the transactions pay back to the wallet are received with the DAA
+	// score of their accepting block, which is preferred over the DAA score of the
+	// virtual when the spend was seen
+	for _, record := range th.spent {
+		if record.SpendingTransactionID == "" {
+			continue
+		}
+		walletTransaction, err := transaction(record.SpendingTransactionID, record.BlockDAAScore)
+		if err != nil {
+			return nil, err
+		}
+		walletTransaction.spentAmount += record.Amount
+		walletTransaction.addAddress(record.Address)
+	}
+
+	return transactionsByID, nil
+}
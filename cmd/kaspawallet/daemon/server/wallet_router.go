@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// WalletMetadataKey is the gRPC metadata key that holds the name of the wallet a request
+// is routed to. Requests without it are routed to the default wallet, whose name is empty.
+const WalletMetadataKey = "wallet"
+
+// walletRouter routes the requests of the wallet daemon to the wallets it loaded, by the
+// wallet name in the metadata of each request
+type walletRouter struct {
+	wallets map[string]*server
+}
+
+func (r *walletRouter) wallet(ctx context.Context) (*server, error) {
+	walletName := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(WalletMetadataKey); len(values) > 0 {
+			walletName = values[0]
+		}
+	}
+
+	wallet, ok := r.wallets[walletName]
+	if !ok {
+		if walletName == "" {
+			return nil, status.Errorf(codes.NotFound, "no default wallet is loaded, a wallet name is required")
+		}
+		return nil, status.Errorf(codes.NotFound, "wallet %s is not loaded", walletName)
+	}
+	return wallet, nil
+}
+
+// serviceDesc returns the description of the wallet daemon service, with every method
+// handled by the wallet the request is routed to
+func (r *walletRouter) serviceDesc() *grpc.ServiceDesc {
+	serviceDesc := pb.Kaspawalletd_ServiceDesc
+	// The handlers are called with the wallets rather than with the router itself
+	serviceDesc.HandlerType = (*interface{})(nil)
+	serviceDesc.Methods = make([]grpc.MethodDesc, len(pb.Kaspawalletd_ServiceDesc.Methods))
+	for i, method := range pb.Kaspawalletd_ServiceDesc.Methods {
+		handler := method.Handler
+		serviceDesc.Methods[i] = grpc.MethodDesc{
+			MethodName: method.MethodName,
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error,
+				interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+
+				wallet, err := r.wallet(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return handler(wallet, ctx, dec, interceptor)
+			},
+		}
+	}
+	return &serviceDesc
+}
+
+func (r *walletRouter) register(grpcServer *grpc.Server) {
+	grpcServer.RegisterService(r.serviceDesc(), r)
+}
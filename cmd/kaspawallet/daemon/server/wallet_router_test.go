@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestWalletRouter(t *testing.T) {
+	lockedWallet := func(id byte) *server {
+		transactionID := externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{id})
+		return &server{lockedOutpoints: map[externalapi.DomainOutpoint]bool{
+			*externalapi.NewDomainOutpoint(transactionID, 0): false,
+		}}
+	}
+	defaultWallet := lockedWallet(1)
+	otherWallet := lockedWallet(2)
+	router := &walletRouter{wallets: map[string]*server{"": defaultWallet, "other": otherWallet}}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %+v", err)
+	}
+	grpcServer := grpc.NewServer()
+	router.register(grpcServer)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Dial: %+v", err)
+	}
+	defer conn.Close()
+	client := pb.NewKaspawalletdClient(conn)
+
+	lockedTransactionID := func(walletName string) (string, error) {
+		ctx := context.Background()
+		if walletName != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, WalletMetadataKey, walletName)
+		}
+		response, err := client.ListLockUnspent(ctx, &pb.ListLockUnspentRequest{})
+		if err != nil {
+			return "", err
+		}
+		return response.Outpoints[0].Outpoint.TransactionId, nil
+	}
+
+	for walletName, wallet := range map[string]*server{"": defaultWallet, "other": otherWallet} {
+		transactionID, err := lockedTransactionID(walletName)
+		if err != nil {
+			t.Fatalf("ListLockUnspent of wallet %s: %+v", walletName, err)
+		}
+		for outpoint := range wallet.lockedOutpoints {
+			if transactionID != outpoint.TransactionID.String() {
+				t.Fatalf("expected the request to wallet %s to be routed to it", walletName)
+			}
+		}
+	}
+
+	_, err = lockedTransactionID("missing")
+	if err == nil {
+		t.Fatalf("expected an error for a wallet that isn't loaded")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/client"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/utils"
+	"github.com/pkg/errors"
+)
+
+func dumpPrivateKey(conf *dumpPrivateKeyConfig) error {
+	if !conf.Yes {
+		err := confirmPrivateKeyDump(conf.Address)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(conf.Password) == 0 {
+		conf.Password = keys.GetPassword("Password:")
+	}
+
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.DumpPrivateKey(ctx, &pb.DumpPrivateKeyRequest{
+		Address:  conf.Address,
+		Password: conf.Password,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Private key (WIF):\n%s\n", response.PrivateKey)
+	return nil
+}
+
+func confirmPrivateKeyDump(address string) error {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("This operation will print the unencrypted private key of %s on the screen. Anyone that sees "+
+		"it will be able to steal the funds of this address. Are you sure you want to proceed (y/N)? ", address)
+	line, err := utils.ReadLine(reader)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+
+	if string(line) != "y" {
+		return errors.Errorf("Dump aborted by user")
+	}
+
+	return nil
+}
@@ -54,6 +54,15 @@ func dumpUnencryptedData(conf *dumpUnencryptedDataConfig) error {
 		i++
 	}
 
+	for _, address := range keysFile.ImportedAddresses() {
+		wif, _, err := keysFile.ImportedPrivateKey(address, conf.Password)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Imported private key of %s:\n%s\n\n", address, wif)
+	}
+
 	fmt.Printf("Minimum number of signatures: %d\n", keysFile.MinimumSignatures)
 	return nil
 }
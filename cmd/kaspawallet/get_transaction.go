@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/client"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/utils"
+)
+
+func getTransaction(conf *getTransactionConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.GetTransaction(ctx, &pb.GetTransactionRequest{TransactionId: conf.TransactionID})
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case response.IsInMempool:
+		fmt.Println("Status: in the mempool")
+	case response.AcceptingBlockHash != "":
+		fmt.Printf("Status: accepted by block %s\n", response.AcceptingBlockHash)
+		fmt.Printf("Confirmations: %d\n", response.Confirmations)
+	default:
+		fmt.Println("Status: not accepted")
+	}
+
+	if response.WalletTransaction != nil {
+		fmt.Printf("Received KAS: %s\n", utils.FormatKas(response.WalletTransaction.Amount))
+		fmt.Printf("Spent KAS: %s\n", utils.FormatKas(response.WalletTransaction.SpentAmount))
+		if response.WalletTransaction.IsCoinbase {
+			fmt.Println("Coinbase: yes")
+		}
+		for _, address := range response.WalletTransaction.Addresses {
+			fmt.Printf("\t%s\n", address)
+		}
+	}
+	return nil
+}
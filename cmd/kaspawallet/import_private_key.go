@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/client"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/utils"
+)
+
+func importPrivateKey(conf *importPrivateKeyConfig) error {
+	if len(conf.PrivateKey) == 0 {
+		conf.PrivateKey = keys.GetPassword("Private key (WIF):")
+	}
+
+	// The key is decoded before it's sent to the daemon, so that a key of
+	// the wrong network is rejected before the password is asked for
+	privateKey, err := libkaspawallet.DecodeWIF(conf.NetParams(), conf.PrivateKey)
+	if err != nil {
+		return err
+	}
+
+	if len(conf.Password) == 0 {
+		conf.Password = keys.GetPassword("Password:")
+	}
+
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.ImportPrivateKey(ctx, &pb.ImportPrivateKeyRequest{
+		PrivateKey: conf.PrivateKey,
+		Password:   conf.Password,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported the private key of:\n%s\n", response.Address)
+
+	shouldRescan := conf.Rescan
+	if !conf.Rescan && !conf.NoRescan {
+		shouldRescan, err = confirmRescan()
+		if err != nil {
+			return err
+		}
+	}
+	if !shouldRescan {
+		fmt.Println("\nThe funds of the imported key weren't swept. Use the sweep command or " +
+			"import-private-key --rescan to sweep them later.")
+		return nil
+	}
+
+	return sweepPrivateKey(conf.NetParams(), daemonClient, privateKey)
+}
+
+func confirmRescan() (bool, error) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("Scan the DAG for the funds of the imported key and sweep them to a new address of " +
+		"the wallet (y/N)? ")
+	line, err := utils.ReadLine(reader)
+	if err != nil {
+		return false, err
+	}
+
+	return string(line) == "y", nil
+}
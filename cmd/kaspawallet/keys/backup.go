@@ -0,0 +1,267 @@
+package keys
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+)
+
+// LastBackupVersion is the most up to date wallet backup format version
+const LastBackupVersion = 1
+
+// backupJSON is the encrypted envelope of a wallet backup. Its version is kept
+// unencrypted so that the format of the payload can change in future versions.
+type backupJSON struct {
+	Version uint32 `json:"version"`
+	Cipher  string `json:"cipher"`
+	Salt    string `json:"salt"`
+}
+
+// backupPayloadJSON is the content of a wallet backup
+type backupPayloadJSON struct {
+	Network   string        `json:"network"`
+	CreatedAt int64         `json:"createdAt"` // In milliseconds since the epoch
+	DAAScore  uint64        `json:"daaScore"`  // The virtual DAA score when the backup was created
+	KeysFile  *keysFileJSON `json:"keysFile"`
+}
+
+// Backup holds the metadata of a decrypted and verified wallet backup
+type Backup struct {
+	CreatedAt time.Time
+	DAAScore  uint64
+	File      *File
+}
+
+// CreateBackup returns the wallet, including its encrypted mnemonics and imported private
+// keys, as a versioned backup archive that is encrypted with the password of the wallet
+func (d *File) CreateBackup(params *dagconfig.Params, password string, daaScore uint64) ([]byte, error) {
+	err := d.verifyBackupPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(&backupPayloadJSON{
+		Network:   params.Name,
+		CreatedAt: time.Now().UnixMilli(),
+		DAAScore:  daaScore,
+		KeysFile:  d.toJSON(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	salt, err := generateSalt()
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := getAEAD(defaultNumThreads, []byte(password), salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(payload)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&backupJSON{
+		Version: LastBackupVersion,
+		Cipher:  hex.EncodeToString(aead.Seal(nonce, nonce, payload, nil)),
+		Salt:    hex.EncodeToString(salt),
+	})
+}
+
+// verifyBackupPassword makes sure that the password decrypts the mnemonics of the wallet, so that a
+// backup can be restored with the same password as the wallet. Watch-only wallets don't hold
+// mnemonics, so any non-empty password may be used for their backups.
+func (d *File) verifyBackupPassword(password string) error {
+	if len(password) == 0 {
+		return errors.New("a password is required in order to encrypt the backup")
+	}
+
+	if len(d.EncryptedMnemonics) == 0 {
+		return nil
+	}
+	return d.verifyPassword(password)
+}
+
+// OpenBackup decrypts the given wallet backup archive, and verifies that it's intact before it's
+// used: it must belong to the given network, the password must decrypt all of its keys, and its
+// mnemonics and imported private keys must match the public keys and addresses it holds.
+func OpenBackup(params *dagconfig.Params, archive []byte, password string) (*Backup, error) {
+	backup := &backupJSON{}
+	err := json.Unmarshal(archive, backup)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed wallet backup")
+	}
+
+	if backup.Version == 0 || backup.Version > LastBackupVersion {
+		return nil, errors.Errorf("unsupported wallet backup version %d", backup.Version)
+	}
+
+	cipher, err := hex.DecodeString(backup.Cipher)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed wallet backup")
+	}
+
+	salt, err := hex.DecodeString(backup.Salt)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed wallet backup")
+	}
+
+	payloadBytes, err := decryptMnemonic(defaultNumThreads, &EncryptedMnemonic{cipher: cipher, salt: salt}, []byte(password))
+	if err != nil {
+		return nil, errors.Wrap(err, "the wallet backup couldn't be decrypted, either the password is "+
+			"wrong or the backup is corrupted")
+	}
+
+	payload := &backupPayloadJSON{}
+	err = json.Unmarshal([]byte(payloadBytes), payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed wallet backup")
+	}
+
+	if payload.Network != params.Name {
+		return nil, errors.Errorf("the wallet backup belongs to %s while the wallet is on %s", payload.Network, params.Name)
+	}
+
+	if payload.KeysFile == nil {
+		return nil, errors.New("the wallet backup doesn't contain a keys file")
+	}
+
+	file := &File{}
+	err = file.fromJSON(payload.KeysFile)
+	if err != nil {
+		return nil, err
+	}
+
+	err = file.verifyIntegrity(params, password)
+	if err != nil {
+		return nil, errors.Wrap(err, "the wallet backup failed its integrity verification")
+	}
+
+	return &Backup{
+		CreatedAt: time.UnixMilli(payload.CreatedAt),
+		DAAScore:  payload.DAAScore,
+		File:      file,
+	}, nil
+}
+
+// verifyIntegrity makes sure that the mnemonics of the file match its extended public keys, and that
+// the imported private keys match their addresses
+func (d *File) verifyIntegrity(params *dagconfig.Params, password string) error {
+	if len(d.ExtendedPublicKeys) == 0 {
+		return errors.New("the wallet has no public keys")
+	}
+	if d.MinimumSignatures == 0 || d.MinimumSignatures > uint32(len(d.ExtendedPublicKeys)) {
+		return errors.Errorf("the wallet requires %d signatures out of %d public keys",
+			d.MinimumSignatures, len(d.ExtendedPublicKeys))
+	}
+
+	mnemonics, err := d.DecryptMnemonics(password)
+	if err != nil {
+		return err
+	}
+
+	isMultisig := len(d.ExtendedPublicKeys) > 1
+	for i, mnemonic := range mnemonics {
+		extendedPublicKey, err := libkaspawallet.MasterPublicKeyFromMnemonic(params, mnemonic, isMultisig)
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, walletExtendedPublicKey := range d.ExtendedPublicKeys {
+			if walletExtendedPublicKey == extendedPublicKey {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.Errorf("mnemonic #%d doesn't match any of the public keys of the wallet", i+1)
+		}
+	}
+
+	for _, address := range d.ImportedAddresses() {
+		wif, _, err := d.ImportedPrivateKey(address, password)
+		if err != nil {
+			return err
+		}
+
+		privateKey, err := libkaspawallet.DecodeWIF(params, wif)
+		if err != nil {
+			return err
+		}
+
+		publicKey, err := libkaspawallet.PublicKeyFromPrivateKey(privateKey)
+		if err != nil {
+			return err
+		}
+
+		importedAddress, err := util.NewAddressPublicKey(publicKey, params.Prefix)
+		if err != nil {
+			return err
+		}
+		if importedAddress.String() != address {
+			return errors.Errorf("the imported private key of %s belongs to %s", address, importedAddress)
+		}
+	}
+
+	return nil
+}
+
+// Restore replaces the content of the file with the content of the given verified backup, and saves
+// it. The replaced file is kept next to it, with the suffix ".before-restore".
+func (d *File) Restore(backup *Backup) error {
+	if d.path == "" {
+		return errors.New("cannot restore to a file with uninitialized path")
+	}
+
+	previousContent, err := os.ReadFile(d.path)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(d.path+".before-restore", previousContent, 0600)
+	if err != nil {
+		return err
+	}
+
+	restored := *backup.File
+	restored.path = d.path
+	restored.usedAddresses = nil
+	err = restored.saveAtomically()
+	if err != nil {
+		return err
+	}
+
+	// The addresses of the restored wallet are scanned from scratch
+	err = os.Remove(usedAddressesPath(d.path))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	*d = restored
+	return nil
+}
+
+// saveAtomically writes the file contents to a temporary file, and then moves it to
+// the path of the file, so that the file is never left partially written
+func (d *File) saveAtomically() error {
+	temporaryFile := *d
+	temporaryFile.path = d.path + ".tmp"
+	err := temporaryFile.Save()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temporaryFile.path, d.path)
+}
@@ -0,0 +1,197 @@
+package keys
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/util"
+)
+
+func TestBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestBackup")
+	if err != nil {
+		t.Fatalf("TempDir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	params := &dagconfig.SimnetParams
+	const password = "password"
+
+	file := newTestFile(t, params, filepath.Join(dir, "keys.json"), password)
+	privateKey, _, err := libkaspawallet.CreateKeyPair(false)
+	if err != nil {
+		t.Fatalf("CreateKeyPair: %+v", err)
+	}
+	wif, address := testWIFAndAddress(t, params, privateKey)
+	err = file.AddImportedPrivateKey(address, wif, password)
+	if err != nil {
+		t.Fatalf("AddImportedPrivateKey: %+v", err)
+	}
+	err = file.SetLastUsedExternalIndex(7)
+	if err != nil {
+		t.Fatalf("SetLastUsedExternalIndex: %+v", err)
+	}
+
+	_, err = file.CreateBackup(params, "wrong password", 0)
+	if err == nil {
+		t.Fatalf("expected creating a backup with a wrong password to fail")
+	}
+
+	const daaScore = 1234
+	archive, err := file.CreateBackup(params, password, daaScore)
+	if err != nil {
+		t.Fatalf("CreateBackup: %+v", err)
+	}
+
+	_, err = OpenBackup(params, archive, "wrong password")
+	if err == nil {
+		t.Fatalf("expected opening a backup with a wrong password to fail")
+	}
+	_, err = OpenBackup(&dagconfig.TestnetParams, archive, password)
+	if err == nil {
+		t.Fatalf("expected opening a backup of another network to fail")
+	}
+
+	corruptedArchive := make([]byte, len(archive))
+	copy(corruptedArchive, archive)
+	cipherIndex := len(`{"version":1,"cipher":"`)
+	if corruptedArchive[cipherIndex] == '0' {
+		corruptedArchive[cipherIndex] = '1'
+	} else {
+		corruptedArchive[cipherIndex] = '0'
+	}
+	_, err = OpenBackup(params, corruptedArchive, password)
+	if err == nil {
+		t.Fatalf("expected opening a corrupted backup to fail")
+	}
+
+	backup, err := OpenBackup(params, archive, password)
+	if err != nil {
+		t.Fatalf("OpenBackup: %+v", err)
+	}
+	if backup.DAAScore != daaScore {
+		t.Fatalf("expected the backup DAA score to be %d but got %d", daaScore, backup.DAAScore)
+	}
+
+	otherFilePath := filepath.Join(dir, "other-keys.json")
+	otherFile := newTestFile(t, params, otherFilePath, "other password")
+	err = otherFile.Restore(backup)
+	if err != nil {
+		t.Fatalf("Restore: %+v", err)
+	}
+	if otherFile.Path() != otherFilePath {
+		t.Fatalf("expected the restored file path to be %s but got %s", otherFilePath, otherFile.Path())
+	}
+
+	restoredFile, err := ReadKeysFile(params, otherFilePath)
+	if err != nil {
+		t.Fatalf("ReadKeysFile: %+v", err)
+	}
+	if !reflect.DeepEqual(restoredFile.ExtendedPublicKeys, file.ExtendedPublicKeys) {
+		t.Fatalf("expected the restored public keys to be %v but got %v",
+			file.ExtendedPublicKeys, restoredFile.ExtendedPublicKeys)
+	}
+	if restoredFile.LastUsedExternalIndex() != file.LastUsedExternalIndex() {
+		t.Fatalf("expected the restored last used external index to be %d but got %d",
+			file.LastUsedExternalIndex(), restoredFile.LastUsedExternalIndex())
+	}
+	restoredWIF, found, err := restoredFile.ImportedPrivateKey(address, password)
+	if err != nil {
+		t.Fatalf("ImportedPrivateKey: %+v", err)
+	}
+	if !found || restoredWIF != wif {
+		t.Fatalf("expected the restored file to hold the imported private key of %s", address)
+	}
+
+	_, err = ReadKeysFile(params, otherFilePath+".before-restore")
+	if err != nil {
+		t.Fatalf("expected the replaced file to be kept: %+v", err)
+	}
+}
+
+func TestBackupIntegrity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestBackupIntegrity")
+	if err != nil {
+		t.Fatalf("TempDir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	params := &dagconfig.SimnetParams
+	const password = "password"
+
+	// An imported private key that doesn't match its address
+	file := newTestFile(t, params, filepath.Join(dir, "keys.json"), password)
+	privateKey, _, err := libkaspawallet.CreateKeyPair(false)
+	if err != nil {
+		t.Fatalf("CreateKeyPair: %+v", err)
+	}
+	otherPrivateKey, _, err := libkaspawallet.CreateKeyPair(false)
+	if err != nil {
+		t.Fatalf("CreateKeyPair: %+v", err)
+	}
+	wif, _ := testWIFAndAddress(t, params, privateKey)
+	_, otherAddress := testWIFAndAddress(t, params, otherPrivateKey)
+	err = file.AddImportedPrivateKey(otherAddress, wif, password)
+	if err != nil {
+		t.Fatalf("AddImportedPrivateKey: %+v", err)
+	}
+	archive, err := file.CreateBackup(params, password, 0)
+	if err != nil {
+		t.Fatalf("CreateBackup: %+v", err)
+	}
+	_, err = OpenBackup(params, archive, password)
+	if err == nil {
+		t.Fatalf("expected opening a backup with a mismatching imported private key to fail")
+	}
+
+	// A mnemonic that doesn't match the public key of the wallet
+	file = newTestFile(t, params, filepath.Join(dir, "keys.json"), password)
+	otherFile := newTestFile(t, params, filepath.Join(dir, "other-keys.json"), password)
+	file.ExtendedPublicKeys = otherFile.ExtendedPublicKeys
+	archive, err = file.CreateBackup(params, password, 0)
+	if err != nil {
+		t.Fatalf("CreateBackup: %+v", err)
+	}
+	_, err = OpenBackup(params, archive, password)
+	if err == nil {
+		t.Fatalf("expected opening a backup with a mismatching mnemonic to fail")
+	}
+}
+
+func newTestFile(t *testing.T, params *dagconfig.Params, path string, password string) *File {
+	mnemonic, err := libkaspawallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+	file, err := NewFileFromMnemonic(params, mnemonic, password)
+	if err != nil {
+		t.Fatalf("NewFileFromMnemonic: %+v", err)
+	}
+	file.path = path
+	err = file.Save()
+	if err != nil {
+		t.Fatalf("Save: %+v", err)
+	}
+	return file
+}
+
+func testWIFAndAddress(t *testing.T, params *dagconfig.Params, privateKey []byte) (string, string) {
+	wif, err := libkaspawallet.EncodeWIF(params, privateKey)
+	if err != nil {
+		t.Fatalf("EncodeWIF: %+v", err)
+	}
+	publicKey, err := libkaspawallet.PublicKeyFromPrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("PublicKeyFromPrivateKey: %+v", err)
+	}
+	address, err := util.NewAddressPublicKey(publicKey, params.Prefix)
+	if err != nil {
+		t.Fatalf("NewAddressPublicKey: %+v", err)
+	}
+	return wif, address.String()
+}
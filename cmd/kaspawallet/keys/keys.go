@@ -47,6 +47,15 @@ type keysFileJSON struct {
 	LastUsedExternalIndex uint32                     `json:"lastUsedExternalIndex"`
 	LastUsedInternalIndex uint32                     `json:"lastUsedInternalIndex"`
 	ECDSA                 bool                       `json:"ecdsa"`
+	SyncCheckpoint        *SyncCheckpoint            `json:"syncCheckpoint,omitempty"` // Only read from keys files of older wallets. See syncCheckpointPath.
+	ImportedKeys          []*importedKeyJSON         `json:"importedKeys,omitempty"`
+	LockedOutpoints       []*LockedOutpoint          `json:"lockedOutpoints,omitempty"`
+}
+
+type importedKeyJSON struct {
+	Address string `json:"address"`
+	Cipher  string `json:"cipher"`
+	Salt    string `json:"salt"`
 }
 
 // EncryptedMnemonic represents an encrypted mnemonic
@@ -55,6 +64,34 @@ type EncryptedMnemonic struct {
 	salt   []byte
 }
 
+// importedKey is a private key that was imported to the wallet in the Wallet Import
+// Format, encrypted with the password of the wallet
+type importedKey struct {
+	address      string
+	encryptedWIF *EncryptedMnemonic
+}
+
+// SyncCheckpoint holds the progress of the first address scan of the wallet, so that an
+// interrupted scan is resumed instead of restarted from the first address index
+type SyncCheckpoint struct {
+	NextIndex     uint32         `json:"nextIndex"`
+	UsedAddresses []*UsedAddress `json:"usedAddresses"`
+}
+
+// UsedAddress is the derivation data of an address that was found to be in use
+type UsedAddress struct {
+	Index         uint32 `json:"index"`
+	CosignerIndex uint32 `json:"cosignerIndex"`
+	KeyChain      uint8  `json:"keyChain"`
+}
+
+// LockedOutpoint is an outpoint of the wallet that is reserved for manual
+// transactions, so it isn't selected automatically to fund transactions
+type LockedOutpoint struct {
+	TransactionID string `json:"transactionId"`
+	Index         uint32 `json:"index"`
+}
+
 // File holds all the data related to the wallet keys
 type File struct {
 	Version               uint32
@@ -65,6 +102,10 @@ type File struct {
 	CosignerIndex         uint32
 	lastUsedExternalIndex uint32
 	lastUsedInternalIndex uint32
+	syncCheckpoint        *SyncCheckpoint
+	usedAddresses         []*UsedAddress
+	importedKeys          []*importedKey
+	lockedOutpoints       []*LockedOutpoint
 	ECDSA                 bool
 	path                  string
 }
@@ -78,6 +119,15 @@ func (d *File) toJSON() *keysFileJSON {
 		}
 	}
 
+	var importedKeysJSON []*importedKeyJSON
+	for _, key := range d.importedKeys {
+		importedKeysJSON = append(importedKeysJSON, &importedKeyJSON{
+			Address: key.address,
+			Cipher:  hex.EncodeToString(key.encryptedWIF.cipher),
+			Salt:    hex.EncodeToString(key.encryptedWIF.salt),
+		})
+	}
+
 	return &keysFileJSON{
 		Version:               d.Version,
 		NumThreads:            d.NumThreads,
@@ -88,6 +138,8 @@ func (d *File) toJSON() *keysFileJSON {
 		CosignerIndex:         d.CosignerIndex,
 		LastUsedExternalIndex: d.lastUsedExternalIndex,
 		LastUsedInternalIndex: d.lastUsedInternalIndex,
+		ImportedKeys:          importedKeysJSON,
+		LockedOutpoints:       d.lockedOutpoints,
 	}
 }
 
@@ -117,6 +169,8 @@ func (d *File) fromJSON(fileJSON *keysFileJSON) error {
 	d.CosignerIndex = fileJSON.CosignerIndex
 	d.lastUsedExternalIndex = fileJSON.LastUsedExternalIndex
 	d.lastUsedInternalIndex = fileJSON.LastUsedInternalIndex
+	d.syncCheckpoint = fileJSON.SyncCheckpoint
+	d.lockedOutpoints = fileJSON.LockedOutpoints
 
 	d.EncryptedMnemonics = make([]*EncryptedMnemonic, len(fileJSON.EncryptedPrivateKeys))
 	for i, encryptedPrivateKeyJSON := range fileJSON.EncryptedPrivateKeys {
@@ -136,6 +190,24 @@ func (d *File) fromJSON(fileJSON *keysFileJSON) error {
 		}
 	}
 
+	d.importedKeys = make([]*importedKey, len(fileJSON.ImportedKeys))
+	for i, importedKeyJSON := range fileJSON.ImportedKeys {
+		cipher, err := hex.DecodeString(importedKeyJSON.Cipher)
+		if err != nil {
+			return err
+		}
+
+		salt, err := hex.DecodeString(importedKeyJSON.Salt)
+		if err != nil {
+			return err
+		}
+
+		d.importedKeys[i] = &importedKey{
+			address:      importedKeyJSON.Address,
+			encryptedWIF: &EncryptedMnemonic{cipher: cipher, salt: salt},
+		}
+	}
+
 	return nil
 }
 
@@ -205,6 +277,94 @@ func (d *File) LastUsedInternalIndex() uint32 {
 	return d.lastUsedInternalIndex
 }
 
+// syncCheckpointPath returns the path of the file that holds the sync checkpoint.
+// It's saved once per batch of scanned addresses, so it's kept apart from the
+// keys file so that the keys file isn't rewritten that often.
+func syncCheckpointPath(path string) string {
+	return path + ".sync-checkpoint"
+}
+
+// SetSyncCheckpoint sets the progress of the first address scan of the wallet,
+// and saves it to the disk. A nil checkpoint marks the scan as complete.
+func (d *File) SetSyncCheckpoint(checkpoint *SyncCheckpoint) error {
+	if d.syncCheckpoint == nil && checkpoint == nil {
+		return nil
+	}
+	if d.path == "" {
+		return errors.New("cannot save the sync checkpoint of a file with uninitialized path")
+	}
+
+	d.syncCheckpoint = checkpoint
+	if checkpoint == nil {
+		err := os.Remove(syncCheckpointPath(d.path))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	serializedCheckpoint, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(syncCheckpointPath(d.path), serializedCheckpoint)
+}
+
+// SyncCheckpoint returns the progress of an interrupted first address scan
+// of the wallet, or nil if there's no scan to resume
+func (d *File) SyncCheckpoint() *SyncCheckpoint {
+	return d.syncCheckpoint
+}
+
+// usedAddressesPath returns the path of the file that holds the addresses that
+// ever received funds. It grows with every used address that's found, so it's
+// kept apart from the keys file as well.
+func usedAddressesPath(path string) string {
+	return path + ".used-addresses"
+}
+
+// AddUsedAddresses adds addresses that were found to have received funds, and saves
+// them to the disk. Addresses that are emptied are still counted as used by the
+// address scan, so that the addresses that come after them are still found.
+func (d *File) AddUsedAddresses(addresses []*UsedAddress) error {
+	if len(addresses) == 0 {
+		return nil
+	}
+	if d.path == "" {
+		return errors.New("cannot save the used addresses of a file with uninitialized path")
+	}
+
+	usedAddresses := append(append([]*UsedAddress{}, d.usedAddresses...), addresses...)
+	serializedUsedAddresses, err := json.Marshal(usedAddresses)
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomically(usedAddressesPath(d.path), serializedUsedAddresses)
+	if err != nil {
+		return err
+	}
+	d.usedAddresses = usedAddresses
+	return nil
+}
+
+// UsedAddresses returns the addresses that were found to have received funds
+func (d *File) UsedAddresses() []*UsedAddress {
+	return d.usedAddresses
+}
+
+// SetLockedOutpoints sets the outpoints whose locks are kept across restarts
+// of the wallet daemon, and saves the file.
+func (d *File) SetLockedOutpoints(lockedOutpoints []*LockedOutpoint) error {
+	d.lockedOutpoints = lockedOutpoints
+	return d.Save()
+}
+
+// LockedOutpoints returns the outpoints whose locks are kept across restarts
+// of the wallet daemon
+func (d *File) LockedOutpoints() []*LockedOutpoint {
+	return d.lockedOutpoints
+}
+
 // DecryptMnemonics asks the user to enter the password for the private keys and
 // returns the decrypted private keys.
 func (d *File) DecryptMnemonics(password string) ([]string, error) {
@@ -231,6 +391,74 @@ func (d *File) DecryptMnemonics(password string) ([]string, error) {
 	return privateKeys, nil
 }
 
+// verifyPassword makes sure that the wallet holds encrypted mnemonics and that
+// the given password decrypts them
+func (d *File) verifyPassword(password string) error {
+	if len(d.EncryptedMnemonics) == 0 {
+		return errors.New("the wallet doesn't hold any encrypted private keys")
+	}
+
+	_, err := d.DecryptMnemonics(password)
+	if err != nil {
+		return errors.Wrap(err, "wrong password")
+	}
+	return nil
+}
+
+// AddImportedPrivateKey encrypts the given WIF private key with the password of the wallet,
+// and saves it to the file along with its address. Only wallets that hold encrypted mnemonics
+// can hold imported private keys, so that they're never saved unencrypted.
+func (d *File) AddImportedPrivateKey(address string, wif string, password string) error {
+	for _, key := range d.importedKeys {
+		if key.address == address {
+			return errors.Errorf("the private key of %s was already imported", address)
+		}
+	}
+
+	err := d.verifyPassword(password)
+	if err != nil {
+		return err
+	}
+
+	encryptedWIF, err := encryptMnemonic(wif, []byte(password))
+	if err != nil {
+		return err
+	}
+
+	d.importedKeys = append(d.importedKeys, &importedKey{
+		address:      address,
+		encryptedWIF: encryptedWIF,
+	})
+	return d.Save()
+}
+
+// ImportedPrivateKey returns the decrypted WIF private key that was imported for the given
+// address, or false if no private key was imported for it
+func (d *File) ImportedPrivateKey(address string, password string) (wif string, found bool, err error) {
+	for _, key := range d.importedKeys {
+		if key.address != address {
+			continue
+		}
+
+		wif, err := decryptMnemonic(defaultNumThreads, key.encryptedWIF, []byte(password))
+		if err != nil {
+			return "", false, errors.Wrap(err, "wrong password")
+		}
+		return wif, true, nil
+	}
+
+	return "", false, nil
+}
+
+// ImportedAddresses returns the addresses of the imported private keys
+func (d *File) ImportedAddresses() []string {
+	addresses := make([]string, len(d.importedKeys))
+	for i, key := range d.importedKeys {
+		addresses[i] = key.address
+	}
+	return addresses
+}
+
 // ReadKeysFile returns the data related to the keys file
 func ReadKeysFile(netParams *dagconfig.Params, path string) (*File, error) {
 	if path == "" {
@@ -258,9 +486,62 @@ func ReadKeysFile(netParams *dagconfig.Params, path string) (*File, error) {
 		return nil, err
 	}
 
+	err = keysFile.readSyncCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	err = keysFile.readUsedAddresses()
+	if err != nil {
+		return nil, err
+	}
+
 	return keysFile, nil
 }
 
+// readSyncCheckpoint reads the sync checkpoint from its own file. Older wallets kept
+// the checkpoint in the keys file itself, so such a checkpoint is moved to its own file.
+func (d *File) readSyncCheckpoint() error {
+	serializedCheckpoint, err := os.ReadFile(syncCheckpointPath(d.path))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if d.syncCheckpoint == nil {
+			return nil
+		}
+		checkpoint := d.syncCheckpoint
+		d.syncCheckpoint = nil
+		return d.SetSyncCheckpoint(checkpoint)
+	}
+
+	checkpoint := &SyncCheckpoint{}
+	err = json.Unmarshal(serializedCheckpoint, checkpoint)
+	if err != nil {
+		return errors.Wrapf(err, "error reading the sync checkpoint of %s", d.path)
+	}
+	d.syncCheckpoint = checkpoint
+	return nil
+}
+
+func (d *File) readUsedAddresses() error {
+	serializedUsedAddresses, err := os.ReadFile(usedAddressesPath(d.path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var usedAddresses []*UsedAddress
+	err = json.Unmarshal(serializedUsedAddresses, &usedAddresses)
+	if err != nil {
+		return errors.Wrapf(err, "error reading the used addresses of %s", d.path)
+	}
+	d.usedAddresses = usedAddresses
+	return nil
+}
+
 func createFileDirectoryIfDoesntExist(path string) error {
 	dir := filepath.Dir(path)
 	exists, err := pathExists(dir)
@@ -301,19 +582,44 @@ func (d *File) Save() error {
 		return err
 	}
 
-	file, err := os.OpenFile(d.path, os.O_WRONLY|os.O_CREATE, 0600)
+	serializedFile, err := json.Marshal(d.toJSON())
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	return writeFileAtomically(d.path, append(serializedFile, '\n'))
+}
 
-	encoder := json.NewEncoder(file)
-	err = encoder.Encode(d.toJSON())
+// writeFileAtomically writes data to a temporary file in the directory of path and
+// renames it over path once it's flushed to the disk, so that a crash midway leaves
+// either the old or the new contents in path, and never a truncated file.
+func writeFileAtomically(path string, data []byte) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
 	if err != nil {
 		return err
 	}
+	tempPath := tempFile.Name()
+	defer os.Remove(tempPath)
 
-	return nil
+	err = tempFile.Chmod(0600)
+	if err != nil {
+		tempFile.Close()
+		return err
+	}
+	_, err = tempFile.Write(data)
+	if err != nil {
+		tempFile.Close()
+		return err
+	}
+	err = tempFile.Sync()
+	if err != nil {
+		tempFile.Close()
+		return err
+	}
+	err = tempFile.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tempPath, path)
 }
 
 const defaultNumThreads = 8
@@ -336,6 +642,11 @@ func (d *File) numThreads(password []byte) (uint8, error) {
 	}
 
 	d.NumThreads = numThreads
+	// A file that wasn't read from the disk, such as the file of a wallet backup,
+	// keeps the detected number of threads until it's saved
+	if d.path == "" {
+		return numThreads, nil
+	}
 	err = d.Save()
 	if err != nil {
 		return 0, err
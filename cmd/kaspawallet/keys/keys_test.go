@@ -0,0 +1,302 @@
+package keys
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+)
+
+func TestSyncCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestSyncCheckpoint")
+	if err != nil {
+		t.Fatalf("TempDir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	params := &dagconfig.SimnetParams
+	path := filepath.Join(dir, "keys.json")
+	file := &File{
+		Version:            LastVersion,
+		ExtendedPublicKeys: []string{"kpub"},
+		MinimumSignatures:  1,
+		path:               path,
+	}
+	err = file.Save()
+	if err != nil {
+		t.Fatalf("Save: %+v", err)
+	}
+
+	checkpoint := &SyncCheckpoint{
+		NextIndex: 3000,
+		UsedAddresses: []*UsedAddress{
+			{Index: 5, CosignerIndex: 0, KeyChain: 0},
+			{Index: 2500, CosignerIndex: 0, KeyChain: 1},
+		},
+	}
+	err = file.SetSyncCheckpoint(checkpoint)
+	if err != nil {
+		t.Fatalf("SetSyncCheckpoint: %+v", err)
+	}
+
+	// The checkpoint is kept out of the keys file, and no temporary files are left behind
+	serializedFile, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %+v", err)
+	}
+	if strings.Contains(string(serializedFile), "syncCheckpoint") {
+		t.Fatalf("expected the sync checkpoint not to be saved in the keys file")
+	}
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %+v", err)
+	}
+	if len(dirEntries) != 2 {
+		t.Fatalf("expected only the keys file and the sync checkpoint file, but got %d files", len(dirEntries))
+	}
+
+	readFile, err := ReadKeysFile(params, path)
+	if err != nil {
+		t.Fatalf("ReadKeysFile: %+v", err)
+	}
+	if !reflect.DeepEqual(readFile.SyncCheckpoint(), checkpoint) {
+		t.Fatalf("expected sync checkpoint %+v but got %+v", checkpoint, readFile.SyncCheckpoint())
+	}
+
+	// Clearing the checkpoint marks the first scan as complete
+	err = readFile.SetSyncCheckpoint(nil)
+	if err != nil {
+		t.Fatalf("SetSyncCheckpoint: %+v", err)
+	}
+
+	readFile, err = ReadKeysFile(params, path)
+	if err != nil {
+		t.Fatalf("ReadKeysFile: %+v", err)
+	}
+	if readFile.SyncCheckpoint() != nil {
+		t.Fatalf("expected no sync checkpoint but got %+v", readFile.SyncCheckpoint())
+	}
+}
+
+func TestUsedAddresses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestUsedAddresses")
+	if err != nil {
+		t.Fatalf("TempDir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	params := &dagconfig.SimnetParams
+	path := filepath.Join(dir, "keys.json")
+	file := &File{
+		Version:            LastVersion,
+		ExtendedPublicKeys: []string{"kpub"},
+		MinimumSignatures:  1,
+		path:               path,
+	}
+	err = file.Save()
+	if err != nil {
+		t.Fatalf("Save: %+v", err)
+	}
+
+	firstAddresses := []*UsedAddress{{Index: 5, CosignerIndex: 0, KeyChain: 0}}
+	secondAddresses := []*UsedAddress{{Index: 40, CosignerIndex: 0, KeyChain: 1}}
+	err = file.AddUsedAddresses(firstAddresses)
+	if err != nil {
+		t.Fatalf("AddUsedAddresses: %+v", err)
+	}
+	err = file.AddUsedAddresses(secondAddresses)
+	if err != nil {
+		t.Fatalf("AddUsedAddresses: %+v", err)
+	}
+
+	readFile, err := ReadKeysFile(params, path)
+	if err != nil {
+		t.Fatalf("ReadKeysFile: %+v", err)
+	}
+	expected := append(firstAddresses, secondAddresses...)
+	if !reflect.DeepEqual(readFile.UsedAddresses(), expected) {
+		t.Fatalf("expected used addresses %+v but got %+v", expected, readFile.UsedAddresses())
+	}
+}
+
+// TestLegacySyncCheckpoint tests that a sync checkpoint that was saved in the keys
+// file by an older wallet is still read, and is moved to its own file
+func TestLegacySyncCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestLegacySyncCheckpoint")
+	if err != nil {
+		t.Fatalf("TempDir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	params := &dagconfig.SimnetParams
+	path := filepath.Join(dir, "keys.json")
+	checkpoint := &SyncCheckpoint{
+		NextIndex:     1000,
+		UsedAddresses: []*UsedAddress{{Index: 7, CosignerIndex: 0, KeyChain: 0}},
+	}
+	legacyFileJSON := (&File{Version: LastVersion, ExtendedPublicKeys: []string{"kpub"}, MinimumSignatures: 1}).toJSON()
+	legacyFileJSON.SyncCheckpoint = checkpoint
+	serializedLegacyFile, err := json.Marshal(legacyFileJSON)
+	if err != nil {
+		t.Fatalf("Marshal: %+v", err)
+	}
+	err = ioutil.WriteFile(path, serializedLegacyFile, 0600)
+	if err != nil {
+		t.Fatalf("WriteFile: %+v", err)
+	}
+
+	readFile, err := ReadKeysFile(params, path)
+	if err != nil {
+		t.Fatalf("ReadKeysFile: %+v", err)
+	}
+	if !reflect.DeepEqual(readFile.SyncCheckpoint(), checkpoint) {
+		t.Fatalf("expected sync checkpoint %+v but got %+v", checkpoint, readFile.SyncCheckpoint())
+	}
+
+	// Once the keys file is saved without it, the checkpoint is read from its own file
+	err = readFile.Save()
+	if err != nil {
+		t.Fatalf("Save: %+v", err)
+	}
+	readFile, err = ReadKeysFile(params, path)
+	if err != nil {
+		t.Fatalf("ReadKeysFile: %+v", err)
+	}
+	if !reflect.DeepEqual(readFile.SyncCheckpoint(), checkpoint) {
+		t.Fatalf("expected sync checkpoint %+v but got %+v", checkpoint, readFile.SyncCheckpoint())
+	}
+}
+
+func TestLockedOutpoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestLockedOutpoints")
+	if err != nil {
+		t.Fatalf("TempDir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	params := &dagconfig.SimnetParams
+	path := filepath.Join(dir, "keys.json")
+	file := &File{
+		Version:            LastVersion,
+		ExtendedPublicKeys: []string{"kpub"},
+		MinimumSignatures:  1,
+		path:               path,
+	}
+	err = file.Save()
+	if err != nil {
+		t.Fatalf("Save: %+v", err)
+	}
+
+	lockedOutpoints := []*LockedOutpoint{
+		{TransactionID: "0000000000000000000000000000000000000000000000000000000000000001", Index: 0},
+		{TransactionID: "0000000000000000000000000000000000000000000000000000000000000002", Index: 3},
+	}
+	err = file.SetLockedOutpoints(lockedOutpoints)
+	if err != nil {
+		t.Fatalf("SetLockedOutpoints: %+v", err)
+	}
+
+	readFile, err := ReadKeysFile(params, path)
+	if err != nil {
+		t.Fatalf("ReadKeysFile: %+v", err)
+	}
+	if !reflect.DeepEqual(readFile.LockedOutpoints(), lockedOutpoints) {
+		t.Fatalf("expected locked outpoints %+v but got %+v", lockedOutpoints, readFile.LockedOutpoints())
+	}
+
+	err = readFile.SetLockedOutpoints(nil)
+	if err != nil {
+		t.Fatalf("SetLockedOutpoints: %+v", err)
+	}
+
+	readFile, err = ReadKeysFile(params, path)
+	if err != nil {
+		t.Fatalf("ReadKeysFile: %+v", err)
+	}
+	if len(readFile.LockedOutpoints()) != 0 {
+		t.Fatalf("expected no locked outpoints but got %+v", readFile.LockedOutpoints())
+	}
+}
+
+func TestImportedPrivateKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestImportedPrivateKeys")
+	if err != nil {
+		t.Fatalf("TempDir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	params := &dagconfig.SimnetParams
+	path := filepath.Join(dir, "keys.json")
+	const password = "password"
+	const address = "kaspasim:address"
+	const wif = "wif"
+
+	watchOnlyFile := &File{
+		Version:            LastVersion,
+		ExtendedPublicKeys: []string{"kpub"},
+		MinimumSignatures:  1,
+		path:               path,
+	}
+	err = watchOnlyFile.AddImportedPrivateKey(address, wif, password)
+	if err == nil {
+		t.Fatalf("expected importing a private key to a wallet without private keys to fail")
+	}
+
+	mnemonic, err := libkaspawallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+	file, err := NewFileFromMnemonic(params, mnemonic, password)
+	if err != nil {
+		t.Fatalf("NewFileFromMnemonic: %+v", err)
+	}
+	file.path = path
+
+	err = file.AddImportedPrivateKey(address, wif, "wrong password")
+	if err == nil {
+		t.Fatalf("expected importing a private key with a wrong password to fail")
+	}
+	err = file.AddImportedPrivateKey(address, wif, password)
+	if err != nil {
+		t.Fatalf("AddImportedPrivateKey: %+v", err)
+	}
+	err = file.AddImportedPrivateKey(address, wif, password)
+	if err == nil {
+		t.Fatalf("expected importing the same private key twice to fail")
+	}
+
+	readFile, err := ReadKeysFile(params, path)
+	if err != nil {
+		t.Fatalf("ReadKeysFile: %+v", err)
+	}
+	if !reflect.DeepEqual(readFile.ImportedAddresses(), []string{address}) {
+		t.Fatalf("expected imported addresses %v but got %v", []string{address}, readFile.ImportedAddresses())
+	}
+
+	decryptedWIF, found, err := readFile.ImportedPrivateKey(address, password)
+	if err != nil {
+		t.Fatalf("ImportedPrivateKey: %+v", err)
+	}
+	if !found || decryptedWIF != wif {
+		t.Fatalf("expected to find the private key %s but got %s (found: %t)", wif, decryptedWIF, found)
+	}
+
+	_, _, err = readFile.ImportedPrivateKey(address, "wrong password")
+	if err == nil {
+		t.Fatalf("expected decrypting an imported private key with a wrong password to fail")
+	}
+
+	_, found, err = readFile.ImportedPrivateKey("kaspasim:other", password)
+	if err != nil {
+		t.Fatalf("ImportedPrivateKey: %+v", err)
+	}
+	if found {
+		t.Fatalf("expected not to find a private key for an address that wasn't imported")
+	}
+}
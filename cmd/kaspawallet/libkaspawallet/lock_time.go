@@ -0,0 +1,37 @@
+package libkaspawallet
+
+import "math/rand"
+
+const (
+	// antiFeeSnipingRandomizationChance is one in how many transactions get a lock time
+	// further back than the current DAA score, so that transactions that were delayed,
+	// such as ones that were signed offline, don't stand out
+	antiFeeSnipingRandomizationChance = 10
+
+	// antiFeeSnipingMaxRandomOffset is how far back the lock time of such transactions is at most
+	antiFeeSnipingMaxRandomOffset = 100
+)
+
+// AntiFeeSnipingLockTime returns a lock time for a new transaction, given the DAA score
+// of the virtual of the node the transaction is sent to.
+//
+// The lock time lets the transaction only be included in blocks whose DAA score is
+// above the current one, so that a miner can't take its fee by reorganizing the DAG to
+// include it in a block below the current DAA score. The more transactions do this, the
+// less reorganizing the DAG rewards miners.
+func AntiFeeSnipingLockTime(virtualDAAScore uint64) uint64 {
+	// A transaction is finalized once the DAA score of the block is above its lock time,
+	// so a lock time of virtualDAAScore-1 is the highest one that the next block finalizes
+	if virtualDAAScore <= 1 {
+		return 0
+	}
+	lockTime := virtualDAAScore - 1
+	if rand.Intn(antiFeeSnipingRandomizationChance) == 0 {
+		offset := uint64(rand.Intn(antiFeeSnipingMaxRandomOffset + 1))
+		if offset >= lockTime {
+			return 0
+		}
+		lockTime -= offset
+	}
+	return lockTime
+}
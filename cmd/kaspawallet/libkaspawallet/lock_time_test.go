@@ -0,0 +1,37 @@
+package libkaspawallet_test
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+)
+
+func TestAntiFeeSnipingLockTime(t *testing.T) {
+	const virtualDAAScore = 1000
+	const maxRandomOffset = 100
+	transaction := &externalapi.DomainTransaction{
+		Inputs: []*externalapi.DomainTransactionInput{{Sequence: 0}},
+	}
+	for i := 0; i < 1000; i++ {
+		transaction.LockTime = libkaspawallet.AntiFeeSnipingLockTime(virtualDAAScore)
+		if transaction.LockTime >= virtualDAAScore || transaction.LockTime < virtualDAAScore-1-maxRandomOffset {
+			t.Fatalf("Lock time %d is out of range for a virtual DAA score of %d", transaction.LockTime, virtualDAAScore)
+		}
+		if !transactionhelper.IsFinalizedTransaction(transaction, virtualDAAScore, 0) {
+			t.Fatalf("A transaction with lock time %d isn't finalized at DAA score %d", transaction.LockTime, virtualDAAScore)
+		}
+	}
+	transaction.LockTime = libkaspawallet.AntiFeeSnipingLockTime(virtualDAAScore)
+	if transactionhelper.IsFinalizedTransaction(transaction, transaction.LockTime, 0) {
+		t.Fatalf("A transaction with lock time %d is finalized at the same DAA score", transaction.LockTime)
+	}
+
+	for _, lowDAAScore := range []uint64{0, 1} {
+		lockTime := libkaspawallet.AntiFeeSnipingLockTime(lowDAAScore)
+		if lockTime != 0 {
+			t.Fatalf("Expected a lock time of 0 for a virtual DAA score of %d, but got %d", lowDAAScore, lockTime)
+		}
+	}
+}
@@ -43,6 +43,34 @@ func Sign(params *dagconfig.Params, mnemonics []string, serializedPSTx []byte, e
 	return serialization.SerializePartiallySignedTransaction(partiallySignedTransaction)
 }
 
+// SignTransactions signs each of the given transactions with the given private keys, and
+// returns the updated transactions along with whether they are all fully signed
+func SignTransactions(params *dagconfig.Params, mnemonics []string, serializedPSTxs [][]byte, ecdsa bool) (
+	updatedSerializedPSTxs [][]byte, areAllTransactionsFullySigned bool, err error) {
+
+	updatedSerializedPSTxs = make([][]byte, len(serializedPSTxs))
+	for i, serializedPSTx := range serializedPSTxs {
+		updatedSerializedPSTxs[i], err = Sign(params, mnemonics, serializedPSTx, ecdsa)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	areAllTransactionsFullySigned = true
+	for _, updatedSerializedPSTx := range updatedSerializedPSTxs {
+		// This is somewhat redundant to check all transactions, but we do that just-in-case
+		isFullySigned, err := IsTransactionFullySigned(updatedSerializedPSTx)
+		if err != nil {
+			return nil, false, err
+		}
+		if !isFullySigned {
+			areAllTransactionsFullySigned = false
+		}
+	}
+
+	return updatedSerializedPSTxs, areAllTransactionsFullySigned, nil
+}
+
 func sign(params *dagconfig.Params, mnemonic string, partiallySignedTransaction *serialization.PartiallySignedTransaction, ecdsa bool) error {
 	if isTransactionFullySigned(partiallySignedTransaction) {
 		return nil
@@ -34,8 +34,20 @@ func CreateUnsignedTransaction(
 	payments []*Payment,
 	selectedUTXOs []*UTXO) (*serialization.PartiallySignedTransaction, error) {
 
+	return CreateUnsignedTransactionWithLockTime(extendedPublicKeys, minimumSignatures, payments, selectedUTXOs, 0)
+}
+
+// CreateUnsignedTransactionWithLockTime creates an unsigned transaction that can't be
+// included in a block before the given lock time. See AntiFeeSnipingLockTime.
+func CreateUnsignedTransactionWithLockTime(
+	extendedPublicKeys []string,
+	minimumSignatures uint32,
+	payments []*Payment,
+	selectedUTXOs []*UTXO,
+	lockTime uint64) (*serialization.PartiallySignedTransaction, error) {
+
 	sortPublicKeys(extendedPublicKeys)
-	return createUnsignedTransaction(extendedPublicKeys, minimumSignatures, payments, selectedUTXOs)
+	return createUnsignedTransaction(extendedPublicKeys, minimumSignatures, payments, selectedUTXOs, lockTime)
 }
 
 func multiSigRedeemScript(extendedPublicKeys []string, minimumSignatures uint32, path string, ecdsa bool) ([]byte, error) {
@@ -94,7 +106,8 @@ func createUnsignedTransaction(
 	extendedPublicKeys []string,
 	minimumSignatures uint32,
 	payments []*Payment,
-	selectedUTXOs []*UTXO) (*serialization.PartiallySignedTransaction, error) {
+	selectedUTXOs []*UTXO,
+	lockTime uint64) (*serialization.PartiallySignedTransaction, error) {
 
 	inputs := make([]*externalapi.DomainTransactionInput, len(selectedUTXOs))
 	partiallySignedInputs := make([]*serialization.PartiallySignedInput, len(selectedUTXOs))
@@ -145,7 +158,7 @@ func createUnsignedTransaction(
 		Version:      constants.MaxTransactionVersion,
 		Inputs:       inputs,
 		Outputs:      outputs,
-		LockTime:     0,
+		LockTime:     lockTime,
 		SubnetworkID: subnetworks.SubnetworkIDNative,
 		Gas:          0,
 		Payload:      nil,
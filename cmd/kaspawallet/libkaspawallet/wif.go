@@ -0,0 +1,99 @@
+package libkaspawallet
+
+import (
+	"strings"
+
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/bip32/base58"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+// compressedWIFFlag is appended to the private key of a WIF string to mark that
+// its public key is serialized in the compressed form, which is always the case in kaspa
+const compressedWIFFlag = 0x01
+
+var wifNetworks = []*dagconfig.Params{
+	&dagconfig.MainnetParams,
+	&dagconfig.TestnetParams,
+	&dagconfig.SimnetParams,
+	&dagconfig.DevnetParams,
+}
+
+// EncodeWIF encodes the given private key in the Wallet Import Format, using the
+// private key version byte of the given network
+func EncodeWIF(params *dagconfig.Params, privateKey []byte) (string, error) {
+	if len(privateKey) != secp256k1.SerializedPrivateKeySize {
+		return "", errors.Errorf("a private key must be %d bytes long, but got %d bytes",
+			secp256k1.SerializedPrivateKeySize, len(privateKey))
+	}
+
+	payload := make([]byte, 0, len(privateKey)+1)
+	payload = append(payload, privateKey...)
+	payload = append(payload, compressedWIFFlag)
+	return base58.CheckEncode(payload, params.PrivateKeyID), nil
+}
+
+// DecodeWIF decodes a private key in the Wallet Import Format, and makes sure
+// it was encoded with the private key version byte of the given network
+func DecodeWIF(params *dagconfig.Params, wif string) ([]byte, error) {
+	payload, version, err := base58.CheckDecode(wif)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed WIF private key")
+	}
+
+	if version != params.PrivateKeyID {
+		return nil, errors.Errorf("the WIF private key has the version byte 0x%02x which belongs to %s, "+
+			"while the wallet is on %s", version, wifNetworkNames(version), params.Name)
+	}
+
+	switch len(payload) {
+	case secp256k1.SerializedPrivateKeySize:
+	case secp256k1.SerializedPrivateKeySize + 1:
+		if payload[secp256k1.SerializedPrivateKeySize] != compressedWIFFlag {
+			return nil, errors.Errorf("the WIF private key has an unknown compression flag 0x%02x",
+				payload[secp256k1.SerializedPrivateKeySize])
+		}
+		payload = payload[:secp256k1.SerializedPrivateKeySize]
+	default:
+		return nil, errors.Errorf("the WIF private key has a payload of %d bytes, while %d or %d bytes are expected",
+			len(payload), secp256k1.SerializedPrivateKeySize, secp256k1.SerializedPrivateKeySize+1)
+	}
+
+	_, err = secp256k1.DeserializeSchnorrPrivateKeyFromSlice(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "the WIF private key is not a valid secp256k1 private key")
+	}
+
+	return payload, nil
+}
+
+func wifNetworkNames(version byte) string {
+	var names []string
+	for _, params := range wifNetworks {
+		if params.PrivateKeyID == version {
+			names = append(names, params.Name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "no known network"
+	}
+	return strings.Join(names, "/")
+}
+
+// PrivateKeyFromMnemonic returns the private key of the address at the given derivation path
+// of a single-signature wallet with the given mnemonic
+func PrivateKeyFromMnemonic(params *dagconfig.Params, mnemonic string, derivationPath string) ([]byte, error) {
+	extendedKey, err := extendedKeyFromMnemonicAndPath(mnemonic, defaultPath(false), params)
+	if err != nil {
+		return nil, err
+	}
+
+	derivedKey, err := extendedKey.DeriveFromPath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return derivedKey.PrivateKey().Serialize()[:], nil
+}
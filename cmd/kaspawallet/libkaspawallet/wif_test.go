@@ -0,0 +1,71 @@
+package libkaspawallet_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+)
+
+func TestWIF(t *testing.T) {
+	privateKey, err := hex.DecodeString("0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d")
+	if err != nil {
+		t.Fatalf("DecodeString: %+v", err)
+	}
+
+	// The mainnet private key version byte is the same as in Bitcoin,
+	// so the well known Bitcoin test vectors apply
+	const compressedWIF = "KwdMAjGmerYanjeui5SHS7JkmpZvVipYvB2LJGU1ZxJwYvP98617"
+	const uncompressedWIF = "5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ"
+
+	wif, err := libkaspawallet.EncodeWIF(&dagconfig.MainnetParams, privateKey)
+	if err != nil {
+		t.Fatalf("EncodeWIF: %+v", err)
+	}
+	if wif != compressedWIF {
+		t.Fatalf("expected WIF %s but got %s", compressedWIF, wif)
+	}
+
+	for _, wif := range []string{compressedWIF, uncompressedWIF} {
+		decodedPrivateKey, err := libkaspawallet.DecodeWIF(&dagconfig.MainnetParams, wif)
+		if err != nil {
+			t.Fatalf("DecodeWIF: %+v", err)
+		}
+		if !bytes.Equal(decodedPrivateKey, privateKey) {
+			t.Fatalf("expected private key %x but got %x", privateKey, decodedPrivateKey)
+		}
+	}
+
+	for _, params := range []*dagconfig.Params{&dagconfig.TestnetParams, &dagconfig.SimnetParams, &dagconfig.DevnetParams} {
+		wif, err := libkaspawallet.EncodeWIF(params, privateKey)
+		if err != nil {
+			t.Fatalf("EncodeWIF: %+v", err)
+		}
+		decodedPrivateKey, err := libkaspawallet.DecodeWIF(params, wif)
+		if err != nil {
+			t.Fatalf("DecodeWIF: %+v", err)
+		}
+		if !bytes.Equal(decodedPrivateKey, privateKey) {
+			t.Fatalf("%s: expected private key %x but got %x", params.Name, privateKey, decodedPrivateKey)
+		}
+	}
+
+	_, err = libkaspawallet.DecodeWIF(&dagconfig.SimnetParams, compressedWIF)
+	if err == nil || !strings.Contains(err.Error(), dagconfig.MainnetParams.Name) {
+		t.Fatalf("expected decoding a mainnet key on simnet to fail with a network mismatch, but got %v", err)
+	}
+
+	corruptedWIF := compressedWIF[:len(compressedWIF)-1] + "8"
+	_, err = libkaspawallet.DecodeWIF(&dagconfig.MainnetParams, corruptedWIF)
+	if err == nil {
+		t.Fatalf("expected decoding a WIF with a bad checksum to fail")
+	}
+
+	_, err = libkaspawallet.EncodeWIF(&dagconfig.MainnetParams, privateKey[1:])
+	if err == nil {
+		t.Fatalf("expected encoding a short private key to fail")
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/client"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/utils"
+)
+
+func listTransactions(conf *listTransactionsConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.ListTransactions(ctx, &pb.ListTransactionsRequest{
+		Cursor: conf.Cursor,
+		Limit:  conf.Limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Transactions (%d):\n", len(response.Transactions))
+	for _, transaction := range response.Transactions {
+		coinbaseSuffix := ""
+		if transaction.IsCoinbase {
+			coinbaseSuffix = " (coinbase)"
+		}
+		fmt.Printf("%s DAA score %d, received KAS %s, spent KAS %s%s\n", transaction.TransactionId,
+			transaction.BlockDaaScore, utils.FormatKas(transaction.Amount), utils.FormatKas(transaction.SpentAmount),
+			coinbaseSuffix)
+		for _, address := range transaction.Addresses {
+			fmt.Printf("\t%s\n", address)
+		}
+	}
+
+	if response.NextCursor != "" {
+		fmt.Printf("\nTo list the next transactions, use '--cursor %s'\n", response.NextCursor)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/client"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/pkg/errors"
+)
+
+func lockUnspent(conf *lockUnspentConfig) error {
+	if !conf.Unlock && len(conf.Outpoints) == 0 {
+		return errors.New("at least one '--outpoint' must be specified")
+	}
+	if conf.Unlock && conf.Persist {
+		return errors.New("'--persist' cannot be used with '--unlock'")
+	}
+
+	outpoints := make([]*pb.Outpoint, len(conf.Outpoints))
+	for i, outpointString := range conf.Outpoints {
+		outpoint, err := parseOutpoint(outpointString)
+		if err != nil {
+			return err
+		}
+		outpoints[i] = outpoint
+	}
+
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	_, err = daemonClient.LockUnspent(ctx, &pb.LockUnspentRequest{
+		Unlock:    conf.Unlock,
+		Outpoints: outpoints,
+		Persist:   conf.Persist,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case conf.Unlock && len(outpoints) == 0:
+		fmt.Println("Unlocked all the outpoints")
+	case conf.Unlock:
+		fmt.Printf("Unlocked %d outpoints\n", len(outpoints))
+	default:
+		fmt.Printf("Locked %d outpoints\n", len(outpoints))
+	}
+	return nil
+}
+
+func listLockUnspent(conf *listLockUnspentConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.ListLockUnspent(ctx, &pb.ListLockUnspentRequest{})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Locked outpoints (%d):\n", len(response.Outpoints))
+	for _, lockedOutpoint := range response.Outpoints {
+		persistedSuffix := ""
+		if lockedOutpoint.IsPersisted {
+			persistedSuffix = " (persisted)"
+		}
+		fmt.Printf("%s:%d%s\n", lockedOutpoint.Outpoint.TransactionId, lockedOutpoint.Outpoint.Index, persistedSuffix)
+	}
+	return nil
+}
+
+func parseOutpoint(outpointString string) (*pb.Outpoint, error) {
+	parts := strings.Split(outpointString, ":")
+	if len(parts) != 2 {
+		return nil, errors.Errorf("malformed outpoint %s, expected <transaction ID>:<index>", outpointString)
+	}
+
+	index, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed outpoint index in %s", outpointString)
+	}
+
+	return &pb.Outpoint{TransactionId: parts[0], Index: uint32(index)}, nil
+}
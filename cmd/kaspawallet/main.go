@@ -42,6 +42,22 @@ func main() {
 		err = bumpFee(config.(*bumpFeeConfig))
 	case bumpFeeUnsignedSubCmd:
 		err = bumpFeeUnsigned(config.(*bumpFeeUnsignedConfig))
+	case importPrivateKeySubCmd:
+		err = importPrivateKey(config.(*importPrivateKeyConfig))
+	case dumpPrivateKeySubCmd:
+		err = dumpPrivateKey(config.(*dumpPrivateKeyConfig))
+	case backupWalletSubCmd:
+		err = backupWallet(config.(*backupWalletConfig))
+	case restoreWalletSubCmd:
+		err = restoreWallet(config.(*restoreWalletConfig))
+	case listTransactionsSubCmd:
+		err = listTransactions(config.(*listTransactionsConfig))
+	case lockUnspentSubCmd:
+		err = lockUnspent(config.(*lockUnspentConfig))
+	case listLockUnspentSubCmd:
+		err = listLockUnspent(config.(*listLockUnspentConfig))
+	case getTransactionSubCmd:
+		err = getTransaction(config.(*getTransactionConfig))
 	default:
 		err = errors.Errorf("Unknown sub-command '%s'\n", subCmd)
 	}
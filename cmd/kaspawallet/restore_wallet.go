@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/client"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/utils"
+	"github.com/pkg/errors"
+)
+
+func restoreWallet(conf *restoreWalletConfig) error {
+	archive, err := os.ReadFile(conf.File)
+	if err != nil {
+		return err
+	}
+
+	if !conf.Yes {
+		err := confirmRestore()
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(conf.Password) == 0 {
+		conf.Password = keys.GetPassword("Password of the backed up wallet:")
+	}
+
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.RestoreWallet(ctx, &pb.RestoreWalletRequest{
+		Archive:  archive,
+		Password: conf.Password,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("The wallet was restored from a backup created at %s (DAA score %d).\n",
+		time.UnixMilli(response.CreatedAt).UTC(), response.DaaScore)
+	fmt.Println("The daemon is rescanning the addresses of the restored wallet.")
+	return nil
+}
+
+func confirmRestore() error {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("This operation will replace the keys file of the wallet daemon with the backed up one. " +
+		"The replaced keys file is kept next to it with the suffix \".before-restore\". " +
+		"Are you sure you want to proceed (y/N)? ")
+	line, err := utils.ReadLine(reader)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+
+	if string(line) != "y" {
+		return errors.Errorf("Restore aborted by user")
+	}
+
+	return nil
+}
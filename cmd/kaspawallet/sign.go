@@ -46,25 +46,10 @@ func sign(conf *signConfig) error {
 		return err
 	}
 
-	updatedPartiallySignedTransactions := make([][]byte, len(partiallySignedTransactions))
-	for i, partiallySignedTransaction := range partiallySignedTransactions {
-		updatedPartiallySignedTransactions[i], err =
-			libkaspawallet.Sign(conf.NetParams(), privateKeys, partiallySignedTransaction, keysFile.ECDSA)
-		if err != nil {
-			return err
-		}
-	}
-
-	areAllTransactionsFullySigned := true
-	for _, updatedPartiallySignedTransaction := range updatedPartiallySignedTransactions {
-		// This is somewhat redundant to check all transactions, but we do that just-in-case
-		isFullySigned, err := libkaspawallet.IsTransactionFullySigned(updatedPartiallySignedTransaction)
-		if err != nil {
-			return err
-		}
-		if !isFullySigned {
-			areAllTransactionsFullySigned = false
-		}
+	updatedPartiallySignedTransactions, areAllTransactionsFullySigned, err :=
+		libkaspawallet.SignTransactions(conf.NetParams(), privateKeys, partiallySignedTransactions, keysFile.ECDSA)
+	if err != nil {
+		return err
 	}
 
 	if areAllTransactionsFullySigned {
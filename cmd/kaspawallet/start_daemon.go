@@ -1,7 +1,47 @@
 package main
 
-import "github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/server"
+import (
+	"strings"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/server"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/pkg/errors"
+)
 
 func startDaemon(conf *startDaemonConfig) error {
-	return server.Start(conf.NetParams(), conf.Listen, conf.RPCServer, conf.KeysFile, conf.Profile, conf.Timeout)
+	consolidationConfig := &server.ConsolidationConfig{
+		MaxUTXOs:   conf.ConsolidateUTXOsAbove,
+		MaxFeeRate: conf.ConsolidateMaxFeeRate,
+		Password:   conf.Password,
+	}
+	if consolidationConfig.MaxUTXOs != 0 && consolidationConfig.Password == "" {
+		consolidationConfig.Password = keys.GetPassword("Password:")
+	}
+
+	// The consolidation only applies to the default wallet, since the password is of its keys file
+	wallets := []*server.WalletConfig{{
+		KeysFilePath:        conf.KeysFile,
+		ConsolidationConfig: consolidationConfig,
+	}}
+	for _, walletString := range conf.Wallets {
+		wallet, err := parseWalletConfig(walletString)
+		if err != nil {
+			return err
+		}
+		wallets = append(wallets, wallet)
+	}
+
+	return server.Start(conf.NetParams(), conf.Listen, conf.RPCServer, wallets, conf.Profile, conf.Timeout, conf.GapLimit,
+		conf.WalletNotify, conf.NotifyMaxProcesses)
+}
+
+func parseWalletConfig(walletString string) (*server.WalletConfig, error) {
+	parts := strings.SplitN(walletString, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, errors.Errorf("wallet %s is not formatted as <name>=<keys file>", walletString)
+	}
+	if strings.Contains(parts[0], "/") {
+		return nil, errors.Errorf("wallet name %s cannot contain '/'", parts[0])
+	}
+	return &server.WalletConfig{Name: parts[0], KeysFilePath: parts[1]}, nil
 }
@@ -33,26 +33,32 @@ func sweep(conf *sweepConfig) error {
 		return err
 	}
 
-	publicKeybytes, err := libkaspawallet.PublicKeyFromPrivateKey(privateKeyBytes)
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
 	if err != nil {
 		return err
 	}
+	defer tearDown()
+
+	return sweepPrivateKey(conf.NetParams(), daemonClient, privateKeyBytes)
+}
 
-	addressPubKey, err := util.NewAddressPublicKey(publicKeybytes, conf.NetParams().Prefix)
+// sweepPrivateKey sends all funds associated with the given schnorr private key
+// to a new address of the wallet under the daemon's control
+func sweepPrivateKey(params *dagconfig.Params, daemonClient pb.KaspawalletdClient, privateKeyBytes []byte) error {
+	publicKeybytes, err := libkaspawallet.PublicKeyFromPrivateKey(privateKeyBytes)
 	if err != nil {
 		return err
 	}
 
-	address, err := util.DecodeAddress(addressPubKey.String(), conf.NetParams().Prefix)
+	addressPubKey, err := util.NewAddressPublicKey(publicKeybytes, params.Prefix)
 	if err != nil {
 		return err
 	}
 
-	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	address, err := util.DecodeAddress(addressPubKey.String(), params.Prefix)
 	if err != nil {
 		return err
 	}
-	defer tearDown()
 
 	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
 	defer cancel()
@@ -84,17 +90,17 @@ func sweep(conf *sweepConfig) error {
 		return err
 	}
 
-	toAddress, err := util.DecodeAddress(newAddressResponse.Address, conf.ActiveNetParams.Prefix)
+	toAddress, err := util.DecodeAddress(newAddressResponse.Address, params.Prefix)
 	if err != nil {
 		return err
 	}
 
-	splitTransactions, err := createSplitTransactionsWithSchnorrPrivteKey(conf.NetParams(), UTXOs, toAddress, feePerInput)
+	splitTransactions, err := createSplitTransactionsWithSchnorrPrivteKey(params, UTXOs, toAddress, feePerInput)
 	if err != nil {
 		return err
 	}
 
-	serializedSplitTransactions, err := signWithSchnorrPrivateKey(conf.NetParams(), privateKeyBytes, splitTransactions)
+	serializedSplitTransactions, err := signWithSchnorrPrivateKey(params, privateKeyBytes, splitTransactions)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultP2PServer            = "localhost"
+	defaultRPCServer            = "localhost"
+	defaultTimeout              = 30 * time.Second
+	defaultReconnects           = 50
+	defaultSlowLorisConnections = 8
+	defaultSlowLorisTimeout     = 150 * time.Second
+)
+
+type configFlags struct {
+	P2PServer            string        `long:"p2pserver" description:"P2P address of the node to stress"`
+	RPCServer            string        `short:"s" long:"rpcserver" description:"RPC server of the node to stress, used to check its state"`
+	Scenarios            string        `long:"scenarios" description:"Comma separated scenarios to run (default: all of them). Use --list-scenarios to list them"`
+	ListScenarios        bool          `short:"l" long:"list-scenarios" description:"List all scenarios and exit"`
+	Timeout              time.Duration `short:"t" long:"timeout" description:"How long to wait for the node to react in every step. Valid time units are {s, m, h}"`
+	Reconnects           int           `long:"reconnects" description:"How many times the rapid-reconnect scenario connects to the node"`
+	SlowLorisConnections int           `long:"slowloris-connections" description:"How many connections the slow-loris scenario opens"`
+	SlowLorisTimeout     time.Duration `long:"slowloris-timeout" description:"How long the node may keep the connections of the slow-loris scenario open. Valid time units are {s, m, h}"`
+	RequireBan           bool          `long:"requireban" description:"Require the node to ban peers that send invalid messages, rather than only disconnect from them. The node must run with --enablebanning"`
+	Encrypt              bool          `long:"encrypt" description:"Encrypt the P2P connections to the node"`
+	config.NetworkFlags
+}
+
+func parseConfig() (*configFlags, error) {
+	cfg := &configFlags{
+		P2PServer:            defaultP2PServer,
+		RPCServer:            defaultRPCServer,
+		Timeout:              defaultTimeout,
+		Reconnects:           defaultReconnects,
+		SlowLorisConnections: defaultSlowLorisConnections,
+		SlowLorisTimeout:     defaultSlowLorisTimeout,
+	}
+	parser := flags.NewParser(cfg, flags.HelpFlag)
+	parser.Usage = "peerstress [OPTIONS]\n\nConnects to a node as a peer, and checks that it handles misbehaving peers correctly." +
+		"\nThe tool exits with a non-zero status if any of the scenarios fails."
+	_, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ListScenarios {
+		return cfg, nil
+	}
+
+	err = cfg.ResolveNetwork(parser)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Timeout <= 0 || cfg.SlowLorisTimeout <= 0 {
+		return nil, errors.New("--timeout and --slowloris-timeout must be positive")
+	}
+	if cfg.Reconnects < 1 || cfg.SlowLorisConnections < 1 {
+		return nil, errors.New("--reconnects and --slowloris-connections must be at least 1")
+	}
+
+	return cfg, nil
+}
+
+// selectedScenarios returns the scenarios that were chosen with --scenarios, in the
+// order they're listed in
+func (cfg *configFlags) selectedScenarios() ([]*scenario, error) {
+	if cfg.Scenarios == "" {
+		return scenarios, nil
+	}
+
+	var selected []*scenario
+	for _, name := range strings.Split(cfg.Scenarios, ",") {
+		scenario, ok := scenarioByName(strings.TrimSpace(name))
+		if !ok {
+			return nil, errors.Errorf("unknown scenario %s. Use --list-scenarios to list them", name)
+		}
+		selected = append(selected, scenario)
+	}
+	return selected, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcclient"
+	"github.com/kaspanet/kaspad/util/network"
+)
+
+// stresser holds what the scenarios need in order to connect to the node and check
+// its state
+type stresser struct {
+	cfg        *configFlags
+	p2pAddress string
+	rpcClient  *rpcclient.RPCClient
+}
+
+func main() {
+	cfg, err := parseConfig()
+	if err != nil {
+		printErrorAndExit(fmt.Sprintf("error parsing command-line arguments: %s", err))
+	}
+	if cfg.ListScenarios {
+		printAllScenarios()
+		return
+	}
+
+	selectedScenarios, err := cfg.selectedScenarios()
+	if err != nil {
+		printErrorAndExit(err.Error())
+	}
+
+	p2pAddress, err := network.NormalizeAddress(cfg.P2PServer, cfg.NetParams().DefaultPort)
+	if err != nil {
+		printErrorAndExit(fmt.Sprintf("error parsing P2P server address: %s", err))
+	}
+	rpcAddress, err := cfg.NetParams().NormalizeRPCServerAddress(cfg.RPCServer)
+	if err != nil {
+		printErrorAndExit(fmt.Sprintf("error parsing RPC server address: %s", err))
+	}
+	rpcClient, err := rpcclient.NewRPCClient(rpcAddress)
+	if err != nil {
+		printErrorAndExit(fmt.Sprintf("error connecting to the RPC server: %s", err))
+	}
+	defer rpcClient.Close()
+	rpcClient.SetTimeout(cfg.Timeout)
+
+	s := &stresser{
+		cfg:        cfg,
+		p2pAddress: p2pAddress,
+		rpcClient:  rpcClient,
+	}
+
+	failedCount := 0
+	for _, scenario := range selectedScenarios {
+		start := time.Now()
+		err := scenario.run(s)
+		duration := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			failedCount++
+			fmt.Printf("FAIL %s (%s): %s\n", scenario.name, duration, err)
+			continue
+		}
+		fmt.Printf("PASS %s (%s)\n", scenario.name, duration)
+	}
+
+	if failedCount > 0 {
+		printErrorAndExit(fmt.Sprintf("%d of %d scenarios failed", failedCount, len(selectedScenarios)))
+	}
+}
+
+func printAllScenarios() {
+	for _, scenario := range scenarios {
+		fmt.Printf("\t%s: %s\n", scenario.name, scenario.description)
+	}
+}
+
+func printErrorAndExit(message string) {
+	fmt.Fprintf(os.Stderr, "%s\n", message)
+	os.Exit(1)
+}
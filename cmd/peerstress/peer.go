@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/network/encryptedtransport"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/id"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/kaspanet/kaspad/version"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// protocolVersion is the P2P protocol version that the peers of the tool advertise
+const protocolVersion = 5
+
+const p2pMaxMessageSize = 1024 * 1024 * 1024 // 1GB
+
+// stressPeer is a P2P connection to the node that sends whatever the scenario asks
+// it to, valid or not. Once the handshake is over, it ignores everything the node
+// sends other than pings.
+type stressPeer struct {
+	connection *grpc.ClientConn
+	stream     protowire.P2P_MessageStreamClient
+	cancel     context.CancelFunc
+
+	// localAddress is the address of the tool in the connection, which is the one the
+	// node bans
+	localAddress net.Addr
+
+	sendLock sync.Mutex
+
+	// disconnected is closed once the node closes the stream
+	disconnected chan struct{}
+}
+
+// connectPeer connects to the P2P server of the node and performs the handshake with it
+func (s *stresser) connectPeer() (*stressPeer, error) {
+	peer := &stressPeer{disconnected: make(chan struct{})}
+
+	dialCtx, cancelDial := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancelDial()
+	dial := func(ctx context.Context, address string) (net.Conn, error) {
+		connection, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+		if err != nil {
+			return nil, err
+		}
+		peer.localAddress = connection.LocalAddr()
+		if !s.cfg.Encrypt {
+			return connection, nil
+		}
+		encryptedConnection, err := encryptedtransport.Initiate(ctx, connection, s.cfg.NetParams().Net)
+		if err != nil {
+			connection.Close()
+			return nil, err
+		}
+		return encryptedConnection, nil
+	}
+	connection, err := grpc.DialContext(dialCtx, s.p2pAddress, grpc.WithInsecure(), grpc.WithBlock(),
+		grpc.WithContextDialer(dial))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error connecting to %s", s.p2pAddress)
+	}
+	peer.connection = connection
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	peer.cancel = cancel
+	peer.stream, err = protowire.NewP2PClient(connection).MessageStream(streamCtx, grpc.UseCompressor(gzip.Name),
+		grpc.MaxCallRecvMsgSize(p2pMaxMessageSize), grpc.MaxCallSendMsgSize(p2pMaxMessageSize))
+	if err != nil {
+		peer.close()
+		return nil, errors.Wrapf(err, "error opening a message stream to %s", s.p2pAddress)
+	}
+
+	handshakeErrChan := make(chan error, 1)
+	go func() {
+		handshakeErrChan <- peer.handshake(s)
+	}()
+	select {
+	case err = <-handshakeErrChan:
+	case <-time.After(s.cfg.Timeout):
+		err = errors.Errorf("the handshake didn't finish after %s", s.cfg.Timeout)
+	}
+	if err != nil {
+		peer.close()
+		return nil, err
+	}
+
+	go peer.receiveLoop()
+	return peer, nil
+}
+
+// handshake sends the version of the tool and a verack for the version of the node,
+// waits for both of them from the node, and then exchanges ready messages with it,
+// after which the node starts handling whatever the tool sends
+func (p *stressPeer) handshake(s *stresser) error {
+	peerID, err := id.GenerateID()
+	if err != nil {
+		return err
+	}
+	msgVersion := appmessage.NewMsgVersion(nil, peerID, s.cfg.NetParams().Name, nil, protocolVersion)
+	msgVersion.AddUserAgent("peerstress", version.Version())
+	if s.cfg.Encrypt {
+		// Otherwise the node suspects the connection of being downgraded
+		msgVersion.AddService(appmessage.SFNodeEncryptedTransport)
+	}
+	err = p.sendAppMessage(msgVersion)
+	if err != nil {
+		return err
+	}
+
+	isVersionReceived, isVerAckReceived, isReadyReceived := false, false, false
+	for !isVersionReceived || !isVerAckReceived || !isReadyReceived {
+		message, err := p.stream.Recv()
+		if err != nil {
+			return errors.Wrap(err, "the node disconnected during the handshake")
+		}
+		switch message.Payload.(type) {
+		case *protowire.KaspadMessage_Version:
+			isVersionReceived = true
+			err = p.sendAppMessage(appmessage.NewMsgVerAck())
+			if err != nil {
+				return err
+			}
+		case *protowire.KaspadMessage_Verack:
+			isVerAckReceived = true
+		case *protowire.KaspadMessage_Ready:
+			isReadyReceived = true
+			err = p.sendAppMessage(appmessage.NewMsgReady())
+			if err != nil {
+				return err
+			}
+		case *protowire.KaspadMessage_Reject:
+			return errors.Errorf("the node rejected the handshake: %s", message.GetReject().Reason)
+		}
+	}
+	return nil
+}
+
+func (p *stressPeer) receiveLoop() {
+	defer close(p.disconnected)
+	for {
+		message, err := p.stream.Recv()
+		if err != nil {
+			return
+		}
+		if ping, ok := message.Payload.(*protowire.KaspadMessage_Ping); ok {
+			// Failing to send is noticed once the stream is closed
+			_ = p.sendAppMessage(appmessage.NewMsgPong(ping.Ping.Nonce))
+		}
+	}
+}
+
+func (p *stressPeer) sendAppMessage(message appmessage.Message) error {
+	protoMessage, err := protowire.FromAppMessage(message)
+	if err != nil {
+		return err
+	}
+	return p.send(protoMessage)
+}
+
+// send sends message as is, so it may be one that kaspad would never send
+func (p *stressPeer) send(message *protowire.KaspadMessage) error {
+	p.sendLock.Lock()
+	defer p.sendLock.Unlock()
+
+	err := p.stream.Send(message)
+	if err != nil {
+		return errors.Wrapf(err, "error sending %T", message.Payload)
+	}
+	return nil
+}
+
+// waitForDisconnection returns whether the node closed the stream within timeout
+func (p *stressPeer) waitForDisconnection(timeout time.Duration) bool {
+	select {
+	case <-p.disconnected:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (p *stressPeer) close() {
+	p.cancel()
+	p.connection.Close()
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/pkg/errors"
+)
+
+type scenario struct {
+	name        string
+	description string
+	run         func(s *stresser) error
+}
+
+var scenarios = []*scenario{
+	{
+		name:        "huge-inv",
+		description: "Announces more transactions in one inv than the protocol allows, which must get the peer disconnected",
+		run:         runHugeInv,
+	},
+	{
+		name:        "malformed-headers",
+		description: "Sends block headers with a malformed hash, which must get the peer disconnected",
+		run:         runMalformedHeaders,
+	},
+	{
+		name: "slow-loris",
+		description: "Opens connections that send their first bytes too slowly to ever finish connecting, which the " +
+			"node must close, and checks that peers can still connect in the meantime",
+		run: runSlowLoris,
+	},
+	{
+		name: "rapid-reconnect",
+		description: "Connects and disconnects over and over, and checks that the node stays responsive and " +
+			"forgets the connections",
+		run: runRapidReconnect,
+	},
+}
+
+func scenarioByName(name string) (*scenario, bool) {
+	for _, scenario := range scenarios {
+		if scenario.name == name {
+			return scenario, true
+		}
+	}
+	return nil, false
+}
+
+func runHugeInv(s *stresser) error {
+	peer, err := s.connectPeer()
+	if err != nil {
+		return err
+	}
+	defer peer.close()
+
+	ids := make([]*protowire.TransactionId, appmessage.MaxInvPerTxInvMsg+1)
+	for i := range ids {
+		idBytes := make([]byte, 32)
+		binary.LittleEndian.PutUint64(idBytes, uint64(i))
+		ids[i] = &protowire.TransactionId{Bytes: idBytes}
+	}
+	err = peer.send(&protowire.KaspadMessage{Payload: &protowire.KaspadMessage_InvTransactions{
+		InvTransactions: &protowire.InvTransactionsMessage{Ids: ids},
+	}})
+	if err != nil {
+		return err
+	}
+
+	return s.expectMisbehaviorHandled(peer)
+}
+
+func runMalformedHeaders(s *stresser) error {
+	peer, err := s.connectPeer()
+	if err != nil {
+		return err
+	}
+	defer peer.close()
+
+	genesisHeader := appmessage.DomainBlockHeaderToBlockHeader(s.cfg.NetParams().GenesisBlock.Header)
+	message, err := protowire.FromAppMessage(appmessage.NewBlockHeadersMessage(
+		[]*appmessage.MsgBlockHeader{genesisHeader}))
+	if err != nil {
+		return err
+	}
+	hashMerkleRoot := message.GetBlockHeaders().BlockHeaders[0].HashMerkleRoot
+	hashMerkleRoot.Bytes = hashMerkleRoot.Bytes[:len(hashMerkleRoot.Bytes)-1]
+	err = peer.send(message)
+	if err != nil {
+		return err
+	}
+
+	return s.expectMisbehaviorHandled(peer)
+}
+
+// slowLorisInterval is how long the connections of the slow-loris scenario wait between
+// the bytes they send. It's long enough that the HTTP/2 preface isn't over before gRPC
+// gives up on the connection.
+const slowLorisInterval = 10 * time.Second
+
+// slowLorisPreface is what gRPC clients send first, which the slow-loris scenario
+// sends a byte at a time
+var slowLorisPreface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+func runSlowLoris(s *stresser) error {
+	start := time.Now()
+	closedChan := make(chan error, s.cfg.SlowLorisConnections)
+	var connections []net.Conn
+	var connectionsLock sync.Mutex
+	defer func() {
+		connectionsLock.Lock()
+		defer connectionsLock.Unlock()
+		for _, connection := range connections {
+			connection.Close()
+		}
+	}()
+
+	for i := 0; i < s.cfg.SlowLorisConnections; i++ {
+		connection, err := net.DialTimeout("tcp", s.p2pAddress, s.cfg.Timeout)
+		if err != nil {
+			return errors.Wrapf(err, "error connecting to %s", s.p2pAddress)
+		}
+		connectionsLock.Lock()
+		connections = append(connections, connection)
+		connectionsLock.Unlock()
+
+		go func() {
+			for _, b := range slowLorisPreface {
+				_, err := connection.Write([]byte{b})
+				if err != nil {
+					return
+				}
+				time.Sleep(slowLorisInterval)
+			}
+		}()
+		go func() {
+			// The node never sends anything before the preface is over, so
+			// reading only ends once it closes the connection
+			_, err := io.ReadAll(connection)
+			closedChan <- err
+		}()
+	}
+
+	peer, err := s.connectPeer()
+	if err != nil {
+		return errors.Wrapf(err, "error connecting while %d slow connections are open",
+			s.cfg.SlowLorisConnections)
+	}
+	peer.close()
+
+	deadline := time.After(s.cfg.SlowLorisTimeout - time.Since(start))
+	for i := 0; i < s.cfg.SlowLorisConnections; i++ {
+		select {
+		case <-closedChan:
+		case <-deadline:
+			return errors.Errorf("%d of %d slow connections are still open after %s",
+				s.cfg.SlowLorisConnections-i, s.cfg.SlowLorisConnections, s.cfg.SlowLorisTimeout)
+		}
+	}
+	return nil
+}
+
+func runRapidReconnect(s *stresser) error {
+	peerCountBefore, err := s.connectedPeerCount()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < s.cfg.Reconnects; i++ {
+		peer, err := s.connectPeer()
+		if err != nil {
+			return errors.Wrapf(err, "error in connection %d of %d", i+1, s.cfg.Reconnects)
+		}
+		peer.close()
+	}
+
+	_, err = s.rpcClient.GetInfo()
+	if err != nil {
+		return errors.Wrap(err, "the node doesn't respond to RPC after the reconnects")
+	}
+	peer, err := s.connectPeer()
+	if err != nil {
+		return errors.Wrap(err, "error connecting after the reconnects")
+	}
+	peer.close()
+
+	deadline := time.Now().Add(s.cfg.Timeout)
+	for {
+		peerCount, err := s.connectedPeerCount()
+		if err != nil {
+			return err
+		}
+		if peerCount <= peerCountBefore {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("the node is still connected to %d peers after %s, while it was connected "+
+				"to %d before the reconnects", peerCount, s.cfg.Timeout, peerCountBefore)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// expectMisbehaviorHandled checks that the node disconnects from peer, and, with
+// --requireban, that it bans it. Peers that are banned are unbanned right away, so
+// that the scenarios that follow can connect.
+func (s *stresser) expectMisbehaviorHandled(peer *stressPeer) error {
+	if !peer.waitForDisconnection(s.cfg.Timeout) {
+		return errors.Errorf("the node is still connected after %s", s.cfg.Timeout)
+	}
+
+	isBanned, err := s.isBanned(peer)
+	if err != nil {
+		return err
+	}
+	if isBanned {
+		_, err := s.rpcClient.Unban(s.bannedIP(peer))
+		if err != nil {
+			return errors.Wrap(err, "error unbanning the tool")
+		}
+	}
+	if s.cfg.RequireBan && !isBanned {
+		return errors.New("the node disconnected, but didn't ban the peer")
+	}
+	return nil
+}
+
+func (s *stresser) isBanned(peer *stressPeer) (bool, error) {
+	response, err := s.rpcClient.GetPeerAddresses()
+	if err != nil {
+		return false, err
+	}
+	ip := net.ParseIP(s.bannedIP(peer))
+	for _, bannedAddress := range response.BannedAddresses {
+		host, _, err := net.SplitHostPort(bannedAddress.Addr)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		if ip.Equal(net.ParseIP(host)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *stresser) bannedIP(peer *stressPeer) string {
+	return peer.localAddress.(*net.TCPAddr).IP.String()
+}
+
+func (s *stresser) connectedPeerCount() (int, error) {
+	response, err := s.rpcClient.GetConnectedPeerInfo()
+	if err != nil {
+		return 0, err
+	}
+	return len(response.Infos), nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"github.com/jessevdk/go-flags"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/pkg/errors"
+)
+
+type configFlags struct {
+	KeysFile                string `long:"keys-file" short:"f" description:"Keys file location (default: the kaspawallet keys file of the network)"`
+	Password                string `long:"password" short:"p" description:"Wallet password"`
+	MnemonicFile            string `long:"mnemonic-file" description:"A file containing the mnemonic of a single signature schnorr wallet to sign with, instead of a keys file"`
+	Transaction             string `long:"transaction" short:"t" description:"The unsigned transaction(s) to sign on (encoded in hex)"`
+	TransactionFile         string `long:"transaction-file" short:"F" description:"The file containing the unsigned transaction(s) to sign on (encoded in hex)"`
+	UnsignedTransactionFile string `long:"unsigned-transaction-file" description:"A JSON file describing the unsigned transaction and the UTXOs it spends, instead of --transaction"`
+	config.NetworkFlags
+}
+
+func parseConfig() (*configFlags, error) {
+	cfg := &configFlags{}
+	parser := flags.NewParser(cfg, flags.PrintErrors|flags.HelpFlag)
+	_, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	err = cfg.ResolveNetwork(parser)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionSources := 0
+	for _, transactionSource := range []string{cfg.Transaction, cfg.TransactionFile, cfg.UnsignedTransactionFile} {
+		if transactionSource != "" {
+			transactionSources++
+		}
+	}
+	if transactionSources != 1 {
+		return nil, errors.Errorf("Exactly one of --transaction, --transaction-file " +
+			"or --unsigned-transaction-file is required")
+	}
+
+	if cfg.MnemonicFile != "" && cfg.KeysFile != "" {
+		return nil, errors.Errorf("Both --mnemonic-file and --keys-file cannot be passed at the same time")
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/server"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/serialization"
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+)
+
+func main() {
+	cfg, err := parseConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
+	err = signTransactions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%+v\n", err)
+		os.Exit(1)
+	}
+}
+
+// signer holds the keys the transactions are signed with
+type signer struct {
+	mnemonics          []string
+	extendedPublicKeys []string
+	minimumSignatures  uint32
+	ecdsa              bool
+}
+
+func signTransactions(cfg *configFlags) error {
+	signer, err := readSigner(cfg)
+	if err != nil {
+		return err
+	}
+
+	partiallySignedTransactions, err := readTransactions(cfg, signer)
+	if err != nil {
+		return err
+	}
+
+	signedTransactions, areAllTransactionsFullySigned, err := libkaspawallet.SignTransactions(
+		cfg.NetParams(), signer.mnemonics, partiallySignedTransactions, signer.ecdsa)
+	if err != nil {
+		return err
+	}
+
+	if areAllTransactionsFullySigned {
+		fmt.Fprintln(os.Stderr, "The transaction is signed and ready to broadcast")
+	} else {
+		fmt.Fprintln(os.Stderr, "Successfully signed transaction. It still requires the signatures of other cosigners")
+	}
+
+	fmt.Println(server.EncodeTransactionsToHex(signedTransactions))
+	return nil
+}
+
+func readSigner(cfg *configFlags) (*signer, error) {
+	if cfg.MnemonicFile != "" {
+		mnemonicBytes, err := ioutil.ReadFile(cfg.MnemonicFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not read the mnemonic from %s", cfg.MnemonicFile)
+		}
+		mnemonic := strings.TrimSpace(string(mnemonicBytes))
+		if !bip39.IsMnemonicValid(mnemonic) {
+			return nil, errors.Errorf("The mnemonic in %s is invalid", cfg.MnemonicFile)
+		}
+
+		extendedPublicKey, err := libkaspawallet.MasterPublicKeyFromMnemonic(cfg.NetParams(), mnemonic, false)
+		if err != nil {
+			return nil, err
+		}
+		return &signer{
+			mnemonics:          []string{mnemonic},
+			extendedPublicKeys: []string{extendedPublicKey},
+			minimumSignatures:  1,
+			ecdsa:              false,
+		}, nil
+	}
+
+	keysFile, err := keys.ReadKeysFile(cfg.NetParams(), cfg.KeysFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Password) == 0 {
+		cfg.Password = keys.GetPassword("Password:")
+	}
+	mnemonics, err := keysFile.DecryptMnemonics(cfg.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signer{
+		mnemonics:          mnemonics,
+		extendedPublicKeys: keysFile.ExtendedPublicKeys,
+		minimumSignatures:  keysFile.MinimumSignatures,
+		ecdsa:              keysFile.ECDSA,
+	}, nil
+}
+
+// readTransactions returns the serialized partially signed transactions to sign
+func readTransactions(cfg *configFlags, signer *signer) ([][]byte, error) {
+	if cfg.UnsignedTransactionFile != "" {
+		partiallySignedTransaction, err := readUnsignedTransactionFile(cfg.NetParams(), cfg.UnsignedTransactionFile, signer)
+		if err != nil {
+			return nil, err
+		}
+		serializedPartiallySignedTransaction, err :=
+			serialization.SerializePartiallySignedTransaction(partiallySignedTransaction)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{serializedPartiallySignedTransaction}, nil
+	}
+
+	transactionsHex := cfg.Transaction
+	if cfg.TransactionFile != "" {
+		transactionHexBytes, err := ioutil.ReadFile(cfg.TransactionFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not read hex from %s", cfg.TransactionFile)
+		}
+		transactionsHex = strings.TrimSpace(string(transactionHexBytes))
+	}
+	return server.DecodeTransactionsFromHex(transactionsHex)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/serialization"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/utils"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionid"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+)
+
+// unsignedTransactionJSON describes an unsigned transaction along with the
+// metadata of the UTXOs it spends, so that it can be signed on a machine
+// without access to a node. All the amounts are in sompi.
+type unsignedTransactionJSON struct {
+	Inputs  []*unsignedTransactionInputJSON  `json:"inputs"`
+	Outputs []*unsignedTransactionOutputJSON `json:"outputs"`
+}
+
+// unsignedTransactionInputJSON describes a spent UTXO. Its script public key
+// is derived from the keys of the signer by the derivation path.
+type unsignedTransactionInputJSON struct {
+	TransactionID  string `json:"transactionId"`
+	Index          uint32 `json:"index"`
+	Amount         uint64 `json:"amount"`
+	DerivationPath string `json:"derivationPath"`
+}
+
+type unsignedTransactionOutputJSON struct {
+	Address string `json:"address"`
+	Amount  uint64 `json:"amount"`
+}
+
+func readUnsignedTransactionFile(params *dagconfig.Params, path string, signer *signer) (
+	*serialization.PartiallySignedTransaction, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	unsignedTransaction := &unsignedTransactionJSON{}
+	err = decoder.Decode(unsignedTransaction)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not parse the unsigned transaction in %s", path)
+	}
+
+	return signer.unsignedTransaction(params, unsignedTransaction)
+}
+
+// unsignedTransaction converts the given unsigned transaction to a partially signed
+// transaction of the signer, and prints the payments it makes for review
+func (s *signer) unsignedTransaction(params *dagconfig.Params, unsignedTransaction *unsignedTransactionJSON) (
+	*serialization.PartiallySignedTransaction, error) {
+
+	if len(unsignedTransaction.Inputs) == 0 {
+		return nil, errors.Errorf("The unsigned transaction has no inputs")
+	}
+
+	utxos := make([]*libkaspawallet.UTXO, len(unsignedTransaction.Inputs))
+	inputsSompi := uint64(0)
+	for i, input := range unsignedTransaction.Inputs {
+		transactionID, err := transactionid.FromString(input.TransactionID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Input %d has an invalid transaction ID", i)
+		}
+
+		address, err := libkaspawallet.Address(params, s.extendedPublicKeys, s.minimumSignatures, input.DerivationPath, s.ecdsa)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Input %d has an invalid derivation path", i)
+		}
+		scriptPublicKey, err := txscript.PayToAddrScript(address)
+		if err != nil {
+			return nil, err
+		}
+
+		utxos[i] = &libkaspawallet.UTXO{
+			Outpoint: externalapi.NewDomainOutpoint(transactionID, input.Index),
+			UTXOEntry: utxo.NewUTXOEntry(
+				input.Amount,
+				scriptPublicKey,
+				false, // This is a fake value, because it's irrelevant for the signature
+				0,     // This is a fake value, because it's irrelevant for the signature
+			),
+			DerivationPath: input.DerivationPath,
+		}
+		inputsSompi += input.Amount
+	}
+
+	payments := make([]*libkaspawallet.Payment, len(unsignedTransaction.Outputs))
+	outputsSompi := uint64(0)
+	for i, output := range unsignedTransaction.Outputs {
+		address, err := util.DecodeAddress(output.Address, params.Prefix)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Output %d has an invalid address", i)
+		}
+
+		payments[i] = &libkaspawallet.Payment{
+			Address: address,
+			Amount:  output.Amount,
+		}
+		outputsSompi += output.Amount
+	}
+	if outputsSompi > inputsSompi {
+		return nil, errors.Errorf("The outputs of the transaction spend %s KAS, but its inputs only have %s KAS",
+			utils.FormatKas(outputsSompi), utils.FormatKas(inputsSompi))
+	}
+
+	for _, payment := range payments {
+		fmt.Fprintf(os.Stderr, "Sending %s KAS to %s\n", utils.FormatKas(payment.Amount), payment.Address)
+	}
+	fmt.Fprintf(os.Stderr, "Fee: %s KAS\n", utils.FormatKas(inputsSompi-outputsSompi))
+
+	return libkaspawallet.CreateUnsignedTransaction(s.extendedPublicKeys, s.minimumSignatures, payments, utxos)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/serialization"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+)
+
+func TestSignUnsignedTransaction(t *testing.T) {
+	params := &dagconfig.SimnetParams
+	mnemonic, err := libkaspawallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+	extendedPublicKey, err := libkaspawallet.MasterPublicKeyFromMnemonic(params, mnemonic, false)
+	if err != nil {
+		t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+	}
+	testSigner := &signer{
+		mnemonics:          []string{mnemonic},
+		extendedPublicKeys: []string{extendedPublicKey},
+		minimumSignatures:  1,
+	}
+
+	recipientAddress, err := libkaspawallet.Address(params, testSigner.extendedPublicKeys, 1, "m/0/5", false)
+	if err != nil {
+		t.Fatalf("Address: %+v", err)
+	}
+	unsignedTransaction := &unsignedTransactionJSON{
+		Inputs: []*unsignedTransactionInputJSON{
+			{
+				TransactionID:  "0101010101010101010101010101010101010101010101010101010101010101",
+				Index:          0,
+				Amount:         100_000,
+				DerivationPath: "m/0/0",
+			},
+			{
+				TransactionID:  "0202020202020202020202020202020202020202020202020202020202020202",
+				Index:          3,
+				Amount:         50_000,
+				DerivationPath: "m/1/2",
+			},
+		},
+		Outputs: []*unsignedTransactionOutputJSON{
+			{Address: recipientAddress.String(), Amount: 140_000},
+		},
+	}
+
+	partiallySignedTransaction, err := testSigner.unsignedTransaction(params, unsignedTransaction)
+	if err != nil {
+		t.Fatalf("unsignedTransaction: %+v", err)
+	}
+	serializedPartiallySignedTransaction, err := serialization.SerializePartiallySignedTransaction(partiallySignedTransaction)
+	if err != nil {
+		t.Fatalf("SerializePartiallySignedTransaction: %+v", err)
+	}
+
+	signedTransactions, areAllTransactionsFullySigned, err := libkaspawallet.SignTransactions(
+		params, testSigner.mnemonics, [][]byte{serializedPartiallySignedTransaction}, false)
+	if err != nil {
+		t.Fatalf("SignTransactions: %+v", err)
+	}
+	if !areAllTransactionsFullySigned {
+		t.Fatalf("Expected the transaction to be fully signed")
+	}
+
+	transaction, err := libkaspawallet.ExtractTransaction(signedTransactions[0], false)
+	if err != nil {
+		t.Fatalf("ExtractTransaction: %+v", err)
+	}
+	sighashReusedValues := &consensushashing.SighashReusedValues{}
+	for i, input := range transaction.Inputs {
+		engine, err := txscript.NewEngine(input.UTXOEntry.ScriptPublicKey(), transaction, i, txscript.ScriptNoFlags,
+			txscript.NewSigCache(10), txscript.NewSigCacheECDSA(10), sighashReusedValues)
+		if err != nil {
+			t.Fatalf("NewEngine: %+v", err)
+		}
+		err = engine.Execute()
+		if err != nil {
+			t.Fatalf("The signature of input %d is invalid: %+v", i, err)
+		}
+	}
+
+	unsignedTransaction.Outputs[0].Amount = 150_001
+	_, err = testSigner.unsignedTransaction(params, unsignedTransaction)
+	if err == nil {
+		t.Fatalf("Expected an error for a transaction spending more than its inputs")
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"runtime"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/pkg/errors"
+)
+
+type configFlags struct {
+	Prefix  string `long:"prefix" short:"p" description:"The address should start with this prefix, with or without the network prefix (eg. kaspa:qqkas)"`
+	Regex   string `long:"regex" short:"r" description:"The address, without the network prefix, should match this regular expression"`
+	Workers int    `long:"workers" short:"w" description:"The number of keys to generate in parallel (default: the number of CPUs)"`
+	ECDSA   bool   `long:"ecdsa" description:"Generate an ECDSA key pair instead of a Schnorr one"`
+	config.NetworkFlags
+}
+
+func parseConfig() (*configFlags, error) {
+	cfg := &configFlags{
+		Workers: runtime.NumCPU(),
+	}
+	parser := flags.NewParser(cfg, flags.PrintErrors|flags.HelpFlag)
+	_, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	err = cfg.ResolveNetwork(parser)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Prefix == "" && cfg.Regex == "" {
+		return nil, errors.Errorf("At least one of --prefix or --regex is required")
+	}
+	if cfg.Workers < 1 {
+		return nil, errors.Errorf("--workers must be at least 1")
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kaspanet/kaspad/util"
+)
+
+func main() {
+	cfg, err := parseConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
+	addressPrefix := cfg.NetParams().Prefix
+	matcher, err := newAddressMatcher(cfg.Prefix, cfg.Regex, addressPrefix, cfg.ECDSA)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
+	if matcher.expectedAttempts > 0 {
+		fmt.Printf("Expecting to generate %.0f keys to find a match\n", matcher.expectedAttempts)
+	}
+	fmt.Printf("Searching with %d workers\n", cfg.Workers)
+
+	result, err := search(matcher, addressPrefix, cfg.ECDSA, cfg.Workers, printProgress(matcher))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%+v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Private key: %x\n", result.privateKey)
+	fmt.Printf("Address: %s\n", result.address)
+}
+
+func newAddress(publicKey []byte, addressPrefix util.Bech32Prefix, ecdsa bool) (util.Address, error) {
+	if ecdsa {
+		return util.NewAddressPublicKeyECDSA(publicKey, addressPrefix)
+	}
+	return util.NewAddressPublicKey(publicKey, addressPrefix)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+)
+
+// bech32Charset is the alphabet addresses are encoded with, in the order of
+// the values of the characters. It doesn't contain "1", "b", "i" and "o".
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// addressMatcher matches the part of an address that follows the network
+// prefix and the separator, referred to as its payload
+type addressMatcher struct {
+	prefix string
+	regex  *regexp.Regexp
+
+	// expectedAttempts is the expected number of keys to generate until one
+	// matches the prefix, or 0 if it's unknown since a regex is used
+	expectedAttempts float64
+}
+
+func newAddressMatcher(prefix string, regex string, addressPrefix util.Bech32Prefix, ecdsa bool) (*addressMatcher, error) {
+	matcher := &addressMatcher{}
+
+	if prefix != "" {
+		prefix = strings.ToLower(prefix)
+		if separatorIndex := strings.IndexByte(prefix, ':'); separatorIndex != -1 {
+			if prefix[:separatorIndex] != addressPrefix.String() {
+				return nil, errors.Errorf("The prefix %s is not of the %s network", prefix, addressPrefix)
+			}
+			prefix = prefix[separatorIndex+1:]
+		}
+		for _, char := range prefix {
+			if !strings.ContainsRune(bech32Charset, char) {
+				return nil, errors.Errorf("Addresses can't contain %q. The allowed characters are %s",
+					char, bech32Charset)
+			}
+		}
+
+		expectedAttempts, err := expectedAttempts(prefix, ecdsa)
+		if err != nil {
+			return nil, err
+		}
+		matcher.prefix = prefix
+		matcher.expectedAttempts = expectedAttempts
+	}
+
+	if regex != "" {
+		compiledRegex, err := regexp.Compile(regex)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid regex %s", regex)
+		}
+		matcher.regex = compiledRegex
+		matcher.expectedAttempts = 0
+	}
+
+	return matcher, nil
+}
+
+func (m *addressMatcher) match(address string) bool {
+	payload := address[strings.IndexByte(address, ':')+1:]
+	if !strings.HasPrefix(payload, m.prefix) {
+		return false
+	}
+	return m.regex == nil || m.regex.MatchString(payload)
+}
+
+// payloadBit is a bit of the encoded payload of an address. Bits that are
+// the same in all the addresses of a type are known.
+type payloadBit struct {
+	isKnown bool
+	value   byte
+}
+
+// knownPayloadBits returns the leading bits of the payload of an address of
+// the given type, up to the last bit that is the same in all such addresses
+func knownPayloadBits(ecdsa bool) []payloadBit {
+	var bits []payloadBit
+	appendByte := func(b byte, knownBitCount int) {
+		for i := 0; i < 8; i++ {
+			bits = append(bits, payloadBit{isKnown: i < knownBitCount, value: (b >> (7 - i)) & 1})
+		}
+	}
+
+	// The payload starts with the address version, which is 0 for Schnorr public keys
+	// and 1 for ECDSA ones. The rest of a Schnorr public key is random, while a
+	// compressed ECDSA public key starts with either 0x02 or 0x03.
+	if !ecdsa {
+		appendByte(0x00, 8)
+		return bits
+	}
+	appendByte(0x01, 8)
+	appendByte(0x02, 7)
+	return bits
+}
+
+// expectedAttempts returns the expected number of keys to generate until the
+// payload of the address of one starts with the given prefix, or an error if
+// no address of the given type starts with it
+func expectedAttempts(prefix string, ecdsa bool) (float64, error) {
+	knownBits := knownPayloadBits(ecdsa)
+	attempts := 1.0
+	for i, char := range prefix {
+		characterAttempts, ok := characterAttempts(knownBits, i, char)
+		if !ok {
+			position := "first character"
+			if i > 0 {
+				position = fmt.Sprintf("character after %s", prefix[:i])
+			}
+			return 0, errors.Errorf("%s addresses can't start with %s. The %s must be one of %s",
+				addressType(ecdsa), prefix[:i+1], position, possibleCharacters(knownBits, i))
+		}
+		attempts *= characterAttempts
+	}
+	return attempts, nil
+}
+
+// characterAttempts returns the expected number of keys to generate until the
+// character at the given index of the payload of an address is char, or false
+// if it never is
+func characterAttempts(knownBits []payloadBit, index int, char rune) (float64, bool) {
+	value := strings.IndexRune(bech32Charset, char)
+	attempts := 1.0
+	for i := 0; i < 5; i++ {
+		bitIndex := index*5 + i
+		if bitIndex >= len(knownBits) || !knownBits[bitIndex].isKnown {
+			attempts *= 2
+			continue
+		}
+		if knownBits[bitIndex].value != byte(value>>(4-i))&1 {
+			return 0, false
+		}
+	}
+	return attempts, true
+}
+
+// possibleCharacters returns the characters that may be at the given index
+// of the payload of an address
+func possibleCharacters(knownBits []payloadBit, index int) string {
+	var characters []string
+	for _, char := range bech32Charset {
+		if _, ok := characterAttempts(knownBits, index, char); ok {
+			characters = append(characters, string(char))
+		}
+	}
+	return strings.Join(characters, ", ")
+}
+
+func addressType(ecdsa bool) string {
+	if ecdsa {
+		return "ECDSA"
+	}
+	return "Schnorr"
+}
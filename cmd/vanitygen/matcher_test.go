@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/util"
+)
+
+func TestExpectedAttempts(t *testing.T) {
+	tests := []struct {
+		prefix           string
+		ecdsa            bool
+		expectedAttempts float64
+		expectsError     bool
+	}{
+		{prefix: "q", expectedAttempts: 1},
+		{prefix: "qq", expectedAttempts: 4},
+		{prefix: "qrk", expectedAttempts: 128},
+		{prefix: "p", expectsError: true},
+		{prefix: "qy", expectsError: true},
+		{prefix: "qyp", ecdsa: true, expectedAttempts: 1},
+		{prefix: "qypa", ecdsa: true, expectedAttempts: 32},
+		{prefix: "qq", ecdsa: true, expectsError: true},
+		{prefix: "qyq", ecdsa: true, expectsError: true},
+	}
+	for _, test := range tests {
+		attempts, err := expectedAttempts(test.prefix, test.ecdsa)
+		if test.expectsError {
+			if err == nil {
+				t.Errorf("expected an error for the prefix %s (ecdsa: %t)", test.prefix, test.ecdsa)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expectedAttempts(%s, %t): %+v", test.prefix, test.ecdsa, err)
+			continue
+		}
+		if attempts != test.expectedAttempts {
+			t.Errorf("expected %.0f attempts for the prefix %s (ecdsa: %t) but got %.0f",
+				test.expectedAttempts, test.prefix, test.ecdsa, attempts)
+		}
+	}
+}
+
+// TestPossiblePrefixes makes sure that the generated addresses only start with the
+// characters expectedAttempts considers possible
+func TestPossiblePrefixes(t *testing.T) {
+	for _, ecdsa := range []bool{false, true} {
+		for i := 0; i < 100; i++ {
+			_, publicKey, err := libkaspawallet.CreateKeyPair(ecdsa)
+			if err != nil {
+				t.Fatalf("CreateKeyPair: %+v", err)
+			}
+			address, err := newAddress(publicKey, util.Bech32PrefixKaspa, ecdsa)
+			if err != nil {
+				t.Fatalf("newAddress: %+v", err)
+			}
+			payload := strings.TrimPrefix(address.String(), "kaspa:")
+			_, err = expectedAttempts(payload[:4], ecdsa)
+			if err != nil {
+				t.Fatalf("the prefix of the generated address %s is considered impossible: %s", address, err)
+			}
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	matcher, err := newAddressMatcher("kaspasim:qq", "[0-9]$", util.Bech32PrefixKaspaSim, false)
+	if err != nil {
+		t.Fatalf("newAddressMatcher: %+v", err)
+	}
+	result, err := search(matcher, util.Bech32PrefixKaspaSim, false, 2, nil)
+	if err != nil {
+		t.Fatalf("search: %+v", err)
+	}
+	address := result.address.String()
+	if !strings.HasPrefix(address, "kaspasim:qq") || !strings.ContainsAny(address[len(address)-1:], "0123456789") {
+		t.Fatalf("the address %s doesn't match", address)
+	}
+}
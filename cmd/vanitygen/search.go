@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/util"
+)
+
+const progressInterval = 10 * time.Second
+
+type searchResult struct {
+	privateKey []byte
+	address    util.Address
+}
+
+// search generates keys on the given number of workers until the address of
+// one matches, and calls onProgress with the number of keys generated so far
+// and the time passed once every progressInterval
+func search(matcher *addressMatcher, addressPrefix util.Bech32Prefix, ecdsa bool, workers int,
+	onProgress func(attempts uint64, elapsed time.Duration)) (*searchResult, error) {
+
+	var attempts uint64
+	results := make(chan *searchResult, workers)
+	errs := make(chan error, workers)
+	quit := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-quit:
+					return
+				default:
+				}
+
+				privateKey, publicKey, err := libkaspawallet.CreateKeyPair(ecdsa)
+				if err != nil {
+					errs <- err
+					return
+				}
+				address, err := newAddress(publicKey, addressPrefix, ecdsa)
+				if err != nil {
+					errs <- err
+					return
+				}
+				atomic.AddUint64(&attempts, 1)
+				if matcher.match(address.String()) {
+					results <- &searchResult{privateKey: privateKey, address: address}
+					return
+				}
+			}
+		}()
+	}
+	defer func() {
+		close(quit)
+		wg.Wait()
+	}()
+
+	start := time.Now()
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case result := <-results:
+			return result, nil
+		case err := <-errs:
+			return nil, err
+		case <-ticker.C:
+			onProgress(atomic.LoadUint64(&attempts), time.Since(start))
+		}
+	}
+}
+
+// printProgress returns a progress callback for search that prints the key
+// generation rate, along with estimates if the difficulty of the match is known
+func printProgress(matcher *addressMatcher) func(attempts uint64, elapsed time.Duration) {
+	return func(attempts uint64, elapsed time.Duration) {
+		rate := float64(attempts) / elapsed.Seconds()
+		if matcher.expectedAttempts == 0 || rate == 0 {
+			fmt.Printf("Generated %d keys (%.0f keys/s)\n", attempts, rate)
+			return
+		}
+
+		// The chance of a key to match is 1/expectedAttempts, independently of the previous
+		// keys, so the time until the next 50% chance doesn't depend on the time passed
+		logChanceToMiss := math.Log1p(-1 / matcher.expectedAttempts)
+		chanceToHaveMatched := 1 - math.Exp(float64(attempts)*logChanceToMiss)
+		secondsToHalfChance := -math.Ln2 / logChanceToMiss / rate
+		fmt.Printf("Generated %d keys (%.0f keys/s). %.1f%% chance to have found a match by now, "+
+			"50%% chance to find one within %s\n", attempts, rate, chanceToHaveMatched*100,
+			time.Duration(secondsToHalfChance*float64(time.Second)).Round(time.Second))
+	}
+}
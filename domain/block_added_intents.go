@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"sync/atomic"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/db/intentlog"
+	"github.com/pkg/errors"
+)
+
+// blockAddedIntentLogName is the name of the intent log that tracks blocks whose
+// BlockAdded event wasn't processed by the transaction index yet. A block is
+// committed by consensus in a single database commit, but the transaction index
+// is only updated by its BlockAdded event later on. If the node crashes in between,
+// the event is sent again at startup.
+//
+// The intent log only covers the transaction index. The UTXO index is updated from
+// the virtual changes, and the other state that's derived from BlockAdded events,
+// such as notifications, isn't persisted.
+const blockAddedIntentLogName = "block-added"
+
+// intentLoggingConsensus records an intent for every block with a body that it
+// inserts, so that its BlockAdded event can be replayed if it isn't processed.
+// Intents are only recorded once the domain enabled them.
+type intentLoggingConsensus struct {
+	externalapi.Consensus
+	intentLog *intentlog.IntentLog
+	isEnabled *uint32
+}
+
+func (d *domain) newIntentLoggingConsensus(consensus externalapi.Consensus) externalapi.Consensus {
+	return &intentLoggingConsensus{
+		Consensus: consensus,
+		intentLog: d.blockAddedIntentLog,
+		isEnabled: &d.isBlockAddedIntentLogEnabled,
+	}
+}
+
+func (c *intentLoggingConsensus) ValidateAndInsertBlock(block *externalapi.DomainBlock, updateVirtual bool) error {
+	// Header-only blocks don't have a BlockAdded event
+	if atomic.LoadUint32(c.isEnabled) == 0 || len(block.Transactions) == 0 {
+		return c.Consensus.ValidateAndInsertBlock(block, updateVirtual)
+	}
+
+	// Blocks that already have a body are rejected as duplicates without being
+	// committed again, so there's no point in recording their intents
+	blockHash := consensushashing.BlockHash(block)
+	blockInfo, err := c.Consensus.GetBlockInfo(blockHash)
+	if err != nil {
+		return err
+	}
+	if blockInfo.HasBody() {
+		return c.Consensus.ValidateAndInsertBlock(block, updateVirtual)
+	}
+
+	err = c.intentLog.Record(blockHash.ByteSlice(), nil)
+	if err != nil {
+		return err
+	}
+
+	insertErr := c.Consensus.ValidateAndInsertBlock(block, updateVirtual)
+	if insertErr != nil {
+		// The block was committed by a concurrent insertion, whose BlockAdded event
+		// is processed as usual, so its intent isn't needed. It was just replaced by
+		// the intent of this insertion, so it's completed rather than left behind.
+		if errors.Is(insertErr, ruleerrors.ErrDuplicateBlock) {
+			err := c.intentLog.Complete(blockHash.ByteSlice())
+			if err != nil {
+				return err
+			}
+			return insertErr
+		}
+
+		// The block may have been committed even though the insertion
+		// failed afterwards, in which case its intent is kept
+		_, err := resolveBlockAddedIntent(c.Consensus, c.intentLog, blockHash)
+		if err != nil {
+			return err
+		}
+		return insertErr
+	}
+	return nil
+}
+
+// resolveBlockAddedIntent completes the intent of the given block if the block
+// wasn't committed with its body, and returns the block otherwise
+func resolveBlockAddedIntent(consensus externalapi.Consensus, intentLog *intentlog.IntentLog,
+	blockHash *externalapi.DomainHash) (*externalapi.DomainBlock, error) {
+
+	blockInfo, err := consensus.GetBlockInfo(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if blockInfo.HasBody() {
+		block, found, err := consensus.GetBlock(blockHash)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return block, nil
+		}
+	}
+
+	return nil, intentLog.Complete(blockHash.ByteSlice())
+}
+
+func (d *domain) EnableBlockAddedIntents() (*intentlog.IntentLog, error) {
+	if !atomic.CompareAndSwapUint32(&d.isBlockAddedIntentLogEnabled, 0, 1) {
+		return nil, errors.New("the BlockAdded intents are already enabled")
+	}
+
+	err := d.recoverBlockAddedIntents()
+	if err != nil {
+		return nil, err
+	}
+	return d.blockAddedIntentLog, nil
+}
+
+// recoverBlockAddedIntents rolls forward the blocks that were committed but whose
+// BlockAdded event wasn't processed, by sending their events again, and rolls back
+// the intents of blocks that weren't committed
+func (d *domain) recoverBlockAddedIntents() error {
+	pending, err := d.blockAddedIntentLog.Pending()
+	if err != nil {
+		return err
+	}
+
+	replayed := 0
+	for _, intent := range pending {
+		blockHash, err := externalapi.NewDomainHashFromByteSlice(intent.ID)
+		if err != nil {
+			return err
+		}
+
+		block, err := resolveBlockAddedIntent(d.Consensus(), d.blockAddedIntentLog, blockHash)
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			continue
+		}
+
+		// Intents that don't fit in the channel stay pending until the next startup
+		if len(d.consensusEventsChannel) == cap(d.consensusEventsChannel) {
+			log.Warnf("The consensus events channel is full. The rest of the " +
+				"BlockAdded events will be replayed on the next startup")
+			break
+		}
+		d.consensusEventsChannel <- &externalapi.BlockAdded{Block: block}
+		replayed++
+	}
+
+	if replayed > 0 {
+		log.Infof("Replayed the BlockAdded events of %d blocks that weren't processed before shutdown", replayed)
+	}
+	return nil
+}
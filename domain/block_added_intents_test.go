@@ -0,0 +1,162 @@
+package domain_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+	"github.com/kaspanet/kaspad/infrastructure/db/intentlog"
+	"github.com/pkg/errors"
+)
+
+func TestBlockAddedEventRecovery(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		dataDir, err := ioutil.TempDir("", fmt.Sprintf("TestBlockAddedEventRecovery-%s", consensusConfig.Name))
+		if err != nil {
+			t.Fatalf("ioutil.TempDir: %+v", err)
+		}
+		defer os.RemoveAll(dataDir)
+
+		// openDomain simulates a restart of the node, after a crash that left the
+		// events that were sent so far unprocessed. The intents are enabled as
+		// the transaction index does, unless enableIntents is false.
+		var db *ldb.LevelDB
+		var intentLog *intentlog.IntentLog
+		openDomain := func(enableIntents bool) domain.Domain {
+			if db != nil {
+				err := db.Close()
+				if err != nil {
+					t.Fatalf("Close: %+v", err)
+				}
+			}
+			db, err = ldb.NewLevelDB(dataDir, 8)
+			if err != nil {
+				t.Fatalf("NewLevelDB: %+v", err)
+			}
+			domainInstance, err := domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), db)
+			if err != nil {
+				t.Fatalf("New: %+v", err)
+			}
+			if enableIntents {
+				intentLog, err = domainInstance.EnableBlockAddedIntents()
+				if err != nil {
+					t.Fatalf("EnableBlockAddedIntents: %+v", err)
+				}
+			}
+			return domainInstance
+		}
+		defer func() { db.Close() }()
+
+		replayedBlockHashes := func(domainInstance domain.Domain) []*externalapi.DomainHash {
+			var blockHashes []*externalapi.DomainHash
+			for len(domainInstance.ConsensusEventsChannel()) > 0 {
+				event := <-domainInstance.ConsensusEventsChannel()
+				blockAdded, ok := event.(*externalapi.BlockAdded)
+				if !ok {
+					t.Fatalf("expected only BlockAdded events to be replayed but got %T", event)
+				}
+				blockHashes = append(blockHashes, consensushashing.BlockHash(blockAdded.Block))
+			}
+			return blockHashes
+		}
+
+		scriptPublicKey, _ := testutils.OpTrueScript()
+		addBlock := func(domainInstance domain.Domain) *externalapi.DomainBlock {
+			coinbaseData := &externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey, ExtraData: []byte{}}
+			block, err := domainInstance.Consensus().BuildBlock(coinbaseData, nil)
+			if err != nil {
+				t.Fatalf("BuildBlock: %+v", err)
+			}
+			err = domainInstance.Consensus().ValidateAndInsertBlock(block, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+			return block
+		}
+
+		// No intents are recorded until they're enabled
+		domainInstance := openDomain(false)
+		addBlock(domainInstance)
+
+		domainInstance = openDomain(true)
+		if len(replayedBlockHashes(domainInstance)) != 0 {
+			t.Fatalf("expected no events to be replayed for blocks that were added without intents")
+		}
+		var blocks []*externalapi.DomainBlock
+		for i := 0; i < 3; i++ {
+			blocks = append(blocks, addBlock(domainInstance))
+		}
+
+		// The first block was indexed before the crash
+		err = intentLog.Complete(consensushashing.BlockHash(blocks[0]).ByteSlice())
+		if err != nil {
+			t.Fatalf("Complete: %+v", err)
+		}
+
+		// Inserting it again fails as a duplicate, and doesn't record its intent again
+		err = domainInstance.Consensus().ValidateAndInsertBlock(blocks[0], true)
+		if !errors.Is(err, ruleerrors.ErrDuplicateBlock) {
+			t.Fatalf("expected ErrDuplicateBlock but got: %v", err)
+		}
+
+		// A block whose intent was recorded but which wasn't committed before the crash
+		notCommittedBlockHash := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{0xff})
+		err = intentlog.New(db, "block-added").Record(notCommittedBlockHash.ByteSlice(), nil)
+		if err != nil {
+			t.Fatalf("Record: %+v", err)
+		}
+
+		// The events aren't replayed until the intents are enabled
+		domainInstance = openDomain(false)
+		if len(replayedBlockHashes(domainInstance)) != 0 {
+			t.Fatalf("expected no events to be replayed before the intents are enabled")
+		}
+
+		domainInstance = openDomain(true)
+		replayed := replayedBlockHashes(domainInstance)
+		if len(replayed) != 2 {
+			t.Fatalf("expected the events of 2 blocks to be replayed but got %d", len(replayed))
+		}
+		for _, expectedBlock := range blocks[1:] {
+			expectedBlockHash := consensushashing.BlockHash(expectedBlock)
+			found := false
+			for _, blockHash := range replayed {
+				if blockHash.Equal(expectedBlockHash) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Fatalf("the event of block %s wasn't replayed", expectedBlockHash)
+			}
+		}
+
+		// The events are replayed until they're processed
+		domainInstance = openDomain(true)
+		replayed = replayedBlockHashes(domainInstance)
+		if len(replayed) != 2 {
+			t.Fatalf("expected the events of 2 blocks to be replayed again but got %d", len(replayed))
+		}
+		for _, blockHash := range replayed {
+			err = intentLog.Complete(blockHash.ByteSlice())
+			if err != nil {
+				t.Fatalf("Complete: %+v", err)
+			}
+		}
+
+		domainInstance = openDomain(true)
+		replayed = replayedBlockHashes(domainInstance)
+		if len(replayed) != 0 {
+			t.Fatalf("expected no events to be replayed after they were processed but got %d", len(replayed))
+		}
+	})
+}
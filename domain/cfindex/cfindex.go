@@ -0,0 +1,271 @@
+package cfindex
+
+import (
+	"sync"
+
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/util/gcs"
+	"github.com/kaspanet/kaspad/util/gcs/builder"
+)
+
+// CFIndex maintains an index between blocks and their compact
+// filters (BIP157/158), along with the headers of the filters.
+//
+// The header of a filter commits to the filter and to the header of the
+// filter of the selected parent of its block, down to the first block on
+// the selected chain whose blue score isn't above the pruning point's, whose
+// filter header commits to the zero hash instead. The filter headers are
+// therefore the same for all nodes that agree on the pruning point, and the
+// whole index is reset whenever the pruning point moves.
+//
+// Filters are indexed once their blocks are added to the DAG, and lazily for
+// blocks that were added without passing through Update, such as during IBD.
+type CFIndex struct {
+	domain domain.Domain
+	store  *cfIndexStore
+
+	pruningPoint          *externalapi.DomainHash
+	pruningPointBlueScore uint64
+
+	mutex sync.Mutex
+}
+
+// New creates a new compact filter index.
+func New(domain domain.Domain, database database.Database) (*CFIndex, error) {
+	cfIndex := &CFIndex{
+		domain: domain,
+		store:  newCFIndexStore(database),
+	}
+	err := cfIndex.syncPruningPoint()
+	if err != nil {
+		return nil, err
+	}
+	return cfIndex, nil
+}
+
+// Drop deletes the whole compact filter index from the given database,
+// so that it's rebuilt once it's created again with New.
+func Drop(database database.Database) error {
+	return newCFIndexStore(database).deleteAll()
+}
+
+// syncPruningPoint resets the index if the pruning point moved since it was built
+func (cfi *CFIndex) syncPruningPoint() error {
+	pruningPoint, err := cfi.domain.Consensus().PruningPoint()
+	if err != nil {
+		return err
+	}
+	if cfi.pruningPoint != nil && cfi.pruningPoint.Equal(pruningPoint) {
+		return nil
+	}
+
+	indexPruningPoint, found, err := cfi.store.getPruningPoint()
+	if err != nil {
+		return err
+	}
+	if !found || !indexPruningPoint.Equal(pruningPoint) {
+		log.Infof("Resetting the compact filter index for pruning point %s", pruningPoint)
+		err := cfi.store.deleteAll()
+		if err != nil {
+			return err
+		}
+		err = cfi.store.putPruningPoint(pruningPoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	pruningPointInfo, err := cfi.domain.Consensus().GetBlockInfo(pruningPoint)
+	if err != nil {
+		return err
+	}
+	cfi.pruningPoint = pruningPoint
+	cfi.pruningPointBlueScore = pruningPointInfo.BlueScore
+	return nil
+}
+
+// Update indexes the filter of the given block, which was just added to the DAG, along with its header
+func (cfi *CFIndex) Update(block *externalapi.DomainBlock) error {
+	cfi.mutex.Lock()
+	defer cfi.mutex.Unlock()
+
+	err := cfi.syncPruningPoint()
+	if err != nil {
+		return err
+	}
+
+	blockHash := consensushashing.BlockHash(block)
+	_, err = cfi.indexFilter(blockHash, block)
+	if err != nil {
+		return err
+	}
+	_, _, err = cfi.filterHeader(blockHash)
+	return err
+}
+
+// Filter returns the filter of the block of the given hash.
+// It returns false if the block doesn't exist or has no body.
+func (cfi *CFIndex) Filter(blockHash *externalapi.DomainHash) (*gcs.Filter, bool, error) {
+	cfi.mutex.Lock()
+	defer cfi.mutex.Unlock()
+
+	err := cfi.syncPruningPoint()
+	if err != nil {
+		return nil, false, err
+	}
+	return cfi.filter(blockHash)
+}
+
+// FilterHeader returns the header of the filter of the block of the given hash.
+// It returns false if the filter of the block, or of any of the blocks its
+// header commits to, is not available.
+func (cfi *CFIndex) FilterHeader(blockHash *externalapi.DomainHash) (*externalapi.DomainHash, bool, error) {
+	cfi.mutex.Lock()
+	defer cfi.mutex.Unlock()
+
+	err := cfi.syncPruningPoint()
+	if err != nil {
+		return nil, false, err
+	}
+	return cfi.filterHeader(blockHash)
+}
+
+// SelectedChainFilterHeaders returns the filter headers of every interval-th block
+// on the selected chain of stopHash, counting from the block whose filter header
+// commits to the zero hash. It returns false if the filter header of stopHash
+// is not available.
+func (cfi *CFIndex) SelectedChainFilterHeaders(stopHash *externalapi.DomainHash, interval int) (
+	[]*externalapi.DomainHash, bool, error) {
+
+	cfi.mutex.Lock()
+	defer cfi.mutex.Unlock()
+
+	err := cfi.syncPruningPoint()
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Make sure the headers of the whole selected chain are indexed
+	_, found, err := cfi.filterHeader(stopHash)
+	if err != nil || !found {
+		return nil, false, err
+	}
+
+	selectedChain, err := cfi.selectedChain(stopHash)
+	if err != nil {
+		return nil, false, err
+	}
+	filterHeaders := make([]*externalapi.DomainHash, 0, len(selectedChain)/interval)
+	for i := interval; i < len(selectedChain); i += interval {
+		filterHeader, found, err := cfi.store.getFilterHeader(selectedChain[i])
+		if err != nil {
+			return nil, false, err
+		}
+		if !found {
+			return nil, false, nil
+		}
+		filterHeaders = append(filterHeaders, filterHeader)
+	}
+	return filterHeaders, true, nil
+}
+
+// selectedChain returns the selected chain of the given block, starting
+// from the block whose filter header commits to the zero hash
+func (cfi *CFIndex) selectedChain(blockHash *externalapi.DomainHash) ([]*externalapi.DomainHash, error) {
+	var selectedChain []*externalapi.DomainHash
+	for current := blockHash; ; {
+		selectedChain = append(selectedChain, current)
+		blockInfo, err := cfi.domain.Consensus().GetBlockInfo(current)
+		if err != nil {
+			return nil, err
+		}
+		if blockInfo.BlueScore <= cfi.pruningPointBlueScore {
+			break
+		}
+		current = blockInfo.SelectedParent
+	}
+
+	for i, j := 0, len(selectedChain)-1; i < j; i, j = i+1, j-1 {
+		selectedChain[i], selectedChain[j] = selectedChain[j], selectedChain[i]
+	}
+	return selectedChain, nil
+}
+
+func (cfi *CFIndex) filter(blockHash *externalapi.DomainHash) (*gcs.Filter, bool, error) {
+	filter, found, err := cfi.store.getFilter(blockHash)
+	if err != nil {
+		return nil, false, err
+	}
+	if found {
+		return filter, true, nil
+	}
+
+	block, found, err := cfi.domain.Consensus().GetBlock(blockHash)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	filter, err = cfi.indexFilter(blockHash, block)
+	if err != nil {
+		return nil, false, err
+	}
+	return filter, true, nil
+}
+
+func (cfi *CFIndex) indexFilter(blockHash *externalapi.DomainHash, block *externalapi.DomainBlock) (*gcs.Filter, error) {
+	filter, err := builder.BuildBasicFilter(block)
+	if err != nil {
+		return nil, err
+	}
+	err = cfi.store.putFilter(blockHash, filter)
+	if err != nil {
+		return nil, err
+	}
+	log.Tracef("Indexed the filter of block %s", blockHash)
+	return filter, nil
+}
+
+func (cfi *CFIndex) filterHeader(blockHash *externalapi.DomainHash) (*externalapi.DomainHash, bool, error) {
+	// Collect the selected chain of the block down to the
+	// first block whose filter header is already indexed
+	var unindexedChain []*externalapi.DomainHash
+	previousHeader := &externalapi.DomainHash{}
+	for current := blockHash; ; {
+		header, found, err := cfi.store.getFilterHeader(current)
+		if err != nil {
+			return nil, false, err
+		}
+		if found {
+			previousHeader = header
+			break
+		}
+
+		blockInfo, err := cfi.domain.Consensus().GetBlockInfo(current)
+		if err != nil {
+			return nil, false, err
+		}
+		if !blockInfo.HasBody() {
+			return nil, false, nil
+		}
+		unindexedChain = append(unindexedChain, current)
+		if blockInfo.BlueScore <= cfi.pruningPointBlueScore {
+			break
+		}
+		current = blockInfo.SelectedParent
+	}
+
+	for i := len(unindexedChain) - 1; i >= 0; i-- {
+		filter, found, err := cfi.filter(unindexedChain[i])
+		if err != nil || !found {
+			return nil, false, err
+		}
+		previousHeader = builder.MakeHeaderForFilter(filter, previousHeader)
+		err = cfi.store.putFilterHeader(unindexedChain[i], previousHeader)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return previousHeader, true, nil
+}
@@ -0,0 +1,7 @@
+package cfindex
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+)
+
+var log = logger.RegisterSubSystem("CFIN")
@@ -0,0 +1,105 @@
+package cfindex
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/util/gcs"
+	"github.com/kaspanet/kaspad/util/gcs/builder"
+)
+
+var cfIndexBucket = database.MakeBucket([]byte("cf-index"))
+var filtersBucket = cfIndexBucket.Bucket([]byte("filters"))
+var filterHeadersBucket = cfIndexBucket.Bucket([]byte("filter-headers"))
+var pruningPointKey = database.MakeBucket([]byte("")).Key([]byte("cf-index-pruning-point"))
+
+type cfIndexStore struct {
+	database database.Database
+}
+
+func newCFIndexStore(database database.Database) *cfIndexStore {
+	return &cfIndexStore{database: database}
+}
+
+func (cis *cfIndexStore) getFilter(blockHash *externalapi.DomainHash) (*gcs.Filter, bool, error) {
+	filterBytes, err := cis.database.Get(filtersBucket.Key(blockHash.ByteSlice()))
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	filter, err := gcs.FromNBytes(builder.DefaultP, builder.DefaultM, filterBytes)
+	if err != nil {
+		return nil, false, err
+	}
+	return filter, true, nil
+}
+
+func (cis *cfIndexStore) putFilter(blockHash *externalapi.DomainHash, filter *gcs.Filter) error {
+	return cis.database.Put(filtersBucket.Key(blockHash.ByteSlice()), filter.NBytes())
+}
+
+func (cis *cfIndexStore) getFilterHeader(blockHash *externalapi.DomainHash) (*externalapi.DomainHash, bool, error) {
+	headerBytes, err := cis.database.Get(filterHeadersBucket.Key(blockHash.ByteSlice()))
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	header, err := externalapi.NewDomainHashFromByteSlice(headerBytes)
+	if err != nil {
+		return nil, false, err
+	}
+	return header, true, nil
+}
+
+func (cis *cfIndexStore) putFilterHeader(blockHash *externalapi.DomainHash, header *externalapi.DomainHash) error {
+	return cis.database.Put(filterHeadersBucket.Key(blockHash.ByteSlice()), header.ByteSlice())
+}
+
+func (cis *cfIndexStore) getPruningPoint() (*externalapi.DomainHash, bool, error) {
+	pruningPointBytes, err := cis.database.Get(pruningPointKey)
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	pruningPoint, err := externalapi.NewDomainHashFromByteSlice(pruningPointBytes)
+	if err != nil {
+		return nil, false, err
+	}
+	return pruningPoint, true, nil
+}
+
+func (cis *cfIndexStore) putPruningPoint(pruningPoint *externalapi.DomainHash) error {
+	return cis.database.Put(pruningPointKey, pruningPoint.ByteSlice())
+}
+
+func (cis *cfIndexStore) deleteAll() error {
+	// First we delete the pruning point, so if anything goes wrong,
+	// the index will be reset again on the next run.
+	err := cis.database.Delete(pruningPointKey)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := cis.database.Cursor(cfIndexBucket)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+	for cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+
+		err = cis.database.Delete(key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package consensus
 
 import (
 	"math/big"
+	"sort"
 	"sync"
 
 	"github.com/kaspanet/kaspad/util/mstime"
@@ -10,13 +11,17 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/model"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
 	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/kaspanet/kaspad/util/staging"
 	"github.com/pkg/errors"
 )
 
 type consensus struct {
+	// lock is held by every operation that changes the consensus. Operations that
+	// only read its committed state lock segments instead, as described in dagSegments.
 	lock            *sync.Mutex
+	segments        *dagSegments
 	databaseContext model.DBManager
 
 	genesisBlock *externalapi.DomainBlock
@@ -75,10 +80,12 @@ func (s *consensus) ValidateAndInsertBlockWithTrustedData(block *externalapi.Blo
 	defer s.lock.Unlock()
 
 	_, _, err := s.blockProcessor.ValidateAndInsertBlockWithTrustedData(block, validateUTXO)
+	finalityConflicts := s.consensusStateManager.PopFinalityConflicts()
 	if err != nil {
 		return err
 	}
-	return nil
+
+	return s.sendFinalityConflictEvents(finalityConflicts)
 }
 
 // Init initializes consensus
@@ -251,7 +258,16 @@ func (s *consensus) validateAndInsertBlockWithLock(block *externalapi.DomainBloc
 }
 
 func (s *consensus) validateAndInsertBlockNoLock(block *externalapi.DomainBlock, updateVirtual bool) (*externalapi.VirtualChangeSet, error) {
+	// This is checked before the block is inserted, so that the header of a block whose
+	// body completes an already known header isn't reported again
+	isHeaderNew, err := s.isHeaderNew(block)
+	if err != nil {
+		return nil, err
+	}
+
 	virtualChangeSet, blockStatus, err := s.blockProcessor.ValidateAndInsertBlock(block, updateVirtual)
+	// Finality conflicts found while processing a block that failed to be committed are discarded
+	finalityConflicts := s.consensusStateManager.PopFinalityConflicts()
 	if err != nil {
 		return nil, err
 	}
@@ -261,6 +277,11 @@ func (s *consensus) validateAndInsertBlockNoLock(block *externalapi.DomainBlock,
 		s.virtualNotUpdated = true
 	}
 
+	err = s.sendBlockHeaderAddedEvent(block, blockStatus, isHeaderNew)
+	if err != nil {
+		return nil, err
+	}
+
 	err = s.sendBlockAddedEvent(block, blockStatus)
 	if err != nil {
 		return nil, err
@@ -271,9 +292,43 @@ func (s *consensus) validateAndInsertBlockNoLock(block *externalapi.DomainBlock,
 		return nil, err
 	}
 
+	err = s.sendFinalityConflictEvents(finalityConflicts)
+	if err != nil {
+		return nil, err
+	}
+
 	return virtualChangeSet, nil
 }
 
+func (s *consensus) isHeaderNew(block *externalapi.DomainBlock) (bool, error) {
+	if s.consensusEventsChan == nil {
+		return false, nil
+	}
+
+	stagingArea := model.NewStagingArea()
+	exists, err := s.blockStatusStore.Exists(s.databaseContext, stagingArea, consensushashing.BlockHash(block))
+	if err != nil {
+		return false, err
+	}
+	return !exists, nil
+}
+
+func (s *consensus) sendBlockHeaderAddedEvent(block *externalapi.DomainBlock, blockStatus externalapi.BlockStatus,
+	isHeaderNew bool) error {
+
+	if s.consensusEventsChan != nil {
+		if !isHeaderNew || blockStatus == externalapi.StatusInvalid {
+			return nil
+		}
+
+		if len(s.consensusEventsChan) == cap(s.consensusEventsChan) {
+			return errors.Errorf("consensusEventsChan is full")
+		}
+		s.consensusEventsChan <- &externalapi.BlockHeaderAdded{Header: block.Header}
+	}
+	return nil
+}
+
 func (s *consensus) sendBlockAddedEvent(block *externalapi.DomainBlock, blockStatus externalapi.BlockStatus) error {
 	if s.consensusEventsChan != nil {
 		if blockStatus == externalapi.StatusHeaderOnly || blockStatus == externalapi.StatusInvalid {
@@ -317,10 +372,27 @@ func (s *consensus) sendVirtualChangedEvent(virtualChangeSet *externalapi.Virtua
 	virtualChangeSet.VirtualSelectedParentBlueScore = virtualSelectedParentGHOSTDAGData.BlueScore()
 	virtualChangeSet.VirtualDAAScore = virtualDAAScore
 
+	virtualDAAScoreGauge.Set(float64(virtualDAAScore))
+	virtualSelectedParentBlueScoreGauge.Set(float64(virtualChangeSet.VirtualSelectedParentBlueScore))
+
 	s.consensusEventsChan <- virtualChangeSet
 	return nil
 }
 
+func (s *consensus) sendFinalityConflictEvents(violatingBlockHashes []*externalapi.DomainHash) error {
+	if s.consensusEventsChan == nil {
+		return nil
+	}
+
+	for _, violatingBlockHash := range violatingBlockHashes {
+		if len(s.consensusEventsChan) == cap(s.consensusEventsChan) {
+			return errors.Errorf("consensusEventsChan is full")
+		}
+		s.consensusEventsChan <- &externalapi.FinalityConflict{ViolatingBlockHash: violatingBlockHash}
+	}
+	return nil
+}
+
 // ValidateTransactionAndPopulateWithConsensusData validates the given transaction
 // and populates it with any missing consensus data
 func (s *consensus) ValidateTransactionAndPopulateWithConsensusData(transaction *externalapi.DomainTransaction) error {
@@ -358,8 +430,8 @@ func (s *consensus) ValidateTransactionAndPopulateWithConsensusData(transaction
 }
 
 func (s *consensus) GetBlock(blockHash *externalapi.DomainHash) (*externalapi.DomainBlock, bool, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(blockIndexSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -374,8 +446,8 @@ func (s *consensus) GetBlock(blockHash *externalapi.DomainHash) (*externalapi.Do
 }
 
 func (s *consensus) GetBlockEvenIfHeaderOnly(blockHash *externalapi.DomainHash) (*externalapi.DomainBlock, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(blockIndexSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -398,8 +470,8 @@ func (s *consensus) GetBlockEvenIfHeaderOnly(blockHash *externalapi.DomainHash)
 }
 
 func (s *consensus) GetBlockHeader(blockHash *externalapi.DomainHash) (externalapi.BlockHeader, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(blockIndexSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -414,8 +486,8 @@ func (s *consensus) GetBlockHeader(blockHash *externalapi.DomainHash) (externala
 }
 
 func (s *consensus) GetBlockInfo(blockHash *externalapi.DomainHash) (*externalapi.BlockInfo, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(blockIndexSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -458,8 +530,8 @@ func (s *consensus) GetBlockInfo(blockHash *externalapi.DomainHash) (*externalap
 func (s *consensus) GetBlockRelations(blockHash *externalapi.DomainHash) (
 	parents []*externalapi.DomainHash, children []*externalapi.DomainHash, err error) {
 
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(blockIndexSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -472,8 +544,8 @@ func (s *consensus) GetBlockRelations(blockHash *externalapi.DomainHash) (
 }
 
 func (s *consensus) GetBlockAcceptanceData(blockHash *externalapi.DomainHash) (externalapi.AcceptanceData, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(blockIndexSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -486,8 +558,8 @@ func (s *consensus) GetBlockAcceptanceData(blockHash *externalapi.DomainHash) (e
 }
 
 func (s *consensus) GetBlocksAcceptanceData(blockHashes []*externalapi.DomainHash) ([]externalapi.AcceptanceData, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(blockIndexSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 	blocksAcceptanceData := make([]externalapi.AcceptanceData, len(blockHashes))
@@ -509,6 +581,116 @@ func (s *consensus) GetBlocksAcceptanceData(blockHashes []*externalapi.DomainHas
 	return blocksAcceptanceData, nil
 }
 
+// GetAcceptingBlock returns the block of the virtual selected parent chain that accepts the
+// transactions of the given block, that is, the lowest chain block that has it in its past.
+// It returns false if no chain block has it in its past yet, in which case its transactions
+// are accepted only by the virtual.
+func (s *consensus) GetAcceptingBlock(blockHash *externalapi.DomainHash) (*externalapi.DomainHash, bool, error) {
+	unlock := s.segments.rLock(blockIndexSegment, virtualSegment)
+	defer unlock()
+
+	stagingArea := model.NewStagingArea()
+
+	err := s.validateBlockHashExists(stagingArea, blockHash)
+	if err != nil {
+		return nil, false, err
+	}
+
+	isInChainBlockPast := func(chainBlockHash *externalapi.DomainHash) (bool, error) {
+		if chainBlockHash.Equal(blockHash) {
+			return false, nil
+		}
+		return s.dagTopologyManagers[0].IsAncestorOf(stagingArea, blockHash, chainBlockHash)
+	}
+
+	virtualGHOSTDAGData, err := s.ghostdagDataStores[0].Get(s.databaseContext, stagingArea, model.VirtualBlockHash, false)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// The virtual selected parent chain may diverge from the headers selected chain while
+	// the node syncs, so its blocks above the divergence point are traversed one by one
+	var chainBlocksAboveHeadersChain []*externalapi.DomainHash
+	current := virtualGHOSTDAGData.SelectedParent()
+	var currentIndex uint64
+	for {
+		currentIndex, err = s.headersSelectedChainStore.GetIndexByHash(s.databaseContext, stagingArea, current)
+		if err == nil {
+			break
+		}
+		if !database.IsNotFoundError(err) {
+			return nil, false, err
+		}
+		chainBlocksAboveHeadersChain = append(chainBlocksAboveHeadersChain, current)
+
+		currentGHOSTDAGData, err := s.ghostdagDataStores[0].Get(s.databaseContext, stagingArea, current, false)
+		if err != nil {
+			return nil, false, err
+		}
+		current = currentGHOSTDAGData.SelectedParent()
+	}
+
+	isInPast, err := isInChainBlockPast(current)
+	if err != nil {
+		return nil, false, err
+	}
+	if !isInPast {
+		for i := len(chainBlocksAboveHeadersChain) - 1; i >= 0; i-- {
+			isInPast, err := isInChainBlockPast(chainBlocksAboveHeadersChain[i])
+			if err != nil {
+				return nil, false, err
+			}
+			if isInPast {
+				return chainBlocksAboveHeadersChain[i], true, nil
+			}
+		}
+		return nil, false, nil
+	}
+
+	pruningPoint, err := s.pruningStore.PruningPoint(s.databaseContext, stagingArea)
+	if err != nil {
+		return nil, false, err
+	}
+	isInPruningPointPast, err := isInChainBlockPast(pruningPoint)
+	if err != nil {
+		return nil, false, err
+	}
+	if isInPruningPointPast {
+		return nil, false, errors.Errorf("block %s is in the past of the pruning point", blockHash)
+	}
+	pruningPointIndex, err := s.headersSelectedChainStore.GetIndexByHash(s.databaseContext, stagingArea, pruningPoint)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// The chain blocks that have the block in their past are a suffix of the chain, so the
+	// lowest of them is found by a binary search. The block at lowIndex never has the block
+	// in its past, while the block at highIndex always has.
+	lowIndex, highIndex := pruningPointIndex, currentIndex
+	for highIndex-lowIndex > 1 {
+		middleIndex := lowIndex + (highIndex-lowIndex)/2
+		middleHash, err := s.headersSelectedChainStore.GetHashByIndex(s.databaseContext, stagingArea, middleIndex)
+		if err != nil {
+			return nil, false, err
+		}
+		isInPast, err := isInChainBlockPast(middleHash)
+		if err != nil {
+			return nil, false, err
+		}
+		if isInPast {
+			highIndex = middleIndex
+		} else {
+			lowIndex = middleIndex
+		}
+	}
+
+	acceptingBlockHash, err := s.headersSelectedChainStore.GetHashByIndex(s.databaseContext, stagingArea, highIndex)
+	if err != nil {
+		return nil, false, err
+	}
+	return acceptingBlockHash, true, nil
+}
+
 func (s *consensus) GetHashesBetween(lowHash, highHash *externalapi.DomainHash, maxBlocks uint64) (
 	hashes []*externalapi.DomainHash, actualHighHash *externalapi.DomainHash, err error) {
 
@@ -548,6 +730,68 @@ func (s *consensus) GetAnticone(blockHash, contextHash *externalapi.DomainHash,
 	return s.syncManager.GetAnticone(stagingArea, blockHash, contextHash, maxBlocks)
 }
 
+// BluesBetween returns the blocks that GHOSTDAG colored blue in the past of highHash
+// that are not in the past of lowHash, in GHOSTDAG order. lowHash must be in the
+// selected parent chain of highHash. If maxBlocks is not 0, the returned blocks are
+// cut at the selected chain block actualHighHash whose blues exceed maxBlocks
+func (s *consensus) BluesBetween(lowHash, highHash *externalapi.DomainHash, maxBlocks uint64) (
+	hashes []*externalapi.DomainHash, actualHighHash *externalapi.DomainHash, err error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stagingArea := model.NewStagingArea()
+
+	err = s.validateBlockHashExists(stagingArea, lowHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = s.validateBlockHashExists(stagingArea, highHash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if lowHash.Equal(highHash) {
+		return []*externalapi.DomainHash{}, highHash, nil
+	}
+	isInSelectedParentChain, err := s.dagTopologyManagers[0].IsInSelectedParentChainOf(stagingArea, lowHash, highHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isInSelectedParentChain {
+		return nil, nil, errors.Errorf("%s is not in the selected parent chain of %s", lowHash, highHash)
+	}
+
+	hashes = []*externalapi.DomainHash{}
+	actualHighHash = lowHash
+	iterator, err := s.dagTraversalManager.SelectedChildIterator(stagingArea, highHash, lowHash, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer iterator.Close()
+	for ok := iterator.First(); ok; ok = iterator.Next() {
+		current, err := iterator.Get()
+		if err != nil {
+			return nil, nil, err
+		}
+		currentGHOSTDAGData, err := s.ghostdagDataStores[0].Get(s.databaseContext, stagingArea, current, false)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The blues of a single selected chain block are always returned, so that
+		// the caller can advance even if they exceed maxBlocks
+		mergeSetBlues := currentGHOSTDAGData.MergeSetBlues()
+		if maxBlocks != 0 && len(hashes) > 0 && uint64(len(hashes)+len(mergeSetBlues)) > maxBlocks {
+			break
+		}
+		hashes = append(hashes, mergeSetBlues...)
+		actualHighHash = current
+	}
+
+	return hashes, actualHighHash, nil
+}
+
 func (s *consensus) GetMissingBlockBodyHashes(highHash *externalapi.DomainHash) ([]*externalapi.DomainHash, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -591,8 +835,8 @@ func (s *consensus) GetPruningPointUTXOs(expectedPruningPointHash *externalapi.D
 func (s *consensus) GetVirtualUTXOs(expectedVirtualParents []*externalapi.DomainHash,
 	fromOutpoint *externalapi.DomainOutpoint, limit int) ([]*externalapi.OutpointAndUTXOEntryPair, error) {
 
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(utxoSegment, virtualSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -614,13 +858,102 @@ func (s *consensus) GetVirtualUTXOs(expectedVirtualParents []*externalapi.Domain
 	return virtualUTXOs, nil
 }
 
+// GetVirtualUTXOSetSnapshot returns a snapshot of the virtual UTXO set, which
+// unlike GetVirtualUTXOs can be paged through while the virtual changes
+func (s *consensus) GetVirtualUTXOSetSnapshot() (externalapi.VirtualUTXOSetSnapshot, error) {
+	unlock := s.segments.rLock(utxoSegment, virtualSegment)
+	defer unlock()
+
+	stagingArea := model.NewStagingArea()
+
+	virtualParents, err := s.dagTopologyManagers[0].Parents(stagingArea, model.VirtualBlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// The virtual UTXO set and the virtual parents are only written with their
+	// segments locked for writing, so the snapshot matches the virtual parents
+	dbSnapshot, err := s.databaseContext.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	return &virtualUTXOSetSnapshot{
+		dbSnapshot:          dbSnapshot,
+		consensusStateStore: s.consensusStateStore,
+		virtualParents:      virtualParents,
+	}, nil
+}
+
+// GetVirtualUTXOEntries returns the entries of the given outpoints in the virtual UTXO set,
+// with nil for the outpoints that aren't in it, along with the selected parent of the
+// virtual at the time they were read
+func (s *consensus) GetVirtualUTXOEntries(outpoints []*externalapi.DomainOutpoint) (
+	entries []externalapi.UTXOEntry, virtualSelectedParent *externalapi.DomainHash, err error) {
+
+	unlock := s.segments.rLock(utxoSegment, virtualSegment)
+	defer unlock()
+
+	stagingArea := model.NewStagingArea()
+
+	virtualGHOSTDAGData, err := s.ghostdagDataStores[0].Get(s.databaseContext, stagingArea, model.VirtualBlockHash, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries = make([]externalapi.UTXOEntry, len(outpoints))
+	for i, outpoint := range outpoints {
+		hasEntry, err := s.consensusStateStore.HasUTXOByOutpoint(s.databaseContext, stagingArea, outpoint)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !hasEntry {
+			continue
+		}
+		entries[i], err = s.consensusStateStore.UTXOByOutpoint(s.databaseContext, stagingArea, outpoint)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return entries, virtualGHOSTDAGData.SelectedParent(), nil
+}
+
 func (s *consensus) PruningPoint() (*externalapi.DomainHash, error) {
+	unlock := s.segments.rLock(virtualSegment)
+	defer unlock()
+
+	stagingArea := model.NewStagingArea()
+
+	return s.pruningStore.PruningPoint(s.databaseContext, stagingArea)
+}
+
+// VirtualFinalityPoint returns the finality point that blocks are validated against. That's
+// the finality point of the virtual, unless it's in the past of the pruning point, in which
+// case finality is validated using the pruning point.
+func (s *consensus) VirtualFinalityPoint() (*externalapi.DomainHash, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
 	stagingArea := model.NewStagingArea()
 
-	return s.pruningStore.PruningPoint(s.databaseContext, stagingArea)
+	virtualFinalityPoint, err := s.finalityManager.VirtualFinalityPoint(stagingArea)
+	if err != nil {
+		return nil, err
+	}
+
+	pruningPoint, err := s.pruningStore.PruningPoint(s.databaseContext, stagingArea)
+	if err != nil {
+		return nil, err
+	}
+
+	isFinalityPointInPastOfPruningPoint, err := s.dagTopologyManagers[0].IsAncestorOf(stagingArea, virtualFinalityPoint, pruningPoint)
+	if err != nil {
+		return nil, err
+	}
+	if isFinalityPointInPastOfPruningPoint {
+		return pruningPoint, nil
+	}
+	return virtualFinalityPoint, nil
 }
 
 func (s *consensus) PruningPointHeaders() ([]externalapi.BlockHeader, error) {
@@ -674,8 +1007,8 @@ func (s *consensus) ValidateAndInsertImportedPruningPoint(newPruningPoint *exter
 }
 
 func (s *consensus) GetVirtualSelectedParent() (*externalapi.DomainHash, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(virtualSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -687,8 +1020,8 @@ func (s *consensus) GetVirtualSelectedParent() (*externalapi.DomainHash, error)
 }
 
 func (s *consensus) Tips() ([]*externalapi.DomainHash, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(virtualSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -696,8 +1029,8 @@ func (s *consensus) Tips() ([]*externalapi.DomainHash, error) {
 }
 
 func (s *consensus) GetVirtualInfo() (*externalapi.VirtualInfo, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(blockIndexSegment, virtualSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -732,9 +1065,22 @@ func (s *consensus) GetVirtualInfo() (*externalapi.VirtualInfo, error) {
 	}, nil
 }
 
+func (s *consensus) GetVirtualUTXOCommitment() (*externalapi.DomainHash, error) {
+	unlock := s.segments.rLock(utxoSegment)
+	defer unlock()
+
+	stagingArea := model.NewStagingArea()
+
+	virtualMultiset, err := s.multisetStore.Get(s.databaseContext, stagingArea, model.VirtualBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	return virtualMultiset.Hash(), nil
+}
+
 func (s *consensus) GetVirtualDAAScore() (uint64, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(virtualSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -760,6 +1106,101 @@ func (s *consensus) CreateBlockLocatorFromPruningPoint(highHash *externalapi.Dom
 	return s.syncManager.CreateBlockLocator(stagingArea, pruningPoint, highHash, limit)
 }
 
+// CreateAntichainLocator creates a locator of the selected parent chain from the sink
+// down to the pruning point, along with the tips of the DAG. The tips with the highest
+// blue work are kept if there are more than tipsLimit of them.
+func (s *consensus) CreateAntichainLocator(chainLimit uint32, tipsLimit uint32) (*externalapi.AntichainLocator, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stagingArea := model.NewStagingArea()
+
+	pruningPoint, err := s.pruningStore.PruningPoint(s.databaseContext, stagingArea)
+	if err != nil {
+		return nil, err
+	}
+
+	virtualGHOSTDAGData, err := s.ghostdagDataStores[0].Get(s.databaseContext, stagingArea, model.VirtualBlockHash, false)
+	if err != nil {
+		return nil, err
+	}
+
+	chainLocator, err := s.syncManager.CreateBlockLocator(stagingArea, pruningPoint,
+		virtualGHOSTDAGData.SelectedParent(), chainLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	tips, err := s.consensusStateStore.Tips(stagingArea, s.databaseContext)
+	if err != nil {
+		return nil, err
+	}
+	if uint32(len(tips)) > tipsLimit {
+		blueWorks := make(map[externalapi.DomainHash]*big.Int, len(tips))
+		for _, tip := range tips {
+			tipGHOSTDAGData, err := s.ghostdagDataStores[0].Get(s.databaseContext, stagingArea, tip, false)
+			if err != nil {
+				return nil, err
+			}
+			blueWorks[*tip] = tipGHOSTDAGData.BlueWork()
+		}
+		tips = externalapi.CloneHashes(tips)
+		sort.Slice(tips, func(i, j int) bool {
+			return blueWorks[*tips[i]].Cmp(blueWorks[*tips[j]]) > 0
+		})
+		tips = tips[:tipsLimit]
+	}
+
+	return &externalapi.AntichainLocator{ChainLocator: chainLocator, Tips: tips}, nil
+}
+
+// AntichainLocatorMissingBlocks returns the highest block of the chain locator of the
+// given locator that this node has the body of, or nil if it has none of them, and the
+// tips of the locator whose bodies this node is missing
+func (s *consensus) AntichainLocatorMissingBlocks(locator *externalapi.AntichainLocator) (
+	highestSharedChainHash *externalapi.DomainHash, missingHashes []*externalapi.DomainHash, err error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stagingArea := model.NewStagingArea()
+
+	hasBody := func(blockHash *externalapi.DomainHash) (bool, error) {
+		exists, err := s.blockStatusStore.Exists(s.databaseContext, stagingArea, blockHash)
+		if err != nil || !exists {
+			return false, err
+		}
+		status, err := s.blockStatusStore.Get(s.databaseContext, stagingArea, blockHash)
+		if err != nil {
+			return false, err
+		}
+		return status != externalapi.StatusHeaderOnly, nil
+	}
+
+	for _, blockHash := range locator.ChainLocator {
+		has, err := hasBody(blockHash)
+		if err != nil {
+			return nil, nil, err
+		}
+		if has {
+			highestSharedChainHash = blockHash
+			break
+		}
+	}
+
+	for _, tip := range locator.Tips {
+		has, err := hasBody(tip)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !has {
+			missingHashes = append(missingHashes, tip)
+		}
+	}
+
+	return highestSharedChainHash, missingHashes, nil
+}
+
 func (s *consensus) CreateFullHeadersSelectedChainBlockLocator() (externalapi.BlockLocator, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -839,8 +1280,8 @@ func (s *consensus) ImportPruningPoints(pruningPoints []externalapi.BlockHeader)
 }
 
 func (s *consensus) GetVirtualSelectedParentChainFromBlock(blockHash *externalapi.DomainHash) (*externalapi.SelectedChainPath, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(blockIndexSegment, virtualSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -868,8 +1309,8 @@ func (s *consensus) validateBlockHashExists(stagingArea *model.StagingArea, bloc
 }
 
 func (s *consensus) IsInSelectedParentChainOf(blockHashA *externalapi.DomainHash, blockHashB *externalapi.DomainHash) (bool, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(blockIndexSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -886,8 +1327,8 @@ func (s *consensus) IsInSelectedParentChainOf(blockHashA *externalapi.DomainHash
 }
 
 func (s *consensus) GetHeadersSelectedTip() (*externalapi.DomainHash, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(blockIndexSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -895,8 +1336,8 @@ func (s *consensus) GetHeadersSelectedTip() (*externalapi.DomainHash, error) {
 }
 
 func (s *consensus) Anticone(blockHash *externalapi.DomainHash) ([]*externalapi.DomainHash, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(blockIndexSegment, virtualSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 
@@ -951,6 +1392,122 @@ func (s *consensus) ResolveVirtual(progressReportCallback func(uint64, uint64))
 	return nil
 }
 
+// RebuildUTXOState rebuilds the virtual UTXO set from the pruning point UTXO set, and discards the
+// UTXO state of all the blocks in the future of the pruning point. The virtual has to be resolved
+// afterwards in order to rebuild the UTXO state of these blocks.
+func (s *consensus) RebuildUTXOState() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	err := s.pruningManager.ClearImportedPruningPointData()
+	if err != nil {
+		return err
+	}
+
+	var fromOutpoint *externalapi.DomainOutpoint
+	const step = 100_000
+	for {
+		outpointAndUTXOEntryPairs, err := s.pruningStore.PruningPointUTXOs(s.databaseContext, fromOutpoint, step)
+		if err != nil {
+			return err
+		}
+		// This is called even if there are no UTXOs left, so that the imported multiset is always stored
+		err = s.pruningManager.AppendImportedPruningPointUTXOs(outpointAndUTXOEntryPairs)
+		if err != nil {
+			return err
+		}
+		if len(outpointAndUTXOEntryPairs) < step {
+			break
+		}
+		fromOutpoint = outpointAndUTXOEntryPairs[len(outpointAndUTXOEntryPairs)-1].Outpoint
+	}
+
+	err = s.consensusStateManager.RebuildUTXOState()
+	if err != nil {
+		return err
+	}
+	s.virtualNotUpdated = true
+
+	return s.pruningManager.ClearImportedPruningPointData()
+}
+
+// InvalidateBlock marks the given block as invalid, which excludes it and its future from the
+// past of the virtual, and resolves the virtual accordingly
+func (s *consensus) InvalidateBlock(blockHash *externalapi.DomainHash) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stagingArea := model.NewStagingArea()
+	err := s.validateBlockHashExists(stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+	status, err := s.blockStatusStore.Get(s.databaseContext, stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+	if status == externalapi.StatusHeaderOnly {
+		return errors.Errorf("block %s has no body", blockHash)
+	}
+
+	virtualFinalityPoint, err := s.finalityManager.VirtualFinalityPoint(stagingArea)
+	if err != nil {
+		return err
+	}
+	isInPastOfFinalityPoint, err := s.dagTopologyManagers[0].IsAncestorOf(stagingArea, blockHash, virtualFinalityPoint)
+	if err != nil {
+		return err
+	}
+	if isInPastOfFinalityPoint {
+		return errors.Errorf("block %s is not in the future of the virtual finality point %s",
+			blockHash, virtualFinalityPoint)
+	}
+
+	virtualChangeSet, err := s.consensusStateManager.InvalidateBlock(blockHash)
+	if err != nil {
+		return err
+	}
+	return s.resolveVirtualAfterChangeNoLock(virtualChangeSet)
+}
+
+// ReconsiderBlock removes the invalidation of the given block, of the invalidated blocks in its
+// past and of the invalidated blocks in its future, and resolves the virtual accordingly
+func (s *consensus) ReconsiderBlock(blockHash *externalapi.DomainHash) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stagingArea := model.NewStagingArea()
+	err := s.validateBlockHashExists(stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+
+	virtualChangeSet, err := s.consensusStateManager.ReconsiderBlock(blockHash)
+	if err != nil {
+		return err
+	}
+	return s.resolveVirtualAfterChangeNoLock(virtualChangeSet)
+}
+
+// resolveVirtualAfterChangeNoLock sends the given change of the virtual, and then resolves the
+// blocks that the change made candidates for the virtual selected parent
+func (s *consensus) resolveVirtualAfterChangeNoLock(virtualChangeSet *externalapi.VirtualChangeSet) error {
+	err := s.sendVirtualChangedEvent(virtualChangeSet, true)
+	if err != nil {
+		return err
+	}
+
+	for {
+		_, isCompletelyResolved, err := s.resolveVirtualChunkNoLock(virtualResolveChunk)
+		if err != nil {
+			return err
+		}
+		if isCompletelyResolved {
+			return nil
+		}
+	}
+}
+
 func (s *consensus) resolveVirtualChunkWithLock(maxBlocksToResolve uint64) (*externalapi.VirtualChangeSet, bool, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -960,6 +1517,7 @@ func (s *consensus) resolveVirtualChunkWithLock(maxBlocksToResolve uint64) (*ext
 
 func (s *consensus) resolveVirtualChunkNoLock(maxBlocksToResolve uint64) (*externalapi.VirtualChangeSet, bool, error) {
 	virtualChangeSet, isCompletelyResolved, err := s.consensusStateManager.ResolveVirtual(maxBlocksToResolve)
+	finalityConflicts := s.consensusStateManager.PopFinalityConflicts()
 	if err != nil {
 		return nil, false, err
 	}
@@ -986,6 +1544,11 @@ func (s *consensus) resolveVirtualChunkNoLock(maxBlocksToResolve uint64) (*exter
 		return nil, false, err
 	}
 
+	err = s.sendFinalityConflictEvents(finalityConflicts)
+	if err != nil {
+		return nil, false, err
+	}
+
 	return virtualChangeSet, isCompletelyResolved, nil
 }
 
@@ -1088,8 +1651,8 @@ func (s *consensus) TrustedGHOSTDAGData(blockHash *externalapi.DomainHash) (*ext
 }
 
 func (s *consensus) IsChainBlock(blockHash *externalapi.DomainHash) (bool, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(blockIndexSegment, virtualSegment)
+	defer unlock()
 
 	stagingArea := model.NewStagingArea()
 	virtualGHOSTDAGData, err := s.ghostdagDataStores[0].Get(s.databaseContext, stagingArea, model.VirtualBlockHash, false)
@@ -1111,8 +1674,8 @@ func (s *consensus) VirtualMergeDepthRoot() (*externalapi.DomainHash, error) {
 // IsNearlySynced returns whether this consensus is considered synced or close to being synced. This info
 // is used to determine if it's ok to use a block template from this node for mining purposes.
 func (s *consensus) IsNearlySynced() (bool, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	unlock := s.segments.rLock(blockIndexSegment, virtualSegment)
+	defer unlock()
 
 	return s.isNearlySyncedNoLock()
 }
@@ -1146,3 +1709,39 @@ func (s *consensus) isNearlySyncedNoLock() (bool, error) {
 		virtualSelectedParentHeader.TimeInMilliseconds())
 	return false, nil
 }
+
+// The estimated numbers of bytes an entry of each of the consensus caches occupies in
+// memory. The size of blocks varies widely, so the size of a cached block is a rough average.
+const (
+	utxoCacheEntryMemory  = 200
+	blockCacheEntryMemory = 20_000
+)
+
+// CacheMemoryUsage returns the estimated number of bytes the given cache occupies
+func (s *consensus) CacheMemoryUsage(cache externalapi.ConsensusCache) uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch cache {
+	case externalapi.UTXOCache:
+		return uint64(s.consensusStateStore.VirtualUTXOSetCacheLen()) * utxoCacheEntryMemory
+	case externalapi.BlockCache:
+		return uint64(s.blockStore.CacheLen()) * blockCacheEntryMemory
+	default:
+		return 0
+	}
+}
+
+// SetCacheMemoryLimit limits the given cache to the given number of bytes, and evicts
+// the entries above the limit
+func (s *consensus) SetCacheMemoryLimit(cache externalapi.ConsensusCache, limit uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch cache {
+	case externalapi.UTXOCache:
+		s.consensusStateStore.SetVirtualUTXOSetCacheCapacity(int(limit / utxoCacheEntryMemory))
+	case externalapi.BlockCache:
+		s.blockStore.SetCacheCapacity(int(limit / blockCacheEntryMemory))
+	}
+}
@@ -74,3 +74,272 @@ func TestConsensus_GetBlockInfo(t *testing.T) {
 
 	})
 }
+
+func TestConsensus_GetVirtualUTXOSetSnapshot(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestConsensus_GetVirtualUTXOSetSnapshot")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		scriptPublicKey, _ := testutils.OpTrueScript()
+		addBlock := func() {
+			block, err := tc.BuildBlock(&externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey}, nil)
+			if err != nil {
+				t.Fatalf("BuildBlock: %+v", err)
+			}
+			err = tc.ValidateAndInsertBlock(block, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+		}
+		for i := 0; i < 5; i++ {
+			addBlock()
+		}
+
+		virtualInfo, err := tc.GetVirtualInfo()
+		if err != nil {
+			t.Fatalf("GetVirtualInfo: %+v", err)
+		}
+		expectedUTXOs, err := tc.GetVirtualUTXOs(virtualInfo.ParentHashes, nil, 100_000)
+		if err != nil {
+			t.Fatalf("GetVirtualUTXOs: %+v", err)
+		}
+
+		snapshot, err := tc.GetVirtualUTXOSetSnapshot()
+		if err != nil {
+			t.Fatalf("GetVirtualUTXOSetSnapshot: %+v", err)
+		}
+		defer snapshot.Release()
+
+		// The snapshot is paged through while blocks keep being added. Every page
+		// but the first starts with the last UTXO of the previous page.
+		utxos := make(map[externalapi.DomainOutpoint]externalapi.UTXOEntry)
+		var fromOutpoint *externalapi.DomainOutpoint
+		for {
+			const step = 2
+			page, err := snapshot.VirtualUTXOs(fromOutpoint, step)
+			if err != nil {
+				t.Fatalf("VirtualUTXOs: %+v", err)
+			}
+			for _, pair := range page {
+				utxos[*pair.Outpoint] = pair.UTXOEntry
+			}
+			addBlock()
+			if len(page) < step {
+				break
+			}
+			fromOutpoint = page[len(page)-1].Outpoint
+		}
+
+		if !externalapi.HashesEqual(snapshot.VirtualParents(), virtualInfo.ParentHashes) {
+			t.Fatalf("expected the snapshot virtual parents to be %s but got %s",
+				virtualInfo.ParentHashes, snapshot.VirtualParents())
+		}
+		if len(utxos) != len(expectedUTXOs) {
+			t.Fatalf("expected the snapshot to hold %d UTXOs but got %d", len(expectedUTXOs), len(utxos))
+		}
+		for _, expectedPair := range expectedUTXOs {
+			utxoEntry, ok := utxos[*expectedPair.Outpoint]
+			if !ok || !expectedPair.UTXOEntry.Equal(utxoEntry) {
+				t.Fatalf("the snapshot is missing UTXO %s", expectedPair.Outpoint)
+			}
+		}
+
+		_, err = tc.GetVirtualUTXOs(virtualInfo.ParentHashes, nil, 100_000)
+		if !errors.Is(err, ruleerrors.ErrGetVirtualUTXOsWrongVirtualParents) {
+			t.Fatalf("expected the virtual to change while the snapshot was read, but got %v", err)
+		}
+	})
+}
+
+func TestConsensus_GetVirtualUTXOEntries(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestConsensus_GetVirtualUTXOEntries")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		scriptPublicKey, _ := testutils.OpTrueScript()
+		for i := 0; i < 5; i++ {
+			block, err := tc.BuildBlock(&externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey}, nil)
+			if err != nil {
+				t.Fatalf("BuildBlock: %+v", err)
+			}
+			err = tc.ValidateAndInsertBlock(block, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+		}
+
+		virtualInfo, err := tc.GetVirtualInfo()
+		if err != nil {
+			t.Fatalf("GetVirtualInfo: %+v", err)
+		}
+		virtualUTXOs, err := tc.GetVirtualUTXOs(virtualInfo.ParentHashes, nil, 100_000)
+		if err != nil {
+			t.Fatalf("GetVirtualUTXOs: %+v", err)
+		}
+		if len(virtualUTXOs) == 0 {
+			t.Fatalf("expected the virtual UTXO set to hold UTXOs")
+		}
+
+		missingOutpoint := &externalapi.DomainOutpoint{TransactionID: externalapi.DomainTransactionID{}, Index: 0}
+		outpoints := []*externalapi.DomainOutpoint{missingOutpoint}
+		for _, pair := range virtualUTXOs {
+			outpoints = append(outpoints, pair.Outpoint)
+		}
+
+		entries, virtualSelectedParent, err := tc.GetVirtualUTXOEntries(outpoints)
+		if err != nil {
+			t.Fatalf("GetVirtualUTXOEntries: %+v", err)
+		}
+		if len(entries) != len(outpoints) {
+			t.Fatalf("expected %d entries but got %d", len(outpoints), len(entries))
+		}
+		if entries[0] != nil {
+			t.Fatalf("expected no entry for an outpoint that isn't in the UTXO set")
+		}
+		for i, pair := range virtualUTXOs {
+			if entries[i+1] == nil || !pair.UTXOEntry.Equal(entries[i+1]) {
+				t.Fatalf("unexpected entry for UTXO %s", pair.Outpoint)
+			}
+		}
+		if !virtualSelectedParent.Equal(virtualInfo.ParentHashes[0]) {
+			t.Fatalf("expected the virtual selected parent to be %s but got %s",
+				virtualInfo.ParentHashes[0], virtualSelectedParent)
+		}
+	})
+}
+
+func TestConsensus_BluesBetween(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestConsensus_BluesBetween")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		addBlock := func(parentHashes ...*externalapi.DomainHash) *externalapi.DomainHash {
+			blockHash, _, err := tc.AddBlock(parentHashes, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			return blockHash
+		}
+
+		// genesis <- a <- b <- d
+		//       ^              |
+		//       \---- c <------/
+		genesisHash := consensusConfig.GenesisHash
+		a := addBlock(genesisHash)
+		b := addBlock(a)
+		c := addBlock(genesisHash)
+		d := addBlock(b, c)
+
+		blues, actualHighHash, err := tc.BluesBetween(genesisHash, d, 0)
+		if err != nil {
+			t.Fatalf("BluesBetween: %+v", err)
+		}
+		expectedBlues := []*externalapi.DomainHash{genesisHash, a, b, c}
+		if !externalapi.HashesEqual(blues, expectedBlues) {
+			t.Fatalf("expected the blues %s but got %s", expectedBlues, blues)
+		}
+		if !actualHighHash.Equal(d) {
+			t.Fatalf("expected the actual high hash to be %s but got %s", d, actualHighHash)
+		}
+
+		// The blues are cut at the selected chain block b, since the blues of d
+		// exceed maxBlocks
+		blues, actualHighHash, err = tc.BluesBetween(genesisHash, d, 3)
+		if err != nil {
+			t.Fatalf("BluesBetween: %+v", err)
+		}
+		if !externalapi.HashesEqual(blues, expectedBlues[:2]) {
+			t.Fatalf("expected the blues %s but got %s", expectedBlues[:2], blues)
+		}
+		if !actualHighHash.Equal(b) {
+			t.Fatalf("expected the actual high hash to be %s but got %s", b, actualHighHash)
+		}
+
+		_, _, err = tc.BluesBetween(c, d, 0)
+		if err == nil {
+			t.Fatalf("expected an error for a low hash that is not in the selected parent chain of the high hash")
+		}
+
+		anticone, err := tc.Anticone(c)
+		if err != nil {
+			t.Fatalf("Anticone: %+v", err)
+		}
+		if !externalapi.HashesEqual(anticone, []*externalapi.DomainHash{b, a}) &&
+			!externalapi.HashesEqual(anticone, []*externalapi.DomainHash{a, b}) {
+			t.Fatalf("expected the anticone of c to be a and b but got %s", anticone)
+		}
+	})
+}
+
+func TestConsensus_GetAcceptingBlock(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestConsensus_GetAcceptingBlock")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		addBlock := func(parentHashes ...*externalapi.DomainHash) *externalapi.DomainHash {
+			blockHash, _, err := tc.AddBlock(parentHashes, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			return blockHash
+		}
+
+		// genesis <- a <- b <- d
+		//       ^              |
+		//       \---- c <------/
+		genesisHash := consensusConfig.GenesisHash
+		a := addBlock(genesisHash)
+		b := addBlock(a)
+		c := addBlock(genesisHash)
+		d := addBlock(b, c)
+
+		tests := []struct {
+			name                       string
+			blockHash                  *externalapi.DomainHash
+			expectedAcceptingBlockHash *externalapi.DomainHash
+		}{
+			{name: "genesis", blockHash: genesisHash, expectedAcceptingBlockHash: a},
+			{name: "a", blockHash: a, expectedAcceptingBlockHash: b},
+			{name: "b", blockHash: b, expectedAcceptingBlockHash: d},
+			{name: "c", blockHash: c, expectedAcceptingBlockHash: d},
+		}
+		for _, test := range tests {
+			acceptingBlockHash, found, err := tc.GetAcceptingBlock(test.blockHash)
+			if err != nil {
+				t.Fatalf("%s: GetAcceptingBlock: %+v", test.name, err)
+			}
+			if !found {
+				t.Fatalf("%s: expected an accepting block to be found", test.name)
+			}
+			if !acceptingBlockHash.Equal(test.expectedAcceptingBlockHash) {
+				t.Fatalf("%s: expected the accepting block to be %s but got %s",
+					test.name, test.expectedAcceptingBlockHash, acceptingBlockHash)
+			}
+		}
+
+		// The transactions of the virtual selected parent are accepted only by the virtual
+		_, found, err := tc.GetAcceptingBlock(d)
+		if err != nil {
+			t.Fatalf("GetAcceptingBlock: %+v", err)
+		}
+		if found {
+			t.Fatalf("expected no accepting block for the virtual selected parent")
+		}
+	})
+}
@@ -0,0 +1,288 @@
+package consensus
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+)
+
+// dagSegment identifies one of the parts of the committed consensus state that
+// are guarded by their own lock
+type dagSegment int
+
+// The segments are listed in their lock order. Whoever holds more than one of
+// them must have locked them in this order.
+const (
+	// blockIndexSegment guards the data of the blocks themselves: their headers,
+	// bodies, statuses, relations, GHOSTDAG and reachability data, acceptance data
+	// and the headers selected chain
+	blockIndexSegment dagSegment = iota
+
+	// utxoSegment guards the virtual UTXO set, the UTXO diffs and the multisets,
+	// including the multiset of the virtual, and the pruning point UTXO set
+	utxoSegment
+
+	// virtualSegment guards the state of the virtual: the tips, the data that the
+	// block index keeps for the virtual block, and the pruning point
+	virtualSegment
+
+	dagSegmentCount
+)
+
+// dagSegmentsByName maps the names of the buckets and keys of the consensus stores
+// to the segment that guards them
+var dagSegmentsByName = map[string]dagSegment{
+	"block-headers":                         blockIndexSegment,
+	"block-headers-count":                   blockIndexSegment,
+	"blocks":                                blockIndexSegment,
+	"blocks-count":                          blockIndexSegment,
+	"block-statuses":                        blockIndexSegment,
+	"block-relations":                       blockIndexSegment,
+	"block-ghostdag-data":                   blockIndexSegment,
+	"block-with-trusted-data-ghostdag-data": blockIndexSegment,
+	"reachability-data":                     blockIndexSegment,
+	"reachability-reindex-root":             blockIndexSegment,
+	"acceptance-data":                       blockIndexSegment,
+	"chain-block-hash-by-index":             blockIndexSegment,
+	"chain-block-index-by-hash":             blockIndexSegment,
+	"highest-chain-block-index":             blockIndexSegment,
+	"headers-selected-tip":                  blockIndexSegment,
+	"daa-score":                             blockIndexSegment,
+	"daa-added-blocks":                      blockIndexSegment,
+	"daa-window":                            blockIndexSegment,
+	"merge-depth-roots":                     blockIndexSegment,
+	"finality-points":                       blockIndexSegment,
+	"invalidated-blocks":                    blockIndexSegment,
+
+	"virtual-utxo-set":                 utxoSegment,
+	"importing-pruning-point-utxo-set": utxoSegment,
+	"utxo-diffs":                       utxoSegment,
+	"utxo-diff-children":               utxoSegment,
+	"multisets":                        utxoSegment,
+	"pruning-point-utxo-set":           utxoSegment,
+	"updating-pruning-point-utxo-set":  utxoSegment,
+	"imported-pruning-point-utxos":     utxoSegment,
+	"imported-pruning-point-multiset":  utxoSegment,
+
+	"tips":                         virtualSegment,
+	"pruning-block-index":          virtualSegment,
+	"candidate-pruning-point-hash": virtualSegment,
+	"pruning-point-by-index":       virtualSegment,
+}
+
+// segmentOfKey returns the segment that guards the given key. It returns false if the
+// key doesn't belong to any known store, in which case writing it locks every segment.
+func segmentOfKey(key model.DBKey) (dagSegment, bool) {
+	bucketPath := bytes.TrimSuffix(key.Bucket().Path(), []byte{'/'})
+	bucketName := bucketPath[bytes.LastIndexByte(bucketPath, '/')+1:]
+
+	segment, ok := dagSegmentsByName[string(bucketName)]
+	if !ok {
+		// A key such as the tips isn't kept in a bucket of its own
+		segment, ok = dagSegmentsByName[string(key.Suffix())]
+		return segment, ok
+	}
+	if segment == blockIndexSegment && bytes.Equal(key.Suffix(), model.VirtualBlockHash.ByteSlice()) {
+		return virtualSegment, true
+	}
+	return segment, true
+}
+
+// dagSegments are reader-writer locks over the committed consensus state.
+//
+// The consensus lock serializes the operations that change the consensus, and is
+// held throughout them, including while they validate blocks. These operations only
+// stage their changes, so the committed state stays the same until they commit.
+// Committing locks for writing the segments of the keys that it writes, as it writes
+// them, and holds them until the database transaction is closed. That is the only
+// time the committed state changes. An operation that only reads the committed state
+// therefore doesn't take the consensus lock at all, and locks for reading the segments
+// it reads instead. This way reads wait only for the commits that write what they read,
+// and never for the validation that precedes them.
+//
+// The lock order is: the consensus lock, then writeLock, then the segments in the order
+// of their declaration. The order in which a commit writes its keys isn't known in
+// advance, so a writer may lock the segments out of order. This can't deadlock, since
+// writeLock lets only one writer in at a time, and readers never wait for a segment
+// while they hold another one. A reader holding a segment must never take the consensus
+// lock.
+type dagSegments struct {
+	writeLock sync.Mutex
+	locks     [dagSegmentCount]sync.RWMutex
+}
+
+func newDAGSegments() *dagSegments {
+	return &dagSegments{}
+}
+
+// rLock locks the given segments for reading, in their lock order, and returns a
+// function that unlocks them
+func (ds *dagSegments) rLock(segments ...dagSegment) (unlock func()) {
+	orderedSegments := append([]dagSegment{}, segments...)
+	sort.Slice(orderedSegments, func(i, j int) bool { return orderedSegments[i] < orderedSegments[j] })
+
+	unlock = func() {
+		for i := len(orderedSegments) - 1; i >= 0; i-- {
+			ds.locks[orderedSegments[i]].RUnlock()
+		}
+	}
+
+	for {
+		lockedCount := 0
+		for _, segment := range orderedSegments {
+			if !ds.locks[segment].TryRLock() {
+				break
+			}
+			lockedCount++
+		}
+		if lockedCount == len(orderedSegments) {
+			return unlock
+		}
+
+		// A writer holds, or waits for, the segment that couldn't be locked. It
+		// might be waiting for a segment that's already locked here as well, so
+		// everything is unlocked before waiting for the writer to be done.
+		for i := lockedCount - 1; i >= 0; i-- {
+			ds.locks[orderedSegments[i]].RUnlock()
+		}
+		busySegment := orderedSegments[lockedCount]
+		ds.locks[busySegment].RLock()
+		ds.locks[busySegment].RUnlock()
+	}
+}
+
+// segmentWriter locks for writing the segments of the keys that are written through it.
+// It must be used by a single writer that holds the writeLock of its segments.
+type segmentWriter struct {
+	segments *dagSegments
+	isLocked [dagSegmentCount]bool
+}
+
+// lockKey locks for writing the segment of the given key, or every segment if the key
+// isn't known, unless they're already locked
+func (sw *segmentWriter) lockKey(key model.DBKey) {
+	segment, ok := segmentOfKey(key)
+	if ok {
+		sw.lock(segment)
+		return
+	}
+	for segment := dagSegment(0); segment < dagSegmentCount; segment++ {
+		sw.lock(segment)
+	}
+}
+
+func (sw *segmentWriter) lock(segment dagSegment) {
+	if sw.isLocked[segment] {
+		return
+	}
+	sw.segments.locks[segment].Lock()
+	sw.isLocked[segment] = true
+}
+
+// unlockAll unlocks every segment that was locked through this segmentWriter
+func (sw *segmentWriter) unlockAll() {
+	for segment := dagSegmentCount - 1; segment >= 0; segment-- {
+		if sw.isLocked[segment] {
+			sw.segments.locks[segment].Unlock()
+			sw.isLocked[segment] = false
+		}
+	}
+}
+
+// lockingDBManager is a model.DBManager that locks for writing the segments of the
+// committed state that are written: for the lifetime of every transaction, and for
+// every write made outside a transaction.
+type lockingDBManager struct {
+	model.DBManager
+	segments *dagSegments
+}
+
+func newLockingDBManager(dbManager model.DBManager, segments *dagSegments) model.DBManager {
+	return &lockingDBManager{
+		DBManager: dbManager,
+		segments:  segments,
+	}
+}
+
+func (ldm *lockingDBManager) Put(key model.DBKey, value []byte) error {
+	ldm.segments.writeLock.Lock()
+	defer ldm.segments.writeLock.Unlock()
+
+	writer := &segmentWriter{segments: ldm.segments}
+	writer.lockKey(key)
+	defer writer.unlockAll()
+
+	return ldm.DBManager.Put(key, value)
+}
+
+func (ldm *lockingDBManager) Delete(key model.DBKey) error {
+	ldm.segments.writeLock.Lock()
+	defer ldm.segments.writeLock.Unlock()
+
+	writer := &segmentWriter{segments: ldm.segments}
+	writer.lockKey(key)
+	defer writer.unlockAll()
+
+	return ldm.DBManager.Delete(key)
+}
+
+// Begin begins a transaction that holds every segment it writes to until it's
+// committed or rolled back. Transactions must therefore always be closed, even on
+// failure.
+func (ldm *lockingDBManager) Begin() (model.DBTransaction, error) {
+	ldm.segments.writeLock.Lock()
+	dbTx, err := ldm.DBManager.Begin()
+	if err != nil {
+		ldm.segments.writeLock.Unlock()
+		return nil, err
+	}
+	return &lockingDBTransaction{
+		DBTransaction: dbTx,
+		segments:      ldm.segments,
+		writer:        &segmentWriter{segments: ldm.segments},
+	}, nil
+}
+
+type lockingDBTransaction struct {
+	model.DBTransaction
+	segments   *dagSegments
+	writer     *segmentWriter
+	unlockOnce sync.Once
+}
+
+func (ldt *lockingDBTransaction) Put(key model.DBKey, value []byte) error {
+	ldt.writer.lockKey(key)
+	return ldt.DBTransaction.Put(key, value)
+}
+
+func (ldt *lockingDBTransaction) Delete(key model.DBKey) error {
+	ldt.writer.lockKey(key)
+	return ldt.DBTransaction.Delete(key)
+}
+
+func (ldt *lockingDBTransaction) unlock() {
+	ldt.unlockOnce.Do(func() {
+		ldt.writer.unlockAll()
+		ldt.segments.writeLock.Unlock()
+	})
+}
+
+func (ldt *lockingDBTransaction) Commit() error {
+	defer ldt.unlock()
+
+	return ldt.DBTransaction.Commit()
+}
+
+func (ldt *lockingDBTransaction) Rollback() error {
+	defer ldt.unlock()
+
+	return ldt.DBTransaction.Rollback()
+}
+
+func (ldt *lockingDBTransaction) RollbackUnlessClosed() error {
+	defer ldt.unlock()
+
+	return ldt.DBTransaction.RollbackUnlessClosed()
+}
@@ -0,0 +1,319 @@
+package consensus
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	consensusdatabase "github.com/kaspanet/kaspad/domain/consensus/database"
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+func TestReadsDontWaitForConsensusLock(t *testing.T) {
+	consensusConfig := &Config{Params: dagconfig.DevnetParams}
+	tc, teardown, err := NewFactory().NewTestConsensus(consensusConfig, "TestReadsDontWaitForConsensusLock")
+	if err != nil {
+		t.Fatalf("Error setting up consensus: %+v", err)
+	}
+	defer teardown(false)
+
+	// Holding the consensus lock stands for a block that takes long to validate
+	tc.(*testConsensus).lock.Lock()
+	defer tc.(*testConsensus).lock.Unlock()
+
+	done := make(chan error)
+	go func() {
+		_, err := tc.GetBlockHeader(consensusConfig.GenesisHash)
+		if err != nil {
+			done <- err
+			return
+		}
+		_, err = tc.GetVirtualInfo()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Read failed: %+v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Reads are blocked by the consensus lock")
+	}
+}
+
+func TestReadsDuringBlockInsertion(t *testing.T) {
+	consensusConfig := &Config{Params: dagconfig.DevnetParams}
+	consensusConfig.SkipProofOfWork = true
+	tc, teardown, err := NewFactory().NewTestConsensus(consensusConfig, "TestReadsDuringBlockInsertion")
+	if err != nil {
+		t.Fatalf("Error setting up consensus: %+v", err)
+	}
+	defer teardown(false)
+
+	const blockCount = 20
+	stop := make(chan struct{})
+	readErrors := make(chan error, 1)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			tips, err := tc.Tips()
+			if err != nil {
+				readErrors <- err
+				return
+			}
+			for _, tip := range tips {
+				info, err := tc.GetBlockInfo(tip)
+				if err != nil {
+					readErrors <- err
+					return
+				}
+				if !info.Exists {
+					readErrors <- errors.Errorf("tip %s doesn't exist", tip)
+					return
+				}
+			}
+			_, err = tc.GetVirtualInfo()
+			if err != nil {
+				readErrors <- err
+				return
+			}
+		}
+	}()
+
+	tipHash := consensusConfig.GenesisHash
+	for i := 0; i < blockCount; i++ {
+		tipHash, _, err = tc.AddBlock([]*externalapi.DomainHash{tipHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	select {
+	case err := <-readErrors:
+		t.Fatalf("Read failed while blocks were inserted: %+v", err)
+	default:
+	}
+}
+
+func TestSegmentOfKey(t *testing.T) {
+	prefixBucket := consensusdatabase.MakeBucket([]byte{0})
+	blockHash := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{1})
+
+	tests := []struct {
+		name            string
+		key             model.DBKey
+		expectedSegment dagSegment
+		expectedOK      bool
+	}{
+		{"header", prefixBucket.Bucket([]byte("block-headers")).Key(blockHash.ByteSlice()), blockIndexSegment, true},
+		{"header count", prefixBucket.Key([]byte("block-headers-count")), blockIndexSegment, true},
+		{"relations of a level", prefixBucket.Bucket([]byte{1}).Bucket([]byte("block-relations")).Key(blockHash.ByteSlice()),
+			blockIndexSegment, true},
+		{"GHOSTDAG data of the virtual", prefixBucket.Bucket([]byte{0}).Bucket([]byte("block-ghostdag-data")).
+			Key(model.VirtualBlockHash.ByteSlice()), virtualSegment, true},
+		{"multiset of the virtual", prefixBucket.Bucket([]byte("multisets")).Key(model.VirtualBlockHash.ByteSlice()),
+			utxoSegment, true},
+		{"UTXO", prefixBucket.Bucket([]byte("virtual-utxo-set")).Key([]byte{1, 2, 3}), utxoSegment, true},
+		{"tips", prefixBucket.Key([]byte("tips")), virtualSegment, true},
+		{"pruning point", prefixBucket.Key([]byte("pruning-block-index")), virtualSegment, true},
+		{"unknown", prefixBucket.Bucket([]byte("unknown")).Key(blockHash.ByteSlice()), 0, false},
+	}
+	for _, test := range tests {
+		segment, ok := segmentOfKey(test.key)
+		if ok != test.expectedOK || (ok && segment != test.expectedSegment) {
+			t.Errorf("%s: expected segment %d (%t) but got %d (%t)",
+				test.name, test.expectedSegment, test.expectedOK, segment, ok)
+		}
+	}
+}
+
+// recordingDBManager records the keys that are written through it
+type recordingDBManager struct {
+	model.DBManager
+	lock sync.Mutex
+	keys []model.DBKey
+}
+
+func (rdm *recordingDBManager) record(key model.DBKey) {
+	rdm.lock.Lock()
+	defer rdm.lock.Unlock()
+	rdm.keys = append(rdm.keys, key)
+}
+
+func (rdm *recordingDBManager) Put(key model.DBKey, value []byte) error {
+	rdm.record(key)
+	return rdm.DBManager.Put(key, value)
+}
+
+func (rdm *recordingDBManager) Delete(key model.DBKey) error {
+	rdm.record(key)
+	return rdm.DBManager.Delete(key)
+}
+
+func (rdm *recordingDBManager) Begin() (model.DBTransaction, error) {
+	dbTx, err := rdm.DBManager.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &recordingDBTransaction{DBTransaction: dbTx, manager: rdm}, nil
+}
+
+type recordingDBTransaction struct {
+	model.DBTransaction
+	manager *recordingDBManager
+}
+
+func (rdt *recordingDBTransaction) Put(key model.DBKey, value []byte) error {
+	rdt.manager.record(key)
+	return rdt.DBTransaction.Put(key, value)
+}
+
+func (rdt *recordingDBTransaction) Delete(key model.DBKey) error {
+	rdt.manager.record(key)
+	return rdt.DBTransaction.Delete(key)
+}
+
+func TestCommittedKeysBelongToSegments(t *testing.T) {
+	consensusConfig := &Config{Params: dagconfig.DevnetParams}
+	consensusConfig.SkipProofOfWork = true
+	tc, teardown, err := NewFactory().NewTestConsensus(consensusConfig, "TestCommittedKeysBelongToSegments")
+	if err != nil {
+		t.Fatalf("Error setting up consensus: %+v", err)
+	}
+	defer teardown(false)
+
+	lockingDBManager := tc.(*testConsensus).databaseContext.(*lockingDBManager)
+	recorder := &recordingDBManager{DBManager: lockingDBManager.DBManager}
+	lockingDBManager.DBManager = recorder
+
+	tipHash := consensusConfig.GenesisHash
+	for i := 0; i < 10; i++ {
+		tipHash, _, err = tc.AddBlock([]*externalapi.DomainHash{tipHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+	}
+
+	if len(recorder.keys) == 0 {
+		t.Fatalf("No keys were written")
+	}
+	for _, key := range recorder.keys {
+		if _, ok := segmentOfKey(key); !ok {
+			t.Errorf("Key %q doesn't belong to any segment", key.Bytes())
+		}
+	}
+}
+
+func TestCommitsLockOnlyTheirSegments(t *testing.T) {
+	consensusConfig := &Config{Params: dagconfig.DevnetParams}
+	tc, teardown, err := NewFactory().NewTestConsensus(consensusConfig, "TestCommitsLockOnlyTheirSegments")
+	if err != nil {
+		t.Fatalf("Error setting up consensus: %+v", err)
+	}
+	defer teardown(false)
+
+	segments := tc.(*testConsensus).segments
+	dbTx, err := tc.(*testConsensus).databaseContext.Begin()
+	if err != nil {
+		t.Fatalf("Begin: %+v", err)
+	}
+	prefixBucket := consensusdatabase.MakeBucket([]byte{0})
+	err = dbTx.Put(prefixBucket.Bucket([]byte("virtual-utxo-set")).Key([]byte{1, 2, 3}), []byte{1})
+	if err != nil {
+		t.Fatalf("Put: %+v", err)
+	}
+
+	// The transaction only wrote to the UTXO segment
+	blockIndexLocked := make(chan struct{})
+	go func() {
+		unlock := segments.rLock(blockIndexSegment, virtualSegment)
+		unlock()
+		close(blockIndexLocked)
+	}()
+	select {
+	case <-blockIndexLocked:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Reading the block index waits for a transaction that doesn't write to it")
+	}
+
+	utxoLocked := make(chan struct{})
+	go func() {
+		unlock := segments.rLock(blockIndexSegment, utxoSegment)
+		unlock()
+		close(utxoLocked)
+	}()
+	select {
+	case <-utxoLocked:
+		t.Fatalf("Reading the UTXO set doesn't wait for a transaction that writes to it")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	err = dbTx.Rollback()
+	if err != nil {
+		t.Fatalf("Rollback: %+v", err)
+	}
+	select {
+	case <-utxoLocked:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Reading the UTXO set waits after the transaction was rolled back")
+	}
+}
+
+func TestSegmentsLockedOutOfOrder(t *testing.T) {
+	segments := newDAGSegments()
+	prefixBucket := consensusdatabase.MakeBucket([]byte{0})
+	virtualKey := prefixBucket.Key([]byte("tips"))
+	blockIndexKey := prefixBucket.Key([]byte("block-headers-count"))
+
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				unlock := segments.rLock(blockIndexSegment, utxoSegment, virtualSegment)
+				unlock()
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			segments.writeLock.Lock()
+			writer := &segmentWriter{segments: segments}
+			writer.lockKey(virtualKey)
+			writer.lockKey(blockIndexKey)
+			writer.unlockAll()
+			segments.writeLock.Unlock()
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatalf("A writer that locks the segments out of order deadlocked with the readers")
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,174 @@
+package dagtest
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// GHOSTDAGData returns the GHOSTDAG data of the block with the given name
+func (dag *DAG) GHOSTDAGData(name string) *externalapi.BlockGHOSTDAGData {
+	dag.t.Helper()
+
+	ghostdagData, err := dag.consensus.GHOSTDAGDataStore().Get(dag.consensus.DatabaseContext(),
+		model.NewStagingArea(), dag.Hash(name), false)
+	if err != nil {
+		dag.t.Fatalf("Error getting the GHOSTDAG data of block %s: %+v", name, err)
+	}
+	return ghostdagData
+}
+
+// AssertBlueScore fails the test if the blue score of the block with the given name
+// isn't the expected one
+func (dag *DAG) AssertBlueScore(name string, expected uint64) {
+	dag.t.Helper()
+
+	blueScore := dag.GHOSTDAGData(name).BlueScore()
+	if blueScore != expected {
+		dag.t.Fatalf("Expected the blue score of block %s to be %d but got %d", name, expected, blueScore)
+	}
+}
+
+// AssertSelectedParent fails the test if the selected parent of the block with the
+// given name isn't the expected one
+func (dag *DAG) AssertSelectedParent(name string, expected string) {
+	dag.t.Helper()
+
+	selectedParent := dag.Name(dag.GHOSTDAGData(name).SelectedParent())
+	if selectedParent != expected {
+		dag.t.Fatalf("Expected the selected parent of block %s to be %s but got %s", name, expected, selectedParent)
+	}
+}
+
+// AssertMergeSet fails the test if the blues and the reds of the merge set of the
+// block with the given name aren't the expected ones. The selected parent is one of
+// the blues. The order of the names doesn't matter.
+func (dag *DAG) AssertMergeSet(name string, expectedBlues []string, expectedReds []string) {
+	dag.t.Helper()
+
+	ghostdagData := dag.GHOSTDAGData(name)
+	blues := dag.sortedNames(ghostdagData.MergeSetBlues())
+	reds := dag.sortedNames(ghostdagData.MergeSetReds())
+	if !equalNames(blues, sortNames(expectedBlues)) || !equalNames(reds, sortNames(expectedReds)) {
+		dag.t.Fatalf("Expected the merge set of block %s to have the blues %s and the reds %s but got "+
+			"the blues %s and the reds %s", name, sortNames(expectedBlues), sortNames(expectedReds), blues, reds)
+	}
+}
+
+// AssertVirtualParents fails the test if the parents of the virtual aren't the
+// expected ones. The order of the names doesn't matter.
+func (dag *DAG) AssertVirtualParents(expected ...string) {
+	dag.t.Helper()
+
+	virtualInfo, err := dag.consensus.GetVirtualInfo()
+	if err != nil {
+		dag.t.Fatalf("Error getting the virtual info: %+v", err)
+	}
+	parents := dag.sortedNames(virtualInfo.ParentHashes)
+	if !equalNames(parents, sortNames(expected)) {
+		dag.t.Fatalf("Expected the virtual parents to be %s but got %s", sortNames(expected), parents)
+	}
+}
+
+// AssertVirtualSelectedParent fails the test if the selected parent of the virtual
+// isn't the expected one
+func (dag *DAG) AssertVirtualSelectedParent(expected string) {
+	dag.t.Helper()
+
+	virtualSelectedParent, err := dag.consensus.GetVirtualSelectedParent()
+	if err != nil {
+		dag.t.Fatalf("Error getting the virtual selected parent: %+v", err)
+	}
+	if dag.Name(virtualSelectedParent) != expected {
+		dag.t.Fatalf("Expected the virtual selected parent to be %s but got %s",
+			expected, dag.Name(virtualSelectedParent))
+	}
+}
+
+// AssertVirtualBlueScore fails the test if the blue score of the virtual isn't the
+// expected one
+func (dag *DAG) AssertVirtualBlueScore(expected uint64) {
+	dag.t.Helper()
+
+	virtualInfo, err := dag.consensus.GetVirtualInfo()
+	if err != nil {
+		dag.t.Fatalf("Error getting the virtual info: %+v", err)
+	}
+	if virtualInfo.BlueScore != expected {
+		dag.t.Fatalf("Expected the virtual blue score to be %d but got %d", expected, virtualInfo.BlueScore)
+	}
+}
+
+// AssertVirtualUTXO fails the test if whether the UTXO set of the virtual contains
+// the given outpoint isn't the expected one
+func (dag *DAG) AssertVirtualUTXO(outpoint *externalapi.DomainOutpoint, expectedContained bool) {
+	dag.t.Helper()
+
+	contained, err := dag.consensus.ConsensusStateStore().HasUTXOByOutpoint(dag.consensus.DatabaseContext(),
+		model.NewStagingArea(), outpoint)
+	if err != nil {
+		dag.t.Fatalf("Error looking up %s in the virtual UTXO set: %+v", outpoint, err)
+	}
+	if contained != expectedContained {
+		dag.t.Fatalf("Expected the virtual UTXO set to contain %s: %t, but got %t", outpoint, expectedContained, contained)
+	}
+}
+
+// AssertUTXODiff fails the test if the outpoints that the given UTXO diff adds and
+// removes aren't the expected ones. The order of the outpoints doesn't matter.
+func (dag *DAG) AssertUTXODiff(utxoDiff externalapi.UTXODiff, expectedToAdd []*externalapi.DomainOutpoint,
+	expectedToRemove []*externalapi.DomainOutpoint) {
+
+	dag.t.Helper()
+
+	toAdd := dag.sortedOutpoints(utxoDiff.ToAdd())
+	toRemove := dag.sortedOutpoints(utxoDiff.ToRemove())
+	if !equalNames(toAdd, sortOutpoints(expectedToAdd)) || !equalNames(toRemove, sortOutpoints(expectedToRemove)) {
+		dag.t.Fatalf("Expected the UTXO diff to add %s and remove %s but it adds %s and removes %s",
+			sortOutpoints(expectedToAdd), sortOutpoints(expectedToRemove), toAdd, toRemove)
+	}
+}
+
+func (dag *DAG) sortedNames(hashes []*externalapi.DomainHash) []string {
+	names := make([]string, len(hashes))
+	for i, hash := range hashes {
+		names[i] = dag.Name(hash)
+	}
+	return sortNames(names)
+}
+
+func (dag *DAG) sortedOutpoints(utxoCollection externalapi.UTXOCollection) []string {
+	dag.t.Helper()
+
+	var outpoints []*externalapi.DomainOutpoint
+	iterator := utxoCollection.Iterator()
+	defer iterator.Close()
+	for ok := iterator.First(); ok; ok = iterator.Next() {
+		outpoint, _, err := iterator.Get()
+		if err != nil {
+			dag.t.Fatalf("Error iterating over a UTXO collection: %+v", err)
+		}
+		outpoints = append(outpoints, outpoint)
+	}
+	return sortOutpoints(outpoints)
+}
+
+func sortOutpoints(outpoints []*externalapi.DomainOutpoint) []string {
+	outpointStrings := make([]string, len(outpoints))
+	for i, outpoint := range outpoints {
+		outpointStrings[i] = outpoint.String()
+	}
+	return sortNames(outpointStrings)
+}
+
+func sortNames(names []string) []string {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func equalNames(a []string, b []string) bool {
+	return strings.Join(a, ",") == strings.Join(b, ",")
+}
@@ -0,0 +1,199 @@
+/*
+Package dagtest builds deterministic DAGs for consensus tests.
+
+A DAG is built block by block on a test consensus that runs against a real
+database. Every block is given a name and its parents are chosen explicitly by
+their names, so a test can describe an arbitrary topology without handling
+hashes. Proof of work isn't checked and block timestamps are derived from the
+past median time of the parents, so building the same topology always yields
+the same blocks. The name of a block is committed to by its coinbase, so blocks
+with the same parents and transactions are still distinct.
+
+	dag := dagtest.New(t, &dagconfig.SimnetParams)
+	dag.AddBlock("A", dagtest.GenesisName)
+	dag.AddBlock("B", dagtest.GenesisName)
+	dag.AddBlock("C", "A", "B")
+	dag.AssertBlueScore("C", 3)
+	dag.AssertVirtualParents("C")
+*/
+package dagtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/model/testapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+)
+
+// GenesisName is the name of the genesis block of every DAG
+const GenesisName = "genesis"
+
+// DAG is a DAG of named blocks, built on a test consensus
+type DAG struct {
+	t         testing.TB
+	consensus testapi.TestConsensus
+	hashes    map[string]*externalapi.DomainHash
+	names     map[externalapi.DomainHash]string
+}
+
+// New creates a DAG on a new test consensus with the given params. The DAG
+// contains only the genesis block, and is torn down when the test ends.
+func New(t testing.TB, params *dagconfig.Params) *DAG {
+	t.Helper()
+
+	consensusConfig := &consensus.Config{Params: *params}
+	consensusConfig.SkipProofOfWork = true
+	// The name of a subtest contains slashes, which can't be a part of the name of
+	// the data directory
+	testName := strings.ReplaceAll(t.Name(), "/", "_")
+	testConsensus, teardown, err := consensus.NewFactory().NewTestConsensus(consensusConfig, testName)
+	if err != nil {
+		t.Fatalf("Error setting up the test consensus: %+v", err)
+	}
+	t.Cleanup(func() {
+		teardown(false)
+	})
+
+	dag := &DAG{
+		t:         t,
+		consensus: testConsensus,
+		hashes:    map[string]*externalapi.DomainHash{},
+		names:     map[externalapi.DomainHash]string{},
+	}
+	dag.name(GenesisName, params.GenesisHash)
+	return dag
+}
+
+// Consensus returns the test consensus that the DAG is built on
+func (dag *DAG) Consensus() testapi.TestConsensus {
+	return dag.consensus
+}
+
+// AddBlock adds a block with the given name, the given parents and no
+// transactions other than its coinbase
+func (dag *DAG) AddBlock(name string, parentNames ...string) *externalapi.VirtualChangeSet {
+	dag.t.Helper()
+	return dag.AddBlockWithTransactions(name, parentNames)
+}
+
+// AddBlockWithTransactions adds a block with the given name, the given parents
+// and the given transactions. The coinbase of the block pays to an OP_TRUE
+// script, which testutils.CreateTransaction knows how to spend.
+func (dag *DAG) AddBlockWithTransactions(name string, parentNames []string,
+	transactions ...*externalapi.DomainTransaction) *externalapi.VirtualChangeSet {
+
+	dag.t.Helper()
+	dag.requireUnused(name)
+
+	scriptPublicKey, _ := testutils.OpTrueScript()
+	coinbaseData := &externalapi.DomainCoinbaseData{
+		ScriptPublicKey: scriptPublicKey,
+		ExtraData:       []byte(name),
+	}
+	blockHash, virtualChangeSet, err := dag.consensus.AddBlock(dag.Hashes(parentNames...), coinbaseData, transactions)
+	if err != nil {
+		dag.t.Fatalf("Error adding block %s: %+v", name, err)
+	}
+	dag.name(name, blockHash)
+	return virtualChangeSet
+}
+
+// AddUTXOInvalidBlock adds a block with the given name and the given parents,
+// whose UTXO commitment is wrong
+func (dag *DAG) AddUTXOInvalidBlock(name string, parentNames ...string) *externalapi.VirtualChangeSet {
+	dag.t.Helper()
+	dag.requireUnused(name)
+
+	blockHash, virtualChangeSet, err := dag.consensus.AddUTXOInvalidBlock(dag.Hashes(parentNames...))
+	if err != nil {
+		dag.t.Fatalf("Error adding UTXO invalid block %s: %+v", name, err)
+	}
+	dag.name(name, blockHash)
+	return virtualChangeSet
+}
+
+// AddChain adds a chain of blocks with the given names on top of the given parent,
+// and returns the name of its last block
+func (dag *DAG) AddChain(parentName string, names ...string) string {
+	dag.t.Helper()
+
+	for _, name := range names {
+		dag.AddBlock(name, parentName)
+		parentName = name
+	}
+	return parentName
+}
+
+// Hash returns the hash of the block with the given name
+func (dag *DAG) Hash(name string) *externalapi.DomainHash {
+	dag.t.Helper()
+
+	hash, ok := dag.hashes[name]
+	if !ok {
+		dag.t.Fatalf("There's no block named %s", name)
+	}
+	return hash
+}
+
+// Hashes returns the hashes of the blocks with the given names
+func (dag *DAG) Hashes(names ...string) []*externalapi.DomainHash {
+	dag.t.Helper()
+
+	hashes := make([]*externalapi.DomainHash, len(names))
+	for i, name := range names {
+		hashes[i] = dag.Hash(name)
+	}
+	return hashes
+}
+
+// Name returns the name of the block with the given hash. Blocks that weren't
+// added through the DAG are named by their hashes.
+func (dag *DAG) Name(hash *externalapi.DomainHash) string {
+	name, ok := dag.names[*hash]
+	if !ok {
+		return hash.String()
+	}
+	return name
+}
+
+// Block returns the block with the given name
+func (dag *DAG) Block(name string) *externalapi.DomainBlock {
+	dag.t.Helper()
+
+	block, found, err := dag.consensus.GetBlock(dag.Hash(name))
+	if err != nil {
+		dag.t.Fatalf("Error getting block %s: %+v", name, err)
+	}
+	if !found {
+		dag.t.Fatalf("Block %s has no body", name)
+	}
+	return block
+}
+
+// CoinbaseOutpoint returns the outpoint of the first output of the coinbase of
+// the block with the given name
+func (dag *DAG) CoinbaseOutpoint(name string) *externalapi.DomainOutpoint {
+	dag.t.Helper()
+
+	coinbase := dag.Block(name).Transactions[transactionhelper.CoinbaseTransactionIndex]
+	return externalapi.NewDomainOutpoint(consensushashing.TransactionID(coinbase), 0)
+}
+
+func (dag *DAG) requireUnused(name string) {
+	dag.t.Helper()
+
+	if _, ok := dag.hashes[name]; ok {
+		dag.t.Fatalf("There's already a block named %s", name)
+	}
+}
+
+func (dag *DAG) name(name string, hash *externalapi.DomainHash) {
+	dag.hashes[name] = hash
+	dag.names[*hash] = name
+}
@@ -0,0 +1,74 @@
+package dagtest_test
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/dagtest"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+)
+
+func buildDAG(t *testing.T) *dagtest.DAG {
+	params := dagconfig.SimnetParams
+	params.BlockCoinbaseMaturity = 0
+	dag := dagtest.New(t, &params)
+
+	// genesis <- A <- B <- D
+	//        \          /
+	//         <-- C <--
+	dag.AddChain(dagtest.GenesisName, "A", "B")
+	dag.AddBlock("C", dagtest.GenesisName)
+	dag.AddBlock("D", "B", "C")
+	return dag
+}
+
+func TestDAG(t *testing.T) {
+	dag := buildDAG(t)
+
+	dag.AssertBlueScore("A", 1)
+	dag.AssertBlueScore("C", 1)
+	dag.AssertBlueScore("D", 4)
+	dag.AssertSelectedParent("D", "B")
+	dag.AssertMergeSet("D", []string{"B", "C"}, nil)
+	dag.AssertVirtualParents("D")
+	dag.AssertVirtualSelectedParent("D")
+	dag.AssertVirtualBlueScore(5)
+
+	spendingTransaction, err := testutils.CreateTransaction(
+		dag.Block("B").Transactions[0], 1000)
+	if err != nil {
+		t.Fatalf("Error creating the transaction: %+v", err)
+	}
+	virtualChangeSet := dag.AddBlockWithTransactions("E", []string{"D"}, spendingTransaction)
+
+	spendingTransactionOutpoint := externalapi.NewDomainOutpoint(
+		consensushashing.TransactionID(spendingTransaction), 0)
+	dag.AssertUTXODiff(virtualChangeSet.VirtualUTXODiff,
+		[]*externalapi.DomainOutpoint{dag.CoinbaseOutpoint("E"), spendingTransactionOutpoint},
+		[]*externalapi.DomainOutpoint{dag.CoinbaseOutpoint("B")})
+	dag.AssertVirtualUTXO(dag.CoinbaseOutpoint("B"), false)
+	dag.AssertVirtualUTXO(spendingTransactionOutpoint, true)
+}
+
+func TestDAGIsDeterministic(t *testing.T) {
+	dag := buildDAG(t)
+	otherDAG := buildDAG(t)
+
+	for _, name := range []string{"A", "B", "C", "D"} {
+		if !dag.Hash(name).Equal(otherDAG.Hash(name)) {
+			t.Fatalf("Block %s has the hash %s in one DAG but %s in the other", name, dag.Hash(name),
+				otherDAG.Hash(name))
+		}
+		if dag.Name(dag.Hash(name)) != name {
+			t.Fatalf("Expected the block with the hash of %s to be named %s but got %s", name, name,
+				dag.Name(dag.Hash(name)))
+		}
+	}
+
+	// Blocks with the same parents differ by their names
+	if dag.Hash("A").Equal(dag.Hash("C")) {
+		t.Fatalf("Blocks A and C have the same hash")
+	}
+}
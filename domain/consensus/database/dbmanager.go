@@ -42,6 +42,14 @@ func (dbw *dbManager) Begin() (model.DBTransaction, error) {
 	return newDBTransaction(transaction), nil
 }
 
+func (dbw *dbManager) Snapshot() (model.DBSnapshot, error) {
+	snapshot, err := dbw.db.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return newDBSnapshot(snapshot), nil
+}
+
 // New returns wraps the given database as an instance of model.DBManager
 func New(db database.Database) model.DBManager {
 	return &dbManager{db: db}
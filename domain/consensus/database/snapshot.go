@@ -0,0 +1,34 @@
+package database
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+type dbSnapshot struct {
+	snapshot database.Snapshot
+}
+
+func newDBSnapshot(snapshot database.Snapshot) model.DBSnapshot {
+	return &dbSnapshot{snapshot: snapshot}
+}
+
+func (d *dbSnapshot) Get(key model.DBKey) ([]byte, error) {
+	return d.snapshot.Get(dbKeyToDatabaseKey(key))
+}
+
+func (d *dbSnapshot) Has(key model.DBKey) (bool, error) {
+	return d.snapshot.Has(dbKeyToDatabaseKey(key))
+}
+
+func (d *dbSnapshot) Cursor(bucket model.DBBucket) (model.DBCursor, error) {
+	cursor, err := d.snapshot.Cursor(dbBucketToDatabaseBucket(bucket))
+	if err != nil {
+		return nil, err
+	}
+	return newDBCursor(cursor), nil
+}
+
+func (d *dbSnapshot) Release() {
+	d.snapshot.Release()
+}
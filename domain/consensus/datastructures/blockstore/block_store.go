@@ -237,3 +237,13 @@ func (bs *blockStore) AllBlockHashesIterator(dbContext model.DBReader) (model.Bl
 
 	return &allBlockHashesIterator{cursor: cursor}, nil
 }
+
+// CacheLen returns the number of blocks in the cache of the store
+func (bs *blockStore) CacheLen() int {
+	return bs.cache.Len()
+}
+
+// SetCacheCapacity changes the number of blocks the cache of the store may hold
+func (bs *blockStore) SetCacheCapacity(capacity int) {
+	bs.cache.SetCapacity(capacity)
+}
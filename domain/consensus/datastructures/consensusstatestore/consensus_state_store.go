@@ -1,6 +1,8 @@
 package consensusstatestore
 
 import (
+	"sync"
+
 	"github.com/kaspanet/kaspad/domain/consensus/model"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/utxolrucache"
@@ -15,6 +17,9 @@ type consensusStateStore struct {
 	virtualUTXOSetCache             *utxolrucache.LRUCache
 	tipsCache                       []*externalapi.DomainHash
 	tipsKey                         model.DBKey
+	invalidatedBlocksCache          []*externalapi.DomainHash
+	invalidatedBlocksKey            model.DBKey
+	cacheLock                       sync.RWMutex
 	utxoSetBucket                   model.DBBucket
 	importingPruningPointUTXOSetKey model.DBKey
 }
@@ -25,6 +30,7 @@ func New(prefixBucket model.DBBucket, utxoSetCacheSize int, preallocate bool) mo
 		shardID:                         staging.GenerateShardingID(),
 		virtualUTXOSetCache:             utxolrucache.New(utxoSetCacheSize, preallocate),
 		tipsKey:                         prefixBucket.Key(tipsKeyName),
+		invalidatedBlocksKey:            prefixBucket.Key(invalidatedBlocksKeyName),
 		importingPruningPointUTXOSetKey: prefixBucket.Key(importingPruningPointUTXOSetKeyName),
 		utxoSetBucket:                   prefixBucket.Bucket(utxoSetBucketName),
 	}
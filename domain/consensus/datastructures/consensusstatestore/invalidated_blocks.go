@@ -0,0 +1,79 @@
+package consensusstatestore
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/database"
+	"github.com/kaspanet/kaspad/domain/consensus/database/binaryserialization"
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+var invalidatedBlocksKeyName = []byte("invalidated-blocks")
+
+// InvalidatedBlocks returns the blocks that were invalidated manually. Their future
+// is excluded from the past of the virtual.
+func (css *consensusStateStore) InvalidatedBlocks(stagingArea *model.StagingArea, dbContext model.DBReader) (
+	[]*externalapi.DomainHash, error) {
+
+	stagingShard := css.stagingShard(stagingArea)
+
+	if stagingShard.invalidatedBlocksStaging != nil {
+		return externalapi.CloneHashes(stagingShard.invalidatedBlocksStaging), nil
+	}
+
+	if cachedInvalidatedBlocks := css.cachedInvalidatedBlocks(); cachedInvalidatedBlocks != nil {
+		return externalapi.CloneHashes(cachedInvalidatedBlocks), nil
+	}
+
+	invalidatedBlocksBytes, err := dbContext.Get(css.invalidatedBlocksKey)
+	if database.IsNotFoundError(err) {
+		css.setCachedInvalidatedBlocks([]*externalapi.DomainHash{})
+		return []*externalapi.DomainHash{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	invalidatedBlocks, err := binaryserialization.DeserializeHashes(invalidatedBlocksBytes)
+	if err != nil {
+		return nil, err
+	}
+	css.setCachedInvalidatedBlocks(invalidatedBlocks)
+	return externalapi.CloneHashes(invalidatedBlocks), nil
+}
+
+func (css *consensusStateStore) cachedInvalidatedBlocks() []*externalapi.DomainHash {
+	css.cacheLock.RLock()
+	defer css.cacheLock.RUnlock()
+
+	return css.invalidatedBlocksCache
+}
+
+func (css *consensusStateStore) setCachedInvalidatedBlocks(invalidatedBlocks []*externalapi.DomainHash) {
+	css.cacheLock.Lock()
+	defer css.cacheLock.Unlock()
+
+	css.invalidatedBlocksCache = invalidatedBlocks
+}
+
+func (css *consensusStateStore) StageInvalidatedBlocks(stagingArea *model.StagingArea,
+	invalidatedBlocks []*externalapi.DomainHash) {
+
+	stagingShard := css.stagingShard(stagingArea)
+
+	stagingShard.invalidatedBlocksStaging = externalapi.CloneHashes(invalidatedBlocks)
+}
+
+func (csss *consensusStateStagingShard) commitInvalidatedBlocks(dbTx model.DBTransaction) error {
+	if csss.invalidatedBlocksStaging == nil {
+		return nil
+	}
+
+	err := dbTx.Put(csss.store.invalidatedBlocksKey,
+		binaryserialization.SerializeHashes(csss.invalidatedBlocksStaging))
+	if err != nil {
+		return err
+	}
+	csss.store.setCachedInvalidatedBlocks(csss.invalidatedBlocksStaging)
+
+	return nil
+}
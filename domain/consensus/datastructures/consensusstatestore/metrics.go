@@ -0,0 +1,10 @@
+package consensusstatestore
+
+import "github.com/kaspanet/kaspad/infrastructure/metrics"
+
+var (
+	utxoCacheHits = metrics.NewCounter("kaspad_utxo_cache_hits_total",
+		"The number of lookups of virtual UTXO entries that were found in the UTXO cache")
+	utxoCacheMisses = metrics.NewCounter("kaspad_utxo_cache_misses_total",
+		"The number of lookups of virtual UTXO entries that had to be read from the database")
+)
@@ -16,8 +16,8 @@ func (css *consensusStateStore) Tips(stagingArea *model.StagingArea, dbContext m
 		return externalapi.CloneHashes(stagingShard.tipsStaging), nil
 	}
 
-	if css.tipsCache != nil {
-		return externalapi.CloneHashes(css.tipsCache), nil
+	if cachedTips := css.cachedTips(); cachedTips != nil {
+		return externalapi.CloneHashes(cachedTips), nil
 	}
 
 	tipsBytes, err := dbContext.Get(css.tipsKey)
@@ -29,10 +29,24 @@ func (css *consensusStateStore) Tips(stagingArea *model.StagingArea, dbContext m
 	if err != nil {
 		return nil, err
 	}
-	css.tipsCache = tips
+	css.setCachedTips(tips)
 	return externalapi.CloneHashes(tips), nil
 }
 
+func (css *consensusStateStore) cachedTips() []*externalapi.DomainHash {
+	css.cacheLock.RLock()
+	defer css.cacheLock.RUnlock()
+
+	return css.tipsCache
+}
+
+func (css *consensusStateStore) setCachedTips(tips []*externalapi.DomainHash) {
+	css.cacheLock.Lock()
+	defer css.cacheLock.Unlock()
+
+	css.tipsCache = tips
+}
+
 func (css *consensusStateStore) StageTips(stagingArea *model.StagingArea, tipHashes []*externalapi.DomainHash) {
 	stagingShard := css.stagingShard(stagingArea)
 
@@ -69,7 +83,7 @@ func (csss *consensusStateStagingShard) commitTips(dbTx model.DBTransaction) err
 	if err != nil {
 		return err
 	}
-	csss.store.tipsCache = csss.tipsStaging
+	csss.store.setCachedTips(csss.tipsStaging)
 
 	return nil
 }
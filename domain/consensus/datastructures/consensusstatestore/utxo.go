@@ -37,8 +37,6 @@ func (csss *consensusStateStagingShard) commitVirtualUTXODiff(dbTx model.DBTrans
 			return err
 		}
 
-		csss.store.virtualUTXOSetCache.Remove(toRemoveOutpoint)
-
 		dbKey, err := csss.store.utxoKey(toRemoveOutpoint)
 		if err != nil {
 			return err
@@ -47,6 +45,7 @@ func (csss *consensusStateStagingShard) commitVirtualUTXODiff(dbTx model.DBTrans
 		if err != nil {
 			return err
 		}
+		csss.store.virtualUTXOSetCache.Remove(toRemoveOutpoint)
 	}
 
 	toAddIterator := csss.virtualUTXODiffStaging.ToAdd().Iterator()
@@ -57,8 +56,6 @@ func (csss *consensusStateStagingShard) commitVirtualUTXODiff(dbTx model.DBTrans
 			return err
 		}
 
-		csss.store.virtualUTXOSetCache.Add(toAddOutpoint, toAddEntry)
-
 		dbKey, err := csss.store.utxoKey(toAddOutpoint)
 		if err != nil {
 			return err
@@ -71,6 +68,7 @@ func (csss *consensusStateStagingShard) commitVirtualUTXODiff(dbTx model.DBTrans
 		if err != nil {
 			return err
 		}
+		csss.store.virtualUTXOSetCache.Add(toAddOutpoint, toAddEntry)
 	}
 
 	// Note: we don't discard the staging here since that's
@@ -99,8 +97,10 @@ func (css *consensusStateStore) utxoByOutpointFromStagedVirtualUTXODiff(dbContex
 	}
 
 	if entry, ok := css.virtualUTXOSetCache.Get(outpoint); ok {
+		utxoCacheHits.Inc()
 		return entry, nil
 	}
+	utxoCacheMisses.Inc()
 
 	key, err := css.utxoKey(outpoint)
 	if err != nil {
@@ -267,3 +267,14 @@ func (u utxoSetIterator) Close() error {
 	u.cursor = nil
 	return nil
 }
+
+// VirtualUTXOSetCacheLen returns the number of UTXO entries in the cache of the virtual UTXO set
+func (css *consensusStateStore) VirtualUTXOSetCacheLen() int {
+	return css.virtualUTXOSetCache.Len()
+}
+
+// SetVirtualUTXOSetCacheCapacity changes the number of UTXO entries the cache of the
+// virtual UTXO set may hold
+func (css *consensusStateStore) SetVirtualUTXOSetCacheCapacity(capacity int) {
+	css.virtualUTXOSetCache.SetCapacity(capacity)
+}
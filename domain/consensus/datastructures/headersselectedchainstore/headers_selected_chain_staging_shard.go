@@ -74,7 +74,7 @@ func (hscss *headersSelectedChainStagingShard) Commit(dbTx model.DBTransaction)
 		return err
 	}
 
-	hscss.store.cacheHighestChainBlockIndex = highestIndex
+	hscss.store.setCachedHighestChainBlockIndex(highestIndex)
 
 	return nil
 }
@@ -2,6 +2,8 @@ package headersselectedchainstore
 
 import (
 	"encoding/binary"
+	"sync"
+
 	"github.com/kaspanet/kaspad/util/staging"
 
 	"github.com/kaspanet/kaspad/domain/consensus/database"
@@ -22,6 +24,7 @@ type headersSelectedChainStore struct {
 	cacheByIndex                *lrucacheuint64tohash.LRUCache
 	cacheByHash                 *lrucache.LRUCache
 	cacheHighestChainBlockIndex uint64
+	cacheLock                   sync.RWMutex
 	bucketChainBlockHashByIndex model.DBBucket
 	bucketChainBlockIndexByHash model.DBBucket
 	highestChainBlockIndexKey   model.DBKey
@@ -157,8 +160,8 @@ func (hscs *headersSelectedChainStore) indexAsKey(index uint64) model.DBKey {
 }
 
 func (hscs *headersSelectedChainStore) highestChainBlockIndex(dbContext model.DBReader) (uint64, bool, error) {
-	if hscs.cacheHighestChainBlockIndex != 0 {
-		return hscs.cacheHighestChainBlockIndex, true, nil
+	if cachedIndex := hscs.cachedHighestChainBlockIndex(); cachedIndex != 0 {
+		return cachedIndex, true, nil
 	}
 
 	indexBytes, err := dbContext.Get(hscs.highestChainBlockIndexKey)
@@ -174,6 +177,20 @@ func (hscs *headersSelectedChainStore) highestChainBlockIndex(dbContext model.DB
 		return 0, false, err
 	}
 
-	hscs.cacheHighestChainBlockIndex = index
+	hscs.setCachedHighestChainBlockIndex(index)
 	return index, true, nil
 }
+
+func (hscs *headersSelectedChainStore) cachedHighestChainBlockIndex() uint64 {
+	hscs.cacheLock.RLock()
+	defer hscs.cacheLock.RUnlock()
+
+	return hscs.cacheHighestChainBlockIndex
+}
+
+func (hscs *headersSelectedChainStore) setCachedHighestChainBlockIndex(index uint64) {
+	hscs.cacheLock.Lock()
+	defer hscs.cacheLock.Unlock()
+
+	hscs.cacheHighestChainBlockIndex = index
+}
@@ -32,7 +32,7 @@ func (hstss *headersSelectedTipStagingShard) Commit(dbTx model.DBTransaction) er
 	if err != nil {
 		return err
 	}
-	hstss.store.cache = hstss.newSelectedTip
+	hstss.store.setCachedSelectedTip(hstss.newSelectedTip)
 
 	return nil
 }
@@ -1,6 +1,8 @@
 package headersselectedtipstore
 
 import (
+	"sync"
+
 	"github.com/golang/protobuf/proto"
 	"github.com/kaspanet/kaspad/domain/consensus/database/serialization"
 	"github.com/kaspanet/kaspad/domain/consensus/model"
@@ -11,9 +13,10 @@ import (
 var keyName = []byte("headers-selected-tip")
 
 type headerSelectedTipStore struct {
-	shardID model.StagingShardID
-	cache   *externalapi.DomainHash
-	key     model.DBKey
+	shardID   model.StagingShardID
+	cache     *externalapi.DomainHash
+	cacheLock sync.RWMutex
+	key       model.DBKey
 }
 
 // New instantiates a new HeaderSelectedTipStore
@@ -31,7 +34,7 @@ func (hsts *headerSelectedTipStore) Has(dbContext model.DBReader, stagingArea *m
 		return true, nil
 	}
 
-	if hsts.cache != nil {
+	if hsts.cachedSelectedTip() != nil {
 		return true, nil
 	}
 
@@ -56,8 +59,8 @@ func (hsts *headerSelectedTipStore) HeadersSelectedTip(dbContext model.DBReader,
 		return stagingShard.newSelectedTip, nil
 	}
 
-	if hsts.cache != nil {
-		return hsts.cache, nil
+	if cachedSelectedTip := hsts.cachedSelectedTip(); cachedSelectedTip != nil {
+		return cachedSelectedTip, nil
 	}
 
 	selectedTipBytes, err := dbContext.Get(hsts.key)
@@ -69,8 +72,22 @@ func (hsts *headerSelectedTipStore) HeadersSelectedTip(dbContext model.DBReader,
 	if err != nil {
 		return nil, err
 	}
+	hsts.setCachedSelectedTip(selectedTip)
+	return selectedTip, nil
+}
+
+func (hsts *headerSelectedTipStore) cachedSelectedTip() *externalapi.DomainHash {
+	hsts.cacheLock.RLock()
+	defer hsts.cacheLock.RUnlock()
+
+	return hsts.cache
+}
+
+func (hsts *headerSelectedTipStore) setCachedSelectedTip(selectedTip *externalapi.DomainHash) {
+	hsts.cacheLock.Lock()
+	defer hsts.cacheLock.Unlock()
+
 	hsts.cache = selectedTip
-	return hsts.cache, nil
 }
 
 func (hsts *headerSelectedTipStore) serializeHeadersSelectedTip(selectedTip *externalapi.DomainHash) ([]byte, error) {
@@ -46,12 +46,7 @@ func (mss *pruningStagingShard) Commit(dbTx model.DBTransaction) error {
 			return err
 		}
 
-		if mss.store.currentPruningPointIndexCache == nil {
-			var zero uint64
-			mss.store.currentPruningPointIndexCache = &zero
-		}
-
-		*mss.store.currentPruningPointIndexCache = *mss.currentPruningPointIndex
+		mss.store.setCachedCurrentPruningPointIndex(*mss.currentPruningPointIndex)
 	}
 
 	if mss.newPruningPointCandidate != nil {
@@ -63,7 +58,7 @@ func (mss *pruningStagingShard) Commit(dbTx model.DBTransaction) error {
 		if err != nil {
 			return err
 		}
-		mss.store.pruningPointCandidateCache = mss.newPruningPointCandidate
+		mss.store.setCachedPruningPointCandidate(mss.newPruningPointCandidate)
 	}
 
 	if mss.startUpdatingPruningPointUTXOSet {
@@ -2,6 +2,8 @@ package pruningstore
 
 import (
 	"encoding/binary"
+	"sync"
+
 	"github.com/golang/protobuf/proto"
 	"github.com/kaspanet/kaspad/domain/consensus/database"
 	"github.com/kaspanet/kaspad/domain/consensus/database/binaryserialization"
@@ -25,6 +27,7 @@ type pruningStore struct {
 	pruningPointByIndexCache      *lrucacheuint64tohash.LRUCache
 	currentPruningPointIndexCache *uint64
 	pruningPointCandidateCache    *externalapi.DomainHash
+	cacheLock                     sync.RWMutex
 
 	currentPruningPointIndexKey     model.DBKey
 	candidatePruningPointHashKey    model.DBKey
@@ -63,8 +66,8 @@ func (ps *pruningStore) PruningPointCandidate(dbContext model.DBReader, stagingA
 		return stagingShard.newPruningPointCandidate, nil
 	}
 
-	if ps.pruningPointCandidateCache != nil {
-		return ps.pruningPointCandidateCache, nil
+	if cachedCandidate := ps.cachedPruningPointCandidate(); cachedCandidate != nil {
+		return cachedCandidate, nil
 	}
 
 	candidateBytes, err := dbContext.Get(ps.candidatePruningPointHashKey)
@@ -76,10 +79,24 @@ func (ps *pruningStore) PruningPointCandidate(dbContext model.DBReader, stagingA
 	if err != nil {
 		return nil, err
 	}
-	ps.pruningPointCandidateCache = candidate
+	ps.setCachedPruningPointCandidate(candidate)
 	return candidate, nil
 }
 
+func (ps *pruningStore) cachedPruningPointCandidate() *externalapi.DomainHash {
+	ps.cacheLock.RLock()
+	defer ps.cacheLock.RUnlock()
+
+	return ps.pruningPointCandidateCache
+}
+
+func (ps *pruningStore) setCachedPruningPointCandidate(candidate *externalapi.DomainHash) {
+	ps.cacheLock.Lock()
+	defer ps.cacheLock.Unlock()
+
+	ps.pruningPointCandidateCache = candidate
+}
+
 func (ps *pruningStore) HasPruningPointCandidate(dbContext model.DBReader, stagingArea *model.StagingArea) (bool, error) {
 	stagingShard := ps.stagingShard(stagingArea)
 
@@ -87,7 +104,7 @@ func (ps *pruningStore) HasPruningPointCandidate(dbContext model.DBReader, stagi
 		return true, nil
 	}
 
-	if ps.pruningPointCandidateCache != nil {
+	if ps.cachedPruningPointCandidate() != nil {
 		return true, nil
 	}
 
@@ -222,7 +239,7 @@ func (ps *pruningStore) HasPruningPoint(dbContext model.DBReader, stagingArea *m
 		return true, nil
 	}
 
-	if ps.currentPruningPointIndexCache != nil {
+	if ps.cachedCurrentPruningPointIndex() != nil {
 		return true, nil
 	}
 
@@ -332,8 +349,8 @@ func (ps *pruningStore) CurrentPruningPointIndex(dbContext model.DBReader, stagi
 		return *stagingShard.currentPruningPointIndex, nil
 	}
 
-	if ps.currentPruningPointIndexCache != nil {
-		return *ps.currentPruningPointIndexCache, nil
+	if cachedIndex := ps.cachedCurrentPruningPointIndex(); cachedIndex != nil {
+		return *cachedIndex, nil
 	}
 
 	pruningPointIndexBytes, err := dbContext.Get(ps.currentPruningPointIndexKey)
@@ -346,11 +363,22 @@ func (ps *pruningStore) CurrentPruningPointIndex(dbContext model.DBReader, stagi
 		return 0, err
 	}
 
-	if ps.currentPruningPointIndexCache == nil {
-		var zero uint64
-		ps.currentPruningPointIndexCache = &zero
-	}
-
-	*ps.currentPruningPointIndexCache = index
+	ps.setCachedCurrentPruningPointIndex(index)
 	return index, nil
 }
+
+func (ps *pruningStore) cachedCurrentPruningPointIndex() *uint64 {
+	ps.cacheLock.RLock()
+	defer ps.cacheLock.RUnlock()
+
+	return ps.currentPruningPointIndexCache
+}
+
+// setCachedCurrentPruningPointIndex replaces the cached index rather than
+// overwriting it, since readers may still hold the previous one
+func (ps *pruningStore) setCachedCurrentPruningPointIndex(index uint64) {
+	ps.cacheLock.Lock()
+	defer ps.cacheLock.Unlock()
+
+	ps.currentPruningPointIndexCache = &index
+}
@@ -1,6 +1,8 @@
 package reachabilitydatastore
 
 import (
+	"sync"
+
 	"github.com/golang/protobuf/proto"
 	"github.com/kaspanet/kaspad/domain/consensus/database/serialization"
 	"github.com/kaspanet/kaspad/domain/consensus/model"
@@ -19,6 +21,7 @@ type reachabilityDataStore struct {
 	shardID                      model.StagingShardID
 	reachabilityDataCache        *lrucache.LRUCache
 	reachabilityReindexRootCache *externalapi.DomainHash
+	cacheLock                    sync.RWMutex
 
 	reachabilityDataBucket     model.DBBucket
 	reachabilityReindexRootKey model.DBKey
@@ -126,8 +129,8 @@ func (rds *reachabilityDataStore) ReachabilityReindexRoot(dbContext model.DBRead
 		return stagingShard.reachabilityReindexRoot, nil
 	}
 
-	if rds.reachabilityReindexRootCache != nil {
-		return rds.reachabilityReindexRootCache, nil
+	if cachedReindexRoot := rds.cachedReachabilityReindexRoot(); cachedReindexRoot != nil {
+		return cachedReindexRoot, nil
 	}
 
 	reachabilityReindexRootBytes, err := dbContext.Get(rds.reachabilityReindexRootKey)
@@ -139,10 +142,24 @@ func (rds *reachabilityDataStore) ReachabilityReindexRoot(dbContext model.DBRead
 	if err != nil {
 		return nil, err
 	}
-	rds.reachabilityReindexRootCache = reachabilityReindexRoot
+	rds.setCachedReachabilityReindexRoot(reachabilityReindexRoot)
 	return reachabilityReindexRoot, nil
 }
 
+func (rds *reachabilityDataStore) cachedReachabilityReindexRoot() *externalapi.DomainHash {
+	rds.cacheLock.RLock()
+	defer rds.cacheLock.RUnlock()
+
+	return rds.reachabilityReindexRootCache
+}
+
+func (rds *reachabilityDataStore) setCachedReachabilityReindexRoot(reachabilityReindexRoot *externalapi.DomainHash) {
+	rds.cacheLock.Lock()
+	defer rds.cacheLock.Unlock()
+
+	rds.reachabilityReindexRootCache = reachabilityReindexRoot
+}
+
 func (rds *reachabilityDataStore) reachabilityDataBlockHashAsKey(hash *externalapi.DomainHash) model.DBKey {
 	return rds.reachabilityDataBucket.Key(hash.ByteSlice())
 }
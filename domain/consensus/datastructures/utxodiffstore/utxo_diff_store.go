@@ -33,17 +33,15 @@ func New(prefixBucket model.DBBucket, cacheSize int, preallocate bool) model.UTX
 	}
 }
 
-// Stage stages the given utxoDiff for the given blockHash
+// Stage stages the given utxoDiff for the given blockHash. A nil utxoDiffChild
+// removes the UTXO diff child of the block, which makes its utxoDiff relative to the virtual.
 func (uds *utxoDiffStore) Stage(stagingArea *model.StagingArea, blockHash *externalapi.DomainHash,
 	utxoDiff externalapi.UTXODiff, utxoDiffChild *externalapi.DomainHash) {
 
 	stagingShard := uds.stagingShard(stagingArea)
 
 	stagingShard.utxoDiffToAdd[*blockHash] = utxoDiff
-
-	if utxoDiffChild != nil {
-		stagingShard.utxoDiffChildToAdd[*blockHash] = utxoDiffChild
-	}
+	stagingShard.utxoDiffChildToAdd[*blockHash] = utxoDiffChild
 }
 
 func (uds *utxoDiffStore) IsStaged(stagingArea *model.StagingArea) bool {
@@ -112,8 +110,8 @@ func (uds *utxoDiffStore) UTXODiffChild(dbContext model.DBReader, stagingArea *m
 func (uds *utxoDiffStore) HasUTXODiffChild(dbContext model.DBReader, stagingArea *model.StagingArea, blockHash *externalapi.DomainHash) (bool, error) {
 	stagingShard := uds.stagingShard(stagingArea)
 
-	if _, ok := stagingShard.utxoDiffChildToAdd[*blockHash]; ok {
-		return true, nil
+	if utxoDiffChild, ok := stagingShard.utxoDiffChildToAdd[*blockHash]; ok {
+		return utxoDiffChild != nil, nil
 	}
 
 	if uds.utxoDiffChildCache.Has(blockHash) {
@@ -53,6 +53,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/processes/reachabilitymanager"
 	"github.com/kaspanet/kaspad/domain/consensus/processes/syncmanager"
 	"github.com/kaspanet/kaspad/domain/consensus/processes/transactionvalidator"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
 	"github.com/kaspanet/kaspad/domain/dagconfig"
 	infrastructuredatabase "github.com/kaspanet/kaspad/infrastructure/db/database"
 	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
@@ -71,6 +72,12 @@ type Config struct {
 	IsArchival bool
 	// EnableSanityCheckPruningUTXOSet checks the full pruning point utxo set against the commitment at every pruning movement
 	EnableSanityCheckPruningUTXOSet bool
+	// ValidationCaches are the signature and script verification caches that consensus shares
+	// with the mempool. When nil, consensus creates caches of its own.
+	ValidationCaches *txscript.ValidationCaches
+	// BlockValidators are called for every block before it's inserted, and may reject it.
+	// They may be registered to while consensus is running. When nil, no validators are called.
+	BlockValidators *externalapi.BlockValidators
 
 	SkipAddingGenesis bool
 }
@@ -89,8 +96,13 @@ type Factory interface {
 	SetTestPreAllocateCache(preallocateCaches bool)
 	SetTestPastMedianTimeManager(medianTimeConstructor PastMedianTimeManagerConstructor)
 	SetTestDifficultyManager(difficultyConstructor DifficultyManagerConstructor)
+	SetTestConsensusEventsChan(consensusEventsChan chan externalapi.ConsensusEvent)
+	SetTestDatabaseOpener(openDatabase TestDatabaseOpener)
 }
 
+// TestDatabaseOpener opens the database of a test consensus at the given path
+type TestDatabaseOpener func(path string, cacheSizeMiB int) (infrastructuredatabase.Database, error)
+
 type factory struct {
 	dataDir                  string
 	ghostdagConstructor      GHOSTDAGManagerConstructor
@@ -98,6 +110,8 @@ type factory struct {
 	difficultyConstructor    DifficultyManagerConstructor
 	cacheSizeMiB             *int
 	preallocateCaches        *bool
+	testConsensusEventsChan  chan externalapi.ConsensusEvent
+	openTestDatabase         TestDatabaseOpener
 }
 
 // NewFactory creates a new Consensus factory
@@ -114,7 +128,8 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 	consensusEventsChan chan externalapi.ConsensusEvent) (
 	consensusInstance externalapi.Consensus, shouldMigrate bool, err error) {
 
-	dbManager := consensusdatabase.New(db)
+	segments := newDAGSegments()
+	dbManager := newLockingDBManager(consensusdatabase.New(db), segments)
 	prefixBucket := consensusdatabase.MakeBucket(dbPrefix.Serialize())
 
 	pruningWindowSizeForCaches := int(config.PruningDepth())
@@ -218,11 +233,13 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		config.MaxCoinbasePayloadLength,
 		config.K,
 		config.CoinbasePayloadScriptPublicKeyMaxLength,
+		&config.Params,
 		dbManager,
 		pastMedianTimeManager,
 		ghostdagDataStore,
 		daaBlocksStore,
-		txMassCalculator)
+		txMassCalculator,
+		config.ValidationCaches)
 	difficultyManager := f.difficultyConstructor(
 		dbManager,
 		ghostdagManager,
@@ -415,6 +432,10 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		daaBlocksStore,
 	)
 
+	externalBlockValidators := config.BlockValidators
+	if externalBlockValidators == nil {
+		externalBlockValidators = externalapi.NewBlockValidators()
+	}
 	blockProcessor := blockprocessor.New(
 		genesisHash,
 		config.TargetTimePerBlock,
@@ -446,7 +467,8 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		finalityStore,
 		headersSelectedChainStore,
 		daaBlocksStore,
-		daaWindowStore)
+		daaWindowStore,
+		externalBlockValidators)
 
 	pruningProofManager := pruningproofmanager.New(
 		dbManager,
@@ -474,6 +496,7 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 
 	c := &consensus{
 		lock:            &sync.Mutex{},
+		segments:        segments,
 		databaseContext: dbManager,
 
 		genesisBlock: config.GenesisBlock,
@@ -583,13 +606,19 @@ func (f *factory) NewTestConsensus(config *Config, testName string) (
 	if f.preallocateCaches == nil {
 		f.SetTestPreAllocateCache(defaultTestPreallocateCaches)
 	}
-	db, err := ldb.NewLevelDB(datadir, cacheSizeMiB)
+	openTestDatabase := f.openTestDatabase
+	if openTestDatabase == nil {
+		openTestDatabase = func(path string, cacheSizeMiB int) (infrastructuredatabase.Database, error) {
+			return ldb.NewLevelDB(path, cacheSizeMiB)
+		}
+	}
+	db, err := openTestDatabase(datadir, cacheSizeMiB)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	testConsensusDBPrefix := &prefix.Prefix{}
-	consensusAsInterface, shouldMigrate, err := f.NewConsensus(config, db, testConsensusDBPrefix, nil)
+	consensusAsInterface, shouldMigrate, err := f.NewConsensus(config, db, testConsensusDBPrefix, f.testConsensusEventsChan)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -641,6 +670,17 @@ func (f *factory) SetTestDifficultyManager(difficultyConstructor DifficultyManag
 	f.difficultyConstructor = difficultyConstructor
 }
 
+// SetTestConsensusEventsChan sets the channel that test consensuses raise their events to
+func (f *factory) SetTestConsensusEventsChan(consensusEventsChan chan externalapi.ConsensusEvent) {
+	f.testConsensusEventsChan = consensusEventsChan
+}
+
+// SetTestDatabaseOpener sets the function that opens the databases of test consensuses,
+// which are leveldb databases by default
+func (f *factory) SetTestDatabaseOpener(openDatabase TestDatabaseOpener) {
+	f.openTestDatabase = openDatabase
+}
+
 func (f *factory) SetTestLevelDBCacheSize(cacheSizeMiB int) {
 	f.cacheSizeMiB = &cacheSizeMiB
 }
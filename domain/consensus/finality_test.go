@@ -21,6 +21,8 @@ func TestFinality(t *testing.T) {
 		consensusConfig.FinalityDuration = 20 * consensusConfig.TargetTimePerBlock
 
 		factory := consensus.NewFactory()
+		consensusEventsChan := make(chan externalapi.ConsensusEvent, 1000)
+		factory.SetTestConsensusEventsChan(consensusEventsChan)
 		consensus, teardown, err := factory.NewTestConsensus(consensusConfig, "TestFinality")
 		if err != nil {
 			t.Fatalf("Error setting up consensus: %+v", err)
@@ -132,7 +134,11 @@ func TestFinality(t *testing.T) {
 			t.Fatalf("virtual's finalityPoint is still genesis after adding finalityInterval + 1 blocks to the main chain")
 		}
 
-		// TODO: Make sure that a finality conflict notification is sent
+		// Drain the events raised so far, so that only the events of the finality violating blocks are checked
+		for len(consensusEventsChan) > 0 {
+			<-consensusEventsChan
+		}
+
 		// Add two more blocks to the side chain, so that it violates finality and gets status UTXOPendingVerification even
 		// though it is the block with the highest blue score.
 		for i := uint64(0); i < 2; i++ {
@@ -175,6 +181,27 @@ func TestFinality(t *testing.T) {
 			t.Fatalf("TestFinality: Finality violating block expected to have status '%s', but got '%s'",
 				externalapi.StatusUTXOPendingVerification, blockInfo.BlockStatus)
 		}
+
+		// A finality conflict event should have been raised for the finality violating block
+		foundFinalityConflict := false
+		for len(consensusEventsChan) > 0 {
+			event, ok := (<-consensusEventsChan).(*externalapi.FinalityConflict)
+			if ok && event.ViolatingBlockHash.Equal(sideChainTipHash) {
+				foundFinalityConflict = true
+			}
+		}
+		if !foundFinalityConflict {
+			t.Fatalf("TestFinality: Expected a finality conflict event for block %s", sideChainTipHash)
+		}
+
+		// The finality point that blocks are validated against is the virtual finality point
+		finalityPoint, err := consensus.VirtualFinalityPoint()
+		if err != nil {
+			t.Fatalf("TestFinality: VirtualFinalityPoint: %+v", err)
+		}
+		if !finalityPoint.Equal(virtualFinality) {
+			t.Fatalf("TestFinality: Expected the finality point to be %s but got %s", virtualFinality, finalityPoint)
+		}
 	})
 }
 
@@ -0,0 +1,10 @@
+package consensus
+
+import "github.com/kaspanet/kaspad/infrastructure/metrics"
+
+var (
+	virtualDAAScoreGauge = metrics.NewGauge("kaspad_virtual_daa_score",
+		"The DAA score of the virtual block")
+	virtualSelectedParentBlueScoreGauge = metrics.NewGauge("kaspad_virtual_selected_parent_blue_score",
+		"The blue score of the selected parent of the virtual block")
+)
@@ -75,6 +75,16 @@ type DBTransaction interface {
 	RollbackUnlessClosed() error
 }
 
+// DBSnapshot is a read-only view of the database as it was when
+// the snapshot was taken
+type DBSnapshot interface {
+	DBReader
+
+	// Release releases the snapshot. The snapshot, and cursors
+	// that were opened over it, may not be used after that.
+	Release()
+}
+
 // DBManager defines the interface of a database that can begin
 // transactions and read data.
 type DBManager interface {
@@ -82,6 +92,10 @@ type DBManager interface {
 
 	// Begin begins a new database transaction.
 	Begin() (DBTransaction, error)
+
+	// Snapshot takes a consistent read-only snapshot of the database.
+	// The snapshot must be released once it's no longer needed.
+	Snapshot() (DBSnapshot, error)
 }
 
 // DBKey is an interface for a database key
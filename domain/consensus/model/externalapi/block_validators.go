@@ -0,0 +1,59 @@
+package externalapi
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrRejectedByBlockValidator is returned, wrapped, when a BlockValidator rejects a block.
+// It's deliberately not a rule error: the block didn't break any consensus rule, so it
+// must neither be marked invalid nor get the peer that sent it banned.
+var ErrRejectedByBlockValidator = errors.New("rejected by a block validator")
+
+// BlockValidator is a validator outside of consensus, such as a covenant checker or an
+// analytics sidecar, that observes every block that consensus inserts and may veto it.
+// It's called once the block has passed the consensus rules, before it's committed, with
+// the block and the diff that the block applies to the virtual UTXO set. The diff is nil
+// for header-only blocks and for blocks that are inserted without updating the virtual.
+// Returning an error rejects the block, which is then not stored at all, and consensus
+// returns an error that wraps ErrRejectedByBlockValidator.
+//
+// A BlockValidator is called while consensus is locked, so it must not call consensus.
+type BlockValidator func(block *DomainBlock, virtualUTXODiff UTXODiff) error
+
+// BlockValidators is a set of BlockValidators that can be registered to while consensus
+// is running
+type BlockValidators struct {
+	lock       sync.RWMutex
+	validators []BlockValidator
+}
+
+// NewBlockValidators returns an empty set of BlockValidators
+func NewBlockValidators() *BlockValidators {
+	return &BlockValidators{}
+}
+
+// Register adds the given validator to the set. The validators are called in the order in
+// which they were registered.
+func (bv *BlockValidators) Register(validator BlockValidator) {
+	bv.lock.Lock()
+	defer bv.lock.Unlock()
+
+	bv.validators = append(bv.validators, validator)
+}
+
+// Validate calls the validators with the given block and diff, and returns the error of
+// the first validator that rejects the block
+func (bv *BlockValidators) Validate(block *DomainBlock, virtualUTXODiff UTXODiff) error {
+	bv.lock.RLock()
+	defer bv.lock.RUnlock()
+
+	for _, validator := range bv.validators {
+		err := validator(block, virtualUTXODiff)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -22,3 +22,22 @@ type BlockLocator []*DomainHash
 func (locator BlockLocator) Clone() BlockLocator {
 	return CloneHashes(locator)
 }
+
+// AntichainLocator is used to help peers discover which blocks of each other's DAG
+// they're missing. A BlockLocator only samples the selected parent chain, so it
+// doesn't cover the blocks in the anticone of the selected tip, which a DAG has
+// plenty of. The AntichainLocator adds the tips of the DAG, which are an antichain
+// that every other block of the DAG is in the past of.
+type AntichainLocator struct {
+	// ChainLocator samples the selected parent chain from the sink down to the pruning point
+	ChainLocator BlockLocator
+	Tips         []*DomainHash
+}
+
+// Clone returns a clone of AntichainLocator
+func (locator *AntichainLocator) Clone() *AntichainLocator {
+	return &AntichainLocator{
+		ChainLocator: locator.ChainLocator.Clone(),
+		Tips:         CloneHashes(locator.Tips),
+	}
+}
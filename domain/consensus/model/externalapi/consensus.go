@@ -20,13 +20,18 @@ type Consensus interface {
 	GetBlockRelations(blockHash *DomainHash) (parents []*DomainHash, children []*DomainHash, err error)
 	GetBlockAcceptanceData(blockHash *DomainHash) (AcceptanceData, error)
 	GetBlocksAcceptanceData(blockHashes []*DomainHash) ([]AcceptanceData, error)
+	GetAcceptingBlock(blockHash *DomainHash) (acceptingBlockHash *DomainHash, found bool, err error)
 
 	GetHashesBetween(lowHash, highHash *DomainHash, maxBlocks uint64) (hashes []*DomainHash, actualHighHash *DomainHash, err error)
 	GetAnticone(blockHash, contextHash *DomainHash, maxBlocks uint64) (hashes []*DomainHash, err error)
+	BluesBetween(lowHash, highHash *DomainHash, maxBlocks uint64) (hashes []*DomainHash, actualHighHash *DomainHash, err error)
 	GetMissingBlockBodyHashes(highHash *DomainHash) ([]*DomainHash, error)
 	GetPruningPointUTXOs(expectedPruningPointHash *DomainHash, fromOutpoint *DomainOutpoint, limit int) ([]*OutpointAndUTXOEntryPair, error)
 	GetVirtualUTXOs(expectedVirtualParents []*DomainHash, fromOutpoint *DomainOutpoint, limit int) ([]*OutpointAndUTXOEntryPair, error)
+	GetVirtualUTXOSetSnapshot() (VirtualUTXOSetSnapshot, error)
+	GetVirtualUTXOEntries(outpoints []*DomainOutpoint) (entries []UTXOEntry, virtualSelectedParent *DomainHash, err error)
 	PruningPoint() (*DomainHash, error)
+	VirtualFinalityPoint() (*DomainHash, error)
 	PruningPointHeaders() ([]BlockHeader, error)
 	PruningPointAndItsAnticone() ([]*DomainHash, error)
 	ClearImportedPruningPointData() error
@@ -34,12 +39,16 @@ type Consensus interface {
 	ValidateAndInsertImportedPruningPoint(newPruningPoint *DomainHash) error
 	GetVirtualSelectedParent() (*DomainHash, error)
 	CreateBlockLocatorFromPruningPoint(highHash *DomainHash, limit uint32) (BlockLocator, error)
+	CreateAntichainLocator(chainLimit uint32, tipsLimit uint32) (*AntichainLocator, error)
+	AntichainLocatorMissingBlocks(locator *AntichainLocator) (highestSharedChainHash *DomainHash,
+		missingHashes []*DomainHash, err error)
 	CreateHeadersSelectedChainBlockLocator(lowHash, highHash *DomainHash) (BlockLocator, error)
 	CreateFullHeadersSelectedChainBlockLocator() (BlockLocator, error)
 	GetSyncInfo() (*SyncInfo, error)
 	Tips() ([]*DomainHash, error)
 	GetVirtualInfo() (*VirtualInfo, error)
 	GetVirtualDAAScore() (uint64, error)
+	GetVirtualUTXOCommitment() (*DomainHash, error)
 	IsValidPruningPoint(blockHash *DomainHash) (bool, error)
 	ArePruningPointsViolatingFinality(pruningPoints []BlockHeader) (bool, error)
 	GetVirtualSelectedParentChainFromBlock(blockHash *DomainHash) (*SelectedChainPath, error)
@@ -49,6 +58,9 @@ type Consensus interface {
 	EstimateNetworkHashesPerSecond(startHash *DomainHash, windowSize int) (uint64, error)
 	PopulateMass(transaction *DomainTransaction)
 	ResolveVirtual(progressReportCallback func(uint64, uint64)) error
+	RebuildUTXOState() error
+	InvalidateBlock(blockHash *DomainHash) error
+	ReconsiderBlock(blockHash *DomainHash) error
 	BlockDAAWindowHashes(blockHash *DomainHash) ([]*DomainHash, error)
 	TrustedDataDataDAAHeader(trustedBlockHash, daaBlockHash *DomainHash, daaBlockWindowIndex uint64) (*TrustedDataDataDAAHeader, error)
 	TrustedBlockAssociatedGHOSTDAGDataBlockHashes(blockHash *DomainHash) ([]*DomainHash, error)
@@ -56,4 +68,18 @@ type Consensus interface {
 	IsChainBlock(blockHash *DomainHash) (bool, error)
 	VirtualMergeDepthRoot() (*DomainHash, error)
 	IsNearlySynced() (bool, error)
+	CacheMemoryUsage(cache ConsensusCache) uint64
+	SetCacheMemoryLimit(cache ConsensusCache, limit uint64)
 }
+
+// ConsensusCache is a cache of consensus whose memory may be limited
+type ConsensusCache int
+
+// The caches of consensus whose memory may be limited
+const (
+	// UTXOCache is the cache of the virtual UTXO set
+	UTXOCache ConsensusCache = iota
+
+	// BlockCache is the cache of the block store
+	BlockCache
+)
@@ -12,6 +12,15 @@ type BlockAdded struct {
 
 func (*BlockAdded) isConsensusEvent() {}
 
+// BlockHeaderAdded is an event raised by consensus when a block header was added to the dag,
+// including headers whose block bodies aren't known yet. It's raised once per header, so it
+// isn't raised again when the body of a block whose header is known is added.
+type BlockHeaderAdded struct {
+	Header BlockHeader
+}
+
+func (*BlockHeaderAdded) isConsensusEvent() {}
+
 // VirtualChangeSet is an event raised by consensus when virtual changes
 type VirtualChangeSet struct {
 	VirtualSelectedParentChainChanges *SelectedChainPath
@@ -23,6 +32,14 @@ type VirtualChangeSet struct {
 
 func (*VirtualChangeSet) isConsensusEvent() {}
 
+// FinalityConflict is an event raised by consensus when a block that would have become
+// the virtual selected parent is rejected for violating finality
+type FinalityConflict struct {
+	ViolatingBlockHash *DomainHash
+}
+
+func (*FinalityConflict) isConsensusEvent() {}
+
 // SelectedChainPath is a path the of the selected chains between two blocks.
 type SelectedChainPath struct {
 	Added   []*DomainHash
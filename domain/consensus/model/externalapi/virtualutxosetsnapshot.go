@@ -0,0 +1,17 @@
+package externalapi
+
+// VirtualUTXOSetSnapshot is a read-only view of the virtual UTXO set as it was
+// when the snapshot was taken. Blocks may keep being added to the DAG while the
+// snapshot is read, without changing it or being blocked by it.
+type VirtualUTXOSetSnapshot interface {
+	// VirtualParents returns the parents of the virtual block at the time
+	// the snapshot was taken
+	VirtualParents() []*DomainHash
+
+	// VirtualUTXOs returns up to limit UTXOs of the snapshot, starting
+	// from the given outpoint
+	VirtualUTXOs(fromOutpoint *DomainOutpoint, limit int) ([]*OutpointAndUTXOEntryPair, error)
+
+	// Release releases the snapshot. It may not be used after that.
+	Release()
+}
@@ -12,10 +12,15 @@ type ConsensusStateStore interface {
 	HasUTXOByOutpoint(dbContext DBReader, stagingArea *StagingArea, outpoint *externalapi.DomainOutpoint) (bool, error)
 	VirtualUTXOSetIterator(dbContext DBReader, stagingArea *StagingArea) (externalapi.ReadOnlyUTXOSetIterator, error)
 	VirtualUTXOs(dbContext DBReader, fromOutpoint *externalapi.DomainOutpoint, limit int) ([]*externalapi.OutpointAndUTXOEntryPair, error)
+	VirtualUTXOSetCacheLen() int
+	SetVirtualUTXOSetCacheCapacity(capacity int)
 
 	StageTips(stagingArea *StagingArea, tipHashes []*externalapi.DomainHash)
 	Tips(stagingArea *StagingArea, dbContext DBReader) ([]*externalapi.DomainHash, error)
 
+	StageInvalidatedBlocks(stagingArea *StagingArea, invalidatedBlocks []*externalapi.DomainHash)
+	InvalidatedBlocks(stagingArea *StagingArea, dbContext DBReader) ([]*externalapi.DomainHash, error)
+
 	StartImportingPruningPointUTXOSet(dbContext DBWriter) error
 	HadStartedImportingPruningPointUTXOSet(dbContext DBWriter) (bool, error)
 	ImportPruningPointUTXOSetIntoVirtualUTXOSet(dbContext DBWriter, pruningPointUTXOSetIterator externalapi.ReadOnlyUTXOSetIterator) error
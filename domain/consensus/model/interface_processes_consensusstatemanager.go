@@ -12,6 +12,10 @@ type ConsensusStateManager interface {
 	CalculatePastUTXOAndAcceptanceData(stagingArea *StagingArea, blockHash *externalapi.DomainHash) (externalapi.UTXODiff, externalapi.AcceptanceData, Multiset, error)
 	GetVirtualSelectedParentChainFromBlock(stagingArea *StagingArea, blockHash *externalapi.DomainHash) (*externalapi.SelectedChainPath, error)
 	RecoverUTXOIfRequired() error
+	RebuildUTXOState() error
 	ReverseUTXODiffs(tipHash *externalapi.DomainHash, reversalData *UTXODiffReversalData) error
 	ResolveVirtual(maxBlocksToResolve uint64) (*externalapi.VirtualChangeSet, bool, error)
+	InvalidateBlock(blockHash *externalapi.DomainHash) (*externalapi.VirtualChangeSet, error)
+	ReconsiderBlock(blockHash *externalapi.DomainHash) (*externalapi.VirtualChangeSet, error)
+	PopFinalityConflicts() []*externalapi.DomainHash
 }
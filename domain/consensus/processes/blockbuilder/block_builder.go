@@ -2,7 +2,6 @@ package blockbuilder
 
 import (
 	"math/big"
-	"sort"
 
 	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/blockheader"
@@ -10,7 +9,6 @@ import (
 
 	"github.com/kaspanet/kaspad/domain/consensus/model"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
-	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/merkle"
 	"github.com/kaspanet/kaspad/infrastructure/logger"
@@ -290,26 +288,7 @@ func (bb *blockBuilder) newBlockAcceptedIDMerkleRoot(stagingArea *model.StagingA
 		return nil, err
 	}
 
-	return bb.calculateAcceptedIDMerkleRoot(newBlockAcceptanceData)
-}
-
-func (bb *blockBuilder) calculateAcceptedIDMerkleRoot(acceptanceData externalapi.AcceptanceData) (*externalapi.DomainHash, error) {
-	var acceptedTransactions []*externalapi.DomainTransaction
-	for _, blockAcceptanceData := range acceptanceData {
-		for _, transactionAcceptance := range blockAcceptanceData.TransactionAcceptanceData {
-			if !transactionAcceptance.IsAccepted {
-				continue
-			}
-			acceptedTransactions = append(acceptedTransactions, transactionAcceptance.Transaction)
-		}
-	}
-	sort.Slice(acceptedTransactions, func(i, j int) bool {
-		acceptedTransactionIID := consensushashing.TransactionID(acceptedTransactions[i])
-		acceptedTransactionJID := consensushashing.TransactionID(acceptedTransactions[j])
-		return acceptedTransactionIID.Less(acceptedTransactionJID)
-	})
-
-	return merkle.CalculateIDMerkleRoot(acceptedTransactions), nil
+	return merkle.BuildAcceptedIDMerkleTree(newBlockAcceptanceData).Root(), nil
 }
 
 func (bb *blockBuilder) newBlockUTXOCommitment(stagingArea *model.StagingArea) (*externalapi.DomainHash, error) {
@@ -7,6 +7,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/model/testapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/blockheader"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/merkle"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
 	"github.com/kaspanet/kaspad/infrastructure/logger"
@@ -121,10 +122,7 @@ func (bb *testBlockBuilder) buildHeaderWithParents(stagingArea *model.StagingAre
 	}
 
 	hashMerkleRoot := bb.newBlockHashMerkleRoot(transactions)
-	acceptedIDMerkleRoot, err := bb.calculateAcceptedIDMerkleRoot(acceptanceData)
-	if err != nil {
-		return nil, err
-	}
+	acceptedIDMerkleRoot := merkle.BuildAcceptedIDMerkleTree(acceptanceData).Root()
 	utxoCommitment := multiset.Hash()
 
 	return blockheader.NewImmutableBlockHeader(
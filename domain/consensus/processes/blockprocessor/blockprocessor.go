@@ -7,6 +7,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/processes/blockprocessor/blocklogger"
 	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/processingstats"
 )
 
 // blockProcessor is responsible for processing incoming blocks
@@ -47,6 +48,8 @@ type blockProcessor struct {
 	daaBlocksStore                      model.DAABlocksStore
 	blocksWithTrustedDataDAAWindowStore model.BlocksWithTrustedDataDAAWindowStore
 
+	externalBlockValidators *externalapi.BlockValidators
+
 	stores []model.Store
 }
 
@@ -84,6 +87,8 @@ func New(
 	headersSelectedChainStore model.HeadersSelectedChainStore,
 	daaBlocksStore model.DAABlocksStore,
 	blocksWithTrustedDataDAAWindowStore model.BlocksWithTrustedDataDAAWindowStore,
+
+	externalBlockValidators *externalapi.BlockValidators,
 ) model.BlockProcessor {
 
 	return &blockProcessor{
@@ -120,6 +125,8 @@ func New(
 		daaBlocksStore:                      daaBlocksStore,
 		blocksWithTrustedDataDAAWindowStore: blocksWithTrustedDataDAAWindowStore,
 
+		externalBlockValidators: externalBlockValidators,
+
 		stores: []model.Store{
 			consensusStateStore,
 			acceptanceDataStore,
@@ -148,6 +155,8 @@ func (bp *blockProcessor) ValidateAndInsertBlock(block *externalapi.DomainBlock,
 	shouldValidateAgainstUTXO bool) (*externalapi.VirtualChangeSet, externalapi.BlockStatus, error) {
 	onEnd := logger.LogAndMeasureExecutionTime(log, "ValidateAndInsertBlock")
 	defer onEnd()
+	onStageEnd := processingstats.Measure(processingstats.BlockProcessing)
+	defer onStageEnd()
 
 	stagingArea := model.NewStagingArea()
 	return bp.validateAndInsertBlock(stagingArea, block, false, shouldValidateAgainstUTXO, false)
@@ -13,6 +13,7 @@ import (
 	"github.com/kaspanet/kaspad/infrastructure/db/database"
 	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/kaspanet/kaspad/util/difficulty"
+	"github.com/kaspanet/kaspad/util/processingstats"
 	"github.com/kaspanet/kaspad/util/staging"
 	"github.com/pkg/errors"
 )
@@ -136,7 +137,9 @@ func (bp *blockProcessor) validateAndInsertBlock(stagingArea *model.StagingArea,
 	isHeaderOnlyBlock := isHeaderOnlyBlock(block)
 	if !isHeaderOnlyBlock {
 		// Attempt to add the block to the virtual
+		onVirtualUpdateEnd := processingstats.Measure(processingstats.VirtualUpdate)
 		selectedParentChainChanges, virtualUTXODiff, reversalData, err = bp.consensusStateManager.AddBlock(stagingArea, blockHash, shouldValidateAgainstUTXO)
+		onVirtualUpdateEnd()
 		if err != nil {
 			return nil, externalapi.StatusInvalid, err
 		}
@@ -157,7 +160,18 @@ func (bp *blockProcessor) validateAndInsertBlock(stagingArea *model.StagingArea,
 		}
 	}
 
+	err = bp.externalBlockValidators.Validate(block, virtualUTXODiff)
+	if err != nil {
+		// The staging area is discarded, so the block is rejected without being stored, and
+		// may be submitted again. The error isn't a rule error, since the block may well be
+		// valid by consensus.
+		return nil, status, errors.Wrapf(externalapi.ErrRejectedByBlockValidator,
+			"block %s was rejected by a block validator: %s", blockHash, err)
+	}
+
+	onDatabaseCommitEnd := processingstats.Measure(processingstats.DatabaseCommit)
 	err = staging.CommitAllChanges(bp.databaseContext, stagingArea)
+	onDatabaseCommitEnd()
 	if err != nil {
 		return nil, externalapi.StatusInvalid, err
 	}
@@ -216,3 +216,78 @@ func initData(consensusConfig *consensus.Config) (*externalapi.DomainHash, exter
 				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02}),
 		}
 }
+
+func TestExternalBlockValidators(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		var validatedBlockHashes []*externalapi.DomainHash
+		var validatedDiffs []externalapi.UTXODiff
+		shouldReject := false
+		consensusConfig.BlockValidators = externalapi.NewBlockValidators()
+		consensusConfig.BlockValidators.Register(func(block *externalapi.DomainBlock, virtualUTXODiff externalapi.UTXODiff) error {
+			validatedBlockHashes = append(validatedBlockHashes, consensushashing.BlockHash(block))
+			validatedDiffs = append(validatedDiffs, virtualUTXODiff)
+			if shouldReject {
+				return errors.New("rejected")
+			}
+			return nil
+		})
+
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestExternalBlockValidators")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+		// Genesis was validated when consensus was initialized
+		validatedBlockHashes, validatedDiffs = nil, nil
+
+		blockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{consensusConfig.GenesisHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		if len(validatedBlockHashes) != 1 || !validatedBlockHashes[0].Equal(blockHash) {
+			t.Fatalf("Expected the validator to be called with block %s but got %s", blockHash, validatedBlockHashes)
+		}
+		if validatedDiffs[0] == nil {
+			t.Fatalf("Expected the validator to get the virtual UTXO diff of a block with a body")
+		}
+
+		// A rejected block isn't stored, so it can be inserted once the validator accepts it
+		block, _, err := tc.BuildBlockWithParents([]*externalapi.DomainHash{blockHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("BuildBlockWithParents: %+v", err)
+		}
+		rejectedBlockHash := consensushashing.BlockHash(block)
+		shouldReject = true
+		err = tc.ValidateAndInsertBlock(block, true)
+		if !errors.Is(err, externalapi.ErrRejectedByBlockValidator) {
+			t.Fatalf("Expected ErrRejectedByBlockValidator but got: %+v", err)
+		}
+		if errors.As(err, &ruleerrors.RuleError{}) {
+			t.Fatalf("Expected the rejection of a block validator not to be a rule error")
+		}
+		blockInfo, err := tc.GetBlockInfo(rejectedBlockHash)
+		if err != nil {
+			t.Fatalf("GetBlockInfo: %+v", err)
+		}
+		if blockInfo.Exists {
+			t.Fatalf("Expected the rejected block not to be stored")
+		}
+		shouldReject = false
+		err = tc.ValidateAndInsertBlock(block, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertBlock: %+v", err)
+		}
+		if len(validatedBlockHashes) != 3 || !validatedBlockHashes[2].Equal(rejectedBlockHash) {
+			t.Fatalf("Expected the validator to be called again with block %s", rejectedBlockHash)
+		}
+
+		_, _, err = tc.AddUTXOInvalidHeader([]*externalapi.DomainHash{rejectedBlockHash})
+		if err != nil {
+			t.Fatalf("AddUTXOInvalidHeader: %+v", err)
+		}
+		if len(validatedDiffs) != 4 || validatedDiffs[3] != nil {
+			t.Fatalf("Expected the validator to get no virtual UTXO diff for a header-only block")
+		}
+	})
+}
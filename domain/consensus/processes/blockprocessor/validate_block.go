@@ -5,6 +5,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/util/processingstats"
 	"github.com/kaspanet/kaspad/util/staging"
 	"github.com/pkg/errors"
 )
@@ -39,6 +40,7 @@ func (bp *blockProcessor) validateBlock(stagingArea *model.StagingArea, block *e
 	// If any validation until (included) proof-of-work fails, simply
 	// return an error without writing anything in the database.
 	// This is to prevent spamming attacks.
+	onIsolationChecksEnd := processingstats.Measure(processingstats.IsolationChecks)
 	err = bp.validatePreProofOfWork(stagingArea, block)
 	if err != nil {
 		return err
@@ -50,10 +52,13 @@ func (bp *blockProcessor) validateBlock(stagingArea *model.StagingArea, block *e
 			return err
 		}
 	}
+	onIsolationChecksEnd()
 
 	// If in-context validations fail, discard all changes and store the
 	// block with StatusInvalid.
+	onContextChecksEnd := processingstats.Measure(processingstats.ContextChecks)
 	err = bp.validatePostProofOfWork(stagingArea, block, isBlockWithTrustedData)
+	onContextChecksEnd()
 	if err != nil {
 		if errors.As(err, &ruleerrors.RuleError{}) {
 			// We mark invalid blocks with status externalapi.StatusInvalid except in the
@@ -36,8 +36,8 @@ func (csm *consensusStateManager) AddBlock(stagingArea *model.StagingArea, block
 			}
 
 			if shouldNotify {
-				//TODO: Send finality conflict notification
 				log.Warnf("Finality Violation Detected! Block %s violates finality!", blockHash)
+				csm.queueFinalityConflict(blockHash)
 			}
 
 			if !isViolatingFinality {
@@ -68,7 +68,13 @@ func (csm *consensusStateManager) AddBlock(stagingArea *model.StagingArea, block
 	}
 
 	log.Debugf("Updating the virtual with the new tips")
-	selectedParentChainChanges, virtualUTXODiff, err := csm.updateVirtual(stagingArea, blockHash, newTips)
+	// The new tips are the virtual parent candidates unless some blocks were invalidated
+	virtualParentCandidates, err := csm.virtualParentCandidates(stagingArea)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	selectedParentChainChanges, virtualUTXODiff, err := csm.updateVirtual(stagingArea, blockHash, virtualParentCandidates)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -88,6 +94,16 @@ func (csm *consensusStateManager) isCandidateToBeNextVirtualSelectedParent(
 		return true, nil
 	}
 
+	isInFutureOfInvalidatedBlock, err := csm.isInFutureOfInvalidatedBlock(stagingArea, blockHash)
+	if err != nil {
+		return false, err
+	}
+	if isInFutureOfInvalidatedBlock {
+		log.Debugf("Block %s is in the future of an invalidated block, therefore it can't be "+
+			"the selected parent", blockHash)
+		return false, nil
+	}
+
 	virtualGhostdagData, err := csm.ghostdagDataStore.Get(csm.databaseContext, stagingArea, model.VirtualBlockHash, false)
 	if err != nil {
 		return false, err
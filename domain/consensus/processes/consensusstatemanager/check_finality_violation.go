@@ -68,3 +68,17 @@ func (csm *consensusStateManager) isViolatingFinality(stagingArea *model.Staging
 
 	return false, false, nil
 }
+
+// queueFinalityConflict records that the given block violates finality. The queued blocks are
+// reported by consensus only after the staging area they were found in is committed
+func (csm *consensusStateManager) queueFinalityConflict(violatingBlockHash *externalapi.DomainHash) {
+	csm.finalityConflicts = append(csm.finalityConflicts, violatingBlockHash)
+}
+
+// PopFinalityConflicts returns the blocks that were found to violate finality since the
+// last call, and clears them
+func (csm *consensusStateManager) PopFinalityConflicts() []*externalapi.DomainHash {
+	finalityConflicts := csm.finalityConflicts
+	csm.finalityConflicts = nil
+	return finalityConflicts
+}
@@ -35,6 +35,10 @@ type consensusStateManager struct {
 	pruningStore            model.PruningStore
 	daaBlocksStore          model.DAABlocksStore
 
+	// finalityConflicts holds the blocks found to violate finality since the last call to
+	// PopFinalityConflicts, so that they're only reported once their staging area is committed
+	finalityConflicts []*externalapi.DomainHash
+
 	stores []model.Store
 }
 
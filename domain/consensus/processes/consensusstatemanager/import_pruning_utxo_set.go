@@ -149,6 +149,7 @@ func (csm *consensusStateManager) applyImportedPruningPointUTXOSet(stagingArea *
 	if err != nil {
 		return err
 	}
+	defer dbTx.RollbackUnlessClosed()
 
 	err = stagingArea.Commit(dbTx)
 	if err != nil {
@@ -0,0 +1,246 @@
+package consensusstatemanager
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/hashset"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/staging"
+	"github.com/pkg/errors"
+)
+
+// InvalidateBlock marks the given block as invalid, which excludes it and its future from the past
+// of the virtual, and picks the virtual parents anew out of the remaining blocks. Blocks that are
+// pending verification are left for ResolveVirtual.
+func (csm *consensusStateManager) InvalidateBlock(blockHash *externalapi.DomainHash) (
+	*externalapi.VirtualChangeSet, error) {
+
+	onEnd := logger.LogAndMeasureExecutionTime(log, "csm.InvalidateBlock")
+	defer onEnd()
+
+	stagingArea := model.NewStagingArea()
+	invalidatedBlocks, err := csm.consensusStateStore.InvalidatedBlocks(stagingArea, csm.databaseContext)
+	if err != nil {
+		return nil, err
+	}
+	for _, invalidatedBlock := range invalidatedBlocks {
+		if invalidatedBlock.Equal(blockHash) {
+			return nil, errors.Errorf("block %s is already invalidated", blockHash)
+		}
+	}
+	csm.consensusStateStore.StageInvalidatedBlocks(stagingArea, append(invalidatedBlocks, blockHash))
+
+	log.Infof("Invalidating block %s", blockHash)
+	return csm.repickVirtualParents(stagingArea)
+}
+
+// ReconsiderBlock removes the invalidation of the given block, of the invalidated blocks in its past
+// that it was invalidated by, and of the invalidated blocks in its future, and picks the virtual
+// parents anew. Blocks that are pending verification are left for ResolveVirtual.
+func (csm *consensusStateManager) ReconsiderBlock(blockHash *externalapi.DomainHash) (
+	*externalapi.VirtualChangeSet, error) {
+
+	onEnd := logger.LogAndMeasureExecutionTime(log, "csm.ReconsiderBlock")
+	defer onEnd()
+
+	stagingArea := model.NewStagingArea()
+	invalidatedBlocks, err := csm.consensusStateStore.InvalidatedBlocks(stagingArea, csm.databaseContext)
+	if err != nil {
+		return nil, err
+	}
+
+	remainingInvalidatedBlocks := make([]*externalapi.DomainHash, 0, len(invalidatedBlocks))
+	for _, invalidatedBlock := range invalidatedBlocks {
+		isAncestor, err := csm.dagTopologyManager.IsAncestorOf(stagingArea, invalidatedBlock, blockHash)
+		if err != nil {
+			return nil, err
+		}
+		isDescendant, err := csm.dagTopologyManager.IsAncestorOf(stagingArea, blockHash, invalidatedBlock)
+		if err != nil {
+			return nil, err
+		}
+		if isAncestor || isDescendant {
+			log.Infof("Reconsidering block %s", invalidatedBlock)
+			continue
+		}
+		remainingInvalidatedBlocks = append(remainingInvalidatedBlocks, invalidatedBlock)
+	}
+	if len(remainingInvalidatedBlocks) == len(invalidatedBlocks) {
+		return nil, errors.Errorf("neither block %s nor any block related to it is invalidated", blockHash)
+	}
+	csm.consensusStateStore.StageInvalidatedBlocks(stagingArea, remainingInvalidatedBlocks)
+
+	return csm.repickVirtualParents(stagingArea)
+}
+
+// repickVirtualParents picks the virtual parents out of all the virtual parent candidates rather
+// than out of the tips that a new block changed, and commits the new virtual along with whatever
+// is staged in stagingArea. Only UTXO-valid blocks are picked, so candidates that aren't are
+// replaced by their closest UTXO-valid selected ancestors.
+func (csm *consensusStateManager) repickVirtualParents(stagingArea *model.StagingArea) (
+	*externalapi.VirtualChangeSet, error) {
+
+	previousVirtualSelectedParent, err := csm.virtualSelectedParent(stagingArea)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := csm.virtualParentCandidates(stagingArea)
+	if err != nil {
+		return nil, err
+	}
+	validCandidates := make([]*externalapi.DomainHash, len(candidates))
+	for i, candidate := range candidates {
+		validCandidates[i], err = csm.closestUTXOValidSelectedAncestor(stagingArea, candidate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	validCandidates, err = csm.maximalBlocks(stagingArea, validCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	virtualParents, err := csm.pickVirtualParents(stagingArea, validCandidates)
+	if err != nil {
+		return nil, err
+	}
+	virtualUTXODiff, err := csm.updateVirtualWithParents(stagingArea, virtualParents)
+	if err != nil {
+		return nil, err
+	}
+
+	virtualSelectedParent, err := csm.virtualSelectedParent(stagingArea)
+	if err != nil {
+		return nil, err
+	}
+	selectedParentChainChanges, err := csm.dagTraversalManager.
+		CalculateChainPath(stagingArea, previousVirtualSelectedParent, virtualSelectedParent)
+	if err != nil {
+		return nil, err
+	}
+	virtualParentsOutcome, err := csm.dagTopologyManager.Parents(stagingArea, model.VirtualBlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	err = staging.CommitAllChanges(csm.databaseContext, stagingArea)
+	if err != nil {
+		return nil, err
+	}
+
+	return &externalapi.VirtualChangeSet{
+		VirtualSelectedParentChainChanges: selectedParentChainChanges,
+		VirtualUTXODiff:                   virtualUTXODiff,
+		VirtualParents:                    virtualParentsOutcome,
+	}, nil
+}
+
+// virtualParentCandidates returns the tips of the DAG without the invalidated blocks and their
+// future. With no invalidated blocks, these are the tips of the DAG.
+func (csm *consensusStateManager) virtualParentCandidates(stagingArea *model.StagingArea) (
+	[]*externalapi.DomainHash, error) {
+
+	tips, err := csm.consensusStateStore.Tips(stagingArea, csm.databaseContext)
+	if err != nil {
+		return nil, err
+	}
+	invalidatedBlocks, err := csm.consensusStateStore.InvalidatedBlocks(stagingArea, csm.databaseContext)
+	if err != nil {
+		return nil, err
+	}
+	if len(invalidatedBlocks) == 0 {
+		return tips, nil
+	}
+
+	var candidates []*externalapi.DomainHash
+	visited := hashset.New()
+	queue := tips
+	for len(queue) > 0 {
+		var current *externalapi.DomainHash
+		current, queue = queue[0], queue[1:]
+		if visited.Contains(current) {
+			continue
+		}
+		visited.Add(current)
+
+		isInvalidated, err := csm.dagTopologyManager.IsAnyAncestorOf(stagingArea, invalidatedBlocks, current)
+		if err != nil {
+			return nil, err
+		}
+		if !isInvalidated {
+			candidates = append(candidates, current)
+			continue
+		}
+		parents, err := csm.dagTopologyManager.Parents(stagingArea, current)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, parents...)
+	}
+	return csm.maximalBlocks(stagingArea, candidates)
+}
+
+// isInFutureOfInvalidatedBlock returns whether the given block is an invalidated block or is in
+// the future of one
+func (csm *consensusStateManager) isInFutureOfInvalidatedBlock(stagingArea *model.StagingArea,
+	blockHash *externalapi.DomainHash) (bool, error) {
+
+	invalidatedBlocks, err := csm.consensusStateStore.InvalidatedBlocks(stagingArea, csm.databaseContext)
+	if err != nil {
+		return false, err
+	}
+	return csm.dagTopologyManager.IsAnyAncestorOf(stagingArea, invalidatedBlocks, blockHash)
+}
+
+func (csm *consensusStateManager) closestUTXOValidSelectedAncestor(stagingArea *model.StagingArea,
+	blockHash *externalapi.DomainHash) (*externalapi.DomainHash, error) {
+
+	current := blockHash
+	for {
+		status, err := csm.blockStatusStore.Get(csm.databaseContext, stagingArea, current)
+		if err != nil {
+			return nil, err
+		}
+		if status == externalapi.StatusUTXOValid {
+			return current, nil
+		}
+		ghostdagData, err := csm.ghostdagDataStore.Get(csm.databaseContext, stagingArea, current, false)
+		if err != nil {
+			return nil, err
+		}
+		if ghostdagData.SelectedParent() == nil {
+			return nil, errors.Errorf("block %s has no UTXO-valid selected ancestor", blockHash)
+		}
+		current = ghostdagData.SelectedParent()
+	}
+}
+
+// maximalBlocks returns the given blocks without duplicates and without the blocks that are in the
+// past of any of the others
+func (csm *consensusStateManager) maximalBlocks(stagingArea *model.StagingArea,
+	blockHashes []*externalapi.DomainHash) ([]*externalapi.DomainHash, error) {
+
+	uniqueBlockHashes := hashset.NewFromSlice(blockHashes...).ToSlice()
+	maximal := make([]*externalapi.DomainHash, 0, len(uniqueBlockHashes))
+	for _, blockHash := range uniqueBlockHashes {
+		isInPastOfAnother := false
+		for _, other := range uniqueBlockHashes {
+			if other.Equal(blockHash) {
+				continue
+			}
+			isAncestor, err := csm.dagTopologyManager.IsAncestorOf(stagingArea, blockHash, other)
+			if err != nil {
+				return nil, err
+			}
+			if isAncestor {
+				isInPastOfAnother = true
+				break
+			}
+		}
+		if !isInPastOfAnother {
+			maximal = append(maximal, blockHash)
+		}
+	}
+	return maximal, nil
+}
@@ -0,0 +1,108 @@
+package consensusstatemanager_test
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/dagtest"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+)
+
+func TestInvalidateAndReconsiderBlock(t *testing.T) {
+	params := dagconfig.SimnetParams
+	params.BlockCoinbaseMaturity = 0
+	dag := dagtest.New(t, &params)
+	tc := dag.Consensus()
+
+	// genesis <- A <- B <- C
+	//              \
+	//               <- S
+	dag.AddChain(dagtest.GenesisName, "A", "B", "C")
+	dag.AddBlock("S", "A")
+	dag.AssertVirtualParents("C", "S")
+	dag.AssertVirtualSelectedParent("C")
+	dag.AssertVirtualUTXO(dag.CoinbaseOutpoint("C"), true)
+
+	err := tc.InvalidateBlock(dag.Hash("B"))
+	if err != nil {
+		t.Fatalf("InvalidateBlock: %+v", err)
+	}
+	dag.AssertVirtualParents("S")
+	dag.AssertVirtualSelectedParent("S")
+	dag.AssertVirtualUTXO(dag.CoinbaseOutpoint("B"), false)
+	dag.AssertVirtualUTXO(dag.CoinbaseOutpoint("C"), false)
+	dag.AssertVirtualUTXO(dag.CoinbaseOutpoint("S"), true)
+
+	err = tc.InvalidateBlock(dag.Hash("B"))
+	if err == nil {
+		t.Fatalf("Expected an error invalidating an invalidated block")
+	}
+	err = tc.InvalidateBlock(dag.Hash(dagtest.GenesisName))
+	if err == nil {
+		t.Fatalf("Expected an error invalidating the genesis")
+	}
+
+	// Blocks in the future of the invalidated block are added, but are kept out of the virtual
+	dag.AddChain("C", "D", "E")
+	dag.AddBlock("T", "S")
+	dag.AssertVirtualParents("T")
+	dag.AssertVirtualSelectedParent("T")
+	dag.AssertVirtualUTXO(dag.CoinbaseOutpoint("T"), true)
+
+	// Reconsidering C reconsiders B, which is in its past
+	err = tc.ReconsiderBlock(dag.Hash("C"))
+	if err != nil {
+		t.Fatalf("ReconsiderBlock: %+v", err)
+	}
+	dag.AssertVirtualParents("E", "T")
+	dag.AssertVirtualSelectedParent("E")
+	dag.AssertVirtualUTXO(dag.CoinbaseOutpoint("C"), true)
+	dag.AssertVirtualUTXO(dag.CoinbaseOutpoint("E"), true)
+
+	err = tc.ReconsiderBlock(dag.Hash("C"))
+	if err == nil {
+		t.Fatalf("Expected an error reconsidering a block that isn't invalidated")
+	}
+
+	// Switching back to a block whose UTXO state was already verified
+	err = tc.InvalidateBlock(dag.Hash("D"))
+	if err != nil {
+		t.Fatalf("InvalidateBlock: %+v", err)
+	}
+	dag.AssertVirtualParents("C", "T")
+	dag.AssertVirtualUTXO(dag.CoinbaseOutpoint("D"), false)
+	dag.AssertVirtualUTXO(dag.CoinbaseOutpoint("E"), false)
+	err = tc.ReconsiderBlock(dag.Hash("D"))
+	if err != nil {
+		t.Fatalf("ReconsiderBlock: %+v", err)
+	}
+	dag.AssertVirtualParents("E", "T")
+	dag.AssertVirtualSelectedParent("E")
+
+	// The UTXO state of the virtual is still consistent, which new blocks are validated against
+	dag.AddBlock("F", "E", "T")
+	dag.AssertVirtualParents("F")
+	dag.AssertVirtualUTXO(dag.CoinbaseOutpoint("F"), true)
+	dag.AssertVirtualUTXO(dag.CoinbaseOutpoint("E"), true)
+
+	// The virtual UTXO set matches the one built from scratch
+	utxoCommitment, err := tc.GetVirtualUTXOCommitment()
+	if err != nil {
+		t.Fatalf("GetVirtualUTXOCommitment: %+v", err)
+	}
+	err = tc.RebuildUTXOState()
+	if err != nil {
+		t.Fatalf("RebuildUTXOState: %+v", err)
+	}
+	err = tc.ResolveVirtual(nil)
+	if err != nil {
+		t.Fatalf("ResolveVirtual: %+v", err)
+	}
+	rebuiltUTXOCommitment, err := tc.GetVirtualUTXOCommitment()
+	if err != nil {
+		t.Fatalf("GetVirtualUTXOCommitment: %+v", err)
+	}
+	if !utxoCommitment.Equal(rebuiltUTXOCommitment) {
+		t.Fatalf("Expected the UTXO commitment of the virtual to be %s but got %s once the UTXO state "+
+			"was rebuilt", rebuiltUTXOCommitment, utxoCommitment)
+	}
+}
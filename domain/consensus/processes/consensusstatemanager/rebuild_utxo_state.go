@@ -0,0 +1,171 @@
+package consensusstatemanager
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/hashset"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/pkg/errors"
+)
+
+// RebuildUTXOState discards the virtual UTXO set along with the UTXO state of all the blocks in
+// the future of the pruning point, and sets the virtual UTXO set to the imported pruning point
+// UTXO set, which is expected to be a copy of the UTXO set of the current pruning point.
+//
+// The blocks in the future of the pruning point are marked as pending verification, so their
+// UTXO diffs, acceptance data and multisets are rebuilt once the virtual is resolved.
+func (csm *consensusStateManager) RebuildUTXOState() error {
+	onEnd := logger.LogAndMeasureExecutionTime(log, "RebuildUTXOState")
+	defer onEnd()
+
+	stagingArea := model.NewStagingArea()
+	pruningPoint, err := csm.pruningStore.PruningPoint(csm.databaseContext, stagingArea)
+	if err != nil {
+		return err
+	}
+
+	err = csm.validateImportedPruningPointMultiset(stagingArea, pruningPoint)
+	if err != nil {
+		return err
+	}
+
+	// The UTXO diffs are deleted in a separate staging area, since the UTXO diff
+	// store commits deletions after additions, and the diff of the pruning point
+	// is staged again below
+	deletionStagingArea := model.NewStagingArea()
+	csm.utxoDiffStore.Delete(deletionStagingArea, pruningPoint)
+	pendingBlockCount, err := csm.stagePruningPointFutureAsPendingVerification(
+		stagingArea, deletionStagingArea, pruningPoint)
+	if err != nil {
+		return err
+	}
+	log.Infof("Marked %d blocks in the future of the pruning point %s as pending verification",
+		pendingBlockCount, pruningPoint)
+
+	log.Debugf("Setting the pruning point as the only virtual parent")
+	err = csm.dagTopologyManager.SetParents(stagingArea, model.VirtualBlockHash, []*externalapi.DomainHash{pruningPoint})
+	if err != nil {
+		return err
+	}
+	err = csm.ghostdagManager.GHOSTDAG(stagingArea, model.VirtualBlockHash)
+	if err != nil {
+		return err
+	}
+	csm.stageDiff(stagingArea, pruningPoint, utxo.NewUTXODiff(), nil)
+	_, err = csm.difficultyManager.StageDAADataAndReturnRequiredDifficulty(stagingArea, model.VirtualBlockHash, false)
+	if err != nil {
+		return err
+	}
+
+	dbTx, err := csm.databaseContext.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTx.RollbackUnlessClosed()
+
+	err = deletionStagingArea.Commit(dbTx)
+	if err != nil {
+		return err
+	}
+	err = stagingArea.Commit(dbTx)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Starting to import virtual UTXO set and pruning point utxo set")
+	err = csm.consensusStateStore.StartImportingPruningPointUTXOSet(dbTx)
+	if err != nil {
+		return err
+	}
+
+	err = dbTx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return csm.importVirtualUTXOSetAndPruningPointUTXOSet(pruningPoint)
+}
+
+func (csm *consensusStateManager) validateImportedPruningPointMultiset(stagingArea *model.StagingArea,
+	pruningPoint *externalapi.DomainHash) error {
+
+	importedPruningPointMultiset, err := csm.pruningStore.ImportedPruningPointMultiset(csm.databaseContext)
+	if err != nil {
+		return err
+	}
+
+	var expectedMultisetHash *externalapi.DomainHash
+	if pruningPoint.Equal(csm.genesisHash) {
+		// The UTXO set the genesis is loaded with doesn't satisfy its UTXO
+		// commitment, so it's compared to the multiset of the genesis instead
+		genesisMultiset, err := csm.multisetStore.Get(csm.databaseContext, stagingArea, pruningPoint)
+		if err != nil {
+			return err
+		}
+		expectedMultisetHash = genesisMultiset.Hash()
+	} else {
+		pruningPointHeader, err := csm.blockHeaderStore.BlockHeader(csm.databaseContext, stagingArea, pruningPoint)
+		if err != nil {
+			return err
+		}
+		expectedMultisetHash = pruningPointHeader.UTXOCommitment()
+	}
+
+	if !expectedMultisetHash.Equal(importedPruningPointMultiset.Hash()) {
+		return errors.Wrapf(ruleerrors.ErrBadPruningPointUTXOSet, "the expected multiset hash of the pruning "+
+			"point UTXO set is %s but got %s", expectedMultisetHash, *importedPruningPointMultiset.Hash())
+	}
+	return nil
+}
+
+// stagePruningPointFutureAsPendingVerification stages the status of every block in the future of the
+// pruning point whose UTXO state was resolved as pending verification, stages the deletion of their
+// UTXO diffs in deletionStagingArea, and returns their amount
+func (csm *consensusStateManager) stagePruningPointFutureAsPendingVerification(
+	stagingArea, deletionStagingArea *model.StagingArea, pruningPoint *externalapi.DomainHash) (int, error) {
+
+	tips, err := csm.consensusStateStore.Tips(stagingArea, csm.databaseContext)
+	if err != nil {
+		return 0, err
+	}
+
+	pendingBlockCount := 0
+	visited := hashset.New()
+	queue := tips
+	for len(queue) > 0 {
+		var current *externalapi.DomainHash
+		current, queue = queue[0], queue[1:]
+		if visited.Contains(current) || current.Equal(pruningPoint) {
+			continue
+		}
+		visited.Add(current)
+
+		isInFutureOfPruningPoint, err := csm.dagTopologyManager.IsAncestorOf(stagingArea, pruningPoint, current)
+		if err != nil {
+			return 0, err
+		}
+		if !isInFutureOfPruningPoint {
+			continue
+		}
+
+		status, err := csm.blockStatusStore.Get(csm.databaseContext, stagingArea, current)
+		if err != nil {
+			return 0, err
+		}
+		if status == externalapi.StatusUTXOValid || status == externalapi.StatusDisqualifiedFromChain {
+			csm.blockStatusStore.Stage(stagingArea, current, externalapi.StatusUTXOPendingVerification)
+			csm.utxoDiffStore.Delete(deletionStagingArea, current)
+			pendingBlockCount++
+		}
+
+		parents, err := csm.dagTopologyManager.Parents(stagingArea, current)
+		if err != nil {
+			return 0, err
+		}
+		queue = append(queue, parents...)
+	}
+
+	return pendingBlockCount, nil
+}
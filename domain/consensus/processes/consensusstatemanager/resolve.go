@@ -9,11 +9,11 @@ import (
 	"sort"
 )
 
-// tipsInDecreasingGHOSTDAGParentSelectionOrder returns the current DAG tips in decreasing parent selection order.
-// This means that the first tip in the resulting list would be the GHOSTDAG selected parent, and if removed from the list,
-// the second tip would be the selected parent, and so on.
+// tipsInDecreasingGHOSTDAGParentSelectionOrder returns the current virtual parent candidates in decreasing parent
+// selection order. This means that the first tip in the resulting list would be the GHOSTDAG selected parent, and if
+// removed from the list, the second tip would be the selected parent, and so on.
 func (csm *consensusStateManager) tipsInDecreasingGHOSTDAGParentSelectionOrder(stagingArea *model.StagingArea) ([]*externalapi.DomainHash, error) {
-	tips, err := csm.consensusStateStore.Tips(stagingArea, csm.databaseContext)
+	tips, err := csm.virtualParentCandidates(stagingArea)
 	if err != nil {
 		return nil, err
 	}
@@ -49,8 +49,8 @@ func (csm *consensusStateManager) findNextPendingTip(stagingArea *model.StagingA
 
 		if isViolatingFinality {
 			if shouldNotify {
-				//TODO: Send finality conflict notification
 				log.Warnf("Skipping %s tip resolution because it violates finality", tip)
+				csm.queueFinalityConflict(tip)
 			}
 			continue
 		}
@@ -70,7 +70,7 @@ func (csm *consensusStateManager) findNextPendingTip(stagingArea *model.StagingA
 // getGHOSTDAGLowerTips returns the set of tips which are lower in GHOSTDAG parent selection order than `pendingTip`. i.e.,
 // they can be added to virtual parents but `pendingTip` will remain the virtual selected parent
 func (csm *consensusStateManager) getGHOSTDAGLowerTips(stagingArea *model.StagingArea, pendingTip *externalapi.DomainHash) ([]*externalapi.DomainHash, error) {
-	tips, err := csm.consensusStateStore.Tips(stagingArea, csm.databaseContext)
+	tips, err := csm.virtualParentCandidates(stagingArea)
 	if err != nil {
 		return nil, err
 	}
@@ -69,6 +69,17 @@ func (csm *consensusStateManager) updateVirtualWithParents(
 		return nil, err
 	}
 
+	// The virtual selected parent may be one that doesn't win the previous one, once blocks
+	// were invalidated or reconsidered
+	virtualSelectedParent, err := csm.virtualSelectedParent(stagingArea)
+	if err != nil {
+		return nil, err
+	}
+	err = csm.makeUTXODiffRoot(stagingArea, virtualSelectedParent)
+	if err != nil {
+		return nil, err
+	}
+
 	// This is needed for `csm.CalculatePastUTXOAndAcceptanceData`
 	_, err = csm.difficultyManager.StageDAADataAndReturnRequiredDifficulty(stagingArea, model.VirtualBlockHash, false)
 	if err != nil {
@@ -130,3 +141,44 @@ func (csm *consensusStateManager) updateSelectedTipUTXODiff(
 
 	return nil
 }
+
+// makeUTXODiffRoot makes the UTXO diff of the given block relative to the virtual, so that the
+// UTXO diff paths of all blocks end at it. The previous root gets the given block as its UTXO diff child.
+func (csm *consensusStateManager) makeUTXODiffRoot(stagingArea *model.StagingArea, blockHash *externalapi.DomainHash) error {
+	hasUTXODiffChild, err := csm.utxoDiffStore.HasUTXODiffChild(csm.databaseContext, stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+	if !hasUTXODiffChild {
+		return nil
+	}
+
+	root := blockHash
+	for hasUTXODiffChild {
+		root, err = csm.utxoDiffStore.UTXODiffChild(csm.databaseContext, stagingArea, root)
+		if err != nil {
+			return err
+		}
+		hasUTXODiffChild, err = csm.utxoDiffStore.HasUTXODiffChild(csm.databaseContext, stagingArea, root)
+		if err != nil {
+			return err
+		}
+	}
+	log.Debugf("Moving the UTXO diff root from %s to %s", root, blockHash)
+
+	blockPastUTXO, err := csm.restorePastUTXO(stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+	rootPastUTXO, err := csm.utxoDiffStore.UTXODiff(csm.databaseContext, stagingArea, root)
+	if err != nil {
+		return err
+	}
+	rootUTXODiff, err := blockPastUTXO.DiffFrom(rootPastUTXO)
+	if err != nil {
+		return err
+	}
+	csm.stageDiff(stagingArea, root, rootUTXODiff, blockHash)
+	csm.stageDiff(stagingArea, blockHash, blockPastUTXO, nil)
+	return nil
+}
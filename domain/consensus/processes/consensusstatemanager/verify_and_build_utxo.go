@@ -1,8 +1,6 @@
 package consensusstatemanager
 
 import (
-	"sort"
-
 	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
 
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
@@ -101,11 +99,11 @@ func (csm *consensusStateManager) validateAcceptedIDMerkleRoot(block *externalap
 	log.Tracef("validateAcceptedIDMerkleRoot start for block %s", blockHash)
 	defer log.Tracef("validateAcceptedIDMerkleRoot end for block %s", blockHash)
 
-	calculatedAcceptedIDMerkleRoot := calculateAcceptedIDMerkleRoot(acceptanceData)
+	calculatedAcceptedIDMerkleRoot := merkle.BuildAcceptedIDMerkleTree(acceptanceData).Root()
 	if !block.Header.AcceptedIDMerkleRoot().Equal(calculatedAcceptedIDMerkleRoot) {
 		return errors.Wrapf(ruleerrors.ErrBadMerkleRoot, "block %s accepted ID merkle root is invalid - block "+
 			"header indicates %s, but calculated value is %s",
-			blockHash, block.Header.UTXOCommitment(), calculatedAcceptedIDMerkleRoot)
+			blockHash, block.Header.AcceptedIDMerkleRoot(), calculatedAcceptedIDMerkleRoot)
 	}
 
 	return nil
@@ -130,28 +128,6 @@ func (csm *consensusStateManager) validateUTXOCommitment(
 	return nil
 }
 
-func calculateAcceptedIDMerkleRoot(multiblockAcceptanceData externalapi.AcceptanceData) *externalapi.DomainHash {
-	log.Tracef("calculateAcceptedIDMerkleRoot start")
-	defer log.Tracef("calculateAcceptedIDMerkleRoot end")
-
-	var acceptedTransactions []*externalapi.DomainTransaction
-
-	for _, blockAcceptanceData := range multiblockAcceptanceData {
-		for _, transactionAcceptance := range blockAcceptanceData.TransactionAcceptanceData {
-			if !transactionAcceptance.IsAccepted {
-				continue
-			}
-			acceptedTransactions = append(acceptedTransactions, transactionAcceptance.Transaction)
-		}
-	}
-	sort.Slice(acceptedTransactions, func(i, j int) bool {
-		return consensushashing.TransactionID(acceptedTransactions[i]).Less(
-			consensushashing.TransactionID(acceptedTransactions[j]))
-	})
-
-	return merkle.CalculateIDMerkleRoot(acceptedTransactions)
-}
-
 func (csm *consensusStateManager) validateCoinbaseTransaction(stagingArea *model.StagingArea,
 	blockHash *externalapi.DomainHash, coinbaseTransaction *externalapi.DomainTransaction) error {
 
@@ -2,6 +2,7 @@ package pastmediantimemanager
 
 import (
 	"sort"
+	"sync/atomic"
 
 	"github.com/kaspanet/kaspad/domain/consensus/utils/sorters"
 
@@ -24,7 +25,22 @@ type pastMedianTimeManager struct {
 
 	genesisHash *externalapi.DomainHash
 
-	virtualPastMedianTimeCache int64
+	virtualPastMedianTimeCache atomic.Pointer[virtualPastMedianTime]
+}
+
+// virtualPastMedianTime is the past median time of the virtual whose GHOSTDAG data is
+// ghostdagData. Block validation stages virtuals that might never be committed, so the
+// cached past median time is used only by a staging area whose virtual has the same
+// selected parent and mergeset, which determine its window.
+type virtualPastMedianTime struct {
+	ghostdagData   *externalapi.BlockGHOSTDAGData
+	pastMedianTime int64
+}
+
+func (vpmt *virtualPastMedianTime) isOf(ghostdagData *externalapi.BlockGHOSTDAGData) bool {
+	return vpmt.ghostdagData.SelectedParent().Equal(ghostdagData.SelectedParent()) &&
+		externalapi.HashesEqual(vpmt.ghostdagData.MergeSetBlues(), ghostdagData.MergeSetBlues()) &&
+		externalapi.HashesEqual(vpmt.ghostdagData.MergeSetReds(), ghostdagData.MergeSetReds())
 }
 
 // New instantiates a new PastMedianTimeManager
@@ -49,8 +65,17 @@ func New(timestampDeviationTolerance int,
 
 // PastMedianTime returns the past median time for some block
 func (pmtm *pastMedianTimeManager) PastMedianTime(stagingArea *model.StagingArea, blockHash *externalapi.DomainHash) (int64, error) {
-	if blockHash == model.VirtualBlockHash && pmtm.virtualPastMedianTimeCache != 0 {
-		return pmtm.virtualPastMedianTimeCache, nil
+	var virtualGHOSTDAGData *externalapi.BlockGHOSTDAGData
+	if blockHash == model.VirtualBlockHash {
+		var err error
+		virtualGHOSTDAGData, err = pmtm.ghostdagDataStore.Get(pmtm.databaseContext, stagingArea, blockHash, false)
+		if err != nil {
+			return 0, err
+		}
+		cached := pmtm.virtualPastMedianTimeCache.Load()
+		if cached != nil && cached.isOf(virtualGHOSTDAGData) {
+			return cached.pastMedianTime, nil
+		}
 	}
 	window, err := pmtm.dagTraversalManager.BlockWindow(stagingArea, blockHash, 2*pmtm.timestampDeviationTolerance-1)
 	if err != nil {
@@ -70,7 +95,10 @@ func (pmtm *pastMedianTimeManager) PastMedianTime(stagingArea *model.StagingArea
 	}
 
 	if blockHash == model.VirtualBlockHash {
-		pmtm.virtualPastMedianTimeCache = pastMedianTime
+		pmtm.virtualPastMedianTimeCache.Store(&virtualPastMedianTime{
+			ghostdagData:   virtualGHOSTDAGData,
+			pastMedianTime: pastMedianTime,
+		})
 	}
 
 	return pastMedianTime, nil
@@ -98,5 +126,5 @@ func (pmtm *pastMedianTimeManager) windowMedianTimestamp(
 }
 
 func (pmtm *pastMedianTimeManager) InvalidateVirtualPastMedianTimeCache() {
-	pmtm.virtualPastMedianTimeCache = 0
+	pmtm.virtualPastMedianTimeCache.Store(nil)
 }
@@ -14,6 +14,8 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/model/testapi"
 	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/badgerdb"
 	"github.com/pkg/errors"
 )
 
@@ -21,10 +23,16 @@ import (
 const numBlocksExponent = 12
 
 func initializeTest(t *testing.T, testName string) (tc testapi.TestConsensus, teardown func(keepDataDir bool)) {
+	return initializeTestWithFactory(t, consensus.NewFactory(), testName)
+}
+
+func initializeTestWithFactory(t *testing.T, factory consensus.Factory, testName string) (
+	tc testapi.TestConsensus, teardown func(keepDataDir bool)) {
+
 	t.Parallel()
 	consensusConfig := consensus.Config{Params: dagconfig.SimnetParams}
 	consensusConfig.SkipProofOfWork = true
-	tc, teardown, err := consensus.NewFactory().NewTestConsensus(&consensusConfig, testName)
+	tc, teardown, err := factory.NewTestConsensus(&consensusConfig, testName)
 	if err != nil {
 		t.Fatalf("Error setting up consensus: %+v", err)
 	}
@@ -256,3 +264,15 @@ func TestAttackAlternateReorg(t *testing.T) {
 	tips := buildJsonDAG(t, tc, true)
 	addAlternatingReorgBlocks(t, tc, tips)
 }
+
+func TestAttackOnBadgerDB(t *testing.T) {
+	factory := consensus.NewFactory()
+	// The memtable is small enough for a reindex to be too big for a single badger transaction
+	factory.SetTestDatabaseOpener(func(path string, _ int) (database.Database, error) {
+		return badgerdb.NewBadgerDBWithOptions(badgerdb.Options(path, 0).WithMemTableSize(1024 * 1024).
+			WithValueThreshold(1024))
+	})
+	tc, teardown := initializeTestWithFactory(t, factory, "TestAttackOnBadgerDB")
+	defer teardown(false)
+	buildJsonDAG(t, tc, true)
+}
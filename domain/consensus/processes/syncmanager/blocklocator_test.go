@@ -226,3 +226,66 @@ func TestCreateHeadersSelectedChainBlockLocator(t *testing.T) {
 		}
 	})
 }
+
+func TestCreateAntichainLocator(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, tearDown, err := factory.NewTestConsensus(consensusConfig, "TestCreateAntichainLocator")
+		if err != nil {
+			t.Fatalf("NewTestConsensus: %+v", err)
+		}
+		defer tearDown(false)
+
+		chain := []*externalapi.DomainHash{consensusConfig.GenesisHash}
+		for i := 0; i < 5; i++ {
+			tipHash, _, err := tc.AddBlock([]*externalapi.DomainHash{chain[len(chain)-1]}, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			chain = append(chain, tipHash)
+		}
+		sideTipHash, _, err := tc.AddBlock([]*externalapi.DomainHash{chain[2]}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+
+		locator, err := tc.CreateAntichainLocator(0, 10)
+		if err != nil {
+			t.Fatalf("CreateAntichainLocator: %+v", err)
+		}
+		if !locator.ChainLocator[0].Equal(chain[5]) {
+			t.Fatalf("expected the chain locator to start at the sink %s, but it starts at %s",
+				chain[5], locator.ChainLocator[0])
+		}
+		if !externalapi.HashesEqual(locator.ChainLocator[len(locator.ChainLocator)-1:], chain[:1]) {
+			t.Fatalf("expected the chain locator to end at the pruning point, but got %s", locator.ChainLocator)
+		}
+		if len(locator.Tips) != 2 {
+			t.Fatalf("expected 2 tips, but got %s", locator.Tips)
+		}
+
+		// The tips with the highest blue work are kept
+		locator, err = tc.CreateAntichainLocator(0, 1)
+		if err != nil {
+			t.Fatalf("CreateAntichainLocator: %+v", err)
+		}
+		if !externalapi.HashesEqual(locator.Tips, chain[5:]) {
+			t.Fatalf("expected the tip of the selected chain to be kept, but got %s", locator.Tips)
+		}
+
+		unknownHash := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{0xff})
+		highestSharedChainHash, missingHashes, err := tc.AntichainLocatorMissingBlocks(&externalapi.AntichainLocator{
+			ChainLocator: externalapi.BlockLocator{unknownHash, chain[3], chain[1]},
+			Tips:         []*externalapi.DomainHash{sideTipHash, unknownHash},
+		})
+		if err != nil {
+			t.Fatalf("AntichainLocatorMissingBlocks: %+v", err)
+		}
+		if !highestSharedChainHash.Equal(chain[3]) {
+			t.Fatalf("expected the highest shared chain block to be %s, but got %s", chain[3], highestSharedChainHash)
+		}
+		if !externalapi.HashesEqual(missingHashes, []*externalapi.DomainHash{unknownHash}) {
+			t.Fatalf("expected only the unknown tip to be missing, but got %s", missingHashes)
+		}
+	})
+}
@@ -1,8 +1,6 @@
 package transactionvalidator
 
 import (
-	"math"
-
 	"github.com/kaspanet/kaspad/domain/consensus/model"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
@@ -10,40 +8,13 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/util/processingstats"
 	"github.com/pkg/errors"
 )
 
 // IsFinalizedTransaction determines whether or not a transaction is finalized.
 func (v *transactionValidator) IsFinalizedTransaction(tx *externalapi.DomainTransaction, blockDAAScore uint64, blockTime int64) bool {
-	// Lock time of zero means the transaction is finalized.
-	lockTime := tx.LockTime
-	if lockTime == 0 {
-		return true
-	}
-
-	// The lock time field of a transaction is either a block DAA score at
-	// which the transaction is finalized or a timestamp depending on if the
-	// value is before the constants.LockTimeThreshold. When it is under the
-	// threshold it is a DAA score.
-	blockTimeOrBlueScore := uint64(0)
-	if lockTime < constants.LockTimeThreshold {
-		blockTimeOrBlueScore = blockDAAScore
-	} else {
-		blockTimeOrBlueScore = uint64(blockTime)
-	}
-	if lockTime < blockTimeOrBlueScore {
-		return true
-	}
-
-	// At this point, the transaction's lock time hasn't occurred yet, but
-	// the transaction might still be finalized if the sequence number
-	// for all transaction inputs is maxed out.
-	for _, input := range tx.Inputs {
-		if input.Sequence != math.MaxUint64 {
-			return false
-		}
-	}
-	return true
+	return transactionhelper.IsFinalizedTransaction(tx, blockDAAScore, blockTime)
 }
 
 // ValidateTransactionInContextIgnoringUTXO validates the transaction with consensus context but ignoring UTXO
@@ -95,7 +66,17 @@ func (v *transactionValidator) ValidateTransactionInContextAndPopulateFee(stagin
 		return err
 	}
 
-	err = v.validateTransactionScripts(tx)
+	povDAAScore, err := v.daaBlocksStore.DAAScore(v.databaseContext, stagingArea, povBlockHash)
+	if err != nil {
+		return err
+	}
+	// The scripts of transactions that are validated against the virtual, for the mempool
+	// and for block templates, aren't part of block processing
+	if !povBlockHash.Equal(model.VirtualBlockHash) {
+		onScriptValidationEnd := processingstats.Measure(processingstats.ScriptValidation)
+		defer onScriptValidationEnd()
+	}
+	err = v.validateTransactionScripts(tx, txscript.ConsensusVerifyFlags(v.dagParams, povDAAScore))
 	if err != nil {
 		return err
 	}
@@ -225,7 +206,19 @@ func (v *transactionValidator) checkTransactionSequenceLock(stagingArea *model.S
 	return nil
 }
 
-func (v *transactionValidator) validateTransactionScripts(tx *externalapi.DomainTransaction) error {
+func (v *transactionValidator) validateTransactionScripts(tx *externalapi.DomainTransaction,
+	scriptFlags txscript.ScriptFlags) error {
+
+	// A transaction whose scripts were already executed successfully with the same flags,
+	// for example when the mempool accepted it, isn't executed again
+	var transactionHash *externalapi.DomainHash
+	if allInputsHaveUTXOEntries(tx) {
+		transactionHash = consensushashing.TransactionHash(tx)
+		if v.scriptCache.Exists(transactionHash, scriptFlags) {
+			return nil
+		}
+	}
+
 	var missingOutpoints []*externalapi.DomainOutpoint
 	sighashReusedValues := &consensushashing.SighashReusedValues{}
 
@@ -238,8 +231,15 @@ func (v *transactionValidator) validateTransactionScripts(tx *externalapi.Domain
 			continue
 		}
 
+		// The scripts of the input may have been executed as part of another transaction
+		// that only differs in the signature scripts of its other inputs
+		spendingInputHash := txscript.SpendingInputHash(tx, i)
+		if v.inputScriptCache.Exists(&input.PreviousOutpoint, spendingInputHash, scriptFlags) {
+			continue
+		}
+
 		scriptPubKey := utxoEntry.ScriptPublicKey()
-		vm, err := txscript.NewEngine(scriptPubKey, tx, i, txscript.ScriptNoFlags, v.sigCache, v.sigCacheECDSA, sighashReusedValues)
+		vm, err := txscript.NewEngine(scriptPubKey, tx, i, scriptFlags, v.sigCache, v.sigCacheECDSA, sighashReusedValues)
 		if err != nil {
 			return errors.Wrapf(ruleerrors.ErrScriptMalformed, "failed to parse input "+
 				"%d which references output %s - "+
@@ -258,13 +258,24 @@ func (v *transactionValidator) validateTransactionScripts(tx *externalapi.Domain
 				i,
 				input.PreviousOutpoint, err, sigScript, scriptPubKey)
 		}
+		v.inputScriptCache.Add(&input.PreviousOutpoint, spendingInputHash, scriptFlags)
 	}
 	if len(missingOutpoints) > 0 {
 		return ruleerrors.NewErrMissingTxOut(missingOutpoints)
 	}
+	v.scriptCache.Add(transactionHash, scriptFlags)
 	return nil
 }
 
+func allInputsHaveUTXOEntries(tx *externalapi.DomainTransaction) bool {
+	for _, input := range tx.Inputs {
+		if input.UTXOEntry == nil {
+			return false
+		}
+	}
+	return true
+}
+
 func (v *transactionValidator) calcTxSequenceLockFromReferencedUTXOEntries(stagingArea *model.StagingArea,
 	povBlockHash *externalapi.DomainHash, tx *externalapi.DomainTransaction) (*sequenceLock, error) {
 
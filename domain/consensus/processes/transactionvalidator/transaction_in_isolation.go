@@ -6,12 +6,17 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
 	"github.com/pkg/errors"
 )
 
 // ValidateTransactionInIsolation validates the parts of the transaction that can be validated context-free
 func (v *transactionValidator) ValidateTransactionInIsolation(tx *externalapi.DomainTransaction, povDAAScore uint64) error {
-	err := v.checkTransactionInputCount(tx)
+	versionParams, err := v.checkTransactionVersion(tx, povDAAScore)
+	if err != nil {
+		return err
+	}
+	err = v.checkTransactionInputCount(tx)
 	if err != nil {
 		return err
 	}
@@ -36,7 +41,7 @@ func (v *transactionValidator) ValidateTransactionInIsolation(tx *externalapi.Do
 		return err
 	}
 
-	err = v.checkNativeTransactionPayload(tx)
+	err = v.checkNativeTransactionPayload(tx, versionParams)
 	if err != nil {
 		return err
 	}
@@ -47,13 +52,26 @@ func (v *transactionValidator) ValidateTransactionInIsolation(tx *externalapi.Do
 		return err
 	}
 
-	if tx.Version > constants.MaxTransactionVersion {
-		return errors.Wrapf(ruleerrors.ErrTransactionVersionIsUnknown, "validation failed: unknown transaction version. ")
-	}
-
 	return nil
 }
 
+// checkTransactionVersion makes sure the transaction version is defined in the DAG params and
+// active at povDAAScore, and returns its rules
+func (v *transactionValidator) checkTransactionVersion(tx *externalapi.DomainTransaction,
+	povDAAScore uint64) (*dagconfig.TransactionVersionParams, error) {
+
+	versionParams, ok := v.dagParams.TransactionVersion(tx.Version)
+	if !ok {
+		return nil, errors.Wrapf(ruleerrors.ErrTransactionVersionIsUnknown, "validation failed: unknown "+
+			"transaction version %d. The maximum known version is %d", tx.Version, v.dagParams.MaxTransactionVersion())
+	}
+	if povDAAScore < versionParams.ActivationDAAScore {
+		return nil, errors.Wrapf(ruleerrors.ErrTransactionVersionIsNotActive, "transaction version %d is "+
+			"active from DAA score %d, but the DAA score is %d", tx.Version, versionParams.ActivationDAAScore, povDAAScore)
+	}
+	return versionParams, nil
+}
+
 func (v *transactionValidator) checkTransactionInputCount(tx *externalapi.DomainTransaction) error {
 	// A non-coinbase transaction must have at least one input.
 	if !transactionhelper.IsCoinBase(tx) && len(tx.Inputs) == 0 {
@@ -167,7 +185,12 @@ func (v *transactionValidator) checkSubnetworkRegistryTransaction(tx *externalap
 	return nil
 }
 
-func (v *transactionValidator) checkNativeTransactionPayload(tx *externalapi.DomainTransaction) error {
+func (v *transactionValidator) checkNativeTransactionPayload(tx *externalapi.DomainTransaction,
+	versionParams *dagconfig.TransactionVersionParams) error {
+
+	if versionParams.Features.Has(dagconfig.TransactionFeatureNativePayload) {
+		return nil
+	}
 	if tx.SubnetworkID == subnetworks.SubnetworkIDNative && len(tx.Payload) > 0 {
 		return errors.Wrapf(ruleerrors.ErrInvalidPayload, "transaction in the native subnetwork "+
 			"includes a payload")
@@ -10,6 +10,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
 	"github.com/pkg/errors"
 )
 
@@ -129,6 +130,52 @@ func TestValidateTransactionInIsolationAndPopulateMass(t *testing.T) {
 	})
 }
 
+func TestValidateTransactionVersion(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		const versionOneActivationDAAScore = 100
+
+		cfg := *consensusConfig
+		cfg.TransactionVersions = []dagconfig.TransactionVersionParams{
+			{ActivationDAAScore: 0},
+			{ActivationDAAScore: versionOneActivationDAAScore, Features: dagconfig.TransactionFeatureNativePayload},
+		}
+
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(&cfg, "TestValidateTransactionVersion")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		tests := []struct {
+			name        string
+			version     uint16
+			payload     []byte
+			daaScore    uint64
+			expectedErr error
+		}{
+			{"version 0", 0, nil, 0, nil},
+			{"version 0 with payload", 0, []byte{1}, versionOneActivationDAAScore, ruleerrors.ErrInvalidPayload},
+			{"version 1 before activation", 1, nil, versionOneActivationDAAScore - 1,
+				ruleerrors.ErrTransactionVersionIsNotActive},
+			{"version 1 after activation", 1, nil, versionOneActivationDAAScore, nil},
+			{"version 1 with payload", 1, []byte{1}, versionOneActivationDAAScore, nil},
+			{"unknown version", 2, nil, versionOneActivationDAAScore, ruleerrors.ErrTransactionVersionIsUnknown},
+		}
+
+		for _, test := range tests {
+			tx := createTxForTest(1, 1, 1, nil)
+			tx.Version = test.version
+			tx.Payload = test.payload
+
+			err := tc.TransactionValidator().ValidateTransactionInIsolation(tx, test.daaScore)
+			if !errors.Is(err, test.expectedErr) {
+				t.Errorf("TestValidateTransactionVersion: '%s': unexpected error %+v", test.name, err)
+			}
+		}
+	})
+}
+
 func createTxForTest(numInputs uint32, numOutputs uint32, outputValue uint64, subnetworkData *txSubnetworkData) *externalapi.DomainTransaction {
 	txIns := []*externalapi.DomainTransactionInput{}
 	txOuts := []*externalapi.DomainTransactionOutput{}
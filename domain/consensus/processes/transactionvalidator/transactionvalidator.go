@@ -4,10 +4,15 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/model"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
 	"github.com/kaspanet/kaspad/util/txmass"
 )
 
-const sigCacheSize = 10_000
+// The sizes of the validation caches of a transaction validator that isn't given shared ones
+const (
+	sigCacheSize    = 10_000
+	scriptCacheSize = 10_000
+)
 
 // transactionValidator exposes a set of validation classes, after which
 // it's possible to determine whether either a transaction is valid
@@ -21,35 +26,47 @@ type transactionValidator struct {
 	maxCoinbasePayloadLength                uint64
 	ghostdagK                               externalapi.KType
 	coinbasePayloadScriptPublicKeyMaxLength uint8
+	dagParams                               *dagconfig.Params
 	sigCache                                *txscript.SigCache
 	sigCacheECDSA                           *txscript.SigCacheECDSA
+	scriptCache                             *txscript.ScriptCache
+	inputScriptCache                        *txscript.InputScriptCache
 	txMassCalculator                        *txmass.Calculator
 }
 
-// New instantiates a new TransactionValidator
+// New instantiates a new TransactionValidator. When validationCaches is nil, the validator
+// creates caches of its own.
 func New(blockCoinbaseMaturity uint64,
 	enableNonNativeSubnetworks bool,
 	maxCoinbasePayloadLength uint64,
 	ghostdagK externalapi.KType,
 	coinbasePayloadScriptPublicKeyMaxLength uint8,
+	dagParams *dagconfig.Params,
 	databaseContext model.DBReader,
 	pastMedianTimeManager model.PastMedianTimeManager,
 	ghostdagDataStore model.GHOSTDAGDataStore,
 	daaBlocksStore model.DAABlocksStore,
-	txMassCalculator *txmass.Calculator) model.TransactionValidator {
+	txMassCalculator *txmass.Calculator,
+	validationCaches *txscript.ValidationCaches) model.TransactionValidator {
 
+	if validationCaches == nil {
+		validationCaches = txscript.NewValidationCaches(sigCacheSize, scriptCacheSize)
+	}
 	return &transactionValidator{
 		blockCoinbaseMaturity:                   blockCoinbaseMaturity,
 		enableNonNativeSubnetworks:              enableNonNativeSubnetworks,
 		maxCoinbasePayloadLength:                maxCoinbasePayloadLength,
 		ghostdagK:                               ghostdagK,
 		coinbasePayloadScriptPublicKeyMaxLength: coinbasePayloadScriptPublicKeyMaxLength,
+		dagParams:                               dagParams,
 		databaseContext:                         databaseContext,
 		pastMedianTimeManager:                   pastMedianTimeManager,
 		ghostdagDataStore:                       ghostdagDataStore,
 		daaBlocksStore:                          daaBlocksStore,
-		sigCache:                                txscript.NewSigCache(sigCacheSize),
-		sigCacheECDSA:                           txscript.NewSigCacheECDSA(sigCacheSize),
+		sigCache:                                validationCaches.SigCache,
+		sigCacheECDSA:                           validationCaches.SigCacheECDSA,
+		scriptCache:                             validationCaches.ScriptCache,
+		inputScriptCache:                        validationCaches.InputScriptCache,
 		txMassCalculator:                        txMassCalculator,
 	}
 }
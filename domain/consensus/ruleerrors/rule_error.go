@@ -215,6 +215,10 @@ var (
 	//ErrTransactionVersionIsUnknown indicates that the transaction version is unknown.
 	ErrTransactionVersionIsUnknown = newRuleError("ErrTransactionVersionIsUnknown")
 
+	//ErrTransactionVersionIsNotActive indicates that the transaction version is known but
+	//isn't active yet at the DAA score of the block.
+	ErrTransactionVersionIsNotActive = newRuleError("ErrTransactionVersionIsNotActive")
+
 	// ErrPrunedBlock indicates that the block currently being validated had already been pruned.
 	ErrPrunedBlock = newRuleError("ErrPrunedBlock")
 
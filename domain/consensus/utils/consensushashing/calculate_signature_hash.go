@@ -1,6 +1,9 @@
 package consensushashing
 
 import (
+	"bytes"
+	"io"
+
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/hashes"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/serialization"
@@ -56,6 +59,23 @@ type SighashReusedValues struct {
 	sigOpCountsHash     *externalapi.DomainHash
 	outputsHash         *externalapi.DomainHash
 	payloadHash         *externalapi.DomainHash
+
+	// preimageParts caches, per hash type, the serialized parts of the
+	// signature hash preimage that precede and follow the fields of the
+	// signed input.
+	preimageParts map[SigHashType]*sighashPreimageParts
+}
+
+// sighashPreimageParts holds the parts of the signature hash preimage that are the same for all
+// inputs signed with the same hash type. They're serialized once per transaction and hashed with
+// a single write per input, which is as close to a midstate as the keyed blake2b writer allows:
+// its state can't be cloned, so the prefix must be rehashed, but never reserialized.
+type sighashPreimageParts struct {
+	prefix []byte
+
+	// suffix is nil for SigHashSingle, where the suffix commits to the
+	// output of the signed input's index, and so differs between inputs
+	suffix []byte
 }
 
 // CalculateSignatureHashSchnorr will, given a script and hash type calculate the signature hash
@@ -94,17 +114,10 @@ func calculateSignatureHash(tx *externalapi.DomainTransaction, inputIndex int, t
 	prevScriptPublicKey *externalapi.ScriptPublicKey, hashType SigHashType, reusedValues *SighashReusedValues) (
 	*externalapi.DomainHash, error) {
 
-	hashWriter := hashes.NewTransactionSigningHashWriter()
-	infallibleWriteElement(hashWriter, tx.Version)
-
-	previousOutputsHash := getPreviousOutputsHash(tx, hashType, reusedValues)
-	infallibleWriteElement(hashWriter, previousOutputsHash)
-
-	sequencesHash := getSequencesHash(tx, hashType, reusedValues)
-	infallibleWriteElement(hashWriter, sequencesHash)
+	preimageParts := getPreimageParts(tx, hashType, reusedValues)
 
-	sigOpCountsHash := getSigOpCountsHash(tx, hashType, reusedValues)
-	infallibleWriteElement(hashWriter, sigOpCountsHash)
+	hashWriter := hashes.NewTransactionSigningHashWriter()
+	hashWriter.InfallibleWrite(preimageParts.prefix)
 
 	hashOutpoint(hashWriter, txIn.PreviousOutpoint)
 
@@ -117,20 +130,56 @@ func calculateSignatureHash(tx *externalapi.DomainTransaction, inputIndex int, t
 
 	infallibleWriteElement(hashWriter, txIn.SigOpCount)
 
-	outputsHash := getOutputsHash(tx, inputIndex, hashType, reusedValues)
-	infallibleWriteElement(hashWriter, outputsHash)
+	if preimageParts.suffix != nil {
+		hashWriter.InfallibleWrite(preimageParts.suffix)
+	} else {
+		writePreimageSuffix(hashWriter, tx, inputIndex, hashType, reusedValues)
+	}
 
-	infallibleWriteElement(hashWriter, tx.LockTime)
+	return hashWriter.Finalize(), nil
+}
 
-	infallibleWriteElement(hashWriter, tx.SubnetworkID)
-	infallibleWriteElement(hashWriter, tx.Gas)
+func getPreimageParts(tx *externalapi.DomainTransaction, hashType SigHashType,
+	reusedValues *SighashReusedValues) *sighashPreimageParts {
 
-	payloadHash := getPayloadHash(tx, reusedValues)
-	infallibleWriteElement(hashWriter, payloadHash)
+	if preimageParts, ok := reusedValues.preimageParts[hashType]; ok {
+		return preimageParts
+	}
 
-	infallibleWriteElement(hashWriter, uint8(hashType))
+	prefix := &bytes.Buffer{}
+	infallibleWriteElement(prefix, tx.Version)
+	infallibleWriteElement(prefix, getPreviousOutputsHash(tx, hashType, reusedValues))
+	infallibleWriteElement(prefix, getSequencesHash(tx, hashType, reusedValues))
+	infallibleWriteElement(prefix, getSigOpCountsHash(tx, hashType, reusedValues))
+	preimageParts := &sighashPreimageParts{prefix: prefix.Bytes()}
+
+	if !hashType.isSigHashSingle() {
+		// The input index is only used by SigHashSingle
+		suffix := &bytes.Buffer{}
+		writePreimageSuffix(suffix, tx, 0, hashType, reusedValues)
+		preimageParts.suffix = suffix.Bytes()
+	}
 
-	return hashWriter.Finalize(), nil
+	if reusedValues.preimageParts == nil {
+		reusedValues.preimageParts = make(map[SigHashType]*sighashPreimageParts)
+	}
+	reusedValues.preimageParts[hashType] = preimageParts
+	return preimageParts
+}
+
+func writePreimageSuffix(writer io.Writer, tx *externalapi.DomainTransaction, inputIndex int,
+	hashType SigHashType, reusedValues *SighashReusedValues) {
+
+	infallibleWriteElement(writer, getOutputsHash(tx, inputIndex, hashType, reusedValues))
+
+	infallibleWriteElement(writer, tx.LockTime)
+
+	infallibleWriteElement(writer, tx.SubnetworkID)
+	infallibleWriteElement(writer, tx.Gas)
+
+	infallibleWriteElement(writer, getPayloadHash(tx, reusedValues))
+
+	infallibleWriteElement(writer, uint8(hashType))
 }
 
 func getPreviousOutputsHash(tx *externalapi.DomainTransaction, hashType SigHashType, reusedValues *SighashReusedValues) *externalapi.DomainHash {
@@ -233,11 +282,11 @@ func hashOutpoint(hashWriter hashes.HashWriter, outpoint externalapi.DomainOutpo
 	infallibleWriteElement(hashWriter, outpoint.Index)
 }
 
-func infallibleWriteElement(hashWriter hashes.HashWriter, element interface{}) {
-	err := serialization.WriteElement(hashWriter, element)
+func infallibleWriteElement(writer io.Writer, element interface{}) {
+	err := serialization.WriteElement(writer, element)
 	if err != nil {
 		// It seems like this could only happen if the writer returned an error.
-		// and this writer should never return an error (no allocations or possible failures)
+		// and neither hash writers nor buffers should ever return an error (no allocations or possible failures)
 		// the only non-writer error path here is unknown types in `WriteElement`
 		panic(errors.Wrap(err, "TransactionHashForSigning() failed. this should never fail for structurally-valid transactions"))
 	}
@@ -333,6 +333,37 @@ func TestCalculateSignatureHashECDSA(t *testing.T) {
 	}
 }
 
+func TestSighashReusedValues(t *testing.T) {
+	nativeTx, subnetworkTx, err := generateTxs()
+	if err != nil {
+		t.Fatalf("Error from generateTxs: %+v", err)
+	}
+	hashTypes := []consensushashing.SigHashType{all, none, single, allAnyoneCanPay, noneAnyoneCanPay, singleAnyoneCanPay}
+
+	// Signature hashes calculated with values reused across all the inputs and hash types
+	// of a transaction must be the same as ones calculated from scratch
+	for _, tx := range []*externalapi.DomainTransaction{nativeTx, subnetworkTx} {
+		reusedValues := &consensushashing.SighashReusedValues{}
+		for inputIndex := range tx.Inputs {
+			for _, hashType := range hashTypes {
+				reusedHash, err := consensushashing.CalculateSignatureHashSchnorr(tx, inputIndex, hashType, reusedValues)
+				if err != nil {
+					t.Fatalf("Error from CalculateSignatureHashSchnorr: %+v", err)
+				}
+				expectedHash, err := consensushashing.CalculateSignatureHashSchnorr(
+					tx, inputIndex, hashType, &consensushashing.SighashReusedValues{})
+				if err != nil {
+					t.Fatalf("Error from CalculateSignatureHashSchnorr: %+v", err)
+				}
+				if !reusedHash.Equal(expectedHash) {
+					t.Errorf("input %d, hash type %d: expected signature hash %s but got %s",
+						inputIndex, hashType, expectedHash, reusedHash)
+				}
+			}
+		}
+	}
+}
+
 func generateTxs() (nativeTx, subnetworkTx *externalapi.DomainTransaction, err error) {
 	genesisCoinbase := dagconfig.SimnetParams.GenesisBlock.Transactions[0]
 	genesisCoinbaseTransactionID := consensushashing.TransactionID(genesisCoinbase)
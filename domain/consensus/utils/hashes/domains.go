@@ -16,6 +16,8 @@ const (
 	proofOfWorkDomain             = "ProofOfWorkHash"
 	heavyHashDomain               = "HeavyHash"
 	merkleBranchDomain            = "MerkleBranchHash"
+	compactFilterDomain           = "CompactFilterHash"
+	compactFilterHeaderDomain     = "CompactFilterHeader"
 )
 
 // transactionSigningECDSADomainHash is a hashed version of transcationSigningECDSADomain that is used
@@ -86,3 +88,21 @@ func NewMerkleBranchHashWriter() HashWriter {
 	}
 	return HashWriter{blake}
 }
+
+// NewCompactFilterHashWriter Returns a new HashWriter used for hashing compact block filters
+func NewCompactFilterHashWriter() HashWriter {
+	blake, err := blake2b.New256([]byte(compactFilterDomain))
+	if err != nil {
+		panic(errors.Wrapf(err, "this should never happen. %s is less than 64 bytes", compactFilterDomain))
+	}
+	return HashWriter{blake}
+}
+
+// NewCompactFilterHeaderWriter Returns a new HashWriter used for the headers of compact block filters
+func NewCompactFilterHeaderWriter() HashWriter {
+	blake, err := blake2b.New256([]byte(compactFilterHeaderDomain))
+	if err != nil {
+		panic(errors.Wrapf(err, "this should never happen. %s is less than 64 bytes", compactFilterHeaderDomain))
+	}
+	return HashWriter{blake}
+}
@@ -1,6 +1,8 @@
 package lrucache
 
 import (
+	"sync"
+
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 )
 
@@ -9,6 +11,7 @@ import (
 type LRUCache struct {
 	cache    map[externalapi.DomainHash]interface{}
 	capacity int
+	lock     sync.RWMutex
 }
 
 // New creates a new LRUCache
@@ -27,6 +30,9 @@ func New(capacity int, preallocate bool) *LRUCache {
 
 // Add adds an entry to the LRUCache
 func (c *LRUCache) Add(key *externalapi.DomainHash, value interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	c.cache[*key] = value
 
 	if len(c.cache) > c.capacity {
@@ -36,6 +42,9 @@ func (c *LRUCache) Add(key *externalapi.DomainHash, value interface{}) {
 
 // Get returns the entry for the given key, or (nil, false) otherwise
 func (c *LRUCache) Get(key *externalapi.DomainHash) (interface{}, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	value, ok := c.cache[*key]
 	if !ok {
 		return nil, false
@@ -45,6 +54,9 @@ func (c *LRUCache) Get(key *externalapi.DomainHash) (interface{}, bool) {
 
 // Has returns whether the LRUCache contains the given key
 func (c *LRUCache) Has(key *externalapi.DomainHash) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	_, ok := c.cache[*key]
 	return ok
 }
@@ -52,14 +64,37 @@ func (c *LRUCache) Has(key *externalapi.DomainHash) bool {
 // Remove removes the entry for the the given key. Does nothing if
 // the entry does not exist
 func (c *LRUCache) Remove(key *externalapi.DomainHash) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	delete(c.cache, *key)
 }
 
+// Len returns the number of entries in the cache
+func (c *LRUCache) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return len(c.cache)
+}
+
+// SetCapacity changes the capacity of the cache, and evicts random entries if it
+// holds more
+func (c *LRUCache) SetCapacity(capacity int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.capacity = capacity
+	for len(c.cache) > c.capacity {
+		c.evictRandom()
+	}
+}
+
 func (c *LRUCache) evictRandom() {
 	var keyToEvict externalapi.DomainHash
 	for key := range c.cache {
 		keyToEvict = key
 		break
 	}
-	c.Remove(&keyToEvict)
+	delete(c.cache, keyToEvict)
 }
@@ -1,6 +1,10 @@
 package lrucacheghostdagdata
 
-import "github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+import (
+	"sync"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
 
 type lruKey struct {
 	blockHash     externalapi.DomainHash
@@ -19,6 +23,7 @@ func newKey(blockHash *externalapi.DomainHash, isTrustedData bool) lruKey {
 type LRUCache struct {
 	cache    map[lruKey]*externalapi.BlockGHOSTDAGData
 	capacity int
+	lock     sync.RWMutex
 }
 
 // New creates a new LRUCache
@@ -37,6 +42,9 @@ func New(capacity int, preallocate bool) *LRUCache {
 
 // Add adds an entry to the LRUCache
 func (c *LRUCache) Add(blockHash *externalapi.DomainHash, isTrustedData bool, value *externalapi.BlockGHOSTDAGData) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	key := newKey(blockHash, isTrustedData)
 	c.cache[key] = value
 
@@ -47,6 +55,9 @@ func (c *LRUCache) Add(blockHash *externalapi.DomainHash, isTrustedData bool, va
 
 // Get returns the entry for the given key, or (nil, false) otherwise
 func (c *LRUCache) Get(blockHash *externalapi.DomainHash, isTrustedData bool) (*externalapi.BlockGHOSTDAGData, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	key := newKey(blockHash, isTrustedData)
 	value, ok := c.cache[key]
 	if !ok {
@@ -57,6 +68,9 @@ func (c *LRUCache) Get(blockHash *externalapi.DomainHash, isTrustedData bool) (*
 
 // Has returns whether the LRUCache contains the given key
 func (c *LRUCache) Has(blockHash *externalapi.DomainHash, isTrustedData bool) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	key := newKey(blockHash, isTrustedData)
 	_, ok := c.cache[key]
 	return ok
@@ -65,6 +79,9 @@ func (c *LRUCache) Has(blockHash *externalapi.DomainHash, isTrustedData bool) bo
 // Remove removes the entry for the the given key. Does nothing if
 // the entry does not exist
 func (c *LRUCache) Remove(blockHash *externalapi.DomainHash, isTrustedData bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	key := newKey(blockHash, isTrustedData)
 	delete(c.cache, key)
 }
@@ -75,5 +92,5 @@ func (c *LRUCache) evictRandom() {
 		keyToEvict = key
 		break
 	}
-	c.Remove(&keyToEvict.blockHash, keyToEvict.isTrustedData)
+	delete(c.cache, keyToEvict)
 }
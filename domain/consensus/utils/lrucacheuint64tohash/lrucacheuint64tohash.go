@@ -1,12 +1,17 @@
 package lrucacheuint64tohash
 
-import "github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+import (
+	"sync"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
 
 // LRUCache is a least-recently-used cache from
 // uint64 to DomainHash
 type LRUCache struct {
 	cache    map[uint64]*externalapi.DomainHash
 	capacity int
+	lock     sync.RWMutex
 }
 
 // New creates a new LRUCache
@@ -25,6 +30,9 @@ func New(capacity int, preallocate bool) *LRUCache {
 
 // Add adds an entry to the LRUCache
 func (c *LRUCache) Add(key uint64, value *externalapi.DomainHash) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	c.cache[key] = value
 
 	if len(c.cache) > c.capacity {
@@ -34,6 +42,9 @@ func (c *LRUCache) Add(key uint64, value *externalapi.DomainHash) {
 
 // Get returns the entry for the given key, or (nil, false) otherwise
 func (c *LRUCache) Get(key uint64) (*externalapi.DomainHash, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	value, ok := c.cache[key]
 	if !ok {
 		return nil, false
@@ -43,6 +54,9 @@ func (c *LRUCache) Get(key uint64) (*externalapi.DomainHash, bool) {
 
 // Has returns whether the LRUCache contains the given key
 func (c *LRUCache) Has(key uint64) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	_, ok := c.cache[key]
 	return ok
 }
@@ -50,6 +64,9 @@ func (c *LRUCache) Has(key uint64) bool {
 // Remove removes the entry for the the given key. Does nothing if
 // the entry does not exist
 func (c *LRUCache) Remove(key uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	delete(c.cache, key)
 }
 
@@ -59,5 +76,5 @@ func (c *LRUCache) evictRandom() {
 		keyToEvict = key
 		break
 	}
-	c.Remove(keyToEvict)
+	delete(c.cache, keyToEvict)
 }
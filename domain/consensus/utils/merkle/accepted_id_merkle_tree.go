@@ -0,0 +1,65 @@
+package merkle
+
+import (
+	"sort"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+)
+
+// AcceptedIDMerkleTree is the merkle tree of the IDs of the transactions that the merge set
+// of a block accepts, sorted by ID. Its root is the accepted ID merkle root that the header
+// of the block commits to, so a proof that a transaction ID is in the tree proves to anyone
+// who has the header that the block accepted the transaction.
+type AcceptedIDMerkleTree struct {
+	transactionIDs []*externalapi.DomainTransactionID
+	merkles        []*externalapi.DomainHash
+}
+
+// BuildAcceptedIDMerkleTree builds the accepted ID merkle tree of a block from its acceptance data
+func BuildAcceptedIDMerkleTree(acceptanceData externalapi.AcceptanceData) *AcceptedIDMerkleTree {
+	var transactionIDs []*externalapi.DomainTransactionID
+	for _, blockAcceptanceData := range acceptanceData {
+		for _, transactionAcceptance := range blockAcceptanceData.TransactionAcceptanceData {
+			if !transactionAcceptance.IsAccepted {
+				continue
+			}
+			transactionIDs = append(transactionIDs, consensushashing.TransactionID(transactionAcceptance.Transaction))
+		}
+	}
+	sort.Slice(transactionIDs, func(i, j int) bool {
+		return transactionIDs[i].Less(transactionIDs[j])
+	})
+
+	tree := &AcceptedIDMerkleTree{transactionIDs: transactionIDs}
+	if len(transactionIDs) == 0 {
+		return tree
+	}
+	leaves := make([]*externalapi.DomainHash, len(transactionIDs))
+	for i, transactionID := range transactionIDs {
+		leaves[i] = (*externalapi.DomainHash)(transactionID)
+	}
+	tree.merkles = buildMerkleTree(leaves)
+	return tree
+}
+
+// Root returns the root of the tree. Like CalculateIDMerkleRoot, the root of an empty tree
+// is the zero hash.
+func (t *AcceptedIDMerkleTree) Root() *externalapi.DomainHash {
+	if len(t.merkles) == 0 {
+		return &externalapi.DomainHash{}
+	}
+	return t.merkles[len(t.merkles)-1]
+}
+
+// Proof returns the proof that the given transaction ID is in the tree, or false if the
+// block didn't accept the transaction
+func (t *AcceptedIDMerkleTree) Proof(transactionID *externalapi.DomainTransactionID) (*Proof, bool) {
+	index := sort.Search(len(t.transactionIDs), func(i int) bool {
+		return transactionID.LessOrEqual(t.transactionIDs[i])
+	})
+	if index == len(t.transactionIDs) || !t.transactionIDs[index].Equal(transactionID) {
+		return nil, false
+	}
+	return buildProof(t.merkles, len(t.transactionIDs), index), true
+}
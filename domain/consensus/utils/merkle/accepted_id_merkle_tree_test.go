@@ -0,0 +1,73 @@
+package merkle
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+)
+
+func TestAcceptedIDMerkleTree(t *testing.T) {
+	for transactionCount := 0; transactionCount <= 9; transactionCount++ {
+		// Every third transaction isn't accepted, and the accepted ones are spread over two blocks
+		acceptanceData := externalapi.AcceptanceData{
+			&externalapi.BlockAcceptanceData{BlockHash: &externalapi.DomainHash{}},
+			&externalapi.BlockAcceptanceData{BlockHash: &externalapi.DomainHash{}},
+		}
+		var acceptedTransactions, rejectedTransactions []*externalapi.DomainTransaction
+		for i := 0; i < transactionCount*3/2; i++ {
+			transaction := &externalapi.DomainTransaction{Payload: []byte{byte(i)}}
+			isAccepted := i%3 != 2
+			blockAcceptanceData := acceptanceData[i%2]
+			blockAcceptanceData.TransactionAcceptanceData = append(blockAcceptanceData.TransactionAcceptanceData,
+				&externalapi.TransactionAcceptanceData{Transaction: transaction, IsAccepted: isAccepted})
+			if isAccepted {
+				acceptedTransactions = append(acceptedTransactions, transaction)
+			} else {
+				rejectedTransactions = append(rejectedTransactions, transaction)
+			}
+		}
+
+		tree := BuildAcceptedIDMerkleTree(acceptanceData)
+		sort.Slice(acceptedTransactions, func(i, j int) bool {
+			return consensushashing.TransactionID(acceptedTransactions[i]).Less(
+				consensushashing.TransactionID(acceptedTransactions[j]))
+		})
+		root := tree.Root()
+		if !root.Equal(CalculateIDMerkleRoot(acceptedTransactions)) {
+			t.Fatalf("%d accepted transactions: the root of the tree isn't the ID merkle root of the "+
+				"sorted accepted transactions", len(acceptedTransactions))
+		}
+
+		for _, transaction := range acceptedTransactions {
+			transactionID := consensushashing.TransactionID(transaction)
+			proof, ok := tree.Proof(transactionID)
+			if !ok {
+				t.Fatalf("No proof for accepted transaction %s", transactionID)
+			}
+			if !VerifyProof(root, transactionID, proof) {
+				t.Fatalf("The proof for accepted transaction %s doesn't verify", transactionID)
+			}
+			if len(proof.Siblings) > 0 {
+				tamperedProof := &Proof{Index: proof.Index ^ 1, Siblings: proof.Siblings}
+				if VerifyProof(root, transactionID, tamperedProof) {
+					t.Fatalf("A proof with the wrong index verifies for transaction %s", transactionID)
+				}
+			}
+			if len(rejectedTransactions) > 0 {
+				otherTransactionID := consensushashing.TransactionID(rejectedTransactions[0])
+				if VerifyProof(root, otherTransactionID, proof) {
+					t.Fatalf("The proof for transaction %s verifies for transaction %s", transactionID,
+						otherTransactionID)
+				}
+			}
+		}
+		for _, transaction := range rejectedTransactions {
+			_, ok := tree.Proof(consensushashing.TransactionID(transaction))
+			if ok {
+				t.Fatalf("Got a proof for a transaction that wasn't accepted")
+			}
+		}
+	}
+}
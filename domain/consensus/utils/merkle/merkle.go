@@ -61,6 +61,14 @@ func CalculateIDMerkleRoot(transactions []*externalapi.DomainTransaction) *exter
 
 // merkleRoot creates a merkle tree from a slice of hashes, and returns its root.
 func merkleRoot(hashes []*externalapi.DomainHash) *externalapi.DomainHash {
+	merkles := buildMerkleTree(hashes)
+	return merkles[len(merkles)-1]
+}
+
+// buildMerkleTree creates a merkle tree from a non-empty slice of hashes, and returns
+// it as a linear array: the leaves, padded with nils to the next power of two, followed
+// by every level up to the root.
+func buildMerkleTree(hashes []*externalapi.DomainHash) []*externalapi.DomainHash {
 	// Calculate how many entries are required to hold the binary merkle
 	// tree as a linear array and create an array of that size.
 	nextPoT := nextPowerOfTwo(len(hashes))
@@ -96,5 +104,5 @@ func merkleRoot(hashes []*externalapi.DomainHash) *externalapi.DomainHash {
 		offset++
 	}
 
-	return merkles[len(merkles)-1]
+	return merkles
 }
@@ -0,0 +1,87 @@
+package merkle
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+)
+
+// Proof is a proof that a hash is a leaf of a merkle tree with a given root
+type Proof struct {
+	// Index is the position of the leaf in the tree
+	Index uint32
+	// Siblings are the siblings of the nodes on the path from the leaf to the root, from the
+	// leaf up. A missing sibling is the zero hash.
+	Siblings []*externalapi.DomainHash
+}
+
+// TransactionMerkleProof returns the proof that the transaction with the given ID is in the
+// hash merkle tree of a block with the given transactions, or false if the block doesn't
+// contain the transaction. The leaf of the transaction is its hash, so the proof is verified
+// with VerifyTransactionMerkleProof against the hash merkle root of the header of the block.
+func TransactionMerkleProof(transactions []*externalapi.DomainTransaction,
+	transactionID *externalapi.DomainTransactionID) (*Proof, bool) {
+
+	for index, transaction := range transactions {
+		if !consensushashing.TransactionID(transaction).Equal(transactionID) {
+			continue
+		}
+		transactionHashes := make([]*externalapi.DomainHash, len(transactions))
+		for i, transaction := range transactions {
+			transactionHashes[i] = consensushashing.TransactionHash(transaction)
+		}
+		return buildProof(buildMerkleTree(transactionHashes), len(transactions), index), true
+	}
+	return nil, false
+}
+
+// VerifyTransactionMerkleProof returns whether the given proof proves that the transaction
+// with the given hash is a leaf of the hash merkle tree with the given root
+func VerifyTransactionMerkleProof(hashMerkleRoot *externalapi.DomainHash,
+	transactionHash *externalapi.DomainHash, proof *Proof) bool {
+
+	return verifyProof(hashMerkleRoot, transactionHash, proof)
+}
+
+// VerifyProof returns whether the given proof proves that the transaction ID is a leaf of
+// the accepted ID merkle tree with the given root
+func VerifyProof(root *externalapi.DomainHash, transactionID *externalapi.DomainTransactionID, proof *Proof) bool {
+	return verifyProof(root, (*externalapi.DomainHash)(transactionID), proof)
+}
+
+// buildProof returns the proof of the leaf at the given index of a merkle tree, which is
+// given as the linear array that buildMerkleTree returns
+func buildProof(merkles []*externalapi.DomainHash, leafCount int, index int) *Proof {
+	proof := &Proof{Index: uint32(index)}
+	levelStart := 0
+	levelWidth := nextPowerOfTwo(leafCount)
+	for levelWidth > 1 {
+		sibling := merkles[levelStart+(index^1)]
+		if sibling == nil {
+			sibling = &externalapi.DomainHash{}
+		}
+		proof.Siblings = append(proof.Siblings, sibling)
+
+		index /= 2
+		levelStart += levelWidth
+		levelWidth /= 2
+	}
+	return proof
+}
+
+func verifyProof(root *externalapi.DomainHash, leaf *externalapi.DomainHash, proof *Proof) bool {
+	if len(proof.Siblings) < 32 && proof.Index >= 1<<len(proof.Siblings) {
+		return false
+	}
+
+	node := leaf
+	index := proof.Index
+	for _, sibling := range proof.Siblings {
+		if index%2 == 0 {
+			node = hashMerkleBranches(node, sibling)
+		} else {
+			node = hashMerkleBranches(sibling, node)
+		}
+		index /= 2
+	}
+	return node.Equal(root)
+}
@@ -0,0 +1,54 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+)
+
+func TestTransactionMerkleProof(t *testing.T) {
+	missingTransaction := &externalapi.DomainTransaction{Payload: []byte("missing")}
+	for transactionCount := 1; transactionCount <= 9; transactionCount++ {
+		transactions := make([]*externalapi.DomainTransaction, transactionCount)
+		for i := range transactions {
+			// The signature script changes the hash of the transaction but not its ID
+			transactions[i] = &externalapi.DomainTransaction{
+				Inputs:  []*externalapi.DomainTransactionInput{{SignatureScript: []byte{byte(i)}}},
+				Payload: []byte{byte(i)},
+			}
+		}
+		hashMerkleRoot := CalculateHashMerkleRoot(transactions)
+
+		for i, transaction := range transactions {
+			proof, ok := TransactionMerkleProof(transactions, consensushashing.TransactionID(transaction))
+			if !ok {
+				t.Fatalf("%d transactions: no proof for transaction %d", transactionCount, i)
+			}
+			if proof.Index != uint32(i) {
+				t.Fatalf("%d transactions: expected the proof of transaction %d to have index %d but got %d",
+					transactionCount, i, i, proof.Index)
+			}
+			transactionHash := consensushashing.TransactionHash(transaction)
+			if !VerifyTransactionMerkleProof(hashMerkleRoot, transactionHash, proof) {
+				t.Fatalf("%d transactions: the proof for transaction %d doesn't verify", transactionCount, i)
+			}
+			transactionID := (*externalapi.DomainHash)(consensushashing.TransactionID(transaction))
+			if !transactionID.Equal(transactionHash) && VerifyTransactionMerkleProof(hashMerkleRoot, transactionID, proof) {
+				t.Fatalf("%d transactions: the proof for transaction %d verifies for its ID", transactionCount, i)
+			}
+			if len(proof.Siblings) > 0 {
+				tamperedProof := &Proof{Index: proof.Index ^ 1, Siblings: proof.Siblings}
+				if VerifyTransactionMerkleProof(hashMerkleRoot, transactionHash, tamperedProof) {
+					t.Fatalf("%d transactions: a proof with the wrong index verifies for transaction %d",
+						transactionCount, i)
+				}
+			}
+		}
+
+		_, ok := TransactionMerkleProof(transactions, consensushashing.TransactionID(missingTransaction))
+		if ok {
+			t.Fatalf("%d transactions: got a proof for a transaction that isn't in the block", transactionCount)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package transactionhelper
+
+import (
+	"math"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+)
+
+// IsFinalizedTransaction determines whether or not a transaction is finalized
+// in a block with the given DAA score and past median time.
+func IsFinalizedTransaction(tx *externalapi.DomainTransaction, blockDAAScore uint64, blockTime int64) bool {
+	// Lock time of zero means the transaction is finalized.
+	lockTime := tx.LockTime
+	if lockTime == 0 {
+		return true
+	}
+
+	// The lock time field of a transaction is either a block DAA score at
+	// which the transaction is finalized or a timestamp depending on if the
+	// value is before the constants.LockTimeThreshold. When it is under the
+	// threshold it is a DAA score.
+	blockTimeOrBlueScore := uint64(0)
+	if lockTime < constants.LockTimeThreshold {
+		blockTimeOrBlueScore = blockDAAScore
+	} else {
+		blockTimeOrBlueScore = uint64(blockTime)
+	}
+	if lockTime < blockTimeOrBlueScore {
+		return true
+	}
+
+	// At this point, the transaction's lock time hasn't occurred yet, but
+	// the transaction might still be finalized if the sequence number
+	// for all transaction inputs is maxed out.
+	for _, input := range tx.Inputs {
+		if input.Sequence != math.MaxUint64 {
+			return false
+		}
+	}
+	return true
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
 	"github.com/kaspanet/kaspad/infrastructure/logger"
 )
 
@@ -20,8 +21,43 @@ type ScriptFlags uint32
 const (
 	// ScriptNoFlags is used when you want to use ScriptFlags without raising any flags
 	ScriptNoFlags ScriptFlags = 0
+
+	// ScriptVerifyCleanStack defines that the stack must contain only
+	// one stack element after evaluation and that the element must be
+	// true if interpreted as a boolean.
+	ScriptVerifyCleanStack ScriptFlags = 1 << 0
+
+	// ScriptVerifyMinimalData defines that data pushes must use the
+	// smallest push operator that is able to push them.
+	ScriptVerifyMinimalData ScriptFlags = 1 << 1
 )
 
+// StandardVerifyFlags are the script flags which are used when executing
+// transaction scripts to enforce additional checks which are required for
+// the script to be considered standard. These checks help reduce issues
+// related to transaction malleability as well as allow pay-to-script hash
+// transactions. Note these flags are different than what is required for
+// the consensus rules, which are defined per network by
+// ConsensusVerifyFlags.
+const StandardVerifyFlags = ScriptVerifyCleanStack |
+	ScriptVerifyMinimalData
+
+// ConsensusVerifyFlags returns the script flags which are enforced by
+// the consensus rules of the given network at the given DAA score
+func ConsensusVerifyFlags(params *dagconfig.Params, daaScore uint64) ScriptFlags {
+	flags := ScriptNoFlags
+	if daaScore < params.ScriptVerifyFlagsActivationDAAScore {
+		return flags
+	}
+	if params.EnforceCleanStack {
+		flags |= ScriptVerifyCleanStack
+	}
+	if params.EnforceMinimalData {
+		flags |= ScriptVerifyMinimalData
+	}
+	return flags
+}
+
 const (
 	// MaxStackSize is the maximum combined height of stack and alt stack
 	// during execution.
@@ -108,7 +144,7 @@ func (vm *Engine) executeOpcode(pop *parsedOpcode) error {
 
 	// Ensure all executed data push opcodes use the minimal encoding when
 	// the minimal data verification flag is set.
-	if vm.isBranchExecuting() &&
+	if vm.isBranchExecuting() && vm.hasFlag(ScriptVerifyMinimalData) &&
 		pop.opcode.value != 0 && pop.opcode.value <= OpPushData4 {
 
 		if err := pop.checkMinimalDataPush(); err != nil {
@@ -198,7 +234,7 @@ func (vm *Engine) CheckErrorCondition(finalScript bool) error {
 	}
 
 	if finalScript {
-		if vm.dstack.Depth() > 1 {
+		if vm.hasFlag(ScriptVerifyCleanStack) && vm.dstack.Depth() > 1 {
 			str := fmt.Sprintf("stack contains %d unexpected items",
 				vm.dstack.Depth()-1)
 			return scriptError(ErrCleanStack, str)
@@ -10,6 +10,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
 
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
 )
 
 // TestBadPC sets the pc to a deliberately bad result then confirms that Step()
@@ -124,7 +125,7 @@ func TestCheckErrorCondition(t *testing.T) {
 
 			scriptPubKey := &externalapi.ScriptPublicKey{Script: mustParseShortForm(test.script, 0), Version: 0}
 
-			vm, err := NewEngine(scriptPubKey, tx, 0, 0, nil, nil, &consensushashing.SighashReusedValues{})
+			vm, err := NewEngine(scriptPubKey, tx, 0, StandardVerifyFlags, nil, nil, &consensushashing.SighashReusedValues{})
 			if err != nil {
 				t.Errorf("TestCheckErrorCondition: %d: failed to create script: %v", i, err)
 			}
@@ -154,6 +155,100 @@ func TestCheckErrorCondition(t *testing.T) {
 	}
 }
 
+// TestScriptVerifyFlags ensures that each of the script verification
+// rules that may be toggled is enforced only when its flag is set.
+func TestScriptVerifyFlags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		sigScript    string
+		scriptPubKey string
+		flag         ScriptFlags
+		expectedErr  ErrorCode
+	}{
+		{
+			name:         "stack that isn't clean",
+			sigScript:    "1 1",
+			scriptPubKey: "",
+			flag:         ScriptVerifyCleanStack,
+			expectedErr:  ErrCleanStack,
+		},
+		{
+			name:         "data push that isn't minimal",
+			sigScript:    "",
+			scriptPubKey: "PUSHDATA1 0x01 0x07 7 EQUAL",
+			flag:         ScriptVerifyMinimalData,
+			expectedErr:  ErrMinimalData,
+		},
+	}
+
+	for _, test := range tests {
+		tx := &externalapi.DomainTransaction{
+			Version: 0,
+			Inputs: []*externalapi.DomainTransactionInput{{
+				SignatureScript: mustParseShortForm(test.sigScript, 0),
+			}},
+		}
+		scriptPubKey := &externalapi.ScriptPublicKey{Script: mustParseShortForm(test.scriptPubKey, 0), Version: 0}
+
+		execute := func(flags ScriptFlags) error {
+			vm, err := NewEngine(scriptPubKey, tx, 0, flags, nil, nil, &consensushashing.SighashReusedValues{})
+			if err != nil {
+				return err
+			}
+			return vm.Execute()
+		}
+
+		err := execute(StandardVerifyFlags &^ test.flag)
+		if err != nil {
+			t.Errorf("%s: unexpected error without the flag: %v", test.name, err)
+		}
+		err = execute(test.flag)
+		if !IsErrorCode(err, test.expectedErr) {
+			t.Errorf("%s: expected %s with the flag but got: %v", test.name, test.expectedErr, err)
+		}
+	}
+}
+
+// TestPushOnlyWithoutFlags ensures that signature scripts must be push only
+// even when no script verification flags are set.
+func TestPushOnlyWithoutFlags(t *testing.T) {
+	t.Parallel()
+
+	tx := &externalapi.DomainTransaction{
+		Version: 0,
+		Inputs: []*externalapi.DomainTransactionInput{{
+			SignatureScript: mustParseShortForm("1 DUP", 0),
+		}},
+	}
+	scriptPubKey := &externalapi.ScriptPublicKey{Script: mustParseShortForm("EQUAL", 0), Version: 0}
+	_, err := NewEngine(scriptPubKey, tx, 0, ScriptNoFlags, nil, nil, &consensushashing.SighashReusedValues{})
+	if !IsErrorCode(err, ErrNotPushOnly) {
+		t.Errorf("expected %s but got: %v", ErrNotPushOnly, err)
+	}
+}
+
+// TestConsensusVerifyFlags ensures that the script verification rules
+// of a network are enforced by consensus only from their activation DAA score.
+func TestConsensusVerifyFlags(t *testing.T) {
+	t.Parallel()
+
+	params := dagconfig.MainnetParams
+	params.EnforceMinimalData = false
+	params.ScriptVerifyFlagsActivationDAAScore = 1000
+
+	if flags := ConsensusVerifyFlags(&params, 999); flags != ScriptNoFlags {
+		t.Errorf("expected no flags before the activation DAA score, but got %b", flags)
+	}
+	expectedFlags := ScriptVerifyCleanStack
+	for _, daaScore := range []uint64{1000, 1001} {
+		if flags := ConsensusVerifyFlags(&params, daaScore); flags != expectedFlags {
+			t.Errorf("expected flags %b at DAA score %d, but got %b", expectedFlags, daaScore, flags)
+		}
+	}
+}
+
 // TestCheckPubKeyEncoding ensures the internal checkPubKeyEncoding function
 // works as expected.
 func TestCheckPubKeyEncoding(t *testing.T) {
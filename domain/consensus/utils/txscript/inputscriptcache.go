@@ -0,0 +1,133 @@
+package txscript
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/hashes"
+)
+
+// inputScriptCacheKey identifies the execution of the scripts of a single input by the
+// outpoint it spends, the hash of the spending input and the flags it was executed with
+type inputScriptCacheKey struct {
+	outpoint          externalapi.DomainOutpoint
+	spendingInputHash externalapi.DomainHash
+	flags             ScriptFlags
+}
+
+// InputScriptCache caches the inputs whose scripts were executed successfully with given
+// script flags, with a randomized entry eviction policy. Unlike ScriptCache, whose entries
+// are whole transactions, a transaction that isn't found in it only has the scripts of
+// its inputs that aren't found in the cache executed. Since the entries aren't tied to
+// the mempool, they outlive the transactions that are evicted from it or replaced, so
+// the blocks of the DAG that include those transactions don't execute their scripts again.
+type InputScriptCache struct {
+	sync.RWMutex
+	validInputs map[inputScriptCacheKey]struct{}
+	maxEntries  uint
+}
+
+// NewInputScriptCache creates a new InputScriptCache with room for up to maxEntries
+// entries. Random entries are evicted to make room for new ones.
+func NewInputScriptCache(maxEntries uint) *InputScriptCache {
+	return &InputScriptCache{
+		validInputs: make(map[inputScriptCacheKey]struct{}, maxEntries),
+		maxEntries:  maxEntries,
+	}
+}
+
+// SpendingInputHash returns the hash of the input at the given index of the given
+// transaction, which commits to everything the execution of its scripts depends on:
+// the ID of the transaction, which commits to all of it but its signature scripts and
+// signature operation counts, the signature operation counts of all its inputs, and the
+// signature script and the spent UTXO entry of the input. The UTXO entry of the input
+// must be set.
+func SpendingInputHash(tx *externalapi.DomainTransaction, inputIndex int) *externalapi.DomainHash {
+	input := tx.Inputs[inputIndex]
+	utxoEntry := input.UTXOEntry
+
+	writer := hashes.NewTransactionHashWriter()
+	writer.InfallibleWrite(consensushashing.TransactionID(tx).ByteSlice())
+	for _, txInput := range tx.Inputs {
+		writer.InfallibleWrite([]byte{txInput.SigOpCount})
+	}
+	serialized := binary.LittleEndian.AppendUint32(nil, uint32(inputIndex))
+	serialized = binary.LittleEndian.AppendUint64(serialized, uint64(len(input.SignatureScript)))
+	serialized = append(serialized, input.SignatureScript...)
+	serialized = binary.LittleEndian.AppendUint64(serialized, utxoEntry.Amount())
+	serialized = binary.LittleEndian.AppendUint16(serialized, utxoEntry.ScriptPublicKey().Version)
+	serialized = binary.LittleEndian.AppendUint64(serialized, uint64(len(utxoEntry.ScriptPublicKey().Script)))
+	serialized = append(serialized, utxoEntry.ScriptPublicKey().Script...)
+	writer.InfallibleWrite(serialized)
+	return writer.Finalize()
+}
+
+// Exists returns whether the scripts of an input that spends the given outpoint and whose
+// spending input hash is the given one were executed successfully with the given flags.
+//
+// NOTE: This function is safe for concurrent access.
+func (c *InputScriptCache) Exists(outpoint *externalapi.DomainOutpoint, spendingInputHash *externalapi.DomainHash,
+	flags ScriptFlags) bool {
+
+	c.RLock()
+	defer c.RUnlock()
+
+	_, ok := c.validInputs[inputScriptCacheKey{outpoint: *outpoint, spendingInputHash: *spendingInputHash, flags: flags}]
+	return ok
+}
+
+// Add records that the scripts of an input that spends the given outpoint and whose
+// spending input hash is the given one were executed successfully with the given flags.
+// In the event that the cache is full, a random entry is evicted to make room for the
+// new one.
+//
+// NOTE: This function is safe for concurrent access.
+func (c *InputScriptCache) Add(outpoint *externalapi.DomainOutpoint, spendingInputHash *externalapi.DomainHash,
+	flags ScriptFlags) {
+
+	c.Lock()
+	defer c.Unlock()
+
+	if c.maxEntries == 0 {
+		return
+	}
+
+	if uint(len(c.validInputs)+1) > c.maxEntries {
+		// See SigCache.Add for why relying on the random starting point of the map
+		// iteration is fine
+		for key := range c.validInputs {
+			delete(c.validInputs, key)
+			break
+		}
+	}
+	c.validInputs[inputScriptCacheKey{outpoint: *outpoint, spendingInputHash: *spendingInputHash, flags: flags}] = struct{}{}
+}
+
+// Len returns the number of entries in the cache.
+//
+// NOTE: This function is safe for concurrent access.
+func (c *InputScriptCache) Len() int {
+	c.RLock()
+	defer c.RUnlock()
+
+	return len(c.validInputs)
+}
+
+// SetMaxEntries changes the maximum number of entries in the cache, and evicts random
+// entries if it has more.
+//
+// NOTE: This function is safe for concurrent access.
+func (c *InputScriptCache) SetMaxEntries(maxEntries uint) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.maxEntries = maxEntries
+	for key := range c.validInputs {
+		if uint(len(c.validInputs)) <= maxEntries {
+			break
+		}
+		delete(c.validInputs, key)
+	}
+}
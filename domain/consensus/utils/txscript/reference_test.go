@@ -139,6 +139,10 @@ func parseScriptFlags(flagStr string) (ScriptFlags, error) {
 		switch flag {
 		case "":
 			// Nothing.
+		case "CLEANSTACK":
+			flags |= ScriptVerifyCleanStack
+		case "MINIMALDATA":
+			flags |= ScriptVerifyMinimalData
 		default:
 			return flags, errors.Errorf("invalid flag: %s", flag)
 		}
@@ -0,0 +1,99 @@
+package txscript
+
+import (
+	"sync"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// scriptCacheKey identifies a transaction by its hash, which commits to its signature
+// scripts as well, and the flags its scripts were executed with
+type scriptCacheKey struct {
+	transactionHash externalapi.DomainHash
+	flags           ScriptFlags
+}
+
+// ScriptCache caches the transactions whose scripts were all executed successfully with
+// given script flags, with a randomized entry eviction policy. The outcome of the
+// execution depends only on the transaction, the UTXO entries it spends and the flags,
+// and the UTXO entries are determined by the outpoints that the transaction commits to,
+// so a transaction found in the cache doesn't need its scripts to be executed again.
+// This saves the validation of blocks most of the script executions of transactions
+// that were already accepted to the mempool.
+type ScriptCache struct {
+	sync.RWMutex
+	validTransactions map[scriptCacheKey]struct{}
+	maxEntries        uint
+}
+
+// NewScriptCache creates a new ScriptCache with room for up to maxEntries entries.
+// Random entries are evicted to make room for new ones.
+func NewScriptCache(maxEntries uint) *ScriptCache {
+	return &ScriptCache{
+		validTransactions: make(map[scriptCacheKey]struct{}, maxEntries),
+		maxEntries:        maxEntries,
+	}
+}
+
+// Exists returns whether the scripts of the transaction with the given hash were all
+// executed successfully with the given flags.
+//
+// NOTE: This function is safe for concurrent access.
+func (s *ScriptCache) Exists(transactionHash *externalapi.DomainHash, flags ScriptFlags) bool {
+	s.RLock()
+	defer s.RUnlock()
+
+	_, ok := s.validTransactions[scriptCacheKey{transactionHash: *transactionHash, flags: flags}]
+	return ok
+}
+
+// Add records that the scripts of the transaction with the given hash were all executed
+// successfully with the given flags. In the event that the cache is full, a random entry
+// is evicted to make room for the new one.
+//
+// NOTE: This function is safe for concurrent access.
+func (s *ScriptCache) Add(transactionHash *externalapi.DomainHash, flags ScriptFlags) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.maxEntries == 0 {
+		return
+	}
+
+	if uint(len(s.validTransactions)+1) > s.maxEntries {
+		// See SigCache.Add for why relying on the random starting point of the map
+		// iteration is fine
+		for key := range s.validTransactions {
+			delete(s.validTransactions, key)
+			break
+		}
+	}
+	s.validTransactions[scriptCacheKey{transactionHash: *transactionHash, flags: flags}] = struct{}{}
+}
+
+// Len returns the number of entries in the cache.
+//
+// NOTE: This function is safe for concurrent access.
+func (s *ScriptCache) Len() int {
+	s.RLock()
+	defer s.RUnlock()
+
+	return len(s.validTransactions)
+}
+
+// SetMaxEntries changes the maximum number of entries in the cache, and evicts random
+// entries if it has more.
+//
+// NOTE: This function is safe for concurrent access.
+func (s *ScriptCache) SetMaxEntries(maxEntries uint) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.maxEntries = maxEntries
+	for key := range s.validTransactions {
+		if uint(len(s.validTransactions)) <= maxEntries {
+			break
+		}
+		delete(s.validTransactions, key)
+	}
+}
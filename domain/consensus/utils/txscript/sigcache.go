@@ -5,6 +5,8 @@
 package txscript
 
 import (
+	"sync"
+
 	"github.com/kaspanet/go-secp256k1"
 )
 
@@ -30,6 +32,7 @@ type sigCacheEntry struct {
 // optimization which speeds up the validation of transactions within a block,
 // if they've already been seen and verified within the mempool.
 type SigCache struct {
+	sync.RWMutex
 	validSigs  map[secp256k1.Hash]sigCacheEntry
 	maxEntries uint
 }
@@ -52,7 +55,9 @@ func NewSigCache(maxEntries uint) *SigCache {
 // NOTE: This function is safe for concurrent access. Readers won't be blocked
 // unless there exists a writer, adding an entry to the SigCache.
 func (s *SigCache) Exists(sigHash secp256k1.Hash, sig *secp256k1.SchnorrSignature, pubKey *secp256k1.SchnorrPublicKey) bool {
+	s.RLock()
 	entry, ok := s.validSigs[sigHash]
+	s.RUnlock()
 
 	return ok && entry.pubKey.IsEqual(pubKey) && entry.sig.IsEqual(sig)
 }
@@ -65,6 +70,9 @@ func (s *SigCache) Exists(sigHash secp256k1.Hash, sig *secp256k1.SchnorrSignatur
 // NOTE: This function is safe for concurrent access. Writers will block
 // simultaneous readers until function execution has concluded.
 func (s *SigCache) Add(sigHash secp256k1.Hash, sig *secp256k1.SchnorrSignature, pubKey *secp256k1.SchnorrPublicKey) {
+	s.Lock()
+	defer s.Unlock()
+
 	if s.maxEntries == 0 {
 		return
 	}
@@ -88,3 +96,30 @@ func (s *SigCache) Add(sigHash secp256k1.Hash, sig *secp256k1.SchnorrSignature,
 	}
 	s.validSigs[sigHash] = sigCacheEntry{sig, pubKey}
 }
+
+// Len returns the number of entries in the cache.
+//
+// NOTE: This function is safe for concurrent access.
+func (s *SigCache) Len() int {
+	s.RLock()
+	defer s.RUnlock()
+
+	return len(s.validSigs)
+}
+
+// SetMaxEntries changes the maximum number of entries in the cache, and evicts random
+// entries if it has more.
+//
+// NOTE: This function is safe for concurrent access.
+func (s *SigCache) SetMaxEntries(maxEntries uint) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.maxEntries = maxEntries
+	for key := range s.validSigs {
+		if uint(len(s.validSigs)) <= maxEntries {
+			break
+		}
+		delete(s.validSigs, key)
+	}
+}
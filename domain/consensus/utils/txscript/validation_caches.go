@@ -0,0 +1,241 @@
+package txscript
+
+import (
+	"encoding/binary"
+	"os"
+
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+)
+
+// ValidationCaches are the signature and script verification caches that are shared by
+// everything that validates transactions, so that a transaction that the mempool accepted
+// isn't verified again when a block that contains it arrives.
+//
+// The input script cache isn't saved to a file, since after a restart the script
+// cache spares executing the scripts of the transactions it has
+type ValidationCaches struct {
+	SigCache         *SigCache
+	SigCacheECDSA    *SigCacheECDSA
+	ScriptCache      *ScriptCache
+	InputScriptCache *InputScriptCache
+}
+
+// NewValidationCaches creates validation caches with room for up to sigCacheSize
+// signatures of each kind and up to scriptCacheSize transactions and inputs
+func NewValidationCaches(sigCacheSize uint, scriptCacheSize uint) *ValidationCaches {
+	return &ValidationCaches{
+		SigCache:         NewSigCache(sigCacheSize),
+		SigCacheECDSA:    NewSigCacheECDSA(sigCacheSize),
+		ScriptCache:      NewScriptCache(scriptCacheSize),
+		InputScriptCache: NewInputScriptCache(scriptCacheSize),
+	}
+}
+
+// The estimated numbers of bytes an entry of each of the caches occupies in memory,
+// including the overhead of its map
+const (
+	sigCacheEntryMemory         = 220
+	scriptCacheEntryMemory      = 64
+	inputScriptCacheEntryMemory = 128
+)
+
+// MemoryUsage returns the estimated number of bytes the caches occupy
+func (vc *ValidationCaches) MemoryUsage() uint64 {
+	sigCacheEntries := uint64(vc.SigCache.Len() + vc.SigCacheECDSA.Len())
+	scriptCacheEntries := uint64(vc.ScriptCache.Len())
+	inputScriptCacheEntries := uint64(vc.InputScriptCache.Len())
+	return sigCacheEntries*sigCacheEntryMemory + scriptCacheEntries*scriptCacheEntryMemory +
+		inputScriptCacheEntries*inputScriptCacheEntryMemory
+}
+
+// SetMemoryLimit limits the caches to the given number of bytes, split so that each of
+// them has room for the same number of entries, and evicts the entries above the limit
+func (vc *ValidationCaches) SetMemoryLimit(limit uint64) {
+	maxEntries := uint(limit / (2*sigCacheEntryMemory + scriptCacheEntryMemory + inputScriptCacheEntryMemory))
+	vc.SigCache.SetMaxEntries(maxEntries)
+	vc.SigCacheECDSA.SetMaxEntries(maxEntries)
+	vc.ScriptCache.SetMaxEntries(maxEntries)
+	vc.InputScriptCache.SetMaxEntries(maxEntries)
+}
+
+// validationCachesFileVersion is the version of the format of the file the validation
+// caches are saved to. The file starts with the version as a 4 byte little-endian integer,
+// followed by the Schnorr signatures, the ECDSA signatures and the transactions of the
+// script cache. Each of them is a 4 byte little-endian count followed by that many fixed
+// size entries:
+//   - A Schnorr signature is its 32 byte hash, 64 byte signature and 32 byte public key.
+//   - An ECDSA signature is its 32 byte hash, 64 byte signature and 33 byte public key.
+//   - A transaction is its 32 byte hash and its 4 byte little-endian script flags.
+const validationCachesFileVersion = 1
+
+const (
+	schnorrEntrySize     = secp256k1.HashSize + secp256k1.SerializedSchnorrSignatureSize + secp256k1.SerializedSchnorrPublicKeySize
+	ecdsaEntrySize       = secp256k1.HashSize + secp256k1.SerializedECDSASignatureSize + secp256k1.SerializedECDSAPublicKeySize
+	scriptCacheEntrySize = externalapi.DomainHashSize + 4
+)
+
+// SaveToFile saves the entries of the caches to the file at the given path, so that they
+// can be loaded after a restart. The file is replaced only once it's completely written.
+// It returns the number of saved entries.
+func (vc *ValidationCaches) SaveToFile(path string) (int, error) {
+	serialized := binary.LittleEndian.AppendUint32(nil, validationCachesFileVersion)
+	count := 0
+
+	vc.SigCache.RLock()
+	serialized = binary.LittleEndian.AppendUint32(serialized, uint32(len(vc.SigCache.validSigs)))
+	for sigHash, entry := range vc.SigCache.validSigs {
+		serializedPubKey, err := entry.pubKey.Serialize()
+		if err != nil {
+			vc.SigCache.RUnlock()
+			return 0, err
+		}
+		serialized = append(serialized, sigHash[:]...)
+		serialized = append(serialized, entry.sig.Serialize()[:]...)
+		serialized = append(serialized, serializedPubKey[:]...)
+	}
+	count += len(vc.SigCache.validSigs)
+	vc.SigCache.RUnlock()
+
+	vc.SigCacheECDSA.RLock()
+	serialized = binary.LittleEndian.AppendUint32(serialized, uint32(len(vc.SigCacheECDSA.validSigs)))
+	for sigHash, entry := range vc.SigCacheECDSA.validSigs {
+		serializedPubKey, err := entry.pubKey.Serialize()
+		if err != nil {
+			vc.SigCacheECDSA.RUnlock()
+			return 0, err
+		}
+		serialized = append(serialized, sigHash[:]...)
+		serialized = append(serialized, entry.sig.Serialize()[:]...)
+		serialized = append(serialized, serializedPubKey[:]...)
+	}
+	count += len(vc.SigCacheECDSA.validSigs)
+	vc.SigCacheECDSA.RUnlock()
+
+	vc.ScriptCache.RLock()
+	serialized = binary.LittleEndian.AppendUint32(serialized, uint32(len(vc.ScriptCache.validTransactions)))
+	for key := range vc.ScriptCache.validTransactions {
+		serialized = append(serialized, key.transactionHash.ByteSlice()...)
+		serialized = binary.LittleEndian.AppendUint32(serialized, uint32(key.flags))
+	}
+	count += len(vc.ScriptCache.validTransactions)
+	vc.ScriptCache.RUnlock()
+
+	temporaryPath := path + ".new"
+	err := os.WriteFile(temporaryPath, serialized, 0600)
+	if err != nil {
+		return 0, err
+	}
+	err = os.Rename(temporaryPath, path)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// LoadFromFile adds the entries that were saved to the file at the given path to the
+// caches, and returns their number. A missing file is not an error.
+//
+// The entries aren't verified again, so the file must be as trusted as the database.
+func (vc *ValidationCaches) LoadFromFile(path string) (int, error) {
+	serialized, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	reader := &validationCachesReader{data: serialized}
+	version := reader.uint32()
+	if reader.err == nil && version != validationCachesFileVersion {
+		return 0, errors.Errorf("unknown validation caches file version %d", version)
+	}
+
+	count := 0
+	schnorrCount := reader.uint32()
+	for i := uint32(0); i < schnorrCount && reader.err == nil; i++ {
+		entry := reader.read(schnorrEntrySize)
+		if reader.err != nil {
+			break
+		}
+		var sigHash secp256k1.Hash
+		copy(sigHash[:], entry)
+		sig, err := secp256k1.DeserializeSchnorrSignatureFromSlice(entry[secp256k1.HashSize:][:secp256k1.SerializedSchnorrSignatureSize])
+		if err != nil {
+			return count, err
+		}
+		pubKey, err := secp256k1.DeserializeSchnorrPubKey(entry[secp256k1.HashSize+secp256k1.SerializedSchnorrSignatureSize:])
+		if err != nil {
+			return count, err
+		}
+		vc.SigCache.Add(sigHash, sig, pubKey)
+		count++
+	}
+
+	ecdsaCount := reader.uint32()
+	for i := uint32(0); i < ecdsaCount && reader.err == nil; i++ {
+		entry := reader.read(ecdsaEntrySize)
+		if reader.err != nil {
+			break
+		}
+		var sigHash secp256k1.Hash
+		copy(sigHash[:], entry)
+		sig, err := secp256k1.DeserializeECDSASignatureFromSlice(entry[secp256k1.HashSize:][:secp256k1.SerializedECDSASignatureSize])
+		if err != nil {
+			return count, err
+		}
+		pubKey, err := secp256k1.DeserializeECDSAPubKey(entry[secp256k1.HashSize+secp256k1.SerializedECDSASignatureSize:])
+		if err != nil {
+			return count, err
+		}
+		vc.SigCacheECDSA.Add(sigHash, sig, pubKey)
+		count++
+	}
+
+	scriptCacheCount := reader.uint32()
+	for i := uint32(0); i < scriptCacheCount && reader.err == nil; i++ {
+		entry := reader.read(scriptCacheEntrySize)
+		if reader.err != nil {
+			break
+		}
+		transactionHash, err := externalapi.NewDomainHashFromByteSlice(entry[:externalapi.DomainHashSize])
+		if err != nil {
+			return count, err
+		}
+		vc.ScriptCache.Add(transactionHash, ScriptFlags(binary.LittleEndian.Uint32(entry[externalapi.DomainHashSize:])))
+		count++
+	}
+
+	if reader.err != nil {
+		return count, reader.err
+	}
+	return count, nil
+}
+
+type validationCachesReader struct {
+	data []byte
+	err  error
+}
+
+func (r *validationCachesReader) read(length int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < length {
+		r.err = errors.New("the validation caches file is truncated")
+		return nil
+	}
+	result := r.data[:length]
+	r.data = r.data[length:]
+	return result
+}
+
+func (r *validationCachesReader) uint32() uint32 {
+	bytes := r.read(4)
+	if r.err != nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(bytes)
+}
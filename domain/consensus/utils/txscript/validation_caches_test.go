@@ -0,0 +1,194 @@
+package txscript
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+)
+
+// TestScriptCacheFlags tests that a transaction in the script cache is only found
+// with the flags its scripts were executed with
+func TestScriptCacheFlags(t *testing.T) {
+	scriptCache := NewScriptCache(10)
+	transactionHash := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{1})
+	scriptCache.Add(transactionHash, ScriptNoFlags)
+	if !scriptCache.Exists(transactionHash, ScriptNoFlags) {
+		t.Fatalf("previously added transaction not found in script cache")
+	}
+	if scriptCache.Exists(transactionHash, ScriptVerifyCleanStack) {
+		t.Fatalf("transaction found in script cache with flags it wasn't added with")
+	}
+
+	// A script cache with no room doesn't hold anything
+	scriptCache = NewScriptCache(0)
+	scriptCache.Add(transactionHash, ScriptNoFlags)
+	if scriptCache.Exists(transactionHash, ScriptNoFlags) {
+		t.Fatalf("transaction found in a script cache with no room")
+	}
+}
+
+// TestInputScriptCache tests that an input in the input script cache is only found with
+// the outpoint, spending input hash and flags its scripts were executed with
+func TestInputScriptCache(t *testing.T) {
+	transactionID := externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{1})
+	utxoEntry := utxo.NewUTXOEntry(100, &externalapi.ScriptPublicKey{Script: []byte{OpTrue}}, false, 0)
+	transaction := &externalapi.DomainTransaction{
+		Inputs: []*externalapi.DomainTransactionInput{
+			{PreviousOutpoint: externalapi.DomainOutpoint{TransactionID: *transactionID, Index: 0},
+				SignatureScript: []byte{1}, SigOpCount: 1, UTXOEntry: utxoEntry},
+			{PreviousOutpoint: externalapi.DomainOutpoint{TransactionID: *transactionID, Index: 1},
+				SignatureScript: []byte{2}, SigOpCount: 1, UTXOEntry: utxoEntry},
+		},
+		Outputs: []*externalapi.DomainTransactionOutput{
+			{Value: 90, ScriptPublicKey: &externalapi.ScriptPublicKey{Script: []byte{OpTrue}}},
+		},
+	}
+	spendingInputHash := SpendingInputHash(transaction, 0)
+
+	// The hash doesn't depend on the signature scripts of the other inputs, but depends on
+	// the signature script of the input and on the signature operation counts of all of them
+	otherTransaction := transaction.Clone()
+	otherTransaction.Inputs[1].SignatureScript = []byte{3}
+	if !SpendingInputHash(otherTransaction, 0).Equal(spendingInputHash) {
+		t.Fatalf("the spending input hash changed with the signature script of another input")
+	}
+	otherTransaction = transaction.Clone()
+	otherTransaction.Inputs[0].SignatureScript = []byte{3}
+	if SpendingInputHash(otherTransaction, 0).Equal(spendingInputHash) {
+		t.Fatalf("the spending input hash didn't change with the signature script of the input")
+	}
+	otherTransaction = transaction.Clone()
+	otherTransaction.Inputs[1].SigOpCount = 2
+	if SpendingInputHash(otherTransaction, 0).Equal(spendingInputHash) {
+		t.Fatalf("the spending input hash didn't change with the signature operation count of another input")
+	}
+	otherTransaction = transaction.Clone()
+	otherTransaction.Outputs[0].Value = 80
+	otherTransaction.ID = nil // The ID that was cached by the first hash is no longer the ID of the clone
+	if SpendingInputHash(otherTransaction, 0).Equal(spendingInputHash) {
+		t.Fatalf("the spending input hash didn't change with the outputs of the transaction")
+	}
+
+	inputScriptCache := NewInputScriptCache(10)
+	outpoint := &transaction.Inputs[0].PreviousOutpoint
+	inputScriptCache.Add(outpoint, spendingInputHash, ScriptNoFlags)
+	if !inputScriptCache.Exists(outpoint, spendingInputHash, ScriptNoFlags) {
+		t.Fatalf("previously added input not found in the input script cache")
+	}
+	if inputScriptCache.Exists(outpoint, spendingInputHash, ScriptVerifyCleanStack) {
+		t.Fatalf("input found in the input script cache with flags it wasn't added with")
+	}
+	if inputScriptCache.Exists(&transaction.Inputs[1].PreviousOutpoint, spendingInputHash, ScriptNoFlags) {
+		t.Fatalf("input found in the input script cache with an outpoint it wasn't added with")
+	}
+}
+
+// TestValidationCachesSaveLoad tests that the entries of saved validation caches are
+// found in the caches they're loaded into
+func TestValidationCachesSaveLoad(t *testing.T) {
+	validationCaches := NewValidationCaches(10, 10)
+
+	msg, sig, key, err := genRandomSig()
+	if err != nil {
+		t.Fatalf("unable to generate random signature test data")
+	}
+	validationCaches.SigCache.Add(*msg, sig, key)
+
+	privateKeyECDSA, err := secp256k1.GenerateECDSAPrivateKey()
+	if err != nil {
+		t.Fatalf("GenerateECDSAPrivateKey: %s", err)
+	}
+	sigECDSA, err := privateKeyECDSA.ECDSASign(msg)
+	if err != nil {
+		t.Fatalf("ECDSASign: %s", err)
+	}
+	keyECDSA, err := privateKeyECDSA.ECDSAPublicKey()
+	if err != nil {
+		t.Fatalf("ECDSAPublicKey: %s", err)
+	}
+	validationCaches.SigCacheECDSA.Add(*msg, sigECDSA, keyECDSA)
+
+	transactionHash := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{1})
+	validationCaches.ScriptCache.Add(transactionHash, ScriptVerifyCleanStack)
+
+	path := filepath.Join(t.TempDir(), "validationcaches.dat")
+	savedCount, err := validationCaches.SaveToFile(path)
+	if err != nil {
+		t.Fatalf("SaveToFile: %s", err)
+	}
+	if savedCount != 3 {
+		t.Fatalf("expected 3 saved entries but got %d", savedCount)
+	}
+
+	loadedCaches := NewValidationCaches(10, 10)
+	loadedCount, err := loadedCaches.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile: %s", err)
+	}
+	if loadedCount != 3 {
+		t.Fatalf("expected 3 loaded entries but got %d", loadedCount)
+	}
+	if !loadedCaches.SigCache.Exists(*msg, sig, key) {
+		t.Fatalf("saved Schnorr signature not found in the loaded signature cache")
+	}
+	if !loadedCaches.SigCacheECDSA.Exists(*msg, sigECDSA, keyECDSA) {
+		t.Fatalf("saved ECDSA signature not found in the loaded signature cache")
+	}
+	if !loadedCaches.ScriptCache.Exists(transactionHash, ScriptVerifyCleanStack) {
+		t.Fatalf("saved transaction not found in the loaded script cache")
+	}
+
+	// A missing file loads nothing, and a truncated one is an error
+	loadedCount, err = NewValidationCaches(10, 10).LoadFromFile(filepath.Join(t.TempDir(), "missing.dat"))
+	if err != nil || loadedCount != 0 {
+		t.Fatalf("expected a missing file to load nothing but got %d entries and error %v", loadedCount, err)
+	}
+	serialized, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+	err = os.WriteFile(path, serialized[:len(serialized)-1], 0600)
+	if err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+	_, err = NewValidationCaches(10, 10).LoadFromFile(path)
+	if err == nil {
+		t.Fatalf("expected a truncated file to fail to load")
+	}
+}
+
+// TestValidationCachesSetMemoryLimit tests that limiting the memory of the validation
+// caches evicts the entries above the limit
+func TestValidationCachesSetMemoryLimit(t *testing.T) {
+	validationCaches := NewValidationCaches(10, 10)
+	for i := byte(0); i < 10; i++ {
+		msg, sig, key, err := genRandomSig()
+		if err != nil {
+			t.Fatalf("unable to generate random signature test data")
+		}
+		validationCaches.SigCache.Add(*msg, sig, key)
+		transactionHash := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{i})
+		validationCaches.ScriptCache.Add(transactionHash, ScriptNoFlags)
+	}
+	memoryUsage := validationCaches.MemoryUsage()
+	if memoryUsage != 10*sigCacheEntryMemory+10*scriptCacheEntryMemory {
+		t.Fatalf("unexpected memory usage %d", memoryUsage)
+	}
+
+	validationCaches.SetMemoryLimit(4 * (2*sigCacheEntryMemory + scriptCacheEntryMemory + inputScriptCacheEntryMemory))
+	if validationCaches.SigCache.Len() != 4 || validationCaches.ScriptCache.Len() != 4 {
+		t.Fatalf("expected the caches to be shrunk to 4 entries each, but the signature cache has %d "+
+			"and the script cache has %d", validationCaches.SigCache.Len(), validationCaches.ScriptCache.Len())
+	}
+
+	// The caches don't grow above the limit either
+	transactionHash := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{100})
+	validationCaches.ScriptCache.Add(transactionHash, ScriptNoFlags)
+	if validationCaches.ScriptCache.Len() != 4 {
+		t.Fatalf("expected the script cache to stay at 4 entries but it has %d", validationCaches.ScriptCache.Len())
+	}
+}
@@ -0,0 +1,26 @@
+package consensus
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+type virtualUTXOSetSnapshot struct {
+	dbSnapshot          model.DBSnapshot
+	consensusStateStore model.ConsensusStateStore
+	virtualParents      []*externalapi.DomainHash
+}
+
+func (vss *virtualUTXOSetSnapshot) VirtualParents() []*externalapi.DomainHash {
+	return externalapi.CloneHashes(vss.virtualParents)
+}
+
+func (vss *virtualUTXOSetSnapshot) VirtualUTXOs(fromOutpoint *externalapi.DomainOutpoint, limit int) (
+	[]*externalapi.OutpointAndUTXOEntryPair, error) {
+
+	return vss.consensusStateStore.VirtualUTXOs(vss.dbSnapshot, fromOutpoint, limit)
+}
+
+func (vss *virtualUTXOSetSnapshot) Release() {
+	vss.dbSnapshot.Release()
+}
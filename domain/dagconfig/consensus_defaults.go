@@ -85,3 +85,9 @@ const (
 
 	defaultMergeDepth = 3600
 )
+
+// defaultTransactionVersions are the transaction versions of all the networks. Version 0,
+// which has none of the optional features, has been valid since genesis.
+var defaultTransactionVersions = []TransactionVersionParams{
+	{ActivationDAAScore: 0},
+}
@@ -74,6 +74,11 @@ type Params struct {
 	// as one method to discover peers.
 	GRPCSeeds []string
 
+	// FixedSeeds defines tiers of hard-coded peer addresses, from the highest
+	// priority to the lowest. They're used only when DNS and gRPC seeding yield
+	// no addresses, one tier at a time.
+	FixedSeeds [][]string
+
 	// GenesisBlock defines the first block of the DAG.
 	GenesisBlock *externalapi.DomainBlock
 
@@ -126,6 +131,27 @@ type Params struct {
 	RuleChangeActivationThreshold uint64
 	MinerConfirmationWindow       uint64
 
+	// These fields define which of the script verification rules that are
+	// always enforced by the mempool are enforced by consensus as well,
+	// starting at ScriptVerifyFlagsActivationDAAScore. See txscript.StandardVerifyFlags.
+	// Signature scripts must be push only regardless of these fields.
+	//
+	// The existing networks have enforced these rules since genesis, so they
+	// can't be turned off or activated later there without splitting the network.
+	EnforceCleanStack                   bool
+	EnforceMinimalData                  bool
+	ScriptVerifyFlagsActivationDAAScore uint64
+
+	// TransactionVersions are the rules of the transaction versions consensus accepts,
+	// indexed by version, so that a new version is introduced by appending its rules
+	// with the DAA score it activates at.
+	//
+	// MaxStandardTransactionVersion is the highest version the mempool accepts by default.
+	// It may lag behind the highest version consensus accepts, so that transactions of
+	// a new version are only relayed and mined once enough of the network enforces it.
+	TransactionVersions           []TransactionVersionParams
+	MaxStandardTransactionVersion uint16
+
 	// Mempool parameters
 	RelayNonStdTxs bool
 
@@ -256,6 +282,15 @@ var MainnetParams = Params{
 	RuleChangeActivationThreshold: 1916, // 95% of MinerConfirmationWindow
 	MinerConfirmationWindow:       2016, //
 
+	// Script verification rules
+	EnforceCleanStack:                   true,
+	EnforceMinimalData:                  true,
+	ScriptVerifyFlagsActivationDAAScore: 0, // The rules have been enforced since genesis
+
+	// Transaction versions
+	TransactionVersions:           defaultTransactionVersions,
+	MaxStandardTransactionVersion: 0,
+
 	// Mempool parameters
 	RelayNonStdTxs: false,
 
@@ -325,6 +360,15 @@ var TestnetParams = Params{
 	RuleChangeActivationThreshold: 1512, // 75% of MinerConfirmationWindow
 	MinerConfirmationWindow:       2016,
 
+	// Script verification rules
+	EnforceCleanStack:                   true,
+	EnforceMinimalData:                  true,
+	ScriptVerifyFlagsActivationDAAScore: 0, // The rules have been enforced since genesis
+
+	// Transaction versions
+	TransactionVersions:           defaultTransactionVersions,
+	MaxStandardTransactionVersion: 0,
+
 	// Mempool parameters
 	RelayNonStdTxs: false,
 
@@ -393,6 +437,15 @@ var SimnetParams = Params{
 	RuleChangeActivationThreshold: 75, // 75% of MinerConfirmationWindow
 	MinerConfirmationWindow:       100,
 
+	// Script verification rules
+	EnforceCleanStack:                   true,
+	EnforceMinimalData:                  true,
+	ScriptVerifyFlagsActivationDAAScore: 0, // The rules have been enforced since genesis
+
+	// Transaction versions
+	TransactionVersions:           defaultTransactionVersions,
+	MaxStandardTransactionVersion: 0,
+
 	// Mempool parameters
 	RelayNonStdTxs: false,
 
@@ -453,6 +506,15 @@ var DevnetParams = Params{
 	RuleChangeActivationThreshold: 1512, // 75% of MinerConfirmationWindow
 	MinerConfirmationWindow:       2016,
 
+	// Script verification rules
+	EnforceCleanStack:                   true,
+	EnforceMinimalData:                  true,
+	ScriptVerifyFlagsActivationDAAScore: 0, // The rules have been enforced since genesis
+
+	// Transaction versions
+	TransactionVersions:           defaultTransactionVersions,
+	MaxStandardTransactionVersion: 0,
+
 	// Mempool parameters
 	RelayNonStdTxs: false,
 
@@ -87,3 +87,21 @@ func TestSkipProofOfWork(t *testing.T) {
 		}
 	}
 }
+
+func TestMaxTransactionVersion(t *testing.T) {
+	tests := []struct {
+		transactionVersions []TransactionVersionParams
+		expected            uint16
+	}{
+		{transactionVersions: nil, expected: 0},
+		{transactionVersions: []TransactionVersionParams{{}}, expected: 0},
+		{transactionVersions: []TransactionVersionParams{{}, {ActivationDAAScore: 100}}, expected: 1},
+	}
+
+	for i, test := range tests {
+		params := Params{TransactionVersions: test.transactionVersions}
+		if maxVersion := params.MaxTransactionVersion(); maxVersion != test.expected {
+			t.Errorf("test %d: expected the max transaction version to be %d, but got %d", i, test.expected, maxVersion)
+		}
+	}
+}
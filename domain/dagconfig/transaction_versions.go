@@ -0,0 +1,44 @@
+package dagconfig
+
+// TransactionFeatures is a set of optional features that transactions of a version may use
+type TransactionFeatures uint32
+
+const (
+	// TransactionFeatureNativePayload allows transactions of the native subnetwork to carry
+	// a payload, which the signature hash already commits to
+	TransactionFeatureNativePayload TransactionFeatures = 1 << iota
+)
+
+// Has returns whether the set includes all the given features
+func (f TransactionFeatures) Has(features TransactionFeatures) bool {
+	return f&features == features
+}
+
+// TransactionVersionParams are the rules of a version of transactions
+type TransactionVersionParams struct {
+	// ActivationDAAScore is the DAA score from which transactions of the version are
+	// valid. Before it, a block that contains such a transaction is invalid.
+	ActivationDAAScore uint64 `json:"activationDaaScore"`
+
+	// Features are the optional features transactions of the version may use
+	Features TransactionFeatures `json:"features"`
+}
+
+// TransactionVersion returns the rules of the given transaction version, and false
+// if the version isn't defined
+func (p *Params) TransactionVersion(version uint16) (*TransactionVersionParams, bool) {
+	if int(version) >= len(p.TransactionVersions) {
+		return nil, false
+	}
+	return &p.TransactionVersions[version], true
+}
+
+// MaxTransactionVersion returns the highest transaction version that's defined, whether
+// or not it's active yet. It returns 0 if no version is defined, in which case
+// TransactionVersion rejects every version, including 0.
+func (p *Params) MaxTransactionVersion() uint16 {
+	if len(p.TransactionVersions) == 0 {
+		return 0
+	}
+	return uint16(len(p.TransactionVersions) - 1)
+}
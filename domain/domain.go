@@ -14,6 +14,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/prefixmanager"
 	"github.com/kaspanet/kaspad/domain/prefixmanager/prefix"
 	infrastructuredatabase "github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/intentlog"
 	"github.com/pkg/errors"
 )
 
@@ -26,6 +27,27 @@ type Domain interface {
 	CommitStagingConsensus() error
 	DeleteStagingConsensus() error
 	ConsensusEventsChannel() chan externalapi.ConsensusEvent
+
+	// Reindex rebuilds the whole DAG state by replaying the stored blocks into
+	// a staging consensus, which then replaces the current consensus
+	Reindex() error
+
+	// ReindexState rebuilds the virtual UTXO set from the pruning point UTXO set,
+	// along with the UTXO diffs, acceptance data and multisets of the blocks in
+	// the future of the pruning point
+	ReindexState() error
+
+	// EnableBlockAddedIntents makes the domain record an intent for every block
+	// with a body before it's inserted, and replays the BlockAdded events of the
+	// blocks whose intents are still pending from before the last shutdown.
+	// The intents are completed through the returned intent log by the
+	// transaction index, in the same commit that indexes their blocks.
+	EnableBlockAddedIntents() (*intentlog.IntentLog, error)
+
+	// RegisterBlockValidator registers a validator that observes every block that's
+	// inserted to consensus from now on, and may reject it. The validator remains
+	// registered when the consensus is replaced by a staging consensus.
+	RegisterBlockValidator(validator externalapi.BlockValidator)
 }
 
 type domain struct {
@@ -36,6 +58,11 @@ type domain struct {
 	consensusConfig        *consensus.Config
 	db                     infrastructuredatabase.Database
 	consensusEventsChannel chan externalapi.ConsensusEvent
+	blockAddedIntentLog    *intentlog.IntentLog
+
+	// isBlockAddedIntentLogEnabled is shared with the intent
+	// logging consensus wrappers, and is accessed atomically
+	isBlockAddedIntentLogEnabled uint32
 }
 
 func (d *domain) ConsensusEventsChannel() chan externalapi.ConsensusEvent {
@@ -52,6 +79,10 @@ func (d *domain) StagingConsensus() externalapi.Consensus {
 	return *d.stagingConsensus
 }
 
+func (d *domain) RegisterBlockValidator(validator externalapi.BlockValidator) {
+	d.consensusConfig.BlockValidators.Register(validator)
+}
+
 func (d *domain) MiningManager() miningmanager.MiningManager {
 	return d.miningManager
 }
@@ -102,6 +133,7 @@ func (d *domain) initStagingConsensus(cfg *consensus.Config) error {
 		return errors.Errorf("A fresh consensus should never return shouldMigrate=true")
 	}
 
+	consensusInstance = d.newIntentLoggingConsensus(consensusInstance)
 	d.stagingConsensus = &consensusInstance
 	return nil
 }
@@ -197,6 +229,12 @@ func New(consensusConfig *consensus.Config, mempoolConfig *mempool.Config, db in
 		}
 	}
 
+	// Every consensus that the domain creates shares the validators, so that they can be
+	// registered once
+	if consensusConfig.BlockValidators == nil {
+		consensusConfig.BlockValidators = externalapi.NewBlockValidators()
+	}
+
 	consensusEventsChan := make(chan externalapi.ConsensusEvent, 100e3)
 	consensusFactory := consensus.NewFactory()
 	consensusInstance, shouldMigrate, err := consensusFactory.NewConsensus(consensusConfig, db, activePrefix, consensusEventsChan)
@@ -205,11 +243,13 @@ func New(consensusConfig *consensus.Config, mempoolConfig *mempool.Config, db in
 	}
 
 	domainInstance := &domain{
-		consensus:              &consensusInstance,
 		consensusConfig:        consensusConfig,
 		db:                     db,
 		consensusEventsChannel: consensusEventsChan,
+		blockAddedIntentLog:    intentlog.New(db, blockAddedIntentLogName),
 	}
+	consensusInstance = domainInstance.newIntentLoggingConsensus(consensusInstance)
+	domainInstance.consensus = &consensusInstance
 
 	if shouldMigrate {
 		err := domainInstance.migrate()
@@ -0,0 +1,24 @@
+// Package domaintest provides domains for the tests of the packages that only use
+// the consensus of a domain.
+package domaintest
+
+import (
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// consensusDomain is a domain that has nothing but a consensus. Calling any of its other
+// methods panics.
+type consensusDomain struct {
+	domain.Domain
+	consensus externalapi.Consensus
+}
+
+// NewConsensusDomain returns a domain whose consensus is the given one, usually a test consensus
+func NewConsensusDomain(consensus externalapi.Consensus) domain.Domain {
+	return &consensusDomain{consensus: consensus}
+}
+
+func (cd *consensusDomain) Consensus() externalapi.Consensus {
+	return cd.consensus
+}
@@ -117,6 +117,63 @@ func syncConsensuses(syncer, syncee externalapi.Consensus) error {
 		}
 	}
 
+	err = syncBlocks(syncer, syncee)
+	if err != nil {
+		return err
+	}
+
+	pruningPoint, err := syncer.PruningPoint()
+	if err != nil {
+		return err
+	}
+
+	var fromOutpoint *externalapi.DomainOutpoint
+	const step = 100_000
+	for {
+		outpointAndUTXOEntryPairs, err := syncer.GetPruningPointUTXOs(pruningPoint, fromOutpoint, step)
+		if err != nil {
+			return err
+		}
+		fromOutpoint = outpointAndUTXOEntryPairs[len(outpointAndUTXOEntryPairs)-1].Outpoint
+		err = syncee.AppendImportedPruningPointUTXOs(outpointAndUTXOEntryPairs)
+		if err != nil {
+			return err
+		}
+		if len(outpointAndUTXOEntryPairs) < step {
+			break
+		}
+	}
+
+	// Check that ValidateAndInsertImportedPruningPoint works given the right arguments.
+	err = syncee.ValidateAndInsertImportedPruningPoint(pruningPoint)
+	if err != nil {
+		return err
+	}
+
+	emptyCoinbase := &externalapi.DomainCoinbaseData{
+		ScriptPublicKey: &externalapi.ScriptPublicKey{
+			Script:  nil,
+			Version: 0,
+		},
+	}
+
+	// Check that we can build a block just after importing the pruning point.
+	_, err = syncee.BuildBlock(emptyCoinbase, nil)
+	if err != nil {
+		return err
+	}
+
+	estimatedVirtualDAAScoreTarget, err := syncer.GetVirtualDAAScore()
+	if err != nil {
+		return err
+	}
+
+	return resolveVirtual(syncee, estimatedVirtualDAAScoreTarget)
+}
+
+// syncBlocks inserts the blocks in the future of the pruning point of the syncer
+// to the syncee, without resolving the virtual of the syncee
+func syncBlocks(syncer, syncee externalapi.Consensus) error {
 	syncerVirtualSelectedParent, err := syncer.GetVirtualSelectedParent()
 	if err != nil {
 		return err
@@ -181,49 +238,15 @@ func syncConsensuses(syncer, syncee externalapi.Consensus) error {
 		}
 	}
 
-	var fromOutpoint *externalapi.DomainOutpoint
-	const step = 100_000
-	for {
-		outpointAndUTXOEntryPairs, err := syncer.GetPruningPointUTXOs(pruningPoint, fromOutpoint, step)
-		if err != nil {
-			return err
-		}
-		fromOutpoint = outpointAndUTXOEntryPairs[len(outpointAndUTXOEntryPairs)-1].Outpoint
-		err = syncee.AppendImportedPruningPointUTXOs(outpointAndUTXOEntryPairs)
-		if err != nil {
-			return err
-		}
-		if len(outpointAndUTXOEntryPairs) < step {
-			break
-		}
-	}
-
-	// Check that ValidateAndInsertImportedPruningPoint works given the right arguments.
-	err = syncee.ValidateAndInsertImportedPruningPoint(pruningPoint)
-	if err != nil {
-		return err
-	}
-
-	emptyCoinbase := &externalapi.DomainCoinbaseData{
-		ScriptPublicKey: &externalapi.ScriptPublicKey{
-			Script:  nil,
-			Version: 0,
-		},
-	}
-
-	// Check that we can build a block just after importing the pruning point.
-	_, err = syncee.BuildBlock(emptyCoinbase, nil)
-	if err != nil {
-		return err
-	}
-
-	estimatedVirtualDAAScoreTarget, err := syncer.GetVirtualDAAScore()
-	if err != nil {
-		return err
-	}
+	return nil
+}
 
-	err = syncer.ResolveVirtual(func(virtualDAAScoreStart uint64, virtualDAAScore uint64) {
-		if estimatedVirtualDAAScoreTarget-virtualDAAScoreStart <= 0 {
+// resolveVirtual resolves the virtual of the given consensus, and reports the
+// progress relative to the given estimated virtual DAA score it's resolved to
+func resolveVirtual(consensus externalapi.Consensus, estimatedVirtualDAAScoreTarget uint64) error {
+	err := consensus.ResolveVirtual(func(virtualDAAScoreStart uint64, virtualDAAScore uint64) {
+		var percents int
+		if estimatedVirtualDAAScoreTarget <= virtualDAAScoreStart {
 			percents = 100
 		} else {
 			percents = int(float64(virtualDAAScore-virtualDAAScoreStart) / float64(estimatedVirtualDAAScoreTarget-virtualDAAScoreStart) * 100)
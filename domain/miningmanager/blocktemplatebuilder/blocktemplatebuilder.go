@@ -2,6 +2,7 @@ package blocktemplatebuilder
 
 import (
 	"github.com/kaspanet/kaspad/domain/consensus/processes/coinbasemanager"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/merkle"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
 	"github.com/kaspanet/kaspad/domain/consensusreference"
@@ -118,19 +119,101 @@ func New(consensusReference consensusreference.ConsensusReference, mempool minin
 func (btb *blockTemplateBuilder) BuildBlockTemplate(
 	coinbaseData *consensusexternalapi.DomainCoinbaseData) (*consensusexternalapi.DomainBlockTemplate, error) {
 
+	return btb.BuildBlockTemplateWithOptions(coinbaseData, nil)
+}
+
+// BuildBlockTemplateWithOptions is the same as BuildBlockTemplate, except that the
+// transactions of the template follow the given options: the excluded transactions are
+// left out, the included ones are selected before any other, and the transactions are
+// selected up to the maximum mass of the options
+func (btb *blockTemplateBuilder) BuildBlockTemplateWithOptions(coinbaseData *consensusexternalapi.DomainCoinbaseData,
+	options *miningmanagerapi.BlockTemplateOptions) (*consensusexternalapi.DomainBlockTemplate, error) {
+
+	maxMass := btb.policy.BlockMaxMass
+	excludedIDs := make(map[consensusexternalapi.DomainTransactionID]struct{})
+	includedIDs := make(map[consensusexternalapi.DomainTransactionID]struct{})
+	if options != nil {
+		if options.MaxMass > maxMass {
+			return nil, errors.Wrapf(miningmanagerapi.ErrInvalidBlockTemplateOptions,
+				"the maximum mass %d is above the maximum block mass %d", options.MaxMass, maxMass)
+		}
+		if options.MaxMass != 0 {
+			maxMass = options.MaxMass
+		}
+		for _, transactionID := range options.ExcludedTransactionIDs {
+			excludedIDs[*transactionID] = struct{}{}
+		}
+		for _, transactionID := range options.IncludedTransactionIDs {
+			if _, ok := excludedIDs[*transactionID]; ok {
+				return nil, errors.Wrapf(miningmanagerapi.ErrInvalidBlockTemplateOptions,
+					"transaction %s is both included and excluded", transactionID)
+			}
+			includedIDs[*transactionID] = struct{}{}
+		}
+	}
+
+	// Transactions whose lock time hasn't passed yet for the block of the template are left
+	// in the mempool for later templates, rather than removed from it as invalid. Wallets set
+	// the lock time to the current DAA score against fee sniping, so a reorg that lowers the
+	// DAA score of the virtual may unfinalize transactions that were only just finalized.
+	virtualInfo, err := btb.consensusReference.Consensus().GetVirtualInfo()
+	if err != nil {
+		return nil, err
+	}
+
 	mempoolTransactions := btb.mempool.BlockCandidateTransactions()
 	candidateTxs := make([]*candidateTx, 0, len(mempoolTransactions))
-	for _, tx := range mempoolTransactions {
+	includedTxs := make(map[consensusexternalapi.DomainTransactionID]*candidateTx, len(includedIDs))
+	for _, mempoolTransaction := range mempoolTransactions {
+		tx := mempoolTransaction.Transaction
+		transactionID := consensushashing.TransactionID(tx)
+		if _, ok := excludedIDs[*transactionID]; ok {
+			continue
+		}
+		if !transactionhelper.IsFinalizedTransaction(tx, virtualInfo.DAAScore, virtualInfo.PastMedianTime) {
+			log.Debugf("Leaving transaction %s out of the block template, since its lock time %d hasn't passed yet",
+				transactionID, tx.LockTime)
+			continue
+		}
 		// Calculate the tx value
 		gasLimit := uint64(0)
 		if !subnetworks.IsBuiltInOrNative(tx.SubnetworkID) {
 			panic("We currently don't support non native subnetworks")
 		}
-		candidateTxs = append(candidateTxs, &candidateTx{
+		candidate := &candidateTx{
 			DomainTransaction: tx,
-			txValue:           btb.calcTxValue(tx),
+			txValue:           btb.calcTxValue(mempoolTransaction),
 			gasLimit:          gasLimit,
-		})
+		}
+		if _, ok := includedIDs[*transactionID]; ok {
+			includedTxs[*transactionID] = candidate
+			continue
+		}
+		candidateTxs = append(candidateTxs, candidate)
+	}
+
+	// The included transactions are taken in the order they were given in
+	includedCandidateTxs := make([]*candidateTx, 0, len(includedTxs))
+	includedMass := uint64(0)
+	if options != nil {
+		takenIDs := make(map[consensusexternalapi.DomainTransactionID]struct{}, len(includedTxs))
+		for _, transactionID := range options.IncludedTransactionIDs {
+			if _, ok := takenIDs[*transactionID]; ok {
+				continue
+			}
+			includedTx, ok := includedTxs[*transactionID]
+			if !ok {
+				return nil, errors.Wrapf(miningmanagerapi.ErrInvalidBlockTemplateOptions,
+					"transaction %s isn't in the mempool, or isn't ready to be mined", transactionID)
+			}
+			takenIDs[*transactionID] = struct{}{}
+			includedCandidateTxs = append(includedCandidateTxs, includedTx)
+			includedMass += includedTx.Mass
+		}
+	}
+	if includedMass > maxMass {
+		return nil, errors.Wrapf(miningmanagerapi.ErrInvalidBlockTemplateOptions,
+			"the included transactions have a mass of %d, which is above the maximum mass %d", includedMass, maxMass)
 	}
 
 	// Sort the candidate txs by subnetworkID.
@@ -138,10 +221,10 @@ func (btb *blockTemplateBuilder) BuildBlockTemplate(
 		return subnetworks.Less(candidateTxs[i].SubnetworkID, candidateTxs[j].SubnetworkID)
 	})
 
-	log.Debugf("Considering %d transactions for inclusion to new block",
-		len(candidateTxs))
+	log.Debugf("Considering %d transactions for inclusion to new block, in addition to %d included transactions",
+		len(candidateTxs), len(includedCandidateTxs))
 
-	blockTxs := btb.selectTransactions(candidateTxs)
+	blockTxs := btb.selectTransactions(candidateTxs, includedCandidateTxs, maxMass)
 	blockTemplate, err := btb.consensusReference.Consensus().BuildBlockTemplate(coinbaseData, blockTxs.selectedTxs)
 
 	invalidTxsErr := ruleerrors.ErrInvalidTransactionsInNewBlock{}
@@ -155,7 +238,7 @@ func (btb *blockTemplateBuilder) BuildBlockTemplate(
 			log.Criticalf("Error from mempool.RemoveInvalidTransactions: %+v", err)
 		}
 		// We can call this recursively without worry because this should almost never happen
-		return btb.BuildBlockTemplate(coinbaseData)
+		return btb.BuildBlockTemplateWithOptions(coinbaseData, options)
 	}
 
 	if err != nil {
@@ -204,11 +287,15 @@ func (btb *blockTemplateBuilder) ModifyBlockTemplate(newCoinbaseData *consensuse
 // calcTxValue calculates a value to be used in transaction selection.
 // The higher the number the more likely it is that the transaction will be
 // included in the block.
-func (btb *blockTemplateBuilder) calcTxValue(tx *consensusexternalapi.DomainTransaction) float64 {
+// The value is derived from the fee rate of the transaction's best-scoring
+// package rather than from the transaction alone, so that a low-fee parent
+// of high-fee children is selected on behalf of its children.
+func (btb *blockTemplateBuilder) calcTxValue(candidate *miningmanagerapi.BlockCandidateTransaction) float64 {
 	massLimit := btb.policy.BlockMaxMass
 
-	mass := tx.Mass
-	fee := tx.Fee
+	tx := candidate.Transaction
+	mass := candidate.PackageMass
+	fee := candidate.PackageFee
 	if subnetworks.IsBuiltInOrNative(tx.SubnetworkID) {
 		return float64(fee) / (float64(mass) / float64(massLimit))
 	}
@@ -54,9 +54,12 @@ type selectedTransactions struct {
 
 // selectTransactions loops over the candidate transactions
 // and appends the ones that will be included in the next block into
-// txsForBlockTemplates.
+// txsForBlockTemplates, after includedTxs, which are always selected.
+// The total mass of the selected transactions doesn't exceed maxMass.
 // See selectTxs for further details.
-func (btb *blockTemplateBuilder) selectTransactions(candidateTxs []*candidateTx) selectedTransactions {
+func (btb *blockTemplateBuilder) selectTransactions(candidateTxs []*candidateTx, includedTxs []*candidateTx,
+	maxMass uint64) selectedTransactions {
+
 	txsForBlockTemplate := selectedTransactions{
 		selectedTxs: make([]*consensusexternalapi.DomainTransaction, 0, len(candidateTxs)),
 		txMasses:    make([]uint64, 0, len(candidateTxs)),
@@ -74,7 +77,13 @@ func (btb *blockTemplateBuilder) selectTransactions(candidateTxs []*candidateTx)
 		usedP += candidateTx.p
 	}
 
-	selectedTxs := make([]*candidateTx, 0)
+	selectedTxs := make([]*candidateTx, 0, len(includedTxs))
+	for _, includedTx := range includedTxs {
+		selectedTxs = append(selectedTxs, includedTx)
+		txsForBlockTemplate.totalMass += includedTx.Mass
+		txsForBlockTemplate.totalFees += includedTx.Fee
+	}
+
 	for len(candidateTxs)-usedCount > 0 {
 		// Rebalance the candidates if it's required
 		if usedP >= rebalanceThreshold*totalP {
@@ -102,7 +111,7 @@ func (btb *blockTemplateBuilder) selectTransactions(candidateTxs []*candidateTx)
 		// Enforce maximum transaction mass per block. Also check
 		// for overflow.
 		if txsForBlockTemplate.totalMass+selectedTx.Mass < txsForBlockTemplate.totalMass ||
-			txsForBlockTemplate.totalMass+selectedTx.Mass > btb.policy.BlockMaxMass {
+			txsForBlockTemplate.totalMass+selectedTx.Mass > maxMass {
 			log.Tracef("Tx %s would exceed the max block mass. "+
 				"As such, stopping.", consensushashing.TransactionID(tx))
 			break
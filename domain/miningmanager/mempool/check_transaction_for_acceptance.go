@@ -0,0 +1,86 @@
+package mempool
+
+import (
+	"fmt"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	miningmanagermodel "github.com/kaspanet/kaspad/domain/miningmanager/model"
+	"github.com/pkg/errors"
+)
+
+// acceptanceCheck holds the transactions of a check that would be accepted, which the
+// transactions that follow them may spend as if they were in the mempool. The transactions
+// of a package pay their fees together, so their fees aren't checked one by one.
+type acceptanceCheck struct {
+	acceptedTransactions  map[externalapi.DomainTransactionID]*externalapi.DomainTransaction
+	spendingTransactionID map[externalapi.DomainOutpoint]*externalapi.DomainTransactionID
+	isPackage             bool
+}
+
+func newAcceptanceCheck(isPackage bool) *acceptanceCheck {
+	return &acceptanceCheck{
+		acceptedTransactions:  make(map[externalapi.DomainTransactionID]*externalapi.DomainTransaction),
+		spendingTransactionID: make(map[externalapi.DomainOutpoint]*externalapi.DomainTransactionID),
+		isPackage:             isPackage,
+	}
+}
+
+// accept makes the outputs of the given transaction spendable by the transactions checked after it
+func (check *acceptanceCheck) accept(transactionID *externalapi.DomainTransactionID,
+	transaction *externalapi.DomainTransaction) {
+
+	check.acceptedTransactions[*transactionID] = transaction
+	for _, input := range transaction.Inputs {
+		check.spendingTransactionID[input.PreviousOutpoint] = transactionID
+	}
+}
+
+func (mp *mempool) checkTransactionsForAcceptance(transactions []*externalapi.DomainTransaction) (
+	[]*miningmanagermodel.TransactionAcceptanceCheck, error) {
+
+	check := newAcceptanceCheck(false)
+	results := make([]*miningmanagermodel.TransactionAcceptanceCheck, len(transactions))
+	for i, transaction := range transactions {
+		// The transaction is populated with mass, fee and UTXO entries along the way
+		transaction = transaction.Clone()
+		transactionID := consensushashing.TransactionID(transaction)
+
+		err := mp.checkTransactionForAcceptance(check, transaction)
+		if err != nil && !errors.As(err, &RuleError{}) {
+			return nil, err
+		}
+		results[i] = &miningmanagermodel.TransactionAcceptanceCheck{
+			TransactionID: transactionID,
+			RejectReason:  err,
+			Mass:          transaction.Mass,
+			Fee:           transaction.Fee,
+		}
+		if err != nil {
+			continue
+		}
+
+		check.accept(transactionID, transaction)
+	}
+	return results, nil
+}
+
+func (mp *mempool) checkTransactionForAcceptance(check *acceptanceCheck, transaction *externalapi.DomainTransaction) error {
+	err := mp.validateTransaction(transaction, check.acceptedTransactions, !check.isPackage)
+	if err != nil {
+		return err
+	}
+
+	transactionID := consensushashing.TransactionID(transaction)
+	if _, ok := check.acceptedTransactions[*transactionID]; ok {
+		return transactionRuleError(RejectDuplicate,
+			fmt.Sprintf("transaction %s was already checked", transactionID))
+	}
+	for _, input := range transaction.Inputs {
+		if spendingTransactionID, ok := check.spendingTransactionID[input.PreviousOutpoint]; ok {
+			return transactionRuleError(RejectDuplicate, fmt.Sprintf("output %s already spent by the "+
+				"checked transaction %s", input.PreviousOutpoint, spendingTransactionID))
+		}
+	}
+	return nil
+}
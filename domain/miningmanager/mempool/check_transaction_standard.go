@@ -3,6 +3,7 @@ package mempool
 import (
 	"fmt"
 
+	"github.com/kaspanet/kaspad/util"
 	"github.com/kaspanet/kaspad/util/txmass"
 
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
@@ -105,6 +106,12 @@ func (mp *mempool) checkTransactionStandardInIsolation(transaction *externalapi.
 //
 // It is exported for use by transaction generators and wallets
 func (mp *mempool) IsTransactionOutputDust(output *externalapi.DomainTransactionOutput) bool {
+	return IsTransactionOutputDust(output, mp.config.MinimumRelayTransactionFee)
+}
+
+// IsTransactionOutputDust is the same as the mempool method with the same name, for
+// wallets that don't have a mempool, given the minimum transaction relay fee to use
+func IsTransactionOutputDust(output *externalapi.DomainTransactionOutput, minimumRelayTransactionFee util.Amount) bool {
 	// Unspendable outputs are considered dust.
 	if txscript.IsUnspendable(output.ScriptPublicKey.Script) {
 		return true
@@ -131,7 +138,7 @@ func (mp *mempool) IsTransactionOutputDust(output *externalapi.DomainTransaction
 
 	// The output is considered dust if the cost to the network to spend the
 	// coins is more than 1/3 of the minimum free transaction relay fee.
-	// mp.config.MinimumRelayTransactionFee is in sompi/KB, so multiply
+	// minimumRelayTransactionFee is in sompi/KB, so multiply
 	// by 1000 to convert to bytes.
 	//
 	// Using the typical values for a pay-to-pubkey transaction from
@@ -141,17 +148,18 @@ func (mp *mempool) IsTransactionOutputDust(output *externalapi.DomainTransaction
 	//
 	// The following is equivalent to (value/totalSerializedSize) * (1/3) * 1000
 	// without needing to do floating point math.
-	return output.Value*1000/(3*totalSerializedSize) < uint64(mp.config.MinimumRelayTransactionFee)
+	return output.Value*1000/(3*totalSerializedSize) < uint64(minimumRelayTransactionFee)
 }
 
 // checkTransactionStandardInContext performs a series of checks on a transaction's
 // inputs to ensure they are "standard". A standard transaction input within the
 // context of this function is one whose referenced public key script is of a
 // standard form and, for pay-to-script-hash, does not have more than
-// maxStandardP2SHSigOps signature operations.
-// In addition, makes sure that the transaction's fee is above the minimum for acceptance
-// into the mempool and relay
-func (mp *mempool) checkTransactionStandardInContext(transaction *externalapi.DomainTransaction) error {
+// maxStandardP2SHSigOps signature operations, and whose scripts satisfy the
+// standard script verification flags.
+// In addition, if checkFee is set, makes sure that the transaction's fee is above the minimum
+// for acceptance into the mempool and relay
+func (mp *mempool) checkTransactionStandardInContext(transaction *externalapi.DomainTransaction, checkFee bool) error {
 	for i, input := range transaction.Inputs {
 		// It is safe to elide existence and index checks here since
 		// they have already been checked prior to calling this
@@ -174,6 +182,14 @@ func (mp *mempool) checkTransactionStandardInContext(transaction *externalapi.Do
 		}
 	}
 
+	err := mp.checkTransactionScriptsStandard(transaction)
+	if err != nil {
+		return err
+	}
+
+	if !checkFee {
+		return nil
+	}
 	minimumFee := mp.minimumRequiredTransactionRelayFee(transaction.Mass)
 	if transaction.Fee < minimumFee {
 		str := fmt.Sprintf("transaction %s has %d fees which is under the required amount of %d",
@@ -184,16 +200,70 @@ func (mp *mempool) checkTransactionStandardInContext(transaction *externalapi.Do
 	return nil
 }
 
+// checkTransactionScriptsStandard executes the scripts of the transaction with the
+// standard script verification flags. Consensus validation has already executed them
+// with the consensus flags, so this is skipped if those include the standard flags.
+// The validation caches that are shared with consensus spare executing the scripts of
+// a transaction or an input that was already checked, and verifying signatures again.
+func (mp *mempool) checkTransactionScriptsStandard(transaction *externalapi.DomainTransaction) error {
+	standardFlags := mp.config.StandardScriptVerifyFlags
+	if standardFlags&^mp.config.ConsensusScriptVerifyFlags == 0 {
+		return nil
+	}
+
+	var sigCache *txscript.SigCache
+	var sigCacheECDSA *txscript.SigCacheECDSA
+	var transactionHash *externalapi.DomainHash
+	validationCaches := mp.config.ValidationCaches
+	if validationCaches != nil {
+		transactionHash = consensushashing.TransactionHash(transaction)
+		if validationCaches.ScriptCache.Exists(transactionHash, standardFlags) {
+			return nil
+		}
+		sigCache = validationCaches.SigCache
+		sigCacheECDSA = validationCaches.SigCacheECDSA
+	}
+
+	sighashReusedValues := &consensushashing.SighashReusedValues{}
+	for i, input := range transaction.Inputs {
+		var spendingInputHash *externalapi.DomainHash
+		if validationCaches != nil {
+			spendingInputHash = txscript.SpendingInputHash(transaction, i)
+			if validationCaches.InputScriptCache.Exists(&input.PreviousOutpoint, spendingInputHash, standardFlags) {
+				continue
+			}
+		}
+
+		vm, err := txscript.NewEngine(input.UTXOEntry.ScriptPublicKey(), transaction, i, standardFlags,
+			sigCache, sigCacheECDSA, sighashReusedValues)
+		if err == nil {
+			err = vm.Execute()
+		}
+		if err != nil {
+			str := fmt.Sprintf("transaction input #%d has a non-standard script: %s", i, err)
+			return transactionRuleError(RejectNonstandard, str)
+		}
+		if validationCaches != nil {
+			validationCaches.InputScriptCache.Add(&input.PreviousOutpoint, spendingInputHash, standardFlags)
+		}
+	}
+	if validationCaches != nil {
+		validationCaches.ScriptCache.Add(transactionHash, standardFlags)
+	}
+	return nil
+}
+
 // minimumRequiredTransactionRelayFee returns the minimum transaction fee required for a
 // transaction with the passed mass to be accepted into the mampool and relayed.
 func (mp *mempool) minimumRequiredTransactionRelayFee(mass uint64) uint64 {
 	// Calculate the minimum fee for a transaction to be allowed into the
-	// mempool and relayed by scaling the base fee. MinimumRelayTransactionFee is in
+	// mempool and relayed by scaling the base fee. The minimum fee rate is in
 	// sompi/kg so multiply by mass (which is in grams) and divide by 1000 to get minimum sompis.
-	minimumFee := (mass * uint64(mp.config.MinimumRelayTransactionFee)) / 1000
+	minimumFeeRate := mp.minimumRelayFeeRate()
+	minimumFee := (mass * minimumFeeRate) / 1000
 
-	if minimumFee == 0 && mp.config.MinimumRelayTransactionFee > 0 {
-		minimumFee = uint64(mp.config.MinimumRelayTransactionFee)
+	if minimumFee == 0 && minimumFeeRate > 0 {
+		minimumFee = minimumFeeRate
 	}
 
 	// Set the minimum fee to the maximum possible value if the calculated
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
 
 	"github.com/kaspanet/kaspad/util"
 
@@ -13,6 +14,9 @@ import (
 const (
 	defaultMaximumTransactionCount = 1_000_000
 
+	// defaultMaximumMemoryUsage limits the memory the transactions of the transaction pool may occupy together
+	defaultMaximumMemoryUsage = 300_000_000
+
 	defaultTransactionExpireIntervalSeconds     uint64 = 60
 	defaultTransactionExpireScanIntervalSeconds uint64 = 10
 	defaultOrphanExpireIntervalSeconds          uint64 = 60
@@ -23,21 +27,30 @@ const (
 	// removeOrphans when removeRedeemers = true
 	defaultMaximumOrphanTransactionCount = 50
 
+	// DefaultMaximumOrphanTransactionCountPerTag limits the number of orphans relayed by a single peer,
+	// so that a peer can't take the whole orphan pool with many small orphans
+	DefaultMaximumOrphanTransactionCountPerTag = 20
+
+	// defaultMaximumOrphanTransactionBytes limits the memory all orphans may occupy together, and
+	// defaultMaximumOrphanTransactionBytesPerTag limits the memory the orphans relayed by a single
+	// peer may occupy, so that a few peers can't fill the orphan pool with huge transactions.
+	defaultMaximumOrphanTransactionBytes       = 2_000_000
+	defaultMaximumOrphanTransactionBytesPerTag = 400_000
+
 	// defaultMinimumRelayTransactionFee specifies the minimum transaction fee for a transaction to be accepted to
 	// the mempool and relayed. It is specified in sompi per 1kg (or 1000 grams) of transaction mass.
 	defaultMinimumRelayTransactionFee = util.Amount(1000)
 
 	// Standard transaction version range might be different from what consensus accepts, therefore
-	// we define separate values in mempool.
-	// However, currently there's exactly one transaction version, so mempool accepts the same version
-	// as consensus.
+	// we define separate values in mempool. The maximum is taken from the DAG params, so that
+	// a new transaction version becomes standard only once the network chooses to relay it.
 	defaultMinimumStandardTransactionVersion = constants.MaxTransactionVersion
-	defaultMaximumStandardTransactionVersion = constants.MaxTransactionVersion
 )
 
 // Config represents a mempool configuration
 type Config struct {
 	MaximumTransactionCount               uint64
+	MaximumMemoryUsage                    uint64
 	TransactionExpireIntervalDAAScore     uint64
 	TransactionExpireScanIntervalDAAScore uint64
 	TransactionExpireScanIntervalSeconds  uint64
@@ -45,11 +58,33 @@ type Config struct {
 	OrphanExpireScanIntervalDAAScore      uint64
 	MaximumOrphanTransactionMass          uint64
 	MaximumOrphanTransactionCount         uint64
+	MaximumOrphanTransactionCountPerTag   uint64
+	MaximumOrphanTransactionBytes         uint64
+	MaximumOrphanTransactionBytesPerTag   uint64
 	AcceptNonStandard                     bool
 	MaximumMassPerBlock                   uint64
 	MinimumRelayTransactionFee            util.Amount
 	MinimumStandardTransactionVersion     uint16
 	MaximumStandardTransactionVersion     uint16
+
+	// MinedTransactionExpireIntervalDAAScore is the DAA score interval after which a transaction
+	// that was removed from the mempool because a block included it, but that the virtual didn't
+	// accept, is re-admitted. Past the merge depth, the block can no longer be merged.
+	MinedTransactionExpireIntervalDAAScore uint64
+
+	// StandardScriptVerifyFlags are the script flags a transaction must satisfy to be
+	// considered standard, and ConsensusScriptVerifyFlags are the ones consensus enforces
+	// regardless of the DAA score, so that scripts are executed again only when the
+	// former are stricter
+	StandardScriptVerifyFlags  txscript.ScriptFlags
+	ConsensusScriptVerifyFlags txscript.ScriptFlags
+
+	// FreezePolicy, if set, refuses transactions spending frozen outpoints or addresses
+	FreezePolicy *FreezePolicy
+
+	// ValidationCaches, if set, are the signature and script verification caches that the
+	// mempool shares with consensus
+	ValidationCaches *txscript.ValidationCaches
 }
 
 // DefaultConfig returns the default mempool configuration
@@ -57,18 +92,25 @@ func DefaultConfig(dagParams *dagconfig.Params) *Config {
 	targetBlocksPerSecond := time.Second.Seconds() / dagParams.TargetTimePerBlock.Seconds()
 
 	return &Config{
-		MaximumTransactionCount:               defaultMaximumTransactionCount,
-		TransactionExpireIntervalDAAScore:     uint64(float64(defaultTransactionExpireIntervalSeconds) / targetBlocksPerSecond),
-		TransactionExpireScanIntervalDAAScore: uint64(float64(defaultTransactionExpireScanIntervalSeconds) / targetBlocksPerSecond),
-		TransactionExpireScanIntervalSeconds:  defaultTransactionExpireScanIntervalSeconds,
-		OrphanExpireIntervalDAAScore:          uint64(float64(defaultOrphanExpireIntervalSeconds) / targetBlocksPerSecond),
-		OrphanExpireScanIntervalDAAScore:      uint64(float64(defaultOrphanExpireScanIntervalSeconds) / targetBlocksPerSecond),
-		MaximumOrphanTransactionMass:          defaultMaximumOrphanTransactionMass,
-		MaximumOrphanTransactionCount:         defaultMaximumOrphanTransactionCount,
-		AcceptNonStandard:                     dagParams.RelayNonStdTxs,
-		MaximumMassPerBlock:                   dagParams.MaxBlockMass,
-		MinimumRelayTransactionFee:            defaultMinimumRelayTransactionFee,
-		MinimumStandardTransactionVersion:     defaultMinimumStandardTransactionVersion,
-		MaximumStandardTransactionVersion:     defaultMaximumStandardTransactionVersion,
+		MaximumTransactionCount:                defaultMaximumTransactionCount,
+		MaximumMemoryUsage:                     defaultMaximumMemoryUsage,
+		TransactionExpireIntervalDAAScore:      uint64(float64(defaultTransactionExpireIntervalSeconds) / targetBlocksPerSecond),
+		TransactionExpireScanIntervalDAAScore:  uint64(float64(defaultTransactionExpireScanIntervalSeconds) / targetBlocksPerSecond),
+		TransactionExpireScanIntervalSeconds:   defaultTransactionExpireScanIntervalSeconds,
+		OrphanExpireIntervalDAAScore:           uint64(float64(defaultOrphanExpireIntervalSeconds) / targetBlocksPerSecond),
+		OrphanExpireScanIntervalDAAScore:       uint64(float64(defaultOrphanExpireScanIntervalSeconds) / targetBlocksPerSecond),
+		MaximumOrphanTransactionMass:           defaultMaximumOrphanTransactionMass,
+		MaximumOrphanTransactionCount:          defaultMaximumOrphanTransactionCount,
+		MaximumOrphanTransactionCountPerTag:    DefaultMaximumOrphanTransactionCountPerTag,
+		MaximumOrphanTransactionBytes:          defaultMaximumOrphanTransactionBytes,
+		MaximumOrphanTransactionBytesPerTag:    defaultMaximumOrphanTransactionBytesPerTag,
+		AcceptNonStandard:                      dagParams.RelayNonStdTxs,
+		MaximumMassPerBlock:                    dagParams.MaxBlockMass,
+		MinimumRelayTransactionFee:             defaultMinimumRelayTransactionFee,
+		MinimumStandardTransactionVersion:      defaultMinimumStandardTransactionVersion,
+		MaximumStandardTransactionVersion:      dagParams.MaxStandardTransactionVersion,
+		MinedTransactionExpireIntervalDAAScore: dagParams.MergeDepth,
+		StandardScriptVerifyFlags:              txscript.StandardVerifyFlags,
+		ConsensusScriptVerifyFlags:             txscript.ConsensusVerifyFlags(dagParams, 0),
 	}
 }
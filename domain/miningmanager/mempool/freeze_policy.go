@@ -0,0 +1,158 @@
+package mempool
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+)
+
+// auditLog is a separate subsystem so that operators may route and retain
+// the decisions of the freeze policy apart from the rest of the mempool log
+var auditLog = logger.RegisterSubSystem("FRZP")
+
+// FreezePolicy is a relay and mining policy that refuses transactions spending
+// configured outpoints, or outputs paying to configured addresses.
+//
+// It is a local policy only: transactions it refuses are still valid by
+// consensus, and blocks containing them are accepted as usual.
+type FreezePolicy struct {
+	outpoints        map[externalapi.DomainOutpoint]struct{}
+	scriptPublicKeys map[string]util.Address
+}
+
+// NewFreezePolicy returns a FreezePolicy that refuses transactions
+// spending any of the given outpoints or addresses
+func NewFreezePolicy(outpoints []*externalapi.DomainOutpoint, addresses []util.Address) (*FreezePolicy, error) {
+	policy := &FreezePolicy{
+		outpoints:        make(map[externalapi.DomainOutpoint]struct{}, len(outpoints)),
+		scriptPublicKeys: make(map[string]util.Address, len(addresses)),
+	}
+	for _, outpoint := range outpoints {
+		policy.outpoints[*outpoint] = struct{}{}
+	}
+	for _, address := range addresses {
+		scriptPublicKey, err := txscript.PayToAddrScript(address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create the script public key of %s", address)
+		}
+		policy.scriptPublicKeys[scriptPublicKeyKey(scriptPublicKey)] = address
+	}
+	return policy, nil
+}
+
+// LoadFreezePolicy loads a FreezePolicy from the file at the given path.
+// Every line of the file holds either an address or an outpoint in the
+// form <transaction ID>:<index>. Empty lines and lines starting with # are ignored.
+func LoadFreezePolicy(path string, prefix util.Bech32Prefix) (*FreezePolicy, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open the freeze list %s", path)
+	}
+	defer file.Close()
+
+	var outpoints []*externalapi.DomainOutpoint
+	var addresses []util.Address
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if isOutpoint(line) {
+			outpoint, err := parseOutpoint(line)
+			if err != nil {
+				return nil, errors.Wrapf(err, "%s:%d", path, lineNumber)
+			}
+			outpoints = append(outpoints, outpoint)
+			continue
+		}
+
+		address, err := util.DecodeAddress(line, prefix)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s:%d: invalid address %s", path, lineNumber, line)
+		}
+		addresses = append(addresses, address)
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the freeze list %s", path)
+	}
+
+	policy, err := NewFreezePolicy(outpoints, addresses)
+	if err != nil {
+		return nil, err
+	}
+	auditLog.Infof("Loaded a freeze list of %d outpoints and %d addresses from %s",
+		len(outpoints), len(addresses), path)
+	return policy, nil
+}
+
+// isOutpoint returns whether the given freeze list entry is an outpoint rather than an address.
+// Outpoints start with a hex transaction ID while addresses start with their network prefix.
+func isOutpoint(entry string) bool {
+	separatorIndex := strings.Index(entry, ":")
+	return separatorIndex == externalapi.DomainHashSize*2
+}
+
+func parseOutpoint(outpointString string) (*externalapi.DomainOutpoint, error) {
+	parts := strings.Split(outpointString, ":")
+	if len(parts) != 2 {
+		return nil, errors.Errorf("invalid outpoint %s", outpointString)
+	}
+	transactionID, err := externalapi.NewDomainTransactionIDFromString(parts[0])
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid transaction ID in outpoint %s", outpointString)
+	}
+	index, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid index in outpoint %s", outpointString)
+	}
+	return externalapi.NewDomainOutpoint(transactionID, uint32(index)), nil
+}
+
+func scriptPublicKeyKey(scriptPublicKey *externalapi.ScriptPublicKey) string {
+	return fmt.Sprintf("%d:%x", scriptPublicKey.Version, scriptPublicKey.Script)
+}
+
+// checkOutpoints returns an error if the transaction spends a frozen outpoint
+func (fp *FreezePolicy) checkOutpoints(transaction *externalapi.DomainTransaction) error {
+	for _, input := range transaction.Inputs {
+		if _, ok := fp.outpoints[input.PreviousOutpoint]; ok {
+			transactionID := consensushashing.TransactionID(transaction)
+			auditLog.Infof("Refused transaction %s: it spends the frozen outpoint %s",
+				transactionID, input.PreviousOutpoint)
+			return transactionRuleError(RejectFrozen,
+				fmt.Sprintf("transaction %s spends the frozen outpoint %s", transactionID, input.PreviousOutpoint))
+		}
+	}
+	return nil
+}
+
+// checkAddresses returns an error if the transaction spends an output paying
+// to a frozen address. The UTXO entries of the transaction must be filled in.
+func (fp *FreezePolicy) checkAddresses(transaction *externalapi.DomainTransaction) error {
+	if len(fp.scriptPublicKeys) == 0 {
+		return nil
+	}
+	for _, input := range transaction.Inputs {
+		address, ok := fp.scriptPublicKeys[scriptPublicKeyKey(input.UTXOEntry.ScriptPublicKey())]
+		if ok {
+			transactionID := consensushashing.TransactionID(transaction)
+			auditLog.Infof("Refused transaction %s: its input %s spends from the frozen address %s",
+				transactionID, input.PreviousOutpoint, address)
+			return transactionRuleError(RejectFrozen,
+				fmt.Sprintf("transaction %s spends from the frozen address %s", transactionID, address))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package mempool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+)
+
+func TestFreezePolicy(t *testing.T) {
+	const frozenTransactionID = "0102030405060708091011121314151617181920212223242526272829303132"
+	const frozenAddress = "kaspatest:qputx94qseratdmjs0j395mq8u03er0x3l35ennsep3hxfe7ln35ckquw528z"
+
+	path := filepath.Join(t.TempDir(), "freezelist.txt")
+	content := "# Frozen by court order\n" +
+		frozenTransactionID + ":1\n" +
+		"\n" +
+		frozenAddress + "\n"
+	err := os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	policy, err := LoadFreezePolicy(path, util.Bech32PrefixKaspaTest)
+	if err != nil {
+		t.Fatalf("LoadFreezePolicy: %s", err)
+	}
+
+	transactionID, err := externalapi.NewDomainTransactionIDFromString(frozenTransactionID)
+	if err != nil {
+		t.Fatalf("NewDomainTransactionIDFromString: %s", err)
+	}
+	address, err := util.DecodeAddress(frozenAddress, util.Bech32PrefixKaspaTest)
+	if err != nil {
+		t.Fatalf("DecodeAddress: %s", err)
+	}
+	frozenScriptPublicKey, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %s", err)
+	}
+	otherScriptPublicKey := &externalapi.ScriptPublicKey{Script: []byte{txscript.OpTrue}, Version: 0}
+
+	createTransaction := func(index uint32, scriptPublicKey *externalapi.ScriptPublicKey) *externalapi.DomainTransaction {
+		return &externalapi.DomainTransaction{
+			Inputs: []*externalapi.DomainTransactionInput{{
+				PreviousOutpoint: *externalapi.NewDomainOutpoint(transactionID, index),
+				UTXOEntry:        utxo.NewUTXOEntry(1000, scriptPublicKey, false, 0),
+			}},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		transaction      *externalapi.DomainTransaction
+		isOutpointFrozen bool
+		isAddressFrozen  bool
+	}{
+		{
+			name:        "spends neither a frozen outpoint nor a frozen address",
+			transaction: createTransaction(0, otherScriptPublicKey),
+		},
+		{
+			name:             "spends a frozen outpoint",
+			transaction:      createTransaction(1, otherScriptPublicKey),
+			isOutpointFrozen: true,
+		},
+		{
+			name:            "spends from a frozen address",
+			transaction:     createTransaction(2, frozenScriptPublicKey),
+			isAddressFrozen: true,
+		},
+	}
+
+	for _, test := range tests {
+		checkRejectCode := func(err error, isFrozen bool, checkName string) {
+			if !isFrozen {
+				if err != nil {
+					t.Errorf("%s: %s: unexpected error: %s", test.name, checkName, err)
+				}
+				return
+			}
+			txRuleError := TxRuleError{}
+			if !errors.As(err, &txRuleError) || txRuleError.RejectCode != RejectFrozen {
+				t.Errorf("%s: %s: expected a %s error but got: %v", test.name, checkName, RejectFrozen, err)
+			}
+		}
+		checkRejectCode(policy.checkOutpoints(test.transaction), test.isOutpointFrozen, "checkOutpoints")
+		checkRejectCode(policy.checkAddresses(test.transaction), test.isAddressFrozen, "checkAddresses")
+	}
+}
+
+func TestLoadFreezePolicyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "malformed transaction ID", content: "0102:0\n"},
+		{name: "malformed index", content: "0102030405060708091011121314151617181920212223242526272829303132:x\n"},
+		{name: "address of another network", content: "kaspa:qqkqkzjvr7zwxxmjxjkmxxdwju9kjs6e9u82uh59z07vgaks6gg62v8707g73\n"},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "freezelist.txt")
+		err := os.WriteFile(path, []byte(test.content), 0600)
+		if err != nil {
+			t.Fatalf("WriteFile: %s", err)
+		}
+		_, err = LoadFreezePolicy(path, util.Bech32PrefixKaspaTest)
+		if err == nil {
+			t.Errorf("%s: expected an error but got none", test.name)
+		}
+	}
+}
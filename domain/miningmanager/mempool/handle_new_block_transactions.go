@@ -12,9 +12,18 @@ func (mp *mempool) handleNewBlockTransactions(blockTransactions []*externalapi.D
 	// Skip the coinbase transaction
 	blockTransactions = blockTransactions[transactionhelper.CoinbaseTransactionIndex+1:]
 
+	virtualDAAScore, err := mp.consensusReference.Consensus().GetVirtualDAAScore()
+	if err != nil {
+		return nil, err
+	}
+
 	acceptedOrphans := []*externalapi.DomainTransaction{}
 	for _, transaction := range blockTransactions {
 		transactionID := consensushashing.TransactionID(transaction)
+		// The transaction is kept until the virtual accepts it, in case the block never gets it accepted
+		if mempoolTransaction, ok := mp.transactionsPool.allTransactions[*transactionID]; ok {
+			mp.minedTransactions.add(mempoolTransaction, virtualDAAScore)
+		}
 		err := mp.removeTransaction(transactionID, false)
 		if err != nil {
 			return nil, err
@@ -37,7 +46,7 @@ func (mp *mempool) handleNewBlockTransactions(blockTransactions []*externalapi.D
 
 		acceptedOrphans = append(acceptedOrphans, acceptedOrphansFromThisTransaction...)
 	}
-	err := mp.orphansPool.expireOrphanTransactions()
+	err = mp.orphansPool.expireOrphanTransactions()
 	if err != nil {
 		return nil, err
 	}
@@ -5,7 +5,13 @@
 package mempool
 
 import (
+	"time"
+
 	"github.com/kaspanet/kaspad/infrastructure/logger"
 )
 
 var log = logger.RegisterSubSystem("TXMP")
+
+// spamLog logs the rejections of spam transactions, which a peer may relay at a rate
+// that would flood the log
+var spamLog = log.Sampled(10, time.Second)
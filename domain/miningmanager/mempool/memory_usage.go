@@ -0,0 +1,224 @@
+package mempool
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool/model"
+	"github.com/kaspanet/kaspad/util/txmass"
+)
+
+// The following estimate the memory a transaction of the transaction pool occupies on top
+// of its serialized size, the same way orphans are estimated.
+const (
+	// transactionOverhead covers the DomainTransaction and MempoolTransaction structs along
+	// with the entries of the transaction in the maps and the fee rate order of the pool
+	transactionOverhead = 384
+
+	// transactionInputOverhead covers a DomainTransactionInput struct along with its UTXO
+	// entry and the entry of the input in the mempool UTXO set
+	transactionInputOverhead = 192
+
+	// transactionOutputOverhead covers a DomainTransactionOutput struct along with its ScriptPublicKey
+	transactionOutputOverhead = 64
+)
+
+const (
+	// memoryUsageTrimRatio is the part of MaximumMemoryUsage the pool is trimmed down to once
+	// it exceeds it. Leaving some room means the eviction scores, which are calculated for the
+	// whole pool, aren't calculated again for every transaction that's added to a full pool.
+	memoryUsageTrimRatio = 0.95
+
+	// minimumFeeRateHalfLife is the time it takes the minimum fee rate that was set by
+	// evictions to halve, so that it drops back to the configured one once the mempool
+	// is no longer full
+	minimumFeeRateHalfLife = 12 * time.Hour
+)
+
+// transactionMemoryUsage estimates the number of bytes the given transaction
+// occupies in the transaction pool
+func transactionMemoryUsage(transaction *externalapi.DomainTransaction) uint64 {
+	return txmass.TransactionEstimatedSerializedSize(transaction) +
+		transactionOverhead +
+		uint64(len(transaction.Inputs))*transactionInputOverhead +
+		uint64(len(transaction.Outputs))*transactionOutputOverhead
+}
+
+// evictionCandidate is a transaction that may be evicted along with its descendants.
+// Its score is the highest fee rate of the ancestor packages that are lost by evicting
+// it, so that a low fee rate parent of a high fee rate child is evicted only after
+// transactions that are worth less to miners.
+type evictionCandidate struct {
+	transaction *model.MempoolTransaction
+	score       float64
+}
+
+// evictionScore is the score of a transaction, and whether it or any of its
+// descendants is high priority
+type evictionScore struct {
+	score          float64
+	isHighPriority bool
+}
+
+// evictionCandidates returns the transactions that may be evicted, ordered by their
+// ascending score. Transactions that are high priority, or that have high priority
+// descendants, are never evicted.
+func (tp *transactionsPool) evictionCandidates() []*evictionCandidate {
+	scores := tp.evictionScores()
+
+	candidates := make([]*evictionCandidate, 0, len(tp.allTransactions))
+	for transactionID, transaction := range tp.allTransactions {
+		score := scores[transactionID]
+		if score.isHighPriority {
+			continue
+		}
+		candidates = append(candidates, &evictionCandidate{transaction: transaction, score: score.score})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].score < candidates[j].score
+	})
+	return candidates
+}
+
+// evictionScores returns the eviction scores of all the transactions of the pool. The score
+// of a transaction is the highest of its own ancestor package fee rate and the scores of its
+// redeemers, so every transaction is scored once, after its redeemers.
+func (tp *transactionsPool) evictionScores() map[externalapi.DomainTransactionID]*evictionScore {
+	scores := make(map[externalapi.DomainTransactionID]*evictionScore, len(tp.allTransactions))
+	for _, transaction := range tp.allTransactions {
+		stack := []*model.MempoolTransaction{transaction}
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			currentID := *current.TransactionID()
+			if _, ok := scores[currentID]; ok {
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			// Redeemers that were removed from the pool may still be listed under their parents
+			redeemers := make([]*model.MempoolTransaction, 0, len(tp.chainedTransactionsByParentID[currentID]))
+			areRedeemersScored := true
+			for _, redeemer := range tp.chainedTransactionsByParentID[currentID] {
+				redeemerID := *redeemer.TransactionID()
+				if _, ok := tp.allTransactions[redeemerID]; !ok {
+					continue
+				}
+				redeemers = append(redeemers, redeemer)
+				if _, ok := scores[redeemerID]; !ok {
+					stack = append(stack, redeemer)
+					areRedeemersScored = false
+				}
+			}
+			if !areRedeemersScored {
+				continue
+			}
+			stack = stack[:len(stack)-1]
+
+			score := &evictionScore{
+				score:          tp.ancestorPackages[currentID].feeRate(),
+				isHighPriority: current.IsHighPriority(),
+			}
+			for _, redeemer := range redeemers {
+				redeemerScore := scores[*redeemer.TransactionID()]
+				score.score = math.Max(score.score, redeemerScore.score)
+				score.isHighPriority = score.isHighPriority || redeemerScore.isHighPriority
+			}
+			scores[currentID] = score
+		}
+	}
+	return scores
+}
+
+// limitMemoryUsage evicts the transactions with the lowest eviction scores, along with
+// their descendants, while the pool occupies more than MaximumMemoryUsage, and raises
+// the minimum fee rate above the scores of the evicted transactions
+func (mp *mempool) limitMemoryUsage() error {
+	maximumMemoryUsage := mp.config.MaximumMemoryUsage
+	if maximumMemoryUsage == 0 || mp.transactionsPool.totalMemoryUsage <= maximumMemoryUsage {
+		return nil
+	}
+
+	targetMemoryUsage := uint64(float64(maximumMemoryUsage) * memoryUsageTrimRatio)
+	for _, candidate := range mp.transactionsPool.evictionCandidates() {
+		if mp.transactionsPool.totalMemoryUsage <= targetMemoryUsage {
+			break
+		}
+		// The candidate may have already been evicted as a descendant of another one
+		transactionID := candidate.transaction.TransactionID()
+		if _, ok := mp.transactionsPool.allTransactions[*transactionID]; !ok {
+			continue
+		}
+
+		log.Debugf("Evicting transaction %s with a score of %f sompi per gram, because the mempool "+
+			"occupies %d bytes, which exceeds the limit of %d", transactionID, candidate.score,
+			mp.transactionsPool.totalMemoryUsage, maximumMemoryUsage)
+		err := mp.removeTransaction(transactionID, true)
+		if err != nil {
+			return err
+		}
+		mp.raiseMinimumFeeRate(candidate.score)
+	}
+
+	if mp.transactionsPool.totalMemoryUsage > maximumMemoryUsage {
+		log.Warnf("The high priority transactions in the mempool occupy %d bytes, which exceeds the limit of %d",
+			mp.transactionsPool.totalMemoryUsage, maximumMemoryUsage)
+	}
+	return nil
+}
+
+// transactionsStillInPool returns the given accepted transactions that are still in the pool,
+// since the limits of the pool may have evicted some of them right after they were accepted
+func (mp *mempool) transactionsStillInPool(
+	acceptedTransactions []*externalapi.DomainTransaction) []*externalapi.DomainTransaction {
+
+	transactionsInPool := make([]*externalapi.DomainTransaction, 0, len(acceptedTransactions))
+	for _, transaction := range acceptedTransactions {
+		if _, ok := mp.transactionsPool.allTransactions[*consensushashing.TransactionID(transaction)]; ok {
+			transactionsInPool = append(transactionsInPool, transaction)
+		}
+	}
+	return transactionsInPool
+}
+
+// raiseMinimumFeeRate raises the minimum fee rate, if needed, so that transactions have to
+// pay more than the given fee rate of an evicted transaction by the incremental fee rate,
+// which is the configured minimum fee rate
+func (mp *mempool) raiseMinimumFeeRate(evictedFeeRate float64) {
+	now := time.Now()
+	minimumFeeRate := evictedFeeRate + float64(mp.config.MinimumRelayTransactionFee)/1000
+	if currentMinimumFeeRate := mp.decayedMinimumFeeRate(now); currentMinimumFeeRate > minimumFeeRate {
+		minimumFeeRate = currentMinimumFeeRate
+	}
+	mp.evictionMinimumFeeRate = minimumFeeRate
+	mp.evictionMinimumFeeRateTime = now
+}
+
+// decayedMinimumFeeRate returns the minimum fee rate, in sompi per gram, that was set by
+// evictions, after it halves every minimumFeeRateHalfLife until the given time
+func (mp *mempool) decayedMinimumFeeRate(now time.Time) float64 {
+	if mp.evictionMinimumFeeRate == 0 {
+		return 0
+	}
+	halfLives := now.Sub(mp.evictionMinimumFeeRateTime).Seconds() / minimumFeeRateHalfLife.Seconds()
+	return mp.evictionMinimumFeeRate * math.Pow(0.5, halfLives)
+}
+
+// minimumRelayFeeRate returns the minimum fee rate, in sompi per 1000 grams of mass, for a
+// transaction to be accepted to the mempool: the configured one, or the one that was set by
+// evictions if it's higher
+func (mp *mempool) minimumRelayFeeRate() uint64 {
+	minimumFeeRate := uint64(mp.config.MinimumRelayTransactionFee)
+	evictionMinimumFeeRate := mp.decayedMinimumFeeRate(time.Now()) * 1000
+	if evictionMinimumFeeRate > float64(constants.MaxSompi) {
+		return constants.MaxSompi
+	}
+	if uint64(evictionMinimumFeeRate) > minimumFeeRate {
+		minimumFeeRate = uint64(evictionMinimumFeeRate)
+	}
+	return minimumFeeRate
+}
@@ -0,0 +1,187 @@
+package mempool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool/model"
+)
+
+func TestLimitMemoryUsage(t *testing.T) {
+	mp := &mempool{config: &Config{MinimumRelayTransactionFee: 1000}}
+	mp.mempoolUTXOSet = newMempoolUTXOSet(mp)
+	mp.transactionsPool = newTransactionsPool(mp)
+	mp.orphansPool = newOrphansPool(mp)
+
+	addTransaction := func(index uint32, fee uint64, isHighPriority bool,
+		parents ...*model.MempoolTransaction) *model.MempoolTransaction {
+
+		inputs := []*externalapi.DomainTransactionInput{{
+			PreviousOutpoint: externalapi.DomainOutpoint{Index: index},
+		}}
+		parentTransactionsInPool := model.IDToTransactionMap{}
+		for _, parent := range parents {
+			inputs = append(inputs, &externalapi.DomainTransactionInput{
+				PreviousOutpoint: externalapi.DomainOutpoint{TransactionID: *parent.TransactionID()},
+			})
+			parentTransactionsInPool[*parent.TransactionID()] = parent
+		}
+		transaction := &externalapi.DomainTransaction{
+			Inputs:       inputs,
+			Outputs:      []*externalapi.DomainTransactionOutput{{Value: 1, ScriptPublicKey: &externalapi.ScriptPublicKey{}}},
+			SubnetworkID: subnetworks.SubnetworkIDNative,
+			Fee:          fee,
+			Mass:         1000,
+		}
+		mempoolTransaction := model.NewMempoolTransaction(transaction, parentTransactionsInPool, isHighPriority, 0)
+		err := mp.transactionsPool.addMempoolTransaction(mempoolTransaction)
+		if err != nil {
+			t.Fatalf("addMempoolTransaction: %+v", err)
+		}
+		return mempoolTransaction
+	}
+	isInPool := func(transaction *model.MempoolTransaction) bool {
+		_, ok := mp.transactionsPool.allTransactions[*transaction.TransactionID()]
+		return ok
+	}
+
+	lowFeeParent := addTransaction(0, 100, false)
+	highFeeChild := addTransaction(1, 100_000, false, lowFeeParent)
+	lowFee := addTransaction(2, 500, false)
+	highPriority := addTransaction(3, 1, true)
+	highFee := addTransaction(4, 200_000, false)
+
+	var expectedMemoryUsage uint64
+	for _, transaction := range mp.transactionsPool.allTransactions {
+		expectedMemoryUsage += transactionMemoryUsage(transaction.Transaction())
+	}
+	if mp.transactionsPool.totalMemoryUsage != expectedMemoryUsage {
+		t.Fatalf("Expected a memory usage of %d, but got %d", expectedMemoryUsage, mp.transactionsPool.totalMemoryUsage)
+	}
+
+	// Nothing is evicted while the pool is within its limit
+	mp.config.MaximumMemoryUsage = expectedMemoryUsage
+	err := mp.limitMemoryUsage()
+	if err != nil {
+		t.Fatalf("limitMemoryUsage: %+v", err)
+	}
+	if mp.transactionsPool.transactionCount() != 5 || mp.minimumRelayFeeRate() != 1000 {
+		t.Fatalf("Expected no transaction to be evicted from a pool within its limit")
+	}
+
+	// The low fee parent is worth as much as its high fee child to miners, so the
+	// low fee transaction has to be evicted first. Lowering the limit at runtime, as
+	// the memory budget does, evicts right away.
+	err = mp.SetMaximumMemoryUsage(expectedMemoryUsage - 1)
+	if err != nil {
+		t.Fatalf("SetMaximumMemoryUsage: %+v", err)
+	}
+	if isInPool(lowFee) || !isInPool(lowFeeParent) || !isInPool(highFeeChild) || !isInPool(highFee) {
+		t.Fatalf("Expected only the low fee transaction to be evicted")
+	}
+	// 0.5 sompi per gram of the evicted transaction, plus the incremental 1 sompi per gram
+	minimumFeeRate := mp.minimumRelayFeeRate()
+	if minimumFeeRate < 1490 || minimumFeeRate > 1500 {
+		t.Fatalf("Expected a minimum fee rate of about 1500, but got %d", minimumFeeRate)
+	}
+
+	// High priority transactions are never evicted, even if the pool can't be trimmed below the limit
+	mp.config.MaximumMemoryUsage = transactionMemoryUsage(highPriority.Transaction()) - 1
+	err = mp.limitMemoryUsage()
+	if err != nil {
+		t.Fatalf("limitMemoryUsage: %+v", err)
+	}
+	if mp.transactionsPool.transactionCount() != 1 || !isInPool(highPriority) {
+		t.Fatalf("Expected only the high priority transaction to remain in the pool")
+	}
+	if mp.transactionsPool.totalMemoryUsage != transactionMemoryUsage(highPriority.Transaction()) {
+		t.Fatalf("Expected the memory usage of the pool to be updated after evictions")
+	}
+	minimumFeeRate = mp.minimumRelayFeeRate()
+	if minimumFeeRate < 200_900 || minimumFeeRate > 201_000 {
+		t.Fatalf("Expected a minimum fee rate of about 201000, but got %d", minimumFeeRate)
+	}
+
+	// The minimum fee rate halves every minimumFeeRateHalfLife, down to the configured one
+	mp.evictionMinimumFeeRateTime = mp.evictionMinimumFeeRateTime.Add(-minimumFeeRateHalfLife)
+	minimumFeeRate = mp.minimumRelayFeeRate()
+	if minimumFeeRate < 100_400 || minimumFeeRate > 100_500 {
+		t.Fatalf("Expected a minimum fee rate of about 100500 after a half-life, but got %d", minimumFeeRate)
+	}
+	mp.evictionMinimumFeeRateTime = time.Now().Add(-20 * minimumFeeRateHalfLife)
+	if mp.minimumRelayFeeRate() != 1000 {
+		t.Fatalf("Expected the minimum fee rate to decay back to the configured one, but got %d",
+			mp.minimumRelayFeeRate())
+	}
+}
+
+func TestAncestorPackagesAfterRemoval(t *testing.T) {
+	mp := &mempool{config: &Config{MinimumRelayTransactionFee: 1000}}
+	mp.mempoolUTXOSet = newMempoolUTXOSet(mp)
+	mp.transactionsPool = newTransactionsPool(mp)
+	mp.orphansPool = newOrphansPool(mp)
+
+	addTransaction := func(index uint32, fee uint64, parents ...*model.MempoolTransaction) *model.MempoolTransaction {
+		inputs := []*externalapi.DomainTransactionInput{{
+			PreviousOutpoint: externalapi.DomainOutpoint{Index: index},
+		}}
+		parentTransactionsInPool := model.IDToTransactionMap{}
+		for _, parent := range parents {
+			inputs = append(inputs, &externalapi.DomainTransactionInput{
+				PreviousOutpoint: externalapi.DomainOutpoint{TransactionID: *parent.TransactionID()},
+			})
+			parentTransactionsInPool[*parent.TransactionID()] = parent
+		}
+		transaction := &externalapi.DomainTransaction{
+			Inputs:       inputs,
+			Outputs:      []*externalapi.DomainTransactionOutput{{Value: 1, ScriptPublicKey: &externalapi.ScriptPublicKey{}}},
+			SubnetworkID: subnetworks.SubnetworkIDNative,
+			Fee:          fee,
+			Mass:         1000,
+		}
+		mempoolTransaction := model.NewMempoolTransaction(transaction, parentTransactionsInPool, false, 0)
+		err := mp.transactionsPool.addMempoolTransaction(mempoolTransaction)
+		if err != nil {
+			t.Fatalf("addMempoolTransaction: %+v", err)
+		}
+		return mempoolTransaction
+	}
+	expectAncestorPackage := func(transaction *model.MempoolTransaction, fee, mass uint64) {
+		ancestorPackage := mp.transactionsPool.ancestorPackages[*transaction.TransactionID()]
+		if ancestorPackage.fee != fee || ancestorPackage.mass != mass {
+			t.Fatalf("Expected an ancestor package with a fee of %d and a mass of %d, but got %d and %d",
+				fee, mass, ancestorPackage.fee, ancestorPackage.mass)
+		}
+	}
+
+	// The grandchild spends the parent along two paths, but the parent is in its package once
+	parent := addTransaction(0, 100)
+	firstChild := addTransaction(1, 200, parent)
+	secondChild := addTransaction(2, 300, parent)
+	grandchild := addTransaction(3, 400, firstChild, secondChild)
+	expectAncestorPackage(grandchild, 1000, 4000)
+
+	// A parent that's included in a block leaves the packages of its redeemers
+	err := mp.removeTransaction(parent.TransactionID(), false)
+	if err != nil {
+		t.Fatalf("removeTransaction: %+v", err)
+	}
+	expectAncestorPackage(firstChild, 200, 1000)
+	expectAncestorPackage(grandchild, 900, 3000)
+
+	// Transactions that were evicted right after they were accepted aren't reported as accepted
+	err = mp.removeTransaction(secondChild.TransactionID(), true)
+	if err != nil {
+		t.Fatalf("removeTransaction: %+v", err)
+	}
+	if _, ok := mp.transactionsPool.ancestorPackages[*grandchild.TransactionID()]; ok {
+		t.Fatalf("Expected the ancestor package of an evicted transaction to be removed")
+	}
+	accepted := mp.transactionsStillInPool([]*externalapi.DomainTransaction{
+		firstChild.Transaction(), secondChild.Transaction(), grandchild.Transaction()})
+	if len(accepted) != 1 || accepted[0] != firstChild.Transaction() {
+		t.Fatalf("Expected only the transaction that's still in the pool to be accepted, but got %d", len(accepted))
+	}
+}
@@ -2,6 +2,7 @@ package mempool
 
 import (
 	"sync"
+	"time"
 
 	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
@@ -20,9 +21,15 @@ type mempool struct {
 	config             *Config
 	consensusReference consensusreference.ConsensusReference
 
-	mempoolUTXOSet   *mempoolUTXOSet
-	transactionsPool *transactionsPool
-	orphansPool      *orphansPool
+	mempoolUTXOSet    *mempoolUTXOSet
+	transactionsPool  *transactionsPool
+	orphansPool       *orphansPool
+	minedTransactions *minedTransactions
+
+	// evictionMinimumFeeRate is the minimum fee rate, in sompi per gram, that was set when
+	// transactions were last evicted from the full mempool, at evictionMinimumFeeRateTime
+	evictionMinimumFeeRate     float64
+	evictionMinimumFeeRateTime time.Time
 }
 
 // New constructs a new mempool
@@ -35,6 +42,8 @@ func New(config *Config, consensusReference consensusreference.ConsensusReferenc
 	mp.mempoolUTXOSet = newMempoolUTXOSet(mp)
 	mp.transactionsPool = newTransactionsPool(mp)
 	mp.orphansPool = newOrphansPool(mp)
+	mp.minedTransactions = newMinedTransactions(mp)
+	registerMetrics(mp)
 
 	return mp
 }
@@ -45,7 +54,74 @@ func (mp *mempool) ValidateAndInsertTransaction(transaction *externalapi.DomainT
 	mp.mtx.Lock()
 	defer mp.mtx.Unlock()
 
-	return mp.validateAndInsertTransaction(transaction, isHighPriority, allowOrphan)
+	return mp.validateAndInsertTransaction(transaction, isHighPriority, allowOrphan, miningmanagermodel.UntaggedTag)
+}
+
+func (mp *mempool) ValidateAndInsertTaggedTransaction(transaction *externalapi.DomainTransaction, isHighPriority bool,
+	allowOrphan bool, tag miningmanagermodel.Tag) (acceptedTransactions []*externalapi.DomainTransaction, err error) {
+
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	return mp.validateAndInsertTransaction(transaction, isHighPriority, allowOrphan, tag)
+}
+
+// ValidateTransaction runs the same validation as ValidateAndInsertTransaction, without
+// inserting the transaction into the mempool. Orphans are considered invalid.
+func (mp *mempool) ValidateTransaction(transaction *externalapi.DomainTransaction) error {
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	return mp.validateTransaction(transaction, nil, true)
+}
+
+// CheckTransactionForAcceptance runs the validation of ValidateAndInsertTransaction on each of the
+// given transactions, without inserting them into the mempool. A transaction may spend the outputs
+// of the transactions before it, as long as they would be accepted. Orphans are rejected.
+func (mp *mempool) CheckTransactionForAcceptance(transactions []*externalapi.DomainTransaction) (
+	[]*miningmanagermodel.TransactionAcceptanceCheck, error) {
+
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	return mp.checkTransactionsForAcceptance(transactions)
+}
+
+// ValidateAndInsertPackage inserts the transactions of the given package into the mempool, either all
+// of them or none of them. A package is a transaction along with its unconfirmed ancestors, ordered
+// so that every transaction comes after its parents, and it pays the minimum fee as a whole rather
+// than by each of its transactions.
+func (mp *mempool) ValidateAndInsertPackage(transactions []*externalapi.DomainTransaction, isHighPriority bool) (
+	acceptedTransactions []*externalapi.DomainTransaction, err error) {
+
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	return mp.validateAndInsertPackage(transactions, isHighPriority)
+}
+
+// GetRelayPackage returns the transaction with the given ID along with its ancestors in the mempool,
+// as a package for ValidateAndInsertPackage. It returns false if the transaction isn't in the
+// mempool, or if the package would be larger than MaximumPackageTransactions.
+func (mp *mempool) GetRelayPackage(transactionID *externalapi.DomainTransactionID) (
+	[]*externalapi.DomainTransaction, bool) {
+
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	mempoolTransaction, ok := mp.transactionsPool.allTransactions[*transactionID]
+	if !ok {
+		return nil, false
+	}
+	relayPackage, ok := mp.transactionsPool.getRelayPackage(mempoolTransaction)
+	if !ok {
+		return nil, false
+	}
+	transactions := make([]*externalapi.DomainTransaction, len(relayPackage))
+	for i, packageTransaction := range relayPackage {
+		transactions[i] = packageTransaction.Transaction().Clone()
+	}
+	return transactions, true
 }
 
 func (mp *mempool) GetTransaction(transactionID *externalapi.DomainTransactionID,
@@ -73,6 +149,32 @@ func (mp *mempool) GetTransaction(transactionID *externalapi.DomainTransactionID
 	return transaction, isOrphan, transactionfound
 }
 
+func (mp *mempool) SpendingTx(outpoint *externalapi.DomainOutpoint) (
+	transaction *externalapi.DomainTransaction, found bool) {
+
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	spendingTransaction, ok := mp.mempoolUTXOSet.spendingTransaction(outpoint)
+	if !ok {
+		return nil, false
+	}
+	return spendingTransaction.Transaction().Clone(), true
+}
+
+func (mp *mempool) OutputsCreatedBy(transactionID *externalapi.DomainTransactionID) (
+	unspentOutputs []*externalapi.OutpointAndUTXOEntryPair, found bool) {
+
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	mempoolTransaction, ok := mp.transactionsPool.allTransactions[*transactionID]
+	if !ok {
+		return nil, false
+	}
+	return mp.mempoolUTXOSet.unspentOutputsOf(mempoolTransaction), true
+}
+
 func (mp *mempool) GetTransactionsByAddresses(includeTransactionPool bool, includeOrphanPool bool) (
 	sendingInTransactionPool map[string]*externalapi.DomainTransaction,
 	receivingInTransactionPool map[string]*externalapi.DomainTransaction,
@@ -133,6 +235,28 @@ func (mp *mempool) TransactionCount(includeTransactionPool bool, includeOrphanPo
 	return transactionCount
 }
 
+func (mp *mempool) Info() *miningmanagermodel.MempoolInfo {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	return &miningmanagermodel.MempoolInfo{
+		TransactionCount:           uint64(mp.transactionsPool.transactionCount()),
+		MemoryUsage:                mp.transactionsPool.totalMemoryUsage,
+		MaximumMemoryUsage:         mp.config.MaximumMemoryUsage,
+		MinimumFeeRate:             mp.minimumRelayFeeRate(),
+		MinimumRelayTransactionFee: uint64(mp.config.MinimumRelayTransactionFee),
+		Orphans:                    mp.orphansPool.info(),
+	}
+}
+
+func (mp *mempool) SetMaximumMemoryUsage(maximumMemoryUsage uint64) error {
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	mp.config.MaximumMemoryUsage = maximumMemoryUsage
+	return mp.limitMemoryUsage()
+}
+
 func (mp *mempool) HandleNewBlockTransactions(transactions []*externalapi.DomainTransaction) (
 	acceptedOrphans []*externalapi.DomainTransaction, err error) {
 
@@ -142,11 +266,21 @@ func (mp *mempool) HandleNewBlockTransactions(transactions []*externalapi.Domain
 	return mp.handleNewBlockTransactions(transactions)
 }
 
-func (mp *mempool) BlockCandidateTransactions() []*externalapi.DomainTransaction {
+func (mp *mempool) HandleSelectedChainChanges(chainChanges *externalapi.SelectedChainPath) (
+	readmittedTransactions []*externalapi.DomainTransaction, err error) {
+
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	return mp.minedTransactions.handleSelectedChainChanges(chainChanges)
+}
+
+func (mp *mempool) BlockCandidateTransactions() []*miningmanagermodel.BlockCandidateTransaction {
 	mp.mtx.RLock()
 	defer mp.mtx.RUnlock()
 
 	readyTxs := mp.transactionsPool.allReadyTransactions()
+	readyPackages := mp.transactionsPool.readyTransactionPackages()
 	var candidateTxs []*externalapi.DomainTransaction
 	var spamTx *externalapi.DomainTransaction
 	var spamTxNewestUTXODaaScore uint64
@@ -196,7 +330,20 @@ func (mp *mempool) BlockCandidateTransactions() []*externalapi.DomainTransaction
 		candidateTxs = append(candidateTxs, spamTx)
 	}
 
-	return candidateTxs
+	blockCandidateTxs := make([]*miningmanagermodel.BlockCandidateTransaction, len(candidateTxs))
+	for i, tx := range candidateTxs {
+		blockCandidateTxs[i] = &miningmanagermodel.BlockCandidateTransaction{
+			Transaction: tx,
+			PackageFee:  tx.Fee,
+			PackageMass: tx.Mass,
+		}
+		if readyPackage, ok := readyPackages[*consensushashing.TransactionID(tx)]; ok {
+			blockCandidateTxs[i].PackageFee = readyPackage.fee
+			blockCandidateTxs[i].PackageMass = readyPackage.mass
+		}
+	}
+
+	return blockCandidateTxs
 }
 
 func (mp *mempool) RevalidateHighPriorityTransactions() (validTransactions []*externalapi.DomainTransaction, err error) {
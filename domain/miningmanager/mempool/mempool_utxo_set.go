@@ -27,15 +27,11 @@ func newMempoolUTXOSet(mp *mempool) *mempoolUTXOSet {
 }
 
 func (mpus *mempoolUTXOSet) addTransaction(transaction *model.MempoolTransaction) {
-	outpoint := &externalapi.DomainOutpoint{TransactionID: *transaction.TransactionID()}
-
-	for i, input := range transaction.Transaction().Inputs {
-		outpoint.Index = uint32(i)
-
+	for _, input := range transaction.Transaction().Inputs {
 		// Delete the output this input spends, in case it was created by mempool.
 		// If the outpoint doesn't exist in mpus.poolUnspentOutputs - this means
 		// it was created in the DAG (a.k.a. in consensus).
-		delete(mpus.poolUnspentOutputs, *outpoint)
+		delete(mpus.poolUnspentOutputs, input.PreviousOutpoint)
 
 		mpus.transactionByPreviousOutpoint[input.PreviousOutpoint] = transaction
 	}
@@ -80,3 +76,25 @@ func (mpus *mempoolUTXOSet) checkDoubleSpends(transaction *externalapi.DomainTra
 
 	return nil
 }
+
+// spendingTransaction returns the transaction in the transaction pool that spends the given outpoint
+func (mpus *mempoolUTXOSet) spendingTransaction(outpoint *externalapi.DomainOutpoint) (*model.MempoolTransaction, bool) {
+	transaction, ok := mpus.transactionByPreviousOutpoint[*outpoint]
+	return transaction, ok
+}
+
+// unspentOutputsOf returns the outputs of the given transaction in the transaction pool that
+// no other transaction in the transaction pool spends
+func (mpus *mempoolUTXOSet) unspentOutputsOf(transaction *model.MempoolTransaction) []*externalapi.OutpointAndUTXOEntryPair {
+	var unspentOutputs []*externalapi.OutpointAndUTXOEntryPair
+	for i := range transaction.Transaction().Outputs {
+		outpoint := externalapi.DomainOutpoint{TransactionID: *transaction.TransactionID(), Index: uint32(i)}
+		if utxoEntry, ok := mpus.poolUnspentOutputs[outpoint]; ok {
+			unspentOutputs = append(unspentOutputs, &externalapi.OutpointAndUTXOEntryPair{
+				Outpoint:  &outpoint,
+				UTXOEntry: utxoEntry,
+			})
+		}
+	}
+	return unspentOutputs
+}
@@ -0,0 +1,34 @@
+package mempool
+
+import "github.com/kaspanet/kaspad/infrastructure/metrics"
+
+var (
+	transactionsGauge = metrics.NewGauge("kaspad_mempool_transactions",
+		"The number of transactions in the transaction pool of the mempool")
+	orphansGauge = metrics.NewGauge("kaspad_mempool_orphans",
+		"The number of transactions in the orphan pool of the mempool")
+	memoryUsageGauge = metrics.NewGauge("kaspad_mempool_memory_usage_bytes",
+		"The estimated memory usage of the transaction pool of the mempool")
+)
+
+// registerMetrics makes the mempool gauges read their values from the given mempool
+func registerMetrics(mp *mempool) {
+	transactionsGauge.SetFunction(func() float64 {
+		mp.mtx.RLock()
+		defer mp.mtx.RUnlock()
+
+		return float64(mp.transactionsPool.transactionCount())
+	})
+	orphansGauge.SetFunction(func() float64 {
+		mp.mtx.RLock()
+		defer mp.mtx.RUnlock()
+
+		return float64(mp.orphansPool.orphanTransactionCount())
+	})
+	memoryUsageGauge.SetFunction(func() float64 {
+		mp.mtx.RLock()
+		defer mp.mtx.RUnlock()
+
+		return float64(mp.transactionsPool.totalMemoryUsage)
+	})
+}
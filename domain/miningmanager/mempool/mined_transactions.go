@@ -0,0 +1,173 @@
+package mempool
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool/model"
+	miningmanagermodel "github.com/kaspanet/kaspad/domain/miningmanager/model"
+	"github.com/pkg/errors"
+)
+
+// minedTransaction is a transaction that was removed from the transaction pool because a
+// block included it, along with the virtual DAA score at the time
+type minedTransaction struct {
+	transaction     *externalapi.DomainTransaction
+	isHighPriority  bool
+	minedAtDAAScore uint64
+}
+
+// minedTransactions keeps the transactions that were removed from the transaction pool
+// because a block included them, until the virtual accepts them. The transactions of a
+// block that turns red, or that stops being mergeable once it's too deep in the past of
+// the virtual, may never be accepted, in which case they are re-admitted into the mempool
+// instead of silently disappearing.
+type minedTransactions struct {
+	mempool      *mempool
+	transactions map[externalapi.DomainTransactionID]*minedTransaction
+}
+
+func newMinedTransactions(mp *mempool) *minedTransactions {
+	return &minedTransactions{
+		mempool:      mp,
+		transactions: map[externalapi.DomainTransactionID]*minedTransaction{},
+	}
+}
+
+func (mt *minedTransactions) add(mempoolTransaction *model.MempoolTransaction, virtualDAAScore uint64) {
+	mt.transactions[*mempoolTransaction.TransactionID()] = &minedTransaction{
+		transaction:     mempoolTransaction.Transaction(),
+		isHighPriority:  mempoolTransaction.IsHighPriority(),
+		minedAtDAAScore: virtualDAAScore,
+	}
+}
+
+// handleSelectedChainChanges forgets the mined transactions that the new selected chain
+// accepted, and re-admits the transactions that it doesn't accept anymore: the ones its
+// merge sets didn't accept, the ones that were accepted by the chain blocks it replaced,
+// and the mined ones whose blocks can no longer be merged.
+func (mt *minedTransactions) handleSelectedChainChanges(chainChanges *externalapi.SelectedChainPath) (
+	readmittedTransactions []*externalapi.DomainTransaction, err error) {
+
+	consensus := mt.mempool.consensusReference.Consensus()
+	addedAcceptanceData, err := consensus.GetBlocksAcceptanceData(chainChanges.Added)
+	if err != nil {
+		return nil, err
+	}
+	removedAcceptanceData, err := consensus.GetBlocksAcceptanceData(chainChanges.Removed)
+	if err != nil {
+		return nil, err
+	}
+	virtualDAAScore, err := consensus.GetVirtualDAAScore()
+	if err != nil {
+		return nil, err
+	}
+
+	acceptedTransactionIDs := make(map[externalapi.DomainTransactionID]struct{})
+	for _, acceptanceData := range addedAcceptanceData {
+		for _, blockAcceptanceData := range acceptanceData {
+			for _, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+				if !transactionAcceptanceData.IsAccepted {
+					continue
+				}
+				transactionID := consensushashing.TransactionID(transactionAcceptanceData.Transaction)
+				acceptedTransactionIDs[*transactionID] = struct{}{}
+				delete(mt.transactions, *transactionID)
+			}
+		}
+	}
+
+	var candidates []*minedTransaction
+	candidateIDs := make(map[externalapi.DomainTransactionID]struct{})
+	addCandidate := func(transaction *externalapi.DomainTransaction) {
+		if transactionhelper.IsCoinBase(transaction) {
+			return
+		}
+		transactionID := consensushashing.TransactionID(transaction)
+		if _, ok := acceptedTransactionIDs[*transactionID]; ok {
+			return
+		}
+		if _, ok := candidateIDs[*transactionID]; ok {
+			return
+		}
+		candidateIDs[*transactionID] = struct{}{}
+
+		candidate := &minedTransaction{transaction: transaction}
+		if mined, ok := mt.transactions[*transactionID]; ok {
+			candidate.isHighPriority = mined.isHighPriority
+			delete(mt.transactions, *transactionID)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	for _, acceptanceData := range addedAcceptanceData {
+		for _, blockAcceptanceData := range acceptanceData {
+			for _, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+				if !transactionAcceptanceData.IsAccepted {
+					addCandidate(transactionAcceptanceData.Transaction)
+				}
+			}
+		}
+	}
+	for _, acceptanceData := range removedAcceptanceData {
+		for _, blockAcceptanceData := range acceptanceData {
+			for _, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+				if transactionAcceptanceData.IsAccepted {
+					addCandidate(transactionAcceptanceData.Transaction)
+				}
+			}
+		}
+	}
+	for _, mined := range mt.transactions {
+		if virtualDAAScore-mined.minedAtDAAScore > mt.mempool.config.MinedTransactionExpireIntervalDAAScore {
+			addCandidate(mined.transaction)
+		}
+	}
+
+	return mt.readmit(candidates)
+}
+
+// readmit inserts the given transactions into the mempool, skipping the ones that aren't
+// valid against the virtual UTXO set anymore. The candidates aren't ordered, so the ones
+// that spend the outputs of other candidates are retried for as long as more candidates
+// get admitted.
+func (mt *minedTransactions) readmit(candidates []*minedTransaction) (
+	readmittedTransactions []*externalapi.DomainTransaction, err error) {
+
+	for len(candidates) > 0 {
+		var rejected []*minedTransaction
+		for _, candidate := range candidates {
+			transactionID := consensushashing.TransactionID(candidate.transaction)
+			if _, ok := mt.mempool.transactionsPool.getTransaction(transactionID, false); ok {
+				continue
+			}
+			if _, ok := mt.mempool.orphansPool.allOrphans[*transactionID]; ok {
+				continue
+			}
+
+			// The transaction may be shared with the block it was taken from, so its inputs
+			// are populated again on a clone, against the current virtual UTXO set
+			transaction := candidate.transaction.Clone()
+			for _, input := range transaction.Inputs {
+				input.UTXOEntry = nil
+			}
+			acceptedTransactions, err := mt.mempool.validateAndInsertTransaction(transaction,
+				candidate.isHighPriority, false, miningmanagermodel.UntaggedTag)
+			if err != nil {
+				if !errors.As(err, &RuleError{}) {
+					return nil, err
+				}
+				rejected = append(rejected, candidate)
+				continue
+			}
+			log.Debugf("Re-admitted transaction %s, which is no longer accepted by the virtual", transactionID)
+			readmittedTransactions = append(readmittedTransactions, acceptedTransactions...)
+		}
+
+		if len(rejected) == len(candidates) {
+			break
+		}
+		candidates = rejected
+	}
+	return readmittedTransactions, nil
+}
@@ -63,7 +63,9 @@ func (tobf *TransactionsOrderedByFeeRate) RemoveAtIndex(index int) error {
 // If the transaction was not found, will return wasFound=false and index=the index at which transaction can be inserted
 // while preserving the order.
 func (tobf *TransactionsOrderedByFeeRate) findTransactionIndex(transaction *MempoolTransaction) (index int, wasFound bool, err error) {
-	if transaction.Transaction().Fee == 0 || transaction.Transaction().Mass == 0 {
+	// The fee may be zero for a transaction that was accepted as part of a package, whose
+	// descendants pay for it, so only the mass tells whether the transaction was populated
+	if transaction.Transaction().Mass == 0 {
 		return 0, false, errors.Errorf("findTransactionIndex expects a transaction with " +
 			"populated fee and mass")
 	}
@@ -3,6 +3,7 @@ package model
 import (
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	miningmanagermodel "github.com/kaspanet/kaspad/domain/miningmanager/model"
 )
 
 // OrphanTransaction represents a transaction in the OrphanPool
@@ -10,6 +11,8 @@ type OrphanTransaction struct {
 	transaction     *externalapi.DomainTransaction
 	isHighPriority  bool
 	addedAtDAAScore uint64
+	tag             miningmanagermodel.Tag
+	memoryUsage     uint64
 }
 
 // NewOrphanTransaction constructs a new OrphanTransaction
@@ -17,11 +20,15 @@ func NewOrphanTransaction(
 	transaction *externalapi.DomainTransaction,
 	isHighPriority bool,
 	addedAtDAAScore uint64,
+	tag miningmanagermodel.Tag,
+	memoryUsage uint64,
 ) *OrphanTransaction {
 	return &OrphanTransaction{
 		transaction:     transaction,
 		isHighPriority:  isHighPriority,
 		addedAtDAAScore: addedAtDAAScore,
+		tag:             tag,
+		memoryUsage:     memoryUsage,
 	}
 }
 
@@ -44,3 +51,13 @@ func (ot *OrphanTransaction) IsHighPriority() bool {
 func (ot *OrphanTransaction) AddedAtDAAScore() uint64 {
 	return ot.addedAtDAAScore
 }
+
+// Tag returns the tag of the peer that relayed this OrphanTransaction
+func (ot *OrphanTransaction) Tag() miningmanagermodel.Tag {
+	return ot.tag
+}
+
+// MemoryUsage returns the estimated number of bytes this OrphanTransaction occupies in the OrphanPool
+func (ot *OrphanTransaction) MemoryUsage() uint64 {
+	return ot.memoryUsage
+}
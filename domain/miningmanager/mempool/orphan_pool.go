@@ -13,9 +13,27 @@ import (
 
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/miningmanager/mempool/model"
+	miningmanagermodel "github.com/kaspanet/kaspad/domain/miningmanager/model"
+	"github.com/kaspanet/kaspad/util/txmass"
 	"github.com/pkg/errors"
 )
 
+// The following estimate the memory an orphan occupies on top of its serialized size.
+// They don't need to be exact, only to grow with the number of inputs and outputs
+// the way the actual memory usage does.
+const (
+	// orphanOverhead covers the DomainTransaction and OrphanTransaction structs
+	// along with the entry of the orphan in allOrphans
+	orphanOverhead = 256
+
+	// orphanInputOverhead covers a DomainTransactionInput struct along with the
+	// entries of the input in orphansByPreviousOutpoint and orphansByMissingOutpoint
+	orphanInputOverhead = 128
+
+	// orphanOutputOverhead covers a DomainTransactionOutput struct along with its ScriptPublicKey
+	orphanOutputOverhead = 64
+)
+
 type idToOrphanMap map[externalapi.DomainTransactionID]*model.OrphanTransaction
 type previousOutpointToOrphanMap map[externalapi.DomainOutpoint]*model.OrphanTransaction
 
@@ -23,7 +41,20 @@ type orphansPool struct {
 	mempool                   *mempool
 	allOrphans                idToOrphanMap
 	orphansByPreviousOutpoint previousOutpointToOrphanMap
-	lastExpireScan            uint64
+
+	// orphansByMissingOutpoint indexes the orphans by the outpoints they spend that are
+	// neither in the UTXO set nor in the mempool, so that an orphan is found as soon as
+	// its parents arrive
+	orphansByMissingOutpoint previousOutpointToOrphanMap
+	lastExpireScan           uint64
+
+	totalMemoryUsage uint64
+	memoryUsageByTag map[miningmanagermodel.Tag]uint64
+	countByTag       map[miningmanagermodel.Tag]int
+
+	promotedCount uint64
+	evictedCount  uint64
+	expiredCount  uint64
 }
 
 func newOrphansPool(mp *mempool) *orphansPool {
@@ -31,11 +62,15 @@ func newOrphansPool(mp *mempool) *orphansPool {
 		mempool:                   mp,
 		allOrphans:                idToOrphanMap{},
 		orphansByPreviousOutpoint: previousOutpointToOrphanMap{},
+		orphansByMissingOutpoint:  previousOutpointToOrphanMap{},
 		lastExpireScan:            0,
+		memoryUsageByTag:          map[miningmanagermodel.Tag]uint64{},
+		countByTag:                map[miningmanagermodel.Tag]int{},
 	}
 }
 
-func (op *orphansPool) maybeAddOrphan(transaction *externalapi.DomainTransaction, isHighPriority bool) error {
+func (op *orphansPool) maybeAddOrphan(transaction *externalapi.DomainTransaction, isHighPriority bool,
+	tag miningmanagermodel.Tag) error {
 	if op.mempool.config.MaximumOrphanTransactionCount == 0 {
 		return nil
 	}
@@ -49,12 +84,22 @@ func (op *orphansPool) maybeAddOrphan(transaction *externalapi.DomainTransaction
 	if err != nil {
 		return err
 	}
+	memoryUsage := orphanMemoryUsage(transaction)
+	err = op.checkOrphanMemoryUsage(transaction, memoryUsage, tag)
+	if err != nil {
+		return err
+	}
 	err = op.checkOrphanDoubleSpend(transaction)
 	if err != nil {
 		return err
 	}
 
-	err = op.addOrphan(transaction, isHighPriority)
+	orphanTransaction, err := op.addOrphan(transaction, isHighPriority, tag, memoryUsage)
+	if err != nil {
+		return err
+	}
+
+	err = op.limitTagUsage(orphanTransaction)
 	if err != nil {
 		return err
 	}
@@ -67,14 +112,63 @@ func (op *orphansPool) maybeAddOrphan(transaction *externalapi.DomainTransaction
 	return nil
 }
 
+// orphanMemoryUsage estimates the number of bytes the given transaction
+// occupies in the orphan pool, including the indexes that refer to it
+func orphanMemoryUsage(transaction *externalapi.DomainTransaction) uint64 {
+	return txmass.TransactionEstimatedSerializedSize(transaction) +
+		orphanOverhead +
+		uint64(len(transaction.Inputs))*orphanInputOverhead +
+		uint64(len(transaction.Outputs))*orphanOutputOverhead
+}
+
+// limitTagUsage evicts orphans of the same tag as the given newly added orphan until
+// they're within MaximumOrphanTransactionCountPerTag, and the memory they occupy together
+// is within MaximumOrphanTransactionBytesPerTag. This way, a peer that relays many orphans
+// mostly evicts its own.
+func (op *orphansPool) limitTagUsage(newOrphan *model.OrphanTransaction) error {
+	tag := newOrphan.Tag()
+	if tag == miningmanagermodel.UntaggedTag {
+		return nil
+	}
+
+	for uint64(op.countByTag[tag]) > op.mempool.config.MaximumOrphanTransactionCountPerTag ||
+		op.memoryUsageByTag[tag] > op.mempool.config.MaximumOrphanTransactionBytesPerTag {
+
+		var orphanToRemove *model.OrphanTransaction
+		for _, orphan := range op.allOrphans {
+			if orphan.Tag() == tag && !orphan.IsHighPriority() && orphan != newOrphan {
+				orphanToRemove = orphan
+				break
+			}
+		}
+		if orphanToRemove == nil {
+			break
+		}
+
+		log.Debugf("Evicting orphan %s since the %d orphans of %s occupy %d bytes, more than the maximum "+
+			"of %d orphans and %d bytes", orphanToRemove.TransactionID(), op.countByTag[tag], tag,
+			op.memoryUsageByTag[tag], op.mempool.config.MaximumOrphanTransactionCountPerTag,
+			op.mempool.config.MaximumOrphanTransactionBytesPerTag)
+		err := op.removeOrphan(orphanToRemove.TransactionID(), false)
+		if err != nil {
+			return err
+		}
+		op.evictedCount++
+	}
+	return nil
+}
+
 func (op *orphansPool) limitOrphanPoolSize() error {
-	for uint64(len(op.allOrphans)) > op.mempool.config.MaximumOrphanTransactionCount {
+	for uint64(len(op.allOrphans)) > op.mempool.config.MaximumOrphanTransactionCount ||
+		op.totalMemoryUsage > op.mempool.config.MaximumOrphanTransactionBytes {
+
 		orphanToRemove := op.randomNonHighPriorityOrphan()
 		if orphanToRemove == nil { // this means all orphans are HighPriority
 			log.Warnf(
-				"Number of high-priority transactions in orphanPool (%d) is higher than maximum allowed (%d)",
-				len(op.allOrphans),
-				op.mempool.config.MaximumOrphanTransactionCount)
+				"High-priority transactions in orphanPool (%d transactions, %d bytes) exceed the maximum allowed "+
+					"(%d transactions, %d bytes)",
+				len(op.allOrphans), op.totalMemoryUsage,
+				op.mempool.config.MaximumOrphanTransactionCount, op.mempool.config.MaximumOrphanTransactionBytes)
 			break
 		}
 
@@ -84,6 +178,7 @@ func (op *orphansPool) limitOrphanPoolSize() error {
 		if err != nil {
 			return err
 		}
+		op.evictedCount++
 	}
 	return nil
 }
@@ -98,6 +193,21 @@ func (op *orphansPool) checkOrphanMass(transaction *externalapi.DomainTransactio
 	return nil
 }
 
+func (op *orphansPool) checkOrphanMemoryUsage(transaction *externalapi.DomainTransaction, memoryUsage uint64,
+	tag miningmanagermodel.Tag) error {
+
+	maximumMemoryUsage := op.mempool.config.MaximumOrphanTransactionBytes
+	if tag != miningmanagermodel.UntaggedTag && op.mempool.config.MaximumOrphanTransactionBytesPerTag < maximumMemoryUsage {
+		maximumMemoryUsage = op.mempool.config.MaximumOrphanTransactionBytesPerTag
+	}
+	if memoryUsage > maximumMemoryUsage {
+		str := fmt.Sprintf("orphan transaction %s occupies %d bytes, more than the maximum of %d bytes",
+			consensushashing.TransactionID(transaction), memoryUsage, maximumMemoryUsage)
+		return transactionRuleError(RejectBadOrphan, str)
+	}
+	return nil
+}
+
 func (op *orphansPool) checkOrphanDuplicate(transaction *externalapi.DomainTransaction) error {
 	if _, ok := op.allOrphans[*consensushashing.TransactionID(transaction)]; ok {
 		str := fmt.Sprintf("Orphan transacion %s is already in the orphan pool",
@@ -119,21 +229,73 @@ func (op *orphansPool) checkOrphanDoubleSpend(transaction *externalapi.DomainTra
 	return nil
 }
 
-func (op *orphansPool) addOrphan(transaction *externalapi.DomainTransaction, isHighPriority bool) error {
+func (op *orphansPool) addOrphan(transaction *externalapi.DomainTransaction, isHighPriority bool,
+	tag miningmanagermodel.Tag, memoryUsage uint64) (*model.OrphanTransaction, error) {
+
 	virtualDAAScore, err := op.mempool.consensusReference.Consensus().GetVirtualDAAScore()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	orphanTransaction := model.NewOrphanTransaction(transaction, isHighPriority, virtualDAAScore)
+	orphanTransaction := model.NewOrphanTransaction(transaction, isHighPriority, virtualDAAScore, tag, memoryUsage)
+	op.insertOrphan(orphanTransaction)
+
+	return orphanTransaction, nil
+}
 
+// insertOrphan indexes the given orphan in the orphan pool
+func (op *orphansPool) insertOrphan(orphanTransaction *model.OrphanTransaction) {
 	op.allOrphans[*orphanTransaction.TransactionID()] = orphanTransaction
-	for _, input := range transaction.Inputs {
+	for _, input := range orphanTransaction.Transaction().Inputs {
 		op.orphansByPreviousOutpoint[input.PreviousOutpoint] = orphanTransaction
+		// The inputs whose outpoints were found in the UTXO set or the mempool were filled
+		if input.UTXOEntry == nil {
+			op.orphansByMissingOutpoint[input.PreviousOutpoint] = orphanTransaction
+		}
 	}
+	tag := orphanTransaction.Tag()
+	op.totalMemoryUsage += orphanTransaction.MemoryUsage()
+	op.memoryUsageByTag[tag] += orphanTransaction.MemoryUsage()
+	op.countByTag[tag]++
+}
 
-	return nil
+// orphansDependingOn returns the orphans that are among the given transactions, and the
+// orphans that spend the outputs of any of them, directly or through other orphans
+func (op *orphansPool) orphansDependingOn(transactions []*externalapi.DomainTransaction) []*model.OrphanTransaction {
+	var orphans []*model.OrphanTransaction
+	isCollected := make(map[externalapi.DomainTransactionID]bool)
+	queue := make([]*externalapi.DomainTransaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		transactionID := consensushashing.TransactionID(transaction)
+		if orphan, ok := op.allOrphans[*transactionID]; ok {
+			orphans = append(orphans, orphan)
+		}
+		isCollected[*transactionID] = true
+		queue = append(queue, transaction)
+	}
+
+	for len(queue) > 0 {
+		var current *externalapi.DomainTransaction
+		current, queue = queue[0], queue[1:]
+
+		outpoint := externalapi.DomainOutpoint{TransactionID: *consensushashing.TransactionID(current)}
+		for i := range current.Outputs {
+			outpoint.Index = uint32(i)
+			orphan, ok := op.orphansByPreviousOutpoint[outpoint]
+			if !ok || isCollected[*orphan.TransactionID()] {
+				continue
+			}
+			isCollected[*orphan.TransactionID()] = true
+			orphans = append(orphans, orphan)
+			queue = append(queue, orphan.Transaction())
+		}
+	}
+	return orphans
 }
 
+// processOrphansAfterAcceptedTransaction fills the inputs of the orphans that spend the
+// outputs of the given transaction, which was either accepted to the mempool or included
+// in a block, and moves the orphans that are left with no missing outpoints to the
+// mempool. The orphans that spend the outputs of moved orphans are processed the same way.
 func (op *orphansPool) processOrphansAfterAcceptedTransaction(acceptedTransaction *externalapi.DomainTransaction) (
 	acceptedOrphans []*externalapi.DomainTransaction, err error) {
 
@@ -144,33 +306,37 @@ func (op *orphansPool) processOrphansAfterAcceptedTransaction(acceptedTransactio
 		var current *externalapi.DomainTransaction
 		current, queue = queue[0], queue[1:]
 
-		currentTransactionID := consensushashing.TransactionID(current)
-		outpoint := externalapi.DomainOutpoint{TransactionID: *currentTransactionID}
+		outpoint := externalapi.DomainOutpoint{TransactionID: *consensushashing.TransactionID(current)}
 		for i, output := range current.Outputs {
 			outpoint.Index = uint32(i)
-			orphan, ok := op.orphansByPreviousOutpoint[outpoint]
+			orphan, ok := op.orphansByMissingOutpoint[outpoint]
 			if !ok {
 				continue
 			}
+			delete(op.orphansByMissingOutpoint, outpoint)
 			for _, input := range orphan.Transaction().Inputs {
-				if input.PreviousOutpoint.Equal(&outpoint) && input.UTXOEntry == nil {
+				if input.PreviousOutpoint.Equal(&outpoint) {
 					input.UTXOEntry = utxo.NewUTXOEntry(output.Value, output.ScriptPublicKey, false,
 						constants.UnacceptedDAAScore)
 					break
 				}
 			}
-			if countUnfilledInputs(orphan) == 0 {
-				err := op.unorphanTransaction(orphan)
-				if err != nil {
-					if errors.As(err, &RuleError{}) {
-						log.Infof("Failed to unorphan transaction %s due to rule error: %s",
-							currentTransactionID, err)
-						continue
-					}
-					return nil, err
+			if countUnfilledInputs(orphan) > 0 {
+				continue
+			}
+
+			err := op.unorphanTransaction(orphan)
+			if err != nil {
+				if errors.As(err, &RuleError{}) {
+					log.Infof("Failed to unorphan transaction %s due to rule error: %s",
+						orphan.TransactionID(), err)
+					continue
 				}
-				acceptedOrphans = append(acceptedOrphans, orphan.Transaction().Clone()) //these pointers leave the mempool, hence the clone
+				return nil, err
 			}
+			op.promotedCount++
+			acceptedOrphans = append(acceptedOrphans, orphan.Transaction().Clone()) //these pointers leave the mempool, hence the clone
+			queue = append(queue, orphan.Transaction())
 		}
 	}
 
@@ -204,7 +370,7 @@ func (op *orphansPool) unorphanTransaction(transaction *model.OrphanTransaction)
 		return err
 	}
 
-	err = op.mempool.validateTransactionInContext(transaction.Transaction())
+	err = op.mempool.validateTransactionInContext(transaction.Transaction(), true)
 	if err != nil {
 		return err
 	}
@@ -216,7 +382,7 @@ func (op *orphansPool) unorphanTransaction(transaction *model.OrphanTransaction)
 	mempoolTransaction := model.NewMempoolTransaction(
 		transaction.Transaction(),
 		op.mempool.transactionsPool.getParentTransactionsInPool(transaction.Transaction()),
-		false,
+		transaction.IsHighPriority(),
 		virtualDAAScore,
 	)
 	err = op.mempool.transactionsPool.addMempoolTransaction(mempoolTransaction)
@@ -234,6 +400,14 @@ func (op *orphansPool) removeOrphan(orphanTransactionID *externalapi.DomainTrans
 	}
 
 	delete(op.allOrphans, *orphanTransactionID)
+	tag := orphanTransaction.Tag()
+	op.totalMemoryUsage -= orphanTransaction.MemoryUsage()
+	op.memoryUsageByTag[tag] -= orphanTransaction.MemoryUsage()
+	op.countByTag[tag]--
+	if op.countByTag[tag] == 0 {
+		delete(op.memoryUsageByTag, tag)
+		delete(op.countByTag, tag)
+	}
 
 	for i, input := range orphanTransaction.Transaction().Inputs {
 		if _, ok := op.orphansByPreviousOutpoint[input.PreviousOutpoint]; !ok {
@@ -241,6 +415,9 @@ func (op *orphansPool) removeOrphan(orphanTransactionID *externalapi.DomainTrans
 				i, orphanTransactionID, input.PreviousOutpoint)
 		}
 		delete(op.orphansByPreviousOutpoint, input.PreviousOutpoint)
+		if op.orphansByMissingOutpoint[input.PreviousOutpoint] == orphanTransaction {
+			delete(op.orphansByMissingOutpoint, input.PreviousOutpoint)
+		}
 	}
 
 	if removeRedeemers {
@@ -290,6 +467,7 @@ func (op *orphansPool) expireOrphanTransactions() error {
 			if err != nil {
 				return err
 			}
+			op.expiredCount++
 		}
 	}
 
@@ -304,6 +482,7 @@ func (op *orphansPool) updateOrphansAfterTransactionRemoved(
 		return op.removeRedeemersOf(removedTransaction)
 	}
 
+	// The outpoints the orphans spent from the removed transaction are missing again
 	outpoint := externalapi.DomainOutpoint{TransactionID: *removedTransaction.TransactionID()}
 	for i := range removedTransaction.Transaction().Outputs {
 		outpoint.Index = uint32(i)
@@ -311,6 +490,7 @@ func (op *orphansPool) updateOrphansAfterTransactionRemoved(
 			for _, input := range orphan.Transaction().Inputs {
 				if input.PreviousOutpoint.TransactionID.Equal(removedTransaction.TransactionID()) {
 					input.UTXOEntry = nil
+					op.orphansByMissingOutpoint[input.PreviousOutpoint] = orphan
 				}
 			}
 		}
@@ -373,3 +553,21 @@ func (op *orphansPool) getAllOrphanTransactions() []*externalapi.DomainTransacti
 func (op *orphansPool) orphanTransactionCount() int {
 	return len(op.allOrphans)
 }
+
+func (op *orphansPool) info() *miningmanagermodel.OrphanPoolInfo {
+	peerCount := len(op.countByTag)
+	if _, ok := op.countByTag[miningmanagermodel.UntaggedTag]; ok {
+		peerCount--
+	}
+	return &miningmanagermodel.OrphanPoolInfo{
+		Count:                uint64(len(op.allOrphans)),
+		MemoryUsage:          op.totalMemoryUsage,
+		MaximumCount:         op.mempool.config.MaximumOrphanTransactionCount,
+		MaximumMemoryUsage:   op.mempool.config.MaximumOrphanTransactionBytes,
+		MissingOutpointCount: uint64(len(op.orphansByMissingOutpoint)),
+		PeerCount:            uint64(peerCount),
+		PromotedCount:        op.promotedCount,
+		EvictedCount:         op.evictedCount,
+		ExpiredCount:         op.expiredCount,
+	}
+}
@@ -0,0 +1,149 @@
+package mempool
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/kaspanet/kaspad/domain/consensusreference"
+	miningmanagermodel "github.com/kaspanet/kaspad/domain/miningmanager/model"
+	"github.com/pkg/errors"
+)
+
+func TestOrphanPoolLimits(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestOrphanPoolLimits")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		// Every orphan spends the output of a different unknown parent, and they're all of the same size
+		createOrphan := func(i int) *externalapi.DomainTransaction {
+			scriptPublicKey, _ := testutils.OpTrueScript()
+			parent := &externalapi.DomainTransaction{
+				Version: constants.MaxTransactionVersion,
+				Outputs: []*externalapi.DomainTransactionOutput{{Value: constants.SompiPerKaspa, ScriptPublicKey: scriptPublicKey}},
+				Payload: []byte{byte(i)},
+			}
+			orphan, err := testutils.CreateTransaction(parent, 1000)
+			if err != nil {
+				t.Fatalf("CreateTransaction: %+v", err)
+			}
+			return orphan
+		}
+		orphanSize := orphanMemoryUsage(createOrphan(0))
+
+		mempoolConfig := DefaultConfig(tc.DAGParams())
+		mempoolConfig.MaximumOrphanTransactionCount = 100
+		mempoolConfig.MaximumOrphanTransactionCountPerTag = 100
+		mempoolConfig.MaximumOrphanTransactionBytesPerTag = 2 * orphanSize
+		mempoolConfig.MaximumOrphanTransactionBytes = 3 * orphanSize
+		tcAsConsensus := tc.(externalapi.Consensus)
+		tcAsConsensusPointer := &tcAsConsensus
+		mempool := New(mempoolConfig, consensusreference.NewConsensusReference(&tcAsConsensusPointer)).(*mempool)
+		orphansPool := mempool.orphansPool
+
+		countOrphansOfTag := func(tag miningmanagermodel.Tag) int {
+			count := 0
+			for _, orphan := range orphansPool.allOrphans {
+				if orphan.Tag() == tag {
+					count++
+				}
+			}
+			return count
+		}
+
+		// The orphans of a single tag are limited to MaximumOrphanTransactionBytesPerTag,
+		// and the newest orphan is never the one evicted
+		const tagA, tagB = miningmanagermodel.Tag("a"), miningmanagermodel.Tag("b")
+		var lastOrphan *externalapi.DomainTransaction
+		for i := 0; i < 3; i++ {
+			lastOrphan = createOrphan(i)
+			err := orphansPool.maybeAddOrphan(lastOrphan, false, tagA)
+			if err != nil {
+				t.Fatalf("maybeAddOrphan: %+v", err)
+			}
+		}
+		if countOrphansOfTag(tagA) != 2 {
+			t.Fatalf("Expected 2 orphans of tag %s but got %d", tagA, countOrphansOfTag(tagA))
+		}
+		if orphansPool.memoryUsageByTag[tagA] != 2*orphanSize {
+			t.Fatalf("Expected the orphans of tag %s to occupy %d bytes but got %d",
+				tagA, 2*orphanSize, orphansPool.memoryUsageByTag[tagA])
+		}
+		if _, ok := orphansPool.getOrphanTransaction(consensushashing.TransactionID(lastOrphan)); !ok {
+			t.Fatalf("Expected the newest orphan not to be evicted")
+		}
+
+		// All the orphans together are limited to MaximumOrphanTransactionBytes
+		for i := 3; i < 5; i++ {
+			err := orphansPool.maybeAddOrphan(createOrphan(i), false, tagB)
+			if err != nil {
+				t.Fatalf("maybeAddOrphan: %+v", err)
+			}
+		}
+		if orphansPool.totalMemoryUsage > mempoolConfig.MaximumOrphanTransactionBytes {
+			t.Fatalf("Expected the orphan pool to occupy at most %d bytes but got %d",
+				mempoolConfig.MaximumOrphanTransactionBytes, orphansPool.totalMemoryUsage)
+		}
+		if len(orphansPool.allOrphans) != 3 {
+			t.Fatalf("Expected 3 orphans but got %d", len(orphansPool.allOrphans))
+		}
+
+		// An orphan that exceeds the limit of its tag by itself is rejected
+		mempoolConfig.MaximumOrphanTransactionBytesPerTag = orphanSize - 1
+		err = orphansPool.maybeAddOrphan(createOrphan(5), false, tagA)
+		txRuleError := TxRuleError{}
+		if !errors.As(err, &txRuleError) || txRuleError.RejectCode != RejectBadOrphan {
+			t.Fatalf("Expected a %s error but got: %v", RejectBadOrphan, err)
+		}
+
+		// Removing the orphans releases all the memory they occupied
+		for _, orphan := range orphansPool.allOrphans {
+			err := orphansPool.removeOrphan(orphan.TransactionID(), false)
+			if err != nil {
+				t.Fatalf("removeOrphan: %+v", err)
+			}
+		}
+		if orphansPool.totalMemoryUsage != 0 || len(orphansPool.memoryUsageByTag) != 0 {
+			t.Fatalf("Expected the empty orphan pool to occupy no memory, but it occupies %d bytes: %v",
+				orphansPool.totalMemoryUsage, orphansPool.memoryUsageByTag)
+		}
+		if len(orphansPool.countByTag) != 0 || len(orphansPool.orphansByMissingOutpoint) != 0 {
+			t.Fatalf("Expected the empty orphan pool to have no tags and no missing outpoints, "+
+				"but it has %v and %d missing outpoints", orphansPool.countByTag, len(orphansPool.orphansByMissingOutpoint))
+		}
+
+		// The orphans of a single tag are limited to MaximumOrphanTransactionCountPerTag as well
+		mempoolConfig.MaximumOrphanTransactionBytesPerTag = 100 * orphanSize
+		mempoolConfig.MaximumOrphanTransactionBytes = 100 * orphanSize
+		mempoolConfig.MaximumOrphanTransactionCountPerTag = 2
+		for i := 6; i < 10; i++ {
+			lastOrphan = createOrphan(i)
+			err := orphansPool.maybeAddOrphan(lastOrphan, false, tagA)
+			if err != nil {
+				t.Fatalf("maybeAddOrphan: %+v", err)
+			}
+		}
+		if countOrphansOfTag(tagA) != 2 || orphansPool.countByTag[tagA] != 2 {
+			t.Fatalf("Expected 2 orphans of tag %s but got %d", tagA, countOrphansOfTag(tagA))
+		}
+		if _, ok := orphansPool.getOrphanTransaction(consensushashing.TransactionID(lastOrphan)); !ok {
+			t.Fatalf("Expected the newest orphan not to be evicted")
+		}
+		info := orphansPool.info()
+		if info.Count != 2 || info.PeerCount != 1 || info.MissingOutpointCount != 2 {
+			t.Fatalf("Expected 2 orphans of a single peer that miss 2 outpoints, but got %d orphans "+
+				"of %d peers that miss %d outpoints", info.Count, info.PeerCount, info.MissingOutpointCount)
+		}
+		// 2 orphans were evicted earlier, and 2 now
+		if info.EvictedCount != 4 {
+			t.Fatalf("Expected 4 evicted orphans but got %d", info.EvictedCount)
+		}
+	})
+}
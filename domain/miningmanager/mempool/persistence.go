@@ -0,0 +1,195 @@
+package mempool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+
+	"github.com/kaspanet/kaspad/domain/consensus/database/serialization"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool/model"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// mempoolFileVersion is the version of the format of the file the mempool is saved to.
+// The file starts with the version as a 4 byte little-endian integer, followed by the
+// transactions. Every transaction consists of a flags byte, the 4 byte little-endian
+// length of the transaction, and the transaction serialized as it's serialized in the
+// database.
+const mempoolFileVersion = 1
+
+const (
+	savedTransactionFlagHighPriority = 1 << iota
+)
+
+// maxSavedTransactionSize protects against allocating huge buffers for a corrupted file
+const maxSavedTransactionSize = 10_000_000
+
+type savedTransaction struct {
+	transaction    *externalapi.DomainTransaction
+	isHighPriority bool
+}
+
+// SaveToFile saves the transactions of the transaction pool to the file at the given path,
+// so that they can be loaded after a restart. Orphans aren't saved. The file is replaced
+// only once it's completely written. It returns the number of saved transactions.
+func (mp *mempool) SaveToFile(path string) (int, error) {
+	mp.mtx.RLock()
+	transactions := mp.transactionsPool.transactionsOrderedByParents()
+	serializedTransactions := make([]byte, 4, 4+len(transactions)*512)
+	binary.LittleEndian.PutUint32(serializedTransactions, mempoolFileVersion)
+	for _, transaction := range transactions {
+		serializedTransaction, err := proto.Marshal(serialization.DomainTransactionToDbTransaction(transaction.Transaction()))
+		if err != nil {
+			mp.mtx.RUnlock()
+			return 0, err
+		}
+		var flags byte
+		if transaction.IsHighPriority() {
+			flags |= savedTransactionFlagHighPriority
+		}
+		serializedTransactions = append(serializedTransactions, flags)
+		serializedTransactions = binary.LittleEndian.AppendUint32(serializedTransactions, uint32(len(serializedTransaction)))
+		serializedTransactions = append(serializedTransactions, serializedTransaction...)
+	}
+	mp.mtx.RUnlock()
+
+	temporaryPath := path + ".new"
+	file, err := os.OpenFile(temporaryPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, err
+	}
+	_, err = file.Write(serializedTransactions)
+	if err != nil {
+		file.Close()
+		return 0, err
+	}
+	err = file.Sync()
+	if err != nil {
+		file.Close()
+		return 0, err
+	}
+	err = file.Close()
+	if err != nil {
+		return 0, err
+	}
+	err = os.Rename(temporaryPath, path)
+	if err != nil {
+		return 0, err
+	}
+	return len(transactions), nil
+}
+
+// LoadFromFile validates the transactions that were saved to the file at the given path,
+// and inserts the valid ones into the mempool. Transactions that became invalid while the
+// node was down, such as ones that were included in blocks, are dropped. It returns the
+// number of accepted and rejected transactions. A missing file isn't an error.
+func (mp *mempool) LoadFromFile(path string) (acceptedCount int, rejectedCount int, err error) {
+	transactions, err := readMempoolFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, 0, nil
+		}
+		return 0, 0, err
+	}
+
+	for _, transaction := range transactions {
+		// Parents are saved before their children, so a transaction whose parent was
+		// rejected would be an orphan, which is rejected as well
+		_, err := mp.ValidateAndInsertTransaction(transaction.transaction, transaction.isHighPriority, false)
+		if err != nil {
+			if !errors.As(err, &RuleError{}) {
+				return acceptedCount, rejectedCount, err
+			}
+			log.Debugf("Dropping the saved transaction %s: %s",
+				consensushashing.TransactionID(transaction.transaction), err)
+			rejectedCount++
+			continue
+		}
+		acceptedCount++
+	}
+	return acceptedCount, rejectedCount, nil
+}
+
+func readMempoolFile(path string) ([]*savedTransaction, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+
+	var version uint32
+	err = binary.Read(reader, binary.LittleEndian, &version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading the version of the mempool file %s", path)
+	}
+	if version != mempoolFileVersion {
+		return nil, errors.Errorf("the mempool file %s has an unknown version %d", path, version)
+	}
+
+	var transactions []*savedTransaction
+	for {
+		flags, err := reader.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return transactions, nil
+			}
+			return nil, err
+		}
+		var length uint32
+		err = binary.Read(reader, binary.LittleEndian, &length)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading the mempool file %s", path)
+		}
+		if length > maxSavedTransactionSize {
+			return nil, errors.Errorf("the mempool file %s holds a transaction of %d bytes, which is larger "+
+				"than the maximum of %d", path, length, maxSavedTransactionSize)
+		}
+		serializedTransaction := make([]byte, length)
+		_, err = io.ReadFull(reader, serializedTransaction)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading the mempool file %s", path)
+		}
+
+		dbTransaction := &serialization.DbTransaction{}
+		err = proto.Unmarshal(serializedTransaction, dbTransaction)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error deserializing a transaction of the mempool file %s", path)
+		}
+		transaction, err := serialization.DbTransactionToDomainTransaction(dbTransaction)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error deserializing a transaction of the mempool file %s", path)
+		}
+		transactions = append(transactions, &savedTransaction{
+			transaction:    transaction,
+			isHighPriority: flags&savedTransactionFlagHighPriority != 0,
+		})
+	}
+}
+
+// transactionsOrderedByParents returns all the transactions of the pool, ordered so that
+// every transaction comes after its parents in the pool
+func (tp *transactionsPool) transactionsOrderedByParents() []*model.MempoolTransaction {
+	ordered := make([]*model.MempoolTransaction, 0, len(tp.allTransactions))
+	visited := make(map[externalapi.DomainTransactionID]struct{}, len(tp.allTransactions))
+	var visit func(transaction *model.MempoolTransaction)
+	visit = func(transaction *model.MempoolTransaction) {
+		transactionID := *transaction.TransactionID()
+		if _, ok := visited[transactionID]; ok {
+			return
+		}
+		visited[transactionID] = struct{}{}
+		for _, parent := range transaction.ParentTransactionsInPool() {
+			visit(parent)
+		}
+		ordered = append(ordered, transaction)
+	}
+	for _, transaction := range tp.allTransactions {
+		visit(transaction)
+	}
+	return ordered
+}
@@ -23,6 +23,7 @@ func (mp *mempool) removeTransaction(transactionID *externalapi.DomainTransactio
 		for _, redeemer := range redeemers {
 			redeemer.RemoveParentTransactionInPool(transactionID)
 		}
+		mp.transactionsPool.removeFromAncestorPackages(mempoolTransaction, redeemers)
 	}
 
 	for _, transactionToRemove := range transactionsToRemove {
@@ -0,0 +1,139 @@
+package mempool
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool/model"
+)
+
+// transactionPackage is the total fee and mass of a transaction together with all its in-pool ancestors
+type transactionPackage struct {
+	fee  uint64
+	mass uint64
+}
+
+func (tp *transactionPackage) feeRate() float64 {
+	return float64(tp.fee) / float64(tp.mass)
+}
+
+// getAncestorPackage returns the ancestor package of the given transaction - that is, the transaction
+// itself along with every in-pool transaction it directly or indirectly spends from
+func (tp *transactionsPool) getAncestorPackage(transaction *model.MempoolTransaction) (
+	ancestors model.IDToTransactionMap, ancestorPackage *transactionPackage) {
+
+	ancestors = tp.getAncestors(transaction)
+	ancestorPackage = &transactionPackage{
+		fee:  transaction.Transaction().Fee,
+		mass: transaction.Transaction().Mass,
+	}
+	for _, ancestor := range ancestors {
+		ancestorPackage.fee += ancestor.Transaction().Fee
+		ancestorPackage.mass += ancestor.Transaction().Mass
+	}
+	return ancestors, ancestorPackage
+}
+
+// removeFromAncestorPackages removes the given transaction, which is removed from the pool while
+// its redeemers stay, from the ancestor packages of its redeemers
+func (tp *transactionsPool) removeFromAncestorPackages(transaction *model.MempoolTransaction,
+	redeemers []*model.MempoolTransaction) {
+
+	// A redeemer that spends the transaction along more than one path is listed once per path
+	updatedRedeemers := make(map[externalapi.DomainTransactionID]struct{}, len(redeemers))
+	for _, redeemer := range redeemers {
+		redeemerID := *redeemer.TransactionID()
+		if _, ok := updatedRedeemers[redeemerID]; ok {
+			continue
+		}
+		updatedRedeemers[redeemerID] = struct{}{}
+
+		ancestorPackage, ok := tp.ancestorPackages[redeemerID]
+		if !ok {
+			continue
+		}
+		ancestorPackage.fee -= transaction.Transaction().Fee
+		ancestorPackage.mass -= transaction.Transaction().Mass
+	}
+}
+
+// readyTransactionPackages returns, for every ready transaction in the pool, the highest fee-rate
+// ancestor package it is part of.
+// A ready transaction's own package is the transaction alone. Any in-pool descendant whose
+// ancestor package has a higher fee rate raises the score of all the ready transactions in that
+// package, so that a low-fee parent is prioritized on account of its high-fee children.
+// The packages are cached until a transaction is added to or removed from the pool, so the
+// returned map must not be modified.
+func (tp *transactionsPool) readyTransactionPackages() map[externalapi.DomainTransactionID]*transactionPackage {
+	tp.readyPackagesCacheLock.Lock()
+	defer tp.readyPackagesCacheLock.Unlock()
+
+	if tp.readyPackagesCache == nil {
+		tp.readyPackagesCache = tp.calculateReadyTransactionPackages()
+	}
+	return tp.readyPackagesCache
+}
+
+func (tp *transactionsPool) invalidateReadyPackagesCache() {
+	tp.readyPackagesCacheLock.Lock()
+	defer tp.readyPackagesCacheLock.Unlock()
+
+	tp.readyPackagesCache = nil
+}
+
+func (tp *transactionsPool) calculateReadyTransactionPackages() map[externalapi.DomainTransactionID]*transactionPackage {
+	packages := make(map[externalapi.DomainTransactionID]*transactionPackage)
+
+	for transactionID, mempoolTransaction := range tp.allTransactions {
+		if len(mempoolTransaction.ParentTransactionsInPool()) == 0 {
+			packages[transactionID] = &transactionPackage{
+				fee:  mempoolTransaction.Transaction().Fee,
+				mass: mempoolTransaction.Transaction().Mass,
+			}
+		}
+	}
+
+	for _, mempoolTransaction := range tp.allTransactions {
+		if len(mempoolTransaction.ParentTransactionsInPool()) == 0 {
+			continue
+		}
+
+		ancestors, ancestorPackage := tp.getAncestorPackage(mempoolTransaction)
+		for ancestorID := range ancestors {
+			readyPackage, ok := packages[ancestorID]
+			if !ok {
+				continue
+			}
+			if ancestorPackage.feeRate() > readyPackage.feeRate() {
+				packages[ancestorID] = ancestorPackage
+			}
+		}
+	}
+
+	return packages
+}
+
+// getRelayPackage returns the given transaction along with all its in-pool ancestors, ordered so
+// that every transaction comes after its parents, or false if they are more than
+// MaximumPackageTransactions
+func (tp *transactionsPool) getRelayPackage(transaction *model.MempoolTransaction) (
+	[]*model.MempoolTransaction, bool) {
+
+	if len(tp.getAncestors(transaction))+1 > MaximumPackageTransactions {
+		return nil, false
+	}
+
+	relayPackage := make([]*model.MempoolTransaction, 0, MaximumPackageTransactions)
+	visited := model.IDToTransactionMap{}
+	var visit func(current *model.MempoolTransaction)
+	visit = func(current *model.MempoolTransaction) {
+		if _, ok := visited[*current.TransactionID()]; ok {
+			return
+		}
+		visited[*current.TransactionID()] = current
+		for _, parent := range current.ParentTransactionsInPool() {
+			visit(parent)
+		}
+		relayPackage = append(relayPackage, current)
+	}
+	visit(transaction)
+	return relayPackage, true
+}
@@ -0,0 +1,87 @@
+package mempool
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool/model"
+)
+
+func TestReadyTransactionPackages(t *testing.T) {
+	mp := &mempool{}
+	mp.mempoolUTXOSet = newMempoolUTXOSet(mp)
+	mp.transactionsPool = newTransactionsPool(mp)
+
+	addTransaction := func(index uint32, fee uint64, parents ...*model.MempoolTransaction) *model.MempoolTransaction {
+		inputs := []*externalapi.DomainTransactionInput{{
+			PreviousOutpoint: externalapi.DomainOutpoint{Index: index},
+		}}
+		parentTransactionsInPool := model.IDToTransactionMap{}
+		for _, parent := range parents {
+			inputs = append(inputs, &externalapi.DomainTransactionInput{
+				PreviousOutpoint: externalapi.DomainOutpoint{TransactionID: *parent.TransactionID()},
+			})
+			parentTransactionsInPool[*parent.TransactionID()] = parent
+		}
+		transaction := &externalapi.DomainTransaction{
+			Inputs:       inputs,
+			Outputs:      []*externalapi.DomainTransactionOutput{{Value: 1, ScriptPublicKey: &externalapi.ScriptPublicKey{}}},
+			SubnetworkID: subnetworks.SubnetworkIDNative,
+			Fee:          fee,
+			Mass:         1000,
+		}
+		mempoolTransaction := model.NewMempoolTransaction(transaction, parentTransactionsInPool, false, 0)
+		err := mp.transactionsPool.addMempoolTransaction(mempoolTransaction)
+		if err != nil {
+			t.Fatalf("addMempoolTransaction: %+v", err)
+		}
+		return mempoolTransaction
+	}
+
+	lowFeeParent := addTransaction(0, 100)
+	otherLowFeeParent := addTransaction(1, 100)
+	highFeeChild := addTransaction(2, 10_000, lowFeeParent, otherLowFeeParent)
+	addTransaction(3, 100_000, highFeeChild)
+	standalone := addTransaction(4, 500)
+
+	packages := mp.transactionsPool.readyTransactionPackages()
+	if len(packages) != 3 {
+		t.Fatalf("Expected 3 ready packages, but got %d", len(packages))
+	}
+
+	expectedPackage := transactionPackage{fee: 110_200, mass: 4000}
+	for _, parent := range []*model.MempoolTransaction{lowFeeParent, otherLowFeeParent} {
+		readyPackage := packages[*parent.TransactionID()]
+		if *readyPackage != expectedPackage {
+			t.Fatalf("Unexpected package for %s. Want: %+v, got: %+v",
+				consensushashing.TransactionID(parent.Transaction()), expectedPackage, *readyPackage)
+		}
+	}
+
+	standalonePackage := packages[*standalone.TransactionID()]
+	if standalonePackage.fee != 500 || standalonePackage.mass != 1000 {
+		t.Fatalf("Unexpected package for a transaction without descendants: %+v", *standalonePackage)
+	}
+
+	// Adding a high fee child to the standalone transaction has to invalidate the cached packages
+	addTransaction(5, 100_000, standalone)
+	packages = mp.transactionsPool.readyTransactionPackages()
+	standalonePackage = packages[*standalone.TransactionID()]
+	if standalonePackage.fee != 100_500 || standalonePackage.mass != 2000 {
+		t.Fatalf("Unexpected package for a transaction after adding a child to it: %+v", *standalonePackage)
+	}
+
+	// Removing the child has to invalidate the cached packages as well
+	child := mp.transactionsPool.chainedTransactionsByParentID[*standalone.TransactionID()][0]
+	err := mp.transactionsPool.removeTransaction(child)
+	if err != nil {
+		t.Fatalf("removeTransaction: %+v", err)
+	}
+	packages = mp.transactionsPool.readyTransactionPackages()
+	standalonePackage = packages[*standalone.TransactionID()]
+	if standalonePackage.fee != 500 || standalonePackage.mass != 1000 {
+		t.Fatalf("Unexpected package for a transaction after removing its child: %+v", *standalonePackage)
+	}
+}
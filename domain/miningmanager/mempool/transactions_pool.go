@@ -1,6 +1,7 @@
 package mempool
 
 import (
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -18,6 +19,20 @@ type transactionsPool struct {
 	transactionsOrderedByFeeRate  model.TransactionsOrderedByFeeRate
 	lastExpireScanDAAScore        uint64
 	lastExpireScanTime            time.Time
+
+	// totalMemoryUsage is the estimated number of bytes all the transactions of the pool occupy
+	totalMemoryUsage uint64
+
+	// ancestorPackages are the ancestor packages of all the transactions of the pool. They're
+	// kept up to date as transactions are added and removed, so that evictions don't have to
+	// calculate them for the whole pool.
+	ancestorPackages map[externalapi.DomainTransactionID]*transactionPackage
+
+	// readyPackagesCache caches the result of readyTransactionPackages until a transaction is
+	// added or removed. It's guarded by its own lock since it's filled while the mempool is
+	// only read-locked
+	readyPackagesCache     map[externalapi.DomainTransactionID]*transactionPackage
+	readyPackagesCacheLock sync.Mutex
 }
 
 func newTransactionsPool(mp *mempool) *transactionsPool {
@@ -27,6 +42,7 @@ func newTransactionsPool(mp *mempool) *transactionsPool {
 		highPriorityTransactions:      model.IDToTransactionMap{},
 		chainedTransactionsByParentID: model.IDToTransactionsSliceMap{},
 		transactionsOrderedByFeeRate:  model.TransactionsOrderedByFeeRate{},
+		ancestorPackages:              map[externalapi.DomainTransactionID]*transactionPackage{},
 		lastExpireScanDAAScore:        0,
 		lastExpireScanTime:            time.Now(),
 	}
@@ -53,6 +69,9 @@ func (tp *transactionsPool) addTransaction(transaction *externalapi.DomainTransa
 
 func (tp *transactionsPool) addMempoolTransaction(transaction *model.MempoolTransaction) error {
 	tp.allTransactions[*transaction.TransactionID()] = transaction
+	tp.totalMemoryUsage += transactionMemoryUsage(transaction.Transaction())
+	_, tp.ancestorPackages[*transaction.TransactionID()] = tp.getAncestorPackage(transaction)
+	tp.invalidateReadyPackagesCache()
 
 	for _, parentTransactionInPool := range transaction.ParentTransactionsInPool() {
 		parentTransactionID := *parentTransactionInPool.TransactionID()
@@ -78,7 +97,12 @@ func (tp *transactionsPool) addMempoolTransaction(transaction *model.MempoolTran
 }
 
 func (tp *transactionsPool) removeTransaction(transaction *model.MempoolTransaction) error {
+	if _, ok := tp.allTransactions[*transaction.TransactionID()]; ok {
+		tp.totalMemoryUsage -= transactionMemoryUsage(transaction.Transaction())
+	}
 	delete(tp.allTransactions, *transaction.TransactionID())
+	delete(tp.ancestorPackages, *transaction.TransactionID())
+	tp.invalidateReadyPackagesCache()
 
 	err := tp.transactionsOrderedByFeeRate.Remove(transaction)
 	if err != nil {
@@ -173,6 +197,25 @@ func (tp *transactionsPool) getRedeemers(transaction *model.MempoolTransaction)
 	return redeemers
 }
 
+func (tp *transactionsPool) getAncestors(transaction *model.MempoolTransaction) model.IDToTransactionMap {
+	stack := []*model.MempoolTransaction{transaction}
+	ancestors := model.IDToTransactionMap{}
+	for len(stack) > 0 {
+		var current *model.MempoolTransaction
+		last := len(stack) - 1
+		current, stack = stack[last], stack[:last]
+
+		for parentTransactionID, parentTransaction := range current.ParentTransactionsInPool() {
+			if _, ok := ancestors[parentTransactionID]; ok {
+				continue
+			}
+			stack = append(stack, parentTransaction)
+			ancestors[parentTransactionID] = parentTransaction
+		}
+	}
+	return ancestors
+}
+
 func (tp *transactionsPool) limitTransactionCount() error {
 	currentIndex := 0
 
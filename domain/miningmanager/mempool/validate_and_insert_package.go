@@ -0,0 +1,211 @@
+package mempool
+
+import (
+	"fmt"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool/model"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/pkg/errors"
+)
+
+// MaximumPackageTransactions is the maximum number of transactions in a package
+const MaximumPackageTransactions = 25
+
+// validateAndInsertPackage inserts the transactions of the given package into the mempool, either
+// all of them or none of them. The transactions of the package that are already in the mempool
+// are skipped, and the rest are validated like single transactions, except that their fees only
+// have to cover the minimum fee of their total mass together. This way, a transaction that pays
+// too little is accepted along with the descendants that pay for it.
+func (mp *mempool) validateAndInsertPackage(transactions []*externalapi.DomainTransaction, isHighPriority bool) (
+	acceptedTransactions []*externalapi.DomainTransaction, err error) {
+
+	onEnd := logger.LogAndMeasureExecutionTime(log,
+		fmt.Sprintf("validateAndInsertPackage of %d transactions", len(transactions)))
+	defer onEnd()
+
+	err = checkPackageTopology(transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	check := newAcceptanceCheck(true)
+	var newTransactions []*externalapi.DomainTransaction
+	var packageFee, packageMass uint64
+	for _, transaction := range transactions {
+		transactionID := consensushashing.TransactionID(transaction)
+		if _, ok := mp.transactionsPool.allTransactions[*transactionID]; ok {
+			continue
+		}
+		// The transactions are populated while they're checked, and inserted once the whole package is
+		transaction = transaction.Clone()
+		err := mp.checkTransactionForAcceptance(check, transaction)
+		if err != nil {
+			return nil, errors.Wrapf(err, "transaction %s of the package was rejected", transactionID)
+		}
+		check.accept(transactionID, transaction)
+		newTransactions = append(newTransactions, transaction)
+		packageFee += transaction.Fee
+		packageMass += transaction.Mass
+	}
+	if len(newTransactions) == 0 {
+		return []*externalapi.DomainTransaction{}, nil
+	}
+
+	if !mp.config.AcceptNonStandard {
+		minimumFee := mp.minimumRequiredTransactionRelayFee(packageMass)
+		if packageFee < minimumFee {
+			str := fmt.Sprintf("package of %d transactions has %d fees which is under the required amount of %d",
+				len(newTransactions), packageFee, minimumFee)
+			return nil, transactionRuleError(RejectInsufficientFee, str)
+		}
+	}
+
+	// The orphans the package may unorphan are put back if the package is rolled back
+	packageOrphans := mp.orphansPool.orphansDependingOn(newTransactions)
+
+	// Transactions of the package that were received on their own may be waiting in the orphan pool
+	for _, transaction := range newTransactions {
+		err := mp.orphansPool.removeOrphan(consensushashing.TransactionID(transaction), false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	acceptedTransactions = make([]*externalapi.DomainTransaction, 0, len(newTransactions))
+	for _, transaction := range newTransactions {
+		parentsInPool := mp.transactionsPool.getParentTransactionsInPool(transaction)
+		_, err := mp.transactionsPool.addTransaction(transaction, parentsInPool, isHighPriority)
+		if err != nil {
+			return nil, err
+		}
+		acceptedTransactions = append(acceptedTransactions, transaction.Clone()) //these pointers leave the mempool, hence the clone
+	}
+	for _, transaction := range newTransactions {
+		acceptedOrphans, err := mp.orphansPool.processOrphansAfterAcceptedTransaction(transaction)
+		if err != nil {
+			return nil, err
+		}
+		acceptedTransactions = append(acceptedTransactions, acceptedOrphans...)
+	}
+
+	err = mp.transactionsPool.limitTransactionCount()
+	if err != nil {
+		return nil, err
+	}
+	err = mp.limitMemoryUsage()
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range newTransactions {
+		transactionID := consensushashing.TransactionID(transaction)
+		if _, ok := mp.transactionsPool.allTransactions[*transactionID]; !ok {
+			err := mp.rollBackPackage(newTransactions, packageOrphans)
+			if err != nil {
+				return nil, err
+			}
+			str := fmt.Sprintf("transaction %s of the package was evicted since the mempool is full and "+
+				"the fee rate of the package is too low", transactionID)
+			return nil, transactionRuleError(RejectInsufficientFee, str)
+		}
+	}
+
+	return mp.transactionsStillInPool(acceptedTransactions), nil
+}
+
+// rollBackPackage removes the given transactions of a package from the mempool along with their
+// descendants, and puts back the given orphans that depended on them. The transactions that
+// were evicted to make room for the package aren't put back.
+func (mp *mempool) rollBackPackage(newTransactions []*externalapi.DomainTransaction,
+	packageOrphans []*model.OrphanTransaction) error {
+
+	removedTransactionIDs := make(map[externalapi.DomainTransactionID]struct{})
+	for _, transaction := range newTransactions {
+		removedTransactionIDs[*consensushashing.TransactionID(transaction)] = struct{}{}
+	}
+	for _, orphan := range packageOrphans {
+		removedTransactionIDs[*orphan.TransactionID()] = struct{}{}
+	}
+
+	for i := len(newTransactions) - 1; i >= 0; i-- {
+		err := mp.removeTransaction(consensushashing.TransactionID(newTransactions[i]), true)
+		if err != nil {
+			return err
+		}
+	}
+	// Orphans are promoted to the transaction pool only after their parents, so they were
+	// all removed along with the package, but any of them may be left in the orphan pool
+	for _, orphan := range packageOrphans {
+		err := mp.orphansPool.removeOrphan(orphan.TransactionID(), false)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, orphan := range packageOrphans {
+		// The inputs that were filled by the package are missing again
+		for _, input := range orphan.Transaction().Inputs {
+			if _, ok := removedTransactionIDs[input.PreviousOutpoint.TransactionID]; ok {
+				input.UTXOEntry = nil
+			}
+		}
+		mp.orphansPool.insertOrphan(orphan)
+	}
+	return nil
+}
+
+// checkPackageTopology makes sure that the given transactions are a package: at most
+// MaximumPackageTransactions distinct transactions, each of them after its parents in the
+// package, and all of them connected to each other by the outputs they spend within it
+func checkPackageTopology(transactions []*externalapi.DomainTransaction) error {
+	if len(transactions) == 0 || len(transactions) > MaximumPackageTransactions {
+		str := fmt.Sprintf("a package has between 1 and %d transactions, but got %d",
+			MaximumPackageTransactions, len(transactions))
+		return transactionRuleError(RejectInvalid, str)
+	}
+
+	transactionIDs := make([]*externalapi.DomainTransactionID, len(transactions))
+	indexes := make(map[externalapi.DomainTransactionID]int, len(transactions))
+	for i, transaction := range transactions {
+		transactionIDs[i] = consensushashing.TransactionID(transaction)
+		if _, ok := indexes[*transactionIDs[i]]; ok {
+			return transactionRuleError(RejectInvalid,
+				fmt.Sprintf("transaction %s appears twice in the package", transactionIDs[i]))
+		}
+		indexes[*transactionIDs[i]] = i
+	}
+
+	// components holds the index of a transaction of the same connected component as every
+	// transaction, so that the components are merged as the parents of transactions are found
+	components := make([]int, len(transactions))
+	for i := range components {
+		components[i] = i
+	}
+	componentOf := func(i int) int {
+		for components[i] != i {
+			components[i] = components[components[i]]
+			i = components[i]
+		}
+		return i
+	}
+	for i, transaction := range transactions {
+		for _, input := range transaction.Inputs {
+			parentIndex, ok := indexes[input.PreviousOutpoint.TransactionID]
+			if !ok {
+				continue
+			}
+			if parentIndex > i {
+				return transactionRuleError(RejectInvalid, fmt.Sprintf("transaction %s of the package "+
+					"comes before its child %s", transactionIDs[parentIndex], transactionIDs[i]))
+			}
+			components[componentOf(parentIndex)] = componentOf(i)
+		}
+	}
+	for i := 1; i < len(transactions); i++ {
+		if componentOf(i) != componentOf(0) {
+			return transactionRuleError(RejectInvalid, fmt.Sprintf("transaction %s of the package isn't "+
+				"connected to %s by the transactions of the package", transactionIDs[i], transactionIDs[0]))
+		}
+	}
+	return nil
+}
@@ -6,10 +6,13 @@ import (
 
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	miningmanagermodel "github.com/kaspanet/kaspad/domain/miningmanager/model"
 )
 
 func (mp *mempool) validateAndInsertTransaction(transaction *externalapi.DomainTransaction, isHighPriority bool,
-	allowOrphan bool) (acceptedTransactions []*externalapi.DomainTransaction, err error) {
+	allowOrphan bool, tag miningmanagermodel.Tag) (acceptedTransactions []*externalapi.DomainTransaction, err error) {
 
 	onEnd := logger.LogAndMeasureExecutionTime(log,
 		fmt.Sprintf("validateAndInsertTransaction %s", consensushashing.TransactionID(transaction)))
@@ -35,10 +38,10 @@ func (mp *mempool) validateAndInsertTransaction(transaction *externalapi.DomainT
 			return nil, transactionRuleError(RejectBadOrphan, str)
 		}
 
-		return nil, mp.orphansPool.maybeAddOrphan(transaction, isHighPriority)
+		return nil, mp.orphansPool.maybeAddOrphan(transaction, isHighPriority, tag)
 	}
 
-	err = mp.validateTransactionInContext(transaction)
+	err = mp.validateTransactionInContext(transaction, true)
 	if err != nil {
 		return nil, err
 	}
@@ -60,5 +63,55 @@ func (mp *mempool) validateAndInsertTransaction(transaction *externalapi.DomainT
 		return nil, err
 	}
 
-	return acceptedTransactions, nil
+	err = mp.limitMemoryUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	// The transaction itself may have been evicted to make room, in which
+	// case so were the orphans that depend on it
+	if _, ok := mp.transactionsPool.allTransactions[*consensushashing.TransactionID(transaction)]; !ok {
+		str := fmt.Sprintf("transaction %s was evicted since the mempool is full and its fee rate is too low",
+			consensushashing.TransactionID(transaction))
+		return nil, transactionRuleError(RejectInsufficientFee, str)
+	}
+
+	return mp.transactionsStillInPool(acceptedTransactions), nil
+}
+
+// validateTransaction runs the validation of validateAndInsertTransaction without inserting the
+// transaction, and considers orphans invalid. The transaction may also spend the outputs of
+// checkedParents, which aren't in the mempool, as if they were. Its fee is checked only if
+// shouldCheckFee is true.
+func (mp *mempool) validateTransaction(transaction *externalapi.DomainTransaction,
+	checkedParents map[externalapi.DomainTransactionID]*externalapi.DomainTransaction, shouldCheckFee bool) error {
+
+	mp.consensusReference.Consensus().PopulateMass(transaction)
+
+	err := mp.validateTransactionPreUTXOEntry(transaction)
+	if err != nil {
+		return err
+	}
+
+	// The outputs of the checked parents are filled in like the outputs of mempool transactions
+	for _, input := range transaction.Inputs {
+		parent, ok := checkedParents[input.PreviousOutpoint.TransactionID]
+		if !ok || input.PreviousOutpoint.Index >= uint32(len(parent.Outputs)) {
+			continue
+		}
+		output := parent.Outputs[input.PreviousOutpoint.Index]
+		input.UTXOEntry = utxo.NewUTXOEntry(output.Value, output.ScriptPublicKey, false, constants.UnacceptedDAAScore)
+	}
+
+	_, missingOutpoints, err := mp.fillInputsAndGetMissingParents(transaction)
+	if err != nil {
+		return err
+	}
+	if len(missingOutpoints) > 0 {
+		str := fmt.Sprintf("transaction %s is an orphan, missing outpoints %s",
+			consensushashing.TransactionID(transaction), missingOutpoints)
+		return transactionRuleError(RejectBadOrphan, str)
+	}
+
+	return mp.validateTransactionInContext(transaction, shouldCheckFee)
 }
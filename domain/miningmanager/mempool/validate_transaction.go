@@ -6,6 +6,7 @@ import (
 
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
 )
 
 func (mp *mempool) validateTransactionPreUTXOEntry(transaction *externalapi.DomainTransaction) error {
@@ -17,6 +18,13 @@ func (mp *mempool) validateTransactionPreUTXOEntry(transaction *externalapi.Doma
 	if err := mp.mempoolUTXOSet.checkDoubleSpends(transaction); err != nil {
 		return err
 	}
+
+	if mp.config.FreezePolicy != nil {
+		err := mp.config.FreezePolicy.checkOutpoints(transaction)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -44,7 +52,10 @@ func (mp *mempool) validateTransactionInIsolation(transaction *externalapi.Domai
 	return nil
 }
 
-func (mp *mempool) validateTransactionInContext(transaction *externalapi.DomainTransaction) error {
+// validateTransactionInContext validates the transaction against the filled in UTXO entries of its inputs.
+// The fee of a transaction that's validated as part of a package isn't checked on its own, since the
+// transactions of the package pay for each other, so checkFee is false for them.
+func (mp *mempool) validateTransactionInContext(transaction *externalapi.DomainTransaction, checkFee bool) error {
 	hasCoinbaseInput := false
 	for _, input := range transaction.Inputs {
 		if input.UTXOEntry.IsCoinbase() {
@@ -53,14 +64,23 @@ func (mp *mempool) validateTransactionInContext(transaction *externalapi.DomainT
 		}
 	}
 
+	if mp.config.FreezePolicy != nil {
+		err := mp.config.FreezePolicy.checkAddresses(transaction)
+		if err != nil {
+			return err
+		}
+	}
+
 	numExtraOuts := len(transaction.Outputs) - len(transaction.Inputs)
 	if !hasCoinbaseInput && numExtraOuts > 2 && transaction.Fee < uint64(numExtraOuts)*constants.SompiPerKaspa {
-		log.Warnf("Rejected spam tx %s from mempool (%d outputs)", consensushashing.TransactionID(transaction), len(transaction.Outputs))
-		return transactionRuleError(RejectSpamTx, fmt.Sprintf("Rejected spam tx %s from mempool", consensushashing.TransactionID(transaction)))
+		transactionID := consensushashing.TransactionID(transaction)
+		spamLog.With(logger.TxID(transactionID)).Warnf("Rejected spam tx %s from mempool (%d outputs)",
+			transactionID, len(transaction.Outputs))
+		return transactionRuleError(RejectSpamTx, fmt.Sprintf("Rejected spam tx %s from mempool", transactionID))
 	}
 
 	if !mp.config.AcceptNonStandard {
-		err := mp.checkTransactionStandardInContext(transaction)
+		err := mp.checkTransactionStandardInContext(transaction, checkFee)
 		if err != nil {
 			// Attempt to extract a reject code from the error so
 			// it can be retained. When not possible, fall back to
@@ -13,12 +13,17 @@ import (
 // known transactions that have no yet been added to any block
 type MiningManager interface {
 	GetBlockTemplate(coinbaseData *externalapi.DomainCoinbaseData) (block *externalapi.DomainBlock, isNearlySynced bool, err error)
+	GetBlockTemplateWithOptions(coinbaseData *externalapi.DomainCoinbaseData, options *miningmanagermodel.BlockTemplateOptions) (
+		block *externalapi.DomainBlock, isNearlySynced bool, err error)
 	ClearBlockTemplate()
 	GetBlockTemplateBuilder() miningmanagermodel.BlockTemplateBuilder
 	GetTransaction(transactionID *externalapi.DomainTransactionID, includeTransactionPool bool, includeOrphanPool bool) (
 		transactionPoolTransaction *externalapi.DomainTransaction,
 		isOrphan bool,
 		found bool)
+	SpendingTx(outpoint *externalapi.DomainOutpoint) (transaction *externalapi.DomainTransaction, found bool)
+	OutputsCreatedBy(transactionID *externalapi.DomainTransactionID) (
+		unspentOutputs []*externalapi.OutpointAndUTXOEntryPair, found bool)
 	GetTransactionsByAddresses(includeTransactionPool bool, includeOrphanPool bool) (
 		sendingInTransactionPool map[string]*externalapi.DomainTransaction,
 		receivingInTransactionPool map[string]*externalapi.DomainTransaction,
@@ -29,10 +34,24 @@ type MiningManager interface {
 		transactionPoolTransactions []*externalapi.DomainTransaction,
 		orphanPoolTransactions []*externalapi.DomainTransaction)
 	TransactionCount(includeTransactionPool bool, includeOrphanPool bool) int
+	MempoolInfo() *miningmanagermodel.MempoolInfo
+	SetMaximumMempoolMemoryUsage(maximumMemoryUsage uint64) error
 	HandleNewBlockTransactions(txs []*externalapi.DomainTransaction) ([]*externalapi.DomainTransaction, error)
+	HandleSelectedChainChanges(chainChanges *externalapi.SelectedChainPath) (
+		readmittedTransactions []*externalapi.DomainTransaction, err error)
 	ValidateAndInsertTransaction(transaction *externalapi.DomainTransaction, isHighPriority bool, allowOrphan bool) (
 		acceptedTransactions []*externalapi.DomainTransaction, err error)
+	ValidateAndInsertTaggedTransaction(transaction *externalapi.DomainTransaction, isHighPriority bool, allowOrphan bool,
+		tag miningmanagermodel.Tag) (acceptedTransactions []*externalapi.DomainTransaction, err error)
+	ValidateTransaction(transaction *externalapi.DomainTransaction) error
+	CheckTransactionForAcceptance(transactions []*externalapi.DomainTransaction) (
+		[]*miningmanagermodel.TransactionAcceptanceCheck, error)
+	ValidateAndInsertPackage(transactions []*externalapi.DomainTransaction, isHighPriority bool) (
+		acceptedTransactions []*externalapi.DomainTransaction, err error)
+	GetRelayPackage(transactionID *externalapi.DomainTransactionID) ([]*externalapi.DomainTransaction, bool)
 	RevalidateHighPriorityTransactions() (validTransactions []*externalapi.DomainTransaction, err error)
+	SaveMempoolToFile(path string) (int, error)
+	LoadMempoolFromFile(path string) (acceptedCount int, rejectedCount int, err error)
 }
 
 type miningManager struct {
@@ -76,6 +95,22 @@ func (mm *miningManager) GetBlockTemplate(coinbaseData *externalapi.DomainCoinba
 	return blockTemplate.Block, blockTemplate.IsNearlySynced, nil
 }
 
+// GetBlockTemplateWithOptions is the same as GetBlockTemplate, except that the transactions of
+// the template follow the given options. A template with options is always built anew, and
+// isn't cached, since it's only of use to the caller that asked for it.
+func (mm *miningManager) GetBlockTemplateWithOptions(coinbaseData *externalapi.DomainCoinbaseData,
+	options *miningmanagermodel.BlockTemplateOptions) (block *externalapi.DomainBlock, isNearlySynced bool, err error) {
+
+	if options.IsEmpty() {
+		return mm.GetBlockTemplate(coinbaseData)
+	}
+	blockTemplate, err := mm.blockTemplateBuilder.BuildBlockTemplateWithOptions(coinbaseData, options)
+	if err != nil {
+		return nil, false, err
+	}
+	return blockTemplate.Block, blockTemplate.IsNearlySynced, nil
+}
+
 func (mm *miningManager) ClearBlockTemplate() {
 	mm.cacheLock.Lock()
 	mm.cachingTime = time.Time{}
@@ -91,6 +126,8 @@ func (mm *miningManager) getImmutableCachedTemplate() *externalapi.DomainBlockTe
 		// unmodified for a while. All in all, caching for max 1 second is a good compromise.
 		mm.cachedBlockTemplate = nil
 	}
+	// The cached template is keyed on the DAG tips it was built on: ClearBlockTemplate is called
+	// whenever the virtual changes, so a cached template is never built on stale tips
 	return mm.cachedBlockTemplate
 }
 
@@ -108,6 +145,14 @@ func (mm *miningManager) HandleNewBlockTransactions(txs []*externalapi.DomainTra
 	return mm.mempool.HandleNewBlockTransactions(txs)
 }
 
+// HandleSelectedChainChanges re-admits into the mempool the transactions that the virtual
+// selected chain no longer accepts, and returns the re-admitted transactions
+func (mm *miningManager) HandleSelectedChainChanges(chainChanges *externalapi.SelectedChainPath) (
+	readmittedTransactions []*externalapi.DomainTransaction, err error) {
+
+	return mm.mempool.HandleSelectedChainChanges(chainChanges)
+}
+
 // ValidateAndInsertTransaction validates the given transaction, and
 // adds it to the set of known transactions that have not yet been
 // added to any block
@@ -117,6 +162,45 @@ func (mm *miningManager) ValidateAndInsertTransaction(transaction *externalapi.D
 	return mm.mempool.ValidateAndInsertTransaction(transaction, isHighPriority, allowOrphan)
 }
 
+// ValidateAndInsertTaggedTransaction is the same as ValidateAndInsertTransaction, except that
+// if the transaction is an orphan, it counts towards the orphan pool memory limit of the given tag
+func (mm *miningManager) ValidateAndInsertTaggedTransaction(transaction *externalapi.DomainTransaction,
+	isHighPriority bool, allowOrphan bool, tag miningmanagermodel.Tag) (
+	acceptedTransactions []*externalapi.DomainTransaction, err error) {
+
+	return mm.mempool.ValidateAndInsertTaggedTransaction(transaction, isHighPriority, allowOrphan, tag)
+}
+
+// ValidateTransaction validates the given transaction against the current mempool
+// without adding it to the mempool
+func (mm *miningManager) ValidateTransaction(transaction *externalapi.DomainTransaction) error {
+	return mm.mempool.ValidateTransaction(transaction)
+}
+
+// CheckTransactionForAcceptance checks whether the given transactions would be accepted to the
+// mempool one after the other, without adding them to the mempool
+func (mm *miningManager) CheckTransactionForAcceptance(transactions []*externalapi.DomainTransaction) (
+	[]*miningmanagermodel.TransactionAcceptanceCheck, error) {
+
+	return mm.mempool.CheckTransactionForAcceptance(transactions)
+}
+
+// ValidateAndInsertPackage inserts the transactions of the given package into the mempool, either all
+// of them or none of them
+func (mm *miningManager) ValidateAndInsertPackage(transactions []*externalapi.DomainTransaction, isHighPriority bool) (
+	acceptedTransactions []*externalapi.DomainTransaction, err error) {
+
+	return mm.mempool.ValidateAndInsertPackage(transactions, isHighPriority)
+}
+
+// GetRelayPackage returns the transaction with the given ID along with its ancestors in the mempool,
+// in topological order
+func (mm *miningManager) GetRelayPackage(transactionID *externalapi.DomainTransactionID) (
+	[]*externalapi.DomainTransaction, bool) {
+
+	return mm.mempool.GetRelayPackage(transactionID)
+}
+
 func (mm *miningManager) GetTransaction(
 	transactionID *externalapi.DomainTransactionID,
 	includeTransactionPool bool,
@@ -128,6 +212,22 @@ func (mm *miningManager) GetTransaction(
 	return mm.mempool.GetTransaction(transactionID, includeTransactionPool, includeOrphanPool)
 }
 
+// SpendingTx returns the transaction in the transaction pool that spends the given outpoint, if any
+func (mm *miningManager) SpendingTx(outpoint *externalapi.DomainOutpoint) (
+	transaction *externalapi.DomainTransaction, found bool) {
+
+	return mm.mempool.SpendingTx(outpoint)
+}
+
+// OutputsCreatedBy returns the outputs of the transaction with the given ID in the transaction
+// pool that no other transaction in the pool spends. found is false if the transaction isn't in
+// the transaction pool.
+func (mm *miningManager) OutputsCreatedBy(transactionID *externalapi.DomainTransactionID) (
+	unspentOutputs []*externalapi.OutpointAndUTXOEntryPair, found bool) {
+
+	return mm.mempool.OutputsCreatedBy(transactionID)
+}
+
 func (mm *miningManager) AllTransactions(includeTransactionPool bool, includeOrphanPool bool) (
 	transactionPoolTransactions []*externalapi.DomainTransaction,
 	orphanPoolTransactions []*externalapi.DomainTransaction) {
@@ -149,8 +249,30 @@ func (mm *miningManager) TransactionCount(includeTransactionPool bool, includeOr
 	return mm.mempool.TransactionCount(includeTransactionPool, includeOrphanPool)
 }
 
+// MempoolInfo returns the size of the mempool and its orphan pool, and the fee rates it accepts
+func (mm *miningManager) MempoolInfo() *miningmanagermodel.MempoolInfo {
+	return mm.mempool.Info()
+}
+
+// SetMaximumMempoolMemoryUsage changes the number of bytes the transactions of the mempool
+// may occupy, and evicts transactions if they occupy more
+func (mm *miningManager) SetMaximumMempoolMemoryUsage(maximumMemoryUsage uint64) error {
+	return mm.mempool.SetMaximumMemoryUsage(maximumMemoryUsage)
+}
+
 func (mm *miningManager) RevalidateHighPriorityTransactions() (
 	validTransactions []*externalapi.DomainTransaction, err error) {
 
 	return mm.mempool.RevalidateHighPriorityTransactions()
 }
+
+// SaveMempoolToFile saves the transactions of the mempool to the file at the given path
+func (mm *miningManager) SaveMempoolToFile(path string) (int, error) {
+	return mm.mempool.SaveToFile(path)
+}
+
+// LoadMempoolFromFile validates the transactions that were saved to the file at the
+// given path, and inserts the valid ones into the mempool
+func (mm *miningManager) LoadMempoolFromFile(path string) (acceptedCount int, rejectedCount int, err error) {
+	return mm.mempool.LoadFromFile(path)
+}
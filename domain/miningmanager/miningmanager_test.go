@@ -6,6 +6,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/miningmanager/model"
 	"github.com/kaspanet/kaspad/util"
 	"github.com/kaspanet/kaspad/version"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -643,6 +644,581 @@ func TestRevalidateHighPriorityTransactionsWithChain(t *testing.T) {
 	})
 }
 
+// TestOrphanChainPromotion verifies that a chain of orphans is moved to the mempool once
+// its first parent arrives, whether it's accepted to the mempool or included in a block
+func TestOrphanChainPromotion(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestOrphanChainPromotion")
+		if err != nil {
+			t.Fatalf("Failed setting up TestConsensus: %+v", err)
+		}
+		defer teardown(false)
+
+		miningFactory := miningmanager.NewFactory()
+		tcAsConsensus := tc.(externalapi.Consensus)
+		tcAsConsensusPointer := &tcAsConsensus
+		consensusReference := consensusreference.NewConsensusReference(&tcAsConsensusPointer)
+		miningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params,
+			mempool.DefaultConfig(&consensusConfig.Params))
+
+		const chainSize = 5
+		const tag = model.Tag("peer")
+		addOrphans := func(chain []*externalapi.DomainTransaction) {
+			// The orphans are added from the end of the chain, so that every one of them
+			// but the last is missing both its parent and its child when it's added
+			for i := len(chain) - 1; i >= 1; i-- {
+				acceptedTransactions, err := miningManager.ValidateAndInsertTaggedTransaction(chain[i], false, true, tag)
+				if err != nil {
+					t.Fatalf("ValidateAndInsertTaggedTransaction: %+v", err)
+				}
+				if len(acceptedTransactions) != 0 {
+					t.Fatalf("Expected transaction %d of the chain to be an orphan", i)
+				}
+			}
+			orphans := miningManager.MempoolInfo().Orphans
+			if orphans.Count != chainSize-1 || orphans.MissingOutpointCount != chainSize-1 || orphans.PeerCount != 1 {
+				t.Fatalf("Expected %d orphans of a single peer that miss %d outpoints, but got %d orphans "+
+					"of %d peers that miss %d outpoints", chainSize-1, chainSize-1, orphans.Count,
+					orphans.PeerCount, orphans.MissingOutpointCount)
+			}
+		}
+		checkPromoted := func(chain []*externalapi.DomainTransaction, acceptedTransactions []*externalapi.DomainTransaction,
+			expectedPromotedCount uint64) {
+
+			for _, transaction := range chain[1:] {
+				if !contains(transaction, acceptedTransactions) {
+					t.Fatalf("Expected orphan %s to be accepted", consensushashing.TransactionID(transaction))
+				}
+				_, isOrphan, found := miningManager.GetTransaction(consensushashing.TransactionID(transaction), true, true)
+				if !found || isOrphan {
+					t.Fatalf("Expected orphan %s to be moved to the mempool", consensushashing.TransactionID(transaction))
+				}
+			}
+			orphans := miningManager.MempoolInfo().Orphans
+			if orphans.Count != 0 || orphans.MissingOutpointCount != 0 || orphans.PeerCount != 0 {
+				t.Fatalf("Expected the orphan pool to be empty, but it has %d orphans of %d peers that miss "+
+					"%d outpoints", orphans.Count, orphans.PeerCount, orphans.MissingOutpointCount)
+			}
+			if orphans.PromotedCount != expectedPromotedCount {
+				t.Fatalf("Expected %d promoted orphans but got %d", expectedPromotedCount, orphans.PromotedCount)
+			}
+		}
+
+		// The parent of the chain is accepted to the mempool
+		chain, err := createTxChain(tc, chainSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addOrphans(chain)
+		acceptedTransactions, err := miningManager.ValidateAndInsertTransaction(chain[0], false, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %+v", err)
+		}
+		checkPromoted(chain, acceptedTransactions, chainSize-1)
+
+		// The parent of the chain is included in a block
+		chain, err = createTxChain(tc, chainSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addOrphans(chain)
+		blockHash, _, err := tc.AddBlockOnTips(nil, []*externalapi.DomainTransaction{chain[0].Clone()})
+		if err != nil {
+			t.Fatalf("AddBlockOnTips: %+v", err)
+		}
+		block, _, err := tc.GetBlock(blockHash)
+		if err != nil {
+			t.Fatalf("GetBlock: %+v", err)
+		}
+		acceptedTransactions, err = miningManager.HandleNewBlockTransactions(block.Transactions)
+		if err != nil {
+			t.Fatalf("HandleNewBlockTransactions: %+v", err)
+		}
+		checkPromoted(chain, acceptedTransactions, 2*(chainSize-1))
+	})
+}
+
+// TestCheckTransactionForAcceptance verifies that transactions are checked one after the other
+// without being inserted into the mempool
+func TestCheckTransactionForAcceptance(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestCheckTransactionForAcceptance")
+		if err != nil {
+			t.Fatalf("Error setting up TestConsensus: %+v", err)
+		}
+		defer teardown(false)
+
+		miningFactory := miningmanager.NewFactory()
+		tcAsConsensus := tc.(externalapi.Consensus)
+		tcAsConsensusPointer := &tcAsConsensus
+		consensusReference := consensusreference.NewConsensusReference(&tcAsConsensusPointer)
+		miningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params,
+			mempool.DefaultConfig(&consensusConfig.Params))
+
+		chain, err := createTxChain(tc, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doubleSpendingTransaction := chain[0].Clone()
+		doubleSpendingTransaction.ID = nil
+		doubleSpendingTransaction.Outputs[0].Value--
+
+		checkRejectCodes := func(transactions []*externalapi.DomainTransaction, expectedRejectCodes ...mempool.RejectCode) {
+			results, err := miningManager.CheckTransactionForAcceptance(transactions)
+			if err != nil {
+				t.Fatalf("CheckTransactionForAcceptance: %+v", err)
+			}
+			for i, result := range results {
+				if !result.TransactionID.Equal(consensushashing.TransactionID(transactions[i])) {
+					t.Fatalf("Expected result %d to be of transaction %s but got %s", i,
+						consensushashing.TransactionID(transactions[i]), result.TransactionID)
+				}
+				if expectedRejectCodes[i] == 0 {
+					if result.RejectReason != nil {
+						t.Fatalf("Expected transaction %d to be accepted but got: %s", i, result.RejectReason)
+					}
+					if result.Fee != 1000 || result.Mass == 0 {
+						t.Fatalf("Expected transaction %d to pay a fee of 1000 but got a fee of %d and a mass of %d",
+							i, result.Fee, result.Mass)
+					}
+					continue
+				}
+				txRuleError := &mempool.TxRuleError{}
+				if !errors.As(result.RejectReason, txRuleError) || txRuleError.RejectCode != expectedRejectCodes[i] {
+					t.Fatalf("Expected transaction %d to be rejected with %s but got: %v", i,
+						expectedRejectCodes[i], result.RejectReason)
+				}
+			}
+		}
+
+		// A transaction may spend the outputs of the ones before it, but not the outputs
+		// they already spent
+		checkRejectCodes(
+			[]*externalapi.DomainTransaction{chain[0], chain[1], chain[2], chain[2], doubleSpendingTransaction},
+			0, 0, 0, mempool.RejectDuplicate, mempool.RejectDuplicate)
+		checkRejectCodes([]*externalapi.DomainTransaction{chain[2], chain[1]},
+			mempool.RejectBadOrphan, mempool.RejectBadOrphan)
+		if count := miningManager.TransactionCount(true, true); count != 0 {
+			t.Fatalf("Expected the checked transactions not to be inserted, but the mempool has %d", count)
+		}
+
+		_, err = miningManager.ValidateAndInsertTransaction(chain[0], false, false)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %+v", err)
+		}
+		checkRejectCodes([]*externalapi.DomainTransaction{chain[0], chain[1], doubleSpendingTransaction},
+			mempool.RejectDuplicate, 0, mempool.RejectDuplicate)
+	})
+}
+
+// TestValidateAndInsertPackage verifies that a parent that pays no fee is accepted along with
+// a child that pays for both, and that packages are inserted either whole or not at all
+func TestValidateAndInsertPackage(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestValidateAndInsertPackage")
+		if err != nil {
+			t.Fatalf("Error setting up TestConsensus: %+v", err)
+		}
+		defer teardown(false)
+
+		miningFactory := miningmanager.NewFactory()
+		tcAsConsensus := tc.(externalapi.Consensus)
+		tcAsConsensusPointer := &tcAsConsensus
+		consensusReference := consensusreference.NewConsensusReference(&tcAsConsensusPointer)
+		miningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params,
+			mempool.DefaultConfig(&consensusConfig.Params))
+
+		createPackage := func(childFee uint64) (parent, child *externalapi.DomainTransaction) {
+			chain, err := createTxChain(tc, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The fee of the chain's transaction is given back to its output, so the parent pays nothing
+			parent = chain[0]
+			parent.Outputs[0].Value += 1000
+			child, err = testutils.CreateTransaction(parent, childFee)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return parent, child
+		}
+		expectRejectCode := func(err error, expectedRejectCode mempool.RejectCode) {
+			txRuleError := &mempool.TxRuleError{}
+			if !errors.As(err, txRuleError) || txRuleError.RejectCode != expectedRejectCode {
+				t.Fatalf("Expected a rejection with %s but got: %v", expectedRejectCode, err)
+			}
+		}
+
+		parent, child := createPackage(10_000)
+		_, err = miningManager.ValidateAndInsertTransaction(parent.Clone(), false, false)
+		expectRejectCode(err, mempool.RejectInsufficientFee)
+		// The child waits for its parent in the orphan pool
+		_, err = miningManager.ValidateAndInsertTransaction(child.Clone(), false, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %+v", err)
+		}
+
+		_, err = miningManager.ValidateAndInsertPackage([]*externalapi.DomainTransaction{child, parent}, false)
+		expectRejectCode(err, mempool.RejectInvalid)
+
+		acceptedTransactions, err := miningManager.ValidateAndInsertPackage(
+			[]*externalapi.DomainTransaction{parent, child}, false)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertPackage: %+v", err)
+		}
+		if len(acceptedTransactions) != 2 || !contains(parent, acceptedTransactions) || !contains(child, acceptedTransactions) {
+			t.Fatalf("Expected the parent and the child to be accepted but got %d transactions",
+				len(acceptedTransactions))
+		}
+		if count := miningManager.TransactionCount(true, false); count != 2 {
+			t.Fatalf("Expected 2 transactions in the mempool but got %d", count)
+		}
+		if count := miningManager.TransactionCount(false, true); count != 0 {
+			t.Fatalf("Expected the child to leave the orphan pool but it has %d orphans", count)
+		}
+
+		relayPackage, ok := miningManager.GetRelayPackage(consensushashing.TransactionID(child))
+		if !ok || len(relayPackage) != 2 ||
+			!consensushashing.TransactionID(relayPackage[0]).Equal(consensushashing.TransactionID(parent)) ||
+			!consensushashing.TransactionID(relayPackage[1]).Equal(consensushashing.TransactionID(child)) {
+			t.Fatalf("Expected the relay package of the child to be the parent and the child")
+		}
+		// A package that's already in the mempool is skipped
+		acceptedTransactions, err = miningManager.ValidateAndInsertPackage(relayPackage, false)
+		if err != nil || len(acceptedTransactions) != 0 {
+			t.Fatalf("Expected a known package to be skipped but got %d transactions and: %v",
+				len(acceptedTransactions), err)
+		}
+
+		// A child that doesn't pay for its parent doesn't get either of them accepted
+		poorParent, poorChild := createPackage(1)
+		_, err = miningManager.ValidateAndInsertPackage([]*externalapi.DomainTransaction{poorParent, poorChild}, false)
+		expectRejectCode(err, mempool.RejectInsufficientFee)
+		if count := miningManager.TransactionCount(true, true); count != 2 {
+			t.Fatalf("Expected the rejected package not to be inserted, but the mempool has %d transactions", count)
+		}
+
+		// A parent may pay for several children of the package
+		parent, firstChild := createPackage(10_000)
+		parent.Outputs[0].Value /= 2
+		parent.Outputs = append(parent.Outputs, parent.Outputs[0].Clone())
+		firstChild, err = testutils.CreateTransaction(parent, 10_000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		secondChild := firstChild.Clone()
+		secondChild.Inputs[0].PreviousOutpoint.Index = 1
+		acceptedTransactions, err = miningManager.ValidateAndInsertPackage(
+			[]*externalapi.DomainTransaction{parent, firstChild, secondChild}, false)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertPackage: %+v", err)
+		}
+		if len(acceptedTransactions) != 3 {
+			t.Fatalf("Expected the parent and both children to be accepted but got %d transactions",
+				len(acceptedTransactions))
+		}
+
+		// Every transaction must be connected to the rest of the package
+		unrelatedParent, unrelatedChild := createPackage(10_000)
+		_, err = miningManager.ValidateAndInsertPackage(
+			[]*externalapi.DomainTransaction{unrelatedParent, unrelatedChild, poorParent}, false)
+		expectRejectCode(err, mempool.RejectInvalid)
+
+		// A package that's evicted right away is removed altogether, and its orphans are put back
+		fullMempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
+		fullMempoolConfig.MaximumTransactionCount = 2
+		fullMiningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params,
+			fullMempoolConfig)
+		otherChain, err := createTxChain(tc, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = fullMiningManager.ValidateAndInsertTransaction(otherChain[0], false, false)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %+v", err)
+		}
+		chain, err := createTxChain(tc, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The parent pays for the package, so the child that pays nothing is the one to be evicted
+		chain[0].Outputs[0].Value -= 10_000
+		freeChild, err := testutils.CreateTransaction(chain[0], 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = fullMiningManager.ValidateAndInsertTransaction(freeChild.Clone(), false, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %+v", err)
+		}
+		_, err = fullMiningManager.ValidateAndInsertPackage([]*externalapi.DomainTransaction{chain[0], freeChild}, false)
+		expectRejectCode(err, mempool.RejectInsufficientFee)
+		if count := fullMiningManager.TransactionCount(true, false); count != 1 {
+			t.Fatalf("Expected the evicted package to be removed altogether, but the mempool has %d transactions", count)
+		}
+		_, orphans := fullMiningManager.AllTransactions(false, true)
+		if len(orphans) != 1 || !consensushashing.TransactionID(orphans[0]).Equal(consensushashing.TransactionID(freeChild)) {
+			t.Fatalf("Expected the child to be put back in the orphan pool but got %d orphans", len(orphans))
+		}
+	})
+}
+
+// TestOutpointLookups verifies that the spenders and the unspent outputs of the transactions in
+// the mempool are found by outpoint
+func TestOutpointLookups(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestOutpointLookups")
+		if err != nil {
+			t.Fatalf("Error setting up TestConsensus: %+v", err)
+		}
+		defer teardown(false)
+
+		miningFactory := miningmanager.NewFactory()
+		tcAsConsensus := tc.(externalapi.Consensus)
+		tcAsConsensusPointer := &tcAsConsensus
+		consensusReference := consensusreference.NewConsensusReference(&tcAsConsensusPointer)
+		miningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params,
+			mempool.DefaultConfig(&consensusConfig.Params))
+
+		chain, err := createTxChain(tc, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, transaction := range chain {
+			_, err = miningManager.ValidateAndInsertTransaction(transaction, false, false)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertTransaction: %+v", err)
+			}
+		}
+		parentID := consensushashing.TransactionID(chain[0])
+		childID := consensushashing.TransactionID(chain[1])
+		grandchildID := consensushashing.TransactionID(chain[2])
+
+		// The output of the DAG that the parent spends
+		spendingTransaction, ok := miningManager.SpendingTx(&chain[0].Inputs[0].PreviousOutpoint)
+		if !ok || !consensushashing.TransactionID(spendingTransaction).Equal(parentID) {
+			t.Fatalf("Expected the parent to spend its funding outpoint")
+		}
+		spendingTransaction, ok = miningManager.SpendingTx(externalapi.NewDomainOutpoint(parentID, 0))
+		if !ok || !consensushashing.TransactionID(spendingTransaction).Equal(childID) {
+			t.Fatalf("Expected the child to spend the output of the parent")
+		}
+		_, ok = miningManager.SpendingTx(externalapi.NewDomainOutpoint(grandchildID, 0))
+		if ok {
+			t.Fatalf("Expected the output of the grandchild to be unspent")
+		}
+
+		unspentOutputs, ok := miningManager.OutputsCreatedBy(parentID)
+		if !ok || len(unspentOutputs) != 0 {
+			t.Fatalf("Expected the parent to have no unspent outputs but got %d", len(unspentOutputs))
+		}
+		unspentOutputs, ok = miningManager.OutputsCreatedBy(grandchildID)
+		if !ok || len(unspentOutputs) != len(chain[2].Outputs) {
+			t.Fatalf("Expected all the outputs of the grandchild to be unspent but got %d", len(unspentOutputs))
+		}
+		if !unspentOutputs[0].Outpoint.Equal(externalapi.NewDomainOutpoint(grandchildID, 0)) ||
+			unspentOutputs[0].UTXOEntry.Amount() != chain[2].Outputs[0].Value {
+			t.Fatalf("Unexpected unspent output %s of the grandchild", unspentOutputs[0].Outpoint)
+		}
+		_, ok = miningManager.OutputsCreatedBy(&externalapi.DomainTransactionID{})
+		if ok {
+			t.Fatalf("Expected no outputs for a transaction that isn't in the mempool")
+		}
+	})
+}
+
+// TestMempoolPersistence verifies that the saved transactions of the mempool are loaded
+// in order, and that the ones that became invalid in the meantime are dropped
+func TestMempoolPersistence(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestMempoolPersistence")
+		if err != nil {
+			t.Fatalf("Failed setting up TestConsensus: %+v", err)
+		}
+		defer teardown(false)
+
+		miningFactory := miningmanager.NewFactory()
+		tcAsConsensus := tc.(externalapi.Consensus)
+		tcAsConsensusPointer := &tcAsConsensus
+		consensusReference := consensusreference.NewConsensusReference(&tcAsConsensusPointer)
+		miningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params,
+			mempool.DefaultConfig(&consensusConfig.Params))
+
+		const chainSize = 5
+		chain, err := createTxChain(tc, chainSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, transaction := range chain {
+			_, err = miningManager.ValidateAndInsertTransaction(transaction, i == 1, false)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertTransaction: %+v", err)
+			}
+		}
+
+		path := filepath.Join(t.TempDir(), "mempool.dat")
+		savedCount, err := miningManager.SaveMempoolToFile(path)
+		if err != nil {
+			t.Fatalf("SaveMempoolToFile: %+v", err)
+		}
+		if savedCount != chainSize {
+			t.Fatalf("expected %d saved transactions, but got %d", chainSize, savedCount)
+		}
+
+		// The first transaction of the chain is included in a block while the node is down
+		_, _, err = tc.AddBlockOnTips(nil, []*externalapi.DomainTransaction{chain[0].Clone()})
+		if err != nil {
+			t.Fatalf("AddBlockOnTips: %+v", err)
+		}
+
+		restartedMiningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params,
+			mempool.DefaultConfig(&consensusConfig.Params))
+		acceptedCount, rejectedCount, err := restartedMiningManager.LoadMempoolFromFile(path)
+		if err != nil {
+			t.Fatalf("LoadMempoolFromFile: %+v", err)
+		}
+		if acceptedCount != chainSize-1 || rejectedCount != 1 {
+			t.Fatalf("expected %d accepted and 1 rejected transactions, but got %d and %d",
+				chainSize-1, acceptedCount, rejectedCount)
+		}
+		// The UTXO entries of the first transaction's outputs now come from consensus, so
+		// the transactions are compared by their IDs
+		for _, transaction := range chain[1:] {
+			_, _, found := restartedMiningManager.GetTransaction(consensushashing.TransactionID(transaction), true, false)
+			if !found {
+				t.Fatalf("Missing transaction %s in the mempool", consensushashing.TransactionID(transaction))
+			}
+		}
+
+		// The priority of the transactions is kept
+		revalidated, err := restartedMiningManager.RevalidateHighPriorityTransactions()
+		if err != nil {
+			t.Fatalf("RevalidateHighPriorityTransactions: %+v", err)
+		}
+		if len(revalidated) != 1 ||
+			!consensushashing.TransactionID(revalidated[0]).Equal(consensushashing.TransactionID(chain[1])) {
+
+			t.Fatalf("expected only the second transaction of the chain to be high priority, but got %d "+
+				"transactions", len(revalidated))
+		}
+
+		// A missing file is an empty mempool
+		acceptedCount, rejectedCount, err = restartedMiningManager.LoadMempoolFromFile(path + ".missing")
+		if err != nil || acceptedCount != 0 || rejectedCount != 0 {
+			t.Fatalf("expected a missing file to be ignored, but got %d, %d, %+v", acceptedCount, rejectedCount, err)
+		}
+	})
+}
+
+// TestReadmitUnacceptedTransactions verifies that the transactions of a block that is never
+// accepted are re-admitted into the mempool, while the ones accepted by the virtual aren't
+func TestReadmitUnacceptedTransactions(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestReadmitUnacceptedTransactions")
+		if err != nil {
+			t.Fatalf("Failed setting up TestConsensus: %+v", err)
+		}
+		defer teardown(false)
+
+		miningFactory := miningmanager.NewFactory()
+		mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
+		mempoolConfig.MinedTransactionExpireIntervalDAAScore = 1
+		tcAsConsensus := tc.(externalapi.Consensus)
+		tcAsConsensusPointer := &tcAsConsensus
+		consensusReference := consensusreference.NewConsensusReference(&tcAsConsensusPointer)
+		miningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params, mempoolConfig)
+
+		chain, err := createTxChain(tc, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, transaction := range chain {
+			_, err = miningManager.ValidateAndInsertTransaction(transaction, false, false)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertTransaction: %+v", err)
+			}
+		}
+
+		addBlockAndHandleChainChanges := func(transactions []*externalapi.DomainTransaction) (
+			*externalapi.DomainBlock, []*externalapi.DomainTransaction) {
+
+			blockHash, virtualChangeSet, err := tc.AddBlockOnTips(nil, transactions)
+			if err != nil {
+				t.Fatalf("AddBlockOnTips: %+v", err)
+			}
+			block, _, err := tc.GetBlock(blockHash)
+			if err != nil {
+				t.Fatalf("GetBlock: %+v", err)
+			}
+			readmittedTransactions, err := miningManager.HandleSelectedChainChanges(
+				virtualChangeSet.VirtualSelectedParentChainChanges)
+			if err != nil {
+				t.Fatalf("HandleSelectedChainChanges: %+v", err)
+			}
+			return block, readmittedTransactions
+		}
+
+		// The transactions are included in a block that never makes it into the DAG, so
+		// they're re-admitted once the block could no longer be merged
+		_, err = miningManager.HandleNewBlockTransactions(
+			[]*externalapi.DomainTransaction{nil, chain[0].Clone(), chain[1].Clone()})
+		if err != nil {
+			t.Fatalf("HandleNewBlockTransactions: %+v", err)
+		}
+		if miningManager.TransactionCount(true, false) != 0 {
+			t.Fatalf("expected the mined transactions to be removed from the mempool")
+		}
+		var readmittedTransactions []*externalapi.DomainTransaction
+		for i := 0; i < 2; i++ {
+			_, readmittedByBlock := addBlockAndHandleChainChanges(nil)
+			readmittedTransactions = append(readmittedTransactions, readmittedByBlock...)
+		}
+		if len(readmittedTransactions) != len(chain) {
+			t.Fatalf("expected %d re-admitted transactions, but got %d", len(chain), len(readmittedTransactions))
+		}
+		for _, transaction := range chain {
+			_, _, found := miningManager.GetTransaction(consensushashing.TransactionID(transaction), true, false)
+			if !found {
+				t.Fatalf("Missing re-admitted transaction %s in the mempool", consensushashing.TransactionID(transaction))
+			}
+		}
+
+		// Once blocks that are accepted include them, they're forgotten
+		readmittedTransactions = nil
+		for _, transaction := range chain {
+			block, readmittedByBlock := addBlockAndHandleChainChanges(
+				[]*externalapi.DomainTransaction{transaction.Clone()})
+			_, err = miningManager.HandleNewBlockTransactions(block.Transactions)
+			if err != nil {
+				t.Fatalf("HandleNewBlockTransactions: %+v", err)
+			}
+			readmittedTransactions = append(readmittedTransactions, readmittedByBlock...)
+		}
+		for i := 0; i < 3; i++ {
+			_, readmittedByBlock := addBlockAndHandleChainChanges(nil)
+			readmittedTransactions = append(readmittedTransactions, readmittedByBlock...)
+		}
+		if len(readmittedTransactions) != 0 {
+			t.Fatalf("expected no re-admitted transactions, but got %d", len(readmittedTransactions))
+		}
+		if miningManager.TransactionCount(true, false) != 0 {
+			t.Fatalf("expected the accepted transactions to stay out of the mempool")
+		}
+	})
+}
+
 // TestModifyBlockTemplate verifies that modifying a block template changes coinbase data correctly.
 func TestModifyBlockTemplate(t *testing.T) {
 	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
@@ -807,6 +1383,87 @@ func TestModifyBlockTemplate(t *testing.T) {
 	})
 }
 
+// TestGetBlockTemplateWithOptions verifies that the transactions of a block template follow the options it was asked for with.
+func TestGetBlockTemplateWithOptions(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestGetBlockTemplateWithOptions")
+		if err != nil {
+			t.Fatalf("Error setting up TestConsensus: %+v", err)
+		}
+		defer teardown(false)
+
+		miningFactory := miningmanager.NewFactory()
+		tcAsConsensus := tc.(externalapi.Consensus)
+		tcAsConsensusPointer := &tcAsConsensus
+		consensusReference := consensusreference.NewConsensusReference(&tcAsConsensusPointer)
+		miningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params, mempool.DefaultConfig(&consensusConfig.Params))
+
+		transactions, _, err := createArraysOfParentAndChildrenTransactions(tc)
+		if err != nil {
+			t.Fatalf("Error in createArraysOfParentAndChildrenTransactions: %v", err)
+		}
+		transactionIDs := make([]*externalapi.DomainTransactionID, len(transactions))
+		for i, transaction := range transactions {
+			_, err = miningManager.ValidateAndInsertTransaction(transaction, false, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertTransaction: %v", err)
+			}
+			transactionIDs[i] = consensushashing.TransactionID(transaction)
+		}
+
+		emptyCoinbaseData := &externalapi.DomainCoinbaseData{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{Script: nil, Version: 0},
+			ExtraData:       nil}
+		templateTransactionIDs := func(options *model.BlockTemplateOptions) map[externalapi.DomainTransactionID]struct{} {
+			block, _, err := miningManager.GetBlockTemplateWithOptions(emptyCoinbaseData, options)
+			if err != nil {
+				t.Fatalf("GetBlockTemplateWithOptions: %+v", err)
+			}
+			ids := make(map[externalapi.DomainTransactionID]struct{})
+			for _, transaction := range block.Transactions[transactionhelper.CoinbaseTransactionIndex+1:] {
+				ids[*consensushashing.TransactionID(transaction)] = struct{}{}
+			}
+			return ids
+		}
+
+		ids := templateTransactionIDs(&model.BlockTemplateOptions{
+			ExcludedTransactionIDs: transactionIDs[:2],
+			IncludedTransactionIDs: transactionIDs[2:3],
+		})
+		for _, excludedID := range transactionIDs[:2] {
+			if _, ok := ids[*excludedID]; ok {
+				t.Fatalf("The excluded transaction %s is in the block template", excludedID)
+			}
+		}
+		for _, otherID := range transactionIDs[2:] {
+			if _, ok := ids[*otherID]; !ok {
+				t.Fatalf("The transaction %s is missing from the block template", otherID)
+			}
+		}
+
+		ids = templateTransactionIDs(&model.BlockTemplateOptions{MaxMass: 1})
+		if len(ids) != 0 {
+			t.Fatalf("Expected a block template without transactions, but got %d transactions", len(ids))
+		}
+
+		unknownID := externalapi.DomainTransactionID{}
+		invalidOptions := []*model.BlockTemplateOptions{
+			{IncludedTransactionIDs: []*externalapi.DomainTransactionID{&unknownID}},
+			{ExcludedTransactionIDs: transactionIDs[:1], IncludedTransactionIDs: transactionIDs[:1]},
+			{IncludedTransactionIDs: transactionIDs[:1], MaxMass: 1},
+			{MaxMass: consensusConfig.MaxBlockMass + 1},
+		}
+		for i, options := range invalidOptions {
+			_, _, err = miningManager.GetBlockTemplateWithOptions(emptyCoinbaseData, options)
+			if !errors.Is(err, model.ErrInvalidBlockTemplateOptions) {
+				t.Fatalf("Options %d: expected ErrInvalidBlockTemplateOptions, but got %v", i, err)
+			}
+		}
+	})
+}
+
 func sweepCompareModifiedTemplateToBuilt(
 	t *testing.T, consensusConfig *consensus.Config, builder model.BlockTemplateBuilder) {
 	for i := 0; i < 4; i++ {
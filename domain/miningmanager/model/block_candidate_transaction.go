@@ -0,0 +1,17 @@
+package model
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// BlockCandidateTransaction is a transaction that is ready to be included in a block template,
+// together with the total fee and mass of the highest fee-rate transaction package it belongs to.
+//
+// A block may not contain chained transactions, so the in-pool descendants of a candidate are
+// mined in later blocks. Their fees only serve to raise the selection score of the candidate,
+// allowing a high-fee child to pay for its low-fee parent.
+type BlockCandidateTransaction struct {
+	Transaction *externalapi.DomainTransaction
+	PackageFee  uint64
+	PackageMass uint64
+}
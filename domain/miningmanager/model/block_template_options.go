@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+)
+
+// BlockTemplateOptions lets the caller of GetBlockTemplate apply its own policy to the
+// transactions of the template
+type BlockTemplateOptions struct {
+	// ExcludedTransactionIDs are never included in the template
+	ExcludedTransactionIDs []*externalapi.DomainTransactionID
+
+	// IncludedTransactionIDs are included in the template before any other transaction.
+	// They have to be in the mempool and ready to be mined.
+	IncludedTransactionIDs []*externalapi.DomainTransactionID
+
+	// MaxMass is the maximum mass of the transactions of the template, which may only
+	// be lower than the maximum block mass of the network. 0 keeps the default.
+	MaxMass uint64
+}
+
+// IsEmpty returns whether the options leave the template as it would be without them
+func (o *BlockTemplateOptions) IsEmpty() bool {
+	return o == nil ||
+		(len(o.ExcludedTransactionIDs) == 0 && len(o.IncludedTransactionIDs) == 0 && o.MaxMass == 0)
+}
+
+// ErrInvalidBlockTemplateOptions is returned, wrapped, when a block template can't be
+// built with the given BlockTemplateOptions
+var ErrInvalidBlockTemplateOptions = errors.New("invalid block template options")
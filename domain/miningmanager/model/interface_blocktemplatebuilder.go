@@ -7,6 +7,8 @@ import (
 // BlockTemplateBuilder builds block templates for miners to consume
 type BlockTemplateBuilder interface {
 	BuildBlockTemplate(coinbaseData *consensusexternalapi.DomainCoinbaseData) (*consensusexternalapi.DomainBlockTemplate, error)
+	BuildBlockTemplateWithOptions(coinbaseData *consensusexternalapi.DomainCoinbaseData,
+		options *BlockTemplateOptions) (*consensusexternalapi.DomainBlockTemplate, error)
 	ModifyBlockTemplate(newCoinbaseData *consensusexternalapi.DomainCoinbaseData,
 		blockTemplateToModify *consensusexternalapi.DomainBlockTemplate) (*consensusexternalapi.DomainBlockTemplate, error)
 }
@@ -5,13 +5,31 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
 )
 
+// Tag represents an identifier to use for tagging orphan transactions. The
+// caller may choose any scheme it desires, however it is common to use peer
+// IDs so that orphans can be identified by which peer first relayed them.
+// The orphans of every tag other than UntaggedTag are limited in the memory they may occupy.
+type Tag string
+
+// UntaggedTag is the tag of transactions that weren't submitted by any particular peer
+const UntaggedTag Tag = ""
+
 // Mempool maintains a set of known transactions that
 // are intended to be mined into new blocks
 type Mempool interface {
 	HandleNewBlockTransactions(txs []*externalapi.DomainTransaction) ([]*externalapi.DomainTransaction, error)
-	BlockCandidateTransactions() []*externalapi.DomainTransaction
+	HandleSelectedChainChanges(chainChanges *externalapi.SelectedChainPath) (
+		readmittedTransactions []*externalapi.DomainTransaction, err error)
+	BlockCandidateTransactions() []*BlockCandidateTransaction
 	ValidateAndInsertTransaction(transaction *externalapi.DomainTransaction, isHighPriority bool, allowOrphan bool) (
 		acceptedTransactions []*externalapi.DomainTransaction, err error)
+	ValidateAndInsertTaggedTransaction(transaction *externalapi.DomainTransaction, isHighPriority bool, allowOrphan bool,
+		tag Tag) (acceptedTransactions []*externalapi.DomainTransaction, err error)
+	ValidateTransaction(transaction *externalapi.DomainTransaction) error
+	CheckTransactionForAcceptance(transactions []*externalapi.DomainTransaction) ([]*TransactionAcceptanceCheck, error)
+	ValidateAndInsertPackage(transactions []*externalapi.DomainTransaction, isHighPriority bool) (
+		acceptedTransactions []*externalapi.DomainTransaction, err error)
+	GetRelayPackage(transactionID *externalapi.DomainTransactionID) ([]*externalapi.DomainTransaction, bool)
 	RemoveInvalidTransactions(err *ruleerrors.ErrInvalidTransactionsInNewBlock) error
 	GetTransaction(
 		transactionID *externalapi.DomainTransactionID,
@@ -21,6 +39,9 @@ type Mempool interface {
 		transactionPoolTransaction *externalapi.DomainTransaction,
 		isOrphan bool,
 		found bool)
+	SpendingTx(outpoint *externalapi.DomainOutpoint) (transaction *externalapi.DomainTransaction, found bool)
+	OutputsCreatedBy(transactionID *externalapi.DomainTransactionID) (
+		unspentOutputs []*externalapi.OutpointAndUTXOEntryPair, found bool)
 	GetTransactionsByAddresses(
 		includeTransactionPool bool,
 		includeOrphanPool bool) (
@@ -40,4 +61,8 @@ type Mempool interface {
 		includeOrphanPool bool) int
 	RevalidateHighPriorityTransactions() (validTransactions []*externalapi.DomainTransaction, err error)
 	IsTransactionOutputDust(output *externalapi.DomainTransactionOutput) bool
+	Info() *MempoolInfo
+	SetMaximumMemoryUsage(maximumMemoryUsage uint64) error
+	SaveToFile(path string) (int, error)
+	LoadFromFile(path string) (acceptedCount int, rejectedCount int, err error)
 }
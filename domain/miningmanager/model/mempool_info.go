@@ -0,0 +1,39 @@
+package model
+
+// MempoolInfo describes the size of the mempool and its orphan pool, and the fee rates it accepts
+type MempoolInfo struct {
+	TransactionCount uint64
+
+	// MemoryUsage is the estimated number of bytes the transactions of the transaction
+	// pool occupy, and MaximumMemoryUsage is the limit above which they're evicted
+	MemoryUsage        uint64
+	MaximumMemoryUsage uint64
+
+	// MinimumFeeRate is the fee rate, in sompi per 1000 grams of mass, a transaction has
+	// to pay to be accepted. It's raised above MinimumRelayTransactionFee while the
+	// mempool is full.
+	MinimumFeeRate             uint64
+	MinimumRelayTransactionFee uint64
+
+	Orphans *OrphanPoolInfo
+}
+
+// OrphanPoolInfo describes the size of the orphan pool and how orphans left it
+type OrphanPoolInfo struct {
+	Count              uint64
+	MemoryUsage        uint64
+	MaximumCount       uint64
+	MaximumMemoryUsage uint64
+
+	// MissingOutpointCount is the number of outpoints the orphans are waiting for,
+	// and PeerCount is the number of peers that relayed them
+	MissingOutpointCount uint64
+	PeerCount            uint64
+
+	// The number of orphans that were moved to the mempool once their parents arrived,
+	// that were evicted to keep the orphan pool within its limits, and that expired,
+	// since the node started
+	PromotedCount uint64
+	EvictedCount  uint64
+	ExpiredCount  uint64
+}
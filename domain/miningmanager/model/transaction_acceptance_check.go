@@ -0,0 +1,15 @@
+package model
+
+import "github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+
+// TransactionAcceptanceCheck is the result of checking whether a transaction would be accepted to the mempool
+type TransactionAcceptanceCheck struct {
+	TransactionID *externalapi.DomainTransactionID
+
+	// RejectReason is the rule error the transaction would be rejected with, or nil if it would be accepted
+	RejectReason error
+
+	// Mass is always set, and Fee is set once the inputs of the transaction were found
+	Mass uint64
+	Fee  uint64
+}
@@ -73,7 +73,8 @@ func DeleteInactivePrefix(db database.Database) error {
 	}
 
 	log.Infof("Compacting database after prefix delete")
-	return db.Compact()
+	prefixBucket := database.MakeBucket(prefix.Serialize())
+	return db.Compact(prefixBucket.Path(), prefixBucket.End())
 }
 
 func deletePrefix(dataAccessor database.DataAccessor, prefix *prefix.Prefix) error {
@@ -0,0 +1,82 @@
+package domain
+
+import "github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+
+func (d *domain) Reindex() error {
+	log.Infof("Reindexing the DAG from the stored blocks")
+	pruningPoint, err := d.Consensus().PruningPoint()
+	if err != nil {
+		return err
+	}
+
+	estimatedVirtualDAAScoreTarget, err := d.Consensus().GetVirtualDAAScore()
+	if err != nil {
+		return err
+	}
+
+	err = d.rebuildIntoStagingConsensus(pruningPoint, estimatedVirtualDAAScoreTarget)
+	if err != nil {
+		log.Infof("Reindexing was unsuccessful. Deleting the staging consensus. (%s)", err)
+		deleteStagingConsensusErr := d.DeleteStagingConsensus()
+		if deleteStagingConsensusErr != nil {
+			return deleteStagingConsensusErr
+		}
+		return err
+	}
+
+	err = d.CommitStagingConsensus()
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Done reindexing the DAG")
+	return nil
+}
+
+// rebuildIntoStagingConsensus creates a staging consensus and replays into it the
+// pruning point of the current consensus along with all the blocks in its future
+func (d *domain) rebuildIntoStagingConsensus(pruningPoint *externalapi.DomainHash,
+	estimatedVirtualDAAScoreTarget uint64) error {
+
+	if d.consensusConfig.Params.GenesisHash.Equal(pruningPoint) {
+		err := d.initStagingConsensus(d.consensusConfig)
+		if err != nil {
+			return err
+		}
+
+		err = syncBlocks(d.Consensus(), d.StagingConsensus())
+		if err != nil {
+			return err
+		}
+
+		return resolveVirtual(d.StagingConsensus(), estimatedVirtualDAAScoreTarget)
+	}
+
+	err := d.InitStagingConsensusWithoutGenesis()
+	if err != nil {
+		return err
+	}
+
+	return syncConsensuses(d.Consensus(), d.StagingConsensus())
+}
+
+func (d *domain) ReindexState() error {
+	log.Infof("Rebuilding the UTXO state from the pruning point UTXO set")
+	estimatedVirtualDAAScoreTarget, err := d.Consensus().GetVirtualDAAScore()
+	if err != nil {
+		return err
+	}
+
+	err = d.Consensus().RebuildUTXOState()
+	if err != nil {
+		return err
+	}
+
+	err = resolveVirtual(d.Consensus(), estimatedVirtualDAAScoreTarget)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Done rebuilding the UTXO state")
+	return nil
+}
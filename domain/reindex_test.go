@@ -0,0 +1,151 @@
+package domain_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+)
+
+func TestReindex(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		t.Run("genesis pruning point", func(t *testing.T) {
+			testReindex(t, consensusConfig)
+		})
+
+		// This is done to make a pruning depth of 6 blocks, so the pruning point moves
+		consensusConfig.FinalityDuration = 2 * consensusConfig.TargetTimePerBlock
+		consensusConfig.K = 0
+		t.Run("moved pruning point", func(t *testing.T) {
+			testReindex(t, consensusConfig)
+		})
+	})
+}
+
+func testReindex(t *testing.T, consensusConfig *consensus.Config) {
+	dataDir, err := ioutil.TempDir("", fmt.Sprintf("TestReindex-%s", consensusConfig.Name))
+	if err != nil {
+		t.Fatalf("ioutil.TempDir: %+v", err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	db, err := ldb.NewLevelDB(dataDir, 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %+v", err)
+	}
+	defer db.Close()
+
+	domainInstance, err := domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), db)
+	if err != nil {
+		t.Fatalf("New: %+v", err)
+	}
+
+	// Every round adds two parallel blocks, which are both merged by the blocks of the next round
+	scriptPublicKey, _ := testutils.OpTrueScript()
+	for i := 0; i < 20; i++ {
+		var blocks []*externalapi.DomainBlock
+		for j := 0; j < 2; j++ {
+			coinbaseData := &externalapi.DomainCoinbaseData{
+				ScriptPublicKey: scriptPublicKey,
+				ExtraData:       []byte{byte(j)},
+			}
+			block, err := domainInstance.Consensus().BuildBlock(coinbaseData, nil)
+			if err != nil {
+				t.Fatalf("BuildBlock: %+v", err)
+			}
+			blocks = append(blocks, block)
+		}
+		for _, block := range blocks {
+			err := domainInstance.Consensus().ValidateAndInsertBlock(block, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+		}
+	}
+
+	expectedState := dagState(t, domainInstance.Consensus())
+
+	err = domainInstance.ReindexState()
+	if err != nil {
+		t.Fatalf("ReindexState: %+v", err)
+	}
+	compareDAGStates(t, "ReindexState", expectedState, dagState(t, domainInstance.Consensus()))
+
+	err = domainInstance.Reindex()
+	if err != nil {
+		t.Fatalf("Reindex: %+v", err)
+	}
+	compareDAGStates(t, "Reindex", expectedState, dagState(t, domainInstance.Consensus()))
+}
+
+type testDAGState struct {
+	pruningPoint                  *externalapi.DomainHash
+	virtualInfo                   *externalapi.VirtualInfo
+	virtualUTXOs                  []*externalapi.OutpointAndUTXOEntryPair
+	virtualSelectedParent         *externalapi.DomainHash
+	virtualSelectedParentAccepted externalapi.AcceptanceData
+}
+
+func dagState(t *testing.T, consensus externalapi.Consensus) *testDAGState {
+	pruningPoint, err := consensus.PruningPoint()
+	if err != nil {
+		t.Fatalf("PruningPoint: %+v", err)
+	}
+	virtualInfo, err := consensus.GetVirtualInfo()
+	if err != nil {
+		t.Fatalf("GetVirtualInfo: %+v", err)
+	}
+	virtualUTXOs, err := consensus.GetVirtualUTXOs(virtualInfo.ParentHashes, nil, 100_000)
+	if err != nil {
+		t.Fatalf("GetVirtualUTXOs: %+v", err)
+	}
+	virtualSelectedParent, err := consensus.GetVirtualSelectedParent()
+	if err != nil {
+		t.Fatalf("GetVirtualSelectedParent: %+v", err)
+	}
+	acceptanceData, err := consensus.GetBlockAcceptanceData(virtualSelectedParent)
+	if err != nil {
+		t.Fatalf("GetBlockAcceptanceData: %+v", err)
+	}
+	return &testDAGState{
+		pruningPoint:                  pruningPoint,
+		virtualInfo:                   virtualInfo,
+		virtualUTXOs:                  virtualUTXOs,
+		virtualSelectedParent:         virtualSelectedParent,
+		virtualSelectedParentAccepted: acceptanceData,
+	}
+}
+
+func compareDAGStates(t *testing.T, name string, expected, actual *testDAGState) {
+	if !expected.pruningPoint.Equal(actual.pruningPoint) {
+		t.Fatalf("%s: expected pruning point %s but got %s", name, expected.pruningPoint, actual.pruningPoint)
+	}
+	if !externalapi.HashesEqual(expected.virtualInfo.ParentHashes, actual.virtualInfo.ParentHashes) ||
+		expected.virtualInfo.DAAScore != actual.virtualInfo.DAAScore ||
+		expected.virtualInfo.BlueScore != actual.virtualInfo.BlueScore {
+		t.Fatalf("%s: expected virtual %+v but got %+v", name, expected.virtualInfo, actual.virtualInfo)
+	}
+	if !expected.virtualSelectedParent.Equal(actual.virtualSelectedParent) {
+		t.Fatalf("%s: expected virtual selected parent %s but got %s",
+			name, expected.virtualSelectedParent, actual.virtualSelectedParent)
+	}
+	if !expected.virtualSelectedParentAccepted.Equal(actual.virtualSelectedParentAccepted) {
+		t.Fatalf("%s: the acceptance data of the virtual selected parent changed", name)
+	}
+	if len(expected.virtualUTXOs) != len(actual.virtualUTXOs) {
+		t.Fatalf("%s: expected %d virtual UTXOs but got %d", name, len(expected.virtualUTXOs), len(actual.virtualUTXOs))
+	}
+	for i, expectedPair := range expected.virtualUTXOs {
+		actualPair := actual.virtualUTXOs[i]
+		if !expectedPair.Outpoint.Equal(actualPair.Outpoint) || !expectedPair.UTXOEntry.Equal(actualPair.UTXOEntry) {
+			t.Fatalf("%s: expected virtual UTXO %s but got %s", name, expectedPair.Outpoint, actualPair.Outpoint)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package txindex
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/panics"
+)
+
+var log = logger.RegisterSubSystem("TXIN")
+var spawn = panics.GoroutineWrapperFunc(log)
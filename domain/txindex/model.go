@@ -0,0 +1,12 @@
+package txindex
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// TransactionLocation is the location of a transaction in the DAG: a block
+// that contains it, and the index of the transaction within that block
+type TransactionLocation struct {
+	BlockHash *externalapi.DomainHash
+	Index     uint32
+}
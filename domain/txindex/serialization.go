@@ -0,0 +1,31 @@
+package txindex
+
+import (
+	"encoding/binary"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+)
+
+const serializedTransactionLocationSize = externalapi.DomainHashSize + 4
+
+func serializeTransactionLocation(location *TransactionLocation) []byte {
+	serializedLocation := make([]byte, serializedTransactionLocationSize)
+	copy(serializedLocation, location.BlockHash.ByteSlice())
+	binary.LittleEndian.PutUint32(serializedLocation[externalapi.DomainHashSize:], location.Index)
+	return serializedLocation
+}
+
+func deserializeTransactionLocation(serializedLocation []byte) (*TransactionLocation, error) {
+	if len(serializedLocation) != serializedTransactionLocationSize {
+		return nil, errors.Errorf("invalid transaction location length %d", len(serializedLocation))
+	}
+	blockHash, err := externalapi.NewDomainHashFromByteSlice(serializedLocation[:externalapi.DomainHashSize])
+	if err != nil {
+		return nil, err
+	}
+	return &TransactionLocation{
+		BlockHash: blockHash,
+		Index:     binary.LittleEndian.Uint32(serializedLocation[externalapi.DomainHashSize:]),
+	}, nil
+}
@@ -0,0 +1,26 @@
+package txindex
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+func TestTransactionLocationSerialization(t *testing.T) {
+	location := &TransactionLocation{
+		BlockHash: externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{1, 2, 3}),
+		Index:     0x01020304,
+	}
+	deserializedLocation, err := deserializeTransactionLocation(serializeTransactionLocation(location))
+	if err != nil {
+		t.Fatalf("deserializeTransactionLocation: %s", err)
+	}
+	if !deserializedLocation.BlockHash.Equal(location.BlockHash) || deserializedLocation.Index != location.Index {
+		t.Fatalf("Expected %+v but got %+v", location, deserializedLocation)
+	}
+
+	_, err = deserializeTransactionLocation(make([]byte, serializedTransactionLocationSize-1))
+	if err == nil {
+		t.Fatalf("Expected an error deserializing a truncated transaction location")
+	}
+}
@@ -0,0 +1,259 @@
+package txindex
+
+import (
+	"encoding/binary"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/intentlog"
+	"github.com/pkg/errors"
+)
+
+var txIndexBucket = database.MakeBucket([]byte("tx-index"))
+var transactionLocationsBucket = txIndexBucket.Bucket([]byte("transaction-locations"))
+var blockTransactionsBucket = txIndexBucket.Bucket([]byte("block-transactions"))
+var pruningPointKey = txIndexBucket.Key([]byte("pruning-point"))
+var versionKey = txIndexBucket.Key([]byte("version"))
+var reindexedKey = database.MakeBucket([]byte("")).Key([]byte("tx-index-reindexed"))
+
+type txIndexStore struct {
+	database database.Database
+
+	// blockAddedIntentLog, if set, holds the intents of the blocks that
+	// were inserted into consensus and weren't indexed yet
+	blockAddedIntentLog *intentlog.IntentLog
+}
+
+func newTXIndexStore(database database.Database) *txIndexStore {
+	return &txIndexStore{database: database}
+}
+
+// getTransactionLocations returns the locations of the transaction of the given ID
+// in all the indexed blocks that contain it
+func (tis *txIndexStore) getTransactionLocations(transactionID *externalapi.DomainTransactionID) (
+	[]*TransactionLocation, error) {
+
+	cursor, err := tis.database.Cursor(transactionLocationsBucket.Bucket(transactionID.ByteSlice()))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	var locations []*TransactionLocation
+	for cursor.Next() {
+		serializedLocation, err := cursor.Value()
+		if err != nil {
+			return nil, err
+		}
+		location, err := deserializeTransactionLocation(serializedLocation)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+// transactionLocationKey keys the locations of a transaction by their blocks, so that
+// the locations of a transaction that is contained in several blocks are all kept
+func transactionLocationKey(transactionID []byte, blockHash *externalapi.DomainHash) *database.Key {
+	return transactionLocationsBucket.Bucket(transactionID).Key(blockHash.ByteSlice())
+}
+
+// blockTransactionsKey orders the blocks by blue score, so that the blocks
+// below the pruning point may be found without walking the whole index
+func blockTransactionsKey(blueScore uint64, blockHash *externalapi.DomainHash) *database.Key {
+	keyBytes := make([]byte, 8+externalapi.DomainHashSize)
+	binary.BigEndian.PutUint64(keyBytes, blueScore)
+	copy(keyBytes[8:], blockHash.ByteSlice())
+	return blockTransactionsBucket.Key(keyBytes)
+}
+
+// putBlockTransactions stores the locations of all the transactions of the given block, along
+// with the list of its transaction IDs used for pruning, in a single database transaction,
+// so that a block is never partially indexed. The BlockAdded intent of the block is completed
+// in the same database transaction.
+func (tis *txIndexStore) putBlockTransactions(blockHash *externalapi.DomainHash, block *externalapi.DomainBlock) error {
+	dbTransaction, err := tis.database.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTransaction.RollbackUnlessClosed()
+
+	transactionIDs := make([]byte, 0, len(block.Transactions)*externalapi.DomainHashSize)
+	for i, transaction := range block.Transactions {
+		transactionID := consensushashing.TransactionID(transaction)
+		location := &TransactionLocation{BlockHash: blockHash, Index: uint32(i)}
+		err := dbTransaction.Put(transactionLocationKey(transactionID.ByteSlice(), blockHash),
+			serializeTransactionLocation(location))
+		if err != nil {
+			return err
+		}
+		transactionIDs = append(transactionIDs, transactionID.ByteSlice()...)
+	}
+	err = dbTransaction.Put(blockTransactionsKey(block.Header.BlueScore(), blockHash), transactionIDs)
+	if err != nil {
+		return err
+	}
+	if tis.blockAddedIntentLog != nil {
+		err = tis.blockAddedIntentLog.CompleteIn(dbTransaction, blockHash.ByteSlice())
+		if err != nil {
+			return err
+		}
+	}
+	return dbTransaction.Commit()
+}
+
+// deleteBlockTransactionsBelow deletes the transactions of the indexed blocks whose blue score
+// is lower than the given one and that isPruned reports. A transaction that is also located
+// in another block keeps its other location. Each block is deleted in its own database transaction.
+func (tis *txIndexStore) deleteBlockTransactionsBelow(blueScore uint64,
+	isPruned func(blockHash *externalapi.DomainHash) (bool, error)) (deletedBlockCount int, err error) {
+
+	cursor, err := tis.database.Cursor(blockTransactionsBucket)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close()
+	for cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			return 0, err
+		}
+		keySuffix := key.Suffix()
+		if len(keySuffix) != 8+externalapi.DomainHashSize {
+			return 0, errors.Errorf("invalid block transactions key length %d", len(keySuffix))
+		}
+		if binary.BigEndian.Uint64(keySuffix[:8]) >= blueScore {
+			break
+		}
+		blockHash, err := externalapi.NewDomainHashFromByteSlice(keySuffix[8:])
+		if err != nil {
+			return 0, err
+		}
+		pruned, err := isPruned(blockHash)
+		if err != nil {
+			return 0, err
+		}
+		if !pruned {
+			continue
+		}
+		transactionIDs, err := cursor.Value()
+		if err != nil {
+			return 0, err
+		}
+		err = tis.deleteBlockTransactions(key, blockHash, transactionIDs)
+		if err != nil {
+			return 0, err
+		}
+		deletedBlockCount++
+	}
+	return deletedBlockCount, nil
+}
+
+func (tis *txIndexStore) deleteBlockTransactions(blockTransactionsKey *database.Key,
+	blockHash *externalapi.DomainHash, transactionIDs []byte) error {
+
+	if len(transactionIDs)%externalapi.DomainHashSize != 0 {
+		return errors.Errorf("invalid transaction IDs length %d of block %s", len(transactionIDs), blockHash)
+	}
+
+	dbTransaction, err := tis.database.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTransaction.RollbackUnlessClosed()
+
+	for start := 0; start < len(transactionIDs); start += externalapi.DomainHashSize {
+		err := dbTransaction.Delete(transactionLocationKey(transactionIDs[start:start+externalapi.DomainHashSize], blockHash))
+		if err != nil {
+			return err
+		}
+	}
+	err = dbTransaction.Delete(blockTransactionsKey)
+	if err != nil {
+		return err
+	}
+	return dbTransaction.Commit()
+}
+
+func (tis *txIndexStore) getPruningPoint() (*externalapi.DomainHash, bool, error) {
+	pruningPointBytes, err := tis.database.Get(pruningPointKey)
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	pruningPoint, err := externalapi.NewDomainHashFromByteSlice(pruningPointBytes)
+	if err != nil {
+		return nil, false, err
+	}
+	return pruningPoint, true, nil
+}
+
+func (tis *txIndexStore) putPruningPoint(pruningPoint *externalapi.DomainHash) error {
+	return tis.database.Put(pruningPointKey, pruningPoint.ByteSlice())
+}
+
+// currentVersion is the version of the layout of the index. Indexes of older versions
+// are rebuilt from scratch.
+//
+// Version 1 keeps the locations of a transaction in all the blocks that contain it.
+const currentVersion = 1
+
+func (tis *txIndexStore) getVersion() (uint32, error) {
+	versionBytes, err := tis.database.Get(versionKey)
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if len(versionBytes) != 4 {
+		return 0, errors.Errorf("invalid version length %d", len(versionBytes))
+	}
+	return binary.LittleEndian.Uint32(versionBytes), nil
+}
+
+func (tis *txIndexStore) putVersion(version uint32) error {
+	versionBytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(versionBytes, version)
+	return tis.database.Put(versionKey, versionBytes)
+}
+
+func (tis *txIndexStore) isReindexed() (bool, error) {
+	return tis.database.Has(reindexedKey)
+}
+
+func (tis *txIndexStore) setReindexed() error {
+	return tis.database.Put(reindexedKey, []byte{})
+}
+
+func (tis *txIndexStore) deleteAll() error {
+	// First we delete the reindexed marker, so if anything goes wrong,
+	// the index will be reindexed again on the next run.
+	err := tis.database.Delete(reindexedKey)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := tis.database.Cursor(txIndexBucket)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+	for cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+
+		err = tis.database.Delete(key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package txindex
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/blockheader"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+)
+
+func newTestBlock(blueScore uint64, transactions ...*externalapi.DomainTransaction) *externalapi.DomainBlock {
+	header := blockheader.NewImmutableBlockHeader(0, nil, &externalapi.DomainHash{}, &externalapi.DomainHash{},
+		&externalapi.DomainHash{}, int64(blueScore), 0, 0, 0, blueScore, big.NewInt(0), &externalapi.DomainHash{})
+	return &externalapi.DomainBlock{Header: header, Transactions: transactions}
+}
+
+func newTestTransaction(lockTime uint64) *externalapi.DomainTransaction {
+	return &externalapi.DomainTransaction{
+		Inputs:   []*externalapi.DomainTransactionInput{},
+		Outputs:  []*externalapi.DomainTransactionOutput{},
+		LockTime: lockTime,
+	}
+}
+
+func TestDeleteBlockTransactionsBelow(t *testing.T) {
+	database, err := ldb.NewLevelDB(t.TempDir(), 8)
+	if err != nil {
+		t.Fatalf("could not create a database: %s", err)
+	}
+	defer database.Close()
+	store := newTXIndexStore(database)
+
+	prunedTransaction := newTestTransaction(0)
+	sharedTransaction := newTestTransaction(1)
+	anticoneTransaction := newTestTransaction(2)
+	futureTransaction := newTestTransaction(3)
+
+	prunedBlock := newTestBlock(1, prunedTransaction, sharedTransaction)
+	anticoneBlock := newTestBlock(2, anticoneTransaction)
+	otherBlock := newTestBlock(7, sharedTransaction)
+	futureBlock := newTestBlock(10, futureTransaction)
+	for _, block := range []*externalapi.DomainBlock{prunedBlock, anticoneBlock, otherBlock, futureBlock} {
+		err := store.putBlockTransactions(consensushashing.BlockHash(block), block)
+		if err != nil {
+			t.Fatalf("putBlockTransactions: %s", err)
+		}
+	}
+
+	sharedTransactionLocations, err := store.getTransactionLocations(consensushashing.TransactionID(sharedTransaction))
+	if err != nil {
+		t.Fatalf("getTransactionLocations: %s", err)
+	}
+	if len(sharedTransactionLocations) != 2 {
+		t.Fatalf("expected a transaction that is contained in 2 blocks to be located in both, "+
+			"but got %d locations", len(sharedTransactionLocations))
+	}
+
+	anticoneBlockHash := consensushashing.BlockHash(anticoneBlock)
+	otherBlockHash := consensushashing.BlockHash(otherBlock)
+	futureBlockHash := consensushashing.BlockHash(futureBlock)
+	deletedBlockCount, err := store.deleteBlockTransactionsBelow(5, func(blockHash *externalapi.DomainHash) (bool, error) {
+		if blockHash.Equal(otherBlockHash) || blockHash.Equal(futureBlockHash) {
+			t.Fatalf("a block above the pruning point was checked")
+		}
+		return !blockHash.Equal(anticoneBlockHash), nil
+	})
+	if err != nil {
+		t.Fatalf("deleteBlockTransactionsBelow: %s", err)
+	}
+	if deletedBlockCount != 1 {
+		t.Fatalf("expected 1 deleted block but got %d", deletedBlockCount)
+	}
+
+	tests := []struct {
+		name                  string
+		transaction           *externalapi.DomainTransaction
+		expectedLocationBlock *externalapi.DomainBlock
+	}{
+		{name: "pruned", transaction: prunedTransaction},
+		{name: "also in another block", transaction: sharedTransaction, expectedLocationBlock: otherBlock},
+		{name: "anticone", transaction: anticoneTransaction, expectedLocationBlock: anticoneBlock},
+		{name: "future", transaction: futureTransaction, expectedLocationBlock: futureBlock},
+	}
+	for _, test := range tests {
+		locations, err := store.getTransactionLocations(consensushashing.TransactionID(test.transaction))
+		if err != nil {
+			t.Fatalf("%s: getTransactionLocations: %s", test.name, err)
+		}
+		if test.expectedLocationBlock == nil {
+			if len(locations) != 0 {
+				t.Fatalf("%s: expected the transaction to be deleted", test.name)
+			}
+			continue
+		}
+		if len(locations) != 1 || !locations[0].BlockHash.Equal(consensushashing.BlockHash(test.expectedLocationBlock)) {
+			t.Fatalf("%s: expected the transaction to remain located in its block only", test.name)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package txindex
+
+import (
+	"sync"
+
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// reindexBatchSize is the maximum amount of blocks requested from
+// consensus at once while reindexing. It must be larger than the
+// mergeset size limit of all networks.
+const reindexBatchSize = 1000
+
+// TXIndex maintains an index between transaction IDs and the locations
+// of the transactions in the DAG, so that any transaction whose block
+// wasn't pruned may be retrieved.
+//
+// The transactions of every block are indexed once the block is added to
+// the DAG. When the index is enabled on an existing node, the blocks that
+// are already in the DAG are indexed in the background, and transactions
+// of blocks that weren't reindexed yet are not found in the meanwhile.
+//
+// A transaction that is contained in several blocks is located in all of them.
+//
+// Whenever the pruning point moves, the transactions of the blocks whose
+// bodies were pruned are deleted from the index.
+type TXIndex struct {
+	domain domain.Domain
+	store  *txIndexStore
+
+	pruningPoint *externalapi.DomainHash
+
+	mutex sync.Mutex
+}
+
+// New creates a new transaction index, and starts reindexing the blocks
+// already in the DAG in the background if that wasn't done yet.
+func New(domain domain.Domain, database database.Database) (*TXIndex, error) {
+	txIndex := &TXIndex{
+		domain: domain,
+		store:  newTXIndexStore(database),
+	}
+
+	// Indexes of older versions don't know which transactions belong to which
+	// block, or only know a single block of each transaction
+	version, err := txIndex.store.getVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version != currentVersion {
+		log.Infof("Resetting the transaction index, since it was built by an older version")
+		err := txIndex.store.deleteAll()
+		if err != nil {
+			return nil, err
+		}
+		err = txIndex.store.putVersion(currentVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	isReindexed, err := txIndex.store.isReindexed()
+	if err != nil {
+		return nil, err
+	}
+	err = txIndex.syncPruningPoint()
+	if err != nil {
+		return nil, err
+	}
+
+	// Blocks that were committed by consensus right before a crash have their
+	// BlockAdded events replayed, so that they're indexed as well
+	txIndex.store.blockAddedIntentLog, err = domain.EnableBlockAddedIntents()
+	if err != nil {
+		return nil, err
+	}
+
+	if !isReindexed {
+		spawn("TXIndex.reindex", func() {
+			err := txIndex.reindex()
+			if err != nil {
+				log.Errorf("Error reindexing the transaction index, it will be resumed on the next run: %+v", err)
+			}
+		})
+	}
+	return txIndex, nil
+}
+
+// Drop deletes the whole transaction index from the given database,
+// so that it's reindexed once it's created again with New.
+func Drop(database database.Database) error {
+	return newTXIndexStore(database).deleteAll()
+}
+
+// Update indexes the transactions of the given block, which was just added to the DAG
+func (ti *TXIndex) Update(block *externalapi.DomainBlock) error {
+	ti.mutex.Lock()
+	defer ti.mutex.Unlock()
+
+	err := ti.syncPruningPoint()
+	if err != nil {
+		return err
+	}
+
+	blockHash := consensushashing.BlockHash(block)
+	err = ti.store.putBlockTransactions(blockHash, block)
+	if err != nil {
+		return err
+	}
+	log.Tracef("Indexed the %d transactions of block %s", len(block.Transactions), blockHash)
+	return nil
+}
+
+// syncPruningPoint deletes the transactions of the blocks that were pruned
+// since the pruning point moved. Blocks in the anticone of the pruning point
+// keep their bodies, so they're only deleted once they're actually pruned.
+func (ti *TXIndex) syncPruningPoint() error {
+	consensus := ti.domain.Consensus()
+	pruningPoint, err := consensus.PruningPoint()
+	if err != nil {
+		return err
+	}
+	if ti.pruningPoint != nil && ti.pruningPoint.Equal(pruningPoint) {
+		return nil
+	}
+
+	pruningPointInfo, err := consensus.GetBlockInfo(pruningPoint)
+	if err != nil {
+		return err
+	}
+	deletedBlockCount, err := ti.store.deleteBlockTransactionsBelow(pruningPointInfo.BlueScore,
+		func(blockHash *externalapi.DomainHash) (bool, error) {
+			_, found, err := consensus.GetBlock(blockHash)
+			return !found, err
+		})
+	if err != nil {
+		return err
+	}
+	if deletedBlockCount > 0 {
+		log.Debugf("Deleted the transactions of %d pruned blocks from the transaction index", deletedBlockCount)
+	}
+
+	err = ti.store.putPruningPoint(pruningPoint)
+	if err != nil {
+		return err
+	}
+	ti.pruningPoint = pruningPoint
+	return nil
+}
+
+// TransactionLocations returns the locations of the transaction of the given ID in
+// all the blocks that contain it. It returns nothing if the transaction was not indexed.
+func (ti *TXIndex) TransactionLocations(transactionID *externalapi.DomainTransactionID) (
+	[]*TransactionLocation, error) {
+
+	ti.mutex.Lock()
+	defer ti.mutex.Unlock()
+
+	return ti.store.getTransactionLocations(transactionID)
+}
+
+// Transaction returns the transaction of the given ID along with the hash of the
+// block it was located in. It returns false if the transaction was not indexed,
+// or if the body of its block was pruned.
+func (ti *TXIndex) Transaction(transactionID *externalapi.DomainTransactionID) (
+	*externalapi.DomainTransaction, *externalapi.DomainHash, bool, error) {
+
+	locations, err := ti.TransactionLocations(transactionID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	for _, location := range locations {
+		block, found, err := ti.domain.Consensus().GetBlock(location.BlockHash)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if !found {
+			continue
+		}
+		if int(location.Index) >= len(block.Transactions) {
+			return nil, nil, false, errors.Errorf("transaction %s is located at index %d of block %s "+
+				"which only has %d transactions", transactionID, location.Index, location.BlockHash, len(block.Transactions))
+		}
+		return block.Transactions[location.Index], location.BlockHash, true, nil
+	}
+	return nil, nil, false, nil
+}
+
+// ConfirmationsByBlueScore returns the number of confirmations of the transaction of the given ID
+// along with the hash of the chain block that accepted it. The confirmations are the difference
+// between the blue score of the virtual and the blue score of the accepting block, since unlike the
+// depth of a block in a DAG with many tips, the blue score grows steadily with the work on top of it.
+//
+// A transaction that is contained in several blocks is accepted along with only one of them, so
+// the chain blocks that accept each of them are checked. It returns false if the transaction was
+// not indexed, or if it's not accepted by the virtual selected parent chain yet.
+func (ti *TXIndex) ConfirmationsByBlueScore(transactionID *externalapi.DomainTransactionID) (
+	confirmations uint64, acceptingBlockHash *externalapi.DomainHash, found bool, err error) {
+
+	locations, err := ti.TransactionLocations(transactionID)
+	if err != nil {
+		return 0, nil, false, err
+	}
+
+	consensus := ti.domain.Consensus()
+	for _, location := range locations {
+		acceptingBlockHash, found, err := consensus.GetAcceptingBlock(location.BlockHash)
+		if err != nil {
+			return 0, nil, false, err
+		}
+		if !found {
+			continue
+		}
+
+		acceptanceData, err := consensus.GetBlockAcceptanceData(acceptingBlockHash)
+		if err != nil {
+			return 0, nil, false, err
+		}
+		if !isTransactionAccepted(acceptanceData, location.BlockHash, transactionID) {
+			continue
+		}
+
+		acceptingBlockInfo, err := consensus.GetBlockInfo(acceptingBlockHash)
+		if err != nil {
+			return 0, nil, false, err
+		}
+		virtualInfo, err := consensus.GetVirtualInfo()
+		if err != nil {
+			return 0, nil, false, err
+		}
+		return virtualInfo.BlueScore - acceptingBlockInfo.BlueScore, acceptingBlockHash, true, nil
+	}
+	return 0, nil, false, nil
+}
+
+// isTransactionAccepted returns whether the given acceptance data accepts the transaction
+// of the given ID along with the given block
+func isTransactionAccepted(acceptanceData externalapi.AcceptanceData, blockHash *externalapi.DomainHash,
+	transactionID *externalapi.DomainTransactionID) bool {
+
+	for _, blockAcceptanceData := range acceptanceData {
+		if !blockAcceptanceData.BlockHash.Equal(blockHash) {
+			continue
+		}
+		for _, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+			if transactionAcceptanceData.IsAccepted &&
+				consensushashing.TransactionID(transactionAcceptanceData.Transaction).Equal(transactionID) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reindex indexes the transactions of all the blocks in the DAG whose bodies were
+// not pruned: the pruning point and its anticone, and every block in their future.
+func (ti *TXIndex) reindex() error {
+	log.Infof("Reindexing the transaction index in the background")
+
+	consensus := ti.domain.Consensus()
+	pruningPoint, err := consensus.PruningPoint()
+	if err != nil {
+		return err
+	}
+	virtualSelectedParent, err := consensus.GetVirtualSelectedParent()
+	if err != nil {
+		return err
+	}
+
+	blockHashes, err := consensus.PruningPointAndItsAnticone()
+	if err != nil {
+		return err
+	}
+	err = ti.indexBlocks(blockHashes)
+	if err != nil {
+		return err
+	}
+
+	indexedBlockCount := len(blockHashes)
+	for lowHash := pruningPoint; !lowHash.Equal(virtualSelectedParent); {
+		blockHashes, highHash, err := consensus.GetHashesBetween(lowHash, virtualSelectedParent, reindexBatchSize)
+		if err != nil {
+			return err
+		}
+		err = ti.indexBlocks(blockHashes)
+		if err != nil {
+			return err
+		}
+		indexedBlockCount += len(blockHashes)
+		log.Infof("Reindexed the transactions of %d blocks", indexedBlockCount)
+		lowHash = highHash
+	}
+
+	// The blocks that aren't in the past of the virtual selected parent
+	anticone, err := consensus.Anticone(virtualSelectedParent)
+	if err != nil {
+		return err
+	}
+	err = ti.indexBlocks(anticone)
+	if err != nil {
+		return err
+	}
+
+	err = ti.store.setReindexed()
+	if err != nil {
+		return err
+	}
+	log.Infof("Finished reindexing the transaction index")
+	return nil
+}
+
+// indexBlocks indexes the given blocks one at a time, so that blocks that
+// are added to the DAG meanwhile don't wait for the whole batch to be indexed
+func (ti *TXIndex) indexBlocks(blockHashes []*externalapi.DomainHash) error {
+	for _, blockHash := range blockHashes {
+		block, found, err := ti.domain.Consensus().GetBlock(blockHash)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		err = ti.indexBlock(blockHash, block)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ti *TXIndex) indexBlock(blockHash *externalapi.DomainHash, block *externalapi.DomainBlock) error {
+	ti.mutex.Lock()
+	defer ti.mutex.Unlock()
+
+	return ti.store.putBlockTransactions(blockHash, block)
+}
@@ -0,0 +1,145 @@
+package utxoindex
+
+import (
+	"encoding/binary"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/pkg/errors"
+)
+
+// AmountBucket is a range of UTXO amounts used to break down the UTXO statistics
+type AmountBucket byte
+
+// The amount buckets, ordered by their upper bound
+const (
+	AmountBucketDust AmountBucket = iota
+	AmountBucketBelowOneKaspa
+	AmountBucketBelowHundredKaspa
+	AmountBucketBelowTenThousandKaspa
+	AmountBucketTenThousandKaspaAndAbove
+)
+
+// DustAmountThreshold is the amount in sompi under which a UTXO is counted as dust
+const DustAmountThreshold = constants.SompiPerKaspa / 10_000
+
+// amountBucketUpperBounds are the exclusive upper bounds of all buckets but the last
+var amountBucketUpperBounds = []uint64{
+	AmountBucketDust:                  DustAmountThreshold,
+	AmountBucketBelowOneKaspa:         constants.SompiPerKaspa,
+	AmountBucketBelowHundredKaspa:     100 * constants.SompiPerKaspa,
+	AmountBucketBelowTenThousandKaspa: 10_000 * constants.SompiPerKaspa,
+}
+
+var amountBucketToName = []string{
+	AmountBucketDust:                     "dust",
+	AmountBucketBelowOneKaspa:            "below1KAS",
+	AmountBucketBelowHundredKaspa:        "below100KAS",
+	AmountBucketBelowTenThousandKaspa:    "below10000KAS",
+	AmountBucketTenThousandKaspaAndAbove: "10000KASAndAbove",
+}
+
+const (
+	numScriptClasses = int(txscript.ScriptHashTy) + 1
+	numAmountBuckets = int(AmountBucketTenThousandKaspaAndAbove) + 1
+)
+
+// String returns a human-readable name of the amount bucket
+func (b AmountBucket) String() string {
+	if int(b) >= len(amountBucketToName) {
+		return "Invalid"
+	}
+	return amountBucketToName[b]
+}
+
+// AmountBucketOf returns the bucket the given amount falls in
+func AmountBucketOf(amount uint64) AmountBucket {
+	for bucket, upperBound := range amountBucketUpperBounds {
+		if amount < upperBound {
+			return AmountBucket(bucket)
+		}
+	}
+	return AmountBucketTenThousandKaspaAndAbove
+}
+
+// UTXOStatisticsEntry is the number and total value of a subset of the UTXO set
+type UTXOStatisticsEntry struct {
+	Count uint64
+	Sompi uint64
+}
+
+// UTXOStatistics is a breakdown of the UTXO set by script class and by amount bucket
+type UTXOStatistics struct {
+	ByScriptClass  [numScriptClasses]UTXOStatisticsEntry
+	ByAmountBucket [numAmountBuckets]UTXOStatisticsEntry
+}
+
+// Total returns the number and total value of all the UTXOs in the UTXO set
+func (s *UTXOStatistics) Total() UTXOStatisticsEntry {
+	total := UTXOStatisticsEntry{}
+	for _, entry := range s.ByScriptClass {
+		total.Count += entry.Count
+		total.Sompi += entry.Sompi
+	}
+	return total
+}
+
+func (s *UTXOStatistics) add(utxoEntry externalapi.UTXOEntry) {
+	scriptClass := txscript.GetScriptClass(utxoEntry.ScriptPublicKey().Script)
+	amountBucket := AmountBucketOf(utxoEntry.Amount())
+
+	s.ByScriptClass[scriptClass].Count++
+	s.ByScriptClass[scriptClass].Sompi += utxoEntry.Amount()
+	s.ByAmountBucket[amountBucket].Count++
+	s.ByAmountBucket[amountBucket].Sompi += utxoEntry.Amount()
+}
+
+// applyDiff adds the statistics of toAdd to s and subtracts the statistics of toRemove from it
+func (s *UTXOStatistics) applyDiff(toAdd *UTXOStatistics, toRemove *UTXOStatistics) {
+	for i := range s.ByScriptClass {
+		s.ByScriptClass[i].Count = s.ByScriptClass[i].Count + toAdd.ByScriptClass[i].Count - toRemove.ByScriptClass[i].Count
+		s.ByScriptClass[i].Sompi = s.ByScriptClass[i].Sompi + toAdd.ByScriptClass[i].Sompi - toRemove.ByScriptClass[i].Sompi
+	}
+	for i := range s.ByAmountBucket {
+		s.ByAmountBucket[i].Count = s.ByAmountBucket[i].Count + toAdd.ByAmountBucket[i].Count - toRemove.ByAmountBucket[i].Count
+		s.ByAmountBucket[i].Sompi = s.ByAmountBucket[i].Sompi + toAdd.ByAmountBucket[i].Sompi - toRemove.ByAmountBucket[i].Sompi
+	}
+}
+
+const serializedUTXOStatisticsEntrySize = 16
+const serializedUTXOStatisticsSize = (numScriptClasses + numAmountBuckets) * serializedUTXOStatisticsEntrySize
+
+func serializeUTXOStatistics(statistics *UTXOStatistics) []byte {
+	serializedStatistics := make([]byte, 0, serializedUTXOStatisticsSize)
+	entries := append(statistics.ByScriptClass[:], statistics.ByAmountBucket[:]...)
+	for _, entry := range entries {
+		serializedStatistics = binary.LittleEndian.AppendUint64(serializedStatistics, entry.Count)
+		serializedStatistics = binary.LittleEndian.AppendUint64(serializedStatistics, entry.Sompi)
+	}
+	return serializedStatistics
+}
+
+func deserializeUTXOStatistics(serializedStatistics []byte) (*UTXOStatistics, error) {
+	if len(serializedStatistics) != serializedUTXOStatisticsSize {
+		return nil, errors.Errorf("serialized UTXO statistics are expected to be of length %d but got %d",
+			serializedUTXOStatisticsSize, len(serializedStatistics))
+	}
+
+	readEntry := func(index int) UTXOStatisticsEntry {
+		start := index * serializedUTXOStatisticsEntrySize
+		return UTXOStatisticsEntry{
+			Count: binary.LittleEndian.Uint64(serializedStatistics[start : start+8]),
+			Sompi: binary.LittleEndian.Uint64(serializedStatistics[start+8 : start+16]),
+		}
+	}
+
+	statistics := &UTXOStatistics{}
+	for i := range statistics.ByScriptClass {
+		statistics.ByScriptClass[i] = readEntry(i)
+	}
+	for i := range statistics.ByAmountBucket {
+		statistics.ByAmountBucket[i] = readEntry(numScriptClasses + i)
+	}
+	return statistics, nil
+}
@@ -0,0 +1,72 @@
+package utxoindex
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+)
+
+func TestAmountBucketOf(t *testing.T) {
+	tests := []struct {
+		amount   uint64
+		expected AmountBucket
+	}{
+		{0, AmountBucketDust},
+		{DustAmountThreshold - 1, AmountBucketDust},
+		{DustAmountThreshold, AmountBucketBelowOneKaspa},
+		{constants.SompiPerKaspa - 1, AmountBucketBelowOneKaspa},
+		{constants.SompiPerKaspa, AmountBucketBelowHundredKaspa},
+		{100 * constants.SompiPerKaspa, AmountBucketBelowTenThousandKaspa},
+		{10_000 * constants.SompiPerKaspa, AmountBucketTenThousandKaspaAndAbove},
+		{constants.MaxSompi, AmountBucketTenThousandKaspaAndAbove},
+	}
+	for _, test := range tests {
+		result := AmountBucketOf(test.amount)
+		if result != test.expected {
+			t.Fatalf("AmountBucketOf(%d): expected %s but got %s", test.amount, test.expected, result)
+		}
+	}
+}
+
+func TestUTXOStatistics(t *testing.T) {
+	scriptHashScript := &externalapi.ScriptPublicKey{
+		Script:  append(append([]byte{txscript.OpBlake2b, txscript.OpData32}, make([]byte, 32)...), txscript.OpEqual),
+		Version: 0,
+	}
+	nonStandardScript := &externalapi.ScriptPublicKey{Script: []byte{txscript.OpTrue}, Version: 0}
+
+	toAdd := &UTXOStatistics{}
+	toAdd.add(utxo.NewUTXOEntry(10, scriptHashScript, false, 0))
+	toAdd.add(utxo.NewUTXOEntry(5*constants.SompiPerKaspa, scriptHashScript, false, 0))
+	toAdd.add(utxo.NewUTXOEntry(20_000*constants.SompiPerKaspa, nonStandardScript, true, 0))
+
+	toRemove := &UTXOStatistics{}
+	toRemove.add(utxo.NewUTXOEntry(10, scriptHashScript, false, 0))
+
+	statistics := &UTXOStatistics{}
+	statistics.applyDiff(toAdd, toRemove)
+
+	expectedTotal := UTXOStatisticsEntry{Count: 2, Sompi: 20_005 * constants.SompiPerKaspa}
+	if statistics.Total() != expectedTotal {
+		t.Fatalf("Unexpected total. Want: %+v, got: %+v", expectedTotal, statistics.Total())
+	}
+	expectedScriptHash := UTXOStatisticsEntry{Count: 1, Sompi: 5 * constants.SompiPerKaspa}
+	if statistics.ByScriptClass[txscript.ScriptHashTy] != expectedScriptHash {
+		t.Fatalf("Unexpected script hash statistics. Want: %+v, got: %+v",
+			expectedScriptHash, statistics.ByScriptClass[txscript.ScriptHashTy])
+	}
+	if statistics.ByAmountBucket[AmountBucketDust].Count != 0 {
+		t.Fatalf("Expected no dust UTXOs but got %d", statistics.ByAmountBucket[AmountBucketDust].Count)
+	}
+
+	deserialized, err := deserializeUTXOStatistics(serializeUTXOStatistics(statistics))
+	if err != nil {
+		t.Fatalf("deserializeUTXOStatistics: %+v", err)
+	}
+	if *deserialized != *statistics {
+		t.Fatalf("Expected %+v to be equal to %+v", deserialized, statistics)
+	}
+}
@@ -13,6 +13,7 @@ import (
 var utxoIndexBucket = database.MakeBucket([]byte("utxo-index"))
 var virtualParentsKey = database.MakeBucket([]byte("")).Key([]byte("utxo-index-virtual-parents"))
 var circulatingSupplyKey = database.MakeBucket([]byte("")).Key([]byte("utxo-index-circulating-supply"))
+var utxoStatisticsKey = database.MakeBucket([]byte("")).Key([]byte("utxo-index-statistics"))
 
 type utxoIndexStore struct {
 	database database.Database
@@ -120,6 +121,7 @@ func (uis *utxoIndexStore) commit() error {
 	defer dbTransaction.RollbackUnlessClosed()
 
 	toRemoveSompiSupply := uint64(0)
+	toRemoveStatistics := &UTXOStatistics{}
 
 	for scriptPublicKeyString, toRemoveUTXOOutpointEntryPairs := range uis.toRemove {
 		scriptPublicKey := externalapi.NewScriptPublicKeyFromString(string(scriptPublicKeyString))
@@ -134,10 +136,12 @@ func (uis *utxoIndexStore) commit() error {
 				return err
 			}
 			toRemoveSompiSupply = toRemoveSompiSupply + utxoEntryToRemove.Amount()
+			toRemoveStatistics.add(utxoEntryToRemove)
 		}
 	}
 
 	toAddSompiSupply := uint64(0)
+	toAddStatistics := &UTXOStatistics{}
 
 	for scriptPublicKeyString, toAddUTXOOutpointEntryPairs := range uis.toAdd {
 		scriptPublicKey := externalapi.NewScriptPublicKeyFromString(string(scriptPublicKeyString))
@@ -156,6 +160,7 @@ func (uis *utxoIndexStore) commit() error {
 				return err
 			}
 			toAddSompiSupply = toAddSompiSupply + utxoEntryToAdd.Amount()
+			toAddStatistics.add(utxoEntryToAdd)
 		}
 	}
 
@@ -170,6 +175,11 @@ func (uis *utxoIndexStore) commit() error {
 		return err
 	}
 
+	err = uis.updateUTXOStatistics(dbTransaction, toAddStatistics, toRemoveStatistics)
+	if err != nil {
+		return err
+	}
+
 	err = dbTransaction.Commit()
 	if err != nil {
 		return err
@@ -181,6 +191,7 @@ func (uis *utxoIndexStore) commit() error {
 
 func (uis *utxoIndexStore) addAndCommitOutpointsWithoutTransaction(utxoPairs []*externalapi.OutpointAndUTXOEntryPair) error {
 	toAddSompiSupply := uint64(0)
+	toAddStatistics := &UTXOStatistics{}
 	for _, pair := range utxoPairs {
 		bucket := uis.bucketForScriptPublicKey(pair.UTXOEntry.ScriptPublicKey())
 		key, err := uis.convertOutpointToKey(bucket, pair.Outpoint)
@@ -198,6 +209,7 @@ func (uis *utxoIndexStore) addAndCommitOutpointsWithoutTransaction(utxoPairs []*
 			return err
 		}
 		toAddSompiSupply = toAddSompiSupply + pair.UTXOEntry.Amount()
+		toAddStatistics.add(pair.UTXOEntry)
 	}
 
 	err := uis.updateCirculatingSompiSupplyWithoutTransaction(toAddSompiSupply, uint64(0))
@@ -205,6 +217,11 @@ func (uis *utxoIndexStore) addAndCommitOutpointsWithoutTransaction(utxoPairs []*
 		return err
 	}
 
+	err = uis.updateUTXOStatisticsWithoutTransaction(toAddStatistics, &UTXOStatistics{})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -263,13 +280,13 @@ func (uis *utxoIndexStore) isAnythingStaged() bool {
 	return len(uis.toAdd) > 0 || len(uis.toRemove) > 0
 }
 
-func (uis *utxoIndexStore) getUTXOOutpointEntryPairs(scriptPublicKey *externalapi.ScriptPublicKey) (UTXOOutpointEntryPairs, error) {
-	if uis.isAnythingStaged() {
-		return nil, errors.Errorf("cannot get utxo outpoint entry pairs while staging isn't empty")
-	}
+// getUTXOOutpointEntryPairs reads the UTXOs of the given scriptPublicKey from the given
+// reader, which is normally a snapshot of the database, and so ignores staged data
+func (uis *utxoIndexStore) getUTXOOutpointEntryPairs(reader database.DataReader,
+	scriptPublicKey *externalapi.ScriptPublicKey) (UTXOOutpointEntryPairs, error) {
 
 	bucket := uis.bucketForScriptPublicKey(scriptPublicKey)
-	cursor, err := uis.database.Cursor(bucket)
+	cursor, err := reader.Cursor(bucket)
 	if err != nil {
 		return nil, err
 	}
@@ -323,6 +340,11 @@ func (uis *utxoIndexStore) deleteAll() error {
 		return err
 	}
 
+	err = uis.database.Delete(utxoStatisticsKey)
+	if err != nil {
+		return err
+	}
+
 	cursor, err := uis.database.Cursor(utxoIndexBucket)
 	if err != nil {
 		return err
@@ -431,3 +453,48 @@ func (uis *utxoIndexStore) getCirculatingSompiSupply() (uint64, error) {
 	}
 	return binaryserialization.DeserializeUint64(circulatingSupply)
 }
+
+func (uis *utxoIndexStore) initializeUTXOStatistics() error {
+	return uis.database.Put(utxoStatisticsKey, serializeUTXOStatistics(&UTXOStatistics{}))
+}
+
+func (uis *utxoIndexStore) updateUTXOStatistics(dbTransaction database.Transaction,
+	toAddStatistics *UTXOStatistics, toRemoveStatistics *UTXOStatistics) error {
+
+	serializedStatistics, err := dbTransaction.Get(utxoStatisticsKey)
+	if err != nil {
+		return err
+	}
+	statistics, err := deserializeUTXOStatistics(serializedStatistics)
+	if err != nil {
+		return err
+	}
+	statistics.applyDiff(toAddStatistics, toRemoveStatistics)
+	return dbTransaction.Put(utxoStatisticsKey, serializeUTXOStatistics(statistics))
+}
+
+func (uis *utxoIndexStore) updateUTXOStatisticsWithoutTransaction(
+	toAddStatistics *UTXOStatistics, toRemoveStatistics *UTXOStatistics) error {
+
+	serializedStatistics, err := uis.database.Get(utxoStatisticsKey)
+	if err != nil {
+		return err
+	}
+	statistics, err := deserializeUTXOStatistics(serializedStatistics)
+	if err != nil {
+		return err
+	}
+	statistics.applyDiff(toAddStatistics, toRemoveStatistics)
+	return uis.database.Put(utxoStatisticsKey, serializeUTXOStatistics(statistics))
+}
+
+func (uis *utxoIndexStore) getUTXOStatistics() (*UTXOStatistics, error) {
+	if uis.isAnythingStaged() {
+		return nil, errors.Errorf("cannot get UTXO statistics while staging isn't empty")
+	}
+	serializedStatistics, err := uis.database.Get(utxoStatisticsKey)
+	if err != nil {
+		return nil, err
+	}
+	return deserializeUTXOStatistics(serializedStatistics)
+}
@@ -36,7 +36,13 @@ func New(domain domain.Domain, database database.Database) (*UTXOIndex, error) {
 		return nil, err
 	}
 
-	if !isSynced || !hasCirculatingSupplyKey {
+	// Has check is for migration to UTXO statistics, can be removed eventually.
+	hasUTXOStatisticsKey, err := utxoIndex.store.database.Has(utxoStatisticsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isSynced || !hasCirculatingSupplyKey || !hasUTXOStatisticsKey {
 
 		err := utxoIndex.Reset()
 		if err != nil {
@@ -47,6 +53,12 @@ func New(domain domain.Domain, database database.Database) (*UTXOIndex, error) {
 	return utxoIndex, nil
 }
 
+// Drop deletes the whole UTXO index from the given database, so that
+// it's resynced from consensus once it's created again with New.
+func Drop(database database.Database) error {
+	return newUTXOIndexStore(database).deleteAll()
+}
+
 // Reset deletes the whole UTXO index and resyncs it from consensus.
 func (ui *UTXOIndex) Reset() error {
 	ui.mutex.Lock()
@@ -59,20 +71,28 @@ func (ui *UTXOIndex) Reset() error {
 		return err
 	}
 
-	virtualInfo, err := ui.domain.Consensus().GetVirtualInfo()
+	// The UTXOs are read from a snapshot, so that blocks may keep being added
+	// to the DAG while the index is resynced
+	virtualUTXOSetSnapshot, err := ui.domain.Consensus().GetVirtualUTXOSetSnapshot()
 	if err != nil {
 		return err
 	}
+	defer virtualUTXOSetSnapshot.Release()
 
 	err = ui.store.initializeCirculatingSompiSupply() //At this point the database is empty, so the sole purpose of this call is to initialize the circulating supply key
 	if err != nil {
 		return err
 	}
 
+	err = ui.store.initializeUTXOStatistics()
+	if err != nil {
+		return err
+	}
+
 	var fromOutpoint *externalapi.DomainOutpoint
 	for {
 		const step = 1000
-		virtualUTXOs, err := ui.domain.Consensus().GetVirtualUTXOs(virtualInfo.ParentHashes, fromOutpoint, step)
+		virtualUTXOs, err := virtualUTXOSetSnapshot.VirtualUTXOs(fromOutpoint, step)
 		if err != nil {
 			return err
 		}
@@ -90,7 +110,7 @@ func (ui *UTXOIndex) Reset() error {
 	}
 
 	// This has to be done last to mark that the reset went smoothly and no reset has to be called next time.
-	err = ui.store.updateAndCommitVirtualParentsWithoutTransaction(virtualInfo.ParentHashes)
+	err = ui.store.updateAndCommitVirtualParentsWithoutTransaction(virtualUTXOSetSnapshot.VirtualParents())
 	if err != nil {
 		return err
 	}
@@ -193,10 +213,24 @@ func (ui *UTXOIndex) UTXOs(scriptPublicKey *externalapi.ScriptPublicKey) (UTXOOu
 	onEnd := logger.LogAndMeasureExecutionTime(log, "UTXOIndex.UTXOs")
 	defer onEnd()
 
+	// The UTXOs are read from a snapshot of the index, so that scanning a
+	// script public key with many UTXOs doesn't block updates of the index
+	snapshot, err := ui.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer snapshot.Release()
+
+	return ui.store.getUTXOOutpointEntryPairs(snapshot, scriptPublicKey)
+}
+
+// snapshot takes a snapshot of the database between updates of the index,
+// so that it never contains a partially applied update
+func (ui *UTXOIndex) snapshot() (database.Snapshot, error) {
 	ui.mutex.Lock()
 	defer ui.mutex.Unlock()
 
-	return ui.store.getUTXOOutpointEntryPairs(scriptPublicKey)
+	return ui.store.database.Snapshot()
 }
 
 // GetCirculatingSompiSupply returns the current circulating supply of sompis in the network
@@ -207,3 +241,11 @@ func (ui *UTXOIndex) GetCirculatingSompiSupply() (uint64, error) {
 
 	return ui.store.getCirculatingSompiSupply()
 }
+
+// GetUTXOStatistics returns a breakdown of the current UTXO set by script class and amount bucket
+func (ui *UTXOIndex) GetUTXOStatistics() (*UTXOStatistics, error) {
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+
+	return ui.store.getUTXOStatistics()
+}
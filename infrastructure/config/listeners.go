@@ -0,0 +1,97 @@
+package config
+
+import (
+	"crypto/tls"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// ListenerOptions holds the listeners of the surfaces of the node other than P2P and
+// RPC, whose listeners are set by --listen and --rpclisten, along with the TLS
+// settings of each surface. Each surface can be bound to its own interfaces, so that
+// only some of them are exposed publicly.
+type ListenerOptions struct {
+	RPCCert          string   `long:"rpccert" description:"File containing the TLS certificate of the RPC server. RPC is served over TLS when it's given together with --rpckey"`
+	RPCKey           string   `long:"rpckey" description:"File containing the TLS certificate key of the RPC server"`
+	GRPCListeners    []string `long:"grpclisten" description:"Add an interface/port to serve the typed gRPC service on, instead of on the RPC listeners"`
+	GRPCCert         string   `long:"grpccert" description:"File containing the TLS certificate of the typed gRPC service, when it's served on --grpclisten"`
+	GRPCKey          string   `long:"grpckey" description:"File containing the TLS certificate key of the typed gRPC service"`
+	RESTListeners    []string `long:"restlisten" description:"Add an interface/port to serve the REST interface enabled by --rest on, instead of on the profile HTTP server"`
+	RESTCert         string   `long:"restcert" description:"File containing the TLS certificate of the REST interface, when it's served on --restlisten"`
+	RESTKey          string   `long:"restkey" description:"File containing the TLS certificate key of the REST interface"`
+	MetricsListeners []string `long:"metricslisten" description:"Add an interface/port to serve the profiling endpoints of --profile and the Prometheus metrics at /metrics on. Unlike --profile, which listens on all interfaces, the interface can be chosen"`
+	MetricsCert      string   `long:"metricscert" description:"File containing the TLS certificate of the profiling endpoints and metrics served on --metricslisten"`
+	MetricsKey       string   `long:"metricskey" description:"File containing the TLS certificate key of the profiling endpoints"`
+}
+
+// ListenerTLSConfigs holds the TLS configurations loaded from ListenerOptions. A
+// surface whose TLS configuration is nil is served without TLS.
+type ListenerTLSConfigs struct {
+	RPC     *tls.Config
+	GRPC    *tls.Config
+	REST    *tls.Config
+	Metrics *tls.Config
+}
+
+// loadTLSConfigs validates the listener options and loads the TLS configurations
+// of the surfaces that have a certificate
+func (options *ListenerOptions) loadTLSConfigs() (*ListenerTLSConfigs, error) {
+	for option, listeners := range map[string][]string{
+		"grpclisten":    options.GRPCListeners,
+		"restlisten":    options.RESTListeners,
+		"metricslisten": options.MetricsListeners,
+	} {
+		for _, listener := range listeners {
+			_, _, err := net.SplitHostPort(listener)
+			if err != nil {
+				return nil, errors.Wrapf(err, "the %s option %s must be an interface and a port", option, listener)
+			}
+		}
+	}
+
+	tlsConfigs := &ListenerTLSConfigs{}
+	var err error
+	tlsConfigs.RPC, err = loadTLSConfig("rpc", options.RPCCert, options.RPCKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfigs.GRPC, err = loadTLSConfig("grpc", options.GRPCCert, options.GRPCKey, options.GRPCListeners)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfigs.REST, err = loadTLSConfig("rest", options.RESTCert, options.RESTKey, options.RESTListeners)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfigs.Metrics, err = loadTLSConfig("metrics", options.MetricsCert, options.MetricsKey,
+		options.MetricsListeners)
+	if err != nil {
+		return nil, err
+	}
+	return tlsConfigs, nil
+}
+
+// loadTLSConfig loads the TLS configuration of the surface with the given option
+// prefix. Surfaces other than RPC only have a TLS configuration when they're
+// served on their own listeners, which are passed as listeners.
+func loadTLSConfig(prefix string, certFile string, keyFile string, listeners []string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, errors.Errorf("the %[1]scert and %[1]skey options must be given together", prefix)
+	}
+	if prefix != "rpc" && len(listeners) == 0 {
+		return nil, errors.Errorf("the %[1]scert and %[1]skey options require the %[1]slisten option", prefix)
+	}
+
+	certificate, err := tls.LoadX509KeyPair(cleanAndExpandPath(certFile), cleanAndExpandPath(keyFile))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error loading the %s TLS certificate", prefix)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
@@ -41,6 +41,7 @@ type overrideDAGParamsConfig struct {
 	SubsidyMergeSetRewardMultiplier         *float64           `json:"subsidyMergeSetRewardMultiplier"`
 	TargetTimePerBlockInMilliSeconds        *int64             `json:"targetTimePerBlockInMilliSeconds"`
 	FinalityDuration                        *int64             `json:"finalityDuration"`
+	FinalityDepth                           *uint64            `json:"finalityDepth"`
 	TimestampDeviationTolerance             *int               `json:"timestampDeviationTolerance"`
 	DifficultyAdjustmentWindowSize          *int               `json:"difficultyAdjustmentWindowSize"`
 	RelayNonStdTxs                          *bool              `json:"relayNonStdTxs"`
@@ -49,6 +50,10 @@ type overrideDAGParamsConfig struct {
 	DisableDifficultyAdjustment             *bool              `json:"disableDifficultyAdjustment"`
 	SkipProofOfWork                         *bool              `json:"skipProofOfWork"`
 	HardForkOmitGenesisFromParentsDAAScore  *uint64            `json:"hardForkOmitGenesisFromParentsDaaScore"`
+
+	// Transaction versions
+	TransactionVersions           *[]dagconfig.TransactionVersionParams `json:"transactionVersions"`
+	MaxStandardTransactionVersion *uint16                               `json:"maxStandardTransactionVersion"`
 }
 
 // ResolveNetwork parses the network command line argument and sets NetParams accordingly.
@@ -185,6 +190,19 @@ func (networkFlags *NetworkFlags) overrideDAGParams() error {
 		networkFlags.ActiveNetParams.FinalityDuration = time.Duration(*config.FinalityDuration) * time.Millisecond
 	}
 
+	if config.FinalityDepth != nil {
+		if config.FinalityDuration != nil {
+			return errors.Errorf("finalityDepth and finalityDuration cannot be overridden together")
+		}
+		if *config.FinalityDepth == 0 {
+			return errors.Errorf("finalityDepth must be greater than zero")
+		}
+		// The finality depth is derived from the finality duration, so it's kept in terms of the target
+		// time per block
+		networkFlags.ActiveNetParams.FinalityDuration = time.Duration(*config.FinalityDepth) *
+			networkFlags.ActiveNetParams.TargetTimePerBlock
+	}
+
 	if config.TimestampDeviationTolerance != nil {
 		networkFlags.ActiveNetParams.TimestampDeviationTolerance = *config.TimestampDeviationTolerance
 	}
@@ -213,5 +231,26 @@ func (networkFlags *NetworkFlags) overrideDAGParams() error {
 		networkFlags.ActiveNetParams.SkipProofOfWork = *config.SkipProofOfWork
 	}
 
+	if config.TransactionVersions != nil {
+		if len(*config.TransactionVersions) == 0 {
+			return errors.Errorf("transactionVersions must define at least transaction version 0")
+		}
+		if (*config.TransactionVersions)[0].ActivationDAAScore != 0 {
+			return errors.Errorf("transaction version 0 must be active from genesis")
+		}
+		networkFlags.ActiveNetParams.TransactionVersions = *config.TransactionVersions
+	}
+
+	if config.MaxStandardTransactionVersion != nil {
+		networkFlags.ActiveNetParams.MaxStandardTransactionVersion = *config.MaxStandardTransactionVersion
+	}
+
+	if networkFlags.ActiveNetParams.MaxStandardTransactionVersion >
+		networkFlags.ActiveNetParams.MaxTransactionVersion() {
+
+		return errors.Errorf("maxStandardTransactionVersion %d is higher than the maximum transaction version %d",
+			networkFlags.ActiveNetParams.MaxStandardTransactionVersion, networkFlags.ActiveNetParams.MaxTransactionVersion())
+	}
+
 	return nil
 }
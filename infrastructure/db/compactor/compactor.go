@@ -0,0 +1,108 @@
+package compactor
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+// Compactor compacts the database in the background, either when requested or
+// periodically, and makes sure that no more than one compaction runs at a time
+type Compactor struct {
+	db       database.Database
+	interval time.Duration
+
+	isCompacting       uint32
+	lastCompactionTime time.Time
+	lock               sync.Mutex
+
+	quit chan struct{}
+}
+
+// New creates a new Compactor for the given database. If interval is
+// positive, the whole database is compacted once every interval after Start
+// is called
+func New(db database.Database, interval time.Duration) *Compactor {
+	return &Compactor{
+		db:       db,
+		interval: interval,
+		quit:     make(chan struct{}),
+	}
+}
+
+// Start starts the periodic compaction of the database, if enabled
+func (c *Compactor) Start() {
+	if c.interval <= 0 {
+		return
+	}
+	log.Infof("Compacting the database every %s", c.interval)
+	spawn("Compactor.compactionLoop", c.compactionLoop)
+}
+
+// Stop stops the periodic compaction of the database. A compaction that
+// is already running is not interrupted
+func (c *Compactor) Stop() {
+	close(c.quit)
+}
+
+// Compact starts compacting the whole database in the background. It
+// returns false if a compaction is already running
+func (c *Compactor) Compact() bool {
+	if !atomic.CompareAndSwapUint32(&c.isCompacting, 0, 1) {
+		return false
+	}
+	spawn("Compactor.compact", c.compact)
+	return true
+}
+
+// IsCompacting returns whether a compaction is currently running
+func (c *Compactor) IsCompacting() bool {
+	return atomic.LoadUint32(&c.isCompacting) == 1
+}
+
+// LastCompactionTime returns the time at which the last successful
+// compaction finished, or the zero time if none did
+func (c *Compactor) LastCompactionTime() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.lastCompactionTime
+}
+
+func (c *Compactor) compactionLoop() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.quit:
+			return
+		case <-ticker.C:
+			if !c.Compact() {
+				log.Debugf("Skipping the periodic database compaction since a compaction is already running")
+			}
+		}
+	}
+}
+
+func (c *Compactor) compact() {
+	defer atomic.StoreUint32(&c.isCompacting, 0)
+
+	log.Infof("Compacting the database")
+	start := time.Now()
+	err := c.db.Compact(nil, nil)
+	if err != nil {
+		log.Errorf("Error compacting the database: %s", err)
+		return
+	}
+
+	c.lock.Lock()
+	c.lastCompactionTime = time.Now()
+	c.lock.Unlock()
+
+	duration := time.Since(start)
+	compactionDuration.ObserveDuration(duration)
+	log.Infof("Compacted the database in %s", duration)
+}
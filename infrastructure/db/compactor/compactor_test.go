@@ -0,0 +1,51 @@
+package compactor
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+)
+
+func TestCompactor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestCompactor")
+	if err != nil {
+		t.Fatalf("TempDir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := ldb.NewLevelDB(dir, 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %+v", err)
+	}
+	defer db.Close()
+
+	compactor := New(db, 10*time.Millisecond)
+	compactor.Start()
+	defer compactor.Stop()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for compactor.LastCompactionTime().IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatalf("the database wasn't compacted periodically")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Compact may race with the periodic compaction, so wait for it to finish first
+	for compactor.IsCompacting() {
+		time.Sleep(time.Millisecond)
+	}
+	lastCompactionTime := compactor.LastCompactionTime()
+	for !compactor.Compact() {
+		time.Sleep(time.Millisecond)
+	}
+	for !compactor.LastCompactionTime().After(lastCompactionTime) {
+		if time.Now().After(deadline) {
+			t.Fatalf("the requested compaction didn't finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
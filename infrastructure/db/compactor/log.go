@@ -0,0 +1,9 @@
+package compactor
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/panics"
+)
+
+var log = logger.RegisterSubSystem("KSDB")
+var spawn = panics.GoroutineWrapperFunc(log)
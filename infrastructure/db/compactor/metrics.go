@@ -0,0 +1,6 @@
+package compactor
+
+import "github.com/kaspanet/kaspad/infrastructure/metrics"
+
+var compactionDuration = metrics.NewHistogram("kaspad_db_compaction_seconds",
+	"How long compactions of the whole database take", []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200, 1800})
@@ -0,0 +1,167 @@
+package badgerdb
+
+import (
+	"bytes"
+	"sync/atomic"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/dgraph-io/badger/v4/y"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// valueLogGCDiscardRatio is the fraction of a value log file that has to be
+// stale for the file to be rewritten during compaction
+const valueLogGCDiscardRatio = 0.5
+
+// BadgerDB defines a thin wrapper around badger.
+type BadgerDB struct {
+	db            *badger.DB
+	nextJournalID atomic.Uint64
+}
+
+// NewBadgerDB opens a badger instance defined by the given path.
+// If it doesn't exist, it's created.
+func NewBadgerDB(path string, cacheSizeMiB int) (*BadgerDB, error) {
+	return NewBadgerDBWithOptions(Options(path, cacheSizeMiB))
+}
+
+// NewBadgerDBWithOptions opens a badger instance with the given options,
+// and completes the transactions that were committed before it was last closed.
+func NewBadgerDBWithOptions(options badger.Options) (*BadgerDB, error) {
+	db, err := badger.Open(options)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	badgerDB := &BadgerDB{db: db}
+	err = badgerDB.recoverJournals()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return badgerDB, nil
+}
+
+// Compact compacts the badger instance. Badger can't compact a range of
+// keys, so the whole database is compacted regardless of start and end,
+// after which its value log is garbage collected.
+func (db *BadgerDB) Compact(start, end []byte) error {
+	err := db.db.Flatten(1)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for {
+		err := db.db.RunValueLogGC(valueLogGCDiscardRatio)
+		if errors.Is(err, badger.ErrNoRewrite) {
+			return nil
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+}
+
+// ApproximateSize returns the approximate size in bytes that the keys in the
+// range [start, end) occupy on disk. A nil start means the beginning of the
+// database, and a nil end means its end. The size of a range is the size of
+// the tables that overlap it, and doesn't include the value log.
+func (db *BadgerDB) ApproximateSize(start, end []byte) (uint64, error) {
+	if start == nil && end == nil {
+		lsmSize, valueLogSize := db.db.Size()
+		return uint64(lsmSize + valueLogSize), nil
+	}
+
+	var size uint64
+	for _, table := range db.db.Tables() {
+		if end != nil && bytes.Compare(y.ParseKey(table.Left), end) >= 0 {
+			continue
+		}
+		if start != nil && bytes.Compare(y.ParseKey(table.Right), start) < 0 {
+			continue
+		}
+		size += uint64(table.OnDiskSize)
+	}
+	return size, nil
+}
+
+// Close closes the badger instance.
+func (db *BadgerDB) Close() error {
+	err := db.db.Close()
+	return errors.WithStack(err)
+}
+
+// Put sets the value for the given key. It overwrites
+// any previous value for that key.
+func (db *BadgerDB) Put(key *database.Key, value []byte) error {
+	err := db.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key.Bytes(), value)
+	})
+	return errors.WithStack(err)
+}
+
+// Get gets the value for the given key. It returns
+// ErrNotFound if the given key does not exist.
+func (db *BadgerDB) Get(key *database.Key) ([]byte, error) {
+	var data []byte
+	err := db.db.View(func(txn *badger.Txn) error {
+		var err error
+		data, err = get(txn, key)
+		return err
+	})
+	return data, err
+}
+
+// Has returns true if the database does contains the
+// given key.
+func (db *BadgerDB) Has(key *database.Key) (bool, error) {
+	var exists bool
+	err := db.db.View(func(txn *badger.Txn) error {
+		var err error
+		exists, err = has(txn, key)
+		return err
+	})
+	return exists, err
+}
+
+// Delete deletes the value for the given key. Will not
+// return an error if the key doesn't exist.
+func (db *BadgerDB) Delete(key *database.Key) error {
+	err := db.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(key.Bytes())
+	})
+	return errors.WithStack(err)
+}
+
+func get(txn *badger.Txn, key *database.Key) ([]byte, error) {
+	item, err := txn.Get(key.Bytes())
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil, errors.Wrapf(database.ErrNotFound,
+				"key %s not found", key)
+		}
+		return nil, errors.WithStack(err)
+	}
+	return valueCopy(item)
+}
+
+// valueCopy returns a copy of the value of item, which is valid after
+// the transaction is done. Empty values are returned as empty slices
+// rather than nil, as leveldb does.
+func valueCopy(item *badger.Item) ([]byte, error) {
+	data, err := item.ValueCopy(make([]byte, 0, item.ValueSize()))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return data, nil
+}
+
+func has(txn *badger.Txn, key *database.Key) (bool, error) {
+	_, err := txn.Get(key.Bytes())
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
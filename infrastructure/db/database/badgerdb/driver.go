@@ -0,0 +1,23 @@
+package badgerdb
+
+import (
+	"fmt"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+// DbType is the type of the badger database backend
+const DbType = "badger"
+
+func init() {
+	driver := database.Driver{
+		DbType: DbType,
+		Open: func(path string, cacheSizeMiB int) (database.Database, error) {
+			return NewBadgerDB(path, cacheSizeMiB)
+		},
+	}
+	err := database.RegisterDriver(driver)
+	if err != nil {
+		panic(fmt.Sprintf("failed to register the %s database driver: %s", DbType, err))
+	}
+}
@@ -0,0 +1,251 @@
+package badgerdb
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/pkg/errors"
+)
+
+// A transaction that becomes too big for badger to commit at once is written to a
+// journal, which is then committed and applied in as many badger transactions as
+// it takes. The journal is marked as committed once all of its entries are written,
+// so a journal that's found on startup is applied if it's marked as committed,
+// and discarded otherwise. This keeps such transactions atomic across crashes.
+//
+// Readers that don't wait for the writer of a journal to be done may observe a part
+// of it while it's being applied.
+var (
+	journalEntriesPrefix    = []byte("badger-journal/entries/")
+	committedJournalsPrefix = []byte("badger-journal/committed/")
+)
+
+const (
+	journalEntryPut byte = iota
+	journalEntryDelete
+)
+
+// journalEntry is a single write of a transaction
+type journalEntry struct {
+	key      []byte
+	value    []byte
+	isDelete bool
+}
+
+// batchWriter is implemented by both badger.Txn and badger.WriteBatch
+type batchWriter interface {
+	Set(key, value []byte) error
+	Delete(key []byte) error
+}
+
+func (entry *journalEntry) writeTo(writer batchWriter) error {
+	if entry.isDelete {
+		return writer.Delete(entry.key)
+	}
+	return writer.Set(entry.key, entry.value)
+}
+
+func (entry *journalEntry) serialize() []byte {
+	serializedEntry := make([]byte, 0, 1+binary.MaxVarintLen64+len(entry.key)+len(entry.value))
+	if entry.isDelete {
+		serializedEntry = append(serializedEntry, journalEntryDelete)
+	} else {
+		serializedEntry = append(serializedEntry, journalEntryPut)
+	}
+	serializedEntry = binary.AppendUvarint(serializedEntry, uint64(len(entry.key)))
+	serializedEntry = append(serializedEntry, entry.key...)
+	return append(serializedEntry, entry.value...)
+}
+
+func deserializeJournalEntry(serializedEntry []byte) (*journalEntry, error) {
+	if len(serializedEntry) == 0 {
+		return nil, errors.New("empty journal entry")
+	}
+	entryType := serializedEntry[0]
+	if entryType != journalEntryPut && entryType != journalEntryDelete {
+		return nil, errors.Errorf("unknown journal entry type %d", entryType)
+	}
+	keyLength, n := binary.Uvarint(serializedEntry[1:])
+	if n <= 0 || uint64(len(serializedEntry)-1-n) < keyLength {
+		return nil, errors.New("malformed journal entry")
+	}
+	keyStart := 1 + n
+	keyEnd := keyStart + int(keyLength)
+	return &journalEntry{
+		key:      serializedEntry[keyStart:keyEnd],
+		value:    serializedEntry[keyEnd:],
+		isDelete: entryType == journalEntryDelete,
+	}, nil
+}
+
+// journal is the journal of a single transaction
+type journal struct {
+	db         *BadgerDB
+	id         []byte
+	batch      *badger.WriteBatch
+	entryCount uint64
+}
+
+func (db *BadgerDB) newJournal() *journal {
+	id := make([]byte, 8)
+	binary.BigEndian.PutUint64(id, db.nextJournalID.Add(1))
+	return &journal{
+		db:    db,
+		id:    id,
+		batch: db.db.NewWriteBatch(),
+	}
+}
+
+func journalEntriesPrefixOf(id []byte) []byte {
+	prefix := make([]byte, 0, len(journalEntriesPrefix)+len(id))
+	prefix = append(prefix, journalEntriesPrefix...)
+	return append(prefix, id...)
+}
+
+func committedJournalKey(id []byte) []byte {
+	key := make([]byte, 0, len(committedJournalsPrefix)+len(id))
+	key = append(key, committedJournalsPrefix...)
+	return append(key, id...)
+}
+
+// append adds the given entry to the journal. The entries are written in the
+// background, in batches that are small enough for badger.
+func (j *journal) append(entry *journalEntry) error {
+	key := journalEntriesPrefixOf(j.id)
+	key = binary.BigEndian.AppendUint64(key, j.entryCount)
+	j.entryCount++
+	return errors.WithStack(j.batch.Set(key, entry.serialize()))
+}
+
+// markCommitted waits for the entries of the journal to be written, and then marks it
+// as committed. From here on, the journal is applied even if the node crashes.
+func (j *journal) markCommitted() error {
+	err := j.batch.Flush()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = j.db.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(committedJournalKey(j.id), nil)
+	})
+	return errors.WithStack(err)
+}
+
+func (j *journal) commit() error {
+	err := j.markCommitted()
+	if err != nil {
+		return err
+	}
+	return j.db.applyJournal(j.id)
+}
+
+func (j *journal) rollback() error {
+	j.batch.Cancel()
+	return j.db.deletePrefix(journalEntriesPrefixOf(j.id))
+}
+
+// applyJournal writes the entries of the committed journal of the given ID to the
+// database, and then deletes the journal. Applying a journal more than once writes
+// the same data, so a journal whose application was interrupted is applied again.
+func (db *BadgerDB) applyJournal(id []byte) error {
+	batch := db.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	err := db.db.View(func(txn *badger.Txn) error {
+		iteratorOptions := badger.DefaultIteratorOptions
+		iteratorOptions.Prefix = journalEntriesPrefixOf(id)
+		iterator := txn.NewIterator(iteratorOptions)
+		defer iterator.Close()
+
+		for iterator.Rewind(); iterator.Valid(); iterator.Next() {
+			serializedEntry, err := iterator.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			entry, err := deserializeJournalEntry(serializedEntry)
+			if err != nil {
+				return err
+			}
+			err = entry.writeTo(batch)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = batch.Flush()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Once the journal isn't marked as committed anymore, its entries
+	// are discarded on startup, so they're deleted only after it
+	err = db.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(committedJournalKey(id))
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return db.deletePrefix(journalEntriesPrefixOf(id))
+}
+
+// recoverJournals applies the journals of the transactions that were committed before
+// the database was closed, and discards the journals of the ones that weren't
+func (db *BadgerDB) recoverJournals() error {
+	var committedJournalIDs [][]byte
+	err := db.db.View(func(txn *badger.Txn) error {
+		iteratorOptions := badger.DefaultIteratorOptions
+		iteratorOptions.Prefix = committedJournalsPrefix
+		iteratorOptions.PrefetchValues = false
+		iterator := txn.NewIterator(iteratorOptions)
+		defer iterator.Close()
+
+		for iterator.Rewind(); iterator.Valid(); iterator.Next() {
+			id := bytes.TrimPrefix(iterator.Item().KeyCopy(nil), committedJournalsPrefix)
+			committedJournalIDs = append(committedJournalIDs, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, id := range committedJournalIDs {
+		log.Infof("Applying a database transaction that was committed before the database was closed")
+		err := db.applyJournal(id)
+		if err != nil {
+			return err
+		}
+	}
+	return db.deletePrefix(journalEntriesPrefix)
+}
+
+// deletePrefix deletes every key that starts with the given prefix, in as many
+// badger transactions as it takes
+func (db *BadgerDB) deletePrefix(prefix []byte) error {
+	batch := db.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	err := db.db.View(func(txn *badger.Txn) error {
+		iteratorOptions := badger.DefaultIteratorOptions
+		iteratorOptions.Prefix = prefix
+		iteratorOptions.PrefetchValues = false
+		iterator := txn.NewIterator(iteratorOptions)
+		defer iterator.Close()
+
+		for iterator.Rewind(); iterator.Valid(); iterator.Next() {
+			err := batch.Delete(iterator.Item().KeyCopy(nil))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(batch.Flush())
+}
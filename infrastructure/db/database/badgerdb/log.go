@@ -0,0 +1,27 @@
+package badgerdb
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+)
+
+var log = logger.RegisterSubSystem("KSDB")
+
+// badgerLogger passes the logs of badger on to the KSDB subsystem. Badger is
+// chatty about its background work, so its levels are lowered by one.
+type badgerLogger struct{}
+
+func (badgerLogger) Errorf(format string, args ...interface{}) {
+	log.Errorf(format, args...)
+}
+
+func (badgerLogger) Warningf(format string, args ...interface{}) {
+	log.Warnf(format, args...)
+}
+
+func (badgerLogger) Infof(format string, args ...interface{}) {
+	log.Debugf(format, args...)
+}
+
+func (badgerLogger) Debugf(format string, args ...interface{}) {
+	log.Tracef(format, args...)
+}
@@ -0,0 +1,33 @@
+package badgerdb
+
+import (
+	"github.com/dgraph-io/badger/v4"
+	"github.com/dgraph-io/badger/v4/options"
+)
+
+const (
+	mib = 1024 * 1024
+
+	// minMemTableSize is the smallest memtable that is used regardless of the cache
+	// size. Badger fails transactions that are larger than a fraction of the memtable,
+	// and such transactions are committed through a journal instead (see journal.go).
+	minMemTableSize = 64 * mib
+)
+
+// Options is a function that returns a badger Options struct
+// for opening a database at the given path.
+func Options(path string, cacheSizeMiB int) badger.Options {
+	memTableSize := int64(cacheSizeMiB*mib) / 2
+	if memTableSize < minMemTableSize {
+		memTableSize = minMemTableSize
+	}
+
+	return badger.DefaultOptions(path).
+		WithLogger(badgerLogger{}).
+		WithCompression(options.None).
+		WithSyncWrites(false).
+		WithDetectConflicts(false).
+		WithNumVersionsToKeep(1).
+		WithBlockCacheSize(int64(cacheSizeMiB * mib)).
+		WithMemTableSize(memTableSize)
+}
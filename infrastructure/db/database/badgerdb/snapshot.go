@@ -0,0 +1,39 @@
+package badgerdb
+
+import (
+	"github.com/dgraph-io/badger/v4"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+// BadgerDBSnapshot is a thin wrapper around a read-only badger
+// transaction, which sees the database as it was when it began.
+type BadgerDBSnapshot struct {
+	txn *badger.Txn
+}
+
+// Snapshot takes a consistent read-only snapshot of the badger instance.
+func (db *BadgerDB) Snapshot() (database.Snapshot, error) {
+	return &BadgerDBSnapshot{txn: db.db.NewTransaction(false)}, nil
+}
+
+// Get gets the value for the given key. It returns
+// ErrNotFound if the given key does not exist.
+func (s *BadgerDBSnapshot) Get(key *database.Key) ([]byte, error) {
+	return get(s.txn, key)
+}
+
+// Has returns true if the snapshot does contains the
+// given key.
+func (s *BadgerDBSnapshot) Has(key *database.Key) (bool, error) {
+	return has(s.txn, key)
+}
+
+// Cursor begins a new cursor over the given prefix.
+func (s *BadgerDBSnapshot) Cursor(bucket *database.Bucket) (database.Cursor, error) {
+	return newCursor(s.txn, false, bucket), nil
+}
+
+// Release releases the snapshot.
+func (s *BadgerDBSnapshot) Release() {
+	s.txn.Discard()
+}
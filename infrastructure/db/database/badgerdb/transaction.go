@@ -0,0 +1,169 @@
+package badgerdb
+
+import (
+	"github.com/dgraph-io/badger/v4"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// BadgerDBTransaction is a thin wrapper around native badger
+// transactions. It supports both get and put.
+//
+// Note that reads are done from the Database directly, so if another transaction changed the data,
+// you will read the new data, and not the one from the time the transaction was opened, as in
+// the leveldb backend.
+//
+// Note: As it's currently implemented, if one puts data into the transaction
+// then it will not be available to get within the same transaction.
+//
+// Badger keeps the writes of a transaction in memory until it's committed, and
+// fails writes that would make it larger than a fraction of its memtable size.
+// Once a transaction becomes that large, its writes are moved to a journal, which
+// is committed in as many badger transactions as it takes (see journal.go).
+type BadgerDBTransaction struct {
+	db       *BadgerDB
+	txn      *badger.Txn
+	isClosed bool
+
+	// writes are the writes made in txn. They're kept only until txn becomes
+	// too big, at which point they're moved to journal.
+	writes  []*journalEntry
+	journal *journal
+}
+
+// Begin begins a new transaction.
+func (db *BadgerDB) Begin() (database.Transaction, error) {
+	transaction := &BadgerDBTransaction{
+		db:       db,
+		txn:      db.db.NewTransaction(true),
+		isClosed: false,
+	}
+	return transaction, nil
+}
+
+// Commit commits whatever changes were made to the database
+// within this transaction.
+func (tx *BadgerDBTransaction) Commit() error {
+	if tx.isClosed {
+		return errors.New("cannot commit a closed transaction")
+	}
+
+	tx.isClosed = true
+	if tx.journal != nil {
+		return tx.journal.commit()
+	}
+	return errors.WithStack(tx.txn.Commit())
+}
+
+// Rollback rolls back whatever changes were made to the
+// database within this transaction.
+func (tx *BadgerDBTransaction) Rollback() error {
+	if tx.isClosed {
+		return errors.New("cannot rollback a closed transaction")
+	}
+
+	tx.isClosed = true
+	if tx.journal != nil {
+		return tx.journal.rollback()
+	}
+	tx.txn.Discard()
+	return nil
+}
+
+// RollbackUnlessClosed rolls back changes that were made to
+// the database within the transaction, unless the transaction
+// had already been closed using either Rollback or Commit.
+func (tx *BadgerDBTransaction) RollbackUnlessClosed() error {
+	if tx.isClosed {
+		return nil
+	}
+	return tx.Rollback()
+}
+
+// Put sets the value for the given key. It overwrites
+// any previous value for that key.
+func (tx *BadgerDBTransaction) Put(key *database.Key, value []byte) error {
+	if tx.isClosed {
+		return errors.New("cannot put into a closed transaction")
+	}
+
+	// Badger references the value until the transaction is committed,
+	// so it's copied in case the caller reuses it
+	copiedValue := make([]byte, len(value))
+	copy(copiedValue, value)
+	return tx.write(&journalEntry{key: key.Bytes(), value: copiedValue})
+}
+
+// Get gets the value for the given key. It returns
+// ErrNotFound if the given key does not exist.
+func (tx *BadgerDBTransaction) Get(key *database.Key) ([]byte, error) {
+	if tx.isClosed {
+		return nil, errors.New("cannot get from a closed transaction")
+	}
+	return tx.db.Get(key)
+}
+
+// Has returns true if the database does contains the
+// given key.
+func (tx *BadgerDBTransaction) Has(key *database.Key) (bool, error) {
+	if tx.isClosed {
+		return false, errors.New("cannot has from a closed transaction")
+	}
+	return tx.db.Has(key)
+}
+
+// Delete deletes the value for the given key. Will not
+// return an error if the key doesn't exist.
+func (tx *BadgerDBTransaction) Delete(key *database.Key) error {
+	if tx.isClosed {
+		return errors.New("cannot delete from a closed transaction")
+	}
+
+	return tx.write(&journalEntry{key: key.Bytes(), isDelete: true})
+}
+
+// write writes entry to the transaction, and moves the transaction to a journal
+// once it becomes too big for badger
+func (tx *BadgerDBTransaction) write(entry *journalEntry) error {
+	if tx.journal != nil {
+		return tx.journal.append(entry)
+	}
+
+	err := entry.writeTo(tx.txn)
+	if errors.Is(err, badger.ErrTxnTooBig) {
+		return tx.moveToJournal(entry)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	tx.writes = append(tx.writes, entry)
+	return nil
+}
+
+// moveToJournal discards the badger transaction, and writes its writes along with
+// the given entry, which didn't fit into it, to a new journal instead
+func (tx *BadgerDBTransaction) moveToJournal(entry *journalEntry) error {
+	log.Debugf("A database transaction of %d writes is too big to be committed at once. "+
+		"Committing it through a journal", len(tx.writes)+1)
+
+	tx.txn.Discard()
+	tx.journal = tx.db.newJournal()
+	writes := tx.writes
+	tx.writes = nil
+	for _, write := range writes {
+		err := tx.journal.append(write)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.journal.append(entry)
+}
+
+// Cursor begins a new cursor over the given bucket.
+func (tx *BadgerDBTransaction) Cursor(bucket *database.Bucket) (database.Cursor, error) {
+	if tx.isClosed {
+		return nil, errors.New("cannot open a cursor from a closed transaction")
+	}
+
+	return tx.db.Cursor(bucket)
+}
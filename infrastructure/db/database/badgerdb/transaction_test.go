@@ -0,0 +1,180 @@
+package badgerdb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+// bigTransactionKeyCount is enough keys to make a transaction too big
+// for the memtable of openSmallBadgerDBForTest
+const bigTransactionKeyCount = 5_000
+
+// openSmallBadgerDBForTest opens a database whose memtable is small enough
+// for a few thousand writes to make a transaction too big for badger
+func openSmallBadgerDBForTest(t *testing.T, path string) *BadgerDB {
+	db, err := NewBadgerDBWithOptions(Options(path, 0).WithMemTableSize(mib).WithValueThreshold(1024))
+	if err != nil {
+		t.Fatalf("NewBadgerDBWithOptions: %s", err)
+	}
+	return db
+}
+
+func bigTransactionKey(i int) *database.Key {
+	return database.MakeBucket([]byte("bucket")).Key([]byte(fmt.Sprintf("key%d", i)))
+}
+
+func writeBigTransaction(t *testing.T, db *BadgerDB, value []byte) *BadgerDBTransaction {
+	dbTx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin: %s", err)
+	}
+	for i := 0; i < bigTransactionKeyCount; i++ {
+		err := dbTx.Put(bigTransactionKey(i), value)
+		if err != nil {
+			t.Fatalf("Put: %s", err)
+		}
+	}
+	badgerDBTx := dbTx.(*BadgerDBTransaction)
+	if badgerDBTx.journal == nil {
+		t.Fatalf("The transaction isn't too big for badger")
+	}
+	return badgerDBTx
+}
+
+func checkBigTransactionValues(t *testing.T, db *BadgerDB, expectedValue []byte) {
+	for i := 0; i < bigTransactionKeyCount; i++ {
+		value, err := db.Get(bigTransactionKey(i))
+		if expectedValue == nil {
+			if !database.IsNotFoundError(err) {
+				t.Fatalf("Expected key %d to not exist, but got: %v", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Get of key %d: %s", i, err)
+		}
+		if string(value) != string(expectedValue) {
+			t.Fatalf("Key %d has value %s instead of %s", i, value, expectedValue)
+		}
+	}
+}
+
+func checkNoJournals(t *testing.T, db *BadgerDB) {
+	err := db.db.View(func(txn *badger.Txn) error {
+		iteratorOptions := badger.DefaultIteratorOptions
+		iteratorOptions.Prefix = []byte("badger-journal/")
+		iterator := txn.NewIterator(iteratorOptions)
+		defer iterator.Close()
+
+		iterator.Rewind()
+		if iterator.Valid() {
+			t.Fatalf("Found leftover journal key %s", iterator.Item().Key())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View: %s", err)
+	}
+}
+
+func TestCommitBigTransaction(t *testing.T) {
+	db := openSmallBadgerDBForTest(t, t.TempDir())
+	defer db.Close()
+
+	dbTx := writeBigTransaction(t, db, []byte("value"))
+	err := dbTx.Commit()
+	if err != nil {
+		t.Fatalf("Commit: %s", err)
+	}
+	checkBigTransactionValues(t, db, []byte("value"))
+	checkNoJournals(t, db)
+
+	// Deletes are journaled as well
+	dbTx2, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin: %s", err)
+	}
+	for i := 0; i < bigTransactionKeyCount; i++ {
+		err := dbTx2.Delete(bigTransactionKey(i))
+		if err != nil {
+			t.Fatalf("Delete: %s", err)
+		}
+	}
+	if dbTx2.(*BadgerDBTransaction).journal == nil {
+		t.Fatalf("The transaction isn't too big for badger")
+	}
+	err = dbTx2.Commit()
+	if err != nil {
+		t.Fatalf("Commit: %s", err)
+	}
+	checkBigTransactionValues(t, db, nil)
+	checkNoJournals(t, db)
+}
+
+func TestRollbackBigTransaction(t *testing.T) {
+	db := openSmallBadgerDBForTest(t, t.TempDir())
+	defer db.Close()
+
+	dbTx := writeBigTransaction(t, db, []byte("value"))
+	err := dbTx.Rollback()
+	if err != nil {
+		t.Fatalf("Rollback: %s", err)
+	}
+	checkBigTransactionValues(t, db, nil)
+	checkNoJournals(t, db)
+}
+
+func TestRecoverJournals(t *testing.T) {
+	path := t.TempDir()
+	db := openSmallBadgerDBForTest(t, path)
+
+	// The node crashes after the first transaction was committed but before it was
+	// applied, and while the second transaction was still being written
+	committedTx := writeBigTransaction(t, db, []byte("committed"))
+	err := committedTx.journal.markCommitted()
+	if err != nil {
+		t.Fatalf("markCommitted: %s", err)
+	}
+	uncommittedTx := writeBigTransaction(t, db, []byte("uncommitted"))
+	err = uncommittedTx.journal.batch.Flush()
+	if err != nil {
+		t.Fatalf("Flush: %s", err)
+	}
+	checkBigTransactionValues(t, db, nil)
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	db = openSmallBadgerDBForTest(t, path)
+	defer db.Close()
+	checkBigTransactionValues(t, db, []byte("committed"))
+	checkNoJournals(t, db)
+}
+
+func TestJournalEntrySerialization(t *testing.T) {
+	entries := []*journalEntry{
+		{key: []byte("key"), value: []byte("value")},
+		{key: []byte("key"), value: []byte{}},
+		{key: []byte("key"), isDelete: true},
+	}
+	for _, entry := range entries {
+		deserializedEntry, err := deserializeJournalEntry(entry.serialize())
+		if err != nil {
+			t.Fatalf("deserializeJournalEntry: %s", err)
+		}
+		if string(deserializedEntry.key) != string(entry.key) ||
+			string(deserializedEntry.value) != string(entry.value) ||
+			deserializedEntry.isDelete != entry.isDelete {
+			t.Fatalf("Entry %+v was deserialized as %+v", entry, deserializedEntry)
+		}
+	}
+
+	_, err := deserializeJournalEntry([]byte{journalEntryPut, 10, 'k'})
+	if err == nil {
+		t.Fatalf("Expected a truncated entry to fail deserialization")
+	}
+}
@@ -0,0 +1,57 @@
+package database
+
+import "bytes"
+
+// SubBuckets returns the direct sub-buckets of the given bucket that contain
+// at least one key. The keys that are directly in the bucket are skipped.
+func SubBuckets(dataAccessor DataAccessor, bucket *Bucket) ([]*Bucket, error) {
+	cursor, err := dataAccessor.Cursor(bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	var subBuckets []*Bucket
+	if !cursor.First() {
+		return subBuckets, nil
+	}
+	for {
+		key, err := cursor.Key()
+		if IsNotFoundError(err) {
+			return subBuckets, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		suffix := key.Suffix()
+		separatorIndex := bytes.IndexByte(suffix, bucketSeparator)
+		if separatorIndex == -1 {
+			if !cursor.Next() {
+				return subBuckets, nil
+			}
+			continue
+		}
+
+		// The sub-bucket is made from the path of the key rather than with
+		// bucket.Bucket, which doesn't add a separator to names that end
+		// with one, so that a sub-bucket named "/" isn't mistaken for bucket
+		subBucketPathLength := len(bucket.Path()) + separatorIndex + 1
+		subBucketPath := make([]byte, subBucketPathLength)
+		copy(subBucketPath, key.Bytes())
+		subBucket := MakeBucket(subBucketPath)
+		subBuckets = append(subBuckets, subBucket)
+
+		// Skip all the keys of the sub-bucket. Seek returns ErrNotFound
+		// when there's no key exactly at the end of the sub-bucket, but
+		// it still moves the cursor to the key after it
+		end := subBucket.End()
+		if end == nil {
+			return subBuckets, nil
+		}
+		err = cursor.Seek(MakeBucket(nil).Key(end))
+		if err != nil && !IsNotFoundError(err) {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package database_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+func TestSubBuckets(t *testing.T) {
+	testForAllDatabaseTypes(t, "TestSubBuckets", testSubBuckets)
+}
+
+func testSubBuckets(t *testing.T, db database.Database, testName string) {
+	root := database.MakeBucket(nil)
+	bucketA := root.Bucket([]byte("a"))
+	bucketB := root.Bucket([]byte("b"))
+	keys := []*database.Key{
+		root.Key([]byte("a-key-in-the-root")),
+		bucketA.Key([]byte("key1")),
+		bucketA.Key([]byte("key2")),
+		bucketA.Bucket([]byte("nested")).Key([]byte("key")),
+		bucketB.Key([]byte("key")),
+		bucketB.Bucket([]byte("/")).Key([]byte("key")),
+		bucketB.Bucket([]byte("c")).Key([]byte("key")),
+		root.Key([]byte("z-key-in-the-root")),
+	}
+	for _, key := range keys {
+		err := db.Put(key, []byte("value"))
+		if err != nil {
+			t.Fatalf("%s: Put unexpectedly failed: %s", testName, err)
+		}
+	}
+
+	subBuckets, err := database.SubBuckets(db, root)
+	if err != nil {
+		t.Fatalf("%s: SubBuckets unexpectedly failed: %s", testName, err)
+	}
+	expectedSubBuckets := []*database.Bucket{bucketA, bucketB}
+	if !reflect.DeepEqual(subBuckets, expectedSubBuckets) {
+		t.Fatalf("%s: SubBuckets returned wrong buckets. Want: %s, got: %s",
+			testName, expectedSubBuckets, subBuckets)
+	}
+
+	subBuckets, err = database.SubBuckets(db, bucketA)
+	if err != nil {
+		t.Fatalf("%s: SubBuckets unexpectedly failed: %s", testName, err)
+	}
+	expectedSubBuckets = []*database.Bucket{bucketA.Bucket([]byte("nested"))}
+	if !reflect.DeepEqual(subBuckets, expectedSubBuckets) {
+		t.Fatalf("%s: SubBuckets returned wrong buckets. Want: %s, got: %s",
+			testName, expectedSubBuckets, subBuckets)
+	}
+
+	// A sub-bucket named with the separator doesn't hide the sub-buckets after it
+	subBuckets, err = database.SubBuckets(db, bucketB)
+	if err != nil {
+		t.Fatalf("%s: SubBuckets unexpectedly failed: %s", testName, err)
+	}
+	expectedSubBuckets = []*database.Bucket{
+		database.MakeBucket([]byte("b//")),
+		bucketB.Bucket([]byte("c")),
+	}
+	if !reflect.DeepEqual(subBuckets, expectedSubBuckets) {
+		t.Fatalf("%s: SubBuckets returned wrong buckets. Want: %s, got: %s",
+			testName, expectedSubBuckets, subBuckets)
+	}
+
+	err = db.Compact(bucketA.Path(), bucketA.End())
+	if err != nil {
+		t.Fatalf("%s: Compact unexpectedly failed: %s", testName, err)
+	}
+	_, err = db.ApproximateSize(bucketA.Path(), bucketA.End())
+	if err != nil {
+		t.Fatalf("%s: ApproximateSize unexpectedly failed: %s", testName, err)
+	}
+}
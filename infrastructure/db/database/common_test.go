@@ -3,9 +3,11 @@ package database_test
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/badgerdb"
 	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
 )
 
@@ -16,6 +18,7 @@ type databasePrepareFunc func(t *testing.T, testName string) (db database.Databa
 // See testForAllDatabaseTypes for further details.
 var databasePrepareFuncs = []databasePrepareFunc{
 	prepareLDBForTest,
+	prepareBadgerDBForTest,
 }
 
 func prepareLDBForTest(t *testing.T, testName string) (db database.Database, name string, teardownFunc func()) {
@@ -40,6 +43,33 @@ func prepareLDBForTest(t *testing.T, testName string) (db database.Database, nam
 	return db, "ldb", teardownFunc
 }
 
+func prepareBadgerDBForTest(t *testing.T, testName string) (db database.Database, name string, teardownFunc func()) {
+	// Create a temp db to run tests against
+	path, err := ioutil.TempDir("", testName)
+	if err != nil {
+		t.Fatalf("%s: TempDir unexpectedly "+
+			"failed: %s", testName, err)
+	}
+	db, err = badgerdb.NewBadgerDB(path, 8)
+	if err != nil {
+		t.Fatalf("%s: Open unexpectedly "+
+			"failed: %s", testName, err)
+	}
+	teardownFunc = func() {
+		err = db.Close()
+		if err != nil {
+			t.Fatalf("%s: Close unexpectedly "+
+				"failed: %s", testName, err)
+		}
+		err = os.RemoveAll(path)
+		if err != nil {
+			t.Fatalf("%s: RemoveAll unexpectedly "+
+				"failed: %s", testName, err)
+		}
+	}
+	return db, "badger", teardownFunc
+}
+
 // testForAllDatabaseTypes runs the given testFunc for every database
 // type defined in databasePrepareFuncs. This is to make sure that
 // all supported database types adhere to the assumptions defined in
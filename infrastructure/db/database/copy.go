@@ -0,0 +1,79 @@
+package database
+
+import "errors"
+
+// ErrCopyInterrupted denotes that a copy was interrupted before it was complete
+var ErrCopyInterrupted = errors.New("the copy was interrupted")
+
+// Copy copies all the entries of the source database into the target database,
+// and commits them in transactions of batchSize entries each. It returns the
+// number of copied entries.
+//
+// Copy is meant for moving the data between database backends, so the source
+// must not be modified while it's being copied, unless it's a Snapshot.
+func Copy(source DataReader, target Database, batchSize int) (int, error) {
+	return CopyInterruptibly(source, target, batchSize, nil)
+}
+
+// CopyInterruptibly is like Copy, but stops copying once the interrupt channel is
+// closed, after which it returns ErrCopyInterrupted. The interrupt is checked after
+// every batch, so the entries that were copied until then remain in the target.
+func CopyInterruptibly(source DataReader, target Database, batchSize int, interrupt <-chan struct{}) (int, error) {
+	cursor, err := source.Cursor(MakeBucket(nil))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close()
+
+	copiedCount := 0
+	dbTx, err := target.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		// dbTx is replaced after every batch, so it's captured by the closure
+		_ = dbTx.RollbackUnlessClosed()
+	}()
+
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			return 0, err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return 0, err
+		}
+
+		// The cursor may reuse the memory of the key and the value on its next iteration
+		keyBytes := append([]byte{}, key.Bytes()...)
+		valueBytes := append([]byte{}, value...)
+		err = dbTx.Put(MakeBucket(nil).Key(keyBytes), valueBytes)
+		if err != nil {
+			return 0, err
+		}
+		copiedCount++
+
+		if copiedCount%batchSize == 0 {
+			err = dbTx.Commit()
+			if err != nil {
+				return 0, err
+			}
+			select {
+			case <-interrupt:
+				return copiedCount, ErrCopyInterrupted
+			default:
+			}
+			dbTx, err = target.Begin()
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	err = dbTx.Commit()
+	if err != nil {
+		return 0, err
+	}
+	return copiedCount, nil
+}
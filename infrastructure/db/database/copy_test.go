@@ -0,0 +1,86 @@
+package database_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+func TestCopy(t *testing.T) {
+	testForAllDatabaseTypes(t, "TestCopy", func(t *testing.T, source database.Database, testName string) {
+		for _, prepareTarget := range databasePrepareFuncs {
+			func() {
+				target, targetType, teardownFunc := prepareTarget(t, testName)
+				defer teardownFunc()
+
+				testCopy(t, source, target, fmt.Sprintf("%s to %s", testName, targetType))
+			}()
+		}
+	})
+}
+
+func testCopy(t *testing.T, source database.Database, target database.Database, testName string) {
+	// Put entries in nested buckets, and more entries than
+	// a single batch, so the copy spans a few transactions
+	bucket := database.MakeBucket([]byte("bucket"))
+	subBucket := bucket.Bucket([]byte("subBucket"))
+	var keys []*database.Key
+	for i := 0; i < 10; i++ {
+		keys = append(keys, bucket.Key([]byte(fmt.Sprintf("key%d", i))))
+		keys = append(keys, subBucket.Key([]byte(fmt.Sprintf("key%d", i))))
+	}
+	for i, key := range keys {
+		err := source.Put(key, []byte(fmt.Sprintf("value%d", i)))
+		if err != nil {
+			t.Fatalf("%s: Put unexpectedly failed: %s", testName, err)
+		}
+	}
+
+	copiedCount, err := database.Copy(source, target, 3)
+	if err != nil {
+		t.Fatalf("%s: Copy unexpectedly failed: %s", testName, err)
+	}
+	if copiedCount != len(keys) {
+		t.Fatalf("%s: Copy returned wrong count. Want: %d, got: %d", testName, len(keys), copiedCount)
+	}
+
+	for i, key := range keys {
+		value, err := target.Get(key)
+		if err != nil {
+			t.Fatalf("%s: Get unexpectedly failed: %s", testName, err)
+		}
+		expectedValue := []byte(fmt.Sprintf("value%d", i))
+		if !bytes.Equal(value, expectedValue) {
+			t.Fatalf("%s: Get returned wrong value. Want: %s, got: %s", testName, expectedValue, value)
+		}
+	}
+}
+
+func TestCopyInterruptibly(t *testing.T) {
+	testForAllDatabaseTypes(t, "TestCopyInterruptibly", func(t *testing.T, source database.Database, testName string) {
+		target, _, teardownFunc := databasePrepareFuncs[0](t, testName)
+		defer teardownFunc()
+
+		bucket := database.MakeBucket([]byte("bucket"))
+		for i := 0; i < 10; i++ {
+			err := source.Put(bucket.Key([]byte(fmt.Sprintf("key%d", i))), []byte("value"))
+			if err != nil {
+				t.Fatalf("%s: Put unexpectedly failed: %s", testName, err)
+			}
+		}
+
+		interrupt := make(chan struct{})
+		close(interrupt)
+		copiedCount, err := database.CopyInterruptibly(source, target, 3, interrupt)
+		if !errors.Is(err, database.ErrCopyInterrupted) {
+			t.Fatalf("%s: CopyInterruptibly returned an unexpected error. Want: %s, got: %v",
+				testName, database.ErrCopyInterrupted, err)
+		}
+		if copiedCount != 3 {
+			t.Fatalf("%s: CopyInterruptibly returned wrong count. Want: %d, got: %d", testName, 3, copiedCount)
+		}
+	})
+}
@@ -14,8 +14,19 @@ type Database interface {
 	// Begin begins a new database transaction.
 	Begin() (Transaction, error)
 
-	// Compact compacts the database instance.
-	Compact() error
+	// Snapshot takes a consistent read-only snapshot of the database.
+	// The snapshot must be released once it's no longer needed.
+	Snapshot() (Snapshot, error)
+
+	// Compact compacts the keys of the database in the range [start, end).
+	// A nil start means the beginning of the database, and a nil end means
+	// its end.
+	Compact(start, end []byte) error
+
+	// ApproximateSize returns the approximate size in bytes that the keys
+	// in the range [start, end) occupy on disk. A nil start means the
+	// beginning of the database, and a nil end means its end.
+	ApproximateSize(start, end []byte) (uint64, error)
 
 	// Close closes the database.
 	Close() error
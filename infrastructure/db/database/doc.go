@@ -6,8 +6,10 @@ Package database provides a database for kaspad.
 This package provides a database layer to store and retrieve data in a simple
 and efficient manner.
 
-The current backend is ffldb, which makes use of leveldb, flat files, and strict
-checksums in key areas to ensure data integrity.
+Backends are registered as drivers with RegisterDriver, and are selected by
+their type with Open. The current backends are ldb, which makes use of leveldb,
+and badgerdb, which makes use of badger. Databases can be moved between backends
+with Copy.
 
 Implementors of additional backends are required to implement the following interfaces:
 
@@ -27,6 +29,15 @@ Note: transactions provide data consistency over the state of the database as it
 when the transaction started. There is NO guarantee that if one puts data into the
 transaction then it will be available to get within the same transaction.
 
+# Snapshot
+
+This is a read-only view of the database as it was when the snapshot was taken.
+Snapshots serve as read transactions with snapshot isolation: writes that are
+committed after the snapshot was taken are not visible through it, and readers
+and writers never wait for each other. Long scans should be done over a snapshot
+rather than over the database, so that they observe a consistent state without
+holding the locks that writers need.
+
 # Cursor
 
 This iterates over database entries given some bucket.
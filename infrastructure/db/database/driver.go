@@ -0,0 +1,53 @@
+package database
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Driver defines a database backend that can be selected by its type
+type Driver struct {
+	// DbType is the unique name of the database backend
+	DbType string
+
+	// Open opens the database at the given path, and creates it if it
+	// doesn't exist. cacheSizeMiB is the amount of memory in MiB that
+	// the backend may use for caching.
+	Open func(path string, cacheSizeMiB int) (Database, error)
+}
+
+var drivers = make(map[string]*Driver)
+
+// RegisterDriver adds a database backend to the available backends.
+// It returns an error if a backend of the same type was already registered.
+func RegisterDriver(driver Driver) error {
+	if _, exists := drivers[driver.DbType]; exists {
+		return errors.Errorf("driver %s is already registered", driver.DbType)
+	}
+
+	drivers[driver.DbType] = &driver
+	return nil
+}
+
+// SupportedDrivers returns a sorted slice of the types of the
+// registered database backends.
+func SupportedDrivers() []string {
+	supportedDrivers := make([]string, 0, len(drivers))
+	for dbType := range drivers {
+		supportedDrivers = append(supportedDrivers, dbType)
+	}
+	sort.Strings(supportedDrivers)
+	return supportedDrivers
+}
+
+// Open opens the database of the given backend type at the given path,
+// and creates it if it doesn't exist.
+func Open(dbType string, path string, cacheSizeMiB int) (Database, error) {
+	driver, ok := drivers[dbType]
+	if !ok {
+		return nil, errors.Errorf("unknown database type %s. Supported types: %s", dbType, SupportedDrivers())
+	}
+
+	return driver.Open(path, cacheSizeMiB)
+}
@@ -0,0 +1,37 @@
+package database_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+)
+
+func TestOpen(t *testing.T) {
+	path, err := ioutil.TempDir("", "TestOpen")
+	if err != nil {
+		t.Fatalf("TempDir unexpectedly failed: %s", err)
+	}
+	defer os.RemoveAll(path)
+
+	_, err = database.Open("unknown", path, 8)
+	if err == nil {
+		t.Fatalf("Open unexpectedly succeeded for an unknown database type")
+	}
+
+	db, err := database.Open(ldb.DbType, path, 8)
+	if err != nil {
+		t.Fatalf("Open unexpectedly failed: %s", err)
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("Close unexpectedly failed: %s", err)
+	}
+
+	err = database.RegisterDriver(database.Driver{DbType: ldb.DbType})
+	if err == nil {
+		t.Fatalf("RegisterDriver unexpectedly succeeded for an already registered type")
+	}
+}
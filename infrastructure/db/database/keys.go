@@ -78,3 +78,21 @@ func (b *Bucket) Key(suffix []byte) *Key {
 func (b *Bucket) Path() []byte {
 	return b.path
 }
+
+func (b *Bucket) String() string {
+	return hex.EncodeToString(b.path)
+}
+
+// End returns the smallest path that is greater than the paths of all
+// the keys in the bucket, or nil if there's no such path.
+func (b *Bucket) End() []byte {
+	end := make([]byte, len(b.path))
+	copy(end, b.path)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
@@ -85,3 +85,36 @@ func TestBucketKey(t *testing.T) {
 		}
 	}
 }
+
+func TestBucketEnd(t *testing.T) {
+	tests := []struct {
+		path        []byte
+		expectedEnd []byte
+	}{
+		{
+			path:        nil,
+			expectedEnd: nil,
+		},
+		{
+			path:        []byte("hello/"),
+			expectedEnd: []byte("hello0"),
+		},
+		{
+			path:        []byte{1, 0xff, 0xff},
+			expectedEnd: []byte{2},
+		},
+		{
+			path:        []byte{0xff, 0xff},
+			expectedEnd: nil,
+		},
+	}
+
+	for _, test := range tests {
+		bucket := &Bucket{path: test.path}
+		end := bucket.End()
+		if !bytes.Equal(end, test.expectedEnd) {
+			t.Errorf("TestBucketEnd: got wrong end for path %x. Want: %x, got: %x",
+				test.path, test.expectedEnd, end)
+		}
+	}
+}
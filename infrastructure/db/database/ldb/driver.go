@@ -0,0 +1,23 @@
+package ldb
+
+import (
+	"fmt"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+// DbType is the type of the leveldb database backend
+const DbType = "leveldb"
+
+func init() {
+	driver := database.Driver{
+		DbType: DbType,
+		Open: func(path string, cacheSizeMiB int) (database.Database, error) {
+			return NewLevelDB(path, cacheSizeMiB)
+		},
+	}
+	err := database.RegisterDriver(driver)
+	if err != nil {
+		panic(fmt.Sprintf("failed to register the %s database driver: %s", DbType, err))
+	}
+}
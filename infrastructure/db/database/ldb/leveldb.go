@@ -48,12 +48,43 @@ func NewLevelDB(path string, cacheSizeMiB int) (*LevelDB, error) {
 	return db, nil
 }
 
-// Compact compacts the leveldb instance.
-func (db *LevelDB) Compact() error {
-	err := db.ldb.CompactRange(util.Range{Start: nil, Limit: nil})
+// Compact compacts the keys of the leveldb instance in the range [start, end).
+// A nil start means the beginning of the database, and a nil end means its end.
+func (db *LevelDB) Compact(start, end []byte) error {
+	err := db.ldb.CompactRange(util.Range{Start: start, Limit: end})
 	return errors.WithStack(err)
 }
 
+// ApproximateSize returns the approximate size in bytes that the keys in the
+// range [start, end) occupy on disk. A nil start means the beginning of the
+// database, and a nil end means its end.
+func (db *LevelDB) ApproximateSize(start, end []byte) (uint64, error) {
+	if end != nil {
+		sizes, err := db.ldb.SizeOf([]util.Range{{Start: start, Limit: end}})
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		return uint64(sizes.Sum()), nil
+	}
+
+	// leveldb treats a nil limit as the smallest key, so the size up to the
+	// end of the database is the total size minus the size before start
+	stats := &leveldb.DBStats{}
+	err := db.ldb.Stats(stats)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	totalSize := stats.LevelSizes.Sum()
+	sizes, err := db.ldb.SizeOf([]util.Range{{Start: nil, Limit: start}})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	if sizes.Sum() > totalSize {
+		return 0, nil
+	}
+	return uint64(totalSize - sizes.Sum()), nil
+}
+
 // Close closes the leveldb instance.
 func (db *LevelDB) Close() error {
 	err := db.ldb.Close()
@@ -0,0 +1,26 @@
+package database
+
+// DataReader defines the read-only part of the DataAccessor interface.
+type DataReader interface {
+	// Get gets the value for the given key. It returns
+	// ErrNotFound if the given key does not exist.
+	Get(key *Key) ([]byte, error)
+
+	// Has returns true if the database does contains the
+	// given key.
+	Has(key *Key) (bool, error)
+
+	// Cursor begins a new cursor over the given bucket.
+	Cursor(bucket *Bucket) (Cursor, error)
+}
+
+// Snapshot is a read-only view of the database as it was when the
+// snapshot was taken. Writes to the database after that are not
+// visible through the snapshot.
+type Snapshot interface {
+	DataReader
+
+	// Release releases the snapshot. The snapshot, and cursors
+	// that were opened over it, may not be used after that.
+	Release()
+}
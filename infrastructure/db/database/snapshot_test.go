@@ -0,0 +1,145 @@
+package database_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+func TestSnapshot(t *testing.T) {
+	testForAllDatabaseTypes(t, "TestSnapshot", testSnapshot)
+}
+
+func testSnapshot(t *testing.T, db database.Database, testName string) {
+	bucket := database.MakeBucket([]byte("bucket"))
+	key1 := bucket.Key([]byte("key1"))
+	key2 := bucket.Key([]byte("key2"))
+	err := db.Put(key1, []byte("value1"))
+	if err != nil {
+		t.Fatalf("%s: Put unexpectedly failed: %s", testName, err)
+	}
+
+	snapshot, err := db.Snapshot()
+	if err != nil {
+		t.Fatalf("%s: Snapshot unexpectedly failed: %s", testName, err)
+	}
+	defer snapshot.Release()
+
+	// Writes after the snapshot was taken must not be visible through it
+	err = db.Put(key1, []byte("newValue1"))
+	if err != nil {
+		t.Fatalf("%s: Put unexpectedly failed: %s", testName, err)
+	}
+	err = db.Put(key2, []byte("value2"))
+	if err != nil {
+		t.Fatalf("%s: Put unexpectedly failed: %s", testName, err)
+	}
+
+	value, err := snapshot.Get(key1)
+	if err != nil {
+		t.Fatalf("%s: Get unexpectedly failed: %s", testName, err)
+	}
+	if !bytes.Equal(value, []byte("value1")) {
+		t.Fatalf("%s: Get returned wrong value. Want: %s, got: %s", testName, "value1", value)
+	}
+	_, err = snapshot.Get(key2)
+	if !database.IsNotFoundError(err) {
+		t.Fatalf("%s: Get returned wrong error. Want: ErrNotFound, got: %v", testName, err)
+	}
+	exists, err := snapshot.Has(key2)
+	if err != nil {
+		t.Fatalf("%s: Has unexpectedly failed: %s", testName, err)
+	}
+	if exists {
+		t.Fatalf("%s: Has unexpectedly returned true for a key that was put after the snapshot", testName)
+	}
+
+	cursor, err := snapshot.Cursor(bucket)
+	if err != nil {
+		t.Fatalf("%s: Cursor unexpectedly failed: %s", testName, err)
+	}
+	defer cursor.Close()
+	count := 0
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		count++
+	}
+	if count != 1 {
+		t.Fatalf("%s: the cursor of the snapshot iterated over %d keys instead of 1", testName, count)
+	}
+}
+
+func TestSnapshotConcurrentWrites(t *testing.T) {
+	testForAllDatabaseTypes(t, "TestSnapshotConcurrentWrites", testSnapshotConcurrentWrites)
+}
+
+func testSnapshotConcurrentWrites(t *testing.T, db database.Database, testName string) {
+	bucket := database.MakeBucket([]byte("bucket"))
+	const keyCount = 100
+	for i := 0; i < keyCount; i++ {
+		err := db.Put(bucket.Key([]byte{byte(i)}), []byte{0})
+		if err != nil {
+			t.Fatalf("%s: Put unexpectedly failed: %s", testName, err)
+		}
+	}
+
+	snapshot, err := db.Snapshot()
+	if err != nil {
+		t.Fatalf("%s: Snapshot unexpectedly failed: %s", testName, err)
+	}
+	defer snapshot.Release()
+
+	cursor, err := snapshot.Cursor(bucket)
+	if err != nil {
+		t.Fatalf("%s: Cursor unexpectedly failed: %s", testName, err)
+	}
+	defer cursor.Close()
+
+	// Every key is rewritten, and a new key is added, while the cursor of the snapshot
+	// is in the middle of the bucket. The writes must not wait for the cursor.
+	writesDone := make(chan error)
+	ok := cursor.First()
+	go func() {
+		dbTx, err := db.Begin()
+		if err != nil {
+			writesDone <- err
+			return
+		}
+		defer dbTx.RollbackUnlessClosed()
+		for i := 0; i <= keyCount; i++ {
+			err := dbTx.Put(bucket.Key([]byte{byte(i)}), []byte{1})
+			if err != nil {
+				writesDone <- err
+				return
+			}
+		}
+		writesDone <- dbTx.Commit()
+	}()
+	err = <-writesDone
+	if err != nil {
+		t.Fatalf("%s: the concurrent writes unexpectedly failed: %s", testName, err)
+	}
+
+	count := 0
+	for ; ok; ok = cursor.Next() {
+		value, err := cursor.Value()
+		if err != nil {
+			t.Fatalf("%s: Value unexpectedly failed: %s", testName, err)
+		}
+		if !bytes.Equal(value, []byte{0}) {
+			t.Fatalf("%s: the snapshot observed a value that was written after it was taken", testName)
+		}
+		count++
+	}
+	if count != keyCount {
+		t.Fatalf("%s: the cursor of the snapshot iterated over %d keys instead of %d", testName, count, keyCount)
+	}
+
+	value, err := db.Get(bucket.Key([]byte{0}))
+	if err != nil {
+		t.Fatalf("%s: Get unexpectedly failed: %s", testName, err)
+	}
+	if !bytes.Equal(value, []byte{1}) {
+		t.Fatalf("%s: the database didn't observe the concurrent writes", testName)
+	}
+}
@@ -0,0 +1,86 @@
+package intentlog
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+var intentLogBucket = database.MakeBucket([]byte("intent-log"))
+
+// Intent is an operation that was recorded in an IntentLog and wasn't completed yet
+type Intent struct {
+	ID      []byte
+	Payload []byte
+}
+
+// IntentLog records operations that span more than a single database
+// commit, so that an operation that was interrupted by a crash can be
+// found at startup and either rolled forward or rolled back.
+//
+// An intent is recorded before the first commit of its operation, and
+// completed after the last one. Since the database writes its commits to
+// an append-only journal, an intent is always durable if any of the
+// commits that follow it are.
+type IntentLog struct {
+	db     database.Database
+	bucket *database.Bucket
+}
+
+// New returns the intent log with the given name. Intent logs with
+// different names are independent of each other
+func New(db database.Database, name string) *IntentLog {
+	return &IntentLog{
+		db:     db,
+		bucket: intentLogBucket.Bucket([]byte(name)),
+	}
+}
+
+// Record records the intent to perform the operation with the given ID.
+// Recording an intent that is already pending replaces its payload
+func (il *IntentLog) Record(id []byte, payload []byte) error {
+	return il.db.Put(il.bucket.Key(id), payload)
+}
+
+// Complete marks the operation with the given ID as completed, either
+// because it was fully applied or because it was rolled back. Completing
+// an intent that isn't pending does nothing
+func (il *IntentLog) Complete(id []byte) error {
+	return il.db.Delete(il.bucket.Key(id))
+}
+
+// CompleteIn is like Complete, but completes the intent as part of the given
+// database transaction, so that it's completed atomically with the last
+// commit of its operation
+func (il *IntentLog) CompleteIn(dataAccessor database.DataAccessor, id []byte) error {
+	return dataAccessor.Delete(il.bucket.Key(id))
+}
+
+// Pending returns all the intents that were recorded and weren't completed yet
+func (il *IntentLog) Pending() ([]*Intent, error) {
+	cursor, err := il.db.Cursor(il.bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	var intents []*Intent
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			return nil, err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return nil, err
+		}
+
+		// The cursor may reuse its buffers, so the key and value are copied
+		id := make([]byte, len(key.Suffix()))
+		copy(id, key.Suffix())
+		payload := make([]byte, len(value))
+		copy(payload, value)
+
+		intents = append(intents, &Intent{ID: id, Payload: payload})
+	}
+
+	return intents, nil
+}
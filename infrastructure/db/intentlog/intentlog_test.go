@@ -0,0 +1,94 @@
+package intentlog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+)
+
+func TestIntentLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestIntentLog")
+	if err != nil {
+		t.Fatalf("TempDir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := ldb.NewLevelDB(dir, 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %+v", err)
+	}
+
+	intentLog := New(db, "test")
+	otherIntentLog := New(db, "other")
+
+	err = intentLog.Record([]byte("completed"), []byte("payload"))
+	if err != nil {
+		t.Fatalf("Record: %+v", err)
+	}
+	err = intentLog.Record([]byte("interrupted"), []byte("payload"))
+	if err != nil {
+		t.Fatalf("Record: %+v", err)
+	}
+	err = otherIntentLog.Record([]byte("other"), nil)
+	if err != nil {
+		t.Fatalf("Record: %+v", err)
+	}
+
+	// Simulate an operation whose commits were applied, followed by a crash
+	// in the middle of another operation
+	dataKey := database.MakeBucket([]byte("data")).Key([]byte("key"))
+	err = db.Put(dataKey, []byte("value"))
+	if err != nil {
+		t.Fatalf("Put: %+v", err)
+	}
+	err = intentLog.Complete([]byte("completed"))
+	if err != nil {
+		t.Fatalf("Complete: %+v", err)
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("Close: %+v", err)
+	}
+
+	db, err = ldb.NewLevelDB(dir, 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %+v", err)
+	}
+	defer db.Close()
+
+	intentLog = New(db, "test")
+	pending, err := intentLog.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %+v", err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending intent but got %d", len(pending))
+	}
+	if !bytes.Equal(pending[0].ID, []byte("interrupted")) || !bytes.Equal(pending[0].Payload, []byte("payload")) {
+		t.Fatalf("unexpected pending intent %s: %s", pending[0].ID, pending[0].Payload)
+	}
+
+	err = intentLog.Complete(pending[0].ID)
+	if err != nil {
+		t.Fatalf("Complete: %+v", err)
+	}
+	pending, err = intentLog.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %+v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending intents but got %d", len(pending))
+	}
+
+	otherPending, err := New(db, "other").Pending()
+	if err != nil {
+		t.Fatalf("Pending: %+v", err)
+	}
+	if len(otherPending) != 1 {
+		t.Fatalf("expected the other intent log to be independent")
+	}
+}
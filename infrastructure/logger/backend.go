@@ -54,6 +54,7 @@ const logsBuffer = 0
 // subsystems.
 type Backend struct {
 	flag      uint32
+	format    Format
 	isRunning uint32
 	writers   []logWriter
 	writeChan chan logEntry
@@ -64,7 +65,7 @@ type Backend struct {
 // the package's defaults as determined through the LOGFLAGS environment
 // variable.
 func NewBackendWithFlags(flags uint32) *Backend {
-	return &Backend{flag: flags, writeChan: make(chan logEntry, logsBuffer)}
+	return &Backend{flag: flags, format: FormatText, writeChan: make(chan logEntry, logsBuffer)}
 }
 
 // NewBackend creates a new logger backend.
@@ -136,6 +137,16 @@ func (b *Backend) AddLogFileWithCustomRotator(logFile string, logLevel Level, th
 	return nil
 }
 
+// SetFormat sets the format the backend writes the log lines in. It can't be
+// changed once the backend is running.
+func (b *Backend) SetFormat(format Format) error {
+	if b.IsRunning() {
+		return errors.New("The logger is already running")
+	}
+	b.format = format
+	return nil
+}
+
 // Run launches the logger backend in a separate go-routine. should only be called once.
 func (b *Backend) Run() error {
 	if !atomic.CompareAndSwapUint32(&b.isRunning, 0, 1) {
@@ -187,5 +198,6 @@ func (b *Backend) Close() {
 // Backend b. A tag describes the subsystem and is included in all log
 // messages. The logger uses the info verbosity level by default.
 func (b *Backend) Logger(subsystemTag string) *Logger {
-	return &Logger{LevelOff, subsystemTag, b, b.writeChan}
+	level := LevelOff
+	return &Logger{lvl: &level, tag: subsystemTag, b: b, writeChan: b.writeChan}
 }
@@ -10,6 +10,13 @@ Backends can write to any io.Writer, including multi-writers created by
 io.MultiWriter. Multi-writers allow log output to be written to many writers,
 including standard output and log files.
 
+Messages may carry contextual fields, such as the peer, block hash or
+transaction ID they're about, which are attached by deriving a logger with
+Logger.With. A Backend writes its lines either in the default text format, with
+the fields appended as key=value, or in the JSON format, one object per line.
+Messages that may be logged at a high volume can be rate limited by deriving a
+logger with Logger.Sampled.
+
 Optional logging behavior can be specified by using the LOGFLAGS environment
 variable and overridden per-Backend by using the WithFlags call option. Multiple
 LOGFLAGS options can be specified, separated by commas. The following options
@@ -0,0 +1,32 @@
+package logger
+
+import "fmt"
+
+// Field is a key-value pair of context that's attached to log messages. In the
+// text format it's appended to the message as key=value, and in the JSON format
+// it's a member of the object of the message.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// NewField returns a field with the given key and value. The value is only
+// formatted when a message it's attached to is written.
+func NewField(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Peer returns a field of the peer a message is about
+func Peer(peer fmt.Stringer) Field {
+	return NewField("peer", peer)
+}
+
+// BlockHash returns a field of the hash of the block a message is about
+func BlockHash(blockHash fmt.Stringer) Field {
+	return NewField("block", blockHash)
+}
+
+// TxID returns a field of the ID of the transaction a message is about
+func TxID(txID fmt.Stringer) Field {
+	return NewField("txid", txID)
+}
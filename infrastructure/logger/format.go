@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kaspanet/kaspad/util/mstime"
+)
+
+// Format is a format of the log lines a Backend writes
+type Format string
+
+const (
+	// FormatText is the default format, 'YYYY-MM-DD hh:mm:ss.sss [LVL] TAG: message key=value'
+	FormatText Format = "text"
+
+	// FormatJSON writes every message as a JSON object in a line of its own, with
+	// the time, level, subsystem and message as members along with the fields
+	FormatJSON Format = "json"
+)
+
+// FormatFromString returns the format with the given name, and false if there's
+// no such format
+func FormatFromString(s string) (Format, bool) {
+	switch Format(strings.ToLower(s)) {
+	case FormatText:
+		return FormatText, true
+	case FormatJSON:
+		return FormatJSON, true
+	default:
+		return FormatText, false
+	}
+}
+
+// formatEntry formats a log line of the given message, including its trailing newline,
+// in the format of the backend
+func (b *Backend) formatEntry(t mstime.Time, lvl Level, tag string, file string, line int,
+	message string, fields []Field) []byte {
+
+	buf := make([]byte, 0, normalLogSize)
+	if b.format == FormatJSON {
+		buf = appendJSONEntry(buf, t, lvl, tag, file, line, message, fields)
+		return append(buf, '\n')
+	}
+
+	formatHeader(&buf, t, lvl.String(), tag, file, line)
+	buf = append(buf, message...)
+	for _, field := range fields {
+		buf = append(buf, ' ')
+		buf = append(buf, field.Key...)
+		buf = append(buf, '=')
+		value := fmt.Sprint(field.Value)
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			buf = strconv.AppendQuote(buf, value)
+		} else {
+			buf = append(buf, value...)
+		}
+	}
+	return append(buf, '\n')
+}
+
+func appendJSONEntry(buf []byte, t mstime.Time, lvl Level, tag string, file string, line int,
+	message string, fields []Field) []byte {
+
+	buf = append(buf, `{"time":`...)
+	buf = appendJSONString(buf, t.ToNativeTime().UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+	buf = append(buf, `,"level":`...)
+	buf = appendJSONString(buf, lvl.String())
+	buf = append(buf, `,"subsystem":`...)
+	buf = appendJSONString(buf, tag)
+	if file != "" {
+		buf = append(buf, `,"file":`...)
+		buf = appendJSONString(buf, file)
+		buf = append(buf, `,"line":`...)
+		buf = strconv.AppendInt(buf, int64(line), 10)
+	}
+	buf = append(buf, `,"message":`...)
+	buf = appendJSONString(buf, message)
+	for _, field := range fields {
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, field.Key)
+		buf = append(buf, ':')
+		buf = appendJSONValue(buf, field.Value)
+	}
+	return append(buf, '}')
+}
+
+// appendJSONValue appends numbers and booleans as they are, and any other value
+// as the string it's formatted to
+func appendJSONValue(buf []byte, value interface{}) []byte {
+	switch value := value.(type) {
+	case bool:
+		return strconv.AppendBool(buf, value)
+	case int:
+		return strconv.AppendInt(buf, int64(value), 10)
+	case int32:
+		return strconv.AppendInt(buf, int64(value), 10)
+	case int64:
+		return strconv.AppendInt(buf, value, 10)
+	case uint:
+		return strconv.AppendUint(buf, uint64(value), 10)
+	case uint32:
+		return strconv.AppendUint(buf, uint64(value), 10)
+	case uint64:
+		return strconv.AppendUint(buf, value, 10)
+	case float64:
+		if !math.IsInf(value, 0) && !math.IsNaN(value) {
+			return strconv.AppendFloat(buf, value, 'g', -1, 64)
+		}
+	}
+	return appendJSONString(buf, fmt.Sprint(value))
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends the given string as a quoted JSON string. Invalid UTF-8
+// is decoded, and therefore appended, as the replacement character.
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case r == '"' || r == '\\':
+			buf = append(buf, '\\', byte(r))
+		case r == '\n':
+			buf = append(buf, '\\', 'n')
+		case r == '\r':
+			buf = append(buf, '\\', 'r')
+		case r == '\t':
+			buf = append(buf, '\\', 't')
+		case r < 0x20:
+			buf = append(buf, '\\', 'u', '0', '0', hexDigits[r>>4], hexDigits[r&0xf])
+		default:
+			buf = utf8.AppendRune(buf, r)
+		}
+	}
+	return append(buf, '"')
+}
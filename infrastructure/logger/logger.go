@@ -135,6 +135,16 @@ func SupportedSubsystems() []string {
 	return subsystems
 }
 
+// SubsystemLevel returns the logging level of the given subsystem, and false if
+// there's no such subsystem
+func SubsystemLevel(subsystemID string) (Level, bool) {
+	logger, ok := getSubsystem(subsystemID)
+	if !ok {
+		return LevelOff, false
+	}
+	return logger.Level(), true
+}
+
 func getSubsystem(tag string) (logger *Logger, ok bool) {
 	subsystemLoggersMutex.Lock()
 	defer subsystemLoggersMutex.Unlock()
@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bufferWriteCloser struct {
+	bytes.Buffer
+}
+
+func (*bufferWriteCloser) Close() error {
+	return nil
+}
+
+// writeLogs runs a backend of the given format, lets logFunc log to a logger of it
+// and returns the lines the backend wrote
+func writeLogs(t *testing.T, format Format, logFunc func(log *Logger)) []string {
+	backend := NewBackendWithFlags(0)
+	err := backend.SetFormat(format)
+	if err != nil {
+		t.Fatalf("SetFormat: %s", err)
+	}
+	writer := &bufferWriteCloser{}
+	err = backend.AddLogWriter(writer, LevelTrace)
+	if err != nil {
+		t.Fatalf("AddLogWriter: %s", err)
+	}
+	err = backend.Run()
+	if err != nil {
+		t.Fatalf("Run: %s", err)
+	}
+	log := backend.Logger("TEST")
+	log.SetLevel(LevelDebug)
+	logFunc(log)
+	backend.Close()
+
+	return strings.Split(strings.TrimSuffix(writer.String(), "\n"), "\n")
+}
+
+func TestTextFormat(t *testing.T) {
+	lines := writeLogs(t, FormatText, func(log *Logger) {
+		log.With(NewField("peer", "1.2.3.4:16111"), NewField("note", "a b")).Infof("Hello %s", "world")
+		log.Debug("no", "fields")
+	})
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines but got %d: %q", len(lines), lines)
+	}
+	if !strings.HasSuffix(lines[0], ` [INF] TEST: Hello world peer=1.2.3.4:16111 note="a b"`) {
+		t.Fatalf("Unexpected line with fields: %s", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], " [DBG] TEST: no fields") {
+		t.Fatalf("Unexpected line without fields: %s", lines[1])
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	lines := writeLogs(t, FormatJSON, func(log *Logger) {
+		log.With(NewField("peer", "1.2.3.4:16111"), NewField("count", 3), NewField("ok", true)).
+			Warnf("Quote \" and\nnewline")
+	})
+	if len(lines) != 1 {
+		t.Fatalf("Expected a single line but got %d: %q", len(lines), lines)
+	}
+	var entry map[string]interface{}
+	err := json.Unmarshal([]byte(lines[0]), &entry)
+	if err != nil {
+		t.Fatalf("The line %s isn't valid JSON: %s", lines[0], err)
+	}
+	expected := map[string]interface{}{
+		"level":     "WRN",
+		"subsystem": "TEST",
+		"message":   "Quote \" and\nnewline",
+		"peer":      "1.2.3.4:16111",
+		"count":     float64(3),
+		"ok":        true,
+	}
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Fatalf("Expected %s to be %v but got %v in %s", key, value, entry[key], lines[0])
+		}
+	}
+	_, err = time.Parse(time.RFC3339, entry["time"].(string))
+	if err != nil {
+		t.Fatalf("Unexpected time in %s: %s", lines[0], err)
+	}
+}
+
+func TestDerivedLoggerLevel(t *testing.T) {
+	lines := writeLogs(t, FormatText, func(log *Logger) {
+		derived := log.With(NewField("key", "value"))
+		log.SetLevel(LevelInfo)
+		derived.Debugf("dropped")
+		derived.Infof("written")
+	})
+	if len(lines) != 1 || !strings.Contains(lines[0], "written") {
+		t.Fatalf("Expected the derived logger to have the level of its subsystem logger but got %q", lines)
+	}
+}
+
+func TestSampled(t *testing.T) {
+	lines := writeLogs(t, FormatText, func(log *Logger) {
+		sampled := log.Sampled(2, time.Hour)
+		for i := 0; i < 5; i++ {
+			sampled.Infof("message %d", i)
+		}
+		// Messages below the level of the logger don't count towards the rate
+		sampled.Tracef("filtered")
+
+		sampled.sampler.intervalStart = time.Time{}
+		sampled.Infof("after the interval")
+	})
+	expected := []string{"message 0", "message 1", "after the interval suppressed=3"}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines but got %d: %q", len(expected), len(lines), lines)
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, expected[i]) {
+			t.Fatalf("Expected line %d to end with %q but got %s", i, expected[i], line)
+		}
+	}
+}
+
+func TestFormatFromString(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		expected Format
+		ok       bool
+	}{
+		{name: "text", expected: FormatText, ok: true},
+		{name: "JSON", expected: FormatJSON, ok: true},
+		{name: "xml", expected: FormatText, ok: false},
+	} {
+		format, ok := FormatFromString(test.name)
+		if format != test.expected || ok != test.ok {
+			t.Fatalf("FormatFromString(%s): expected (%s, %t) but got (%s, %t)",
+				test.name, test.expected, test.ok, format, ok)
+		}
+	}
+}
@@ -33,20 +33,25 @@
 package logger
 
 import (
-	"bytes"
 	"fmt"
 	"github.com/kaspanet/kaspad/util/mstime"
 	"os"
 	"runtime"
+	"strings"
 	"sync/atomic"
 )
 
 // Logger is a subsystem logger for a Backend.
 type Logger struct {
-	lvl       Level // atomic
+	lvl       *Level // atomic, shared with the loggers derived from this one
 	tag       string
 	b         *Backend
 	writeChan chan<- logEntry
+
+	// fields are attached to every message of the logger, and sampler, if set,
+	// drops the messages above its rate
+	fields  []Field
+	sampler *sampler
 }
 
 type logEntry struct {
@@ -146,12 +151,21 @@ func (l *Logger) Writef(logLevel Level, format string, args ...interface{}) {
 
 // Level returns the current logging level
 func (l *Logger) Level() Level {
-	return Level(atomic.LoadUint32((*uint32)(&l.lvl)))
+	return Level(atomic.LoadUint32((*uint32)(l.lvl)))
 }
 
-// SetLevel changes the logging level to the passed level.
+// SetLevel changes the logging level to the passed level. The level is shared
+// by the subsystem logger and all the loggers derived from it.
 func (l *Logger) SetLevel(level Level) {
-	atomic.StoreUint32((*uint32)(&l.lvl), uint32(level))
+	atomic.StoreUint32((*uint32)(l.lvl), uint32(level))
+}
+
+// With returns a logger that attaches the given fields, on top of the fields
+// of this logger, to every message it writes
+func (l *Logger) With(fields ...Field) *Logger {
+	derived := *l
+	derived.fields = append(l.fields[:len(l.fields):len(l.fields)], fields...)
+	return &derived
 }
 
 // Backend returns the log backend
@@ -172,18 +186,17 @@ func (l *Logger) printf(lvl Level, tag string, format string, args ...interface{
 		file, line = callsite(l.b.flag)
 	}
 
-	buf := make([]byte, 0, normalLogSize)
-
-	formatHeader(&buf, t, lvl.String(), tag, file, line)
-	bytesBuf := bytes.NewBuffer(buf)
-	_, _ = fmt.Fprintf(bytesBuf, format, args...)
-	bytesBuf.WriteByte('\n')
+	fields, ok := l.sampledFields()
+	if !ok {
+		return
+	}
+	log := l.b.formatEntry(t, lvl, tag, file, line, fmt.Sprintf(format, args...), fields)
 
 	if !l.b.IsRunning() {
-		_, _ = fmt.Fprintf(os.Stderr, bytesBuf.String())
+		_, _ = fmt.Fprint(os.Stderr, string(log))
 		panic("Writing to the logger when it's not running")
 	}
-	l.writeChan <- logEntry{bytesBuf.Bytes(), lvl}
+	l.writeChan <- logEntry{log, lvl}
 }
 
 // print outputs a log message to the writer associated with the backend after
@@ -202,15 +215,33 @@ func (l *Logger) print(lvl Level, tag string, args ...interface{}) {
 		file, line = callsite(l.b.flag)
 	}
 
-	buf := make([]byte, 0, normalLogSize)
-	formatHeader(&buf, t, lvl.String(), tag, file, line)
-	bytesBuf := bytes.NewBuffer(buf)
-	_, _ = fmt.Fprintln(bytesBuf, args...)
+	fields, ok := l.sampledFields()
+	if !ok {
+		return
+	}
+	message := strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+	log := l.b.formatEntry(t, lvl, tag, file, line, message, fields)
 
 	if !l.b.IsRunning() {
 		panic("Writing to the logger when it's not running")
 	}
-	l.writeChan <- logEntry{bytesBuf.Bytes(), lvl}
+	l.writeChan <- logEntry{log, lvl}
+}
+
+// sampledFields returns the fields of a message that's about to be written, and
+// false if the sampler of the logger drops it
+func (l *Logger) sampledFields() ([]Field, bool) {
+	if l.sampler == nil {
+		return l.fields, true
+	}
+	suppressedCount, ok := l.sampler.allow()
+	if !ok {
+		return nil, false
+	}
+	if suppressedCount == 0 {
+		return l.fields, true
+	}
+	return append(l.fields[:len(l.fields):len(l.fields)], NewField("suppressed", suppressedCount)), true
 }
 
 // From stdlib log package.
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// sampler limits the rate of the messages of a logger to a burst of messages
+// every interval, and counts the messages it drops
+type sampler struct {
+	burst    uint64
+	interval time.Duration
+
+	lock            sync.Mutex
+	intervalStart   time.Time
+	allowedCount    uint64
+	suppressedCount uint64
+}
+
+// Sampled returns a logger that writes at most burst messages every interval,
+// for messages that may be logged at a high volume, such as the rejections of
+// transactions that peers relay. The first message that's written after some were
+// dropped has the number of dropped messages attached as the "suppressed" field.
+// All the messages of the returned logger, and of the loggers derived from it,
+// share the rate.
+func (l *Logger) Sampled(burst uint64, interval time.Duration) *Logger {
+	derived := *l
+	derived.sampler = &sampler{burst: burst, interval: interval}
+	return &derived
+}
+
+// allow returns whether a message may be written and, if so, the number of
+// messages that were dropped since the last one that was written
+func (s *sampler) allow() (suppressedCount uint64, ok bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.intervalStart) >= s.interval {
+		s.intervalStart = now
+		s.allowedCount = 0
+	}
+	if s.allowedCount >= s.burst {
+		s.suppressedCount++
+		return 0, false
+	}
+	s.allowedCount++
+	suppressedCount = s.suppressedCount
+	s.suppressedCount = 0
+	return suppressedCount, true
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// textContentType is the content type of the Prometheus text format
+const textContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Handler returns an HTTP handler that serves all the registered metrics in the
+// Prometheus text format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", textContentType)
+		err := WriteText(writer)
+		if err != nil {
+			log.Debugf("Error writing the metrics: %s", err)
+		}
+	})
+}
+
+// WriteText writes all the registered metrics to the given writer in the Prometheus
+// text format
+func WriteText(writer io.Writer) error {
+	familiesLock.Lock()
+	familiesCopy := make([]*family, len(families))
+	copy(familiesCopy, families)
+	seriesByFamily := make([][]*series, len(families))
+	for i, metricFamily := range families {
+		seriesByFamily[i] = append([]*series(nil), metricFamily.series...)
+	}
+	familiesLock.Unlock()
+
+	bufferedWriter := bufio.NewWriter(writer)
+	for i, metricFamily := range familiesCopy {
+		bufferedWriter.WriteString("# HELP " + metricFamily.name + " " + escapeHelp(metricFamily.help) + "\n")
+		bufferedWriter.WriteString("# TYPE " + metricFamily.name + " " + string(metricFamily.metricType) + "\n")
+		for _, metricSeries := range seriesByFamily[i] {
+			for _, metricSample := range metricSeries.metric.samples() {
+				bufferedWriter.WriteString(metricFamily.name + metricSample.suffix)
+				labels := metricSeries.labels
+				if metricSample.extraLabel != nil {
+					labels = append(labels[:len(labels):len(labels)], *metricSample.extraLabel)
+				}
+				writeLabels(bufferedWriter, labels)
+				bufferedWriter.WriteString(" " + formatFloat(metricSample.value) + "\n")
+			}
+		}
+	}
+	return bufferedWriter.Flush()
+}
+
+func writeLabels(writer *bufio.Writer, labels []Label) {
+	if len(labels) == 0 {
+		return
+	}
+	writer.WriteString("{")
+	for i, label := range labels {
+		if i > 0 {
+			writer.WriteString(",")
+		}
+		writer.WriteString(label.Name + "=\"" + escapeLabelValue(label.Value) + "\"")
+	}
+	writer.WriteString("}")
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
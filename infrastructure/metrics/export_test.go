@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteText(t *testing.T) {
+	counter := NewCounter("test_counter_total", "A test counter")
+	counter.Add(3)
+	counter.Inc()
+
+	inboundGauge := NewGauge("test_gauge", "A test gauge", Label{Name: "direction", Value: "inbound"})
+	outboundGauge := NewGauge("test_gauge", "A test gauge", Label{Name: "direction", Value: "outbound"})
+	inboundGauge.Set(2)
+	inboundGauge.Add(-0.5)
+	outboundGauge.SetFunction(func() float64 { return 8 })
+
+	histogram := NewHistogram("test_duration_seconds", "A test\nhistogram", []float64{1, 0.1})
+	histogram.ObserveDuration(50 * time.Millisecond)
+	histogram.Observe(0.5)
+	histogram.Observe(2)
+
+	builder := &strings.Builder{}
+	err := WriteText(builder)
+	if err != nil {
+		t.Fatalf("WriteText: %s", err)
+	}
+	expected := `# HELP test_counter_total A test counter
+# TYPE test_counter_total counter
+test_counter_total 4
+# HELP test_gauge A test gauge
+# TYPE test_gauge gauge
+test_gauge{direction="inbound"} 1.5
+test_gauge{direction="outbound"} 8
+# HELP test_duration_seconds A test\nhistogram
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{le="0.1"} 1
+test_duration_seconds_bucket{le="1"} 2
+test_duration_seconds_bucket{le="+Inf"} 3
+test_duration_seconds_sum 2.55
+test_duration_seconds_count 3
+`
+	if !strings.Contains(builder.String(), expected) {
+		t.Fatalf("Expected the metrics to contain:\n%s\nbut got:\n%s", expected, builder.String())
+	}
+}
+
+func TestRegisterTwicePanics(t *testing.T) {
+	NewCounter("test_registered_twice_total", "A counter that's registered twice")
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Expected registering a metric twice to panic")
+		}
+	}()
+	NewCounter("test_registered_twice_total", "A counter that's registered twice")
+}
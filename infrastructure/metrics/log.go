@@ -0,0 +1,7 @@
+package metrics
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+)
+
+var log = logger.RegisterSubSystem("MTRC")
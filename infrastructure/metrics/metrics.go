@@ -0,0 +1,174 @@
+// Package metrics is the registry of the time series metrics of kaspad. The packages that
+// are instrumented declare their metrics as package-level variables, and the registry
+// exports all of them in the Prometheus text format.
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Label is a label of a metric. Metrics that share a name are told apart by their labels.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// DurationBuckets are the upper bounds, in seconds, of the buckets of histograms of
+// durations that take up to several seconds
+var DurationBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1,
+	0.25, 0.5, 1, 2.5, 5, 10}
+
+// Counter is a metric whose value only grows
+type Counter struct {
+	value uint64
+}
+
+// NewCounter registers a new counter
+func NewCounter(name string, help string, labels ...Label) *Counter {
+	counter := &Counter{}
+	register(name, help, typeCounter, labels, counter)
+	return counter
+}
+
+// Inc increments the counter by one
+func (c *Counter) Inc() {
+	atomic.AddUint64(&c.value, 1)
+}
+
+// Add increments the counter by the given delta
+func (c *Counter) Add(delta uint64) {
+	atomic.AddUint64(&c.value, delta)
+}
+
+// Value returns the current value of the counter
+func (c *Counter) Value() uint64 {
+	return atomic.LoadUint64(&c.value)
+}
+
+func (c *Counter) samples() []sample {
+	return []sample{{value: float64(c.Value())}}
+}
+
+// Gauge is a metric whose value may go up and down. Its value is either set directly,
+// or computed by a function whenever the metrics are exported.
+type Gauge struct {
+	bits     uint64
+	lock     sync.Mutex
+	function func() float64
+}
+
+// NewGauge registers a new gauge
+func NewGauge(name string, help string, labels ...Label) *Gauge {
+	gauge := &Gauge{}
+	register(name, help, typeGauge, labels, gauge)
+	return gauge
+}
+
+// Set sets the value of the gauge
+func (g *Gauge) Set(value float64) {
+	atomic.StoreUint64(&g.bits, math.Float64bits(value))
+}
+
+// Add adds the given delta, which may be negative, to the value of the gauge
+func (g *Gauge) Add(delta float64) {
+	for {
+		oldBits := atomic.LoadUint64(&g.bits)
+		newBits := math.Float64bits(math.Float64frombits(oldBits) + delta)
+		if atomic.CompareAndSwapUint64(&g.bits, oldBits, newBits) {
+			return
+		}
+	}
+}
+
+// SetFunction sets a function that computes the value of the gauge whenever it's read,
+// instead of the value that was set. It replaces any function that was set before.
+func (g *Gauge) SetFunction(function func() float64) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.function = function
+}
+
+// Value returns the current value of the gauge
+func (g *Gauge) Value() float64 {
+	g.lock.Lock()
+	function := g.function
+	g.lock.Unlock()
+
+	if function != nil {
+		return function()
+	}
+	return math.Float64frombits(atomic.LoadUint64(&g.bits))
+}
+
+func (g *Gauge) samples() []sample {
+	return []sample{{value: g.Value()}}
+}
+
+// Histogram is a metric that counts observations in buckets of values
+type Histogram struct {
+	upperBounds  []float64
+	lock         sync.Mutex
+	bucketCounts []uint64
+	sum          float64
+	count        uint64
+}
+
+// NewHistogram registers a new histogram with buckets of the given upper bounds
+func NewHistogram(name string, help string, upperBounds []float64, labels ...Label) *Histogram {
+	sortedUpperBounds := make([]float64, len(upperBounds))
+	copy(sortedUpperBounds, upperBounds)
+	sort.Float64s(sortedUpperBounds)
+
+	histogram := &Histogram{
+		upperBounds:  sortedUpperBounds,
+		bucketCounts: make([]uint64, len(sortedUpperBounds)),
+	}
+	register(name, help, typeHistogram, labels, histogram)
+	return histogram
+}
+
+// Observe records the given value
+func (h *Histogram) Observe(value float64) {
+	bucketIndex := sort.SearchFloat64s(h.upperBounds, value)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if bucketIndex < len(h.bucketCounts) {
+		h.bucketCounts[bucketIndex]++
+	}
+	h.sum += value
+	h.count++
+}
+
+// ObserveDuration records the given duration in seconds
+func (h *Histogram) ObserveDuration(duration time.Duration) {
+	h.Observe(duration.Seconds())
+}
+
+func (h *Histogram) samples() []sample {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	samples := make([]sample, 0, len(h.upperBounds)+3)
+	cumulativeCount := uint64(0)
+	for i, upperBound := range h.upperBounds {
+		cumulativeCount += h.bucketCounts[i]
+		samples = append(samples, sample{
+			suffix:     "_bucket",
+			extraLabel: &Label{Name: "le", Value: formatFloat(upperBound)},
+			value:      float64(cumulativeCount),
+		})
+	}
+	samples = append(samples,
+		sample{suffix: "_bucket", extraLabel: &Label{Name: "le", Value: "+Inf"}, value: float64(h.count)},
+		sample{suffix: "_sum", value: h.sum},
+		sample{suffix: "_count", value: float64(h.count)},
+	)
+	return samples
+}
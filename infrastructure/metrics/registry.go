@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type metricType string
+
+const (
+	typeCounter   metricType = "counter"
+	typeGauge     metricType = "gauge"
+	typeHistogram metricType = "histogram"
+)
+
+type metric interface {
+	samples() []sample
+}
+
+// sample is a single value of a metric. Histograms export several samples, which are
+// told apart by the suffix of their name and by an extra label.
+type sample struct {
+	suffix     string
+	extraLabel *Label
+	value      float64
+}
+
+type series struct {
+	labels []Label
+	metric metric
+}
+
+// family is all the series of metrics that share a name
+type family struct {
+	name       string
+	help       string
+	metricType metricType
+	series     []*series
+}
+
+var (
+	families       []*family
+	familiesByName = make(map[string]*family)
+	familiesLock   sync.Mutex
+)
+
+// register adds a metric to the registry. It panics if the metric clashes with a
+// metric that was already registered, since that's a programming error.
+func register(name string, help string, metricType metricType, labels []Label, metric metric) {
+	familiesLock.Lock()
+	defer familiesLock.Unlock()
+
+	metricFamily, ok := familiesByName[name]
+	if !ok {
+		metricFamily = &family{name: name, help: help, metricType: metricType}
+		families = append(families, metricFamily)
+		familiesByName[name] = metricFamily
+	}
+	if metricFamily.metricType != metricType {
+		panic(fmt.Sprintf("metric %s was registered as a %s and as a %s", name, metricFamily.metricType, metricType))
+	}
+	for _, existing := range metricFamily.series {
+		if reflect.DeepEqual(existing.labels, labels) {
+			panic(fmt.Sprintf("metric %s with labels %v was registered twice", name, labels))
+		}
+	}
+	metricFamily.series = append(metricFamily.series, &series{labels: labels, metric: metric})
+}
@@ -0,0 +1,151 @@
+package addressmanager
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+
+	"github.com/kaspanet/kaspad/util/mstime"
+)
+
+const (
+	// newBucketCount is the number of buckets of the new table, which holds the
+	// addresses that were heard of but never connected to
+	newBucketCount = 256
+
+	// triedBucketCount is the number of buckets of the tried table, which holds the
+	// addresses that were connected to successfully
+	triedBucketCount = 64
+
+	// bucketSize is the number of addresses a bucket of either table holds
+	bucketSize = 64
+
+	// newBucketsPerSourceGroup is the number of new buckets that the addresses learned
+	// from a single network group may be in. It bounds the part of the new table that a
+	// peer, or a group of peers in the same network, can fill with addresses of its choice.
+	newBucketsPerSourceGroup = 32
+
+	// triedBucketsPerGroup is the number of tried buckets that the addresses of a single
+	// network group may be in
+	triedBucketsPerGroup = 8
+
+	// maxTriedCollisions is the number of addresses that may wait for a position in the
+	// tried table, while the address that holds it is tested by a feeler connection
+	maxTriedCollisions = 10
+
+	// triedCollisionTimeout is how long the address that holds a position in the tried
+	// table may go untested before it's evicted in favor of the address that collides with it
+	triedCollisionTimeout = 40 * time.Minute
+)
+
+// The bounds of the addresses that are considered terrible, and may be replaced by the
+// addresses that fall on their positions in the new table
+const (
+	terribleAddressMinAge             = time.Minute
+	terribleAddressMaxFutureTimestamp = 10 * time.Minute
+	terribleAddressMaxTimestampAge    = 30 * 24 * time.Hour
+	terribleAddressMaxFailedCount     = connectionFailedCountForRemove - 1
+)
+
+// tablePosition is the position of an address in one of the address tables
+type tablePosition struct {
+	bucket int
+	slot   int
+}
+
+// addressTables places the addresses of the AddressManager in the buckets of the new and
+// tried tables. The position of an address is decided by keyed hashes of its network group
+// and that of the peer it was learned from, so that an attacker can't fill the tables with
+// its own addresses, nor evict the addresses of honest peers at will.
+type addressTables struct {
+	secretKey []byte
+
+	newTable   [newBucketCount][bucketSize]*addressKey
+	triedTable [triedBucketCount][bucketSize]*addressKey
+
+	// triedCollisions holds the addresses that were connected to successfully, but whose
+	// position in the tried table is held by another address, along with the time of the collision
+	triedCollisions map[addressKey]mstime.Time
+}
+
+func newAddressTables(secretKey []byte) *addressTables {
+	return &addressTables{
+		secretKey:       secretKey,
+		triedCollisions: map[addressKey]mstime.Time{},
+	}
+}
+
+func (at *addressTables) hash(data ...[]byte) uint64 {
+	hasher := sha256.New()
+	hasher.Write(at.secretKey)
+	for _, datum := range data {
+		var length [4]byte
+		binary.LittleEndian.PutUint32(length[:], uint32(len(datum)))
+		hasher.Write(length[:])
+		hasher.Write(datum)
+	}
+	return binary.LittleEndian.Uint64(hasher.Sum(nil))
+}
+
+func uint64Bytes(value uint64) []byte {
+	var serialized [8]byte
+	binary.LittleEndian.PutUint64(serialized[:], value)
+	return serialized[:]
+}
+
+func serializeKeyForHash(key addressKey) []byte {
+	serialized := make([]byte, len(key.address)+2)
+	copy(serialized, key.address[:])
+	binary.LittleEndian.PutUint16(serialized[len(key.address):], key.port)
+	return serialized
+}
+
+// newPosition returns the position in the new table of the address with the given key
+// and network group, learned from a peer of sourceGroup
+func (at *addressTables) newPosition(key addressKey, group string, sourceGroup string) tablePosition {
+	sourceBucket := at.hash([]byte(group), []byte(sourceGroup)) % newBucketsPerSourceGroup
+	bucket := at.hash([]byte("new"), []byte(sourceGroup), uint64Bytes(sourceBucket)) % newBucketCount
+	slot := at.hash([]byte("new-slot"), uint64Bytes(bucket), serializeKeyForHash(key)) % bucketSize
+	return tablePosition{bucket: int(bucket), slot: int(slot)}
+}
+
+// triedPosition returns the position in the tried table of the address with the given
+// key and network group
+func (at *addressTables) triedPosition(key addressKey, group string) tablePosition {
+	groupBucket := at.hash(serializeKeyForHash(key)) % triedBucketsPerGroup
+	bucket := at.hash([]byte("tried"), []byte(group), uint64Bytes(groupBucket)) % triedBucketCount
+	slot := at.hash([]byte("tried-slot"), uint64Bytes(bucket), serializeKeyForHash(key)) % bucketSize
+	return tablePosition{bucket: int(bucket), slot: int(slot)}
+}
+
+func (at *addressTables) newOccupant(position tablePosition) *addressKey {
+	return at.newTable[position.bucket][position.slot]
+}
+
+func (at *addressTables) triedOccupant(position tablePosition) *addressKey {
+	return at.triedTable[position.bucket][position.slot]
+}
+
+func (at *addressTables) setNew(position tablePosition, key *addressKey) {
+	at.newTable[position.bucket][position.slot] = key
+}
+
+func (at *addressTables) setTried(position tablePosition, key *addressKey) {
+	at.triedTable[position.bucket][position.slot] = key
+}
+
+// isTerrible returns whether the address is unlikely to be that of a reachable peer,
+// in which case another address may take its position in the new table
+func (a *address) isTerrible(now mstime.Time) bool {
+	// Addresses that were just attempted are kept until the attempt is over
+	if !a.lastAttempt.IsZero() && now.Sub(a.lastAttempt) < terribleAddressMinAge {
+		return false
+	}
+	if a.netAddress.Timestamp.After(now.Add(terribleAddressMaxFutureTimestamp)) {
+		return true
+	}
+	if now.Sub(a.netAddress.Timestamp) > terribleAddressMaxTimestampAge {
+		return true
+	}
+	return a.lastSuccess.IsZero() && a.connectionFailedCount >= terribleAddressMaxFailedCount
+}
@@ -6,6 +6,7 @@ package addressmanager
 
 import (
 	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/network/tor"
 	"github.com/kaspanet/kaspad/util/mstime"
 	"net"
 	"sync"
@@ -16,8 +17,10 @@ import (
 )
 
 const (
-	maxAddresses                   = 4096
 	connectionFailedCountForRemove = 4
+
+	// seededSourceGroup is the source group of the addresses that weren't learned from a peer
+	seededSourceGroup = "seeded"
 )
 
 // addressRandomizer is the interface for the randomizer needed for the AddressManager.
@@ -34,6 +37,19 @@ type addressKey struct {
 type address struct {
 	netAddress            *appmessage.NetAddress
 	connectionFailedCount uint64
+
+	// sourceGroup is the network group of the peer that the address was learned from,
+	// which decides the bucket of the new table that the address is in
+	sourceGroup string
+
+	// isTried is whether the address is in the tried table, rather than the new table
+	isTried     bool
+	lastAttempt mstime.Time
+	lastSuccess mstime.Time
+
+	// services are the services that the peer at the address advertised the last time
+	// an outgoing connection was made to it, or 0 if none ever was
+	services appmessage.ServiceFlag
 }
 
 type ipv6 [net.IPv6len]byte
@@ -49,6 +65,15 @@ var ErrAddressNotFound = errors.New("address not found")
 // NetAddressKey returns a key of the ip address to use it in maps.
 func netAddressKey(netAddress *appmessage.NetAddress) addressKey {
 	key := addressKey{port: netAddress.Port}
+	if netAddress.IsOnion() {
+		// Onion addresses are keyed by their OnionCat IP, so that they are stored
+		// and banned like the addresses of the connections made to them
+		ip, err := tor.OnionCatIP(netAddress.OnionHost)
+		if err == nil {
+			copy(key.address[:], ip)
+		}
+		return key
+	}
 	// all IPv4 can be represented as IPv6.
 	copy(key.address[:], netAddress.IP.To16())
 	return key
@@ -58,6 +83,7 @@ func netAddressKey(netAddress *appmessage.NetAddress) addressKey {
 // peers on the Kaspa network.
 type AddressManager struct {
 	store          *addressStore
+	tables         *addressTables
 	localAddresses *localAddressManager
 	mutex          sync.Mutex
 	cfg            *Config
@@ -75,49 +101,150 @@ func New(cfg *Config, database database.Database) (*AddressManager, error) {
 		return nil, err
 	}
 
-	return &AddressManager{
+	am := &AddressManager{
 		store:          addressStore,
+		tables:         newAddressTables(addressStore.secretKey),
 		localAddresses: localAddresses,
 		random:         NewAddressRandomize(connectionFailedCountForRemove),
 		cfg:            cfg,
-	}, nil
+	}
+	err = am.placeStoredAddresses()
+	if err != nil {
+		return nil, err
+	}
+	return am, nil
+}
+
+// placeStoredAddresses places the addresses of the store in the address tables. The
+// tried addresses are placed first, and the ones that collide are moved to the new table.
+// New addresses that collide are dropped.
+func (am *AddressManager) placeStoredAddresses() error {
+	now := mstime.Now()
+	var newAddresses []*address
+	for _, address := range am.store.getAllNotBanned() {
+		if !address.isTried {
+			newAddresses = append(newAddresses, address)
+			continue
+		}
+		key := netAddressKey(address.netAddress)
+		position := am.tables.triedPosition(key, am.GroupKey(address.netAddress))
+		if am.tables.triedOccupant(position) == nil {
+			am.tables.setTried(position, &key)
+			continue
+		}
+		address.isTried = false
+		err := am.store.updateNotBanned(key, address)
+		if err != nil {
+			return err
+		}
+		newAddresses = append(newAddresses, address)
+	}
+
+	droppedCount := 0
+	for _, address := range newAddresses {
+		key := netAddressKey(address.netAddress)
+		placed, err := am.placeNewNoLock(key, address, now)
+		if err != nil {
+			return err
+		}
+		if !placed {
+			err := am.store.remove(key)
+			if err != nil {
+				return err
+			}
+			droppedCount++
+		}
+	}
+	if droppedCount > 0 {
+		log.Debugf("Dropped %d stored addresses whose positions in the new table are taken", droppedCount)
+	}
+	return nil
+}
+
+// placeNewNoLock places the given address in the new table, in place of the address
+// that holds its position if that one is terrible. It returns false if the position
+// is held by an address that isn't.
+func (am *AddressManager) placeNewNoLock(key addressKey, address *address, now mstime.Time) (bool, error) {
+	if address.sourceGroup == "" {
+		address.sourceGroup = am.GroupKey(address.netAddress)
+	}
+	position := am.tables.newPosition(key, am.GroupKey(address.netAddress), address.sourceGroup)
+	occupantKey := am.tables.newOccupant(position)
+	if occupantKey != nil && *occupantKey != key {
+		if occupant, ok := am.store.getNotBanned(*occupantKey); ok {
+			if !occupant.isTerrible(now) {
+				return false, nil
+			}
+			log.Tracef("Address %s replaces %s in the new table", address.netAddress, occupant.netAddress)
+		}
+		delete(am.tables.triedCollisions, *occupantKey)
+		err := am.store.remove(*occupantKey)
+		if err != nil {
+			return false, err
+		}
+	}
+	am.tables.setNew(position, &key)
+	return true, nil
+}
+
+// unplaceNoLock removes the given address from the table it's in
+func (am *AddressManager) unplaceNoLock(key addressKey, address *address) {
+	group := am.GroupKey(address.netAddress)
+	if address.isTried {
+		position := am.tables.triedPosition(key, group)
+		if occupantKey := am.tables.triedOccupant(position); occupantKey != nil && *occupantKey == key {
+			am.tables.setTried(position, nil)
+		}
+		return
+	}
+	position := am.tables.newPosition(key, group, address.sourceGroup)
+	if occupantKey := am.tables.newOccupant(position); occupantKey != nil && *occupantKey == key {
+		am.tables.setNew(position, nil)
+	}
+	delete(am.tables.triedCollisions, key)
 }
 
-func (am *AddressManager) addAddressNoLock(netAddress *appmessage.NetAddress) error {
+func (am *AddressManager) addAddressNoLock(source *appmessage.NetAddress, netAddress *appmessage.NetAddress) error {
 	if !IsRoutable(netAddress, am.cfg.AcceptUnroutable) {
 		return nil
 	}
+	// Onion addresses can only be connected to through the proxy
+	if netAddress.IsOnion() && !am.cfg.AcceptOnion {
+		return nil
+	}
+	// IPv4 addresses are of no use to an IPv6-only node
+	if IsIPv4(netAddress) && !am.cfg.AcceptIPv4 {
+		return nil
+	}
 
 	key := netAddressKey(netAddress)
+	if am.store.isNotBanned(key) {
+		return nil
+	}
+
+	// The addresses that were learned from no peer in particular, such as the ones of
+	// the seeders, share a single source group
+	sourceGroup := seededSourceGroup
+	if source != nil {
+		sourceGroup = am.GroupKey(source)
+	}
 	// We mark `connectionFailedCount` as 0 only after first success
-	address := &address{netAddress: netAddress, connectionFailedCount: 1}
-	err := am.store.add(key, address)
+	address := &address{netAddress: netAddress, connectionFailedCount: 1, sourceGroup: sourceGroup}
+	placed, err := am.placeNewNoLock(key, address, mstime.Now())
 	if err != nil {
 		return err
 	}
-
-	if am.store.notBannedCount() > maxAddresses {
-		allAddresses := am.store.getAllNotBanned()
-
-		maxConnectionFailedCount := uint64(0)
-		toRemove := allAddresses[0]
-		for _, address := range allAddresses[1:] {
-			if address.connectionFailedCount > maxConnectionFailedCount {
-				maxConnectionFailedCount = address.connectionFailedCount
-				toRemove = address
-			}
-		}
-
-		err := am.removeAddressNoLock(toRemove.netAddress)
-		if err != nil {
-			return err
-		}
+	if !placed {
+		return nil
 	}
-	return nil
+	return am.store.add(key, address)
 }
 
-func (am *AddressManager) removeAddressNoLock(address *appmessage.NetAddress) error {
-	key := netAddressKey(address)
+func (am *AddressManager) removeAddressNoLock(netAddress *appmessage.NetAddress) error {
+	key := netAddressKey(netAddress)
+	if address, ok := am.store.getNotBanned(key); ok {
+		am.unplaceNoLock(key, address)
+	}
 	return am.store.remove(key)
 }
 
@@ -126,16 +253,24 @@ func (am *AddressManager) AddAddress(address *appmessage.NetAddress) error {
 	am.mutex.Lock()
 	defer am.mutex.Unlock()
 
-	return am.addAddressNoLock(address)
+	return am.addAddressNoLock(nil, address)
 }
 
-// AddAddresses adds addresses to the address manager
+// AddAddresses adds addresses that weren't learned from a peer, such as the ones of
+// the seeders, to the address manager
 func (am *AddressManager) AddAddresses(addresses ...*appmessage.NetAddress) error {
+	return am.AddAddressesFromSource(nil, addresses...)
+}
+
+// AddAddressesFromSource adds addresses that were learned from the peer at source to
+// the address manager. The addresses that are learned from the peers of a single
+// network group can only fill a small part of the new table.
+func (am *AddressManager) AddAddressesFromSource(source *appmessage.NetAddress, addresses ...*appmessage.NetAddress) error {
 	am.mutex.Lock()
 	defer am.mutex.Unlock()
 
 	for _, address := range addresses {
-		err := am.addAddressNoLock(address)
+		err := am.addAddressNoLock(source, address)
 		if err != nil {
 			return err
 		}
@@ -160,20 +295,32 @@ func (am *AddressManager) MarkConnectionFailure(address *appmessage.NetAddress)
 	key := netAddressKey(address)
 	entry, ok := am.store.getNotBanned(key)
 	if !ok {
-		return errors.Errorf("address %s is not registered with the address manager", address.TCPAddress())
+		return errors.Errorf("address %s is not registered with the address manager", address)
 	}
 	entry.connectionFailedCount = entry.connectionFailedCount + 1
+	entry.lastAttempt = mstime.Now()
 
 	if entry.connectionFailedCount >= connectionFailedCountForRemove {
 		log.Debugf("Address %s has failed %d connection attempts - removing from address manager",
 			address, entry.connectionFailedCount)
-		return am.store.remove(key)
+		am.unplaceNoLock(key, entry)
+		err := am.store.remove(key)
+		if err != nil {
+			return err
+		}
+		return am.resolveTriedCollisionsNoLock()
 	}
-	return am.store.updateNotBanned(key, entry)
+	err := am.store.updateNotBanned(key, entry)
+	if err != nil {
+		return err
+	}
+	return am.resolveTriedCollisionsNoLock()
 }
 
 // MarkConnectionSuccess notifies the address manager that the given address
-// has successfully connected
+// has successfully connected. The address is moved to the tried table, unless its
+// position there is held by another address, in which case it waits for a feeler
+// connection to tell whether that address is still reachable.
 func (am *AddressManager) MarkConnectionSuccess(address *appmessage.NetAddress) error {
 	am.mutex.Lock()
 	defer am.mutex.Unlock()
@@ -181,12 +328,180 @@ func (am *AddressManager) MarkConnectionSuccess(address *appmessage.NetAddress)
 	key := netAddressKey(address)
 	entry, ok := am.store.getNotBanned(key)
 	if !ok {
-		return errors.Errorf("address %s is not registered with the address manager", address.TCPAddress())
+		return errors.Errorf("address %s is not registered with the address manager", address)
 	}
+	now := mstime.Now()
 	entry.connectionFailedCount = 0
+	entry.lastAttempt = now
+	entry.lastSuccess = now
+	err := am.store.updateNotBanned(key, entry)
+	if err != nil {
+		return err
+	}
+
+	if !entry.isTried {
+		err := am.moveToTriedNoLock(key, entry, now)
+		if err != nil {
+			return err
+		}
+	}
+	return am.resolveTriedCollisionsNoLock()
+}
+
+// SetServices records the services that the peer at the given address advertised
+// when an outgoing connection was made to it
+func (am *AddressManager) SetServices(address *appmessage.NetAddress, services appmessage.ServiceFlag) error {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	key := netAddressKey(address)
+	entry, ok := am.store.getNotBanned(key)
+	if !ok {
+		return errors.Wrapf(ErrAddressNotFound, "address %s is not registered with the address manager", address)
+	}
+	if entry.services == services {
+		return nil
+	}
+	entry.services = services
 	return am.store.updateNotBanned(key, entry)
 }
 
+// ReachableAddresses returns the addresses in the tried table whose last connection
+// attempt succeeded, and whose peers advertised all of the given services. Onion
+// addresses aren't included.
+func (am *AddressManager) ReachableAddresses(services appmessage.ServiceFlag) []*appmessage.NetAddress {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	var addresses []*appmessage.NetAddress
+	for _, address := range am.store.getAllNotBanned() {
+		if !address.isTried || address.connectionFailedCount > 0 || address.netAddress.IsOnion() {
+			continue
+		}
+		if address.services == 0 || address.services&services != services {
+			continue
+		}
+		addresses = append(addresses, address.netAddress)
+	}
+	return addresses
+}
+
+// moveToTriedNoLock moves the given address from the new table to the tried table,
+// or records a collision if its position there is held by another address
+func (am *AddressManager) moveToTriedNoLock(key addressKey, address *address, now mstime.Time) error {
+	position := am.tables.triedPosition(key, am.GroupKey(address.netAddress))
+	if occupantKey := am.tables.triedOccupant(position); occupantKey != nil && *occupantKey != key {
+		if _, ok := am.tables.triedCollisions[key]; !ok && len(am.tables.triedCollisions) < maxTriedCollisions {
+			am.tables.triedCollisions[key] = now
+		}
+		return nil
+	}
+
+	am.unplaceNoLock(key, address)
+	address.isTried = true
+	am.tables.setTried(position, &key)
+	return am.store.updateNotBanned(key, address)
+}
+
+// resolveTriedCollisionsNoLock settles the collisions in the tried table whose outcome
+// is known. The address that holds a position keeps it if it was connected to since the
+// collision, and otherwise it's moved back to the new table once it fails to connect, or
+// once it went untested for too long.
+func (am *AddressManager) resolveTriedCollisionsNoLock() error {
+	now := mstime.Now()
+	for key, collisionTime := range am.tables.triedCollisions {
+		address, ok := am.store.getNotBanned(key)
+		if !ok || address.isTried {
+			delete(am.tables.triedCollisions, key)
+			continue
+		}
+		position := am.tables.triedPosition(key, am.GroupKey(address.netAddress))
+		occupantKey := am.tables.triedOccupant(position)
+		if occupantKey == nil {
+			delete(am.tables.triedCollisions, key)
+			err := am.moveToTriedNoLock(key, address, now)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		occupant, ok := am.store.getNotBanned(*occupantKey)
+		if !ok {
+			am.tables.setTried(position, nil)
+			continue
+		}
+		if occupant.lastSuccess.After(collisionTime) {
+			delete(am.tables.triedCollisions, key)
+			continue
+		}
+		hasFailedSinceCollision := occupant.lastAttempt.After(collisionTime)
+		if !hasFailedSinceCollision && now.Sub(collisionTime) < triedCollisionTimeout {
+			continue
+		}
+
+		log.Debugf("Address %s replaces %s in the tried table", address.netAddress, occupant.netAddress)
+		delete(am.tables.triedCollisions, key)
+		err := am.evictFromTriedNoLock(*occupantKey, occupant, now)
+		if err != nil {
+			return err
+		}
+		err = am.moveToTriedNoLock(key, address, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evictFromTriedNoLock moves the given address from the tried table back to the new
+// table, or removes it if its position there is taken
+func (am *AddressManager) evictFromTriedNoLock(key addressKey, address *address, now mstime.Time) error {
+	am.unplaceNoLock(key, address)
+	address.isTried = false
+	placed, err := am.placeNewNoLock(key, address, now)
+	if err != nil {
+		return err
+	}
+	if !placed {
+		return am.store.remove(key)
+	}
+	return am.store.updateNotBanned(key, address)
+}
+
+// FeelerAddress returns an address to make a short-lived feeler connection to, or nil
+// if there's none. It's the address that holds a contested position in the tried table
+// if there's one, so that it's only evicted once it's found unreachable, and otherwise a
+// random address of the new table, so that reachable addresses keep being moved to the
+// tried table.
+func (am *AddressManager) FeelerAddress(exceptions []*appmessage.NetAddress) *appmessage.NetAddress {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	exceptionKeys := netAddressesKeys(exceptions)
+	for key := range am.tables.triedCollisions {
+		address, ok := am.store.getNotBanned(key)
+		if !ok {
+			continue
+		}
+		position := am.tables.triedPosition(key, am.GroupKey(address.netAddress))
+		occupantKey := am.tables.triedOccupant(position)
+		if occupantKey == nil || exceptionKeys[*occupantKey] {
+			continue
+		}
+		if occupant, ok := am.store.getNotBanned(*occupantKey); ok {
+			return occupant.netAddress
+		}
+	}
+
+	_, newAddresses := am.store.getAllNotBannedNetAddressesWithout(exceptions)
+	addresses := am.random.RandomAddresses(newAddresses, 1)
+	if len(addresses) == 0 {
+		return nil
+	}
+	return addresses[0]
+}
+
 // Addresses returns all addresses
 func (am *AddressManager) Addresses() []*appmessage.NetAddress {
 	am.mutex.Lock()
@@ -203,18 +518,38 @@ func (am *AddressManager) BannedAddresses() []*appmessage.NetAddress {
 	return am.store.getAllBannedNetAddresses()
 }
 
-// notBannedAddressesWithException returns all not banned addresses with excpetion
-func (am *AddressManager) notBannedAddressesWithException(exceptions []*appmessage.NetAddress) []*address {
+// notBannedAddressesWithException returns all not banned addresses with excpetion,
+// split by the table they're in
+func (am *AddressManager) notBannedAddressesWithException(exceptions []*appmessage.NetAddress) (
+	triedAddresses []*address, newAddresses []*address) {
+
 	am.mutex.Lock()
 	defer am.mutex.Unlock()
 
 	return am.store.getAllNotBannedNetAddressesWithout(exceptions)
 }
 
-// RandomAddresses returns count addresses at random that aren't banned and aren't in exceptions
+// RandomAddresses returns count addresses at random that aren't banned and aren't in exceptions.
+// Half of them are taken from each table if possible, so that flooding the new table with
+// addresses doesn't crowd out the addresses that are known to be reachable.
 func (am *AddressManager) RandomAddresses(count int, exceptions []*appmessage.NetAddress) []*appmessage.NetAddress {
-	validAddresses := am.notBannedAddressesWithException(exceptions)
-	return am.random.RandomAddresses(validAddresses, count)
+	triedAddresses, newAddresses := am.notBannedAddressesWithException(exceptions)
+
+	triedCount := (count + 1) / 2
+	if triedCount > len(triedAddresses) {
+		triedCount = len(triedAddresses)
+	}
+	newCount := count - triedCount
+	if newCount > len(newAddresses) {
+		newCount = len(newAddresses)
+		triedCount = count - newCount
+		if triedCount > len(triedAddresses) {
+			triedCount = len(triedAddresses)
+		}
+	}
+
+	randomAddresses := am.random.RandomAddresses(triedAddresses, triedCount)
+	return append(randomAddresses, am.random.RandomAddresses(newAddresses, newCount)...)
 }
 
 // BestLocalAddress returns the most appropriate local address to use
@@ -223,21 +558,37 @@ func (am *AddressManager) BestLocalAddress(remoteAddress *appmessage.NetAddress)
 	return am.localAddresses.bestLocalAddress(remoteAddress)
 }
 
+// AddLocalAddress adds an address of this node, which was discovered by the given method,
+// to the local addresses that are advertised to peers
+func (am *AddressManager) AddLocalAddress(netAddress *appmessage.NetAddress, priority AddressPriority) error {
+	return am.localAddresses.addLocalNetAddress(netAddress, priority)
+}
+
+// RemoveLocalAddress stops advertising the given address of this node to peers
+func (am *AddressManager) RemoveLocalAddress(netAddress *appmessage.NetAddress) {
+	am.localAddresses.removeLocalNetAddress(netAddress)
+}
+
+// LocalAddresses returns all the local addresses that are advertised to peers, along with their scores
+func (am *AddressManager) LocalAddresses() []*LocalAddress {
+	return am.localAddresses.allLocalAddresses()
+}
+
 // Ban marks the given address as banned
 func (am *AddressManager) Ban(addressToBan *appmessage.NetAddress) error {
 	am.mutex.Lock()
 	defer am.mutex.Unlock()
 
 	keyToBan := netAddressKey(addressToBan)
-	keysToDelete := make([]addressKey, 0)
+	addressesToDelete := make([]*appmessage.NetAddress, 0)
 	for _, address := range am.store.getAllNotBannedNetAddresses() {
 		key := netAddressKey(address)
 		if key.address.equal(keyToBan.address) {
-			keysToDelete = append(keysToDelete, key)
+			addressesToDelete = append(addressesToDelete, address)
 		}
 	}
-	for _, key := range keysToDelete {
-		err := am.store.remove(key)
+	for _, address := range addressesToDelete {
+		err := am.removeAddressNoLock(address)
 		if err != nil {
 			return err
 		}
@@ -255,7 +606,7 @@ func (am *AddressManager) Unban(address *appmessage.NetAddress) error {
 	key := netAddressKey(address)
 	if !am.store.isBanned(key) {
 		return errors.Wrapf(ErrAddressNotFound, "address %s "+
-			"is not registered with the address manager as banned", address.TCPAddress())
+			"is not registered with the address manager as banned", address)
 	}
 
 	return am.store.removeBanned(key)
@@ -274,7 +625,7 @@ func (am *AddressManager) IsBanned(address *appmessage.NetAddress) (bool, error)
 	if !am.store.isBanned(key) {
 		if !am.store.isNotBanned(key) {
 			return false, errors.Wrapf(ErrAddressNotFound, "address %s "+
-				"is not registered with the address manager", address.TCPAddress())
+				"is not registered with the address manager", address)
 		}
 		return false, nil
 	}
@@ -8,13 +8,17 @@ import (
 	"net"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/infrastructure/config"
 	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
 	"github.com/kaspanet/kaspad/util/mstime"
+	"github.com/pkg/errors"
 )
 
+var testSecretKey = make([]byte, secretKeySize)
+
 func newAddressManagerForTest(t *testing.T, testName string) (addressManager *AddressManager, teardown func()) {
 	cfg := config.DefaultConfig()
 
@@ -24,6 +28,11 @@ func newAddressManagerForTest(t *testing.T, testName string) (addressManager *Ad
 		t.Fatalf("%s: could not create a database: %s", testName, err)
 	}
 
+	// A fixed secret key places the addresses in the same positions in every run
+	err = database.Put(secretKeyKey, testSecretKey)
+	if err != nil {
+		t.Fatalf("%s: could not put the secret key: %s", testName, err)
+	}
 	addressManager, err = New(NewConfig(cfg), database)
 	if err != nil {
 		t.Fatalf("%s: error creating address manager: %s", testName, err)
@@ -238,6 +247,10 @@ func TestRestoreAddressManager(t *testing.T) {
 		t.Fatalf("Could not create a database: %s", err)
 	}
 	defer database.Close()
+	err = database.Put(secretKeyKey, testSecretKey)
+	if err != nil {
+		t.Fatalf("Could not put the secret key: %s", err)
+	}
 
 	// Create an addressManager with the empty database
 	addressManager, err := New(NewConfig(cfg), database)
@@ -305,70 +318,187 @@ func TestRestoreAddressManager(t *testing.T) {
 	}
 }
 
-func TestOverfillAddressManager(t *testing.T) {
-	addressManager, teardown := newAddressManagerForTest(t, "TestAddressManager")
+func TestNewTableSourceGroups(t *testing.T) {
+	addressManager, teardown := newAddressManagerForTest(t, "TestNewTableSourceGroups")
 	defer teardown()
 
-	generateTestAddresses := func(amount int) []*appmessage.NetAddress {
-		testAddresses := make([]*appmessage.NetAddress, 0, amount)
-		for i := byte(0); i < 128; i++ {
-			for j := byte(0); j < 128; j++ {
-				testAddress := &appmessage.NetAddress{IP: net.IP{1, 2, i, j}, Timestamp: mstime.Now()}
-				testAddresses = append(testAddresses, testAddress)
-				if len(testAddresses) == amount {
-					break
-				}
-			}
-			if len(testAddresses) == amount {
-				break
-			}
-		}
-		return testAddresses
+	// The addresses of a single network group that were learned from a single source
+	// group all fall in a single bucket of the new table
+	var sameGroupAddresses []*appmessage.NetAddress
+	for i := 0; i < 4096; i++ {
+		sameGroupAddresses = append(sameGroupAddresses,
+			&appmessage.NetAddress{IP: net.IP{1, 2, byte(i >> 8), byte(i)}, Port: 16111, Timestamp: mstime.Now()})
 	}
-
-	// Add a single test address to the address manager
-	testAddress := &appmessage.NetAddress{IP: net.IP{5, 6, 0, 0}, Timestamp: mstime.Now()}
-	err := addressManager.AddAddress(testAddress)
+	err := addressManager.AddAddresses(sameGroupAddresses...)
 	if err != nil {
-		t.Fatalf("AddAddress: %s", err)
+		t.Fatalf("AddAddresses: %s", err)
+	}
+	sameGroupCount := len(addressManager.Addresses())
+	if sameGroupCount == 0 || sameGroupCount > bucketSize {
+		t.Fatalf("Expected between 1 and %d addresses of a single group, but got %d", bucketSize, sameGroupCount)
 	}
 
-	// Add `maxAddresses-1` addresses to the address manager
-	addresses := generateTestAddresses(maxAddresses - 1)
-	err = addressManager.AddAddresses(addresses...)
+	// The addresses learned from a single source group fall in a limited number of buckets,
+	// no matter how many network groups they're in
+	source := &appmessage.NetAddress{IP: net.IP{9, 9, 9, 9}, Port: 16111, Timestamp: mstime.Now()}
+	var manyGroupsAddresses []*appmessage.NetAddress
+	for i := 0; i < 8192; i++ {
+		manyGroupsAddresses = append(manyGroupsAddresses,
+			&appmessage.NetAddress{IP: net.IP{byte(i>>8) + 20, byte(i), 1, 1}, Port: 16111, Timestamp: mstime.Now()})
+	}
+	err = addressManager.AddAddressesFromSource(source, manyGroupsAddresses...)
 	if err != nil {
-		t.Fatalf("AddAddresses: %s", err)
+		t.Fatalf("AddAddressesFromSource: %s", err)
+	}
+	manyGroupsCount := len(addressManager.Addresses()) - sameGroupCount
+	if manyGroupsCount <= bucketSize || manyGroupsCount > newBucketsPerSourceGroup*bucketSize {
+		t.Fatalf("Expected between %d and %d addresses from a single source, but got %d",
+			bucketSize+1, newBucketsPerSourceGroup*bucketSize, manyGroupsCount)
 	}
+}
+
+func TestTriedCollision(t *testing.T) {
+	addressManager, teardown := newAddressManagerForTest(t, "TestTriedCollision")
+	defer teardown()
 
-	// Make sure that it now contains exactly `maxAddresses` entries
-	returnedAddresses := addressManager.Addresses()
-	if len(returnedAddresses) != maxAddresses {
-		t.Fatalf("Unexpected address amount. Want: %d, got: %d", maxAddresses, len(returnedAddresses))
+	// Find two addresses of the same group that collide in the tried table
+	positions := make(map[tablePosition]*appmessage.NetAddress)
+	var holder, contender *appmessage.NetAddress
+	for i := 0; holder == nil; i++ {
+		netAddress := &appmessage.NetAddress{IP: net.IP{1, 2, byte(i >> 8), byte(i)}, Port: 16111, Timestamp: mstime.Now()}
+		position := addressManager.tables.triedPosition(netAddressKey(netAddress), addressManager.GroupKey(netAddress))
+		if collidingAddress, ok := positions[position]; ok {
+			holder, contender = collidingAddress, netAddress
+		}
+		positions[position] = netAddress
 	}
 
-	// Mark the first test address as a connection failure
-	err = addressManager.MarkConnectionFailure(testAddress)
+	isTried := func(netAddress *appmessage.NetAddress) bool {
+		address, ok := addressManager.store.getNotBanned(netAddressKey(netAddress))
+		if !ok {
+			t.Fatalf("Address %s is missing from the address manager", netAddress)
+		}
+		return address.isTried
+	}
+	// The address manager tells the events that follow a collision by their times,
+	// which have a precision of a millisecond
+	waitAMillisecond := func() { time.Sleep(2 * time.Millisecond) }
+
+	for _, netAddress := range []*appmessage.NetAddress{holder, contender} {
+		waitAMillisecond()
+		err := addressManager.AddAddresses(netAddress)
+		if err != nil {
+			t.Fatalf("AddAddresses: %s", err)
+		}
+		err = addressManager.MarkConnectionSuccess(netAddress)
+		if err != nil {
+			t.Fatalf("MarkConnectionSuccess: %s", err)
+		}
+	}
+	if !isTried(holder) || isTried(contender) {
+		t.Fatalf("Expected only the first of the colliding addresses to be in the tried table")
+	}
+
+	// The holder of the position is tested by a feeler connection before it's evicted
+	feelerAddress := addressManager.FeelerAddress(nil)
+	if feelerAddress != holder {
+		t.Fatalf("Expected the feeler address to be %s, but got %s", holder, feelerAddress)
+	}
+	waitAMillisecond()
+	err := addressManager.MarkConnectionFailure(holder)
 	if err != nil {
 		t.Fatalf("MarkConnectionFailure: %s", err)
 	}
+	if isTried(holder) || !isTried(contender) {
+		t.Fatalf("Expected the unreachable holder to be replaced by the contender in the tried table")
+	}
 
-	// Add one more address to the address manager
-	err = addressManager.AddAddress(&appmessage.NetAddress{IP: net.IP{7, 8, 0, 0}, Timestamp: mstime.Now()})
+	// A holder that's still reachable keeps its position
+	waitAMillisecond()
+	err = addressManager.MarkConnectionSuccess(holder)
+	if err != nil {
+		t.Fatalf("MarkConnectionSuccess: %s", err)
+	}
+	waitAMillisecond()
+	err = addressManager.MarkConnectionSuccess(contender)
 	if err != nil {
-		t.Fatalf("AddAddress: %s", err)
+		t.Fatalf("MarkConnectionSuccess: %s", err)
+	}
+	if isTried(holder) || !isTried(contender) {
+		t.Fatalf("Expected the reachable contender to keep its position in the tried table")
 	}
+	if len(addressManager.tables.triedCollisions) != 0 {
+		t.Fatalf("Expected the collision to be resolved, but %d collisions are pending",
+			len(addressManager.tables.triedCollisions))
+	}
+}
+
+func TestReachableAddresses(t *testing.T) {
+	addressManager, teardown := newAddressManagerForTest(t, "TestReachableAddresses")
+	defer teardown()
 
-	// Make sure that it now still contains exactly `maxAddresses` entries
-	returnedAddresses = addressManager.Addresses()
-	if len(returnedAddresses) != maxAddresses {
-		t.Fatalf("Unexpected address amount. Want: %d, got: %d", maxAddresses, len(returnedAddresses))
+	newAddress := func(i byte) *appmessage.NetAddress {
+		return &appmessage.NetAddress{IP: net.IP{1, i, 3, 4}, Port: 16111, Timestamp: mstime.Now()}
+	}
+	neverConnected, unknownServices, fullNode, bloomNode := newAddress(1), newAddress(2), newAddress(3), newAddress(4)
+	err := addressManager.AddAddresses(neverConnected, unknownServices, fullNode, bloomNode)
+	if err != nil {
+		t.Fatalf("AddAddresses: %s", err)
+	}
+	for _, netAddress := range []*appmessage.NetAddress{unknownServices, fullNode, bloomNode} {
+		err := addressManager.MarkConnectionSuccess(netAddress)
+		if err != nil {
+			t.Fatalf("MarkConnectionSuccess: %s", err)
+		}
+	}
+	err = addressManager.SetServices(fullNode, appmessage.SFNodeNetwork)
+	if err != nil {
+		t.Fatalf("SetServices: %s", err)
+	}
+	err = addressManager.SetServices(bloomNode, appmessage.SFNodeNetwork|appmessage.SFNodeBloom)
+	if err != nil {
+		t.Fatalf("SetServices: %s", err)
 	}
 
-	// Make sure that the first address is no longer in the
-	// connection manager
-	for _, address := range returnedAddresses {
-		if address.IP.Equal(testAddress.IP) {
-			t.Fatalf("Unexpectedly found testAddress returned addresses")
+	tests := []struct {
+		services appmessage.ServiceFlag
+		expected []*appmessage.NetAddress
+	}{
+		{services: 0, expected: []*appmessage.NetAddress{fullNode, bloomNode}},
+		{services: appmessage.SFNodeNetwork, expected: []*appmessage.NetAddress{fullNode, bloomNode}},
+		{services: appmessage.SFNodeNetwork | appmessage.SFNodeBloom, expected: []*appmessage.NetAddress{bloomNode}},
+		{services: appmessage.SFNodeCF, expected: nil},
+	}
+	for _, test := range tests {
+		addresses := addressManager.ReachableAddresses(test.services)
+		if len(addresses) != len(test.expected) {
+			t.Fatalf("Expected %d reachable addresses with services %s, but got %d",
+				len(test.expected), test.services, len(addresses))
+		}
+		for _, expected := range test.expected {
+			found := false
+			for _, address := range addresses {
+				if address == expected {
+					found = true
+				}
+			}
+			if !found {
+				t.Fatalf("Expected %s to be reachable with services %s", expected, test.services)
+			}
 		}
 	}
+
+	// An address that fails to connect is no longer reachable
+	err = addressManager.MarkConnectionFailure(bloomNode)
+	if err != nil {
+		t.Fatalf("MarkConnectionFailure: %s", err)
+	}
+	if addresses := addressManager.ReachableAddresses(appmessage.SFNodeBloom); len(addresses) != 0 {
+		t.Fatalf("Expected no reachable addresses with services %s, but got %d", appmessage.SFNodeBloom, len(addresses))
+	}
+
+	err = addressManager.SetServices(newAddress(5), appmessage.SFNodeNetwork)
+	if !errors.Is(err, ErrAddressNotFound) {
+		t.Fatalf("Expected SetServices of an unknown address to return ErrAddressNotFound, but got %v", err)
+	}
 }
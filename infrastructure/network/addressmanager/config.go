@@ -9,6 +9,8 @@ import (
 // Config is a descriptor which specifies the AddressManager instance configuration.
 type Config struct {
 	AcceptUnroutable bool
+	AcceptOnion      bool
+	AcceptIPv4       bool
 	DefaultPort      string
 	ExternalIPs      []string
 	Listeners        []string
@@ -19,6 +21,8 @@ type Config struct {
 func NewConfig(cfg *config.Config) *Config {
 	return &Config{
 		AcceptUnroutable: cfg.NetParams().AcceptUnroutable,
+		AcceptOnion:      cfg.Proxy != "",
+		AcceptIPv4:       !cfg.IPv6Only,
 		DefaultPort:      cfg.NetParams().DefaultPort,
 		ExternalIPs:      cfg.ExternalIPs,
 		Listeners:        cfg.Listeners,
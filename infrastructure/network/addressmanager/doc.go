@@ -24,6 +24,15 @@ generally helps provide greater peer diversity, and perhaps more importantly,
 drastically reduces the chances an attacker is able to coerce your peer into
 only connecting to nodes they control.
 
+Addresses are kept in two tables of buckets: the new table, which holds the
+addresses that were heard of, and the tried table, which holds the addresses
+that were connected to successfully. The bucket of an address is decided by a
+keyed hash of its network group and that of the peer it was learned from, so a
+single peer can only fill a small part of the new table. An address that was
+connected to successfully but collides with another address in the tried table
+waits until a feeler connection tests the address that holds its position, and
+only replaces it if it turns out to be unreachable.
+
 The address manager also understands routability and tries hard to only return
 routable addresses. In addition, it uses the information provided by the caller
 about connected, known good, and attempted addresses to periodically purge
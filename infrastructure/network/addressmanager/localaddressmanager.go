@@ -22,7 +22,7 @@ const (
 	// BoundPrio signifies the address has been explicitly bounded to.
 	BoundPrio
 
-	// UpnpPrio signifies the address was obtained from UPnP.
+	// UpnpPrio signifies the address was obtained from UPnP or NAT-PMP.
 	UpnpPrio
 
 	// HTTPPrio signifies the address was obtained from an external HTTP service.
@@ -37,6 +37,13 @@ type localAddress struct {
 	score      AddressPriority
 }
 
+// LocalAddress is an address of this node that is advertised to peers, along
+// with the score of the method it was discovered by
+type LocalAddress struct {
+	NetAddress *appmessage.NetAddress
+	Score      AddressPriority
+}
+
 type localAddressManager struct {
 	localAddresses map[addressKey]*localAddress
 	lookupFunc     func(string) ([]net.IP, error)
@@ -63,7 +70,7 @@ func newLocalAddressManager(cfg *Config) (*localAddressManager, error) {
 // with the given priority.
 func (lam *localAddressManager) addLocalNetAddress(netAddress *appmessage.NetAddress, priority AddressPriority) error {
 	if !IsRoutable(netAddress, lam.cfg.AcceptUnroutable) {
-		return errors.Errorf("address %s is not routable", netAddress.IP)
+		return errors.Errorf("address %s is not routable", netAddress)
 	}
 
 	lam.mutex.Lock()
@@ -84,6 +91,14 @@ func (lam *localAddressManager) addLocalNetAddress(netAddress *appmessage.NetAdd
 	return nil
 }
 
+// removeLocalNetAddress removes netAddress from the list of known local addresses
+func (lam *localAddressManager) removeLocalNetAddress(netAddress *appmessage.NetAddress) {
+	lam.mutex.Lock()
+	defer lam.mutex.Unlock()
+
+	delete(lam.localAddresses, netAddressKey(netAddress))
+}
+
 // bestLocalAddress returns the most appropriate local address to use
 // for the given remote address.
 func (lam *localAddressManager) bestLocalAddress(remoteAddress *appmessage.NetAddress) *appmessage.NetAddress {
@@ -117,6 +132,21 @@ func (lam *localAddressManager) bestLocalAddress(remoteAddress *appmessage.NetAd
 	return bestAddress
 }
 
+// allLocalAddresses returns all the known local addresses along with their scores
+func (lam *localAddressManager) allLocalAddresses() []*LocalAddress {
+	lam.mutex.Lock()
+	defer lam.mutex.Unlock()
+
+	localAddresses := make([]*LocalAddress, 0, len(lam.localAddresses))
+	for _, address := range lam.localAddresses {
+		localAddresses = append(localAddresses, &LocalAddress{
+			NetAddress: address.netAddress,
+			Score:      address.score,
+		})
+	}
+	return localAddresses
+}
+
 // addLocalAddress adds an address that this node is listening on to the
 // address manager so that it may be relayed to peers.
 func (lam *localAddressManager) addLocalAddress(addr string) error {
@@ -322,6 +352,22 @@ func reachabilityFrom(localAddress, remoteAddress *appmessage.NetAddress, accept
 			IsLocal(na) || (IsRFC4193(na)))
 	}
 
+	// Onion peers reach onion services privately, and anything else through the
+	// exit relays of Tor. An onion address is still advertised to other peers,
+	// which may relay it to peers that are on Tor.
+	if IsOnion(remoteAddress) {
+		if IsOnion(localAddress) {
+			return Private
+		}
+		if IsRoutable(localAddress) && IsIPv4(localAddress) {
+			return Ipv4
+		}
+		return Default
+	}
+	if IsOnion(localAddress) {
+		return Default
+	}
+
 	if !IsRoutable(remoteAddress) {
 		return Unreachable
 	}
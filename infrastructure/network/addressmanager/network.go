@@ -8,6 +8,7 @@ import (
 	"net"
 
 	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/network/tor"
 )
 
 var (
@@ -97,6 +98,15 @@ func IsIPv4(na *appmessage.NetAddress) bool {
 	return na.IP.To4() != nil
 }
 
+// IsOnion returns whether or not the given address is the address of an onion
+// service, or the OnionCat IP of a connection made to one.
+func IsOnion(na *appmessage.NetAddress) bool {
+	if na.IsOnion() {
+		return tor.IsOnionHost(na.OnionHost)
+	}
+	return tor.IsOnionCatIP(na.IP)
+}
+
 // IsLocal returns whether or not the given address is a local address.
 func IsLocal(na *appmessage.NetAddress) bool {
 	return na.IP.IsLoopback() || zero4Net.Contains(na.IP)
@@ -208,6 +218,11 @@ func IsValid(na *appmessage.NetAddress) bool {
 // the public internet. This is true as long as the address is valid and is not
 // in any reserved ranges.
 func IsRoutable(na *appmessage.NetAddress, acceptUnroutable bool) bool {
+	// Onion hosts have no IP, and are routed by Tor. The OnionCat IPs that stand
+	// for them can't be dialed, so they're left unroutable.
+	if na.IsOnion() {
+		return tor.IsOnionHost(na.OnionHost)
+	}
 	if acceptUnroutable {
 		return !IsLocal(na)
 	}
@@ -220,12 +235,15 @@ func IsRoutable(na *appmessage.NetAddress, acceptUnroutable bool) bool {
 
 // GroupKey returns a string representing the network group an address is part
 // of. This is the /16 for IPv4, the /32 (/36 for he.net) for IPv6, the string
-// "local" for a local address, and the string "unroutable" for an unroutable
-// address.
+// "local" for a local address, the string "onion" for an onion address, and the
+// string "unroutable" for an unroutable address.
 func (am *AddressManager) GroupKey(na *appmessage.NetAddress) string {
 	if IsLocal(na) {
 		return "local"
 	}
+	if na.IsOnion() && IsRoutable(na, am.cfg.AcceptUnroutable) {
+		return "onion"
+	}
 	if !IsRoutable(na, am.cfg.AcceptUnroutable) {
 		return "unroutable"
 	}
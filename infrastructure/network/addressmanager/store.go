@@ -1,6 +1,7 @@
 package addressmanager
 
 import (
+	"crypto/rand"
 	"encoding/binary"
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/infrastructure/db/database"
@@ -9,13 +10,34 @@ import (
 	"net"
 )
 
-var notBannedAddressBucket = database.MakeBucket([]byte("not-banned-addresses"))
-var bannedAddressBucket = database.MakeBucket([]byte("banned-addresses"))
+var addressBucket = database.MakeBucket([]byte("addresses-v2"))
+var bannedAddressBucket = database.MakeBucket([]byte("banned-addresses-v2"))
+var secretKeyKey = database.MakeBucket([]byte("address-manager")).Key([]byte("secret-key"))
+
+// The buckets of the first version of the store, whose addresses are migrated to the
+// current buckets on startup
+var notBannedAddressBucketV1 = database.MakeBucket([]byte("not-banned-addresses"))
+var bannedAddressBucketV1 = database.MakeBucket([]byte("banned-addresses"))
+
+// addressSerializationVersion is the version of the serialization of the addresses in
+// addressBucket and bannedAddressBucket, which is the first byte of every serialized address
+const addressSerializationVersion = 3
+
+// addressSerializationVersionWithoutServices is the previous version of the serialization
+// of the addresses, which lacked their services. Addresses of this version are still read,
+// with no services, and are written in the current version the next time they change.
+const addressSerializationVersionWithoutServices = 2
+
+const secretKeySize = 32
 
 type addressStore struct {
 	database           database.Database
 	notBannedAddresses map[addressKey]*address
 	bannedAddresses    map[ipv6]*address
+
+	// secretKey keys the hashes that place the addresses in the buckets of the
+	// address tables, so that peers can't tell which addresses collide
+	secretKey []byte
 }
 
 func newAddressStore(database database.Database) (*addressStore, error) {
@@ -24,7 +46,15 @@ func newAddressStore(database database.Database) (*addressStore, error) {
 		notBannedAddresses: map[addressKey]*address{},
 		bannedAddresses:    map[ipv6]*address{},
 	}
-	err := addressStore.restoreNotBannedAddresses()
+	err := addressStore.restoreSecretKey()
+	if err != nil {
+		return nil, err
+	}
+	err = addressStore.migrateFromV1()
+	if err != nil {
+		return nil, err
+	}
+	err = addressStore.restoreNotBannedAddresses()
 	if err != nil {
 		return nil, err
 	}
@@ -39,8 +69,92 @@ func newAddressStore(database database.Database) (*addressStore, error) {
 	return addressStore, nil
 }
 
+func (as *addressStore) restoreSecretKey() error {
+	secretKey, err := as.database.Get(secretKeyKey)
+	if err == nil {
+		as.secretKey = secretKey
+		return nil
+	}
+	if !database.IsNotFoundError(err) {
+		return err
+	}
+
+	secretKey = make([]byte, secretKeySize)
+	_, err = rand.Read(secretKey)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	as.secretKey = secretKey
+	return as.database.Put(secretKeyKey, secretKey)
+}
+
+// migrateFromV1 moves the addresses of the first version of the store, which had no
+// address tables, to the current buckets. The addresses that were connected to
+// successfully go into the tried table.
+func (as *addressStore) migrateFromV1() error {
+	notBannedMigratedCount, err := as.migrateBucketFromV1(notBannedAddressBucketV1, addressBucket,
+		func(address *address) {
+			address.isTried = address.connectionFailedCount == 0
+		})
+	if err != nil {
+		return err
+	}
+	bannedMigratedCount, err := as.migrateBucketFromV1(bannedAddressBucketV1, bannedAddressBucket, nil)
+	if err != nil {
+		return err
+	}
+
+	if notBannedMigratedCount > 0 || bannedMigratedCount > 0 {
+		log.Infof("Migrated %d addresses and %d banned addresses to serialization version %d",
+			notBannedMigratedCount, bannedMigratedCount, addressSerializationVersion)
+	}
+	return nil
+}
+
+func (as *addressStore) migrateBucketFromV1(fromBucket *database.Bucket, toBucket *database.Bucket,
+	upgrade func(address *address)) (migratedCount int, err error) {
+
+	cursor, err := as.database.Cursor(fromBucket)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close()
+
+	transaction, err := as.database.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer transaction.RollbackUnlessClosed()
+
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		databaseKey, err := cursor.Key()
+		if err != nil {
+			return 0, err
+		}
+		serializedAddressV1, err := cursor.Value()
+		if err != nil {
+			return 0, err
+		}
+		address := as.deserializeAddressV1(serializedAddressV1)
+		if upgrade != nil {
+			upgrade(address)
+		}
+
+		err = transaction.Put(toBucket.Key(databaseKey.Suffix()), as.serializeAddress(address))
+		if err != nil {
+			return 0, err
+		}
+		err = transaction.Delete(databaseKey)
+		if err != nil {
+			return 0, err
+		}
+		migratedCount++
+	}
+	return migratedCount, transaction.Commit()
+}
+
 func (as *addressStore) restoreNotBannedAddresses() error {
-	cursor, err := as.database.Cursor(notBannedAddressBucket)
+	cursor, err := as.database.Cursor(addressBucket)
 	if err != nil {
 		return err
 	}
@@ -57,7 +171,10 @@ func (as *addressStore) restoreNotBannedAddresses() error {
 		if err != nil {
 			return err
 		}
-		netAddress := as.deserializeAddress(serializedNetAddress)
+		netAddress, err := as.deserializeAddress(serializedNetAddress)
+		if err != nil {
+			return err
+		}
 		as.notBannedAddresses[key] = netAddress
 	}
 	return nil
@@ -81,7 +198,10 @@ func (as *addressStore) restoreBannedAddresses() error {
 		if err != nil {
 			return err
 		}
-		netAddress := as.deserializeAddress(serializedNetAddress)
+		netAddress, err := as.deserializeAddress(serializedNetAddress)
+		if err != nil {
+			return err
+		}
 		as.bannedAddresses[ipv6] = netAddress
 	}
 	return nil
@@ -106,7 +226,7 @@ func (as *addressStore) add(key addressKey, address *address) error {
 // updateNotBanned updates the not-banned address collection
 func (as *addressStore) updateNotBanned(key addressKey, address *address) error {
 	if _, ok := as.notBannedAddresses[key]; !ok {
-		return errors.Errorf("address %s is not in the store", address.netAddress.TCPAddress())
+		return errors.Errorf("address %s is not in the store", address.netAddress)
 	}
 
 	as.notBannedAddresses[key] = address
@@ -144,16 +264,24 @@ func (as *addressStore) getAllNotBannedNetAddresses() []*appmessage.NetAddress {
 	return addresses
 }
 
-func (as *addressStore) getAllNotBannedNetAddressesWithout(ignoredAddresses []*appmessage.NetAddress) []*address {
+// getAllNotBannedNetAddressesWithout returns the not-banned addresses that aren't in
+// ignoredAddresses, split by the table they're in
+func (as *addressStore) getAllNotBannedNetAddressesWithout(ignoredAddresses []*appmessage.NetAddress) (
+	triedAddresses []*address, newAddresses []*address) {
+
 	ignoredKeys := netAddressesKeys(ignoredAddresses)
 
-	addresses := make([]*address, 0, len(as.notBannedAddresses))
 	for key, address := range as.notBannedAddresses {
-		if !ignoredKeys[key] {
-			addresses = append(addresses, address)
+		if ignoredKeys[key] {
+			continue
+		}
+		if address.isTried {
+			triedAddresses = append(triedAddresses, address)
+		} else {
+			newAddresses = append(newAddresses, address)
 		}
 	}
-	return addresses
+	return triedAddresses, newAddresses
 }
 
 func (as *addressStore) isNotBanned(key addressKey) bool {
@@ -210,7 +338,7 @@ func netAddressesKeys(netAddresses []*appmessage.NetAddress) map[addressKey]bool
 
 func (as *addressStore) notBannedDatabaseKey(key addressKey) *database.Key {
 	serializedKey := as.serializeAddressKey(key)
-	return notBannedAddressBucket.Key(serializedKey)
+	return addressBucket.Key(serializedKey)
 }
 
 func (as *addressStore) bannedDatabaseKey(key addressKey) *database.Key {
@@ -239,19 +367,89 @@ func (as *addressStore) deserializeAddressKey(serializedKey []byte) addressKey {
 	}
 }
 
+// serializeAddress serializes the given address as:
+// version (1 byte) + ipv6 (16 bytes) + port (2 bytes) + timestamp (8 bytes) +
+// connectionFailedCount (8 bytes) + lastAttempt (8 bytes) + lastSuccess (8 bytes) +
+// services (8 bytes) + isTried (1 byte) + source group length (1 byte) + source group +
+// onion host
 func (as *addressStore) serializeAddress(address *address) []byte {
-	serializedSize := 16 + 2 + 8 + 8 // ipv6 + port + timestamp + connectionFailedCount
-	serializedNetAddress := make([]byte, serializedSize)
+	const fixedSize = 1 + 16 + 2 + 8 + 8 + 8 + 8 + 8 + 1 + 1
+	serializedSize := fixedSize + len(address.sourceGroup) + len(address.netAddress.OnionHost)
+	serializedAddress := make([]byte, serializedSize)
+
+	serializedAddress[0] = addressSerializationVersion
+	copy(serializedAddress[1:], address.netAddress.IP.To16())
+	binary.LittleEndian.PutUint16(serializedAddress[17:], address.netAddress.Port)
+	binary.LittleEndian.PutUint64(serializedAddress[19:], uint64(address.netAddress.Timestamp.UnixMilliseconds()))
+	binary.LittleEndian.PutUint64(serializedAddress[27:], address.connectionFailedCount)
+	binary.LittleEndian.PutUint64(serializedAddress[35:], serializeOptionalTime(address.lastAttempt))
+	binary.LittleEndian.PutUint64(serializedAddress[43:], serializeOptionalTime(address.lastSuccess))
+	binary.LittleEndian.PutUint64(serializedAddress[51:], uint64(address.services))
+	if address.isTried {
+		serializedAddress[59] = 1
+	}
+	serializedAddress[60] = byte(len(address.sourceGroup))
+	copy(serializedAddress[fixedSize:], address.sourceGroup)
+	copy(serializedAddress[fixedSize+len(address.sourceGroup):], address.netAddress.OnionHost)
 
-	copy(serializedNetAddress[:], address.netAddress.IP.To16()[:])
-	binary.LittleEndian.PutUint16(serializedNetAddress[16:], address.netAddress.Port)
-	binary.LittleEndian.PutUint64(serializedNetAddress[18:], uint64(address.netAddress.Timestamp.UnixMilliseconds()))
-	binary.LittleEndian.PutUint64(serializedNetAddress[26:], uint64(address.connectionFailedCount))
+	return serializedAddress
+}
 
-	return serializedNetAddress
+func (as *addressStore) deserializeAddress(serializedAddress []byte) (*address, error) {
+	if len(serializedAddress) == 0 {
+		return nil, errors.Errorf("serialized address is empty")
+	}
+	servicesSize := 8
+	switch serializedAddress[0] {
+	case addressSerializationVersion:
+	case addressSerializationVersionWithoutServices:
+		servicesSize = 0
+	default:
+		return nil, errors.Errorf("unknown address serialization version %d", serializedAddress[0])
+	}
+	fixedSize := 1 + 16 + 2 + 8 + 8 + 8 + 8 + servicesSize + 1 + 1
+	if len(serializedAddress) < fixedSize {
+		return nil, errors.Errorf("serialized address is too short: %d bytes", len(serializedAddress))
+	}
+	isTriedOffset := 51 + servicesSize
+	sourceGroupLength := int(serializedAddress[isTriedOffset+1])
+	if len(serializedAddress) < fixedSize+sourceGroupLength {
+		return nil, errors.Errorf("serialized address is too short for its source group")
+	}
+
+	ip := make(net.IP, 16)
+	copy(ip, serializedAddress[1:17])
+	netAddress := &appmessage.NetAddress{
+		IP:        ip,
+		Port:      binary.LittleEndian.Uint16(serializedAddress[17:]),
+		Timestamp: mstime.UnixMilliseconds(int64(binary.LittleEndian.Uint64(serializedAddress[19:]))),
+	}
+	onionHost := serializedAddress[fixedSize+sourceGroupLength:]
+	if len(onionHost) > 0 {
+		netAddress.IP = nil
+		netAddress.OnionHost = string(onionHost)
+	}
+
+	var services appmessage.ServiceFlag
+	if servicesSize > 0 {
+		services = appmessage.ServiceFlag(binary.LittleEndian.Uint64(serializedAddress[51:]))
+	}
+
+	return &address{
+		netAddress:            netAddress,
+		connectionFailedCount: binary.LittleEndian.Uint64(serializedAddress[27:]),
+		lastAttempt:           deserializeOptionalTime(binary.LittleEndian.Uint64(serializedAddress[35:])),
+		lastSuccess:           deserializeOptionalTime(binary.LittleEndian.Uint64(serializedAddress[43:])),
+		services:              services,
+		isTried:               serializedAddress[isTriedOffset] == 1,
+		sourceGroup:           string(serializedAddress[fixedSize : fixedSize+sourceGroupLength]),
+	}, nil
 }
 
-func (as *addressStore) deserializeAddress(serializedAddress []byte) *address {
+// deserializeAddressV1 deserializes an address of the first version of the store,
+// which was serialized as ipv6 (16 bytes) + port (2 bytes) + timestamp (8 bytes) +
+// connectionFailedCount (8 bytes) + onion host
+func (as *addressStore) deserializeAddressV1(serializedAddress []byte) *address {
 	ip := make(net.IP, 16)
 	copy(ip[:], serializedAddress[:])
 
@@ -259,12 +457,33 @@ func (as *addressStore) deserializeAddress(serializedAddress []byte) *address {
 	timestamp := mstime.UnixMilliseconds(int64(binary.LittleEndian.Uint64(serializedAddress[18:])))
 	connectionFailedCount := binary.LittleEndian.Uint64(serializedAddress[26:])
 
+	netAddress := &appmessage.NetAddress{
+		IP:        ip,
+		Port:      port,
+		Timestamp: timestamp,
+	}
+	if len(serializedAddress) > 34 {
+		netAddress.IP = nil
+		netAddress.OnionHost = string(serializedAddress[34:])
+	}
+
 	return &address{
-		netAddress: &appmessage.NetAddress{
-			IP:        ip,
-			Port:      port,
-			Timestamp: timestamp,
-		},
+		netAddress:            netAddress,
 		connectionFailedCount: connectionFailedCount,
 	}
 }
+
+// serializeOptionalTime serializes the given time, with the zero time as 0
+func serializeOptionalTime(t mstime.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.UnixMilliseconds())
+}
+
+func deserializeOptionalTime(serializedTime uint64) mstime.Time {
+	if serializedTime == 0 {
+		return mstime.Time{}
+	}
+	return mstime.UnixMilliseconds(int64(serializedTime))
+}
@@ -1,7 +1,11 @@
 package addressmanager
 
 import (
+	"encoding/binary"
 	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
 	"github.com/kaspanet/kaspad/util/mstime"
 	"net"
 	"reflect"
@@ -29,19 +33,154 @@ func TestAddressSerialization(t *testing.T) {
 	defer teardown()
 	addressStore := addressManager.store
 
+	testAddresses := []*address{
+		{
+			netAddress: &appmessage.NetAddress{
+				IP:        net.ParseIP("2602:100:abcd::102"),
+				Port:      12345,
+				Timestamp: mstime.Now(),
+			},
+			connectionFailedCount: 98465,
+			sourceGroup:           "2602:100::",
+			isTried:               true,
+			lastAttempt:           mstime.Now(),
+			lastSuccess:           mstime.Now(),
+			services:              appmessage.SFNodeNetwork | appmessage.SFNodeBloom,
+		},
+		{
+			netAddress: &appmessage.NetAddress{
+				OnionHost: "pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion",
+				Port:      16111,
+				Timestamp: mstime.Now(),
+			},
+			connectionFailedCount: 3,
+			sourceGroup:           seededSourceGroup,
+			lastAttempt:           mstime.Now(),
+		},
+	}
+
+	for _, testAddress := range testAddresses {
+		serializedTestAddress := addressStore.serializeAddress(testAddress)
+		deserializedTestAddress, err := addressStore.deserializeAddress(serializedTestAddress)
+		if err != nil {
+			t.Fatalf("deserializeAddress: %s", err)
+		}
+		if !reflect.DeepEqual(testAddress, deserializedTestAddress) {
+			t.Fatalf("testAddress and deserializedTestAddress are not equal\n"+
+				"testAddress:%+v\ndeserializedTestAddress:%+v", testAddress, deserializedTestAddress)
+		}
+	}
+}
+
+func TestDeserializeAddressWithoutServices(t *testing.T) {
+	addressManager, teardown := newAddressManagerForTest(t, "TestDeserializeAddressWithoutServices")
+	defer teardown()
+	addressStore := addressManager.store
+
 	testAddress := &address{
 		netAddress: &appmessage.NetAddress{
 			IP:        net.ParseIP("2602:100:abcd::102"),
 			Port:      12345,
 			Timestamp: mstime.Now(),
 		},
-		connectionFailedCount: 98465,
+		connectionFailedCount: 2,
+		sourceGroup:           "2602:100::",
+		isTried:               true,
+		lastSuccess:           mstime.Now(),
+		services:              appmessage.SFNodeNetwork,
+	}
+
+	// An address of the previous serialization version is the current serialization
+	// without the services
+	serializedAddress := addressStore.serializeAddress(testAddress)
+	serializedAddressWithoutServices := append([]byte{}, serializedAddress[:51]...)
+	serializedAddressWithoutServices = append(serializedAddressWithoutServices, serializedAddress[59:]...)
+	serializedAddressWithoutServices[0] = addressSerializationVersionWithoutServices
+
+	deserializedAddress, err := addressStore.deserializeAddress(serializedAddressWithoutServices)
+	if err != nil {
+		t.Fatalf("deserializeAddress: %s", err)
+	}
+	testAddress.services = 0
+	if !reflect.DeepEqual(testAddress, deserializedAddress) {
+		t.Fatalf("testAddress and deserializedAddress are not equal\n"+
+			"testAddress:%+v\ndeserializedAddress:%+v", testAddress, deserializedAddress)
+	}
+}
+
+func TestMigrateFromV1(t *testing.T) {
+	cfg := config.DefaultConfig()
+	testDatabase, err := ldb.NewLevelDB(t.TempDir(), 8)
+	if err != nil {
+		t.Fatalf("Could not create a database: %s", err)
+	}
+	defer testDatabase.Close()
+
+	// serializeAddressV1 serializes an address as the first version of the store did
+	serializeAddressV1 := func(netAddress *appmessage.NetAddress, connectionFailedCount uint64) []byte {
+		serializedAddress := make([]byte, 34+len(netAddress.OnionHost))
+		copy(serializedAddress, netAddress.IP.To16())
+		binary.LittleEndian.PutUint16(serializedAddress[16:], netAddress.Port)
+		binary.LittleEndian.PutUint64(serializedAddress[18:], uint64(netAddress.Timestamp.UnixMilliseconds()))
+		binary.LittleEndian.PutUint64(serializedAddress[26:], connectionFailedCount)
+		copy(serializedAddress[34:], netAddress.OnionHost)
+		return serializedAddress
+	}
+
+	connectedAddress := &appmessage.NetAddress{IP: net.ParseIP("1.2.3.4"), Port: 16111, Timestamp: mstime.Now()}
+	unconnectedAddress := &appmessage.NetAddress{IP: net.ParseIP("5.6.7.8"), Port: 16111, Timestamp: mstime.Now()}
+	bannedAddress := &appmessage.NetAddress{IP: net.ParseIP("9.10.11.12"), Timestamp: mstime.Now()}
+	addressStore := &addressStore{}
+	for netAddress, connectionFailedCount := range map[*appmessage.NetAddress]uint64{
+		connectedAddress: 0, unconnectedAddress: 2} {
+
+		key := addressStore.serializeAddressKey(netAddressKey(netAddress))
+		err := testDatabase.Put(notBannedAddressBucketV1.Key(key), serializeAddressV1(netAddress, connectionFailedCount))
+		if err != nil {
+			t.Fatalf("Put: %s", err)
+		}
+	}
+	bannedKey := netAddressKey(bannedAddress)
+	err = testDatabase.Put(bannedAddressBucketV1.Key(bannedKey.address[:]), serializeAddressV1(bannedAddress, 0))
+	if err != nil {
+		t.Fatalf("Put: %s", err)
+	}
+
+	addressManager, err := New(NewConfig(cfg), testDatabase)
+	if err != nil {
+		t.Fatalf("Error creating address manager: %s", err)
+	}
+
+	for netAddress, expectedIsTried := range map[*appmessage.NetAddress]bool{
+		connectedAddress: true, unconnectedAddress: false} {
+
+		address, ok := addressManager.store.getNotBanned(netAddressKey(netAddress))
+		if !ok {
+			t.Fatalf("Address %s wasn't migrated", netAddress)
+		}
+		if !reflect.DeepEqual(address.netAddress, netAddress) {
+			t.Fatalf("Expected the migrated address to be %+v, but got %+v", netAddress, address.netAddress)
+		}
+		if address.isTried != expectedIsTried {
+			t.Fatalf("Expected isTried of %s to be %t", netAddress, expectedIsTried)
+		}
+	}
+	isBanned, err := addressManager.IsBanned(bannedAddress)
+	if err != nil {
+		t.Fatalf("IsBanned: %s", err)
+	}
+	if !isBanned {
+		t.Fatalf("Banned address %s wasn't migrated", bannedAddress)
 	}
 
-	serializedTestAddress := addressStore.serializeAddress(testAddress)
-	deserializedTestAddress := addressStore.deserializeAddress(serializedTestAddress)
-	if !reflect.DeepEqual(testAddress, deserializedTestAddress) {
-		t.Fatalf("testAddress and deserializedTestAddress are not equal\n"+
-			"testAddress:%+v\ndeserializedTestAddress:%+v", testAddress, deserializedTestAddress)
+	for _, bucket := range []*database.Bucket{notBannedAddressBucketV1, bannedAddressBucketV1} {
+		cursor, err := testDatabase.Cursor(bucket)
+		if err != nil {
+			t.Fatalf("Cursor: %s", err)
+		}
+		if cursor.First() {
+			t.Fatalf("Expected the migrated addresses to be removed from %s", bucket.Path())
+		}
+		cursor.Close()
 	}
 }
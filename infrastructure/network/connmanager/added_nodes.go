@@ -0,0 +1,158 @@
+package connmanager
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+var addedNodeBucket = database.MakeBucket([]byte("added-nodes"))
+
+// ErrNodeAlreadyAdded is the error returned when adding a node that was already added
+var ErrNodeAlreadyAdded = errors.New("ErrNodeAlreadyAdded")
+
+// ErrNodeNotAdded is the error returned when removing a node that wasn't added
+var ErrNodeNotAdded = errors.New("ErrNodeNotAdded")
+
+// ErrNotConnected is the error returned when disconnecting from an address that
+// there's no connection to
+var ErrNotConnected = errors.New("ErrNotConnected")
+
+// AddedNodeInfo holds information about a node that was added with AddNode
+type AddedNodeInfo struct {
+	Address     string
+	IsConnected bool
+}
+
+func (c *ConnectionManager) restoreAddedNodes() error {
+	cursor, err := c.database.Cursor(addedNodeBucket)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		databaseKey, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+		address := string(databaseKey.Suffix())
+		c.addedNodes[address] = struct{}{}
+		c.pendingRequested[address] = &connectionRequest{
+			address:     address,
+			isPermanent: true,
+		}
+	}
+
+	if len(c.addedNodes) > 0 {
+		log.Infof("Loaded %d added nodes", len(c.addedNodes))
+	}
+	return nil
+}
+
+// AddNode adds the given address to the nodes that the ConnectionManager stays
+// connected to. Unlike a permanent connection request, an added node is
+// persisted, so it's connected to again after a restart.
+func (c *ConnectionManager) AddNode(address string) error {
+	err := c.addNode(address)
+	if err != nil {
+		return err
+	}
+
+	spawn("ConnectionManager.AddNode", c.run)
+	return nil
+}
+
+func (c *ConnectionManager) addNode(address string) error {
+	c.connectionRequestsLock.Lock()
+	defer c.connectionRequestsLock.Unlock()
+
+	if _, ok := c.addedNodes[address]; ok {
+		return errors.Wrapf(ErrNodeAlreadyAdded, "%s was already added", address)
+	}
+	err := c.database.Put(addedNodeBucket.Key([]byte(address)), []byte{})
+	if err != nil {
+		return err
+	}
+	c.addedNodes[address] = struct{}{}
+
+	if connReq, ok := c.activeRequested[address]; ok {
+		connReq.isPermanent = true
+		return nil
+	}
+	if connReq, ok := c.pendingRequested[address]; ok {
+		connReq.isPermanent = true
+		return nil
+	}
+	c.pendingRequested[address] = &connectionRequest{
+		address:     address,
+		isPermanent: true,
+	}
+	return nil
+}
+
+// RemoveNode removes the given address from the added nodes, and disconnects from it
+func (c *ConnectionManager) RemoveNode(address string) error {
+	err := c.removeNode(address)
+	if err != nil {
+		return err
+	}
+
+	c.RemoveConnection(address)
+	return nil
+}
+
+func (c *ConnectionManager) removeNode(address string) error {
+	c.connectionRequestsLock.Lock()
+	defer c.connectionRequestsLock.Unlock()
+
+	if _, ok := c.addedNodes[address]; !ok {
+		return errors.Wrapf(ErrNodeNotAdded, "%s wasn't added", address)
+	}
+	err := c.database.Delete(addedNodeBucket.Key([]byte(address)))
+	if err != nil {
+		return err
+	}
+	delete(c.addedNodes, address)
+	return nil
+}
+
+// AddedNodeInfos returns information about all the added nodes
+func (c *ConnectionManager) AddedNodeInfos() []*AddedNodeInfo {
+	connectedAddresses := make(map[string]struct{})
+	for _, connection := range c.netAdapter.P2PConnections() {
+		connectedAddresses[connection.Address()] = struct{}{}
+	}
+
+	c.connectionRequestsLock.RLock()
+	defer c.connectionRequestsLock.RUnlock()
+
+	infos := make([]*AddedNodeInfo, 0, len(c.addedNodes))
+	for address := range c.addedNodes {
+		_, isConnected := connectedAddresses[address]
+		infos = append(infos, &AddedNodeInfo{
+			Address:     address,
+			IsConnected: isConnected,
+		})
+	}
+	return infos
+}
+
+// Disconnect disconnects from the given address. Permanent connection requests
+// and added nodes are connected to again on a later iteration.
+func (c *ConnectionManager) Disconnect(address string) error {
+	disconnected := c.disconnect(address)
+	if !disconnected {
+		return errors.Wrapf(ErrNotConnected, "there's no connection to %s", address)
+	}
+	return nil
+}
+
+func (c *ConnectionManager) disconnect(address string) bool {
+	disconnected := false
+	for _, connection := range c.netAdapter.P2PConnections() {
+		if connection.Address() == address {
+			connection.Disconnect()
+			disconnected = true
+		}
+	}
+	return disconnected
+}
@@ -0,0 +1,121 @@
+package connmanager
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+var anchorBucket = database.MakeBucket([]byte("anchors"))
+
+// anchorCount is the number of outgoing peers that are persisted as anchors
+const anchorCount = 2
+
+// restoreAnchors loads the anchors that were persisted by the previous run, so
+// that they're connected to before any other outgoing peer. This makes it harder
+// for an attacker to eclipse the node by filling its address manager while it's down.
+func (c *ConnectionManager) restoreAnchors() error {
+	cursor, err := c.database.Cursor(anchorBucket)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		databaseKey, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+		c.anchors = append(c.anchors, string(databaseKey.Suffix()))
+	}
+	c.pendingAnchors = append([]string{}, c.anchors...)
+
+	if len(c.anchors) > 0 {
+		log.Infof("Loaded %d anchor peers", len(c.anchors))
+	}
+	return nil
+}
+
+// connectToPendingAnchors makes a single connection attempt to every anchor
+// that was restored on startup. Anchors that can't be connected to are dropped,
+// so that an unreachable anchor doesn't delay startup on every run.
+func (c *ConnectionManager) connectToPendingAnchors(connSet connectionSet) {
+	pendingAnchors := c.pendingAnchors
+	c.pendingAnchors = nil
+
+	for _, address := range pendingAnchors {
+		if _, ok := c.activeOutgoing[address]; ok {
+			continue
+		}
+		if connection, ok := connSet.get(address); ok {
+			connSet.remove(connection)
+			c.activeOutgoing[address] = struct{}{}
+			continue
+		}
+
+		log.Debugf("Connecting to anchor %s", address)
+		err := c.initiateConnection(address)
+		if err != nil {
+			log.Debugf("Couldn't connect to anchor %s: %s", address, err)
+			continue
+		}
+		c.activeOutgoing[address] = struct{}{}
+	}
+}
+
+// updateAnchors keeps the persisted anchors in sync with the active outgoing
+// connections. Anchors that are still connected are kept, and the rest are
+// replaced by other active outgoing connections.
+func (c *ConnectionManager) updateAnchors() error {
+	anchors := make([]string, 0, anchorCount)
+	isAnchor := make(map[string]struct{}, anchorCount)
+	for _, address := range c.anchors {
+		if _, ok := c.activeOutgoing[address]; ok {
+			anchors = append(anchors, address)
+			isAnchor[address] = struct{}{}
+		}
+	}
+	for address := range c.activeOutgoing {
+		if len(anchors) == anchorCount {
+			break
+		}
+		if _, ok := isAnchor[address]; ok {
+			continue
+		}
+		anchors = append(anchors, address)
+		isAnchor[address] = struct{}{}
+	}
+
+	removedAnchors := make([]string, 0)
+	for _, address := range c.anchors {
+		if _, ok := isAnchor[address]; !ok {
+			removedAnchors = append(removedAnchors, address)
+		}
+	}
+	if len(removedAnchors) == 0 && len(anchors) == len(c.anchors) {
+		return nil
+	}
+
+	dbTx, err := c.database.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTx.RollbackUnlessClosed()
+
+	for _, address := range removedAnchors {
+		err := dbTx.Delete(anchorBucket.Key([]byte(address)))
+		if err != nil {
+			return err
+		}
+	}
+	for _, address := range anchors {
+		err := dbTx.Put(anchorBucket.Key([]byte(address)), []byte{})
+		if err != nil {
+			return err
+		}
+	}
+	err = dbTx.Commit()
+	if err != nil {
+		return err
+	}
+
+	c.anchors = anchors
+	return nil
+}
@@ -26,7 +26,7 @@ func (c *ConnectionManager) checkRequestedConnections(connSet connectionSet) {
 	c.connectionRequestsLock.Lock()
 	defer c.connectionRequestsLock.Unlock()
 
-	now := time.Now()
+	now := c.timeSource.Now()
 
 	for address, connReq := range c.activeRequested {
 		connection, ok := connSet.get(address)
@@ -111,5 +111,10 @@ func (c *ConnectionManager) addConnectionRequest(address string, isPermanent boo
 // RemoveConnection disconnects the connection for the given address
 // and removes it entirely from the connection manager.
 func (c *ConnectionManager) RemoveConnection(address string) {
-	panic("unimplemented")
+	c.connectionRequestsLock.Lock()
+	delete(c.activeRequested, address)
+	delete(c.pendingRequested, address)
+	c.connectionRequestsLock.Unlock()
+
+	c.disconnect(address)
 }
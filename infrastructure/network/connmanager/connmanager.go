@@ -1,20 +1,24 @@
 package connmanager
 
 import (
+	"crypto/rand"
 	"net"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
 	"github.com/kaspanet/kaspad/infrastructure/network/dnsseed"
 	"github.com/pkg/errors"
 
 	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
 
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
+	"github.com/kaspanet/kaspad/infrastructure/network/permissions"
 
 	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/util/timesource"
 )
 
 // connectionRequest represents a user request (either through CLI or RPC) to connect to a certain node
@@ -31,33 +35,61 @@ type ConnectionManager struct {
 	cfg            *config.Config
 	netAdapter     *netadapter.NetAdapter
 	addressManager *addressmanager.AddressManager
+	database       database.Database
+	timeSource     timesource.Source
 
 	activeRequested  map[string]*connectionRequest
 	pendingRequested map[string]*connectionRequest
+	addedNodes       map[string]struct{}
 	activeOutgoing   map[string]struct{}
 	targetOutgoing   int
+	anchors          []string
+	pendingAnchors   []string
 	activeIncoming   map[string]struct{}
 	maxIncoming      int
 
+	incomingRateLimiter *incomingRateLimiter
+	peerStatsSource     PeerStatsSource
+	evictionKey         [32]byte
+
+	// seededAddressCount counts the addresses that the last seeding attempt
+	// yielded. It's updated asynchronously by the seeders.
+	seededAddressCount uint32
+	hasSeeded          bool
+	nextFixedSeedTier  int
+
+	lastFeeler time.Time
+
 	stop                   uint32
 	connectionRequestsLock sync.RWMutex
 
 	resetLoopChan chan struct{}
-	loopTicker    *time.Ticker
+	loopTicker    timesource.Ticker
 }
 
 // New instantiates a new instance of a ConnectionManager
-func New(cfg *config.Config, netAdapter *netadapter.NetAdapter, addressManager *addressmanager.AddressManager) (*ConnectionManager, error) {
+func New(cfg *config.Config, netAdapter *netadapter.NetAdapter, addressManager *addressmanager.AddressManager,
+	database database.Database) (*ConnectionManager, error) {
+
 	c := &ConnectionManager{
 		cfg:              cfg,
 		netAdapter:       netAdapter,
 		addressManager:   addressManager,
+		database:         database,
+		timeSource:       timesource.System(),
 		activeRequested:  map[string]*connectionRequest{},
 		pendingRequested: map[string]*connectionRequest{},
+		addedNodes:       map[string]struct{}{},
 		activeOutgoing:   map[string]struct{}{},
 		activeIncoming:   map[string]struct{}{},
 		resetLoopChan:    make(chan struct{}),
-		loopTicker:       time.NewTicker(connectionsLoopInterval),
+
+		incomingRateLimiter: newIncomingRateLimiter(),
+	}
+
+	_, err := rand.Read(c.evictionKey[:])
+	if err != nil {
+		return nil, err
 	}
 
 	connectPeers := cfg.AddPeers
@@ -65,9 +97,14 @@ func New(cfg *config.Config, netAdapter *netadapter.NetAdapter, addressManager *
 		connectPeers = cfg.ConnectPeers
 	}
 
+	c.loopTicker = c.timeSource.NewTicker(connectionsLoopInterval)
+
 	c.maxIncoming = cfg.MaxInboundPeers
 	c.targetOutgoing = cfg.TargetOutboundPeers
 
+	// The node stays connected to its trusted peers, since they're what it compares
+	// its view of the DAG to
+	connectPeers = append(append([]string{}, connectPeers...), cfg.TrustedPeers...)
 	for _, connectPeer := range connectPeers {
 		c.pendingRequested[connectPeer] = &connectionRequest{
 			address:     connectPeer,
@@ -75,9 +112,29 @@ func New(cfg *config.Config, netAdapter *netadapter.NetAdapter, addressManager *
 		}
 	}
 
+	err = c.restoreAddedNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.restoreAnchors()
+	if err != nil {
+		return nil, err
+	}
+
 	return c, nil
 }
 
+// SetTimeSource replaces the source of the clock and the timers used by the
+// ConnectionManager, which the connection loop and the retry backoff of connection
+// requests are based on. It's meant for tests and simulations that need these to
+// be deterministic, and must be called before Start.
+func (c *ConnectionManager) SetTimeSource(timeSource timesource.Source) {
+	c.loopTicker.Stop()
+	c.timeSource = timeSource
+	c.loopTicker = timeSource.NewTicker(connectionsLoopInterval)
+}
+
 // Start begins the operation of the ConnectionManager
 func (c *ConnectionManager) Start() {
 	spawn("ConnectionManager.connectionsLoop", c.connectionsLoop)
@@ -122,8 +179,17 @@ func (c *ConnectionManager) connectionsLoop() {
 
 		c.checkOutgoingConnections(connSet)
 
+		if atomic.LoadUint32(&c.stop) == 0 {
+			err := c.updateAnchors()
+			if err != nil {
+				log.Warnf("Couldn't persist the anchor peers: %s", err)
+			}
+		}
+
 		c.checkIncomingConnections(connSet)
 
+		c.checkFeelerConnection()
+
 		c.waitTillNextIteration()
 	}
 }
@@ -136,11 +202,17 @@ func (c *ConnectionManager) ConnectionCount() int {
 // ErrCannotBanPermanent is the error returned when trying to ban a permanent peer.
 var ErrCannotBanPermanent = errors.New("ErrCannotBanPermanent")
 
+// ErrCannotBanNoBan is the error returned when trying to ban a peer with the noban permission.
+var ErrCannotBanNoBan = errors.New("ErrCannotBanNoBan")
+
 // Ban marks the given netConnection as banned
 func (c *ConnectionManager) Ban(netConnection *netadapter.NetConnection) error {
 	if c.isPermanent(netConnection.Address()) {
 		return errors.Wrapf(ErrCannotBanPermanent, "Cannot ban %s because it's a permanent connection", netConnection.Address())
 	}
+	if netConnection.Permissions().Has(permissions.NoBan) {
+		return errors.Wrapf(ErrCannotBanNoBan, "Cannot ban %s because it has the noban permission", netConnection.Address())
+	}
 
 	return c.addressManager.Ban(netConnection.NetAddress())
 }
@@ -168,7 +240,7 @@ func (c *ConnectionManager) BanByIP(ip net.IP) error {
 
 // IsBanned returns whether the given netConnection is banned
 func (c *ConnectionManager) IsBanned(netConnection *netadapter.NetConnection) (bool, error) {
-	if c.isPermanent(netConnection.Address()) {
+	if c.isPermanent(netConnection.Address()) || netConnection.Permissions().Has(permissions.NoBan) {
 		return false, nil
 	}
 
@@ -179,7 +251,7 @@ func (c *ConnectionManager) waitTillNextIteration() {
 	select {
 	case <-c.resetLoopChan:
 		c.loopTicker.Reset(connectionsLoopInterval)
-	case <-c.loopTicker.C:
+	case <-c.loopTicker.C():
 	}
 }
 
@@ -256,17 +328,43 @@ func (c *ConnectionManager) extractAddressIPs(address string) ([]net.IP, error)
 func (c *ConnectionManager) seedFromDNS() {
 	cfg := c.cfg
 	if len(c.activeOutgoing) == 0 && !cfg.DisableDNSSeed {
+		// Seeding is asynchronous, so whether the previous attempt failed is only
+		// known by the next one
+		if c.hasSeeded && atomic.SwapUint32(&c.seededAddressCount, 0) == 0 {
+			c.seedFromFixedSeeds()
+		}
+		c.hasSeeded = true
+
 		dnsseed.SeedFromDNS(cfg.NetParams(), cfg.DNSSeed, false, nil,
 			cfg.Lookup, func(addresses []*appmessage.NetAddress) {
 				// Kaspad uses a lookup of the dns seeder here. Since seeder returns
 				// IPs of nodes and not its own IP, we can not know real IP of
 				// source. So we'll take first returned address as source.
+				atomic.AddUint32(&c.seededAddressCount, uint32(len(addresses)))
 				_ = c.addressManager.AddAddresses(addresses...)
 			})
 
 		dnsseed.SeedFromGRPC(cfg.NetParams(), cfg.GRPCSeed, false, nil,
 			func(addresses []*appmessage.NetAddress) {
+				atomic.AddUint32(&c.seededAddressCount, uint32(len(addresses)))
 				_ = c.addressManager.AddAddresses(addresses...)
 			})
 	}
 }
+
+// seedFromFixedSeeds adds the addresses of the next tier of the fixed seeds. Once
+// every tier was tried, it starts over from the first one.
+func (c *ConnectionManager) seedFromFixedSeeds() {
+	fixedSeeds := c.cfg.NetParams().FixedSeeds
+	if len(fixedSeeds) == 0 {
+		return
+	}
+	tier := c.nextFixedSeedTier
+	c.nextFixedSeedTier = (c.nextFixedSeedTier + 1) % len(fixedSeeds)
+
+	log.Infof("DNS seeding yielded no addresses - seeding from tier %d of the fixed seeds", tier)
+	dnsseed.SeedFromFixedSeeds(c.cfg.NetParams(), tier, c.cfg.Lookup,
+		func(addresses []*appmessage.NetAddress) {
+			_ = c.addressManager.AddAddresses(addresses...)
+		})
+}
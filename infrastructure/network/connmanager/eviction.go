@@ -0,0 +1,165 @@
+package connmanager
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
+)
+
+const (
+	// evictionProtectedByNetGroup is the number of incoming connections from distinct
+	// netgroups that are never evicted. The netgroups are chosen by a keyed hash that's
+	// unknown to other nodes, so that an attacker can't pick the netgroups it connects
+	// from in order to be protected.
+	evictionProtectedByNetGroup = 4
+
+	// evictionProtectedByPing is the number of incoming connections with the fastest
+	// pings that are never evicted
+	evictionProtectedByPing = 8
+
+	// evictionProtectedByTransactions is the number of incoming connections that most
+	// recently announced transactions to us that are never evicted
+	evictionProtectedByTransactions = 4
+
+	// evictionProtectedByBlocks is the number of incoming connections that most
+	// recently announced blocks to us that are never evicted
+	evictionProtectedByBlocks = 4
+)
+
+// PeerStats is what the eviction of incoming connections knows about a connected peer
+type PeerStats struct {
+	MinPingDuration                 time.Duration // 0 if no ping returned yet
+	TimeConnected                   time.Duration
+	LastBlockAnnouncementTime       time.Time // The zero time if the peer never announced a block
+	LastTransactionAnnouncementTime time.Time // The zero time if the peer never announced transactions
+}
+
+// PeerStatsSource provides the PeerStats of the peers of connections that finished
+// their handshake
+type PeerStatsSource interface {
+	PeerStats(netConnection *netadapter.NetConnection) (*PeerStats, bool)
+}
+
+// SetPeerStatsSource sets where the eviction of incoming connections takes the
+// PeerStats of connected peers from. Without one, all peers look alike, and incoming
+// connections are evicted by netgroup alone. It must be called before Start.
+func (c *ConnectionManager) SetPeerStatsSource(peerStatsSource PeerStatsSource) {
+	c.peerStatsSource = peerStatsSource
+}
+
+// evictionCandidate is an incoming connection that may be evicted to make room for others
+type evictionCandidate struct {
+	connection  *netadapter.NetConnection
+	netGroup    string
+	netGroupKey uint64
+	stats       PeerStats
+}
+
+func (c *ConnectionManager) newEvictionCandidate(connection *netadapter.NetConnection) *evictionCandidate {
+	netGroup := c.addressManager.GroupKey(connection.NetAddress())
+	candidate := &evictionCandidate{
+		connection:  connection,
+		netGroup:    netGroup,
+		netGroupKey: c.keyedNetGroupHash(netGroup),
+	}
+	if c.peerStatsSource != nil {
+		if stats, ok := c.peerStatsSource.PeerStats(connection); ok {
+			candidate.stats = *stats
+		}
+	}
+	return candidate
+}
+
+func (c *ConnectionManager) keyedNetGroupHash(netGroup string) uint64 {
+	hash := sha256.Sum256(append(c.evictionKey[:], netGroup...))
+	return binary.LittleEndian.Uint64(hash[:8])
+}
+
+// selectCandidateToEvict returns the candidate to disconnect in order to make room for
+// other incoming connections, or nil if all of them are protected.
+//
+// Connections that are hard for an attacker to take over are protected first: ones
+// from distinct netgroups, ones with the fastest pings, ones that recently announced
+// transactions or blocks to us, and half of the rest by how long they've been
+// connected. Of the rest, the youngest connection of the netgroup with the most
+// connections is evicted, so that an attacker that opens many connections from a
+// single netgroup ends up evicting its own.
+func selectCandidateToEvict(candidates []*evictionCandidate) *evictionCandidate {
+	candidates = append([]*evictionCandidate{}, candidates...)
+
+	candidates = protectCandidates(candidates, evictionProtectedByNetGroup, func(a, b *evictionCandidate) bool {
+		return a.netGroupKey > b.netGroupKey
+	})
+	candidates = protectCandidates(candidates, evictionProtectedByPing, func(a, b *evictionCandidate) bool {
+		return hasFasterPing(a, b)
+	})
+	candidates = protectCandidates(candidates, evictionProtectedByTransactions, func(a, b *evictionCandidate) bool {
+		return a.stats.LastTransactionAnnouncementTime.After(b.stats.LastTransactionAnnouncementTime)
+	})
+	candidates = protectCandidates(candidates, evictionProtectedByBlocks, func(a, b *evictionCandidate) bool {
+		return a.stats.LastBlockAnnouncementTime.After(b.stats.LastBlockAnnouncementTime)
+	})
+	candidates = protectCandidates(candidates, len(candidates)/2, func(a, b *evictionCandidate) bool {
+		return a.stats.TimeConnected > b.stats.TimeConnected
+	})
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	netGroups := make(map[string][]*evictionCandidate)
+	for _, candidate := range candidates {
+		netGroups[candidate.netGroup] = append(netGroups[candidate.netGroup], candidate)
+	}
+	var evictedNetGroup []*evictionCandidate
+	var evictedNetGroupYoungest *evictionCandidate
+	for _, netGroup := range netGroups {
+		youngest := youngestCandidate(netGroup)
+		if len(netGroup) > len(evictedNetGroup) || (len(netGroup) == len(evictedNetGroup) &&
+			isYounger(youngest, evictedNetGroupYoungest)) {
+
+			evictedNetGroup = netGroup
+			evictedNetGroupYoungest = youngest
+		}
+	}
+	return evictedNetGroupYoungest
+}
+
+// protectCandidates removes from candidates the count candidates that come first by
+// isPreferred, and returns the rest
+func protectCandidates(candidates []*evictionCandidate, count int,
+	isPreferred func(a, b *evictionCandidate) bool) []*evictionCandidate {
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return isPreferred(candidates[i], candidates[j])
+	})
+	if count > len(candidates) {
+		count = len(candidates)
+	}
+	return candidates[count:]
+}
+
+// hasFasterPing returns whether a has a faster ping than b. Peers that didn't return a
+// ping yet are the slowest.
+func hasFasterPing(a, b *evictionCandidate) bool {
+	if a.stats.MinPingDuration == 0 || b.stats.MinPingDuration == 0 {
+		return b.stats.MinPingDuration == 0 && a.stats.MinPingDuration != 0
+	}
+	return a.stats.MinPingDuration < b.stats.MinPingDuration
+}
+
+func youngestCandidate(candidates []*evictionCandidate) *evictionCandidate {
+	var youngest *evictionCandidate
+	for _, candidate := range candidates {
+		if youngest == nil || isYounger(candidate, youngest) {
+			youngest = candidate
+		}
+	}
+	return youngest
+}
+
+func isYounger(a, b *evictionCandidate) bool {
+	return a.stats.TimeConnected < b.stats.TimeConnected
+}
@@ -0,0 +1,121 @@
+package connmanager
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
+)
+
+func newTestEvictionCandidate(netGroup string, netGroupKey uint64, timeConnected time.Duration) *evictionCandidate {
+	return &evictionCandidate{
+		connection:  &netadapter.NetConnection{},
+		netGroup:    netGroup,
+		netGroupKey: netGroupKey,
+		stats:       PeerStats{TimeConnected: timeConnected},
+	}
+}
+
+func TestSelectCandidateToEvict(t *testing.T) {
+	var candidates []*evictionCandidate
+	var attackers []*evictionCandidate
+
+	// Well behaved peers from distinct netgroups, which are either fast, useful or
+	// long connected
+	for i := 0; i < evictionProtectedByNetGroup; i++ {
+		candidates = append(candidates, newTestEvictionCandidate(fmt.Sprintf("honest-%d", i), 1000, time.Minute))
+	}
+	for i := 0; i < evictionProtectedByPing; i++ {
+		candidate := newTestEvictionCandidate(fmt.Sprintf("fast-%d", i), 0, time.Minute)
+		candidate.stats.MinPingDuration = time.Millisecond
+		candidates = append(candidates, candidate)
+	}
+	for i := 0; i < evictionProtectedByTransactions; i++ {
+		candidate := newTestEvictionCandidate(fmt.Sprintf("transactions-%d", i), 0, time.Minute)
+		candidate.stats.LastTransactionAnnouncementTime = time.Now()
+		candidates = append(candidates, candidate)
+	}
+	for i := 0; i < evictionProtectedByBlocks; i++ {
+		candidate := newTestEvictionCandidate(fmt.Sprintf("blocks-%d", i), 0, time.Minute)
+		candidate.stats.LastBlockAnnouncementTime = time.Now()
+		candidates = append(candidates, candidate)
+	}
+	for i := 0; i < 10; i++ {
+		candidates = append(candidates, newTestEvictionCandidate(fmt.Sprintf("old-%d", i), 0, time.Hour))
+	}
+
+	// An attacker that opens many connections from a single netgroup, and a younger
+	// peer of its own netgroup
+	for i := 0; i < 10; i++ {
+		attacker := newTestEvictionCandidate("attacker", 0, time.Duration(i+1)*time.Second)
+		candidates = append(candidates, attacker)
+		attackers = append(attackers, attacker)
+	}
+	candidates = append(candidates, newTestEvictionCandidate("new", 0, 0))
+
+	evictedAttackers := make(map[*evictionCandidate]struct{})
+	for len(evictedAttackers) < len(attackers)-1 {
+		evicted := selectCandidateToEvict(candidates)
+		if evicted == nil {
+			t.Fatalf("Expected an attacker to be evicted, but none was")
+		}
+		if evicted.netGroup != "attacker" {
+			t.Fatalf("Expected an attacker to be evicted, but %s was", evicted.netGroup)
+		}
+		for _, attacker := range attackers {
+			if attacker != evicted {
+				_, isEvicted := evictedAttackers[attacker]
+				if !isEvicted && attacker.stats.TimeConnected < evicted.stats.TimeConnected {
+					t.Fatalf("Expected the youngest attacker to be evicted first")
+				}
+			}
+		}
+		evictedAttackers[evicted] = struct{}{}
+
+		for i, candidate := range candidates {
+			if candidate == evicted {
+				candidates = append(candidates[:i], candidates[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func TestSelectCandidateToEvictProtected(t *testing.T) {
+	if selectCandidateToEvict(nil) != nil {
+		t.Fatalf("Expected nothing to be evicted out of no candidates")
+	}
+
+	var candidates []*evictionCandidate
+	for i := 0; i < evictionProtectedByNetGroup; i++ {
+		candidates = append(candidates, newTestEvictionCandidate(fmt.Sprintf("%d", i), uint64(i), time.Minute))
+	}
+	if selectCandidateToEvict(candidates) != nil {
+		t.Fatalf("Expected the candidates that are protected by netgroup not to be evicted")
+	}
+}
+
+func TestHasFasterPing(t *testing.T) {
+	noPing := newTestEvictionCandidate("a", 0, 0)
+	slow := newTestEvictionCandidate("b", 0, 0)
+	slow.stats.MinPingDuration = time.Second
+	fast := newTestEvictionCandidate("c", 0, 0)
+	fast.stats.MinPingDuration = time.Millisecond
+
+	tests := []struct {
+		a, b     *evictionCandidate
+		expected bool
+	}{
+		{a: fast, b: slow, expected: true},
+		{a: slow, b: fast, expected: false},
+		{a: slow, b: noPing, expected: true},
+		{a: noPing, b: slow, expected: false},
+		{a: noPing, b: noPing, expected: false},
+	}
+	for i, test := range tests {
+		if hasFasterPing(test.a, test.b) != test.expected {
+			t.Errorf("Test %d: expected hasFasterPing to be %t", i, test.expected)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package connmanager
 
+import "github.com/kaspanet/kaspad/infrastructure/network/permissions"
+
 // checkIncomingConnections makes sure there's no more than maxIncoming incoming connections
-// if there are - it randomly disconnects enough to go below that number
+// if there are - it evicts enough to go below that number, as selected by selectCandidateToEvict
 func (c *ConnectionManager) checkIncomingConnections(incomingConnectionSet connectionSet) {
 	if len(incomingConnectionSet) <= c.maxIncoming {
 		return
@@ -11,14 +13,34 @@ func (c *ConnectionManager) checkIncomingConnections(incomingConnectionSet conne
 	log.Debugf("Got %d incoming connections while only %d are allowed. Disconnecting "+
 		"%d", len(incomingConnectionSet), c.maxIncoming, numConnectionsOverMax)
 
-	// randomly disconnect nodes until the number of incoming connections is smaller than maxIncoming
+	// Peers with the noban permission are never disconnected to make room.
+	candidates := make([]*evictionCandidate, 0, len(incomingConnectionSet))
 	for _, connection := range incomingConnectionSet {
-		log.Debugf("Disconnecting %s due to exceeding incoming connections", connection)
-		connection.Disconnect()
+		if connection.Permissions().Has(permissions.NoBan) {
+			continue
+		}
+		candidates = append(candidates, c.newEvictionCandidate(connection))
+	}
+
+	for ; numConnectionsOverMax > 0; numConnectionsOverMax-- {
+		evicted := selectCandidateToEvict(candidates)
+		if evicted == nil {
+			// All of the candidates are protected, which happens when maxIncoming is
+			// small, so the youngest connection is the one to go, as if it was never
+			// accepted in the first place
+			evicted = youngestCandidate(candidates)
+			if evicted == nil {
+				return
+			}
+		}
+		log.Debugf("Disconnecting %s due to exceeding incoming connections", evicted.connection)
+		evicted.connection.Disconnect()
 
-		numConnectionsOverMax--
-		if numConnectionsOverMax == 0 {
-			break
+		for i, candidate := range candidates {
+			if candidate == evicted {
+				candidates = append(candidates[:i], candidates[i+1:]...)
+				break
+			}
 		}
 	}
 }
@@ -0,0 +1,101 @@
+package connmanager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
+	"github.com/kaspanet/kaspad/infrastructure/network/permissions"
+)
+
+const (
+	// incomingConnectionBurst is the number of incoming connections that a netgroup may
+	// make in a row before it's rate limited
+	incomingConnectionBurst = 10
+
+	// incomingConnectionInterval is how long it takes a rate limited netgroup to be
+	// allowed one more incoming connection
+	incomingConnectionInterval = 10 * time.Second
+
+	// maxIdleIncomingRateLimits is the number of netgroups whose rate limits are kept
+	// before the ones that are back to a full burst are forgotten
+	maxIdleIncomingRateLimits = 1024
+)
+
+// tokenBucket is the rate limit of the incoming connections of a single netgroup. Every
+// incoming connection takes a token, and tokens are added back at a constant rate, up
+// to incomingConnectionBurst.
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += float64(now.Sub(b.lastRefill)) / float64(incomingConnectionInterval)
+	if b.tokens > incomingConnectionBurst {
+		b.tokens = incomingConnectionBurst
+	}
+	b.lastRefill = now
+}
+
+// incomingRateLimiter rate limits the incoming connection attempts of every netgroup,
+// so that a single netgroup can't keep the node busy with connections that it's going
+// to evict anyway
+type incomingRateLimiter struct {
+	buckets map[string]*tokenBucket
+	lock    sync.Mutex
+}
+
+func newIncomingRateLimiter() *incomingRateLimiter {
+	return &incomingRateLimiter{buckets: make(map[string]*tokenBucket)}
+}
+
+// allow takes a token from the bucket of netGroup, and returns false if there were
+// none left
+func (l *incomingRateLimiter) allow(netGroup string, now time.Time) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	bucket, ok := l.buckets[netGroup]
+	if !ok {
+		if len(l.buckets) >= maxIdleIncomingRateLimits {
+			l.forgetFullBuckets(now)
+		}
+		bucket = &tokenBucket{tokens: incomingConnectionBurst, lastRefill: now}
+		l.buckets[netGroup] = bucket
+	}
+	bucket.refill(now)
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// forgetFullBuckets removes the buckets that are back to a full burst, which are the
+// same as ones that were never created
+func (l *incomingRateLimiter) forgetFullBuckets(now time.Time) {
+	for netGroup, bucket := range l.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= incomingConnectionBurst {
+			delete(l.buckets, netGroup)
+		}
+	}
+}
+
+// AllowIncoming records an incoming connection attempt of netConnection, and returns
+// whether it's within the rate limit of its netgroup. Connections from peers with the
+// noban permission and from local addresses, such as the ones that are forwarded by a
+// local proxy, are never rate limited.
+func (c *ConnectionManager) AllowIncoming(netConnection *netadapter.NetConnection) bool {
+	if netConnection.IsOutbound() || netConnection.Permissions().Has(permissions.NoBan) {
+		return true
+	}
+	if addressmanager.IsLocal(netConnection.NetAddress()) {
+		return true
+	}
+	netGroup := c.addressManager.GroupKey(netConnection.NetAddress())
+	return c.incomingRateLimiter.allow(netGroup, c.timeSource.Now())
+}
@@ -0,0 +1,64 @@
+package connmanager
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestIncomingRateLimiter(t *testing.T) {
+	limiter := newIncomingRateLimiter()
+	now := time.Unix(1600000000, 0)
+
+	for i := 0; i < incomingConnectionBurst; i++ {
+		if !limiter.allow("1.2.0.0", now) {
+			t.Fatalf("Expected connection %d of the burst to be allowed", i)
+		}
+	}
+	if limiter.allow("1.2.0.0", now) {
+		t.Fatalf("Expected a connection after the burst to be rate limited")
+	}
+	if !limiter.allow("1.3.0.0", now) {
+		t.Fatalf("Expected a connection from another netgroup to be allowed")
+	}
+
+	now = now.Add(incomingConnectionInterval / 2)
+	if limiter.allow("1.2.0.0", now) {
+		t.Fatalf("Expected a connection to be rate limited before a whole interval passed")
+	}
+	now = now.Add(incomingConnectionInterval / 2)
+	if !limiter.allow("1.2.0.0", now) {
+		t.Fatalf("Expected a connection to be allowed once an interval passed")
+	}
+	if limiter.allow("1.2.0.0", now) {
+		t.Fatalf("Expected only a single connection to be allowed after an interval")
+	}
+
+	now = now.Add(incomingConnectionBurst * incomingConnectionInterval * 2)
+	for i := 0; i < incomingConnectionBurst; i++ {
+		if !limiter.allow("1.2.0.0", now) {
+			t.Fatalf("Expected connection %d of the burst to be allowed once the bucket refilled", i)
+		}
+	}
+}
+
+func TestIncomingRateLimiterForgetsFullBuckets(t *testing.T) {
+	limiter := newIncomingRateLimiter()
+	now := time.Unix(1600000000, 0)
+
+	for i := 0; i < maxIdleIncomingRateLimits; i++ {
+		limiter.allow(fmt.Sprintf("netgroup-%d", i), now)
+	}
+	for i := 0; i < incomingConnectionBurst; i++ {
+		limiter.allow("busy", now)
+	}
+
+	now = now.Add(incomingConnectionInterval)
+	limiter.allow("new", now)
+	if len(limiter.buckets) != 2 {
+		t.Fatalf("Expected only the buckets that aren't full to be kept, but %d were", len(limiter.buckets))
+	}
+	if !limiter.allow("busy", now) || limiter.allow("busy", now) {
+		t.Fatalf("Expected the rate limit of a busy netgroup to be kept")
+	}
+}
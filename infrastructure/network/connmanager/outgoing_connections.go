@@ -1,6 +1,11 @@
 package connmanager
 
-import "github.com/kaspanet/kaspad/app/appmessage"
+import (
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
+)
 
 // checkOutgoingConnections goes over all activeOutgoing and makes sure they are still active.
 // Then it opens connections so that we have targetOutgoing active connections
@@ -16,6 +21,8 @@ func (c *ConnectionManager) checkOutgoingConnections(connSet connectionSet) {
 		delete(c.activeOutgoing, address)
 	}
 
+	c.connectToPendingAnchors(connSet)
+
 	connections := c.netAdapter.P2PConnections()
 	connectedAddresses := make([]*appmessage.NetAddress, len(connections))
 	for i, connection := range connections {
@@ -31,10 +38,10 @@ func (c *ConnectionManager) checkOutgoingConnections(connSet connectionSet) {
 		liveConnections, c.targetOutgoing, c.targetOutgoing-liveConnections)
 
 	connectionsNeededCount := c.targetOutgoing - len(c.activeOutgoing)
-	netAddresses := c.addressManager.RandomAddresses(connectionsNeededCount, connectedAddresses)
+	netAddresses := c.outgoingCandidates(connectionsNeededCount, connections, connectedAddresses)
 
 	for _, netAddress := range netAddresses {
-		addressString := netAddress.TCPAddress().String()
+		addressString := netAddress.String()
 
 		log.Debugf("Connecting to %s because we have %d outgoing connections and the target is "+
 			"%d", addressString, len(c.activeOutgoing), c.targetOutgoing)
@@ -59,3 +66,84 @@ func (c *ConnectionManager) checkOutgoingConnections(connSet connectionSet) {
 		c.seedFromDNS()
 	}
 }
+
+// outgoingCandidatesFactor is how many more addresses than needed are drawn
+// from the address manager, so that enough of them remain after the ones
+// that share a network group with another outgoing peer are filtered out
+const outgoingCandidatesFactor = 4
+
+// outgoingCandidates returns up to count addresses to make outgoing connections
+// to. No two of them, and none of them and an active outgoing connection, share
+// a network group, so that a single network operator can't control more than
+// one of the node's outgoing peers. Addresses in networks that the node can't
+// reach, such as IPv6 on a host without IPv6 connectivity, are skipped.
+func (c *ConnectionManager) outgoingCandidates(count int, connections []*netadapter.NetConnection,
+	exceptions []*appmessage.NetAddress) []*appmessage.NetAddress {
+
+	usedGroups := make(map[string]struct{}, len(c.activeOutgoing))
+	for _, connection := range connections {
+		if _, ok := c.activeOutgoing[connection.Address()]; ok {
+			usedGroups[c.addressManager.GroupKey(connection.NetAddress())] = struct{}{}
+		}
+	}
+
+	candidates := c.addressManager.RandomAddresses(count*outgoingCandidatesFactor, exceptions)
+	netAddresses := make([]*appmessage.NetAddress, 0, count)
+	for _, candidate := range candidates {
+		if len(netAddresses) == count {
+			break
+		}
+		if !c.netAdapter.CanDial(candidate.String()) {
+			log.Tracef("Skipping %s because its network is unreachable", candidate)
+			continue
+		}
+		group := c.addressManager.GroupKey(candidate)
+		if _, ok := usedGroups[group]; ok {
+			log.Tracef("Skipping %s because another outgoing peer is in its network group %s",
+				candidate, group)
+			continue
+		}
+		usedGroups[group] = struct{}{}
+		netAddresses = append(netAddresses, candidate)
+	}
+	return netAddresses
+}
+
+// feelerInterval is the minimal interval between two feeler connections
+const feelerInterval = 2 * time.Minute
+
+// checkFeelerConnection briefly connects to an address that the address manager wants
+// tested: either one that holds a contested position in the tried table, or one that was
+// never connected to. The connection is dropped as soon as it's established, and only
+// serves to tell the address manager whether the address is reachable.
+func (c *ConnectionManager) checkFeelerConnection() {
+	if len(c.activeOutgoing) < c.targetOutgoing || c.timeSource.Since(c.lastFeeler) < feelerInterval {
+		return
+	}
+	c.lastFeeler = c.timeSource.Now()
+
+	connections := c.netAdapter.P2PConnections()
+	connectedAddresses := make([]*appmessage.NetAddress, len(connections))
+	for i, connection := range connections {
+		connectedAddresses[i] = connection.NetAddress()
+	}
+
+	netAddress := c.addressManager.FeelerAddress(connectedAddresses)
+	if netAddress == nil {
+		return
+	}
+	addressString := netAddress.String()
+	if !c.netAdapter.CanDial(addressString) {
+		return
+	}
+
+	log.Debugf("Making a feeler connection to %s", addressString)
+	err := c.netAdapter.P2PConnect(addressString)
+	if err != nil {
+		log.Debugf("Feeler connection to %s failed: %s", addressString, err)
+		c.addressManager.MarkConnectionFailure(netAddress)
+		return
+	}
+	c.addressManager.MarkConnectionSuccess(netAddress)
+	c.disconnect(addressString)
+}
@@ -0,0 +1,25 @@
+// Package controlline holds helpers for the line-based control protocols the node speaks
+// with local routers, such as the Tor control protocol and the I2P SAM protocol.
+package controlline
+
+import "strings"
+
+// Unquote returns the quoted string at the start of s, whose opening quote was already
+// consumed, along with whatever follows its closing quote
+func Unquote(s string) (value string, rest string) {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				builder.WriteByte(s[i])
+			}
+		case '"':
+			return builder.String(), s[i+1:]
+		default:
+			builder.WriteByte(s[i])
+		}
+	}
+	return builder.String(), ""
+}
@@ -0,0 +1,25 @@
+package controlline
+
+import "testing"
+
+func TestUnquote(t *testing.T) {
+	tests := []struct {
+		s             string
+		expectedValue string
+		expectedRest  string
+	}{
+		{s: `value" KEY=other`, expectedValue: "value", expectedRest: " KEY=other"},
+		{s: `with spaces"`, expectedValue: "with spaces", expectedRest: ""},
+		{s: `escaped \"quote\" and \\"rest`, expectedValue: `escaped "quote" and \`, expectedRest: "rest"},
+		{s: `unterminated`, expectedValue: "unterminated", expectedRest: ""},
+		{s: `trailing backslash\`, expectedValue: "trailing backslash", expectedRest: ""},
+	}
+
+	for _, test := range tests {
+		value, rest := Unquote(test.s)
+		if value != test.expectedValue || rest != test.expectedRest {
+			t.Errorf("Unquote(%q): expected (%q, %q), but got (%q, %q)",
+				test.s, test.expectedValue, test.expectedRest, value, rest)
+		}
+	}
+}
@@ -159,6 +159,49 @@ func SeedFromGRPC(dagParams *dagconfig.Params, customSeed string, includeAllSubn
 	}
 }
 
+// SeedFromFixedSeeds resolves the given tier of the fixed seeds of the network
+// and populates the address manager with them
+func SeedFromFixedSeeds(dagParams *dagconfig.Params, tier int, lookupFn LookupFunc, seedFn OnSeed) {
+	if tier >= len(dagParams.FixedSeeds) {
+		return
+	}
+	fixedSeeds := dagParams.FixedSeeds[tier]
+
+	spawn("SeedFromFixedSeeds", func() {
+		var addresses []*appmessage.NetAddress
+		for _, fixedSeed := range fixedSeeds {
+			host, portString, err := net.SplitHostPort(fixedSeed)
+			if err != nil {
+				host, portString = fixedSeed, dagParams.DefaultPort
+			}
+			port, err := strconv.ParseUint(portString, 10, 16)
+			if err != nil {
+				log.Warnf("Invalid port of fixed seed %s: %s", fixedSeed, err)
+				continue
+			}
+
+			ips := []net.IP{net.ParseIP(host)}
+			if ips[0] == nil {
+				ips, err = lookupFn(host)
+				if err != nil {
+					log.Infof("Lookup failed on fixed seed %s: %s", host, err)
+					continue
+				}
+			}
+			for _, ip := range ips {
+				addresses = append(addresses, appmessage.NewNetAddressIPPort(ip, uint16(port)))
+			}
+		}
+
+		log.Infof("%d addresses found from tier %d of the fixed seeds", len(addresses), tier)
+
+		if len(addresses) == 0 {
+			return
+		}
+		seedFn(addresses)
+	})
+}
+
 func fromProtobufAddresses(proto []*pb2.NetAddress) []net.IP {
 	var addresses []net.IP
 
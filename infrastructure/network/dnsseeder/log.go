@@ -0,0 +1,9 @@
+package dnsseeder
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/panics"
+)
+
+var log = logger.RegisterSubSystem("DNSS")
+var spawn = panics.GoroutineWrapperFunc(log)
@@ -0,0 +1,215 @@
+package dnsseeder
+
+import (
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/network/dnsseed"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// maxUDPResponseSize is the largest response that resolvers that don't support EDNS
+// accept over UDP. Address records are left out of responses until they fit.
+const maxUDPResponseSize = 512
+
+// maxAddressRecords is the number of addresses in a response at most
+const maxAddressRecords = 25
+
+const (
+	// addressTTL is the TTL of the address records, which is short, so that
+	// resolvers keep asking for fresh addresses
+	addressTTL = 60
+
+	// zoneTTL is the TTL of the NS and SOA records of the zone
+	zoneTTL = 3600
+)
+
+// servicesLabelPrefix is the prefix of the label that limits the answer to the peers
+// that advertised the services that follow it in hex, as in x5.<zone> for peers that
+// advertised both SFNodeNetwork and SFNodeBloom
+const servicesLabelPrefix = 'x'
+
+// defaultServices are the services that the peers in answers to queries without a
+// services label advertised
+const defaultServices = appmessage.SFNodeNetwork
+
+// queryFilter is what the labels of a query name, below the zone, ask for
+type queryFilter struct {
+	isApex   bool
+	services appmessage.ServiceFlag
+
+	// isPartialNodes is whether the query asks for the partial nodes of a
+	// subnetwork, as in n<subnetwork ID>.<zone>. The address manager doesn't keep
+	// the subnetworks of peers, so there are never any.
+	isPartialNodes bool
+}
+
+// answer returns the serialized response to serializedQuery, or false if it's not
+// a query that should be answered at all
+func (s *Server) answer(serializedQuery []byte) ([]byte, bool) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(serializedQuery)
+	if err != nil || header.Response {
+		return nil, false
+	}
+	questions, err := parser.AllQuestions()
+	if err != nil {
+		return nil, false
+	}
+
+	response := &dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:               header.ID,
+			Response:         true,
+			OpCode:           header.OpCode,
+			Authoritative:    true,
+			RecursionDesired: header.RecursionDesired,
+		},
+	}
+	if len(questions) != 1 {
+		response.Header.RCode = dnsmessage.RCodeFormatError
+		return s.pack(response)
+	}
+	question := questions[0]
+	response.Questions = questions
+	if header.OpCode != 0 {
+		response.Header.RCode = dnsmessage.RCodeNotImplemented
+		return s.pack(response)
+	}
+
+	filter, isInZone, ok := s.parseQueryName(question.Name)
+	if !isInZone || question.Class != dnsmessage.ClassINET {
+		response.Header.Authoritative = false
+		response.Header.RCode = dnsmessage.RCodeRefused
+		return s.pack(response)
+	}
+	if !ok {
+		response.Header.RCode = dnsmessage.RCodeNameError
+		response.Authorities = []dnsmessage.Resource{s.soaRecord()}
+		return s.pack(response)
+	}
+
+	switch {
+	case question.Type == dnsmessage.TypeA && !filter.isPartialNodes:
+		ipv4, _ := s.reachableIPs(filter.services)
+		for _, ip := range randomIPs(ipv4) {
+			record := &dnsmessage.AResource{}
+			copy(record.A[:], ip)
+			response.Answers = append(response.Answers, s.addressRecord(question.Name, record))
+		}
+	case question.Type == dnsmessage.TypeAAAA && !filter.isPartialNodes:
+		_, ipv6 := s.reachableIPs(filter.services)
+		for _, ip := range randomIPs(ipv6) {
+			record := &dnsmessage.AAAAResource{}
+			copy(record.AAAA[:], ip)
+			response.Answers = append(response.Answers, s.addressRecord(question.Name, record))
+		}
+	case question.Type == dnsmessage.TypeNS && filter.isApex:
+		response.Answers = []dnsmessage.Resource{s.nsRecord()}
+	case question.Type == dnsmessage.TypeSOA && filter.isApex:
+		response.Answers = []dnsmessage.Resource{s.soaRecord()}
+	}
+	if len(response.Answers) == 0 {
+		response.Authorities = []dnsmessage.Resource{s.soaRecord()}
+	}
+	return s.pack(response)
+}
+
+// parseQueryName returns what the labels of name below the zone ask for, whether name
+// is in the zone at all, and false if the labels aren't ones the seeder knows. The
+// labels may come in any order, but each kind only once.
+func (s *Server) parseQueryName(name dnsmessage.Name) (filter *queryFilter, isInZone bool, ok bool) {
+	nameString := strings.ToLower(name.String())
+	zoneString := s.zone.String()
+	filter = &queryFilter{services: defaultServices}
+	if nameString == zoneString {
+		filter.isApex = true
+		return filter, true, true
+	}
+	if !strings.HasSuffix(nameString, "."+zoneString) {
+		return nil, false, false
+	}
+
+	hasServicesLabel, hasSubnetworkLabel := false, false
+	labels := strings.Split(strings.TrimSuffix(nameString, "."+zoneString), ".")
+	for _, label := range labels {
+		switch {
+		case len(label) > 1 && label[0] == servicesLabelPrefix && !hasServicesLabel:
+			services, err := strconv.ParseUint(label[1:], 16, 64)
+			if err != nil {
+				return nil, true, false
+			}
+			filter.services = appmessage.ServiceFlag(services)
+			hasServicesLabel = true
+		case len(label) > 0 && label[0] == dnsseed.SubnetworkIDPrefixChar && !hasSubnetworkLabel:
+			filter.isPartialNodes = len(label) > 1
+			hasSubnetworkLabel = true
+		default:
+			return nil, true, false
+		}
+	}
+	return filter, true, true
+}
+
+// randomIPs returns up to maxAddressRecords of ips, in random order
+func randomIPs(ips []net.IP) []net.IP {
+	shuffledIPs := make([]net.IP, len(ips))
+	copy(shuffledIPs, ips)
+	rand.Shuffle(len(shuffledIPs), func(i, j int) {
+		shuffledIPs[i], shuffledIPs[j] = shuffledIPs[j], shuffledIPs[i]
+	})
+	if len(shuffledIPs) > maxAddressRecords {
+		shuffledIPs = shuffledIPs[:maxAddressRecords]
+	}
+	return shuffledIPs
+}
+
+func (s *Server) addressRecord(name dnsmessage.Name, body dnsmessage.ResourceBody) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: addressTTL},
+		Body:   body,
+	}
+}
+
+func (s *Server) nsRecord() dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: s.zone, Class: dnsmessage.ClassINET, TTL: zoneTTL},
+		Body:   &dnsmessage.NSResource{NS: s.nameServer},
+	}
+}
+
+func (s *Server) soaRecord() dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: s.zone, Class: dnsmessage.ClassINET, TTL: zoneTTL},
+		Body: &dnsmessage.SOAResource{
+			NS:   s.nameServer,
+			MBox: s.hostmaster,
+			// The zone changes all the time, so its serial is the current time
+			Serial:  uint32(time.Now().Unix()),
+			Refresh: 604800,
+			Retry:   86400,
+			Expire:  2592000,
+			MinTTL:  addressTTL,
+		},
+	}
+}
+
+// pack serializes response, leaving out as many of its answers as needed for it to
+// fit in maxUDPResponseSize
+func (s *Server) pack(response *dnsmessage.Message) ([]byte, bool) {
+	for {
+		serializedResponse, err := response.Pack()
+		if err != nil {
+			log.Errorf("Error serializing DNS response: %s", err)
+			return nil, false
+		}
+		if len(serializedResponse) <= maxUDPResponseSize || len(response.Answers) == 0 {
+			return serializedResponse, true
+		}
+		response.Answers = response.Answers[:len(response.Answers)-1]
+	}
+}
@@ -0,0 +1,202 @@
+package dnsseeder
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/pkg/errors"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// maxQuerySize is the largest query that is read. Queries are a single question,
+// so anything larger isn't a query the seeder can answer.
+const maxQuerySize = 1024
+
+// cacheDuration is how long the addresses that match a filter are reused for before
+// they're taken from the address manager again
+const cacheDuration = 10 * time.Second
+
+// maxCachedFilters is the number of filters whose addresses are cached at most. Once
+// it's reached, the cache is cleared, so that querying many filters doesn't grow it
+// without bounds.
+const maxCachedFilters = 64
+
+// addressSource is where the seeder takes the addresses it answers with from
+type addressSource interface {
+	ReachableAddresses(services appmessage.ServiceFlag) []*appmessage.NetAddress
+}
+
+// Server is an authoritative DNS server for the zone of a DNS seed, which answers A
+// and AAAA queries with the addresses of reachable peers from the address manager.
+// Queries are only answered over UDP.
+type Server struct {
+	listenAddresses []string
+	zone            dnsmessage.Name
+	nameServer      dnsmessage.Name
+	hostmaster      dnsmessage.Name
+	defaultPort     uint16
+	addressSource   addressSource
+
+	cache     map[appmessage.ServiceFlag]*cachedAddresses
+	cacheLock sync.Mutex
+
+	connections []net.PacketConn
+	quit        chan struct{}
+	stopOnce    sync.Once
+}
+
+type cachedAddresses struct {
+	ipv4       []net.IP
+	ipv6       []net.IP
+	expiration time.Time
+}
+
+// NewServer creates a DNS seeder that answers for the zone of --dnsseederhost with
+// the addresses of addressSource. Use Start() to begin listening
+func NewServer(cfg *config.Config, addressSource addressSource) (*Server, error) {
+	defaultPort, err := strconv.ParseUint(cfg.NetParams().DefaultPort, 10, 16)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid default port %s", cfg.NetParams().DefaultPort)
+	}
+	return newServer(cfg.DNSSeederListeners, cfg.DNSSeederHost, cfg.DNSSeederNameServer, uint16(defaultPort),
+		addressSource)
+}
+
+func newServer(listenAddresses []string, zone string, nameServer string, defaultPort uint16,
+	addressSource addressSource) (*Server, error) {
+
+	zoneName, err := parseName(zone)
+	if err != nil {
+		return nil, err
+	}
+	nameServerName, err := parseName(nameServer)
+	if err != nil {
+		return nil, err
+	}
+	hostmasterName, err := parseName("hostmaster." + zone)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		listenAddresses: listenAddresses,
+		zone:            zoneName,
+		nameServer:      nameServerName,
+		hostmaster:      hostmasterName,
+		defaultPort:     defaultPort,
+		addressSource:   addressSource,
+		cache:           make(map[appmessage.ServiceFlag]*cachedAddresses),
+		quit:            make(chan struct{}),
+	}, nil
+}
+
+// parseName parses a domain name into its canonical form: lowercase and fully qualified
+func parseName(name string) (dnsmessage.Name, error) {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	parsedName, err := dnsmessage.NewName(name)
+	if err != nil || name == "." || strings.Contains(name, "..") {
+		return dnsmessage.Name{}, errors.Errorf("invalid domain name %s", name)
+	}
+	return parsedName, nil
+}
+
+// Start starts listening on all the configured listeners of the DNS seeder
+func (s *Server) Start() error {
+	for _, listenAddress := range s.listenAddresses {
+		connection, err := net.ListenPacket("udp", listenAddress)
+		if err != nil {
+			s.closeConnections()
+			return errors.Wrapf(err, "failed to listen for DNS queries on %s", listenAddress)
+		}
+		s.connections = append(s.connections, connection)
+		log.Infof("DNS seeder for %s listening on %s", s.zone, connection.LocalAddr())
+	}
+
+	for _, connection := range s.connections {
+		connection := connection
+		spawn("dnsseeder.Server.serve", func() {
+			s.serve(connection)
+		})
+	}
+	return nil
+}
+
+// Stop stops answering DNS queries
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.quit)
+		s.closeConnections()
+	})
+}
+
+func (s *Server) closeConnections() {
+	for _, connection := range s.connections {
+		err := connection.Close()
+		if err != nil {
+			log.Warnf("Error closing DNS seeder listener %s: %s", connection.LocalAddr(), err)
+		}
+	}
+}
+
+func (s *Server) serve(connection net.PacketConn) {
+	buffer := make([]byte, maxQuerySize)
+	for {
+		n, remoteAddress, err := connection.ReadFrom(buffer)
+		if err != nil {
+			select {
+			case <-s.quit:
+				return
+			default:
+			}
+			log.Errorf("Error reading DNS query on %s: %s", connection.LocalAddr(), err)
+			return
+		}
+
+		response, ok := s.answer(buffer[:n])
+		if !ok {
+			log.Debugf("Ignoring malformed DNS query from %s", remoteAddress)
+			continue
+		}
+		_, err = connection.WriteTo(response, remoteAddress)
+		if err != nil {
+			log.Debugf("Error answering DNS query from %s: %s", remoteAddress, err)
+		}
+	}
+}
+
+// reachableIPs returns the IPv4 and IPv6 addresses of the reachable peers that
+// advertised all of the given services and listen on the default port of the
+// network, which is the only port DNS seeding can tell
+func (s *Server) reachableIPs(services appmessage.ServiceFlag) (ipv4 []net.IP, ipv6 []net.IP) {
+	s.cacheLock.Lock()
+	defer s.cacheLock.Unlock()
+
+	now := time.Now()
+	if cached, ok := s.cache[services]; ok && now.Before(cached.expiration) {
+		return cached.ipv4, cached.ipv6
+	}
+
+	for _, address := range s.addressSource.ReachableAddresses(services) {
+		if address.Port != s.defaultPort {
+			continue
+		}
+		if ip := address.IP.To4(); ip != nil {
+			ipv4 = append(ipv4, ip)
+		} else {
+			ipv6 = append(ipv6, address.IP.To16())
+		}
+	}
+
+	if len(s.cache) >= maxCachedFilters {
+		s.cache = make(map[appmessage.ServiceFlag]*cachedAddresses)
+	}
+	s.cache[services] = &cachedAddresses{ipv4: ipv4, ipv6: ipv6, expiration: now.Add(cacheDuration)}
+	return ipv4, ipv6
+}
@@ -0,0 +1,219 @@
+package dnsseeder
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const testDefaultPort = 16111
+
+type fakeAddress struct {
+	netAddress *appmessage.NetAddress
+	services   appmessage.ServiceFlag
+}
+
+type fakeAddressSource []*fakeAddress
+
+func (f fakeAddressSource) ReachableAddresses(services appmessage.ServiceFlag) []*appmessage.NetAddress {
+	var addresses []*appmessage.NetAddress
+	for _, address := range f {
+		if address.services&services == services {
+			addresses = append(addresses, address.netAddress)
+		}
+	}
+	return addresses
+}
+
+func newFakeAddress(ip string, port uint16, services appmessage.ServiceFlag) *fakeAddress {
+	return &fakeAddress{
+		netAddress: &appmessage.NetAddress{IP: net.ParseIP(ip), Port: port},
+		services:   services,
+	}
+}
+
+func startTestServer(t *testing.T, addressSource addressSource) (*Server, net.Addr) {
+	server, err := newServer([]string{"127.0.0.1:0"}, "Seed.Example.org", "ns.example.org", testDefaultPort,
+		addressSource)
+	if err != nil {
+		t.Fatalf("newServer: %s", err)
+	}
+	err = server.Start()
+	if err != nil {
+		t.Fatalf("Start: %s", err)
+	}
+	t.Cleanup(server.Stop)
+	return server, server.connections[0].LocalAddr()
+}
+
+func query(t *testing.T, serverAddress net.Addr, name string, queryType dnsmessage.Type) *dnsmessage.Message {
+	queryMessage := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 1234, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName(name),
+			Type:  queryType,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	serializedQuery, err := queryMessage.Pack()
+	if err != nil {
+		t.Fatalf("Pack: %s", err)
+	}
+
+	connection, err := net.Dial("udp", serverAddress.String())
+	if err != nil {
+		t.Fatalf("Dial: %s", err)
+	}
+	defer connection.Close()
+	err = connection.SetDeadline(time.Now().Add(5 * time.Second))
+	if err != nil {
+		t.Fatalf("SetDeadline: %s", err)
+	}
+	_, err = connection.Write(serializedQuery)
+	if err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+	buffer := make([]byte, 65535)
+	n, err := connection.Read(buffer)
+	if err != nil {
+		t.Fatalf("Read: %s", err)
+	}
+	if n > maxUDPResponseSize {
+		t.Fatalf("Got a response of %d bytes, while the largest allowed is %d", n, maxUDPResponseSize)
+	}
+
+	var response dnsmessage.Message
+	err = response.Unpack(buffer[:n])
+	if err != nil {
+		t.Fatalf("Unpack: %s", err)
+	}
+	if response.Header.ID != queryMessage.Header.ID || !response.Header.Response {
+		t.Fatalf("Got a response that doesn't match the query: %+v", response.Header)
+	}
+	return &response
+}
+
+func answeredIPs(t *testing.T, response *dnsmessage.Message) map[string]struct{} {
+	ips := make(map[string]struct{})
+	for _, answer := range response.Answers {
+		switch body := answer.Body.(type) {
+		case *dnsmessage.AResource:
+			ips[net.IP(body.A[:]).String()] = struct{}{}
+		case *dnsmessage.AAAAResource:
+			ips[net.IP(body.AAAA[:]).String()] = struct{}{}
+		default:
+			t.Fatalf("Got an unexpected %s answer", answer.Header.Type)
+		}
+	}
+	return ips
+}
+
+func TestServer(t *testing.T) {
+	addressSource := fakeAddressSource{
+		newFakeAddress("1.2.3.4", testDefaultPort, appmessage.SFNodeNetwork),
+		newFakeAddress("1.2.3.5", testDefaultPort, appmessage.SFNodeNetwork|appmessage.SFNodeBloom),
+		newFakeAddress("1.2.3.6", testDefaultPort+1, appmessage.SFNodeNetwork),
+		newFakeAddress("2001:db8::1", testDefaultPort, appmessage.SFNodeNetwork|appmessage.SFNodeBloom),
+	}
+	_, serverAddress := startTestServer(t, addressSource)
+
+	tests := []struct {
+		name        string
+		queryType   dnsmessage.Type
+		expectedIPs []string
+	}{
+		{name: "seed.example.org.", queryType: dnsmessage.TypeA, expectedIPs: []string{"1.2.3.4", "1.2.3.5"}},
+		{name: "n.SEED.example.org.", queryType: dnsmessage.TypeA, expectedIPs: []string{"1.2.3.4", "1.2.3.5"}},
+		{name: "x4.seed.example.org.", queryType: dnsmessage.TypeA, expectedIPs: []string{"1.2.3.5"}},
+		{name: "n.x5.seed.example.org.", queryType: dnsmessage.TypeA, expectedIPs: []string{"1.2.3.5"}},
+		{name: "seed.example.org.", queryType: dnsmessage.TypeAAAA, expectedIPs: []string{"2001:db8::1"}},
+		{name: "x8.seed.example.org.", queryType: dnsmessage.TypeA, expectedIPs: nil},
+		{name: "n0000000000000000000000000000000000000001.seed.example.org.", queryType: dnsmessage.TypeA,
+			expectedIPs: nil},
+		{name: "seed.example.org.", queryType: dnsmessage.TypeTXT, expectedIPs: nil},
+	}
+	for _, test := range tests {
+		response := query(t, serverAddress, test.name, test.queryType)
+		if response.Header.RCode != dnsmessage.RCodeSuccess || !response.Header.Authoritative {
+			t.Fatalf("%s %s: expected an authoritative success, but got %s (authoritative: %t)",
+				test.name, test.queryType, response.Header.RCode, response.Header.Authoritative)
+		}
+		ips := answeredIPs(t, response)
+		if len(ips) != len(test.expectedIPs) {
+			t.Fatalf("%s %s: expected %d addresses, but got %d", test.name, test.queryType,
+				len(test.expectedIPs), len(ips))
+		}
+		for _, expectedIP := range test.expectedIPs {
+			if _, ok := ips[expectedIP]; !ok {
+				t.Fatalf("%s %s: expected %s to be answered", test.name, test.queryType, expectedIP)
+			}
+		}
+		if len(ips) == 0 && (len(response.Authorities) != 1 ||
+			response.Authorities[0].Header.Type != dnsmessage.TypeSOA) {
+			t.Fatalf("%s %s: expected the SOA record of the zone in an empty answer", test.name, test.queryType)
+		}
+	}
+}
+
+func TestServerZoneRecords(t *testing.T) {
+	_, serverAddress := startTestServer(t, fakeAddressSource{})
+
+	response := query(t, serverAddress, "seed.example.org.", dnsmessage.TypeNS)
+	if len(response.Answers) != 1 {
+		t.Fatalf("Expected a single NS record, but got %d answers", len(response.Answers))
+	}
+	nsRecord, ok := response.Answers[0].Body.(*dnsmessage.NSResource)
+	if !ok || nsRecord.NS.String() != "ns.example.org." {
+		t.Fatalf("Expected an NS record of ns.example.org., but got %s", response.Answers[0].Body.GoString())
+	}
+
+	response = query(t, serverAddress, "seed.example.org.", dnsmessage.TypeSOA)
+	if len(response.Answers) != 1 {
+		t.Fatalf("Expected a single SOA record, but got %d answers", len(response.Answers))
+	}
+	soaRecord, ok := response.Answers[0].Body.(*dnsmessage.SOAResource)
+	if !ok || soaRecord.MBox.String() != "hostmaster.seed.example.org." {
+		t.Fatalf("Expected an SOA record of hostmaster.seed.example.org., but got %s",
+			response.Answers[0].Body.GoString())
+	}
+
+	response = query(t, serverAddress, "y1.seed.example.org.", dnsmessage.TypeA)
+	if response.Header.RCode != dnsmessage.RCodeNameError {
+		t.Fatalf("Expected a name error for an unknown label, but got %s", response.Header.RCode)
+	}
+	response = query(t, serverAddress, "x1.x1.seed.example.org.", dnsmessage.TypeA)
+	if response.Header.RCode != dnsmessage.RCodeNameError {
+		t.Fatalf("Expected a name error for a repeated label, but got %s", response.Header.RCode)
+	}
+
+	response = query(t, serverAddress, "example.org.", dnsmessage.TypeA)
+	if response.Header.RCode != dnsmessage.RCodeRefused || response.Header.Authoritative {
+		t.Fatalf("Expected a non-authoritative refusal outside of the zone, but got %s (authoritative: %t)",
+			response.Header.RCode, response.Header.Authoritative)
+	}
+}
+
+func TestServerResponseSize(t *testing.T) {
+	var addressSource fakeAddressSource
+	for i := 0; i < 100; i++ {
+		ipv4 := net.IP{1, 2, 3, byte(i)}
+		ipv6 := net.ParseIP("2001:db8::")
+		ipv6[15] = byte(i)
+		addressSource = append(addressSource,
+			newFakeAddress(ipv4.String(), testDefaultPort, appmessage.SFNodeNetwork),
+			newFakeAddress(ipv6.String(), testDefaultPort, appmessage.SFNodeNetwork))
+	}
+	_, serverAddress := startTestServer(t, addressSource)
+
+	// query fails the test if the response is too large for UDP
+	for _, queryType := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		response := query(t, serverAddress, "x1.seed.example.org.", queryType)
+		if len(response.Answers) == 0 || len(response.Answers) > maxAddressRecords {
+			t.Fatalf("%s: expected between 1 and %d answers, but got %d", queryType, maxAddressRecords,
+				len(response.Answers))
+		}
+	}
+}
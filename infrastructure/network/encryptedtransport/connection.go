@@ -0,0 +1,191 @@
+package encryptedtransport
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// A frame starts with its encrypted length, so that the lengths of the
+	// messages aren't visible either
+	frameLengthSize          = 3
+	encryptedFrameLengthSize = frameLengthSize + chacha20poly1305.Overhead
+
+	// maxFramePayloadSize is the most that's sent in a single frame. Larger
+	// writes are split between several frames.
+	maxFramePayloadSize = 1 << 16
+)
+
+// frameCipher encrypts or decrypts the frames of one direction of a connection.
+// Every frame is sealed under the next nonce, so frames can't be replayed or
+// reordered without failing to authenticate.
+type frameCipher struct {
+	aead         cipher.AEAD
+	nonceCounter uint64
+}
+
+func newFrameCipher(key []byte) (*frameCipher, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &frameCipher{aead: aead}, nil
+}
+
+func (c *frameCipher) nextNonce() []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.LittleEndian.PutUint64(nonce[chacha20poly1305.NonceSize-8:], c.nonceCounter)
+	c.nonceCounter++
+	return nonce
+}
+
+func (c *frameCipher) seal(destination []byte, plaintext []byte) []byte {
+	return c.aead.Seal(destination, c.nextNonce(), plaintext, nil)
+}
+
+func (c *frameCipher) open(ciphertext []byte) ([]byte, error) {
+	plaintext, err := c.aead.Open(ciphertext[:0], c.nextNonce(), ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("failed to authenticate an encrypted frame")
+	}
+	return plaintext, nil
+}
+
+type encryptedConnection struct {
+	net.Conn
+
+	readLock      sync.Mutex
+	receiveCipher *frameCipher
+	// readBuffer is what's left of the last frame that was received
+	readBuffer []byte
+
+	writeLock  sync.Mutex
+	sendCipher *frameCipher
+}
+
+func newEncryptedConnection(connection net.Conn, sendKey []byte, receiveKey []byte) (*encryptedConnection, error) {
+	sendCipher, err := newFrameCipher(sendKey)
+	if err != nil {
+		return nil, err
+	}
+	receiveCipher, err := newFrameCipher(receiveKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedConnection{Conn: connection, sendCipher: sendCipher, receiveCipher: receiveCipher}, nil
+}
+
+func (c *encryptedConnection) Read(b []byte) (int, error) {
+	c.readLock.Lock()
+	defer c.readLock.Unlock()
+
+	for len(c.readBuffer) == 0 {
+		payload, err := c.readFrame()
+		if err != nil {
+			return 0, err
+		}
+		c.readBuffer = payload
+	}
+	n := copy(b, c.readBuffer)
+	c.readBuffer = c.readBuffer[n:]
+	return n, nil
+}
+
+func (c *encryptedConnection) Write(b []byte) (int, error) {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	written := 0
+	for len(b) > 0 {
+		payload := b
+		if len(payload) > maxFramePayloadSize {
+			payload = payload[:maxFramePayloadSize]
+		}
+		frame, err := c.sealFrame(payload)
+		if err != nil {
+			return written, err
+		}
+		_, err = c.Conn.Write(frame)
+		if err != nil {
+			return written, err
+		}
+		written += len(payload)
+		b = b[len(payload):]
+	}
+	return written, nil
+}
+
+// sealFrame encrypts payload into the next frame to send.
+// This function must be called with the write lock held, unless the connection
+// isn't used by anyone else yet.
+func (c *encryptedConnection) sealFrame(payload []byte) ([]byte, error) {
+	if len(payload) > maxFramePayloadSize {
+		return nil, errors.Errorf("frame payload of %d bytes is over the limit of %d",
+			len(payload), maxFramePayloadSize)
+	}
+	length := make([]byte, 4)
+	binary.LittleEndian.PutUint32(length, uint32(len(payload)))
+
+	frame := make([]byte, 0, encryptedFrameLengthSize+len(payload)+chacha20poly1305.Overhead)
+	frame = c.sendCipher.seal(frame, length[:frameLengthSize])
+	frame = c.sendCipher.seal(frame, payload)
+	return frame, nil
+}
+
+// readFrame receives the next frame and returns its decrypted payload.
+// This function must be called with the read lock held, unless the connection
+// isn't used by anyone else yet.
+func (c *encryptedConnection) readFrame() ([]byte, error) {
+	encryptedLength := make([]byte, encryptedFrameLengthSize)
+	_, err := io.ReadFull(c.Conn, encryptedLength)
+	if err != nil {
+		return nil, err
+	}
+	lengthBytes, err := c.receiveCipher.open(encryptedLength)
+	if err != nil {
+		return nil, err
+	}
+	length := int(lengthBytes[0]) | int(lengthBytes[1])<<8 | int(lengthBytes[2])<<16
+	if length > maxFramePayloadSize {
+		return nil, errors.Errorf("frame payload of %d bytes is over the limit of %d",
+			length, maxFramePayloadSize)
+	}
+
+	encryptedPayload := make([]byte, length+chacha20poly1305.Overhead)
+	_, err = io.ReadFull(c.Conn, encryptedPayload)
+	if err != nil {
+		return nil, err
+	}
+	return c.receiveCipher.open(encryptedPayload)
+}
+
+func (c *encryptedConnection) sendVersion() error {
+	frame, err := c.sealFrame([]byte{protocolVersion})
+	if err != nil {
+		return err
+	}
+	_, err = c.Conn.Write(frame)
+	if err != nil {
+		return handshakeError(err, "error sending the version")
+	}
+	return nil
+}
+
+// receiveVersion receives the first frame of the peer, which fails to authenticate
+// if the peers didn't derive the same keys
+func (c *encryptedConnection) receiveVersion() error {
+	version, err := c.readFrame()
+	if err != nil {
+		return handshakeError(err, "error receiving the version")
+	}
+	if len(version) != 1 || version[0] != protocolVersion {
+		return handshakeError(errors.Errorf("unsupported version %x", version), "error receiving the version")
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package encryptedtransport
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+type respondResult struct {
+	connection  net.Conn
+	isEncrypted bool
+	err         error
+}
+
+func handshake(t *testing.T, initiatorNetwork appmessage.KaspaNet, responderNetwork appmessage.KaspaNet) (
+	initiator net.Conn, initiatorErr error, responder respondResult) {
+
+	initiatorSide, responderSide := net.Pipe()
+	t.Cleanup(func() {
+		initiatorSide.Close()
+		responderSide.Close()
+	})
+
+	respondResultChan := make(chan respondResult, 1)
+	go func() {
+		connection, isEncrypted, err := Respond(responderSide, responderNetwork)
+		if err != nil {
+			// Unblock the initiator, which would otherwise wait for the version
+			responderSide.Close()
+		}
+		respondResultChan <- respondResult{connection: connection, isEncrypted: isEncrypted, err: err}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	initiator, initiatorErr = Initiate(ctx, initiatorSide, initiatorNetwork)
+	if initiatorErr != nil {
+		initiatorSide.Close()
+	}
+	return initiator, initiatorErr, <-respondResultChan
+}
+
+func TestEncryptedConnection(t *testing.T) {
+	initiator, err, responder := handshake(t, appmessage.Simnet, appmessage.Simnet)
+	if err != nil {
+		t.Fatalf("Initiate: %+v", err)
+	}
+	if responder.err != nil {
+		t.Fatalf("Respond: %+v", responder.err)
+	}
+	if !responder.isEncrypted {
+		t.Fatalf("Respond didn't detect the encrypted handshake")
+	}
+
+	// Larger than a frame, so that it's split between several
+	message := bytes.Repeat([]byte("kaspa"), maxFramePayloadSize)
+	go func() {
+		_, err := initiator.Write(message)
+		if err != nil {
+			t.Errorf("Write: %+v", err)
+		}
+	}()
+	received := make([]byte, len(message))
+	_, err = io.ReadFull(responder.connection, received)
+	if err != nil {
+		t.Fatalf("ReadFull: %+v", err)
+	}
+	if !bytes.Equal(received, message) {
+		t.Fatalf("received message is different than the one sent")
+	}
+
+	reply := []byte("reply")
+	go func() {
+		_, err := responder.connection.Write(reply)
+		if err != nil {
+			t.Errorf("Write: %+v", err)
+		}
+	}()
+	receivedReply := make([]byte, len(reply))
+	_, err = io.ReadFull(initiator, receivedReply)
+	if err != nil {
+		t.Fatalf("ReadFull: %+v", err)
+	}
+	if !bytes.Equal(receivedReply, reply) {
+		t.Fatalf("received reply %q instead of %q", receivedReply, reply)
+	}
+}
+
+func TestRespondToPlaintext(t *testing.T) {
+	initiatorSide, responderSide := net.Pipe()
+	defer initiatorSide.Close()
+	defer responderSide.Close()
+
+	sent := append(append([]byte{}, http2ClientPreface...), []byte("the rest of the stream")...)
+	go func() {
+		_, err := initiatorSide.Write(sent)
+		if err != nil {
+			t.Errorf("Write: %+v", err)
+		}
+	}()
+
+	connection, isEncrypted, err := Respond(responderSide, appmessage.Simnet)
+	if err != nil {
+		t.Fatalf("Respond: %+v", err)
+	}
+	if isEncrypted {
+		t.Fatalf("Respond detected an encrypted handshake in a plaintext connection")
+	}
+	received := make([]byte, len(sent))
+	_, err = io.ReadFull(connection, received)
+	if err != nil {
+		t.Fatalf("ReadFull: %+v", err)
+	}
+	if !bytes.Equal(received, sent) {
+		t.Fatalf("received %q instead of %q", received, sent)
+	}
+}
+
+func TestHandshakeWithDifferentNetworks(t *testing.T) {
+	_, err, responder := handshake(t, appmessage.Simnet, appmessage.Mainnet)
+	if !errors.Is(err, ErrHandshakeFailed) {
+		t.Fatalf("expected Initiate to fail with ErrHandshakeFailed, got %+v", err)
+	}
+	if responder.err == nil {
+		t.Fatalf("expected Respond to fail")
+	}
+}
+
+func TestTamperedFrame(t *testing.T) {
+	initiator, err, responder := handshake(t, appmessage.Simnet, appmessage.Simnet)
+	if err != nil {
+		t.Fatalf("Initiate: %+v", err)
+	}
+	if responder.err != nil {
+		t.Fatalf("Respond: %+v", responder.err)
+	}
+
+	frame, err := initiator.(*encryptedConnection).sealFrame([]byte("message"))
+	if err != nil {
+		t.Fatalf("sealFrame: %+v", err)
+	}
+	frame[len(frame)-1] ^= 1
+	go func() {
+		initiator.(*encryptedConnection).Conn.Write(frame)
+	}()
+
+	_, err = responder.connection.Read(make([]byte, 10))
+	if err == nil {
+		t.Fatalf("expected reading a tampered frame to fail")
+	}
+}
@@ -0,0 +1,214 @@
+// Package encryptedtransport encrypts P2P connections, so that whoever watches the
+// network between two peers can't read the messages they exchange.
+//
+// The peer that initiates a connection sends an ephemeral X25519 public key, and the
+// responder answers with its own. Both derive a key for every direction of the
+// connection from the shared secret, the public keys and the network, and every
+// message is then sent in frames that are encrypted and authenticated with
+// ChaCha20-Poly1305. The peers aren't authenticated, so this protects against passive
+// surveillance, but not against an attacker that actively intercepts the connection.
+//
+// Responders tell initiators that connect in plaintext apart by the HTTP/2 client
+// preface that gRPC clients send first, so nodes that don't support encryption can
+// still connect to ones that do.
+package encryptedtransport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"net"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	publicKeySize = curve25519.PointSize
+
+	// protocolVersion is sent in the first encrypted frame of every peer, which
+	// confirms to the other that both derived the same keys
+	protocolVersion = 1
+
+	keyDerivationSalt = "kaspad_encrypted_transport"
+)
+
+// http2ClientPreface is what gRPC clients send first on plaintext connections
+var http2ClientPreface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+// ErrHandshakeFailed is the error returned when the encrypted handshake with a peer fails,
+// which is usually because the peer doesn't support encryption
+var ErrHandshakeFailed = errors.New("ErrHandshakeFailed")
+
+// Initiate performs the handshake of the peer that initiated connection, until ctx is done,
+// and returns a connection that encrypts everything that's sent over connection.
+// Errors wrap ErrHandshakeFailed.
+func Initiate(ctx context.Context, connection net.Conn, network appmessage.KaspaNet) (net.Conn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		err := connection.SetDeadline(deadline)
+		if err != nil {
+			return nil, handshakeError(err, "error setting the handshake deadline")
+		}
+		defer connection.SetDeadline(time.Time{})
+	}
+
+	privateKey, publicKey, err := generateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	_, err = connection.Write(publicKey)
+	if err != nil {
+		return nil, handshakeError(err, "error sending the public key")
+	}
+
+	responderPublicKey := make([]byte, publicKeySize)
+	_, err = io.ReadFull(connection, responderPublicKey)
+	if err != nil {
+		return nil, handshakeError(err, "error receiving the public key of the responder")
+	}
+
+	initiatorKey, responderKey, err := deriveKeys(privateKey, responderPublicKey,
+		publicKey, responderPublicKey, network)
+	if err != nil {
+		return nil, err
+	}
+	encryptedConnection, err := newEncryptedConnection(connection, initiatorKey, responderKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = encryptedConnection.receiveVersion()
+	if err != nil {
+		return nil, err
+	}
+	err = encryptedConnection.sendVersion()
+	if err != nil {
+		return nil, err
+	}
+	return encryptedConnection, nil
+}
+
+// Respond performs the handshake of the peer that accepted connection, if the initiator
+// started one, and returns a connection that encrypts everything that's sent over it.
+// Otherwise, it returns a plaintext connection that's read from the start, and false.
+// It's up to the caller to limit how long the handshake may take.
+func Respond(connection net.Conn, network appmessage.KaspaNet) (net.Conn, bool, error) {
+	prefix := make([]byte, len(http2ClientPreface))
+	_, err := io.ReadFull(connection, prefix)
+	if err != nil {
+		return nil, false, handshakeError(err, "error receiving the start of the connection")
+	}
+	if bytes.Equal(prefix, http2ClientPreface) {
+		return &replayConnection{Conn: connection, replay: prefix}, false, nil
+	}
+
+	initiatorPublicKey := make([]byte, publicKeySize)
+	copy(initiatorPublicKey, prefix)
+	_, err = io.ReadFull(connection, initiatorPublicKey[len(prefix):])
+	if err != nil {
+		return nil, false, handshakeError(err, "error receiving the public key of the initiator")
+	}
+
+	privateKey, publicKey, err := generateKeyPair()
+	if err != nil {
+		return nil, false, err
+	}
+	initiatorKey, responderKey, err := deriveKeys(privateKey, initiatorPublicKey,
+		initiatorPublicKey, publicKey, network)
+	if err != nil {
+		return nil, false, err
+	}
+	encryptedConnection, err := newEncryptedConnection(connection, responderKey, initiatorKey)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// The public key and the version are sent together, so that the initiator
+	// is the one that waits for the other to be read
+	versionFrame, err := encryptedConnection.sealFrame([]byte{protocolVersion})
+	if err != nil {
+		return nil, false, err
+	}
+	_, err = connection.Write(append(publicKey, versionFrame...))
+	if err != nil {
+		return nil, false, handshakeError(err, "error sending the public key")
+	}
+
+	err = encryptedConnection.receiveVersion()
+	if err != nil {
+		return nil, false, err
+	}
+	return encryptedConnection, true, nil
+}
+
+func generateKeyPair() (privateKey []byte, publicKey []byte, err error) {
+	privateKey = make([]byte, curve25519.ScalarSize)
+	_, err = rand.Read(privateKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error generating an ephemeral key")
+	}
+	publicKey, err = curve25519.X25519(privateKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error computing an ephemeral public key")
+	}
+	return privateKey, publicKey, nil
+}
+
+// deriveKeys derives the keys of the frames sent by the initiator and by the responder
+// from the secret that privateKey shares with peerPublicKey. The keys are bound to the
+// public keys of both peers and to the network, so peers of different networks fail
+// the handshake.
+func deriveKeys(privateKey []byte, peerPublicKey []byte, initiatorPublicKey []byte, responderPublicKey []byte,
+	network appmessage.KaspaNet) (initiatorKey []byte, responderKey []byte, err error) {
+
+	// X25519 fails on low order points, which would make the shared secret predictable
+	sharedSecret, err := curve25519.X25519(privateKey, peerPublicKey)
+	if err != nil {
+		return nil, nil, handshakeError(err, "invalid public key")
+	}
+
+	salt := make([]byte, len(keyDerivationSalt)+4)
+	copy(salt, keyDerivationSalt)
+	binary.LittleEndian.PutUint32(salt[len(keyDerivationSalt):], uint32(network))
+	transcript := append(append([]byte{}, initiatorPublicKey...), responderPublicKey...)
+
+	keys := hkdf.New(sha256.New, sharedSecret, salt, transcript)
+	initiatorKey = make([]byte, chacha20poly1305.KeySize)
+	responderKey = make([]byte, chacha20poly1305.KeySize)
+	_, err = io.ReadFull(keys, initiatorKey)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	_, err = io.ReadFull(keys, responderKey)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return initiatorKey, responderKey, nil
+}
+
+func handshakeError(err error, message string) error {
+	return errors.Wrapf(ErrHandshakeFailed, "%s: %s", message, err)
+}
+
+// replayConnection is a connection that returns what was already read from it
+// before reading anything new
+type replayConnection struct {
+	net.Conn
+	replay []byte
+}
+
+func (c *replayConnection) Read(b []byte) (int, error) {
+	if len(c.replay) > 0 {
+		n := copy(b, c.replay)
+		c.replay = c.replay[n:]
+		return n, nil
+	}
+	return c.Conn.Read(b)
+}
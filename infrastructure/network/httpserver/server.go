@@ -0,0 +1,95 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// shutdownTimeout bounds how long Stop waits for in-flight requests to complete
+const shutdownTimeout = 5 * time.Second
+
+// Server serves an HTTP handler on its own listening addresses, over TLS if
+// it's given a TLS config
+type Server struct {
+	name               string
+	listeningAddresses []string
+	server             *http.Server
+	tlsConfig          *tls.Config
+
+	listeners []net.Listener
+	stopOnce  sync.Once
+}
+
+// New creates a new Server. The name is used only for logging.
+func New(name string, listeningAddresses []string, handler http.Handler, tlsConfig *tls.Config) *Server {
+	return &Server{
+		name:               name,
+		listeningAddresses: listeningAddresses,
+		server:             &http.Server{Handler: handler},
+		tlsConfig:          tlsConfig,
+	}
+}
+
+// Start starts listening on all the listening addresses of the server
+func (s *Server) Start() error {
+	for _, listenAddress := range s.listeningAddresses {
+		listener, err := net.Listen("tcp", listenAddress)
+		if err != nil {
+			s.closeListeners()
+			return errors.Wrapf(err, "failed to listen for %s connections on %s", s.name, listenAddress)
+		}
+		if s.tlsConfig != nil {
+			listener = tls.NewListener(listener, s.tlsConfig)
+		}
+		s.listeners = append(s.listeners, listener)
+		log.Infof("%s server listening on %s", s.name, listenAddress)
+	}
+
+	for _, listener := range s.listeners {
+		listener := listener
+		spawn("httpserver.Server.serve", func() {
+			err := s.server.Serve(listener)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Errorf("%s server stopped serving on %s: %s", s.name, listener.Addr(), err)
+			}
+		})
+	}
+	return nil
+}
+
+// Stop closes the listeners of the server and waits for in-flight requests to complete
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		err := s.server.Shutdown(ctx)
+		if err != nil {
+			log.Warnf("Error shutting down the %s server: %s", s.name, err)
+		}
+	})
+}
+
+// Addresses returns the addresses the server listens on. It's useful when a
+// listening address has port 0.
+func (s *Server) Addresses() []net.Addr {
+	addresses := make([]net.Addr, len(s.listeners))
+	for i, listener := range s.listeners {
+		addresses[i] = listener.Addr()
+	}
+	return addresses
+}
+
+func (s *Server) closeListeners() {
+	for _, listener := range s.listeners {
+		err := listener.Close()
+		if err != nil {
+			log.Warnf("Error closing %s listener %s: %s", s.name, listener.Addr(), err)
+		}
+	}
+}
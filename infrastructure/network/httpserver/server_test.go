@@ -0,0 +1,108 @@
+package httpserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("hello"))
+	})
+
+	plainServer := New("plain", []string{"127.0.0.1:0"}, handler, nil)
+	err := plainServer.Start()
+	if err != nil {
+		t.Fatalf("Start: %s", err)
+	}
+	defer plainServer.Stop()
+	expectResponse(t, http.DefaultClient, "http://"+plainServer.Addresses()[0].String())
+
+	certificate := selfSignedCertificate(t)
+	tlsServer := New("TLS", []string{"127.0.0.1:0"}, handler, &tls.Config{Certificates: []tls.Certificate{certificate}})
+	err = tlsServer.Start()
+	if err != nil {
+		t.Fatalf("Start: %s", err)
+	}
+	defer tlsServer.Stop()
+
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		t.Fatalf("ParseCertificate: %s", err)
+	}
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(leaf)
+	tlsClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs}}}
+	tlsAddress := tlsServer.Addresses()[0].String()
+	expectResponse(t, tlsClient, "https://"+tlsAddress)
+
+	// The TLS server answers a plain HTTP request with a Bad Request
+	response, err := http.Get("http://" + tlsAddress)
+	if err == nil {
+		response.Body.Close()
+		if response.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Expected a plain HTTP request to a TLS server to fail but got status %d", response.StatusCode)
+		}
+	}
+
+	tlsServer.Stop()
+	_, err = tlsClient.Get("https://" + tlsAddress)
+	if err == nil {
+		t.Fatalf("Expected a request to a stopped server to fail")
+	}
+}
+
+func TestServerListenError(t *testing.T) {
+	server := New("plain", []string{"127.0.0.1:0", "invalid address"}, http.NotFoundHandler(), nil)
+	err := server.Start()
+	if err == nil {
+		server.Stop()
+		t.Fatalf("Expected an error listening on an invalid address")
+	}
+}
+
+func expectResponse(t *testing.T, client *http.Client, url string) {
+	response, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("Get %s: %s", url, err)
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("ReadAll: %s", err)
+	}
+	if string(body) != "hello" {
+		t.Fatalf("Expected %s to respond with hello but got %s", url, body)
+	}
+}
+
+func selfSignedCertificate(t *testing.T) tls.Certificate {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certificateBytes, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %s", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{certificateBytes}, PrivateKey: privateKey}
+}
@@ -0,0 +1,95 @@
+package i2p
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	b32Suffix = ".b32.i2p"
+
+	// b32HostLength is the length of a b32 host, including its .b32.i2p suffix
+	b32HostLength = 52 + len(b32Suffix)
+
+	// destinationKeysLength is the length of the encryption and signing public keys at
+	// the start of a destination, which are followed by its certificate
+	destinationKeysLength = 384
+
+	// certificateHeaderLength is the length of the type and the payload length of a
+	// certificate
+	certificateHeaderLength = 3
+)
+
+var b32Encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// i2pBase64Encoding is the base64 variant of I2P, which uses - and ~ instead of + and /
+var i2pBase64Encoding = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-~")
+
+// garliCatPrefix is the IPv6 prefix of GarliCat (fd60:db4d:ddb5::/48), the I2P
+// counterpart of OnionCat
+var garliCatPrefix = []byte{0xfd, 0x60, 0xdb, 0x4d, 0xdd, 0xb5}
+
+// IsI2PHost returns whether host is a valid b32 I2P host
+func IsI2PHost(host string) bool {
+	_, err := decodeB32Host(host)
+	return err == nil
+}
+
+// decodeB32Host returns the hash of the destination of the given b32 host
+func decodeB32Host(host string) ([]byte, error) {
+	host = strings.ToLower(host)
+	if len(host) != b32HostLength || !strings.HasSuffix(host, b32Suffix) {
+		return nil, errors.Errorf("%s is not a b32 I2P host", host)
+	}
+	hash, err := b32Encoding.DecodeString(strings.ToUpper(strings.TrimSuffix(host, b32Suffix)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s is not a b32 I2P host", host)
+	}
+	return hash, nil
+}
+
+// hostOfDestination returns the b32 host of the given destination, which is the
+// base32 encoding of its hash
+func hostOfDestination(destination []byte) string {
+	hash := sha256.Sum256(destination)
+	return strings.ToLower(b32Encoding.EncodeToString(hash[:])) + b32Suffix
+}
+
+// publicDestination returns the destination at the start of the given private key
+// of a session
+func publicDestination(privateKey []byte) ([]byte, error) {
+	if len(privateKey) < destinationKeysLength+certificateHeaderLength {
+		return nil, errors.Errorf("the I2P private key is too short")
+	}
+	certificateLength := binary.BigEndian.Uint16(privateKey[destinationKeysLength+1:])
+	destinationLength := destinationKeysLength + certificateHeaderLength + int(certificateLength)
+	if len(privateKey) < destinationLength {
+		return nil, errors.Errorf("the I2P private key is too short for its certificate")
+	}
+	return privateKey[:destinationLength], nil
+}
+
+// GarliCatIP returns the GarliCat IPv6 address of the given b32 I2P host, which is
+// used wherever the peer is identified by an IP, such as for banning
+func GarliCatIP(host string) (net.IP, error) {
+	hash, err := decodeB32Host(host)
+	if err != nil {
+		return nil, err
+	}
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, garliCatPrefix)
+	copy(ip[len(garliCatPrefix):], hash)
+	return ip, nil
+}
+
+// IsGarliCatIP returns whether ip is in the GarliCat range
+func IsGarliCatIP(ip net.IP) bool {
+	ip = ip.To16()
+	return ip != nil && ip.To4() == nil && string(ip[:len(garliCatPrefix)]) == string(garliCatPrefix)
+}
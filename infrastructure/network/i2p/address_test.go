@@ -0,0 +1,67 @@
+package i2p
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestGarliCatIP(t *testing.T) {
+	destination := bytes.Repeat([]byte{1}, destinationKeysLength+certificateHeaderLength)
+	host := hostOfDestination(destination)
+	if !IsI2PHost(host) {
+		t.Fatalf("IsI2PHost: expected %s to be an I2P host", host)
+	}
+
+	ip, err := GarliCatIP(host)
+	if err != nil {
+		t.Fatalf("GarliCatIP: %+v", err)
+	}
+	if !IsGarliCatIP(ip) {
+		t.Fatalf("IsGarliCatIP: expected %s to be a GarliCat IP", ip)
+	}
+	hash := sha256.Sum256(destination)
+	if !bytes.Equal(ip[len(garliCatPrefix):], hash[:len(ip)-len(garliCatPrefix)]) {
+		t.Fatalf("GarliCatIP: expected %s to end with the start of the hash of the destination", ip)
+	}
+
+	tests := []struct {
+		host      string
+		isI2PHost bool
+	}{
+		{host: host, isI2PHost: true},
+		{host: "UDHDRTRCETJM5SXZSKJYR5ZTQ4G2GFQOBTP65RFW3VFMDVAAX3IQ.B32.I2P", isI2PHost: true},
+		{host: "udhdrtrcetjm5sxzskjyr5ztq4g2gfqobtp65rfw3vfmdvaax3iq.i2p", isI2PHost: false},
+		{host: "udhdrtrcetjm5sxzskjyr5ztq4g2gfqobtp65rfw3vfmdvaax3.b32.i2p", isI2PHost: false},
+		{host: "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad.onion", isI2PHost: false},
+		{host: "127.0.0.1", isI2PHost: false},
+	}
+	for _, test := range tests {
+		isI2PHost := IsI2PHost(test.host)
+		if isI2PHost != test.isI2PHost {
+			t.Errorf("IsI2PHost(%s): expected %t but got %t", test.host, test.isI2PHost, isI2PHost)
+		}
+	}
+}
+
+func TestPublicDestination(t *testing.T) {
+	const certificatePayloadLength = 7
+	privateKey := make([]byte, destinationKeysLength+certificateHeaderLength+certificatePayloadLength+64)
+	privateKey[destinationKeysLength] = 5
+	privateKey[destinationKeysLength+2] = certificatePayloadLength
+
+	destination, err := publicDestination(privateKey)
+	if err != nil {
+		t.Fatalf("publicDestination: %+v", err)
+	}
+	expectedLength := destinationKeysLength + certificateHeaderLength + certificatePayloadLength
+	if len(destination) != expectedLength {
+		t.Fatalf("publicDestination: expected a destination of %d bytes but got %d bytes",
+			expectedLength, len(destination))
+	}
+
+	_, err = publicDestination(privateKey[:expectedLength-1])
+	if err == nil {
+		t.Fatalf("publicDestination: expected an error for a private key shorter than its certificate")
+	}
+}
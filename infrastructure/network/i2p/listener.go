@@ -0,0 +1,136 @@
+package i2p
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// listener accepts the streams of I2P peers to the destination of the node, one
+// STREAM ACCEPT at a time
+type listener struct {
+	transport *Transport
+	address   net.Addr
+
+	lock      sync.Mutex
+	accepting *samConn
+	closed    bool
+}
+
+func newListener(transport *Transport, address net.Addr) *listener {
+	return &listener{transport: transport, address: address}
+}
+
+// acceptError is an error of the SAM bridge while accepting a stream. It's temporary,
+// so that the gRPC server retries accepting rather than stopping to serve.
+type acceptError struct {
+	error
+}
+
+func (e acceptError) Temporary() bool {
+	return true
+}
+
+// Accept waits for an I2P peer to connect, and returns its stream
+func (l *listener) Accept() (net.Conn, error) {
+	conn, s, err := l.startAccepting()
+	if err != nil {
+		if l.isClosed() {
+			return nil, net.ErrClosed
+		}
+		return nil, acceptError{err}
+	}
+	defer l.stopAccepting()
+
+	// The first line of an accepted stream is the destination of the peer
+	line, err := conn.readLine()
+	if err != nil {
+		conn.Close()
+		if l.isClosed() {
+			return nil, net.ErrClosed
+		}
+		return nil, acceptError{err}
+	}
+	peerDestination, _, _ := strings.Cut(line, " ")
+	decodedDestination, err := i2pBase64Encoding.DecodeString(peerDestination)
+	if err != nil {
+		conn.Close()
+		return nil, acceptError{errors.Wrapf(err, "the I2P SAM bridge accepted a stream from a malformed destination")}
+	}
+	remoteAddress, err := l.transport.addressOfHost(hostOfDestination(decodedDestination))
+	if err != nil {
+		conn.Close()
+		return nil, acceptError{err}
+	}
+	localAddress, err := l.transport.addressOfHost(s.host)
+	if err != nil {
+		conn.Close()
+		return nil, acceptError{err}
+	}
+	return &connection{samConn: conn, localAddress: localAddress, remoteAddress: remoteAddress}, nil
+}
+
+// startAccepting opens a SAM connection that waits for the next stream of the session
+func (l *listener) startAccepting() (*samConn, *session, error) {
+	if l.isClosed() {
+		return nil, nil, net.ErrClosed
+	}
+	ctx := context.Background()
+	s, err := l.transport.getSession(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := dialSAM(ctx, l.transport.samAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	arguments, err := conn.command(ctx, "STREAM STATUS", "STREAM ACCEPT ID=%s SILENT=false", s.id)
+	if err != nil {
+		conn.Close()
+		if arguments["RESULT"] == samResultInvalidID {
+			l.transport.closeSession(s)
+		}
+		return nil, nil, err
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.closed {
+		conn.Close()
+		return nil, nil, net.ErrClosed
+	}
+	l.accepting = conn
+	return conn, s, nil
+}
+
+func (l *listener) stopAccepting() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.accepting = nil
+}
+
+func (l *listener) isClosed() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.closed
+}
+
+// Close stops accepting streams. The session itself is closed along with the transport.
+func (l *listener) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.closed = true
+	if l.accepting != nil {
+		return errors.WithStack(l.accepting.Close())
+	}
+	return nil
+}
+
+// Addr returns the GarliCat address of the destination of the node
+func (l *listener) Addr() net.Addr {
+	return l.address
+}
@@ -0,0 +1,7 @@
+package i2p
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+)
+
+var log = logger.RegisterSubSystem("I2PT")
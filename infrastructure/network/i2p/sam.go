@@ -0,0 +1,134 @@
+package i2p
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/network/controlline"
+	"github.com/pkg/errors"
+)
+
+const (
+	samVersion = "3.1"
+
+	samDialTimeout = 10 * time.Second
+
+	// samResultOK is the RESULT of successful SAM replies
+	samResultOK = "OK"
+
+	// samResultInvalidID is the RESULT of a SAM reply to a command for a session
+	// that doesn't exist anymore
+	samResultInvalidID = "INVALID_ID"
+)
+
+// samConn is a connection to the SAM bridge of an I2P router, as specified in
+// https://geti2p.net/en/docs/api/samv3. Once a stream is connected or accepted on
+// it, it carries the data of the stream.
+type samConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// dialSAM connects to the SAM bridge at address and negotiates the SAM version
+func dialSAM(ctx context.Context, address string) (*samConn, error) {
+	ctx, cancel := context.WithTimeout(ctx, samDialTimeout)
+	defer cancel()
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not connect to the I2P SAM bridge at %s", address)
+	}
+	c := &samConn{Conn: conn, reader: bufio.NewReader(conn)}
+
+	_, err = c.command(ctx, "HELLO REPLY", "HELLO VERSION MIN=%s MAX=%s", samVersion, samVersion)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Read reads the data of the stream, starting with whatever was buffered while
+// reading the replies of the SAM bridge
+func (c *samConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// command sends a command to the SAM bridge and returns the arguments of its reply,
+// which must start with expectedReply. The arguments are returned along with the
+// error of a failed command, so that its RESULT may be inspected.
+func (c *samConn) command(ctx context.Context, expectedReply string, format string, args ...interface{}) (
+	map[string]string, error) {
+
+	if deadline, ok := ctx.Deadline(); ok {
+		err := c.SetDeadline(deadline)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		defer c.SetDeadline(time.Time{})
+	}
+
+	_, err := fmt.Fprintf(c.Conn, format+"\n", args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	commandName := strings.SplitN(format, " ", 2)[0]
+	reply, arguments := parseReply(line)
+	if reply != expectedReply {
+		return nil, errors.Errorf("the I2P SAM bridge replied to %s with %s", commandName, reply)
+	}
+	if arguments["RESULT"] != samResultOK {
+		err := errors.Errorf("the I2P SAM bridge rejected %s with %s", commandName, arguments["RESULT"])
+		if message, ok := arguments["MESSAGE"]; ok {
+			err = errors.Wrap(err, message)
+		}
+		return arguments, err
+	}
+	return arguments, nil
+}
+
+func (c *samConn) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", errors.Wrapf(err, "could not read from the I2P SAM bridge")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// parseReply splits a SAM reply line of the form `TOPIC TYPE KEY=VALUE KEY="QUOTED VALUE"`
+// into its topic and type, and its arguments
+func parseReply(line string) (reply string, arguments map[string]string) {
+	arguments = make(map[string]string)
+	var words []string
+	for line != "" {
+		line = strings.TrimLeft(line, " ")
+		if line == "" {
+			break
+		}
+		var token string
+		key, afterKey, isArgument := strings.Cut(line, "=")
+		if isArgument && !strings.Contains(key, " ") {
+			var value string
+			if strings.HasPrefix(afterKey, `"`) {
+				value, line = controlline.Unquote(afterKey[1:])
+			} else {
+				value, line, _ = strings.Cut(afterKey, " ")
+			}
+			arguments[key] = value
+			continue
+		}
+		token, line, _ = strings.Cut(line, " ")
+		words = append(words, token)
+	}
+	return strings.Join(words, " "), arguments
+}
@@ -0,0 +1,283 @@
+package i2p
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/network/transport"
+	"github.com/pkg/errors"
+)
+
+const (
+	privateKeyFileName = "i2p_private_key"
+
+	// i2pDialTimeout is how long connecting to an I2P peer may take. Building the
+	// tunnels to a destination takes a lot longer than a TCP handshake.
+	i2pDialTimeout = 60 * time.Second
+
+	// signatureTypeEdDSA is the signature type of new destinations, Ed25519
+	signatureTypeEdDSA = 7
+
+	sessionIDLength = 8
+)
+
+// Transport connects to peers over I2P through the SAM bridge of an I2P router, and
+// accepts the connections of I2P peers to the destination of the node. The private key
+// of the destination is kept in the app directory, so that the node keeps the same I2P
+// address across restarts.
+type Transport struct {
+	samAddress     string
+	privateKeyFile string
+
+	// port is the port of the I2P addresses of peers. SAM 3.1 streams have no ports,
+	// so it only serves to keep the addresses in the host:port form of the other transports.
+	port int
+
+	sessionLock sync.Mutex
+	session     *session
+}
+
+// session is a SAM stream session, which lives as long as its control connection is open
+type session struct {
+	id      string
+	control *samConn
+	host    string
+}
+
+// NewTransport returns a Transport through the SAM bridge set by --i2psam, or nil
+// if it isn't set
+func NewTransport(cfg *config.Config) (*Transport, error) {
+	if cfg.I2PSAM == "" {
+		return nil, nil
+	}
+	port, err := strconv.Atoi(cfg.NetParams().DefaultPort)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Transport{
+		samAddress:     cfg.I2PSAM,
+		privateKeyFile: filepath.Join(cfg.AppDir, privateKeyFileName),
+		port:           port,
+	}, nil
+}
+
+// Name returns the name of the network that the transport carries connections over
+func (t *Transport) Name() string {
+	return "i2p"
+}
+
+// CanDial returns whether address is the address of an I2P peer
+func (t *Transport) CanDial(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	return err == nil && IsI2PHost(host)
+}
+
+// DialTimeout returns how long connecting to an I2P peer may take
+func (t *Transport) DialTimeout(string) time.Duration {
+	return i2pDialTimeout
+}
+
+// Dial connects to the I2P peer at address, through a new stream of the session
+func (t *Transport) Dial(ctx context.Context, address string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	remoteAddress, err := t.addressOfHost(host)
+	if err != nil {
+		return nil, err
+	}
+	s, err := t.getSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dialSAM(ctx, t.samAddress)
+	if err != nil {
+		return nil, err
+	}
+	arguments, err := conn.command(ctx, "NAMING REPLY", "NAMING LOOKUP NAME=%s", host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	arguments, err = conn.command(ctx, "STREAM STATUS", "STREAM CONNECT ID=%s DESTINATION=%s SILENT=false",
+		s.id, arguments["VALUE"])
+	if err != nil {
+		conn.Close()
+		if arguments["RESULT"] == samResultInvalidID {
+			t.closeSession(s)
+		}
+		return nil, err
+	}
+
+	localAddress, err := t.addressOfHost(s.host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &connection{samConn: conn, localAddress: localAddress, remoteAddress: remoteAddress}, nil
+}
+
+// Listen returns a listener for the connections of I2P peers to the destination of the node
+func (t *Transport) Listen() (net.Listener, error) {
+	s, err := t.getSession(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	address, err := t.addressOfHost(s.host)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Accepting I2P connections at %s", net.JoinHostPort(s.host, strconv.Itoa(t.port)))
+	return newListener(t, address), nil
+}
+
+// Close closes the session, which removes the destination of the node from the I2P network
+func (t *Transport) Close() error {
+	t.sessionLock.Lock()
+	defer t.sessionLock.Unlock()
+
+	if t.session == nil {
+		return nil
+	}
+	err := t.session.control.Close()
+	t.session = nil
+	return errors.WithStack(err)
+}
+
+// getSession returns the current session, creating it if there's none, or if the
+// previous one was closed by the SAM bridge
+func (t *Transport) getSession(ctx context.Context) (*session, error) {
+	t.sessionLock.Lock()
+	defer t.sessionLock.Unlock()
+
+	if t.session != nil {
+		return t.session, nil
+	}
+
+	privateKey, err := t.loadPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	idBytes := make([]byte, sessionIDLength)
+	_, err = rand.Read(idBytes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	id := hex.EncodeToString(idBytes)
+
+	control, err := dialSAM(ctx, t.samAddress)
+	if err != nil {
+		return nil, err
+	}
+	// A new destination is generated by the SAM bridge if there's no private key yet
+	destination, options := privateKey, ""
+	if privateKey == "" {
+		destination, options = "TRANSIENT", fmt.Sprintf(" SIGNATURE_TYPE=%d", signatureTypeEdDSA)
+	}
+	arguments, err := control.command(ctx, "SESSION STATUS", "SESSION CREATE STYLE=STREAM ID=%s DESTINATION=%s%s",
+		id, destination, options)
+	if err != nil {
+		control.Close()
+		return nil, err
+	}
+	privateKey = arguments["DESTINATION"]
+	host, err := hostOfPrivateKey(privateKey)
+	if err != nil {
+		control.Close()
+		return nil, err
+	}
+	err = t.savePrivateKey(privateKey)
+	if err != nil {
+		control.Close()
+		return nil, err
+	}
+
+	t.session = &session{id: id, control: control, host: host}
+	log.Infof("Created I2P session %s for %s", id, host)
+	return t.session, nil
+}
+
+// closeSession closes s if it's still the current session, so that the next
+// connection creates a new one
+func (t *Transport) closeSession(s *session) {
+	t.sessionLock.Lock()
+	defer t.sessionLock.Unlock()
+
+	if t.session != s {
+		return
+	}
+	log.Warnf("The I2P SAM bridge doesn't know session %s anymore", s.id)
+	s.control.Close()
+	t.session = nil
+}
+
+// addressOfHost returns the GarliCat TCP address of the given I2P host, which stands
+// for the peer wherever an IP is expected
+func (t *Transport) addressOfHost(host string) (*net.TCPAddr, error) {
+	ip, err := GarliCatIP(host)
+	if err != nil {
+		return nil, err
+	}
+	return &net.TCPAddr{IP: ip, Port: t.port}, nil
+}
+
+func hostOfPrivateKey(privateKey string) (string, error) {
+	decoded, err := i2pBase64Encoding.DecodeString(privateKey)
+	if err != nil {
+		return "", errors.Wrapf(err, "the I2P SAM bridge replied with a malformed private key")
+	}
+	destination, err := publicDestination(decoded)
+	if err != nil {
+		return "", err
+	}
+	return hostOfDestination(destination), nil
+}
+
+func (t *Transport) loadPrivateKey() (string, error) {
+	privateKey, err := os.ReadFile(t.privateKeyFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "could not read the I2P private key")
+	}
+	return strings.TrimSpace(string(privateKey)), nil
+}
+
+func (t *Transport) savePrivateKey(privateKey string) error {
+	err := os.WriteFile(t.privateKeyFile, []byte(privateKey), 0600)
+	if err != nil {
+		return errors.Wrapf(err, "could not write the I2P private key")
+	}
+	return nil
+}
+
+// connection is a stream to an I2P peer, whose addresses are the GarliCat addresses
+// of the destinations at its ends
+type connection struct {
+	*samConn
+	localAddress  net.Addr
+	remoteAddress net.Addr
+}
+
+func (c *connection) LocalAddr() net.Addr {
+	return c.localAddress
+}
+
+func (c *connection) RemoteAddr() net.Addr {
+	return c.remoteAddress
+}
+
+var _ transport.Transport = (*Transport)(nil)
@@ -0,0 +1,189 @@
+package i2p
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/config"
+)
+
+// fakeSAMBridge answers the SAM commands of a Transport the way an I2P router does,
+// with every stream being connected to an echo of its data
+type fakeSAMBridge struct {
+	listener           net.Listener
+	privateKey         string
+	peerDestination    string
+	peerHost           string
+	createdSessionWith []string
+}
+
+func newFakeSAMBridge(t *testing.T) *fakeSAMBridge {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %+v", err)
+	}
+	privateKey := make([]byte, destinationKeysLength+certificateHeaderLength+64)
+	privateKey[0] = 1
+	peerDestination := make([]byte, destinationKeysLength+certificateHeaderLength)
+	peerDestination[0] = 2
+
+	bridge := &fakeSAMBridge{
+		listener:        listener,
+		privateKey:      i2pBase64Encoding.EncodeToString(privateKey),
+		peerDestination: i2pBase64Encoding.EncodeToString(peerDestination),
+		peerHost:        hostOfDestination(peerDestination),
+	}
+	go bridge.serve()
+	return bridge
+}
+
+func (b *fakeSAMBridge) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(conn)
+	}
+}
+
+func (b *fakeSAMBridge) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		reply, arguments := parseReply(strings.TrimSpace(line))
+		switch reply {
+		case "HELLO VERSION":
+			fmt.Fprintf(conn, "HELLO REPLY RESULT=OK VERSION=%s\n", samVersion)
+		case "SESSION CREATE":
+			b.createdSessionWith = append(b.createdSessionWith, arguments["DESTINATION"])
+			fmt.Fprintf(conn, "SESSION STATUS RESULT=OK DESTINATION=%s\n", b.privateKey)
+		case "NAMING LOOKUP":
+			fmt.Fprintf(conn, "NAMING REPLY RESULT=OK NAME=%s VALUE=%s\n", arguments["NAME"], b.peerDestination)
+		case "STREAM CONNECT":
+			if arguments["DESTINATION"] != b.peerDestination {
+				fmt.Fprintf(conn, "STREAM STATUS RESULT=CANT_REACH_PEER MESSAGE=\"unknown destination\"\n")
+				continue
+			}
+			fmt.Fprintf(conn, "STREAM STATUS RESULT=OK\n")
+			io.Copy(conn, reader)
+			return
+		case "STREAM ACCEPT":
+			fmt.Fprintf(conn, "STREAM STATUS RESULT=OK\n")
+			fmt.Fprintf(conn, "%s FROM_PORT=0 TO_PORT=0\nhello", b.peerDestination)
+			io.Copy(io.Discard, reader)
+			return
+		default:
+			fmt.Fprintf(conn, "%s RESULT=I2P_ERROR\n", reply)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	bridge := newFakeSAMBridge(t)
+	defer bridge.listener.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.I2PSAM = bridge.listener.Addr().String()
+	cfg.AppDir = t.TempDir()
+	transport, err := NewTransport(cfg)
+	if err != nil {
+		t.Fatalf("NewTransport: %+v", err)
+	}
+	defer transport.Close()
+
+	peerAddress := net.JoinHostPort(bridge.peerHost, cfg.NetParams().DefaultPort)
+	if !transport.CanDial(peerAddress) {
+		t.Fatalf("CanDial: expected the transport to be able to dial %s", peerAddress)
+	}
+	if transport.CanDial(net.JoinHostPort("127.0.0.1", cfg.NetParams().DefaultPort)) {
+		t.Fatalf("CanDial: expected the transport to be unable to dial an IP")
+	}
+
+	conn, err := transport.Dial(context.Background(), peerAddress)
+	if err != nil {
+		t.Fatalf("Dial: %+v", err)
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte("ping"))
+	if err != nil {
+		t.Fatalf("Write: %+v", err)
+	}
+	echoed := make([]byte, len("ping"))
+	_, err = io.ReadFull(conn, echoed)
+	if err != nil {
+		t.Fatalf("ReadFull: %+v", err)
+	}
+	if string(echoed) != "ping" {
+		t.Fatalf("Dial: expected the stream to echo ping but got %s", echoed)
+	}
+	expectedRemoteIP, err := GarliCatIP(bridge.peerHost)
+	if err != nil {
+		t.Fatalf("GarliCatIP: %+v", err)
+	}
+	if remoteIP := conn.RemoteAddr().(*net.TCPAddr).IP; !remoteIP.Equal(expectedRemoteIP) {
+		t.Fatalf("Dial: expected the remote address to be %s but got %s", expectedRemoteIP, remoteIP)
+	}
+
+	listener, err := transport.Listen()
+	if err != nil {
+		t.Fatalf("Listen: %+v", err)
+	}
+	defer listener.Close()
+	accepted, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("Accept: %+v", err)
+	}
+	defer accepted.Close()
+	greeting := make([]byte, len("hello"))
+	_, err = io.ReadFull(accepted, greeting)
+	if err != nil {
+		t.Fatalf("ReadFull: %+v", err)
+	}
+	if string(greeting) != "hello" {
+		t.Fatalf("Accept: expected the stream to start with hello but got %s", greeting)
+	}
+	if remoteIP := accepted.RemoteAddr().(*net.TCPAddr).IP; !remoteIP.Equal(expectedRemoteIP) {
+		t.Fatalf("Accept: expected the remote address to be %s but got %s", expectedRemoteIP, remoteIP)
+	}
+
+	// The private key is kept, so that a new session keeps the same destination
+	err = transport.Close()
+	if err != nil {
+		t.Fatalf("Close: %+v", err)
+	}
+	_, err = transport.Listen()
+	if err != nil {
+		t.Fatalf("Listen: %+v", err)
+	}
+	if len(bridge.createdSessionWith) != 2 || bridge.createdSessionWith[0] != "TRANSIENT" ||
+		bridge.createdSessionWith[1] != bridge.privateKey {
+		t.Fatalf("Expected a TRANSIENT session followed by a session with the saved private key, but got %v",
+			bridge.createdSessionWith)
+	}
+}
+
+func TestParseReply(t *testing.T) {
+	reply, arguments := parseReply(`STREAM STATUS RESULT=I2P_ERROR MESSAGE="Tunnels \"not\" built" DESTINATION=abc==`)
+	if reply != "STREAM STATUS" {
+		t.Fatalf("parseReply: expected reply STREAM STATUS but got %s", reply)
+	}
+	if arguments["RESULT"] != "I2P_ERROR" {
+		t.Fatalf("parseReply: unexpected RESULT %s", arguments["RESULT"])
+	}
+	if arguments["MESSAGE"] != `Tunnels "not" built` {
+		t.Fatalf("parseReply: unexpected MESSAGE %s", arguments["MESSAGE"])
+	}
+	if arguments["DESTINATION"] != "abc==" {
+		t.Fatalf("parseReply: unexpected DESTINATION %s", arguments["DESTINATION"])
+	}
+}
@@ -0,0 +1,49 @@
+package nat
+
+import (
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// device is a router that maps ports with some Method
+type device interface {
+	// name is the name of the protocol of the device, for logging
+	name() string
+
+	// externalIP returns the address of the device on the internet
+	externalIP() (net.IP, error)
+
+	// addPortMapping forwards the given TCP port of the device to the same port of
+	// this host for the given lifetime, and returns the external port and the
+	// lifetime that were granted. A zero lifetime means the mapping is permanent.
+	addPortMapping(port uint16, lifetime time.Duration) (externalPort uint16, grantedLifetime time.Duration, err error)
+
+	// deletePortMapping removes a mapping made by addPortMapping
+	deletePortMapping(port uint16) error
+}
+
+// discoverDevice returns the device in front of this host that supports method.
+// With MethodAny, UPnP is tried first, since NAT-PMP is mostly found on routers
+// that support both.
+func discoverDevice(method Method) (device, error) {
+	switch method {
+	case MethodUPnP:
+		return discoverUPnPDevice()
+	case MethodNATPMP:
+		return discoverNATPMPDevice()
+	case MethodAny:
+		upnpDevice, upnpErr := discoverUPnPDevice()
+		if upnpErr == nil {
+			return upnpDevice, nil
+		}
+		natPMPDevice, natPMPErr := discoverNATPMPDevice()
+		if natPMPErr == nil {
+			return natPMPDevice, nil
+		}
+		return nil, errors.Errorf("no UPnP device (%s) and no NAT-PMP device (%s)", upnpErr, natPMPErr)
+	default:
+		return nil, errors.Errorf("no device for method %s", method)
+	}
+}
@@ -0,0 +1,73 @@
+package nat
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// defaultGateway returns the IPv4 address of the default gateway. On Linux it's
+// read from the routing table. Elsewhere it's guessed to be the first address of
+// the /24 network of the interface that routes to the internet, which is how most
+// home routers are set up.
+func defaultGateway() (net.IP, error) {
+	if runtime.GOOS == "linux" {
+		return defaultGatewayFromRoutingTable("/proc/net/route")
+	}
+	return guessDefaultGateway()
+}
+
+func defaultGatewayFromRoutingTable(path string) (net.IP, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// The first line holds the names of the columns
+	scanner.Scan()
+	for scanner.Scan() {
+		// Iface, Destination, Gateway, Flags, ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+		gateway, err := strconv.ParseUint(fields[2], 16, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "malformed gateway in %s", path)
+		}
+		// The address is in host byte order, which is little endian on the
+		// architectures kaspad runs on
+		return net.IPv4(byte(gateway), byte(gateway>>8), byte(gateway>>16), byte(gateway>>24)), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return nil, errors.Errorf("no default route in %s", path)
+}
+
+func guessDefaultGateway() (net.IP, error) {
+	localIP, err := outboundIPv4()
+	if err != nil {
+		return nil, err
+	}
+	return net.IPv4(localIP[0], localIP[1], localIP[2], 1), nil
+}
+
+// outboundIPv4 returns the local IPv4 address that connections to the internet
+// are made from. Connecting a UDP socket sends nothing, so no actual traffic is
+// made.
+func outboundIPv4() (net.IP, error) {
+	connection, err := net.Dial("udp4", "198.51.100.1:9")
+	if err != nil {
+		return nil, errors.Wrap(err, "no route to the internet")
+	}
+	defer connection.Close()
+	return connection.LocalAddr().(*net.UDPAddr).IP.To4(), nil
+}
@@ -0,0 +1,40 @@
+package nat
+
+// Method is a protocol used to map a port on the router in front of the node
+type Method int
+
+const (
+	// MethodNone disables port mapping
+	MethodNone Method = iota
+
+	// MethodUPnP maps the port with the WANIPConnection service of UPnP
+	MethodUPnP
+
+	// MethodNATPMP maps the port with NAT-PMP (RFC 6886)
+	MethodNATPMP
+
+	// MethodAny maps the port with UPnP if the router supports it, and with
+	// NAT-PMP otherwise
+	MethodAny
+)
+
+var methodStrings = map[Method]string{
+	MethodNone:   "none",
+	MethodUPnP:   "upnp",
+	MethodNATPMP: "natpmp",
+	MethodAny:    "any",
+}
+
+func (m Method) String() string {
+	return methodStrings[m]
+}
+
+// MethodFromString returns the Method of the given name, or false if there's none
+func MethodFromString(methodString string) (Method, bool) {
+	for method, name := range methodStrings {
+		if name == methodString {
+			return method, true
+		}
+	}
+	return MethodNone, false
+}
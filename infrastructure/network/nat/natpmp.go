@@ -0,0 +1,146 @@
+package nat
+
+import (
+	"encoding/binary"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	natPMPPort = 5351
+
+	natPMPOpcodeExternalAddress = 0
+	natPMPOpcodeMapTCP          = 2
+
+	// natPMPResponseOpcodeOffset is added to the opcode of a request to get the
+	// opcode of its response
+	natPMPResponseOpcodeOffset = 128
+
+	// RFC 6886 starts waiting for a response for 250ms and doubles that after
+	// every attempt. It keeps trying for over a minute, which is cut short here
+	// so that a router without NAT-PMP is given up on within a few seconds.
+	natPMPInitialTimeout = 250 * time.Millisecond
+	natPMPAttempts       = 4
+)
+
+var natPMPResultCodes = map[uint16]string{
+	1: "unsupported version",
+	2: "not authorized or refused",
+	3: "network failure",
+	4: "out of resources",
+	5: "unsupported opcode",
+}
+
+// natPMPDevice is a router that maps ports with NAT-PMP (RFC 6886)
+type natPMPDevice struct {
+	gateway *net.UDPAddr
+}
+
+// discoverNATPMPDevice returns the default gateway as a NAT-PMP device, after
+// checking that it answers NAT-PMP requests
+func discoverNATPMPDevice() (device, error) {
+	gatewayIP, err := defaultGateway()
+	if err != nil {
+		return nil, err
+	}
+	device := newNATPMPDevice(&net.UDPAddr{IP: gatewayIP, Port: natPMPPort})
+	_, err = device.externalIP()
+	if err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func newNATPMPDevice(gateway *net.UDPAddr) *natPMPDevice {
+	return &natPMPDevice{gateway: gateway}
+}
+
+func (d *natPMPDevice) name() string {
+	return "NAT-PMP"
+}
+
+func (d *natPMPDevice) externalIP() (net.IP, error) {
+	response, err := d.request([]byte{0, natPMPOpcodeExternalAddress}, 12)
+	if err != nil {
+		return nil, err
+	}
+	return net.IPv4(response[8], response[9], response[10], response[11]), nil
+}
+
+func (d *natPMPDevice) addPortMapping(port uint16, lifetime time.Duration) (uint16, time.Duration, error) {
+	return d.mapTCP(port, uint32(lifetime/time.Second))
+}
+
+// deletePortMapping asks for a mapping with no lifetime, which is how NAT-PMP
+// removes mappings
+func (d *natPMPDevice) deletePortMapping(port uint16) error {
+	_, _, err := d.mapTCP(port, 0)
+	return err
+}
+
+func (d *natPMPDevice) mapTCP(port uint16, lifetimeSeconds uint32) (uint16, time.Duration, error) {
+	request := make([]byte, 12)
+	request[1] = natPMPOpcodeMapTCP
+	binary.BigEndian.PutUint16(request[4:6], port)
+	binary.BigEndian.PutUint16(request[6:8], port)
+	binary.BigEndian.PutUint32(request[8:12], lifetimeSeconds)
+
+	response, err := d.request(request, 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	externalPort := binary.BigEndian.Uint16(response[10:12])
+	grantedLifetime := time.Duration(binary.BigEndian.Uint32(response[12:16])) * time.Second
+	return externalPort, grantedLifetime, nil
+}
+
+// request sends request to the gateway until it answers or the attempts run out,
+// and returns the answer if it's successful and at least responseLength long
+func (d *natPMPDevice) request(request []byte, responseLength int) ([]byte, error) {
+	connection, err := net.DialUDP("udp4", nil, d.gateway)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer connection.Close()
+
+	response := make([]byte, 16)
+	timeout := natPMPInitialTimeout
+	for attempt := 0; attempt < natPMPAttempts; attempt++ {
+		_, err := connection.Write(request)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		err = connection.SetReadDeadline(time.Now().Add(timeout))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		n, err := connection.Read(response)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				timeout *= 2
+				continue
+			}
+			return nil, errors.WithStack(err)
+		}
+
+		if n < 4 || response[0] != 0 || response[1] != request[1]+natPMPResponseOpcodeOffset {
+			return nil, errors.Errorf("unexpected NAT-PMP response from %s", d.gateway)
+		}
+		resultCode := binary.BigEndian.Uint16(response[2:4])
+		if resultCode != 0 {
+			reason, ok := natPMPResultCodes[resultCode]
+			if !ok {
+				reason = "unknown error"
+			}
+			return nil, errors.Errorf("NAT-PMP request to %s failed with result code %d (%s)",
+				d.gateway, resultCode, reason)
+		}
+		if n < responseLength {
+			return nil, errors.Errorf("NAT-PMP response from %s is too short: %d bytes", d.gateway, n)
+		}
+		return response[:n], nil
+	}
+	return nil, errors.Errorf("no NAT-PMP response from %s", d.gateway)
+}
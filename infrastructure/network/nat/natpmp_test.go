@@ -0,0 +1,155 @@
+package nat
+
+import (
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeNATPMPGateway answers NAT-PMP requests like a router whose external address
+// is externalIP, and that maps every port to externalPortOffset above it
+type fakeNATPMPGateway struct {
+	connection         *net.UDPConn
+	externalIP         net.IP
+	externalPortOffset uint16
+	resultCode         uint32
+	requests           chan []byte
+}
+
+func newFakeNATPMPGateway(t *testing.T) *fakeNATPMPGateway {
+	connection, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP: %s", err)
+	}
+	gateway := &fakeNATPMPGateway{
+		connection:         connection,
+		externalIP:         net.IPv4(203, 0, 113, 7).To4(),
+		externalPortOffset: 1000,
+		requests:           make(chan []byte, 10),
+	}
+	go gateway.serve()
+	return gateway
+}
+
+func (g *fakeNATPMPGateway) serve() {
+	buffer := make([]byte, 16)
+	for {
+		n, address, err := g.connection.ReadFromUDP(buffer)
+		if err != nil {
+			return
+		}
+		request := append([]byte{}, buffer[:n]...)
+		g.requests <- request
+
+		var response []byte
+		switch request[1] {
+		case natPMPOpcodeExternalAddress:
+			response = make([]byte, 12)
+			copy(response[8:], g.externalIP)
+		case natPMPOpcodeMapTCP:
+			response = make([]byte, 16)
+			internalPort := binary.BigEndian.Uint16(request[4:6])
+			binary.BigEndian.PutUint16(response[8:10], internalPort)
+			binary.BigEndian.PutUint16(response[10:12], internalPort+g.externalPortOffset)
+			copy(response[12:16], request[8:12])
+		}
+		response[1] = request[1] + natPMPResponseOpcodeOffset
+		binary.BigEndian.PutUint16(response[2:4], uint16(atomic.LoadUint32(&g.resultCode)))
+		_, _ = g.connection.WriteToUDP(response, address)
+	}
+}
+
+func (g *fakeNATPMPGateway) close() {
+	g.connection.Close()
+}
+
+func TestNATPMPDevice(t *testing.T) {
+	gateway := newFakeNATPMPGateway(t)
+	defer gateway.close()
+	device := newNATPMPDevice(gateway.connection.LocalAddr().(*net.UDPAddr))
+
+	externalIP, err := device.externalIP()
+	if err != nil {
+		t.Fatalf("externalIP: %s", err)
+	}
+	if !externalIP.Equal(gateway.externalIP) {
+		t.Fatalf("Unexpected external IP. Want: %s, got: %s", gateway.externalIP, externalIP)
+	}
+	<-gateway.requests
+
+	externalPort, grantedLifetime, err := device.addPortMapping(16111, time.Hour)
+	if err != nil {
+		t.Fatalf("addPortMapping: %s", err)
+	}
+	if externalPort != 17111 {
+		t.Fatalf("Unexpected external port. Want: 17111, got: %d", externalPort)
+	}
+	if grantedLifetime != time.Hour {
+		t.Fatalf("Unexpected lifetime. Want: %s, got: %s", time.Hour, grantedLifetime)
+	}
+	<-gateway.requests
+
+	// A mapping is deleted by asking for it with no lifetime
+	err = device.deletePortMapping(16111)
+	if err != nil {
+		t.Fatalf("deletePortMapping: %s", err)
+	}
+	request := <-gateway.requests
+	if lifetime := binary.BigEndian.Uint32(request[8:12]); lifetime != 0 {
+		t.Fatalf("Unexpected lifetime in the delete request. Want: 0, got: %d", lifetime)
+	}
+
+	atomic.StoreUint32(&gateway.resultCode, 2)
+	_, _, err = device.addPortMapping(16111, time.Hour)
+	if err == nil {
+		t.Fatalf("Expected a refused mapping to fail")
+	}
+}
+
+func TestNATPMPDeviceNoResponse(t *testing.T) {
+	// Nothing listens on the port of a closed connection
+	connection, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP: %s", err)
+	}
+	address := connection.LocalAddr().(*net.UDPAddr)
+	connection.Close()
+
+	device := newNATPMPDevice(address)
+	_, err = device.externalIP()
+	if err == nil {
+		t.Fatalf("Expected a gateway that doesn't answer to fail")
+	}
+}
+
+func TestDefaultGatewayFromRoutingTable(t *testing.T) {
+	routingTable := "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n" +
+		"eth0\t0000A8C0\t00000000\t0001\t0\t0\t0\t00FFFFFF\t0\t0\t0\n" +
+		"eth0\t00000000\t0100A8C0\t0003\t0\t0\t0\t00000000\t0\t0\t0\n"
+	path := filepath.Join(t.TempDir(), "route")
+	err := os.WriteFile(path, []byte(routingTable), 0600)
+	if err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	gateway, err := defaultGatewayFromRoutingTable(path)
+	if err != nil {
+		t.Fatalf("defaultGatewayFromRoutingTable: %s", err)
+	}
+	if !gateway.Equal(net.IPv4(192, 168, 0, 1)) {
+		t.Fatalf("Unexpected gateway. Want: 192.168.0.1, got: %s", gateway)
+	}
+
+	err = os.WriteFile(path, []byte(routingTable[:len(routingTable)/2]), 0600)
+	if err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+	_, err = defaultGatewayFromRoutingTable(path)
+	if err == nil {
+		t.Fatalf("Expected a routing table without a default route to fail")
+	}
+}
@@ -0,0 +1,145 @@
+package nat
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// mappingLifetime is the lifetime asked for the mapping of the port. The
+	// mapping is renewed at half its lifetime, so that it doesn't expire while
+	// the node runs, and expires soon after the node stops without removing it.
+	mappingLifetime = 20 * time.Minute
+
+	// retryInterval is how long to wait before looking for a device again
+	// once none is found or the mapping fails
+	retryInterval = 5 * time.Minute
+)
+
+// OnExternalAddressChangedHandler is a handler function that's triggered when
+// the external address of the node changes. previous is nil when the port is
+// first mapped, and current is nil when the mapping is lost.
+type OnExternalAddressChangedHandler func(previous, current *net.TCPAddr)
+
+// PortMapper maps the P2P listen port of the node on the router in front of it,
+// and renews the mapping until it's stopped. The external address the port is
+// mapped to is reported through onExternalAddressChanged.
+type PortMapper struct {
+	method                   Method
+	port                     uint16
+	onExternalAddressChanged OnExternalAddressChangedHandler
+
+	device          device
+	externalAddress *net.TCPAddr
+
+	quit      chan struct{}
+	waitGroup sync.WaitGroup
+}
+
+// NewPortMapper returns a PortMapper that maps the given port with method
+func NewPortMapper(method Method, port uint16, onExternalAddressChanged OnExternalAddressChangedHandler) *PortMapper {
+	return &PortMapper{
+		method:                   method,
+		port:                     port,
+		onExternalAddressChanged: onExternalAddressChanged,
+		quit:                     make(chan struct{}),
+	}
+}
+
+// Start starts looking for a device to map the port on in the background
+func (m *PortMapper) Start() {
+	m.waitGroup.Add(1)
+	spawn("PortMapper.run", m.run)
+}
+
+// Stop stops renewing the mapping and removes it from the device
+func (m *PortMapper) Stop() {
+	close(m.quit)
+	m.waitGroup.Wait()
+}
+
+func (m *PortMapper) run() {
+	defer m.waitGroup.Done()
+
+	for {
+		wait := m.refresh()
+		select {
+		case <-m.quit:
+			m.unmap()
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// refresh maps the port, finding a device first if there's none, and returns how
+// long to wait before refreshing again
+func (m *PortMapper) refresh() time.Duration {
+	if m.device == nil {
+		device, err := discoverDevice(m.method)
+		if err != nil {
+			log.Warnf("Couldn't find a device to map port %d with %s: %s", m.port, m.method, err)
+			return retryInterval
+		}
+		log.Infof("Found a %s device to map port %d", device.name(), m.port)
+		m.device = device
+	}
+
+	externalPort, grantedLifetime, err := m.device.addPortMapping(m.port, mappingLifetime)
+	if err != nil {
+		log.Warnf("Couldn't map port %d with %s: %s", m.port, m.device.name(), err)
+		m.lose()
+		return retryInterval
+	}
+	externalIP, err := m.device.externalIP()
+	if err != nil {
+		log.Warnf("Couldn't get the external address from %s: %s", m.device.name(), err)
+		m.lose()
+		return retryInterval
+	}
+	m.setExternalAddress(&net.TCPAddr{IP: externalIP, Port: int(externalPort)})
+
+	// Permanent mappings are still refreshed, so that a change of the external
+	// address is noticed
+	if grantedLifetime == 0 || grantedLifetime > mappingLifetime {
+		grantedLifetime = mappingLifetime
+	}
+	return grantedLifetime / 2
+}
+
+// lose forgets the device, so that it's looked for again, and the external address
+func (m *PortMapper) lose() {
+	m.device = nil
+	m.setExternalAddress(nil)
+}
+
+func (m *PortMapper) setExternalAddress(externalAddress *net.TCPAddr) {
+	previous := m.externalAddress
+	if previous == nil && externalAddress == nil {
+		return
+	}
+	if previous != nil && externalAddress != nil &&
+		previous.IP.Equal(externalAddress.IP) && previous.Port == externalAddress.Port {
+		return
+	}
+	m.externalAddress = externalAddress
+	if externalAddress != nil {
+		log.Infof("Port %d is mapped to the external address %s", m.port, externalAddress)
+	} else {
+		log.Infof("Port %d is no longer mapped to the external address %s", m.port, previous)
+	}
+	m.onExternalAddressChanged(previous, externalAddress)
+}
+
+func (m *PortMapper) unmap() {
+	if m.device == nil {
+		return
+	}
+	err := m.device.deletePortMapping(m.port)
+	if err != nil {
+		log.Warnf("Couldn't remove the mapping of port %d from %s: %s", m.port, m.device.name(), err)
+		return
+	}
+	log.Infof("Removed the mapping of port %d from %s", m.port, m.device.name())
+}
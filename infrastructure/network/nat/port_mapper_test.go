@@ -0,0 +1,89 @@
+package nat
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type fakeDevice struct {
+	ip           net.IP
+	err          error
+	deletedPorts []uint16
+}
+
+func (d *fakeDevice) name() string {
+	return "fake"
+}
+
+func (d *fakeDevice) externalIP() (net.IP, error) {
+	return d.ip, d.err
+}
+
+func (d *fakeDevice) addPortMapping(port uint16, lifetime time.Duration) (uint16, time.Duration, error) {
+	return port + 1, lifetime / 2, d.err
+}
+
+func (d *fakeDevice) deletePortMapping(port uint16) error {
+	d.deletedPorts = append(d.deletedPorts, port)
+	return nil
+}
+
+func TestPortMapperRefresh(t *testing.T) {
+	var changes [][2]*net.TCPAddr
+	mapper := NewPortMapper(MethodAny, 16111, func(previous, current *net.TCPAddr) {
+		changes = append(changes, [2]*net.TCPAddr{previous, current})
+	})
+	device := &fakeDevice{ip: net.IPv4(203, 0, 113, 7)}
+	mapper.device = device
+
+	// The mapping is renewed at half the lifetime the device granted
+	wait := mapper.refresh()
+	if wait != mappingLifetime/4 {
+		t.Fatalf("Unexpected wait. Want: %s, got: %s", mappingLifetime/4, wait)
+	}
+	if len(changes) != 1 || changes[0][0] != nil || changes[0][1].String() != "203.0.113.7:16112" {
+		t.Fatalf("Unexpected changes of the external address: %v", changes)
+	}
+
+	// Renewing a mapping that didn't change reports nothing
+	mapper.refresh()
+	if len(changes) != 1 {
+		t.Fatalf("Unexpected changes of the external address: %v", changes)
+	}
+
+	// A new external IP replaces the previous one
+	device.ip = net.IPv4(203, 0, 113, 8)
+	mapper.refresh()
+	if len(changes) != 2 || changes[1][0].String() != "203.0.113.7:16112" ||
+		changes[1][1].String() != "203.0.113.8:16112" {
+		t.Fatalf("Unexpected changes of the external address: %v", changes)
+	}
+
+	// A failed mapping loses the external address and the device
+	device.err = errors.New("router rebooted")
+	wait = mapper.refresh()
+	if wait != retryInterval {
+		t.Fatalf("Unexpected wait. Want: %s, got: %s", retryInterval, wait)
+	}
+	if len(changes) != 3 || changes[2][0].String() != "203.0.113.8:16112" || changes[2][1] != nil {
+		t.Fatalf("Unexpected changes of the external address: %v", changes)
+	}
+	if mapper.device != nil {
+		t.Fatalf("Expected the device to be forgotten after a failed mapping")
+	}
+}
+
+func TestPortMapperUnmap(t *testing.T) {
+	mapper := NewPortMapper(MethodAny, 16111, func(previous, current *net.TCPAddr) {})
+	device := &fakeDevice{ip: net.IPv4(203, 0, 113, 7)}
+	mapper.device = device
+	mapper.refresh()
+
+	mapper.unmap()
+	if len(device.deletedPorts) != 1 || device.deletedPorts[0] != 16111 {
+		t.Fatalf("Unexpected deleted ports: %v", device.deletedPorts)
+	}
+}
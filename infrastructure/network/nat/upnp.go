@@ -0,0 +1,342 @@
+package nat
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ssdpAddress       = "239.255.255.250:1900"
+	ssdpSearchTarget  = "urn:schemas-upnp-org:device:InternetGatewayDevice:1"
+	ssdpSearchTimeout = 3 * time.Second
+	ssdpSearchCount   = 3
+
+	upnpRequestTimeout = 10 * time.Second
+
+	upnpPortMappingDescription = "kaspad"
+
+	// upnpErrorOnlyPermanentLeasesSupported is returned by routers that don't
+	// support mappings with a lease duration
+	upnpErrorOnlyPermanentLeasesSupported = 725
+)
+
+// upnpServiceTypes are the services that map ports, from the most preferred
+var upnpServiceTypes = []string{
+	"urn:schemas-upnp-org:service:WANIPConnection:2",
+	"urn:schemas-upnp-org:service:WANIPConnection:1",
+	"urn:schemas-upnp-org:service:WANPPPConnection:1",
+}
+
+// upnpDevice is a router that maps ports with the WANIPConnection or WANPPPConnection
+// service of UPnP
+type upnpDevice struct {
+	controlURL  string
+	serviceType string
+	client      *http.Client
+
+	// localIP is the address of this host that the router forwards the port to
+	localIP net.IP
+}
+
+// discoverUPnPDevice searches the local network for an internet gateway device
+// with SSDP, and returns the first one that maps ports
+func discoverUPnPDevice() (device, error) {
+	locations, err := ssdpSearch()
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, location := range locations {
+		device, err := newUPnPDevice(location)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return device, nil
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, errors.New("no internet gateway device answered the SSDP search")
+}
+
+// ssdpSearch multicasts an SSDP search for internet gateway devices, and returns
+// the locations of the descriptions of those that answered
+func ssdpSearch() ([]string, error) {
+	connection, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer connection.Close()
+
+	multicastAddress, err := net.ResolveUDPAddr("udp4", ssdpAddress)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	request := strings.Join([]string{
+		"M-SEARCH * HTTP/1.1",
+		"HOST: " + ssdpAddress,
+		"ST: " + ssdpSearchTarget,
+		`MAN: "ssdp:discover"`,
+		"MX: 2",
+		"", "",
+	}, "\r\n")
+	for i := 0; i < ssdpSearchCount; i++ {
+		_, err = connection.WriteTo([]byte(request), multicastAddress)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	err = connection.SetReadDeadline(time.Now().Add(ssdpSearchTimeout))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var locations []string
+	seenLocations := make(map[string]struct{})
+	buffer := make([]byte, 2048)
+	for {
+		n, _, err := connection.ReadFrom(buffer)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				break
+			}
+			return nil, errors.WithStack(err)
+		}
+		response, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(buffer[:n])), nil)
+		if err != nil {
+			continue
+		}
+		response.Body.Close()
+		location := response.Header.Get("Location")
+		if location == "" {
+			continue
+		}
+		if _, ok := seenLocations[location]; ok {
+			continue
+		}
+		seenLocations[location] = struct{}{}
+		locations = append(locations, location)
+	}
+	if len(locations) == 0 {
+		return nil, errors.New("no internet gateway device answered the SSDP search")
+	}
+	return locations, nil
+}
+
+type upnpDescription struct {
+	Device upnpDescriptionDevice `xml:"device"`
+}
+
+type upnpDescriptionDevice struct {
+	Devices  []upnpDescriptionDevice  `xml:"deviceList>device"`
+	Services []upnpDescriptionService `xml:"serviceList>service"`
+}
+
+type upnpDescriptionService struct {
+	ServiceType string `xml:"serviceType"`
+	ControlURL  string `xml:"controlURL"`
+}
+
+// findService returns the service of the given type in device or in any of the
+// devices it's made of
+func (device *upnpDescriptionDevice) findService(serviceType string) (*upnpDescriptionService, bool) {
+	for i := range device.Services {
+		if device.Services[i].ServiceType == serviceType {
+			return &device.Services[i], true
+		}
+	}
+	for i := range device.Devices {
+		service, ok := device.Devices[i].findService(serviceType)
+		if ok {
+			return service, true
+		}
+	}
+	return nil, false
+}
+
+// newUPnPDevice reads the description of a device at location, and returns the
+// device if it has a service that maps ports
+func newUPnPDevice(location string) (*upnpDevice, error) {
+	client := &http.Client{Timeout: upnpRequestTimeout}
+	response, err := client.Get(location)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("the description at %s is unavailable: %s", location, response.Status)
+	}
+	description := &upnpDescription{}
+	err = xml.NewDecoder(response.Body).Decode(description)
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed description at %s", location)
+	}
+
+	for _, serviceType := range upnpServiceTypes {
+		service, ok := description.Device.findService(serviceType)
+		if !ok {
+			continue
+		}
+		locationURL, err := url.Parse(location)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		controlURL, err := locationURL.Parse(service.ControlURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "malformed control URL in the description at %s", location)
+		}
+		localIP, err := localIPTowards(locationURL.Host)
+		if err != nil {
+			return nil, err
+		}
+		return &upnpDevice{
+			controlURL:  controlURL.String(),
+			serviceType: service.ServiceType,
+			client:      client,
+			localIP:     localIP,
+		}, nil
+	}
+	return nil, errors.Errorf("the device at %s doesn't map ports", location)
+}
+
+// localIPTowards returns the local address that connections to host are made from
+func localIPTowards(host string) (net.IP, error) {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "80")
+	}
+	connection, err := net.Dial("udp", host)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer connection.Close()
+	return connection.LocalAddr().(*net.UDPAddr).IP, nil
+}
+
+func (d *upnpDevice) name() string {
+	return "UPnP"
+}
+
+func (d *upnpDevice) externalIP() (net.IP, error) {
+	response := &struct {
+		IP string `xml:"Body>GetExternalIPAddressResponse>NewExternalIPAddress"`
+	}{}
+	err := d.soapRequest("GetExternalIPAddress", nil, response)
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(strings.TrimSpace(response.IP))
+	if ip == nil {
+		return nil, errors.Errorf("malformed external IP %q", response.IP)
+	}
+	return ip, nil
+}
+
+// addPortMapping maps the port for the given lifetime, or permanently if the
+// router only supports permanent mappings
+func (d *upnpDevice) addPortMapping(port uint16, lifetime time.Duration) (uint16, time.Duration, error) {
+	err := d.addPortMappingWithLifetime(port, lifetime)
+	var soapErr *upnpError
+	if errors.As(err, &soapErr) && soapErr.Code == upnpErrorOnlyPermanentLeasesSupported {
+		lifetime = 0
+		err = d.addPortMappingWithLifetime(port, lifetime)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return port, lifetime, nil
+}
+
+func (d *upnpDevice) addPortMappingWithLifetime(port uint16, lifetime time.Duration) error {
+	portString := strconv.Itoa(int(port))
+	return d.soapRequest("AddPortMapping", [][2]string{
+		{"NewRemoteHost", ""},
+		{"NewExternalPort", portString},
+		{"NewProtocol", "TCP"},
+		{"NewInternalPort", portString},
+		{"NewInternalClient", d.localIP.String()},
+		{"NewEnabled", "1"},
+		{"NewPortMappingDescription", upnpPortMappingDescription},
+		{"NewLeaseDuration", strconv.Itoa(int(lifetime / time.Second))},
+	}, nil)
+}
+
+func (d *upnpDevice) deletePortMapping(port uint16) error {
+	return d.soapRequest("DeletePortMapping", [][2]string{
+		{"NewRemoteHost", ""},
+		{"NewExternalPort", strconv.Itoa(int(port))},
+		{"NewProtocol", "TCP"},
+	}, nil)
+}
+
+// upnpError is the error a UPnP device answers a failed action with
+type upnpError struct {
+	Code        int    `xml:"Body>Fault>detail>UPnPError>errorCode"`
+	Description string `xml:"Body>Fault>detail>UPnPError>errorDescription"`
+}
+
+func (e *upnpError) Error() string {
+	return fmt.Sprintf("UPnP error %d (%s)", e.Code, e.Description)
+}
+
+// soapRequest calls action of the service of d with the given arguments, and
+// decodes the response into response unless it's nil
+func (d *upnpDevice) soapRequest(action string, arguments [][2]string, response interface{}) error {
+	body := &bytes.Buffer{}
+	body.WriteString(`<?xml version="1.0"?>` +
+		`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" ` +
+		`s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`)
+	fmt.Fprintf(body, `<u:%s xmlns:u="%s">`, action, d.serviceType)
+	for _, argument := range arguments {
+		fmt.Fprintf(body, "<%s>", argument[0])
+		err := xml.EscapeText(body, []byte(argument[1]))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Fprintf(body, "</%s>", argument[0])
+	}
+	fmt.Fprintf(body, `</u:%s></s:Body></s:Envelope>`, action)
+
+	request, err := http.NewRequest(http.MethodPost, d.controlURL, body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	request.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	request.Header.Set("SOAPAction", fmt.Sprintf(`"%s#%s"`, d.serviceType, action))
+	httpResponse, err := d.client.Do(request)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer httpResponse.Body.Close()
+	responseBody, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if httpResponse.StatusCode != http.StatusOK {
+		soapErr := &upnpError{}
+		if xml.Unmarshal(responseBody, soapErr) == nil && soapErr.Code != 0 {
+			return errors.Wrapf(soapErr, "UPnP action %s failed", action)
+		}
+		return errors.Errorf("UPnP action %s failed: %s", action, httpResponse.Status)
+	}
+	if response == nil {
+		return nil
+	}
+	err = xml.Unmarshal(responseBody, response)
+	if err != nil {
+		return errors.Wrapf(err, "malformed response to UPnP action %s", action)
+	}
+	return nil
+}
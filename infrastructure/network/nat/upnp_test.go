@@ -0,0 +1,150 @@
+package nat
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const fakeUPnPDescription = `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <device>
+    <deviceType>urn:schemas-upnp-org:device:InternetGatewayDevice:1</deviceType>
+    <deviceList>
+      <device>
+        <deviceType>urn:schemas-upnp-org:device:WANDevice:1</deviceType>
+        <deviceList>
+          <device>
+            <deviceType>urn:schemas-upnp-org:device:WANConnectionDevice:1</deviceType>
+            <serviceList>
+              <service>
+                <serviceType>urn:schemas-upnp-org:service:WANIPConnection:1</serviceType>
+                <controlURL>/control/wanip</controlURL>
+              </service>
+            </serviceList>
+          </device>
+        </deviceList>
+      </device>
+    </deviceList>
+  </device>
+</root>`
+
+// fakeUPnPDevice answers the SOAP actions of the WANIPConnection service like a
+// router that only supports permanent mappings
+type fakeUPnPDevice struct {
+	lock     sync.Mutex
+	mappings map[string]string
+	actions  []string
+}
+
+func (d *fakeUPnPDevice) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.URL.Path == "/description.xml" {
+		fmt.Fprint(writer, fakeUPnPDescription)
+		return
+	}
+	body, _ := io.ReadAll(request.Body)
+	soapAction := request.Header.Get("SOAPAction")
+	action := soapAction[strings.Index(soapAction, "#")+1 : len(soapAction)-1]
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.actions = append(d.actions, action)
+
+	switch action {
+	case "GetExternalIPAddress":
+		fmt.Fprint(writer, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">`+
+			`<s:Body><u:GetExternalIPAddressResponse xmlns:u="urn:schemas-upnp-org:service:WANIPConnection:1">`+
+			`<NewExternalIPAddress>203.0.113.7</NewExternalIPAddress>`+
+			`</u:GetExternalIPAddressResponse></s:Body></s:Envelope>`)
+	case "AddPortMapping":
+		if !strings.Contains(string(body), "<NewLeaseDuration>0</NewLeaseDuration>") {
+			writer.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(writer, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">`+
+				`<s:Body><s:Fault><faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring><detail>`+
+				`<UPnPError xmlns="urn:schemas-upnp-org:control-1-0"><errorCode>725</errorCode>`+
+				`<errorDescription>OnlyPermanentLeasesSupported</errorDescription></UPnPError>`+
+				`</detail></s:Fault></s:Body></s:Envelope>`)
+			return
+		}
+		d.mappings["16111"] = "mapped"
+		fmt.Fprint(writer, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">`+
+			`<s:Body><u:AddPortMappingResponse xmlns:u="urn:schemas-upnp-org:service:WANIPConnection:1"/>`+
+			`</s:Body></s:Envelope>`)
+	case "DeletePortMapping":
+		delete(d.mappings, "16111")
+		fmt.Fprint(writer, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">`+
+			`<s:Body><u:DeletePortMappingResponse xmlns:u="urn:schemas-upnp-org:service:WANIPConnection:1"/>`+
+			`</s:Body></s:Envelope>`)
+	default:
+		writer.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func TestUPnPDevice(t *testing.T) {
+	fakeDevice := &fakeUPnPDevice{mappings: make(map[string]string)}
+	server := httptest.NewServer(fakeDevice)
+	defer server.Close()
+
+	device, err := newUPnPDevice(server.URL + "/description.xml")
+	if err != nil {
+		t.Fatalf("newUPnPDevice: %s", err)
+	}
+	if device.controlURL != server.URL+"/control/wanip" {
+		t.Fatalf("Unexpected control URL. Want: %s, got: %s", server.URL+"/control/wanip", device.controlURL)
+	}
+	if device.serviceType != "urn:schemas-upnp-org:service:WANIPConnection:1" {
+		t.Fatalf("Unexpected service type: %s", device.serviceType)
+	}
+
+	externalIP, err := device.externalIP()
+	if err != nil {
+		t.Fatalf("externalIP: %s", err)
+	}
+	if externalIP.String() != "203.0.113.7" {
+		t.Fatalf("Unexpected external IP. Want: 203.0.113.7, got: %s", externalIP)
+	}
+
+	// The device only supports permanent mappings, so the mapping is retried without a lifetime
+	externalPort, grantedLifetime, err := device.addPortMapping(16111, time.Hour)
+	if err != nil {
+		t.Fatalf("addPortMapping: %s", err)
+	}
+	if externalPort != 16111 || grantedLifetime != 0 {
+		t.Fatalf("Unexpected mapping. Want: 16111 permanently, got: %d for %s", externalPort, grantedLifetime)
+	}
+	if _, ok := fakeDevice.mappings["16111"]; !ok {
+		t.Fatalf("The port wasn't mapped")
+	}
+
+	err = device.deletePortMapping(16111)
+	if err != nil {
+		t.Fatalf("deletePortMapping: %s", err)
+	}
+	if _, ok := fakeDevice.mappings["16111"]; ok {
+		t.Fatalf("The mapping wasn't deleted")
+	}
+
+	expectedActions := []string{"GetExternalIPAddress", "AddPortMapping", "AddPortMapping", "DeletePortMapping"}
+	if strings.Join(fakeDevice.actions, ",") != strings.Join(expectedActions, ",") {
+		t.Fatalf("Unexpected actions. Want: %s, got: %s", expectedActions, fakeDevice.actions)
+	}
+}
+
+func TestUPnPDeviceWithoutPortMapping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, `<?xml version="1.0"?><root><device><serviceList><service>`+
+			`<serviceType>urn:schemas-upnp-org:service:Layer3Forwarding:1</serviceType>`+
+			`<controlURL>/control/l3f</controlURL></service></serviceList></device></root>`)
+	}))
+	defer server.Close()
+
+	_, err := newUPnPDevice(server.URL + "/description.xml")
+	if err == nil {
+		t.Fatalf("Expected a device that doesn't map ports to be rejected")
+	}
+}
@@ -0,0 +1,17 @@
+package netadapter
+
+import "github.com/kaspanet/kaspad/infrastructure/metrics"
+
+var (
+	inboundPeersGauge = metrics.NewGauge("kaspad_peers", "The number of connected P2P peers",
+		metrics.Label{Name: "direction", Value: "inbound"})
+	outboundPeersGauge = metrics.NewGauge("kaspad_peers", "The number of connected P2P peers",
+		metrics.Label{Name: "direction", Value: "outbound"})
+)
+
+func peersGauge(netConnection *NetConnection) *metrics.Gauge {
+	if netConnection.IsOutbound() {
+		return outboundPeersGauge
+	}
+	return inboundPeersGauge
+}
@@ -6,11 +6,16 @@ import (
 
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/network/i2p"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/id"
 	routerpkg "github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver"
+	"github.com/kaspanet/kaspad/infrastructure/network/permissions"
+	"github.com/kaspanet/kaspad/infrastructure/network/transport"
+	"github.com/kaspanet/kaspad/util/timesource"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc"
 )
 
 // RouterInitializer is a function that initializes a new
@@ -27,12 +32,19 @@ type NetAdapter struct {
 	id                   *id.ID
 	p2pServer            server.P2PServer
 	p2pRouterInitializer RouterInitializer
-	rpcServer            server.Server
+	rpcServer            server.RPCServer
 	rpcRouterInitializer RouterInitializer
+	serviceServer        server.ServiceServer
 	stop                 uint32
 
 	p2pConnections     map[*NetConnection]struct{}
 	p2pConnectionsLock sync.RWMutex
+
+	traffic      trafficCounters
+	uploadTarget *UploadTarget
+
+	transports   []transport.Transport
+	reachability *transport.Reachability
 }
 
 // NewNetAdapter creates and starts a new NetAdapter on the
@@ -42,21 +54,35 @@ func NewNetAdapter(cfg *config.Config) (*NetAdapter, error) {
 	if err != nil {
 		return nil, err
 	}
-	p2pServer, err := grpcserver.NewP2PServer(cfg.Listeners)
+	reachability := transport.TestReachability(cfg.IPv6Only, cfg.Proxy != "")
+	transports, err := newTransports(cfg, reachability)
+	if err != nil {
+		return nil, err
+	}
+	p2pServer, err := grpcserver.NewP2PServer(p2pListeners(cfg), transports, !cfg.DisableP2PEncryption,
+		cfg.ActiveNetParams.Net)
 	if err != nil {
 		return nil, err
 	}
-	rpcServer, err := grpcserver.NewRPCServer(cfg.RPCListeners, cfg.RPCMaxClients)
+	rpcServer, err := grpcserver.NewRPCServer(cfg.RPCListeners, cfg.RPCMaxClients, cfg.ListenerTLS.RPC)
 	if err != nil {
 		return nil, err
 	}
+	var serviceServer server.ServiceServer
+	if len(cfg.GRPCListeners) > 0 {
+		serviceServer = grpcserver.NewServiceServer(cfg.GRPCListeners, cfg.ListenerTLS.GRPC)
+	}
 	adapter := NetAdapter{
 		cfg:       cfg,
 		id:        netAdapterID,
 		p2pServer: p2pServer,
 		rpcServer: rpcServer,
 
+		serviceServer:  serviceServer,
 		p2pConnections: make(map[*NetConnection]struct{}),
+		uploadTarget:   newUploadTarget(cfg.MaxUploadTarget*1024*1024, timesource.System()),
+		transports:     transports,
+		reachability:   reachability,
 	}
 
 	adapter.p2pServer.SetOnConnectedHandler(adapter.onP2PConnectedHandler)
@@ -65,6 +91,48 @@ func NewNetAdapter(cfg *config.Config) (*NetAdapter, error) {
 	return &adapter, nil
 }
 
+// p2pListeners returns the addresses that the P2P server listens on: the ones
+// of --listen, and the ones of --whitebind that aren't among them
+func p2pListeners(cfg *config.Config) []string {
+	listeners := append([]string{}, cfg.Listeners...)
+	for _, whitebind := range cfg.Whitebinds {
+		isListened := false
+		for _, listener := range listeners {
+			if listener == whitebind.Address {
+				isListened = true
+				break
+			}
+		}
+		if !isListened {
+			listeners = append(listeners, whitebind.Address)
+		}
+	}
+	return listeners
+}
+
+// newTransports returns the transports that P2P connections are made over. I2P
+// addresses are reached through the I2P SAM bridge, and everything else over TCP,
+// unless the node is restricted to I2P. Host names are resolved by the TCP transport
+// itself unless there's a proxy, which resolves them instead.
+func newTransports(cfg *config.Config, reachability *transport.Reachability) ([]transport.Transport, error) {
+	var transports []transport.Transport
+	i2pTransport, err := i2p.NewTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if i2pTransport != nil {
+		transports = append(transports, i2pTransport)
+	}
+	if !cfg.I2POnly {
+		var lookup transport.LookupFunc
+		if cfg.Proxy == "" {
+			lookup = cfg.Lookup
+		}
+		transports = append(transports, transport.NewTCP(cfg.Dial, lookup, reachability))
+	}
+	return transports, nil
+}
+
 // Start begins the operation of the NetAdapter
 func (na *NetAdapter) Start() error {
 	if na.p2pRouterInitializer == nil {
@@ -82,6 +150,12 @@ func (na *NetAdapter) Start() error {
 	if err != nil {
 		return err
 	}
+	if na.serviceServer != nil {
+		err = na.serviceServer.Start()
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -95,6 +169,12 @@ func (na *NetAdapter) Stop() error {
 	if err != nil {
 		return err
 	}
+	if na.serviceServer != nil {
+		err = na.serviceServer.Stop()
+		if err != nil {
+			return err
+		}
+	}
 	return na.rpcServer.Stop()
 }
 
@@ -128,7 +208,21 @@ func (na *NetAdapter) P2PConnectionCount() int {
 }
 
 func (na *NetAdapter) onP2PConnectedHandler(connection server.Connection) error {
-	netConnection := newNetConnection(connection, na.p2pRouterInitializer, "on P2P connected")
+	connectionPermissions := permissions.ForConnection(na.cfg.Whitelists, na.cfg.Whitebinds,
+		connection.Address().IP, connection.ListenAddress())
+	netConnection := newNetConnection(connection, connectionPermissions, na.p2pRouterInitializer, "on P2P connected")
+
+	connection.SetOnMessageSentHandler(func(command appmessage.MessageCommand, size int) {
+		netConnection.traffic.recordSent(command, size)
+		netConnection.messages.recordSent(command, size)
+		na.traffic.recordSent(command, size)
+		na.uploadTarget.recordSent(size)
+	})
+	connection.SetOnMessageReceivedHandler(func(command appmessage.MessageCommand, size int) {
+		netConnection.traffic.recordReceived(size)
+		netConnection.messages.recordReceived(command, size)
+		na.traffic.recordReceived(size)
+	})
 
 	na.p2pConnectionsLock.Lock()
 	defer na.p2pConnectionsLock.Unlock()
@@ -138,9 +232,11 @@ func (na *NetAdapter) onP2PConnectedHandler(connection server.Connection) error
 		defer na.p2pConnectionsLock.Unlock()
 
 		delete(na.p2pConnections, netConnection)
+		peersGauge(netConnection).Add(-1)
 	})
 
 	na.p2pConnections[netConnection] = struct{}{}
+	peersGauge(netConnection).Add(1)
 
 	netConnection.start()
 
@@ -148,7 +244,7 @@ func (na *NetAdapter) onP2PConnectedHandler(connection server.Connection) error
 }
 
 func (na *NetAdapter) onRPCConnectedHandler(connection server.Connection) error {
-	netConnection := newNetConnection(connection, na.rpcRouterInitializer, "on RPC connected")
+	netConnection := newNetConnection(connection, permissions.None, na.rpcRouterInitializer, "on RPC connected")
 	netConnection.setOnDisconnectedHandler(func() {})
 	netConnection.start()
 
@@ -167,6 +263,39 @@ func (na *NetAdapter) SetRPCRouterInitializer(routerInitializer RouterInitialize
 	na.rpcRouterInitializer = routerInitializer
 }
 
+// RegisterRPCService registers an additional gRPC service. It's served on the
+// gRPC service listeners if there are any, and otherwise on the RPC server
+// alongside the message stream. It must be called before Start.
+func (na *NetAdapter) RegisterRPCService(serviceDescription *grpc.ServiceDesc, implementation interface{}) {
+	if na.serviceServer != nil {
+		na.serviceServer.RegisterService(serviceDescription, implementation)
+		return
+	}
+	na.rpcServer.RegisterService(serviceDescription, implementation)
+}
+
+// P2PTraffic returns the bytes transferred over all the P2P connections
+// since the NetAdapter was created, including the ones that are closed by now
+func (na *NetAdapter) P2PTraffic() *TrafficCounters {
+	return na.traffic.snapshot()
+}
+
+// UploadTarget returns the tracker of the upload target set by --maxuploadtarget
+func (na *NetAdapter) UploadTarget() *UploadTarget {
+	return na.uploadTarget
+}
+
+// Reachability returns which of the IP networks P2P connections can be made in
+func (na *NetAdapter) Reachability() *transport.Reachability {
+	return na.reachability
+}
+
+// CanDial returns whether one of the transports of the NetAdapter can reach address
+func (na *NetAdapter) CanDial(address string) bool {
+	_, err := transport.ForAddress(na.transports, address)
+	return err == nil
+}
+
 // ID returns this netAdapter's ID in the network
 func (na *NetAdapter) ID() *id.ID {
 	return na.id
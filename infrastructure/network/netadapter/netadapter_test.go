@@ -164,3 +164,60 @@ func TestNetAdapter(t *testing.T) {
 		t.Fatalf("TestNetAdapter: error expected at attempt to stop adapter second time, but got nothing")
 	}
 }
+
+func TestNetAdapterEncryption(t *testing.T) {
+	const (
+		host            = "127.0.0.1"
+		portEncrypted   = 3010
+		portPlaintext   = 3011
+		portEncrypted2  = 3012
+		connectionCount = 2
+	)
+
+	startAdapter := func(port int, isEncryptionDisabled bool) *NetAdapter {
+		cfg := config.DefaultConfig()
+		cfg.Listeners = []string{fmt.Sprintf("%s:%d", host, port)}
+		cfg.DisableP2PEncryption = isEncryptionDisabled
+		adapter, err := NewNetAdapter(cfg)
+		if err != nil {
+			t.Fatalf("TestNetAdapterEncryption: NetAdapter instantiation failed: %+v", err)
+		}
+		adapter.SetP2PRouterInitializer(func(router *router.Router, connection *NetConnection) {})
+		adapter.SetRPCRouterInitializer(func(router *router.Router, connection *NetConnection) {})
+		err = adapter.Start()
+		if err != nil {
+			t.Fatalf("TestNetAdapterEncryption: Start() failed: %+v", err)
+		}
+		t.Cleanup(func() {
+			adapter.Stop()
+		})
+		return adapter
+	}
+
+	adapterEncrypted := startAdapter(portEncrypted, false)
+	startAdapter(portPlaintext, true)
+	startAdapter(portEncrypted2, false)
+
+	// The encrypted handshake with the peer that has encryption disabled fails,
+	// and it's connected to in plaintext instead
+	err := adapterEncrypted.P2PConnect(fmt.Sprintf("%s:%d", host, portPlaintext))
+	if err != nil {
+		t.Fatalf("TestNetAdapterEncryption: connection to the plaintext peer failed: %+v", err)
+	}
+	err = adapterEncrypted.P2PConnect(fmt.Sprintf("%s:%d", host, portEncrypted2))
+	if err != nil {
+		t.Fatalf("TestNetAdapterEncryption: connection to the encrypted peer failed: %+v", err)
+	}
+
+	connections := adapterEncrypted.P2PConnections()
+	if len(connections) != connectionCount {
+		t.Fatalf("TestNetAdapterEncryption: expected %d connections, got %d", connectionCount, len(connections))
+	}
+	for _, connection := range connections {
+		expectedIsEncrypted := connection.Address() == fmt.Sprintf("%s:%d", host, portEncrypted2)
+		if connection.IsEncrypted() != expectedIsEncrypted {
+			t.Fatalf("TestNetAdapterEncryption: expected IsEncrypted of %s to be %t",
+				connection.Address(), expectedIsEncrypted)
+		}
+	}
+}
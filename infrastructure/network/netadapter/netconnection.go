@@ -9,6 +9,7 @@ import (
 
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/id"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server"
+	"github.com/kaspanet/kaspad/infrastructure/network/permissions"
 )
 
 // NetConnection is a wrapper to a server connection for use by services external to NetAdapter
@@ -18,14 +19,20 @@ type NetConnection struct {
 	router                *routerpkg.Router
 	onDisconnectedHandler server.OnDisconnectedHandler
 	isRouterClosed        uint32
+	traffic               trafficCounters
+	messages              messageCounters
+	permissions           permissions.Flags
 }
 
-func newNetConnection(connection server.Connection, routerInitializer RouterInitializer, name string) *NetConnection {
+func newNetConnection(connection server.Connection, connectionPermissions permissions.Flags,
+	routerInitializer RouterInitializer, name string) *NetConnection {
+
 	router := routerpkg.NewRouter(name)
 
 	netConnection := &NetConnection{
-		connection: connection,
-		router:     router,
+		connection:  connection,
+		router:      router,
+		permissions: connectionPermissions,
 	}
 
 	netConnection.connection.SetOnDisconnectedHandler(func() {
@@ -65,8 +72,13 @@ func (c *NetConnection) SetID(peerID *id.ID) {
 	c.id = peerID
 }
 
-// Address returns the address associated with this connection
+// Address returns the address associated with this connection. For outbound
+// connections, it's the address they were dialed at, so that they're found by
+// the same address they were requested by.
 func (c *NetConnection) Address() string {
+	if dialedAddress := c.connection.DialedAddress(); dialedAddress != "" {
+		return dialedAddress
+	}
 	return c.connection.Address().String()
 }
 
@@ -75,6 +87,26 @@ func (c *NetConnection) IsOutbound() bool {
 	return c.connection.IsOutbound()
 }
 
+// IsEncrypted returns whether the connection is encrypted
+func (c *NetConnection) IsEncrypted() bool {
+	return c.connection.IsEncrypted()
+}
+
+// Permissions returns the permissions that the peer was given by --whitelist and --whitebind
+func (c *NetConnection) Permissions() permissions.Flags {
+	return c.permissions
+}
+
+// Traffic returns the bytes transferred over this connection
+func (c *NetConnection) Traffic() *TrafficCounters {
+	return c.traffic.snapshot()
+}
+
+// MessageCounts returns the messages transferred over this connection, by their command
+func (c *NetConnection) MessageCounts() *MessageCounts {
+	return c.messages.snapshot()
+}
+
 // NetAddress returns the NetAddress associated with this connection
 func (c *NetConnection) NetAddress() *appmessage.NetAddress {
 	return appmessage.NewNetAddress(c.connection.Address())
@@ -12,6 +12,7 @@ import (
 
 	routerpkg "github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
 	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
 )
@@ -71,6 +72,9 @@ func (c *gRPCConnection) sendLoop() error {
 		if err != nil {
 			return err
 		}
+		if c.onMessageSentHandler != nil {
+			c.onMessageSentHandler(message.Command(), proto.Size(messageProto))
+		}
 	}
 	return nil
 }
@@ -96,6 +100,9 @@ func (c *gRPCConnection) receiveLoop() error {
 		messageNumber++
 		message.SetMessageNumber(messageNumber)
 		message.SetReceivedAt(time.Now())
+		if c.onMessageReceivedHandler != nil {
+			c.onMessageReceivedHandler(message.Command(), proto.Size(protoMessage))
+		}
 
 		log.Debugf("incoming '%s' message from %s (message number %d)", message.Command(), c,
 			message.MessageNumber())
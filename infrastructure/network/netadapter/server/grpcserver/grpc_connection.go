@@ -19,20 +19,29 @@ type gRPCConnection struct {
 	server                   *gRPCServer
 	address                  *net.TCPAddr
 	stream                   grpcStream
+	dialedAddress            string
+	listenAddress            string
+	isEncrypted              bool
 	router                   *router.Router
 	lowLevelClientConnection *grpc.ClientConn
 
 	// streamLock protects concurrent access to stream.
 	// Note that it's an RWMutex. Despite what the name
-	// implies, we use it to RLock() send() and receive() because
-	// they can work perfectly fine in parallel, and Lock()
-	// closeSend() because it must run alone.
+	// implies, we use it to RLock() send() because it can work
+	// perfectly fine in parallel, and Lock() closeSend() because
+	// it must not run along with send(). receive() doesn't take
+	// it, since gRPC allows receiving along with closing the send
+	// direction, and closeSend() would otherwise wait for the
+	// next message to arrive.
 	streamLock sync.RWMutex
 
 	stopChan                chan struct{}
 	onDisconnectedHandler   server.OnDisconnectedHandler
 	onInvalidMessageHandler server.OnInvalidMessageHandler
 
+	onMessageSentHandler     server.OnMessageTransferredHandler
+	onMessageReceivedHandler server.OnMessageTransferredHandler
+
 	isConnected uint32
 }
 
@@ -96,6 +105,14 @@ func (c *gRPCConnection) SetOnInvalidMessageHandler(onInvalidMessageHandler serv
 	c.onInvalidMessageHandler = onInvalidMessageHandler
 }
 
+func (c *gRPCConnection) SetOnMessageSentHandler(onMessageSentHandler server.OnMessageTransferredHandler) {
+	c.onMessageSentHandler = onMessageSentHandler
+}
+
+func (c *gRPCConnection) SetOnMessageReceivedHandler(onMessageReceivedHandler server.OnMessageTransferredHandler) {
+	c.onMessageReceivedHandler = onMessageReceivedHandler
+}
+
 func (c *gRPCConnection) IsOutbound() bool {
 	return c.lowLevelClientConnection != nil
 }
@@ -127,20 +144,28 @@ func (c *gRPCConnection) Address() *net.TCPAddr {
 	return c.address
 }
 
-func (c *gRPCConnection) receive() (*protowire.KaspadMessage, error) {
-	// We use RLock here and in send() because they can work
-	// in parallel. closeSend(), however, must not have either
-	// receive() nor send() running while it's running.
-	c.streamLock.RLock()
-	defer c.streamLock.RUnlock()
+func (c *gRPCConnection) DialedAddress() string {
+	return c.dialedAddress
+}
+
+func (c *gRPCConnection) ListenAddress() string {
+	return c.listenAddress
+}
 
+func (c *gRPCConnection) IsEncrypted() bool {
+	return c.isEncrypted
+}
+
+func (c *gRPCConnection) receive() (*protowire.KaspadMessage, error) {
+	// receive() is only ever called by the receive loop, and it may run
+	// along with both send() and closeSend(), so it doesn't take streamLock
 	return c.stream.Recv()
 }
 
 func (c *gRPCConnection) send(message *protowire.KaspadMessage) error {
-	// We use RLock here and in receive() because they can work
-	// in parallel. closeSend(), however, must not have either
-	// receive() nor send() running while it's running.
+	// We use RLock here because sends can work in parallel.
+	// closeSend(), however, must not have send() running while
+	// it's running.
 	c.streamLock.RLock()
 	defer c.streamLock.RUnlock()
 
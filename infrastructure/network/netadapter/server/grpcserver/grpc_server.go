@@ -2,11 +2,13 @@ package grpcserver
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server"
 	"github.com/kaspanet/kaspad/util/panics"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/peer"
 	"net"
 	"sync"
@@ -24,11 +26,15 @@ type gRPCServer struct {
 	inboundConnectionCountLock *sync.Mutex
 }
 
-// newGRPCServer creates a gRPC server
-func newGRPCServer(listeningAddresses []string, maxMessageSize int, maxInboundConnections int, name string) *gRPCServer {
+// newGRPCServer creates a gRPC server that's served with transportCredentials
+func newGRPCServer(listeningAddresses []string, maxMessageSize int, maxInboundConnections int, name string,
+	transportCredentials credentials.TransportCredentials) *gRPCServer {
+
 	log.Debugf("Created new %s GRPC server with maxMessageSize %d and maxInboundConnections %d", name, maxMessageSize, maxInboundConnections)
+	options := []grpc.ServerOption{grpc.MaxRecvMsgSize(maxMessageSize), grpc.MaxSendMsgSize(maxMessageSize),
+		grpc.Creds(transportCredentials)}
 	return &gRPCServer{
-		server:                     grpc.NewServer(grpc.MaxRecvMsgSize(maxMessageSize), grpc.MaxSendMsgSize(maxMessageSize)),
+		server:                     grpc.NewServer(options...),
 		listeningAddresses:         listeningAddresses,
 		name:                       name,
 		maxInboundConnections:      maxInboundConnections,
@@ -37,6 +43,16 @@ func newGRPCServer(listeningAddresses []string, maxMessageSize int, maxInboundCo
 	}
 }
 
+// serverCredentials returns the transport credentials of a server that's served over
+// TLS if tlsConfig isn't nil. Otherwise, the handlers of its connections know which
+// listener accepted them.
+func serverCredentials(tlsConfig *tls.Config) credentials.TransportCredentials {
+	if tlsConfig != nil {
+		return credentials.NewTLS(tlsConfig)
+	}
+	return listenAddressCredentials{}
+}
+
 func (s *gRPCServer) Start() error {
 	if s.onConnectedHandler == nil {
 		return errors.New("onConnectedHandler is nil")
@@ -58,15 +74,21 @@ func (s *gRPCServer) listenOn(listenAddr string) error {
 		return errors.Wrapf(err, "%s error listening on %s", s.name, listenAddr)
 	}
 
-	spawn(fmt.Sprintf("%s.gRPCServer.listenOn-Serve", s.name), func() {
+	s.serve(&listenAddressListener{Listener: listener, listenAddress: listenAddr}, listenAddr)
+
+	log.Infof("%s Server listening on %s", s.name, listener.Addr())
+	return nil
+}
+
+// serve serves the connections accepted by listener, which is described by
+// listenerName in errors
+func (s *gRPCServer) serve(listener net.Listener, listenerName string) {
+	spawn(fmt.Sprintf("%s.gRPCServer.serve", s.name), func() {
 		err := s.server.Serve(listener)
 		if err != nil {
-			panics.Exit(log, fmt.Sprintf("error serving %s on %s: %+v", s.name, listenAddr, err))
+			panics.Exit(log, fmt.Sprintf("error serving %s on %s: %+v", s.name, listenerName, err))
 		}
 	})
-
-	log.Infof("%s Server listening on %s", s.name, listener.Addr())
-	return nil
 }
 
 func (s *gRPCServer) Stop() error {
@@ -110,6 +132,8 @@ func (s *gRPCServer) handleInboundConnection(ctx context.Context, stream grpcStr
 	}
 
 	connection := newConnection(s, tcpAddress, stream, nil)
+	connection.listenAddress = listenAddressFromPeer(peerInfo)
+	connection.isEncrypted = isEncryptedFromPeer(peerInfo)
 
 	err = s.onConnectedHandler(connection)
 	if err != nil {
@@ -0,0 +1,109 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/network/encryptedtransport"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// listenAddressListener is a listener that tags the connections it accepts with
+// the address it was started on
+type listenAddressListener struct {
+	net.Listener
+	listenAddress string
+}
+
+func (l *listenAddressListener) Accept() (net.Conn, error) {
+	connection, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &listenAddressConn{Conn: connection, listenAddress: l.listenAddress}, nil
+}
+
+type listenAddressConn struct {
+	net.Conn
+	listenAddress string
+}
+
+// listenAddressCredentials are insecure transport credentials, which pass the listen
+// address that listenAddressListener tagged a connection with to the handlers of the
+// connection, as the AuthInfo of its peer. The handshake sends nothing on the wire,
+// unless encryption is enabled, in which case the server answers the encrypted
+// handshakes of the peers that start one.
+type listenAddressCredentials struct {
+	isEncryptionEnabled bool
+	network             appmessage.KaspaNet
+}
+
+type listenAddressAuthInfo struct {
+	credentials.CommonAuthInfo
+	listenAddress string
+	isEncrypted   bool
+}
+
+func (listenAddressAuthInfo) AuthType() string {
+	return "insecure"
+}
+
+func (listenAddressCredentials) ClientHandshake(_ context.Context, _ string, connection net.Conn) (
+	net.Conn, credentials.AuthInfo, error) {
+
+	return connection, listenAddressAuthInfo{
+		CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity},
+	}, nil
+}
+
+func (c listenAddressCredentials) ServerHandshake(connection net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	authInfo := listenAddressAuthInfo{
+		CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity},
+	}
+	if taggedConnection, ok := connection.(*listenAddressConn); ok {
+		authInfo.listenAddress = taggedConnection.listenAddress
+		connection = taggedConnection.Conn
+	}
+	if c.isEncryptionEnabled {
+		var err error
+		connection, authInfo.isEncrypted, err = encryptedtransport.Respond(connection, c.network)
+		if err != nil {
+			return nil, nil, err
+		}
+		if authInfo.isEncrypted {
+			authInfo.SecurityLevel = credentials.PrivacyAndIntegrity
+		}
+	}
+	return connection, authInfo, nil
+}
+
+func (listenAddressCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "insecure"}
+}
+
+func (c listenAddressCredentials) Clone() credentials.TransportCredentials {
+	return c
+}
+
+func (listenAddressCredentials) OverrideServerName(string) error {
+	return nil
+}
+
+// listenAddressFromPeer returns the address of the listener that accepted the
+// connection of peerInfo, or an empty string if it's unknown
+func listenAddressFromPeer(peerInfo *peer.Peer) string {
+	authInfo, ok := peerInfo.AuthInfo.(listenAddressAuthInfo)
+	if !ok {
+		return ""
+	}
+	return authInfo.listenAddress
+}
+
+// isEncryptedFromPeer returns whether the connection of peerInfo was accepted
+// with an encrypted handshake
+func isEncryptedFromPeer(peerInfo *peer.Peer) bool {
+	authInfo, ok := peerInfo.AuthInfo.(listenAddressAuthInfo)
+	return ok && authInfo.isEncrypted
+}
@@ -2,20 +2,28 @@ package grpcserver
 
 import (
 	"context"
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/network/encryptedtransport"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/kaspanet/kaspad/infrastructure/network/tor"
+	"github.com/kaspanet/kaspad/infrastructure/network/transport"
 	"github.com/kaspanet/kaspad/util/panics"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/peer"
 	"net"
-	"time"
+	"strconv"
 )
 
 type p2pServer struct {
 	protowire.UnimplementedP2PServer
 	gRPCServer
+	transports []transport.Transport
+
+	isEncryptionEnabled bool
+	network             appmessage.KaspaNet
 }
 
 const p2pMaxMessageSize = 1024 * 1024 * 1024 // 1GB
@@ -26,14 +34,59 @@ const p2pMaxMessageSize = 1024 * 1024 * 1024 // 1GB
 // is handled in the ConnectionManager instead.
 const p2pMaxInboundConnections = 0
 
-// NewP2PServer creates a new P2PServer
-func NewP2PServer(listeningAddresses []string) (server.P2PServer, error) {
-	gRPCServer := newGRPCServer(listeningAddresses, p2pMaxMessageSize, p2pMaxInboundConnections, "P2P")
-	p2pServer := &p2pServer{gRPCServer: *gRPCServer}
+// NewP2PServer creates a new P2PServer, which connects to peers through the first of
+// transports that can reach them. If isEncryptionEnabled, connections to and from
+// peers that support encryption are encrypted, and bound to network.
+func NewP2PServer(listeningAddresses []string, transports []transport.Transport, isEncryptionEnabled bool,
+	network appmessage.KaspaNet) (server.P2PServer, error) {
+
+	transportCredentials := listenAddressCredentials{isEncryptionEnabled: isEncryptionEnabled, network: network}
+	gRPCServer := newGRPCServer(listeningAddresses, p2pMaxMessageSize, p2pMaxInboundConnections, "P2P",
+		transportCredentials)
+	p2pServer := &p2pServer{
+		gRPCServer:          *gRPCServer,
+		transports:          transports,
+		isEncryptionEnabled: isEncryptionEnabled,
+		network:             network,
+	}
 	protowire.RegisterP2PServer(gRPCServer.server, p2pServer)
 	return p2pServer, nil
 }
 
+// Start listens on the listening addresses, and for the incoming connections
+// of the transports that have their own
+func (p *p2pServer) Start() error {
+	err := p.gRPCServer.Start()
+	if err != nil {
+		return err
+	}
+	for _, p2pTransport := range p.transports {
+		listener, err := p2pTransport.Listen()
+		if err != nil {
+			return errors.Wrapf(err, "%s error listening over %s", p.name, p2pTransport.Name())
+		}
+		if listener != nil {
+			p.serve(listener, p2pTransport.Name())
+		}
+	}
+	return nil
+}
+
+// Stop stops serving, and then closes the transports
+func (p *p2pServer) Stop() error {
+	err := p.gRPCServer.Stop()
+	if err != nil {
+		return err
+	}
+	for _, p2pTransport := range p.transports {
+		err := p2pTransport.Close()
+		if err != nil {
+			log.Warnf("Could not close the %s transport: %+v", p2pTransport.Name(), err)
+		}
+	}
+	return nil
+}
+
 func (p *p2pServer) MessageStream(stream protowire.P2P_MessageStreamServer) error {
 	defer panics.HandlePanic(log, "p2pServer.MessageStream", nil)
 
@@ -45,13 +98,22 @@ func (p *p2pServer) MessageStream(stream protowire.P2P_MessageStreamServer) erro
 func (p *p2pServer) Connect(address string) (server.Connection, error) {
 	log.Debugf("%s Dialing to %s", p.name, address)
 
-	const dialTimeout = 1 * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
-	defer cancel()
+	p2pTransport, err := transport.ForAddress(p.transports, address)
+	if err != nil {
+		return nil, err
+	}
 
-	gRPCClientConnection, err := grpc.DialContext(ctx, address, grpc.WithInsecure(), grpc.WithBlock())
+	// Peers that don't support encryption fail the encrypted handshake, and are
+	// then connected to in plaintext
+	isEncrypted := p.isEncryptionEnabled
+	gRPCClientConnection, err := p.dial(address, p2pTransport, isEncrypted)
+	if isEncrypted && errors.Is(err, encryptedtransport.ErrHandshakeFailed) {
+		log.Debugf("%s Encrypted handshake with %s failed, connecting in plaintext: %s", p.name, address, err)
+		isEncrypted = false
+		gRPCClientConnection, err = p.dial(address, p2pTransport, isEncrypted)
+	}
 	if err != nil {
-		return nil, errors.Wrapf(err, "%s error connecting to %s", p.name, address)
+		return nil, err
 	}
 
 	client := protowire.NewP2PClient(gRPCClientConnection)
@@ -67,10 +129,17 @@ func (p *p2pServer) Connect(address string) (server.Connection, error) {
 	}
 	tcpAddress, ok := peerInfo.Addr.(*net.TCPAddr)
 	if !ok {
-		return nil, errors.Errorf("non-tcp addresses are not supported")
+		// Connections through a proxy don't know the IP of the peer, so it's
+		// taken from the dialed address instead
+		tcpAddress, err = dialedTCPAddress(address)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	connection := newConnection(&p.gRPCServer, tcpAddress, stream, gRPCClientConnection)
+	connection.dialedAddress = address
+	connection.isEncrypted = isEncrypted
 
 	err = p.onConnectedHandler(connection)
 	if err != nil {
@@ -81,3 +150,68 @@ func (p *p2pServer) Connect(address string) (server.Connection, error) {
 
 	return connection, nil
 }
+
+// dial connects to address over p2pTransport, and if isEncrypted, performs the encrypted
+// handshake with the peer. Errors of the handshake wrap encryptedtransport.ErrHandshakeFailed.
+func (p *p2pServer) dial(address string, p2pTransport transport.Transport, isEncrypted bool) (*grpc.ClientConn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p2pTransport.DialTimeout(address))
+	defer cancel()
+
+	dial := p2pTransport.Dial
+	// gRPC retries failed dials until ctx is done, so a failed handshake cancels
+	// it and is reported through handshakeErrChan instead
+	handshakeErrChan := make(chan error, 1)
+	if isEncrypted {
+		dial = func(dialCtx context.Context, address string) (net.Conn, error) {
+			connection, err := p2pTransport.Dial(dialCtx, address)
+			if err != nil {
+				return nil, err
+			}
+			encryptedConnection, err := encryptedtransport.Initiate(dialCtx, connection, p.network)
+			if err != nil {
+				connection.Close()
+				select {
+				case handshakeErrChan <- err:
+				default:
+				}
+				cancel()
+				return nil, err
+			}
+			return encryptedConnection, nil
+		}
+	}
+
+	gRPCClientConnection, err := grpc.DialContext(ctx, address, grpc.WithInsecure(), grpc.WithBlock(),
+		grpc.WithContextDialer(dial))
+	if err != nil {
+		select {
+		case handshakeErr := <-handshakeErrChan:
+			return nil, errors.Wrapf(handshakeErr, "%s error connecting to %s over %s",
+				p.name, address, p2pTransport.Name())
+		default:
+		}
+		return nil, errors.Wrapf(err, "%s error connecting to %s over %s", p.name, address, p2pTransport.Name())
+	}
+	return gRPCClientConnection, nil
+}
+
+// dialedTCPAddress returns the TCP address of a peer dialed at address. An onion host
+// is represented by its OnionCat IP.
+func dialedTCPAddress(address string) (*net.TCPAddr, error) {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		ip, err = tor.OnionCatIP(host)
+		if err != nil {
+			return nil, errors.Errorf("non-tcp addresses are not supported")
+		}
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
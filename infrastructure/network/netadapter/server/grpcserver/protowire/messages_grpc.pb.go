@@ -253,3 +253,684 @@ var RPC_ServiceDesc = grpc.ServiceDesc{
 	},
 	Metadata: "messages.proto",
 }
+
+// TypedRPCClient is the client API for TypedRPC service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TypedRPCClient interface {
+	GetInfo(ctx context.Context, in *GetInfoRequestMessage, opts ...grpc.CallOption) (*GetInfoResponseMessage, error)
+	GetBlockDagInfo(ctx context.Context, in *GetBlockDagInfoRequestMessage, opts ...grpc.CallOption) (*GetBlockDagInfoResponseMessage, error)
+	GetBlock(ctx context.Context, in *GetBlockRequestMessage, opts ...grpc.CallOption) (*GetBlockResponseMessage, error)
+	SubmitBlock(ctx context.Context, in *SubmitBlockRequestMessage, opts ...grpc.CallOption) (*SubmitBlockResponseMessage, error)
+	SubmitTransaction(ctx context.Context, in *SubmitTransactionRequestMessage, opts ...grpc.CallOption) (*SubmitTransactionResponseMessage, error)
+	GetMempoolEntry(ctx context.Context, in *GetMempoolEntryRequestMessage, opts ...grpc.CallOption) (*GetMempoolEntryResponseMessage, error)
+	GetMempoolEntries(ctx context.Context, in *GetMempoolEntriesRequestMessage, opts ...grpc.CallOption) (*GetMempoolEntriesResponseMessage, error)
+	StreamBlockAdded(ctx context.Context, in *NotifyBlockAddedRequestMessage, opts ...grpc.CallOption) (TypedRPC_StreamBlockAddedClient, error)
+	StreamVirtualSelectedParentChainChanged(ctx context.Context, in *NotifyVirtualSelectedParentChainChangedRequestMessage, opts ...grpc.CallOption) (TypedRPC_StreamVirtualSelectedParentChainChangedClient, error)
+	StreamDagTipChanged(ctx context.Context, in *NotifyDagTipChangedRequestMessage, opts ...grpc.CallOption) (TypedRPC_StreamDagTipChangedClient, error)
+	StreamUtxosChanged(ctx context.Context, in *NotifyUtxosChangedRequestMessage, opts ...grpc.CallOption) (TypedRPC_StreamUtxosChangedClient, error)
+	StreamVirtualDaaScoreChanged(ctx context.Context, in *NotifyVirtualDaaScoreChangedRequestMessage, opts ...grpc.CallOption) (TypedRPC_StreamVirtualDaaScoreChangedClient, error)
+	StreamBlockHeaderAdded(ctx context.Context, in *NotifyBlockHeaderAddedRequestMessage, opts ...grpc.CallOption) (TypedRPC_StreamBlockHeaderAddedClient, error)
+}
+
+type typedRPCClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTypedRPCClient(cc grpc.ClientConnInterface) TypedRPCClient {
+	return &typedRPCClient{cc}
+}
+
+func (c *typedRPCClient) GetInfo(ctx context.Context, in *GetInfoRequestMessage, opts ...grpc.CallOption) (*GetInfoResponseMessage, error) {
+	out := new(GetInfoResponseMessage)
+	err := c.cc.Invoke(ctx, "/protowire.TypedRPC/GetInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *typedRPCClient) GetBlockDagInfo(ctx context.Context, in *GetBlockDagInfoRequestMessage, opts ...grpc.CallOption) (*GetBlockDagInfoResponseMessage, error) {
+	out := new(GetBlockDagInfoResponseMessage)
+	err := c.cc.Invoke(ctx, "/protowire.TypedRPC/GetBlockDagInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *typedRPCClient) GetBlock(ctx context.Context, in *GetBlockRequestMessage, opts ...grpc.CallOption) (*GetBlockResponseMessage, error) {
+	out := new(GetBlockResponseMessage)
+	err := c.cc.Invoke(ctx, "/protowire.TypedRPC/GetBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *typedRPCClient) SubmitBlock(ctx context.Context, in *SubmitBlockRequestMessage, opts ...grpc.CallOption) (*SubmitBlockResponseMessage, error) {
+	out := new(SubmitBlockResponseMessage)
+	err := c.cc.Invoke(ctx, "/protowire.TypedRPC/SubmitBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *typedRPCClient) SubmitTransaction(ctx context.Context, in *SubmitTransactionRequestMessage, opts ...grpc.CallOption) (*SubmitTransactionResponseMessage, error) {
+	out := new(SubmitTransactionResponseMessage)
+	err := c.cc.Invoke(ctx, "/protowire.TypedRPC/SubmitTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *typedRPCClient) GetMempoolEntry(ctx context.Context, in *GetMempoolEntryRequestMessage, opts ...grpc.CallOption) (*GetMempoolEntryResponseMessage, error) {
+	out := new(GetMempoolEntryResponseMessage)
+	err := c.cc.Invoke(ctx, "/protowire.TypedRPC/GetMempoolEntry", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *typedRPCClient) GetMempoolEntries(ctx context.Context, in *GetMempoolEntriesRequestMessage, opts ...grpc.CallOption) (*GetMempoolEntriesResponseMessage, error) {
+	out := new(GetMempoolEntriesResponseMessage)
+	err := c.cc.Invoke(ctx, "/protowire.TypedRPC/GetMempoolEntries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *typedRPCClient) StreamBlockAdded(ctx context.Context, in *NotifyBlockAddedRequestMessage, opts ...grpc.CallOption) (TypedRPC_StreamBlockAddedClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TypedRPC_ServiceDesc.Streams[0], "/protowire.TypedRPC/StreamBlockAdded", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &typedRPCStreamBlockAddedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TypedRPC_StreamBlockAddedClient interface {
+	Recv() (*BlockAddedNotificationMessage, error)
+	grpc.ClientStream
+}
+
+type typedRPCStreamBlockAddedClient struct {
+	grpc.ClientStream
+}
+
+func (x *typedRPCStreamBlockAddedClient) Recv() (*BlockAddedNotificationMessage, error) {
+	m := new(BlockAddedNotificationMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *typedRPCClient) StreamVirtualSelectedParentChainChanged(ctx context.Context, in *NotifyVirtualSelectedParentChainChangedRequestMessage, opts ...grpc.CallOption) (TypedRPC_StreamVirtualSelectedParentChainChangedClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TypedRPC_ServiceDesc.Streams[1], "/protowire.TypedRPC/StreamVirtualSelectedParentChainChanged", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &typedRPCStreamVirtualSelectedParentChainChangedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TypedRPC_StreamVirtualSelectedParentChainChangedClient interface {
+	Recv() (*VirtualSelectedParentChainChangedNotificationMessage, error)
+	grpc.ClientStream
+}
+
+type typedRPCStreamVirtualSelectedParentChainChangedClient struct {
+	grpc.ClientStream
+}
+
+func (x *typedRPCStreamVirtualSelectedParentChainChangedClient) Recv() (*VirtualSelectedParentChainChangedNotificationMessage, error) {
+	m := new(VirtualSelectedParentChainChangedNotificationMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *typedRPCClient) StreamDagTipChanged(ctx context.Context, in *NotifyDagTipChangedRequestMessage, opts ...grpc.CallOption) (TypedRPC_StreamDagTipChangedClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TypedRPC_ServiceDesc.Streams[2], "/protowire.TypedRPC/StreamDagTipChanged", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &typedRPCStreamDagTipChangedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TypedRPC_StreamDagTipChangedClient interface {
+	Recv() (*DagTipChangedNotificationMessage, error)
+	grpc.ClientStream
+}
+
+type typedRPCStreamDagTipChangedClient struct {
+	grpc.ClientStream
+}
+
+func (x *typedRPCStreamDagTipChangedClient) Recv() (*DagTipChangedNotificationMessage, error) {
+	m := new(DagTipChangedNotificationMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *typedRPCClient) StreamUtxosChanged(ctx context.Context, in *NotifyUtxosChangedRequestMessage, opts ...grpc.CallOption) (TypedRPC_StreamUtxosChangedClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TypedRPC_ServiceDesc.Streams[3], "/protowire.TypedRPC/StreamUtxosChanged", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &typedRPCStreamUtxosChangedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TypedRPC_StreamUtxosChangedClient interface {
+	Recv() (*UtxosChangedNotificationMessage, error)
+	grpc.ClientStream
+}
+
+type typedRPCStreamUtxosChangedClient struct {
+	grpc.ClientStream
+}
+
+func (x *typedRPCStreamUtxosChangedClient) Recv() (*UtxosChangedNotificationMessage, error) {
+	m := new(UtxosChangedNotificationMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *typedRPCClient) StreamVirtualDaaScoreChanged(ctx context.Context, in *NotifyVirtualDaaScoreChangedRequestMessage, opts ...grpc.CallOption) (TypedRPC_StreamVirtualDaaScoreChangedClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TypedRPC_ServiceDesc.Streams[4], "/protowire.TypedRPC/StreamVirtualDaaScoreChanged", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &typedRPCStreamVirtualDaaScoreChangedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TypedRPC_StreamVirtualDaaScoreChangedClient interface {
+	Recv() (*VirtualDaaScoreChangedNotificationMessage, error)
+	grpc.ClientStream
+}
+
+type typedRPCStreamVirtualDaaScoreChangedClient struct {
+	grpc.ClientStream
+}
+
+func (x *typedRPCStreamVirtualDaaScoreChangedClient) Recv() (*VirtualDaaScoreChangedNotificationMessage, error) {
+	m := new(VirtualDaaScoreChangedNotificationMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *typedRPCClient) StreamBlockHeaderAdded(ctx context.Context, in *NotifyBlockHeaderAddedRequestMessage, opts ...grpc.CallOption) (TypedRPC_StreamBlockHeaderAddedClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TypedRPC_ServiceDesc.Streams[5], "/protowire.TypedRPC/StreamBlockHeaderAdded", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &typedRPCStreamBlockHeaderAddedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TypedRPC_StreamBlockHeaderAddedClient interface {
+	Recv() (*BlockHeaderAddedNotificationMessage, error)
+	grpc.ClientStream
+}
+
+type typedRPCStreamBlockHeaderAddedClient struct {
+	grpc.ClientStream
+}
+
+func (x *typedRPCStreamBlockHeaderAddedClient) Recv() (*BlockHeaderAddedNotificationMessage, error) {
+	m := new(BlockHeaderAddedNotificationMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TypedRPCServer is the server API for TypedRPC service.
+// All implementations must embed UnimplementedTypedRPCServer
+// for forward compatibility
+type TypedRPCServer interface {
+	GetInfo(context.Context, *GetInfoRequestMessage) (*GetInfoResponseMessage, error)
+	GetBlockDagInfo(context.Context, *GetBlockDagInfoRequestMessage) (*GetBlockDagInfoResponseMessage, error)
+	GetBlock(context.Context, *GetBlockRequestMessage) (*GetBlockResponseMessage, error)
+	SubmitBlock(context.Context, *SubmitBlockRequestMessage) (*SubmitBlockResponseMessage, error)
+	SubmitTransaction(context.Context, *SubmitTransactionRequestMessage) (*SubmitTransactionResponseMessage, error)
+	GetMempoolEntry(context.Context, *GetMempoolEntryRequestMessage) (*GetMempoolEntryResponseMessage, error)
+	GetMempoolEntries(context.Context, *GetMempoolEntriesRequestMessage) (*GetMempoolEntriesResponseMessage, error)
+	StreamBlockAdded(*NotifyBlockAddedRequestMessage, TypedRPC_StreamBlockAddedServer) error
+	StreamVirtualSelectedParentChainChanged(*NotifyVirtualSelectedParentChainChangedRequestMessage, TypedRPC_StreamVirtualSelectedParentChainChangedServer) error
+	StreamDagTipChanged(*NotifyDagTipChangedRequestMessage, TypedRPC_StreamDagTipChangedServer) error
+	StreamUtxosChanged(*NotifyUtxosChangedRequestMessage, TypedRPC_StreamUtxosChangedServer) error
+	StreamVirtualDaaScoreChanged(*NotifyVirtualDaaScoreChangedRequestMessage, TypedRPC_StreamVirtualDaaScoreChangedServer) error
+	StreamBlockHeaderAdded(*NotifyBlockHeaderAddedRequestMessage, TypedRPC_StreamBlockHeaderAddedServer) error
+	mustEmbedUnimplementedTypedRPCServer()
+}
+
+// UnimplementedTypedRPCServer must be embedded to have forward compatible implementations.
+type UnimplementedTypedRPCServer struct {
+}
+
+func (UnimplementedTypedRPCServer) GetInfo(context.Context, *GetInfoRequestMessage) (*GetInfoResponseMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInfo not implemented")
+}
+func (UnimplementedTypedRPCServer) GetBlockDagInfo(context.Context, *GetBlockDagInfoRequestMessage) (*GetBlockDagInfoResponseMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockDagInfo not implemented")
+}
+func (UnimplementedTypedRPCServer) GetBlock(context.Context, *GetBlockRequestMessage) (*GetBlockResponseMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
+}
+func (UnimplementedTypedRPCServer) SubmitBlock(context.Context, *SubmitBlockRequestMessage) (*SubmitBlockResponseMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitBlock not implemented")
+}
+func (UnimplementedTypedRPCServer) SubmitTransaction(context.Context, *SubmitTransactionRequestMessage) (*SubmitTransactionResponseMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitTransaction not implemented")
+}
+func (UnimplementedTypedRPCServer) GetMempoolEntry(context.Context, *GetMempoolEntryRequestMessage) (*GetMempoolEntryResponseMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMempoolEntry not implemented")
+}
+func (UnimplementedTypedRPCServer) GetMempoolEntries(context.Context, *GetMempoolEntriesRequestMessage) (*GetMempoolEntriesResponseMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMempoolEntries not implemented")
+}
+func (UnimplementedTypedRPCServer) StreamBlockAdded(*NotifyBlockAddedRequestMessage, TypedRPC_StreamBlockAddedServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamBlockAdded not implemented")
+}
+func (UnimplementedTypedRPCServer) StreamVirtualSelectedParentChainChanged(*NotifyVirtualSelectedParentChainChangedRequestMessage, TypedRPC_StreamVirtualSelectedParentChainChangedServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamVirtualSelectedParentChainChanged not implemented")
+}
+func (UnimplementedTypedRPCServer) StreamDagTipChanged(*NotifyDagTipChangedRequestMessage, TypedRPC_StreamDagTipChangedServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamDagTipChanged not implemented")
+}
+func (UnimplementedTypedRPCServer) StreamUtxosChanged(*NotifyUtxosChangedRequestMessage, TypedRPC_StreamUtxosChangedServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamUtxosChanged not implemented")
+}
+func (UnimplementedTypedRPCServer) StreamVirtualDaaScoreChanged(*NotifyVirtualDaaScoreChangedRequestMessage, TypedRPC_StreamVirtualDaaScoreChangedServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamVirtualDaaScoreChanged not implemented")
+}
+func (UnimplementedTypedRPCServer) StreamBlockHeaderAdded(*NotifyBlockHeaderAddedRequestMessage, TypedRPC_StreamBlockHeaderAddedServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamBlockHeaderAdded not implemented")
+}
+func (UnimplementedTypedRPCServer) mustEmbedUnimplementedTypedRPCServer() {}
+
+// UnsafeTypedRPCServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TypedRPCServer will
+// result in compilation errors.
+type UnsafeTypedRPCServer interface {
+	mustEmbedUnimplementedTypedRPCServer()
+}
+
+func RegisterTypedRPCServer(s grpc.ServiceRegistrar, srv TypedRPCServer) {
+	s.RegisterService(&TypedRPC_ServiceDesc, srv)
+}
+
+func _TypedRPC_GetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInfoRequestMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TypedRPCServer).GetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protowire.TypedRPC/GetInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TypedRPCServer).GetInfo(ctx, req.(*GetInfoRequestMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TypedRPC_GetBlockDagInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockDagInfoRequestMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TypedRPCServer).GetBlockDagInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protowire.TypedRPC/GetBlockDagInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TypedRPCServer).GetBlockDagInfo(ctx, req.(*GetBlockDagInfoRequestMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TypedRPC_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockRequestMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TypedRPCServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protowire.TypedRPC/GetBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TypedRPCServer).GetBlock(ctx, req.(*GetBlockRequestMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TypedRPC_SubmitBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitBlockRequestMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TypedRPCServer).SubmitBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protowire.TypedRPC/SubmitBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TypedRPCServer).SubmitBlock(ctx, req.(*SubmitBlockRequestMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TypedRPC_SubmitTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitTransactionRequestMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TypedRPCServer).SubmitTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protowire.TypedRPC/SubmitTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TypedRPCServer).SubmitTransaction(ctx, req.(*SubmitTransactionRequestMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TypedRPC_GetMempoolEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMempoolEntryRequestMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TypedRPCServer).GetMempoolEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protowire.TypedRPC/GetMempoolEntry",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TypedRPCServer).GetMempoolEntry(ctx, req.(*GetMempoolEntryRequestMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TypedRPC_GetMempoolEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMempoolEntriesRequestMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TypedRPCServer).GetMempoolEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protowire.TypedRPC/GetMempoolEntries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TypedRPCServer).GetMempoolEntries(ctx, req.(*GetMempoolEntriesRequestMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TypedRPC_StreamBlockAdded_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(NotifyBlockAddedRequestMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TypedRPCServer).StreamBlockAdded(m, &typedRPCStreamBlockAddedServer{stream})
+}
+
+type TypedRPC_StreamBlockAddedServer interface {
+	Send(*BlockAddedNotificationMessage) error
+	grpc.ServerStream
+}
+
+type typedRPCStreamBlockAddedServer struct {
+	grpc.ServerStream
+}
+
+func (x *typedRPCStreamBlockAddedServer) Send(m *BlockAddedNotificationMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TypedRPC_StreamVirtualSelectedParentChainChanged_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(NotifyVirtualSelectedParentChainChangedRequestMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TypedRPCServer).StreamVirtualSelectedParentChainChanged(m, &typedRPCStreamVirtualSelectedParentChainChangedServer{stream})
+}
+
+type TypedRPC_StreamVirtualSelectedParentChainChangedServer interface {
+	Send(*VirtualSelectedParentChainChangedNotificationMessage) error
+	grpc.ServerStream
+}
+
+type typedRPCStreamVirtualSelectedParentChainChangedServer struct {
+	grpc.ServerStream
+}
+
+func (x *typedRPCStreamVirtualSelectedParentChainChangedServer) Send(m *VirtualSelectedParentChainChangedNotificationMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TypedRPC_StreamDagTipChanged_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(NotifyDagTipChangedRequestMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TypedRPCServer).StreamDagTipChanged(m, &typedRPCStreamDagTipChangedServer{stream})
+}
+
+type TypedRPC_StreamDagTipChangedServer interface {
+	Send(*DagTipChangedNotificationMessage) error
+	grpc.ServerStream
+}
+
+type typedRPCStreamDagTipChangedServer struct {
+	grpc.ServerStream
+}
+
+func (x *typedRPCStreamDagTipChangedServer) Send(m *DagTipChangedNotificationMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TypedRPC_StreamUtxosChanged_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(NotifyUtxosChangedRequestMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TypedRPCServer).StreamUtxosChanged(m, &typedRPCStreamUtxosChangedServer{stream})
+}
+
+type TypedRPC_StreamUtxosChangedServer interface {
+	Send(*UtxosChangedNotificationMessage) error
+	grpc.ServerStream
+}
+
+type typedRPCStreamUtxosChangedServer struct {
+	grpc.ServerStream
+}
+
+func (x *typedRPCStreamUtxosChangedServer) Send(m *UtxosChangedNotificationMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TypedRPC_StreamVirtualDaaScoreChanged_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(NotifyVirtualDaaScoreChangedRequestMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TypedRPCServer).StreamVirtualDaaScoreChanged(m, &typedRPCStreamVirtualDaaScoreChangedServer{stream})
+}
+
+type TypedRPC_StreamVirtualDaaScoreChangedServer interface {
+	Send(*VirtualDaaScoreChangedNotificationMessage) error
+	grpc.ServerStream
+}
+
+type typedRPCStreamVirtualDaaScoreChangedServer struct {
+	grpc.ServerStream
+}
+
+func (x *typedRPCStreamVirtualDaaScoreChangedServer) Send(m *VirtualDaaScoreChangedNotificationMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TypedRPC_StreamBlockHeaderAdded_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(NotifyBlockHeaderAddedRequestMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TypedRPCServer).StreamBlockHeaderAdded(m, &typedRPCStreamBlockHeaderAddedServer{stream})
+}
+
+type TypedRPC_StreamBlockHeaderAddedServer interface {
+	Send(*BlockHeaderAddedNotificationMessage) error
+	grpc.ServerStream
+}
+
+type typedRPCStreamBlockHeaderAddedServer struct {
+	grpc.ServerStream
+}
+
+func (x *typedRPCStreamBlockHeaderAddedServer) Send(m *BlockHeaderAddedNotificationMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// TypedRPC_ServiceDesc is the grpc.ServiceDesc for TypedRPC service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TypedRPC_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "protowire.TypedRPC",
+	HandlerType: (*TypedRPCServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetInfo",
+			Handler:    _TypedRPC_GetInfo_Handler,
+		},
+		{
+			MethodName: "GetBlockDagInfo",
+			Handler:    _TypedRPC_GetBlockDagInfo_Handler,
+		},
+		{
+			MethodName: "GetBlock",
+			Handler:    _TypedRPC_GetBlock_Handler,
+		},
+		{
+			MethodName: "SubmitBlock",
+			Handler:    _TypedRPC_SubmitBlock_Handler,
+		},
+		{
+			MethodName: "SubmitTransaction",
+			Handler:    _TypedRPC_SubmitTransaction_Handler,
+		},
+		{
+			MethodName: "GetMempoolEntry",
+			Handler:    _TypedRPC_GetMempoolEntry_Handler,
+		},
+		{
+			MethodName: "GetMempoolEntries",
+			Handler:    _TypedRPC_GetMempoolEntries_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamBlockAdded",
+			Handler:       _TypedRPC_StreamBlockAdded_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamVirtualSelectedParentChainChanged",
+			Handler:       _TypedRPC_StreamVirtualSelectedParentChainChanged_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamDagTipChanged",
+			Handler:       _TypedRPC_StreamDagTipChanged_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamUtxosChanged",
+			Handler:       _TypedRPC_StreamUtxosChanged_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamVirtualDaaScoreChanged",
+			Handler:       _TypedRPC_StreamVirtualDaaScoreChanged_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamBlockHeaderAdded",
+			Handler:       _TypedRPC_StreamBlockHeaderAdded_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "messages.proto",
+}
@@ -0,0 +1,56 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_AntichainLocator) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_AntichainLocator is nil")
+	}
+	return x.AntichainLocator.toAppMessage()
+}
+
+func (x *AntichainLocatorMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "AntichainLocatorMessage is nil")
+	}
+	if len(x.ChainLocatorHashes) > appmessage.MaxBlockLocatorsPerMsg {
+		return nil, errors.Errorf("too many block locator hashes for message "+
+			"[count %d, max %d]", len(x.ChainLocatorHashes), appmessage.MaxBlockLocatorsPerMsg)
+	}
+	if len(x.TipHashes) > appmessage.MaxAntichainLocatorTipsPerMsg {
+		return nil, errors.Errorf("too many tip hashes for message "+
+			"[count %d, max %d]", len(x.TipHashes), appmessage.MaxAntichainLocatorTipsPerMsg)
+	}
+
+	chainLocatorHashes, err := protoHashesToDomain(x.ChainLocatorHashes)
+	if err != nil {
+		return nil, err
+	}
+	tipHashes, err := protoHashesToDomain(x.TipHashes)
+	if err != nil {
+		return nil, err
+	}
+	return &appmessage.MsgAntichainLocator{
+		ChainLocatorHashes: chainLocatorHashes,
+		TipHashes:          tipHashes,
+	}, nil
+}
+
+func (x *KaspadMessage_AntichainLocator) fromAppMessage(msgAntichainLocator *appmessage.MsgAntichainLocator) error {
+	if len(msgAntichainLocator.ChainLocatorHashes) > appmessage.MaxBlockLocatorsPerMsg {
+		return errors.Errorf("too many block locator hashes for message "+
+			"[count %d, max %d]", len(msgAntichainLocator.ChainLocatorHashes), appmessage.MaxBlockLocatorsPerMsg)
+	}
+	if len(msgAntichainLocator.TipHashes) > appmessage.MaxAntichainLocatorTipsPerMsg {
+		return errors.Errorf("too many tip hashes for message "+
+			"[count %d, max %d]", len(msgAntichainLocator.TipHashes), appmessage.MaxAntichainLocatorTipsPerMsg)
+	}
+	x.AntichainLocator = &AntichainLocatorMessage{
+		ChainLocatorHashes: domainHashesToProto(msgAntichainLocator.ChainLocatorHashes),
+		TipHashes:          domainHashesToProto(msgAntichainLocator.TipHashes),
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_CfCheckpt) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_CfCheckpt is nil")
+	}
+	return x.CfCheckpt.toAppMessage()
+}
+
+func (x *CFCheckptMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "CFCheckptMessage is nil")
+	}
+	filterType, err := filterTypeToAppMessage(x.FilterType)
+	if err != nil {
+		return nil, err
+	}
+	stopHash, err := x.StopHash.toDomain()
+	if err != nil {
+		return nil, err
+	}
+	if len(x.FilterHeaders) > appmessage.MaxCFCheckptHeaders {
+		return nil, errors.Errorf("too many filter headers for message "+
+			"[count %d, max %d]", len(x.FilterHeaders), appmessage.MaxCFCheckptHeaders)
+	}
+	filterHeaders, err := protoHashesToDomain(x.FilterHeaders)
+	if err != nil {
+		return nil, err
+	}
+	return &appmessage.MsgCFCheckpt{
+		FilterType:    filterType,
+		StopHash:      stopHash,
+		FilterHeaders: filterHeaders,
+	}, nil
+}
+
+func (x *KaspadMessage_CfCheckpt) fromAppMessage(msgCFCheckpt *appmessage.MsgCFCheckpt) error {
+	if len(msgCFCheckpt.FilterHeaders) > appmessage.MaxCFCheckptHeaders {
+		return errors.Errorf("too many filter headers for message "+
+			"[count %d, max %d]", len(msgCFCheckpt.FilterHeaders), appmessage.MaxCFCheckptHeaders)
+	}
+	x.CfCheckpt = &CFCheckptMessage{
+		FilterType:    uint32(msgCFCheckpt.FilterType),
+		StopHash:      domainHashToProto(msgCFCheckpt.StopHash),
+		FilterHeaders: domainHashesToProto(msgCFCheckpt.FilterHeaders),
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_CfHeaders) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_CfHeaders is nil")
+	}
+	return x.CfHeaders.toAppMessage()
+}
+
+func (x *CFHeadersMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "CFHeadersMessage is nil")
+	}
+	filterType, err := filterTypeToAppMessage(x.FilterType)
+	if err != nil {
+		return nil, err
+	}
+	stopHash, err := x.StopHash.toDomain()
+	if err != nil {
+		return nil, err
+	}
+	if len(x.BlockHashes) > appmessage.MaxGetCFiltersReqRange {
+		return nil, errors.Errorf("too many filter headers for message "+
+			"[count %d, max %d]", len(x.BlockHashes), appmessage.MaxGetCFiltersReqRange)
+	}
+	if len(x.FilterHashes) != len(x.BlockHashes) || len(x.FilterHeaders) != len(x.BlockHashes) {
+		return nil, errors.Errorf("mismatched number of block hashes (%d), filter hashes (%d) "+
+			"and filter headers (%d)", len(x.BlockHashes), len(x.FilterHashes), len(x.FilterHeaders))
+	}
+	blockHashes, err := protoHashesToDomain(x.BlockHashes)
+	if err != nil {
+		return nil, err
+	}
+	filterHashes, err := protoHashesToDomain(x.FilterHashes)
+	if err != nil {
+		return nil, err
+	}
+	filterHeaders, err := protoHashesToDomain(x.FilterHeaders)
+	if err != nil {
+		return nil, err
+	}
+	return &appmessage.MsgCFHeaders{
+		FilterType:    filterType,
+		StopHash:      stopHash,
+		BlockHashes:   blockHashes,
+		FilterHashes:  filterHashes,
+		FilterHeaders: filterHeaders,
+	}, nil
+}
+
+func (x *KaspadMessage_CfHeaders) fromAppMessage(msgCFHeaders *appmessage.MsgCFHeaders) error {
+	if len(msgCFHeaders.BlockHashes) > appmessage.MaxGetCFiltersReqRange {
+		return errors.Errorf("too many filter headers for message "+
+			"[count %d, max %d]", len(msgCFHeaders.BlockHashes), appmessage.MaxGetCFiltersReqRange)
+	}
+	x.CfHeaders = &CFHeadersMessage{
+		FilterType:    uint32(msgCFHeaders.FilterType),
+		StopHash:      domainHashToProto(msgCFHeaders.StopHash),
+		BlockHashes:   domainHashesToProto(msgCFHeaders.BlockHashes),
+		FilterHashes:  domainHashesToProto(msgCFHeaders.FilterHashes),
+		FilterHeaders: domainHashesToProto(msgCFHeaders.FilterHeaders),
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package protowire
+
+import (
+	"math"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func filterTypeToAppMessage(filterType uint32) (appmessage.FilterType, error) {
+	if filterType > math.MaxUint8 {
+		return 0, errors.Errorf("filter type %d is out of range", filterType)
+	}
+	return appmessage.FilterType(filterType), nil
+}
+
+func (x *KaspadMessage_Cfilter) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_Cfilter is nil")
+	}
+	return x.Cfilter.toAppMessage()
+}
+
+func (x *CFilterMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "CFilterMessage is nil")
+	}
+	filterType, err := filterTypeToAppMessage(x.FilterType)
+	if err != nil {
+		return nil, err
+	}
+	blockHash, err := x.BlockHash.toDomain()
+	if err != nil {
+		return nil, err
+	}
+	if len(x.Data) > appmessage.MaxCFilterDataSize {
+		return nil, errors.Errorf("filter size too large for message "+
+			"[size %d, max %d]", len(x.Data), appmessage.MaxCFilterDataSize)
+	}
+	return &appmessage.MsgCFilter{
+		FilterType: filterType,
+		BlockHash:  blockHash,
+		Data:       x.Data,
+	}, nil
+}
+
+func (x *KaspadMessage_Cfilter) fromAppMessage(msgCFilter *appmessage.MsgCFilter) error {
+	if len(msgCFilter.Data) > appmessage.MaxCFilterDataSize {
+		return errors.Errorf("filter size too large for message "+
+			"[size %d, max %d]", len(msgCFilter.Data), appmessage.MaxCFilterDataSize)
+	}
+	x.Cfilter = &CFilterMessage{
+		FilterType: uint32(msgCFilter.FilterType),
+		BlockHash:  domainHashToProto(msgCFilter.BlockHash),
+		Data:       msgCFilter.Data,
+	}
+	return nil
+}
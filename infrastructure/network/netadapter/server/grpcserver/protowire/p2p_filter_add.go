@@ -0,0 +1,29 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_FilterAdd) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_FilterAdd is nil")
+	}
+	return x.FilterAdd.toAppMessage()
+}
+
+func (x *FilterAddMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "FilterAddMessage is nil")
+	}
+	if len(x.Data) > appmessage.MaxFilterAddDataSize {
+		return nil, errors.Errorf("filter data too large for message "+
+			"[size %d, max %d]", len(x.Data), appmessage.MaxFilterAddDataSize)
+	}
+	return &appmessage.MsgFilterAdd{Data: x.Data}, nil
+}
+
+func (x *KaspadMessage_FilterAdd) fromAppMessage(msgFilterAdd *appmessage.MsgFilterAdd) error {
+	x.FilterAdd = &FilterAddMessage{Data: msgFilterAdd.Data}
+	return nil
+}
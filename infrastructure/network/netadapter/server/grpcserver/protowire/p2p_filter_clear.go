@@ -0,0 +1,18 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_FilterClear) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_FilterClear is nil")
+	}
+	return &appmessage.MsgFilterClear{}, nil
+}
+
+func (x *KaspadMessage_FilterClear) fromAppMessage(_ *appmessage.MsgFilterClear) error {
+	x.FilterClear = &FilterClearMessage{}
+	return nil
+}
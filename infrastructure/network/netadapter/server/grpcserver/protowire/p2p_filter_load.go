@@ -0,0 +1,46 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_FilterLoad) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_FilterLoad is nil")
+	}
+	return x.FilterLoad.toAppMessage()
+}
+
+func (x *FilterLoadMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "FilterLoadMessage is nil")
+	}
+	if len(x.Filter) > appmessage.MaxFilterLoadFilterSize {
+		return nil, errors.Errorf("filter size too large for message "+
+			"[size %d, max %d]", len(x.Filter), appmessage.MaxFilterLoadFilterSize)
+	}
+	if x.HashFuncs > appmessage.MaxFilterLoadHashFuncs {
+		return nil, errors.Errorf("too many filter hash functions for message "+
+			"[count %d, max %d]", x.HashFuncs, appmessage.MaxFilterLoadHashFuncs)
+	}
+	if x.Flags > uint32(appmessage.BloomUpdateP2PubkeyOnly) {
+		return nil, errors.Errorf("unknown filter update type %d", x.Flags)
+	}
+	return &appmessage.MsgFilterLoad{
+		Filter:    x.Filter,
+		HashFuncs: x.HashFuncs,
+		Tweak:     x.Tweak,
+		Flags:     appmessage.BloomUpdateType(x.Flags),
+	}, nil
+}
+
+func (x *KaspadMessage_FilterLoad) fromAppMessage(msgFilterLoad *appmessage.MsgFilterLoad) error {
+	x.FilterLoad = &FilterLoadMessage{
+		Filter:    msgFilterLoad.Filter,
+		HashFuncs: msgFilterLoad.HashFuncs,
+		Tweak:     msgFilterLoad.Tweak,
+		Flags:     uint32(msgFilterLoad.Flags),
+	}
+	return nil
+}
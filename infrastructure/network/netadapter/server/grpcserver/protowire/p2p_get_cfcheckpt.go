@@ -0,0 +1,39 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetCFCheckpt) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetCFCheckpt is nil")
+	}
+	return x.GetCFCheckpt.toAppMessage()
+}
+
+func (x *GetCFCheckptMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetCFCheckptMessage is nil")
+	}
+	filterType, err := filterTypeToAppMessage(x.FilterType)
+	if err != nil {
+		return nil, err
+	}
+	stopHash, err := x.StopHash.toDomain()
+	if err != nil {
+		return nil, err
+	}
+	return &appmessage.MsgGetCFCheckpt{
+		FilterType: filterType,
+		StopHash:   stopHash,
+	}, nil
+}
+
+func (x *KaspadMessage_GetCFCheckpt) fromAppMessage(msgGetCFCheckpt *appmessage.MsgGetCFCheckpt) error {
+	x.GetCFCheckpt = &GetCFCheckptMessage{
+		FilterType: uint32(msgGetCFCheckpt.FilterType),
+		StopHash:   domainHashToProto(msgGetCFCheckpt.StopHash),
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetCFHeaders) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetCFHeaders is nil")
+	}
+	return x.GetCFHeaders.toAppMessage()
+}
+
+func (x *GetCFHeadersMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetCFHeadersMessage is nil")
+	}
+	filterType, err := filterTypeToAppMessage(x.FilterType)
+	if err != nil {
+		return nil, err
+	}
+	lowHash, err := x.LowHash.toDomain()
+	if err != nil {
+		return nil, err
+	}
+	highHash, err := x.HighHash.toDomain()
+	if err != nil {
+		return nil, err
+	}
+	return &appmessage.MsgGetCFHeaders{
+		FilterType: filterType,
+		LowHash:    lowHash,
+		HighHash:   highHash,
+	}, nil
+}
+
+func (x *KaspadMessage_GetCFHeaders) fromAppMessage(msgGetCFHeaders *appmessage.MsgGetCFHeaders) error {
+	x.GetCFHeaders = &GetCFHeadersMessage{
+		FilterType: uint32(msgGetCFHeaders.FilterType),
+		LowHash:    domainHashToProto(msgGetCFHeaders.LowHash),
+		HighHash:   domainHashToProto(msgGetCFHeaders.HighHash),
+	}
+	return nil
+}
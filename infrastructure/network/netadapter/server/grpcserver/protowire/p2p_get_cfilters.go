@@ -0,0 +1,45 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetCFilters) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetCFilters is nil")
+	}
+	return x.GetCFilters.toAppMessage()
+}
+
+func (x *GetCFiltersMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetCFiltersMessage is nil")
+	}
+	filterType, err := filterTypeToAppMessage(x.FilterType)
+	if err != nil {
+		return nil, err
+	}
+	lowHash, err := x.LowHash.toDomain()
+	if err != nil {
+		return nil, err
+	}
+	highHash, err := x.HighHash.toDomain()
+	if err != nil {
+		return nil, err
+	}
+	return &appmessage.MsgGetCFilters{
+		FilterType: filterType,
+		LowHash:    lowHash,
+		HighHash:   highHash,
+	}, nil
+}
+
+func (x *KaspadMessage_GetCFilters) fromAppMessage(msgGetCFilters *appmessage.MsgGetCFilters) error {
+	x.GetCFilters = &GetCFiltersMessage{
+		FilterType: uint32(msgGetCFilters.FilterType),
+		LowHash:    domainHashToProto(msgGetCFilters.LowHash),
+		HighHash:   domainHashToProto(msgGetCFilters.HighHash),
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_InvPackage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_InvPackage is nil")
+	}
+	return x.InvPackage.toAppMessage()
+}
+
+func (x *InvPackageMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "InvPackageMessage is nil")
+	}
+	if len(x.Ids) > appmessage.MaxInvPerPackageInvMsg {
+		return nil, errors.Errorf("too many IDs for message "+
+			"[count %d, max %d]", len(x.Ids), appmessage.MaxInvPerPackageInvMsg)
+	}
+
+	ids, err := protoTransactionIDsToDomain(x.Ids)
+	if err != nil {
+		return nil, err
+	}
+	return appmessage.NewMsgInvPackage(ids), nil
+}
+
+func (x *KaspadMessage_InvPackage) fromAppMessage(msgInvPackage *appmessage.MsgInvPackage) error {
+	if len(msgInvPackage.IDs) > appmessage.MaxInvPerPackageInvMsg {
+		return errors.Errorf("too many IDs for message "+
+			"[count %d, max %d]", len(msgInvPackage.IDs), appmessage.MaxInvPerPackageInvMsg)
+	}
+
+	x.InvPackage = &InvPackageMessage{
+		Ids: wireTransactionIDsToProto(msgInvPackage.IDs),
+	}
+	return nil
+}
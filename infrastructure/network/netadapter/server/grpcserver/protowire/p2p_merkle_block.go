@@ -0,0 +1,72 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_MerkleBlock) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_MerkleBlock is nil")
+	}
+	return x.MerkleBlock.toAppMessage()
+}
+
+func (x *MerkleBlockMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "MerkleBlockMessage is nil")
+	}
+	header, err := x.Header.toAppMessage()
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := protoHashesToDomain(x.Hashes)
+	if err != nil {
+		return nil, err
+	}
+	if uint32(len(x.Transactions)) > x.TransactionCount {
+		return nil, errors.Errorf("more matched transactions than transactions in the block "+
+			"[matched %d, total %d]", len(x.Transactions), x.TransactionCount)
+	}
+
+	transactions := make([]*appmessage.MsgTx, len(x.Transactions))
+	for i, protoTx := range x.Transactions {
+		msgTx, err := protoTx.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		transactions[i] = msgTx.(*appmessage.MsgTx)
+	}
+
+	return &appmessage.MsgMerkleBlock{
+		Header:           header,
+		TransactionCount: x.TransactionCount,
+		Hashes:           hashes,
+		Flags:            x.Flags,
+		Transactions:     transactions,
+	}, nil
+}
+
+func (x *KaspadMessage_MerkleBlock) fromAppMessage(msgMerkleBlock *appmessage.MsgMerkleBlock) error {
+	protoHeader := new(BlockHeader)
+	err := protoHeader.fromAppMessage(msgMerkleBlock.Header)
+	if err != nil {
+		return err
+	}
+
+	protoTransactions := make([]*TransactionMessage, len(msgMerkleBlock.Transactions))
+	for i, tx := range msgMerkleBlock.Transactions {
+		protoTx := new(TransactionMessage)
+		protoTx.fromAppMessage(tx)
+		protoTransactions[i] = protoTx
+	}
+
+	x.MerkleBlock = &MerkleBlockMessage{
+		Header:           protoHeader,
+		TransactionCount: msgMerkleBlock.TransactionCount,
+		Hashes:           domainHashesToProto(msgMerkleBlock.Hashes),
+		Flags:            msgMerkleBlock.Flags,
+		Transactions:     protoTransactions,
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_TransactionPackage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_TransactionPackage is nil")
+	}
+	return x.TransactionPackage.toAppMessage()
+}
+
+func (x *PackageMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "PackageMessage is nil")
+	}
+	id, err := x.Id.toDomain()
+	if err != nil {
+		return nil, err
+	}
+	transactions := make([]*appmessage.MsgTx, len(x.Transactions))
+	for i, transaction := range x.Transactions {
+		msgTx, err := transaction.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		transactions[i] = msgTx.(*appmessage.MsgTx)
+	}
+	return appmessage.NewMsgPackage(id, transactions), nil
+}
+
+func (x *KaspadMessage_TransactionPackage) fromAppMessage(msgPackage *appmessage.MsgPackage) error {
+	transactions := make([]*TransactionMessage, len(msgPackage.Transactions))
+	for i, msgTx := range msgPackage.Transactions {
+		transactions[i] = new(TransactionMessage)
+		transactions[i].fromAppMessage(msgTx)
+	}
+	x.TransactionPackage = &PackageMessage{
+		Id:           domainTransactionIDToProto(msgPackage.ID),
+		Transactions: transactions,
+	}
+	return nil
+}
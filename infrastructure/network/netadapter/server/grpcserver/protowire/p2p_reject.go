@@ -2,6 +2,7 @@ package protowire
 
 import (
 	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/pkg/errors"
 )
 
@@ -16,14 +17,30 @@ func (x *RejectMessage) toAppMessage() (appmessage.Message, error) {
 	if x == nil {
 		return nil, errors.Wrapf(errorNil, "RejectMessage is nil")
 	}
+	var rejectedHash *externalapi.DomainHash
+	if x.RejectedHash != nil {
+		var err error
+		rejectedHash, err = x.RejectedHash.toDomain()
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &appmessage.MsgReject{
-		Reason: x.Reason,
+		Reason:       x.Reason,
+		Code:         appmessage.RejectCode(x.Code),
+		RejectedHash: rejectedHash,
 	}, nil
 }
 
 func (x *KaspadMessage_Reject) fromAppMessage(msgReject *appmessage.MsgReject) error {
+	var rejectedHash *Hash
+	if msgReject.RejectedHash != nil {
+		rejectedHash = domainHashToProto(msgReject.RejectedHash)
+	}
 	x.Reject = &RejectMessage{
-		Reason: msgReject.Reason,
+		Reason:       msgReject.Reason,
+		Code:         RejectMessage_RejectCode(msgReject.Code),
+		RejectedHash: rejectedHash,
 	}
 	return nil
 }
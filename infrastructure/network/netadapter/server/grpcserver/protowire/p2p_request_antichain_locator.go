@@ -0,0 +1,18 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_RequestAntichainLocator) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_RequestAntichainLocator is nil")
+	}
+	return &appmessage.MsgRequestAntichainLocator{}, nil
+}
+
+func (x *KaspadMessage_RequestAntichainLocator) fromAppMessage(_ *appmessage.MsgRequestAntichainLocator) error {
+	x.RequestAntichainLocator = &RequestAntichainLocatorMessage{}
+	return nil
+}
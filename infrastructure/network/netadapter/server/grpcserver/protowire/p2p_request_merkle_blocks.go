@@ -0,0 +1,41 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_RequestMerkleBlocks) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_RequestMerkleBlocks is nil")
+	}
+	return x.RequestMerkleBlocks.toAppMessage()
+}
+
+func (x *RequestMerkleBlocksMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "RequestMerkleBlocksMessage is nil")
+	}
+	if len(x.Hashes) > appmessage.MaxRequestMerkleBlocksHashes {
+		return nil, errors.Errorf("too many hashes for message "+
+			"[count %d, max %d]", len(x.Hashes), appmessage.MaxRequestMerkleBlocksHashes)
+	}
+	hashes, err := protoHashesToDomain(x.Hashes)
+	if err != nil {
+		return nil, err
+	}
+	return &appmessage.MsgRequestMerkleBlocks{Hashes: hashes}, nil
+
+}
+
+func (x *KaspadMessage_RequestMerkleBlocks) fromAppMessage(msgRequestMerkleBlocks *appmessage.MsgRequestMerkleBlocks) error {
+	if len(msgRequestMerkleBlocks.Hashes) > appmessage.MaxRequestMerkleBlocksHashes {
+		return errors.Errorf("too many hashes for message "+
+			"[count %d, max %d]", len(msgRequestMerkleBlocks.Hashes), appmessage.MaxRequestMerkleBlocksHashes)
+	}
+
+	x.RequestMerkleBlocks = &RequestMerkleBlocksMessage{
+		Hashes: domainHashesToProto(msgRequestMerkleBlocks.Hashes),
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_RequestPackage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_RequestPackage is nil")
+	}
+	return x.RequestPackage.toAppMessage()
+}
+
+func (x *RequestPackageMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "RequestPackageMessage is nil")
+	}
+	id, err := x.Id.toDomain()
+	if err != nil {
+		return nil, err
+	}
+	return appmessage.NewMsgRequestPackage(id), nil
+}
+
+func (x *KaspadMessage_RequestPackage) fromAppMessage(msgRequestPackage *appmessage.MsgRequestPackage) error {
+	x.RequestPackage = &RequestPackageMessage{
+		Id: domainTransactionIDToProto(msgRequestPackage.ID),
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_RequestUtxoExistence) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_RequestUtxoExistence is nil")
+	}
+	return x.RequestUtxoExistence.toAppMessage()
+}
+
+func (x *RequestUTXOExistenceMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "RequestUTXOExistenceMessage is nil")
+	}
+	if len(x.Outpoints) > appmessage.MaxUTXOExistenceOutpoints {
+		return nil, errors.Errorf("too many outpoints in message [count %d, max %d]",
+			len(x.Outpoints), appmessage.MaxUTXOExistenceOutpoints)
+	}
+	outpoints := make([]*appmessage.Outpoint, len(x.Outpoints))
+	for i, protoOutpoint := range x.Outpoints {
+		outpoint, err := protoOutpoint.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		outpoints[i] = outpoint
+	}
+	return appmessage.NewMsgRequestUTXOExistence(outpoints, x.IncludeEntries, x.IncludeProofs), nil
+}
+
+func (x *KaspadMessage_RequestUtxoExistence) fromAppMessage(msgRequestUTXOExistence *appmessage.MsgRequestUTXOExistence) error {
+	if len(msgRequestUTXOExistence.Outpoints) > appmessage.MaxUTXOExistenceOutpoints {
+		return errors.Errorf("too many outpoints in message [count %d, max %d]",
+			len(msgRequestUTXOExistence.Outpoints), appmessage.MaxUTXOExistenceOutpoints)
+	}
+	outpoints := make([]*Outpoint, len(msgRequestUTXOExistence.Outpoints))
+	for i, outpoint := range msgRequestUTXOExistence.Outpoints {
+		outpoints[i] = &Outpoint{
+			TransactionId: domainTransactionIDToProto(&outpoint.TxID),
+			Index:         outpoint.Index,
+		}
+	}
+	x.RequestUtxoExistence = &RequestUTXOExistenceMessage{
+		Outpoints:      outpoints,
+		IncludeEntries: msgRequestUTXOExistence.IncludeEntries,
+		IncludeProofs:  msgRequestUTXOExistence.IncludeProofs,
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_StemTransaction) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_StemTransaction is nil")
+	}
+	return x.StemTransaction.toAppMessage()
+}
+
+func (x *KaspadMessage_StemTransaction) fromAppMessage(msgStemTransaction *appmessage.MsgStemTransaction) error {
+	x.StemTransaction = &StemTransactionMessage{
+		Transaction: new(TransactionMessage),
+	}
+	x.StemTransaction.Transaction.fromAppMessage(msgStemTransaction.Transaction)
+	return nil
+}
+
+func (x *StemTransactionMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "StemTransactionMessage is nil")
+	}
+	transaction, err := x.Transaction.toAppMessage()
+	if err != nil {
+		return nil, err
+	}
+	return &appmessage.MsgStemTransaction{
+		Transaction: transaction.(*appmessage.MsgTx),
+	}, nil
+}
@@ -0,0 +1,121 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+// maxUTXOExistenceBitsLength is the length of the existence bits of the most
+// outpoints that may be requested at once
+const maxUTXOExistenceBitsLength = (appmessage.MaxUTXOExistenceOutpoints + 7) / 8
+
+func (x *KaspadMessage_UtxoExistence) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_UtxoExistence is nil")
+	}
+	return x.UtxoExistence.toAppMessage()
+}
+
+func (x *UTXOExistenceMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "UTXOExistenceMessage is nil")
+	}
+	if len(x.ExistenceBits) > maxUTXOExistenceBitsLength {
+		return nil, errors.Errorf("too many existence bits in message [length %d, max %d]",
+			len(x.ExistenceBits), maxUTXOExistenceBitsLength)
+	}
+	if len(x.Entries) > appmessage.MaxUTXOExistenceOutpoints {
+		return nil, errors.Errorf("too many entries in message [count %d, max %d]",
+			len(x.Entries), appmessage.MaxUTXOExistenceOutpoints)
+	}
+	if len(x.Proofs) > appmessage.MaxUTXOExistenceOutpoints {
+		return nil, errors.Errorf("too many proofs in message [count %d, max %d]",
+			len(x.Proofs), appmessage.MaxUTXOExistenceOutpoints)
+	}
+
+	virtualSelectedParent, err := x.VirtualSelectedParent.toDomain()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*appmessage.UTXOEntry, len(x.Entries))
+	for i, protoEntry := range x.Entries {
+		entry, err := protoEntry.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = entry
+	}
+	proofs := make([]*appmessage.UTXOExistenceProof, len(x.Proofs))
+	for i, protoProof := range x.Proofs {
+		proof, err := protoProof.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		proofs[i] = proof
+	}
+	return appmessage.NewMsgUTXOExistence(virtualSelectedParent, x.ExistenceBits, entries, proofs), nil
+}
+
+func (x *UTXOExistenceProof) toAppMessage() (*appmessage.UTXOExistenceProof, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "UTXOExistenceProof is nil")
+	}
+	header, err := x.Header.toAppMessage()
+	if err != nil {
+		return nil, err
+	}
+	transaction, err := x.Transaction.toAppMessage()
+	if err != nil {
+		return nil, err
+	}
+	siblings, err := protoHashesToDomain(x.Siblings)
+	if err != nil {
+		return nil, err
+	}
+	return &appmessage.UTXOExistenceProof{
+		OutpointIndex:    x.OutpointIndex,
+		Header:           header,
+		Transaction:      transaction.(*appmessage.MsgTx),
+		TransactionIndex: x.TransactionIndex,
+		Siblings:         siblings,
+	}, nil
+}
+
+func (x *KaspadMessage_UtxoExistence) fromAppMessage(msgUTXOExistence *appmessage.MsgUTXOExistence) error {
+	entries := make([]*UtxoEntry, len(msgUTXOExistence.Entries))
+	for i, entry := range msgUTXOExistence.Entries {
+		entries[i] = &UtxoEntry{
+			Amount: entry.Amount,
+			ScriptPublicKey: &ScriptPublicKey{
+				Script:  entry.ScriptPublicKey.Script,
+				Version: uint32(entry.ScriptPublicKey.Version),
+			},
+			BlockDaaScore: entry.BlockDAAScore,
+			IsCoinbase:    entry.IsCoinbase,
+		}
+	}
+	proofs := make([]*UTXOExistenceProof, len(msgUTXOExistence.Proofs))
+	for i, proof := range msgUTXOExistence.Proofs {
+		protoHeader := new(BlockHeader)
+		err := protoHeader.fromAppMessage(proof.Header)
+		if err != nil {
+			return err
+		}
+		protoTransaction := new(TransactionMessage)
+		protoTransaction.fromAppMessage(proof.Transaction)
+		proofs[i] = &UTXOExistenceProof{
+			OutpointIndex:    proof.OutpointIndex,
+			Header:           protoHeader,
+			Transaction:      protoTransaction,
+			TransactionIndex: proof.TransactionIndex,
+			Siblings:         domainHashesToProto(proof.Siblings),
+		}
+	}
+	x.UtxoExistence = &UTXOExistenceMessage{
+		VirtualSelectedParent: domainHashToProto(msgUTXOExistence.VirtualSelectedParent),
+		ExistenceBits:         msgUTXOExistence.ExistenceBits,
+		Entries:               entries,
+		Proofs:                proofs,
+	}
+	return nil
+}
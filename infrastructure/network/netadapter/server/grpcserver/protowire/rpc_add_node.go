@@ -0,0 +1,63 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_AddNodeRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_AddNodeRequest is nil")
+	}
+	return x.AddNodeRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_AddNodeRequest) fromAppMessage(message *appmessage.AddNodeRequestMessage) error {
+	x.AddNodeRequest = &AddNodeRequestMessage{
+		Address: message.Address,
+		Command: message.NodeCommand,
+	}
+	return nil
+}
+
+func (x *AddNodeRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "AddNodeRequestMessage is nil")
+	}
+	return &appmessage.AddNodeRequestMessage{
+		Address:     x.Address,
+		NodeCommand: x.Command,
+	}, nil
+}
+
+func (x *KaspadMessage_AddNodeResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_AddNodeResponse is nil")
+	}
+	return x.AddNodeResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_AddNodeResponse) fromAppMessage(message *appmessage.AddNodeResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.AddNodeResponse = &AddNodeResponseMessage{
+		Error: err,
+	}
+	return nil
+}
+
+func (x *AddNodeResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "AddNodeResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.AddNodeResponseMessage{
+		Error: rpcErr,
+	}, nil
+}
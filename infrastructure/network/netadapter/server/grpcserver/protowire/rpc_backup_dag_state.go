@@ -0,0 +1,61 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_BackupDagStateRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_BackupDagStateRequest is nil")
+	}
+	return x.BackupDagStateRequest.toAppMessage()
+}
+
+func (x *BackupDagStateRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "BackupDagStateRequestMessage is nil")
+	}
+	return &appmessage.BackupDagStateRequestMessage{
+		Path: x.Path,
+	}, nil
+}
+
+func (x *KaspadMessage_BackupDagStateRequest) fromAppMessage(message *appmessage.BackupDagStateRequestMessage) error {
+	x.BackupDagStateRequest = &BackupDagStateRequestMessage{Path: message.Path}
+	return nil
+}
+
+func (x *KaspadMessage_BackupDagStateResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_BackupDagStateResponse is nil")
+	}
+	return x.BackupDagStateResponse.toAppMessage()
+}
+
+func (x *BackupDagStateResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "BackupDagStateResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.BackupDagStateResponseMessage{
+		BackupPath: x.BackupPath,
+		Error:      rpcErr,
+	}, nil
+}
+
+func (x *KaspadMessage_BackupDagStateResponse) fromAppMessage(message *appmessage.BackupDagStateResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.BackupDagStateResponse = &BackupDagStateResponseMessage{
+		BackupPath: message.BackupPath,
+		Error:      err,
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_CompactDbRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_CompactDbRequest is nil")
+	}
+	return &appmessage.CompactDbRequestMessage{}, nil
+}
+
+func (x *KaspadMessage_CompactDbRequest) fromAppMessage(_ *appmessage.CompactDbRequestMessage) error {
+	x.CompactDbRequest = &CompactDbRequestMessage{}
+	return nil
+}
+
+func (x *KaspadMessage_CompactDbResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_CompactDbResponse is nil")
+	}
+	return x.CompactDbResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_CompactDbResponse) fromAppMessage(message *appmessage.CompactDbResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.CompactDbResponse = &CompactDbResponseMessage{
+		Error: err,
+	}
+	return nil
+}
+
+func (x *CompactDbResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "CompactDbResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.CompactDbResponseMessage{
+		Error: rpcErr,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_DebugLevelRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_DebugLevelRequest is nil")
+	}
+	return x.DebugLevelRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_DebugLevelRequest) fromAppMessage(message *appmessage.DebugLevelRequestMessage) error {
+	x.DebugLevelRequest = &DebugLevelRequestMessage{
+		LevelSpec: message.LevelSpec,
+	}
+	return nil
+}
+
+func (x *DebugLevelRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "DebugLevelRequestMessage is nil")
+	}
+	return &appmessage.DebugLevelRequestMessage{
+		LevelSpec: x.LevelSpec,
+	}, nil
+}
+
+func (x *KaspadMessage_DebugLevelResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_DebugLevelResponse is nil")
+	}
+	return x.DebugLevelResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_DebugLevelResponse) fromAppMessage(message *appmessage.DebugLevelResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	subsystems := make([]*SubsystemLogLevel, len(message.Subsystems))
+	for i, subsystem := range message.Subsystems {
+		subsystems[i] = &SubsystemLogLevel{
+			Subsystem: subsystem.Subsystem,
+			Level:     subsystem.Level,
+		}
+	}
+	x.DebugLevelResponse = &DebugLevelResponseMessage{
+		Subsystems: subsystems,
+		Error:      err,
+	}
+	return nil
+}
+
+func (x *DebugLevelResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "DebugLevelResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	subsystems := make([]*appmessage.SubsystemLogLevel, len(x.Subsystems))
+	for i, subsystem := range x.Subsystems {
+		appSubsystem, err := subsystem.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		subsystems[i] = appSubsystem
+	}
+	return &appmessage.DebugLevelResponseMessage{
+		Subsystems: subsystems,
+		Error:      rpcErr,
+	}, nil
+}
+
+func (x *SubsystemLogLevel) toAppMessage() (*appmessage.SubsystemLogLevel, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SubsystemLogLevel is nil")
+	}
+	return &appmessage.SubsystemLogLevel{
+		Subsystem: x.Subsystem,
+		Level:     x.Level,
+	}, nil
+}
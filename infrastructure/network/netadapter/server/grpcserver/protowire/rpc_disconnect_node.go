@@ -0,0 +1,61 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_DisconnectNodeRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_DisconnectNodeRequest is nil")
+	}
+	return x.DisconnectNodeRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_DisconnectNodeRequest) fromAppMessage(message *appmessage.DisconnectNodeRequestMessage) error {
+	x.DisconnectNodeRequest = &DisconnectNodeRequestMessage{
+		Address: message.Address,
+	}
+	return nil
+}
+
+func (x *DisconnectNodeRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "DisconnectNodeRequestMessage is nil")
+	}
+	return &appmessage.DisconnectNodeRequestMessage{
+		Address: x.Address,
+	}, nil
+}
+
+func (x *KaspadMessage_DisconnectNodeResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_DisconnectNodeResponse is nil")
+	}
+	return x.DisconnectNodeResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_DisconnectNodeResponse) fromAppMessage(message *appmessage.DisconnectNodeResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.DisconnectNodeResponse = &DisconnectNodeResponseMessage{
+		Error: err,
+	}
+	return nil
+}
+
+func (x *DisconnectNodeResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "DisconnectNodeResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.DisconnectNodeResponseMessage{
+		Error: rpcErr,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetAddedNodeInfoRequest) toAppMessage() (appmessage.Message, error) {
+	return &appmessage.GetAddedNodeInfoRequestMessage{}, nil
+}
+
+func (x *KaspadMessage_GetAddedNodeInfoRequest) fromAppMessage(_ *appmessage.GetAddedNodeInfoRequestMessage) error {
+	x.GetAddedNodeInfoRequest = &GetAddedNodeInfoRequestMessage{}
+	return nil
+}
+
+func (x *KaspadMessage_GetAddedNodeInfoResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetAddedNodeInfoResponse is nil")
+	}
+	return x.GetAddedNodeInfoResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetAddedNodeInfoResponse) fromAppMessage(message *appmessage.GetAddedNodeInfoResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	infos := make([]*AddedNodeInfo, len(message.Infos))
+	for i, info := range message.Infos {
+		infos[i] = &AddedNodeInfo{
+			Address:     info.Address,
+			IsConnected: info.IsConnected,
+		}
+	}
+	x.GetAddedNodeInfoResponse = &GetAddedNodeInfoResponseMessage{
+		Infos: infos,
+		Error: err,
+	}
+	return nil
+}
+
+func (x *GetAddedNodeInfoResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetAddedNodeInfoResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	infos := make([]*appmessage.AddedNodeInfo, len(x.Infos))
+	for i, info := range x.Infos {
+		appInfo, err := info.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		infos[i] = appInfo
+	}
+	return &appmessage.GetAddedNodeInfoResponseMessage{
+		Infos: infos,
+		Error: rpcErr,
+	}, nil
+}
+
+func (x *AddedNodeInfo) toAppMessage() (*appmessage.AddedNodeInfo, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "AddedNodeInfo is nil")
+	}
+	return &appmessage.AddedNodeInfo{
+		Address:     x.Address,
+		IsConnected: x.IsConnected,
+	}, nil
+}
@@ -46,6 +46,9 @@ func (x *GetBlockDagInfoResponseMessage) toAppMessage() (appmessage.Message, err
 		PastMedianTime:      x.PastMedianTime,
 		PruningPointHash:    x.PruningPointHash,
 		VirtualDAAScore:     x.VirtualDaaScore,
+		VirtualBlueScore:    x.VirtualBlueScore,
+		UTXOCommitment:      x.UtxoCommitment,
+		SyncProgress:        x.SyncProgress,
 		Error:               rpcErr,
 	}, nil
 }
@@ -65,6 +68,9 @@ func (x *KaspadMessage_GetBlockDagInfoResponse) fromAppMessage(message *appmessa
 		PastMedianTime:      message.PastMedianTime,
 		PruningPointHash:    message.PruningPointHash,
 		VirtualDaaScore:     message.VirtualDAAScore,
+		VirtualBlueScore:    message.VirtualBlueScore,
+		UtxoCommitment:      message.UTXOCommitment,
+		SyncProgress:        message.SyncProgress,
 		Error:               err,
 	}
 	return nil
@@ -0,0 +1,72 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetBlockDagRelationsRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetBlockDagRelationsRequest is nil")
+	}
+	return x.GetBlockDagRelationsRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_GetBlockDagRelationsRequest) fromAppMessage(message *appmessage.GetBlockDAGRelationsRequestMessage) error {
+	x.GetBlockDagRelationsRequest = &GetBlockDagRelationsRequestMessage{
+		Hash:    message.Hash,
+		LowHash: message.LowHash,
+	}
+	return nil
+}
+
+func (x *GetBlockDagRelationsRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetBlockDagRelationsRequestMessage is nil")
+	}
+	return &appmessage.GetBlockDAGRelationsRequestMessage{
+		Hash:    x.Hash,
+		LowHash: x.LowHash,
+	}, nil
+}
+
+func (x *KaspadMessage_GetBlockDagRelationsResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetBlockDagRelationsResponse is nil")
+	}
+	return x.GetBlockDagRelationsResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetBlockDagRelationsResponse) fromAppMessage(message *appmessage.GetBlockDAGRelationsResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.GetBlockDagRelationsResponse = &GetBlockDagRelationsResponseMessage{
+		AnticoneHashes:     message.AnticoneHashes,
+		BlueHashes:         message.BlueHashes,
+		BlueHashesHighHash: message.BlueHashesHighHash,
+		Error:              err,
+	}
+	return nil
+}
+
+func (x *GetBlockDagRelationsResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetBlockDagRelationsResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	if rpcErr != nil && (len(x.AnticoneHashes) != 0 || len(x.BlueHashes) != 0 || x.BlueHashesHighHash != "") {
+		return nil, errors.New("GetBlockDagRelationsResponseMessage contains both an error and a response")
+	}
+	return &appmessage.GetBlockDAGRelationsResponseMessage{
+		AnticoneHashes:     x.AnticoneHashes,
+		BlueHashes:         x.BlueHashes,
+		BlueHashesHighHash: x.BlueHashesHighHash,
+		Error:              rpcErr,
+	}, nil
+}
@@ -0,0 +1,122 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetBlockStatsRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetBlockStatsRequest is nil")
+	}
+	return x.GetBlockStatsRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_GetBlockStatsRequest) fromAppMessage(message *appmessage.GetBlockStatsRequestMessage) error {
+	x.GetBlockStatsRequest = &GetBlockStatsRequestMessage{
+		Hash:     message.Hash,
+		HighHash: message.HighHash,
+	}
+	return nil
+}
+
+func (x *GetBlockStatsRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetBlockStatsRequestMessage is nil")
+	}
+	return &appmessage.GetBlockStatsRequestMessage{
+		Hash:     x.Hash,
+		HighHash: x.HighHash,
+	}, nil
+}
+
+func (x *KaspadMessage_GetBlockStatsResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetBlockStatsResponse is nil")
+	}
+	return x.GetBlockStatsResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetBlockStatsResponse) fromAppMessage(message *appmessage.GetBlockStatsResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	blockStats := make([]*RpcBlockStats, len(message.BlockStats))
+	for i, stats := range message.BlockStats {
+		blockStats[i] = &RpcBlockStats{
+			Hash:                    stats.Hash,
+			BlueScore:               stats.BlueScore,
+			Timestamp:               stats.Timestamp,
+			TimeSinceSelectedParent: stats.TimeSinceSelectedParent,
+			ParentCount:             stats.ParentCount,
+			MergeSetBluesCount:      stats.MergeSetBluesCount,
+			MergeSetRedsCount:       stats.MergeSetRedsCount,
+			IsChainBlock:            stats.IsChainBlock,
+			Size:                    stats.Size,
+			Mass:                    stats.Mass,
+			TransactionCount:        stats.TransactionCount,
+			InputCount:              stats.InputCount,
+			OutputCount:             stats.OutputCount,
+			IsAccepted:              stats.IsAccepted,
+			AcceptingBlockHash:      stats.AcceptingBlockHash,
+			AcceptedTxCount:         stats.AcceptedTxCount,
+			TotalFees:               stats.TotalFees,
+			FeeratePercentiles:      stats.FeeratePercentiles,
+		}
+	}
+	x.GetBlockStatsResponse = &GetBlockStatsResponseMessage{
+		BlockStats: blockStats,
+		Error:      err,
+	}
+	return nil
+}
+
+func (x *GetBlockStatsResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetBlockStatsResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	blockStats := make([]*appmessage.RPCBlockStats, len(x.BlockStats))
+	for i, stats := range x.BlockStats {
+		appStats, err := stats.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		blockStats[i] = appStats
+	}
+	return &appmessage.GetBlockStatsResponseMessage{
+		BlockStats: blockStats,
+		Error:      rpcErr,
+	}, nil
+}
+
+func (x *RpcBlockStats) toAppMessage() (*appmessage.RPCBlockStats, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "RpcBlockStats is nil")
+	}
+	return &appmessage.RPCBlockStats{
+		Hash:                    x.Hash,
+		BlueScore:               x.BlueScore,
+		Timestamp:               x.Timestamp,
+		TimeSinceSelectedParent: x.TimeSinceSelectedParent,
+		ParentCount:             x.ParentCount,
+		MergeSetBluesCount:      x.MergeSetBluesCount,
+		MergeSetRedsCount:       x.MergeSetRedsCount,
+		IsChainBlock:            x.IsChainBlock,
+		Size:                    x.Size,
+		Mass:                    x.Mass,
+		TransactionCount:        x.TransactionCount,
+		InputCount:              x.InputCount,
+		OutputCount:             x.OutputCount,
+		IsAccepted:              x.IsAccepted,
+		AcceptingBlockHash:      x.AcceptingBlockHash,
+		AcceptedTxCount:         x.AcceptedTxCount,
+		TotalFees:               x.TotalFees,
+		FeeratePercentiles:      x.FeeratePercentiles,
+	}, nil
+}
@@ -14,8 +14,13 @@ func (x *KaspadMessage_GetBlockTemplateRequest) toAppMessage() (appmessage.Messa
 
 func (x *KaspadMessage_GetBlockTemplateRequest) fromAppMessage(message *appmessage.GetBlockTemplateRequestMessage) error {
 	x.GetBlockTemplateRequest = &GetBlockTemplateRequestMessage{
-		PayAddress: message.PayAddress,
-		ExtraData:  message.ExtraData,
+		PayAddress:             message.PayAddress,
+		ExtraData:              message.ExtraData,
+		LongPollId:             message.LongPollID,
+		Capabilities:           message.Capabilities,
+		ExcludedTransactionIds: message.ExcludedTransactionIDs,
+		IncludedTransactionIds: message.IncludedTransactionIDs,
+		MaxMass:                message.MaxMass,
 	}
 	return nil
 }
@@ -25,8 +30,13 @@ func (x *GetBlockTemplateRequestMessage) toAppMessage() (appmessage.Message, err
 		return nil, errors.Wrapf(errorNil, "GetBlockTemplateRequestMessage is nil")
 	}
 	return &appmessage.GetBlockTemplateRequestMessage{
-		PayAddress: x.PayAddress,
-		ExtraData:  x.ExtraData,
+		PayAddress:             x.PayAddress,
+		ExtraData:              x.ExtraData,
+		LongPollID:             x.LongPollId,
+		Capabilities:           x.Capabilities,
+		ExcludedTransactionIDs: x.ExcludedTransactionIds,
+		IncludedTransactionIDs: x.IncludedTransactionIds,
+		MaxMass:                x.MaxMass,
 	}, nil
 }
 
@@ -54,9 +64,13 @@ func (x *KaspadMessage_GetBlockTemplateResponse) fromAppMessage(message *appmess
 	}
 
 	x.GetBlockTemplateResponse = &GetBlockTemplateResponseMessage{
-		Block:    block,
-		IsSynced: message.IsSynced,
-		Error:    err,
+		Block:                   block,
+		IsSynced:                message.IsSynced,
+		LongPollId:              message.LongPollID,
+		Capabilities:            message.Capabilities,
+		Mutable:                 message.Mutable,
+		CoinbaseAppendMaxLength: message.CoinbaseAppendMaxLength,
+		Error:                   err,
 	}
 	return nil
 }
@@ -82,8 +96,12 @@ func (x *GetBlockTemplateResponseMessage) toAppMessage() (appmessage.Message, er
 		}
 	}
 	return &appmessage.GetBlockTemplateResponseMessage{
-		Block:    msgBlock,
-		IsSynced: x.IsSynced,
-		Error:    rpcError,
+		Block:                   msgBlock,
+		IsSynced:                x.IsSynced,
+		LongPollID:              x.LongPollId,
+		Capabilities:            x.Capabilities,
+		Mutable:                 x.Mutable,
+		CoinbaseAppendMaxLength: x.CoinbaseAppendMaxLength,
+		Error:                   rpcError,
 	}, nil
 }
@@ -37,6 +37,20 @@ func (x *KaspadMessage_GetConnectedPeerInfoResponse) fromAppMessage(message *app
 			AdvertisedProtocolVersion: info.AdvertisedProtocolVersion,
 			TimeConnected:             info.TimeConnected,
 			IsIbdPeer:                 info.IsIBDPeer,
+			BytesSent:                 info.BytesSent,
+			BytesReceived:             info.BytesReceived,
+			BlockBytesServed:          info.BlockBytesServed,
+			TransactionBytesServed:    info.TransactionBytesServed,
+			Permissions:               info.Permissions,
+			IsEncrypted:               info.IsEncrypted,
+			MinPingDuration:           info.MinPingDuration,
+			AveragePingDuration:       info.AveragePingDuration,
+
+			LastBlockAnnouncementTime:       info.LastBlockAnnouncementTime,
+			LastTransactionAnnouncementTime: info.LastTransactionAnnouncementTime,
+
+			SentMessages:     peerMessageCountsFromAppMessage(info.SentMessages),
+			ReceivedMessages: peerMessageCountsFromAppMessage(info.ReceivedMessages),
 		}
 	}
 	x.GetConnectedPeerInfoResponse = &GetConnectedPeerInfoResponseMessage{
@@ -86,7 +100,45 @@ func (x *GetConnectedPeerInfoMessage) toAppMessage() (*appmessage.GetConnectedPe
 		TimeOffset:                x.TimeOffset,
 		UserAgent:                 x.UserAgent,
 		AdvertisedProtocolVersion: x.AdvertisedProtocolVersion,
-		TimeConnected:             x.TimeOffset,
+		TimeConnected:             x.TimeConnected,
 		IsIBDPeer:                 x.IsIbdPeer,
+		BytesSent:                 x.BytesSent,
+		BytesReceived:             x.BytesReceived,
+		BlockBytesServed:          x.BlockBytesServed,
+		TransactionBytesServed:    x.TransactionBytesServed,
+		Permissions:               x.Permissions,
+		IsEncrypted:               x.IsEncrypted,
+		MinPingDuration:           x.MinPingDuration,
+		AveragePingDuration:       x.AveragePingDuration,
+
+		LastBlockAnnouncementTime:       x.LastBlockAnnouncementTime,
+		LastTransactionAnnouncementTime: x.LastTransactionAnnouncementTime,
+
+		SentMessages:     peerMessageCountsToAppMessage(x.SentMessages),
+		ReceivedMessages: peerMessageCountsToAppMessage(x.ReceivedMessages),
 	}, nil
 }
+
+func peerMessageCountsFromAppMessage(counts []*appmessage.PeerMessageCount) []*PeerMessageCount {
+	protoCounts := make([]*PeerMessageCount, len(counts))
+	for i, count := range counts {
+		protoCounts[i] = &PeerMessageCount{
+			Command:  count.Command,
+			Messages: count.Messages,
+			Bytes:    count.Bytes,
+		}
+	}
+	return protoCounts
+}
+
+func peerMessageCountsToAppMessage(protoCounts []*PeerMessageCount) []*appmessage.PeerMessageCount {
+	counts := make([]*appmessage.PeerMessageCount, len(protoCounts))
+	for i, protoCount := range protoCounts {
+		counts[i] = &appmessage.PeerMessageCount{
+			Command:  protoCount.Command,
+			Messages: protoCount.Messages,
+			Bytes:    protoCount.Bytes,
+		}
+	}
+	return counts
+}
@@ -0,0 +1,77 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetDbInfoRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetDbInfoRequest is nil")
+	}
+	return &appmessage.GetDbInfoRequestMessage{}, nil
+}
+
+func (x *KaspadMessage_GetDbInfoRequest) fromAppMessage(_ *appmessage.GetDbInfoRequestMessage) error {
+	x.GetDbInfoRequest = &GetDbInfoRequestMessage{}
+	return nil
+}
+
+func (x *KaspadMessage_GetDbInfoResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetDbInfoResponse is nil")
+	}
+	return x.GetDbInfoResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetDbInfoResponse) fromAppMessage(message *appmessage.GetDbInfoResponseMessage) error {
+	var rpcErr *RPCError
+	if message.Error != nil {
+		rpcErr = &RPCError{Message: message.Error.Message}
+	}
+	buckets := make([]*DbBucketInfo, len(message.Buckets))
+	for i, bucket := range message.Buckets {
+		buckets[i] = &DbBucketInfo{
+			Name:      bucket.Name,
+			SizeBytes: bucket.SizeBytes,
+		}
+	}
+	x.GetDbInfoResponse = &GetDbInfoResponseMessage{
+		DbType:                  message.DbType,
+		SizeBytes:               message.SizeBytes,
+		Buckets:                 buckets,
+		IsCompacting:            message.IsCompacting,
+		LastCompactionTimestamp: message.LastCompactionTimestamp,
+		Error:                   rpcErr,
+	}
+	return nil
+}
+
+func (x *GetDbInfoResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetDbInfoResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	buckets := make([]*appmessage.DbBucketInfo, len(x.Buckets))
+	for i, bucket := range x.Buckets {
+		if bucket == nil {
+			return nil, errors.Wrapf(errorNil, "DbBucketInfo is nil")
+		}
+		buckets[i] = &appmessage.DbBucketInfo{
+			Name:      bucket.Name,
+			SizeBytes: bucket.SizeBytes,
+		}
+	}
+	return &appmessage.GetDbInfoResponseMessage{
+		DbType:                  x.DbType,
+		SizeBytes:               x.SizeBytes,
+		Buckets:                 buckets,
+		IsCompacting:            x.IsCompacting,
+		LastCompactionTimestamp: x.LastCompactionTimestamp,
+		Error:                   rpcErr,
+	}, nil
+}
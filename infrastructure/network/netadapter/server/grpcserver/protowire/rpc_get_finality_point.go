@@ -0,0 +1,56 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetFinalityPointRequest) toAppMessage() (appmessage.Message, error) {
+	return &appmessage.GetFinalityPointRequestMessage{}, nil
+}
+
+func (x *KaspadMessage_GetFinalityPointRequest) fromAppMessage(_ *appmessage.GetFinalityPointRequestMessage) error {
+	x.GetFinalityPointRequest = &GetFinalityPointRequestMessage{}
+	return nil
+}
+
+func (x *KaspadMessage_GetFinalityPointResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetFinalityPointResponse is nil")
+	}
+	return x.GetFinalityPointResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetFinalityPointResponse) fromAppMessage(message *appmessage.GetFinalityPointResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.GetFinalityPointResponse = &GetFinalityPointResponseMessage{
+		FinalityPointHash: message.FinalityPointHash,
+		FinalityDepth:     message.FinalityDepth,
+		Error:             err,
+	}
+	return nil
+}
+
+func (x *GetFinalityPointResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetFinalityPointResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+
+	if rpcErr != nil && len(x.FinalityPointHash) != 0 {
+		return nil, errors.New("GetFinalityPointResponseMessage contains both an error and a response")
+	}
+
+	return &appmessage.GetFinalityPointResponseMessage{
+		FinalityPointHash: x.FinalityPointHash,
+		FinalityDepth:     x.FinalityDepth,
+		Error:             rpcErr,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetMempoolInfoRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetMempoolInfoRequest is nil")
+	}
+	return &appmessage.GetMempoolInfoRequestMessage{}, nil
+}
+
+func (x *KaspadMessage_GetMempoolInfoRequest) fromAppMessage(_ *appmessage.GetMempoolInfoRequestMessage) error {
+	x.GetMempoolInfoRequest = &GetMempoolInfoRequestMessage{}
+	return nil
+}
+
+func (x *KaspadMessage_GetMempoolInfoResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetMempoolInfoResponse is nil")
+	}
+	return x.GetMempoolInfoResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetMempoolInfoResponse) fromAppMessage(message *appmessage.GetMempoolInfoResponseMessage) error {
+	var rpcErr *RPCError
+	if message.Error != nil {
+		rpcErr = &RPCError{Message: message.Error.Message}
+	}
+	x.GetMempoolInfoResponse = &GetMempoolInfoResponseMessage{
+		TransactionCount:           message.TransactionCount,
+		OrphanCount:                message.OrphanCount,
+		MemoryUsage:                message.MemoryUsage,
+		MaximumMemoryUsage:         message.MaximumMemoryUsage,
+		MempoolMinimumFee:          message.MempoolMinimumFee,
+		MinimumRelayTransactionFee: message.MinimumRelayTransactionFee,
+		IncrementalRelayFee:        message.IncrementalRelayFee,
+		OrphanMemoryUsage:          message.OrphanMemoryUsage,
+		MaximumOrphanCount:         message.MaximumOrphanCount,
+		MaximumOrphanMemoryUsage:   message.MaximumOrphanMemoryUsage,
+		MissingOrphanOutpointCount: message.MissingOrphanOutpointCount,
+		OrphanPeerCount:            message.OrphanPeerCount,
+		PromotedOrphanCount:        message.PromotedOrphanCount,
+		EvictedOrphanCount:         message.EvictedOrphanCount,
+		ExpiredOrphanCount:         message.ExpiredOrphanCount,
+		Error:                      rpcErr,
+	}
+	return nil
+}
+
+func (x *GetMempoolInfoResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetMempoolInfoResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.GetMempoolInfoResponseMessage{
+		TransactionCount:           x.TransactionCount,
+		OrphanCount:                x.OrphanCount,
+		MemoryUsage:                x.MemoryUsage,
+		MaximumMemoryUsage:         x.MaximumMemoryUsage,
+		MempoolMinimumFee:          x.MempoolMinimumFee,
+		MinimumRelayTransactionFee: x.MinimumRelayTransactionFee,
+		IncrementalRelayFee:        x.IncrementalRelayFee,
+		OrphanMemoryUsage:          x.OrphanMemoryUsage,
+		MaximumOrphanCount:         x.MaximumOrphanCount,
+		MaximumOrphanMemoryUsage:   x.MaximumOrphanMemoryUsage,
+		MissingOrphanOutpointCount: x.MissingOrphanOutpointCount,
+		OrphanPeerCount:            x.OrphanPeerCount,
+		PromotedOrphanCount:        x.PromotedOrphanCount,
+		EvictedOrphanCount:         x.EvictedOrphanCount,
+		ExpiredOrphanCount:         x.ExpiredOrphanCount,
+		Error:                      rpcErr,
+	}, nil
+}
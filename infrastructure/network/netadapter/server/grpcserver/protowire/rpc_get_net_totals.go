@@ -0,0 +1,85 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetNetTotalsRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetNetTotalsRequest is nil")
+	}
+	return &appmessage.GetNetTotalsRequestMessage{}, nil
+}
+
+func (x *KaspadMessage_GetNetTotalsRequest) fromAppMessage(_ *appmessage.GetNetTotalsRequestMessage) error {
+	x.GetNetTotalsRequest = &GetNetTotalsRequestMessage{}
+	return nil
+}
+
+func (x *KaspadMessage_GetNetTotalsResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetNetTotalsResponse is nil")
+	}
+	return x.GetNetTotalsResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetNetTotalsResponse) fromAppMessage(message *appmessage.GetNetTotalsResponseMessage) error {
+	var rpcErr *RPCError
+	if message.Error != nil {
+		rpcErr = &RPCError{Message: message.Error.Message}
+	}
+	var uploadTarget *UploadTarget
+	if message.UploadTarget != nil {
+		uploadTarget = &UploadTarget{
+			TimeframeSeconds:      message.UploadTarget.TimeframeSeconds,
+			Target:                message.UploadTarget.Target,
+			TargetReached:         message.UploadTarget.TargetReached,
+			ServeHistoricalBlocks: message.UploadTarget.ServeHistoricalBlocks,
+			BytesLeftInCycle:      message.UploadTarget.BytesLeftInCycle,
+			SecondsLeftInCycle:    message.UploadTarget.SecondsLeftInCycle,
+		}
+	}
+	x.GetNetTotalsResponse = &GetNetTotalsResponseMessage{
+		TotalBytesSent:         message.TotalBytesSent,
+		TotalBytesReceived:     message.TotalBytesReceived,
+		BlockBytesServed:       message.BlockBytesServed,
+		TransactionBytesServed: message.TransactionBytesServed,
+		TimeMilliseconds:       message.TimeMilliseconds,
+		UploadTarget:           uploadTarget,
+		Error:                  rpcErr,
+	}
+	return nil
+}
+
+func (x *GetNetTotalsResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetNetTotalsResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	var uploadTarget *appmessage.UploadTarget
+	// UploadTarget is omitted in error responses
+	if x.UploadTarget != nil {
+		uploadTarget = &appmessage.UploadTarget{
+			TimeframeSeconds:      x.UploadTarget.TimeframeSeconds,
+			Target:                x.UploadTarget.Target,
+			TargetReached:         x.UploadTarget.TargetReached,
+			ServeHistoricalBlocks: x.UploadTarget.ServeHistoricalBlocks,
+			BytesLeftInCycle:      x.UploadTarget.BytesLeftInCycle,
+			SecondsLeftInCycle:    x.UploadTarget.SecondsLeftInCycle,
+		}
+	}
+	return &appmessage.GetNetTotalsResponseMessage{
+		TotalBytesSent:         x.TotalBytesSent,
+		TotalBytesReceived:     x.TotalBytesReceived,
+		BlockBytesServed:       x.BlockBytesServed,
+		TransactionBytesServed: x.TransactionBytesServed,
+		TimeMilliseconds:       x.TimeMilliseconds,
+		UploadTarget:           uploadTarget,
+		Error:                  rpcErr,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetNetworkInfoRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetNetworkInfoRequest is nil")
+	}
+	return &appmessage.GetNetworkInfoRequestMessage{}, nil
+}
+
+func (x *KaspadMessage_GetNetworkInfoRequest) fromAppMessage(_ *appmessage.GetNetworkInfoRequestMessage) error {
+	x.GetNetworkInfoRequest = &GetNetworkInfoRequestMessage{}
+	return nil
+}
+
+func (x *KaspadMessage_GetNetworkInfoResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetNetworkInfoResponse is nil")
+	}
+	return x.GetNetworkInfoResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetNetworkInfoResponse) fromAppMessage(message *appmessage.GetNetworkInfoResponseMessage) error {
+	var rpcErr *RPCError
+	if message.Error != nil {
+		rpcErr = &RPCError{Message: message.Error.Message}
+	}
+	localAddresses := make([]*LocalAddress, len(message.LocalAddresses))
+	for i, localAddress := range message.LocalAddresses {
+		localAddresses[i] = &LocalAddress{
+			Address: localAddress.Address,
+			Score:   localAddress.Score,
+		}
+	}
+	networks := make([]*NetworkReachability, len(message.Networks))
+	for i, network := range message.Networks {
+		networks[i] = &NetworkReachability{
+			Name:           network.Name,
+			Reachable:      network.Reachable,
+			Proxy:          network.Proxy,
+			Limited:        network.Limited,
+			SelfTestPassed: network.SelfTestPassed,
+			SelfTestError:  network.SelfTestError,
+		}
+	}
+	x.GetNetworkInfoResponse = &GetNetworkInfoResponseMessage{
+		ProtocolVersion:            message.ProtocolVersion,
+		LocalServices:              message.LocalServices,
+		MinimumRelayTransactionFee: message.MinimumRelayTransactionFee,
+		IncrementalRelayFee:        message.IncrementalRelayFee,
+		LocalAddresses:             localAddresses,
+		Networks:                   networks,
+		InboundConnectionCount:     message.InboundConnectionCount,
+		OutboundConnectionCount:    message.OutboundConnectionCount,
+		Error:                      rpcErr,
+	}
+	return nil
+}
+
+func (x *GetNetworkInfoResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetNetworkInfoResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	localAddresses := make([]*appmessage.LocalAddress, len(x.LocalAddresses))
+	for i, localAddress := range x.LocalAddresses {
+		if localAddress == nil {
+			return nil, errors.Wrapf(errorNil, "LocalAddress is nil")
+		}
+		localAddresses[i] = &appmessage.LocalAddress{
+			Address: localAddress.Address,
+			Score:   localAddress.Score,
+		}
+	}
+	networks := make([]*appmessage.NetworkReachability, len(x.Networks))
+	for i, network := range x.Networks {
+		if network == nil {
+			return nil, errors.Wrapf(errorNil, "NetworkReachability is nil")
+		}
+		networks[i] = &appmessage.NetworkReachability{
+			Name:           network.Name,
+			Reachable:      network.Reachable,
+			Proxy:          network.Proxy,
+			Limited:        network.Limited,
+			SelfTestPassed: network.SelfTestPassed,
+			SelfTestError:  network.SelfTestError,
+		}
+	}
+	return &appmessage.GetNetworkInfoResponseMessage{
+		ProtocolVersion:            x.ProtocolVersion,
+		LocalServices:              x.LocalServices,
+		MinimumRelayTransactionFee: x.MinimumRelayTransactionFee,
+		IncrementalRelayFee:        x.IncrementalRelayFee,
+		LocalAddresses:             localAddresses,
+		Networks:                   networks,
+		InboundConnectionCount:     x.InboundConnectionCount,
+		OutboundConnectionCount:    x.OutboundConnectionCount,
+		Error:                      rpcErr,
+	}, nil
+}
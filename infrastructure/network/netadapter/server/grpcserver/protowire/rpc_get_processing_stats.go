@@ -0,0 +1,84 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetProcessingStatsRequest) toAppMessage() (appmessage.Message, error) {
+	return &appmessage.GetProcessingStatsRequestMessage{}, nil
+}
+
+func (x *KaspadMessage_GetProcessingStatsRequest) fromAppMessage(_ *appmessage.GetProcessingStatsRequestMessage) error {
+	x.GetProcessingStatsRequest = &GetProcessingStatsRequestMessage{}
+	return nil
+}
+
+func (x *KaspadMessage_GetProcessingStatsResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetProcessingStatsResponse is nil")
+	}
+	return x.GetProcessingStatsResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetProcessingStatsResponse) fromAppMessage(message *appmessage.GetProcessingStatsResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	stages := make([]*ProcessingStageStats, len(message.Stages))
+	for i, stage := range message.Stages {
+		stages[i] = &ProcessingStageStats{
+			Stage:               stage.Stage,
+			Count:               stage.Count,
+			AverageMicroseconds: stage.AverageMicroseconds,
+			P50Microseconds:     stage.P50Microseconds,
+			P90Microseconds:     stage.P90Microseconds,
+			P99Microseconds:     stage.P99Microseconds,
+			MaxMicroseconds:     stage.MaxMicroseconds,
+		}
+	}
+	x.GetProcessingStatsResponse = &GetProcessingStatsResponseMessage{
+		Stages: stages,
+		Error:  err,
+	}
+	return nil
+}
+
+func (x *GetProcessingStatsResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetProcessingStatsResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	stages := make([]*appmessage.ProcessingStageStats, len(x.Stages))
+	for i, stage := range x.Stages {
+		appStage, err := stage.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		stages[i] = appStage
+	}
+	return &appmessage.GetProcessingStatsResponseMessage{
+		Stages: stages,
+		Error:  rpcErr,
+	}, nil
+}
+
+func (x *ProcessingStageStats) toAppMessage() (*appmessage.ProcessingStageStats, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "ProcessingStageStats is nil")
+	}
+	return &appmessage.ProcessingStageStats{
+		Stage:               x.Stage,
+		Count:               x.Count,
+		AverageMicroseconds: x.AverageMicroseconds,
+		P50Microseconds:     x.P50Microseconds,
+		P90Microseconds:     x.P90Microseconds,
+		P99Microseconds:     x.P99Microseconds,
+		MaxMicroseconds:     x.MaxMicroseconds,
+	}, nil
+}
@@ -0,0 +1,84 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetRawTransactionRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetRawTransactionRequest is nil")
+	}
+	return x.GetRawTransactionRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_GetRawTransactionRequest) fromAppMessage(message *appmessage.GetRawTransactionRequestMessage) error {
+	x.GetRawTransactionRequest = &GetRawTransactionRequestMessage{
+		TxId: message.TxID,
+	}
+	return nil
+}
+
+func (x *GetRawTransactionRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetRawTransactionRequestMessage is nil")
+	}
+	return &appmessage.GetRawTransactionRequestMessage{
+		TxID: x.TxId,
+	}, nil
+}
+
+func (x *KaspadMessage_GetRawTransactionResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetRawTransactionResponse is nil")
+	}
+	return x.GetRawTransactionResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetRawTransactionResponse) fromAppMessage(message *appmessage.GetRawTransactionResponseMessage) error {
+	var rpcErr *RPCError
+	if message.Error != nil {
+		rpcErr = &RPCError{Message: message.Error.Message}
+	}
+	var transaction *RpcTransaction
+	if message.Transaction != nil {
+		transaction = new(RpcTransaction)
+		transaction.fromAppMessage(message.Transaction)
+	}
+	x.GetRawTransactionResponse = &GetRawTransactionResponseMessage{
+		Transaction:        transaction,
+		IsInMempool:        message.IsInMempool,
+		Confirmations:      message.Confirmations,
+		AcceptingBlockHash: message.AcceptingBlockHash,
+		Error:              rpcErr,
+	}
+	return nil
+}
+
+func (x *GetRawTransactionResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetRawTransactionResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+
+	transaction, err := x.Transaction.toAppMessage()
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+
+	if rpcErr != nil && transaction != nil {
+		return nil, errors.New("GetRawTransactionResponseMessage contains both an error and a response")
+	}
+
+	return &appmessage.GetRawTransactionResponseMessage{
+		Transaction:        transaction,
+		IsInMempool:        x.IsInMempool,
+		Confirmations:      x.Confirmations,
+		AcceptingBlockHash: x.AcceptingBlockHash,
+		Error:              rpcErr,
+	}, nil
+}
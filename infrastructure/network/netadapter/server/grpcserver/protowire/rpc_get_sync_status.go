@@ -0,0 +1,101 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetSyncStatusRequest) toAppMessage() (appmessage.Message, error) {
+	return &appmessage.GetSyncStatusRequestMessage{}, nil
+}
+
+func (x *KaspadMessage_GetSyncStatusRequest) fromAppMessage(_ *appmessage.GetSyncStatusRequestMessage) error {
+	x.GetSyncStatusRequest = &GetSyncStatusRequestMessage{}
+	return nil
+}
+
+func (x *KaspadMessage_GetSyncStatusResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetSyncStatusResponse is nil")
+	}
+	return x.GetSyncStatusResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetSyncStatusResponse) fromAppMessage(message *appmessage.GetSyncStatusResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	trustedPeers := make([]*TrustedPeerSyncStatus, len(message.TrustedPeers))
+	for i, trustedPeer := range message.TrustedPeers {
+		trustedPeers[i] = &TrustedPeerSyncStatus{
+			Address:              trustedPeer.Address,
+			AnnouncedBlockHash:   trustedPeer.AnnouncedBlockHash,
+			AnnouncementTime:     trustedPeer.AnnouncementTime,
+			AgreedChainBlockHash: trustedPeer.AgreedChainBlockHash,
+			IsDiverged:           trustedPeer.IsDiverged,
+		}
+	}
+	x.GetSyncStatusResponse = &GetSyncStatusResponseMessage{
+		IsSynced:              message.IsSynced,
+		IsIbdRunning:          message.IsIBDRunning,
+		IbdPeerAddress:        message.IBDPeerAddress,
+		HeaderCount:           message.HeaderCount,
+		BlockCount:            message.BlockCount,
+		HeadersDaaScore:       message.HeadersDAAScore,
+		BlocksDaaScore:        message.BlocksDAAScore,
+		TargetDaaScore:        message.TargetDAAScore,
+		HeadersProgress:       message.HeadersProgress,
+		BlocksProgress:        message.BlocksProgress,
+		TrustedChainBlockHash: message.TrustedChainBlockHash,
+		TrustedPeers:          trustedPeers,
+		Error:                 err,
+	}
+	return nil
+}
+
+func (x *GetSyncStatusResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetSyncStatusResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+
+	if rpcErr != nil && (x.HeaderCount != 0 || x.BlockCount != 0) {
+		return nil, errors.New("GetSyncStatusResponseMessage contains both an error and a response")
+	}
+
+	trustedPeers := make([]*appmessage.TrustedPeerSyncStatus, len(x.TrustedPeers))
+	for i, trustedPeer := range x.TrustedPeers {
+		trustedPeers[i] = trustedPeer.toAppMessage()
+	}
+
+	return &appmessage.GetSyncStatusResponseMessage{
+		IsSynced:              x.IsSynced,
+		IsIBDRunning:          x.IsIbdRunning,
+		IBDPeerAddress:        x.IbdPeerAddress,
+		HeaderCount:           x.HeaderCount,
+		BlockCount:            x.BlockCount,
+		HeadersDAAScore:       x.HeadersDaaScore,
+		BlocksDAAScore:        x.BlocksDaaScore,
+		TargetDAAScore:        x.TargetDaaScore,
+		HeadersProgress:       x.HeadersProgress,
+		BlocksProgress:        x.BlocksProgress,
+		TrustedChainBlockHash: x.TrustedChainBlockHash,
+		TrustedPeers:          trustedPeers,
+		Error:                 rpcErr,
+	}, nil
+}
+
+func (x *TrustedPeerSyncStatus) toAppMessage() *appmessage.TrustedPeerSyncStatus {
+	return &appmessage.TrustedPeerSyncStatus{
+		Address:              x.Address,
+		AnnouncedBlockHash:   x.AnnouncedBlockHash,
+		AnnouncementTime:     x.AnnouncementTime,
+		AgreedChainBlockHash: x.AgreedChainBlockHash,
+		IsDiverged:           x.IsDiverged,
+	}
+}
@@ -0,0 +1,113 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetTxOutProofRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetTxOutProofRequest is nil")
+	}
+	return x.GetTxOutProofRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_GetTxOutProofRequest) fromAppMessage(message *appmessage.GetTxOutProofRequestMessage) error {
+	x.GetTxOutProofRequest = &GetTxOutProofRequestMessage{
+		TransactionId: message.TransactionID,
+		BlockHash:     message.BlockHash,
+	}
+	return nil
+}
+
+func (x *GetTxOutProofRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetTxOutProofRequestMessage is nil")
+	}
+	return &appmessage.GetTxOutProofRequestMessage{
+		TransactionID: x.TransactionId,
+		BlockHash:     x.BlockHash,
+	}, nil
+}
+
+func (x *KaspadMessage_GetTxOutProofResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetTxOutProofResponse is nil")
+	}
+	return x.GetTxOutProofResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetTxOutProofResponse) fromAppMessage(message *appmessage.GetTxOutProofResponseMessage) error {
+	var rpcErr *RPCError
+	if message.Error != nil {
+		rpcErr = &RPCError{Message: message.Error.Message}
+	}
+	var proof *RpcTransactionMerkleProof
+	if message.Proof != nil {
+		proof = &RpcTransactionMerkleProof{}
+		proof.fromAppMessage(message.Proof)
+	}
+	x.GetTxOutProofResponse = &GetTxOutProofResponseMessage{
+		Proof: proof,
+		Error: rpcErr,
+	}
+	return nil
+}
+
+func (x *GetTxOutProofResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetTxOutProofResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	if rpcErr != nil && x.Proof != nil {
+		return nil, errors.New("GetTxOutProofResponseMessage contains both an error and a response")
+	}
+	var proof *appmessage.RPCTransactionMerkleProof
+	if rpcErr == nil {
+		proof, err = x.Proof.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &appmessage.GetTxOutProofResponseMessage{
+		Proof: proof,
+		Error: rpcErr,
+	}, nil
+}
+
+func (x *RpcTransactionMerkleProof) toAppMessage() (*appmessage.RPCTransactionMerkleProof, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "RpcTransactionMerkleProof is nil")
+	}
+	header, err := x.Header.toAppMessage()
+	if err != nil {
+		return nil, err
+	}
+	transaction, err := x.Transaction.toAppMessage()
+	if err != nil {
+		return nil, err
+	}
+	return &appmessage.RPCTransactionMerkleProof{
+		Header:      header,
+		Transaction: transaction,
+		Index:       x.Index,
+		Siblings:    x.Siblings,
+	}, nil
+}
+
+func (x *RpcTransactionMerkleProof) fromAppMessage(message *appmessage.RPCTransactionMerkleProof) {
+	header := &RpcBlockHeader{}
+	header.fromAppMessage(message.Header)
+	transaction := &RpcTransaction{}
+	transaction.fromAppMessage(message.Transaction)
+	*x = RpcTransactionMerkleProof{
+		Header:      header,
+		Transaction: transaction,
+		Index:       message.Index,
+		Siblings:    message.Siblings,
+	}
+}
@@ -0,0 +1,116 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetUtxoSetInfoRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetUtxoSetInfoRequest is nil")
+	}
+	return x.GetUtxoSetInfoRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_GetUtxoSetInfoRequest) fromAppMessage(message *appmessage.GetUTXOSetInfoRequestMessage) error {
+	x.GetUtxoSetInfoRequest = &GetUtxoSetInfoRequestMessage{
+		Verbose: message.Verbose,
+	}
+	return nil
+}
+
+func (x *GetUtxoSetInfoRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetUtxoSetInfoRequestMessage is nil")
+	}
+	return &appmessage.GetUTXOSetInfoRequestMessage{
+		Verbose: x.Verbose,
+	}, nil
+}
+
+func (x *KaspadMessage_GetUtxoSetInfoResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetUtxoSetInfoResponse is nil")
+	}
+	return x.GetUtxoSetInfoResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetUtxoSetInfoResponse) fromAppMessage(message *appmessage.GetUTXOSetInfoResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.GetUtxoSetInfoResponse = &GetUtxoSetInfoResponseMessage{
+		UtxoCount:              message.UTXOCount,
+		TotalSompi:             message.TotalSompi,
+		ScriptClassStatistics:  utxoSetStatisticsEntriesFromAppMessage(message.ScriptClassStatistics),
+		AmountBucketStatistics: utxoSetStatisticsEntriesFromAppMessage(message.AmountBucketStatistics),
+
+		Error: err,
+	}
+	return nil
+}
+
+func (x *GetUtxoSetInfoResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetUtxoSetInfoResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+
+	scriptClassStatistics, err := utxoSetStatisticsEntriesToAppMessage(x.ScriptClassStatistics)
+	if err != nil {
+		return nil, err
+	}
+	amountBucketStatistics, err := utxoSetStatisticsEntriesToAppMessage(x.AmountBucketStatistics)
+	if err != nil {
+		return nil, err
+	}
+
+	return &appmessage.GetUTXOSetInfoResponseMessage{
+		UTXOCount:              x.UtxoCount,
+		TotalSompi:             x.TotalSompi,
+		ScriptClassStatistics:  scriptClassStatistics,
+		AmountBucketStatistics: amountBucketStatistics,
+
+		Error: rpcErr,
+	}, nil
+}
+
+func (x *UtxoSetStatisticsEntry) toAppMessage() (*appmessage.UTXOSetStatisticsEntry, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "UtxoSetStatisticsEntry is nil")
+	}
+	return &appmessage.UTXOSetStatisticsEntry{
+		Name:  x.Name,
+		Count: x.Count,
+		Sompi: x.Sompi,
+	}, nil
+}
+
+func utxoSetStatisticsEntriesFromAppMessage(entries []*appmessage.UTXOSetStatisticsEntry) []*UtxoSetStatisticsEntry {
+	protoEntries := make([]*UtxoSetStatisticsEntry, len(entries))
+	for i, entry := range entries {
+		protoEntries[i] = &UtxoSetStatisticsEntry{
+			Name:  entry.Name,
+			Count: entry.Count,
+			Sompi: entry.Sompi,
+		}
+	}
+	return protoEntries
+}
+
+func utxoSetStatisticsEntriesToAppMessage(protoEntries []*UtxoSetStatisticsEntry) ([]*appmessage.UTXOSetStatisticsEntry, error) {
+	entries := make([]*appmessage.UTXOSetStatisticsEntry, len(protoEntries))
+	for i, protoEntry := range protoEntries {
+		entry, err := protoEntry.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = entry
+	}
+	return entries, nil
+}
@@ -0,0 +1,61 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_InvalidateBlockRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_InvalidateBlockRequest is nil")
+	}
+	return x.InvalidateBlockRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_InvalidateBlockRequest) fromAppMessage(message *appmessage.InvalidateBlockRequestMessage) error {
+	x.InvalidateBlockRequest = &InvalidateBlockRequestMessage{
+		Hash: message.Hash,
+	}
+	return nil
+}
+
+func (x *InvalidateBlockRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "InvalidateBlockRequestMessage is nil")
+	}
+	return &appmessage.InvalidateBlockRequestMessage{
+		Hash: x.Hash,
+	}, nil
+}
+
+func (x *KaspadMessage_InvalidateBlockResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_InvalidateBlockResponse is nil")
+	}
+	return x.InvalidateBlockResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_InvalidateBlockResponse) fromAppMessage(message *appmessage.InvalidateBlockResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.InvalidateBlockResponse = &InvalidateBlockResponseMessage{
+		Error: err,
+	}
+	return nil
+}
+
+func (x *InvalidateBlockResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "InvalidateBlockResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.InvalidateBlockResponseMessage{
+		Error: rpcErr,
+	}, nil
+}
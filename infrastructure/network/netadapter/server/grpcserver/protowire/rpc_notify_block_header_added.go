@@ -0,0 +1,81 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_NotifyBlockHeaderAddedRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_NotifyBlockHeaderAddedRequest is nil")
+	}
+	return &appmessage.NotifyBlockHeaderAddedRequestMessage{}, nil
+}
+
+func (x *KaspadMessage_NotifyBlockHeaderAddedRequest) fromAppMessage(_ *appmessage.NotifyBlockHeaderAddedRequestMessage) error {
+	x.NotifyBlockHeaderAddedRequest = &NotifyBlockHeaderAddedRequestMessage{}
+	return nil
+}
+
+func (x *KaspadMessage_NotifyBlockHeaderAddedResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_NotifyBlockHeaderAddedResponse is nil")
+	}
+	return x.NotifyBlockHeaderAddedResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_NotifyBlockHeaderAddedResponse) fromAppMessage(message *appmessage.NotifyBlockHeaderAddedResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.NotifyBlockHeaderAddedResponse = &NotifyBlockHeaderAddedResponseMessage{
+		Error: err,
+	}
+	return nil
+}
+
+func (x *NotifyBlockHeaderAddedResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "NotifyBlockHeaderAddedResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.NotifyBlockHeaderAddedResponseMessage{
+		Error: rpcErr,
+	}, nil
+}
+
+func (x *KaspadMessage_BlockHeaderAddedNotification) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_BlockHeaderAddedNotification is nil")
+	}
+	return x.BlockHeaderAddedNotification.toAppMessage()
+}
+
+func (x *KaspadMessage_BlockHeaderAddedNotification) fromAppMessage(message *appmessage.BlockHeaderAddedNotificationMessage) error {
+	header := &RpcBlockHeader{}
+	header.fromAppMessage(message.Header)
+	x.BlockHeaderAddedNotification = &BlockHeaderAddedNotificationMessage{
+		Hash:   message.Hash,
+		Header: header,
+	}
+	return nil
+}
+
+func (x *BlockHeaderAddedNotificationMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "BlockHeaderAddedNotificationMessage is nil")
+	}
+	header, err := x.Header.toAppMessage()
+	if err != nil {
+		return nil, err
+	}
+	return &appmessage.BlockHeaderAddedNotificationMessage{
+		Hash:   x.Hash,
+		Header: header,
+	}, nil
+}
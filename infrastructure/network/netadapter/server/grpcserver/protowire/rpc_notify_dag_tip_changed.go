@@ -0,0 +1,89 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_NotifyDagTipChangedRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_NotifyDagTipChangedRequest is nil")
+	}
+	return &appmessage.NotifyDAGTipChangedRequestMessage{}, nil
+}
+
+func (x *KaspadMessage_NotifyDagTipChangedRequest) fromAppMessage(_ *appmessage.NotifyDAGTipChangedRequestMessage) error {
+	x.NotifyDagTipChangedRequest = &NotifyDagTipChangedRequestMessage{}
+	return nil
+}
+
+func (x *KaspadMessage_NotifyDagTipChangedResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_NotifyDagTipChangedResponse is nil")
+	}
+	return x.NotifyDagTipChangedResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_NotifyDagTipChangedResponse) fromAppMessage(message *appmessage.NotifyDAGTipChangedResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.NotifyDagTipChangedResponse = &NotifyDagTipChangedResponseMessage{
+		Error: err,
+	}
+	return nil
+}
+
+func (x *NotifyDagTipChangedResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "NotifyDagTipChangedResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.NotifyDAGTipChangedResponseMessage{
+		Error: rpcErr,
+	}, nil
+}
+
+func (x *KaspadMessage_DagTipChangedNotification) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_DagTipChangedNotification is nil")
+	}
+	return x.DagTipChangedNotification.toAppMessage()
+}
+
+func (x *KaspadMessage_DagTipChangedNotification) fromAppMessage(message *appmessage.DAGTipChangedNotificationMessage) error {
+	x.DagTipChangedNotification = &DagTipChangedNotificationMessage{
+		TipHashes:               message.TipHashes,
+		RemovedChainBlockHashes: message.RemovedChainBlockHashes,
+		AddedChainBlockHashes:   message.AddedChainBlockHashes,
+		AcceptedTransactionIds:  make([]*AcceptedTransactionIds, len(message.AcceptedTransactionIDs)),
+	}
+
+	for i, acceptedTransactionIDs := range message.AcceptedTransactionIDs {
+		x.DagTipChangedNotification.AcceptedTransactionIds[i] = &AcceptedTransactionIds{}
+		x.DagTipChangedNotification.AcceptedTransactionIds[i].fromAppMessage(acceptedTransactionIDs)
+	}
+	return nil
+}
+
+func (x *DagTipChangedNotificationMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "DagTipChangedNotificationMessage is nil")
+	}
+	message := &appmessage.DAGTipChangedNotificationMessage{
+		TipHashes:               x.TipHashes,
+		RemovedChainBlockHashes: x.RemovedChainBlockHashes,
+		AddedChainBlockHashes:   x.AddedChainBlockHashes,
+		AcceptedTransactionIDs:  make([]*appmessage.AcceptedTransactionIDs, len(x.AcceptedTransactionIds)),
+	}
+
+	for i, acceptedTransactionIds := range x.AcceptedTransactionIds {
+		message.AcceptedTransactionIDs[i] = acceptedTransactionIds.toAppMessage()
+	}
+	return message, nil
+}
@@ -62,15 +62,20 @@ func (x *KaspadMessage_VirtualSelectedParentChainChangedNotification) toAppMessa
 
 func (x *KaspadMessage_VirtualSelectedParentChainChangedNotification) fromAppMessage(message *appmessage.VirtualSelectedParentChainChangedNotificationMessage) error {
 	x.VirtualSelectedParentChainChangedNotification = &VirtualSelectedParentChainChangedNotificationMessage{
-		RemovedChainBlockHashes: message.RemovedChainBlockHashes,
-		AddedChainBlockHashes:   message.AddedChainBlockHashes,
-		AcceptedTransactionIds:  make([]*AcceptedTransactionIds, len(message.AcceptedTransactionIDs)),
+		RemovedChainBlockHashes:  message.RemovedChainBlockHashes,
+		AddedChainBlockHashes:    message.AddedChainBlockHashes,
+		AcceptedTransactionIds:   make([]*AcceptedTransactionIds, len(message.AcceptedTransactionIDs)),
+		UnacceptedTransactionIds: make([]*AcceptedTransactionIds, len(message.UnacceptedTransactionIDs)),
 	}
 
 	for i, acceptedTransactionIDs := range message.AcceptedTransactionIDs {
 		x.VirtualSelectedParentChainChangedNotification.AcceptedTransactionIds[i] = &AcceptedTransactionIds{}
 		x.VirtualSelectedParentChainChangedNotification.AcceptedTransactionIds[i].fromAppMessage(acceptedTransactionIDs)
 	}
+	for i, unacceptedTransactionIDs := range message.UnacceptedTransactionIDs {
+		x.VirtualSelectedParentChainChangedNotification.UnacceptedTransactionIds[i] = &AcceptedTransactionIds{}
+		x.VirtualSelectedParentChainChangedNotification.UnacceptedTransactionIds[i].fromAppMessage(unacceptedTransactionIDs)
+	}
 	return nil
 }
 
@@ -79,13 +84,17 @@ func (x *VirtualSelectedParentChainChangedNotificationMessage) toAppMessage() (a
 		return nil, errors.Wrapf(errorNil, "VirtualSelectedParentChainChangedNotificationMessage is nil")
 	}
 	message := &appmessage.VirtualSelectedParentChainChangedNotificationMessage{
-		RemovedChainBlockHashes: x.RemovedChainBlockHashes,
-		AddedChainBlockHashes:   x.AddedChainBlockHashes,
-		AcceptedTransactionIDs:  make([]*appmessage.AcceptedTransactionIDs, len(x.AcceptedTransactionIds)),
+		RemovedChainBlockHashes:  x.RemovedChainBlockHashes,
+		AddedChainBlockHashes:    x.AddedChainBlockHashes,
+		AcceptedTransactionIDs:   make([]*appmessage.AcceptedTransactionIDs, len(x.AcceptedTransactionIds)),
+		UnacceptedTransactionIDs: make([]*appmessage.AcceptedTransactionIDs, len(x.UnacceptedTransactionIds)),
 	}
 
 	for i, acceptedTransactionIds := range x.AcceptedTransactionIds {
 		message.AcceptedTransactionIDs[i] = acceptedTransactionIds.toAppMessage()
 	}
+	for i, unacceptedTransactionIds := range x.UnacceptedTransactionIds {
+		message.UnacceptedTransactionIDs[i] = unacceptedTransactionIds.toAppMessage()
+	}
 	return message, nil
 }
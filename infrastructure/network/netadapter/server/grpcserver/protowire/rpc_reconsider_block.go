@@ -0,0 +1,61 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_ReconsiderBlockRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_ReconsiderBlockRequest is nil")
+	}
+	return x.ReconsiderBlockRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_ReconsiderBlockRequest) fromAppMessage(message *appmessage.ReconsiderBlockRequestMessage) error {
+	x.ReconsiderBlockRequest = &ReconsiderBlockRequestMessage{
+		Hash: message.Hash,
+	}
+	return nil
+}
+
+func (x *ReconsiderBlockRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "ReconsiderBlockRequestMessage is nil")
+	}
+	return &appmessage.ReconsiderBlockRequestMessage{
+		Hash: x.Hash,
+	}, nil
+}
+
+func (x *KaspadMessage_ReconsiderBlockResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_ReconsiderBlockResponse is nil")
+	}
+	return x.ReconsiderBlockResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_ReconsiderBlockResponse) fromAppMessage(message *appmessage.ReconsiderBlockResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.ReconsiderBlockResponse = &ReconsiderBlockResponseMessage{
+		Error: err,
+	}
+	return nil
+}
+
+func (x *ReconsiderBlockResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "ReconsiderBlockResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.ReconsiderBlockResponseMessage{
+		Error: rpcErr,
+	}, nil
+}
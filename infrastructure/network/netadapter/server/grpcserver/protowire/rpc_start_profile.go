@@ -0,0 +1,63 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_StartProfileRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_StartProfileRequest is nil")
+	}
+	return x.StartProfileRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_StartProfileRequest) fromAppMessage(message *appmessage.StartProfileRequestMessage) error {
+	x.StartProfileRequest = &StartProfileRequestMessage{
+		ProfileType: message.ProfileType,
+	}
+	return nil
+}
+
+func (x *StartProfileRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "StartProfileRequestMessage is nil")
+	}
+	return &appmessage.StartProfileRequestMessage{
+		ProfileType: x.ProfileType,
+	}, nil
+}
+
+func (x *KaspadMessage_StartProfileResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_StartProfileResponse is nil")
+	}
+	return x.StartProfileResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_StartProfileResponse) fromAppMessage(message *appmessage.StartProfileResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.StartProfileResponse = &StartProfileResponseMessage{
+		FilePath: message.FilePath,
+		Error:    err,
+	}
+	return nil
+}
+
+func (x *StartProfileResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "StartProfileResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.StartProfileResponseMessage{
+		FilePath: x.FilePath,
+		Error:    rpcErr,
+	}, nil
+}
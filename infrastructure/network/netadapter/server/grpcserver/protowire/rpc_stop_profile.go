@@ -0,0 +1,65 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_StopProfileRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_StopProfileRequest is nil")
+	}
+	return x.StopProfileRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_StopProfileRequest) fromAppMessage(message *appmessage.StopProfileRequestMessage) error {
+	x.StopProfileRequest = &StopProfileRequestMessage{
+		ProfileType: message.ProfileType,
+	}
+	return nil
+}
+
+func (x *StopProfileRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "StopProfileRequestMessage is nil")
+	}
+	return &appmessage.StopProfileRequestMessage{
+		ProfileType: x.ProfileType,
+	}, nil
+}
+
+func (x *KaspadMessage_StopProfileResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_StopProfileResponse is nil")
+	}
+	return x.StopProfileResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_StopProfileResponse) fromAppMessage(message *appmessage.StopProfileResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.StopProfileResponse = &StopProfileResponseMessage{
+		FilePath:             message.FilePath,
+		DurationMilliseconds: message.DurationMilliseconds,
+		Error:                err,
+	}
+	return nil
+}
+
+func (x *StopProfileResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "StopProfileResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.StopProfileResponseMessage{
+		FilePath:             x.FilePath,
+		DurationMilliseconds: x.DurationMilliseconds,
+		Error:                rpcErr,
+	}, nil
+}
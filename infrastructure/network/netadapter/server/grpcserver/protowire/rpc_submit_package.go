@@ -0,0 +1,76 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_SubmitPackageRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_SubmitPackageRequest is nil")
+	}
+	return x.SubmitPackageRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_SubmitPackageRequest) fromAppMessage(message *appmessage.SubmitPackageRequestMessage) error {
+	transactions := make([]*RpcTransaction, len(message.Transactions))
+	for i, transaction := range message.Transactions {
+		transactions[i] = &RpcTransaction{}
+		transactions[i].fromAppMessage(transaction)
+	}
+	x.SubmitPackageRequest = &SubmitPackageRequestMessage{
+		Transactions: transactions,
+	}
+	return nil
+}
+
+func (x *SubmitPackageRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SubmitPackageRequestMessage is nil")
+	}
+	transactions := make([]*appmessage.RPCTransaction, len(x.Transactions))
+	for i, transaction := range x.Transactions {
+		appTransaction, err := transaction.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		transactions[i] = appTransaction
+	}
+	return &appmessage.SubmitPackageRequestMessage{
+		Transactions: transactions,
+	}, nil
+}
+
+func (x *KaspadMessage_SubmitPackageResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_SubmitPackageResponse is nil")
+	}
+	return x.SubmitPackageResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_SubmitPackageResponse) fromAppMessage(message *appmessage.SubmitPackageResponseMessage) error {
+	var rpcErr *RPCError
+	if message.Error != nil {
+		rpcErr = &RPCError{Message: message.Error.Message}
+	}
+	x.SubmitPackageResponse = &SubmitPackageResponseMessage{
+		TransactionIds: message.TransactionIDs,
+		Error:          rpcErr,
+	}
+	return nil
+}
+
+func (x *SubmitPackageResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SubmitPackageResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.SubmitPackageResponseMessage{
+		TransactionIDs: x.TransactionIds,
+		Error:          rpcErr,
+	}, nil
+}
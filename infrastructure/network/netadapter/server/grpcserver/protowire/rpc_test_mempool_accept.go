@@ -0,0 +1,99 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_TestMempoolAcceptRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_TestMempoolAcceptRequest is nil")
+	}
+	return x.TestMempoolAcceptRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_TestMempoolAcceptRequest) fromAppMessage(message *appmessage.TestMempoolAcceptRequestMessage) error {
+	transactions := make([]*RpcTransaction, len(message.Transactions))
+	for i, transaction := range message.Transactions {
+		transactions[i] = &RpcTransaction{}
+		transactions[i].fromAppMessage(transaction)
+	}
+	x.TestMempoolAcceptRequest = &TestMempoolAcceptRequestMessage{
+		Transactions: transactions,
+	}
+	return nil
+}
+
+func (x *TestMempoolAcceptRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "TestMempoolAcceptRequestMessage is nil")
+	}
+	transactions := make([]*appmessage.RPCTransaction, len(x.Transactions))
+	for i, transaction := range x.Transactions {
+		appTransaction, err := transaction.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		transactions[i] = appTransaction
+	}
+	return &appmessage.TestMempoolAcceptRequestMessage{
+		Transactions: transactions,
+	}, nil
+}
+
+func (x *KaspadMessage_TestMempoolAcceptResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_TestMempoolAcceptResponse is nil")
+	}
+	return x.TestMempoolAcceptResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_TestMempoolAcceptResponse) fromAppMessage(message *appmessage.TestMempoolAcceptResponseMessage) error {
+	var rpcErr *RPCError
+	if message.Error != nil {
+		rpcErr = &RPCError{Message: message.Error.Message}
+	}
+	results := make([]*TestMempoolAcceptResult, len(message.Results))
+	for i, result := range message.Results {
+		results[i] = &TestMempoolAcceptResult{
+			TransactionId: result.TransactionID,
+			Allowed:       result.Allowed,
+			RejectReason:  result.RejectReason,
+			Fee:           result.Fee,
+			Mass:          result.Mass,
+		}
+	}
+	x.TestMempoolAcceptResponse = &TestMempoolAcceptResponseMessage{
+		Results: results,
+		Error:   rpcErr,
+	}
+	return nil
+}
+
+func (x *TestMempoolAcceptResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "TestMempoolAcceptResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	results := make([]*appmessage.TestMempoolAcceptResult, len(x.Results))
+	for i, result := range x.Results {
+		if result == nil {
+			return nil, errors.Wrapf(errorNil, "TestMempoolAcceptResult is nil")
+		}
+		results[i] = &appmessage.TestMempoolAcceptResult{
+			TransactionID: result.TransactionId,
+			Allowed:       result.Allowed,
+			RejectReason:  result.RejectReason,
+			Fee:           result.Fee,
+			Mass:          result.Mass,
+		}
+	}
+	return &appmessage.TestMempoolAcceptResponseMessage{
+		Results: results,
+		Error:   rpcErr,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_VerifyTxOutProofRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_VerifyTxOutProofRequest is nil")
+	}
+	return x.VerifyTxOutProofRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_VerifyTxOutProofRequest) fromAppMessage(message *appmessage.VerifyTxOutProofRequestMessage) error {
+	proof := &RpcTransactionMerkleProof{}
+	proof.fromAppMessage(message.Proof)
+	x.VerifyTxOutProofRequest = &VerifyTxOutProofRequestMessage{
+		Proof: proof,
+	}
+	return nil
+}
+
+func (x *VerifyTxOutProofRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "VerifyTxOutProofRequestMessage is nil")
+	}
+	proof, err := x.Proof.toAppMessage()
+	if err != nil {
+		return nil, err
+	}
+	return &appmessage.VerifyTxOutProofRequestMessage{
+		Proof: proof,
+	}, nil
+}
+
+func (x *KaspadMessage_VerifyTxOutProofResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_VerifyTxOutProofResponse is nil")
+	}
+	return x.VerifyTxOutProofResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_VerifyTxOutProofResponse) fromAppMessage(message *appmessage.VerifyTxOutProofResponseMessage) error {
+	var rpcErr *RPCError
+	if message.Error != nil {
+		rpcErr = &RPCError{Message: message.Error.Message}
+	}
+	x.VerifyTxOutProofResponse = &VerifyTxOutProofResponseMessage{
+		TransactionId: message.TransactionID,
+		BlockHash:     message.BlockHash,
+		Error:         rpcErr,
+	}
+	return nil
+}
+
+func (x *VerifyTxOutProofResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "VerifyTxOutProofResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.VerifyTxOutProofResponseMessage{
+		TransactionID: x.TransactionId,
+		BlockHash:     x.BlockHash,
+		Error:         rpcErr,
+	}, nil
+}
@@ -359,6 +359,139 @@ func toP2PPayload(message appmessage.Message) (isKaspadMessage_Payload, error) {
 			return nil, err
 		}
 		return payload, nil
+	case *appmessage.MsgStemTransaction:
+		payload := new(KaspadMessage_StemTransaction)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgFilterLoad:
+		payload := new(KaspadMessage_FilterLoad)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgFilterAdd:
+		payload := new(KaspadMessage_FilterAdd)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgFilterClear:
+		payload := new(KaspadMessage_FilterClear)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgRequestMerkleBlocks:
+		payload := new(KaspadMessage_RequestMerkleBlocks)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgMerkleBlock:
+		payload := new(KaspadMessage_MerkleBlock)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgGetCFilters:
+		payload := new(KaspadMessage_GetCFilters)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgCFilter:
+		payload := new(KaspadMessage_Cfilter)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgGetCFHeaders:
+		payload := new(KaspadMessage_GetCFHeaders)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgCFHeaders:
+		payload := new(KaspadMessage_CfHeaders)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgGetCFCheckpt:
+		payload := new(KaspadMessage_GetCFCheckpt)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgCFCheckpt:
+		payload := new(KaspadMessage_CfCheckpt)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgRequestAntichainLocator:
+		payload := new(KaspadMessage_RequestAntichainLocator)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgAntichainLocator:
+		payload := new(KaspadMessage_AntichainLocator)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgInvPackage:
+		payload := new(KaspadMessage_InvPackage)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgRequestPackage:
+		payload := new(KaspadMessage_RequestPackage)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgPackage:
+		payload := new(KaspadMessage_TransactionPackage)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgRequestUTXOExistence:
+		payload := new(KaspadMessage_RequestUtxoExistence)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgUTXOExistence:
+		payload := new(KaspadMessage_UtxoExistence)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
 	default:
 		return nil, nil
 	}
@@ -989,6 +1122,398 @@ func toRPCPayload(message appmessage.Message) (isKaspadMessage_Payload, error) {
 			return nil, err
 		}
 		return payload, nil
+	case *appmessage.GetUTXOSetInfoRequestMessage:
+		payload := new(KaspadMessage_GetUtxoSetInfoRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetUTXOSetInfoResponseMessage:
+		payload := new(KaspadMessage_GetUtxoSetInfoResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.NotifyDAGTipChangedRequestMessage:
+		payload := new(KaspadMessage_NotifyDagTipChangedRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.NotifyDAGTipChangedResponseMessage:
+		payload := new(KaspadMessage_NotifyDagTipChangedResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.DAGTipChangedNotificationMessage:
+		payload := new(KaspadMessage_DagTipChangedNotification)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetRawTransactionRequestMessage:
+		payload := new(KaspadMessage_GetRawTransactionRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetRawTransactionResponseMessage:
+		payload := new(KaspadMessage_GetRawTransactionResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetNetworkInfoRequestMessage:
+		payload := new(KaspadMessage_GetNetworkInfoRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetNetworkInfoResponseMessage:
+		payload := new(KaspadMessage_GetNetworkInfoResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.CompactDbRequestMessage:
+		payload := new(KaspadMessage_CompactDbRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.CompactDbResponseMessage:
+		payload := new(KaspadMessage_CompactDbResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetDbInfoRequestMessage:
+		payload := new(KaspadMessage_GetDbInfoRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetDbInfoResponseMessage:
+		payload := new(KaspadMessage_GetDbInfoResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.BackupDagStateRequestMessage:
+		payload := new(KaspadMessage_BackupDagStateRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.BackupDagStateResponseMessage:
+		payload := new(KaspadMessage_BackupDagStateResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetBlockDAGRelationsRequestMessage:
+		payload := new(KaspadMessage_GetBlockDagRelationsRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetBlockDAGRelationsResponseMessage:
+		payload := new(KaspadMessage_GetBlockDagRelationsResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetFinalityPointRequestMessage:
+		payload := new(KaspadMessage_GetFinalityPointRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetFinalityPointResponseMessage:
+		payload := new(KaspadMessage_GetFinalityPointResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetSyncStatusRequestMessage:
+		payload := new(KaspadMessage_GetSyncStatusRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetSyncStatusResponseMessage:
+		payload := new(KaspadMessage_GetSyncStatusResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetMempoolInfoRequestMessage:
+		payload := new(KaspadMessage_GetMempoolInfoRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetMempoolInfoResponseMessage:
+		payload := new(KaspadMessage_GetMempoolInfoResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.TestMempoolAcceptRequestMessage:
+		payload := new(KaspadMessage_TestMempoolAcceptRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.TestMempoolAcceptResponseMessage:
+		payload := new(KaspadMessage_TestMempoolAcceptResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.SubmitPackageRequestMessage:
+		payload := new(KaspadMessage_SubmitPackageRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.SubmitPackageResponseMessage:
+		payload := new(KaspadMessage_SubmitPackageResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetTxOutProofRequestMessage:
+		payload := new(KaspadMessage_GetTxOutProofRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetTxOutProofResponseMessage:
+		payload := new(KaspadMessage_GetTxOutProofResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.VerifyTxOutProofRequestMessage:
+		payload := new(KaspadMessage_VerifyTxOutProofRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.VerifyTxOutProofResponseMessage:
+		payload := new(KaspadMessage_VerifyTxOutProofResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.AddNodeRequestMessage:
+		payload := new(KaspadMessage_AddNodeRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.AddNodeResponseMessage:
+		payload := new(KaspadMessage_AddNodeResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.DisconnectNodeRequestMessage:
+		payload := new(KaspadMessage_DisconnectNodeRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.DisconnectNodeResponseMessage:
+		payload := new(KaspadMessage_DisconnectNodeResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetAddedNodeInfoRequestMessage:
+		payload := new(KaspadMessage_GetAddedNodeInfoRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetAddedNodeInfoResponseMessage:
+		payload := new(KaspadMessage_GetAddedNodeInfoResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.InvalidateBlockRequestMessage:
+		payload := new(KaspadMessage_InvalidateBlockRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.InvalidateBlockResponseMessage:
+		payload := new(KaspadMessage_InvalidateBlockResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.ReconsiderBlockRequestMessage:
+		payload := new(KaspadMessage_ReconsiderBlockRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.ReconsiderBlockResponseMessage:
+		payload := new(KaspadMessage_ReconsiderBlockResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetBlockStatsRequestMessage:
+		payload := new(KaspadMessage_GetBlockStatsRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetBlockStatsResponseMessage:
+		payload := new(KaspadMessage_GetBlockStatsResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetProcessingStatsRequestMessage:
+		payload := new(KaspadMessage_GetProcessingStatsRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetProcessingStatsResponseMessage:
+		payload := new(KaspadMessage_GetProcessingStatsResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.DebugLevelRequestMessage:
+		payload := new(KaspadMessage_DebugLevelRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.DebugLevelResponseMessage:
+		payload := new(KaspadMessage_DebugLevelResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.StartProfileRequestMessage:
+		payload := new(KaspadMessage_StartProfileRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.StartProfileResponseMessage:
+		payload := new(KaspadMessage_StartProfileResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.StopProfileRequestMessage:
+		payload := new(KaspadMessage_StopProfileRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.StopProfileResponseMessage:
+		payload := new(KaspadMessage_StopProfileResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.NotifyBlockHeaderAddedRequestMessage:
+		payload := new(KaspadMessage_NotifyBlockHeaderAddedRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.NotifyBlockHeaderAddedResponseMessage:
+		payload := new(KaspadMessage_NotifyBlockHeaderAddedResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.BlockHeaderAddedNotificationMessage:
+		payload := new(KaspadMessage_BlockHeaderAddedNotification)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetNetTotalsRequestMessage:
+		payload := new(KaspadMessage_GetNetTotalsRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetNetTotalsResponseMessage:
+		payload := new(KaspadMessage_GetNetTotalsResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
 	default:
 		return nil, nil
 	}
@@ -1,9 +1,12 @@
 package grpcserver
 
 import (
+	"crypto/tls"
+
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
 	"github.com/kaspanet/kaspad/util/panics"
+	"google.golang.org/grpc"
 )
 
 type rpcServer struct {
@@ -14,9 +17,9 @@ type rpcServer struct {
 // RPCMaxMessageSize is the max message size for the RPC server to send and receive
 const RPCMaxMessageSize = 1024 * 1024 * 1024 // 1 GB
 
-// NewRPCServer creates a new RPCServer
-func NewRPCServer(listeningAddresses []string, rpcMaxInboundConnections int) (server.Server, error) {
-	gRPCServer := newGRPCServer(listeningAddresses, RPCMaxMessageSize, rpcMaxInboundConnections, "RPC")
+// NewRPCServer creates a new RPCServer. It's served over TLS if tlsConfig isn't nil.
+func NewRPCServer(listeningAddresses []string, rpcMaxInboundConnections int, tlsConfig *tls.Config) (server.RPCServer, error) {
+	gRPCServer := newGRPCServer(listeningAddresses, RPCMaxMessageSize, rpcMaxInboundConnections, "RPC", serverCredentials(tlsConfig))
 	rpcServer := &rpcServer{gRPCServer: *gRPCServer}
 	protowire.RegisterRPCServer(gRPCServer.server, rpcServer)
 	return rpcServer, nil
@@ -27,3 +30,9 @@ func (r *rpcServer) MessageStream(stream protowire.RPC_MessageStreamServer) erro
 
 	return r.handleInboundConnection(stream.Context(), stream)
 }
+
+// RegisterService registers an additional gRPC service on the RPC server.
+// It must be called before the server is started.
+func (r *rpcServer) RegisterService(serviceDescription *grpc.ServiceDesc, implementation interface{}) {
+	r.server.RegisterService(serviceDescription, implementation)
+}
@@ -0,0 +1,38 @@
+package grpcserver
+
+import (
+	"crypto/tls"
+
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server"
+	"google.golang.org/grpc"
+)
+
+type serviceServer struct {
+	gRPCServer
+}
+
+// NewServiceServer creates a new ServiceServer, which serves the gRPC services
+// registered on it on their own listeners. It's served over TLS if tlsConfig
+// isn't nil.
+func NewServiceServer(listeningAddresses []string, tlsConfig *tls.Config) server.ServiceServer {
+	gRPCServer := newGRPCServer(listeningAddresses, RPCMaxMessageSize, 0, "gRPC service", serverCredentials(tlsConfig))
+	return &serviceServer{gRPCServer: *gRPCServer}
+}
+
+// Start listens on all the listening addresses. Unlike the message stream servers,
+// a service server has no connected handler, since its services handle their own calls.
+func (s *serviceServer) Start() error {
+	for _, listenAddress := range s.listeningAddresses {
+		err := s.listenOn(listenAddress)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterService registers a gRPC service on the server.
+// It must be called before the server is started.
+func (s *serviceServer) RegisterService(serviceDescription *grpc.ServiceDesc, implementation interface{}) {
+	s.server.RegisterService(serviceDescription, implementation)
+}
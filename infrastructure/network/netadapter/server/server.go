@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"net"
 
+	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"google.golang.org/grpc"
 )
 
 // OnConnectedHandler is a function that is to be called
@@ -20,6 +22,11 @@ type OnDisconnectedHandler func()
 // was received from a connection.
 type OnInvalidMessageHandler func(err error)
 
+// OnMessageTransferredHandler is a function that is to be called
+// once a message was sent or received over a Connection, along with
+// the size of the message on the wire.
+type OnMessageTransferredHandler func(command appmessage.MessageCommand, size int)
+
 // Server represents a server.
 type Server interface {
 	Start() error
@@ -33,6 +40,20 @@ type P2PServer interface {
 	Connect(address string) (Connection, error)
 }
 
+// RPCServer represents an rpc server.
+type RPCServer interface {
+	Server
+	RegisterService(serviceDescription *grpc.ServiceDesc, implementation interface{})
+}
+
+// ServiceServer represents a server of gRPC services, apart from
+// the message streams of the P2P and RPC servers.
+type ServiceServer interface {
+	Start() error
+	Stop() error
+	RegisterService(serviceDescription *grpc.ServiceDesc, implementation interface{})
+}
+
 // Connection represents a server connection.
 type Connection interface {
 	fmt.Stringer
@@ -42,5 +63,21 @@ type Connection interface {
 	IsOutbound() bool
 	SetOnDisconnectedHandler(onDisconnectedHandler OnDisconnectedHandler)
 	SetOnInvalidMessageHandler(onInvalidMessageHandler OnInvalidMessageHandler)
+	SetOnMessageSentHandler(onMessageSentHandler OnMessageTransferredHandler)
+	SetOnMessageReceivedHandler(onMessageReceivedHandler OnMessageTransferredHandler)
 	Address() *net.TCPAddr
+
+	// DialedAddress returns the address that an outbound connection was dialed at,
+	// which is a host name rather than an IP for onion and I2P peers. It's empty for
+	// inbound connections.
+	DialedAddress() string
+
+	// ListenAddress returns the listening address that accepted an inbound connection,
+	// as it was given to the server. It's empty for outbound connections, and for the
+	// inbound connections of transports that have their own listeners.
+	ListenAddress() string
+
+	// IsEncrypted returns whether the connection is encrypted, which it is if both
+	// peers support encryption and have it enabled
+	IsEncrypted() bool
 }
@@ -0,0 +1,139 @@
+package netadapter
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+)
+
+// trafficCounters count the bytes transferred over P2P connections, along
+// with the bytes of the block and transaction data that was served
+type trafficCounters struct {
+	bytesSent              uint64
+	bytesReceived          uint64
+	blockBytesServed       uint64
+	transactionBytesServed uint64
+}
+
+func (tc *trafficCounters) recordSent(command appmessage.MessageCommand, size int) {
+	atomic.AddUint64(&tc.bytesSent, uint64(size))
+	switch {
+	case isBlockDataCommand(command):
+		atomic.AddUint64(&tc.blockBytesServed, uint64(size))
+	case isTransactionDataCommand(command):
+		atomic.AddUint64(&tc.transactionBytesServed, uint64(size))
+	}
+}
+
+func (tc *trafficCounters) recordReceived(size int) {
+	atomic.AddUint64(&tc.bytesReceived, uint64(size))
+}
+
+// TrafficCounters is a snapshot of the bytes transferred over P2P connections
+type TrafficCounters struct {
+	BytesSent     uint64
+	BytesReceived uint64
+
+	// BlockBytesServed and TransactionBytesServed are the parts of BytesSent
+	// that carried blocks, headers and UTXO set chunks, and transactions
+	BlockBytesServed       uint64
+	TransactionBytesServed uint64
+}
+
+func (tc *trafficCounters) snapshot() *TrafficCounters {
+	return &TrafficCounters{
+		BytesSent:              atomic.LoadUint64(&tc.bytesSent),
+		BytesReceived:          atomic.LoadUint64(&tc.bytesReceived),
+		BlockBytesServed:       atomic.LoadUint64(&tc.blockBytesServed),
+		TransactionBytesServed: atomic.LoadUint64(&tc.transactionBytesServed),
+	}
+}
+
+func isBlockDataCommand(command appmessage.MessageCommand) bool {
+	switch command {
+	case appmessage.CmdBlock, appmessage.CmdIBDBlock, appmessage.CmdBlockWithTrustedData,
+		appmessage.CmdBlockWithTrustedDataV4, appmessage.CmdTrustedData, appmessage.CmdBlockHeaders,
+		appmessage.CmdPruningPoints, appmessage.CmdPruningPointProof, appmessage.CmdPruningPointUTXOSetChunk,
+		appmessage.CmdMerkleBlock:
+		return true
+	}
+	return false
+}
+
+func isTransactionDataCommand(command appmessage.MessageCommand) bool {
+	switch command {
+	case appmessage.CmdTx, appmessage.CmdStemTransaction, appmessage.CmdPackage:
+		return true
+	}
+	return false
+}
+
+// messageCounters count the messages of each command transferred over a P2P
+// connection, along with their bytes
+type messageCounters struct {
+	lock     sync.Mutex
+	sent     map[appmessage.MessageCommand]*MessageCount
+	received map[appmessage.MessageCommand]*MessageCount
+}
+
+// MessageCount is the number of messages of a command that were transferred
+// over a P2P connection, and their total size in bytes
+type MessageCount struct {
+	Messages uint64
+	Bytes    uint64
+}
+
+// MessageCounts is a snapshot of the messages transferred over a P2P connection,
+// by their command
+type MessageCounts struct {
+	Sent     map[appmessage.MessageCommand]MessageCount
+	Received map[appmessage.MessageCommand]MessageCount
+}
+
+func (mc *messageCounters) recordSent(command appmessage.MessageCommand, size int) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+
+	if mc.sent == nil {
+		mc.sent = make(map[appmessage.MessageCommand]*MessageCount)
+	}
+	recordMessage(mc.sent, command, size)
+}
+
+func (mc *messageCounters) recordReceived(command appmessage.MessageCommand, size int) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+
+	if mc.received == nil {
+		mc.received = make(map[appmessage.MessageCommand]*MessageCount)
+	}
+	recordMessage(mc.received, command, size)
+}
+
+func recordMessage(counts map[appmessage.MessageCommand]*MessageCount, command appmessage.MessageCommand, size int) {
+	count, ok := counts[command]
+	if !ok {
+		count = &MessageCount{}
+		counts[command] = count
+	}
+	count.Messages++
+	count.Bytes += uint64(size)
+}
+
+func (mc *messageCounters) snapshot() *MessageCounts {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+
+	snapshot := &MessageCounts{
+		Sent:     make(map[appmessage.MessageCommand]MessageCount, len(mc.sent)),
+		Received: make(map[appmessage.MessageCommand]MessageCount, len(mc.received)),
+	}
+	for command, count := range mc.sent {
+		snapshot.Sent[command] = *count
+	}
+	for command, count := range mc.received {
+		snapshot.Received[command] = *count
+	}
+	return snapshot
+}
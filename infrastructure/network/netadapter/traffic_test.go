@@ -0,0 +1,38 @@
+package netadapter
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+)
+
+func TestMessageCounters(t *testing.T) {
+	var counters messageCounters
+	counters.recordSent(appmessage.CmdBlock, 1000)
+	counters.recordSent(appmessage.CmdBlock, 500)
+	counters.recordSent(appmessage.CmdPing, 8)
+	counters.recordReceived(appmessage.CmdPong, 8)
+
+	snapshot := counters.snapshot()
+	expectedSent := map[appmessage.MessageCommand]MessageCount{
+		appmessage.CmdBlock: {Messages: 2, Bytes: 1500},
+		appmessage.CmdPing:  {Messages: 1, Bytes: 8},
+	}
+	if len(snapshot.Sent) != len(expectedSent) {
+		t.Fatalf("Expected counts of %d sent commands, but got %d", len(expectedSent), len(snapshot.Sent))
+	}
+	for command, expectedCount := range expectedSent {
+		if snapshot.Sent[command] != expectedCount {
+			t.Fatalf("Expected %+v sent for %s, but got %+v", expectedCount, command, snapshot.Sent[command])
+		}
+	}
+	if len(snapshot.Received) != 1 || snapshot.Received[appmessage.CmdPong] != (MessageCount{Messages: 1, Bytes: 8}) {
+		t.Fatalf("Expected a single pong received, but got %+v", snapshot.Received)
+	}
+
+	// The snapshot isn't changed by messages that are transferred after it's taken
+	counters.recordSent(appmessage.CmdBlock, 1000)
+	if snapshot.Sent[appmessage.CmdBlock].Messages != 2 {
+		t.Fatalf("Expected the snapshot to keep 2 sent blocks, but got %d", snapshot.Sent[appmessage.CmdBlock].Messages)
+	}
+}
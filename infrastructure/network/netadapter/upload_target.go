@@ -0,0 +1,114 @@
+package netadapter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/util/timesource"
+)
+
+// UploadTargetCycle is the period that the upload target applies to
+const UploadTargetCycle = 24 * time.Hour
+
+// uploadTargetRelayReserveDivisor sets the part of the upload target that's reserved
+// for relaying new blocks and transactions. Historical data stops being served once
+// less than 1/uploadTargetRelayReserveDivisor of the target is left in the cycle.
+const uploadTargetRelayReserveDivisor = 10
+
+// UploadTarget tracks the bytes sent to peers in the current cycle against the
+// target set by --maxuploadtarget. The target isn't enforced on the wire. Rather,
+// the flows that serve historical data to syncing peers check ShouldServeHistoricalData
+// first, since that's where nearly all of the upload traffic of a node comes from.
+type UploadTarget struct {
+	lock       sync.Mutex
+	timeSource timesource.Source
+
+	target           uint64
+	cycleStart       time.Time
+	bytesSentInCycle uint64
+}
+
+func newUploadTarget(target uint64, timeSource timesource.Source) *UploadTarget {
+	return &UploadTarget{
+		timeSource: timeSource,
+		target:     target,
+		cycleStart: timeSource.Now(),
+	}
+}
+
+// maybeStartNewCycle resets the bytes sent in the cycle once it's over.
+// This function must be called with the lock held.
+func (ut *UploadTarget) maybeStartNewCycle() {
+	now := ut.timeSource.Now()
+	if now.Sub(ut.cycleStart) >= UploadTargetCycle {
+		ut.cycleStart = now
+		ut.bytesSentInCycle = 0
+	}
+}
+
+func (ut *UploadTarget) recordSent(size int) {
+	ut.lock.Lock()
+	defer ut.lock.Unlock()
+
+	ut.maybeStartNewCycle()
+	ut.bytesSentInCycle += uint64(size)
+}
+
+// Target returns the maximum number of bytes to send in a cycle. It's 0 if there's no target.
+func (ut *UploadTarget) Target() uint64 {
+	return ut.target
+}
+
+// IsReached returns whether the bytes sent in the current cycle reached the target
+func (ut *UploadTarget) IsReached() bool {
+	ut.lock.Lock()
+	defer ut.lock.Unlock()
+
+	ut.maybeStartNewCycle()
+	return ut.target != 0 && ut.bytesSentInCycle >= ut.target
+}
+
+// ShouldServeHistoricalData returns whether there's enough left of the upload target
+// to serve historical data, such as the blocks and the UTXO set that syncing peers
+// request, without cutting into the reserve kept for relaying new blocks and transactions
+func (ut *UploadTarget) ShouldServeHistoricalData() bool {
+	ut.lock.Lock()
+	defer ut.lock.Unlock()
+
+	ut.maybeStartNewCycle()
+	if ut.target == 0 {
+		return true
+	}
+	return ut.bytesLeftInCycle() > ut.target/uploadTargetRelayReserveDivisor
+}
+
+// BytesLeftInCycle returns the number of bytes that can still be sent in the current
+// cycle before the target is reached. It's 0 if there's no target.
+func (ut *UploadTarget) BytesLeftInCycle() uint64 {
+	ut.lock.Lock()
+	defer ut.lock.Unlock()
+
+	ut.maybeStartNewCycle()
+	return ut.bytesLeftInCycle()
+}
+
+// bytesLeftInCycle is BytesLeftInCycle without the lock
+func (ut *UploadTarget) bytesLeftInCycle() uint64 {
+	if ut.bytesSentInCycle >= ut.target {
+		return 0
+	}
+	return ut.target - ut.bytesSentInCycle
+}
+
+// TimeLeftInCycle returns the time until the current cycle is over. It's 0 if there's no target.
+func (ut *UploadTarget) TimeLeftInCycle() time.Duration {
+	if ut.target == 0 {
+		return 0
+	}
+
+	ut.lock.Lock()
+	defer ut.lock.Unlock()
+
+	ut.maybeStartNewCycle()
+	return ut.cycleStart.Add(UploadTargetCycle).Sub(ut.timeSource.Now())
+}
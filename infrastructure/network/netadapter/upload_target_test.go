@@ -0,0 +1,61 @@
+package netadapter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/util/timesource"
+)
+
+func TestUploadTarget(t *testing.T) {
+	timeSource := timesource.NewManual(0, time.Unix(0, 0))
+	uploadTarget := newUploadTarget(1000, timeSource)
+
+	if !uploadTarget.ShouldServeHistoricalData() {
+		t.Fatalf("Expected historical data to be served at the start of the cycle")
+	}
+
+	uploadTarget.recordSent(850)
+	if !uploadTarget.ShouldServeHistoricalData() {
+		t.Fatalf("Expected historical data to be served while more than the reserve is left")
+	}
+	uploadTarget.recordSent(50)
+	if uploadTarget.ShouldServeHistoricalData() {
+		t.Fatalf("Expected historical data not to be served once only the reserve is left")
+	}
+	if uploadTarget.IsReached() {
+		t.Fatalf("Expected the target not to be reached after sending 900 of 1000 bytes")
+	}
+	if uploadTarget.BytesLeftInCycle() != 100 {
+		t.Fatalf("Expected 100 bytes left in the cycle but got %d", uploadTarget.BytesLeftInCycle())
+	}
+
+	uploadTarget.recordSent(200)
+	if !uploadTarget.IsReached() {
+		t.Fatalf("Expected the target to be reached after sending 1100 of 1000 bytes")
+	}
+	if uploadTarget.BytesLeftInCycle() != 0 {
+		t.Fatalf("Expected no bytes left in the cycle but got %d", uploadTarget.BytesLeftInCycle())
+	}
+
+	timeSource.Advance(UploadTargetCycle - time.Hour)
+	if uploadTarget.TimeLeftInCycle() != time.Hour {
+		t.Fatalf("Expected an hour left in the cycle but got %s", uploadTarget.TimeLeftInCycle())
+	}
+
+	timeSource.Advance(time.Hour)
+	if uploadTarget.IsReached() || !uploadTarget.ShouldServeHistoricalData() {
+		t.Fatalf("Expected the bytes sent to be reset once the cycle is over")
+	}
+	if uploadTarget.TimeLeftInCycle() != UploadTargetCycle {
+		t.Fatalf("Expected a full cycle left but got %s", uploadTarget.TimeLeftInCycle())
+	}
+}
+
+func TestUploadTargetWithoutTarget(t *testing.T) {
+	uploadTarget := newUploadTarget(0, timesource.NewManual(0, time.Unix(0, 0)))
+	uploadTarget.recordSent(1 << 40)
+	if uploadTarget.IsReached() || !uploadTarget.ShouldServeHistoricalData() {
+		t.Fatalf("Expected no limit when there's no target")
+	}
+}
@@ -0,0 +1,167 @@
+// Package permissions describes the permissions that peers can be given by
+// --whitelist and --whitebind, which exempt them from some of the limits the
+// node puts on its peers.
+package permissions
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Flags is a set of permissions
+type Flags uint32
+
+const (
+	// Relay lets transactions from the peer be accepted even in blocks-only mode
+	Relay Flags = 1 << iota
+
+	// Mempool has the inv of all the transactions in the mempool sent to the
+	// peer when it connects, so that it doesn't have to wait for them to be relayed
+	Mempool
+
+	// NoBan keeps the peer from being banned for misbehaving, or evicted to make
+	// room for other incoming peers. It's still disconnected when it misbehaves.
+	NoBan
+
+	// ForceRelay has transactions from the peer propagated even if they're already
+	// in the mempool. It implies Relay.
+	ForceRelay
+
+	// Download lets the peer download historical data, such as the blocks and the
+	// UTXO set that syncing peers request, even once the upload target is reached
+	Download
+)
+
+// None is the empty set of permissions
+const None Flags = 0
+
+// Default is the set of permissions given by a --whitelist or --whitebind entry
+// that doesn't name any
+const Default = Relay | Mempool | NoBan | Download
+
+var flagNames = map[string]Flags{
+	"relay":      Relay,
+	"mempool":    Mempool,
+	"noban":      NoBan,
+	"forcerelay": ForceRelay | Relay,
+	"download":   Download,
+}
+
+// Has returns whether flags include all of the given permissions
+func (flags Flags) Has(permissions Flags) bool {
+	return flags&permissions == permissions
+}
+
+// Names returns the names of the permissions in flags, sorted alphabetically
+func (flags Flags) Names() []string {
+	names := make([]string, 0, len(flagNames))
+	for name, flag := range flagNames {
+		if flags.Has(flag) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (flags Flags) String() string {
+	if flags == None {
+		return "none"
+	}
+	return strings.Join(flags.Names(), ",")
+}
+
+// parseFlags splits an entry of the form [permissions@]value into its permissions
+// and its value. The permissions are a comma separated list of permission names,
+// and are Default if they're omitted.
+func parseFlags(entry string) (Flags, string, error) {
+	separatorIndex := strings.LastIndex(entry, "@")
+	if separatorIndex == -1 {
+		return Default, entry, nil
+	}
+
+	flags := None
+	for _, name := range strings.Split(entry[:separatorIndex], ",") {
+		flag, ok := flagNames[strings.TrimSpace(name)]
+		if !ok {
+			return None, "", errors.Errorf("unknown permission '%s'", name)
+		}
+		flags |= flag
+	}
+	return flags, entry[separatorIndex+1:], nil
+}
+
+// Whitelist gives the peers in an IP network permissions
+type Whitelist struct {
+	Flags   Flags
+	Network *net.IPNet
+}
+
+// ParseWhitelist parses an entry of the form [permissions@]<IP or network>, such
+// as noban,relay@192.168.1.0/24 or ::1
+func ParseWhitelist(entry string) (*Whitelist, error) {
+	flags, value, err := parseFlags(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	_, network, err := net.ParseCIDR(value)
+	if err != nil {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, errors.Errorf("'%s' is neither an IP nor an IP network", value)
+		}
+		bits := net.IPv6len * 8
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = net.IPv4len * 8
+		}
+		network = &net.IPNet{
+			IP:   ip,
+			Mask: net.CIDRMask(bits, bits),
+		}
+	}
+	return &Whitelist{Flags: flags, Network: network}, nil
+}
+
+// Whitebind gives the peers that connect to a listening address permissions
+type Whitebind struct {
+	Flags   Flags
+	Address string
+}
+
+// ParseWhitebind parses an entry of the form [permissions@]<address>, such as
+// noban@127.0.0.1:16111
+func ParseWhitebind(entry string) (*Whitebind, error) {
+	flags, value, err := parseFlags(entry)
+	if err != nil {
+		return nil, err
+	}
+	_, _, err = net.SplitHostPort(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "'%s' is not a listening address", value)
+	}
+	return &Whitebind{Flags: flags, Address: value}, nil
+}
+
+// ForConnection returns the permissions of a peer at ip, which connected to the
+// listening address listenAddress, or to none if it's an outbound peer
+func ForConnection(whitelists []*Whitelist, whitebinds []*Whitebind, ip net.IP, listenAddress string) Flags {
+	flags := None
+	for _, whitelist := range whitelists {
+		if whitelist.Network.Contains(ip) {
+			flags |= whitelist.Flags
+		}
+	}
+	if listenAddress != "" {
+		for _, whitebind := range whitebinds {
+			if whitebind.Address == listenAddress {
+				flags |= whitebind.Flags
+			}
+		}
+	}
+	return flags
+}
@@ -0,0 +1,92 @@
+package permissions
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestParseWhitelist(t *testing.T) {
+	tests := []struct {
+		entry           string
+		expectedFlags   Flags
+		expectedNetwork string
+		expectsError    bool
+	}{
+		{entry: "192.168.1.0/24", expectedFlags: Default, expectedNetwork: "192.168.1.0/24"},
+		{entry: "::1", expectedFlags: Default, expectedNetwork: "::1/128"},
+		{entry: "10.0.0.1", expectedFlags: Default, expectedNetwork: "10.0.0.1/32"},
+		{entry: "noban@10.0.0.1", expectedFlags: NoBan, expectedNetwork: "10.0.0.1/32"},
+		{entry: "noban,download@fd00::/8", expectedFlags: NoBan | Download, expectedNetwork: "fd00::/8"},
+		{entry: "forcerelay@10.0.0.0/8", expectedFlags: ForceRelay | Relay, expectedNetwork: "10.0.0.0/8"},
+		{entry: "invalid@10.0.0.0/8", expectsError: true},
+		{entry: "relay@not-an-ip", expectsError: true},
+	}
+	for _, test := range tests {
+		whitelist, err := ParseWhitelist(test.entry)
+		if test.expectsError {
+			if err == nil {
+				t.Errorf("Expected an error parsing %s", test.entry)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseWhitelist(%s): %s", test.entry, err)
+		}
+		if whitelist.Flags != test.expectedFlags {
+			t.Errorf("Expected %s to have the permissions %s but got %s", test.entry, test.expectedFlags, whitelist.Flags)
+		}
+		if whitelist.Network.String() != test.expectedNetwork {
+			t.Errorf("Expected %s to have the network %s but got %s", test.entry, test.expectedNetwork, whitelist.Network)
+		}
+	}
+}
+
+func TestParseWhitebind(t *testing.T) {
+	whitebind, err := ParseWhitebind("relay,mempool@[::1]:16111")
+	if err != nil {
+		t.Fatalf("ParseWhitebind: %s", err)
+	}
+	if whitebind.Flags != Relay|Mempool || whitebind.Address != "[::1]:16111" {
+		t.Fatalf("Unexpected whitebind %s@%s", whitebind.Flags, whitebind.Address)
+	}
+
+	_, err = ParseWhitebind("noban@127.0.0.1")
+	if err == nil {
+		t.Fatalf("Expected an error parsing a whitebind without a port")
+	}
+}
+
+func TestForConnection(t *testing.T) {
+	whitelists := []*Whitelist{
+		{Flags: NoBan, Network: &net.IPNet{IP: net.ParseIP("10.0.0.0").To4(), Mask: net.CIDRMask(8, 32)}},
+		{Flags: Download, Network: &net.IPNet{IP: net.ParseIP("10.0.0.1").To4(), Mask: net.CIDRMask(32, 32)}},
+	}
+	whitebinds := []*Whitebind{{Flags: Mempool, Address: "127.0.0.1:16112"}}
+
+	tests := []struct {
+		ip            string
+		listenAddress string
+		expectedFlags Flags
+	}{
+		{ip: "10.0.0.1", listenAddress: "", expectedFlags: NoBan | Download},
+		{ip: "10.0.0.2", listenAddress: "127.0.0.1:16111", expectedFlags: NoBan},
+		{ip: "127.0.0.1", listenAddress: "127.0.0.1:16112", expectedFlags: Mempool},
+		{ip: "1.1.1.1", listenAddress: "", expectedFlags: None},
+	}
+	for _, test := range tests {
+		flags := ForConnection(whitelists, whitebinds, net.ParseIP(test.ip), test.listenAddress)
+		if flags != test.expectedFlags {
+			t.Errorf("Expected %s at %s to have the permissions %s but got %s",
+				test.ip, test.listenAddress, test.expectedFlags, flags)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	names := (ForceRelay | Relay | NoBan).Names()
+	expected := []string{"forcerelay", "noban", "relay"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Expected %s but got %s", expected, names)
+	}
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// AddNode sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) AddNode(address string, nodeCommand string) error {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewAddNodeRequestMessage(address, nodeCommand))
+	if err != nil {
+		return err
+	}
+	response, err := c.route(appmessage.CmdAddNodeResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return err
+	}
+	addNodeResponse := response.(*appmessage.AddNodeResponseMessage)
+	if addNodeResponse.Error != nil {
+		return c.convertRPCError(addNodeResponse.Error)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// BackupDagState sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) BackupDagState(path string) (*appmessage.BackupDagStateResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewBackupDagStateRequestMessage(path))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdBackupDagStateResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	backupDagStateResponse := response.(*appmessage.BackupDagStateResponseMessage)
+	if backupDagStateResponse.Error != nil {
+		return nil, c.convertRPCError(backupDagStateResponse.Error)
+	}
+	return backupDagStateResponse, nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// CompactDb sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) CompactDb() (*appmessage.CompactDbResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewCompactDbRequestMessage())
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdCompactDbResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	compactDbResponse := response.(*appmessage.CompactDbResponseMessage)
+	if compactDbResponse.Error != nil {
+		return nil, c.convertRPCError(compactDbResponse.Error)
+	}
+	return compactDbResponse, nil
+}
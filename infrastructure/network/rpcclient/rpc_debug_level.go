@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// DebugLevel sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) DebugLevel(levelSpec string) (*appmessage.DebugLevelResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewDebugLevelRequestMessage(levelSpec))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdDebugLevelResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	debugLevelResponse := response.(*appmessage.DebugLevelResponseMessage)
+	if debugLevelResponse.Error != nil {
+		return nil, c.convertRPCError(debugLevelResponse.Error)
+	}
+	return debugLevelResponse, nil
+}
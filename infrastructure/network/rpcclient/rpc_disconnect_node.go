@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// DisconnectNode sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) DisconnectNode(address string) error {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewDisconnectNodeRequestMessage(address))
+	if err != nil {
+		return err
+	}
+	response, err := c.route(appmessage.CmdDisconnectNodeResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return err
+	}
+	disconnectNodeResponse := response.(*appmessage.DisconnectNodeResponseMessage)
+	if disconnectNodeResponse.Error != nil {
+		return c.convertRPCError(disconnectNodeResponse.Error)
+	}
+	return nil
+}
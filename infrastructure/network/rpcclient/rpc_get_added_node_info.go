@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// GetAddedNodeInfo sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetAddedNodeInfo() (*appmessage.GetAddedNodeInfoResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetAddedNodeInfoRequestMessage())
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetAddedNodeInfoResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getAddedNodeInfoResponse := response.(*appmessage.GetAddedNodeInfoResponseMessage)
+	if getAddedNodeInfoResponse.Error != nil {
+		return nil, c.convertRPCError(getAddedNodeInfoResponse.Error)
+	}
+	return getAddedNodeInfoResponse, nil
+}
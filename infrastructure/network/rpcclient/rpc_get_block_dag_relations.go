@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// GetBlockDAGRelations sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetBlockDAGRelations(hash string, lowHash string) (*appmessage.GetBlockDAGRelationsResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetBlockDAGRelationsRequestMessage(hash, lowHash))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetBlockDAGRelationsResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getBlockDAGRelationsResponse := response.(*appmessage.GetBlockDAGRelationsResponseMessage)
+	if getBlockDAGRelationsResponse.Error != nil {
+		return nil, c.convertRPCError(getBlockDAGRelationsResponse.Error)
+	}
+	return getBlockDAGRelationsResponse, nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// GetBlockStats sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetBlockStats(hash string, highHash string) (*appmessage.GetBlockStatsResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetBlockStatsRequestMessage(hash, highHash))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetBlockStatsResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getBlockStatsResponse := response.(*appmessage.GetBlockStatsResponseMessage)
+	if getBlockStatsResponse.Error != nil {
+		return nil, c.convertRPCError(getBlockStatsResponse.Error)
+	}
+	return getBlockStatsResponse, nil
+}
@@ -18,3 +18,51 @@ func (c *RPCClient) GetBlockTemplate(miningAddress, extraData string) (*appmessa
 	}
 	return getBlockTemplateResponse, nil
 }
+
+// GetBlockTemplateWithCapabilities sends a getBlockTemplate RPC request declaring the given capabilities.
+// If longPollID is the ID of the current template, the call blocks until a new template is available.
+func (c *RPCClient) GetBlockTemplateWithCapabilities(miningAddress, extraData string, longPollID string, capabilities []string) (
+	*appmessage.GetBlockTemplateResponseMessage, error) {
+
+	request := appmessage.NewGetBlockTemplateRequestMessage(miningAddress, extraData)
+	request.LongPollID = longPollID
+	request.Capabilities = capabilities
+	err := c.rpcRouter.outgoingRoute().Enqueue(request)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetBlockTemplateResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getBlockTemplateResponse := response.(*appmessage.GetBlockTemplateResponseMessage)
+	if getBlockTemplateResponse.Error != nil {
+		return nil, c.convertRPCError(getBlockTemplateResponse.Error)
+	}
+	return getBlockTemplateResponse, nil
+}
+
+// GetBlockTemplateWithOptions sends a getBlockTemplate RPC request for a template whose transactions
+// follow the given policy: excludedTransactionIDs are left out, includedTransactionIDs are selected
+// before any other transaction, and the total mass of the transactions is at most maxMass, unless it's 0.
+func (c *RPCClient) GetBlockTemplateWithOptions(miningAddress, extraData string, excludedTransactionIDs []string,
+	includedTransactionIDs []string, maxMass uint64) (*appmessage.GetBlockTemplateResponseMessage, error) {
+
+	request := appmessage.NewGetBlockTemplateRequestMessage(miningAddress, extraData)
+	request.ExcludedTransactionIDs = excludedTransactionIDs
+	request.IncludedTransactionIDs = includedTransactionIDs
+	request.MaxMass = maxMass
+	err := c.rpcRouter.outgoingRoute().Enqueue(request)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetBlockTemplateResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getBlockTemplateResponse := response.(*appmessage.GetBlockTemplateResponseMessage)
+	if getBlockTemplateResponse.Error != nil {
+		return nil, c.convertRPCError(getBlockTemplateResponse.Error)
+	}
+	return getBlockTemplateResponse, nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// GetDbInfo sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetDbInfo() (*appmessage.GetDbInfoResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetDbInfoRequestMessage())
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetDbInfoResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getDbInfoResponse := response.(*appmessage.GetDbInfoResponseMessage)
+	if getDbInfoResponse.Error != nil {
+		return nil, c.convertRPCError(getDbInfoResponse.Error)
+	}
+	return getDbInfoResponse, nil
+}
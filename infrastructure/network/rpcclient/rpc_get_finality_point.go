@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// GetFinalityPoint sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetFinalityPoint() (*appmessage.GetFinalityPointResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetFinalityPointRequestMessage())
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetFinalityPointResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getFinalityPointResponse := response.(*appmessage.GetFinalityPointResponseMessage)
+	if getFinalityPointResponse.Error != nil {
+		return nil, c.convertRPCError(getFinalityPointResponse.Error)
+	}
+	return getFinalityPointResponse, nil
+}
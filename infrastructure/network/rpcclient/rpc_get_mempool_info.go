@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// GetMempoolInfo sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetMempoolInfo() (*appmessage.GetMempoolInfoResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetMempoolInfoRequestMessage())
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetMempoolInfoResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getMempoolInfoResponse := response.(*appmessage.GetMempoolInfoResponseMessage)
+	if getMempoolInfoResponse.Error != nil {
+		return nil, c.convertRPCError(getMempoolInfoResponse.Error)
+	}
+	return getMempoolInfoResponse, nil
+}
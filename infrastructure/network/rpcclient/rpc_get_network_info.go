@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// GetNetworkInfo sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetNetworkInfo() (*appmessage.GetNetworkInfoResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetNetworkInfoRequestMessage())
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetNetworkInfoResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getNetworkInfoResponse := response.(*appmessage.GetNetworkInfoResponseMessage)
+	if getNetworkInfoResponse.Error != nil {
+		return nil, c.convertRPCError(getNetworkInfoResponse.Error)
+	}
+	return getNetworkInfoResponse, nil
+}
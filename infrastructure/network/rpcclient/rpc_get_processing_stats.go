@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// GetProcessingStats sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetProcessingStats() (*appmessage.GetProcessingStatsResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetProcessingStatsRequestMessage())
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetProcessingStatsResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getProcessingStatsResponse := response.(*appmessage.GetProcessingStatsResponseMessage)
+	if getProcessingStatsResponse.Error != nil {
+		return nil, c.convertRPCError(getProcessingStatsResponse.Error)
+	}
+	return getProcessingStatsResponse, nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// GetRawTransaction sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetRawTransaction(txID string) (*appmessage.GetRawTransactionResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetRawTransactionRequestMessage(txID))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetRawTransactionResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getRawTransactionResponse := response.(*appmessage.GetRawTransactionResponseMessage)
+	if getRawTransactionResponse.Error != nil {
+		return nil, c.convertRPCError(getRawTransactionResponse.Error)
+	}
+	return getRawTransactionResponse, nil
+}
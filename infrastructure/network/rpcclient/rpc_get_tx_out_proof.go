@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// GetTxOutProof sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetTxOutProof(transactionID string, blockHash string) (*appmessage.GetTxOutProofResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetTxOutProofRequestMessage(transactionID, blockHash))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetTxOutProofResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getTxOutProofResponse := response.(*appmessage.GetTxOutProofResponseMessage)
+	if getTxOutProofResponse.Error != nil {
+		return nil, c.convertRPCError(getTxOutProofResponse.Error)
+	}
+	return getTxOutProofResponse, nil
+}
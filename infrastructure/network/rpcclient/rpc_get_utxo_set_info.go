@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// GetUTXOSetInfo sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetUTXOSetInfo(verbose bool) (*appmessage.GetUTXOSetInfoResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetUTXOSetInfoRequestMessage(verbose))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetUTXOSetInfoResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getUTXOSetInfoResponse := response.(*appmessage.GetUTXOSetInfoResponseMessage)
+	if getUTXOSetInfoResponse.Error != nil {
+		return nil, c.convertRPCError(getUTXOSetInfoResponse.Error)
+	}
+	return getUTXOSetInfoResponse, nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// InvalidateBlock sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) InvalidateBlock(hash string) error {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewInvalidateBlockRequestMessage(hash))
+	if err != nil {
+		return err
+	}
+	response, err := c.route(appmessage.CmdInvalidateBlockResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return err
+	}
+	invalidateBlockResponse := response.(*appmessage.InvalidateBlockResponseMessage)
+	if invalidateBlockResponse.Error != nil {
+		return c.convertRPCError(invalidateBlockResponse.Error)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package rpcclient
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	routerpkg "github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// RegisterForBlockHeaderAddedNotifications sends an RPC request respective to the function's
+// name and returns the RPC server's response. Additionally, it starts listening for the appropriate notification
+// using the given handler function
+func (c *RPCClient) RegisterForBlockHeaderAddedNotifications(
+	onBlockHeaderAdded func(notification *appmessage.BlockHeaderAddedNotificationMessage)) error {
+
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewNotifyBlockHeaderAddedRequestMessage())
+	if err != nil {
+		return err
+	}
+	response, err := c.route(appmessage.CmdNotifyBlockHeaderAddedResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return err
+	}
+	notifyBlockHeaderAddedResponse := response.(*appmessage.NotifyBlockHeaderAddedResponseMessage)
+	if notifyBlockHeaderAddedResponse.Error != nil {
+		return c.convertRPCError(notifyBlockHeaderAddedResponse.Error)
+	}
+	spawn("RegisterForBlockHeaderAddedNotifications", func() {
+		for {
+			notification, err := c.route(appmessage.CmdBlockHeaderAddedNotificationMessage).Dequeue()
+			if err != nil {
+				if errors.Is(err, routerpkg.ErrRouteClosed) {
+					break
+				}
+				panic(err)
+			}
+			blockHeaderAddedNotification := notification.(*appmessage.BlockHeaderAddedNotificationMessage)
+			onBlockHeaderAdded(blockHeaderAddedNotification)
+		}
+	})
+	return nil
+}
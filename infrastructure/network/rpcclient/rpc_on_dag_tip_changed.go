@@ -0,0 +1,41 @@
+package rpcclient
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	routerpkg "github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// RegisterForDAGTipChangedNotifications sends an RPC request respective to the function's
+// name and returns the RPC server's response. Additionally, it starts listening for the appropriate notification
+// using the given handler function
+func (c *RPCClient) RegisterForDAGTipChangedNotifications(
+	onDAGTipChanged func(notification *appmessage.DAGTipChangedNotificationMessage)) error {
+
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewNotifyDAGTipChangedRequestMessage())
+	if err != nil {
+		return err
+	}
+	response, err := c.route(appmessage.CmdNotifyDAGTipChangedResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return err
+	}
+	notifyDAGTipChangedResponse := response.(*appmessage.NotifyDAGTipChangedResponseMessage)
+	if notifyDAGTipChangedResponse.Error != nil {
+		return c.convertRPCError(notifyDAGTipChangedResponse.Error)
+	}
+	spawn("RegisterForDAGTipChangedNotifications", func() {
+		for {
+			notification, err := c.route(appmessage.CmdDAGTipChangedNotificationMessage).Dequeue()
+			if err != nil {
+				if errors.Is(err, routerpkg.ErrRouteClosed) {
+					break
+				}
+				panic(err)
+			}
+			dagTipChangedNotification := notification.(*appmessage.DAGTipChangedNotificationMessage)
+			onDAGTipChanged(dagTipChangedNotification)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// ReconsiderBlock sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) ReconsiderBlock(hash string) error {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewReconsiderBlockRequestMessage(hash))
+	if err != nil {
+		return err
+	}
+	response, err := c.route(appmessage.CmdReconsiderBlockResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return err
+	}
+	reconsiderBlockResponse := response.(*appmessage.ReconsiderBlockResponseMessage)
+	if reconsiderBlockResponse.Error != nil {
+		return c.convertRPCError(reconsiderBlockResponse.Error)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// StartProfile sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) StartProfile(profileType string) (*appmessage.StartProfileResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewStartProfileRequestMessage(profileType))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdStartProfileResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	startProfileResponse := response.(*appmessage.StartProfileResponseMessage)
+	if startProfileResponse.Error != nil {
+		return nil, c.convertRPCError(startProfileResponse.Error)
+	}
+	return startProfileResponse, nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// StopProfile sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) StopProfile(profileType string) (*appmessage.StopProfileResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewStopProfileRequestMessage(profileType))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdStopProfileResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	stopProfileResponse := response.(*appmessage.StopProfileResponseMessage)
+	if stopProfileResponse.Error != nil {
+		return nil, c.convertRPCError(stopProfileResponse.Error)
+	}
+	return stopProfileResponse, nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// SubmitPackage sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) SubmitPackage(transactions []*appmessage.RPCTransaction) (*appmessage.SubmitPackageResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewSubmitPackageRequestMessage(transactions))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdSubmitPackageResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	submitPackageResponse := response.(*appmessage.SubmitPackageResponseMessage)
+	if submitPackageResponse.Error != nil {
+		return nil, c.convertRPCError(submitPackageResponse.Error)
+	}
+	return submitPackageResponse, nil
+}
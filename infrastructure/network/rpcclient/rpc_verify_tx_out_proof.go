@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// VerifyTxOutProof sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) VerifyTxOutProof(proof *appmessage.RPCTransactionMerkleProof) (*appmessage.VerifyTxOutProofResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewVerifyTxOutProofRequestMessage(proof))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdVerifyTxOutProofResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	verifyTxOutProofResponse := response.(*appmessage.VerifyTxOutProofResponseMessage)
+	if verifyTxOutProofResponse.Error != nil {
+		return nil, c.convertRPCError(verifyTxOutProofResponse.Error)
+	}
+	return verifyTxOutProofResponse, nil
+}
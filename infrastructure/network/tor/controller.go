@@ -0,0 +1,221 @@
+package tor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/network/controlline"
+	"github.com/pkg/errors"
+)
+
+const (
+	controlDialTimeout = 10 * time.Second
+
+	// replyOK is the status code of successful replies of the Tor control protocol
+	replyOK = 250
+
+	safeCookieServerToControllerKey = "Tor safe cookie authentication server-to-controller hash"
+	safeCookieControllerToServerKey = "Tor safe cookie authentication controller-to-server hash"
+	safeCookieNonceSize             = 32
+)
+
+// The authentication methods of the Tor control port, as listed by PROTOCOLINFO
+const (
+	authMethodNull           = "NULL"
+	authMethodHashedPassword = "HASHEDPASSWORD"
+	authMethodCookie         = "COOKIE"
+	authMethodSafeCookie     = "SAFECOOKIE"
+)
+
+// controller is a connection to the control port of a Tor process, as specified in
+// control-spec.txt of Tor
+type controller struct {
+	conn *textproto.Conn
+}
+
+// dialController connects to the Tor control port at address and authenticates to it,
+// with password if it's not empty, or else with the cookie or without authentication
+func dialController(address string, password string) (*controller, error) {
+	conn, err := net.DialTimeout("tcp", address, controlDialTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not connect to the Tor control port at %s", address)
+	}
+	c := &controller{conn: textproto.NewConn(conn)}
+
+	err = c.authenticate(password)
+	if err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *controller) close() error {
+	return c.conn.Close()
+}
+
+// command sends a command to the control port and returns the lines of its reply, without
+// their status codes
+func (c *controller) command(format string, args ...interface{}) ([]string, error) {
+	err := c.conn.PrintfLine(format, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	_, message, err := c.conn.ReadResponse(replyOK)
+	if err != nil {
+		keyword := strings.SplitN(format, " ", 2)[0]
+		return nil, errors.Wrapf(err, "the Tor control port rejected %s", keyword)
+	}
+	return strings.Split(message, "\n"), nil
+}
+
+func (c *controller) authenticate(password string) error {
+	lines, err := c.command("PROTOCOLINFO 1")
+	if err != nil {
+		return err
+	}
+	methods := make(map[string]bool)
+	var cookieFile string
+	for _, line := range lines {
+		keyword, arguments := parseReplyLine(line)
+		if keyword != "AUTH" {
+			continue
+		}
+		for _, method := range strings.Split(arguments["METHODS"], ",") {
+			methods[method] = true
+		}
+		cookieFile = arguments["COOKIEFILE"]
+	}
+
+	switch {
+	case password != "":
+		if !methods[authMethodHashedPassword] {
+			return errors.New("the Tor control port doesn't accept password authentication")
+		}
+		_, err = c.command("AUTHENTICATE %s", quote(password))
+	case methods[authMethodSafeCookie]:
+		err = c.authenticateSafeCookie(cookieFile)
+	case methods[authMethodCookie]:
+		var cookie []byte
+		cookie, err = os.ReadFile(cookieFile)
+		if err != nil {
+			return errors.Wrapf(err, "could not read the Tor control cookie")
+		}
+		_, err = c.command("AUTHENTICATE %s", hex.EncodeToString(cookie))
+	case methods[authMethodNull]:
+		_, err = c.command("AUTHENTICATE")
+	default:
+		return errors.New("the Tor control port requires a password, which can be set with --torpassword")
+	}
+	return err
+}
+
+// authenticateSafeCookie proves that the cookie is readable without sending it, so that
+// it isn't leaked to whatever listens on the control port address
+func (c *controller) authenticateSafeCookie(cookieFile string) error {
+	cookie, err := os.ReadFile(cookieFile)
+	if err != nil {
+		return errors.Wrapf(err, "could not read the Tor control cookie")
+	}
+	clientNonce := make([]byte, safeCookieNonceSize)
+	_, err = rand.Read(clientNonce)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	lines, err := c.command("AUTHCHALLENGE SAFECOOKIE %s", hex.EncodeToString(clientNonce))
+	if err != nil {
+		return err
+	}
+	_, arguments := parseReplyLine(lines[0])
+	serverHash, err := hex.DecodeString(arguments["SERVERHASH"])
+	if err != nil {
+		return errors.Wrapf(err, "the Tor control port replied with a malformed SERVERHASH")
+	}
+	serverNonce, err := hex.DecodeString(arguments["SERVERNONCE"])
+	if err != nil {
+		return errors.Wrapf(err, "the Tor control port replied with a malformed SERVERNONCE")
+	}
+
+	message := append(append(append([]byte{}, cookie...), clientNonce...), serverNonce...)
+	if !hmac.Equal(serverHash, safeCookieHash(safeCookieServerToControllerKey, message)) {
+		return errors.New("the Tor control port doesn't know the cookie")
+	}
+	_, err = c.command("AUTHENTICATE %s", hex.EncodeToString(safeCookieHash(safeCookieControllerToServerKey, message)))
+	return err
+}
+
+func safeCookieHash(key string, message []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(message)
+	return mac.Sum(nil)
+}
+
+// addOnion creates an onion service that forwards virtualPort to target, and returns its
+// service ID along with its private key. A new key is generated if privateKey is empty.
+// The onion service is removed by Tor once the control connection is closed.
+func (c *controller) addOnion(privateKey string, virtualPort uint16, target string) (
+	serviceID string, servicePrivateKey string, err error) {
+
+	keyArgument := privateKey
+	if keyArgument == "" {
+		keyArgument = "NEW:ED25519-V3"
+	}
+	lines, err := c.command("ADD_ONION %s Port=%d,%s", keyArgument, virtualPort, target)
+	if err != nil {
+		return "", "", err
+	}
+
+	servicePrivateKey = privateKey
+	for _, line := range lines {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "ServiceID":
+			serviceID = value
+		case "PrivateKey":
+			servicePrivateKey = value
+		}
+	}
+	if serviceID == "" {
+		return "", "", errors.New("the Tor control port didn't reply with the ID of the onion service")
+	}
+	return serviceID, servicePrivateKey, nil
+}
+
+// parseReplyLine splits a reply line of the form `KEYWORD KEY=VALUE KEY="QUOTED VALUE"`
+// into its keyword and arguments
+func parseReplyLine(line string) (keyword string, arguments map[string]string) {
+	arguments = make(map[string]string)
+	keyword, rest, _ := strings.Cut(line, " ")
+	for rest != "" {
+		rest = strings.TrimLeft(rest, " ")
+		key, afterKey, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		var value string
+		if strings.HasPrefix(afterKey, `"`) {
+			value, rest = controlline.Unquote(afterKey[1:])
+		} else {
+			value, rest, _ = strings.Cut(afterKey, " ")
+		}
+		arguments[key] = value
+	}
+	return keyword, arguments
+}
+
+func quote(s string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+	return fmt.Sprintf(`"%s"`, escaped)
+}
@@ -0,0 +1,7 @@
+package tor
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+)
+
+var log = logger.RegisterSubSystem("TORC")
@@ -0,0 +1,95 @@
+package tor
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base32"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	onionSuffix       = ".onion"
+	onionVersion      = 3
+	onionChecksumSize = 2
+
+	// onionHostLength is the length of a v3 onion host, including its .onion suffix
+	onionHostLength = 56 + len(onionSuffix)
+)
+
+var onionEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// onionCatPrefix is the IPv6 prefix of OnionCat (fd87:d87e:eb43::/48)
+var onionCatPrefix = []byte{0xfd, 0x87, 0xd8, 0x7e, 0xeb, 0x43}
+
+// IsOnionHost returns whether host is a valid v3 onion service host
+func IsOnionHost(host string) bool {
+	_, err := DecodeOnionHost(host)
+	return err == nil
+}
+
+// DecodeOnionHost returns the ed25519 public key of the given v3 onion service host,
+// after verifying its checksum and version
+func DecodeOnionHost(host string) (ed25519.PublicKey, error) {
+	host = strings.ToLower(host)
+	if len(host) != onionHostLength || !strings.HasSuffix(host, onionSuffix) {
+		return nil, errors.Errorf("%s is not a v3 onion host", host)
+	}
+	decoded, err := onionEncoding.DecodeString(strings.ToUpper(strings.TrimSuffix(host, onionSuffix)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s is not a v3 onion host", host)
+	}
+
+	publicKey := ed25519.PublicKey(decoded[:ed25519.PublicKeySize])
+	checksum := decoded[ed25519.PublicKeySize : ed25519.PublicKeySize+onionChecksumSize]
+	version := decoded[ed25519.PublicKeySize+onionChecksumSize]
+	if version != onionVersion {
+		return nil, errors.Errorf("%s has onion version %d instead of %d", host, version, onionVersion)
+	}
+	expectedChecksum := onionChecksum(publicKey)
+	if checksum[0] != expectedChecksum[0] || checksum[1] != expectedChecksum[1] {
+		return nil, errors.Errorf("%s has an invalid checksum", host)
+	}
+	return publicKey, nil
+}
+
+// EncodeOnionHost returns the v3 onion service host of the given ed25519 public key
+func EncodeOnionHost(publicKey ed25519.PublicKey) string {
+	decoded := make([]byte, 0, ed25519.PublicKeySize+onionChecksumSize+1)
+	decoded = append(decoded, publicKey...)
+	decoded = append(decoded, onionChecksum(publicKey)...)
+	decoded = append(decoded, onionVersion)
+	return strings.ToLower(onionEncoding.EncodeToString(decoded)) + onionSuffix
+}
+
+// onionChecksum is the checksum of v3 onion hosts, as defined in the rend-spec-v3
+// of Tor: SHA3-256(".onion checksum" | PUBKEY | VERSION)[:2]
+func onionChecksum(publicKey ed25519.PublicKey) []byte {
+	hasher := sha3.New256()
+	hasher.Write([]byte(".onion checksum"))
+	hasher.Write(publicKey)
+	hasher.Write([]byte{onionVersion})
+	return hasher.Sum(nil)[:onionChecksumSize]
+}
+
+// OnionCatIP returns the IPv6 address that stands for the given v3 onion service host
+// where an IP is required: the OnionCat prefix followed by the first bytes of its public
+// key. Unlike the IPs of v2 onion hosts, it's not enough to recover the host.
+func OnionCatIP(host string) (net.IP, error) {
+	publicKey, err := DecodeOnionHost(host)
+	if err != nil {
+		return nil, err
+	}
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, onionCatPrefix)
+	copy(ip[len(onionCatPrefix):], publicKey)
+	return ip, nil
+}
+
+// IsOnionCatIP returns whether ip is in the OnionCat range, which stands for onion hosts
+func IsOnionCatIP(ip net.IP) bool {
+	return len(ip) == net.IPv6len && bytes.HasPrefix(ip, onionCatPrefix)
+}
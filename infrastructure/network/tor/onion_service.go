@@ -0,0 +1,120 @@
+package tor
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/pkg/errors"
+)
+
+const privateKeyFileName = "onion_v3_private_key"
+
+// OnionService is a v3 onion service, created through the Tor control port, that
+// forwards the incoming connections of the Tor network to the P2P listener of the node.
+// Its private key is kept in the app directory, so that the node keeps the same onion
+// address across restarts.
+type OnionService struct {
+	controlAddress string
+	password       string
+	privateKeyFile string
+	target         string
+	virtualPort    uint16
+
+	controller *controller
+}
+
+// NewOnionService returns an OnionService for the first P2P listener of the node, or
+// nil if --torcontrol isn't set
+func NewOnionService(cfg *config.Config) (*OnionService, error) {
+	if cfg.TorControl == "" {
+		return nil, nil
+	}
+
+	host, portString, err := net.SplitHostPort(cfg.Listeners[0])
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// Tor runs on the same machine, so a listener on all interfaces is reached
+	// through the loopback interface
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		if ip != nil && ip.To4() == nil {
+			host = net.IPv6loopback.String()
+		} else {
+			host = "127.0.0.1"
+		}
+	}
+
+	return &OnionService{
+		controlAddress: cfg.TorControl,
+		password:       cfg.TorPassword,
+		privateKeyFile: filepath.Join(cfg.AppDir, privateKeyFileName),
+		target:         net.JoinHostPort(host, portString),
+		virtualPort:    uint16(port),
+	}, nil
+}
+
+// Start creates the onion service and returns its address, to be advertised to peers
+func (s *OnionService) Start() (*appmessage.NetAddress, error) {
+	privateKey, err := s.loadPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	controller, err := dialController(s.controlAddress, s.password)
+	if err != nil {
+		return nil, err
+	}
+	serviceID, privateKey, err := controller.addOnion(privateKey, s.virtualPort, s.target)
+	if err != nil {
+		controller.close()
+		return nil, err
+	}
+	s.controller = controller
+
+	err = s.savePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	address := appmessage.NewNetAddressOnion(serviceID+onionSuffix, s.virtualPort)
+	log.Infof("Onion service %s forwards to %s", address, s.target)
+	return address, nil
+}
+
+// Stop removes the onion service, by closing the connection to the Tor control port
+func (s *OnionService) Stop() error {
+	if s.controller == nil {
+		return nil
+	}
+	err := s.controller.close()
+	s.controller = nil
+	return errors.WithStack(err)
+}
+
+func (s *OnionService) loadPrivateKey() (string, error) {
+	privateKey, err := os.ReadFile(s.privateKeyFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "could not read the onion service private key")
+	}
+	return strings.TrimSpace(string(privateKey)), nil
+}
+
+func (s *OnionService) savePrivateKey(privateKey string) error {
+	err := os.WriteFile(s.privateKeyFile, []byte(privateKey), 0600)
+	if err != nil {
+		return errors.Wrapf(err, "could not write the onion service private key")
+	}
+	return nil
+}
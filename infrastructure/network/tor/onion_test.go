@@ -0,0 +1,82 @@
+package tor
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestOnionHost(t *testing.T) {
+	const host = "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad.onion"
+
+	publicKey, err := DecodeOnionHost(host)
+	if err != nil {
+		t.Fatalf("DecodeOnionHost: %+v", err)
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		t.Fatalf("DecodeOnionHost: expected a public key of %d bytes but got %d bytes",
+			ed25519.PublicKeySize, len(publicKey))
+	}
+	encodedHost := EncodeOnionHost(publicKey)
+	if encodedHost != host {
+		t.Fatalf("EncodeOnionHost: expected %s but got %s", host, encodedHost)
+	}
+
+	tests := []struct {
+		host        string
+		isOnionHost bool
+	}{
+		{host: host, isOnionHost: true},
+		{host: "DUCKDUCKGOGG42XJOC72X3SJASOWOARFBGCMVFIMAFTT6TWAGSWZCZAD.ONION", isOnionHost: true},
+		// Invalid checksum
+		{host: "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczae.onion", isOnionHost: false},
+		// v2 onion host
+		{host: "expyuzz4wqqyqhjn.onion", isOnionHost: false},
+		{host: "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad.com", isOnionHost: false},
+		{host: "127.0.0.1", isOnionHost: false},
+	}
+	for _, test := range tests {
+		isOnionHost := IsOnionHost(test.host)
+		if isOnionHost != test.isOnionHost {
+			t.Errorf("IsOnionHost(%s): expected %t but got %t", test.host, test.isOnionHost, isOnionHost)
+		}
+	}
+}
+
+func TestOnionCatIP(t *testing.T) {
+	const host = "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad.onion"
+
+	ip, err := OnionCatIP(host)
+	if err != nil {
+		t.Fatalf("OnionCatIP: %+v", err)
+	}
+	if !IsOnionCatIP(ip) {
+		t.Fatalf("IsOnionCatIP: expected %s to be an OnionCat IP", ip)
+	}
+	publicKey, err := DecodeOnionHost(host)
+	if err != nil {
+		t.Fatalf("DecodeOnionHost: %+v", err)
+	}
+	if !bytes.Equal(ip[len(onionCatPrefix):], publicKey[:len(ip)-len(onionCatPrefix)]) {
+		t.Fatalf("OnionCatIP: expected %s to end with the start of the public key", ip)
+	}
+
+	_, err = OnionCatIP("expyuzz4wqqyqhjn.onion")
+	if err == nil {
+		t.Fatalf("OnionCatIP: expected an error for a v2 onion host")
+	}
+}
+
+func TestParseReplyLine(t *testing.T) {
+	keyword, arguments := parseReplyLine(
+		`AUTH METHODS=COOKIE,SAFECOOKIE COOKIEFILE="/var/run/tor/control \"auth\".cookie"`)
+	if keyword != "AUTH" {
+		t.Fatalf("parseReplyLine: expected keyword AUTH but got %s", keyword)
+	}
+	if arguments["METHODS"] != "COOKIE,SAFECOOKIE" {
+		t.Fatalf("parseReplyLine: unexpected METHODS %s", arguments["METHODS"])
+	}
+	if arguments["COOKIEFILE"] != `/var/run/tor/control "auth".cookie` {
+		t.Fatalf("parseReplyLine: unexpected COOKIEFILE %s", arguments["COOKIEFILE"])
+	}
+}
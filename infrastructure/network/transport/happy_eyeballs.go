@@ -0,0 +1,108 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// happyEyeballsHeadStart is how long a connection attempt goes on before the next
+// one is started, as recommended by RFC 8305
+const happyEyeballsHeadStart = 250 * time.Millisecond
+
+// interleaveAddressFamilies orders ips for dialing, alternating between the IPv6
+// and the IPv4 addresses, starting with IPv6. The order within each family is kept.
+func interleaveAddressFamilies(ips []net.IP) []net.IP {
+	var ipv4s, ipv6s []net.IP
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			ipv4s = append(ipv4s, ip)
+		} else {
+			ipv6s = append(ipv6s, ip)
+		}
+	}
+
+	interleaved := make([]net.IP, 0, len(ips))
+	for i := 0; i < len(ipv4s) || i < len(ipv6s); i++ {
+		if i < len(ipv6s) {
+			interleaved = append(interleaved, ipv6s[i])
+		}
+		if i < len(ipv4s) {
+			interleaved = append(interleaved, ipv4s[i])
+		}
+	}
+	return interleaved
+}
+
+type dialResult struct {
+	connection net.Conn
+	err        error
+}
+
+// dialHappyEyeballs connects to one of ips at port, as described in RFC 6555: the
+// addresses are dialed in the order of interleaveAddressFamilies, and each attempt
+// starts once the previous one failed or had a head start of headStart, so that a
+// broken IPv6 route delays the connection by no more than headStart. The first
+// connection to be established is returned, and the rest are closed.
+func dialHappyEyeballs(ctx context.Context, dial DialFunc, ips []net.IP, port string,
+	headStart time.Duration) (net.Conn, error) {
+
+	ips = interleaveAddressFamilies(ips)
+	results := make(chan dialResult, len(ips))
+	started := 0
+	startNext := func() {
+		address := net.JoinHostPort(ips[started].String(), port)
+		timeout := timeoutFromContext(ctx)
+		started++
+		go func() {
+			connection, err := dial("tcp", address, timeout)
+			results <- dialResult{connection: connection, err: err}
+		}()
+	}
+	// closeLateConnections closes the connections that are established after
+	// the dial returned
+	closeLateConnections := func(pending int) {
+		go func() {
+			for i := 0; i < pending; i++ {
+				result := <-results
+				if result.err == nil {
+					result.connection.Close()
+				}
+			}
+		}()
+	}
+
+	headStartTimer := time.NewTimer(headStart)
+	defer headStartTimer.Stop()
+
+	startNext()
+	finished := 0
+	var firstErr error
+	for {
+		select {
+		case result := <-results:
+			finished++
+			if result.err == nil {
+				closeLateConnections(started - finished)
+				return result.connection, nil
+			}
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			if started < len(ips) {
+				startNext()
+				headStartTimer.Reset(headStart)
+			} else if finished == started {
+				return nil, firstErr
+			}
+		case <-headStartTimer.C:
+			if started < len(ips) {
+				startNext()
+				headStartTimer.Reset(headStart)
+			}
+		case <-ctx.Done():
+			closeLateConnections(started - finished)
+			return nil, ctx.Err()
+		}
+	}
+}
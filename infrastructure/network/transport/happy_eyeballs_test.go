@@ -0,0 +1,189 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestInterleaveAddressFamilies(t *testing.T) {
+	ips := []net.IP{
+		net.ParseIP("1.1.1.1"),
+		net.ParseIP("1.1.1.2"),
+		net.ParseIP("1.1.1.3"),
+		net.ParseIP("2001:4860::1"),
+		net.ParseIP("2001:4860::2"),
+	}
+	expected := []net.IP{ips[3], ips[0], ips[4], ips[1], ips[2]}
+	interleaved := interleaveAddressFamilies(ips)
+	if !reflect.DeepEqual(interleaved, expected) {
+		t.Fatalf("Expected %s but got %s", expected, interleaved)
+	}
+}
+
+// fakeConn is a net.Conn that records whether it was closed
+type fakeConn struct {
+	net.Conn
+	address string
+	closed  chan struct{}
+}
+
+func (c *fakeConn) Close() error {
+	close(c.closed)
+	return nil
+}
+
+// fakeDial returns a DialFunc that connects to the addresses in delays after their
+// delay, and fails to connect to the rest right away
+func fakeDial(delays map[string]time.Duration, dialed chan<- *fakeConn) DialFunc {
+	return func(network string, address string, timeout time.Duration) (net.Conn, error) {
+		delay, ok := delays[address]
+		if !ok {
+			return nil, errors.Errorf("connection to %s refused", address)
+		}
+		time.Sleep(delay)
+		connection := &fakeConn{address: address, closed: make(chan struct{})}
+		if dialed != nil {
+			dialed <- connection
+		}
+		return connection, nil
+	}
+}
+
+func TestDialHappyEyeballs(t *testing.T) {
+	ipv6 := net.ParseIP("2001:4860::1")
+	ipv4 := net.ParseIP("1.1.1.1")
+	ips := []net.IP{ipv4, ipv6}
+	const headStart = 50 * time.Millisecond
+
+	tests := []struct {
+		name            string
+		delays          map[string]time.Duration
+		expectedAddress string
+	}{
+		{
+			name:            "IPv6 connects within its head start",
+			delays:          map[string]time.Duration{"[2001:4860::1]:16111": 0, "1.1.1.1:16111": 0},
+			expectedAddress: "[2001:4860::1]:16111",
+		},
+		{
+			name:            "IPv6 hangs past its head start",
+			delays:          map[string]time.Duration{"[2001:4860::1]:16111": time.Second, "1.1.1.1:16111": 0},
+			expectedAddress: "1.1.1.1:16111",
+		},
+		{
+			name:            "IPv6 is refused",
+			delays:          map[string]time.Duration{"1.1.1.1:16111": 0},
+			expectedAddress: "1.1.1.1:16111",
+		},
+	}
+	for _, test := range tests {
+		dialed := make(chan *fakeConn, len(ips))
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		connection, err := dialHappyEyeballs(ctx, fakeDial(test.delays, dialed), ips, "16111", headStart)
+		cancel()
+		if err != nil {
+			t.Fatalf("%s: dialHappyEyeballs: %s", test.name, err)
+		}
+		if connection.(*fakeConn).address != test.expectedAddress {
+			t.Fatalf("%s: expected a connection to %s but got one to %s",
+				test.name, test.expectedAddress, connection.(*fakeConn).address)
+		}
+	}
+}
+
+func TestDialHappyEyeballsClosesLateConnections(t *testing.T) {
+	ips := []net.IP{net.ParseIP("2001:4860::1"), net.ParseIP("1.1.1.1")}
+	delays := map[string]time.Duration{"[2001:4860::1]:16111": 100 * time.Millisecond, "1.1.1.1:16111": 0}
+	dialed := make(chan *fakeConn, len(ips))
+
+	connection, err := dialHappyEyeballs(context.Background(), fakeDial(delays, dialed), ips, "16111",
+		10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("dialHappyEyeballs: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < len(ips); i++ {
+		dialedConnection := <-dialed
+		if dialedConnection == connection {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case <-dialedConnection.closed:
+			case <-time.After(5 * time.Second):
+				t.Errorf("The connection to %s that lost the race was never closed", dialedConnection.address)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDialHappyEyeballsFailure(t *testing.T) {
+	ips := []net.IP{net.ParseIP("2001:4860::1"), net.ParseIP("1.1.1.1")}
+	_, err := dialHappyEyeballs(context.Background(), fakeDial(nil, nil), ips, "16111", time.Second)
+	if err == nil {
+		t.Fatalf("Expected dialHappyEyeballs to fail when all the addresses refuse connections")
+	}
+	if err.Error() != "connection to [2001:4860::1]:16111 refused" {
+		t.Fatalf("Expected the error of the first attempt but got: %s", err)
+	}
+}
+
+func TestTCPTransportReachability(t *testing.T) {
+	reachability := &Reachability{
+		IPv4: NetworkReachability{Limited: true},
+		IPv6: NetworkReachability{SelfTestError: errors.New("no route")},
+	}
+	tcpTransport := NewTCP(nil, nil, reachability)
+
+	tests := []struct {
+		address         string
+		expectedCanDial bool
+	}{
+		{address: "1.1.1.1:16111", expectedCanDial: false},
+		{address: "[2001:4860::1]:16111", expectedCanDial: false},
+		{address: "127.0.0.1:16111", expectedCanDial: true},
+		{address: "192.168.0.1:16111", expectedCanDial: true},
+		{address: "[fd00::1]:16111", expectedCanDial: true},
+		{address: "seed.example.com:16111", expectedCanDial: true},
+		{address: "peer.i2p:0", expectedCanDial: false},
+	}
+	for _, test := range tests {
+		canDial := tcpTransport.CanDial(test.address)
+		if canDial != test.expectedCanDial {
+			t.Errorf("Expected CanDial(%s) to be %t but got %t", test.address, test.expectedCanDial, canDial)
+		}
+	}
+}
+
+func TestTCPTransportResolvesReachableAddresses(t *testing.T) {
+	lookup := func(host string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("1.1.1.1"), net.ParseIP("2001:4860::1")}, nil
+	}
+	delays := map[string]time.Duration{"[2001:4860::1]:16111": 0, "1.1.1.1:16111": 0}
+	reachability := &Reachability{IPv4: NetworkReachability{Limited: true}}
+	tcpTransport := NewTCP(fakeDial(delays, nil), lookup, reachability)
+
+	connection, err := tcpTransport.Dial(context.Background(), "seed.example.com:16111")
+	if err != nil {
+		t.Fatalf("Dial: %s", err)
+	}
+	if connection.(*fakeConn).address != "[2001:4860::1]:16111" {
+		t.Fatalf("Expected the IPv6 address to be dialed but got %s", connection.(*fakeConn).address)
+	}
+
+	reachability.IPv6.Limited = true
+	_, err = tcpTransport.Dial(context.Background(), "seed.example.com:16111")
+	if err == nil {
+		t.Fatalf("Expected Dial to fail when none of the addresses are reachable")
+	}
+}
@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// NetworkReachability is whether peers in an IP network can be connected to directly
+type NetworkReachability struct {
+	// Limited is set when connections over the network are turned off by the config
+	Limited bool
+
+	// SelfTestError is why this host was found unable to reach the network, or
+	// nil if it passed the self-test
+	SelfTestError error
+}
+
+// IsReachable returns whether peers in the network can be connected to
+func (nr *NetworkReachability) IsReachable() bool {
+	return !nr.Limited && nr.SelfTestError == nil
+}
+
+// Reachability tells which of the IP networks peers can be reached in. It only
+// applies to public addresses: local and private ones are always dialed.
+type Reachability struct {
+	IPv4 NetworkReachability
+	IPv6 NetworkReachability
+}
+
+// selfTestIPv4 and selfTestIPv6 are the addresses that the self-tests look up the
+// route to. They're documentation addresses (RFC 5737 and RFC 3849), which are
+// routed like any other public address, and nothing is ever sent to them.
+var (
+	selfTestIPv4 = net.ParseIP("192.0.2.1")
+	selfTestIPv6 = net.ParseIP("2001:db8::1")
+)
+
+// TestReachability runs the self-tests of the IP networks, unless connections go
+// through a proxy, which makes the routes of this host irrelevant. IPv4 is limited
+// if isIPv6Only is set.
+func TestReachability(isIPv6Only bool, isProxied bool) *Reachability {
+	reachability := &Reachability{
+		IPv4: NetworkReachability{Limited: isIPv6Only},
+	}
+	if isProxied {
+		return reachability
+	}
+	if !reachability.IPv4.Limited {
+		reachability.IPv4.SelfTestError = selfTest(selfTestIPv4)
+	}
+	reachability.IPv6.SelfTestError = selfTest(selfTestIPv6)
+	return reachability
+}
+
+// selfTest checks that this host has a route to target, and an address to connect
+// from that peers can answer to. Connecting a UDP socket only picks the route and
+// the local address, so no packet is sent.
+func selfTest(target net.IP) error {
+	network := "udp4"
+	if target.To4() == nil {
+		network = "udp6"
+	}
+	connection, err := net.DialUDP(network, nil, &net.UDPAddr{IP: target, Port: 9})
+	if err != nil {
+		return errors.Wrapf(err, "no route to %s", target)
+	}
+	defer connection.Close()
+
+	// Unlike IPv4, where a private address behind a NAT is the norm, an IPv6 host
+	// that only has a link-local or a unique local address can't be reached from the
+	// internet, and usually can't reach it either
+	localIP := connection.LocalAddr().(*net.UDPAddr).IP
+	if network == "udp6" && (!localIP.IsGlobalUnicast() || localIP.IsPrivate()) {
+		return errors.Errorf("the route to %s has no global address, but %s", target, localIP)
+	}
+	return nil
+}
+
+// CanReach returns whether ip can be connected to
+func (r *Reachability) CanReach(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() {
+		return true
+	}
+	if ip.To4() != nil {
+		return r.IPv4.IsReachable()
+	}
+	return r.IPv6.IsReachable()
+}
@@ -0,0 +1,109 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/network/tor"
+	"github.com/pkg/errors"
+)
+
+const (
+	tcpDialTimeout = 1 * time.Second
+
+	// A host name has to be resolved first, and then its addresses may be dialed
+	// one after the other
+	hostNameDialTimeout = 5 * time.Second
+
+	// Building a circuit to an onion service takes a lot longer than a TCP handshake
+	onionDialTimeout = 30 * time.Second
+
+	i2pSuffix = ".i2p"
+)
+
+// DialFunc connects to the given address on the named network, within the given timeout
+type DialFunc func(network string, address string, timeout time.Duration) (net.Conn, error)
+
+// LookupFunc resolves a host name to its IP addresses
+type LookupFunc func(host string) ([]net.IP, error)
+
+type tcpTransport struct {
+	dial         DialFunc
+	lookup       LookupFunc
+	reachability *Reachability
+}
+
+// NewTCP returns a transport that connects to peers over TCP using dial, which either
+// connects through a proxy or directly, as it does if it's nil. Onion hosts are reachable
+// if dial goes through Tor. Incoming connections arrive at the P2P listeners of the node.
+//
+// IP addresses in the networks that reachability finds unreachable aren't dialed. If lookup
+// is set, host names are resolved with it, and their addresses are raced against each other
+// by dialHappyEyeballs. Otherwise, they're passed to dial as is, which is what a proxy needs.
+func NewTCP(dial DialFunc, lookup LookupFunc, reachability *Reachability) Transport {
+	if dial == nil {
+		dial = net.DialTimeout
+	}
+	if reachability == nil {
+		reachability = &Reachability{}
+	}
+	return &tcpTransport{dial: dial, lookup: lookup, reachability: reachability}
+}
+
+func (t *tcpTransport) Name() string {
+	return "tcp"
+}
+
+func (t *tcpTransport) CanDial(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return t.reachability.CanReach(ip)
+	}
+	return !strings.HasSuffix(strings.ToLower(host), i2pSuffix)
+}
+
+func (t *tcpTransport) DialTimeout(address string) time.Duration {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil {
+		return tcpDialTimeout
+	}
+	if tor.IsOnionHost(host) {
+		return onionDialTimeout
+	}
+	return hostNameDialTimeout
+}
+
+func (t *tcpTransport) Dial(ctx context.Context, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || t.lookup == nil || net.ParseIP(host) != nil || tor.IsOnionHost(host) {
+		return t.dial("tcp", address, timeoutFromContext(ctx))
+	}
+
+	ips, err := t.lookup(host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error resolving %s", host)
+	}
+	reachableIPs := make([]net.IP, 0, len(ips))
+	for _, ip := range ips {
+		if t.reachability.CanReach(ip) {
+			reachableIPs = append(reachableIPs, ip)
+		}
+	}
+	if len(reachableIPs) == 0 {
+		return nil, errors.Errorf("none of the %d addresses of %s is in a reachable network", len(ips), host)
+	}
+	return dialHappyEyeballs(ctx, t.dial, reachableIPs, port, happyEyeballsHeadStart)
+}
+
+func (t *tcpTransport) Listen() (net.Listener, error) {
+	return nil, nil
+}
+
+func (t *tcpTransport) Close() error {
+	return nil
+}
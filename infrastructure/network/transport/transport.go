@@ -0,0 +1,56 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Transport carries P2P connections over a network, such as the internet or an
+// anonymity network. The P2P server dials every address through the first of its
+// transports that can reach it.
+type Transport interface {
+	// Name is the name of the network that the transport carries connections over
+	Name() string
+
+	// CanDial returns whether the transport is able to reach the given address
+	CanDial(address string) bool
+
+	// DialTimeout returns how long connecting to the given address may take
+	DialTimeout(address string) time.Duration
+
+	// Dial connects to the given address, until ctx is done
+	Dial(ctx context.Context, address string) (net.Conn, error)
+
+	// Listen returns a listener for the incoming connections of the transport, or nil
+	// if they arrive at the P2P listeners of the node instead
+	Listen() (net.Listener, error)
+
+	// Close releases the resources of the transport
+	Close() error
+}
+
+// ErrNoTransport is the error returned when none of the transports can reach an address
+var ErrNoTransport = errors.New("ErrNoTransport")
+
+// ForAddress returns the first of the given transports that can reach address
+func ForAddress(transports []Transport, address string) (Transport, error) {
+	for _, transport := range transports {
+		if transport.CanDial(address) {
+			return transport, nil
+		}
+	}
+	return nil, errors.Wrapf(ErrNoTransport, "none of the transports can reach %s", address)
+}
+
+// timeoutFromContext returns the time left until the deadline of ctx, or 0 if
+// it has none
+func timeoutFromContext(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+	return time.Until(deadline)
+}
@@ -0,0 +1,2 @@
+// Package diskspace reports the disk space that is available to kaspad.
+package diskspace
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package diskspace
+
+// Available returns false, since the available space can't be determined
+// on this platform.
+func Available(_ string) (uint64, bool, error) {
+	return 0, false, nil
+}
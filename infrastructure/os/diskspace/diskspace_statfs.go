@@ -0,0 +1,21 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package diskspace
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// Available returns the number of bytes that are available to unprivileged
+// users on the filesystem of the given path, which must exist. It returns
+// false if the available space can't be determined on this platform.
+func Available(path string) (uint64, bool, error) {
+	var stat unix.Statfs_t
+	err := unix.Statfs(path, &stat)
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), true, nil
+}
@@ -0,0 +1,22 @@
+package diskspace
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/sys/windows"
+)
+
+// Available returns the number of bytes that are available to the user
+// on the volume of the given path, which must exist. It returns false
+// if the available space can't be determined on this platform.
+func Available(path string) (uint64, bool, error) {
+	pathPointer, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+	var freeBytesAvailable uint64
+	err = windows.GetDiskFreeSpaceEx(pathPointer, &freeBytesAvailable, nil, nil)
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+	return freeBytesAvailable, true, nil
+}
@@ -0,0 +1,149 @@
+// Package notifycmd runs external commands that are configured to be notified of events,
+// in the manner of bitcoind's -blocknotify.
+package notifycmd
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/panics"
+)
+
+// maxPendingCommands is the number of commands that may wait for a free process
+// before the commands of new events are dropped
+const maxPendingCommands = 1000
+
+// Notifier runs a command for every event it's notified of. The commands run in the
+// background, at most maxProcesses of them at once, so that a slow command never
+// blocks the caller. The commands of events that arrive while too many commands are
+// pending are dropped.
+type Notifier struct {
+	name         string
+	command      string
+	maxProcesses int
+	log          *logger.Logger
+	spawn        func(name string, spawnedFunction func())
+
+	commands chan string
+	quit     chan struct{}
+	stopOnce sync.Once
+}
+
+// New creates a Notifier that runs the given command with the given name, which is used
+// in its logs. It returns nil if the command is empty. Use Start() to begin running commands.
+func New(name, command string, maxProcesses int, log *logger.Logger) *Notifier {
+	if command == "" {
+		return nil
+	}
+	if maxProcesses < 1 {
+		maxProcesses = 1
+	}
+	return &Notifier{
+		name:         name,
+		command:      command,
+		maxProcesses: maxProcesses,
+		log:          log,
+		spawn:        panics.GoroutineWrapperFunc(log),
+		commands:     make(chan string, maxPendingCommands),
+		quit:         make(chan struct{}),
+	}
+}
+
+// Start starts the processes that run the commands
+func (n *Notifier) Start() {
+	for i := 0; i < n.maxProcesses; i++ {
+		n.spawn("notifycmd.Notifier.runCommands", n.runCommands)
+	}
+}
+
+// Stop stops running commands. Commands that are already running aren't interrupted,
+// and pending commands are dropped.
+func (n *Notifier) Stop() {
+	n.stopOnce.Do(func() {
+		close(n.quit)
+	})
+}
+
+// Notify runs the command of the notifier with its placeholders replaced by the given
+// values. The placeholder of a value is a '%' followed by its key, and '%%' stands for
+// '%'. Values are stripped of any character that isn't alphanumeric, '.', '_' or '-', so
+// that they can't inject shell syntax into the command.
+func (n *Notifier) Notify(values map[rune]string) {
+	select {
+	case <-n.quit:
+		return
+	default:
+	}
+
+	command := Expand(n.command, values)
+	select {
+	case n.commands <- command:
+	default:
+		n.log.Warnf("Too many %s commands are pending. Dropping `%s`", n.name, command)
+	}
+}
+
+func (n *Notifier) runCommands() {
+	for {
+		select {
+		case <-n.quit:
+			return
+		case command := <-n.commands:
+			n.run(command)
+		}
+	}
+}
+
+func (n *Notifier) run(command string) {
+	n.log.Debugf("Running the %s command `%s`", n.name, command)
+	output, err := shellCommand(command).CombinedOutput()
+	if err != nil {
+		n.log.Warnf("The %s command `%s` failed: %s. Its output: %s", n.name, command, err, output)
+	}
+}
+
+// Expand replaces the placeholders of the given command by the given values, as
+// described in Notify. Placeholders without a value are left as they are.
+func Expand(command string, values map[rune]string) string {
+	var builder strings.Builder
+	isPlaceholder := false
+	for _, r := range command {
+		if !isPlaceholder {
+			if r == '%' {
+				isPlaceholder = true
+			} else {
+				builder.WriteRune(r)
+			}
+			continue
+		}
+
+		isPlaceholder = false
+		if r == '%' {
+			builder.WriteRune('%')
+			continue
+		}
+		value, ok := values[r]
+		if !ok {
+			builder.WriteRune('%')
+			builder.WriteRune(r)
+			continue
+		}
+		builder.WriteString(sanitize(value))
+	}
+	if isPlaceholder {
+		builder.WriteRune('%')
+	}
+	return builder.String()
+}
+
+func sanitize(value string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+			r == '.' || r == '_' || r == '-' {
+
+			return r
+		}
+		return -1
+	}, value)
+}
@@ -0,0 +1,80 @@
+package notifycmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+)
+
+func TestExpand(t *testing.T) {
+	values := map[rune]string{'s': "abc123", 'w': "my wallet;rm -rf ~"}
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{command: "notify %s", expected: "notify abc123"},
+		{command: "notify %s %w", expected: "notify abc123 mywalletrm-rf"},
+		{command: "notify 100%% %s", expected: "notify 100% abc123"},
+		{command: "notify %x %s", expected: "notify %x abc123"},
+		{command: "notify %", expected: "notify %"},
+		{command: "notify", expected: "notify"},
+	}
+	for _, test := range tests {
+		expanded := Expand(test.command, values)
+		if expanded != test.expected {
+			t.Errorf("expected %s to be expanded to %s, but got %s", test.command, test.expected, expanded)
+		}
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command requires a POSIX shell")
+	}
+
+	if New("test", "", 1, logger.NewBackend().Logger("TEST")) != nil {
+		t.Fatalf("expected no notifier for an empty command")
+	}
+
+	outputDir := t.TempDir()
+	notifier := New("test", "touch "+outputDir+"/%s", 2, logger.NewBackend().Logger("TEST"))
+	notifier.Start()
+	defer notifier.Stop()
+
+	names := []string{"a", "b", "c"}
+	for _, name := range names {
+		notifier.Notify(map[rune]string{'s': name})
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for _, name := range names {
+		for {
+			_, err := os.Stat(filepath.Join(outputDir, name))
+			if err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("the command wasn't run for %s: %s", name, err)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	notifier.Stop()
+	notifier.Notify(map[rune]string{'s': "d"})
+	time.Sleep(100 * time.Millisecond)
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		t.Fatalf("ReadDir: %s", err)
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "d") {
+			t.Fatalf("expected no command to run after the notifier was stopped")
+		}
+	}
+}
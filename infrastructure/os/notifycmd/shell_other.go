@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package notifycmd
+
+import "os/exec"
+
+func shellCommand(command string) *exec.Cmd {
+	return exec.Command("/bin/sh", "-c", command)
+}
@@ -0,0 +1,9 @@
+package systemd
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/panics"
+)
+
+var log = logger.RegisterSubSystem("SYSD")
+var spawn = panics.GoroutineWrapperFunc(log)
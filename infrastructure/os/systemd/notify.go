@@ -0,0 +1,49 @@
+// Package systemd implements the sd_notify protocol, which lets systemd supervise
+// kaspad when it runs as a service of Type=notify.
+package systemd
+
+import (
+	"net"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// The states that are sent to systemd. See sd_notify(3).
+const (
+	Ready        = "READY=1"
+	Stopping     = "STOPPING=1"
+	WatchdogPing = "WATCHDOG=1"
+)
+
+// Status returns the state that sets the status line systemd shows for the service
+func Status(status string) string {
+	return "STATUS=" + status
+}
+
+// Notify sends the given states to systemd, one per line. It returns false if kaspad
+// wasn't started by systemd with a notification socket, in which case nothing is sent.
+func Notify(states ...string) (bool, error) {
+	socketAddress := os.Getenv("NOTIFY_SOCKET")
+	if socketAddress == "" {
+		return false, nil
+	}
+
+	// An address that starts with '@' is in the abstract namespace, which the net
+	// package handles by itself
+	connection, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketAddress, Net: "unixgram"})
+	if err != nil {
+		return false, errors.Wrapf(err, "error connecting to the systemd notification socket %s", socketAddress)
+	}
+	defer connection.Close()
+
+	message := ""
+	for _, state := range states {
+		message += state + "\n"
+	}
+	_, err = connection.Write([]byte(message))
+	if err != nil {
+		return false, errors.Wrapf(err, "error notifying systemd")
+	}
+	return true, nil
+}
@@ -0,0 +1,145 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// listenForNotifications creates a notification socket and points NOTIFY_SOCKET at it
+func listenForNotifications(t *testing.T) *net.UnixConn {
+	// The path of a unix socket is limited to about a hundred bytes, which the
+	// directory of t.TempDir() may exceed
+	directory, err := os.MkdirTemp("", "systemd")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %s", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(directory) })
+
+	socketAddress := filepath.Join(directory, "notify")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketAddress, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("ListenUnixgram: %s", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	t.Setenv("NOTIFY_SOCKET", socketAddress)
+	return listener
+}
+
+func receiveNotification(t *testing.T, listener *net.UnixConn) string {
+	err := listener.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err != nil {
+		t.Fatalf("SetReadDeadline: %s", err)
+	}
+	buffer := make([]byte, 1024)
+	n, err := listener.Read(buffer)
+	if err != nil {
+		t.Fatalf("Error receiving a notification: %s", err)
+	}
+	return string(buffer[:n])
+}
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	sent, err := Notify(Ready)
+	if err != nil {
+		t.Fatalf("Notify: %s", err)
+	}
+	if sent {
+		t.Fatalf("Expected nothing to be sent without a notification socket")
+	}
+
+	listener := listenForNotifications(t)
+	sent, err = Notify(Ready, Status("Started"))
+	if err != nil {
+		t.Fatalf("Notify: %s", err)
+	}
+	if !sent {
+		t.Fatalf("Expected the notification to be sent")
+	}
+	notification := receiveNotification(t, listener)
+	if notification != "READY=1\nSTATUS=Started\n" {
+		t.Fatalf("Unexpected notification %q", notification)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	tests := []struct {
+		watchdogUsec     string
+		watchdogPID      string
+		expectedInterval time.Duration
+		expectsError     bool
+	}{
+		{watchdogUsec: "", expectedInterval: 0},
+		{watchdogUsec: "30000000", expectedInterval: 30 * time.Second},
+		{watchdogUsec: "30000000", watchdogPID: strconv.Itoa(os.Getpid()), expectedInterval: 30 * time.Second},
+		{watchdogUsec: "30000000", watchdogPID: strconv.Itoa(os.Getpid() + 1), expectedInterval: 0},
+		{watchdogUsec: "0", expectsError: true},
+		{watchdogUsec: "thirty seconds", expectsError: true},
+	}
+	for _, test := range tests {
+		t.Setenv("WATCHDOG_USEC", test.watchdogUsec)
+		t.Setenv("WATCHDOG_PID", test.watchdogPID)
+		interval, err := WatchdogInterval()
+		if test.expectsError {
+			if err == nil {
+				t.Fatalf("WATCHDOG_USEC=%s: expected an error", test.watchdogUsec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("WATCHDOG_USEC=%s: %s", test.watchdogUsec, err)
+		}
+		if interval != test.expectedInterval {
+			t.Fatalf("WATCHDOG_USEC=%s, WATCHDOG_PID=%s: expected an interval of %s but got %s",
+				test.watchdogUsec, test.watchdogPID, test.expectedInterval, interval)
+		}
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	watchdog, err := NewWatchdog(nil)
+	if err != nil {
+		t.Fatalf("NewWatchdog: %s", err)
+	}
+	if watchdog != nil {
+		t.Fatalf("Expected no watchdog when it isn't enabled")
+	}
+
+	listener := listenForNotifications(t)
+	t.Setenv("WATCHDOG_USEC", "20000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	healthCheckResults := make(chan error, 2)
+	healthCheckResults <- errors.New("stuck")
+	healthCheckResults <- nil
+	watchdog, err = NewWatchdog(func() (string, error) {
+		select {
+		case err := <-healthCheckResults:
+			return "Synced", err
+		default:
+			return "Synced", nil
+		}
+	})
+	if err != nil {
+		t.Fatalf("NewWatchdog: %s", err)
+	}
+	watchdog.Start()
+	defer watchdog.Stop()
+
+	notification := receiveNotification(t, listener)
+	if strings.Contains(notification, WatchdogPing) || !strings.Contains(notification, "STATUS=Unhealthy: stuck") {
+		t.Fatalf("Expected a failed health check to report the failure without notifying the watchdog, "+
+			"but got %q", notification)
+	}
+	notification = receiveNotification(t, listener)
+	if notification != "WATCHDOG=1\nSTATUS=Synced\n" {
+		t.Fatalf("Expected a passing health check to notify the watchdog, but got %q", notification)
+	}
+}
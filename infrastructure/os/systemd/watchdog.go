@@ -0,0 +1,106 @@
+package systemd
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// HealthCheck reports whether kaspad is healthy, along with a short status that is
+// shown by systemd. A HealthCheck that hangs stops the watchdog notifications as
+// well, which is what lets systemd restart a node that got stuck.
+type HealthCheck func() (status string, err error)
+
+// WatchdogInterval returns the interval in which systemd expects the watchdog to be
+// notified, or zero if the watchdog isn't enabled for kaspad
+func WatchdogInterval() (time.Duration, error) {
+	watchdogUsec := os.Getenv("WATCHDOG_USEC")
+	if watchdogUsec == "" {
+		return 0, nil
+	}
+	// WATCHDOG_PID is set when the variables might have been inherited by a child
+	// process, in which case the watchdog is only meant for the process it names
+	watchdogPID := os.Getenv("WATCHDOG_PID")
+	if watchdogPID != "" && watchdogPID != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+
+	microseconds, err := strconv.ParseUint(watchdogUsec, 10, 63)
+	if err != nil || microseconds == 0 {
+		return 0, errors.Errorf("WATCHDOG_USEC must be a positive number of microseconds, but is %s",
+			watchdogUsec)
+	}
+	return time.Duration(microseconds) * time.Microsecond, nil
+}
+
+// Watchdog notifies the systemd watchdog for as long as its health check passes
+type Watchdog struct {
+	interval    time.Duration
+	healthCheck HealthCheck
+
+	quit     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWatchdog creates a Watchdog that runs the given health check. It returns nil if
+// the watchdog isn't enabled for kaspad. Use Start() to begin notifying systemd.
+func NewWatchdog(healthCheck HealthCheck) (*Watchdog, error) {
+	interval, err := WatchdogInterval()
+	if err != nil || interval == 0 {
+		return nil, err
+	}
+	return &Watchdog{
+		// systemd recommends notifying at half the interval, so that a single late
+		// notification doesn't get kaspad restarted
+		interval:    interval / 2,
+		healthCheck: healthCheck,
+		quit:        make(chan struct{}),
+	}, nil
+}
+
+// Start starts notifying systemd
+func (w *Watchdog) Start() {
+	log.Infof("Notifying the systemd watchdog every %s", w.interval)
+	spawn("systemd.Watchdog.run", w.run)
+}
+
+// Stop stops notifying systemd
+func (w *Watchdog) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.quit)
+	})
+}
+
+func (w *Watchdog) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.notify()
+		select {
+		case <-w.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watchdog) notify() {
+	status, err := w.healthCheck()
+	if err != nil {
+		log.Warnf("The health check failed, so the systemd watchdog isn't notified: %s", err)
+		_, err = Notify(Status("Unhealthy: " + err.Error()))
+		if err != nil {
+			log.Warnf("%s", err)
+		}
+		return
+	}
+
+	_, err = Notify(WatchdogPing, Status(status))
+	if err != nil {
+		log.Warnf("%s", err)
+	}
+}
@@ -32,6 +32,13 @@ func (s *Service) performServiceCommand() error {
 	case "stop":
 		err = s.controlService(svc.Stop, svc.Stopped)
 
+	case "restart":
+		err = s.controlService(svc.Stop, svc.Stopped)
+		if err != nil {
+			return err
+		}
+		err = s.startService()
+
 	default:
 		err = errors.Errorf("invalid service command [%s]", command)
 	}
@@ -74,6 +81,7 @@ func (s *Service) installService() error {
 	service, err = serviceManager.CreateService(s.description.Name, exePath, mgr.Config{
 		DisplayName: s.description.DisplayName,
 		Description: s.description.Description,
+		StartType:   mgr.StartAutomatic,
 	})
 	if err != nil {
 		return err
@@ -138,8 +146,8 @@ func (s *Service) startService() error {
 }
 
 // controlService allows commands which change the status of the service. It
-// also waits for up to 10 seconds for the service to change to the passed
-// state.
+// also waits for the service to change to the passed state, for up to the time
+// the service may take to shut down.
 func (s *Service) controlService(c svc.Cmd, to svc.State) error {
 	// Connect to the windows service manager.
 	serviceManager, err := mgr.Connect()
@@ -160,7 +168,7 @@ func (s *Service) controlService(c svc.Cmd, to svc.State) error {
 	}
 
 	// Send the control message.
-	timeout := time.Now().Add(10 * time.Second)
+	timeout := time.Now().Add(stopWaitHint)
 	for status.State != to {
 		if timeout.Before(time.Now()) {
 			return errors.Errorf("timeout waiting for service to go "+
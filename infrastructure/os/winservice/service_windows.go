@@ -6,6 +6,7 @@ package winservice
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/btcsuite/winsvc/eventlog"
 	"github.com/btcsuite/winsvc/svc"
@@ -14,6 +15,22 @@ import (
 	"github.com/kaspanet/kaspad/version"
 )
 
+const (
+	// stopWaitHint is the time the service control manager is told to wait
+	// for kaspad to shut down. It matches the timeout of the graceful
+	// shutdown of kaspad.
+	stopWaitHint = 2 * time.Minute
+
+	// startWaitHint is the time the service control manager is told to wait
+	// for progress while kaspad starts. Loading the database may take much
+	// longer, so the progress is reported again every half of it.
+	startWaitHint = 30 * time.Second
+
+	// serviceFailedExitCode is the service specific exit code that is
+	// reported when kaspad stops because of an error
+	serviceFailedExitCode = 1
+)
+
 // Service houses the main service handler which handles all service
 // updates and launching the application's main.
 type Service struct {
@@ -40,7 +57,7 @@ func (s *Service) Start() error {
 	s.eventLog = elog
 	defer s.eventLog.Close()
 
-	err = svc.Run(s.description.Name, &Service{})
+	err = svc.Run(s.description.Name, s)
 	if err != nil {
 		s.eventLog.Error(1, fmt.Sprintf("Service start failed: %s", err))
 		return err
@@ -56,7 +73,11 @@ func (s *Service) Start() error {
 func (s *Service) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
 	// Service start is pending.
 	const cmdsAccepted = svc.AcceptStop | svc.AcceptShutdown
-	changes <- svc.Status{State: svc.StartPending}
+	startPending := svc.Status{State: svc.StartPending, WaitHint: uint32(startWaitHint.Milliseconds())}
+	changes <- startPending
+	startPendingTicker := time.NewTicker(startWaitHint / 2)
+	defer startPendingTicker.Stop()
+	startPendingTicks := startPendingTicker.C
 
 	// Start kaspadMain in a separate goroutine so the service can start
 	// quickly. Shutdown (along with a potential error) is reported via
@@ -69,8 +90,6 @@ func (s *Service) Execute(args []string, r <-chan svc.ChangeRequest, changes cha
 		doneChan <- err
 	})
 
-	// Service is now started.
-	changes <- svc.Status{State: svc.Running, Accepts: cmdsAccepted}
 loop:
 	for {
 		select {
@@ -81,8 +100,11 @@ loop:
 
 			case svc.Stop, svc.Shutdown:
 				// Service stop is pending. Don't accept any
-				// more commands while pending.
-				changes <- svc.Status{State: svc.StopPending}
+				// more commands while pending. Shutting down
+				// may take a while, so the service control
+				// manager is told not to give up on kaspad too
+				// soon.
+				changes <- svc.Status{State: svc.StopPending, WaitHint: uint32(stopWaitHint.Milliseconds())}
 
 				// Signal the main function to exit.
 				signal.ShutdownRequestChannel <- struct{}{}
@@ -92,12 +114,26 @@ loop:
 					"request #%d.", c))
 			}
 
+		case <-startPendingTicks:
+			startPending.CheckPoint++
+			changes <- startPending
+
 		case <-startedChan:
+			// Service is now started. It's only reported as
+			// running once kaspad is started, so that the
+			// service control manager doesn't consider the
+			// service up while the database is still loading.
+			startPendingTicks = nil
+			changes <- svc.Status{State: svc.Running, Accepts: cmdsAccepted}
 			s.logServiceStart()
 
 		case err := <-doneChan:
 			if err != nil {
 				s.eventLog.Error(1, err.Error())
+				// An exit code makes the service control
+				// manager apply the recovery actions of the
+				// service, such as restarting it.
+				return true, serviceFailedExitCode
 			}
 			break loop
 		}
@@ -116,4 +152,5 @@ func (s *Service) logServiceStart() {
 	message += fmt.Sprintf("Configuration file: %s\n", s.cfg.ConfigFile)
 	message += fmt.Sprintf("Application directory: %s\n", s.cfg.AppDir)
 	message += fmt.Sprintf("Logs directory: %s\n", s.cfg.LogDir)
+	s.eventLog.Info(1, message)
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"math/big"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/blockheader"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/merkle"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+)
+
+// blockMutation describes how a block was changed from the template it was built from
+type blockMutation string
+
+const (
+	noMutation                   blockMutation = "no mutation"
+	wrongHashMerkleRoot          blockMutation = "a wrong hash merkle root"
+	wrongAcceptedIDMerkleRoot    blockMutation = "a wrong accepted ID merkle root"
+	wrongUTXOCommitment          blockMutation = "a wrong UTXO commitment"
+	wrongBlueScore               blockMutation = "a wrong blue score"
+	wrongBlueWork                blockMutation = "a wrong blue work"
+	wrongDAAScore                blockMutation = "a wrong DAA score"
+	wrongBits                    blockMutation = "wrong bits"
+	zeroTimestamp                blockMutation = "a zero timestamp"
+	futureTimestamp              blockMutation = "a timestamp a day in the future"
+	wrongPruningPoint            blockMutation = "a wrong pruning point"
+	excessiveCoinbaseOutputValue blockMutation = "an excessive coinbase output value"
+	duplicateTransaction         blockMutation = "a duplicate transaction"
+)
+
+var blockMutations = []blockMutation{
+	wrongHashMerkleRoot,
+	wrongAcceptedIDMerkleRoot,
+	wrongUTXOCommitment,
+	wrongBlueScore,
+	wrongBlueWork,
+	wrongDAAScore,
+	wrongBits,
+	zeroTimestamp,
+	futureTimestamp,
+	wrongPruningPoint,
+	excessiveCoinbaseOutputValue,
+	duplicateTransaction,
+}
+
+const dayInMilliseconds = 24 * 60 * 60 * 1000
+
+// mutateBlock leaves about half of the blocks as they were built, so that the DAG keeps
+// growing, and applies a random mutation to the rest
+func (d *differential) mutateBlock(block *externalapi.DomainBlock) blockMutation {
+	if d.random.Intn(2) == 0 {
+		return noMutation
+	}
+	mutation := blockMutations[d.random.Intn(len(blockMutations))]
+
+	fields := newHeaderFields(block.Header)
+	switch mutation {
+	case wrongHashMerkleRoot:
+		fields.hashMerkleRoot = d.randomHash()
+	case wrongAcceptedIDMerkleRoot:
+		fields.acceptedIDMerkleRoot = d.randomHash()
+	case wrongUTXOCommitment:
+		fields.utxoCommitment = d.randomHash()
+	case wrongBlueScore:
+		fields.blueScore++
+	case wrongBlueWork:
+		fields.blueWork = new(big.Int).Add(fields.blueWork, big.NewInt(1))
+	case wrongDAAScore:
+		fields.daaScore++
+	case wrongBits:
+		// Incrementing the bits could set the sign bit of the compact target, which no
+		// nonce can solve
+		fields.bits--
+	case zeroTimestamp:
+		fields.timeInMilliseconds = 0
+	case futureTimestamp:
+		fields.timeInMilliseconds += dayInMilliseconds
+	case wrongPruningPoint:
+		fields.pruningPoint = d.randomHash()
+	case excessiveCoinbaseOutputValue:
+		coinbase := block.Transactions[transactionhelper.CoinbaseTransactionIndex]
+		if len(coinbase.Outputs) == 0 {
+			coinbase.Outputs = append(coinbase.Outputs,
+				&externalapi.DomainTransactionOutput{ScriptPublicKey: d.scriptPublicKey})
+		}
+		coinbase.Outputs[0].Value++
+		fields.hashMerkleRoot = merkle.CalculateHashMerkleRoot(block.Transactions)
+	case duplicateTransaction:
+		duplicated := block.Transactions[len(block.Transactions)-1]
+		block.Transactions = append(block.Transactions, duplicated.Clone())
+		fields.hashMerkleRoot = merkle.CalculateHashMerkleRoot(block.Transactions)
+	}
+	block.Header = fields.toHeader()
+	return mutation
+}
+
+func (d *differential) randomHash() *externalapi.DomainHash {
+	var hashBytes [externalapi.DomainHashSize]byte
+	d.random.Read(hashBytes[:])
+	return externalapi.NewDomainHashFromByteArray(&hashBytes)
+}
+
+// headerFields holds the fields of a header while they're changed, since a mutable header
+// allows changing only some of them
+type headerFields struct {
+	version              uint16
+	parents              []externalapi.BlockLevelParents
+	hashMerkleRoot       *externalapi.DomainHash
+	acceptedIDMerkleRoot *externalapi.DomainHash
+	utxoCommitment       *externalapi.DomainHash
+	timeInMilliseconds   int64
+	bits                 uint32
+	nonce                uint64
+	daaScore             uint64
+	blueScore            uint64
+	blueWork             *big.Int
+	pruningPoint         *externalapi.DomainHash
+}
+
+func newHeaderFields(header externalapi.BlockHeader) *headerFields {
+	return &headerFields{
+		version:              header.Version(),
+		parents:              header.Parents(),
+		hashMerkleRoot:       header.HashMerkleRoot(),
+		acceptedIDMerkleRoot: header.AcceptedIDMerkleRoot(),
+		utxoCommitment:       header.UTXOCommitment(),
+		timeInMilliseconds:   header.TimeInMilliseconds(),
+		bits:                 header.Bits(),
+		nonce:                header.Nonce(),
+		daaScore:             header.DAAScore(),
+		blueScore:            header.BlueScore(),
+		blueWork:             header.BlueWork(),
+		pruningPoint:         header.PruningPoint(),
+	}
+}
+
+func (f *headerFields) toHeader() externalapi.BlockHeader {
+	return blockheader.NewImmutableBlockHeader(f.version, f.parents, f.hashMerkleRoot, f.acceptedIDMerkleRoot,
+		f.utxoCommitment, f.timeInMilliseconds, f.bits, f.nonce, f.daaScore, f.blueScore, f.blueWork, f.pruningPoint)
+}
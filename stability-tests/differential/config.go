@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/stability-tests/common"
+	"github.com/kaspanet/kaspad/stability-tests/common/rpc"
+	"github.com/pkg/errors"
+
+	"github.com/jessevdk/go-flags"
+)
+
+const (
+	defaultLogFilename    = "differential.log"
+	defaultErrLogFilename = "differential_err.log"
+	defaultRounds         = 300
+)
+
+var (
+	// Default configuration options
+	defaultLogFile    = filepath.Join(common.DefaultAppDir, defaultLogFilename)
+	defaultErrLogFile = filepath.Join(common.DefaultAppDir, defaultErrLogFilename)
+)
+
+type configFlags struct {
+	rpc.Config
+	ReferenceRPCServer string `long:"reference-rpcserver" description:"RPC server of the reference implementation to compare with"`
+	Rounds             int    `long:"rounds" description:"Number of rounds. Every round submits a block and may submit transactions"`
+	Seed               int64  `long:"seed" description:"Seed of the random choice of the valid and invalid blocks and transactions"`
+	Profile            string `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
+	config.NetworkFlags
+}
+
+var cfg *configFlags
+
+func activeConfig() *configFlags {
+	return cfg
+}
+
+func parseConfig() error {
+	cfg = &configFlags{
+		Rounds: defaultRounds,
+	}
+	parser := flags.NewParser(cfg, flags.PrintErrors|flags.HelpFlag)
+
+	_, err := parser.Parse()
+
+	if err != nil {
+		if err, ok := err.(*flags.Error); ok && err.Type == flags.ErrHelp {
+			os.Exit(0)
+		}
+		return err
+	}
+
+	err = cfg.ResolveNetwork(parser)
+	if err != nil {
+		return err
+	}
+	err = rpc.ValidateRPCConfig(&cfg.Config)
+	if err != nil {
+		return err
+	}
+	if cfg.ReferenceRPCServer == "" {
+		return errors.New("--reference-rpcserver is required")
+	}
+	log.SetLevel(logger.LevelInfo)
+	common.InitBackend(backendLog, defaultLogFile, defaultErrLogFile)
+
+	return nil
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/stability-tests/common/mine"
+	"github.com/kaspanet/kaspad/stability-tests/common/rpc"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// settleAttempts and settleInterval bound how long the state of the nodes is compared
+	// again before it's flagged, since the reference may resolve the virtual asynchronously
+	settleAttempts = 10
+	settleInterval = 200 * time.Millisecond
+
+	maxTransactionsPerRound = 3
+)
+
+type node struct {
+	name   string
+	client *rpc.Client
+}
+
+type differential struct {
+	params     *dagconfig.Params
+	random     *rand.Rand
+	testedNode *node
+	reference  *node
+
+	keyPair         *secp256k1.SchnorrKeyPair
+	payAddress      string
+	scriptPublicKey *externalapi.ScriptPublicKey
+
+	immatureCoinbases []*immatureCoinbase
+	spendableOutputs  []*spendableOutput
+	spentOutputs      []*spendableOutput
+
+	blockDivergences       int
+	transactionDivergences int
+}
+
+// runDifferential feeds the same blocks and transactions to the tested node and to the
+// reference, and flags every difference in their decisions and in their resulting state
+func runDifferential(cfg *configFlags) error {
+	testedNodeClient, err := rpc.ConnectToRPC(&cfg.Config, cfg.NetParams())
+	if err != nil {
+		return errors.Wrap(err, "error connecting to the tested node")
+	}
+	defer testedNodeClient.Disconnect()
+	referenceClient, err := rpc.ConnectToRPC(&rpc.Config{RPCServer: cfg.ReferenceRPCServer}, cfg.NetParams())
+	if err != nil {
+		return errors.Wrap(err, "error connecting to the reference")
+	}
+	defer referenceClient.Disconnect()
+
+	keyPair, err := secp256k1.GenerateSchnorrKeyPair()
+	if err != nil {
+		return err
+	}
+	publicKey, err := keyPair.SchnorrPublicKey()
+	if err != nil {
+		return err
+	}
+	serializedPublicKey, err := publicKey.Serialize()
+	if err != nil {
+		return err
+	}
+	address, err := util.NewAddressPublicKey(serializedPublicKey[:], cfg.NetParams().Prefix)
+	if err != nil {
+		return err
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return err
+	}
+
+	d := &differential{
+		params:          cfg.NetParams(),
+		random:          rand.New(rand.NewSource(cfg.Seed)),
+		testedNode:      &node{name: "tested node", client: testedNodeClient},
+		reference:       &node{name: "reference", client: referenceClient},
+		keyPair:         keyPair,
+		payAddress:      address.String(),
+		scriptPublicKey: scriptPublicKey,
+	}
+	err = d.checkSameStartingDAG()
+	if err != nil {
+		return err
+	}
+
+	for round := 1; round <= cfg.Rounds; round++ {
+		err := d.runRound(round)
+		if err != nil {
+			return err
+		}
+		if round%50 == 0 {
+			log.Infof("Ran %d rounds", round)
+		}
+	}
+
+	log.Infof("Ran %d rounds. Block divergences: %d, transaction divergences: %d",
+		cfg.Rounds, d.blockDivergences, d.transactionDivergences)
+	if d.blockDivergences > 0 || d.transactionDivergences > 0 {
+		return errors.Errorf("the tested node diverged from the reference %d times",
+			d.blockDivergences+d.transactionDivergences)
+	}
+	return nil
+}
+
+func (d *differential) checkSameStartingDAG() error {
+	testedNodeDAGInfo, err := d.testedNode.client.GetBlockDAGInfo()
+	if err != nil {
+		return err
+	}
+	referenceDAGInfo, err := d.reference.client.GetBlockDAGInfo()
+	if err != nil {
+		return err
+	}
+	if testedNodeDAGInfo.NetworkName != referenceDAGInfo.NetworkName {
+		return errors.Errorf("the tested node is on %s but the reference is on %s",
+			testedNodeDAGInfo.NetworkName, referenceDAGInfo.NetworkName)
+	}
+	if !equalStringSets(testedNodeDAGInfo.TipHashes, referenceDAGInfo.TipHashes) {
+		return errors.Errorf("the nodes must start from the same DAG, but the tips of the tested node "+
+			"are %s and the tips of the reference are %s", testedNodeDAGInfo.TipHashes, referenceDAGInfo.TipHashes)
+	}
+	return nil
+}
+
+func (d *differential) runRound(round int) error {
+	transactionCount := d.random.Intn(maxTransactionsPerRound + 1)
+	for i := 0; i < transactionCount; i++ {
+		err := d.submitTransaction(round)
+		if err != nil {
+			return err
+		}
+	}
+
+	response, err := d.testedNode.client.GetBlockTemplate(d.payAddress, "")
+	if err != nil {
+		return err
+	}
+	block, err := appmessage.RPCBlockToDomainBlock(response.Block)
+	if err != nil {
+		return err
+	}
+	mutation := d.mutateBlock(block)
+	mine.SolveBlock(block)
+	blockHash := consensushashing.BlockHash(block)
+
+	testedNodeAccepted, testedNodeErr := d.submitBlock(d.testedNode, block)
+	referenceAccepted, referenceErr := d.submitBlock(d.reference, block)
+	if testedNodeAccepted != referenceAccepted {
+		d.blockDivergences++
+		log.Errorf("Round %d: block %s with %s was accepted by only one of the nodes. Tested node: %s, "+
+			"reference: %s", round, blockHash, mutation, decision(testedNodeErr), decision(referenceErr))
+	}
+	if testedNodeAccepted && mutation == noMutation {
+		d.immatureCoinbases = append(d.immatureCoinbases, &immatureCoinbase{
+			transaction: block.Transactions[0],
+			daaScore:    block.Header.DAAScore(),
+		})
+	}
+
+	differences, err := d.settledDifferences(func() ([]string, error) {
+		return d.blockDifferences(blockHash)
+	})
+	if err != nil {
+		return err
+	}
+	for _, difference := range differences {
+		d.blockDivergences++
+		log.Errorf("Round %d: block %s with %s: %s", round, blockHash, mutation, difference)
+	}
+
+	// Once the virtuals diverge, every later comparison would only repeat the divergence
+	differences, err = d.settledDifferences(d.virtualDifferences)
+	if err != nil {
+		return err
+	}
+	if len(differences) > 0 {
+		d.blockDivergences++
+		return errors.Errorf("round %d: the virtual of the nodes diverged after block %s with %s: %s",
+			round, blockHash, mutation, strings.Join(differences, "; "))
+	}
+	return nil
+}
+
+func (d *differential) submitBlock(node *node, block *externalapi.DomainBlock) (bool, error) {
+	_, err := node.client.SubmitBlockAlsoIfNonDAA(block)
+	return err == nil, err
+}
+
+// decision describes the response of a node to a submitted block or transaction
+func decision(err error) string {
+	if err == nil {
+		return "accepted"
+	}
+	return fmt.Sprintf("rejected (%s)", err)
+}
+
+// settledDifferences returns the differences that compare returns, once they remain after
+// the nodes had time to settle
+func (d *differential) settledDifferences(compare func() ([]string, error)) ([]string, error) {
+	for attempt := 1; ; attempt++ {
+		differences, err := compare()
+		if err != nil || len(differences) == 0 || attempt == settleAttempts {
+			return differences, err
+		}
+		time.Sleep(settleInterval)
+	}
+}
+
+// blockDifferences compares what the nodes know of the given block
+func (d *differential) blockDifferences(blockHash *externalapi.DomainHash) ([]string, error) {
+	testedNodeResponse, testedNodeErr := d.testedNode.client.GetBlock(blockHash.String(), false)
+	referenceResponse, referenceErr := d.reference.client.GetBlock(blockHash.String(), false)
+	if (testedNodeErr == nil) != (referenceErr == nil) {
+		return []string{fmt.Sprintf("the block is known to only one of the nodes. Tested node: %v, reference: %v",
+			testedNodeErr, referenceErr)}, nil
+	}
+	if testedNodeErr != nil {
+		return nil, nil
+	}
+
+	testedNode := testedNodeResponse.Block.VerboseData
+	reference := referenceResponse.Block.VerboseData
+	var differences []string
+	compare := func(field string, testedNodeValue, referenceValue interface{}) {
+		if fmt.Sprint(testedNodeValue) != fmt.Sprint(referenceValue) {
+			differences = append(differences, fmt.Sprintf("%s is %v on the tested node but %v on the reference",
+				field, testedNodeValue, referenceValue))
+		}
+	}
+	compare("blue score", testedNode.BlueScore, reference.BlueScore)
+	compare("selected parent", testedNode.SelectedParentHash, reference.SelectedParentHash)
+	compare("is chain block", testedNode.IsChainBlock, reference.IsChainBlock)
+	compare("is header only", testedNode.IsHeaderOnly, reference.IsHeaderOnly)
+	compare("merge set blues", sortedStrings(testedNode.MergeSetBluesHashes), sortedStrings(reference.MergeSetBluesHashes))
+	compare("merge set reds", sortedStrings(testedNode.MergeSetRedsHashes), sortedStrings(reference.MergeSetRedsHashes))
+	return differences, nil
+}
+
+// virtualDifferences compares the virtuals of the nodes, through the headers of the block
+// templates that they build on them. The UTXO commitment and the accepted ID merkle root of
+// a template commit to the UTXO set and to the accepted transactions of the virtual.
+func (d *differential) virtualDifferences() ([]string, error) {
+	testedNodeTemplate, err := d.blockTemplate(d.testedNode)
+	if err != nil {
+		return nil, err
+	}
+	referenceTemplate, err := d.blockTemplate(d.reference)
+	if err != nil {
+		return nil, err
+	}
+	testedNodeSelectedTip, err := d.testedNode.client.GetSelectedTipHash()
+	if err != nil {
+		return nil, err
+	}
+	referenceSelectedTip, err := d.reference.client.GetSelectedTipHash()
+	if err != nil {
+		return nil, err
+	}
+
+	testedNode := testedNodeTemplate.Header
+	reference := referenceTemplate.Header
+	var differences []string
+	compare := func(field string, testedNodeValue, referenceValue interface{}) {
+		if fmt.Sprint(testedNodeValue) != fmt.Sprint(referenceValue) {
+			differences = append(differences, fmt.Sprintf("%s is %v on the tested node but %v on the reference",
+				field, testedNodeValue, referenceValue))
+		}
+	}
+	compare("selected tip", testedNodeSelectedTip.SelectedTipHash, referenceSelectedTip.SelectedTipHash)
+	compare("virtual parents", sortedHashes(testedNode.DirectParents()), sortedHashes(reference.DirectParents()))
+	compare("UTXO commitment", testedNode.UTXOCommitment(), reference.UTXOCommitment())
+	compare("accepted ID merkle root", testedNode.AcceptedIDMerkleRoot(), reference.AcceptedIDMerkleRoot())
+	compare("blue score", testedNode.BlueScore(), reference.BlueScore())
+	compare("blue work", testedNode.BlueWork(), reference.BlueWork())
+	compare("DAA score", testedNode.DAAScore(), reference.DAAScore())
+	compare("bits", testedNode.Bits(), reference.Bits())
+	compare("pruning point", testedNode.PruningPoint(), reference.PruningPoint())
+	return differences, nil
+}
+
+func (d *differential) blockTemplate(node *node) (*externalapi.DomainBlock, error) {
+	response, err := node.client.GetBlockTemplate(d.payAddress, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting a block template from the %s", node.name)
+	}
+	return appmessage.RPCBlockToDomainBlock(response.Block)
+}
+
+func sortedStrings(strings []string) []string {
+	sorted := append([]string{}, strings...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func sortedHashes(hashes []*externalapi.DomainHash) []string {
+	strings := make([]string, len(hashes))
+	for i, hash := range hashes {
+		strings[i] = hash.String()
+	}
+	sort.Strings(strings)
+	return strings
+}
+
+func equalStringSets(a []string, b []string) bool {
+	return fmt.Sprint(sortedStrings(a)) == fmt.Sprint(sortedStrings(b))
+}
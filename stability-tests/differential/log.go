@@ -0,0 +1,12 @@
+package main
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/panics"
+)
+
+var (
+	backendLog = logger.NewBackend()
+	log        = backendLog.Logger("DIFF")
+	spawn      = panics.GoroutineWrapperFunc(log)
+)
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kaspanet/kaspad/stability-tests/common"
+	"github.com/kaspanet/kaspad/util/profiling"
+)
+
+func main() {
+	err := parseConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing config: %+v", err)
+		os.Exit(1)
+	}
+	defer backendLog.Close()
+	common.UseLogger(backendLog, log.Level())
+	cfg := activeConfig()
+	if cfg.Profile != "" {
+		profiling.Start(cfg.Profile, log)
+	}
+
+	err = runDifferential(cfg)
+	if err != nil {
+		log.Errorf("Error in the differential test: %+v", err)
+		backendLog.Close()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,44 @@
+#!/bin/bash
+
+# REFERENCE_KASPAD is the kaspad binary that the installed kaspad is compared with, for
+# example the kaspad of the latest release
+REFERENCE_KASPAD=${REFERENCE_KASPAD:-kaspad}
+
+APPDIR=/tmp/kaspad-temp
+REFERENCE_APPDIR=/tmp/kaspad-reference-temp
+KASPAD_RPC_PORT=29687
+REFERENCE_RPC_PORT=29688
+
+rm -rf "${APPDIR}" "${REFERENCE_APPDIR}"
+
+kaspad --simnet --appdir="${APPDIR}" --rpclisten=0.0.0.0:"${KASPAD_RPC_PORT}" --listen=0.0.0.0:29689 \
+  --allow-submit-block-when-not-synced --profile=6061 &
+KASPAD_PID=$!
+
+"${REFERENCE_KASPAD}" --simnet --appdir="${REFERENCE_APPDIR}" --rpclisten=0.0.0.0:"${REFERENCE_RPC_PORT}" \
+  --listen=0.0.0.0:29690 --allow-submit-block-when-not-synced --profile=6062 &
+REFERENCE_PID=$!
+
+sleep 1
+
+differential --simnet --rpcserver=127.0.0.1:"${KASPAD_RPC_PORT}" \
+  --reference-rpcserver=127.0.0.1:"${REFERENCE_RPC_PORT}" --profile=7000
+TEST_EXIT_CODE=$?
+
+kill $KASPAD_PID $REFERENCE_PID
+
+wait $KASPAD_PID
+KASPAD_EXIT_CODE=$?
+wait $REFERENCE_PID
+REFERENCE_EXIT_CODE=$?
+
+echo "Exit code: $TEST_EXIT_CODE"
+echo "Kaspad exit code: $KASPAD_EXIT_CODE"
+echo "Reference kaspad exit code: $REFERENCE_EXIT_CODE"
+
+if [ $TEST_EXIT_CODE -eq 0 ] && [ $KASPAD_EXIT_CODE -eq 0 ] && [ $REFERENCE_EXIT_CODE -eq 0 ]; then
+  echo "differential test: PASSED"
+  exit 0
+fi
+echo "differential test: FAILED"
+exit 1
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/pkg/errors"
+)
+
+const transactionFee = 10_000
+
+// coinbaseMaturityMargin is added to the coinbase maturity before a coinbase output is
+// spent, since the DAA score of its UTXO entry is the DAA score of the block that accepted
+// it rather than of the block that contains it
+const coinbaseMaturityMargin = 10
+
+// transactionVariant describes how a submitted transaction was built
+type transactionVariant string
+
+const (
+	validSpend            transactionVariant = "a valid spend"
+	doubleSpend           transactionVariant = "a double spend"
+	badSignature          transactionVariant = "a bad signature"
+	overspend             transactionVariant = "an overspend"
+	immatureCoinbaseSpend transactionVariant = "an immature coinbase spend"
+)
+
+var transactionVariants = []transactionVariant{
+	validSpend,
+	validSpend,
+	doubleSpend,
+	badSignature,
+	overspend,
+	immatureCoinbaseSpend,
+}
+
+type immatureCoinbase struct {
+	transaction *externalapi.DomainTransaction
+	daaScore    uint64
+}
+
+type spendableOutput struct {
+	outpoint externalapi.DomainOutpoint
+	value    uint64
+}
+
+// submitTransaction builds a transaction of a random variant, submits it to both nodes and
+// flags a divergence if only one of them accepts it. Variants that need outputs which
+// aren't available yet are skipped.
+func (d *differential) submitTransaction(round int) error {
+	dagInfo, err := d.testedNode.client.GetBlockDAGInfo()
+	if err != nil {
+		return err
+	}
+	d.matureCoinbases(dagInfo.VirtualDAAScore)
+
+	variant := transactionVariants[d.random.Intn(len(transactionVariants))]
+	var output *spendableOutput
+	switch variant {
+	case doubleSpend:
+		if len(d.spentOutputs) == 0 {
+			return nil
+		}
+		output = d.spentOutputs[d.random.Intn(len(d.spentOutputs))]
+	case immatureCoinbaseSpend:
+		if len(d.immatureCoinbases) == 0 {
+			return nil
+		}
+		coinbase := d.immatureCoinbases[len(d.immatureCoinbases)-1]
+		if len(coinbase.transaction.Outputs) == 0 {
+			return nil
+		}
+		output = &spendableOutput{
+			outpoint: externalapi.DomainOutpoint{TransactionID: *consensushashing.TransactionID(coinbase.transaction)},
+			value:    coinbase.transaction.Outputs[0].Value,
+		}
+	default:
+		if len(d.spendableOutputs) == 0 {
+			return nil
+		}
+		index := d.random.Intn(len(d.spendableOutputs))
+		output = d.spendableOutputs[index]
+		if variant == validSpend {
+			d.spendableOutputs = append(d.spendableOutputs[:index], d.spendableOutputs[index+1:]...)
+		}
+	}
+
+	outputValue := output.value - transactionFee
+	if variant == overspend {
+		outputValue = output.value + 1
+	}
+	transaction, err := d.buildTransaction(output, outputValue)
+	if err != nil {
+		return err
+	}
+	if variant == badSignature {
+		signatureScript := transaction.Inputs[0].SignatureScript
+		signatureScript[1+d.random.Intn(len(signatureScript)-1)] ^= 0xff
+	}
+	transactionID := consensushashing.TransactionID(transaction)
+
+	testedNodeErr := d.submitTransactionTo(d.testedNode, transaction)
+	referenceErr := d.submitTransactionTo(d.reference, transaction)
+	if (testedNodeErr == nil) != (referenceErr == nil) {
+		d.transactionDivergences++
+		log.Errorf("Round %d: transaction %s with %s was accepted by only one of the nodes. Tested node: %s, "+
+			"reference: %s", round, transactionID, variant, decision(testedNodeErr), decision(referenceErr))
+	}
+
+	if variant == validSpend {
+		d.spentOutputs = append(d.spentOutputs, output)
+		if testedNodeErr == nil {
+			d.spendableOutputs = append(d.spendableOutputs, &spendableOutput{
+				outpoint: externalapi.DomainOutpoint{TransactionID: *transactionID},
+				value:    outputValue,
+			})
+		}
+	}
+	return nil
+}
+
+func (d *differential) submitTransactionTo(node *node, transaction *externalapi.DomainTransaction) error {
+	rpcTransaction := appmessage.DomainTransactionToRPCTransaction(transaction)
+	_, err := node.client.SubmitTransaction(rpcTransaction, consensushashing.TransactionID(transaction).String(), false)
+	return err
+}
+
+// matureCoinbases moves the outputs of the coinbases that matured by the given DAA score to
+// the spendable outputs
+func (d *differential) matureCoinbases(virtualDAAScore uint64) {
+	maturity := d.params.BlockCoinbaseMaturity + coinbaseMaturityMargin
+	for len(d.immatureCoinbases) > 0 && d.immatureCoinbases[0].daaScore+maturity <= virtualDAAScore {
+		coinbase := d.immatureCoinbases[0]
+		d.immatureCoinbases = d.immatureCoinbases[1:]
+
+		coinbaseID := consensushashing.TransactionID(coinbase.transaction)
+		for i, output := range coinbase.transaction.Outputs {
+			if output.Value <= transactionFee || !output.ScriptPublicKey.Equal(d.scriptPublicKey) {
+				continue
+			}
+			d.spendableOutputs = append(d.spendableOutputs, &spendableOutput{
+				outpoint: externalapi.DomainOutpoint{TransactionID: *coinbaseID, Index: uint32(i)},
+				value:    output.Value,
+			})
+		}
+	}
+}
+
+func (d *differential) buildTransaction(output *spendableOutput, outputValue uint64) (
+	*externalapi.DomainTransaction, error) {
+
+	transaction := &externalapi.DomainTransaction{
+		Version: constants.MaxTransactionVersion,
+		Inputs: []*externalapi.DomainTransactionInput{{
+			PreviousOutpoint: output.outpoint,
+			UTXOEntry:        utxo.NewUTXOEntry(output.value, d.scriptPublicKey, false, 0),
+			SigOpCount:       1,
+		}},
+		Outputs: []*externalapi.DomainTransactionOutput{{
+			Value:           outputValue,
+			ScriptPublicKey: d.scriptPublicKey,
+		}},
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+	}
+	signatureScript, err := txscript.SignatureScript(transaction, 0, consensushashing.SigHashAll, d.keyPair,
+		&consensushashing.SighashReusedValues{})
+	if err != nil {
+		return nil, errors.Wrap(err, "error signing a transaction")
+	}
+	transaction.Inputs[0].SignatureScript = signatureScript
+	return transaction, nil
+}
@@ -0,0 +1,90 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+)
+
+func TestAddedNodes(t *testing.T) {
+	harnesses, teardown := setupHarnesses(t, []*harnessParams{
+		{
+			p2pAddress:              p2pAddress1,
+			rpcAddress:              rpcAddress1,
+			miningAddress:           miningAddress1,
+			miningAddressPrivateKey: miningAddress1PrivateKey,
+		},
+		{
+			p2pAddress:              p2pAddress2,
+			rpcAddress:              rpcAddress2,
+			miningAddress:           miningAddress2,
+			miningAddressPrivateKey: miningAddress2PrivateKey,
+		},
+	})
+	defer teardown()
+	harness, addedHarness := harnesses[0], harnesses[1]
+
+	err := harness.rpcClient.AddNode(addedHarness.p2pAddress, appmessage.AddNodeCommandAdd)
+	if err != nil {
+		t.Fatalf("Error adding the node: %s", err)
+	}
+	waitForAddedNode(t, harness, addedHarness.p2pAddress, true)
+
+	err = harness.rpcClient.AddNode(addedHarness.p2pAddress, appmessage.AddNodeCommandAdd)
+	if err == nil {
+		t.Fatalf("Expected an error adding a node that was already added")
+	}
+
+	// Disconnecting from an added node doesn't remove it
+	err = harness.rpcClient.DisconnectNode(addedHarness.p2pAddress)
+	if err != nil {
+		t.Fatalf("Error disconnecting the node: %s", err)
+	}
+	waitForAddedNode(t, harness, addedHarness.p2pAddress, false)
+
+	// Added nodes are persisted, so they're connected to again after a restart
+	harness.rpcClient.Close()
+	harness.app.Stop()
+	setApp(t, harness)
+	harness.app.Start()
+	setRPCClient(t, harness)
+	waitForAddedNode(t, harness, addedHarness.p2pAddress, true)
+
+	err = harness.rpcClient.AddNode(addedHarness.p2pAddress, appmessage.AddNodeCommandRemove)
+	if err != nil {
+		t.Fatalf("Error removing the node: %s", err)
+	}
+	getAddedNodeInfoResponse, err := harness.rpcClient.GetAddedNodeInfo()
+	if err != nil {
+		t.Fatalf("Error getting the added nodes: %s", err)
+	}
+	if len(getAddedNodeInfoResponse.Infos) != 0 {
+		t.Fatalf("Expected no added nodes after removing the node but got %d", len(getAddedNodeInfoResponse.Infos))
+	}
+
+	err = harness.rpcClient.AddNode(addedHarness.p2pAddress, appmessage.AddNodeCommandRemove)
+	if err == nil {
+		t.Fatalf("Expected an error removing a node that isn't added")
+	}
+	err = harness.rpcClient.AddNode(addedHarness.p2pAddress, "unknown")
+	if err == nil {
+		t.Fatalf("Expected an error for an unknown command")
+	}
+}
+
+func waitForAddedNode(t *testing.T, harness *appHarness, address string, isConnected bool) {
+	var infos []*appmessage.AddedNodeInfo
+	for start := time.Now(); time.Since(start) < defaultTimeout; time.Sleep(10 * time.Millisecond) {
+		getAddedNodeInfoResponse, err := harness.rpcClient.GetAddedNodeInfo()
+		if err != nil {
+			t.Fatalf("Error getting the added nodes: %s", err)
+		}
+		infos = getAddedNodeInfoResponse.Infos
+		if len(infos) == 1 && infos[0].Address == address && infos[0].IsConnected == isConnected {
+			return
+		}
+	}
+	t.Fatalf("Timed out waiting for %s to be the only added node with IsConnected %t. Added nodes: %v",
+		address, isConnected, infos)
+}
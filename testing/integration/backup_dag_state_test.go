@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+)
+
+func TestBackupDagState(t *testing.T) {
+	harnessParams := &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	}
+	kaspad, teardown := setupHarness(t, harnessParams)
+	defer teardown()
+
+	for i := 0; i < 10; i++ {
+		mineNextBlock(t, kaspad)
+	}
+	selectedTipHashResponse, err := kaspad.rpcClient.GetSelectedTipHash()
+	if err != nil {
+		t.Fatalf("Error getting the selected tip hash: %s", err)
+	}
+
+	backupDagStateResponse, err := kaspad.rpcClient.BackupDagState("")
+	if err != nil {
+		t.Fatalf("Error backing up the DAG state: %s", err)
+	}
+	backupPath := backupDagStateResponse.BackupPath
+
+	// Blocks that are added after the backup was requested must not be in the backup
+	for i := 0; i < 5; i++ {
+		mineNextBlock(t, kaspad)
+	}
+
+	_, err = kaspad.rpcClient.BackupDagState(backupPath)
+	if err == nil {
+		t.Fatalf("Expected backing up the DAG state to an existing path to fail")
+	}
+
+	deadline := time.Now().Add(defaultTimeout)
+	for {
+		_, err := os.Stat(backupPath)
+		if err == nil {
+			break
+		}
+		if !os.IsNotExist(err) {
+			t.Fatalf("Error checking the backup path: %s", err)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("The backup wasn't written to %s in time", backupPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, fileName := range []string{"version", "dbtype"} {
+		_, err := os.Stat(filepath.Join(backupPath, fileName))
+		if err != nil {
+			t.Fatalf("Error checking the %s file of the backup: %s", fileName, err)
+		}
+	}
+
+	backupDB, err := ldb.NewLevelDB(backupPath, 8)
+	if err != nil {
+		t.Fatalf("Error opening the backup: %s", err)
+	}
+	defer backupDB.Close()
+	consensusConfig := &consensus.Config{Params: *kaspad.config.ActiveNetParams}
+	backupDomain, err := domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), backupDB)
+	if err != nil {
+		t.Fatalf("Error loading the DAG from the backup: %s", err)
+	}
+	backupSelectedTip, err := backupDomain.Consensus().GetVirtualSelectedParent()
+	if err != nil {
+		t.Fatalf("Error getting the selected tip of the backup: %s", err)
+	}
+	if backupSelectedTip.String() != selectedTipHashResponse.SelectedTipHash {
+		t.Fatalf("Expected the selected tip of the backup to be %s but got %s",
+			selectedTipHashResponse.SelectedTipHash, backupSelectedTip)
+	}
+}
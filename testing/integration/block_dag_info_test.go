@@ -0,0 +1,43 @@
+package integration
+
+import (
+	"testing"
+)
+
+func TestGetBlockDAGInfo(t *testing.T) {
+	harnessParams := &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	}
+	kaspad, teardown := setupHarness(t, harnessParams)
+	defer teardown()
+
+	const blockAmountToMine = 10
+	for i := 0; i < blockAmountToMine; i++ {
+		mineNextBlock(t, kaspad)
+	}
+
+	response, err := kaspad.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		t.Fatalf("Error getting the block DAG info: %s", err)
+	}
+	if response.VirtualBlueScore != blockAmountToMine+1 {
+		t.Fatalf("Unexpected virtual blue score. Want: %d, got: %d",
+			blockAmountToMine+1, response.VirtualBlueScore)
+	}
+	if response.SyncProgress != 100 {
+		t.Fatalf("Unexpected sync progress. Want: 100, got: %f", response.SyncProgress)
+	}
+
+	// A block that is built on top of the virtual commits to the virtual UTXO set
+	blockTemplate, err := kaspad.rpcClient.GetBlockTemplate(kaspad.miningAddress, "integration")
+	if err != nil {
+		t.Fatalf("Error getting block template: %+v", err)
+	}
+	if response.UTXOCommitment != blockTemplate.Block.Header.UTXOCommitment {
+		t.Fatalf("Unexpected UTXO commitment. Want: %s, got: %s",
+			blockTemplate.Block.Header.UTXOCommitment, response.UTXOCommitment)
+	}
+}
@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+)
+
+func TestGetBlockDAGRelations(t *testing.T) {
+	harnessParams := &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	}
+	kaspad, teardown := setupHarness(t, harnessParams)
+	defer teardown()
+
+	const blockAmountToMine = 5
+	var blockHashes []string
+	for i := 0; i < blockAmountToMine; i++ {
+		block := mineNextBlock(t, kaspad)
+		blockHashes = append(blockHashes, consensushashing.BlockHash(block).String())
+	}
+	tipHash := blockHashes[len(blockHashes)-1]
+
+	response, err := kaspad.rpcClient.GetBlockDAGRelations(tipHash, blockHashes[0])
+	if err != nil {
+		t.Fatalf("Error getting the block DAG relations: %s", err)
+	}
+	if len(response.AnticoneHashes) != 0 {
+		t.Fatalf("Expected a chain of blocks to have empty anticones, but got %s", response.AnticoneHashes)
+	}
+	expectedBlueHashes := blockHashes[:len(blockHashes)-1]
+	if len(response.BlueHashes) != len(expectedBlueHashes) {
+		t.Fatalf("Unexpected blue hashes. Want: %s, got: %s", expectedBlueHashes, response.BlueHashes)
+	}
+	for i, blueHash := range response.BlueHashes {
+		if blueHash != expectedBlueHashes[i] {
+			t.Fatalf("Unexpected blue hashes. Want: %s, got: %s", expectedBlueHashes, response.BlueHashes)
+		}
+	}
+	if response.BlueHashesHighHash != tipHash {
+		t.Fatalf("Unexpected blue hashes high hash. Want: %s, got: %s", tipHash, response.BlueHashesHighHash)
+	}
+
+	_, err = kaspad.rpcClient.GetBlockDAGRelations(blockHashes[0], tipHash)
+	if err == nil {
+		t.Fatalf("Expected an error for a low hash that is not in the selected parent chain of the block")
+	}
+}
@@ -0,0 +1,95 @@
+package integration
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+)
+
+func TestBlockHeaderAdded(t *testing.T) {
+	const numBlocks = 20
+
+	syncer, syncee, _, teardown := standardSetup(t)
+	defer teardown()
+
+	minedBlockHashes := make(map[string]struct{}, numBlocks)
+	for i := 0; i < numBlocks; i++ {
+		block := mineNextBlock(t, syncer)
+		minedBlockHashes[consensushashing.BlockHash(block).String()] = struct{}{}
+	}
+
+	var headersLock sync.Mutex
+	headerNotificationCounts := make(map[string]int, numBlocks)
+	err := syncee.rpcClient.RegisterForBlockHeaderAddedNotifications(
+		func(notification *appmessage.BlockHeaderAddedNotificationMessage) {
+			headersLock.Lock()
+			defer headersLock.Unlock()
+			headerNotificationCounts[notification.Hash]++
+		})
+	if err != nil {
+		t.Fatalf("Failed to register for block header added notifications: %s", err)
+	}
+
+	blockAddedWG := sync.WaitGroup{}
+	blockAddedWG.Add(numBlocks)
+	setOnBlockAddedHandler(t, syncee, func(_ *appmessage.BlockAddedNotificationMessage) {
+		blockAddedWG.Done()
+	})
+
+	// We expect this to trigger IBD, in which the headers are synced before the block bodies
+	connect(t, syncer, syncee)
+
+	select {
+	case <-time.After(defaultTimeout):
+		t.Fatalf("Timeout waiting for IBD to finish")
+	case <-ReceiveFromChanWhenDone(func() { blockAddedWG.Wait() }):
+	}
+	// Give the notifications of the last blocks time to arrive
+	time.Sleep(time.Second)
+
+	headersLock.Lock()
+	defer headersLock.Unlock()
+	if len(headerNotificationCounts) != numBlocks {
+		t.Fatalf("Expected header notifications for %d blocks but got %d", numBlocks, len(headerNotificationCounts))
+	}
+	for hash, count := range headerNotificationCounts {
+		if _, ok := minedBlockHashes[hash]; !ok {
+			t.Fatalf("Got a header notification for %s, which wasn't mined", hash)
+		}
+		// The header must not be reported again once the block body completes it
+		if count != 1 {
+			t.Fatalf("Expected one header notification for %s but got %d", hash, count)
+		}
+	}
+
+	// Headers of blocks that are added with their bodies are reported too, along with
+	// their parents and blue score
+	headerAddedChan := make(chan *appmessage.BlockHeaderAddedNotificationMessage, 1)
+	err = syncer.rpcClient.RegisterForBlockHeaderAddedNotifications(
+		func(notification *appmessage.BlockHeaderAddedNotificationMessage) {
+			headerAddedChan <- notification
+		})
+	if err != nil {
+		t.Fatalf("Failed to register for block header added notifications: %s", err)
+	}
+	selectedTipHash, err := syncer.rpcClient.GetSelectedTipHash()
+	if err != nil {
+		t.Fatalf("Error getting the selected tip: %s", err)
+	}
+	block := mineNextBlock(t, syncer)
+	notification := <-headerAddedChan
+	if notification.Hash != consensushashing.BlockHash(block).String() {
+		t.Fatalf("Expected a header notification for %s but got %s", consensushashing.BlockHash(block), notification.Hash)
+	}
+	if len(notification.Header.Parents) == 0 || len(notification.Header.Parents[0].ParentHashes) != 1 ||
+		notification.Header.Parents[0].ParentHashes[0] != selectedTipHash.SelectedTipHash {
+		t.Fatalf("Expected the header's parent to be %s but got %+v", selectedTipHash.SelectedTipHash,
+			notification.Header.Parents)
+	}
+	if notification.Header.BlueScore != block.Header.BlueScore() {
+		t.Fatalf("Expected blue score %d but got %d", block.Header.BlueScore(), notification.Header.BlueScore)
+	}
+}
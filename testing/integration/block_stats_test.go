@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+)
+
+func TestGetBlockStats(t *testing.T) {
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	})
+	defer teardown()
+
+	// skip the first block because it's paying to genesis script
+	mineNextBlock(t, harness)
+	secondBlock := mineNextBlock(t, harness)
+	for i := uint64(0); i < harness.config.ActiveNetParams.BlockCoinbaseMaturity; i++ {
+		mineNextBlock(t, harness)
+	}
+
+	const fee = 5000
+	msgTx := generateTxWithFee(t, secondBlock.Transactions[transactionhelper.CoinbaseTransactionIndex], fee, harness, harness)
+	domainTransaction := appmessage.MsgTxToDomainTransaction(msgTx)
+	_, err := harness.rpcClient.SubmitTransaction(appmessage.DomainTransactionToRPCTransaction(domainTransaction),
+		consensushashing.TransactionID(domainTransaction).String(), false)
+	if err != nil {
+		t.Fatalf("Error submitting the transaction: %s", err)
+	}
+	blockHash := consensushashing.BlockHash(mineNextBlock(t, harness)).String()
+	acceptingBlockHash := consensushashing.BlockHash(mineNextBlock(t, harness)).String()
+
+	getBlockStatsResponse, err := harness.rpcClient.GetBlockStats(blockHash, "")
+	if err != nil {
+		t.Fatalf("Error getting the block stats: %s", err)
+	}
+	if len(getBlockStatsResponse.BlockStats) != 1 {
+		t.Fatalf("Expected the stats of a single block but got %d", len(getBlockStatsResponse.BlockStats))
+	}
+	stats := getBlockStatsResponse.BlockStats[0]
+	if stats.Hash != blockHash || !stats.IsChainBlock || stats.ParentCount != 1 || stats.MergeSetBluesCount != 1 ||
+		stats.MergeSetRedsCount != 0 {
+		t.Fatalf("Unexpected stats of block %s: %+v", blockHash, stats)
+	}
+	if stats.TransactionCount != 1 || stats.InputCount != 1 || stats.OutputCount != 1 {
+		t.Fatalf("Expected a single transaction with a single input and output but got %d transactions "+
+			"with %d inputs and %d outputs", stats.TransactionCount, stats.InputCount, stats.OutputCount)
+	}
+	if stats.Size == 0 || stats.Mass == 0 || stats.TimeSinceSelectedParent < 0 {
+		t.Fatalf("Unexpected size %d, mass %d or time since the selected parent %d",
+			stats.Size, stats.Mass, stats.TimeSinceSelectedParent)
+	}
+	if !stats.IsAccepted || stats.AcceptingBlockHash != acceptingBlockHash {
+		t.Fatalf("Expected the block to be accepted by %s but got %t by %s",
+			acceptingBlockHash, stats.IsAccepted, stats.AcceptingBlockHash)
+	}
+	if stats.AcceptedTxCount != 1 || stats.TotalFees != fee {
+		t.Fatalf("Expected a single accepted transaction that pays %d but got %d that pay %d",
+			fee, stats.AcceptedTxCount, stats.TotalFees)
+	}
+	if len(stats.FeeratePercentiles) != 5 {
+		t.Fatalf("Expected 5 feerate percentiles but got %d", len(stats.FeeratePercentiles))
+	}
+	for _, feerate := range stats.FeeratePercentiles {
+		if feerate <= 0 || feerate != stats.FeeratePercentiles[0] {
+			t.Fatalf("Expected all the percentiles to be the feerate of the transaction but got %v",
+				stats.FeeratePercentiles)
+		}
+	}
+
+	// The transactions of the virtual selected parent aren't accepted by a chain block yet
+	getBlockStatsResponse, err = harness.rpcClient.GetBlockStats(blockHash, acceptingBlockHash)
+	if err != nil {
+		t.Fatalf("Error getting the block stats of a range: %s", err)
+	}
+	if len(getBlockStatsResponse.BlockStats) != 2 {
+		t.Fatalf("Expected the stats of 2 blocks but got %d", len(getBlockStatsResponse.BlockStats))
+	}
+	acceptingBlockStats := getBlockStatsResponse.BlockStats[1]
+	if acceptingBlockStats.Hash != acceptingBlockHash || acceptingBlockStats.IsAccepted ||
+		len(acceptingBlockStats.FeeratePercentiles) != 0 {
+		t.Fatalf("Expected the stats of the unaccepted block %s but got %+v", acceptingBlockHash, acceptingBlockStats)
+	}
+
+	_, err = harness.rpcClient.GetBlockStats(acceptingBlockHash, blockHash)
+	if err == nil {
+		t.Fatalf("Expected an error getting the stats of a range whose high hash is below its low hash")
+	}
+}
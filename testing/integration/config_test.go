@@ -37,10 +37,13 @@ const (
 func setConfig(t *testing.T, harness *appHarness, protocolVersion uint32) {
 	harness.config = commonConfig()
 	harness.config.AppDir = randomDirectory(t)
+	harness.config.BackupDir = t.TempDir()
 	harness.config.Listeners = []string{harness.p2pAddress}
 	harness.config.RPCListeners = []string{harness.rpcAddress}
 	harness.config.UTXOIndex = harness.utxoIndex
+	harness.config.TXIndex = harness.txIndex
 	harness.config.AllowSubmitBlockWhenNotSynced = true
+	harness.config.TrustedPeers = harness.trustedPeers
 	if protocolVersion != 0 {
 		harness.config.ProtocolVersion = protocolVersion
 	}
@@ -48,6 +51,7 @@ func setConfig(t *testing.T, harness *appHarness, protocolVersion uint32) {
 	if harness.overrideDAGParams != nil {
 		harness.config.ActiveNetParams = harness.overrideDAGParams
 	}
+	harness.config.TrustedPeerMaxDivergence = harness.config.ActiveNetParams.MergeDepth
 }
 
 func commonConfig() *config.Config {
@@ -0,0 +1,52 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+)
+
+func TestDAGTipChanged(t *testing.T) {
+	// Setup a single kaspad instance
+	harnessParams := &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	}
+	kaspad, teardown := setupHarness(t, harnessParams)
+	defer teardown()
+
+	onDAGTipChangedChan := make(chan *appmessage.DAGTipChangedNotificationMessage, 10)
+	err := kaspad.rpcClient.RegisterForDAGTipChangedNotifications(
+		func(notification *appmessage.DAGTipChangedNotificationMessage) {
+			onDAGTipChangedChan <- notification
+		})
+	if err != nil {
+		t.Fatalf("Failed to register for DAG tip changed notifications: %s", err)
+	}
+
+	// Mine some blocks in a chain and make sure that each of them is reported as
+	// the single tip, and as the single added chain block along with its coinbase
+	const blockAmountToMine = 10
+	for i := 0; i < blockAmountToMine; i++ {
+		block := mineNextBlock(t, kaspad)
+		blockHash := consensushashing.BlockHash(block).String()
+
+		notification := <-onDAGTipChangedChan
+		if len(notification.TipHashes) != 1 || notification.TipHashes[0] != blockHash {
+			t.Fatalf("Unexpected tips. Want: [%s], got: %s", blockHash, notification.TipHashes)
+		}
+		if len(notification.RemovedChainBlockHashes) != 0 {
+			t.Fatalf("Unexpected removed chain blocks: %s", notification.RemovedChainBlockHashes)
+		}
+		if len(notification.AddedChainBlockHashes) != 1 || notification.AddedChainBlockHashes[0] != blockHash {
+			t.Fatalf("Unexpected added chain blocks. Want: [%s], got: %s", blockHash, notification.AddedChainBlockHashes)
+		}
+		if len(notification.AcceptedTransactionIDs) != 1 ||
+			notification.AcceptedTransactionIDs[0].AcceptingBlockHash != blockHash {
+			t.Fatalf("Unexpected accepted transaction IDs: %+v", notification.AcceptedTransactionIDs)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompactDbAndGetDbInfo(t *testing.T) {
+	harnessParams := &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+		utxoIndex:               true,
+	}
+	kaspad, teardown := setupHarness(t, harnessParams)
+	defer teardown()
+
+	for i := 0; i < 10; i++ {
+		mineNextBlock(t, kaspad)
+	}
+
+	_, err := kaspad.rpcClient.CompactDb()
+	if err != nil {
+		t.Fatalf("Error compacting the database: %s", err)
+	}
+
+	// The compaction runs in the background, so wait for it to finish
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		getDbInfoResponse, err := kaspad.rpcClient.GetDbInfo()
+		if err != nil {
+			t.Fatalf("Error getting the database info: %s", err)
+		}
+		if !getDbInfoResponse.IsCompacting && getDbInfoResponse.LastCompactionTimestamp != 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("The database compaction didn't finish in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	getDbInfoResponse, err := kaspad.rpcClient.GetDbInfo()
+	if err != nil {
+		t.Fatalf("Error getting the database info: %s", err)
+	}
+	if getDbInfoResponse.DbType != kaspad.config.DbType {
+		t.Fatalf("Expected database type %s but got %s", kaspad.config.DbType, getDbInfoResponse.DbType)
+	}
+	if getDbInfoResponse.SizeBytes == 0 {
+		t.Fatalf("Expected the database to have a non-zero size after compacting it")
+	}
+
+	foundBlockHeaders, foundUTXOIndex := false, false
+	bucketSizes := make(map[string]uint64, len(getDbInfoResponse.Buckets))
+	for _, bucket := range getDbInfoResponse.Buckets {
+		bucketSizes[bucket.Name] = bucket.SizeBytes
+		if strings.HasSuffix(bucket.Name, "/block-headers") && bucket.SizeBytes > 0 {
+			foundBlockHeaders = true
+		}
+		if bucket.Name == "utxo-index" && bucket.SizeBytes > 0 {
+			foundUTXOIndex = true
+		}
+	}
+	if !foundBlockHeaders || !foundUTXOIndex {
+		t.Fatalf("Expected non-empty block-headers and utxo-index buckets but got %v", bucketSizes)
+	}
+}
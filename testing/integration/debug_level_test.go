@@ -0,0 +1,58 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+)
+
+func TestDebugLevel(t *testing.T) {
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	})
+	defer teardown()
+
+	// The levels are global, so restore the level the other tests in this process run with
+	defer func() {
+		_, err := harness.rpcClient.DebugLevel("debug")
+		if err != nil {
+			t.Fatalf("Error restoring the log levels: %s", err)
+		}
+	}()
+
+	levelOf := func(subsystems []*appmessage.SubsystemLogLevel, subsystem string) string {
+		for _, subsystemLevel := range subsystems {
+			if subsystemLevel.Subsystem == subsystem {
+				return subsystemLevel.Level
+			}
+		}
+		t.Fatalf("Subsystem %s is missing from %+v", subsystem, subsystems)
+		return ""
+	}
+
+	debugLevelResponse, err := harness.rpcClient.DebugLevel("show")
+	if err != nil {
+		t.Fatalf("Error getting the log levels: %s", err)
+	}
+	if level := levelOf(debugLevelResponse.Subsystems, "TXMP"); level != "DBG" {
+		t.Fatalf("Expected the TXMP subsystem to be at level DBG but got %s", level)
+	}
+
+	debugLevelResponse, err = harness.rpcClient.DebugLevel("TXMP=warn,PROT=trace")
+	if err != nil {
+		t.Fatalf("Error setting the log levels: %s", err)
+	}
+	if levelOf(debugLevelResponse.Subsystems, "TXMP") != "WRN" ||
+		levelOf(debugLevelResponse.Subsystems, "PROT") != "TRC" ||
+		levelOf(debugLevelResponse.Subsystems, "BDAG") != "DBG" {
+		t.Fatalf("Unexpected log levels after setting them: %+v", debugLevelResponse.Subsystems)
+	}
+
+	_, err = harness.rpcClient.DebugLevel("NOSUCHSUBSYSTEM=info")
+	if err == nil {
+		t.Fatalf("Expected an error setting the log level of a subsystem that doesn't exist")
+	}
+}
@@ -0,0 +1,27 @@
+package integration
+
+import (
+	"testing"
+)
+
+func TestEncryptedTransport(t *testing.T) {
+	appHarness1, appHarness2, appHarness3, teardown := standardSetup(t)
+	defer teardown()
+
+	connect(t, appHarness1, appHarness2)
+	connect(t, appHarness3, appHarness1)
+
+	mineNextBlock(t, appHarness1)
+
+	for _, harness := range []*appHarness{appHarness1, appHarness2, appHarness3} {
+		getConnectedPeerInfoResponse, err := harness.rpcClient.GetConnectedPeerInfo()
+		if err != nil {
+			t.Fatalf("Error getting the connected peer info: %s", err)
+		}
+		for _, peerInfo := range getConnectedPeerInfoResponse.Infos {
+			if !peerInfo.IsEncrypted {
+				t.Fatalf("Expected the connection with %s to be encrypted", peerInfo.Address)
+			}
+		}
+	}
+}
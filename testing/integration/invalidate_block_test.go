@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+)
+
+func TestInvalidateBlock(t *testing.T) {
+	harnessParams := &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	}
+	kaspad, teardown := setupHarness(t, harnessParams)
+	defer teardown()
+
+	const blockAmountToMine = 3
+	var blockHashes []string
+	for i := 0; i < blockAmountToMine; i++ {
+		block := mineNextBlock(t, kaspad)
+		blockHashes = append(blockHashes, consensushashing.BlockHash(block).String())
+	}
+
+	err := kaspad.rpcClient.InvalidateBlock(blockHashes[1])
+	if err != nil {
+		t.Fatalf("Error invalidating block %s: %s", blockHashes[1], err)
+	}
+	requireSelectedTip(t, kaspad, blockHashes[0])
+
+	err = kaspad.rpcClient.InvalidateBlock(blockHashes[1])
+	if err == nil {
+		t.Fatalf("Expected an error invalidating a block that is already invalidated")
+	}
+
+	// The next block is mined on top of the block before the invalidated one
+	forkBlock := mineNextBlock(t, kaspad)
+	forkBlockHash := consensushashing.BlockHash(forkBlock).String()
+	if forkBlock.Header.DirectParents()[0].String() != blockHashes[0] || len(forkBlock.Header.DirectParents()) != 1 {
+		t.Fatalf("Expected the block mined after the invalidation to point only at %s but got %s",
+			blockHashes[0], forkBlock.Header.DirectParents())
+	}
+	requireSelectedTip(t, kaspad, forkBlockHash)
+
+	err = kaspad.rpcClient.ReconsiderBlock(blockHashes[1])
+	if err != nil {
+		t.Fatalf("Error reconsidering block %s: %s", blockHashes[1], err)
+	}
+	requireSelectedTip(t, kaspad, blockHashes[2])
+
+	err = kaspad.rpcClient.ReconsiderBlock(blockHashes[1])
+	if err == nil {
+		t.Fatalf("Expected an error reconsidering a block that isn't invalidated")
+	}
+
+	err = kaspad.rpcClient.InvalidateBlock("invalid hash")
+	if err == nil {
+		t.Fatalf("Expected an error invalidating a block by an invalid hash")
+	}
+}
+
+func requireSelectedTip(t *testing.T, harness *appHarness, expectedSelectedTipHash string) {
+	response, err := harness.rpcClient.GetSelectedTipHash()
+	if err != nil {
+		t.Fatalf("Error getting the selected tip hash: %s", err)
+	}
+	if response.SelectedTipHash != expectedSelectedTipHash {
+		t.Fatalf("Expected the selected tip to be %s but got %s", expectedSelectedTipHash, response.SelectedTipHash)
+	}
+}
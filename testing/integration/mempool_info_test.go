@@ -0,0 +1,43 @@
+package integration
+
+import (
+	"testing"
+)
+
+func TestGetMempoolInfo(t *testing.T) {
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	})
+	defer teardown()
+
+	getMempoolInfoResponse, err := harness.rpcClient.GetMempoolInfo()
+	if err != nil {
+		t.Fatalf("Error getting the mempool info: %s", err)
+	}
+	if getMempoolInfoResponse.TransactionCount != 0 || getMempoolInfoResponse.MemoryUsage != 0 {
+		t.Fatalf("Expected an empty mempool but got %d transactions occupying %d bytes",
+			getMempoolInfoResponse.TransactionCount, getMempoolInfoResponse.MemoryUsage)
+	}
+	expectedMaximumMemoryUsage := harness.config.MaxMempool * 1_000_000
+	if getMempoolInfoResponse.MaximumMemoryUsage != expectedMaximumMemoryUsage {
+		t.Fatalf("Expected a maximum memory usage of %d but got %d",
+			expectedMaximumMemoryUsage, getMempoolInfoResponse.MaximumMemoryUsage)
+	}
+	// The minimum fee is only raised above the configured one once transactions are evicted
+	if getMempoolInfoResponse.MempoolMinimumFee != uint64(harness.config.MinRelayTxFee) ||
+		getMempoolInfoResponse.MinimumRelayTransactionFee != uint64(harness.config.MinRelayTxFee) {
+		t.Fatalf("Expected a minimum fee of %d but got %d",
+			harness.config.MinRelayTxFee, getMempoolInfoResponse.MempoolMinimumFee)
+	}
+	if getMempoolInfoResponse.OrphanCount != 0 || getMempoolInfoResponse.MissingOrphanOutpointCount != 0 {
+		t.Fatalf("Expected an empty orphan pool but got %d orphans that miss %d outpoints",
+			getMempoolInfoResponse.OrphanCount, getMempoolInfoResponse.MissingOrphanOutpointCount)
+	}
+	if getMempoolInfoResponse.MaximumOrphanCount != harness.config.MaxOrphanTxs {
+		t.Fatalf("Expected a maximum of %d orphans but got %d",
+			harness.config.MaxOrphanTxs, getMempoolInfoResponse.MaximumOrphanCount)
+	}
+}
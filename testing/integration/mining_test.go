@@ -7,6 +7,7 @@ import (
 
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/mining"
 )
 
@@ -31,3 +32,176 @@ func mineNextBlock(t *testing.T, harness *appHarness) *externalapi.DomainBlock {
 
 	return block
 }
+
+func TestGetBlockTemplateLongPoll(t *testing.T) {
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	})
+	defer teardown()
+
+	capabilities := []string{
+		appmessage.BlockTemplateCapabilityLongPoll,
+		appmessage.BlockTemplateMutationCoinbaseAppend,
+		"unknown",
+	}
+	blockTemplate, err := harness.rpcClient.GetBlockTemplateWithCapabilities(harness.miningAddress, "", "", capabilities)
+	if err != nil {
+		t.Fatalf("Error getting block template: %+v", err)
+	}
+	if blockTemplate.LongPollID == "" {
+		t.Fatalf("Expected a long poll ID")
+	}
+	if len(blockTemplate.Capabilities) != 2 {
+		t.Fatalf("Unexpected negotiated capabilities: %v", blockTemplate.Capabilities)
+	}
+	if len(blockTemplate.Mutable) != 1 || blockTemplate.Mutable[0] != appmessage.BlockTemplateMutationCoinbaseAppend {
+		t.Fatalf("Unexpected mutable fields: %v", blockTemplate.Mutable)
+	}
+	if blockTemplate.CoinbaseAppendMaxLength == 0 {
+		t.Fatalf("Expected a positive coinbase append max length")
+	}
+
+	// mineNextBlock requests a template of its own, whose response could be mistaken for the
+	// response to the long poll if both were sent on the same connection
+	longPollClient, err := newTestRPCClient(rpcAddress1)
+	if err != nil {
+		t.Fatalf("Error creating RPC client: %s", err)
+	}
+	defer longPollClient.Close()
+
+	type longPollResult struct {
+		response *appmessage.GetBlockTemplateResponseMessage
+		err      error
+	}
+	longPollResultChan := make(chan longPollResult)
+	go func() {
+		response, err := longPollClient.GetBlockTemplateWithCapabilities(harness.miningAddress, "",
+			blockTemplate.LongPollID, capabilities)
+		longPollResultChan <- longPollResult{response: response, err: err}
+	}()
+
+	select {
+	case <-longPollResultChan:
+		t.Fatalf("Long poll returned before a new block template was available")
+	case <-time.After(time.Second):
+	}
+
+	minedBlock := mineNextBlock(t, harness)
+
+	result := <-longPollResultChan
+	if result.err != nil {
+		t.Fatalf("Error long polling for a block template: %+v", result.err)
+	}
+	if result.response.LongPollID == blockTemplate.LongPollID {
+		t.Fatalf("Expected the long poll ID to change after a block was mined")
+	}
+	newTemplateBlock, err := appmessage.RPCBlockToDomainBlock(result.response.Block)
+	if err != nil {
+		t.Fatalf("Error converting block: %s", err)
+	}
+	if !newTemplateBlock.Header.DirectParents().Contains(consensushashing.BlockHash(minedBlock)) {
+		t.Fatalf("Expected the new block template to point at the mined block")
+	}
+}
+
+func TestSubmitBlockDuringLongPoll(t *testing.T) {
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	})
+	defer teardown()
+
+	capabilities := []string{appmessage.BlockTemplateCapabilityLongPoll}
+	blockTemplate, err := harness.rpcClient.GetBlockTemplateWithCapabilities(harness.miningAddress, "", "", capabilities)
+	if err != nil {
+		t.Fatalf("Error getting block template: %+v", err)
+	}
+	templateBlock, err := appmessage.RPCBlockToDomainBlock(blockTemplate.Block)
+	if err != nil {
+		t.Fatalf("Error converting block: %s", err)
+	}
+
+	longPollErrChan := make(chan error)
+	go func() {
+		_, err := harness.rpcClient.GetBlockTemplateWithCapabilities(harness.miningAddress, "",
+			blockTemplate.LongPollID, capabilities)
+		longPollErrChan <- err
+	}()
+
+	select {
+	case <-longPollErrChan:
+		t.Fatalf("Long poll returned before a new block template was available")
+	case <-time.After(time.Second):
+	}
+
+	// The block is submitted on the connection of the pending long poll, and has to be
+	// answered well before the long poll times out
+	rd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	mining.SolveBlock(templateBlock, rd)
+	submitErrChan := make(chan error)
+	go func() {
+		_, err := harness.rpcClient.SubmitBlockAlsoIfNonDAA(templateBlock)
+		submitErrChan <- err
+	}()
+	select {
+	case err := <-submitErrChan:
+		if err != nil {
+			t.Fatalf("Error submitting block: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("submitBlock wasn't answered while a long poll was pending on the same connection")
+	}
+
+	err = <-longPollErrChan
+	if err != nil {
+		t.Fatalf("Error long polling for a block template: %+v", err)
+	}
+}
+
+func TestGetBlockTemplateMutations(t *testing.T) {
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	})
+	defer teardown()
+
+	capabilities := []string{appmessage.BlockTemplateMutationTime}
+	blockTemplate, err := harness.rpcClient.GetBlockTemplateWithCapabilities(harness.miningAddress, "", "", capabilities)
+	if err != nil {
+		t.Fatalf("Error getting block template: %+v", err)
+	}
+	templateBlock, err := appmessage.RPCBlockToDomainBlock(blockTemplate.Block)
+	if err != nil {
+		t.Fatalf("Error converting block: %s", err)
+	}
+
+	// Appending to the coinbase payload wasn't negotiated, so the block has to be rejected
+	appendedBlock := templateBlock.Clone()
+	coinbase := appendedBlock.Transactions[0]
+	coinbase.Payload = append(coinbase.Payload, []byte("appended")...)
+	rejectReason, err := harness.rpcClient.SubmitBlockAlsoIfNonDAA(appendedBlock)
+	if err == nil {
+		t.Fatalf("Expected a block with an appended coinbase payload to be rejected")
+	}
+	if rejectReason != appmessage.RejectReasonBlockInvalid {
+		t.Fatalf("Unexpected reject reason: %s", rejectReason)
+	}
+
+	// Increasing the timestamp was negotiated, so the block has to be accepted
+	mutatedHeader := templateBlock.Header.ToMutable()
+	mutatedHeader.SetTimeInMilliseconds(templateBlock.Header.TimeInMilliseconds() + 1)
+	templateBlock.Header = mutatedHeader.ToImmutable()
+	rd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	mining.SolveBlock(templateBlock, rd)
+	_, err = harness.rpcClient.SubmitBlockAlsoIfNonDAA(templateBlock)
+	if err != nil {
+		t.Fatalf("Error submitting a block with a mutated timestamp: %s", err)
+	}
+}
@@ -0,0 +1,48 @@
+package integration
+
+import (
+	"testing"
+)
+
+func TestGetNetTotals(t *testing.T) {
+	appHarness1, appHarness2, appHarness3, teardown := standardSetup(t)
+	defer teardown()
+
+	connect(t, appHarness1, appHarness2)
+	connect(t, appHarness1, appHarness3)
+
+	mineNextBlock(t, appHarness1)
+
+	// The peer info is requested first since traffic keeps flowing between the requests
+	getConnectedPeerInfoResponse, err := appHarness1.rpcClient.GetConnectedPeerInfo()
+	if err != nil {
+		t.Fatalf("Error getting the connected peer info: %s", err)
+	}
+	var peersBytesSent uint64
+	for _, peerInfo := range getConnectedPeerInfoResponse.Infos {
+		if peerInfo.BytesSent == 0 || peerInfo.BytesReceived == 0 {
+			t.Fatalf("Expected traffic in both directions with %s", peerInfo.Address)
+		}
+		peersBytesSent += peerInfo.BytesSent
+	}
+
+	getNetTotalsResponse, err := appHarness1.rpcClient.GetNetTotals()
+	if err != nil {
+		t.Fatalf("Error getting the net totals: %s", err)
+	}
+	if getNetTotalsResponse.TotalBytesSent == 0 || getNetTotalsResponse.TotalBytesReceived == 0 {
+		t.Fatalf("Expected traffic in both directions but got %d bytes sent and %d bytes received",
+			getNetTotalsResponse.TotalBytesSent, getNetTotalsResponse.TotalBytesReceived)
+	}
+	if getNetTotalsResponse.UploadTarget == nil {
+		t.Fatalf("Expected the upload target to be reported")
+	}
+	if getNetTotalsResponse.UploadTarget.Target != 0 || !getNetTotalsResponse.UploadTarget.ServeHistoricalBlocks {
+		t.Fatalf("Expected no upload target by default")
+	}
+
+	if peersBytesSent > getNetTotalsResponse.TotalBytesSent {
+		t.Fatalf("Expected the bytes sent to peers (%d) to be at most the total bytes sent (%d)",
+			peersBytesSent, getNetTotalsResponse.TotalBytesSent)
+	}
+}
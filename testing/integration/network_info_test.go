@@ -0,0 +1,49 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+)
+
+func TestGetNetworkInfo(t *testing.T) {
+	appHarness1, appHarness2, appHarness3, teardown := standardSetup(t)
+	defer teardown()
+
+	connect(t, appHarness1, appHarness2)
+	connect(t, appHarness1, appHarness3)
+	connect(t, appHarness2, appHarness3)
+
+	getNetworkInfoResponse, err := appHarness2.rpcClient.GetNetworkInfo()
+	if err != nil {
+		t.Fatalf("Error getting the network info: %s", err)
+	}
+	if getNetworkInfoResponse.InboundConnectionCount != 1 || getNetworkInfoResponse.OutboundConnectionCount != 1 {
+		t.Fatalf("Expected 1 inbound and 1 outbound connection but got %d inbound and %d outbound",
+			getNetworkInfoResponse.InboundConnectionCount, getNetworkInfoResponse.OutboundConnectionCount)
+	}
+	if getNetworkInfoResponse.ProtocolVersion != appHarness2.config.ProtocolVersion {
+		t.Fatalf("Expected protocol version %d but got %d",
+			appHarness2.config.ProtocolVersion, getNetworkInfoResponse.ProtocolVersion)
+	}
+	if appmessage.ServiceFlag(getNetworkInfoResponse.LocalServices)&appmessage.SFNodeNetwork == 0 {
+		t.Fatalf("Expected the local services %d to include %s",
+			getNetworkInfoResponse.LocalServices, appmessage.SFNodeNetwork)
+	}
+	if getNetworkInfoResponse.MinimumRelayTransactionFee != uint64(appHarness2.config.MinRelayTxFee) {
+		t.Fatalf("Expected a minimum relay fee of %d but got %d",
+			appHarness2.config.MinRelayTxFee, getNetworkInfoResponse.MinimumRelayTransactionFee)
+	}
+	if len(getNetworkInfoResponse.Networks) != 4 {
+		t.Fatalf("Expected the reachability of 4 networks but got %d", len(getNetworkInfoResponse.Networks))
+	}
+	for _, network := range getNetworkInfoResponse.Networks[:2] {
+		if network.Limited {
+			t.Fatalf("Expected %s not to be limited without --ipv6only", network.Name)
+		}
+		if network.SelfTestPassed != (network.SelfTestError == "") {
+			t.Fatalf("Expected the self-test of %s to either pass or have an error, but got passed: %t, error: %q",
+				network.Name, network.SelfTestPassed, network.SelfTestError)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+)
+
+func TestPackageRelay(t *testing.T) {
+	payer, mediator, payee, teardown := standardSetup(t)
+	defer teardown()
+
+	// Connect nodes in chain: payer <--> mediator <--> payee
+	connect(t, payer, mediator)
+	connect(t, mediator, payee)
+
+	payeeBlockAddedChan := make(chan *appmessage.RPCBlockHeader)
+	setOnBlockAddedHandler(t, payee, func(notification *appmessage.BlockAddedNotificationMessage) {
+		payeeBlockAddedChan <- notification.Block.Header
+	})
+	// skip the first block because it's paying to genesis script
+	mineNextBlock(t, payer)
+	waitForPayeeToReceiveBlock(t, payeeBlockAddedChan)
+	secondBlock := mineNextBlock(t, payer)
+	waitForPayeeToReceiveBlock(t, payeeBlockAddedChan)
+	for i := uint64(0); i < payer.config.ActiveNetParams.BlockCoinbaseMaturity; i++ {
+		mineNextBlock(t, payer)
+		waitForPayeeToReceiveBlock(t, payeeBlockAddedChan)
+	}
+
+	// The parent pays no fee, and its child pays for both
+	parent := generateTxWithFee(t, secondBlock.Transactions[transactionhelper.CoinbaseTransactionIndex], 0, payer, payer)
+	parentDomainTransaction := appmessage.MsgTxToDomainTransaction(parent)
+	child := generateTxWithFee(t, parentDomainTransaction, 10000, payer, payee)
+	childDomainTransaction := appmessage.MsgTxToDomainTransaction(child)
+
+	response, err := payer.rpcClient.SubmitPackage([]*appmessage.RPCTransaction{
+		appmessage.DomainTransactionToRPCTransaction(parentDomainTransaction),
+		appmessage.DomainTransactionToRPCTransaction(childDomainTransaction),
+	})
+	if err != nil {
+		t.Fatalf("Error submitting package: %+v", err)
+	}
+	if len(response.TransactionIDs) != 2 {
+		t.Fatalf("Expected 2 accepted transactions but got %d", len(response.TransactionIDs))
+	}
+
+	// The payee only gets the package through the mediator
+	for _, transaction := range []*appmessage.MsgTx{parent, child} {
+		transactionID := consensushashing.TransactionID(appmessage.MsgTxToDomainTransaction(transaction)).String()
+		deadline := time.Now().Add(defaultTimeout)
+		for {
+			getMempoolEntryResponse, err := payee.rpcClient.GetMempoolEntry(transactionID, false, false)
+			if err == nil {
+				if getMempoolEntryResponse.Entry.IsOrphan {
+					t.Fatalf("Expected transaction %s not to be an orphan", transactionID)
+				}
+				break
+			}
+			if !strings.Contains(err.Error(), "not found") {
+				t.Fatalf("Error getting mempool entry: %+v", err)
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Timeout waiting for transaction %s to be relayed to the payee", transactionID)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
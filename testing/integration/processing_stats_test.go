@@ -0,0 +1,45 @@
+package integration
+
+import (
+	"testing"
+)
+
+func TestGetProcessingStats(t *testing.T) {
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	})
+	defer teardown()
+
+	const blockCount = 3
+	for i := 0; i < blockCount; i++ {
+		mineNextBlock(t, harness)
+	}
+
+	getProcessingStatsResponse, err := harness.rpcClient.GetProcessingStats()
+	if err != nil {
+		t.Fatalf("Error getting the processing stats: %s", err)
+	}
+
+	// The stats are global, so other tests in this process may have processed blocks too
+	statsByStage := make(map[string]bool)
+	for _, stage := range getProcessingStatsResponse.Stages {
+		statsByStage[stage.Stage] = true
+		if stage.P50Microseconds > stage.P90Microseconds || stage.P90Microseconds > stage.P99Microseconds ||
+			stage.P99Microseconds > stage.MaxMicroseconds {
+			t.Fatalf("Expected the percentiles of stage %s to be ordered but got %+v", stage.Stage, stage)
+		}
+		switch stage.Stage {
+		case "isolationChecks", "contextChecks", "virtualUpdate", "databaseCommit", "blockProcessing":
+			if stage.Count < blockCount {
+				t.Fatalf("Expected stage %s to run at least %d times but it ran %d times",
+					stage.Stage, blockCount, stage.Count)
+			}
+		}
+	}
+	if !statsByStage["blockProcessing"] || !statsByStage["scriptValidation"] {
+		t.Fatalf("Expected the stats of all the stages but got %v", statsByStage)
+	}
+}
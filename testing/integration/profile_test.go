@@ -0,0 +1,90 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfile(t *testing.T) {
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	})
+	defer teardown()
+
+	profilesDir := filepath.Join(harness.config.AppDir, "profiles")
+	requireNonEmptyProfile := func(filePath string) {
+		if filepath.Dir(filePath) != profilesDir {
+			t.Fatalf("Expected the profile %s to be in %s", filePath, profilesDir)
+		}
+		fileInfo, err := os.Stat(filePath)
+		if err != nil {
+			t.Fatalf("Error reading the profile %s: %s", filePath, err)
+		}
+		if fileInfo.Size() == 0 {
+			t.Fatalf("The profile %s is empty", filePath)
+		}
+	}
+
+	startResponse, err := harness.rpcClient.StartProfile("cpu")
+	if err != nil {
+		t.Fatalf("Error starting a CPU profile: %s", err)
+	}
+	_, err = harness.rpcClient.StartProfile("cpu")
+	if err == nil {
+		t.Fatalf("Expected an error starting a CPU profile while one is running")
+	}
+	mineNextBlock(t, harness)
+	stopResponse, err := harness.rpcClient.StopProfile("cpu")
+	if err != nil {
+		t.Fatalf("Error stopping the CPU profile: %s", err)
+	}
+	if stopResponse.FilePath != startResponse.FilePath {
+		t.Fatalf("Expected the stopped profile to be %s but got %s", startResponse.FilePath, stopResponse.FilePath)
+	}
+	requireNonEmptyProfile(stopResponse.FilePath)
+
+	_, err = harness.rpcClient.StopProfile("cpu")
+	if err == nil {
+		t.Fatalf("Expected an error stopping a CPU profile that isn't running")
+	}
+
+	startResponse, err = harness.rpcClient.StartProfile("trace")
+	if err != nil {
+		t.Fatalf("Error starting an execution trace: %s", err)
+	}
+	mineNextBlock(t, harness)
+	stopResponse, err = harness.rpcClient.StopProfile("trace")
+	if err != nil {
+		t.Fatalf("Error stopping the execution trace: %s", err)
+	}
+	requireNonEmptyProfile(stopResponse.FilePath)
+
+	startResponse, err = harness.rpcClient.StartProfile("heap")
+	if err != nil {
+		t.Fatalf("Error writing a heap profile: %s", err)
+	}
+	requireNonEmptyProfile(startResponse.FilePath)
+
+	startResponse, err = harness.rpcClient.StartProfile("goroutine")
+	if err != nil {
+		t.Fatalf("Error writing a goroutine dump: %s", err)
+	}
+	requireNonEmptyProfile(startResponse.FilePath)
+	goroutineDump, err := os.ReadFile(startResponse.FilePath)
+	if err != nil {
+		t.Fatalf("Error reading the goroutine dump: %s", err)
+	}
+	if !strings.Contains(string(goroutineDump), "goroutine ") {
+		t.Fatalf("The goroutine dump doesn't contain any goroutine")
+	}
+
+	_, err = harness.rpcClient.StartProfile("nosuchprofile")
+	if err == nil {
+		t.Fatalf("Expected an error starting a profile of an unknown type")
+	}
+}
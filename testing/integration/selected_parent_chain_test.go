@@ -77,6 +77,31 @@ func TestVirtualSelectedParentChain(t *testing.T) {
 			blockAmountToMine, len(reorgNotification.RemovedChainBlockHashes))
 	}
 
+	// Make sure that the transactions accepted by the removed blocks are reported
+	// as unaccepted, unless they're accepted again by the added blocks
+	if len(reorgNotification.UnacceptedTransactionIDs) != blockAmountToMine {
+		t.Fatalf("Unexpected length of reorgNotification.UnacceptedTransactionIDs. Want: %d, got: %d",
+			blockAmountToMine, len(reorgNotification.UnacceptedTransactionIDs))
+	}
+	reacceptedTransactionIDs := make(map[string]struct{})
+	for _, acceptedTransactionIDs := range reorgNotification.AcceptedTransactionIDs {
+		for _, transactionID := range acceptedTransactionIDs.AcceptedTransactionIDs {
+			reacceptedTransactionIDs[transactionID] = struct{}{}
+		}
+	}
+	for i, unacceptedTransactionIDs := range reorgNotification.UnacceptedTransactionIDs {
+		if unacceptedTransactionIDs.AcceptingBlockHash != reorgNotification.RemovedChainBlockHashes[i] {
+			t.Fatalf("Unexpected accepting block hash in UnacceptedTransactionIDs. Want: %s, got: %s",
+				reorgNotification.RemovedChainBlockHashes[i], unacceptedTransactionIDs.AcceptingBlockHash)
+		}
+		for _, transactionID := range unacceptedTransactionIDs.AcceptedTransactionIDs {
+			if _, ok := reacceptedTransactionIDs[transactionID]; ok {
+				t.Fatalf("Transaction %s is reported as unaccepted although it's accepted by an added block",
+					transactionID)
+			}
+		}
+	}
+
 	// Get the virtual selected parent chain from the tip of
 	// the first chain
 	virtualSelectedParentChainFromChain1Tip, err := kaspad1.rpcClient.GetVirtualSelectedParentChainFromBlock(
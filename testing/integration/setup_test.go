@@ -24,7 +24,9 @@ type appHarness struct {
 	config                  *config.Config
 	database                database.Database
 	utxoIndex               bool
+	txIndex                 bool
 	overrideDAGParams       *dagconfig.Params
+	trustedPeers            []string
 }
 
 type harnessParams struct {
@@ -33,8 +35,10 @@ type harnessParams struct {
 	miningAddress           string
 	miningAddressPrivateKey string
 	utxoIndex               bool
+	txIndex                 bool
 	overrideDAGParams       *dagconfig.Params
 	protocolVersion         uint32
+	trustedPeers            []string
 }
 
 // setupHarness creates a single appHarness with given parameters
@@ -45,7 +49,9 @@ func setupHarness(t *testing.T, params *harnessParams) (harness *appHarness, tea
 		miningAddress:           params.miningAddress,
 		miningAddressPrivateKey: params.miningAddressPrivateKey,
 		utxoIndex:               params.utxoIndex,
+		txIndex:                 params.txIndex,
 		overrideDAGParams:       params.overrideDAGParams,
+		trustedPeers:            params.trustedPeers,
 	}
 
 	setConfig(t, harness, params.protocolVersion)
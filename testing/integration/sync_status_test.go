@@ -0,0 +1,145 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+)
+
+func TestGetSyncStatus(t *testing.T) {
+	harnesses, teardown := setupHarnesses(t, []*harnessParams{
+		{
+			p2pAddress:              p2pAddress1,
+			rpcAddress:              rpcAddress1,
+			miningAddress:           miningAddress1,
+			miningAddressPrivateKey: miningAddress1PrivateKey,
+		},
+		{
+			p2pAddress:              p2pAddress2,
+			rpcAddress:              rpcAddress2,
+			miningAddress:           miningAddress2,
+			miningAddressPrivateKey: miningAddress2PrivateKey,
+		},
+	})
+	defer teardown()
+	miner, syncee := harnesses[0], harnesses[1]
+
+	// A node that only has the genesis isn't synced
+	response, err := miner.rpcClient.GetSyncStatus()
+	if err != nil {
+		t.Fatalf("Error getting the sync status: %s", err)
+	}
+	if response.IsSynced {
+		t.Fatalf("Expected a node with only the genesis not to be synced")
+	}
+
+	const blockAmountToMine = 10
+	for i := 0; i < blockAmountToMine; i++ {
+		mineNextBlock(t, miner)
+	}
+
+	// A node with a recent tip but without peers isn't synced either
+	response, err = miner.rpcClient.GetSyncStatus()
+	if err != nil {
+		t.Fatalf("Error getting the sync status: %s", err)
+	}
+	if response.IsSynced {
+		t.Fatalf("Expected a node without peers not to be synced")
+	}
+	if response.BlockCount != blockAmountToMine+1 || response.HeaderCount != blockAmountToMine+1 {
+		t.Fatalf("Unexpected block and header counts. Want: %d, got: %d and %d",
+			blockAmountToMine+1, response.BlockCount, response.HeaderCount)
+	}
+	if response.HeadersProgress != 100 || response.BlocksProgress != 100 {
+		t.Fatalf("Expected the sync of a node without peers to be done, but got %f%% of the headers "+
+			"and %f%% of the blocks", response.HeadersProgress, response.BlocksProgress)
+	}
+
+	connect(t, syncee, miner)
+	mineNextBlock(t, miner)
+
+	deadline := time.Now().Add(defaultTimeout)
+	for {
+		response, err = syncee.rpcClient.GetSyncStatus()
+		if err != nil {
+			t.Fatalf("Error getting the sync status: %s", err)
+		}
+		if response.IsSynced && response.BlockCount == blockAmountToMine+2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("The syncee didn't sync in time. Its last sync status is %+v", response)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if response.IsIBDRunning || response.BlocksProgress != 100 {
+		t.Fatalf("Expected the syncee to be done with IBD, but got %+v", response)
+	}
+}
+
+func TestTrustedPeers(t *testing.T) {
+	harnesses, teardown := setupHarnesses(t, []*harnessParams{
+		{
+			p2pAddress:              p2pAddress1,
+			rpcAddress:              rpcAddress1,
+			miningAddress:           miningAddress1,
+			miningAddressPrivateKey: miningAddress1PrivateKey,
+		},
+		{
+			p2pAddress:              p2pAddress2,
+			rpcAddress:              rpcAddress2,
+			miningAddress:           miningAddress2,
+			miningAddressPrivateKey: miningAddress2PrivateKey,
+			trustedPeers:            []string{p2pAddress1},
+		},
+	})
+	defer teardown()
+	trustedPeer, node := harnesses[0], harnesses[1]
+
+	// The node connects to its trusted peer by itself
+	deadline := time.Now().Add(defaultTimeout)
+	for {
+		connectedPeerInfo, err := node.rpcClient.GetConnectedPeerInfo()
+		if err != nil {
+			t.Fatalf("Error getting the connected peer info: %s", err)
+		}
+		if len(connectedPeerInfo.Infos) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("The node didn't connect to its trusted peer in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var lastBlockHash string
+	for i := 0; i < 5; i++ {
+		block := mineNextBlock(t, trustedPeer)
+		lastBlockHash = consensushashing.BlockHash(block).String()
+	}
+
+	deadline = time.Now().Add(defaultTimeout)
+	for {
+		response, err := node.rpcClient.GetSyncStatus()
+		if err != nil {
+			t.Fatalf("Error getting the sync status: %s", err)
+		}
+		if len(response.TrustedPeers) != 1 {
+			t.Fatalf("Expected a single trusted peer but got %d", len(response.TrustedPeers))
+		}
+		trustedPeerStatus := response.TrustedPeers[0]
+		if trustedPeerStatus.IsDiverged {
+			t.Fatalf("Expected the trusted peer not to diverge, but got %+v", trustedPeerStatus)
+		}
+		if response.IsSynced && response.TrustedChainBlockHash == lastBlockHash &&
+			trustedPeerStatus.AgreedChainBlockHash == lastBlockHash {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("The node didn't agree with its trusted peer in time. Its last sync status is %+v "+
+				"and the status of its trusted peer is %+v", response, trustedPeerStatus)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+)
+
+func TestTestMempoolAccept(t *testing.T) {
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	})
+	defer teardown()
+
+	// skip the first block because it's paying to genesis script
+	mineNextBlock(t, harness)
+	secondBlock := mineNextBlock(t, harness)
+	for i := uint64(0); i < harness.config.ActiveNetParams.BlockCoinbaseMaturity; i++ {
+		mineNextBlock(t, harness)
+	}
+
+	msgTx := generateTx(t, secondBlock.Transactions[transactionhelper.CoinbaseTransactionIndex], harness, harness)
+	rpcTransaction := appmessage.DomainTransactionToRPCTransaction(appmessage.MsgTxToDomainTransaction(msgTx))
+	response, err := harness.rpcClient.TestMempoolAccept([]*appmessage.RPCTransaction{rpcTransaction, rpcTransaction})
+	if err != nil {
+		t.Fatalf("Error testing mempool acceptance: %s", err)
+	}
+	if len(response.Results) != 2 {
+		t.Fatalf("Expected 2 results but got %d", len(response.Results))
+	}
+	result := response.Results[0]
+	if !result.Allowed || result.RejectReason != "" || result.Fee != 1000 || result.Mass == 0 {
+		t.Fatalf("Expected the transaction to be allowed with a fee of 1000 but got %+v", result)
+	}
+	// The second copy is checked after the first one, so it's a duplicate
+	if response.Results[1].Allowed || response.Results[1].RejectReason == "" {
+		t.Fatalf("Expected the second copy of the transaction to be rejected but got %+v", response.Results[1])
+	}
+
+	getMempoolInfoResponse, err := harness.rpcClient.GetMempoolInfo()
+	if err != nil {
+		t.Fatalf("Error getting the mempool info: %s", err)
+	}
+	if getMempoolInfoResponse.TransactionCount != 0 {
+		t.Fatalf("Expected the tested transaction not to be added to the mempool")
+	}
+}
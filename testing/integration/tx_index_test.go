@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+)
+
+func TestTXIndex(t *testing.T) {
+	kaspad, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+		txIndex:                 true,
+	})
+	defer teardown()
+
+	block := mineNextBlock(t, kaspad)
+	blockHash := consensushashing.BlockHash(block)
+	coinbaseTransactionID := consensushashing.TransactionID(block.Transactions[0])
+
+	// The index is updated asynchronously once the block is added, so wait for it
+	var err error
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(100 * time.Millisecond) {
+		getRawTransactionResponse, getRawTransactionErr := kaspad.rpcClient.GetRawTransaction(coinbaseTransactionID.String())
+		err = getRawTransactionErr
+		if err != nil {
+			continue
+		}
+		if getRawTransactionResponse.IsInMempool {
+			t.Fatalf("Expected the coinbase transaction not to be in the mempool")
+		}
+		if getRawTransactionResponse.Transaction.VerboseData.TransactionID != coinbaseTransactionID.String() {
+			t.Fatalf("Expected transaction %s but got %s",
+				coinbaseTransactionID, getRawTransactionResponse.Transaction.VerboseData.TransactionID)
+		}
+		if getRawTransactionResponse.Transaction.VerboseData.BlockHash != blockHash.String() {
+			t.Fatalf("Expected the transaction to be located in block %s but got %s",
+				blockHash, getRawTransactionResponse.Transaction.VerboseData.BlockHash)
+		}
+		break
+	}
+	if err != nil {
+		t.Fatalf("Error getting the coinbase transaction: %s", err)
+	}
+
+	// The transactions of the virtual selected parent are not accepted by any chain block yet
+	getRawTransactionResponse, err := kaspad.rpcClient.GetRawTransaction(coinbaseTransactionID.String())
+	if err != nil {
+		t.Fatalf("Error getting the coinbase transaction: %s", err)
+	}
+	if getRawTransactionResponse.Confirmations != 0 || getRawTransactionResponse.AcceptingBlockHash != "" {
+		t.Fatalf("Expected the coinbase transaction not to be accepted yet, but it has %d confirmations by block %s",
+			getRawTransactionResponse.Confirmations, getRawTransactionResponse.AcceptingBlockHash)
+	}
+
+	// The coinbase transaction is accepted by the next chain block, and the blue
+	// score of the virtual grows by one with every block in a single chain
+	acceptingBlock := mineNextBlock(t, kaspad)
+	mineNextBlock(t, kaspad)
+	getRawTransactionResponse, err = kaspad.rpcClient.GetRawTransaction(coinbaseTransactionID.String())
+	if err != nil {
+		t.Fatalf("Error getting the coinbase transaction: %s", err)
+	}
+	acceptingBlockHash := consensushashing.BlockHash(acceptingBlock)
+	if getRawTransactionResponse.AcceptingBlockHash != acceptingBlockHash.String() {
+		t.Fatalf("Expected the coinbase transaction to be accepted by block %s but got %s",
+			acceptingBlockHash, getRawTransactionResponse.AcceptingBlockHash)
+	}
+	if getRawTransactionResponse.Confirmations != 2 {
+		t.Fatalf("Expected the coinbase transaction to have 2 confirmations but got %d",
+			getRawTransactionResponse.Confirmations)
+	}
+
+	const unknownTransactionID = "0000000000000000000000000000000000000000000000000000000000000001"
+	_, err = kaspad.rpcClient.GetRawTransaction(unknownTransactionID)
+	if err == nil {
+		t.Fatalf("Expected an error getting an unknown transaction")
+	}
+}
@@ -0,0 +1,87 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+)
+
+func TestTxOutProof(t *testing.T) {
+	harness, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+		txIndex:                 true,
+	})
+	defer teardown()
+
+	// skip the first block because it's paying to genesis script
+	mineNextBlock(t, harness)
+	secondBlock := mineNextBlock(t, harness)
+	for i := uint64(0); i < harness.config.ActiveNetParams.BlockCoinbaseMaturity; i++ {
+		mineNextBlock(t, harness)
+	}
+
+	msgTx := generateTx(t, secondBlock.Transactions[transactionhelper.CoinbaseTransactionIndex], harness, harness)
+	domainTransaction := appmessage.MsgTxToDomainTransaction(msgTx)
+	transactionID := consensushashing.TransactionID(domainTransaction).String()
+	_, err := harness.rpcClient.SubmitTransaction(appmessage.DomainTransactionToRPCTransaction(domainTransaction),
+		transactionID, false)
+	if err != nil {
+		t.Fatalf("Error submitting the transaction: %s", err)
+	}
+	block := mineNextBlock(t, harness)
+	if len(block.Transactions) != 2 {
+		t.Fatalf("Expected the block to contain the coinbase and the submitted transaction but got %d transactions",
+			len(block.Transactions))
+	}
+	blockHash := consensushashing.BlockHash(block).String()
+
+	getTxOutProofResponse, err := harness.rpcClient.GetTxOutProof(transactionID, blockHash)
+	if err != nil {
+		t.Fatalf("Error getting the proof: %s", err)
+	}
+	proof := getTxOutProofResponse.Proof
+	if proof.Index != 1 || len(proof.Siblings) != 1 {
+		t.Fatalf("Expected a proof of the second leaf with a single sibling but got index %d with %d siblings",
+			proof.Index, len(proof.Siblings))
+	}
+	verifyTxOutProofResponse, err := harness.rpcClient.VerifyTxOutProof(proof)
+	if err != nil {
+		t.Fatalf("Error verifying the proof: %s", err)
+	}
+	if verifyTxOutProofResponse.TransactionID != transactionID || verifyTxOutProofResponse.BlockHash != blockHash {
+		t.Fatalf("Expected the proof to prove that block %s contains transaction %s but got block %s and "+
+			"transaction %s", blockHash, transactionID, verifyTxOutProofResponse.BlockHash,
+			verifyTxOutProofResponse.TransactionID)
+	}
+
+	// With --txindex the block hash may be omitted. The index is updated asynchronously.
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(100 * time.Millisecond) {
+		getTxOutProofResponse, err = harness.rpcClient.GetTxOutProof(transactionID, "")
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("Error getting the proof without a block hash: %s", err)
+	}
+	if getTxOutProofResponse.Proof.Header.HashMerkleRoot != proof.Header.HashMerkleRoot {
+		t.Fatalf("Expected the proof without a block hash to be of block %s", blockHash)
+	}
+
+	_, err = harness.rpcClient.GetTxOutProof(transactionID, consensushashing.BlockHash(secondBlock).String())
+	if err == nil {
+		t.Fatalf("Expected an error getting a proof from a block that doesn't contain the transaction")
+	}
+
+	proof.Siblings[0] = consensushashing.TransactionHash(secondBlock.Transactions[0]).String()
+	_, err = harness.rpcClient.VerifyTxOutProof(proof)
+	if err == nil {
+		t.Fatalf("Expected a tampered proof not to verify")
+	}
+}
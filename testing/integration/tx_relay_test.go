@@ -136,8 +136,16 @@ func waitForPayeeToReceiveBlock(t *testing.T, payeeBlockAddedChan chan *appmessa
 }
 
 func generateTx(t *testing.T, firstBlockCoinbase *externalapi.DomainTransaction, payer, payee *appHarness) *appmessage.MsgTx {
+	return generateTxWithFee(t, firstBlockCoinbase, 1000, payer, payee)
+}
+
+// generateTxWithFee returns a transaction that pays the first output of txToSpend, which belongs
+// to the payer, to the payee, apart from the given fee
+func generateTxWithFee(t *testing.T, txToSpend *externalapi.DomainTransaction, fee uint64,
+	payer, payee *appHarness) *appmessage.MsgTx {
+
 	txIns := make([]*appmessage.TxIn, 1)
-	txIns[0] = appmessage.NewTxIn(appmessage.NewOutpoint(consensushashing.TransactionID(firstBlockCoinbase), 0), []byte{}, 0, 1)
+	txIns[0] = appmessage.NewTxIn(appmessage.NewOutpoint(consensushashing.TransactionID(txToSpend), 0), []byte{}, 0, 1)
 
 	payeeAddress, err := util.DecodeAddress(payee.miningAddress, util.Bech32PrefixKaspaSim)
 	if err != nil {
@@ -148,7 +156,7 @@ func generateTx(t *testing.T, firstBlockCoinbase *externalapi.DomainTransaction,
 		t.Fatalf("Error generating script: %+v", err)
 	}
 
-	txOuts := []*appmessage.TxOut{appmessage.NewTxOut(firstBlockCoinbase.Outputs[0].Value-1000, toScript)}
+	txOuts := []*appmessage.TxOut{appmessage.NewTxOut(txToSpend.Outputs[0].Value-fee, toScript)}
 
 	msgTx := appmessage.NewNativeMsgTx(constants.MaxTransactionVersion, txIns, txOuts)
 
@@ -161,8 +169,8 @@ func generateTx(t *testing.T, firstBlockCoinbase *externalapi.DomainTransaction,
 		t.Fatalf("Error deserializing private key: %+v", err)
 	}
 
-	fromScript := firstBlockCoinbase.Outputs[0].ScriptPublicKey
-	fromAmount := firstBlockCoinbase.Outputs[0].Value
+	fromScript := txToSpend.Outputs[0].ScriptPublicKey
+	fromAmount := txToSpend.Outputs[0].Value
 
 	tx := appmessage.MsgTxToDomainTransaction(msgTx)
 	tx.Inputs[0].UTXOEntry = utxo.NewUTXOEntry(fromAmount, fromScript, false, 500)
@@ -70,6 +70,24 @@ func TestUTXOIndex(t *testing.T) {
 		t.Fatalf("Error: Circulating supply Mismatch - Circulating Sompi: %d Sompi Mined via Block count: %d", getCoinSupplyResponse.CirculatingSompi, rewardsMinedViaBlockCountSompi)
 	}
 
+	// Make sure the UTXO set statistics agree with the circulating supply
+	getUTXOSetInfoResponse, err := kaspad.rpcClient.GetUTXOSetInfo(true)
+	if err != nil {
+		t.Fatalf("Error Retriving UTXO set info: %s", err)
+	}
+	if getUTXOSetInfoResponse.TotalSompi != getCoinSupplyResponse.CirculatingSompi {
+		t.Fatalf("Error: UTXO set info total mismatch - Total Sompi: %d Circulating Sompi: %d",
+			getUTXOSetInfoResponse.TotalSompi, getCoinSupplyResponse.CirculatingSompi)
+	}
+	amountBucketsTotalSompi := uint64(0)
+	for _, entry := range getUTXOSetInfoResponse.AmountBucketStatistics {
+		amountBucketsTotalSompi += entry.Sompi
+	}
+	if amountBucketsTotalSompi != getUTXOSetInfoResponse.TotalSompi {
+		t.Fatalf("Error: amount buckets total %d does not match UTXO set total %d",
+			amountBucketsTotalSompi, getUTXOSetInfoResponse.TotalSompi)
+	}
+
 	// Collect the UTXO and make sure there's nothing in Removed
 	// Note that we expect blockAmountToMine-1 messages because
 	// the last block won't be accepted until the next block is
@@ -0,0 +1,125 @@
+package simulation
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/util/difficulty"
+)
+
+// Metrics describe how consensus responded to the miners of a simulation
+type Metrics struct {
+	Miners []*MinerMetrics
+
+	// ChainBlocks is the number of blocks in the selected chain of the virtual
+	ChainBlocks int
+
+	// Reorgs is the number of published blocks that removed blocks from the selected chain of
+	// the observer, and MaxReorgDepth is the most blocks that one of them removed
+	Reorgs        int
+	MaxReorgDepth int
+
+	// PastMedianTimeDrift is the past median time of the virtual at the end of the
+	// simulation, relative to the simulated time
+	PastMedianTimeDrift time.Duration
+
+	// Difficulty is the difficulty of the virtual, relative to the difficulty of the genesis
+	Difficulty float64
+}
+
+// MinerMetrics describe how consensus responded to a simulated miner
+type MinerMetrics struct {
+	Name string
+
+	// HashShare is the share of the miner of the total hash rate
+	HashShare float64
+
+	// Mined is the number of blocks that the miner mined, including the Rejected ones
+	Mined    int
+	Rejected int
+
+	// Blue and Red are the numbers of blocks of the miner that the virtual merges as blue
+	// and as red, and Unmerged is the number of the accepted blocks it doesn't merge
+	Blue     int
+	Red      int
+	Unmerged int
+
+	// RewardShare is the share of the miner of the blue blocks, which are the rewarded ones
+	RewardShare float64
+}
+
+func (s *simulation) collectMetrics() error {
+	stagingArea := model.NewStagingArea()
+	ghostdagDataStore := s.observer.GHOSTDAGDataStore()
+	genesisHash := s.observer.DAGParams().GenesisHash
+
+	totalBlue := 0
+	countMergeSet := func(ghostdagData *externalapi.BlockGHOSTDAGData) {
+		for _, blueHash := range ghostdagData.MergeSetBlues() {
+			if block := s.blocks[*blueHash]; block.minerIndex >= 0 {
+				s.metrics.Miners[block.minerIndex].Blue++
+				totalBlue++
+			}
+		}
+		for _, redHash := range ghostdagData.MergeSetReds() {
+			if block := s.blocks[*redHash]; block.minerIndex >= 0 {
+				s.metrics.Miners[block.minerIndex].Red++
+			}
+		}
+	}
+	ghostdagData, err := ghostdagDataStore.Get(s.observer.DatabaseContext(), stagingArea, model.VirtualBlockHash, false)
+	if err != nil {
+		return err
+	}
+	for {
+		countMergeSet(ghostdagData)
+		if ghostdagData.SelectedParent().Equal(genesisHash) {
+			break
+		}
+		s.metrics.ChainBlocks++
+		ghostdagData, err = ghostdagDataStore.Get(
+			s.observer.DatabaseContext(), stagingArea, ghostdagData.SelectedParent(), false)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, miner := range s.miners {
+		minerMetrics := s.metrics.Miners[i]
+		minerMetrics.HashShare = miner.config.HashShare / s.totalHashShare
+		minerMetrics.Unmerged = minerMetrics.Mined - minerMetrics.Rejected - minerMetrics.Blue - minerMetrics.Red
+		if totalBlue > 0 {
+			minerMetrics.RewardShare = float64(minerMetrics.Blue) / float64(totalBlue)
+		}
+	}
+
+	virtualInfo, err := s.observer.GetVirtualInfo()
+	if err != nil {
+		return err
+	}
+	endTime := s.now(s.config.Rounds)
+	s.metrics.PastMedianTimeDrift = time.Duration(virtualInfo.PastMedianTime-endTime) * time.Millisecond
+	genesisTarget := difficulty.CompactToBig(s.observer.DAGParams().GenesisBlock.Header.Bits())
+	virtualTarget := difficulty.CompactToBig(virtualInfo.Bits)
+	s.metrics.Difficulty, _ = new(big.Float).Quo(new(big.Float).SetInt(genesisTarget),
+		new(big.Float).SetInt(virtualTarget)).Float64()
+	return nil
+}
+
+// String returns the metrics as a table, for logs and reports
+func (m *Metrics) String() string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "%-16s %10s %6s %8s %6s %6s %8s %12s\n",
+		"Miner", "Hash share", "Mined", "Rejected", "Blue", "Red", "Unmerged", "Reward share")
+	for _, miner := range m.Miners {
+		fmt.Fprintf(builder, "%-16s %10.3f %6d %8d %6d %6d %8d %12.3f\n", miner.Name, miner.HashShare,
+			miner.Mined, miner.Rejected, miner.Blue, miner.Red, miner.Unmerged, miner.RewardShare)
+	}
+	fmt.Fprintf(builder, "Chain blocks: %d, reorgs: %d (max depth %d), past median time drift: %s, difficulty: %.3f\n",
+		m.ChainBlocks, m.Reorgs, m.MaxReorgDepth, m.PastMedianTimeDrift, m.Difficulty)
+	return builder.String()
+}
@@ -0,0 +1,315 @@
+// Package simulation deterministically simulates miners, some of them adversarial, that mine
+// on the same DAG, and measures how consensus responds to them.
+//
+// The simulation advances in rounds of one target block time each. In every round, one miner,
+// chosen at random by hash share from a seeded source, mines a block. The other miners see
+// the block PropagationDelay rounds after it's published. A builder consensus holds every
+// block that was mined, so that miners can build on the blocks they withhold, and an observer
+// consensus holds the published blocks, the way an honest node sees them.
+package simulation
+
+import (
+	"math/rand"
+
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/model/testapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/pkg/errors"
+)
+
+// Config is the configuration of a simulation
+type Config struct {
+	// ConsensusConfig is the configuration of the simulated consensus. Proof of work is
+	// never checked.
+	ConsensusConfig *consensus.Config
+	Miners          []*MinerConfig
+	Rounds          int
+	// PropagationDelay is the number of rounds it takes a published block to reach the
+	// other miners
+	PropagationDelay int
+	Seed             int64
+}
+
+// MinerConfig is the configuration of a simulated miner
+type MinerConfig struct {
+	Name string
+	// HashShare is the hash rate of the miner, relative to the other miners
+	HashShare float64
+	// Strategy decides how the miner builds and publishes its blocks. Strategies may keep
+	// state, so every miner needs its own instance.
+	Strategy Strategy
+}
+
+// blockInfo is what the simulation keeps of the blocks that the builder accepted. The miner
+// index of the genesis is -1.
+type blockInfo struct {
+	minerIndex   int
+	parents      []*externalapi.DomainHash
+	ghostdagData *externalapi.BlockGHOSTDAGData
+}
+
+type miner struct {
+	config       *MinerConfig
+	coinbaseData *externalapi.DomainCoinbaseData
+	view         *View
+	withheld     []*WithheldBlock
+	metrics      *MinerMetrics
+}
+
+type simulation struct {
+	config             *Config
+	random             *rand.Rand
+	builder            testapi.TestConsensus
+	observer           testapi.TestConsensus
+	miners             []*miner
+	totalHashShare     float64
+	blocks             map[externalapi.DomainHash]*blockInfo
+	deliveries         map[int][]*externalapi.DomainHash
+	genesisTime        int64
+	targetTimePerBlock int64
+	maxFutureTime      int64
+	metrics            *Metrics
+}
+
+// Run runs a simulation and returns its metrics. Runs with the same configuration, and with
+// strategies in the same state, return the same metrics.
+func Run(config *Config) (*Metrics, error) {
+	err := validateConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	consensusConfig := *config.ConsensusConfig
+	consensusConfig.SkipProofOfWork = true
+	factory := consensus.NewFactory()
+	builder, teardownBuilder, err := factory.NewTestConsensus(&consensusConfig, "simulation-builder")
+	if err != nil {
+		return nil, err
+	}
+	defer teardownBuilder(false)
+	observer, teardownObserver, err := factory.NewTestConsensus(&consensusConfig, "simulation-observer")
+	if err != nil {
+		return nil, err
+	}
+	defer teardownObserver(false)
+
+	targetTimePerBlock := consensusConfig.TargetTimePerBlock.Milliseconds()
+	s := &simulation{
+		config:             config,
+		random:             rand.New(rand.NewSource(config.Seed)),
+		builder:            builder,
+		observer:           observer,
+		blocks:             make(map[externalapi.DomainHash]*blockInfo),
+		deliveries:         make(map[int][]*externalapi.DomainHash),
+		genesisTime:        consensusConfig.GenesisBlock.Header.TimeInMilliseconds(),
+		targetTimePerBlock: targetTimePerBlock,
+		maxFutureTime:      int64(consensusConfig.TimestampDeviationTolerance) * targetTimePerBlock,
+		metrics:            &Metrics{},
+	}
+	err = s.addBlockInfo(consensusConfig.GenesisHash, -1, nil)
+	if err != nil {
+		return nil, err
+	}
+	scriptPublicKey, _ := testutils.OpTrueScript()
+	for i, minerConfig := range config.Miners {
+		minerMetrics := &MinerMetrics{Name: minerConfig.Name}
+		s.miners = append(s.miners, &miner{
+			config:       minerConfig,
+			coinbaseData: &externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey, ExtraData: []byte(minerConfig.Name)},
+			view:         newView(s, i, consensusConfig.GenesisHash),
+			metrics:      minerMetrics,
+		})
+		s.metrics.Miners = append(s.metrics.Miners, minerMetrics)
+		s.totalHashShare += minerConfig.HashShare
+	}
+
+	for round := 1; round <= config.Rounds; round++ {
+		err := s.runRound(round)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Every withheld block is eventually published
+	for _, miner := range s.miners {
+		err := s.publish(miner, len(miner.withheld), config.Rounds)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.collectMetrics()
+	if err != nil {
+		return nil, err
+	}
+	return s.metrics, nil
+}
+
+func validateConfig(config *Config) error {
+	if config.ConsensusConfig == nil {
+		return errors.New("the consensus configuration is missing")
+	}
+	if len(config.Miners) == 0 {
+		return errors.New("a simulation requires at least one miner")
+	}
+	if config.Rounds <= 0 {
+		return errors.Errorf("the number of rounds must be positive but is %d", config.Rounds)
+	}
+	if config.PropagationDelay < 0 {
+		return errors.Errorf("the propagation delay can't be negative but is %d", config.PropagationDelay)
+	}
+	for _, minerConfig := range config.Miners {
+		if minerConfig.HashShare <= 0 {
+			return errors.Errorf("the hash share of miner %s must be positive", minerConfig.Name)
+		}
+		if minerConfig.Strategy == nil {
+			return errors.Errorf("miner %s has no strategy", minerConfig.Name)
+		}
+	}
+	return nil
+}
+
+func (s *simulation) now(round int) int64 {
+	return s.genesisTime + int64(round)*s.targetTimePerBlock
+}
+
+func (s *simulation) runRound(round int) error {
+	for _, blockHash := range s.deliveries[round] {
+		s.deliver(blockHash)
+	}
+	delete(s.deliveries, round)
+
+	err := s.mine(s.chooseMiner(), round)
+	if err != nil {
+		return err
+	}
+
+	for _, miner := range s.miners {
+		count := miner.config.Strategy.Publish(miner.view, round, miner.withheld)
+		if count > len(miner.withheld) {
+			count = len(miner.withheld)
+		}
+		err := s.publish(miner, count, round)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *simulation) chooseMiner() *miner {
+	target := s.random.Float64() * s.totalHashShare
+	for _, miner := range s.miners {
+		target -= miner.config.HashShare
+		if target < 0 {
+			return miner
+		}
+	}
+	return s.miners[len(s.miners)-1]
+}
+
+func (s *simulation) mine(miner *miner, round int) error {
+	miner.metrics.Mined++
+
+	parents := miner.config.Strategy.Parents(miner.view)
+	block, _, err := s.builder.BuildBlockWithParents(parents, miner.coinbaseData, nil)
+	if err != nil {
+		return err
+	}
+
+	// The builder sets the earliest timestamp that the parents allow
+	now := s.now(round)
+	minimumTimestamp := block.Header.TimeInMilliseconds()
+	maximumTimestamp := now + s.maxFutureTime
+	timestamp := miner.config.Strategy.Timestamp(now, minimumTimestamp, maximumTimestamp)
+	if timestamp < minimumTimestamp || timestamp > maximumTimestamp {
+		miner.metrics.Rejected++
+		return nil
+	}
+	mutableHeader := block.Header.ToMutable()
+	mutableHeader.SetTimeInMilliseconds(timestamp)
+	block.Header = mutableHeader.ToImmutable()
+
+	err = s.builder.ValidateAndInsertBlock(block, true)
+	if err != nil {
+		if errors.As(err, &ruleerrors.RuleError{}) {
+			miner.metrics.Rejected++
+			return nil
+		}
+		return err
+	}
+
+	blockHash := consensushashing.BlockHash(block)
+	err = s.addBlockInfo(blockHash, miner.view.minerIndex, block.Header.DirectParents())
+	if err != nil {
+		return err
+	}
+	miner.view.add(blockHash)
+	miner.withheld = append(miner.withheld, &WithheldBlock{Hash: blockHash, Round: round})
+	return nil
+}
+
+func (s *simulation) addBlockInfo(blockHash *externalapi.DomainHash, minerIndex int,
+	parents []*externalapi.DomainHash) error {
+
+	ghostdagData, err := s.builder.GHOSTDAGDataStore().Get(
+		s.builder.DatabaseContext(), model.NewStagingArea(), blockHash, false)
+	if err != nil {
+		return err
+	}
+	s.blocks[*blockHash] = &blockInfo{
+		minerIndex:   minerIndex,
+		parents:      parents,
+		ghostdagData: ghostdagData,
+	}
+	return nil
+}
+
+// publish publishes the first count withheld blocks of the miner
+func (s *simulation) publish(miner *miner, count int, round int) error {
+	for _, withheldBlock := range miner.withheld[:count] {
+		previousSelectedParent, err := s.observer.GetVirtualSelectedParent()
+		if err != nil {
+			return err
+		}
+		block, _, err := s.builder.GetBlock(withheldBlock.Hash)
+		if err != nil {
+			return err
+		}
+		err = s.observer.ValidateAndInsertBlock(block, true)
+		if err != nil {
+			return errors.Wrapf(err, "the observer rejected block %s of miner %s", withheldBlock.Hash, miner.config.Name)
+		}
+		selectedChainChanges, err := s.observer.GetVirtualSelectedParentChainFromBlock(previousSelectedParent)
+		if err != nil {
+			return err
+		}
+		if len(selectedChainChanges.Removed) > 0 {
+			s.metrics.Reorgs++
+			if len(selectedChainChanges.Removed) > s.metrics.MaxReorgDepth {
+				s.metrics.MaxReorgDepth = len(selectedChainChanges.Removed)
+			}
+		}
+
+		if s.config.PropagationDelay == 0 {
+			s.deliver(withheldBlock.Hash)
+		} else {
+			deliveryRound := round + s.config.PropagationDelay
+			s.deliveries[deliveryRound] = append(s.deliveries[deliveryRound], withheldBlock.Hash)
+		}
+	}
+	miner.withheld = miner.withheld[count:]
+	return nil
+}
+
+// deliver adds a published block to the views of the miners that didn't mine it
+func (s *simulation) deliver(blockHash *externalapi.DomainHash) {
+	for _, miner := range s.miners {
+		if miner.view.minerIndex != s.blocks[*blockHash].minerIndex {
+			miner.view.add(blockHash)
+		}
+	}
+}
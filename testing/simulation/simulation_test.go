@@ -0,0 +1,133 @@
+package simulation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+)
+
+func TestHonestMiners(t *testing.T) {
+	newConfig := func() *Config {
+		return &Config{
+			ConsensusConfig: &consensus.Config{Params: dagconfig.DevnetParams},
+			Miners: []*MinerConfig{
+				{Name: "a", HashShare: 1, Strategy: Honest{}},
+				{Name: "b", HashShare: 2, Strategy: Honest{}},
+				{Name: "c", HashShare: 1, Strategy: Honest{}},
+			},
+			Rounds:           150,
+			PropagationDelay: 1,
+			Seed:             7,
+		}
+	}
+	metrics, err := Run(newConfig())
+	if err != nil {
+		t.Fatalf("Run: %+v", err)
+	}
+	t.Logf("\n%s", metrics)
+
+	totalMined := 0
+	for _, miner := range metrics.Miners {
+		totalMined += miner.Mined
+		if miner.Rejected != 0 || miner.Red != 0 || miner.Unmerged != 0 || miner.Blue != miner.Mined {
+			t.Fatalf("Expected every block of honest miner %s to be blue but got %+v", miner.Name, miner)
+		}
+	}
+	if totalMined != 150 {
+		t.Fatalf("Expected a block in every round but got %d blocks", totalMined)
+	}
+	if metrics.ChainBlocks == 0 || metrics.ChainBlocks > 150 {
+		t.Fatalf("Unexpected number of chain blocks %d", metrics.ChainBlocks)
+	}
+	if metrics.PastMedianTimeDrift > 0 {
+		t.Fatalf("Expected the past median time of honest miners to lag behind but got %s",
+			metrics.PastMedianTimeDrift)
+	}
+
+	// The same configuration gives the same metrics
+	secondMetrics, err := Run(newConfig())
+	if err != nil {
+		t.Fatalf("Run: %+v", err)
+	}
+	if !reflect.DeepEqual(metrics, secondMetrics) {
+		t.Fatalf("Expected a second run to give the same metrics but got:\n%s\n%s", metrics, secondMetrics)
+	}
+}
+
+func TestAdversarialMiners(t *testing.T) {
+	newConfig := func() *Config {
+		return &Config{
+			ConsensusConfig: &consensus.Config{Params: dagconfig.DevnetParams},
+			Miners: []*MinerConfig{
+				{Name: "honest", HashShare: 4, Strategy: Honest{}},
+				{Name: "withholding", HashShare: 1, Strategy: &Withholding{Rounds: 5}},
+				{Name: "selfish", HashShare: 3, Strategy: &SelfishMining{}},
+				{Name: "parent-omission", HashShare: 1, Strategy: ParentOmission{}},
+				{Name: "timestamp-gaming", HashShare: 1, Strategy: &TimestampGaming{Offset: time.Minute}},
+			},
+			Rounds:           200,
+			PropagationDelay: 2,
+			Seed:             11,
+		}
+	}
+	metrics, err := Run(newConfig())
+	if err != nil {
+		t.Fatalf("Run: %+v", err)
+	}
+	t.Logf("\n%s", metrics)
+
+	totalMined := 0
+	for _, miner := range metrics.Miners {
+		totalMined += miner.Mined
+		if miner.Mined == 0 {
+			t.Fatalf("Expected miner %s to mine", miner.Name)
+		}
+		if miner.Rejected != 0 || miner.Unmerged != 0 {
+			t.Fatalf("Expected every block of miner %s to be merged but got %+v", miner.Name, miner)
+		}
+	}
+	if totalMined != 200 {
+		t.Fatalf("Expected a block in every round but got %d blocks", totalMined)
+	}
+
+	secondMetrics, err := Run(newConfig())
+	if err != nil {
+		t.Fatalf("Run: %+v", err)
+	}
+	if !reflect.DeepEqual(metrics, secondMetrics) {
+		t.Fatalf("Expected a second run to give the same metrics but got:\n%s\n%s", metrics, secondMetrics)
+	}
+}
+
+func TestTimestampGaming(t *testing.T) {
+	metrics, err := Run(&Config{
+		ConsensusConfig: &consensus.Config{Params: dagconfig.DevnetParams},
+		Miners: []*MinerConfig{
+			{Name: "honest", HashShare: 1, Strategy: Honest{}},
+			{Name: "timestamp-gaming", HashShare: 3, Strategy: &TimestampGaming{Offset: time.Hour}},
+		},
+		Rounds:           100,
+		PropagationDelay: 1,
+		Seed:             3,
+	})
+	if err != nil {
+		t.Fatalf("Run: %+v", err)
+	}
+	t.Logf("\n%s", metrics)
+
+	// The timestamps of the majority are capped to the maximum that consensus allows, which
+	// pushes the past median time ahead of the simulated time
+	maximumDrift := time.Duration(dagconfig.DevnetParams.TimestampDeviationTolerance) * dagconfig.DevnetParams.TargetTimePerBlock
+	if metrics.PastMedianTimeDrift <= 0 || metrics.PastMedianTimeDrift > maximumDrift {
+		t.Fatalf("Expected the past median time to drift ahead by up to %s but got %s",
+			maximumDrift, metrics.PastMedianTimeDrift)
+	}
+	for _, miner := range metrics.Miners {
+		if miner.Rejected != 0 {
+			t.Fatalf("Expected no rejected blocks but got %+v", miner)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package simulation
+
+import (
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// Strategy decides how a simulated miner builds and publishes its blocks
+type Strategy interface {
+	// Parents returns the parents of the next block of the miner
+	Parents(view *View) []*externalapi.DomainHash
+
+	// Timestamp returns the timestamp of the next block of the miner, given the simulated
+	// time. Blocks with timestamps outside of the given bounds are rejected.
+	Timestamp(now int64, minimumTimestamp int64, maximumTimestamp int64) int64
+
+	// Publish is called at the end of every round, and returns how many of the withheld
+	// blocks of the miner, in the order they were mined, it publishes
+	Publish(view *View, round int, withheld []*WithheldBlock) int
+}
+
+// WithheldBlock is a block that its miner didn't publish yet
+type WithheldBlock struct {
+	Hash *externalapi.DomainHash
+	// Round is the round the block was mined in
+	Round int
+}
+
+// Honest builds on all the tips of its view, timestamps its blocks with the simulated time,
+// and publishes them right away
+type Honest struct{}
+
+// Parents implements Strategy
+func (Honest) Parents(view *View) []*externalapi.DomainHash {
+	tips := view.Tips()
+	if len(tips) > view.MaxBlockParents() {
+		return tips[:view.MaxBlockParents()]
+	}
+	return tips
+}
+
+// Timestamp implements Strategy
+func (Honest) Timestamp(now int64, minimumTimestamp int64, _ int64) int64 {
+	if now < minimumTimestamp {
+		return minimumTimestamp
+	}
+	return now
+}
+
+// Publish implements Strategy
+func (Honest) Publish(_ *View, _ int, withheld []*WithheldBlock) int {
+	return len(withheld)
+}
+
+// Withholding mines like an honest miner, but publishes every block only Rounds rounds after
+// it was mined
+type Withholding struct {
+	Honest
+	Rounds int
+}
+
+// Publish implements Strategy
+func (w *Withholding) Publish(_ *View, round int, withheld []*WithheldBlock) int {
+	count := 0
+	for count < len(withheld) && withheld[count].Round+w.Rounds <= round {
+		count++
+	}
+	return count
+}
+
+// SelfishMining mines on a private branch while it's ahead of the other miners, and publishes
+// it once they catch up, like the selfish mining attack on a chain. With a lead of a single
+// block it publishes the whole branch, and with a longer lead it publishes only the blocks
+// that the other miners matched.
+type SelfishMining struct {
+	Honest
+	othersBlueScore uint64
+}
+
+// Parents implements Strategy
+func (sm *SelfishMining) Parents(view *View) []*externalapi.DomainHash {
+	tips := view.Tips()
+	for _, tip := range tips {
+		if view.IsOwn(tip) && view.BlueScore(tip) >= view.OthersBlueScore() {
+			return []*externalapi.DomainHash{tip}
+		}
+	}
+	return sm.Honest.Parents(view)
+}
+
+// Publish implements Strategy
+func (sm *SelfishMining) Publish(view *View, _ int, withheld []*WithheldBlock) int {
+	// The private branch is only reconsidered once the other miners mine
+	if view.OthersBlueScore() == sm.othersBlueScore {
+		return 0
+	}
+	sm.othersBlueScore = view.OthersBlueScore()
+	if len(withheld) == 0 {
+		return 0
+	}
+
+	privateBlueScore := view.BlueScore(withheld[len(withheld)-1].Hash)
+	if privateBlueScore <= sm.othersBlueScore+1 {
+		return len(withheld)
+	}
+	count := 0
+	for count < len(withheld) && view.BlueScore(withheld[count].Hash) <= sm.othersBlueScore {
+		count++
+	}
+	return count
+}
+
+// ParentOmission builds only on the tip of its view with the most blue work, and so doesn't
+// merge the other tips
+type ParentOmission struct {
+	Honest
+}
+
+// Parents implements Strategy
+func (ParentOmission) Parents(view *View) []*externalapi.DomainHash {
+	return view.Tips()[:1]
+}
+
+// TimestampGaming mines like an honest miner, but offsets the timestamps of its blocks from
+// the simulated time by Offset, as far as they remain valid
+type TimestampGaming struct {
+	Honest
+	Offset time.Duration
+}
+
+// Timestamp implements Strategy
+func (tg *TimestampGaming) Timestamp(now int64, minimumTimestamp int64, maximumTimestamp int64) int64 {
+	timestamp := now + tg.Offset.Milliseconds()
+	if timestamp < minimumTimestamp {
+		return minimumTimestamp
+	}
+	if timestamp > maximumTimestamp {
+		return maximumTimestamp
+	}
+	return timestamp
+}
@@ -0,0 +1,74 @@
+package simulation
+
+import (
+	"sort"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// View is the part of the DAG that a simulated miner knows of: the blocks it mined, and the
+// blocks of other miners that reached it
+type View struct {
+	simulation      *simulation
+	minerIndex      int
+	tips            map[externalapi.DomainHash]struct{}
+	othersBlueScore uint64
+}
+
+func newView(simulation *simulation, minerIndex int, genesisHash *externalapi.DomainHash) *View {
+	return &View{
+		simulation: simulation,
+		minerIndex: minerIndex,
+		tips:       map[externalapi.DomainHash]struct{}{*genesisHash: {}},
+	}
+}
+
+func (v *View) add(blockHash *externalapi.DomainHash) {
+	block := v.simulation.blocks[*blockHash]
+	for _, parent := range block.parents {
+		delete(v.tips, *parent)
+	}
+	v.tips[*blockHash] = struct{}{}
+	if block.minerIndex != v.minerIndex && block.ghostdagData.BlueScore() > v.othersBlueScore {
+		v.othersBlueScore = block.ghostdagData.BlueScore()
+	}
+}
+
+// Tips returns the blocks of the view that no other block of the view points to, from the
+// one with the most blue work
+func (v *View) Tips() []*externalapi.DomainHash {
+	tips := make([]*externalapi.DomainHash, 0, len(v.tips))
+	for tip := range v.tips {
+		tip := tip
+		tips = append(tips, &tip)
+	}
+	sort.Slice(tips, func(i, j int) bool {
+		blueWorkI := v.simulation.blocks[*tips[i]].ghostdagData.BlueWork()
+		blueWorkJ := v.simulation.blocks[*tips[j]].ghostdagData.BlueWork()
+		if comparison := blueWorkI.Cmp(blueWorkJ); comparison != 0 {
+			return comparison > 0
+		}
+		return tips[i].Less(tips[j])
+	})
+	return tips
+}
+
+// MaxBlockParents returns the maximum number of parents a block may have
+func (v *View) MaxBlockParents() int {
+	return int(v.simulation.builder.DAGParams().MaxBlockParents)
+}
+
+// BlueScore returns the blue score of the given block of the view
+func (v *View) BlueScore(blockHash *externalapi.DomainHash) uint64 {
+	return v.simulation.blocks[*blockHash].ghostdagData.BlueScore()
+}
+
+// IsOwn returns whether the miner mined the given block of the view
+func (v *View) IsOwn(blockHash *externalapi.DomainHash) bool {
+	return v.simulation.blocks[*blockHash].minerIndex == v.minerIndex
+}
+
+// OthersBlueScore returns the highest blue score of the blocks of other miners in the view
+func (v *View) OthersBlueScore() uint64 {
+	return v.othersBlueScore
+}
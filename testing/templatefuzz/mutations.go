@@ -0,0 +1,380 @@
+package templatefuzz
+
+import (
+	"sort"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/pkg/errors"
+)
+
+// mutation changes the mempool, the DAG or both
+type mutation func(f *fuzzer) error
+
+// mutations are chosen uniformly, so a mutation that appears more than once is chosen
+// more often
+var mutations = []mutation{
+	(*fuzzer).submitTransaction,
+	(*fuzzer).submitTransaction,
+	(*fuzzer).submitTransaction,
+	(*fuzzer).submitTransaction,
+	(*fuzzer).submitOrphan,
+	(*fuzzer).submitPackage,
+	(*fuzzer).submitDoubleSpend,
+	(*fuzzer).addCompetingBlock,
+	(*fuzzer).addSideChain,
+}
+
+// fees are the fees that transactions pay. The lower ones are below the minimum relay fee
+// of the default mempool configuration.
+var fees = []uint64{0, 100, 10_000, 100_000}
+
+const (
+	maxFee = 100_000
+	// minOutputValue is the value of the smallest output, well above the dust threshold
+	minOutputValue = 100_000
+	maxInputs      = 3
+	maxOutputs     = 4
+	// maxConfirmedOutputs is the number of outputs of the virtual UTXO set that transactions
+	// may spend
+	maxConfirmedOutputs = 1000
+	maxSideChainLength  = 3
+	// maxCompetingBlockTransactions is the number of mempool transactions that a block of
+	// another miner includes
+	maxCompetingBlockTransactions = 3
+)
+
+func (f *fuzzer) mutate() error {
+	return mutations[f.random.Intn(len(mutations))](f)
+}
+
+type spendableOutput struct {
+	outpoint    externalapi.DomainOutpoint
+	value       uint64
+	isConfirmed bool
+}
+
+// spendableOutputs returns the outputs of the virtual UTXO set and of the mempool that no
+// mempool transaction spends and that are worth spending, in a deterministic order
+func (f *fuzzer) spendableOutputs() ([]*spendableOutput, error) {
+	poolTransactions, orphanTransactions := f.miningManager.AllTransactions(true, true)
+	spentOutpoints := make(map[externalapi.DomainOutpoint]struct{})
+	for _, transaction := range append(poolTransactions, orphanTransactions...) {
+		for _, input := range transaction.Inputs {
+			spentOutpoints[input.PreviousOutpoint] = struct{}{}
+		}
+	}
+
+	var outputs []*spendableOutput
+	addOutput := func(outpoint externalapi.DomainOutpoint, value uint64, isConfirmed bool) {
+		if _, ok := spentOutpoints[outpoint]; ok || value < maxFee+minOutputValue {
+			return
+		}
+		outputs = append(outputs, &spendableOutput{outpoint: outpoint, value: value, isConfirmed: isConfirmed})
+	}
+
+	virtualInfo, err := f.tc.GetVirtualInfo()
+	if err != nil {
+		return nil, err
+	}
+	utxos, err := f.tc.GetVirtualUTXOs(virtualInfo.ParentHashes, nil, maxConfirmedOutputs)
+	if err != nil {
+		return nil, err
+	}
+	for _, utxo := range utxos {
+		addOutput(*utxo.Outpoint, utxo.UTXOEntry.Amount(), true)
+	}
+	for _, transaction := range poolTransactions {
+		transactionID := consensushashing.TransactionID(transaction)
+		for i, output := range transaction.Outputs {
+			addOutput(externalapi.DomainOutpoint{TransactionID: *transactionID, Index: uint32(i)}, output.Value, false)
+		}
+	}
+
+	sort.Slice(outputs, func(i, j int) bool {
+		if !outputs[i].outpoint.TransactionID.Equal(&outputs[j].outpoint.TransactionID) {
+			return outputs[i].outpoint.TransactionID.Less(&outputs[j].outpoint.TransactionID)
+		}
+		return outputs[i].outpoint.Index < outputs[j].outpoint.Index
+	})
+	return outputs, nil
+}
+
+// pickOutputs picks up to count distinct random outputs, only confirmed ones if onlyConfirmed
+// is set
+func (f *fuzzer) pickOutputs(count int, onlyConfirmed bool) ([]*spendableOutput, error) {
+	outputs, err := f.spendableOutputs()
+	if err != nil {
+		return nil, err
+	}
+	if onlyConfirmed {
+		confirmedOutputs := outputs[:0]
+		for _, output := range outputs {
+			if output.isConfirmed {
+				confirmedOutputs = append(confirmedOutputs, output)
+			}
+		}
+		outputs = confirmedOutputs
+	}
+	f.random.Shuffle(len(outputs), func(i, j int) {
+		outputs[i], outputs[j] = outputs[j], outputs[i]
+	})
+	if len(outputs) > count {
+		outputs = outputs[:count]
+	}
+	return outputs, nil
+}
+
+func (f *fuzzer) randomFee() uint64 {
+	return fees[f.random.Intn(len(fees))]
+}
+
+// newTransaction returns a transaction that spends the given outpoints, whose values add up
+// to inputsValue, and pays the given fee. The rest of the value is split among a random
+// number of outputs.
+func (f *fuzzer) newTransaction(outpoints []externalapi.DomainOutpoint, inputsValue uint64,
+	fee uint64) *externalapi.DomainTransaction {
+
+	inputs := make([]*externalapi.DomainTransactionInput, len(outpoints))
+	for i, outpoint := range outpoints {
+		inputs[i] = &externalapi.DomainTransactionInput{
+			PreviousOutpoint: outpoint,
+			SignatureScript:  f.signatureScript,
+			Sequence:         constants.MaxTxInSequenceNum,
+		}
+	}
+
+	value := inputsValue - fee
+	outputCount := 1 + f.random.Intn(maxOutputs)
+	if maxOutputCount := value / minOutputValue; uint64(outputCount) > maxOutputCount {
+		outputCount = int(maxOutputCount)
+	}
+	outputs := make([]*externalapi.DomainTransactionOutput, outputCount)
+	for i := range outputs {
+		outputValue := value / uint64(outputCount)
+		if i == outputCount-1 {
+			outputValue = value - outputValue*uint64(outputCount-1)
+		}
+		outputs[i] = &externalapi.DomainTransactionOutput{
+			ScriptPublicKey: f.scriptPublicKey,
+			Value:           outputValue,
+		}
+	}
+
+	return &externalapi.DomainTransaction{
+		Version: constants.MaxTransactionVersion,
+		Inputs:  inputs,
+		Outputs: outputs,
+		Payload: []byte{},
+	}
+}
+
+// spend returns a transaction that spends the given outputs and pays the given fee
+func (f *fuzzer) spend(outputs []*spendableOutput, fee uint64) *externalapi.DomainTransaction {
+	outpoints := make([]externalapi.DomainOutpoint, len(outputs))
+	inputsValue := uint64(0)
+	for i, output := range outputs {
+		outpoints[i] = output.outpoint
+		inputsValue += output.value
+	}
+	return f.newTransaction(outpoints, inputsValue, fee)
+}
+
+// spendTransaction returns a transaction that spends all the outputs of the given one and
+// pays the given fee
+func (f *fuzzer) spendTransaction(transaction *externalapi.DomainTransaction, fee uint64) *externalapi.DomainTransaction {
+	transactionID := consensushashing.TransactionID(transaction)
+	outpoints := make([]externalapi.DomainOutpoint, len(transaction.Outputs))
+	inputsValue := uint64(0)
+	for i, output := range transaction.Outputs {
+		outpoints[i] = externalapi.DomainOutpoint{TransactionID: *transactionID, Index: uint32(i)}
+		inputsValue += output.Value
+	}
+	return f.newTransaction(outpoints, inputsValue, fee)
+}
+
+// countSubmission counts a transaction submitted to the mempool. Rejections by the mempool
+// are expected, since mutations don't follow its policy.
+func (f *fuzzer) countSubmission(transactionCount int, acceptedTransactions []*externalapi.DomainTransaction,
+	err error) error {
+
+	f.report.SubmittedTransactions += transactionCount
+	if err != nil {
+		if !errors.As(err, &mempool.RuleError{}) {
+			return err
+		}
+		f.report.RejectedTransactions += transactionCount
+		return nil
+	}
+	f.report.AcceptedTransactions += len(acceptedTransactions)
+	return nil
+}
+
+// submitTransaction submits a transaction that spends confirmed outputs, outputs of mempool
+// transactions or both
+func (f *fuzzer) submitTransaction() error {
+	outputs, err := f.pickOutputs(1+f.random.Intn(maxInputs), false)
+	if err != nil || len(outputs) == 0 {
+		return err
+	}
+	transaction := f.spend(outputs, f.randomFee())
+	acceptedTransactions, err := f.miningManager.ValidateAndInsertTransaction(transaction, false, true)
+	return f.countSubmission(1, acceptedTransactions, err)
+}
+
+// submitOrphan submits a transaction before the transaction it spends, so that it's an
+// orphan until its parent is submitted
+func (f *fuzzer) submitOrphan() error {
+	outputs, err := f.pickOutputs(1+f.random.Intn(maxInputs), true)
+	if err != nil || len(outputs) == 0 {
+		return err
+	}
+	parent := f.spend(outputs, maxFee)
+	child := f.spendTransaction(parent, f.randomFee())
+	f.report.Orphans++
+
+	acceptedTransactions, err := f.miningManager.ValidateAndInsertTransaction(child, false, true)
+	err = f.countSubmission(1, acceptedTransactions, err)
+	if err != nil {
+		return err
+	}
+	acceptedTransactions, err = f.miningManager.ValidateAndInsertTransaction(parent, false, true)
+	return f.countSubmission(1, acceptedTransactions, err)
+}
+
+// submitPackage submits a parent that pays no fee along with a child that pays for both
+func (f *fuzzer) submitPackage() error {
+	outputs, err := f.pickOutputs(1+f.random.Intn(maxInputs), true)
+	if err != nil || len(outputs) == 0 {
+		return err
+	}
+	parent := f.spend(outputs, 0)
+	child := f.spendTransaction(parent, maxFee)
+	f.report.Packages++
+
+	acceptedTransactions, err := f.miningManager.ValidateAndInsertPackage(
+		[]*externalapi.DomainTransaction{parent, child}, false)
+	return f.countSubmission(2, acceptedTransactions, err)
+}
+
+// submitDoubleSpend submits a transaction that spends an outpoint that a mempool transaction
+// already spends
+func (f *fuzzer) submitDoubleSpend() error {
+	poolTransactions := f.poolTransactions()
+	if len(poolTransactions) == 0 {
+		return nil
+	}
+	conflictingTransaction := f.conflictingTransaction(poolTransactions[f.random.Intn(len(poolTransactions))])
+	f.report.DoubleSpends++
+	acceptedTransactions, err := f.miningManager.ValidateAndInsertTransaction(conflictingTransaction, false, true)
+	return f.countSubmission(1, acceptedTransactions, err)
+}
+
+// poolTransactions returns the transactions of the transaction pool, in a deterministic order
+func (f *fuzzer) poolTransactions() []*externalapi.DomainTransaction {
+	poolTransactions, _ := f.miningManager.AllTransactions(true, false)
+	sort.Slice(poolTransactions, func(i, j int) bool {
+		return consensushashing.TransactionID(poolTransactions[i]).Less(consensushashing.TransactionID(poolTransactions[j]))
+	})
+	return poolTransactions
+}
+
+// conflictingTransaction returns a transaction that spends the first outpoint that the given
+// mempool transaction spends, with another fee
+func (f *fuzzer) conflictingTransaction(transaction *externalapi.DomainTransaction) *externalapi.DomainTransaction {
+	input := transaction.Inputs[0]
+	return f.newTransaction([]externalapi.DomainOutpoint{input.PreviousOutpoint}, input.UTXOEntry.Amount(), f.randomFee())
+}
+
+// addCompetingBlock adds a block of another miner on the virtual. The block includes some
+// mempool transactions, and may include a transaction that conflicts with another one.
+func (f *fuzzer) addCompetingBlock() error {
+	poolTransactions := f.poolTransactions()
+	var candidates []*externalapi.DomainTransaction
+	for _, transaction := range poolTransactions {
+		if f.spendsOnlyConfirmedOutputs(transaction, poolTransactions) {
+			candidates = append(candidates, transaction)
+		}
+	}
+	f.random.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	var transactions []*externalapi.DomainTransaction
+	if len(candidates) > 0 && f.random.Intn(2) == 0 {
+		transactions = append(transactions, f.conflictingTransaction(candidates[0]))
+		candidates = candidates[1:]
+	}
+	for _, candidate := range candidates[:f.random.Intn(min(len(candidates), maxCompetingBlockTransactions)+1)] {
+		transactions = append(transactions, candidate.Clone())
+	}
+
+	virtualInfo, err := f.tc.GetVirtualInfo()
+	if err != nil {
+		return err
+	}
+	block, _, err := f.tc.BuildBlockWithParents(virtualInfo.ParentHashes, f.coinbaseData[0], transactions)
+	if err != nil {
+		return err
+	}
+	err = f.tc.ValidateAndInsertBlock(block, true)
+	if err != nil {
+		return errors.Wrap(err, "a block of another miner was rejected")
+	}
+	f.report.CompetingBlocks++
+	return f.onBlockAdded(block, consensushashing.BlockHash(block))
+}
+
+// spendsOnlyConfirmedOutputs returns whether a mempool transaction spends no output of the
+// given other mempool transactions
+func (f *fuzzer) spendsOnlyConfirmedOutputs(transaction *externalapi.DomainTransaction,
+	poolTransactions []*externalapi.DomainTransaction) bool {
+
+	for _, input := range transaction.Inputs {
+		for _, poolTransaction := range poolTransactions {
+			if input.PreviousOutpoint.TransactionID.Equal(consensushashing.TransactionID(poolTransaction)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// addSideChain adds a chain of blocks that forks from one of the recently added blocks. The
+// side chain reorgs the selected chain if it's heavier.
+func (f *fuzzer) addSideChain() error {
+	previousSelectedParent, err := f.tc.GetVirtualSelectedParent()
+	if err != nil {
+		return err
+	}
+
+	parentHash := f.recentBlocks[f.random.Intn(len(f.recentBlocks))]
+	length := 1 + f.random.Intn(maxSideChainLength)
+	for i := 0; i < length; i++ {
+		blockHash, _, err := f.tc.AddBlock([]*externalapi.DomainHash{parentHash}, f.coinbaseData[0], nil)
+		if err != nil {
+			return errors.Wrap(err, "a side chain block was rejected")
+		}
+		block, _, err := f.tc.GetBlock(blockHash)
+		if err != nil {
+			return err
+		}
+		f.report.SideChainBlocks++
+		err = f.onBlockAdded(block, blockHash)
+		if err != nil {
+			return err
+		}
+		parentHash = blockHash
+	}
+
+	selectedChainChanges, err := f.tc.GetVirtualSelectedParentChainFromBlock(previousSelectedParent)
+	if err != nil {
+		return err
+	}
+	if len(selectedChainChanges.Removed) > 0 {
+		f.report.Reorgs++
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package templatefuzz
+
+import "fmt"
+
+// Report counts what a fuzzer run did
+type Report struct {
+	// Templates is the number of templates that were built, and TemplateTransactions is the
+	// number of mempool transactions they included
+	Templates            int
+	TemplateTransactions int
+	// StaleTemplates is the number of templates that were submitted only after the mutations
+	// of the next round
+	StaleTemplates int
+
+	// SubmittedTransactions is the number of transactions submitted to the mempool.
+	// AcceptedTransactions includes the orphans that the mempool accepted once their parents
+	// were submitted.
+	SubmittedTransactions int
+	AcceptedTransactions  int
+	RejectedTransactions  int
+	Orphans               int
+	Packages              int
+	DoubleSpends          int
+
+	// CompetingBlocks is the number of blocks of other miners that were added on the virtual,
+	// and SideChainBlocks is the number of blocks of side chains. Reorgs is the number of side
+	// chains that removed blocks from the selected chain.
+	CompetingBlocks int
+	SideChainBlocks int
+	Reorgs          int
+}
+
+func (r *Report) String() string {
+	return fmt.Sprintf("Templates: %d (%d stale) with %d transactions\n"+
+		"Transactions: %d submitted, %d accepted, %d rejected (%d orphans, %d packages, %d double spends)\n"+
+		"Blocks of other miners: %d, side chain blocks: %d, reorgs: %d\n",
+		r.Templates, r.StaleTemplates, r.TemplateTransactions,
+		r.SubmittedTransactions, r.AcceptedTransactions, r.RejectedTransactions, r.Orphans, r.Packages, r.DoubleSpends,
+		r.CompetingBlocks, r.SideChainBlocks, r.Reorgs)
+}
@@ -0,0 +1,245 @@
+// Package templatefuzz stresses the block template builder with a mempool that keeps changing,
+// and checks that every template it produces connects to the DAG.
+//
+// A block template is built from the mempool, which follows its own policy, and then validated
+// by consensus, which follows the consensus rules. When the two diverge, the miners of the
+// node mine blocks that the DAG rejects or disqualifies from the chain, which is only noticed
+// as orphaned blocks in production. The fuzzer runs a test consensus and a mining manager on
+// it, and in every round applies random mutations to the mempool and to the DAG: transactions,
+// chains of transactions, orphans, packages, double spends, blocks of other miners that
+// include or conflict with mempool transactions, and side chains that reorg the selected
+// chain. It then builds a template, as a miner would, and submits it. Some templates are held
+// until after the mutations of the next round, the way a miner submits a template that went
+// stale while it was being mined.
+package templatefuzz
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/model/testapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/consensusreference"
+	"github.com/kaspanet/kaspad/domain/miningmanager"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/pkg/errors"
+)
+
+// Config is the configuration of a fuzzer run
+type Config struct {
+	// ConsensusConfig is the configuration of the consensus. Proof of work is never checked,
+	// and coinbase outputs are spendable right away.
+	ConsensusConfig *consensus.Config
+	// MempoolConfig is the configuration of the mempool. The default one is used if it's nil.
+	MempoolConfig *mempool.Config
+	Rounds        int
+	// MaxMutationsPerRound is the most mutations applied between two templates
+	MaxMutationsPerRound int
+	// Seed determines the mutations. Block timestamps are taken from the clock, so runs with
+	// the same seed apply the same mutations but don't build the same blocks.
+	Seed int64
+}
+
+// TemplateError is returned when a template doesn't connect to the DAG
+type TemplateError struct {
+	Round     int
+	Seed      int64
+	BlockHash *externalapi.DomainHash
+	Err       error
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("the template %s of round %d (seed %d) doesn't connect: %s",
+		e.BlockHash, e.Round, e.Seed, e.Err)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// numberOfMiners is the number of coinbase data that templates are requested with. Every
+// round requests a template with a random one, so that cached templates are modified
+// for another miner as well as built from scratch.
+const numberOfMiners = 3
+
+type fuzzer struct {
+	config        *Config
+	random        *rand.Rand
+	tc            testapi.TestConsensus
+	miningManager miningmanager.MiningManager
+	report        *Report
+
+	coinbaseData      []*externalapi.DomainCoinbaseData
+	scriptPublicKey   *externalapi.ScriptPublicKey
+	signatureScript   []byte
+	recentBlocks      []*externalapi.DomainHash
+	heldTemplate      *externalapi.DomainBlock
+	heldTemplateRound int
+}
+
+// maxRecentBlocks is the number of the last added blocks that side chains may fork from
+const maxRecentBlocks = 10
+
+// Run runs the fuzzer. It returns a *TemplateError if a template doesn't connect, along with
+// the report of the rounds until then.
+func Run(config *Config) (*Report, error) {
+	err := validateConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	consensusConfig := *config.ConsensusConfig
+	consensusConfig.SkipProofOfWork = true
+	consensusConfig.BlockCoinbaseMaturity = 0
+	factory := consensus.NewFactory()
+	tc, teardown, err := factory.NewTestConsensus(&consensusConfig, "templatefuzz")
+	if err != nil {
+		return nil, err
+	}
+	defer teardown(false)
+
+	mempoolConfig := config.MempoolConfig
+	if mempoolConfig == nil {
+		mempoolConfig = mempool.DefaultConfig(&consensusConfig.Params)
+	}
+	tcAsConsensus := tc.(externalapi.Consensus)
+	tcAsConsensusPointer := &tcAsConsensus
+	consensusReference := consensusreference.NewConsensusReference(&tcAsConsensusPointer)
+	miningManager := miningmanager.NewFactory().NewMiningManager(consensusReference, &consensusConfig.Params, mempoolConfig)
+
+	scriptPublicKey, redeemScript := testutils.OpTrueScript()
+	signatureScript, err := txscript.PayToScriptHashSignatureScript(redeemScript, nil)
+	if err != nil {
+		return nil, err
+	}
+	f := &fuzzer{
+		config:          config,
+		random:          rand.New(rand.NewSource(config.Seed)),
+		tc:              tc,
+		miningManager:   miningManager,
+		report:          &Report{},
+		scriptPublicKey: scriptPublicKey,
+		signatureScript: signatureScript,
+		recentBlocks:    []*externalapi.DomainHash{consensusConfig.GenesisHash},
+	}
+	for i := 0; i < numberOfMiners; i++ {
+		f.coinbaseData = append(f.coinbaseData, &externalapi.DomainCoinbaseData{
+			ScriptPublicKey: scriptPublicKey,
+			ExtraData:       []byte{byte(i)},
+		})
+	}
+
+	for round := 1; round <= config.Rounds; round++ {
+		err := f.runRound(round)
+		if err != nil {
+			return f.report, err
+		}
+	}
+	return f.report, nil
+}
+
+func validateConfig(config *Config) error {
+	if config.ConsensusConfig == nil {
+		return errors.New("the consensus configuration is missing")
+	}
+	if config.Rounds <= 0 {
+		return errors.Errorf("the number of rounds must be positive but is %d", config.Rounds)
+	}
+	if config.MaxMutationsPerRound < 0 {
+		return errors.Errorf("the number of mutations per round can't be negative but is %d",
+			config.MaxMutationsPerRound)
+	}
+	return nil
+}
+
+func (f *fuzzer) runRound(round int) error {
+	mutationCount := 0
+	if f.config.MaxMutationsPerRound > 0 {
+		mutationCount = f.random.Intn(f.config.MaxMutationsPerRound + 1)
+	}
+	for i := 0; i < mutationCount; i++ {
+		err := f.mutate()
+		if err != nil {
+			return err
+		}
+	}
+
+	if f.heldTemplate != nil {
+		heldTemplate := f.heldTemplate
+		f.heldTemplate = nil
+		f.report.StaleTemplates++
+		err := f.submitTemplate(heldTemplate, f.heldTemplateRound, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	coinbaseData := f.coinbaseData[f.random.Intn(len(f.coinbaseData))]
+	template, _, err := f.miningManager.GetBlockTemplate(coinbaseData)
+	if err != nil {
+		return err
+	}
+	f.report.Templates++
+	f.report.TemplateTransactions += len(template.Transactions) - 1
+
+	// A quarter of the templates are submitted only after the next round's mutations
+	if f.random.Intn(4) == 0 {
+		f.heldTemplate = template
+		f.heldTemplateRound = round
+		return nil
+	}
+	return f.submitTemplate(template, round, false)
+}
+
+// submitTemplate adds a template to the DAG the way the node adds a block its miner submits
+// over RPC, and checks that it connects. A stale template is built on tips that are no
+// longer the tips, so it may not be in the selected chain, but it must not be disqualified
+// from it.
+func (f *fuzzer) submitTemplate(template *externalapi.DomainBlock, round int, isStale bool) error {
+	blockHash := consensushashing.BlockHash(template)
+	templateError := func(err error) error {
+		return &TemplateError{Round: round, Seed: f.config.Seed, BlockHash: blockHash, Err: err}
+	}
+
+	block, err := appmessage.RPCBlockToDomainBlock(appmessage.DomainBlockToRPCBlock(template))
+	if err != nil {
+		return templateError(err)
+	}
+	err = f.tc.ValidateAndInsertBlock(block, true)
+	if err != nil {
+		return templateError(err)
+	}
+	blockInfo, err := f.tc.GetBlockInfo(blockHash)
+	if err != nil {
+		return err
+	}
+	switch {
+	case blockInfo.BlockStatus == externalapi.StatusUTXOValid:
+	case isStale && blockInfo.BlockStatus == externalapi.StatusUTXOPendingVerification:
+	default:
+		return templateError(errors.Errorf("the template has status %s", blockInfo.BlockStatus))
+	}
+
+	return f.onBlockAdded(block, blockHash)
+}
+
+// onBlockAdded updates the mempool and the template cache the way the node does for every
+// block added to the DAG
+func (f *fuzzer) onBlockAdded(block *externalapi.DomainBlock, blockHash *externalapi.DomainHash) error {
+	_, err := f.miningManager.HandleNewBlockTransactions(block.Transactions)
+	if err != nil {
+		return err
+	}
+	f.miningManager.ClearBlockTemplate()
+
+	f.recentBlocks = append(f.recentBlocks, blockHash)
+	if len(f.recentBlocks) > maxRecentBlocks {
+		f.recentBlocks = f.recentBlocks[1:]
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package templatefuzz
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+)
+
+// The fuzzer is run longer, or with other seeds, with for example:
+// go test ./testing/templatefuzz -run TestTemplateFuzz -rounds=5000 -seed=42
+var (
+	rounds = flag.Int("rounds", 100, "the number of fuzzer rounds")
+	seed   = flag.Int64("seed", 1, "the fuzzer seed")
+)
+
+func TestTemplateFuzz(t *testing.T) {
+	report, err := Run(&Config{
+		ConsensusConfig:      &consensus.Config{Params: dagconfig.DevnetParams},
+		Rounds:               *rounds,
+		MaxMutationsPerRound: 8,
+		Seed:                 *seed,
+	})
+	if err != nil {
+		t.Fatalf("Run: %+v", err)
+	}
+	t.Logf("\n%s", report)
+
+	if report.Templates != *rounds {
+		t.Fatalf("Expected a template in every round but got %d templates", report.Templates)
+	}
+	// Make sure the mutations exercised the template builder
+	if report.TemplateTransactions == 0 || report.AcceptedTransactions == 0 || report.RejectedTransactions == 0 ||
+		report.CompetingBlocks == 0 || report.SideChainBlocks == 0 || report.StaleTemplates == 0 {
+		t.Fatalf("Expected every kind of mutation to have an effect but got:\n%s", report)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	_, err := Run(&Config{ConsensusConfig: &consensus.Config{Params: dagconfig.DevnetParams}})
+	if err == nil {
+		t.Fatalf("Expected a run without rounds to fail")
+	}
+}
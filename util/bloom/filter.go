@@ -0,0 +1,330 @@
+// Copyright (c) 2014-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bloom
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+)
+
+// ln2Squared is simply the square of the natural log of 2.
+const ln2Squared = math.Ln2 * math.Ln2
+
+// minUint32 is a convenience function to return the minimum value of the two
+// passed uint32 values.
+func minUint32(a, b uint32) uint32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Filter defines a kaspa bloom filter that provides easy manipulation of raw
+// filter data.
+type Filter struct {
+	mtx           sync.Mutex
+	msgFilterLoad *appmessage.MsgFilterLoad
+}
+
+// NewFilter creates a new bloom filter instance, mainly to be used by SPV
+// clients. The tweak parameter is a random value added to the seed value.
+// The false positive rate is the probability of a false positive where 1.0 is
+// "match everything" and zero is unachievable. Thus, providing any false
+// positive rates less than 0 or greater than 1 will be adjusted to the valid
+// range.
+func NewFilter(elements, tweak uint32, fprate float64, flags appmessage.BloomUpdateType) *Filter {
+	// Massage the false positive rate to sane values.
+	if fprate > 1.0 {
+		fprate = 1.0
+	}
+	if fprate < 1e-9 {
+		fprate = 1e-9
+	}
+
+	// Calculate the size of the filter in bytes for the given number of
+	// elements and false positive rate.
+	//
+	// Equivalent to m = -(n*ln(p) / ln(2)^2), where m is in bits.
+	// Then clamp it to the maximum filter size and convert to bytes.
+	dataLen := uint32(-1 * float64(elements) * math.Log(fprate) / ln2Squared)
+	dataLen = minUint32(dataLen, appmessage.MaxFilterLoadFilterSize*8) / 8
+
+	// Calculate the number of hash functions based on the size of the
+	// filter calculated above and the number of elements.
+	//
+	// Equivalent to k = (m/n) * ln(2)
+	// Then clamp it to the maximum allowed hash funcs.
+	hashFuncs := uint32(float64(dataLen*8) / float64(elements) * math.Ln2)
+	hashFuncs = minUint32(hashFuncs, appmessage.MaxFilterLoadHashFuncs)
+
+	data := make([]byte, dataLen)
+	msg := appmessage.NewMsgFilterLoad(data, hashFuncs, tweak, flags)
+
+	return &Filter{
+		msgFilterLoad: msg,
+	}
+}
+
+// LoadFilter creates a new Filter instance with the given underlying
+// appmessage.MsgFilterLoad.
+func LoadFilter(filter *appmessage.MsgFilterLoad) *Filter {
+	return &Filter{
+		msgFilterLoad: filter,
+	}
+}
+
+// IsLoaded returns true if a filter is loaded, otherwise false.
+//
+// This function is safe for concurrent access.
+func (bf *Filter) IsLoaded() bool {
+	bf.mtx.Lock()
+	defer bf.mtx.Unlock()
+
+	return bf.msgFilterLoad != nil
+}
+
+// Reload loads a new filter replacing any existing filter.
+//
+// This function is safe for concurrent access.
+func (bf *Filter) Reload(filter *appmessage.MsgFilterLoad) {
+	bf.mtx.Lock()
+	defer bf.mtx.Unlock()
+
+	bf.msgFilterLoad = filter
+}
+
+// Unload unloads the bloom filter.
+//
+// This function is safe for concurrent access.
+func (bf *Filter) Unload() {
+	bf.mtx.Lock()
+	defer bf.mtx.Unlock()
+
+	bf.msgFilterLoad = nil
+}
+
+// hash returns the bit offset in the bloom filter which corresponds to the
+// passed data for the given independent hash function number.
+func (bf *Filter) hash(hashNum uint32, data []byte) uint32 {
+	// 0xfba4c795 is chosen as it guarantees a reasonable bit
+	// difference between hashNum values.
+	//
+	// Note that << 3 is equivalent to multiplying by 8, but is faster.
+	// Thus the returned hash is brought into range of the number of bits
+	// the filter has and returned.
+	mm := MurmurHash3(hashNum*0xfba4c795+bf.msgFilterLoad.Tweak, data)
+	return mm % (uint32(len(bf.msgFilterLoad.Filter)) << 3)
+}
+
+// matches returns true if the bloom filter might contain the passed data and
+// false if it definitely does not.
+//
+// This function MUST be called with the filter lock held.
+func (bf *Filter) matches(data []byte) bool {
+	if bf.msgFilterLoad == nil {
+		return false
+	}
+	// An empty filter matches everything, which also avoids dividing by zero
+	if len(bf.msgFilterLoad.Filter) == 0 {
+		return true
+	}
+
+	// The bloom filter does not contain the data if any of the bit offsets
+	// which result from hashing the data using each independent hash
+	// function are not set. The shifts and masks below are a faster
+	// equivalent of:
+	//   arrayIndex := idx / 8     (idx >> 3)
+	//   bitOffset := idx % 8      (idx & 7)
+	//   if filter[arrayIndex] & 1<<bitOffset == 0 { ... }
+	for i := uint32(0); i < bf.msgFilterLoad.HashFuncs; i++ {
+		idx := bf.hash(i, data)
+		if bf.msgFilterLoad.Filter[idx>>3]&(1<<(idx&7)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches returns true if the bloom filter might contain the passed data and
+// false if it definitely does not.
+//
+// This function is safe for concurrent access.
+func (bf *Filter) Matches(data []byte) bool {
+	bf.mtx.Lock()
+	defer bf.mtx.Unlock()
+
+	return bf.matches(data)
+}
+
+// serializeOutpoint serializes an outpoint the way it's added to the filter:
+// the transaction ID followed by the little-endian index
+func serializeOutpoint(outpoint *externalapi.DomainOutpoint) []byte {
+	var buf [externalapi.DomainHashSize + 4]byte
+	copy(buf[:], outpoint.TransactionID.ByteSlice())
+	binary.LittleEndian.PutUint32(buf[externalapi.DomainHashSize:], outpoint.Index)
+	return buf[:]
+}
+
+// MatchesOutpoint returns true if the bloom filter might contain the passed
+// outpoint and false if it definitely does not.
+//
+// This function is safe for concurrent access.
+func (bf *Filter) MatchesOutpoint(outpoint *externalapi.DomainOutpoint) bool {
+	bf.mtx.Lock()
+	defer bf.mtx.Unlock()
+
+	return bf.matches(serializeOutpoint(outpoint))
+}
+
+// add adds the passed byte slice to the bloom filter.
+//
+// This function MUST be called with the filter lock held.
+func (bf *Filter) add(data []byte) {
+	if bf.msgFilterLoad == nil || len(bf.msgFilterLoad.Filter) == 0 {
+		return
+	}
+
+	// Adding data to a bloom filter consists of setting all of the bit
+	// offsets which result from hashing the data using each independent
+	// hash function. The shifts and masks below are a faster equivalent
+	// of:
+	//   arrayIndex := idx / 8    (idx >> 3)
+	//   bitOffset := idx % 8     (idx & 7)
+	//   filter[arrayIndex] |= 1<<bitOffset
+	for i := uint32(0); i < bf.msgFilterLoad.HashFuncs; i++ {
+		idx := bf.hash(i, data)
+		bf.msgFilterLoad.Filter[idx>>3] |= 1 << (7 & idx)
+	}
+}
+
+// Add adds the passed byte slice to the bloom filter.
+//
+// This function is safe for concurrent access.
+func (bf *Filter) Add(data []byte) {
+	bf.mtx.Lock()
+	defer bf.mtx.Unlock()
+
+	bf.add(data)
+}
+
+// AddOutpoint adds the passed transaction outpoint to the bloom filter.
+//
+// This function is safe for concurrent access.
+func (bf *Filter) AddOutpoint(outpoint *externalapi.DomainOutpoint) {
+	bf.mtx.Lock()
+	defer bf.mtx.Unlock()
+
+	bf.add(serializeOutpoint(outpoint))
+}
+
+// maybeAddOutpoint potentially adds the passed outpoint to the bloom filter
+// depending on the bloom update flags and the type of the passed script
+// public key.
+//
+// This function MUST be called with the filter lock held.
+func (bf *Filter) maybeAddOutpoint(scriptPublicKey []byte, transactionID *externalapi.DomainTransactionID, index uint32) {
+	switch bf.msgFilterLoad.Flags {
+	case appmessage.BloomUpdateAll:
+		bf.add(serializeOutpoint(externalapi.NewDomainOutpoint(transactionID, index)))
+	case appmessage.BloomUpdateP2PubkeyOnly:
+		class := txscript.GetScriptClass(scriptPublicKey)
+		if class == txscript.PubKeyTy || class == txscript.PubKeyECDSATy {
+			bf.add(serializeOutpoint(externalapi.NewDomainOutpoint(transactionID, index)))
+		}
+	}
+}
+
+// matchTransactionAndUpdate returns true if the bloom filter matches data within
+// the passed transaction, otherwise false is returned. If the filter does match
+// the passed transaction, it will also update the filter depending on the bloom
+// update flags set via the loaded filter if needed.
+//
+// This function MUST be called with the filter lock held.
+func (bf *Filter) matchTransactionAndUpdate(transaction *externalapi.DomainTransaction) bool {
+	// Check if the filter matches the ID of the transaction.
+	// This is useful for finding transactions when they appear in a block.
+	transactionID := consensushashing.TransactionID(transaction)
+	matched := bf.matches(transactionID.ByteSlice())
+
+	// Check if the filter matches any data elements in the script public
+	// keys of any of the outputs. When it does, add the outpoint that
+	// matched so transactions which spend from the matched transaction are
+	// also included in the filter. This removes the burden of updating the
+	// filter for this scenario from the client.
+	for i, output := range transaction.Outputs {
+		pushedData, err := txscript.PushedData(output.ScriptPublicKey.Script)
+		if err != nil {
+			continue
+		}
+
+		for _, data := range pushedData {
+			if !bf.matches(data) {
+				continue
+			}
+
+			matched = true
+			bf.maybeAddOutpoint(output.ScriptPublicKey.Script, transactionID, uint32(i))
+			break
+		}
+	}
+
+	// Nothing more to do if a match has already been made.
+	if matched {
+		return true
+	}
+
+	// At this point, neither the transaction nor any of the data elements in
+	// the script public keys of its outputs matched.
+
+	// Check if the filter matches any outpoints this transaction spends or
+	// any data elements in the signature scripts of any of the inputs.
+	for _, input := range transaction.Inputs {
+		if bf.matches(serializeOutpoint(&input.PreviousOutpoint)) {
+			return true
+		}
+
+		pushedData, err := txscript.PushedData(input.SignatureScript)
+		if err != nil {
+			continue
+		}
+		for _, data := range pushedData {
+			if bf.matches(data) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// MatchTransactionAndUpdate returns true if the bloom filter matches data within
+// the passed transaction, otherwise false is returned. If the filter does match
+// the passed transaction, it will also update the filter depending on the bloom
+// update flags set via the loaded filter if needed.
+//
+// This function is safe for concurrent access.
+func (bf *Filter) MatchTransactionAndUpdate(transaction *externalapi.DomainTransaction) bool {
+	bf.mtx.Lock()
+	defer bf.mtx.Unlock()
+
+	return bf.matchTransactionAndUpdate(transaction)
+}
+
+// MsgFilterLoad returns the underlying appmessage.MsgFilterLoad for the bloom
+// filter.
+//
+// This function is safe for concurrent access.
+func (bf *Filter) MsgFilterLoad() *appmessage.MsgFilterLoad {
+	bf.mtx.Lock()
+	defer bf.mtx.Unlock()
+
+	return bf.msgFilterLoad
+}
@@ -0,0 +1,131 @@
+package bloom_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/util/bloom"
+)
+
+// TestFilterLarge ensures a maximum sized filter can be created.
+func TestFilterLarge(t *testing.T) {
+	f := bloom.NewFilter(100000000, 0, 0.01, appmessage.BloomUpdateNone)
+	if len(f.MsgFilterLoad().Filter) > appmessage.MaxFilterLoadFilterSize {
+		t.Errorf("TestFilterLarge test failed: %d > %d",
+			len(f.MsgFilterLoad().Filter), appmessage.MaxFilterLoadFilterSize)
+	}
+}
+
+// TestFilterLoad ensures loading and unloading of a filter pass.
+func TestFilterLoad(t *testing.T) {
+	f := bloom.LoadFilter(&appmessage.MsgFilterLoad{})
+	if !f.IsLoaded() {
+		t.Fatalf("TestFilterLoad: expected the filter to be loaded")
+	}
+	f.Unload()
+	if f.IsLoaded() {
+		t.Fatalf("TestFilterLoad: expected the filter to be unloaded")
+	}
+	if f.Matches([]byte{1, 2, 3}) {
+		t.Fatalf("TestFilterLoad: expected an unloaded filter to match nothing")
+	}
+}
+
+// TestFilterInsert ensures inserting data into the filter causes that data
+// to be matched and the resulting filter is the same as the one of BIP37.
+func TestFilterInsert(t *testing.T) {
+	var tests = []struct {
+		hex    string
+		insert bool
+	}{
+		{"99108ad8ed9bb6274d3980bab5a85c048f0950c8", true},
+		{"19108ad8ed9bb6274d3980bab5a85c048f0950c8", false},
+		{"b5a2c786d9ef4658287ced5914b37a1b4aa32eee", true},
+		{"b9300670b4c5366e95b2699e8b18bc75e5f729c5", true},
+	}
+
+	f := bloom.NewFilter(3, 0, 0.01, appmessage.BloomUpdateAll)
+
+	for i, test := range tests {
+		data, err := hex.DecodeString(test.hex)
+		if err != nil {
+			t.Fatalf("TestFilterInsert DecodeString failed: %v", err)
+		}
+		if test.insert {
+			f.Add(data)
+		}
+
+		result := f.Matches(data)
+		if test.insert != result {
+			t.Fatalf("TestFilterInsert Matches test #%d failure: got %v want %v",
+				i, result, test.insert)
+		}
+	}
+
+	expectedFilter := []byte{0x61, 0x4e, 0x9b}
+	msgFilterLoad := f.MsgFilterLoad()
+	if !bytes.Equal(msgFilterLoad.Filter, expectedFilter) || msgFilterLoad.HashFuncs != 5 {
+		t.Fatalf("TestFilterInsert failure: got filter %x with %d hash functions, "+
+			"want filter %x with 5 hash functions", msgFilterLoad.Filter, msgFilterLoad.HashFuncs, expectedFilter)
+	}
+}
+
+// TestFilterMatchTransactionAndUpdate ensures that a transaction paying to a
+// public key in the filter is matched, and that with BloomUpdateAll the
+// transactions spending its outputs are matched as well.
+func TestFilterMatchTransactionAndUpdate(t *testing.T) {
+	publicKey := bytes.Repeat([]byte{0x42}, 32)
+	scriptPublicKey, err := txscript.NewScriptBuilder().AddData(publicKey).AddOp(txscript.OpCheckSig).Script()
+	if err != nil {
+		t.Fatalf("Script: %s", err)
+	}
+
+	payingTransaction := &externalapi.DomainTransaction{
+		Inputs: []*externalapi.DomainTransactionInput{},
+		Outputs: []*externalapi.DomainTransactionOutput{
+			{Value: 1, ScriptPublicKey: &externalapi.ScriptPublicKey{Script: []byte{txscript.OpTrue}}},
+			{Value: 1, ScriptPublicKey: &externalapi.ScriptPublicKey{Script: scriptPublicKey}},
+		},
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+	}
+	payingTransactionID := consensushashing.TransactionID(payingTransaction)
+	createSpendingTransaction := func(index uint32) *externalapi.DomainTransaction {
+		return &externalapi.DomainTransaction{
+			Inputs: []*externalapi.DomainTransactionInput{
+				{PreviousOutpoint: *externalapi.NewDomainOutpoint(payingTransactionID, index)},
+			},
+			Outputs:      []*externalapi.DomainTransactionOutput{},
+			SubnetworkID: subnetworks.SubnetworkIDNative,
+		}
+	}
+
+	tests := []struct {
+		flags                       appmessage.BloomUpdateType
+		expectedSpendingOutputMatch bool
+	}{
+		{flags: appmessage.BloomUpdateNone, expectedSpendingOutputMatch: false},
+		{flags: appmessage.BloomUpdateAll, expectedSpendingOutputMatch: true},
+		{flags: appmessage.BloomUpdateP2PubkeyOnly, expectedSpendingOutputMatch: true},
+	}
+	for _, test := range tests {
+		f := bloom.NewFilter(10, 0, 0.000001, test.flags)
+		f.Add(publicKey)
+
+		if !f.MatchTransactionAndUpdate(payingTransaction) {
+			t.Fatalf("flags %d: expected the transaction paying to the public key to match", test.flags)
+		}
+		if f.MatchTransactionAndUpdate(createSpendingTransaction(1)) != test.expectedSpendingOutputMatch {
+			t.Fatalf("flags %d: expected the match of the transaction spending the matched output to be %t",
+				test.flags, test.expectedSpendingOutputMatch)
+		}
+		if f.MatchTransactionAndUpdate(createSpendingTransaction(0)) {
+			t.Fatalf("flags %d: expected the transaction spending the unmatched output not to match", test.flags)
+		}
+	}
+}
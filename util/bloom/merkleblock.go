@@ -0,0 +1,219 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bloom
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/hashes"
+	"github.com/pkg/errors"
+)
+
+// merkleBlock is used to house intermediate information needed to generate an
+// appmessage.MsgMerkleBlock according to a filter.
+type merkleBlock struct {
+	numTx       uint32
+	allHashes   []*externalapi.DomainHash
+	finalHashes []*externalapi.DomainHash
+	matchedBits []byte
+	bits        []byte
+}
+
+// calcTreeWidth calculates and returns the number of nodes (width) of a
+// merkle tree with numTx leaves at the given depth-first height.
+func calcTreeWidth(numTx uint32, height uint32) uint32 {
+	return (numTx + (1 << height) - 1) >> height
+}
+
+// calcTreeHeight calculates the number of merkle branches (height) of a
+// merkle tree with numTx leaves.
+func calcTreeHeight(numTx uint32) uint32 {
+	height := uint32(0)
+	for calcTreeWidth(numTx, height) > 1 {
+		height++
+	}
+	return height
+}
+
+// hashMerkleBranches returns the hash of the concatenation of the given left
+// and right nodes, the same way as the hash merkle root of a block. A missing
+// right node is hashed as the zero hash.
+func hashMerkleBranches(left, right *externalapi.DomainHash) *externalapi.DomainHash {
+	if right == nil {
+		right = &externalapi.DomainHash{}
+	}
+	w := hashes.NewMerkleBranchHashWriter()
+	w.InfallibleWrite(left.ByteSlice())
+	w.InfallibleWrite(right.ByteSlice())
+	return w.Finalize()
+}
+
+// calcHash returns the hash for a sub-tree given a depth-first height and
+// node position.
+func (m *merkleBlock) calcHash(height, pos uint32) *externalapi.DomainHash {
+	if height == 0 {
+		return m.allHashes[pos]
+	}
+
+	var right *externalapi.DomainHash
+	left := m.calcHash(height-1, pos*2)
+	if pos*2+1 < calcTreeWidth(m.numTx, height-1) {
+		right = m.calcHash(height-1, pos*2+1)
+	}
+	return hashMerkleBranches(left, right)
+}
+
+// traverseAndBuild builds a partial merkle tree using a recursive depth-first
+// approach. As it calculates the hashes, it also saves whether or not each
+// node is a parent node and a list of final hashes to be included in the
+// merkle block.
+func (m *merkleBlock) traverseAndBuild(height, pos uint32) {
+	// Determine whether this node is a parent of a matched node.
+	var isParent byte
+	for i := pos << height; i < (pos+1)<<height && i < m.numTx; i++ {
+		isParent |= m.matchedBits[i]
+	}
+	m.bits = append(m.bits, isParent)
+
+	// When the node is a leaf node or not a parent of a matched node,
+	// append the hash to the list that will be part of the final merkle
+	// block.
+	if height == 0 || isParent == 0x00 {
+		m.finalHashes = append(m.finalHashes, m.calcHash(height, pos))
+		return
+	}
+
+	// At this point, the node is an internal node and it is the parent of
+	// of an included leaf node.
+
+	// Descend into the left child and process its sub-tree.
+	m.traverseAndBuild(height-1, pos*2)
+
+	// Descend into the right child and process its sub-tree if
+	// there is one.
+	if pos*2+1 < calcTreeWidth(m.numTx, height-1) {
+		m.traverseAndBuild(height-1, pos*2+1)
+	}
+}
+
+// NewMerkleBlock returns a new *appmessage.MsgMerkleBlock of the given block,
+// holding the transactions that match the given filter. The filter is updated
+// according to its update flags.
+func NewMerkleBlock(block *externalapi.DomainBlock, filter *Filter) *appmessage.MsgMerkleBlock {
+	numTx := uint32(len(block.Transactions))
+	mBlock := merkleBlock{
+		numTx:       numTx,
+		allHashes:   make([]*externalapi.DomainHash, 0, numTx),
+		matchedBits: make([]byte, 0, numTx),
+	}
+
+	// Find and keep track of any transactions that match the filter.
+	var matchedTransactions []*appmessage.MsgTx
+	for _, transaction := range block.Transactions {
+		if filter.MatchTransactionAndUpdate(transaction) {
+			mBlock.matchedBits = append(mBlock.matchedBits, 0x01)
+			matchedTransactions = append(matchedTransactions, appmessage.DomainTransactionToMsgTx(transaction))
+		} else {
+			mBlock.matchedBits = append(mBlock.matchedBits, 0x00)
+		}
+		mBlock.allHashes = append(mBlock.allHashes, consensushashing.TransactionHash(transaction))
+	}
+
+	// Build the depth-first partial merkle tree.
+	mBlock.traverseAndBuild(calcTreeHeight(numTx), 0)
+
+	flags := make([]byte, (len(mBlock.bits)+7)/8)
+	for i := uint32(0); i < uint32(len(mBlock.bits)); i++ {
+		flags[i/8] |= mBlock.bits[i] << (i % 8)
+	}
+	return appmessage.NewMsgMerkleBlock(appmessage.DomainBlockHeaderToBlockHeader(block.Header),
+		numTx, mBlock.finalHashes, flags, matchedTransactions)
+}
+
+// partialMerkleTree is used to house intermediate information needed to
+// extract the matches out of an appmessage.MsgMerkleBlock.
+type partialMerkleTree struct {
+	numTx         uint32
+	hashes        []*externalapi.DomainHash
+	flags         []byte
+	hashesUsed    int
+	bitsUsed      uint32
+	matchedHashes []*externalapi.DomainHash
+}
+
+// traverseAndExtract is the inverse of traverseAndBuild: it recursively
+// calculates the hash of the sub-tree at the given height and position while
+// collecting the hashes of the matched leaves.
+func (t *partialMerkleTree) traverseAndExtract(height, pos uint32) (*externalapi.DomainHash, error) {
+	if t.bitsUsed >= uint32(len(t.flags))*8 {
+		return nil, errors.New("the merkle block flags ran out")
+	}
+	isParent := (t.flags[t.bitsUsed/8] >> (t.bitsUsed % 8)) & 1
+	t.bitsUsed++
+
+	if height == 0 || isParent == 0 {
+		if t.hashesUsed >= len(t.hashes) {
+			return nil, errors.New("the merkle block hashes ran out")
+		}
+		hash := t.hashes[t.hashesUsed]
+		t.hashesUsed++
+		if height == 0 && isParent == 1 {
+			t.matchedHashes = append(t.matchedHashes, hash)
+		}
+		return hash, nil
+	}
+
+	left, err := t.traverseAndExtract(height-1, pos*2)
+	if err != nil {
+		return nil, err
+	}
+	var right *externalapi.DomainHash
+	if pos*2+1 < calcTreeWidth(t.numTx, height-1) {
+		right, err = t.traverseAndExtract(height-1, pos*2+1)
+		if err != nil {
+			return nil, err
+		}
+		if right.Equal(left) {
+			return nil, errors.New("the merkle block has identical sibling nodes")
+		}
+	}
+	return hashMerkleBranches(left, right), nil
+}
+
+// ExtractMatches verifies the partial merkle tree of the given merkle block
+// against the hash merkle root of its header, and returns the hashes of the
+// transactions that matched the filter. SPV clients use it to verify that
+// the transactions they receive are included in the block.
+func ExtractMatches(msgMerkleBlock *appmessage.MsgMerkleBlock) ([]*externalapi.DomainHash, error) {
+	if msgMerkleBlock.TransactionCount == 0 {
+		return nil, errors.New("a merkle block must hold at least one transaction")
+	}
+	if uint32(len(msgMerkleBlock.Hashes)) > msgMerkleBlock.TransactionCount {
+		return nil, errors.Errorf("a merkle block of %d transactions can't hold %d hashes",
+			msgMerkleBlock.TransactionCount, len(msgMerkleBlock.Hashes))
+	}
+
+	tree := partialMerkleTree{
+		numTx:  msgMerkleBlock.TransactionCount,
+		hashes: msgMerkleBlock.Hashes,
+		flags:  msgMerkleBlock.Flags,
+	}
+	root, err := tree.traverseAndExtract(calcTreeHeight(tree.numTx), 0)
+	if err != nil {
+		return nil, err
+	}
+	if tree.hashesUsed != len(tree.hashes) {
+		return nil, errors.Errorf("the merkle block holds %d unused hashes", len(tree.hashes)-tree.hashesUsed)
+	}
+	if (tree.bitsUsed+7)/8 != uint32(len(tree.flags)) {
+		return nil, errors.New("the merkle block holds unused flags")
+	}
+	if !root.Equal(msgMerkleBlock.Header.HashMerkleRoot) {
+		return nil, errors.Errorf("the partial merkle tree root %s doesn't match the hash merkle root %s",
+			root, msgMerkleBlock.Header.HashMerkleRoot)
+	}
+	return tree.matchedHashes, nil
+}
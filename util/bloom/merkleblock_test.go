@@ -0,0 +1,128 @@
+package bloom_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/blockheader"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/merkle"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/util/bloom"
+)
+
+func createBlock(transactionCount int) *externalapi.DomainBlock {
+	transactions := make([]*externalapi.DomainTransaction, transactionCount)
+	for i := range transactions {
+		transactions[i] = &externalapi.DomainTransaction{
+			Inputs:       []*externalapi.DomainTransactionInput{},
+			Outputs:      []*externalapi.DomainTransactionOutput{},
+			SubnetworkID: subnetworks.SubnetworkIDNative,
+			Payload:      []byte{byte(i)},
+		}
+	}
+	header := blockheader.NewImmutableBlockHeader(0, nil, merkle.CalculateHashMerkleRoot(transactions),
+		&externalapi.DomainHash{}, &externalapi.DomainHash{}, 0, 0, 0, 0, 0, big.NewInt(0), &externalapi.DomainHash{})
+	return &externalapi.DomainBlock{Header: header, Transactions: transactions}
+}
+
+func TestMerkleBlock(t *testing.T) {
+	for transactionCount := 1; transactionCount <= 17; transactionCount++ {
+		block := createBlock(transactionCount)
+
+		for _, matchedIndexes := range [][]int{{}, {0}, {transactionCount - 1}, {0, transactionCount / 2}} {
+			filter := bloom.NewFilter(uint32(len(matchedIndexes))+1, 0, 0.000001, appmessage.BloomUpdateNone)
+			expectedHashes := make(map[externalapi.DomainHash]struct{})
+			for _, index := range matchedIndexes {
+				transaction := block.Transactions[index]
+				filter.Add(consensushashing.TransactionID(transaction).ByteSlice())
+				expectedHashes[*consensushashing.TransactionHash(transaction)] = struct{}{}
+			}
+
+			msgMerkleBlock := bloom.NewMerkleBlock(block, filter)
+			if msgMerkleBlock.TransactionCount != uint32(transactionCount) {
+				t.Fatalf("%d transactions: expected a transaction count of %d but got %d",
+					transactionCount, transactionCount, msgMerkleBlock.TransactionCount)
+			}
+
+			matchedHashes, err := bloom.ExtractMatches(msgMerkleBlock)
+			if err != nil {
+				t.Fatalf("%d transactions, matches %v: ExtractMatches: %s", transactionCount, matchedIndexes, err)
+			}
+			if len(matchedHashes) != len(msgMerkleBlock.Transactions) {
+				t.Fatalf("%d transactions, matches %v: extracted %d hashes for %d matched transactions",
+					transactionCount, matchedIndexes, len(matchedHashes), len(msgMerkleBlock.Transactions))
+			}
+			for i, matchedHash := range matchedHashes {
+				transaction := appmessage.MsgTxToDomainTransaction(msgMerkleBlock.Transactions[i])
+				if !matchedHash.Equal(consensushashing.TransactionHash(transaction)) {
+					t.Fatalf("%d transactions, matches %v: extracted hash %s doesn't belong to "+
+						"the matched transaction %s", transactionCount, matchedIndexes, matchedHash,
+						consensushashing.TransactionHash(transaction))
+				}
+				delete(expectedHashes, *matchedHash)
+			}
+			if len(expectedHashes) != 0 {
+				t.Fatalf("%d transactions, matches %v: %d of the expected transactions weren't matched",
+					transactionCount, matchedIndexes, len(expectedHashes))
+			}
+		}
+	}
+}
+
+func TestExtractMatchesErrors(t *testing.T) {
+	block := createBlock(5)
+	filter := bloom.NewFilter(1, 0, 0.000001, appmessage.BloomUpdateNone)
+	filter.Add(consensushashing.TransactionID(block.Transactions[2]).ByteSlice())
+
+	tests := []struct {
+		name   string
+		mutate func(msgMerkleBlock *appmessage.MsgMerkleBlock)
+	}{
+		{
+			name: "wrong hash merkle root",
+			mutate: func(msgMerkleBlock *appmessage.MsgMerkleBlock) {
+				msgMerkleBlock.Header.HashMerkleRoot = &externalapi.DomainHash{}
+			},
+		},
+		{
+			name: "tampered hash",
+			mutate: func(msgMerkleBlock *appmessage.MsgMerkleBlock) {
+				msgMerkleBlock.Hashes[0] = &externalapi.DomainHash{}
+			},
+		},
+		{
+			name: "missing hash",
+			mutate: func(msgMerkleBlock *appmessage.MsgMerkleBlock) {
+				msgMerkleBlock.Hashes = msgMerkleBlock.Hashes[1:]
+			},
+		},
+		{
+			name: "extra flags",
+			mutate: func(msgMerkleBlock *appmessage.MsgMerkleBlock) {
+				msgMerkleBlock.Flags = append(msgMerkleBlock.Flags, 0)
+			},
+		},
+		{
+			name: "wrong transaction count",
+			mutate: func(msgMerkleBlock *appmessage.MsgMerkleBlock) {
+				msgMerkleBlock.TransactionCount *= 2
+			},
+		},
+	}
+
+	for _, test := range tests {
+		msgMerkleBlock := bloom.NewMerkleBlock(block, filter)
+		_, err := bloom.ExtractMatches(msgMerkleBlock)
+		if err != nil {
+			t.Fatalf("%s: ExtractMatches of the untampered merkle block: %s", test.name, err)
+		}
+		test.mutate(msgMerkleBlock)
+		_, err = bloom.ExtractMatches(msgMerkleBlock)
+		if err == nil {
+			t.Errorf("%s: expected an error but got none", test.name)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package gcs
+
+import "io"
+
+// bitWriter writes a stream of bits, most significant bit first
+type bitWriter struct {
+	stream []byte
+	count  uint8 // The number of bits still free in the last byte of the stream
+}
+
+func (w *bitWriter) writeBit(bit bool) {
+	if w.count == 0 {
+		w.stream = append(w.stream, 0)
+		w.count = 8
+	}
+	w.count--
+	if bit {
+		w.stream[len(w.stream)-1] |= 1 << w.count
+	}
+}
+
+// writeBits writes the nbits least significant bits of u
+func (w *bitWriter) writeBits(u uint64, nbits int) {
+	for i := nbits - 1; i >= 0; i-- {
+		w.writeBit(u&(1<<uint(i)) != 0)
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	return w.stream
+}
+
+// bitReader reads a stream of bits written by bitWriter
+type bitReader struct {
+	stream []byte
+	count  uint8 // The number of bits still unread in the first byte of the stream
+}
+
+func newBitReader(stream []byte) *bitReader {
+	return &bitReader{stream: stream, count: 8}
+}
+
+func (r *bitReader) readBit() (bool, error) {
+	if len(r.stream) == 0 {
+		return false, io.EOF
+	}
+	r.count--
+	bit := r.stream[0]&(1<<r.count) != 0
+	if r.count == 0 {
+		r.stream = r.stream[1:]
+		r.count = 8
+	}
+	return bit, nil
+}
+
+func (r *bitReader) readBits(nbits int) (uint64, error) {
+	var u uint64
+	for i := 0; i < nbits; i++ {
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		u <<= 1
+		if bit {
+			u |= 1
+		}
+	}
+	return u, nil
+}
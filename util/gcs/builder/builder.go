@@ -0,0 +1,86 @@
+package builder
+
+import (
+	"encoding/binary"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/hashes"
+	"github.com/kaspanet/kaspad/util/gcs"
+)
+
+const (
+	// DefaultP is the default collision probability (2^-19)
+	DefaultP = 19
+
+	// DefaultM is the default value used for the hash range.
+	DefaultM uint64 = 784931
+)
+
+// DeriveKey derives the SipHash key of the filter of the block of the
+// given hash from the first bytes of the hash
+func DeriveKey(blockHash *externalapi.DomainHash) [gcs.KeySize]byte {
+	var key [gcs.KeySize]byte
+	copy(key[:], blockHash.ByteSlice())
+	return key
+}
+
+// OutpointElement returns the filter element that represents the given outpoint
+func OutpointElement(outpoint *externalapi.DomainOutpoint) []byte {
+	element := make([]byte, externalapi.DomainHashSize+4)
+	copy(element, outpoint.TransactionID.ByteSlice())
+	binary.LittleEndian.PutUint32(element[externalapi.DomainHashSize:], outpoint.Index)
+	return element
+}
+
+// BuildBasicFilter builds the basic filter of the given block. The filter
+// matches the script public key of every output created by the block, as
+// well as every outpoint spent by it.
+//
+// Note that unlike BIP158, spent outputs are represented by their outpoints
+// rather than by their script public keys, since in the DAG the entries they
+// spend depend on the chain block that accepts the block rather than on the
+// block itself.
+func BuildBasicFilter(block *externalapi.DomainBlock) (*gcs.Filter, error) {
+	elements := make(map[string]struct{})
+	for _, transaction := range block.Transactions {
+		for _, input := range transaction.Inputs {
+			elements[string(OutpointElement(&input.PreviousOutpoint))] = struct{}{}
+		}
+		for _, output := range transaction.Outputs {
+			if len(output.ScriptPublicKey.Script) == 0 {
+				continue
+			}
+			elements[string(output.ScriptPublicKey.Script)] = struct{}{}
+		}
+	}
+
+	data := make([][]byte, 0, len(elements))
+	for element := range elements {
+		data = append(data, []byte(element))
+	}
+	return gcs.BuildGCSFilter(DefaultP, DefaultM, DeriveKey(consensushashing.BlockHash(block)), data)
+}
+
+// FilterHash returns the hash of the given filter along with its N
+func FilterHash(filter *gcs.Filter) *externalapi.DomainHash {
+	hashWriter := hashes.NewCompactFilterHashWriter()
+	hashWriter.InfallibleWrite(filter.NBytes())
+	return hashWriter.Finalize()
+}
+
+// MakeHeaderForFilter returns the header of the given filter, which commits
+// to the filter and to the header of the filter of the selected parent
+func MakeHeaderForFilter(filter *gcs.Filter, selectedParentHeader *externalapi.DomainHash) *externalapi.DomainHash {
+	return MakeHeaderForFilterHash(FilterHash(filter), selectedParentHeader)
+}
+
+// MakeHeaderForFilterHash is the same as MakeHeaderForFilter, given the hash of the filter
+func MakeHeaderForFilterHash(filterHash *externalapi.DomainHash,
+	selectedParentHeader *externalapi.DomainHash) *externalapi.DomainHash {
+
+	hashWriter := hashes.NewCompactFilterHeaderWriter()
+	hashWriter.InfallibleWrite(filterHash.ByteSlice())
+	hashWriter.InfallibleWrite(selectedParentHeader.ByteSlice())
+	return hashWriter.Finalize()
+}
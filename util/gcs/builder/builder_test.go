@@ -0,0 +1,60 @@
+package builder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/blockheader"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+)
+
+func TestBuildBasicFilter(t *testing.T) {
+	spentOutpoint := externalapi.NewDomainOutpoint(externalapi.NewDomainTransactionIDFromByteArray(&[32]byte{1}), 3)
+	scriptPublicKey := &externalapi.ScriptPublicKey{Script: []byte{0x20, 1, 2, 3, 0xac}, Version: 0}
+	block := &externalapi.DomainBlock{
+		Header: blockheader.NewImmutableBlockHeader(0, nil, &externalapi.DomainHash{}, &externalapi.DomainHash{},
+			&externalapi.DomainHash{}, 0, 0, 0, 0, 0, big.NewInt(0), &externalapi.DomainHash{}),
+		Transactions: []*externalapi.DomainTransaction{{
+			Inputs:  []*externalapi.DomainTransactionInput{{PreviousOutpoint: *spentOutpoint}},
+			Outputs: []*externalapi.DomainTransactionOutput{{Value: 1000, ScriptPublicKey: scriptPublicKey}},
+		}},
+	}
+
+	filter, err := BuildBasicFilter(block)
+	if err != nil {
+		t.Fatalf("BuildBasicFilter: %+v", err)
+	}
+	if filter.N() != 2 {
+		t.Fatalf("Expected the filter to hold 2 elements but it holds %d", filter.N())
+	}
+
+	key := DeriveKey(consensushashing.BlockHash(block))
+	tests := []struct {
+		name          string
+		element       []byte
+		expectedMatch bool
+	}{
+		{name: "output script public key", element: scriptPublicKey.Script, expectedMatch: true},
+		{name: "spent outpoint", element: OutpointElement(spentOutpoint), expectedMatch: true},
+		{name: "unrelated outpoint", element: OutpointElement(externalapi.NewDomainOutpoint(
+			&spentOutpoint.TransactionID, 4)), expectedMatch: false},
+	}
+	for _, test := range tests {
+		match, err := filter.Match(key, test.element)
+		if err != nil {
+			t.Fatalf("%s: Match: %+v", test.name, err)
+		}
+		// False positives happen with a probability of 2^-DefaultP, so an unexpected match is an error as well
+		if match != test.expectedMatch {
+			t.Errorf("%s: expected match to be %t but got %t", test.name, test.expectedMatch, match)
+		}
+	}
+
+	// Filter headers commit to the header of the selected parent
+	filterHash := FilterHash(filter)
+	if MakeHeaderForFilter(filter, &externalapi.DomainHash{}).Equal(
+		MakeHeaderForFilterHash(filterHash, externalapi.NewDomainHashFromByteArray(&[32]byte{1}))) {
+		t.Errorf("Expected filter headers with different previous headers to differ")
+	}
+}
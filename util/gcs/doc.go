@@ -0,0 +1,23 @@
+// Copyright (c) 2016-2017 The btcsuite developers
+// Copyright (c) 2016-2017 The Lightning Network Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package gcs provides an API for building and using a Golomb-coded set filter.
+
+# Golomb-Coded Set
+
+A Golomb-coded set is a probabilistic data structure used similarly to a Bloom
+filter. A filter uses constant-size overhead plus on average n+2 bits per
+item added to the filter, where 2^-n is the desired false positive (collision)
+probability.
+
+# GCS use in Kaspa
+
+GCS filters are used for the compact block filters (BIP157/158) a full node
+serves to light clients. The usage is intended to be the inverse of Bloom
+filters: a full node sends a light client the GCS filter of a block, which the
+light client checks against its list of relevant items.
+*/
+package gcs
@@ -0,0 +1,229 @@
+// Copyright (c) 2016-2017 The btcsuite developers
+// Copyright (c) 2016-2017 The Lightning Network Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package gcs_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/kaspanet/kaspad/util/gcs"
+)
+
+const (
+	// Collision probability for the tests (1/2**19)
+	P = uint8(19)
+
+	// Modulus value for the tests.
+	M uint64 = 784931
+)
+
+var (
+	key = [gcs.KeySize]byte{
+		0x4c, 0xb1, 0xab, 0x12, 0x57, 0x62, 0x1e, 0x41,
+		0x3b, 0x8b, 0x0e, 0x26, 0x64, 0x8d, 0x4a, 0x15,
+	}
+
+	// List of values for building a filter
+	contents = [][]byte{
+		[]byte("Alex"),
+		[]byte("Bob"),
+		[]byte("Charlie"),
+		[]byte("Dick"),
+		[]byte("Ed"),
+		[]byte("Frank"),
+		[]byte("George"),
+		[]byte("Harry"),
+		[]byte("Ilya"),
+		[]byte("John"),
+		[]byte("Kevin"),
+		[]byte("Larry"),
+		[]byte("Michael"),
+		[]byte("Nate"),
+		[]byte("Owen"),
+		[]byte("Paul"),
+		[]byte("Quentin"),
+	}
+
+	// List of values for querying a filter using MatchAny()
+	contents2 = [][]byte{
+		[]byte("Alice"),
+		[]byte("Betty"),
+		[]byte("Charmaine"),
+		[]byte("Donna"),
+		[]byte("Edith"),
+		[]byte("Faina"),
+		[]byte("Georgia"),
+		[]byte("Hannah"),
+		[]byte("Ilsbeth"),
+		[]byte("Jennifer"),
+		[]byte("Kayla"),
+		[]byte("Lena"),
+		[]byte("Michelle"),
+		[]byte("Natalie"),
+		[]byte("Ophelia"),
+		[]byte("Peggy"),
+		[]byte("Queenie"),
+	}
+)
+
+// TestGCSMatchZeroHash ensures that Match and MatchAny properly match an item
+// if it's hash after the reduction is zero. This is accomplished by brute
+// forcing a specific target whose hash is zero given a certain (P, M, key,
+// len(elements)) combination. In this case, P and M are the default, key was
+// chosen randomly, and len(elements) is 13. The target 4-byte value of 16060032
+// is the first such 32-bit value, thus we use the number 0-11 as the other
+// elements in the filter since we know they won't collide. We test both the
+// positive and negative cases, when the zero hash item is in the filter and
+// when it is excluded. In the negative case, the 32-bit value of 12 is added to
+// the filter instead of the target.
+func TestGCSMatchZeroHash(t *testing.T) {
+	t.Run("include zero", func(t *testing.T) {
+		testGCSMatchZeroHash(t, true)
+	})
+	t.Run("exclude zero", func(t *testing.T) {
+		testGCSMatchZeroHash(t, false)
+	})
+}
+
+func testGCSMatchZeroHash(t *testing.T, includeZeroHash bool) {
+	key := [gcs.KeySize]byte{
+		0x25, 0x28, 0x0d, 0x25, 0x26, 0xe1, 0xd3, 0xc7,
+		0xa5, 0x71, 0x85, 0x34, 0x92, 0xa5, 0x7e, 0x68,
+	}
+
+	// Construct the target data to match, whose hash is zero after applying
+	// the reduction with the parameters in the test.
+	target := make([]byte, 4)
+	binary.BigEndian.PutUint32(target, 16060032)
+
+	// Construct the set of 13 items including the target, using the 32-bit
+	// values of 0 through 11 as the first 12 items. We known none of these
+	// hash to zero since the brute force ended well beyond them.
+	elements := make([][]byte, 0, 13)
+	for i := 0; i < 12; i++ {
+		data := make([]byte, 4)
+		binary.BigEndian.PutUint32(data, uint32(i))
+		elements = append(elements, data)
+	}
+
+	// If the filter should include the zero hash element, add the target
+	// which we know hashes to zero. Otherwise add 32-bit value of 12 which
+	// we know does not hash to zero.
+	if includeZeroHash {
+		elements = append(elements, target)
+	} else {
+		data := make([]byte, 4)
+		binary.BigEndian.PutUint32(data, 12)
+		elements = append(elements, data)
+	}
+
+	filter, err := gcs.BuildGCSFilter(P, M, key, elements)
+	if err != nil {
+		t.Fatalf("unable to build filter: %v", err)
+	}
+
+	match, err := filter.Match(key, target)
+	if err != nil {
+		t.Fatalf("unable to match: %v", err)
+	}
+
+	// We should only get a match iff the target was included.
+	if match != includeZeroHash {
+		t.Fatalf("expected match from Match: %t, got %t",
+			includeZeroHash, match)
+	}
+
+	match, err = filter.MatchAny(key, [][]byte{target})
+	if err != nil {
+		t.Fatalf("unable to match any: %v", err)
+	}
+
+	// We should only get a match iff the target was included.
+	if match != includeZeroHash {
+		t.Fatalf("expected match from MatchAny: %t, got %t",
+			includeZeroHash, match)
+	}
+}
+
+// TestGCSFilter builds a filter, copies it through its serialized form, and
+// checks that both filters match every one of their contents, logging any
+// false positives without failing on them.
+func TestGCSFilter(t *testing.T) {
+	filter, err := gcs.BuildGCSFilter(P, M, key, contents)
+	if err != nil {
+		t.Fatalf("Filter build failed: %s", err)
+	}
+	filterCopy, err := gcs.FromBytes(filter.N(), P, M, filter.Bytes())
+	if err != nil {
+		t.Fatalf("Filter copy failed: %s", err)
+	}
+	filterNCopy, err := gcs.FromNBytes(P, M, filter.NBytes())
+	if err != nil {
+		t.Fatalf("Filter copy failed: %s", err)
+	}
+	if filterNCopy.N() != filter.N() || !bytes.Equal(filter.Bytes(), filterNCopy.Bytes()) {
+		t.Fatalf("Filter copied with N doesn't match the original filter")
+	}
+	if filter.P() != P || filterCopy.P() != P {
+		t.Fatalf("P not correctly stored in filter metadata")
+	}
+	if filter.N() != uint32(len(contents)) || filterCopy.N() != filter.N() {
+		t.Fatalf("N not correctly stored in filter metadata")
+	}
+	if !bytes.Equal(filter.Bytes(), filterCopy.Bytes()) {
+		t.Fatalf("Bytes don't match between copied filters")
+	}
+
+	for _, f := range []*gcs.Filter{filter, filterCopy} {
+		for _, element := range contents {
+			match, err := f.Match(key, element)
+			if err != nil {
+				t.Fatalf("Filter match failed: %s", err)
+			}
+			if !match {
+				t.Fatalf("Filter didn't match %s when it should have!", element)
+			}
+		}
+		match, err := f.Match(key, []byte("Nates"))
+		if err != nil {
+			t.Fatalf("Filter match failed: %s", err)
+		}
+		if match {
+			t.Logf("False positive match, should be 1 in 2**%d!", P)
+		}
+
+		contentsCopy := make([][]byte, len(contents2))
+		copy(contentsCopy, contents2)
+		match, err = f.MatchAny(key, contentsCopy)
+		if err != nil {
+			t.Fatalf("Filter match any failed: %s", err)
+		}
+		if match {
+			t.Logf("False positive match, should be 1 in 2**%d!", P)
+		}
+		contentsCopy = append(contentsCopy, []byte("Nate"))
+		match, err = f.MatchAny(key, contentsCopy)
+		if err != nil {
+			t.Fatalf("Filter match any failed: %s", err)
+		}
+		if !match {
+			t.Fatalf("Filter didn't match any when it should have!")
+		}
+	}
+
+	emptyFilter, err := gcs.BuildGCSFilter(P, M, key, nil)
+	if err != nil {
+		t.Fatalf("Filter build failed: %s", err)
+	}
+	match, err := emptyFilter.MatchAny(key, contents)
+	if err != nil {
+		t.Fatalf("Filter match any failed: %s", err)
+	}
+	if match {
+		t.Fatalf("An empty filter matched")
+	}
+}
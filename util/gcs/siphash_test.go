@@ -0,0 +1,32 @@
+package gcs
+
+import "testing"
+
+func TestSipHash24(t *testing.T) {
+	// Test vectors from the SipHash paper: the key is 00 01 .. 0f and
+	// the message of length n is 00 01 .. n-1
+	var key [KeySize]byte
+	for i := range key {
+		key[i] = byte(i)
+	}
+	tests := []struct {
+		length   int
+		expected uint64
+	}{
+		{length: 0, expected: 0x726fdb47dd0e0e31},
+		{length: 1, expected: 0x74f839c593dc67fd},
+		{length: 7, expected: 0xab0200f58b01d137},
+		{length: 8, expected: 0x93f5f5799a932462},
+		{length: 15, expected: 0xa129ca6149be45e5},
+		{length: 63, expected: 0x958a324ceb064572},
+	}
+	for _, test := range tests {
+		data := make([]byte, test.length)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		if hash := sipHash24(data, &key); hash != test.expected {
+			t.Errorf("length %d: expected %x but got %x", test.length, test.expected, hash)
+		}
+	}
+}
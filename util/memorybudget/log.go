@@ -0,0 +1,9 @@
+package memorybudget
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/util/panics"
+)
+
+var log = logger.RegisterSubSystem("MEMB")
+var spawn = panics.GoroutineWrapperFunc(log)
@@ -0,0 +1,179 @@
+// Package memorybudget apportions a single memory budget between the caches of kaspad,
+// so that operators set one limit instead of tuning the size of every cache.
+package memorybudget
+
+import (
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// Consumer is a cache whose memory is managed by a Manager
+type Consumer interface {
+	// MemoryUsage returns the estimated number of bytes the cache occupies
+	MemoryUsage() uint64
+
+	// SetMemoryLimit sets the number of bytes the cache may occupy, and shrinks it
+	// if it occupies more
+	SetMemoryLimit(limit uint64)
+}
+
+// rebalanceInterval is the interval in which the budget is apportioned again
+const rebalanceInterval = 5 * time.Second
+
+// growthHeadroom is the fraction of its share that a cache which uses less than its share
+// is allowed to grow by until the next rebalance
+const growthHeadroom = 0.25
+
+// heapObjectsMetric is the memory occupied by live heap objects and by dead ones that the
+// garbage collector didn't free yet
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+type registeredConsumer struct {
+	name     string
+	consumer Consumer
+	weight   uint64
+	minimum  uint64
+}
+
+// Manager apportions a memory budget between consumers. The budget is the memory the
+// whole heap may occupy: whatever the heap occupies besides the consumers is taken out
+// of it, so the consumers shrink under memory pressure from the rest of the node. The
+// remainder is split between the consumers in proportion to their weights, and the share
+// a consumer doesn't use goes to the consumers that fill theirs.
+type Manager struct {
+	budget    uint64
+	consumers []*registeredConsumer
+	heapUsage func() uint64
+
+	quit     chan struct{}
+	stopOnce sync.Once
+}
+
+// New creates a Manager of the given budget, in bytes. Use Register() to add consumers and
+// Start() to begin apportioning the budget between them.
+func New(budget uint64) *Manager {
+	return &Manager{
+		budget:    budget,
+		heapUsage: heapUsage,
+		quit:      make(chan struct{}),
+	}
+}
+
+// Register adds a consumer with the given name, which is used in the logs. The weight
+// determines the share of the budget the consumer gets, and the consumer is never limited
+// below the given minimum, in bytes, even under memory pressure.
+func (m *Manager) Register(name string, consumer Consumer, weight uint64, minimum uint64) {
+	m.consumers = append(m.consumers, &registeredConsumer{
+		name:     name,
+		consumer: consumer,
+		weight:   weight,
+		minimum:  minimum,
+	})
+}
+
+// Start apportions the budget, and keeps apportioning it again as the memory usage changes
+func (m *Manager) Start() {
+	log.Infof("Apportioning a memory budget of %d MB between %d caches", m.budget/1_000_000, len(m.consumers))
+	m.Rebalance()
+	spawn("memorybudget.Manager.run", m.run)
+}
+
+// Stop stops apportioning the budget. The consumers keep their last limits.
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.quit)
+	})
+}
+
+func (m *Manager) run() {
+	ticker := time.NewTicker(rebalanceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.quit:
+			return
+		case <-ticker.C:
+			m.Rebalance()
+		}
+	}
+}
+
+// Rebalance apportions the budget between the consumers according to their current memory
+// usage and the memory usage of the heap
+func (m *Manager) Rebalance() {
+	usages := make([]uint64, len(m.consumers))
+	totalUsage := uint64(0)
+	for i, registered := range m.consumers {
+		usages[i] = registered.consumer.MemoryUsage()
+		totalUsage += usages[i]
+	}
+
+	// The usages of the consumers are estimates, so the rest of the heap is taken to be
+	// whatever they don't account for
+	otherUsage := uint64(0)
+	if heapUsage := m.heapUsage(); heapUsage > totalUsage {
+		otherUsage = heapUsage - totalUsage
+	}
+	available := uint64(0)
+	if m.budget > otherUsage {
+		available = m.budget - otherUsage
+	}
+
+	limits := m.apportion(available, usages)
+	for i, registered := range m.consumers {
+		log.Debugf("The %s occupies %d bytes and is limited to %d", registered.name, usages[i], limits[i])
+		registered.consumer.SetMemoryLimit(limits[i])
+	}
+}
+
+// apportion splits the available memory between the consumers with the given usages. Each
+// consumer gets a share of it by its weight. A consumer that uses less than its share is
+// limited to its usage with some room to grow, and the rest of its share is split between
+// the other consumers by their weights.
+func (m *Manager) apportion(available uint64, usages []uint64) []uint64 {
+	totalWeight := uint64(0)
+	for _, registered := range m.consumers {
+		totalWeight += registered.weight
+	}
+
+	limits := make([]uint64, len(m.consumers))
+	isConstrained := make([]bool, len(m.consumers))
+	surplus := uint64(0)
+	constrainedWeight := uint64(0)
+	for i, registered := range m.consumers {
+		share := uint64(0)
+		if totalWeight > 0 {
+			share = uint64(float64(available) * float64(registered.weight) / float64(totalWeight))
+		}
+		wanted := usages[i] + uint64(float64(share)*growthHeadroom)
+		if wanted < share {
+			limits[i] = wanted
+			surplus += share - wanted
+			continue
+		}
+		limits[i] = share
+		isConstrained[i] = true
+		constrainedWeight += registered.weight
+	}
+
+	for i, registered := range m.consumers {
+		if isConstrained[i] && constrainedWeight > 0 {
+			limits[i] += uint64(float64(surplus) * float64(registered.weight) / float64(constrainedWeight))
+		}
+		if limits[i] < registered.minimum {
+			limits[i] = registered.minimum
+		}
+	}
+	return limits
+}
+
+func heapUsage() uint64 {
+	samples := []metrics.Sample{{Name: heapObjectsMetric}}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return samples[0].Value.Uint64()
+}
@@ -0,0 +1,75 @@
+package memorybudget
+
+import (
+	"testing"
+)
+
+type testConsumer struct {
+	usage uint64
+	limit uint64
+}
+
+func (c *testConsumer) MemoryUsage() uint64 {
+	return c.usage
+}
+
+func (c *testConsumer) SetMemoryLimit(limit uint64) {
+	c.limit = limit
+	if c.usage > limit {
+		c.usage = limit
+	}
+}
+
+func TestRebalance(t *testing.T) {
+	tests := []struct {
+		name           string
+		heapUsage      uint64
+		usages         []uint64
+		expectedLimits []uint64
+	}{
+		{
+			name:           "full consumers split the budget by weight",
+			heapUsage:      1000,
+			usages:         []uint64{300, 700},
+			expectedLimits: []uint64{250, 750},
+		},
+		{
+			name:      "the unused share of a consumer goes to the full consumer",
+			heapUsage: 900,
+			usages:    []uint64{100, 800},
+			// The share of the first consumer is 250, of which it's left its usage
+			// plus a quarter of its share, and it's then raised to its minimum
+			expectedLimits: []uint64{200, 838},
+		},
+		{
+			name:      "the consumers shrink when the rest of the heap grows",
+			heapUsage: 1500,
+			usages:    []uint64{250, 750},
+			// The rest of the heap occupies 500 bytes, which leaves 500 to the
+			// consumers, and the first one is kept at its minimum
+			expectedLimits: []uint64{200, 375},
+		},
+		{
+			name:           "the consumers are kept at their minimums when the heap exceeds the budget",
+			heapUsage:      5000,
+			usages:         []uint64{250, 750},
+			expectedLimits: []uint64{200, 0},
+		},
+	}
+
+	for _, test := range tests {
+		manager := New(1000)
+		manager.heapUsage = func() uint64 { return test.heapUsage }
+		consumers := []*testConsumer{{usage: test.usages[0]}, {usage: test.usages[1]}}
+		manager.Register("first", consumers[0], 1, 200)
+		manager.Register("second", consumers[1], 3, 0)
+
+		manager.Rebalance()
+		for i, consumer := range consumers {
+			if consumer.limit != test.expectedLimits[i] {
+				t.Fatalf("%s: expected consumer %d to be limited to %d but got %d",
+					test.name, i, test.expectedLimits[i], consumer.limit)
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Package processingstats records how long each stage of block processing takes, so that
+// a regression in block processing can be localized to the stage that caused it.
+package processingstats
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/metrics"
+)
+
+// Stage is a stage of block processing whose durations are recorded
+type Stage string
+
+// The stages of block processing. Unless noted otherwise, a duration is recorded for
+// every block that goes through the stage.
+const (
+	// Deserialization is the conversion of a block that was received from a peer
+	// to a domain block
+	Deserialization Stage = "deserialization"
+
+	// IsolationChecks are the checks of the header that don't depend on other blocks,
+	// including its proof of work
+	IsolationChecks Stage = "isolationChecks"
+
+	// ContextChecks are the checks of the body in isolation and of the header and body
+	// in the context of the DAG
+	ContextChecks Stage = "contextChecks"
+
+	// VirtualUpdate is the validation of the block against its past UTXO set and the
+	// update of the virtual, which includes ScriptValidation
+	VirtualUpdate Stage = "virtualUpdate"
+
+	// ScriptValidation is the validation of the scripts of a single transaction that is
+	// validated while blocks are processed. A duration is recorded for every transaction.
+	ScriptValidation Stage = "scriptValidation"
+
+	// DatabaseCommit is the write of the changes of the block, including those of the
+	// virtual UTXO set, to the database
+	DatabaseCommit Stage = "databaseCommit"
+
+	// BlockProcessing is the whole validation and insertion of a block into consensus
+	BlockProcessing Stage = "blockProcessing"
+
+	// TXIndexUpdate, UTXOIndexUpdate and CFIndexUpdate are the updates of the optional
+	// indexes. The UTXO index is updated once per change of the virtual.
+	TXIndexUpdate   Stage = "txIndexUpdate"
+	UTXOIndexUpdate Stage = "utxoIndexUpdate"
+	CFIndexUpdate   Stage = "cfIndexUpdate"
+)
+
+// Stages are all the stages, in the order in which a block goes through them
+var Stages = []Stage{
+	Deserialization,
+	IsolationChecks,
+	ContextChecks,
+	VirtualUpdate,
+	ScriptValidation,
+	DatabaseCommit,
+	BlockProcessing,
+	TXIndexUpdate,
+	UTXOIndexUpdate,
+	CFIndexUpdate,
+}
+
+// windowSize is the number of most recent durations of each stage the statistics are
+// calculated over
+const windowSize = 1000
+
+type stageDurations struct {
+	count     uint64
+	durations []time.Duration
+	next      int
+}
+
+var (
+	durationsByStage = make(map[Stage]*stageDurations)
+	lock             sync.Mutex
+)
+
+// stageHistograms export the durations of every stage as time series, unlike the
+// statistics, which are only of the recent durations
+var stageHistograms = func() map[Stage]*metrics.Histogram {
+	histograms := make(map[Stage]*metrics.Histogram, len(Stages))
+	for _, stage := range Stages {
+		histograms[stage] = metrics.NewHistogram("kaspad_block_processing_stage_seconds",
+			"How long each stage of block processing takes", metrics.DurationBuckets,
+			metrics.Label{Name: "stage", Value: string(stage)})
+	}
+	return histograms
+}()
+
+// Record records a duration of the given stage
+func Record(stage Stage, duration time.Duration) {
+	if histogram, ok := stageHistograms[stage]; ok {
+		histogram.ObserveDuration(duration)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	recorded, ok := durationsByStage[stage]
+	if !ok {
+		recorded = &stageDurations{durations: make([]time.Duration, 0, windowSize)}
+		durationsByStage[stage] = recorded
+	}
+	recorded.count++
+	if len(recorded.durations) < windowSize {
+		recorded.durations = append(recorded.durations, duration)
+		return
+	}
+	recorded.durations[recorded.next] = duration
+	recorded.next = (recorded.next + 1) % windowSize
+}
+
+// Measure starts measuring a duration of the given stage. The user is expected to
+// call `onEnd` when the stage ends, which records the duration.
+func Measure(stage Stage) (onEnd func()) {
+	start := time.Now()
+	return func() {
+		Record(stage, time.Since(start))
+	}
+}
+
+// StageStats are the statistics of the recent durations of a stage
+type StageStats struct {
+	Stage Stage
+
+	// Count is the number of durations that were recorded since the node started. The
+	// rest of the statistics are of the last windowSize of them.
+	Count uint64
+
+	Average time.Duration
+	P50     time.Duration
+	P90     time.Duration
+	P99     time.Duration
+	Max     time.Duration
+}
+
+// Stats returns the statistics of all the stages, in the order of Stages
+func Stats() []*StageStats {
+	lock.Lock()
+	defer lock.Unlock()
+
+	stats := make([]*StageStats, len(Stages))
+	for i, stage := range Stages {
+		stats[i] = &StageStats{Stage: stage}
+		recorded, ok := durationsByStage[stage]
+		if !ok {
+			continue
+		}
+		stats[i].Count = recorded.count
+
+		sorted := make([]time.Duration, len(recorded.durations))
+		copy(sorted, recorded.durations)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		total := time.Duration(0)
+		for _, duration := range sorted {
+			total += duration
+		}
+		stats[i].Average = total / time.Duration(len(sorted))
+		stats[i].P50 = percentile(sorted, 50)
+		stats[i].P90 = percentile(sorted, 90)
+		stats[i].P99 = percentile(sorted, 99)
+		stats[i].Max = sorted[len(sorted)-1]
+	}
+	return stats
+}
+
+// percentile returns the given percentile of the given sorted durations, by the nearest
+// rank method
+func percentile(sorted []time.Duration, percentile int) time.Duration {
+	rank := (percentile*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
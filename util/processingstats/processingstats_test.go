@@ -0,0 +1,43 @@
+package processingstats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	durationsByStage = make(map[Stage]*stageDurations)
+
+	// The first durations are pushed out of the window by the last windowSize ones
+	for i := 0; i < 10; i++ {
+		Record(ContextChecks, time.Hour)
+	}
+	for i := 1; i <= windowSize; i++ {
+		Record(ContextChecks, time.Duration(i)*time.Millisecond)
+	}
+
+	stats := Stats()
+	if len(stats) != len(Stages) {
+		t.Fatalf("Expected the stats of %d stages but got %d", len(Stages), len(stats))
+	}
+	for _, stageStats := range stats {
+		if stageStats.Stage != ContextChecks {
+			if stageStats.Count != 0 {
+				t.Fatalf("Expected no durations of stage %s but got %d", stageStats.Stage, stageStats.Count)
+			}
+			continue
+		}
+		expected := StageStats{
+			Stage:   ContextChecks,
+			Count:   windowSize + 10,
+			Average: 500500 * time.Microsecond,
+			P50:     500 * time.Millisecond,
+			P90:     900 * time.Millisecond,
+			P99:     990 * time.Millisecond,
+			Max:     windowSize * time.Millisecond,
+		}
+		if *stageStats != expected {
+			t.Fatalf("Expected the stats %+v but got %+v", expected, *stageStats)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package profiling
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The types of profiles a Profiler captures
+const (
+	// ProfileTypeCPU is a CPU profile, captured from Start until Stop
+	ProfileTypeCPU = "cpu"
+
+	// ProfileTypeTrace is a Go execution trace, captured from Start until Stop
+	ProfileTypeTrace = "trace"
+
+	// ProfileTypeHeap is a snapshot of the heap, written at once by Start
+	ProfileTypeHeap = "heap"
+
+	// ProfileTypeGoroutine is a dump of the stacks of all goroutines, written at once by Start
+	ProfileTypeGoroutine = "goroutine"
+)
+
+// ProfileTypes returns all the types of profiles a Profiler captures
+func ProfileTypes() []string {
+	return []string{ProfileTypeCPU, ProfileTypeTrace, ProfileTypeHeap, ProfileTypeGoroutine}
+}
+
+// Profiler captures profiles and execution traces of the running process on demand, into
+// files in its directory, so that a node can be diagnosed without restarting it with
+// the profile server enabled
+type Profiler struct {
+	dir string
+
+	mtx     sync.Mutex
+	running map[string]*runningCapture
+}
+
+type runningCapture struct {
+	file      *os.File
+	startTime time.Time
+}
+
+// NewProfiler returns a Profiler that writes its files to dir
+func NewProfiler(dir string) *Profiler {
+	return &Profiler{
+		dir:     dir,
+		running: make(map[string]*runningCapture),
+	}
+}
+
+// Start starts capturing a profile of the given type and returns the path of its file.
+// CPU profiles and execution traces are captured until Stop is called, while heap
+// and goroutine profiles are written at once.
+func (p *Profiler) Start(profileType string) (string, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	switch profileType {
+	case ProfileTypeCPU, ProfileTypeTrace:
+		if _, ok := p.running[profileType]; ok {
+			return "", errors.Errorf("a %s profile is already being captured", profileType)
+		}
+	case ProfileTypeHeap, ProfileTypeGoroutine:
+	default:
+		return "", errors.Errorf("unknown profile type %q. The supported types are %s",
+			profileType, ProfileTypes())
+	}
+
+	file, err := p.createFile(profileType)
+	if err != nil {
+		return "", err
+	}
+
+	switch profileType {
+	case ProfileTypeCPU:
+		err = pprof.StartCPUProfile(file)
+	case ProfileTypeTrace:
+		err = trace.Start(file)
+	case ProfileTypeHeap:
+		err = pprof.Lookup("heap").WriteTo(file, 0)
+	case ProfileTypeGoroutine:
+		// Debug level 2 writes the stacks in the format of an unrecovered panic, which
+		// is readable without any tool
+		err = pprof.Lookup("goroutine").WriteTo(file, 2)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", errors.Wrapf(err, "could not capture a %s profile", profileType)
+	}
+
+	if profileType == ProfileTypeCPU || profileType == ProfileTypeTrace {
+		p.running[profileType] = &runningCapture{file: file, startTime: time.Now()}
+		return file.Name(), nil
+	}
+	err = file.Close()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return file.Name(), nil
+}
+
+// Stop stops capturing the profile of the given type, and returns the path of its
+// file and how long it was captured for
+func (p *Profiler) Stop(profileType string) (string, time.Duration, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	return p.stop(profileType)
+}
+
+// StopAll stops capturing all the running profiles, so that their files are complete
+// when the node shuts down. It returns the first error it encountered.
+func (p *Profiler) StopAll() error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	var firstErr error
+	for _, profileType := range p.runningTypes() {
+		_, _, err := p.stop(profileType)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (p *Profiler) stop(profileType string) (string, time.Duration, error) {
+	capture, ok := p.running[profileType]
+	if !ok {
+		return "", 0, errors.Errorf("no %s profile is being captured", profileType)
+	}
+	delete(p.running, profileType)
+
+	switch profileType {
+	case ProfileTypeCPU:
+		pprof.StopCPUProfile()
+	case ProfileTypeTrace:
+		trace.Stop()
+	}
+	duration := time.Since(capture.startTime)
+
+	err := capture.file.Close()
+	if err != nil {
+		return "", 0, errors.WithStack(err)
+	}
+	return capture.file.Name(), duration, nil
+}
+
+func (p *Profiler) runningTypes() []string {
+	profileTypes := make([]string, 0, len(p.running))
+	for profileType := range p.running {
+		profileTypes = append(profileTypes, profileType)
+	}
+	sort.Strings(profileTypes)
+	return profileTypes
+}
+
+func (p *Profiler) createFile(profileType string) (*os.File, error) {
+	err := os.MkdirAll(p.dir, 0700)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create the profiles directory %s", p.dir)
+	}
+
+	extension := "pprof"
+	if profileType == ProfileTypeTrace {
+		extension = "trace"
+	}
+	// The time format is the one of the heap dumps, with milliseconds so that profiles
+	// captured in the same second don't overwrite each other
+	fileName := fmt.Sprintf("%s-%s.%s", profileType, time.Now().Format("01-02-2006T15.04.05.000"), extension)
+	file, err := os.Create(filepath.Join(p.dir, fileName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create the profile file")
+	}
+	return file, nil
+}
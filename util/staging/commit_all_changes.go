@@ -15,6 +15,7 @@ func CommitAllChanges(databaseContext model.DBManager, stagingArea *model.Stagin
 	if err != nil {
 		return err
 	}
+	defer dbTx.RollbackUnlessClosed()
 
 	err = stagingArea.Commit(dbTx)
 	if err != nil {
@@ -0,0 +1,250 @@
+package timesource
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Manual is a deterministic Source. Its clock only moves when Advance is called,
+// and its randomness comes from a generator with a fixed seed, so that running the
+// same code against two Manuals with the same seed yields the same behavior.
+type Manual struct {
+	lock          sync.Mutex
+	now           time.Time
+	random        *rand.Rand
+	timers        []*manualTimer
+	isAdvancing   bool
+	pendingDelays []time.Duration
+}
+
+// NewManual returns a Manual whose clock starts at start and whose randomness
+// is seeded with seed
+func NewManual(seed int64, start time.Time) *Manual {
+	return &Manual{
+		now:    start,
+		random: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Now returns the time of the clock of m
+func (m *Manual) Now() time.Time {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.now
+}
+
+// Since returns the time elapsed on the clock of m since t
+func (m *Manual) Since(t time.Time) time.Duration {
+	return m.Now().Sub(t)
+}
+
+// AfterFunc calls f once the clock of m is advanced by d. Unlike time.AfterFunc,
+// f is called synchronously by Advance, so that the order of the calls is deterministic.
+func (m *Manual) AfterFunc(d time.Duration, f func()) Timer {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	timer := &manualTimer{
+		manual:   m,
+		deadline: m.now.Add(d),
+		f:        f,
+	}
+	m.addTimer(timer)
+	return timer
+}
+
+// NewTicker returns a Ticker that ticks every time the clock of m is advanced by d.
+// Like time.Ticker, ticks are dropped if the receiver doesn't keep up.
+func (m *Manual) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	timer := &manualTimer{
+		manual:   m,
+		deadline: m.now.Add(d),
+		period:   d,
+		c:        make(chan time.Time, 1),
+	}
+	m.addTimer(timer)
+	return manualTicker{timer}
+}
+
+// Int63n returns a random number in [0,n) from the seeded generator of m
+func (m *Manual) Int63n(n int64) int64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.random.Int63n(n)
+}
+
+// Intn returns a random number in [0,n) from the seeded generator of m
+func (m *Manual) Intn(n int) int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.random.Intn(n)
+}
+
+// Float64 returns a random number in [0.0,1.0) from the seeded generator of m
+func (m *Manual) Float64() float64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.random.Float64()
+}
+
+// Shuffle randomizes the order of n elements using the seeded generator of m
+func (m *Manual) Shuffle(n int, swap func(i, j int)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.random.Shuffle(n, swap)
+}
+
+// Advance moves the clock of m forward by d, firing the timers and tickers whose
+// deadlines are reached on the way in the order of their deadlines. Timers that
+// share a deadline fire in the order they were created. The clock reads the deadline
+// of every timer while it fires, so code that's triggered by a timer and schedules
+// another one sees the same time it would have seen with the system clock.
+//
+// Calling Advance from a timer's function schedules the advancement to take place
+// once the current one is done.
+func (m *Manual) Advance(d time.Duration) {
+	m.lock.Lock()
+	if m.isAdvancing {
+		m.pendingDelays = append(m.pendingDelays, d)
+		m.lock.Unlock()
+		return
+	}
+	m.isAdvancing = true
+	m.lock.Unlock()
+
+	for {
+		m.advance(d)
+
+		m.lock.Lock()
+		if len(m.pendingDelays) == 0 {
+			m.isAdvancing = false
+			m.lock.Unlock()
+			return
+		}
+		d = m.pendingDelays[0]
+		m.pendingDelays = m.pendingDelays[1:]
+		m.lock.Unlock()
+	}
+}
+
+func (m *Manual) advance(d time.Duration) {
+	m.lock.Lock()
+	target := m.now.Add(d)
+	m.lock.Unlock()
+
+	for {
+		m.lock.Lock()
+		if len(m.timers) == 0 || m.timers[0].deadline.After(target) {
+			m.now = target
+			m.lock.Unlock()
+			return
+		}
+
+		timer := m.timers[0]
+		m.timers = m.timers[1:]
+		m.now = timer.deadline
+		now := m.now
+		if timer.period > 0 {
+			timer.deadline = timer.deadline.Add(timer.period)
+			m.addTimer(timer)
+		}
+		m.lock.Unlock()
+
+		if timer.period > 0 {
+			select {
+			case timer.c <- now:
+			default:
+			}
+			continue
+		}
+		timer.f()
+	}
+}
+
+// PendingTimers returns the number of timers and tickers of m that are yet to fire
+func (m *Manual) PendingTimers() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return len(m.timers)
+}
+
+// addTimer inserts timer into the timers of m, keeping them sorted by their
+// deadlines. This function must be called with the lock of m held.
+func (m *Manual) addTimer(timer *manualTimer) {
+	index := sort.Search(len(m.timers), func(i int) bool {
+		return m.timers[i].deadline.After(timer.deadline)
+	})
+	m.timers = append(m.timers, nil)
+	copy(m.timers[index+1:], m.timers[index:])
+	m.timers[index] = timer
+}
+
+// removeTimer removes timer from the timers of m. Returns false if it wasn't
+// there. This function must be called with the lock of m held.
+func (m *Manual) removeTimer(timer *manualTimer) bool {
+	for i, pendingTimer := range m.timers {
+		if pendingTimer == timer {
+			m.timers = append(m.timers[:i], m.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// manualTimer is the Timer of Manual, and also backs its Ticker. A timer with
+// a non-zero period is a ticker.
+type manualTimer struct {
+	manual   *Manual
+	deadline time.Time
+	f        func()
+	period   time.Duration
+	c        chan time.Time
+}
+
+func (mt *manualTimer) Stop() bool {
+	mt.manual.lock.Lock()
+	defer mt.manual.lock.Unlock()
+
+	return mt.manual.removeTimer(mt)
+}
+
+type manualTicker struct {
+	*manualTimer
+}
+
+func (mt manualTicker) C() <-chan time.Time {
+	return mt.c
+}
+
+func (mt manualTicker) Stop() {
+	mt.manualTimer.Stop()
+}
+
+func (mt manualTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+
+	mt.manual.lock.Lock()
+	defer mt.manual.lock.Unlock()
+
+	mt.manual.removeTimer(mt.manualTimer)
+	mt.period = d
+	mt.deadline = mt.manual.now.Add(d)
+	mt.manual.addTimer(mt.manualTimer)
+}
@@ -0,0 +1,139 @@
+package timesource
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestManualAfterFunc(t *testing.T) {
+	start := time.Unix(1000, 0)
+	manual := NewManual(0, start)
+
+	var fired []int
+	var firedAt []time.Time
+	addTimer := func(d time.Duration, id int) Timer {
+		return manual.AfterFunc(d, func() {
+			fired = append(fired, id)
+			firedAt = append(firedAt, manual.Now())
+		})
+	}
+	addTimer(3*time.Second, 1)
+	addTimer(time.Second, 2)
+	addTimer(3*time.Second, 3)
+	stoppedTimer := addTimer(2*time.Second, 4)
+
+	if !stoppedTimer.Stop() {
+		t.Fatalf("Stop returned false for a pending timer")
+	}
+	if stoppedTimer.Stop() {
+		t.Fatalf("Stop returned true for a stopped timer")
+	}
+
+	manual.Advance(500 * time.Millisecond)
+	if len(fired) != 0 {
+		t.Fatalf("Timers fired before their deadlines: %v", fired)
+	}
+
+	manual.Advance(5 * time.Second)
+	expectedFired := []int{2, 1, 3}
+	if !reflect.DeepEqual(fired, expectedFired) {
+		t.Fatalf("Timers fired in order %v, expected %v", fired, expectedFired)
+	}
+	expectedFiredAt := []time.Time{start.Add(time.Second), start.Add(3 * time.Second), start.Add(3 * time.Second)}
+	if !reflect.DeepEqual(firedAt, expectedFiredAt) {
+		t.Fatalf("Timers fired at %v, expected %v", firedAt, expectedFiredAt)
+	}
+	if !manual.Now().Equal(start.Add(5500 * time.Millisecond)) {
+		t.Fatalf("Unexpected time after advancing: %s", manual.Now())
+	}
+	if manual.PendingTimers() != 0 {
+		t.Fatalf("Expected no pending timers, but got %d", manual.PendingTimers())
+	}
+}
+
+func TestManualAfterFuncSchedulingAnotherTimer(t *testing.T) {
+	manual := NewManual(0, time.Unix(0, 0))
+
+	count := 0
+	var schedule func()
+	schedule = func() {
+		manual.AfterFunc(time.Second, func() {
+			count++
+			schedule()
+		})
+	}
+	schedule()
+
+	manual.Advance(10 * time.Second)
+	if count != 10 {
+		t.Fatalf("Expected the timer to fire 10 times, but it fired %d times", count)
+	}
+}
+
+func TestManualTicker(t *testing.T) {
+	start := time.Unix(0, 0)
+	manual := NewManual(0, start)
+
+	ticker := manual.NewTicker(time.Second)
+	manual.Advance(time.Second)
+	select {
+	case tick := <-ticker.C():
+		if !tick.Equal(start.Add(time.Second)) {
+			t.Fatalf("Unexpected tick time: %s", tick)
+		}
+	default:
+		t.Fatalf("Expected a tick")
+	}
+
+	// Ticks that aren't received are dropped
+	manual.Advance(3 * time.Second)
+	<-ticker.C()
+	select {
+	case <-ticker.C():
+		t.Fatalf("Expected ticks to be dropped")
+	default:
+	}
+
+	ticker.Reset(10 * time.Second)
+	manual.Advance(9 * time.Second)
+	select {
+	case <-ticker.C():
+		t.Fatalf("Unexpected tick before the reset period elapsed")
+	default:
+	}
+	manual.Advance(time.Second)
+	select {
+	case <-ticker.C():
+	default:
+		t.Fatalf("Expected a tick after the reset period elapsed")
+	}
+
+	ticker.Stop()
+	manual.Advance(time.Minute)
+	select {
+	case <-ticker.C():
+		t.Fatalf("Unexpected tick from a stopped ticker")
+	default:
+	}
+}
+
+func TestManualRandomnessIsReproducible(t *testing.T) {
+	draw := func(source Source) []int64 {
+		values := make([]int64, 0, 10)
+		for i := 0; i < 10; i++ {
+			values = append(values, source.Int63n(1000))
+		}
+		return values
+	}
+
+	first := draw(NewManual(42, time.Unix(0, 0)))
+	second := draw(NewManual(42, time.Unix(0, 0)))
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("Manuals with the same seed drew different values: %v and %v", first, second)
+	}
+	third := draw(NewManual(43, time.Unix(0, 0)))
+	if reflect.DeepEqual(first, third) {
+		t.Fatalf("Manuals with different seeds drew the same values: %v", first)
+	}
+}
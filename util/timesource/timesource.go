@@ -0,0 +1,104 @@
+package timesource
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Source supplies the clock, the timers and the randomness that timing-dependent
+// code relies on, such as the trickle timers of transaction relay, the ping
+// interval and the connection retry backoff. Taking them from a Source rather than
+// from the time and math/rand packages directly allows replacing them with a
+// deterministic implementation in tests and simulations.
+type Source interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// Since returns the time elapsed since t
+	Since(t time.Time) time.Duration
+
+	// AfterFunc calls f in its own goroutine once d has elapsed
+	AfterFunc(d time.Duration, f func()) Timer
+
+	// NewTicker returns a Ticker that ticks every d
+	NewTicker(d time.Duration) Ticker
+
+	// Int63n returns a random number in [0,n). It panics if n <= 0.
+	Int63n(n int64) int64
+
+	// Intn returns a random number in [0,n). It panics if n <= 0.
+	Intn(n int) int
+
+	// Float64 returns a random number in [0.0,1.0)
+	Float64() float64
+
+	// Shuffle randomizes the order of n elements, using swap to swap them
+	Shuffle(n int, swap func(i, j int))
+}
+
+// Timer is a timer that was created by Source.AfterFunc
+type Timer interface {
+	// Stop prevents the timer from firing. Returns false if the timer had
+	// already fired or been stopped.
+	Stop() bool
+}
+
+// Ticker is a ticker that was created by Source.NewTicker
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered
+	C() <-chan time.Time
+
+	// Reset stops the ticker and resets its period to d
+	Reset(d time.Duration)
+
+	// Stop turns off the ticker
+	Stop()
+}
+
+type systemSource struct{}
+
+// System returns the Source that is backed by the system clock and by the
+// global generator of math/rand
+func System() Source {
+	return systemSource{}
+}
+
+func (systemSource) Now() time.Time {
+	return time.Now()
+}
+
+func (systemSource) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (systemSource) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+func (systemSource) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+func (systemSource) Int63n(n int64) int64 {
+	return rand.Int63n(n)
+}
+
+func (systemSource) Intn(n int) int {
+	return rand.Intn(n)
+}
+
+func (systemSource) Float64() float64 {
+	return rand.Float64()
+}
+
+func (systemSource) Shuffle(n int, swap func(i, j int)) {
+	rand.Shuffle(n, swap)
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (st systemTicker) C() <-chan time.Time {
+	return st.Ticker.C
+}
@@ -42,7 +42,7 @@ func (c *Calculator) CalculateTransactionMass(transaction *externalapi.DomainTra
 	}
 
 	// calculate mass for size
-	size := transactionEstimatedSerializedSize(transaction)
+	size := TransactionEstimatedSerializedSize(transaction)
 	massForSize := size * c.massPerTxByte
 
 	// calculate mass for scriptPubKey
@@ -133,11 +133,11 @@ func (c *Calculator) CalculateTransactionOverallMass(transaction *externalapi.Do
 	return max(c.CalculateTransactionMass(transaction), c.CalculateTransactionStorageMass(transaction))
 }
 
-// transactionEstimatedSerializedSize is the estimated size of a transaction in some
+// TransactionEstimatedSerializedSize is the estimated size of a transaction in some
 // serialization. This has to be deterministic, but not necessarily accurate, since
 // it's only used as the size component in the transaction and block mass limit
 // calculation.
-func transactionEstimatedSerializedSize(tx *externalapi.DomainTransaction) uint64 {
+func TransactionEstimatedSerializedSize(tx *externalapi.DomainTransaction) uint64 {
 	if transactionhelper.IsCoinBase(tx) {
 		return 0
 	}
@@ -182,7 +182,7 @@ func outpointEstimatedSerializedSize() uint64 {
 	return size
 }
 
-// TransactionOutputEstimatedSerializedSize is the same as transactionEstimatedSerializedSize but for outputs only
+// TransactionOutputEstimatedSerializedSize is the same as TransactionEstimatedSerializedSize but for outputs only
 func TransactionOutputEstimatedSerializedSize(output *externalapi.DomainTransactionOutput) uint64 {
 	size := uint64(0)
 	size += 8 // value (uint64)